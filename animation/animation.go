@@ -5,7 +5,10 @@
 // Package animation
 package animation
 
-import "github.com/g3n/engine/util/logger"
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/util/logger"
+)
 
 // Package logger
 var log = logger.New("ANIMATION", logger.Default)
@@ -13,26 +16,132 @@ var log = logger.New("ANIMATION", logger.Default)
 // Animation is a keyframe animation, containing channels.
 // Each channel animates a specific property of an object.
 // Animations can span multiple objects and properties.
+// Animations dispatch OnEvent when their playback crosses their named events.
 type Animation struct {
-	name     string     // Animation name
-	loop     bool       // Whether the animation loops
-	paused   bool       // Whether the animation is paused
-	start    float32    // Initial time offset value
-	time     float32    // Total running time
-	minTime  float32    // Minimum time value across all channels
-	maxTime  float32    // Maximum time value across all channels
-	speed    float32    // Animation speed multiplier
-	channels []IChannel // List of channels
+	core.Dispatcher                             // Embedded event dispatcher
+	name            string                      // Animation name
+	loop            bool                        // Whether the animation loops
+	paused          bool                        // Whether the animation is paused
+	start           float32                     // Initial time offset value
+	time            float32                     // Total running time
+	minTime         float32                     // Minimum time value across all channels
+	maxTime         float32                     // Maximum time value across all channels
+	speed           float32                     // Animation speed multiplier
+	channels        []IChannel                  // List of channels
+	events          []*Event                    // Named events sorted by time
+	atStart         bool                        // Whether the events at the current time were not dispatched yet
+	loader          func(anim *Animation) error // Function which adds the channels of a lazily loaded animation
+	loaded          bool                        // Whether the loader was called
+	loadErr         error                       // Error returned by the loader
+	tolerance       float32                     // Keyframe reduction tolerance applied to lazily loaded channels
+	quantize        bool                        // Whether lazily loaded channels are quantized
 }
 
 // NewAnimation creates and returns a pointer to a new Animation object.
 func NewAnimation() *Animation {
 
 	anim := new(Animation)
+	anim.Dispatcher.Initialize()
 	anim.speed = 1
+	anim.atStart = true
 	return anim
 }
 
+// NewLazyAnimation creates and returns a pointer to a new Animation whose channels are added
+// by the specified loader function the first time the animation is updated, reset or its
+// channels or time range are requested, so that animations which are never played
+// do not use memory for their keyframes.
+func NewLazyAnimation(loader func(anim *Animation) error) *Animation {
+
+	anim := NewAnimation()
+	anim.loader = loader
+	return anim
+}
+
+// Load calls the loader of a lazily loaded animation if it was not called yet
+// and returns its error. It does nothing for other animations.
+func (anim *Animation) Load() error {
+
+	if anim.loader == nil || anim.loaded {
+		return anim.loadErr
+	}
+	anim.loaded = true
+	anim.loadErr = anim.loader(anim)
+	if anim.loadErr != nil {
+		log.Error("Error loading animation '%s': %v", anim.name, anim.loadErr)
+		return anim.loadErr
+	}
+	if anim.tolerance > 0 {
+		anim.Reduce(anim.tolerance)
+	}
+	if anim.quantize {
+		anim.Quantize()
+	}
+	return nil
+}
+
+// Loaded returns whether the channels of the animation are loaded.
+func (anim *Animation) Loaded() bool {
+
+	return anim.loader == nil || anim.loaded
+}
+
+// Unload removes the channels of a lazily loaded animation, releasing their memory.
+// They are loaded again the next time the animation is used.
+func (anim *Animation) Unload() {
+
+	if anim.loader == nil {
+		return
+	}
+	anim.channels = nil
+	anim.minTime = 0
+	anim.maxTime = 0
+	anim.loaded = false
+	anim.loadErr = nil
+}
+
+// SetCompression sets the keyframe reduction tolerance (0 for none) and whether the values are
+// quantized, which are applied to the channels of a lazily loaded animation when they are loaded.
+// They are applied immediately to the channels already loaded.
+func (anim *Animation) SetCompression(tolerance float32, quantize bool) {
+
+	anim.tolerance = tolerance
+	anim.quantize = quantize
+	if anim.loader != nil && !anim.loaded {
+		return
+	}
+	if tolerance > 0 {
+		anim.Reduce(tolerance)
+	}
+	if quantize {
+		anim.Quantize()
+	}
+}
+
+// Reduce removes from all the channels which implement ICompressible the keyframes which are reproduced
+// within the specified tolerance by the interpolation of the remaining keyframes and returns the number
+// of keyframes removed.
+func (anim *Animation) Reduce(tolerance float32) int {
+
+	removed := 0
+	for _, ch := range anim.channels {
+		if cc, ok := ch.(ICompressible); ok {
+			removed += cc.Reduce(tolerance)
+		}
+	}
+	return removed
+}
+
+// Quantize stores the values of all the channels which implement ICompressible as 16 bit integers.
+func (anim *Animation) Quantize() {
+
+	for _, ch := range anim.channels {
+		if cc, ok := ch.(ICompressible); ok {
+			cc.Quantize()
+		}
+	}
+}
+
 // SetName sets the animation name.
 func (anim *Animation) SetName(name string) {
 
@@ -60,7 +169,9 @@ func (anim *Animation) Speed() float32 {
 // Reset resets the animation to the beginning.
 func (anim *Animation) Reset() {
 
+	anim.Load()
 	anim.time = anim.start
+	anim.atStart = true
 
 	// Update all channels
 	for i := range anim.channels {
@@ -99,31 +210,64 @@ func (anim *Animation) SetStart(v float32) {
 	anim.start = v
 }
 
+// TimeRange returns the minimum and maximum times of the keyframes of all the channels.
+func (anim *Animation) TimeRange() (float32, float32) {
+
+	anim.Load()
+	return anim.minTime, anim.maxTime
+}
+
 // Update interpolates and updates the target values for each channel.
 // If the animation is paused, returns false. If the animation is not paused,
 // returns true if the input value is inside the key frames ranges or false otherwise.
+// The events crossed by the playback, forwards or backwards, are dispatched in order.
 func (anim *Animation) Update(delta float32) {
 
 	// Check if paused
 	if anim.paused {
 		return
 	}
+	anim.Load()
 
-	// Check if input is less than minimum
+	prev := anim.time
+	inclusive := anim.atStart
+	anim.atStart = false
 	anim.time = anim.time + delta*anim.speed
+	duration := anim.maxTime - anim.minTime
+
+	// Check if input is less than minimum
 	if anim.time < anim.minTime {
-		return
+		if !anim.loop || anim.time >= prev || duration <= 0 {
+			anim.dispatchEvents(prev, anim.time, inclusive)
+			return
+		}
+		// Wraps around to the end when playing backwards
+		for anim.time < anim.minTime {
+			anim.dispatchEvents(prev, anim.minTime, inclusive)
+			anim.time += duration
+			prev = anim.maxTime
+			inclusive = true
+		}
 	}
 
 	// Check if input is greater than maximum
 	if anim.time > anim.maxTime {
-		if anim.loop {
-			anim.time = anim.time - anim.maxTime
+		if anim.loop && duration > 0 {
+			for anim.time > anim.maxTime {
+				anim.dispatchEvents(prev, anim.maxTime, inclusive)
+				anim.time -= duration
+				prev = anim.minTime
+				inclusive = true
+			}
 		} else {
+			anim.dispatchEvents(prev, anim.maxTime, inclusive)
 			anim.time = anim.maxTime - 0.000001
 			anim.SetPaused(true)
+			prev = anim.time
+			inclusive = false
 		}
 	}
+	anim.dispatchEvents(prev, anim.time, inclusive)
 
 	// Update all channels
 	for i := range anim.channels {
@@ -132,6 +276,13 @@ func (anim *Animation) Update(delta float32) {
 	}
 }
 
+// Channels returns the list of channels of the animation.
+func (anim *Animation) Channels() []IChannel {
+
+	anim.Load()
+	return anim.channels
+}
+
 // AddChannel adds a channel to the animation.
 func (anim *Animation) AddChannel(ch IChannel) {
 
@@ -19,6 +19,7 @@ type Channel struct {
 	updateInterpAction func()                   // Function to update interpAction based on interpolation type
 	inTangent          math32.ArrayF32          // Origin tangents for Spline interpolation
 	outTangent         math32.ArrayF32          // End tangents for Spline interpolation
+	quant              *quantizedValues         // Quantized values (nil if not quantized)
 }
 
 // SetBuffers sets the keyframe and value buffers.
@@ -26,6 +27,7 @@ func (c *Channel) SetBuffers(keyframes, values math32.ArrayF32) {
 
 	c.keyframes = keyframes
 	c.values = values
+	c.quant = nil
 }
 
 // Keyframes returns the keyframe buffer.
@@ -35,8 +37,14 @@ func (c *Channel) Keyframes() math32.ArrayF32 {
 }
 
 // Values returns the value buffer.
+// If the values are quantized, returns a dequantized copy.
 func (c *Channel) Values() math32.ArrayF32 {
 
+	if c.quant != nil {
+		values := math32.NewArrayF32(len(c.quant.data), len(c.quant.data))
+		c.getValues(0, values)
+		return values
+	}
 	return c.values
 }
 
@@ -126,22 +134,22 @@ func NewPositionChannel(node core.INode) *PositionChannel {
 		case STEP:
 			pc.interpAction = func(idx int, k float32) {
 				var v math32.Vector3
-				pc.values.GetVector3(idx*3, &v)
+				pc.getVector3(idx*3, &v)
 				node.SetPositionVec(&v)
 			}
 		case LINEAR:
 			pc.interpAction = func(idx int, k float32) {
 				var v1, v2 math32.Vector3
-				pc.values.GetVector3(idx*3, &v1)
-				pc.values.GetVector3((idx+1)*3, &v2)
+				pc.getVector3(idx*3, &v1)
+				pc.getVector3((idx+1)*3, &v2)
 				v1.Lerp(&v2, k)
 				node.SetPositionVec(&v1)
 			}
 		case CUBICSPLINE: // TODO
 			pc.interpAction = func(idx int, k float32) {
 				var v1, v2 math32.Vector3
-				pc.values.GetVector3(idx*3, &v1)
-				pc.values.GetVector3((idx+1)*3, &v2)
+				pc.getVector3(idx*3, &v1)
+				pc.getVector3((idx+1)*3, &v2)
 				v1.Lerp(&v2, k)
 				node.SetPositionVec(&v1)
 			}
@@ -151,6 +159,12 @@ func NewPositionChannel(node core.INode) *PositionChannel {
 	return pc
 }
 
+// Target returns the node animated by this channel.
+func (pc *PositionChannel) Target() core.INode {
+
+	return pc.target
+}
+
 // RotationChannel is the animation channel for a node's rotation.
 type RotationChannel NodeChannel
 
@@ -166,14 +180,14 @@ func NewRotationChannel(node core.INode) *RotationChannel {
 		case STEP:
 			rc.interpAction = func(idx int, k float32) {
 				var q math32.Vector4
-				rc.values.GetVector4(idx*4, &q)
+				rc.getVector4(idx*4, &q)
 				node.SetQuaternionVec(&q)
 			}
 		case LINEAR:
 			rc.interpAction = func(idx int, k float32) {
 				var q1, q2 math32.Vector4
-				rc.values.GetVector4(idx*4, &q1)
-				rc.values.GetVector4((idx+1)*4, &q2)
+				rc.getVector4(idx*4, &q1)
+				rc.getVector4((idx+1)*4, &q2)
 				quat1 := math32.NewQuaternion(q1.X, q1.Y, q1.Z, q1.W)
 				quat2 := math32.NewQuaternion(q2.X, q2.Y, q2.Z, q2.W)
 				quat1.Slerp(quat2, k)
@@ -182,8 +196,8 @@ func NewRotationChannel(node core.INode) *RotationChannel {
 		case CUBICSPLINE: // TODO
 			rc.interpAction = func(idx int, k float32) {
 				var q1, q2 math32.Vector4
-				rc.values.GetVector4(idx*4, &q1)
-				rc.values.GetVector4((idx+1)*4, &q2)
+				rc.getVector4(idx*4, &q1)
+				rc.getVector4((idx+1)*4, &q2)
 				quat1 := math32.NewQuaternion(q1.X, q1.Y, q1.Z, q1.W)
 				quat2 := math32.NewQuaternion(q2.X, q2.Y, q2.Z, q2.W)
 				quat1.Slerp(quat2, k)
@@ -195,6 +209,12 @@ func NewRotationChannel(node core.INode) *RotationChannel {
 	return rc
 }
 
+// Target returns the node animated by this channel.
+func (rc *RotationChannel) Target() core.INode {
+
+	return rc.target
+}
+
 // ScaleChannel is the animation channel for a node's scale.
 type ScaleChannel NodeChannel
 
@@ -210,22 +230,22 @@ func NewScaleChannel(node core.INode) *ScaleChannel {
 		case STEP:
 			sc.interpAction = func(idx int, k float32) {
 				var v math32.Vector3
-				sc.values.GetVector3(idx*3, &v)
+				sc.getVector3(idx*3, &v)
 				node.SetScaleVec(&v)
 			}
 		case LINEAR:
 			sc.interpAction = func(idx int, k float32) {
 				var v1, v2 math32.Vector3
-				sc.values.GetVector3(idx*3, &v1)
-				sc.values.GetVector3((idx+1)*3, &v2)
+				sc.getVector3(idx*3, &v1)
+				sc.getVector3((idx+1)*3, &v2)
 				v1.Lerp(&v2, k)
 				node.SetScaleVec(&v1)
 			}
 		case CUBICSPLINE: // TODO
 			sc.interpAction = func(idx int, k float32) {
 				var v1, v2 math32.Vector3
-				sc.values.GetVector3(idx*3, &v1)
-				sc.values.GetVector3((idx+1)*3, &v2)
+				sc.getVector3(idx*3, &v1)
+				sc.getVector3((idx+1)*3, &v2)
 				v1.Lerp(&v2, k)
 				node.SetScaleVec(&v1)
 			}
@@ -235,10 +255,18 @@ func NewScaleChannel(node core.INode) *ScaleChannel {
 	return sc
 }
 
+// Target returns the node animated by this channel.
+func (sc *ScaleChannel) Target() core.INode {
+
+	return sc.target
+}
+
 // MorphChannel is the IChannel for morph geometries.
 type MorphChannel struct {
 	Channel
-	target *geometry.MorphGeometry
+	target  *geometry.MorphGeometry
+	weights []float32 // Interpolated weights set in the target
+	next    []float32 // Weights of the next keyframe
 }
 
 func NewMorphChannel(mg *geometry.MorphGeometry) *MorphChannel {
@@ -246,38 +274,23 @@ func NewMorphChannel(mg *geometry.MorphGeometry) *MorphChannel {
 	mc := new(MorphChannel)
 	mc.target = mg
 	numWeights := len(mg.Weights())
+	mc.weights = make([]float32, numWeights)
+	mc.next = make([]float32, numWeights)
 	mc.updateInterpAction = func() {
 		// Update interpolation function
 		switch mc.interpType {
 		case STEP:
 			mc.interpAction = func(idx int, k float32) {
-				start := idx * numWeights
-				weights := mc.values[start : start+numWeights]
-				mg.SetWeights(weights)
+				mc.getValues(idx*numWeights, mc.weights)
+				mg.SetWeights(mc.weights)
 			}
 		case LINEAR:
 			mc.interpAction = func(idx int, k float32) {
-				start1 := idx * numWeights
-				start2 := (idx + 1) * numWeights
-				weights1 := mc.values[start1 : start1+numWeights]
-				weights2 := mc.values[start2 : start2+numWeights]
-				weightsNew := make([]float32, numWeights)
-				for i := range weights1 {
-					weightsNew[i] = weights1[i] + (weights2[i]-weights1[i])*k
-				}
-				mg.SetWeights(weightsNew)
+				mc.interpolate(idx, k)
 			}
 		case CUBICSPLINE: // TODO
 			mc.interpAction = func(idx int, k float32) {
-				start1 := idx * numWeights
-				start2 := (idx + 1) * numWeights
-				weights1 := mc.values[start1 : start1+numWeights]
-				weights2 := mc.values[start2 : start2+numWeights]
-				weightsNew := make([]float32, numWeights)
-				for i := range weights1 {
-					weightsNew[i] = weights1[i] + (weights2[i]-weights1[i])*k
-				}
-				mg.SetWeights(weightsNew)
+				mc.interpolate(idx, k)
 			}
 		}
 	}
@@ -285,6 +298,25 @@ func NewMorphChannel(mg *geometry.MorphGeometry) *MorphChannel {
 	return mc
 }
 
+// interpolate sets the weights of the target linearly interpolated between
+// the keyframe with the specified index and the next keyframe.
+func (mc *MorphChannel) interpolate(idx int, k float32) {
+
+	n := len(mc.weights)
+	mc.getValues(idx*n, mc.weights)
+	mc.getValues((idx+1)*n, mc.next)
+	for i := range mc.weights {
+		mc.weights[i] += (mc.next[i] - mc.weights[i]) * k
+	}
+	mc.target.SetWeights(mc.weights)
+}
+
+// Target returns the morph geometry animated by this channel.
+func (mc *MorphChannel) Target() *geometry.MorphGeometry {
+
+	return mc.target
+}
+
 // InterpolationType specifies the interpolation type.
 type InterpolationType string
 
@@ -0,0 +1,191 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// ICompressible is the interface of the channels whose keyframes can be reduced
+// and whose values can be quantized, such as the channels embedding a Channel.
+type ICompressible interface {
+	Reduce(tolerance float32) int
+	Quantize()
+}
+
+// quantizedValues contains the values of a channel quantized to 16 bits.
+type quantizedValues struct {
+	data  []uint16  // Quantized values
+	min   []float32 // Minimum value of each component
+	scale []float32 // Difference between consecutive quantized values of each component
+}
+
+// value returns the dequantized value with the specified index.
+func (q *quantizedValues) value(i int) float32 {
+
+	comp := i % len(q.min)
+	return q.min[comp] + float32(q.data[i])*q.scale[comp]
+}
+
+// Quantize stores the values of the channel as 16 bit integers, scaled to the range of
+// each component, halving their memory. The maximum error of each component is its range
+// divided by 131070. The keyframe times are not quantized.
+func (c *Channel) Quantize() {
+
+	if c.quant != nil || len(c.values) == 0 {
+		return
+	}
+	comps := c.components()
+	if comps == 0 {
+		return
+	}
+	q := &quantizedValues{
+		data:  make([]uint16, len(c.values)),
+		min:   make([]float32, comps),
+		scale: make([]float32, comps),
+	}
+	for comp := 0; comp < comps; comp++ {
+		min := c.values[comp]
+		max := min
+		for i := comp; i < len(c.values); i += comps {
+			min = math32.Min(min, c.values[i])
+			max = math32.Max(max, c.values[i])
+		}
+		q.min[comp] = min
+		q.scale[comp] = (max - min) / 65535
+	}
+	for i, v := range c.values {
+		comp := i % comps
+		if q.scale[comp] > 0 {
+			q.data[i] = uint16(math32.Round((v - q.min[comp]) / q.scale[comp]))
+		}
+	}
+	c.quant = q
+	c.values = nil
+}
+
+// Quantized returns whether the values of the channel are quantized.
+func (c *Channel) Quantized() bool {
+
+	return c.quant != nil
+}
+
+// Reduce removes the keyframes which are reproduced, within the specified tolerance for
+// each component, by the interpolation of the remaining keyframes, and returns the number
+// of keyframes removed. The first and last keyframes are always kept. Linearly interpolated
+// quaternions are compared component-wise, which is accurate for the small rotations between
+// consecutive keyframes. Cubic spline channels are not reduced.
+func (c *Channel) Reduce(tolerance float32) int {
+
+	n := len(c.keyframes)
+	if n < 3 || c.interpType == CUBICSPLINE {
+		return 0
+	}
+	values := c.Values()
+	comps := len(values) / n
+	if comps == 0 {
+		return 0
+	}
+
+	// Selects the keyframes to keep
+	keep := []int{0}
+	for i := 1; i < n-1; i++ {
+		last := keep[len(keep)-1]
+		removable := true
+		if c.interpType == STEP {
+			// The value of the last kept keyframe holds until the next kept keyframe
+			for comp := 0; comp < comps; comp++ {
+				if math32.Abs(values[i*comps+comp]-values[last*comps+comp]) > tolerance {
+					removable = false
+					break
+				}
+			}
+		} else {
+			// The keyframes between the last kept keyframe and the next one must be reproduced
+			next := i + 1
+			span := c.keyframes[next] - c.keyframes[last]
+			if span <= 0 {
+				removable = false
+			}
+			for j := last + 1; j < next && removable; j++ {
+				k := (c.keyframes[j] - c.keyframes[last]) / span
+				for comp := 0; comp < comps; comp++ {
+					v1 := values[last*comps+comp]
+					v2 := values[next*comps+comp]
+					if math32.Abs(v1+(v2-v1)*k-values[j*comps+comp]) > tolerance {
+						removable = false
+						break
+					}
+				}
+			}
+		}
+		if !removable {
+			keep = append(keep, i)
+		}
+	}
+	keep = append(keep, n-1)
+	removed := n - len(keep)
+	if removed == 0 {
+		return 0
+	}
+
+	// Copies the kept keyframes and values
+	keyframes := math32.NewArrayF32(0, len(keep))
+	reduced := math32.NewArrayF32(0, len(keep)*comps)
+	for _, i := range keep {
+		keyframes.Append(c.keyframes[i])
+		reduced.Append(values[i*comps : (i+1)*comps]...)
+	}
+	quantized := c.quant != nil
+	c.SetBuffers(keyframes, reduced)
+	if quantized {
+		c.Quantize()
+	}
+	return removed
+}
+
+// components returns the number of values of each keyframe.
+func (c *Channel) components() int {
+
+	if len(c.keyframes) == 0 {
+		return 0
+	}
+	if c.quant != nil {
+		return len(c.quant.data) / len(c.keyframes)
+	}
+	return len(c.values) / len(c.keyframes)
+}
+
+// getValues copies the values starting at the specified position to the specified slice.
+func (c *Channel) getValues(pos int, dst []float32) {
+
+	if c.quant == nil {
+		copy(dst, c.values[pos:pos+len(dst)])
+		return
+	}
+	for i := range dst {
+		dst[i] = c.quant.value(pos + i)
+	}
+}
+
+// getVector3 sets the specified vector from the values starting at the specified position.
+func (c *Channel) getVector3(pos int, v *math32.Vector3) {
+
+	if c.quant == nil {
+		c.values.GetVector3(pos, v)
+		return
+	}
+	v.Set(c.quant.value(pos), c.quant.value(pos+1), c.quant.value(pos+2))
+}
+
+// getVector4 sets the specified vector from the values starting at the specified position.
+func (c *Channel) getVector4(pos int, v *math32.Vector4) {
+
+	if c.quant == nil {
+		c.values.GetVector4(pos, v)
+		return
+	}
+	v.Set(c.quant.value(pos), c.quant.value(pos+1), c.quant.value(pos+2), c.quant.value(pos+3))
+}
@@ -0,0 +1,108 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import "sort"
+
+// OnEvent is the event dispatched by an Animation, with a pointer to the Event,
+// when its playback crosses one of its named events.
+const OnEvent = "animation.OnEvent"
+
+// Event is a named event at a specific time of an animation, as a footstep or a sound cue.
+type Event struct {
+	Name      string     // Event name
+	Time      float32    // Time of the event
+	Animation *Animation // Animation containing the event
+}
+
+// AddEvent adds a named event at the specified time of the animation and returns a pointer to it.
+// Several events can have the same name or time.
+func (anim *Animation) AddEvent(name string, time float32) *Event {
+
+	ev := &Event{Name: name, Time: time, Animation: anim}
+	// Inserts after the events with the same time to keep the insertion order
+	i := sort.Search(len(anim.events), func(i int) bool { return anim.events[i].Time > time })
+	anim.events = append(anim.events, nil)
+	copy(anim.events[i+1:], anim.events[i:])
+	anim.events[i] = ev
+	return ev
+}
+
+// RemoveEvents removes all the events with the specified name and returns how many were removed.
+func (anim *Animation) RemoveEvents(name string) int {
+
+	events := anim.events[:0]
+	for _, ev := range anim.events {
+		if ev.Name != name {
+			events = append(events, ev)
+		}
+	}
+	removed := len(anim.events) - len(events)
+	for i := len(events); i < len(anim.events); i++ {
+		anim.events[i] = nil
+	}
+	anim.events = events
+	return removed
+}
+
+// ClearEvents removes all the events of the animation.
+func (anim *Animation) ClearEvents() {
+
+	anim.events = nil
+}
+
+// Events returns the events of the animation sorted by time.
+func (anim *Animation) Events() []*Event {
+
+	return anim.events
+}
+
+// Time returns the current time of the animation.
+func (anim *Animation) Time() float32 {
+
+	return anim.time
+}
+
+// SetTime moves the animation to the specified time, as when scrubbing, updating the
+// target values of the channels and dispatching the events crossed forwards or backwards.
+// The time is not wrapped around even if the animation is looping.
+func (anim *Animation) SetTime(time float32) {
+
+	prev := anim.time
+	inclusive := anim.atStart
+	anim.atStart = false
+	anim.time = time
+	anim.dispatchEvents(prev, time, inclusive)
+	for i := range anim.channels {
+		anim.channels[i].Update(time)
+	}
+}
+
+// dispatchEvents dispatches the events crossed when moving from the specified time to the other
+// in the order they are crossed. The events at the final time are included and the events at
+// the initial time only if inclusive is true.
+func (anim *Animation) dispatchEvents(from, to float32, inclusive bool) {
+
+	if from < to || from == to && inclusive {
+		for _, ev := range anim.events {
+			if ev.Time > to {
+				break
+			}
+			if ev.Time > from || inclusive && ev.Time == from {
+				anim.Dispatch(OnEvent, ev)
+			}
+		}
+	} else if from > to {
+		for i := len(anim.events) - 1; i >= 0; i-- {
+			ev := anim.events[i]
+			if ev.Time < to {
+				break
+			}
+			if ev.Time < from || inclusive && ev.Time == from {
+				anim.Dispatch(OnEvent, ev)
+			}
+		}
+	}
+}
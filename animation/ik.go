@@ -0,0 +1,385 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+)
+
+// IKChain is an inverse kinematics chain of joints, usually skeleton bones, which rotates
+// them so the last joint, the end effector, reaches a target position, for instance to plant
+// the feet of a character on the ground or make it reach for objects.
+// Chains of three joints are solved analytically and longer chains with the FABRIK algorithm.
+// Solve should be called each frame after the animations are updated.
+type IKChain struct {
+	joints     []*core.Node     // Joints from the root to the end effector
+	target     math32.Vector3   // Target position of the end effector in world coordinates
+	pole       math32.Vector3   // Position in world coordinates towards which the chain bends
+	hasPole    bool             // Whether the pole is set
+	limits     []float32        // Maximum angle of each joint between its bone and the previous bone (0 for none)
+	weight     float32          // Weight of the solution blended with the current pose
+	iterations int              // Maximum number of FABRIK iterations
+	tolerance  float32          // Distance to the target at which FABRIK stops
+	positions  []math32.Vector3 // Joint positions being solved in world coordinates
+	original   []math32.Vector3 // Joint positions before solving in world coordinates
+	lengths    []float32        // Length of each bone
+}
+
+// NewIKChain creates and returns a pointer to a new IK chain of the specified joints, from the
+// root of the chain to its end effector. Each joint must be the parent or a descendant of the previous one.
+func NewIKChain(joints ...*core.Node) *IKChain {
+
+	ik := new(IKChain)
+	ik.joints = joints
+	ik.limits = make([]float32, len(joints))
+	ik.positions = make([]math32.Vector3, len(joints))
+	ik.original = make([]math32.Vector3, len(joints))
+	ik.lengths = make([]float32, len(joints))
+	ik.weight = 1
+	ik.iterations = 10
+	ik.tolerance = 0.001
+	if len(joints) > 0 {
+		joints[len(joints)-1].WorldPosition(&ik.target)
+	}
+	return ik
+}
+
+// NewIKChainFromSkeleton creates and returns a pointer to a new IK chain of the bones of the
+// specified skeleton from the bone named root to its descendant bone named end.
+func NewIKChainFromSkeleton(sk *graphic.Skeleton, root, end string) (*IKChain, error) {
+
+	var endBone *core.Node
+	for _, bone := range sk.Bones() {
+		if bone.Name() == end {
+			endBone = bone
+			break
+		}
+	}
+	if endBone == nil {
+		return nil, fmt.Errorf("bone '%s' not found", end)
+	}
+
+	// Walks the bone ancestors up to the root bone
+	joints := []*core.Node{endBone}
+	for joints[0].Name() != root {
+		parent := joints[0].Parent()
+		if parent == nil {
+			return nil, fmt.Errorf("bone '%s' is not an ancestor of bone '%s'", root, end)
+		}
+		joints = append([]*core.Node{parent.GetNode()}, joints...)
+	}
+	if len(joints) < 2 {
+		return nil, fmt.Errorf("IK chain needs at least two bones")
+	}
+	return NewIKChain(joints...), nil
+}
+
+// Joints returns the joints of the chain from the root to the end effector.
+func (ik *IKChain) Joints() []*core.Node {
+
+	return ik.joints
+}
+
+// SetTarget sets the position in world coordinates the end effector should reach.
+func (ik *IKChain) SetTarget(target *math32.Vector3) {
+
+	ik.target = *target
+}
+
+// Target returns the position in world coordinates the end effector should reach.
+func (ik *IKChain) Target() math32.Vector3 {
+
+	return ik.target
+}
+
+// SetPole sets the position in world coordinates towards which the chain bends,
+// for instance a point in front of the knee of a leg.
+func (ik *IKChain) SetPole(pole *math32.Vector3) {
+
+	ik.pole = *pole
+	ik.hasPole = true
+}
+
+// ClearPole removes the pole, so the chain bends in the plane of its current pose.
+func (ik *IKChain) ClearPole() {
+
+	ik.hasPole = false
+}
+
+// Pole returns the pole position in world coordinates and whether it is set.
+func (ik *IKChain) Pole() (math32.Vector3, bool) {
+
+	return ik.pole, ik.hasPole
+}
+
+// SetJointLimit sets the maximum angle, in radians, between the bone starting at the
+// specified joint and the previous bone of the chain. Zero (the default) removes the limit.
+// The root joint can't be limited.
+func (ik *IKChain) SetJointLimit(joint int, maxAngle float32) {
+
+	ik.limits[joint] = maxAngle
+}
+
+// JointLimit returns the maximum angle, in radians, of the specified joint (0 for none).
+func (ik *IKChain) JointLimit(joint int) float32 {
+
+	return ik.limits[joint]
+}
+
+// SetWeight sets how much the solution is blended with the current pose,
+// from 0 (no effect) to 1 (the default).
+func (ik *IKChain) SetWeight(weight float32) {
+
+	ik.weight = math32.Clamp(weight, 0, 1)
+}
+
+// Weight returns how much the solution is blended with the current pose.
+func (ik *IKChain) Weight() float32 {
+
+	return ik.weight
+}
+
+// SetIterations sets the maximum number of iterations and the distance to the target at
+// which the FABRIK solver of chains longer than three joints stops. The defaults are 10 and 0.001.
+func (ik *IKChain) SetIterations(iterations int, tolerance float32) {
+
+	ik.iterations = iterations
+	ik.tolerance = tolerance
+}
+
+// Solve rotates the joints of the chain so that the end effector reaches the target or, if it is
+// out of reach, points to it. The world matrices of the ancestors of the chain must be up to date.
+func (ik *IKChain) Solve() {
+
+	n := len(ik.joints)
+	if n < 2 || ik.weight <= 0 {
+		return
+	}
+	ik.joints[0].UpdateMatrixWorld()
+	for i, joint := range ik.joints {
+		joint.WorldPosition(&ik.positions[i])
+		ik.original[i] = ik.positions[i]
+		if i > 0 {
+			ik.lengths[i-1] = ik.positions[i].DistanceTo(&ik.positions[i-1])
+		}
+	}
+	if n == 3 {
+		ik.solveTwoBone()
+	} else {
+		ik.solveFABRIK()
+	}
+	if ik.weight < 1 {
+		for i := range ik.positions {
+			ik.positions[i].Sub(&ik.original[i]).MultiplyScalar(ik.weight).Add(&ik.original[i])
+		}
+	}
+	ik.applyPositions()
+}
+
+// solveTwoBone analytically solves the positions of a chain of three joints.
+func (ik *IKChain) solveTwoBone() {
+
+	a := ik.positions[0]
+	l1 := ik.lengths[0]
+	l2 := ik.lengths[1]
+	if l1 == 0 || l2 == 0 {
+		return
+	}
+
+	// Distance from the root to the end effector, limited by the reach and the joint limit
+	var dir math32.Vector3
+	dir.SubVectors(&ik.target, &a)
+	d := dir.Length()
+	if d < 1e-6 {
+		return
+	}
+	dir.DivideScalar(d)
+	minDist := math32.Abs(l1 - l2)
+	if limit := ik.limits[1]; limit > 0 && limit < math32.Pi {
+		minDist = math32.Sqrt(l1*l1 + l2*l2 + 2*l1*l2*math32.Cos(limit))
+	}
+	d = math32.Clamp(d, minDist+1e-5, l1+l2-1e-5)
+
+	// Direction perpendicular to the target direction in the bend plane
+	perp := ik.bendDirection(&a, &ik.positions[1], &dir)
+
+	// Places the middle joint with the law of cosines
+	cosA := math32.Clamp((l1*l1+d*d-l2*l2)/(2*l1*d), -1, 1)
+	sinA := math32.Sqrt(1 - cosA*cosA)
+	var mid, along math32.Vector3
+	along.Copy(&dir).MultiplyScalar(l1 * cosA)
+	mid.Copy(&perp).MultiplyScalar(l1 * sinA).Add(&along).Add(&a)
+	ik.positions[1] = mid
+	ik.positions[2].Copy(&dir).MultiplyScalar(d).Add(&a)
+}
+
+// bendDirection returns the unit direction, perpendicular to the specified direction from the
+// specified root, towards the pole if set or else towards the specified current joint position.
+func (ik *IKChain) bendDirection(root, joint, dir *math32.Vector3) math32.Vector3 {
+
+	var perp math32.Vector3
+	if ik.hasPole {
+		perp.SubVectors(&ik.pole, root)
+	} else {
+		perp.SubVectors(joint, root)
+	}
+	var proj math32.Vector3
+	proj.Copy(dir).MultiplyScalar(perp.Dot(dir))
+	perp.Sub(&proj)
+	if perp.LengthSq() < 1e-12 {
+		t1, _ := dir.RandomTangents()
+		perp = *t1
+	}
+	perp.Normalize()
+	return perp
+}
+
+// solveFABRIK solves the positions of the joints with the FABRIK algorithm.
+func (ik *IKChain) solveFABRIK() {
+
+	n := len(ik.positions)
+	root := ik.positions[0]
+	total := float32(0)
+	for i := 0; i < n-1; i++ {
+		total += ik.lengths[i]
+	}
+
+	// Straightens the chain towards an unreachable target
+	if root.DistanceTo(&ik.target) >= total {
+		var dir math32.Vector3
+		dir.SubVectors(&ik.target, &root).Normalize()
+		for i := 1; i < n; i++ {
+			ik.positions[i].Copy(&dir).MultiplyScalar(ik.lengths[i-1]).Add(&ik.positions[i-1])
+		}
+		ik.applyLimits()
+		return
+	}
+
+	for iter := 0; iter < ik.iterations; iter++ {
+		// Backward pass from the target
+		ik.positions[n-1] = ik.target
+		for i := n - 2; i >= 0; i-- {
+			ik.placeJoint(i, i+1)
+		}
+		// Forward pass from the root
+		ik.positions[0] = root
+		for i := 1; i < n; i++ {
+			ik.placeJoint(i, i-1)
+		}
+		ik.applyLimits()
+		if ik.positions[n-1].DistanceTo(&ik.target) <= ik.tolerance {
+			break
+		}
+	}
+
+	// Rotates the intermediate joints towards the pole around the line of their neighbors
+	if !ik.hasPole {
+		return
+	}
+	for i := 1; i < n-1; i++ {
+		var axis, joint, pole math32.Vector3
+		axis.SubVectors(&ik.positions[i+1], &ik.positions[i-1])
+		if axis.LengthSq() < 1e-12 {
+			continue
+		}
+		axis.Normalize()
+		joint.SubVectors(&ik.positions[i], &ik.positions[i-1])
+		pole.SubVectors(&ik.pole, &ik.positions[i-1])
+		joint.ProjectOnPlane(&axis)
+		pole.ProjectOnPlane(&axis)
+		if joint.LengthSq() < 1e-12 || pole.LengthSq() < 1e-12 {
+			continue
+		}
+		joint.Normalize()
+		pole.Normalize()
+		var q math32.Quaternion
+		q.SetFromUnitVectors(&joint, &pole)
+		ik.positions[i].Sub(&ik.positions[i-1]).ApplyQuaternion(&q).Add(&ik.positions[i-1])
+	}
+}
+
+// placeJoint places the specified joint at the length of their bone from the specified
+// neighbor joint, in the direction of its current position.
+func (ik *IKChain) placeJoint(joint, neighbor int) {
+
+	length := ik.lengths[joint]
+	if neighbor < joint {
+		length = ik.lengths[neighbor]
+	}
+	var dir math32.Vector3
+	dir.SubVectors(&ik.positions[joint], &ik.positions[neighbor])
+	if dir.LengthSq() < 1e-12 {
+		dir.Set(0, 1, 0)
+	}
+	dir.Normalize().MultiplyScalar(length)
+	ik.positions[joint].AddVectors(&ik.positions[neighbor], &dir)
+}
+
+// applyLimits rotates the bones whose angle with the previous bone exceeds their joint limit.
+func (ik *IKChain) applyLimits() {
+
+	for i := 1; i < len(ik.positions)-1; i++ {
+		limit := ik.limits[i]
+		if limit <= 0 {
+			continue
+		}
+		var prev, dir math32.Vector3
+		prev.SubVectors(&ik.positions[i], &ik.positions[i-1]).Normalize()
+		dir.SubVectors(&ik.positions[i+1], &ik.positions[i]).Normalize()
+		angle := prev.AngleTo(&dir)
+		if angle <= limit {
+			continue
+		}
+		var axis math32.Vector3
+		axis.CrossVectors(&prev, &dir)
+		if axis.LengthSq() < 1e-12 {
+			continue
+		}
+		axis.Normalize()
+		dir.Copy(&prev).ApplyAxisAngle(&axis, limit).MultiplyScalar(ik.lengths[i])
+		// Moves the following joints rigidly with the limited bone
+		offset := ik.positions[i+1]
+		ik.positions[i+1].AddVectors(&ik.positions[i], &dir)
+		offset.SubVectors(&ik.positions[i+1], &offset)
+		for j := i + 2; j < len(ik.positions); j++ {
+			ik.positions[j].Add(&offset)
+		}
+	}
+}
+
+// applyPositions rotates the joints so that the bones point to the solved joint positions.
+func (ik *IKChain) applyPositions() {
+
+	for i := 0; i < len(ik.joints)-1; i++ {
+		joint := ik.joints[i]
+		var cur, next, from, to math32.Vector3
+		joint.WorldPosition(&cur)
+		ik.joints[i+1].WorldPosition(&next)
+		from.SubVectors(&next, &cur)
+		to.SubVectors(&ik.positions[i+1], &cur)
+		if from.LengthSq() < 1e-12 || to.LengthSq() < 1e-12 {
+			continue
+		}
+		from.Normalize()
+		to.Normalize()
+
+		// Rotates the world rotation of the joint and converts it to the parent space
+		var delta, world, parent math32.Quaternion
+		delta.SetFromUnitVectors(&from, &to)
+		joint.WorldQuaternion(&world)
+		world.MultiplyQuaternions(&delta, &world)
+		parent.SetIdentity()
+		if p := joint.Parent(); p != nil {
+			p.GetNode().WorldQuaternion(&parent)
+		}
+		parent.Inverse().Multiply(&world)
+		joint.SetQuaternionQuat(&parent)
+		joint.UpdateMatrixWorld()
+	}
+}
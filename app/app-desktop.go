@@ -2,8 +2,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-//go:build !wasm
-// +build !wasm
+//go:build !wasm && !soft
+// +build !wasm,!soft
 
 package app
 
@@ -31,13 +31,20 @@ type Application struct {
 // App returns the Application singleton, creating it the first time.
 func App(width, height int, title string) *Application {
 
+	return AppWithOptions(&window.Options{Width: width, Height: height, Title: title})
+}
+
+// AppWithOptions returns the Application singleton, creating it the first time
+// with a window created with the specified options.
+func AppWithOptions(opts *window.Options) *Application {
+
 	// Return singleton if already created
 	if a != nil {
 		return a
 	}
 	a = new(Application)
 	// Initialize window
-	err := window.Init(width, height, title)
+	err := window.InitWithOptions(opts)
 	if err != nil {
 		panic(err)
 	}
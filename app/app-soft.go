@@ -0,0 +1,104 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build soft && !wasm
+// +build soft,!wasm
+
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/g3n/engine/renderer"
+	"github.com/g3n/engine/window"
+)
+
+// Application
+type Application struct {
+	window.IWindow                    // Embedded HeadlessWindow
+	keyState       *window.KeyState   // Keep track of keyboard state
+	renderer       *renderer.Renderer // Renderer object
+	startTime      time.Time          // Application start time
+	frameStart     time.Time          // Frame start time
+	frameDelta     time.Duration      // Duration of last frame
+}
+
+// App returns the Application singleton, creating it the first time.
+// With the "soft" build tag the application has a headless window
+// rendered by the software OpenGL state and no audio.
+func App(width, height int, title string) *Application {
+
+	return AppWithOptions(&window.Options{Width: width, Height: height, Title: title})
+}
+
+// AppWithOptions returns the Application singleton, creating it the first time
+// with a window created with the specified options.
+func AppWithOptions(opts *window.Options) *Application {
+
+	// Return singleton if already created
+	if a != nil {
+		return a
+	}
+	a = new(Application)
+	// Initialize window
+	err := window.InitWithOptions(opts)
+	if err != nil {
+		panic(err)
+	}
+	a.IWindow = window.Get()
+	a.keyState = window.NewKeyState(a) // Create KeyState
+	// Create renderer and add default shaders
+	a.renderer = renderer.NewRenderer(a.Gls())
+	err = a.renderer.AddDefaultShaders()
+	if err != nil {
+		panic(fmt.Errorf("AddDefaultShaders:%v", err))
+	}
+	return a
+}
+
+// Run starts the update loop.
+// It calls the user-provided update function every frame until Exit is called.
+func (a *Application) Run(update func(rend *renderer.Renderer, deltaTime time.Duration)) {
+
+	// Initialize start and frame time
+	a.startTime = time.Now()
+	a.frameStart = time.Now()
+
+	w := a.IWindow.(*window.HeadlessWindow)
+	for !w.ShouldClose() {
+		// Update frame start and frame delta
+		now := time.Now()
+		a.frameDelta = now.Sub(a.frameStart)
+		a.frameStart = now
+		// Call user's update function
+		update(a.renderer, a.frameDelta)
+	}
+	a.Dispatch(OnExit, nil)
+	a.Destroy()
+}
+
+// Exit requests to terminate the application.
+func (a *Application) Exit() {
+
+	a.IWindow.(*window.HeadlessWindow).SetShouldClose(true)
+}
+
+// Renderer returns the application's renderer.
+func (a *Application) Renderer() *renderer.Renderer {
+
+	return a.renderer
+}
+
+// KeyState returns the application's KeyState.
+func (a *Application) KeyState() *window.KeyState {
+
+	return a.keyState
+}
+
+// RunTime returns the elapsed duration since the call to Run().
+func (a *Application) RunTime() time.Duration {
+
+	return time.Since(a.startTime)
+}
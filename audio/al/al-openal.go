@@ -0,0 +1,11 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !noopenal
+// +build !noopenal
+
+package al
+
+// noOpenAL is true if the OpenAL library must not be loaded.
+const noOpenAL = false
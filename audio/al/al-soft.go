@@ -0,0 +1,701 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package al
+
+// The software mixer implements the subset of the OpenAL API used by the engine in Go
+// (see mix-soft.go) and plays its output through the portable oto library (see mix-oto.go).
+// It is used when the OpenAL library can't be loaded or can't open a device, or with the
+// "noopenal" build tag, which is required on Linux to build its audio output.
+// It supports the playback of static and streaming (queued) buffers, looping, offsets,
+// pitch, gains, distance attenuation with the inverse distance clamped model, sound cones
+// and stereo panning from the listener orientation. Doppler shift, capture and the
+// effects extension are not supported: their functions only record an AL_INVALID_OPERATION error.
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// Maximum number of sources of the software mixer
+const maxSources = 256
+
+// Name of the software mixer device
+const deviceName = "G3N Software Mixer"
+
+// State of the software mixer protected by its mutex
+var (
+	mutex     sync.Mutex
+	sources   = map[uint32]*source{}
+	buffers   = map[uint32]*buffer{}
+	lastName  uint32
+	lastError uint32
+	current   *Context
+	listener  = listenerState{gain: 1, at: vec3{0, 0, -1}, up: vec3{0, 1, 0}}
+)
+
+// setError records the specified error if there is no error not yet retrieved by GetError.
+// It must be called with the mutex locked.
+func setError(code uint32) {
+
+	if lastError == NoError {
+		lastError = code
+	}
+}
+
+// softUnsupported records the error of calling a function not implemented by the software mixer.
+func softUnsupported() {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	setError(InvalidOperation)
+}
+
+// genName returns a new object name. It must be called with the mutex locked.
+func genName() uint32 {
+
+	lastName++
+	return lastName
+}
+
+func softCreateContext(dev *Device, attrlist []int) (*Context, error) {
+
+	if dev == nil {
+		return nil, fmt.Errorf("%s", errCodes[InvalidValue])
+	}
+	return &Context{dev: dev}, nil
+}
+
+func softMakeContextCurrent(ctx *Context) error {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	current = ctx
+	return nil
+}
+
+func softProcessContext(ctx *Context) {
+}
+
+func softSuspendContext(ctx *Context) {
+}
+
+func softDestroyContext(ctx *Context) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if current == ctx {
+		current = nil
+	}
+}
+
+func softGetContextsDevice(ctx *Context) *Device {
+
+	return ctx.dev
+}
+
+// softOpenDevice opens the audio output and starts the software mixer.
+// Only one device can be open at a time.
+func softOpenDevice(name string) (*Device, error) {
+
+	m, err := newMixer()
+	if err != nil {
+		return nil, err
+	}
+	return &Device{mixer: m}, nil
+}
+
+func softCloseDevice(dev *Device) error {
+
+	return dev.mixer.close()
+}
+
+func softCtxGetError(dev *Device) error {
+
+	return nil
+}
+
+func softCtxIsExtensionPresent(dev *Device, extname string) bool {
+
+	return false
+}
+
+func softCtxGetString(dev *Device, param uint) string {
+
+	switch param {
+	case DefaultDeviceSpecifier, DeviceSpecifier, DefaultAllDevicesSpecifier, AllDevicesSpecifier:
+		return deviceName
+	}
+	return ""
+}
+
+func softEnable(capability uint) {
+}
+
+func softDisable(capability uint) {
+}
+
+func softIsEnabled(capability uint) bool {
+
+	return false
+}
+
+func softGetString(param uint32) string {
+
+	switch param {
+	case Vendor:
+		return "G3N"
+	case Version:
+		return "1.1 (software mixer)"
+	case Renderer:
+		return deviceName
+	}
+	return ""
+}
+
+func softGetError() error {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	code := lastError
+	lastError = NoError
+	if code == NoError {
+		return nil
+	}
+	return fmt.Errorf("%s", errCodes[uint(code)])
+}
+
+func softIsExtensionPresent(extName string) bool {
+
+	return false
+}
+
+func softListenerf(param uint32, value float32) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if param != Gain {
+		setError(InvalidEnum)
+		return
+	}
+	listener.gain = value
+}
+
+func softListener3f(param uint32, value1, value2, value3 float32) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	switch param {
+	case Position:
+		listener.position = vec3{value1, value2, value3}
+	case Velocity:
+		listener.velocity = vec3{value1, value2, value3}
+	default:
+		setError(InvalidEnum)
+	}
+}
+
+func softListenerfv(param uint32, values []float32) {
+
+	if param != Orientation {
+		softListener3f(param, values[0], values[1], values[2])
+		return
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	listener.at = vec3{values[0], values[1], values[2]}
+	listener.up = vec3{values[3], values[4], values[5]}
+}
+
+func softGetListenerf(param uint32) float32 {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if param != Gain {
+		setError(InvalidEnum)
+		return 0
+	}
+	return listener.gain
+}
+
+func softGetListener3f(param uint32) (float32, float32, float32) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	switch param {
+	case Position:
+		return listener.position[0], listener.position[1], listener.position[2]
+	case Velocity:
+		return listener.velocity[0], listener.velocity[1], listener.velocity[2]
+	}
+	setError(InvalidEnum)
+	return 0, 0, 0
+}
+
+func softGetListenerfv(param uint32, values []float32) {
+
+	if param != Orientation {
+		values[0], values[1], values[2] = softGetListener3f(param)
+		return
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	copy(values, listener.at[:])
+	copy(values[3:], listener.up[:])
+}
+
+func softGenSource() uint32 {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(sources) >= maxSources {
+		setError(OutOfMemory)
+		return 0
+	}
+	name := genName()
+	sources[name] = newSource()
+	stats.Sources++
+	return name
+}
+
+func softGenSources(sources []uint32) {
+
+	for i := range sources {
+		sources[i] = softGenSource()
+	}
+}
+
+func softDeleteSource(source uint32) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if sources[source] == nil {
+		setError(InvalidName)
+		return
+	}
+	delete(sources, source)
+	stats.Sources--
+}
+
+func softDeleteSources(sources []uint32) {
+
+	for _, source := range sources {
+		softDeleteSource(source)
+	}
+}
+
+func softIsSource(source uint32) bool {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	return sources[source] != nil
+}
+
+// getSource returns the source with the specified name setting
+// an error if it does not exist. It must be called with the mutex locked.
+func getSource(name uint32) *source {
+
+	s := sources[name]
+	if s == nil {
+		setError(InvalidName)
+	}
+	return s
+}
+
+func softSourcef(source uint32, param uint32, value float32) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	s := getSource(source)
+	if s == nil {
+		return
+	}
+	switch param {
+	case Gain:
+		s.gain = value
+	case MinGain:
+		s.minGain = value
+	case MaxGain:
+		s.maxGain = value
+	case Pitch:
+		s.pitch = value
+	case ConeInnerAngle:
+		s.coneInner = value
+	case ConeOuterAngle:
+		s.coneOuter = value
+	case ConeOuterGain:
+		s.coneOuterGain = value
+	case ReferenceDistance:
+		s.refDistance = value
+	case RolloffFactor:
+		s.rolloff = value
+	case MaxDistance:
+		s.maxDistance = value
+	case SecOffset:
+		s.setOffset(float64(value), true)
+	case SampleOffset:
+		s.setOffset(float64(value), false)
+	default:
+		setError(InvalidEnum)
+	}
+}
+
+func softSource3f(source uint32, param uint32, value1, value2, value3 float32) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	s := getSource(source)
+	if s == nil {
+		return
+	}
+	switch param {
+	case Position:
+		s.position = vec3{value1, value2, value3}
+	case Direction:
+		s.direction = vec3{value1, value2, value3}
+	case Velocity:
+		s.velocity = vec3{value1, value2, value3}
+	default:
+		setError(InvalidEnum)
+	}
+}
+
+func softSourcefv(source uint32, param uint32, values []float32) {
+
+	switch param {
+	case Position, Direction, Velocity:
+		softSource3f(source, param, values[0], values[1], values[2])
+	default:
+		softSourcef(source, param, values[0])
+	}
+}
+
+func softSourcei(source uint32, param uint32, value int32) {
+
+	switch param {
+	case Looping, SourceRelative, Buffer:
+	default:
+		softSourcef(source, param, float32(value))
+		return
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	s := getSource(source)
+	if s == nil {
+		return
+	}
+	switch param {
+	case Looping:
+		s.looping = value != False
+	case SourceRelative:
+		s.relative = value != False
+	case Buffer:
+		if value != 0 && buffers[uint32(value)] == nil {
+			setError(InvalidValue)
+			return
+		}
+		s.setBuffer(uint32(value))
+	}
+}
+
+func softSource3i(source uint32, param uint32, value1, value2, value3 int32) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if getSource(source) == nil {
+		return
+	}
+	setError(InvalidEnum)
+}
+
+func softGetSourcef(source uint32, param uint32) float32 {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	s := getSource(source)
+	if s == nil {
+		return 0
+	}
+	switch param {
+	case Gain:
+		return s.gain
+	case MinGain:
+		return s.minGain
+	case MaxGain:
+		return s.maxGain
+	case Pitch:
+		return s.pitch
+	case ConeInnerAngle:
+		return s.coneInner
+	case ConeOuterAngle:
+		return s.coneOuter
+	case ConeOuterGain:
+		return s.coneOuterGain
+	case ReferenceDistance:
+		return s.refDistance
+	case RolloffFactor:
+		return s.rolloff
+	case MaxDistance:
+		return s.maxDistance
+	case SecOffset:
+		return float32(s.offset(true))
+	case SampleOffset:
+		return float32(s.offset(false))
+	}
+	setError(InvalidEnum)
+	return 0
+}
+
+func softGetSource3f(source uint32, param uint32) (float32, float32, float32) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	s := getSource(source)
+	if s == nil {
+		return 0, 0, 0
+	}
+	var v vec3
+	switch param {
+	case Position:
+		v = s.position
+	case Direction:
+		v = s.direction
+	case Velocity:
+		v = s.velocity
+	default:
+		setError(InvalidEnum)
+	}
+	return v[0], v[1], v[2]
+}
+
+func softGetSourcefv(source uint32, param uint32, values []float32) {
+
+	switch param {
+	case Position, Direction, Velocity:
+		values[0], values[1], values[2] = softGetSource3f(source, param)
+	default:
+		values[0] = softGetSourcef(source, param)
+	}
+}
+
+func softGetSourcei(source uint32, param uint32) int32 {
+
+	switch param {
+	case SourceState, BuffersQueued, BuffersProcessed, Looping, SourceRelative, Buffer, SourceType, SampleOffset:
+	default:
+		return int32(softGetSourcef(source, param))
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	s := getSource(source)
+	if s == nil {
+		return 0
+	}
+	switch param {
+	case SourceState:
+		return s.state
+	case BuffersQueued:
+		return int32(len(s.queue))
+	case BuffersProcessed:
+		return int32(s.processed())
+	case Looping:
+		if s.looping {
+			return True
+		}
+		return False
+	case SourceRelative:
+		if s.relative {
+			return True
+		}
+		return False
+	case Buffer:
+		if s.static && len(s.queue) > 0 {
+			return int32(s.queue[0])
+		}
+		return 0
+	case SourceType:
+		if len(s.queue) == 0 {
+			return Undetermined
+		}
+		if s.static {
+			return Static
+		}
+		return Streaming
+	}
+	return int32(s.offset(false))
+}
+
+func softSourcePlayv(sources []uint32) {
+
+	for _, source := range sources {
+		softSourcePlay(source)
+	}
+}
+
+func softSourceStopv(sources []uint32) {
+
+	for _, source := range sources {
+		softSourceStop(source)
+	}
+}
+
+func softSourceRewindv(sources []uint32) {
+
+	for _, source := range sources {
+		softSourceRewind(source)
+	}
+}
+
+func softSourcePausev(sources []uint32) {
+
+	for _, source := range sources {
+		softSourcePause(source)
+	}
+}
+
+func softSourcePlay(source uint32) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if s := getSource(source); s != nil {
+		s.play()
+	}
+}
+
+func softSourceStop(source uint32) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if s := getSource(source); s != nil {
+		s.stop()
+	}
+}
+
+func softSourceRewind(source uint32) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if s := getSource(source); s != nil {
+		s.rewind()
+	}
+}
+
+func softSourcePause(source uint32) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if s := getSource(source); s != nil && s.state == Playing {
+		s.state = Paused
+	}
+}
+
+func softSourceQueueBuffers(source uint32, buffers ...uint32) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	s := getSource(source)
+	if s == nil {
+		return
+	}
+	if s.static {
+		setError(InvalidOperation)
+		return
+	}
+	s.queue = append(s.queue, buffers...)
+}
+
+// softSourceUnqueueBuffers removes the specified number of processed buffers from the queue
+// of the specified source and stores their names in buffers if it is not nil.
+func softSourceUnqueueBuffers(source uint32, n uint32, buffers []uint32) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	s := getSource(source)
+	if s == nil {
+		return
+	}
+	if int(n) > s.processed() {
+		setError(InvalidValue)
+		return
+	}
+	copy(buffers, s.queue[:n])
+	s.unqueue(int(n))
+}
+
+func softGenBuffers(n uint32) []uint32 {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	names := make([]uint32, n)
+	for i := range names {
+		names[i] = genName()
+		buffers[names[i]] = new(buffer)
+	}
+	stats.Buffers += int(n)
+	return names
+}
+
+func softDeleteBuffers(names []uint32) {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	for _, name := range names {
+		if buffers[name] == nil {
+			setError(InvalidName)
+			continue
+		}
+		delete(buffers, name)
+		stats.Buffers--
+	}
+}
+
+func softIsBuffer(buffer uint32) bool {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	return buffers[buffer] != nil
+}
+
+// softBufferData converts the specified PCM data to the samples of the specified buffer.
+func softBufferData(buffer uint32, format uint32, data unsafe.Pointer, size uint32, freq uint32) {
+
+	var pcm []byte
+	if size > 0 {
+		pcm = (*[1 << 30]byte)(data)[:size:size]
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	b := buffers[buffer]
+	if b == nil {
+		setError(InvalidName)
+		return
+	}
+	if !b.setData(format, pcm, int(freq)) {
+		setError(InvalidEnum)
+	}
+}
+
+func softGetBufferi(buffer uint32, param uint32) int32 {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	b := buffers[buffer]
+	if b == nil {
+		setError(InvalidName)
+		return 0
+	}
+	switch param {
+	case Frequency:
+		return int32(b.rate)
+	case Bits:
+		return int32(b.bits)
+	case Channels:
+		return int32(b.channels)
+	case Size:
+		return int32(b.size)
+	}
+	setError(InvalidEnum)
+	return 0
+}
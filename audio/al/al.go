@@ -4,26 +4,24 @@
 
 // Package al implements the Go bindings of a subset of the functions of the OpenAL C library.
 // The OpenAL documentation can be accessed at https://openal.org/documentation/
+//
+// The OpenAL library is loaded at runtime, so it is not needed to build the engine. When it is not
+// found or it can't open an audio device, or when building with the "noopenal" build tag, the functions
+// are implemented by a software mixer written in Go (see al-soft.go) which plays the audio through
+// the oto library. On Linux oto requires cgo and the ALSA development files, so the software mixer
+// can only play audio when building with the "noopenal" build tag.
 package al
 
-// #cgo darwin,amd64  CFLAGS:  -DGO_DARWIN  -I/usr/local/opt/openal-soft/include/AL -I/usr/include/AL
-// #cgo darwin,arm64  CFLAGS:  -DGO_DARWIN  -I/opt/homebrew/opt/openal-soft/include/AL
-// #cgo freebsd       CFLAGS:  -DGO_FREEBSD -I/usr/local/include/AL
-// #cgo linux         CFLAGS:  -DGO_LINUX   -I/usr/include/AL
-// #cgo windows       CFLAGS:  -DGO_WINDOWS -I${SRCDIR}/../windows/openal-soft-1.18.2/include/AL
-// #cgo darwin,amd64  LDFLAGS: -L/usr/local/opt/openal-soft/lib -lopenal
-// #cgo darwin,arm64  LDFLAGS: -L/opt/homebrew/opt/openal-soft/lib -lopenal
-// #cgo freebsd       LDFLAGS: -L/usr/local/lib -lopenal
-// #cgo linux         LDFLAGS: -lopenal
-// #cgo windows       LDFLAGS: -L${SRCDIR}/../windows/bin -lOpenAL32
+// #cgo       CFLAGS:  -I${SRCDIR}/../windows/openal-soft-1.18.2/include/AL
+// #cgo linux LDFLAGS: -ldl
 // #include <stdlib.h>
-// #include "al.h"
-// #include "alc.h"
+// #include "alapi.h"
 // #include "efx.h"
 import "C"
 
 import (
 	"fmt"
+	"sync"
 	"unsafe"
 )
 
@@ -259,11 +257,13 @@ var errCodes = map[uint]string{
 }
 
 type Device struct {
-	cdev *C.ALCdevice
+	cdev  *C.ALCdevice
+	mixer *mixer // Output of the software mixer
 }
 
 type Context struct {
 	cctx *C.ALCcontext
+	dev  *Device // Device of the software mixer context
 }
 
 // Statistics
@@ -274,6 +274,34 @@ type Stats struct {
 	Callocs  int   // Current number of C allocations
 }
 
+// Backend selected by useMixer
+var (
+	backendOnce sync.Once
+	mixerUsed   bool
+)
+
+// useMixer returns true if the functions are implemented by the software mixer because
+// the OpenAL library could not be loaded or could not open the default device.
+// The backend is selected at the first call and doesn't change afterwards.
+func useMixer() bool {
+
+	backendOnce.Do(func() {
+		mixerUsed = noOpenAL || C.alapiLoad() != 0 || !canOpenDevice()
+	})
+	return mixerUsed
+}
+
+// canOpenDevice returns true if OpenAL can open the default device.
+func canOpenDevice() bool {
+
+	cdev := C.alcOpenDevice(nil)
+	if cdev == nil {
+		return false
+	}
+	C.alcCloseDevice(cdev)
+	return true
+}
+
 // Maps C pointer to device to Go pointer to Device
 var mapDevice = map[*C.ALCdevice]*Device{}
 
@@ -296,19 +324,25 @@ func checkCtxError(dev *Device) {
 
 func CreateContext(dev *Device, attrlist []int) (*Context, error) {
 
+	if useMixer() {
+		return softCreateContext(dev, attrlist)
+	}
 	var plist unsafe.Pointer
 	if len(attrlist) != 0 {
 		plist = (unsafe.Pointer)(&attrlist[0])
 	}
 	ctx := C.alcCreateContext(dev.cdev, (*C.ALCint)(plist))
 	if ctx != nil {
-		return &Context{ctx}, nil
+		return &Context{cctx: ctx}, nil
 	}
 	return nil, fmt.Errorf("%s", errCodes[uint(C.alcGetError(dev.cdev))])
 }
 
 func MakeContextCurrent(ctx *Context) error {
 
+	if useMixer() {
+		return softMakeContextCurrent(ctx)
+	}
 	cres := C.alcMakeContextCurrent(ctx.cctx)
 	if cres == C.ALC_TRUE {
 		return nil
@@ -318,21 +352,36 @@ func MakeContextCurrent(ctx *Context) error {
 
 func ProcessContext(ctx *Context) {
 
+	if useMixer() {
+		softProcessContext(ctx)
+		return
+	}
 	C.alcProcessContext(ctx.cctx)
 }
 
 func SuspendContext(ctx *Context) {
 
+	if useMixer() {
+		softSuspendContext(ctx)
+		return
+	}
 	C.alcSuspendContext(ctx.cctx)
 }
 
 func DestroyContext(ctx *Context) {
 
+	if useMixer() {
+		softDestroyContext(ctx)
+		return
+	}
 	C.alcDestroyContext(ctx.cctx)
 }
 
 func GetContextsDevice(ctx *Context) *Device {
 
+	if useMixer() {
+		return softGetContextsDevice(ctx)
+	}
 	cdev := C.alcGetContextsDevice(ctx.cctx)
 	if cdev == nil {
 		return nil
@@ -340,13 +389,18 @@ func GetContextsDevice(ctx *Context) *Device {
 	return mapDevice[cdev]
 }
 
+// OpenDevice opens the specified audio device or the default device if the name is empty.
+// If OpenAL can't open the default device the software mixer is used instead.
 func OpenDevice(name string) (*Device, error) {
 
+	if useMixer() {
+		return softOpenDevice(name)
+	}
 	cstr := (*C.ALCchar)(C.CString(name))
 	defer C.free(unsafe.Pointer(cstr))
 	cdev := C.alcOpenDevice(cstr)
 	if cdev != nil {
-		dev := &Device{cdev}
+		dev := &Device{cdev: cdev}
 		mapDevice[cdev] = dev
 		return dev, nil
 	}
@@ -355,6 +409,9 @@ func OpenDevice(name string) (*Device, error) {
 
 func CloseDevice(dev *Device) error {
 
+	if useMixer() {
+		return softCloseDevice(dev)
+	}
 	cres := C.alcCloseDevice(dev.cdev)
 	if cres == C.ALC_TRUE {
 		delete(mapDevice, dev.cdev)
@@ -365,6 +422,9 @@ func CloseDevice(dev *Device) error {
 
 func CtxGetError(dev *Device) error {
 
+	if useMixer() {
+		return softCtxGetError(dev)
+	}
 	cerr := C.alcGetError(dev.cdev)
 	if cerr == C.AL_NONE {
 		return nil
@@ -374,6 +434,9 @@ func CtxGetError(dev *Device) error {
 
 func CtxIsExtensionPresent(dev *Device, extname string) bool {
 
+	if useMixer() {
+		return softCtxIsExtensionPresent(dev, extname)
+	}
 	cname := (*C.ALCchar)(C.CString(extname))
 	defer C.free(unsafe.Pointer(cname))
 	cres := C.alcIsExtensionPresent(dev.cdev, cname)
@@ -382,6 +445,10 @@ func CtxIsExtensionPresent(dev *Device, extname string) bool {
 
 func CtxGetEnumValue(dev *Device, enumName string) uint32 {
 
+	if useMixer() {
+		softUnsupported()
+		return 0
+	}
 	cname := (*C.ALCchar)(C.CString(enumName))
 	defer C.free(unsafe.Pointer(cname))
 	cres := C.alcGetEnumValue(dev.cdev, cname)
@@ -390,6 +457,9 @@ func CtxGetEnumValue(dev *Device, enumName string) uint32 {
 
 func CtxGetString(dev *Device, param uint) string {
 
+	if useMixer() {
+		return softCtxGetString(dev, param)
+	}
 	var cdev *C.ALCdevice = nil
 	if dev != nil {
 		cdev = dev.cdev
@@ -400,16 +470,23 @@ func CtxGetString(dev *Device, param uint) string {
 
 func CtxGetIntegerv(dev *Device, param uint32, values []int32) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	C.alcGetIntegerv(dev.cdev, C.ALCenum(param), C.ALCsizei(len(values)), (*C.ALCint)(unsafe.Pointer(&values[0])))
 }
 
 func CaptureOpenDevice(devname string, frequency uint32, format uint32, buffersize uint32) (*Device, error) {
 
+	if useMixer() {
+		return nil, fmt.Errorf("capture is not supported by the software mixer")
+	}
 	cstr := (*C.ALCchar)(C.CString(devname))
 	defer C.free(unsafe.Pointer(cstr))
 	cdev := C.alcCaptureOpenDevice(cstr, C.ALCuint(frequency), C.ALCenum(format), C.ALCsizei(buffersize))
 	if cdev != nil {
-		dev := &Device{cdev}
+		dev := &Device{cdev: cdev}
 		mapDevice[cdev] = dev
 		return dev, nil
 	}
@@ -418,6 +495,10 @@ func CaptureOpenDevice(devname string, frequency uint32, format uint32, buffersi
 
 func CaptureCloseDevice(dev *Device) error {
 
+	if useMixer() {
+		softUnsupported()
+		return nil
+	}
 	cres := C.alcCaptureCloseDevice(dev.cdev)
 	if cres == C.AL_TRUE {
 		return nil
@@ -427,46 +508,76 @@ func CaptureCloseDevice(dev *Device) error {
 
 func CaptureStart(dev *Device) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	C.alcCaptureStart(dev.cdev)
 	checkCtxError(dev)
 }
 
 func CaptureStop(dev *Device) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	C.alcCaptureStop(dev.cdev)
 	checkCtxError(dev)
 }
 
 func CaptureSamples(dev *Device, buffer []byte, nsamples uint) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	C.alcCaptureSamples(dev.cdev, unsafe.Pointer(&buffer[0]), C.ALCsizei(nsamples))
 	checkCtxError(dev)
 }
 
 func Enable(capability uint) {
 
+	if useMixer() {
+		softEnable(capability)
+		return
+	}
 	C.alEnable(C.ALenum(capability))
 }
 
 func Disable(capability uint) {
 
+	if useMixer() {
+		softDisable(capability)
+		return
+	}
 	C.alDisable(C.ALenum(capability))
 }
 
 func IsEnabled(capability uint) bool {
 
+	if useMixer() {
+		return softIsEnabled(capability)
+	}
 	cres := C.alIsEnabled(C.ALenum(capability))
 	return cres == C.AL_TRUE
 }
 
 func GetString(param uint32) string {
 
+	if useMixer() {
+		return softGetString(param)
+	}
 	cstr := C.alGetString(C.ALenum(param))
 	return C.GoString((*C.char)(cstr))
 }
 
 func GetBooleanv(param uint32, values []bool) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	cvals := make([]C.ALboolean, len(values))
 	C.alGetBooleanv(C.ALenum(param), &cvals[0])
 	for i := 0; i < len(cvals); i++ {
@@ -480,45 +591,76 @@ func GetBooleanv(param uint32, values []bool) {
 
 func GetIntegerv(param uint32, values []int32) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	C.alGetIntegerv(C.ALenum(param), (*C.ALint)(unsafe.Pointer(&values[0])))
 }
 
 func GetFloatv(param uint32, values []float32) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	C.alGetFloatv(C.ALenum(param), (*C.ALfloat)(unsafe.Pointer(&values[0])))
 }
 
 func GetDoublev(param uint32, values []float64) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	C.alGetDoublev(C.ALenum(param), (*C.ALdouble)(unsafe.Pointer(&values[0])))
 }
 
 func GetBoolean(param uint32) bool {
 
+	if useMixer() {
+		softUnsupported()
+		return false
+	}
 	cres := C.alGetBoolean(C.ALenum(param))
 	return cres == C.AL_TRUE
 }
 
 func GetInteger(param uint32) int32 {
 
+	if useMixer() {
+		softUnsupported()
+		return 0
+	}
 	cres := C.alGetInteger(C.ALenum(param))
 	return int32(cres)
 }
 
 func GetFloat(param uint32) float32 {
 
+	if useMixer() {
+		softUnsupported()
+		return 0
+	}
 	cres := C.alGetFloat(C.ALenum(param))
 	return float32(cres)
 }
 
 func GetDouble(param uint32) float64 {
 
+	if useMixer() {
+		softUnsupported()
+		return 0
+	}
 	cres := C.alGetDouble(C.ALenum(param))
 	return float64(cres)
 }
 
 func GetError() error {
 
+	if useMixer() {
+		return softGetError()
+	}
 	cerr := C.alGetError()
 	if cerr == C.AL_NONE {
 		return nil
@@ -528,6 +670,9 @@ func GetError() error {
 
 func IsExtensionPresent(extName string) bool {
 
+	if useMixer() {
+		return softIsExtensionPresent(extName)
+	}
 	cstr := (*C.ALchar)(C.CString(extName))
 	defer C.free(unsafe.Pointer(cstr))
 	cres := C.alIsExtensionPresent(cstr)
@@ -536,6 +681,10 @@ func IsExtensionPresent(extName string) bool {
 
 func GetEnumValue(enam string) uint32 {
 
+	if useMixer() {
+		softUnsupported()
+		return 0
+	}
 	cenam := (*C.ALchar)(C.CString(enam))
 	defer C.free(unsafe.Pointer(cenam))
 	cres := C.alGetEnumValue(cenam)
@@ -544,36 +693,63 @@ func GetEnumValue(enam string) uint32 {
 
 func Listenerf(param uint32, value float32) {
 
+	if useMixer() {
+		softListenerf(param, value)
+		return
+	}
 	C.alListenerf(C.ALenum(param), C.ALfloat(value))
 }
 
 func Listener3f(param uint32, value1, value2, value3 float32) {
 
+	if useMixer() {
+		softListener3f(param, value1, value2, value3)
+		return
+	}
 	C.alListener3f(C.ALenum(param), C.ALfloat(value1), C.ALfloat(value2), C.ALfloat(value3))
 }
 
 func Listenerfv(param uint32, values []float32) {
 
+	if useMixer() {
+		softListenerfv(param, values)
+		return
+	}
 	C.alListenerfv(C.ALenum(param), (*C.ALfloat)(unsafe.Pointer(&values[0])))
 }
 
 func Listeneri(param uint32, value int32) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	C.alListeneri(C.ALenum(param), C.ALint(value))
 }
 
 func Listener3i(param uint32, value1, value2, value3 int32) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	C.alListener3i(C.ALenum(param), C.ALint(value1), C.ALint(value2), C.ALint(value3))
 }
 
 func Listeneriv(param uint32, values []int32) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	C.alListeneriv(C.ALenum(param), (*C.ALint)(unsafe.Pointer(&values[0])))
 }
 
 func GetListenerf(param uint32) float32 {
 
+	if useMixer() {
+		return softGetListenerf(param)
+	}
 	var cval C.ALfloat
 	C.alGetListenerf(C.ALenum(param), &cval)
 	return float32(cval)
@@ -581,6 +757,9 @@ func GetListenerf(param uint32) float32 {
 
 func GetListener3f(param uint32) (float32, float32, float32) {
 
+	if useMixer() {
+		return softGetListener3f(param)
+	}
 	var cval1 C.ALfloat
 	var cval2 C.ALfloat
 	var cval3 C.ALfloat
@@ -590,11 +769,19 @@ func GetListener3f(param uint32) (float32, float32, float32) {
 
 func GetListenerfv(param uint32, values []float32) {
 
+	if useMixer() {
+		softGetListenerfv(param, values)
+		return
+	}
 	C.alGetListenerfv(C.ALenum(param), (*C.ALfloat)(unsafe.Pointer(&values[0])))
 }
 
 func GetListeneri(param uint32) int32 {
 
+	if useMixer() {
+		softUnsupported()
+		return 0
+	}
 	var cval C.ALint
 	C.alGetListeneri(C.ALenum(param), &cval)
 	return int32(cval)
@@ -602,6 +789,10 @@ func GetListeneri(param uint32) int32 {
 
 func GetListener3i(param uint32) (int32, int32, int32) {
 
+	if useMixer() {
+		softUnsupported()
+		return 0, 0, 0
+	}
 	var cval1 C.ALint
 	var cval2 C.ALint
 	var cval3 C.ALint
@@ -611,6 +802,10 @@ func GetListener3i(param uint32) (int32, int32, int32) {
 
 func GetListeneriv(param uint32, values []int32) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	if len(values) < 3 {
 		panic("Slice length less than minimum")
 	}
@@ -619,6 +814,9 @@ func GetListeneriv(param uint32, values []int32) {
 
 func GenSource() uint32 {
 
+	if useMixer() {
+		return softGenSource()
+	}
 	var csource C.ALuint
 	C.alGenSources(1, &csource)
 	stats.Sources++
@@ -627,40 +825,67 @@ func GenSource() uint32 {
 
 func GenSources(sources []uint32) {
 
+	if useMixer() {
+		softGenSources(sources)
+		return
+	}
 	C.alGenSources(C.ALsizei(len(sources)), (*C.ALuint)(unsafe.Pointer(&sources[0])))
 	stats.Sources += len(sources)
 }
 
 func DeleteSource(source uint32) {
 
+	if useMixer() {
+		softDeleteSource(source)
+		return
+	}
 	C.alDeleteSources(1, (*C.ALuint)(unsafe.Pointer(&source)))
 	stats.Sources--
 }
 
 func DeleteSources(sources []uint32) {
 
+	if useMixer() {
+		softDeleteSources(sources)
+		return
+	}
 	C.alDeleteSources(C.ALsizei(len(sources)), (*C.ALuint)(unsafe.Pointer(&sources[0])))
 	stats.Sources -= len(sources)
 }
 
 func IsSource(source uint32) bool {
 
+	if useMixer() {
+		return softIsSource(source)
+	}
 	cres := C.alIsSource(C.ALuint(source))
 	return cres == C.AL_TRUE
 }
 
 func Sourcef(source uint32, param uint32, value float32) {
 
+	if useMixer() {
+		softSourcef(source, param, value)
+		return
+	}
 	C.alSourcef(C.ALuint(source), C.ALenum(param), C.ALfloat(value))
 }
 
 func Source3f(source uint32, param uint32, value1, value2, value3 float32) {
 
+	if useMixer() {
+		softSource3f(source, param, value1, value2, value3)
+		return
+	}
 	C.alSource3f(C.ALuint(source), C.ALenum(param), C.ALfloat(value1), C.ALfloat(value2), C.ALfloat(value3))
 }
 
 func Sourcefv(source uint32, param uint32, values []float32) {
 
+	if useMixer() {
+		softSourcefv(source, param, values)
+		return
+	}
 	if len(values) < 3 {
 		panic("Slice length less than minimum")
 	}
@@ -669,16 +894,28 @@ func Sourcefv(source uint32, param uint32, values []float32) {
 
 func Sourcei(source uint32, param uint32, value int32) {
 
+	if useMixer() {
+		softSourcei(source, param, value)
+		return
+	}
 	C.alSourcei(C.ALuint(source), C.ALenum(param), C.ALint(value))
 }
 
 func Source3i(source uint32, param uint32, value1, value2, value3 int32) {
 
+	if useMixer() {
+		softSource3i(source, param, value1, value2, value3)
+		return
+	}
 	C.alSource3i(C.ALuint(source), C.ALenum(param), C.ALint(value1), C.ALint(value2), C.ALint(value3))
 }
 
 func Sourceiv(source uint32, param uint32, values []int32) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	if len(values) < 3 {
 		panic("Slice length less than minimum")
 	}
@@ -687,6 +924,9 @@ func Sourceiv(source uint32, param uint32, values []int32) {
 
 func GetSourcef(source uint32, param uint32) float32 {
 
+	if useMixer() {
+		return softGetSourcef(source, param)
+	}
 	var value C.ALfloat
 	C.alGetSourcef(C.ALuint(source), C.ALenum(param), &value)
 	return float32(value)
@@ -694,6 +934,9 @@ func GetSourcef(source uint32, param uint32) float32 {
 
 func GetSource3f(source uint32, param uint32) (float32, float32, float32) {
 
+	if useMixer() {
+		return softGetSource3f(source, param)
+	}
 	var cval1 C.ALfloat
 	var cval2 C.ALfloat
 	var cval3 C.ALfloat
@@ -703,6 +946,10 @@ func GetSource3f(source uint32, param uint32) (float32, float32, float32) {
 
 func GetSourcefv(source uint32, param uint32, values []float32) {
 
+	if useMixer() {
+		softGetSourcefv(source, param, values)
+		return
+	}
 	if len(values) < 3 {
 		panic("Slice length less than minimum")
 	}
@@ -711,6 +958,9 @@ func GetSourcefv(source uint32, param uint32, values []float32) {
 
 func GetSourcei(source uint32, param uint32) int32 {
 
+	if useMixer() {
+		return softGetSourcei(source, param)
+	}
 	var value C.ALint
 	C.alGetSourcei(C.ALuint(source), C.ALenum(param), &value)
 	return int32(value)
@@ -718,6 +968,10 @@ func GetSourcei(source uint32, param uint32) int32 {
 
 func GetSource3i(source uint32, param uint32) (int32, int32, int32) {
 
+	if useMixer() {
+		softUnsupported()
+		return 0, 0, 0
+	}
 	var cval1 C.ALint
 	var cval2 C.ALint
 	var cval3 C.ALint
@@ -727,6 +981,10 @@ func GetSource3i(source uint32, param uint32) (int32, int32, int32) {
 
 func GetSourceiv(source uint32, param uint32, values []int32) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	if len(values) < 3 {
 		panic("Slice length less than minimum")
 	}
@@ -735,57 +993,100 @@ func GetSourceiv(source uint32, param uint32, values []int32) {
 
 func SourcePlayv(sources []uint32) {
 
+	if useMixer() {
+		softSourcePlayv(sources)
+		return
+	}
 	C.alSourcePlayv(C.ALsizei(len(sources)), (*C.ALuint)(unsafe.Pointer(&sources[0])))
 }
 
 func SourceStopv(sources []uint32) {
 
+	if useMixer() {
+		softSourceStopv(sources)
+		return
+	}
 	C.alSourceStopv(C.ALsizei(len(sources)), (*C.ALuint)(unsafe.Pointer(&sources[0])))
 }
 
 func SourceRewindv(sources []uint32) {
 
+	if useMixer() {
+		softSourceRewindv(sources)
+		return
+	}
 	C.alSourceRewindv(C.ALsizei(len(sources)), (*C.ALuint)(unsafe.Pointer(&sources[0])))
 }
 
 func SourcePausev(sources []uint32) {
 
+	if useMixer() {
+		softSourcePausev(sources)
+		return
+	}
 	C.alSourcePausev(C.ALsizei(len(sources)), (*C.ALuint)(unsafe.Pointer(&sources[0])))
 }
 
 func SourcePlay(source uint32) {
 
+	if useMixer() {
+		softSourcePlay(source)
+		return
+	}
 	C.alSourcePlay(C.ALuint(source))
 }
 
 func SourceStop(source uint32) {
 
+	if useMixer() {
+		softSourceStop(source)
+		return
+	}
 	C.alSourceStop(C.ALuint(source))
 }
 
 func SourceRewind(source uint32) {
 
+	if useMixer() {
+		softSourceRewind(source)
+		return
+	}
 	C.alSourceRewind(C.ALuint(source))
 }
 
 func SourcePause(source uint32) {
 
+	if useMixer() {
+		softSourcePause(source)
+		return
+	}
 	C.alSourcePause(C.ALuint(source))
 }
 
 func SourceQueueBuffers(source uint32, buffers ...uint32) {
 
+	if useMixer() {
+		softSourceQueueBuffers(source, buffers...)
+		return
+	}
 	C.alSourceQueueBuffers(C.ALuint(source), C.ALsizei(len(buffers)), (*C.ALuint)(unsafe.Pointer(&buffers[0])))
 }
 
 func SourceUnqueueBuffers(source uint32, n uint32, buffers []uint32) {
 
+	if useMixer() {
+		softSourceUnqueueBuffers(source, n, buffers)
+		return
+	}
 	removed := make([]C.ALuint, n)
 	C.alSourceUnqueueBuffers(C.ALuint(source), C.ALsizei(n), &removed[0])
 }
 
 func GenBuffers(n uint32) []uint32 {
 
+	if useMixer() {
+		return softGenBuffers(n)
+	}
 	buffers := make([]uint32, n)
 	C.alGenBuffers(C.ALsizei(len(buffers)), (*C.ALuint)(unsafe.Pointer(&buffers[0])))
 	return buffers
@@ -793,52 +1094,91 @@ func GenBuffers(n uint32) []uint32 {
 
 func DeleteBuffers(buffers []uint32) {
 
+	if useMixer() {
+		softDeleteBuffers(buffers)
+		return
+	}
 	C.alDeleteBuffers(C.ALsizei(len(buffers)), (*C.ALuint)(unsafe.Pointer(&buffers[0])))
 }
 
 func IsBuffer(buffer uint32) bool {
 
+	if useMixer() {
+		return softIsBuffer(buffer)
+	}
 	cres := C.alIsBuffer(C.ALuint(buffer))
 	return cres == C.AL_TRUE
 }
 
 func BufferData(buffer uint32, format uint32, data unsafe.Pointer, size uint32, freq uint32) {
 
+	if useMixer() {
+		softBufferData(buffer, format, data, size, freq)
+		return
+	}
 	C.alBufferData(C.ALuint(buffer), C.ALenum(format), data, C.ALsizei(size), C.ALsizei(freq))
 }
 
 func Bufferf(buffer uint32, param uint32, value float32) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	C.alBufferf(C.ALuint(buffer), C.ALenum(param), C.ALfloat(value))
 }
 
 func Buffer3f(buffer uint32, param uint32, value1, value2, value3 float32) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	C.alBuffer3f(C.ALuint(buffer), C.ALenum(param), C.ALfloat(value1), C.ALfloat(value2), C.ALfloat(value3))
 }
 
 func Bufferfv(buffer uint32, param uint32, values []float32) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	C.alBufferfv(C.ALuint(buffer), C.ALenum(param), (*C.ALfloat)(unsafe.Pointer(&values[0])))
 }
 
 func Bufferi(buffer uint32, param uint32, value int32) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	C.alBufferi(C.ALuint(buffer), C.ALenum(param), C.ALint(value))
 }
 
 func Buffer3i(buffer uint32, param uint32, value1, value2, value3 int32) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	C.alBuffer3i(C.ALuint(buffer), C.ALenum(param), C.ALint(value1), C.ALint(value2), C.ALint(value3))
 }
 
 func Bufferiv(buffer uint32, param uint32, values []int32) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	C.alBufferiv(C.ALuint(buffer), C.ALenum(param), (*C.ALint)(unsafe.Pointer(&values[0])))
 }
 
 func GetBufferf(buffer uint32, param uint32) float32 {
 
+	if useMixer() {
+		softUnsupported()
+		return 0
+	}
 	var value C.ALfloat
 	C.alGetBufferf(C.ALuint(buffer), C.ALenum(param), &value)
 	return float32(value)
@@ -846,6 +1186,10 @@ func GetBufferf(buffer uint32, param uint32) float32 {
 
 func GetBuffer3f(buffer uint32, param uint32) (v1 float32, v2 float32, v3 float32) {
 
+	if useMixer() {
+		softUnsupported()
+		return 0, 0, 0
+	}
 	var value1, value2, value3 C.ALfloat
 	C.alGetBuffer3f(C.ALuint(buffer), C.ALenum(param), &value1, &value2, &value3)
 	return float32(value1), float32(value2), float32(value3)
@@ -853,11 +1197,18 @@ func GetBuffer3f(buffer uint32, param uint32) (v1 float32, v2 float32, v3 float3
 
 func GetBufferfv(buffer uint32, param uint32, values []float32) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	C.alGetBufferfv(C.ALuint(buffer), C.ALenum(param), (*C.ALfloat)(unsafe.Pointer(&values[0])))
 }
 
 func GetBufferi(buffer uint32, param uint32) int32 {
 
+	if useMixer() {
+		return softGetBufferi(buffer, param)
+	}
 	var value C.ALint
 	C.alGetBufferi(C.ALuint(buffer), C.ALenum(param), &value)
 	return int32(value)
@@ -865,6 +1216,10 @@ func GetBufferi(buffer uint32, param uint32) int32 {
 
 func GetBuffer3i(buffer uint32, param uint32) (int32, int32, int32) {
 
+	if useMixer() {
+		softUnsupported()
+		return 0, 0, 0
+	}
 	var value1, value2, value3 C.ALint
 	C.alGetBuffer3i(C.ALuint(buffer), C.ALenum(param), &value1, &value2, &value3)
 	return int32(value1), int32(value2), int32(value3)
@@ -872,5 +1227,9 @@ func GetBuffer3i(buffer uint32, param uint32) (int32, int32, int32) {
 
 func GetBufferiv(buffer uint32, param uint32, values []int32) {
 
+	if useMixer() {
+		softUnsupported()
+		return
+	}
 	C.alGetBufferiv(C.ALuint(buffer), C.ALenum(param), (*C.ALint)(unsafe.Pointer(&values[0])))
 }
@@ -0,0 +1,935 @@
+// This file contains functions to open the OpenAL shared library at runtime and to load
+// the pointers of its functions, so the engine doesn't need the library to be installed
+// to build or to run. When the library is not found the al package uses its software mixer.
+//
+// As Go cgo cannot call directly to C pointers it also defines C functions with the names
+// of the OpenAL functions which call the loaded pointers.
+// The code is based on the OpenGL function loader of the gls package.
+
+#include <stdlib.h>
+#include "alapi.h"
+
+//
+// OpenAL library loader for Windows
+//
+#ifdef _WIN32
+#define WIN32_LEAN_AND_MEAN 1
+#include <windows.h>
+
+static HMODULE libal;
+
+// open_libal opens the OpenAL dll for Windows
+static int open_libal(void) {
+
+	libal = LoadLibraryA("OpenAL32.dll");
+	if (libal == NULL) {
+		libal = LoadLibraryA("soft_oal.dll");
+	}
+	if (libal == NULL) {
+		return -1;
+	}
+	return 0;
+}
+
+// get_proc gets the pointer for an OpenAL function for Windows
+static void* get_proc(const char *proc) {
+
+	return (void*)GetProcAddress(libal, proc);
+}
+
+//
+// OpenAL library loader for Mac OS, Linux and Unix*
+//
+#else
+#include <dlfcn.h>
+
+// Names of the OpenAL shared library tried in order
+static const char *libal_names[] = {
+#ifdef __APPLE__
+	"libopenal.1.dylib",
+	"libopenal.dylib",
+	"/opt/homebrew/opt/openal-soft/lib/libopenal.1.dylib",
+	"/usr/local/opt/openal-soft/lib/libopenal.1.dylib",
+	"/System/Library/Frameworks/OpenAL.framework/OpenAL",
+#else
+	"libopenal.so.1",
+	"libopenal.so",
+#endif
+	NULL,
+};
+
+static void *libal;
+
+// open_libal opens the OpenAL shared library for Mac OS, Linux and Unix*
+static int open_libal(void) {
+
+	for (int i = 0; libal_names[i] != NULL; i++) {
+		libal = dlopen(libal_names[i], RTLD_LAZY | RTLD_LOCAL);
+		if (libal != NULL) {
+			return 0;
+		}
+	}
+	return -1;
+}
+
+// get_proc gets the pointer for an OpenAL function for Mac OS, Linux and Unix*
+static void* get_proc(const char *proc) {
+
+	void* res;
+	*(void **)(&res) = dlsym(libal, proc);
+	return res;
+}
+#endif
+
+// Pointers to the OpenAL functions
+
+static LPALDOPPLERFACTOR        palDopplerFactor;
+static LPALDOPPLERVELOCITY      palDopplerVelocity;
+static LPALSPEEDOFSOUND         palSpeedOfSound;
+static LPALDISTANCEMODEL        palDistanceModel;
+static LPALENABLE               palEnable;
+static LPALDISABLE              palDisable;
+static LPALISENABLED            palIsEnabled;
+static LPALGETSTRING            palGetString;
+static LPALGETBOOLEANV          palGetBooleanv;
+static LPALGETINTEGERV          palGetIntegerv;
+static LPALGETFLOATV            palGetFloatv;
+static LPALGETDOUBLEV           palGetDoublev;
+static LPALGETBOOLEAN           palGetBoolean;
+static LPALGETINTEGER           palGetInteger;
+static LPALGETFLOAT             palGetFloat;
+static LPALGETDOUBLE            palGetDouble;
+static LPALGETERROR             palGetError;
+static LPALISEXTENSIONPRESENT   palIsExtensionPresent;
+static LPALGETPROCADDRESS       palGetProcAddress;
+static LPALGETENUMVALUE         palGetEnumValue;
+static LPALLISTENERF            palListenerf;
+static LPALLISTENER3F           palListener3f;
+static LPALLISTENERFV           palListenerfv;
+static LPALLISTENERI            palListeneri;
+static LPALLISTENER3I           palListener3i;
+static LPALLISTENERIV           palListeneriv;
+static LPALGETLISTENERF         palGetListenerf;
+static LPALGETLISTENER3F        palGetListener3f;
+static LPALGETLISTENERFV        palGetListenerfv;
+static LPALGETLISTENERI         palGetListeneri;
+static LPALGETLISTENER3I        palGetListener3i;
+static LPALGETLISTENERIV        palGetListeneriv;
+static LPALGENSOURCES           palGenSources;
+static LPALDELETESOURCES        palDeleteSources;
+static LPALISSOURCE             palIsSource;
+static LPALSOURCEF              palSourcef;
+static LPALSOURCE3F             palSource3f;
+static LPALSOURCEFV             palSourcefv;
+static LPALSOURCEI              palSourcei;
+static LPALSOURCE3I             palSource3i;
+static LPALSOURCEIV             palSourceiv;
+static LPALGETSOURCEF           palGetSourcef;
+static LPALGETSOURCE3F          palGetSource3f;
+static LPALGETSOURCEFV          palGetSourcefv;
+static LPALGETSOURCEI           palGetSourcei;
+static LPALGETSOURCE3I          palGetSource3i;
+static LPALGETSOURCEIV          palGetSourceiv;
+static LPALSOURCEPLAYV          palSourcePlayv;
+static LPALSOURCESTOPV          palSourceStopv;
+static LPALSOURCEREWINDV        palSourceRewindv;
+static LPALSOURCEPAUSEV         palSourcePausev;
+static LPALSOURCEPLAY           palSourcePlay;
+static LPALSOURCESTOP           palSourceStop;
+static LPALSOURCEREWIND         palSourceRewind;
+static LPALSOURCEPAUSE          palSourcePause;
+static LPALSOURCEQUEUEBUFFERS   palSourceQueueBuffers;
+static LPALSOURCEUNQUEUEBUFFERS palSourceUnqueueBuffers;
+static LPALGENBUFFERS           palGenBuffers;
+static LPALDELETEBUFFERS        palDeleteBuffers;
+static LPALISBUFFER             palIsBuffer;
+static LPALBUFFERDATA           palBufferData;
+static LPALBUFFERF              palBufferf;
+static LPALBUFFER3F             palBuffer3f;
+static LPALBUFFERFV             palBufferfv;
+static LPALBUFFERI              palBufferi;
+static LPALBUFFER3I             palBuffer3i;
+static LPALBUFFERIV             palBufferiv;
+static LPALGETBUFFERF           palGetBufferf;
+static LPALGETBUFFER3F          palGetBuffer3f;
+static LPALGETBUFFERFV          palGetBufferfv;
+static LPALGETBUFFERI           palGetBufferi;
+static LPALGETBUFFER3I          palGetBuffer3i;
+static LPALGETBUFFERIV          palGetBufferiv;
+static LPALCCREATECONTEXT       palcCreateContext;
+static LPALCMAKECONTEXTCURRENT  palcMakeContextCurrent;
+static LPALCPROCESSCONTEXT      palcProcessContext;
+static LPALCSUSPENDCONTEXT      palcSuspendContext;
+static LPALCDESTROYCONTEXT      palcDestroyContext;
+static LPALCGETCURRENTCONTEXT   palcGetCurrentContext;
+static LPALCGETCONTEXTSDEVICE   palcGetContextsDevice;
+static LPALCOPENDEVICE          palcOpenDevice;
+static LPALCCLOSEDEVICE         palcCloseDevice;
+static LPALCGETERROR            palcGetError;
+static LPALCISEXTENSIONPRESENT  palcIsExtensionPresent;
+static LPALCGETPROCADDRESS      palcGetProcAddress;
+static LPALCGETENUMVALUE        palcGetEnumValue;
+static LPALCGETSTRING           palcGetString;
+static LPALCGETINTEGERV         palcGetIntegerv;
+static LPALCCAPTUREOPENDEVICE   palcCaptureOpenDevice;
+static LPALCCAPTURECLOSEDEVICE  palcCaptureCloseDevice;
+static LPALCCAPTURESTART        palcCaptureStart;
+static LPALCCAPTURESTOP         palcCaptureStop;
+static LPALCCAPTURESAMPLES      palcCaptureSamples;
+
+//
+// alapiLoad() tries to open the OpenAL library and to load the addresses of its functions.
+// Returns 0 if all the functions were loaded or -1 otherwise.
+//
+int alapiLoad(void) {
+
+	if (open_libal() != 0) {
+		return -1;
+	}
+	int res = 0;
+	if ((palDopplerFactor = (LPALDOPPLERFACTOR)get_proc("alDopplerFactor")) == NULL) {
+		res = -1;
+	}
+	if ((palDopplerVelocity = (LPALDOPPLERVELOCITY)get_proc("alDopplerVelocity")) == NULL) {
+		res = -1;
+	}
+	if ((palSpeedOfSound = (LPALSPEEDOFSOUND)get_proc("alSpeedOfSound")) == NULL) {
+		res = -1;
+	}
+	if ((palDistanceModel = (LPALDISTANCEMODEL)get_proc("alDistanceModel")) == NULL) {
+		res = -1;
+	}
+	if ((palEnable = (LPALENABLE)get_proc("alEnable")) == NULL) {
+		res = -1;
+	}
+	if ((palDisable = (LPALDISABLE)get_proc("alDisable")) == NULL) {
+		res = -1;
+	}
+	if ((palIsEnabled = (LPALISENABLED)get_proc("alIsEnabled")) == NULL) {
+		res = -1;
+	}
+	if ((palGetString = (LPALGETSTRING)get_proc("alGetString")) == NULL) {
+		res = -1;
+	}
+	if ((palGetBooleanv = (LPALGETBOOLEANV)get_proc("alGetBooleanv")) == NULL) {
+		res = -1;
+	}
+	if ((palGetIntegerv = (LPALGETINTEGERV)get_proc("alGetIntegerv")) == NULL) {
+		res = -1;
+	}
+	if ((palGetFloatv = (LPALGETFLOATV)get_proc("alGetFloatv")) == NULL) {
+		res = -1;
+	}
+	if ((palGetDoublev = (LPALGETDOUBLEV)get_proc("alGetDoublev")) == NULL) {
+		res = -1;
+	}
+	if ((palGetBoolean = (LPALGETBOOLEAN)get_proc("alGetBoolean")) == NULL) {
+		res = -1;
+	}
+	if ((palGetInteger = (LPALGETINTEGER)get_proc("alGetInteger")) == NULL) {
+		res = -1;
+	}
+	if ((palGetFloat = (LPALGETFLOAT)get_proc("alGetFloat")) == NULL) {
+		res = -1;
+	}
+	if ((palGetDouble = (LPALGETDOUBLE)get_proc("alGetDouble")) == NULL) {
+		res = -1;
+	}
+	if ((palGetError = (LPALGETERROR)get_proc("alGetError")) == NULL) {
+		res = -1;
+	}
+	if ((palIsExtensionPresent = (LPALISEXTENSIONPRESENT)get_proc("alIsExtensionPresent")) == NULL) {
+		res = -1;
+	}
+	if ((palGetProcAddress = (LPALGETPROCADDRESS)get_proc("alGetProcAddress")) == NULL) {
+		res = -1;
+	}
+	if ((palGetEnumValue = (LPALGETENUMVALUE)get_proc("alGetEnumValue")) == NULL) {
+		res = -1;
+	}
+	if ((palListenerf = (LPALLISTENERF)get_proc("alListenerf")) == NULL) {
+		res = -1;
+	}
+	if ((palListener3f = (LPALLISTENER3F)get_proc("alListener3f")) == NULL) {
+		res = -1;
+	}
+	if ((palListenerfv = (LPALLISTENERFV)get_proc("alListenerfv")) == NULL) {
+		res = -1;
+	}
+	if ((palListeneri = (LPALLISTENERI)get_proc("alListeneri")) == NULL) {
+		res = -1;
+	}
+	if ((palListener3i = (LPALLISTENER3I)get_proc("alListener3i")) == NULL) {
+		res = -1;
+	}
+	if ((palListeneriv = (LPALLISTENERIV)get_proc("alListeneriv")) == NULL) {
+		res = -1;
+	}
+	if ((palGetListenerf = (LPALGETLISTENERF)get_proc("alGetListenerf")) == NULL) {
+		res = -1;
+	}
+	if ((palGetListener3f = (LPALGETLISTENER3F)get_proc("alGetListener3f")) == NULL) {
+		res = -1;
+	}
+	if ((palGetListenerfv = (LPALGETLISTENERFV)get_proc("alGetListenerfv")) == NULL) {
+		res = -1;
+	}
+	if ((palGetListeneri = (LPALGETLISTENERI)get_proc("alGetListeneri")) == NULL) {
+		res = -1;
+	}
+	if ((palGetListener3i = (LPALGETLISTENER3I)get_proc("alGetListener3i")) == NULL) {
+		res = -1;
+	}
+	if ((palGetListeneriv = (LPALGETLISTENERIV)get_proc("alGetListeneriv")) == NULL) {
+		res = -1;
+	}
+	if ((palGenSources = (LPALGENSOURCES)get_proc("alGenSources")) == NULL) {
+		res = -1;
+	}
+	if ((palDeleteSources = (LPALDELETESOURCES)get_proc("alDeleteSources")) == NULL) {
+		res = -1;
+	}
+	if ((palIsSource = (LPALISSOURCE)get_proc("alIsSource")) == NULL) {
+		res = -1;
+	}
+	if ((palSourcef = (LPALSOURCEF)get_proc("alSourcef")) == NULL) {
+		res = -1;
+	}
+	if ((palSource3f = (LPALSOURCE3F)get_proc("alSource3f")) == NULL) {
+		res = -1;
+	}
+	if ((palSourcefv = (LPALSOURCEFV)get_proc("alSourcefv")) == NULL) {
+		res = -1;
+	}
+	if ((palSourcei = (LPALSOURCEI)get_proc("alSourcei")) == NULL) {
+		res = -1;
+	}
+	if ((palSource3i = (LPALSOURCE3I)get_proc("alSource3i")) == NULL) {
+		res = -1;
+	}
+	if ((palSourceiv = (LPALSOURCEIV)get_proc("alSourceiv")) == NULL) {
+		res = -1;
+	}
+	if ((palGetSourcef = (LPALGETSOURCEF)get_proc("alGetSourcef")) == NULL) {
+		res = -1;
+	}
+	if ((palGetSource3f = (LPALGETSOURCE3F)get_proc("alGetSource3f")) == NULL) {
+		res = -1;
+	}
+	if ((palGetSourcefv = (LPALGETSOURCEFV)get_proc("alGetSourcefv")) == NULL) {
+		res = -1;
+	}
+	if ((palGetSourcei = (LPALGETSOURCEI)get_proc("alGetSourcei")) == NULL) {
+		res = -1;
+	}
+	if ((palGetSource3i = (LPALGETSOURCE3I)get_proc("alGetSource3i")) == NULL) {
+		res = -1;
+	}
+	if ((palGetSourceiv = (LPALGETSOURCEIV)get_proc("alGetSourceiv")) == NULL) {
+		res = -1;
+	}
+	if ((palSourcePlayv = (LPALSOURCEPLAYV)get_proc("alSourcePlayv")) == NULL) {
+		res = -1;
+	}
+	if ((palSourceStopv = (LPALSOURCESTOPV)get_proc("alSourceStopv")) == NULL) {
+		res = -1;
+	}
+	if ((palSourceRewindv = (LPALSOURCEREWINDV)get_proc("alSourceRewindv")) == NULL) {
+		res = -1;
+	}
+	if ((palSourcePausev = (LPALSOURCEPAUSEV)get_proc("alSourcePausev")) == NULL) {
+		res = -1;
+	}
+	if ((palSourcePlay = (LPALSOURCEPLAY)get_proc("alSourcePlay")) == NULL) {
+		res = -1;
+	}
+	if ((palSourceStop = (LPALSOURCESTOP)get_proc("alSourceStop")) == NULL) {
+		res = -1;
+	}
+	if ((palSourceRewind = (LPALSOURCEREWIND)get_proc("alSourceRewind")) == NULL) {
+		res = -1;
+	}
+	if ((palSourcePause = (LPALSOURCEPAUSE)get_proc("alSourcePause")) == NULL) {
+		res = -1;
+	}
+	if ((palSourceQueueBuffers = (LPALSOURCEQUEUEBUFFERS)get_proc("alSourceQueueBuffers")) == NULL) {
+		res = -1;
+	}
+	if ((palSourceUnqueueBuffers = (LPALSOURCEUNQUEUEBUFFERS)get_proc("alSourceUnqueueBuffers")) == NULL) {
+		res = -1;
+	}
+	if ((palGenBuffers = (LPALGENBUFFERS)get_proc("alGenBuffers")) == NULL) {
+		res = -1;
+	}
+	if ((palDeleteBuffers = (LPALDELETEBUFFERS)get_proc("alDeleteBuffers")) == NULL) {
+		res = -1;
+	}
+	if ((palIsBuffer = (LPALISBUFFER)get_proc("alIsBuffer")) == NULL) {
+		res = -1;
+	}
+	if ((palBufferData = (LPALBUFFERDATA)get_proc("alBufferData")) == NULL) {
+		res = -1;
+	}
+	if ((palBufferf = (LPALBUFFERF)get_proc("alBufferf")) == NULL) {
+		res = -1;
+	}
+	if ((palBuffer3f = (LPALBUFFER3F)get_proc("alBuffer3f")) == NULL) {
+		res = -1;
+	}
+	if ((palBufferfv = (LPALBUFFERFV)get_proc("alBufferfv")) == NULL) {
+		res = -1;
+	}
+	if ((palBufferi = (LPALBUFFERI)get_proc("alBufferi")) == NULL) {
+		res = -1;
+	}
+	if ((palBuffer3i = (LPALBUFFER3I)get_proc("alBuffer3i")) == NULL) {
+		res = -1;
+	}
+	if ((palBufferiv = (LPALBUFFERIV)get_proc("alBufferiv")) == NULL) {
+		res = -1;
+	}
+	if ((palGetBufferf = (LPALGETBUFFERF)get_proc("alGetBufferf")) == NULL) {
+		res = -1;
+	}
+	if ((palGetBuffer3f = (LPALGETBUFFER3F)get_proc("alGetBuffer3f")) == NULL) {
+		res = -1;
+	}
+	if ((palGetBufferfv = (LPALGETBUFFERFV)get_proc("alGetBufferfv")) == NULL) {
+		res = -1;
+	}
+	if ((palGetBufferi = (LPALGETBUFFERI)get_proc("alGetBufferi")) == NULL) {
+		res = -1;
+	}
+	if ((palGetBuffer3i = (LPALGETBUFFER3I)get_proc("alGetBuffer3i")) == NULL) {
+		res = -1;
+	}
+	if ((palGetBufferiv = (LPALGETBUFFERIV)get_proc("alGetBufferiv")) == NULL) {
+		res = -1;
+	}
+	if ((palcCreateContext = (LPALCCREATECONTEXT)get_proc("alcCreateContext")) == NULL) {
+		res = -1;
+	}
+	if ((palcMakeContextCurrent = (LPALCMAKECONTEXTCURRENT)get_proc("alcMakeContextCurrent")) == NULL) {
+		res = -1;
+	}
+	if ((palcProcessContext = (LPALCPROCESSCONTEXT)get_proc("alcProcessContext")) == NULL) {
+		res = -1;
+	}
+	if ((palcSuspendContext = (LPALCSUSPENDCONTEXT)get_proc("alcSuspendContext")) == NULL) {
+		res = -1;
+	}
+	if ((palcDestroyContext = (LPALCDESTROYCONTEXT)get_proc("alcDestroyContext")) == NULL) {
+		res = -1;
+	}
+	if ((palcGetCurrentContext = (LPALCGETCURRENTCONTEXT)get_proc("alcGetCurrentContext")) == NULL) {
+		res = -1;
+	}
+	if ((palcGetContextsDevice = (LPALCGETCONTEXTSDEVICE)get_proc("alcGetContextsDevice")) == NULL) {
+		res = -1;
+	}
+	if ((palcOpenDevice = (LPALCOPENDEVICE)get_proc("alcOpenDevice")) == NULL) {
+		res = -1;
+	}
+	if ((palcCloseDevice = (LPALCCLOSEDEVICE)get_proc("alcCloseDevice")) == NULL) {
+		res = -1;
+	}
+	if ((palcGetError = (LPALCGETERROR)get_proc("alcGetError")) == NULL) {
+		res = -1;
+	}
+	if ((palcIsExtensionPresent = (LPALCISEXTENSIONPRESENT)get_proc("alcIsExtensionPresent")) == NULL) {
+		res = -1;
+	}
+	if ((palcGetProcAddress = (LPALCGETPROCADDRESS)get_proc("alcGetProcAddress")) == NULL) {
+		res = -1;
+	}
+	if ((palcGetEnumValue = (LPALCGETENUMVALUE)get_proc("alcGetEnumValue")) == NULL) {
+		res = -1;
+	}
+	if ((palcGetString = (LPALCGETSTRING)get_proc("alcGetString")) == NULL) {
+		res = -1;
+	}
+	if ((palcGetIntegerv = (LPALCGETINTEGERV)get_proc("alcGetIntegerv")) == NULL) {
+		res = -1;
+	}
+	if ((palcCaptureOpenDevice = (LPALCCAPTUREOPENDEVICE)get_proc("alcCaptureOpenDevice")) == NULL) {
+		res = -1;
+	}
+	if ((palcCaptureCloseDevice = (LPALCCAPTURECLOSEDEVICE)get_proc("alcCaptureCloseDevice")) == NULL) {
+		res = -1;
+	}
+	if ((palcCaptureStart = (LPALCCAPTURESTART)get_proc("alcCaptureStart")) == NULL) {
+		res = -1;
+	}
+	if ((palcCaptureStop = (LPALCCAPTURESTOP)get_proc("alcCaptureStop")) == NULL) {
+		res = -1;
+	}
+	if ((palcCaptureSamples = (LPALCCAPTURESAMPLES)get_proc("alcCaptureSamples")) == NULL) {
+		res = -1;
+	}
+	return res;
+}
+
+void AL_APIENTRY alDopplerFactor(ALfloat value) {
+
+	palDopplerFactor(value);
+}
+
+void AL_APIENTRY alDopplerVelocity(ALfloat value) {
+
+	palDopplerVelocity(value);
+}
+
+void AL_APIENTRY alSpeedOfSound(ALfloat value) {
+
+	palSpeedOfSound(value);
+}
+
+void AL_APIENTRY alDistanceModel(ALenum distanceModel) {
+
+	palDistanceModel(distanceModel);
+}
+
+void AL_APIENTRY alEnable(ALenum capability) {
+
+	palEnable(capability);
+}
+
+void AL_APIENTRY alDisable(ALenum capability) {
+
+	palDisable(capability);
+}
+
+ALboolean AL_APIENTRY alIsEnabled(ALenum capability) {
+
+	return palIsEnabled(capability);
+}
+
+const ALchar* AL_APIENTRY alGetString(ALenum param) {
+
+	return palGetString(param);
+}
+
+void AL_APIENTRY alGetBooleanv(ALenum param, ALboolean *values) {
+
+	palGetBooleanv(param, values);
+}
+
+void AL_APIENTRY alGetIntegerv(ALenum param, ALint *values) {
+
+	palGetIntegerv(param, values);
+}
+
+void AL_APIENTRY alGetFloatv(ALenum param, ALfloat *values) {
+
+	palGetFloatv(param, values);
+}
+
+void AL_APIENTRY alGetDoublev(ALenum param, ALdouble *values) {
+
+	palGetDoublev(param, values);
+}
+
+ALboolean AL_APIENTRY alGetBoolean(ALenum param) {
+
+	return palGetBoolean(param);
+}
+
+ALint AL_APIENTRY alGetInteger(ALenum param) {
+
+	return palGetInteger(param);
+}
+
+ALfloat AL_APIENTRY alGetFloat(ALenum param) {
+
+	return palGetFloat(param);
+}
+
+ALdouble AL_APIENTRY alGetDouble(ALenum param) {
+
+	return palGetDouble(param);
+}
+
+ALenum AL_APIENTRY alGetError(void) {
+
+	return palGetError();
+}
+
+ALboolean AL_APIENTRY alIsExtensionPresent(const ALchar *extname) {
+
+	return palIsExtensionPresent(extname);
+}
+
+void* AL_APIENTRY alGetProcAddress(const ALchar *fname) {
+
+	return palGetProcAddress(fname);
+}
+
+ALenum AL_APIENTRY alGetEnumValue(const ALchar *ename) {
+
+	return palGetEnumValue(ename);
+}
+
+void AL_APIENTRY alListenerf(ALenum param, ALfloat value) {
+
+	palListenerf(param, value);
+}
+
+void AL_APIENTRY alListener3f(ALenum param, ALfloat value1, ALfloat value2, ALfloat value3) {
+
+	palListener3f(param, value1, value2, value3);
+}
+
+void AL_APIENTRY alListenerfv(ALenum param, const ALfloat *values) {
+
+	palListenerfv(param, values);
+}
+
+void AL_APIENTRY alListeneri(ALenum param, ALint value) {
+
+	palListeneri(param, value);
+}
+
+void AL_APIENTRY alListener3i(ALenum param, ALint value1, ALint value2, ALint value3) {
+
+	palListener3i(param, value1, value2, value3);
+}
+
+void AL_APIENTRY alListeneriv(ALenum param, const ALint *values) {
+
+	palListeneriv(param, values);
+}
+
+void AL_APIENTRY alGetListenerf(ALenum param, ALfloat *value) {
+
+	palGetListenerf(param, value);
+}
+
+void AL_APIENTRY alGetListener3f(ALenum param, ALfloat *value1, ALfloat *value2, ALfloat *value3) {
+
+	palGetListener3f(param, value1, value2, value3);
+}
+
+void AL_APIENTRY alGetListenerfv(ALenum param, ALfloat *values) {
+
+	palGetListenerfv(param, values);
+}
+
+void AL_APIENTRY alGetListeneri(ALenum param, ALint *value) {
+
+	palGetListeneri(param, value);
+}
+
+void AL_APIENTRY alGetListener3i(ALenum param, ALint *value1, ALint *value2, ALint *value3) {
+
+	palGetListener3i(param, value1, value2, value3);
+}
+
+void AL_APIENTRY alGetListeneriv(ALenum param, ALint *values) {
+
+	palGetListeneriv(param, values);
+}
+
+void AL_APIENTRY alGenSources(ALsizei n, ALuint *sources) {
+
+	palGenSources(n, sources);
+}
+
+void AL_APIENTRY alDeleteSources(ALsizei n, const ALuint *sources) {
+
+	palDeleteSources(n, sources);
+}
+
+ALboolean AL_APIENTRY alIsSource(ALuint source) {
+
+	return palIsSource(source);
+}
+
+void AL_APIENTRY alSourcef(ALuint source, ALenum param, ALfloat value) {
+
+	palSourcef(source, param, value);
+}
+
+void AL_APIENTRY alSource3f(ALuint source, ALenum param, ALfloat value1, ALfloat value2, ALfloat value3) {
+
+	palSource3f(source, param, value1, value2, value3);
+}
+
+void AL_APIENTRY alSourcefv(ALuint source, ALenum param, const ALfloat *values) {
+
+	palSourcefv(source, param, values);
+}
+
+void AL_APIENTRY alSourcei(ALuint source, ALenum param, ALint value) {
+
+	palSourcei(source, param, value);
+}
+
+void AL_APIENTRY alSource3i(ALuint source, ALenum param, ALint value1, ALint value2, ALint value3) {
+
+	palSource3i(source, param, value1, value2, value3);
+}
+
+void AL_APIENTRY alSourceiv(ALuint source, ALenum param, const ALint *values) {
+
+	palSourceiv(source, param, values);
+}
+
+void AL_APIENTRY alGetSourcef(ALuint source, ALenum param, ALfloat *value) {
+
+	palGetSourcef(source, param, value);
+}
+
+void AL_APIENTRY alGetSource3f(ALuint source, ALenum param, ALfloat *value1, ALfloat *value2, ALfloat *value3) {
+
+	palGetSource3f(source, param, value1, value2, value3);
+}
+
+void AL_APIENTRY alGetSourcefv(ALuint source, ALenum param, ALfloat *values) {
+
+	palGetSourcefv(source, param, values);
+}
+
+void AL_APIENTRY alGetSourcei(ALuint source,  ALenum param, ALint *value) {
+
+	palGetSourcei(source, param, value);
+}
+
+void AL_APIENTRY alGetSource3i(ALuint source, ALenum param, ALint *value1, ALint *value2, ALint *value3) {
+
+	palGetSource3i(source, param, value1, value2, value3);
+}
+
+void AL_APIENTRY alGetSourceiv(ALuint source,  ALenum param, ALint *values) {
+
+	palGetSourceiv(source, param, values);
+}
+
+void AL_APIENTRY alSourcePlayv(ALsizei n, const ALuint *sources) {
+
+	palSourcePlayv(n, sources);
+}
+
+void AL_APIENTRY alSourceStopv(ALsizei n, const ALuint *sources) {
+
+	palSourceStopv(n, sources);
+}
+
+void AL_APIENTRY alSourceRewindv(ALsizei n, const ALuint *sources) {
+
+	palSourceRewindv(n, sources);
+}
+
+void AL_APIENTRY alSourcePausev(ALsizei n, const ALuint *sources) {
+
+	palSourcePausev(n, sources);
+}
+
+void AL_APIENTRY alSourcePlay(ALuint source) {
+
+	palSourcePlay(source);
+}
+
+void AL_APIENTRY alSourceStop(ALuint source) {
+
+	palSourceStop(source);
+}
+
+void AL_APIENTRY alSourceRewind(ALuint source) {
+
+	palSourceRewind(source);
+}
+
+void AL_APIENTRY alSourcePause(ALuint source) {
+
+	palSourcePause(source);
+}
+
+void AL_APIENTRY alSourceQueueBuffers(ALuint source, ALsizei nb, const ALuint *buffers) {
+
+	palSourceQueueBuffers(source, nb, buffers);
+}
+
+void AL_APIENTRY alSourceUnqueueBuffers(ALuint source, ALsizei nb, ALuint *buffers) {
+
+	palSourceUnqueueBuffers(source, nb, buffers);
+}
+
+void AL_APIENTRY alGenBuffers(ALsizei n, ALuint *buffers) {
+
+	palGenBuffers(n, buffers);
+}
+
+void AL_APIENTRY alDeleteBuffers(ALsizei n, const ALuint *buffers) {
+
+	palDeleteBuffers(n, buffers);
+}
+
+ALboolean AL_APIENTRY alIsBuffer(ALuint buffer) {
+
+	return palIsBuffer(buffer);
+}
+
+void AL_APIENTRY alBufferData(ALuint buffer, ALenum format, const ALvoid *data, ALsizei size, ALsizei freq) {
+
+	palBufferData(buffer, format, data, size, freq);
+}
+
+void AL_APIENTRY alBufferf(ALuint buffer, ALenum param, ALfloat value) {
+
+	palBufferf(buffer, param, value);
+}
+
+void AL_APIENTRY alBuffer3f(ALuint buffer, ALenum param, ALfloat value1, ALfloat value2, ALfloat value3) {
+
+	palBuffer3f(buffer, param, value1, value2, value3);
+}
+
+void AL_APIENTRY alBufferfv(ALuint buffer, ALenum param, const ALfloat *values) {
+
+	palBufferfv(buffer, param, values);
+}
+
+void AL_APIENTRY alBufferi(ALuint buffer, ALenum param, ALint value) {
+
+	palBufferi(buffer, param, value);
+}
+
+void AL_APIENTRY alBuffer3i(ALuint buffer, ALenum param, ALint value1, ALint value2, ALint value3) {
+
+	palBuffer3i(buffer, param, value1, value2, value3);
+}
+
+void AL_APIENTRY alBufferiv(ALuint buffer, ALenum param, const ALint *values) {
+
+	palBufferiv(buffer, param, values);
+}
+
+void AL_APIENTRY alGetBufferf(ALuint buffer, ALenum param, ALfloat *value) {
+
+	palGetBufferf(buffer, param, value);
+}
+
+void AL_APIENTRY alGetBuffer3f(ALuint buffer, ALenum param, ALfloat *value1, ALfloat *value2, ALfloat *value3) {
+
+	palGetBuffer3f(buffer, param, value1, value2, value3);
+}
+
+void AL_APIENTRY alGetBufferfv(ALuint buffer, ALenum param, ALfloat *values) {
+
+	palGetBufferfv(buffer, param, values);
+}
+
+void AL_APIENTRY alGetBufferi(ALuint buffer, ALenum param, ALint *value) {
+
+	palGetBufferi(buffer, param, value);
+}
+
+void AL_APIENTRY alGetBuffer3i(ALuint buffer, ALenum param, ALint *value1, ALint *value2, ALint *value3) {
+
+	palGetBuffer3i(buffer, param, value1, value2, value3);
+}
+
+void AL_APIENTRY alGetBufferiv(ALuint buffer, ALenum param, ALint *values) {
+
+	palGetBufferiv(buffer, param, values);
+}
+
+ALCcontext* ALC_APIENTRY alcCreateContext(ALCdevice *device, const ALCint* attrlist) {
+
+	return palcCreateContext(device, attrlist);
+}
+
+ALCboolean ALC_APIENTRY alcMakeContextCurrent(ALCcontext *context) {
+
+	return palcMakeContextCurrent(context);
+}
+
+void ALC_APIENTRY alcProcessContext(ALCcontext *context) {
+
+	palcProcessContext(context);
+}
+
+void ALC_APIENTRY alcSuspendContext(ALCcontext *context) {
+
+	palcSuspendContext(context);
+}
+
+void ALC_APIENTRY alcDestroyContext(ALCcontext *context) {
+
+	palcDestroyContext(context);
+}
+
+ALCcontext* ALC_APIENTRY alcGetCurrentContext(void) {
+
+	return palcGetCurrentContext();
+}
+
+ALCdevice* ALC_APIENTRY alcGetContextsDevice(ALCcontext *context) {
+
+	return palcGetContextsDevice(context);
+}
+
+ALCdevice* ALC_APIENTRY alcOpenDevice(const ALCchar *devicename) {
+
+	return palcOpenDevice(devicename);
+}
+
+ALCboolean ALC_APIENTRY alcCloseDevice(ALCdevice *device) {
+
+	return palcCloseDevice(device);
+}
+
+ALCenum ALC_APIENTRY alcGetError(ALCdevice *device) {
+
+	return palcGetError(device);
+}
+
+ALCboolean ALC_APIENTRY alcIsExtensionPresent(ALCdevice *device, const ALCchar *extname) {
+
+	return palcIsExtensionPresent(device, extname);
+}
+
+void* ALC_APIENTRY alcGetProcAddress(ALCdevice *device, const ALCchar *funcname) {
+
+	return palcGetProcAddress(device, funcname);
+}
+
+ALCenum ALC_APIENTRY alcGetEnumValue(ALCdevice *device, const ALCchar *enumname) {
+
+	return palcGetEnumValue(device, enumname);
+}
+
+const ALCchar* ALC_APIENTRY alcGetString(ALCdevice *device, ALCenum param) {
+
+	return palcGetString(device, param);
+}
+
+void ALC_APIENTRY alcGetIntegerv(ALCdevice *device, ALCenum param, ALCsizei size, ALCint *values) {
+
+	palcGetIntegerv(device, param, size, values);
+}
+
+ALCdevice* ALC_APIENTRY alcCaptureOpenDevice(const ALCchar *devicename, ALCuint frequency, ALCenum format, ALCsizei buffersize) {
+
+	return palcCaptureOpenDevice(devicename, frequency, format, buffersize);
+}
+
+ALCboolean ALC_APIENTRY alcCaptureCloseDevice(ALCdevice *device) {
+
+	return palcCaptureCloseDevice(device);
+}
+
+void ALC_APIENTRY alcCaptureStart(ALCdevice *device) {
+
+	palcCaptureStart(device);
+}
+
+void ALC_APIENTRY alcCaptureStop(ALCdevice *device) {
+
+	palcCaptureStop(device);
+}
+
+void ALC_APIENTRY alcCaptureSamples(ALCdevice *device, ALCvoid *buffer, ALCsizei samples) {
+
+	palcCaptureSamples(device, buffer, samples);
+}
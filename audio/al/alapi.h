@@ -0,0 +1,15 @@
+// This file contains declarations of public functions from "alapi.c".
+
+#ifndef _alapi_h_
+#define _alapi_h_
+
+// The OpenAL functions are defined by "alapi.c" and not imported from the library
+#define AL_LIBTYPE_STATIC
+
+#include "al.h"
+#include "alc.h"
+
+// Tries to open the OpenAL library and to load the addresses of its functions
+int alapiLoad(void);
+
+#endif
@@ -0,0 +1,217 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package al
+
+// The functions of the EFX extension are not exported by all the OpenAL libraries,
+// so they are loaded with alGetProcAddress when the extension is first used.
+
+// #include "alapi.h"
+// #include "efx.h"
+//
+// static LPALGENEFFECTS                palGenEffects;
+// static LPALDELETEEFFECTS             palDeleteEffects;
+// static LPALEFFECTI                   palEffecti;
+// static LPALEFFECTF                   palEffectf;
+// static LPALGENFILTERS                palGenFilters;
+// static LPALDELETEFILTERS             palDeleteFilters;
+// static LPALFILTERI                   palFilteri;
+// static LPALFILTERF                   palFilterf;
+// static LPALGENAUXILIARYEFFECTSLOTS    palGenAuxiliaryEffectSlots;
+// static LPALDELETEAUXILIARYEFFECTSLOTS palDeleteAuxiliaryEffectSlots;
+// static LPALAUXILIARYEFFECTSLOTI       palAuxiliaryEffectSloti;
+// static LPALAUXILIARYEFFECTSLOTF       palAuxiliaryEffectSlotf;
+//
+// static int loadEFX() {
+//     ALCdevice *dev = alcGetContextsDevice(alcGetCurrentContext());
+//     if (dev == NULL || !alcIsExtensionPresent(dev, "ALC_EXT_EFX")) {
+//         return 0;
+//     }
+//     palGenEffects = (LPALGENEFFECTS)alGetProcAddress("alGenEffects");
+//     palDeleteEffects = (LPALDELETEEFFECTS)alGetProcAddress("alDeleteEffects");
+//     palEffecti = (LPALEFFECTI)alGetProcAddress("alEffecti");
+//     palEffectf = (LPALEFFECTF)alGetProcAddress("alEffectf");
+//     palGenFilters = (LPALGENFILTERS)alGetProcAddress("alGenFilters");
+//     palDeleteFilters = (LPALDELETEFILTERS)alGetProcAddress("alDeleteFilters");
+//     palFilteri = (LPALFILTERI)alGetProcAddress("alFilteri");
+//     palFilterf = (LPALFILTERF)alGetProcAddress("alFilterf");
+//     palGenAuxiliaryEffectSlots = (LPALGENAUXILIARYEFFECTSLOTS)alGetProcAddress("alGenAuxiliaryEffectSlots");
+//     palDeleteAuxiliaryEffectSlots = (LPALDELETEAUXILIARYEFFECTSLOTS)alGetProcAddress("alDeleteAuxiliaryEffectSlots");
+//     palAuxiliaryEffectSloti = (LPALAUXILIARYEFFECTSLOTI)alGetProcAddress("alAuxiliaryEffectSloti");
+//     palAuxiliaryEffectSlotf = (LPALAUXILIARYEFFECTSLOTF)alGetProcAddress("alAuxiliaryEffectSlotf");
+//     return palGenEffects && palDeleteEffects && palEffecti && palEffectf &&
+//         palGenFilters && palDeleteFilters && palFilteri && palFilterf &&
+//         palGenAuxiliaryEffectSlots && palDeleteAuxiliaryEffectSlots &&
+//         palAuxiliaryEffectSloti && palAuxiliaryEffectSlotf;
+// }
+//
+// static ALint maxAuxiliarySends() {
+//     ALint sends = 0;
+//     ALCdevice *dev = alcGetContextsDevice(alcGetCurrentContext());
+//     if (dev != NULL) {
+//         alcGetIntegerv(dev, ALC_MAX_AUXILIARY_SENDS, 1, &sends);
+//     }
+//     return sends;
+// }
+//
+// static void genEffects(ALsizei n, ALuint *effects) { palGenEffects(n, effects); }
+// static void deleteEffects(ALsizei n, const ALuint *effects) { palDeleteEffects(n, effects); }
+// static void effecti(ALuint effect, ALenum param, ALint value) { palEffecti(effect, param, value); }
+// static void effectf(ALuint effect, ALenum param, ALfloat value) { palEffectf(effect, param, value); }
+// static void genFilters(ALsizei n, ALuint *filters) { palGenFilters(n, filters); }
+// static void deleteFilters(ALsizei n, const ALuint *filters) { palDeleteFilters(n, filters); }
+// static void filteri(ALuint filter, ALenum param, ALint value) { palFilteri(filter, param, value); }
+// static void filterf(ALuint filter, ALenum param, ALfloat value) { palFilterf(filter, param, value); }
+// static void genAuxiliaryEffectSlots(ALsizei n, ALuint *slots) { palGenAuxiliaryEffectSlots(n, slots); }
+// static void deleteAuxiliaryEffectSlots(ALsizei n, const ALuint *slots) { palDeleteAuxiliaryEffectSlots(n, slots); }
+// static void auxiliaryEffectSloti(ALuint slot, ALenum param, ALint value) { palAuxiliaryEffectSloti(slot, param, value); }
+// static void auxiliaryEffectSlotf(ALuint slot, ALenum param, ALfloat value) { palAuxiliaryEffectSlotf(slot, param, value); }
+import "C"
+
+// EFX loading state
+var (
+	efxLoaded    bool // The loading of the EFX functions was attempted
+	efxSupported bool // The EFX functions were loaded
+)
+
+// EFXSupported returns whether the device of the current context supports the EFX extension.
+// The functions of the extension must only be called if it returns true.
+func EFXSupported() bool {
+
+	if useMixer() {
+		return false
+	}
+	if !efxLoaded {
+		efxSupported = C.loadEFX() != 0
+		efxLoaded = efxSupported || C.alcGetCurrentContext() != nil
+	}
+	return efxSupported
+}
+
+// MaxAuxiliarySends returns the maximum number of auxiliary sends of each source
+// of the device of the current context.
+func MaxAuxiliarySends() int {
+
+	if useMixer() {
+		return 0
+	}
+	return int(C.maxAuxiliarySends())
+}
+
+func GenEffect() uint32 {
+
+	if useMixer() {
+		softUnsupported()
+		return 0
+	}
+	var ceffect C.ALuint
+	C.genEffects(1, &ceffect)
+	return uint32(ceffect)
+}
+
+func DeleteEffect(effect uint32) {
+
+	if useMixer() {
+		softUnsupported()
+		return
+	}
+	ceffect := C.ALuint(effect)
+	C.deleteEffects(1, &ceffect)
+}
+
+func Effecti(effect uint32, param uint32, value int32) {
+
+	if useMixer() {
+		softUnsupported()
+		return
+	}
+	C.effecti(C.ALuint(effect), C.ALenum(param), C.ALint(value))
+}
+
+func Effectf(effect uint32, param uint32, value float32) {
+
+	if useMixer() {
+		softUnsupported()
+		return
+	}
+	C.effectf(C.ALuint(effect), C.ALenum(param), C.ALfloat(value))
+}
+
+func GenFilter() uint32 {
+
+	if useMixer() {
+		softUnsupported()
+		return 0
+	}
+	var cfilter C.ALuint
+	C.genFilters(1, &cfilter)
+	return uint32(cfilter)
+}
+
+func DeleteFilter(filter uint32) {
+
+	if useMixer() {
+		softUnsupported()
+		return
+	}
+	cfilter := C.ALuint(filter)
+	C.deleteFilters(1, &cfilter)
+}
+
+func Filteri(filter uint32, param uint32, value int32) {
+
+	if useMixer() {
+		softUnsupported()
+		return
+	}
+	C.filteri(C.ALuint(filter), C.ALenum(param), C.ALint(value))
+}
+
+func Filterf(filter uint32, param uint32, value float32) {
+
+	if useMixer() {
+		softUnsupported()
+		return
+	}
+	C.filterf(C.ALuint(filter), C.ALenum(param), C.ALfloat(value))
+}
+
+func GenAuxiliaryEffectSlot() uint32 {
+
+	if useMixer() {
+		softUnsupported()
+		return 0
+	}
+	var cslot C.ALuint
+	C.genAuxiliaryEffectSlots(1, &cslot)
+	return uint32(cslot)
+}
+
+func DeleteAuxiliaryEffectSlot(slot uint32) {
+
+	if useMixer() {
+		softUnsupported()
+		return
+	}
+	cslot := C.ALuint(slot)
+	C.deleteAuxiliaryEffectSlots(1, &cslot)
+}
+
+func AuxiliaryEffectSloti(slot uint32, param uint32, value int32) {
+
+	if useMixer() {
+		softUnsupported()
+		return
+	}
+	C.auxiliaryEffectSloti(C.ALuint(slot), C.ALenum(param), C.ALint(value))
+}
+
+func AuxiliaryEffectSlotf(slot uint32, param uint32, value float32) {
+
+	if useMixer() {
+		softUnsupported()
+		return
+	}
+	C.auxiliaryEffectSlotf(C.ALuint(slot), C.ALenum(param), C.ALfloat(value))
+}
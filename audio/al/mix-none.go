@@ -0,0 +1,33 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux && !noopenal
+// +build linux,!noopenal
+
+package al
+
+import (
+	"errors"
+)
+
+// output is the audio output of the software mixer. On Linux oto requires cgo and
+// the ALSA development files, so it is only built with the "noopenal" build tag and
+// the OpenAL builds have no fallback output.
+type output struct{}
+
+// openOutput returns an error because the software mixer has no audio output in this build.
+func openOutput() (*output, error) {
+
+	return nil, errors.New("the OpenAL library is not available and the software mixer requires the noopenal build tag on Linux")
+}
+
+func (o *output) write(samples []byte) error {
+
+	return nil
+}
+
+func (o *output) close() error {
+
+	return nil
+}
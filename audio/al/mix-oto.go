@@ -0,0 +1,42 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build noopenal || !linux
+// +build noopenal !linux
+
+package al
+
+import (
+	"github.com/hajimehoshi/oto"
+)
+
+// output plays the mix of the software mixer through the oto library.
+type output struct {
+	ctx    *oto.Context // Audio output context
+	player *oto.Player  // Audio output stream
+}
+
+// openOutput opens the audio output of the software mixer.
+func openOutput() (*output, error) {
+
+	ctx, err := oto.NewContext(mixRate, 2, 2, mixBufferBytes)
+	if err != nil {
+		return nil, err
+	}
+	return &output{ctx: ctx, player: ctx.NewPlayer()}, nil
+}
+
+// write writes the specified 16 bits stereo samples, blocking while the output buffer is full.
+func (o *output) write(samples []byte) error {
+
+	_, err := o.player.Write(samples)
+	return err
+}
+
+// close closes the audio output.
+func (o *output) close() error {
+
+	o.player.Close()
+	return o.ctx.Close()
+}
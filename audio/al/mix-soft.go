@@ -0,0 +1,475 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package al
+
+import (
+	"math"
+)
+
+// Output format of the software mixer
+const (
+	mixRate        = 44100 // Sample rate in Hz
+	mixFrames      = 512   // Number of sample frames mixed at a time
+	mixBufferBytes = 8192  // Size of the output buffer in bytes (about 46ms)
+)
+
+// vec3 is a 3D vector of source and listener parameters.
+type vec3 [3]float32
+
+// listenerState contains the parameters of the listener.
+type listenerState struct {
+	gain     float32 // Gain applied to all sources
+	position vec3    // Position
+	velocity vec3    // Velocity (not used by the mixer)
+	at       vec3    // Direction the listener faces
+	up       vec3    // Up direction of the listener
+}
+
+// buffer contains the PCM data of a buffer converted to samples.
+type buffer struct {
+	samples  []float32 // Interleaved samples in [-1,1]
+	channels int       // Number of channels (1 or 2)
+	rate     int       // Sample rate in Hz
+	bits     int       // Bits per sample of the original data
+	size     int       // Size in bytes of the original data
+}
+
+// source contains the parameters and the playback state of a source.
+type source struct {
+	gain          float32  // Gain
+	minGain       float32  // Minimum gain after attenuation
+	maxGain       float32  // Maximum gain after attenuation
+	pitch         float32  // Pitch factor
+	position      vec3     // Position
+	direction     vec3     // Direction of the sound cone (zero for omnidirectional)
+	velocity      vec3     // Velocity (not used by the mixer)
+	relative      bool     // Position is relative to the listener
+	looping       bool     // Looping flag
+	coneInner     float32  // Inner cone angle in degrees
+	coneOuter     float32  // Outer cone angle in degrees
+	coneOuterGain float32  // Gain outside the outer cone
+	refDistance   float32  // Distance under which the source is not attenuated
+	rolloff       float32  // Rolloff factor of the distance attenuation
+	maxDistance   float32  // Distance beyond which the source is not attenuated any further
+	state         int32    // Playback state
+	queue         []uint32 // Queued buffers
+	static        bool     // The queue has a single buffer set with the Buffer parameter
+	current       int      // Index in the queue of the buffer being played
+	frame         float64  // Playback position in sample frames in the current buffer
+	startOffset   float64  // Offset where the next playback starts
+	startSeconds  bool     // The start offset is in seconds instead of sample frames
+}
+
+// mixer mixes the playing sources and writes the mix to the audio output.
+type mixer struct {
+	out  *output   // Audio output (see mix-oto.go)
+	done chan bool // Closed to end the mixing goroutine
+}
+
+// newMixer opens the audio output and starts the mixing goroutine.
+func newMixer() (*mixer, error) {
+
+	out, err := openOutput()
+	if err != nil {
+		return nil, err
+	}
+	m := new(mixer)
+	m.out = out
+	m.done = make(chan bool)
+	go m.run()
+	return m, nil
+}
+
+// close ends the mixing goroutine and closes the audio output.
+func (m *mixer) close() error {
+
+	close(m.done)
+	return m.out.close()
+}
+
+// run mixes the playing sources writing the mix to the audio output,
+// which blocks while its buffer is full, until the mixer is closed.
+func (m *mixer) run() {
+
+	mix := make([]float32, mixFrames*2)
+	out := make([]byte, mixFrames*4)
+	for {
+		select {
+		case <-m.done:
+			return
+		default:
+		}
+		mixSources(mix)
+		for i, v := range mix {
+			if v > 1 {
+				v = 1
+			} else if v < -1 {
+				v = -1
+			}
+			s := int16(v * math.MaxInt16)
+			out[2*i] = byte(s)
+			out[2*i+1] = byte(s >> 8)
+		}
+		if err := m.out.write(out); err != nil {
+			return
+		}
+	}
+}
+
+// mixSources mixes the next frames of all the playing sources into the specified stereo buffer.
+func mixSources(mix []float32) {
+
+	for i := range mix {
+		mix[i] = 0
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	for _, s := range sources {
+		if s.state == Playing {
+			s.mix(mix)
+		}
+	}
+}
+
+// setData converts the specified PCM data with the specified format to the samples of this buffer
+// and returns false if the format is not supported.
+func (b *buffer) setData(format uint32, pcm []byte, rate int) bool {
+
+	switch format {
+	case FormatMono8, FormatStereo8:
+		b.bits = 8
+		b.samples = make([]float32, len(pcm))
+		for i, v := range pcm {
+			b.samples[i] = (float32(v) - 128) / 128
+		}
+	case FormatMono16, FormatStereo16:
+		b.bits = 16
+		b.samples = make([]float32, len(pcm)/2)
+		for i := range b.samples {
+			v := int16(uint16(pcm[2*i]) | uint16(pcm[2*i+1])<<8)
+			b.samples[i] = float32(v) / 32768
+		}
+	default:
+		return false
+	}
+	b.channels = 1
+	if format == FormatStereo8 || format == FormatStereo16 {
+		b.channels = 2
+		b.samples = b.samples[:len(b.samples)/2*2]
+	}
+	b.rate = rate
+	b.size = len(pcm)
+	return true
+}
+
+// frames returns the number of sample frames of this buffer.
+func (b *buffer) frames() int {
+
+	if b.channels == 0 {
+		return 0
+	}
+	return len(b.samples) / b.channels
+}
+
+// sample returns the left and right samples at the specified position in sample
+// frames, interpolated linearly with the next frame. The mono samples are duplicated.
+func (b *buffer) sample(pos float64) (float32, float32) {
+
+	i := int(pos)
+	t := float32(pos - float64(i))
+	j := i + 1
+	if j >= b.frames() {
+		j = i
+	}
+	if b.channels == 1 {
+		v := b.samples[i] + (b.samples[j]-b.samples[i])*t
+		return v, v
+	}
+	l := b.samples[2*i] + (b.samples[2*j]-b.samples[2*i])*t
+	r := b.samples[2*i+1] + (b.samples[2*j+1]-b.samples[2*i+1])*t
+	return l, r
+}
+
+// newSource returns a pointer to a new source with the default OpenAL parameters.
+func newSource() *source {
+
+	s := new(source)
+	s.gain = 1
+	s.maxGain = 1
+	s.pitch = 1
+	s.coneInner = 360
+	s.coneOuter = 360
+	s.refDistance = 1
+	s.rolloff = 1
+	s.maxDistance = math.MaxFloat32
+	s.state = Initial
+	return s
+}
+
+// setBuffer sets the single buffer of this source, or removes all its buffers if zero.
+func (s *source) setBuffer(name uint32) {
+
+	s.queue = s.queue[:0]
+	s.static = name != 0
+	if name != 0 {
+		s.queue = append(s.queue, name)
+	}
+	s.current = 0
+	s.frame = 0
+}
+
+// play starts the playback from the start offset or resumes a paused playback.
+func (s *source) play() {
+
+	if s.state == Paused {
+		s.state = Playing
+		return
+	}
+	s.state = Playing
+	s.current = 0
+	s.frame = 0
+	s.seek(s.startOffset, s.startSeconds)
+	s.startOffset = 0
+}
+
+// stop stops the playback marking all the queued buffers as processed.
+func (s *source) stop() {
+
+	s.state = Stopped
+	s.current = len(s.queue)
+	s.frame = 0
+	s.startOffset = 0
+}
+
+// rewind sets the source to the initial state.
+func (s *source) rewind() {
+
+	s.state = Initial
+	s.current = 0
+	s.frame = 0
+	s.startOffset = 0
+}
+
+// processed returns the number of queued buffers already played.
+func (s *source) processed() int {
+
+	if s.looping && s.state != Stopped {
+		return 0
+	}
+	return s.current
+}
+
+// unqueue removes the specified number of processed buffers from the queue.
+func (s *source) unqueue(n int) {
+
+	s.queue = append(s.queue[:0], s.queue[n:]...)
+	s.current -= n
+	if len(s.queue) == 0 {
+		s.static = false
+	}
+}
+
+// setOffset sets the playback position in seconds or sample frames from the start of the queue.
+// If the source is not playing or paused the position is applied when it starts playing.
+func (s *source) setOffset(offset float64, seconds bool) {
+
+	if s.state != Playing && s.state != Paused {
+		s.startOffset = offset
+		s.startSeconds = seconds
+		return
+	}
+	s.current = 0
+	s.frame = 0
+	s.seek(offset, seconds)
+}
+
+// seek advances the playback position by the specified offset in seconds or sample frames.
+func (s *source) seek(offset float64, seconds bool) {
+
+	for ; s.current < len(s.queue); s.current++ {
+		b := buffers[s.queue[s.current]]
+		if b == nil {
+			continue
+		}
+		frames := float64(b.frames())
+		length := frames
+		if seconds {
+			length = frames / float64(b.rate)
+		}
+		if offset < length {
+			if seconds {
+				offset *= float64(b.rate)
+			}
+			s.frame = offset
+			return
+		}
+		offset -= length
+	}
+}
+
+// offset returns the playback position in seconds or sample frames from the start of the queue.
+func (s *source) offset(seconds bool) float64 {
+
+	if s.state != Playing && s.state != Paused {
+		return 0
+	}
+	var offset float64
+	for i := 0; i <= s.current && i < len(s.queue); i++ {
+		b := buffers[s.queue[i]]
+		if b == nil {
+			continue
+		}
+		frames := float64(b.frames())
+		if i == s.current {
+			frames = s.frame
+		}
+		if seconds && b.rate > 0 {
+			frames /= float64(b.rate)
+		}
+		offset += frames
+	}
+	return offset
+}
+
+// next advances the playback to the next queued buffer and returns false if the playback stopped.
+func (s *source) next() bool {
+
+	s.frame = 0
+	s.current++
+	if s.current < len(s.queue) {
+		return true
+	}
+	if s.looping {
+		for _, name := range s.queue {
+			if b := buffers[name]; b != nil && b.frames() > 0 {
+				s.current = 0
+				return true
+			}
+		}
+	}
+	s.state = Stopped
+	return false
+}
+
+// mix adds the next frames of this source to the specified stereo buffer.
+func (s *source) mix(mix []float32) {
+
+	var left, right float32
+	channels := 0
+	for i := 0; i < len(mix); {
+		// The source stops when it runs out of queued buffers
+		if s.current >= len(s.queue) {
+			s.stop()
+			return
+		}
+		b := buffers[s.queue[s.current]]
+		if b == nil || int(s.frame) >= b.frames() {
+			if !s.next() {
+				return
+			}
+			continue
+		}
+		if b.channels != channels {
+			channels = b.channels
+			left, right = s.gains(channels)
+		}
+		l, r := b.sample(s.frame)
+		mix[i] += l * left
+		mix[i+1] += r * right
+		i += 2
+		s.frame += float64(s.pitch) * float64(b.rate) / mixRate
+	}
+}
+
+// gains returns the gains of the left and right output channels for buffers with the specified
+// number of channels. Mono buffers are attenuated by distance and by the sound cone and are
+// panned from the position of the source relative to the listener. Stereo buffers are only
+// affected by the source and listener gains, as in OpenAL.
+func (s *source) gains(channels int) (float32, float32) {
+
+	gain := s.gain
+	if channels != 1 {
+		gain = clamp(gain, s.minGain, s.maxGain) * listener.gain
+		return gain, gain
+	}
+
+	// Position of the source relative to the listener
+	rel := s.position
+	if !s.relative {
+		rel = sub(s.position, listener.position)
+	}
+	dist := length(rel)
+
+	// Inverse distance clamped attenuation
+	d := clamp(dist, s.refDistance, s.maxDistance)
+	if den := s.refDistance + s.rolloff*(d-s.refDistance); den > 0 {
+		gain *= s.refDistance / den
+	}
+
+	// Attenuation outside the inner cone from the angle between the source direction and the listener
+	if length(s.direction) > 0 && dist > 0 {
+		cos := -dot(s.direction, rel) / (length(s.direction) * dist)
+		angle := 2 * float32(math.Acos(float64(clamp(cos, -1, 1)))) * 180 / math.Pi
+		if angle >= s.coneOuter {
+			gain *= s.coneOuterGain
+		} else if angle > s.coneInner {
+			t := (angle - s.coneInner) / (s.coneOuter - s.coneInner)
+			gain *= 1 + (s.coneOuterGain-1)*t
+		}
+	}
+	gain = clamp(gain, s.minGain, s.maxGain) * listener.gain
+
+	// Equal power panning from the projection of the direction to the source on the right
+	// axis of the listener. The relative positions are in the coordinates of the listener.
+	var pan float32
+	if dist > 0 {
+		if s.relative {
+			pan = rel[0] / dist
+		} else {
+			right := cross(listener.at, listener.up)
+			if l := length(right); l > 0 {
+				pan = dot(rel, right) / (l * dist)
+			}
+		}
+	}
+	angle := float64(pan+1) * math.Pi / 4
+	return gain * float32(math.Cos(angle)), gain * float32(math.Sin(angle))
+}
+
+// clamp returns the specified value clamped to the specified interval.
+func clamp(v, min, max float32) float32 {
+
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// sub returns the difference of the specified vectors.
+func sub(a, b vec3) vec3 {
+
+	return vec3{a[0] - b[0], a[1] - b[1], a[2] - b[2]}
+}
+
+// dot returns the dot product of the specified vectors.
+func dot(a, b vec3) float32 {
+
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+// cross returns the cross product of the specified vectors.
+func cross(a, b vec3) vec3 {
+
+	return vec3{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+// length returns the length of the specified vector.
+func length(v vec3) float32 {
+
+	return float32(math.Sqrt(float64(dot(v, v))))
+}
@@ -11,6 +11,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
+	"strings"
 	"unsafe"
 
 	"github.com/g3n/engine/audio/al"
@@ -36,10 +38,12 @@ type AudioInfo struct {
 
 // AudioFile represents an audio file
 type AudioFile struct {
-	wavef   *os.File  // Pointer to wave file opened filed (nil for vorbis)
-	vorbisf *ov.File  // Pointer to vorbis file structure (nil for wave)
-	info    AudioInfo // Audio information structure
-	looping bool      // Looping flag
+	wavef     *os.File  // Pointer to wave file opened filed (nil for vorbis)
+	vorbisf   *ov.File  // Pointer to vorbis file structure (nil for wave)
+	info      AudioInfo // Audio information structure
+	looping   bool      // Looping flag
+	loopStart int64     // Sample frame where the loop starts
+	loopEnd   int64     // Sample frame where the loop ends (0 for the end of the stream)
 }
 
 // NewAudioFile creates and returns a pointer to a new audio file object and an error
@@ -75,62 +79,80 @@ func (af *AudioFile) Close() error {
 	return ov.Clear(af.vorbisf)
 }
 
-// Read reads decoded data from the audio file
+// Read reads decoded data from the audio file.
+// When looping, the data from the loop start is read after the loop end.
 func (af *AudioFile) Read(pdata unsafe.Pointer, nbytes int) (int, error) {
 
 	// Slice to access buffer
 	bs := (*[1 << 30]byte)(pdata)[0:nbytes:nbytes]
 
-	// Reads wave file directly
-	if af.wavef != nil {
-		n, err := af.wavef.Read(bs)
-		if err != nil {
-			return 0, err
-		}
-		if !af.looping {
-			return n, nil
-		}
-		if n == nbytes {
-			return n, nil
-		}
-		// EOF reached. Position file at the beginning
-		_, err = af.wavef.Seek(int64(waveHeaderSize), 0)
-		if err != nil {
-			return 0, nil
-		}
-		// Reads next data into the remaining buffer space
-		n2, err := af.wavef.Read(bs[n:])
-		if err != nil {
-			return 0, err
+	read := 0
+	wrapped := false
+	for read < nbytes {
+		chunk := nbytes - read
+		if af.looping {
+			pos, err := af.Tell()
+			if err != nil {
+				return 0, err
+			}
+			// Loop end reached. Position file at the loop start
+			left := int(af.loopEndFrame()-pos) * af.frameSize()
+			if left <= 0 {
+				if wrapped {
+					break
+				}
+				err = af.SeekFrame(af.loopStart)
+				if err != nil {
+					return 0, err
+				}
+				wrapped = true
+				continue
+			}
+			if left < chunk {
+				chunk = left
+			}
 		}
-		return n + n2, err
-	}
-
-	// Decodes Ogg vorbis
-	decoded := 0
-	for decoded < nbytes {
-		n, _, err := ov.Read(af.vorbisf, unsafe.Pointer(&bs[decoded]), nbytes-decoded, false, 2, true)
-		// Error
+		n, err := af.readData(bs[read : read+chunk])
 		if err != nil {
 			return 0, err
 		}
 		// EOF
 		if n == 0 {
-			if !af.looping {
+			if !af.looping || wrapped {
 				break
 			}
-			// Position file at the beginning
-			err = ov.PcmSeek(af.vorbisf, 0)
+			err = af.SeekFrame(af.loopStart)
 			if err != nil {
 				return 0, err
 			}
+			wrapped = true
+			continue
 		}
-		decoded += n
+		wrapped = false
+		read += n
 	}
-	if nbytes > 0 && decoded == 0 {
+	if nbytes > 0 && read == 0 {
 		return 0, io.EOF
 	}
-	return decoded, nil
+	return read, nil
+}
+
+// readData reads the next decoded data into the specified buffer
+// and returns the number of bytes read, which is zero at the end of the file.
+func (af *AudioFile) readData(bs []byte) (int, error) {
+
+	// Reads wave file directly
+	if af.wavef != nil {
+		n, err := af.wavef.Read(bs)
+		if err == io.EOF {
+			return 0, nil
+		}
+		return n, err
+	}
+
+	// Decodes Ogg vorbis
+	n, _, err := ov.Read(af.vorbisf, unsafe.Pointer(&bs[0]), len(bs), false, 2, true)
+	return n, err
 }
 
 // Seek sets the file reading position relative to the origin
@@ -143,6 +165,42 @@ func (af *AudioFile) Seek(pos uint) error {
 	return ov.PcmSeek(af.vorbisf, int64(pos))
 }
 
+// SeekFrame sets the file reading position to the specified sample frame
+// (a sample for each channel) from the beginning of the audio data.
+func (af *AudioFile) SeekFrame(frame int64) error {
+
+	if af.wavef != nil {
+		_, err := af.wavef.Seek(int64(waveHeaderSize)+frame*int64(af.frameSize()), 0)
+		return err
+	}
+	return ov.PcmSeek(af.vorbisf, frame)
+}
+
+// SeekTime sets the file reading position to the specified time in seconds.
+func (af *AudioFile) SeekTime(seconds float64) error {
+
+	return af.SeekFrame(int64(seconds * float64(af.info.SampleRate)))
+}
+
+// Tell returns the current file reading position in sample frames from the beginning of the audio data.
+func (af *AudioFile) Tell() (int64, error) {
+
+	if af.vorbisf != nil {
+		return ov.PcmTell(af.vorbisf)
+	}
+	pos, err := af.wavef.Seek(0, 1)
+	if err != nil {
+		return 0, err
+	}
+	return (pos - waveHeaderSize) / int64(af.frameSize()), nil
+}
+
+// Frames returns the total number of sample frames of the audio data.
+func (af *AudioFile) Frames() int64 {
+
+	return int64(af.info.DataSize / af.frameSize())
+}
+
 // Info returns the audio info structure for this audio file
 func (af *AudioFile) Info() AudioInfo {
 
@@ -163,6 +221,51 @@ func (af *AudioFile) CurrentTime() float64 {
 	return float64(pos) / float64(af.info.BytesSec)
 }
 
+// SetLoopPoints sets the sample frames where the loop starts and ends when looping.
+// An end of zero is the end of the audio data. The loop points of Ogg Vorbis files
+// are initialized from the LOOPSTART and LOOPLENGTH or LOOPEND comments if present.
+func (af *AudioFile) SetLoopPoints(start, end int64) error {
+
+	if start < 0 || end < 0 || end > af.Frames() || (end > 0 && start >= end) || start >= af.Frames() {
+		return fmt.Errorf("Invalid loop points")
+	}
+	af.loopStart = start
+	af.loopEnd = end
+	return nil
+}
+
+// LoopPoints returns the sample frames where the loop starts and ends when looping.
+func (af *AudioFile) LoopPoints() (int64, int64) {
+
+	return af.loopStart, af.loopEnd
+}
+
+// loopEndFrame returns the sample frame where the loop ends.
+func (af *AudioFile) loopEndFrame() int64 {
+
+	if af.loopEnd > 0 {
+		return af.loopEnd
+	}
+	return af.Frames()
+}
+
+// wrapFrame returns the position in the audio data of the specified sample frame
+// counted from the beginning without returning to the loop start when looping.
+func (af *AudioFile) wrapFrame(frame int64) int64 {
+
+	end := af.loopEndFrame()
+	if !af.looping || frame < end || end <= af.loopStart {
+		return frame
+	}
+	return af.loopStart + (frame-end)%(end-af.loopStart)
+}
+
+// frameSize returns the size in bytes of a sample frame.
+func (af *AudioFile) frameSize() int {
+
+	return af.info.Channels * af.info.BitsSample / 8
+}
+
 // Looping returns the current looping state of this audio file
 func (af *AudioFile) Looping() bool {
 
@@ -293,5 +396,40 @@ func (af *AudioFile) openVorbis(filename string) error {
 	af.info.Channels = info.Channels
 	af.info.DataSize = int(totalSamples) * info.Channels * 2
 	af.info.TotalTime = timeTotal
+	af.readLoopComments()
 	return nil
 }
+
+// readLoopComments sets the loop points from the LOOPSTART and LOOPLENGTH or LOOPEND
+// comments, in sample frames, of the opened Ogg Vorbis file if present.
+func (af *AudioFile) readLoopComments() {
+
+	comments, err := ov.Comments(af.vorbisf, -1)
+	if err != nil {
+		return
+	}
+	var start, length, end int64
+	for _, c := range comments {
+		parts := strings.SplitN(c, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		v, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil {
+			continue
+		}
+		switch strings.ToUpper(parts[0]) {
+		case "LOOPSTART":
+			start = v
+		case "LOOPLENGTH":
+			length = v
+		case "LOOPEND":
+			end = v
+		}
+	}
+	if length > 0 {
+		end = start + length
+	}
+	// Invalid loop points are ignored
+	af.SetLoopPoints(start, end)
+}
@@ -0,0 +1,33 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package audio
+
+import (
+	"time"
+)
+
+// Start time of the shared audio clock
+var clockStart = time.Now()
+
+// ClockTime returns the current time in seconds of the shared audio clock
+// used to schedule the playback of players (see Player.PlayAt).
+// The clock starts when the package is initialized and never pauses,
+// so the application can compute the start times of sounds which must be
+// synchronized, for example with animation events or music beats.
+func ClockTime() float64 {
+
+	return time.Since(clockStart).Seconds()
+}
+
+// clockDelay returns the duration from now until the specified clock time
+// or zero if the time has already passed.
+func clockDelay(t float64) time.Duration {
+
+	delay := time.Duration((t - ClockTime()) * float64(time.Second))
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
@@ -0,0 +1,396 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !wasm
+// +build !wasm
+
+package audio
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/audio/al"
+)
+
+// IEffect is the interface for all audio effects
+type IEffect interface {
+	GetEffect() *Effect
+}
+
+// Effect is an audio effect loaded in an OpenAL auxiliary effect slot.
+// The players send their sound to the effect with Player.AddEffect and
+// the output of the effect is mixed with the direct sound of the players.
+// The effects require the OpenAL EFX extension.
+type Effect struct {
+	effect uint32  // OpenAL effect name
+	slot   uint32  // OpenAL auxiliary effect slot name
+	gain   float32 // Output gain of the effect slot
+}
+
+// ReverbParams describes the parameters of a Reverb effect.
+// The gains are linear factors and the times are in seconds.
+type ReverbParams struct {
+	Density             float32 // Modal density of the late reverberation [0, 1]
+	Diffusion           float32 // Echo density of the late reverberation [0, 1]
+	Gain                float32 // Master gain of the reflected sound [0, 1]
+	GainHF              float32 // High frequencies gain of the reflected sound [0, 1]
+	DecayTime           float32 // Reverberation decay time [0.1, 20]
+	DecayHFRatio        float32 // Ratio of the high frequencies decay time to the decay time [0.1, 2]
+	ReflectionsGain     float32 // Gain of the early reflections [0, 3.16]
+	ReflectionsDelay    float32 // Delay of the early reflections [0, 0.3]
+	LateReverbGain      float32 // Gain of the late reverberation [0, 10]
+	LateReverbDelay     float32 // Delay of the late reverberation from the early reflections [0, 0.1]
+	AirAbsorptionGainHF float32 // High frequencies air absorption per meter [0.892, 1]
+	RoomRolloffFactor   float32 // Distance attenuation factor of the reflected sound [0, 10]
+	DecayHFLimit        bool    // Limits the high frequencies decay time by the air absorption
+}
+
+// Reverb presets
+var (
+	ReverbGeneric    = ReverbParams{1, 1, 0.3162, 0.8913, 1.49, 0.83, 0.05, 0.007, 1.2589, 0.011, 0.9943, 0, true}
+	ReverbRoom       = ReverbParams{0.4287, 1, 0.3162, 0.5929, 0.4, 0.83, 0.1503, 0.002, 1.0629, 0.003, 0.9943, 0, true}
+	ReverbBathroom   = ReverbParams{0.1715, 1, 0.3162, 0.2512, 1.49, 0.54, 0.6531, 0.007, 3.2734, 0.011, 0.9943, 0, true}
+	ReverbHall       = ReverbParams{1, 1, 0.3162, 0.5623, 3.92, 0.7, 0.2427, 0.02, 0.9977, 0.029, 0.9943, 0, true}
+	ReverbCave       = ReverbParams{1, 1, 0.3162, 1, 2.91, 1.3, 0.5, 0.015, 0.7063, 0.022, 0.9943, 0, false}
+	ReverbArena      = ReverbParams{1, 1, 0.3162, 0.4477, 7.24, 0.33, 0.2612, 0.02, 1.0186, 0.03, 0.9943, 0, true}
+	ReverbHangar     = ReverbParams{1, 1, 0.3162, 0.3162, 10.05, 0.23, 0.5, 0.02, 1.256, 0.03, 0.9943, 0, true}
+	ReverbUnderwater = ReverbParams{0.3645, 1, 0.3162, 0.01, 1.49, 0.1, 0.5963, 0.007, 7.0795, 0.011, 0.9943, 0, true}
+)
+
+// Reverb is an effect which simulates the reflections of the sound in an environment
+type Reverb struct {
+	Effect
+	params ReverbParams
+}
+
+// EchoParams describes the parameters of an Echo effect
+type EchoParams struct {
+	Delay    float32 // Delay of the first echo in seconds [0, 0.207]
+	LRDelay  float32 // Delay between the first and second echoes in seconds [0, 0.404]
+	Damping  float32 // High frequencies damping of the echoes [0, 0.99]
+	Feedback float32 // Gain of each echo relative to the previous one [0, 1]
+	Spread   float32 // Left to right spread of the echoes [-1, 1]
+}
+
+// DefaultEcho contains the default echo parameters
+var DefaultEcho = EchoParams{0.1, 0.1, 0.5, 0.5, -1}
+
+// Echo is an effect which repeats the sound with a delay
+type Echo struct {
+	Effect
+	params EchoParams
+}
+
+// Chorus waveforms
+const (
+	ChorusSinusoid = 0
+	ChorusTriangle = 1
+)
+
+// ChorusParams describes the parameters of a Chorus effect
+type ChorusParams struct {
+	Waveform int     // Waveform of the delay modulation (ChorusSinusoid or ChorusTriangle)
+	Phase    int     // Phase difference in degrees between the left and right modulations [-180, 180]
+	Rate     float32 // Modulation frequency in Hz [0, 10]
+	Depth    float32 // Modulation depth [0, 1]
+	Feedback float32 // Amount of the output fed back to the input [-1, 1]
+	Delay    float32 // Average delay in seconds [0, 0.016]
+}
+
+// DefaultChorus contains the default chorus parameters
+var DefaultChorus = ChorusParams{ChorusTriangle, 90, 1.1, 0.1, 0.25, 0.016}
+
+// Chorus is an effect which mixes the sound with modulated delayed copies of it
+type Chorus struct {
+	Effect
+	params ChorusParams
+}
+
+// DistortionParams describes the parameters of a Distortion effect
+type DistortionParams struct {
+	Edge          float32 // Shape of the distortion [0, 1]
+	Gain          float32 // Output gain [0.01, 1]
+	LowpassCutoff float32 // Cutoff frequency in Hz of the filter applied before the distortion [80, 24000]
+	EQCenter      float32 // Center frequency in Hz of the post distortion band [80, 24000]
+	EQBandwidth   float32 // Bandwidth in Hz of the post distortion band [80, 24000]
+}
+
+// DefaultDistortion contains the default distortion parameters
+var DefaultDistortion = DistortionParams{0.2, 0.05, 8000, 3600, 3600}
+
+// Distortion is an effect which clips the sound
+type Distortion struct {
+	Effect
+	params DistortionParams
+}
+
+// NewReverb creates and returns a pointer to a new Reverb effect with the specified parameters.
+// It returns an error if the current audio device does not support effects.
+func NewReverb(params ReverbParams) (*Reverb, error) {
+
+	r := new(Reverb)
+	err := r.Effect.init(al.AL_EFFECT_REVERB)
+	if err != nil {
+		return nil, err
+	}
+	r.SetParams(params)
+	return r, nil
+}
+
+// Params returns the current parameters of this reverb
+func (r *Reverb) Params() ReverbParams {
+
+	return r.params
+}
+
+// SetParams sets the parameters of this reverb
+func (r *Reverb) SetParams(params ReverbParams) {
+
+	r.params = params
+	al.Effectf(r.effect, al.AL_REVERB_DENSITY, params.Density)
+	al.Effectf(r.effect, al.AL_REVERB_DIFFUSION, params.Diffusion)
+	al.Effectf(r.effect, al.AL_REVERB_GAIN, params.Gain)
+	al.Effectf(r.effect, al.AL_REVERB_GAINHF, params.GainHF)
+	al.Effectf(r.effect, al.AL_REVERB_DECAY_TIME, params.DecayTime)
+	al.Effectf(r.effect, al.AL_REVERB_DECAY_HFRATIO, params.DecayHFRatio)
+	al.Effectf(r.effect, al.AL_REVERB_REFLECTIONS_GAIN, params.ReflectionsGain)
+	al.Effectf(r.effect, al.AL_REVERB_REFLECTIONS_DELAY, params.ReflectionsDelay)
+	al.Effectf(r.effect, al.AL_REVERB_LATE_REVERB_GAIN, params.LateReverbGain)
+	al.Effectf(r.effect, al.AL_REVERB_LATE_REVERB_DELAY, params.LateReverbDelay)
+	al.Effectf(r.effect, al.AL_REVERB_AIR_ABSORPTION_GAINHF, params.AirAbsorptionGainHF)
+	al.Effectf(r.effect, al.AL_REVERB_ROOM_ROLLOFF_FACTOR, params.RoomRolloffFactor)
+	al.Effecti(r.effect, al.AL_REVERB_DECAY_HFLIMIT, alBool(params.DecayHFLimit))
+	r.reload()
+}
+
+// NewEcho creates and returns a pointer to a new Echo effect with the specified parameters.
+// It returns an error if the current audio device does not support effects.
+func NewEcho(params EchoParams) (*Echo, error) {
+
+	e := new(Echo)
+	err := e.Effect.init(al.AL_EFFECT_ECHO)
+	if err != nil {
+		return nil, err
+	}
+	e.SetParams(params)
+	return e, nil
+}
+
+// Params returns the current parameters of this echo
+func (e *Echo) Params() EchoParams {
+
+	return e.params
+}
+
+// SetParams sets the parameters of this echo
+func (e *Echo) SetParams(params EchoParams) {
+
+	e.params = params
+	al.Effectf(e.effect, al.AL_ECHO_DELAY, params.Delay)
+	al.Effectf(e.effect, al.AL_ECHO_LRDELAY, params.LRDelay)
+	al.Effectf(e.effect, al.AL_ECHO_DAMPING, params.Damping)
+	al.Effectf(e.effect, al.AL_ECHO_FEEDBACK, params.Feedback)
+	al.Effectf(e.effect, al.AL_ECHO_SPREAD, params.Spread)
+	e.reload()
+}
+
+// NewChorus creates and returns a pointer to a new Chorus effect with the specified parameters.
+// It returns an error if the current audio device does not support effects.
+func NewChorus(params ChorusParams) (*Chorus, error) {
+
+	c := new(Chorus)
+	err := c.Effect.init(al.AL_EFFECT_CHORUS)
+	if err != nil {
+		return nil, err
+	}
+	c.SetParams(params)
+	return c, nil
+}
+
+// Params returns the current parameters of this chorus
+func (c *Chorus) Params() ChorusParams {
+
+	return c.params
+}
+
+// SetParams sets the parameters of this chorus
+func (c *Chorus) SetParams(params ChorusParams) {
+
+	c.params = params
+	al.Effecti(c.effect, al.AL_CHORUS_WAVEFORM, int32(params.Waveform))
+	al.Effecti(c.effect, al.AL_CHORUS_PHASE, int32(params.Phase))
+	al.Effectf(c.effect, al.AL_CHORUS_RATE, params.Rate)
+	al.Effectf(c.effect, al.AL_CHORUS_DEPTH, params.Depth)
+	al.Effectf(c.effect, al.AL_CHORUS_FEEDBACK, params.Feedback)
+	al.Effectf(c.effect, al.AL_CHORUS_DELAY, params.Delay)
+	c.reload()
+}
+
+// NewDistortion creates and returns a pointer to a new Distortion effect with the specified parameters.
+// It returns an error if the current audio device does not support effects.
+func NewDistortion(params DistortionParams) (*Distortion, error) {
+
+	d := new(Distortion)
+	err := d.Effect.init(al.AL_EFFECT_DISTORTION)
+	if err != nil {
+		return nil, err
+	}
+	d.SetParams(params)
+	return d, nil
+}
+
+// Params returns the current parameters of this distortion
+func (d *Distortion) Params() DistortionParams {
+
+	return d.params
+}
+
+// SetParams sets the parameters of this distortion
+func (d *Distortion) SetParams(params DistortionParams) {
+
+	d.params = params
+	al.Effectf(d.effect, al.AL_DISTORTION_EDGE, params.Edge)
+	al.Effectf(d.effect, al.AL_DISTORTION_GAIN, params.Gain)
+	al.Effectf(d.effect, al.AL_DISTORTION_LOWPASS_CUTOFF, params.LowpassCutoff)
+	al.Effectf(d.effect, al.AL_DISTORTION_EQCENTER, params.EQCenter)
+	al.Effectf(d.effect, al.AL_DISTORTION_EQBANDWIDTH, params.EQBandwidth)
+	d.reload()
+}
+
+// init generates the OpenAL effect of the specified type and the slot where it is loaded.
+func (e *Effect) init(etype int32) error {
+
+	if !al.EFXSupported() {
+		return fmt.Errorf("audio effects not supported by the audio device")
+	}
+	e.effect = al.GenEffect()
+	al.Effecti(e.effect, al.AL_EFFECT_TYPE, etype)
+	e.slot = al.GenAuxiliaryEffectSlot()
+	e.gain = 1
+	return nil
+}
+
+// reload loads the effect in its slot again after a change of its parameters,
+// as the slot keeps a copy of the parameters loaded.
+func (e *Effect) reload() {
+
+	al.AuxiliaryEffectSloti(e.slot, al.AL_EFFECTSLOT_EFFECT, int32(e.effect))
+}
+
+// GetEffect satisfies the IEffect interface
+func (e *Effect) GetEffect() *Effect {
+
+	return e
+}
+
+// Gain returns the output gain of this effect
+func (e *Effect) Gain() float32 {
+
+	return e.gain
+}
+
+// SetGain sets the output gain of this effect in the range [0, 1]
+func (e *Effect) SetGain(gain float32) {
+
+	e.gain = gain
+	al.AuxiliaryEffectSlotf(e.slot, al.AL_EFFECTSLOT_GAIN, gain)
+}
+
+// Dispose releases the OpenAL resources of this effect.
+// The effect must be removed from all the players before being disposed.
+func (e *Effect) Dispose() {
+
+	al.DeleteAuxiliaryEffectSlot(e.slot)
+	al.DeleteEffect(e.effect)
+}
+
+// Filter is an OpenAL filter which can be applied to the direct sound of a player
+// (see Player.SetDirectFilter) or to the sound sent to an effect (see Player.AddEffect).
+// The filter parameters are copied when the filter is applied, so changes to the
+// filter only affect the players to which it is applied afterwards.
+type Filter struct {
+	filter uint32  // OpenAL filter name
+	ftype  int32   // OpenAL filter type
+	gain   float32 // Overall gain
+	gainLF float32 // Low frequencies gain
+	gainHF float32 // High frequencies gain
+}
+
+// NewLowPass creates and returns a pointer to a new low-pass filter with the
+// specified overall gain and high frequencies gain in the range [0, 1].
+func NewLowPass(gain, gainHF float32) (*Filter, error) {
+
+	return newFilter(al.AL_FILTER_LOWPASS, gain, 1, gainHF)
+}
+
+// NewHighPass creates and returns a pointer to a new high-pass filter with the
+// specified overall gain and low frequencies gain in the range [0, 1].
+func NewHighPass(gain, gainLF float32) (*Filter, error) {
+
+	return newFilter(al.AL_FILTER_HIGHPASS, gain, gainLF, 1)
+}
+
+// NewBandPass creates and returns a pointer to a new band-pass filter with the
+// specified overall, low frequencies and high frequencies gains in the range [0, 1].
+func NewBandPass(gain, gainLF, gainHF float32) (*Filter, error) {
+
+	return newFilter(al.AL_FILTER_BANDPASS, gain, gainLF, gainHF)
+}
+
+// newFilter creates and returns a pointer to a new filter of the specified type
+func newFilter(ftype int32, gain, gainLF, gainHF float32) (*Filter, error) {
+
+	if !al.EFXSupported() {
+		return nil, fmt.Errorf("audio filters not supported by the audio device")
+	}
+	f := new(Filter)
+	f.filter = al.GenFilter()
+	f.ftype = ftype
+	al.Filteri(f.filter, al.AL_FILTER_TYPE, ftype)
+	f.SetGains(gain, gainLF, gainHF)
+	return f, nil
+}
+
+// Gains returns the overall, low frequencies and high frequencies gains of this filter
+func (f *Filter) Gains() (float32, float32, float32) {
+
+	return f.gain, f.gainLF, f.gainHF
+}
+
+// SetGains sets the overall, low frequencies and high frequencies gains of this filter.
+// The low frequencies gain is ignored by low-pass filters and the high frequencies
+// gain is ignored by high-pass filters.
+func (f *Filter) SetGains(gain, gainLF, gainHF float32) {
+
+	f.gain = gain
+	f.gainLF = gainLF
+	f.gainHF = gainHF
+	switch f.ftype {
+	case al.AL_FILTER_LOWPASS:
+		al.Filterf(f.filter, al.AL_LOWPASS_GAIN, gain)
+		al.Filterf(f.filter, al.AL_LOWPASS_GAINHF, gainHF)
+	case al.AL_FILTER_HIGHPASS:
+		al.Filterf(f.filter, al.AL_HIGHPASS_GAIN, gain)
+		al.Filterf(f.filter, al.AL_HIGHPASS_GAINLF, gainLF)
+	case al.AL_FILTER_BANDPASS:
+		al.Filterf(f.filter, al.AL_BANDPASS_GAIN, gain)
+		al.Filterf(f.filter, al.AL_BANDPASS_GAINLF, gainLF)
+		al.Filterf(f.filter, al.AL_BANDPASS_GAINHF, gainHF)
+	}
+}
+
+// Dispose releases the OpenAL resources of this filter
+func (f *Filter) Dispose() {
+
+	al.DeleteFilter(f.filter)
+}
+
+// alBool converts a boolean to an OpenAL boolean value
+func alBool(v bool) int32 {
+
+	if v {
+		return al.True
+	}
+	return al.False
+}
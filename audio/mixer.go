@@ -0,0 +1,227 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package audio
+
+import (
+	"sync"
+	"time"
+)
+
+// Names of the groups created by NewMixer
+const (
+	GroupMusic = "music"
+	GroupSFX   = "sfx"
+	GroupVoice = "voice"
+)
+
+// Mixer controls the volume of groups of players and the master volume
+// applied to all of them. Each player can belong to one group of a mixer
+// (see Player.SetGroup) and players without a group are only affected by
+// the master volume of the default mixer.
+// The volume changes can be ramped and are applied smoothly by the players
+// while they are playing. A Mixer is safe for concurrent use.
+type Mixer struct {
+	sync.Mutex                        // Protects the mixer and groups state
+	master     volumeRamp             // Master volume
+	groups     map[string]*MixerGroup // Groups by name
+	names      []string               // Group names in creation order
+	solos      int                    // Number of groups in solo
+}
+
+// MixerGroup is a named group of players of a Mixer with its own volume, mute and solo states.
+type MixerGroup struct {
+	mixer  *Mixer     // Mixer which contains this group
+	name   string     // Group name
+	volume volumeRamp // Group volume
+	mute   bool       // Group is muted
+	solo   bool       // Group is in solo
+}
+
+// volumeRamp is a volume which changes linearly to a target volume during a time interval.
+type volumeRamp struct {
+	from     float32       // Volume at the start of the ramp
+	to       float32       // Target volume
+	start    time.Time     // Start time of the ramp
+	duration time.Duration // Duration of the ramp
+}
+
+// Default mixer used by the players without a group
+var defaultMixer = NewMixer()
+
+// DefaultMixer returns the default mixer.
+func DefaultMixer() *Mixer {
+
+	return defaultMixer
+}
+
+// NewMixer creates and returns a pointer to a new Mixer with
+// the music, sfx and voice groups and all volumes set to 1.
+func NewMixer() *Mixer {
+
+	m := new(Mixer)
+	m.master.set(1, 0)
+	m.groups = make(map[string]*MixerGroup)
+	m.AddGroup(GroupMusic)
+	m.AddGroup(GroupSFX)
+	m.AddGroup(GroupVoice)
+	return m
+}
+
+// AddGroup adds a new group with the specified name and returns its pointer.
+// If the group already exists returns the existent group.
+func (m *Mixer) AddGroup(name string) *MixerGroup {
+
+	m.Lock()
+	defer m.Unlock()
+	if g, ok := m.groups[name]; ok {
+		return g
+	}
+	g := &MixerGroup{mixer: m, name: name}
+	g.volume.set(1, 0)
+	m.groups[name] = g
+	m.names = append(m.names, name)
+	return g
+}
+
+// Group returns the pointer to the group with the specified name or nil if not found.
+func (m *Mixer) Group(name string) *MixerGroup {
+
+	m.Lock()
+	defer m.Unlock()
+	return m.groups[name]
+}
+
+// Groups returns the names of the groups of this mixer in creation order.
+func (m *Mixer) Groups() []string {
+
+	m.Lock()
+	defer m.Unlock()
+	return append([]string(nil), m.names...)
+}
+
+// SetMasterVolume sets the master volume changing it linearly from the current
+// volume during the specified ramp duration (zero for an immediate change).
+func (m *Mixer) SetMasterVolume(volume float32, ramp time.Duration) {
+
+	m.Lock()
+	defer m.Unlock()
+	m.master.set(volume, ramp)
+}
+
+// MasterVolume returns the master volume (the target volume if ramping).
+func (m *Mixer) MasterVolume() float32 {
+
+	m.Lock()
+	defer m.Unlock()
+	return m.master.to
+}
+
+// Name returns the name of this group.
+func (g *MixerGroup) Name() string {
+
+	return g.name
+}
+
+// SetVolume sets the volume of this group changing it linearly from the current
+// volume during the specified ramp duration (zero for an immediate change).
+func (g *MixerGroup) SetVolume(volume float32, ramp time.Duration) {
+
+	g.mixer.Lock()
+	defer g.mixer.Unlock()
+	g.volume.set(volume, ramp)
+}
+
+// Volume returns the volume of this group (the target volume if ramping).
+func (g *MixerGroup) Volume() float32 {
+
+	g.mixer.Lock()
+	defer g.mixer.Unlock()
+	return g.volume.to
+}
+
+// SetMute sets the muted state of this group.
+func (g *MixerGroup) SetMute(mute bool) {
+
+	g.mixer.Lock()
+	defer g.mixer.Unlock()
+	g.mute = mute
+}
+
+// Muted returns the muted state of this group.
+func (g *MixerGroup) Muted() bool {
+
+	g.mixer.Lock()
+	defer g.mixer.Unlock()
+	return g.mute
+}
+
+// SetSolo sets the solo state of this group.
+// While any group of the mixer is in solo, the groups not in solo are silenced.
+func (g *MixerGroup) SetSolo(solo bool) {
+
+	g.mixer.Lock()
+	defer g.mixer.Unlock()
+	if solo == g.solo {
+		return
+	}
+	g.solo = solo
+	if solo {
+		g.mixer.solos++
+	} else {
+		g.mixer.solos--
+	}
+}
+
+// Solo returns the solo state of this group.
+func (g *MixerGroup) Solo() bool {
+
+	g.mixer.Lock()
+	defer g.mixer.Unlock()
+	return g.solo
+}
+
+// Gain returns the current gain factor applied to the players of this group
+// considering the master volume, the group volume and the mute and solo states.
+func (g *MixerGroup) Gain() float32 {
+
+	g.mixer.Lock()
+	defer g.mixer.Unlock()
+	if g.mute || (g.mixer.solos > 0 && !g.solo) {
+		return 0
+	}
+	now := time.Now()
+	return g.mixer.master.value(now) * g.volume.value(now)
+}
+
+// masterGain returns the current master volume of this mixer.
+func (m *Mixer) masterGain() float32 {
+
+	m.Lock()
+	defer m.Unlock()
+	return m.master.value(time.Now())
+}
+
+// set starts a ramp from the current volume to the specified volume.
+func (r *volumeRamp) set(volume float32, duration time.Duration) {
+
+	now := time.Now()
+	r.from = r.value(now)
+	r.to = volume
+	r.start = now
+	r.duration = duration
+}
+
+// value returns the volume of the ramp at the specified time.
+func (r *volumeRamp) value(now time.Time) float32 {
+
+	if r.duration <= 0 {
+		return r.to
+	}
+	t := float32(now.Sub(r.start)) / float32(r.duration)
+	if t >= 1 {
+		return r.to
+	}
+	return r.from + (r.to-r.from)*t
+}
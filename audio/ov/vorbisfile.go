@@ -156,6 +156,37 @@ func PcmSeek(f *File, pos int64) error {
 	return fmt.Errorf("Error:%s from 'ov_pcm_seek()'", errCodes[C.int(cres)])
 }
 
+// PcmTell returns the current offset in pcm samples of the decoding position.
+func PcmTell(f *File) (int64, error) {
+
+	cres := C.ov_pcm_tell(f.vf)
+	if cres < 0 {
+		return 0, fmt.Errorf("Error:%s from 'ov_pcm_tell()'", errCodes[C.int(cres)])
+	}
+	return int64(cres), nil
+}
+
+// Comments returns the user comments, as "TAG=value" strings, of the specified logical bitstream.
+// To retrieve the comments of the current logical bitstream, 'link' should be set to -1.
+func Comments(f *File, link int) ([]string, error) {
+
+	vc := C.ov_comment(f.vf, C.int(link))
+	if vc == nil {
+		return nil, fmt.Errorf("Error returned from 'ov_comment'")
+	}
+	count := int(vc.comments)
+	if count == 0 {
+		return nil, nil
+	}
+	ptrs := (*[1 << 20]*C.char)(unsafe.Pointer(vc.user_comments))[:count:count]
+	lengths := (*[1 << 20]C.int)(unsafe.Pointer(vc.comment_lengths))[:count:count]
+	comments := make([]string, count)
+	for i := 0; i < count; i++ {
+		comments[i] = C.GoStringN(ptrs[i], lengths[i])
+	}
+	return comments, nil
+}
+
 // PcmTotal returns the total number of pcm samples of the physical bitstream or a specified logical bit stream.
 // To retrieve the total pcm samples for the entire physical bitstream, the 'link' parameter should be set to -1
 func PcmTotal(f *File, i int) (int64, error) {
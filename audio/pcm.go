@@ -0,0 +1,180 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !wasm
+// +build !wasm
+
+package audio
+
+import (
+	"fmt"
+	"io"
+	"unsafe"
+
+	"github.com/g3n/engine/audio/al"
+)
+
+// PCM is uncompressed audio stored in memory as interleaved float samples
+// in the range [-1, 1], with a sample for each channel in each sample frame.
+type PCM struct {
+	Samples    []float32 // Interleaved samples
+	Channels   int       // Number of channels (1 or 2)
+	SampleRate int       // Sample rate in hz
+}
+
+// NewPCM creates and returns a pointer to a new silent PCM with the specified
+// number of channels, sample rate and sample frames, to be filled by the application.
+func NewPCM(channels, sampleRate, frames int) *PCM {
+
+	return &PCM{Samples: make([]float32, channels*frames), Channels: channels, SampleRate: sampleRate}
+}
+
+// Decode decodes the whole audio data of the specified wave or Ogg Vorbis file.
+func Decode(filename string) (*PCM, error) {
+
+	af, err := NewAudioFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer af.Close()
+	data, err := readAll(af)
+	if err != nil {
+		return nil, err
+	}
+
+	info := af.Info()
+	pcm := &PCM{Channels: info.Channels, SampleRate: info.SampleRate}
+	if info.BitsSample == 8 {
+		// 8 bit samples are unsigned
+		pcm.Samples = make([]float32, len(data))
+		for i, b := range data {
+			pcm.Samples[i] = (float32(b) - 128) / 128
+		}
+		return pcm, nil
+	}
+	pcm.Samples = make([]float32, len(data)/2)
+	for i := range pcm.Samples {
+		pcm.Samples[i] = float32(int16(uint16(data[2*i])|uint16(data[2*i+1])<<8)) / 32768
+	}
+	return pcm, nil
+}
+
+// Frames returns the number of sample frames.
+func (pcm *PCM) Frames() int {
+
+	if pcm.Channels <= 0 {
+		return 0
+	}
+	return len(pcm.Samples) / pcm.Channels
+}
+
+// Duration returns the duration in seconds.
+func (pcm *PCM) Duration() float64 {
+
+	if pcm.SampleRate <= 0 {
+		return 0
+	}
+	return float64(pcm.Frames()) / float64(pcm.SampleRate)
+}
+
+// Sample returns the sample of the specified channel in the specified sample frame.
+func (pcm *PCM) Sample(frame, channel int) float32 {
+
+	return pcm.Samples[frame*pcm.Channels+channel]
+}
+
+// SetSample sets the sample of the specified channel in the specified sample frame.
+func (pcm *PCM) SetSample(frame, channel int, v float32) {
+
+	pcm.Samples[frame*pcm.Channels+channel] = v
+}
+
+// Generate sets the samples of all the channels of each sample frame to the value returned
+// by the specified function for the time in seconds of the frame, to synthesize sounds.
+func (pcm *PCM) Generate(f func(t float64) float32) {
+
+	for frame := 0; frame < pcm.Frames(); frame++ {
+		v := f(float64(frame) / float64(pcm.SampleRate))
+		for ch := 0; ch < pcm.Channels; ch++ {
+			pcm.Samples[frame*pcm.Channels+ch] = v
+		}
+	}
+}
+
+// Int16Data returns the samples converted to signed 16 bit little endian data,
+// clamping the samples to the range [-1, 1].
+func (pcm *PCM) Int16Data() []byte {
+
+	data := make([]byte, 2*len(pcm.Samples))
+	for i, s := range pcm.Samples {
+		if s > 1 {
+			s = 1
+		} else if s < -1 {
+			s = -1
+		}
+		v := uint16(int16(s * 32767))
+		data[2*i] = byte(v)
+		data[2*i+1] = byte(v >> 8)
+	}
+	return data
+}
+
+// NewSoundFromPCM creates and returns a pointer to a new sound with the specified
+// audio data, which may have been generated at runtime or decoded by Decode.
+func NewSoundFromPCM(pcm *PCM) (*Sound, error) {
+
+	return NewSoundFromData(pcm.Int16Data(), pcm.Channels, pcm.SampleRate)
+}
+
+// NewSoundFromData creates and returns a pointer to a new sound with the specified
+// interleaved signed 16 bit little endian samples, such as the output of a speech synthesizer.
+func NewSoundFromData(data []byte, channels, sampleRate int) (*Sound, error) {
+
+	var format uint32
+	switch channels {
+	case 1:
+		format = al.FormatMono16
+	case 2:
+		format = al.FormatStereo16
+	default:
+		return nil, fmt.Errorf("Unsupported number of channels:%d", channels)
+	}
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("Invalid sample rate:%d", sampleRate)
+	}
+	if len(data) < 2*channels {
+		return nil, fmt.Errorf("No audio data")
+	}
+	return newSoundFromData(data, format, channels, 16, sampleRate), nil
+}
+
+// newSoundFromData creates and returns a pointer to a new sound
+// with the specified audio data in the specified OpenAL format.
+func newSoundFromData(data []byte, format uint32, channels, bitsSample, sampleRate int) *Sound {
+
+	s := new(Sound)
+	s.buffer = al.GenBuffers(1)[0]
+	al.BufferData(s.buffer, format, unsafe.Pointer(&data[0]), uint32(len(data)), uint32(sampleRate))
+	bytesSec := sampleRate * channels * bitsSample / 8
+	s.duration = float64(len(data)) / float64(bytesSec)
+	return s
+}
+
+// readAll reads all the decoded audio data of the specified audio file.
+func readAll(af *AudioFile) ([]byte, error) {
+
+	var data []byte
+	chunk := make([]byte, playerBufferSize)
+	for {
+		n, err := af.Read(unsafe.Pointer(&chunk[0]), len(chunk))
+		if err == io.EOF || (err == nil && n == 0) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk[:n]...)
+	}
+	return data, nil
+}
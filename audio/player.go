@@ -11,7 +11,9 @@ package audio
 import "C"
 
 import (
+	"fmt"
 	"io"
+	"sync"
 	"time"
 	"unsafe"
 
@@ -37,6 +39,32 @@ type Player struct {
 	pdata     unsafe.Pointer // Pointer to C allocated storage
 	disposed  bool           // Disposed flag
 	gchan     chan (string)  // Channel for informing of goroutine end
+	gain      float32        // Gain set by the user
+	sched     *time.Timer    // Timer of the scheduled playback (nil if not scheduled)
+	schedMu   sync.Mutex     // Protects sched and schedGen from the timer goroutine
+	schedGen  uint64         // Generation of the scheduled playback incremented when it is cancelled
+	fade      playerFade     // Current gain fade
+	group     *MixerGroup    // Mixer group of this player (nil for the default mixer master volume only)
+	seekPos   float64        // Position in seconds where the next playback starts
+	stream    sync.Mutex     // Protects the head of the buffer queue
+	bufStart  []int64        // Position in sample frames of the audio data of each buffer
+	head      int            // Index of the buffer at the head of the source queue
+	sends     []IEffect      // Effects by auxiliary send index (nil for a free send)
+	filter    *Filter        // Filter of the direct sound (nil for none)
+}
+
+// playerFade describes a fade of the gain of a player which is updated
+// by the player goroutine and so its fields are protected by a mutex.
+type playerFade struct {
+	sync.Mutex
+	active   bool          // Fade is in progress
+	from     float32       // Gain factor at the start of the fade
+	to       float32       // Gain factor at the end of the fade
+	factor   float32       // Current gain factor
+	start    time.Time     // Start time of the fade
+	duration time.Duration // Duration of the fade
+	stop     bool          // Stop the player at the end of the fade
+	gain     float32       // Last source gain set
 }
 
 // NewPlayer creates and returns a pointer to a new audio player object
@@ -57,6 +85,7 @@ func NewPlayer(filename string) (*Player, error) {
 
 	// Generate buffers names
 	p.buffers = al.GenBuffers(playerBufferCount)
+	p.bufStart = make([]int64, playerBufferCount)
 
 	// Generate source name
 	p.source = al.GenSource()
@@ -66,6 +95,9 @@ func NewPlayer(filename string) (*Player, error) {
 
 	// Initialize channel for communication with internal goroutine
 	p.gchan = make(chan string, 1)
+	p.gain = 1
+	p.fade.factor = 1
+	p.fade.gain = 1
 	return p, nil
 }
 
@@ -96,28 +128,85 @@ func (p *Player) State() int {
 // Play starts playing this player
 func (p *Player) Play() error {
 
-	state := p.State()
+	return p.PlayFade(0)
+}
+
+// PlayFade starts playing this player, from the beginning or from the position
+// set by Seek if it is not paused, increasing its gain
+// from zero to the current gain during the specified duration.
+func (p *Player) PlayFade(fadeIn time.Duration) error {
 
 	// If paused, goroutine should be running, just starts playing
-	if state == al.Paused {
+	if p.State() == al.Paused {
+		p.startFade(0, 1, fadeIn, false)
 		al.SourcePlay(p.source)
 		return nil
 	}
 
-	// Already playing - stop in order to start from beginning
-	if state == al.Playing {
-		p.Stop()
+	err := p.prepare()
+	if err != nil {
+		return err
+	}
+	p.start(fadeIn)
+	return nil
+}
+
+// PlayAt schedules this player to start playing from the beginning, or from the
+// position set by Seek, at the specified
+// time of the shared audio clock (see ClockTime), increasing its gain from zero
+// during the specified fade in duration. The audio data is decoded and queued
+// immediately so the playback starts with minimum latency. If the time has already
+// passed the player starts immediately. Stopping the player cancels the scheduled playback.
+func (p *Player) PlayAt(t float64, fadeIn time.Duration) error {
+
+	err := p.prepare()
+	if err != nil {
+		return err
+	}
+	delay := clockDelay(t)
+	if delay == 0 {
+		p.start(fadeIn)
+		return nil
 	}
+	p.schedMu.Lock()
+	defer p.schedMu.Unlock()
+	gen := p.schedGen
+	p.sched = time.AfterFunc(delay, func() {
+		// Only starts if the playback was not cancelled since it was scheduled
+		p.schedMu.Lock()
+		defer p.schedMu.Unlock()
+		if p.schedGen != gen {
+			return
+		}
+		p.sched = nil
+		p.start(fadeIn)
+	})
+	return nil
+}
+
+// prepare stops this player if necessary and fills its buffers
+// with the decoded data from the position where the playback starts.
+func (p *Player) prepare() error {
+
+	// Already playing or scheduled - stop in order to start from beginning
+	p.Stop()
+	return p.fill()
+}
 
-	// Sets file pointer to the beginning
-	err := p.af.Seek(0)
+// fill fills the buffers of this player with the decoded data from the seek
+// position of the audio file, which is reset to the beginning.
+func (p *Player) fill() error {
+
+	// Sets file pointer to the seek position
+	err := p.af.SeekTime(p.seekPos)
+	p.seekPos = 0
 	if err != nil {
 		return err
 	}
 
 	// Fill buffers with decoded data
 	for i := 0; i < playerBufferCount; i++ {
-		err = p.fillBuffer(p.buffers[i])
+		err = p.fillBuffer(i)
 		if err != nil {
 			if err != io.EOF {
 				return err
@@ -126,18 +215,22 @@ func (p *Player) Play() error {
 		}
 	}
 	p.nextBuf = 0
+	p.head = 0
 
 	// Clear previous goroutine response channel
 	select {
 	case _ = <-p.gchan:
 	default:
 	}
+	return nil
+}
+
+// start starts playing the prepared buffers and the goroutine to fill them.
+func (p *Player) start(fadeIn time.Duration) {
 
-	// Starts playing and starts goroutine to fill buffers
+	p.startFade(0, 1, fadeIn, false)
 	al.SourcePlay(p.source)
 	go p.run()
-	
-	return nil
 }
 
 // Pause sets the player in the pause state
@@ -149,9 +242,32 @@ func (p *Player) Pause() {
 	al.SourcePause(p.source)
 }
 
-// Stop stops the player
+// Stop stops the player immediately, cancelling any fade or scheduled playback.
 func (p *Player) Stop() {
 
+	p.fade.Lock()
+	p.fade.active = false
+	p.fade.stop = false
+	p.fade.Unlock()
+
+	// Cancels the scheduled playback if it has not started yet
+	// and removes the queued buffers. The timer function may be already
+	// running, so the generation is changed for it to not start the playback.
+	p.schedMu.Lock()
+	p.schedGen++
+	if p.sched != nil {
+		p.sched.Stop()
+		p.sched = nil
+		p.schedMu.Unlock()
+		al.SourceStop(p.source)
+		queued := al.GetSourcei(p.source, al.BuffersQueued)
+		if queued > 0 {
+			al.SourceUnqueueBuffers(p.source, uint32(queued), nil)
+		}
+		return
+	}
+	p.schedMu.Unlock()
+
 	state := p.State()
 	if state == al.Stopped || state == al.Initial {
 		return
@@ -161,12 +277,122 @@ func (p *Player) Stop() {
 	<-p.gchan
 }
 
+// StopFade decreases the gain of this player to zero during the specified
+// duration and then stops it. It returns immediately.
+func (p *Player) StopFade(fadeOut time.Duration) {
+
+	if fadeOut <= 0 || p.State() != al.Playing {
+		p.Stop()
+		return
+	}
+	p.fade.Lock()
+	from := p.fade.factor
+	p.fade.Unlock()
+	p.startFade(from, 0, fadeOut, true)
+}
+
+// startFade starts a fade of the gain factor of this player.
+func (p *Player) startFade(from, to float32, duration time.Duration, stop bool) {
+
+	p.fade.Lock()
+	defer p.fade.Unlock()
+	p.fade.from = from
+	p.fade.to = to
+	p.fade.start = time.Now()
+	p.fade.duration = duration
+	p.fade.stop = stop
+	p.fade.active = duration > 0
+	if !p.fade.active {
+		p.fade.factor = to
+	} else {
+		p.fade.factor = from
+	}
+	p.applyGain()
+}
+
+// updateGain updates the gain of this player during a fade and
+// from the current gain of its mixer group.
+// It is called by the player goroutine.
+func (p *Player) updateGain() {
+
+	p.fade.Lock()
+	defer p.fade.Unlock()
+	if !p.fade.active {
+		p.applyGain()
+		return
+	}
+	t := float32(time.Since(p.fade.start)) / float32(p.fade.duration)
+	if t >= 1 {
+		t = 1
+		p.fade.active = false
+	}
+	p.fade.factor = p.fade.from + (p.fade.to-p.fade.from)*t
+	p.applyGain()
+	if !p.fade.active && p.fade.stop {
+		// The goroutine ends when it finds the source stopped
+		p.fade.stop = false
+		al.SourceStop(p.source)
+	}
+}
+
+// Fading returns if the gain of this player is being faded.
+func (p *Player) Fading() bool {
+
+	p.fade.Lock()
+	defer p.fade.Unlock()
+	return p.fade.active
+}
+
 // CurrentTime returns the current time in seconds spent in the stream
 func (p *Player) CurrentTime() float64 {
 
 	return p.af.CurrentTime()
 }
 
+// Seek sets the playback position of this player to the specified time in seconds.
+// If the player is playing or paused it continues from the new position,
+// otherwise its next playback, not already scheduled, starts from it.
+func (p *Player) Seek(seconds float64) error {
+
+	if seconds < 0 || seconds > p.TotalTime() {
+		return fmt.Errorf("Seek position out of range")
+	}
+	state := p.State()
+	if state != al.Playing && state != al.Paused {
+		p.seekPos = seconds
+		return nil
+	}
+
+	// Stops the source keeping the current fade and waits for goroutine to finish
+	al.SourceStop(p.source)
+	<-p.gchan
+	p.seekPos = seconds
+	err := p.fill()
+	if err != nil {
+		return err
+	}
+	al.SourcePlay(p.source)
+	if state == al.Paused {
+		al.SourcePause(p.source)
+	}
+	go p.run()
+	return nil
+}
+
+// PlaybackPosition returns the current playback position in seconds. Unlike CurrentTime, which
+// returns the position of the decoded data, it is the position of the sample being played.
+func (p *Player) PlaybackPosition() float64 {
+
+	state := p.State()
+	if state != al.Playing && state != al.Paused {
+		return p.seekPos
+	}
+	p.stream.Lock()
+	frame := p.bufStart[p.head] + int64(al.GetSourcei(p.source, al.SampleOffset))
+	p.stream.Unlock()
+	return float64(p.af.wrapFrame(frame)) / float64(p.af.info.SampleRate)
+}
+
 // TotalTime returns the total time in seconds to play this stream
 func (p *Player) TotalTime() float64 {
 
@@ -174,14 +400,131 @@ func (p *Player) TotalTime() float64 {
 }
 
 // Gain returns the current gain (volume) of this player
+// not considering any fade in progress.
 func (p *Player) Gain() float32 {
 
-	return al.GetSourcef(p.source, al.Gain)
+	return p.gain
 }
 
-// SetGain sets the gain (volume) of this player
+// SetGain sets the gain (volume) of this player.
+// A fade in progress is applied relative to this gain.
 func (p *Player) SetGain(gain float32) {
 
+	p.fade.Lock()
+	defer p.fade.Unlock()
+	p.gain = gain
+	p.applyGain()
+}
+
+// SetGroup sets the mixer group of this player.
+// The volume of the group and the master volume of its mixer multiply the player gain.
+// Nil removes the player from its group and only the master volume of the default mixer is applied.
+func (p *Player) SetGroup(group *MixerGroup) {
+
+	p.fade.Lock()
+	defer p.fade.Unlock()
+	p.group = group
+	p.applyGain()
+}
+
+// Group returns the mixer group of this player or nil if it does not belong to a group.
+func (p *Player) Group() *MixerGroup {
+
+	p.fade.Lock()
+	defer p.fade.Unlock()
+	return p.group
+}
+
+// AddEffect sends the sound of this player to the specified effect through the
+// next free auxiliary send of its source, applying the optional filter to the sent sound.
+// It returns an error if the effect was already added or all the sends are in use.
+func (p *Player) AddEffect(effect IEffect, filter *Filter) error {
+
+	max := al.MaxAuxiliarySends()
+	if len(p.sends) < max {
+		p.sends = append(p.sends, make([]IEffect, max-len(p.sends))...)
+	}
+	send := -1
+	for i, e := range p.sends {
+		if e == effect {
+			return fmt.Errorf("effect already added to the player")
+		}
+		if e == nil && send < 0 {
+			send = i
+		}
+	}
+	if send < 0 {
+		return fmt.Errorf("all the %d auxiliary sends of the player are in use", max)
+	}
+	var fname uint32 = al.AL_FILTER_NULL
+	if filter != nil {
+		fname = filter.filter
+	}
+	al.Source3i(p.source, al.AL_AUXILIARY_SEND_FILTER, int32(effect.GetEffect().slot), int32(send), int32(fname))
+	p.sends[send] = effect
+	return nil
+}
+
+// RemoveEffect stops sending the sound of this player to the specified effect
+// and returns true if the effect was found.
+func (p *Player) RemoveEffect(effect IEffect) bool {
+
+	for i, e := range p.sends {
+		if e == effect {
+			al.Source3i(p.source, al.AL_AUXILIARY_SEND_FILTER, al.AL_EFFECTSLOT_NULL, int32(i), al.AL_FILTER_NULL)
+			p.sends[i] = nil
+			return true
+		}
+	}
+	return false
+}
+
+// Effects returns the effects to which the sound of this player is sent
+func (p *Player) Effects() []IEffect {
+
+	effects := make([]IEffect, 0)
+	for _, e := range p.sends {
+		if e != nil {
+			effects = append(effects, e)
+		}
+	}
+	return effects
+}
+
+// SetDirectFilter sets the filter applied to the direct sound of this player.
+// Nil removes the current filter.
+func (p *Player) SetDirectFilter(filter *Filter) {
+
+	p.filter = filter
+	var fname uint32 = al.AL_FILTER_NULL
+	if filter != nil {
+		fname = filter.filter
+	}
+	al.Sourcei(p.source, al.AL_DIRECT_FILTER, int32(fname))
+}
+
+// DirectFilter returns the filter applied to the direct sound of this player or nil if none.
+func (p *Player) DirectFilter() *Filter {
+
+	return p.filter
+}
+
+// applyGain sets the OpenAL source gain from the player gain, the current
+// fade factor and the mixer gain if it has changed.
+// It must be called with the fade mutex locked.
+func (p *Player) applyGain() {
+
+	var mixerGain float32
+	if p.group != nil {
+		mixerGain = p.group.Gain()
+	} else {
+		mixerGain = defaultMixer.masterGain()
+	}
+	gain := p.gain * p.fade.factor * mixerGain
+	if gain == p.fade.gain {
+		return
+	}
+	p.fade.gain = gain
 	al.Sourcef(p.source, al.Gain, gain)
 }
 
@@ -233,6 +576,20 @@ func (p *Player) SetLooping(looping bool) {
 	p.af.SetLooping(looping)
 }
 
+// SetLoopPoints sets the sample frames where the loop of this player starts and
+// ends when looping. An end of zero is the end of the stream. For Ogg Vorbis files
+// they are initialized from the LOOPSTART and LOOPLENGTH or LOOPEND comments.
+func (p *Player) SetLoopPoints(start, end int64) error {
+
+	return p.af.SetLoopPoints(start, end)
+}
+
+// LoopPoints returns the sample frames where the loop of this player starts and ends when looping.
+func (p *Player) LoopPoints() (int64, int64) {
+
+	return p.af.LoopPoints()
+}
+
 // InnerCone returns the inner cone angle in degrees
 func (p *Player) InnerCone() float32 {
 
@@ -311,6 +668,8 @@ func (p *Player) Render(gl *gls.GLS) {
 func (p *Player) run() {
 
 	for {
+		p.updateGain()
+
 		// Get current state of player source
 		state := al.GetSourcei(p.source, al.SourceState)
 		processed := al.GetSourcei(p.source, al.BuffersProcessed)
@@ -336,10 +695,13 @@ func (p *Player) run() {
 		}
 
 		// Remove processed buffers from the queue
+		p.stream.Lock()
 		al.SourceUnqueueBuffers(p.source, uint32(processed), nil)
+		p.head = (p.head + int(processed)) % playerBufferCount
+		p.stream.Unlock()
 		// Fill and enqueue buffers with new data
 		for i := 0; i < int(processed); i++ {
-			err := p.fillBuffer(p.buffers[p.nextBuf])
+			err := p.fillBuffer(p.nextBuf)
 			if err != nil {
 				break
 			}
@@ -350,15 +712,21 @@ func (p *Player) run() {
 	p.gchan <- "end"
 }
 
-// fillBuffer fills the specified OpenAL buffer with next decoded data
+// fillBuffer fills the OpenAL buffer with the specified index with next decoded data
 // and queues the buffer to this player source
-func (p *Player) fillBuffer(buf uint32) error {
+func (p *Player) fillBuffer(i int) error {
 
-	// Reads next decoded data
+	// Reads next decoded data saving its position
+	pos, err := p.af.Tell()
+	if err != nil {
+		return err
+	}
 	n, err := p.af.Read(p.pdata, playerBufferSize)
 	if err != nil {
 		return err
 	}
+	buf := p.buffers[i]
+	p.bufStart[i] = pos
 	// Sends data to buffer
 	//log.Debug("BufferData:%v format:%x n:%v rate:%v", buf, p.af.info.Format, n, p.af.info.SampleRate)
 	al.BufferData(buf, uint32(p.af.info.Format), p.pdata, uint32(n), uint32(p.af.info.SampleRate))
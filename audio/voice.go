@@ -0,0 +1,496 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !wasm
+// +build !wasm
+
+package audio
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/g3n/engine/audio/al"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+// Sound is a fully decoded audio clip stored in an OpenAL buffer
+// which can be played by any number of voices at the same time.
+type Sound struct {
+	buffer   uint32  // OpenAL buffer name
+	duration float64 // Duration in seconds
+}
+
+// NewSound creates and returns a pointer to a new sound with
+// the decoded audio of the specified wave or Ogg Vorbis file.
+func NewSound(filename string) (*Sound, error) {
+
+	af, err := NewAudioFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer af.Close()
+
+	// Decodes all the audio data
+	data, err := readAll(af)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("No audio data in:%s", filename)
+	}
+
+	info := af.Info()
+	return newSoundFromData(data, uint32(info.Format), info.Channels, info.BitsSample, info.SampleRate), nil
+}
+
+// Duration returns the duration of this sound in seconds.
+func (s *Sound) Duration() float64 {
+
+	return s.duration
+}
+
+// Dispose releases the OpenAL buffer of this sound.
+// The sound must not be used by any playing voice.
+func (s *Sound) Dispose() {
+
+	al.DeleteBuffers([]uint32{s.buffer})
+	s.buffer = 0
+}
+
+// VoiceManager pools a limited number of OpenAL sources and assigns them
+// to the most important playing voices. Voices which do not get a source
+// are virtual: they are not heard but their playback position keeps advancing,
+// so they resume at the right position when they get a source again.
+// Update must be called once per frame.
+type VoiceManager struct {
+	sources  []uint32  // All the pooled OpenAL sources
+	free     []uint32  // Sources not assigned to voices
+	voices   []*Voice  // Playing voices
+	lastTime time.Time // Time of the last update
+}
+
+// Voice is a spatial sound playing instance of a VoiceManager.
+// It embeds a core.Node so it can be inserted as a child in any other 3D object.
+type Voice struct {
+	core.Node                 // Embedded node
+	vm          *VoiceManager // Voice manager of this voice
+	sound       *Sound        // Sound played by this voice
+	source      uint32        // OpenAL source name (0 if virtual or stopped)
+	playing     bool          // Playing flag
+	offset      float64       // Playback position in seconds
+	gain        float32       // Gain set by the user
+	pitch       float32       // Pitch factor
+	looping     bool          // Looping flag
+	priority    int           // Priority (voices with higher priority get sources first)
+	spatial     bool          // Spatial flag (if false the voice is not attenuated and positioned)
+	refDistance float32       // Distance under which the voice is not attenuated
+	rolloff     float32       // Rolloff factor of the distance attenuation
+	maxDistance float32       // Distance beyond which the voice is not attenuated any further
+	group       *MixerGroup   // Mixer group of this voice
+	audibility  float32       // Estimated gain heard by the listener
+}
+
+// NewVoiceManager creates and returns a pointer to a new voice manager which
+// pools up to the specified number of OpenAL sources. Less sources may be
+// pooled if the OpenAL implementation does not support so many.
+func NewVoiceManager(maxSources int) *VoiceManager {
+
+	vm := new(VoiceManager)
+	for i := 0; i < maxSources; i++ {
+		al.GetError()
+		source := al.GenSource()
+		if al.GetError() != nil {
+			break
+		}
+		vm.sources = append(vm.sources, source)
+	}
+	vm.free = append(vm.free, vm.sources...)
+	vm.lastTime = time.Now()
+	return vm
+}
+
+// NewVoice creates and returns a pointer to a new stopped voice which plays the specified sound.
+func (vm *VoiceManager) NewVoice(sound *Sound) *Voice {
+
+	v := new(Voice)
+	v.Node.Init(v)
+	v.vm = vm
+	v.sound = sound
+	v.gain = 1
+	v.pitch = 1
+	v.spatial = true
+	v.refDistance = 1
+	v.rolloff = 1
+	v.maxDistance = math32.Inf(1)
+	return v
+}
+
+// Sources returns the number of pooled OpenAL sources.
+func (vm *VoiceManager) Sources() int {
+
+	return len(vm.sources)
+}
+
+// Voices returns the number of playing voices including the virtual ones.
+func (vm *VoiceManager) Voices() int {
+
+	return len(vm.voices)
+}
+
+// RealVoices returns the number of playing voices which have a source.
+func (vm *VoiceManager) RealVoices() int {
+
+	return len(vm.sources) - len(vm.free)
+}
+
+// Update advances the playback position of the playing voices, removes the
+// finished ones and assigns the pooled sources to the playing voices with
+// higher priority and, for the same priority, higher audibility estimated
+// from their gain and distance from the listener.
+// It must be called once per frame, after the scene matrices are updated.
+func (vm *VoiceManager) Update() {
+
+	now := time.Now()
+	dt := now.Sub(vm.lastTime).Seconds()
+	vm.lastTime = now
+
+	// Advances the voices and removes the finished ones
+	count := 0
+	for _, v := range vm.voices {
+		if v.advance(dt) {
+			vm.voices[count] = v
+			count++
+		} else {
+			v.release()
+			v.playing = false
+		}
+	}
+	for i := count; i < len(vm.voices); i++ {
+		vm.voices[i] = nil
+	}
+	vm.voices = vm.voices[:count]
+	vm.prioritize()
+}
+
+// Dispose stops all the voices and releases the pooled OpenAL sources.
+func (vm *VoiceManager) Dispose() {
+
+	for _, v := range vm.voices {
+		v.release()
+		v.playing = false
+	}
+	vm.voices = nil
+	if len(vm.sources) > 0 {
+		al.DeleteSources(vm.sources)
+	}
+	vm.sources = nil
+	vm.free = nil
+}
+
+// prioritize sorts the playing voices by priority and audibility
+// and assigns the available sources to the first voices.
+func (vm *VoiceManager) prioritize() {
+
+	lx, ly, lz := al.GetListener3f(al.Position)
+	listener := math32.Vector3{X: lx, Y: ly, Z: lz}
+	for _, v := range vm.voices {
+		v.audibility = v.estimateGain(&listener)
+	}
+	sort.SliceStable(vm.voices, func(i, j int) bool {
+		vi, vj := vm.voices[i], vm.voices[j]
+		if vi.priority != vj.priority {
+			return vi.priority > vj.priority
+		}
+		return vi.audibility > vj.audibility
+	})
+
+	// Releases the sources of the voices which became virtual
+	// before assigning them to the voices which became real.
+	real := len(vm.sources)
+	for i, v := range vm.voices {
+		if i >= real || v.audibility <= 0 {
+			v.release()
+		}
+	}
+	for i, v := range vm.voices {
+		if i >= real {
+			break
+		}
+		if v.audibility <= 0 {
+			continue
+		}
+		if v.source == 0 {
+			v.acquire()
+		} else {
+			v.update()
+		}
+	}
+}
+
+// Play starts playing this voice from the beginning.
+// The voice gets a source immediately if it is important enough.
+func (v *Voice) Play() {
+
+	v.offset = 0
+	if v.source != 0 {
+		al.SourceStop(v.source)
+		al.Sourcef(v.source, al.SecOffset, 0)
+		al.SourcePlay(v.source)
+	}
+	if !v.playing {
+		v.playing = true
+		v.vm.voices = append(v.vm.voices, v)
+	}
+	v.vm.prioritize()
+}
+
+// Stop stops this voice and releases its source.
+func (v *Voice) Stop() {
+
+	if !v.playing {
+		return
+	}
+	v.release()
+	v.playing = false
+	for i, other := range v.vm.voices {
+		if other == v {
+			copy(v.vm.voices[i:], v.vm.voices[i+1:])
+			v.vm.voices[len(v.vm.voices)-1] = nil
+			v.vm.voices = v.vm.voices[:len(v.vm.voices)-1]
+			break
+		}
+	}
+}
+
+// Playing returns if this voice is playing (even if virtual).
+func (v *Voice) Playing() bool {
+
+	return v.playing
+}
+
+// Virtual returns if this voice is playing without a source.
+func (v *Voice) Virtual() bool {
+
+	return v.playing && v.source == 0
+}
+
+// Offset returns the current playback position of this voice in seconds.
+func (v *Voice) Offset() float64 {
+
+	return v.offset
+}
+
+// Sound returns the sound played by this voice.
+func (v *Voice) Sound() *Sound {
+
+	return v.sound
+}
+
+// SetGain sets the gain (volume) of this voice.
+func (v *Voice) SetGain(gain float32) {
+
+	v.gain = gain
+	v.update()
+}
+
+// Gain returns the gain (volume) of this voice.
+func (v *Voice) Gain() float32 {
+
+	return v.gain
+}
+
+// SetPitch sets the pitch factor of this voice.
+func (v *Voice) SetPitch(pitch float32) {
+
+	v.pitch = pitch
+	v.update()
+}
+
+// Pitch returns the pitch factor of this voice.
+func (v *Voice) Pitch() float32 {
+
+	return v.pitch
+}
+
+// SetLooping sets the looping state of this voice.
+func (v *Voice) SetLooping(looping bool) {
+
+	v.looping = looping
+	v.update()
+}
+
+// Looping returns the looping state of this voice.
+func (v *Voice) Looping() bool {
+
+	return v.looping
+}
+
+// SetPriority sets the priority of this voice. Voices with higher priority
+// get sources before the voices with lower priority independently of their audibility.
+// The default priority is zero.
+func (v *Voice) SetPriority(priority int) {
+
+	v.priority = priority
+}
+
+// Priority returns the priority of this voice.
+func (v *Voice) Priority() int {
+
+	return v.priority
+}
+
+// SetSpatial sets whether this voice is positioned in space and attenuated by
+// its distance from the listener (the default) or played relative to the listener
+// without attenuation, as music and interface sounds.
+func (v *Voice) SetSpatial(spatial bool) {
+
+	v.spatial = spatial
+	v.update()
+}
+
+// Spatial returns whether this voice is positioned in space.
+func (v *Voice) Spatial() bool {
+
+	return v.spatial
+}
+
+// SetReferenceDistance sets the distance under which this voice is not attenuated.
+func (v *Voice) SetReferenceDistance(dist float32) {
+
+	v.refDistance = dist
+	v.update()
+}
+
+// SetRolloffFactor sets the rolloff factor of the distance attenuation of this voice.
+func (v *Voice) SetRolloffFactor(rfactor float32) {
+
+	v.rolloff = rfactor
+	v.update()
+}
+
+// SetMaxDistance sets the distance beyond which this voice is not attenuated any further.
+func (v *Voice) SetMaxDistance(dist float32) {
+
+	v.maxDistance = dist
+	v.update()
+}
+
+// SetGroup sets the mixer group of this voice (see Player.SetGroup).
+func (v *Voice) SetGroup(group *MixerGroup) {
+
+	v.group = group
+	v.update()
+}
+
+// Group returns the mixer group of this voice or nil if it does not belong to a group.
+func (v *Voice) Group() *MixerGroup {
+
+	return v.group
+}
+
+// mixerGain returns the current gain of the mixer group of this voice.
+func (v *Voice) mixerGain() float32 {
+
+	if v.group != nil {
+		return v.group.Gain()
+	}
+	return defaultMixer.masterGain()
+}
+
+// estimateGain returns the estimated gain of this voice heard by the listener
+// at the specified position using the inverse distance clamped model.
+func (v *Voice) estimateGain(listener *math32.Vector3) float32 {
+
+	gain := v.gain * v.mixerGain()
+	if !v.spatial || gain <= 0 {
+		return gain
+	}
+	var wpos math32.Vector3
+	v.WorldPosition(&wpos)
+	dist := math32.Clamp(wpos.DistanceTo(listener), v.refDistance, v.maxDistance)
+	den := v.refDistance + v.rolloff*(dist-v.refDistance)
+	if den <= 0 {
+		return gain
+	}
+	return gain * v.refDistance / den
+}
+
+// advance advances the playback position of this voice by the specified time interval.
+// Returns false if the voice has finished playing.
+func (v *Voice) advance(dt float64) bool {
+
+	if v.source != 0 {
+		if al.GetSourcei(v.source, al.SourceState) == al.Stopped {
+			return false
+		}
+		v.offset = float64(al.GetSourcef(v.source, al.SecOffset))
+		return true
+	}
+	v.offset += dt * float64(v.pitch)
+	if v.offset < v.sound.duration {
+		return true
+	}
+	if !v.looping || v.sound.duration <= 0 {
+		return false
+	}
+	v.offset = math.Mod(v.offset, v.sound.duration)
+	return true
+}
+
+// acquire gets a free source for this voice and starts playing it from the current offset.
+func (v *Voice) acquire() {
+
+	if len(v.vm.free) == 0 {
+		return
+	}
+	v.source = v.vm.free[len(v.vm.free)-1]
+	v.vm.free = v.vm.free[:len(v.vm.free)-1]
+	al.Sourcei(v.source, al.Buffer, int32(v.sound.buffer))
+	v.update()
+	al.Sourcef(v.source, al.SecOffset, float32(v.offset))
+	al.SourcePlay(v.source)
+}
+
+// release stops the source of this voice, if any, and returns it to the pool.
+func (v *Voice) release() {
+
+	if v.source == 0 {
+		return
+	}
+	al.SourceStop(v.source)
+	al.Sourcei(v.source, al.Buffer, 0)
+	v.vm.free = append(v.vm.free, v.source)
+	v.source = 0
+}
+
+// update transfers the parameters of this voice to its source if any.
+func (v *Voice) update() {
+
+	if v.source == 0 {
+		return
+	}
+	al.Sourcef(v.source, al.Gain, v.gain*v.mixerGain())
+	al.Sourcef(v.source, al.Pitch, v.pitch)
+	if v.looping {
+		al.Sourcei(v.source, al.Looping, al.True)
+	} else {
+		al.Sourcei(v.source, al.Looping, al.False)
+	}
+	al.Sourcef(v.source, al.ReferenceDistance, v.refDistance)
+	al.Sourcef(v.source, al.RolloffFactor, v.rolloff)
+	al.Sourcef(v.source, al.MaxDistance, v.maxDistance)
+	if v.spatial {
+		var wpos math32.Vector3
+		v.WorldPosition(&wpos)
+		al.Sourcei(v.source, al.SourceRelative, al.False)
+		al.Source3f(v.source, al.Position, wpos.X, wpos.Y, wpos.Z)
+	} else {
+		al.Sourcei(v.source, al.SourceRelative, al.True)
+		al.Source3f(v.source, al.Position, 0, 0, 0)
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package camera
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gui"
+	"github.com/g3n/engine/math32"
+)
+
+// OnViewpoint is the event dispatched by an Animator when the camera arrives at a viewpoint.
+// The event parameter is the name of the viewpoint.
+const OnViewpoint = "camera.OnViewpoint"
+
+// Viewpoint is a camera position looking at a target with a field of view.
+type Viewpoint struct {
+	Position math32.Vector3 // Position of the camera
+	Target   math32.Vector3 // Point the camera looks at
+	Fov      float32        // Perspective field of view in degrees (0 keeps the current field of view)
+}
+
+// Animator stores named viewpoints and smoothly moves a camera between them.
+// The position, the target and the field of view are interpolated with an easing function
+// and the orientation is spherically interpolated, so the camera turns at a steady rate.
+// The camera is expected to have no parent and the Y axis up.
+type Animator struct {
+	core.Dispatcher                      // Embedded event dispatcher
+	cam             *Camera              // Animated camera
+	viewpoints      map[string]Viewpoint // Saved viewpoints
+	duration        time.Duration        // Duration of the transitions
+	easing          gui.Easing           // Easing function of the transitions
+	tween           *gui.Tween           // Current transition (nil if none)
+	current         string               // Name of the last viewpoint animated to
+	target          math32.Vector3       // Current target
+	hasTarget       bool                 // Current target is known
+}
+
+// NewAnimator creates and returns a pointer to a new animator for the specified camera.
+// The default transition takes one second and accelerates until halfway and then decelerates.
+func NewAnimator(cam *Camera) *Animator {
+
+	a := new(Animator)
+	a.Dispatcher.Initialize()
+	a.cam = cam
+	a.viewpoints = make(map[string]Viewpoint)
+	a.duration = time.Second
+	a.easing = gui.EaseInOutCubic
+	return a
+}
+
+// Dispose stops the current transition.
+func (a *Animator) Dispose() {
+
+	a.Stop()
+}
+
+// SetViewpoint saves the specified viewpoint with the specified name, replacing any viewpoint with the same name.
+func (a *Animator) SetViewpoint(name string, vp Viewpoint) {
+
+	a.viewpoints[name] = vp
+}
+
+// SaveViewpoint saves the current position and field of view of the camera looking at
+// the specified target as a viewpoint with the specified name.
+func (a *Animator) SaveViewpoint(name string, target *math32.Vector3) {
+
+	a.viewpoints[name] = Viewpoint{Position: a.cam.Position(), Target: *target, Fov: a.cam.Fov()}
+}
+
+// Viewpoint returns the viewpoint with the specified name and whether it was found.
+func (a *Animator) Viewpoint(name string) (Viewpoint, bool) {
+
+	vp, ok := a.viewpoints[name]
+	return vp, ok
+}
+
+// RemoveViewpoint removes the viewpoint with the specified name.
+func (a *Animator) RemoveViewpoint(name string) {
+
+	delete(a.viewpoints, name)
+}
+
+// Viewpoints returns the sorted names of the saved viewpoints.
+func (a *Animator) Viewpoints() []string {
+
+	names := make([]string, 0, len(a.viewpoints))
+	for name := range a.viewpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetDuration sets the duration of the transitions.
+func (a *Animator) SetDuration(duration time.Duration) {
+
+	a.duration = duration
+}
+
+// Duration returns the duration of the transitions.
+func (a *Animator) Duration() time.Duration {
+
+	return a.duration
+}
+
+// SetEasing sets the easing function of the transitions. A nil easing is linear.
+func (a *Animator) SetEasing(easing gui.Easing) {
+
+	a.easing = easing
+}
+
+// GoTo starts a transition of the camera from its current state to the viewpoint with the
+// specified name, stopping the current transition. OnViewpoint is dispatched when it arrives.
+func (a *Animator) GoTo(name string) error {
+
+	to, ok := a.viewpoints[name]
+	if !ok {
+		return fmt.Errorf("viewpoint not found: %s", name)
+	}
+	a.Stop()
+	a.current = name
+
+	// Start state
+	from := Viewpoint{Position: a.cam.Position(), Target: a.target, Fov: a.cam.Fov()}
+	if !a.hasTarget {
+		// Assume the target is in the current view direction at the distance of the new target
+		var dir math32.Vector3
+		a.cam.WorldDirection(&dir)
+		dist := to.Target.DistanceTo(&to.Position)
+		from.Target = *dir.MultiplyScalar(dist).Add(&from.Position)
+	}
+	if to.Fov == 0 {
+		to.Fov = from.Fov
+	}
+	fromQuat := a.cam.Quaternion()
+	toQuat := lookAtQuaternion(&to.Position, &to.Target)
+
+	a.tween = gui.AnimateFunc(0, 1, a.duration, a.easing, func(t float32) {
+		pos := from.Position
+		pos.Lerp(&to.Position, t)
+		a.target = from.Target
+		a.target.Lerp(&to.Target, t)
+		a.hasTarget = true
+		q := fromQuat
+		q.Slerp(&toQuat, t)
+		a.cam.SetPositionVec(&pos)
+		a.cam.SetQuaternionQuat(&q)
+		if a.cam.Projection() == Perspective {
+			a.cam.SetFov(from.Fov + (to.Fov-from.Fov)*t)
+		}
+	})
+	a.tween.SetOnComplete(func() {
+		a.tween = nil
+		a.Dispatch(OnViewpoint, name)
+	})
+	return nil
+}
+
+// Jump moves the camera to the viewpoint with the specified name without a transition,
+// stopping the current transition, and dispatches OnViewpoint.
+func (a *Animator) Jump(name string) error {
+
+	vp, ok := a.viewpoints[name]
+	if !ok {
+		return fmt.Errorf("viewpoint not found: %s", name)
+	}
+	a.Stop()
+	a.current = name
+	a.target = vp.Target
+	a.hasTarget = true
+	q := lookAtQuaternion(&vp.Position, &vp.Target)
+	a.cam.SetPositionVec(&vp.Position)
+	a.cam.SetQuaternionQuat(&q)
+	if vp.Fov != 0 && a.cam.Projection() == Perspective {
+		a.cam.SetFov(vp.Fov)
+	}
+	a.Dispatch(OnViewpoint, name)
+	return nil
+}
+
+// Stop stops the current transition leaving the camera where it is.
+func (a *Animator) Stop() {
+
+	if a.tween != nil {
+		a.tween.Stop()
+		a.tween = nil
+	}
+}
+
+// Running returns whether the camera is moving to a viewpoint.
+func (a *Animator) Running() bool {
+
+	return a.tween != nil
+}
+
+// Current returns the name of the viewpoint the camera is at or moving to (empty if none).
+func (a *Animator) Current() string {
+
+	return a.current
+}
+
+// Target returns the point the camera looks at during and after a transition,
+// which may be set as the target of an orbit control when it ends.
+func (a *Animator) Target() math32.Vector3 {
+
+	return a.target
+}
+
+// lookAtQuaternion returns the orientation of a camera at the specified position looking at the specified target.
+func lookAtQuaternion(position, target *math32.Vector3) math32.Quaternion {
+
+	var m math32.Matrix4
+	var q math32.Quaternion
+	m.LookAt(position, target, math32.NewVector3(0, 1, 0))
+	q.SetFromRotationMatrix(&m)
+	return q
+}
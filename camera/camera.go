@@ -50,6 +50,8 @@ type Camera struct {
 	size        float32        // Orthographic size along reference axis
 	projChanged bool           // Flag indicating that the projection matrix needs to be recalculated
 	projMatrix  math32.Matrix4 // Last calculated projection matrix
+	exposure    exposure       // Exposure settings and automatic exposure state
+	layerMask   uint32         // Bitmask of the layers rendered by the camera
 }
 
 // New creates and returns a new perspective camera with the specified aspect ratio and default parameters.
@@ -71,6 +73,8 @@ func NewPerspective(aspect, near, far, fov float32, axis Axis) *Camera {
 	c.fov = fov
 	c.size = 8
 	c.projChanged = true
+	c.exposure.init()
+	c.layerMask = core.AllLayers
 	return c
 }
 
@@ -88,6 +92,8 @@ func NewOrthographic(aspect, near, far, size float32, axis Axis) *Camera {
 	c.fov = 60
 	c.size = size
 	c.projChanged = true
+	c.exposure.init()
+	c.layerMask = core.AllLayers
 	return c
 }
 
@@ -227,6 +233,20 @@ func (c *Camera) UpdateSize(targetDist float32) {
 	}
 }
 
+// SetLayerMask sets the bitmask of the layers rendered by the camera.
+// Only the nodes which belong to one of these layers are rendered and picked.
+// By default all the layers are rendered.
+func (c *Camera) SetLayerMask(mask uint32) {
+
+	c.layerMask = mask
+}
+
+// LayerMask returns the bitmask of the layers rendered by the camera.
+func (c *Camera) LayerMask() uint32 {
+
+	return c.layerMask
+}
+
 // ViewMatrix returns the view matrix of the camera.
 func (c *Camera) ViewMatrix(m *math32.Matrix4) {
 
@@ -0,0 +1,147 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package camera
+
+import (
+	"time"
+
+	"github.com/g3n/engine/math32"
+)
+
+// ExposureMode is the method used to set the exposure of a camera.
+type ExposureMode int
+
+// The possible exposure modes.
+const (
+	ExposureManual    = ExposureMode(iota) // Exposure value set with SetExposureEV
+	ExposureAverage                        // Automatic exposure from the average luminance of the frame
+	ExposureHistogram                      // Automatic exposure from the luminance histogram of the frame
+)
+
+// Luminance of the scene which is mapped to middle gray by the automatic exposure
+const exposureMiddleGray = 0.18
+
+// exposure contains the exposure settings of a camera and the state of its automatic exposure.
+type exposure struct {
+	mode         ExposureMode // Exposure mode
+	ev           float32      // Manual exposure value
+	compensation float32      // Exposure compensation in EV
+	minEV        float32      // Minimum automatic exposure value
+	maxEV        float32      // Maximum automatic exposure value
+	speed        float32      // Adaptation speed of the automatic exposure
+	adaptedEV    float32      // Current automatic exposure value
+	adapted      time.Time    // Time of the last adaptation (zero if the exposure never adapted)
+}
+
+// init sets the default exposure settings.
+func (e *exposure) init() {
+
+	e.minEV = -8
+	e.maxEV = 8
+	e.speed = 1.5
+}
+
+// SetExposureMode sets the method used to set the exposure of the camera, which is
+// applied by the renderer when HDR rendering is enabled. The default is ExposureManual.
+func (c *Camera) SetExposureMode(mode ExposureMode) {
+
+	c.exposure.mode = mode
+	c.exposure.adapted = time.Time{}
+}
+
+// ExposureMode returns the method used to set the exposure of the camera.
+func (c *Camera) ExposureMode() ExposureMode {
+
+	return c.exposure.mode
+}
+
+// SetExposureEV sets the exposure value used in the manual exposure mode.
+// The colors are multiplied by 2^-EV, so EV 0 (the default) keeps them
+// unchanged and each additional EV halves them.
+func (c *Camera) SetExposureEV(ev float32) {
+
+	c.exposure.ev = ev
+}
+
+// ExposureEV returns the exposure value used in the manual exposure mode.
+func (c *Camera) ExposureEV() float32 {
+
+	return c.exposure.ev
+}
+
+// SetExposureCompensation sets the number of EVs the image is brightened
+// in both the manual and automatic exposure modes. The default is 0.
+func (c *Camera) SetExposureCompensation(ev float32) {
+
+	c.exposure.compensation = ev
+}
+
+// ExposureCompensation returns the exposure compensation in EVs.
+func (c *Camera) ExposureCompensation() float32 {
+
+	return c.exposure.compensation
+}
+
+// SetExposureRange sets the minimum and maximum exposure values of the automatic
+// exposure modes. The defaults are -8 and 8.
+func (c *Camera) SetExposureRange(minEV, maxEV float32) {
+
+	c.exposure.minEV = minEV
+	c.exposure.maxEV = maxEV
+}
+
+// ExposureRange returns the minimum and maximum exposure values of the automatic exposure modes.
+func (c *Camera) ExposureRange() (float32, float32) {
+
+	return c.exposure.minEV, c.exposure.maxEV
+}
+
+// SetExposureAdaptation sets the speed at which the automatic exposure adapts to changes of the
+// luminance of the scene: the fraction of the difference to the target exposure covered in one
+// second is 1-e^-speed. Zero or negative speeds adapt immediately. The default is 1.5.
+func (c *Camera) SetExposureAdaptation(speed float32) {
+
+	c.exposure.speed = speed
+}
+
+// ExposureAdaptation returns the adaptation speed of the automatic exposure.
+func (c *Camera) ExposureAdaptation() float32 {
+
+	return c.exposure.speed
+}
+
+// CurrentEV returns the exposure value currently applied: the manual exposure value
+// or the value the automatic exposure has adapted to, without the compensation.
+func (c *Camera) CurrentEV() float32 {
+
+	if c.exposure.mode == ExposureManual {
+		return c.exposure.ev
+	}
+	return c.exposure.adaptedEV
+}
+
+// Exposure returns the factor the colors rendered with the camera are multiplied by.
+func (c *Camera) Exposure() float32 {
+
+	return math32.Pow(2, c.exposure.compensation-c.CurrentEV())
+}
+
+// AdaptExposure adapts the automatic exposure of the camera to the specified average
+// luminance of the scene, so it is mapped to middle gray after the adaptation.
+// It is called by the renderer for each frame rendered with HDR in an automatic exposure mode.
+func (c *Camera) AdaptExposure(luminance float32) {
+
+	e := &c.exposure
+	target := math32.Log2(math32.Max(luminance, 1e-6) / exposureMiddleGray)
+	target = math32.Clamp(target, e.minEV, math32.Max(e.minEV, e.maxEV))
+	now := time.Now()
+	if e.adapted.IsZero() || e.speed <= 0 {
+		e.adaptedEV = target
+	} else {
+		dt := float32(now.Sub(e.adapted).Seconds())
+		e.adaptedEV += (target - e.adaptedEV) * (1 - math32.Exp(-dt*e.speed))
+	}
+	e.adapted = now
+}
@@ -0,0 +1,101 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package camera
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+// FitToBox moves the camera along its current viewing direction, and for orthographic cameras
+// also sets the size, so the specified box in world coordinates fills the viewport, respecting
+// the aspect ratio, with the specified margin as a fraction of the viewport size (e.g. 0.1 for 10%).
+// The far plane is moved back if the box would be clipped by it. Returns the center of the box,
+// which the camera looks at, to be used as the target of an OrbitControl.
+// The camera is expected to have no parent. An empty box doesn't change the camera and returns the origin.
+func (c *Camera) FitToBox(box *math32.Box3, margin float32) math32.Vector3 {
+
+	var center math32.Vector3
+	if box.Empty() {
+		return center
+	}
+	box.Center(&center)
+
+	// Gets the camera axes
+	var right, up, back math32.Vector3
+	c.UpdateMatrixWorld()
+	mw := c.MatrixWorld()
+	mw.ExtractBasis(&right, &up, &back)
+	right.Normalize()
+	up.Normalize()
+	back.Normalize()
+
+	// Gets the tangents of the half angles of the frustum, reduced by the margin
+	tanV := math32.Tan(math32.DegToRad(c.fov / 2))
+	tanH := tanV
+	if c.axis == Vertical {
+		tanH *= c.aspect
+	} else {
+		tanV /= c.aspect
+	}
+	tanV /= 1 + margin
+	tanH /= 1 + margin
+
+	// Computes the extents of the box corners relative to its center along the camera axes
+	// and the distance at which all the corners are inside the perspective frustum
+	var dist, halfW, halfH, zmin, zmax float32
+	var corner math32.Vector3
+	for i := 0; i < 8; i++ {
+		corner = box.Min
+		if i&1 != 0 {
+			corner.X = box.Max.X
+		}
+		if i&2 != 0 {
+			corner.Y = box.Max.Y
+		}
+		if i&4 != 0 {
+			corner.Z = box.Max.Z
+		}
+		corner.Sub(&center)
+		x := math32.Abs(corner.Dot(&right))
+		y := math32.Abs(corner.Dot(&up))
+		z := -corner.Dot(&back)
+		dist = math32.Max(dist, math32.Max(x/tanH, y/tanV)-z)
+		halfW = math32.Max(halfW, x)
+		halfH = math32.Max(halfH, y)
+		zmin = math32.Min(zmin, z)
+		zmax = math32.Max(zmax, z)
+	}
+
+	// The orthographic size is set for the distance at which the perspective frustum
+	// has the same size, as OrbitControl zooms, so switching the projection keeps the framing
+	if c.proj == Orthographic {
+		halfH = math32.Max(halfH, halfW*tanV/tanH)
+		dist = halfH / tanV
+		c.size = 2 * halfH * (1 + margin)
+		if c.axis == Horizontal {
+			c.size *= c.aspect
+		}
+	}
+
+	// Keeps the box in front of the near plane and behind the far plane
+	dist = math32.Max(dist, c.near-zmin)
+	if dist+zmax > c.far {
+		c.far = dist + zmax
+	}
+	c.projChanged = true
+	c.SetPositionVec(back.MultiplyScalar(dist).Add(&center))
+	return center
+}
+
+// FitToNode moves the camera as FitToBox so the bounding box in world coordinates
+// of the specified node and its descendants fills the viewport with the specified margin.
+// Returns the center of the bounding box.
+func (c *Camera) FitToNode(inode core.INode, margin float32) math32.Vector3 {
+
+	inode.UpdateMatrixWorld()
+	box := inode.BoundingBox()
+	return c.FitToBox(&box, margin)
+}
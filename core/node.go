@@ -19,6 +19,7 @@ type INode interface {
 	GetINode() INode
 	Visible() bool
 	SetVisible(state bool)
+	EffectiveVisible() bool
 	Name() string
 	SetName(string)
 	Parent() INode
@@ -38,8 +39,12 @@ type INode interface {
 // Node events.
 const (
 	OnDescendant = "core.OnDescendant" // Dispatched when a descendent is added or removed
+	OnVisibility = "core.OnVisibility" // Dispatched to a node and its descendants when their effective visibility is changed by SetVisible
 )
 
+// AllLayers is the layer mask which includes all the 32 layers.
+const AllLayers uint32 = 0xFFFFFFFF
+
 // Node represents an object in 3D space existing within a hierarchy.
 type Node struct {
 	Dispatcher                 // Embedded event dispatcher
@@ -52,6 +57,7 @@ type Node struct {
 	matNeedsUpdate bool        // Whether the the local matrix needs to be updated because position or scale has changed
 	rotNeedsUpdate bool        // Whether the euler rotation and local matrix need to be updated because the quaternion has changed
 	userData       interface{} // Generic user data
+	layers         uint32      // Bitmask of the layers the node belongs to
 
 	// Spatial properties
 	position   math32.Vector3    // Node position in 3D space (relative to parent)
@@ -82,6 +88,7 @@ func (n *Node) Init(inode INode) {
 	n.inode = inode
 	n.children = make([]INode, 0)
 	n.visible = true
+	n.layers = 1
 
 	// Initialize spatial properties
 	n.position.Set(0, 0, 0)
@@ -138,41 +145,46 @@ func (n *Node) Dispose() {
 	}
 }
 
-// Clone clones the Node and satisfies the INode interface.
+// Clone clones the node and its descendants and satisfies the INode interface.
+// The clone has no parent, keeps the name of the node, without the " (Clone)" suffix
+// added by earlier versions, and the descendants are cloned with their own Clone methods.
 func (n *Node) Clone() INode {
 
 	clone := new(Node)
+	clone.InitClone(clone, n)
+	return clone
+}
 
-	// TODO clone Dispatcher?
-	clone.Dispatcher.Initialize()
-
-	clone.inode = clone
-	clone.parent = n.parent
-	clone.name = n.name + " (Clone)" // TODO append count?
-	clone.loaderID = n.loaderID
-	clone.visible = n.visible
-	clone.userData = n.userData
+// InitClone initializes this node, embedded in the specified INode, as a copy of the
+// specified node without parent and adds clones of its children. It should be called
+// by the Clone methods of the types which embed a Node.
+func (n *Node) InitClone(inode INode, src *Node) {
 
-	// Update matrix world and rotation if necessary
-	n.UpdateMatrixWorld()
-	n.Rotation()
+	n.Dispatcher.Initialize()
+	n.inode = inode
+	n.parent = nil
+	n.name = src.name
+	n.loaderID = src.loaderID
+	n.visible = src.visible
+	n.layers = src.layers
+	n.matNeedsUpdate = src.matNeedsUpdate
+	n.rotNeedsUpdate = src.rotNeedsUpdate
+	n.userData = src.userData
 
 	// Clone spatial properties
-	clone.position = n.position
-	clone.scale = n.scale
-	clone.direction = n.direction
-	clone.rotation = n.rotation
-	clone.quaternion = n.quaternion
-	clone.matrix = n.matrix
-	clone.matrixWorld = n.matrixWorld
-	clone.children = make([]INode, 0)
+	n.position = src.position
+	n.scale = src.scale
+	n.direction = src.direction
+	n.rotation = src.rotation
+	n.quaternion = src.quaternion
+	n.matrix = src.matrix
+	n.matrixWorld = src.matrixWorld
 
 	// Clone children recursively
-	for _, child := range n.children {
-		clone.Add(child.Clone())
+	n.children = make([]INode, 0, len(src.children))
+	for _, child := range src.children {
+		n.Add(child.Clone())
 	}
-
-	return clone
 }
 
 // Parent returns the parent.
@@ -210,10 +222,18 @@ func (n *Node) LoaderID() string {
 }
 
 // SetVisible sets the visibility of the node.
+// The visibility of the descendants is preserved but they are only
+// effectively visible if all their ancestors are also visible.
 func (n *Node) SetVisible(state bool) {
 
-	n.visible = state
 	n.matNeedsUpdate = true
+	if state == n.visible {
+		return
+	}
+	n.visible = state
+	if n.parent == nil || n.parent.EffectiveVisible() {
+		dispatchVisibility(n.inode)
+	}
 }
 
 // Visible returns the visibility of the node.
@@ -222,6 +242,30 @@ func (n *Node) Visible() bool {
 	return n.visible
 }
 
+// EffectiveVisible returns if the node and all its ancestors are visible.
+func (n *Node) EffectiveVisible() bool {
+
+	if !n.visible {
+		return false
+	}
+	if n.parent == nil {
+		return true
+	}
+	return n.parent.EffectiveVisible()
+}
+
+// dispatchVisibility dispatches OnVisibility to the specified node
+// and to its descendants whose effective visibility depends on it.
+func dispatchVisibility(inode INode) {
+
+	inode.Dispatch(OnVisibility, nil)
+	for _, child := range inode.Children() {
+		if child.Visible() {
+			dispatchVisibility(child)
+		}
+	}
+}
+
 // SetChanged sets the matNeedsUpdate flag of the node.
 func (n *Node) SetChanged(changed bool) {
 
@@ -234,6 +278,38 @@ func (n *Node) Changed() bool {
 	return n.matNeedsUpdate
 }
 
+// SetLayers sets the bitmask of the layers the node belongs to. By default a node only belongs to layer 0.
+// The node is only rendered by the cameras and picked by the raycasters whose layer masks include one of its layers.
+// The layers of a node don't affect its children.
+func (n *Node) SetLayers(mask uint32) {
+
+	n.layers = mask
+}
+
+// Layers returns the bitmask of the layers the node belongs to.
+func (n *Node) Layers() uint32 {
+
+	return n.layers
+}
+
+// EnableLayer adds the node to the specified layer, from 0 to 31.
+func (n *Node) EnableLayer(layer int) {
+
+	n.layers |= 1 << uint(layer)
+}
+
+// DisableLayer removes the node from the specified layer, from 0 to 31.
+func (n *Node) DisableLayer(layer int) {
+
+	n.layers &^= 1 << uint(layer)
+}
+
+// InLayers returns whether the node belongs to any of the layers of the specified mask.
+func (n *Node) InLayers(mask uint32) bool {
+
+	return n.layers&mask != 0
+}
+
 // SetUserData sets the generic user data associated to the node.
 func (n *Node) SetUserData(data interface{}) {
 
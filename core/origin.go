@@ -0,0 +1,129 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// OnOriginShift is the event dispatched by an OriginShifter, to its own subscribers
+// and to the subscribers of its root node, after shifting the world origin.
+// The event object is a pointer to an OriginShiftEvent.
+const OnOriginShift = "core.OnOriginShift"
+
+// OriginShiftEvent describes a shift of the world origin.
+type OriginShiftEvent struct {
+	Offset math32.Vector3 // Translation added to all the world positions
+	Origin math32.Vector3 // Absolute position of the new origin
+}
+
+// OriginShifter keeps a reference node, usually the camera, near the world origin,
+// avoiding the jitter caused by the float32 precision of positions far from the origin
+// in large worlds. When the reference node gets farther from the origin than a threshold,
+// all the children of the root node are translated so the reference node is at the
+// origin again, and the OnOriginShift event is dispatched so systems which keep their
+// own world positions can shift them too. The physics simulations of the root node
+// subscribe to it and shift their bodies. Audio players and listeners follow their
+// nodes, and the cloth simulation runs in the local coordinates of its mesh, so they
+// need no notification. The engine has no particle system: application particle
+// systems which keep world positions must subscribe to the event and shift them.
+// The root node, normally the scene, should not be transformed itself.
+type OriginShifter struct {
+	Dispatcher                // Embedded event dispatcher
+	root       INode          // Node whose children are shifted
+	reference  INode          // Node kept near the origin
+	threshold  float32        // Distance from the origin which triggers a shift
+	origin     math32.Vector3 // Absolute position of the current origin
+}
+
+// NewOriginShifter creates and returns a pointer to a new origin shifter which shifts the
+// children of the specified root node when the specified reference node gets farther than
+// threshold from the origin. Update must be called once per frame.
+func NewOriginShifter(root, reference INode, threshold float32) *OriginShifter {
+
+	sh := new(OriginShifter)
+	sh.Dispatcher.Initialize()
+	sh.root = root
+	sh.reference = reference
+	sh.threshold = threshold
+	return sh
+}
+
+// SetReference sets the node which is kept near the origin.
+func (sh *OriginShifter) SetReference(reference INode) {
+
+	sh.reference = reference
+}
+
+// Reference returns the node which is kept near the origin.
+func (sh *OriginShifter) Reference() INode {
+
+	return sh.reference
+}
+
+// SetThreshold sets the distance from the origin which triggers a shift.
+func (sh *OriginShifter) SetThreshold(threshold float32) {
+
+	sh.threshold = threshold
+}
+
+// Threshold returns the distance from the origin which triggers a shift.
+func (sh *OriginShifter) Threshold() float32 {
+
+	return sh.threshold
+}
+
+// Origin returns the absolute position of the current origin, that is,
+// the sum of the opposite of all the offsets of the shifts done.
+func (sh *OriginShifter) Origin() math32.Vector3 {
+
+	return sh.origin
+}
+
+// ToAbsolute converts the specified world position to an absolute
+// position independent of the shifts and returns the pointer to it.
+func (sh *OriginShifter) ToAbsolute(pos *math32.Vector3) *math32.Vector3 {
+
+	return pos.Add(&sh.origin)
+}
+
+// FromAbsolute converts the specified absolute position to
+// the current world position and returns the pointer to it.
+func (sh *OriginShifter) FromAbsolute(pos *math32.Vector3) *math32.Vector3 {
+
+	return pos.Sub(&sh.origin)
+}
+
+// Update shifts the world origin to the position of the reference node if it is
+// farther than the threshold from the origin. Returns true if the origin was shifted.
+func (sh *OriginShifter) Update() bool {
+
+	if sh.reference == nil || sh.threshold <= 0 {
+		return false
+	}
+	var pos math32.Vector3
+	sh.reference.GetNode().WorldPosition(&pos)
+	if pos.LengthSq() <= sh.threshold*sh.threshold {
+		return false
+	}
+	sh.Shift(pos.Negate())
+	return true
+}
+
+// Shift adds the specified offset to the positions of all the
+// children of the root node and dispatches the OnOriginShift event.
+func (sh *OriginShifter) Shift(offset *math32.Vector3) {
+
+	for _, child := range sh.root.Children() {
+		node := child.GetNode()
+		pos := node.Position()
+		node.SetPositionVec(pos.Add(offset))
+	}
+	sh.origin.Sub(offset)
+
+	ev := &OriginShiftEvent{Offset: *offset, Origin: sh.origin}
+	sh.Dispatch(OnOriginShift, ev)
+	sh.root.GetNode().Dispatch(OnOriginShift, ev)
+}
@@ -0,0 +1,494 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cloth implements a simple mass-spring cloth simulation
+// which deforms the vertices of an indexed geometry.
+// WARNING: This package is experimental and incomplete!
+package cloth
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Cloth simulates the vertices of a triangle mesh geometry as particles connected
+// by springs, integrated with Verlet integration and relaxed iteratively.
+// Structural and shear springs are created along the edges of the triangles
+// and bending springs between the opposite vertices of adjacent triangles.
+// The simulation runs in the local coordinates of the geometry, so the positions
+// of the pinned particles, the gravity, the wind and the colliders are specified
+// in the coordinate system of the mesh which uses the geometry.
+type Cloth struct {
+	geom       *geometry.Geometry // Simulated geometry
+	particles  []particle         // Particles at the geometry vertices
+	springs    []spring           // Springs between the particles
+	triangles  []uint32           // Vertex indices of the triangles
+	colliders  []Collider         // Colliders which the particles cannot penetrate
+	gravity    math32.Vector3     // Gravity acceleration
+	wind       math32.Vector3     // Wind velocity
+	drag       float32            // Aerodynamic drag coefficient of the wind
+	damping    float32            // Velocity damping per step (0 to 1)
+	stiffness  float32            // Stiffness of the structural and shear springs (0 to 1)
+	bending    float32            // Stiffness of the bending springs (0 to 1)
+	iterations int                // Number of constraint relaxation iterations per step
+	timeStep   float32            // Fixed simulation time step
+	maxSteps   int                // Maximum number of steps per frame
+	elapsed    float32            // Accumulated time not yet simulated
+	positions  []float32          // Preallocated buffer of vertex positions
+	normals    []float32          // Preallocated buffer of vertex normals
+}
+
+// particle is a point mass at a geometry vertex
+type particle struct {
+	pos     math32.Vector3 // Current position
+	prev    math32.Vector3 // Position in the previous step
+	force   math32.Vector3 // Accumulated external force
+	invMass float32        // Inverse of the mass (0 if pinned)
+	pinned  bool           // Particle is fixed in its position
+}
+
+// spring connects two particles keeping them at their rest distance
+type spring struct {
+	a, b int     // Indices of the particles
+	rest float32 // Rest distance
+	bend bool    // Bending spring
+}
+
+// NewCloth creates and returns a pointer to a new cloth simulation for the specified indexed
+// triangles geometry with the specified total mass distributed equally among its vertices.
+// The geometry vertices must not be duplicated along the triangle edges, as in the
+// geometries created by geometry.NewSegmentedPlane.
+func NewCloth(geom *geometry.Geometry, mass float32) (*Cloth, error) {
+
+	vbo := geom.VBO(gls.VertexPosition)
+	if vbo == nil {
+		return nil, fmt.Errorf("geometry has no vertex positions")
+	}
+	indices := geom.Indices()
+	if indices.Size() == 0 || indices.Size()%3 != 0 {
+		return nil, fmt.Errorf("geometry is not an indexed triangles geometry")
+	}
+
+	c := new(Cloth)
+	c.geom = geom
+	c.gravity.Set(0, -9.8, 0)
+	c.drag = 1
+	c.damping = 0.01
+	c.stiffness = 1
+	c.bending = 0.5
+	c.iterations = 8
+	c.timeStep = 1.0 / 120
+	c.maxSteps = 8
+
+	// Creates one particle for each vertex
+	geom.ReadVertices(func(vertex math32.Vector3) bool {
+		c.particles = append(c.particles, particle{pos: vertex, prev: vertex})
+		return false
+	})
+	if len(c.particles) == 0 {
+		return nil, fmt.Errorf("geometry has no vertices")
+	}
+	invMass := float32(len(c.particles)) / mass
+	for i := range c.particles {
+		c.particles[i].invMass = invMass
+	}
+	c.triangles = append([]uint32(nil), indices...)
+	for _, idx := range c.triangles {
+		if int(idx) >= len(c.particles) {
+			return nil, fmt.Errorf("vertex index:%d out of range", idx)
+		}
+	}
+	c.createSprings()
+
+	// The geometry buffers are updated every frame
+	vbo.SetUsage(gls.DYNAMIC_DRAW)
+	if nvbo := geom.VBO(gls.VertexNormal); nvbo != nil {
+		nvbo.SetUsage(gls.DYNAMIC_DRAW)
+	}
+	c.positions = make([]float32, 3*len(c.particles))
+	c.normals = make([]float32, 3*len(c.particles))
+	return c, nil
+}
+
+// createSprings creates a spring for each triangle edge and a bending
+// spring for each pair of triangles which share an edge.
+func (c *Cloth) createSprings() {
+
+	type edge struct{ a, b uint32 }
+	opposite := make(map[edge]uint32)
+	for t := 0; t < len(c.triangles); t += 3 {
+		for i := 0; i < 3; i++ {
+			a := c.triangles[t+i]
+			b := c.triangles[t+(i+1)%3]
+			o := c.triangles[t+(i+2)%3]
+			if a > b {
+				a, b = b, a
+			}
+			e := edge{a, b}
+			other, ok := opposite[e]
+			if !ok {
+				opposite[e] = o
+				c.addSpring(int(a), int(b), false)
+				continue
+			}
+			if other != o {
+				c.addSpring(int(other), int(o), true)
+			}
+		}
+	}
+}
+
+// addSpring adds a spring between the specified particles with their current distance as rest distance.
+func (c *Cloth) addSpring(a, b int, bend bool) {
+
+	rest := c.particles[a].pos.DistanceTo(&c.particles[b].pos)
+	c.springs = append(c.springs, spring{a, b, rest, bend})
+}
+
+// Geometry returns the simulated geometry.
+func (c *Cloth) Geometry() *geometry.Geometry {
+
+	return c.geom
+}
+
+// ParticleCount returns the number of particles, which is the number of geometry vertices.
+func (c *Cloth) ParticleCount() int {
+
+	return len(c.particles)
+}
+
+// ParticlePosition returns the current position of the particle with the specified index.
+func (c *Cloth) ParticlePosition(idx int) math32.Vector3 {
+
+	return c.particles[idx].pos
+}
+
+// SetParticlePosition moves the particle with the specified index to the specified position.
+// It is normally used to move pinned particles, e.g. to attach the cloth to a moving object.
+func (c *Cloth) SetParticlePosition(idx int, pos *math32.Vector3) {
+
+	p := &c.particles[idx]
+	p.pos = *pos
+	if p.pinned {
+		p.prev = *pos
+	}
+}
+
+// Pin fixes the particle with the specified index in its current position.
+func (c *Cloth) Pin(idx int) {
+
+	p := &c.particles[idx]
+	p.pinned = true
+	p.prev = p.pos
+}
+
+// Unpin releases the particle with the specified index.
+func (c *Cloth) Unpin(idx int) {
+
+	c.particles[idx].pinned = false
+}
+
+// Pinned returns whether the particle with the specified index is fixed.
+func (c *Cloth) Pinned(idx int) bool {
+
+	return c.particles[idx].pinned
+}
+
+// PinFunc pins all the particles for which the specified function returns true
+// when called with their current position and returns the number of pinned particles.
+func (c *Cloth) PinFunc(cb func(pos math32.Vector3) bool) int {
+
+	count := 0
+	for i := range c.particles {
+		if cb(c.particles[i].pos) {
+			c.Pin(i)
+			count++
+		}
+	}
+	return count
+}
+
+// AddCollider adds a collider which the particles cannot penetrate.
+func (c *Cloth) AddCollider(col Collider) {
+
+	c.colliders = append(c.colliders, col)
+}
+
+// RemoveCollider removes the specified collider.
+// Returns true if found or false otherwise.
+func (c *Cloth) RemoveCollider(col Collider) bool {
+
+	for i, current := range c.colliders {
+		if current == col {
+			copy(c.colliders[i:], c.colliders[i+1:])
+			c.colliders[len(c.colliders)-1] = nil
+			c.colliders = c.colliders[:len(c.colliders)-1]
+			return true
+		}
+	}
+	return false
+}
+
+// SetGravity sets the gravity acceleration. The default is (0, -9.8, 0).
+func (c *Cloth) SetGravity(gravity *math32.Vector3) {
+
+	c.gravity = *gravity
+}
+
+// Gravity returns the gravity acceleration.
+func (c *Cloth) Gravity() math32.Vector3 {
+
+	return c.gravity
+}
+
+// SetWind sets the wind velocity. The default is zero.
+func (c *Cloth) SetWind(wind *math32.Vector3) {
+
+	c.wind = *wind
+}
+
+// Wind returns the wind velocity.
+func (c *Cloth) Wind() math32.Vector3 {
+
+	return c.wind
+}
+
+// SetDrag sets the aerodynamic drag coefficient which scales the force of the wind
+// on the triangles. The default is 1.
+func (c *Cloth) SetDrag(drag float32) {
+
+	c.drag = drag
+}
+
+// Drag returns the aerodynamic drag coefficient.
+func (c *Cloth) Drag() float32 {
+
+	return c.drag
+}
+
+// SetDamping sets the fraction of the velocity of the particles lost at each step (0 to 1).
+// The default is 0.01.
+func (c *Cloth) SetDamping(damping float32) {
+
+	c.damping = math32.Clamp(damping, 0, 1)
+}
+
+// Damping returns the fraction of the velocity of the particles lost at each step.
+func (c *Cloth) Damping() float32 {
+
+	return c.damping
+}
+
+// SetStiffness sets the stiffness of the structural and shear springs
+// and of the bending springs (0 to 1). The defaults are 1 and 0.5.
+func (c *Cloth) SetStiffness(stiffness, bending float32) {
+
+	c.stiffness = math32.Clamp(stiffness, 0, 1)
+	c.bending = math32.Clamp(bending, 0, 1)
+}
+
+// Stiffness returns the stiffness of the structural and shear springs and of the bending springs.
+func (c *Cloth) Stiffness() (stiffness, bending float32) {
+
+	return c.stiffness, c.bending
+}
+
+// SetIterations sets the number of constraint relaxation iterations per step.
+// More iterations make the cloth less stretchy. The default is 8.
+func (c *Cloth) SetIterations(iterations int) {
+
+	c.iterations = iterations
+}
+
+// Iterations returns the number of constraint relaxation iterations per step.
+func (c *Cloth) Iterations() int {
+
+	return c.iterations
+}
+
+// SetTimeStep sets the fixed time step of the simulation in seconds and the maximum number
+// of steps done per frame. The defaults are 1/120 seconds and 8 steps.
+func (c *Cloth) SetTimeStep(timeStep float32, maxSteps int) {
+
+	c.timeStep = timeStep
+	c.maxSteps = maxSteps
+}
+
+// Step advances the simulation by the specified frame time in seconds using fixed
+// time steps and updates the positions and normals of the geometry.
+// It should be called once per frame.
+func (c *Cloth) Step(frameDelta float32) {
+
+	c.elapsed += frameDelta
+	steps := 0
+	for c.elapsed >= c.timeStep && steps < c.maxSteps {
+		c.step(c.timeStep)
+		c.elapsed -= c.timeStep
+		steps++
+	}
+	// Discards the time which could not be simulated
+	if steps == c.maxSteps {
+		c.elapsed = 0
+	}
+	if steps > 0 {
+		c.updateGeometry()
+	}
+}
+
+// step advances the simulation by one time step.
+func (c *Cloth) step(dt float32) {
+
+	c.applyWind(dt)
+
+	// Verlet integration
+	dt2 := dt * dt
+	keep := 1 - c.damping
+	for i := range c.particles {
+		p := &c.particles[i]
+		if p.pinned {
+			p.force.Zero()
+			continue
+		}
+		var accel, vel math32.Vector3
+		accel = p.force
+		accel.MultiplyScalar(p.invMass).Add(&c.gravity).MultiplyScalar(dt2)
+		vel.SubVectors(&p.pos, &p.prev).MultiplyScalar(keep)
+		p.prev = p.pos
+		p.pos.Add(&vel).Add(&accel)
+		p.force.Zero()
+	}
+
+	// Relaxes the springs and solves the collisions
+	for it := 0; it < c.iterations; it++ {
+		for i := range c.springs {
+			c.relax(&c.springs[i])
+		}
+		for _, col := range c.colliders {
+			for i := range c.particles {
+				p := &c.particles[i]
+				if !p.pinned {
+					col.Collide(&p.pos)
+				}
+			}
+		}
+	}
+}
+
+// applyWind adds to the particles the force of the wind on the triangles,
+// which is proportional to the triangle area and to the component of the
+// wind velocity relative to the triangle along its normal.
+func (c *Cloth) applyWind(dt float32) {
+
+	if c.wind.LengthSq() == 0 || c.drag == 0 {
+		return
+	}
+	var e1, e2, normal, vel, rel math32.Vector3
+	for t := 0; t < len(c.triangles); t += 3 {
+		p1 := &c.particles[c.triangles[t]]
+		p2 := &c.particles[c.triangles[t+1]]
+		p3 := &c.particles[c.triangles[t+2]]
+		e1.SubVectors(&p2.pos, &p1.pos)
+		e2.SubVectors(&p3.pos, &p1.pos)
+		normal.CrossVectors(&e1, &e2)
+		area := normal.Length() / 2
+		if area == 0 {
+			continue
+		}
+		normal.DivideScalar(2 * area)
+
+		// Average velocity of the triangle
+		vel.SubVectors(&p1.pos, &p1.prev)
+		rel.SubVectors(&p2.pos, &p2.prev)
+		vel.Add(&rel)
+		rel.SubVectors(&p3.pos, &p3.prev)
+		vel.Add(&rel).DivideScalar(3 * dt)
+
+		rel.SubVectors(&c.wind, &vel)
+		force := normal
+		force.MultiplyScalar(c.drag * area * normal.Dot(&rel) / 3)
+		p1.force.Add(&force)
+		p2.force.Add(&force)
+		p3.force.Add(&force)
+	}
+}
+
+// relax moves the particles of the specified spring towards its rest distance.
+func (c *Cloth) relax(s *spring) {
+
+	pa := &c.particles[s.a]
+	pb := &c.particles[s.b]
+	wa := pa.invMass
+	if pa.pinned {
+		wa = 0
+	}
+	wb := pb.invMass
+	if pb.pinned {
+		wb = 0
+	}
+	if wa+wb == 0 {
+		return
+	}
+	var delta math32.Vector3
+	delta.SubVectors(&pb.pos, &pa.pos)
+	dist := delta.Length()
+	if dist == 0 {
+		return
+	}
+	stiffness := c.stiffness
+	if s.bend {
+		stiffness = c.bending
+	}
+	delta.MultiplyScalar(stiffness * (dist - s.rest) / (dist * (wa + wb)))
+	var move math32.Vector3
+	move = delta
+	pa.pos.Add(move.MultiplyScalar(wa))
+	move = delta
+	pb.pos.Sub(move.MultiplyScalar(wb))
+}
+
+// updateGeometry transfers the positions of the particles and the
+// recomputed vertex normals to the geometry buffers.
+func (c *Cloth) updateGeometry() {
+
+	for i := range c.particles {
+		c.particles[i].pos.ToArray(c.positions, i*3)
+	}
+	err := c.geom.UpdateVBO(gls.VertexPosition, 0, c.positions)
+	if err != nil {
+		log.Error("Cloth update positions: %v", err)
+		return
+	}
+	if c.geom.VBO(gls.VertexNormal) == nil {
+		return
+	}
+
+	// Accumulates the area weighted normals of the triangles at their vertices
+	for i := range c.normals {
+		c.normals[i] = 0
+	}
+	var e1, e2, normal math32.Vector3
+	for t := 0; t < len(c.triangles); t += 3 {
+		i1, i2, i3 := c.triangles[t], c.triangles[t+1], c.triangles[t+2]
+		e1.SubVectors(&c.particles[i2].pos, &c.particles[i1].pos)
+		e2.SubVectors(&c.particles[i3].pos, &c.particles[i1].pos)
+		normal.CrossVectors(&e1, &e2)
+		for _, idx := range [3]uint32{i1, i2, i3} {
+			c.normals[idx*3] += normal.X
+			c.normals[idx*3+1] += normal.Y
+			c.normals[idx*3+2] += normal.Z
+		}
+	}
+	for i := 0; i < len(c.normals); i += 3 {
+		normal.FromArray(c.normals, i)
+		normal.Normalize()
+		normal.ToArray(c.normals, i)
+	}
+	err = c.geom.UpdateVBO(gls.VertexNormal, 0, c.normals)
+	if err != nil {
+		log.Error("Cloth update normals: %v", err)
+	}
+}
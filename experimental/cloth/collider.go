@@ -0,0 +1,143 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cloth
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// Collider is the interface for the shapes which the cloth particles cannot penetrate.
+type Collider interface {
+	// Collide moves the specified position to the surface of the collider
+	// if it is inside the collider and returns true if it was moved.
+	Collide(pos *math32.Vector3) bool
+}
+
+// SphereCollider is a sphere which the cloth particles cannot penetrate.
+type SphereCollider struct {
+	center math32.Vector3 // Center of the sphere
+	radius float32        // Radius of the sphere
+}
+
+// NewSphereCollider creates and returns a pointer to a new sphere
+// collider with the specified center and radius.
+func NewSphereCollider(center *math32.Vector3, radius float32) *SphereCollider {
+
+	sc := new(SphereCollider)
+	sc.center = *center
+	sc.radius = radius
+	return sc
+}
+
+// SetCenter sets the center of the sphere.
+func (sc *SphereCollider) SetCenter(center *math32.Vector3) {
+
+	sc.center = *center
+}
+
+// Center returns the center of the sphere.
+func (sc *SphereCollider) Center() math32.Vector3 {
+
+	return sc.center
+}
+
+// SetRadius sets the radius of the sphere.
+func (sc *SphereCollider) SetRadius(radius float32) {
+
+	sc.radius = radius
+}
+
+// Radius returns the radius of the sphere.
+func (sc *SphereCollider) Radius() float32 {
+
+	return sc.radius
+}
+
+// Collide satisfies the Collider interface.
+func (sc *SphereCollider) Collide(pos *math32.Vector3) bool {
+
+	return pushOut(pos, &sc.center, sc.radius)
+}
+
+// CapsuleCollider is a capsule which the cloth particles cannot penetrate,
+// formed by the points within a radius of a segment.
+type CapsuleCollider struct {
+	start  math32.Vector3 // Start point of the segment
+	end    math32.Vector3 // End point of the segment
+	radius float32        // Radius of the capsule
+}
+
+// NewCapsuleCollider creates and returns a pointer to a new capsule collider
+// with the specified segment end points and radius.
+func NewCapsuleCollider(start, end *math32.Vector3, radius float32) *CapsuleCollider {
+
+	cc := new(CapsuleCollider)
+	cc.start = *start
+	cc.end = *end
+	cc.radius = radius
+	return cc
+}
+
+// SetPoints sets the end points of the segment of the capsule.
+func (cc *CapsuleCollider) SetPoints(start, end *math32.Vector3) {
+
+	cc.start = *start
+	cc.end = *end
+}
+
+// Points returns the end points of the segment of the capsule.
+func (cc *CapsuleCollider) Points() (start, end math32.Vector3) {
+
+	return cc.start, cc.end
+}
+
+// SetRadius sets the radius of the capsule.
+func (cc *CapsuleCollider) SetRadius(radius float32) {
+
+	cc.radius = radius
+}
+
+// Radius returns the radius of the capsule.
+func (cc *CapsuleCollider) Radius() float32 {
+
+	return cc.radius
+}
+
+// Collide satisfies the Collider interface.
+func (cc *CapsuleCollider) Collide(pos *math32.Vector3) bool {
+
+	// Get the point of the segment closest to the position
+	var axis, rel math32.Vector3
+	axis.SubVectors(&cc.end, &cc.start)
+	rel.SubVectors(pos, &cc.start)
+	t := float32(0)
+	if lsq := axis.LengthSq(); lsq > 0 {
+		t = math32.Clamp(rel.Dot(&axis)/lsq, 0, 1)
+	}
+	closest := cc.start
+	closest.Add(axis.MultiplyScalar(t))
+	return pushOut(pos, &closest, cc.radius)
+}
+
+// pushOut moves the specified position to the surface of the sphere with the
+// specified center and radius if it is inside it and returns true if it was moved.
+func pushOut(pos, center *math32.Vector3, radius float32) bool {
+
+	var delta math32.Vector3
+	delta.SubVectors(pos, center)
+	dsq := delta.LengthSq()
+	if dsq >= radius*radius {
+		return false
+	}
+	// Position at the center is moved up
+	if dsq == 0 {
+		delta.Set(0, 1, 0)
+	} else {
+		delta.DivideScalar(math32.Sqrt(dsq))
+	}
+	*pos = *center
+	pos.Add(delta.MultiplyScalar(radius))
+	return true
+}
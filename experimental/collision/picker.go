@@ -0,0 +1,181 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collision
+
+import (
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/renderer"
+	"github.com/g3n/engine/window"
+)
+
+// PickMode specifies how a Picker finds the object at a screen position.
+type PickMode int
+
+// The picking modes.
+const (
+	PickAuto    PickMode = iota // GPU picking if the scene has deformed meshes or too many triangles, raycasting otherwise
+	PickRaycast                 // Raycasting of the geometries of all the objects
+	PickGPU                     // GPU picking of the object followed by raycasting of its geometry
+)
+
+// Picker finds the object of a scene under a screen position, as the position of the cursor,
+// and the point, the normal and the texture coordinates of its surface at that position.
+// It raycasts the geometries of the objects, checking only the triangles found with the bounding
+// volume hierarchies cached by the geometries, or, for scenes with many triangles or with meshes
+// deformed by morph targets or skinning, renders the ids of the objects with the renderer and
+// raycasts only the picked object. The screen positions are in window coordinates as received
+// in the mouse and cursor events and are converted to the current viewport of the window,
+// taking into account its DPI scale. Only the objects in the layers of both the camera and the
+// raycaster are picked.
+type Picker struct {
+	rend         *renderer.Renderer // Renderer used for GPU picking (nil to only raycast)
+	cam          *camera.Camera     // Camera which renders the scene
+	rc           *Raycaster         // Raycaster
+	mode         PickMode           // Picking mode
+	maxTriangles int                // Maximum number of triangles raycast in PickAuto mode
+}
+
+// NewPicker creates and returns a pointer to a new picker for the scenes rendered by the
+// specified renderer with the specified camera. If the renderer is nil it only raycasts.
+func NewPicker(rend *renderer.Renderer, cam *camera.Camera) *Picker {
+
+	p := new(Picker)
+	p.rend = rend
+	p.cam = cam
+	p.rc = NewRaycaster(&math32.Vector3{}, &math32.Vector3{X: 0, Y: 0, Z: -1})
+	p.mode = PickAuto
+	p.maxTriangles = 100000
+	return p
+}
+
+// SetMode sets the picking mode.
+func (p *Picker) SetMode(mode PickMode) {
+
+	p.mode = mode
+}
+
+// Mode returns the picking mode.
+func (p *Picker) Mode() PickMode {
+
+	return p.mode
+}
+
+// SetMaxRaycastTriangles sets the maximum number of triangles of the scene which are raycast
+// in the PickAuto mode. Scenes with more triangles use GPU picking. The default is 100000.
+func (p *Picker) SetMaxRaycastTriangles(count int) {
+
+	p.maxTriangles = count
+}
+
+// MaxRaycastTriangles returns the maximum number of triangles of the scene which are raycast in the PickAuto mode.
+func (p *Picker) MaxRaycastTriangles() int {
+
+	return p.maxTriangles
+}
+
+// Raycaster returns the raycaster used by this picker, for example to set its precision for lines and points.
+func (p *Picker) Raycaster() *Raycaster {
+
+	return p.rc
+}
+
+// Pick returns the intersection with the nearest object of the specified scene at the specified
+// position in window coordinates or nil if there is none. If an object deformed by morph targets
+// or skinning is picked by the GPU but its undeformed geometry is not intersected by the ray,
+// only the Object field of the intersection is set and its Distance is infinite.
+func (p *Picker) Pick(scene core.INode, x, y float32) (*Intersect, error) {
+
+	px, py, ok := p.viewportCoords(x, y)
+	if !ok {
+		return nil, nil
+	}
+	scene.UpdateMatrixWorld()
+	_, _, vw, vh := window.Get().Gls().GetViewport()
+	err := p.rc.SetFromCamera(p.cam, 2*px/float32(vw)-1, 2*py/float32(vh)-1)
+	if err != nil {
+		return nil, err
+	}
+	mask := p.rc.LayerMask
+	p.rc.LayerMask &= p.cam.LayerMask()
+	defer func() { p.rc.LayerMask = mask }()
+
+	// Raycasts all the objects of the scene
+	if p.rend == nil || p.mode == PickRaycast || (p.mode == PickAuto && !p.needsGPU(scene)) {
+		intersects := p.rc.IntersectObject(scene, true)
+		if len(intersects) == 0 {
+			return nil, nil
+		}
+		return &intersects[0], nil
+	}
+
+	// Picks the object with the GPU and raycasts only the picked object
+	igr, err := p.rend.PickLayers(scene, p.cam, int(px), int(py), p.rc.LayerMask)
+	if igr == nil || err != nil {
+		return nil, err
+	}
+	intersects := p.rc.IntersectObject(igr, false)
+	if len(intersects) > 0 {
+		return &intersects[0], nil
+	}
+	return &Intersect{Object: igr, Distance: math32.Inf(1), Group: -1, Instance: -1}, nil
+}
+
+// viewportCoords converts the specified position in window coordinates to the coordinates
+// in pixels of the frame buffer relative to the current viewport, with the origin at the
+// bottom left corner, and returns if the position is inside the viewport.
+func (p *Picker) viewportCoords(x, y float32) (float32, float32, bool) {
+
+	win := window.Get()
+	scaleX, scaleY := win.GetScale()
+	_, fbHeight := win.GetFramebufferSize()
+	vx, vy, vw, vh := win.Gls().GetViewport()
+	px := x*float32(scaleX) - float32(vx)
+	py := float32(fbHeight) - y*float32(scaleY) - float32(vy)
+	return px, py, px >= 0 && py >= 0 && px < float32(vw) && py < float32(vh)
+}
+
+// needsGPU returns if the specified scene has visible meshes deformed by morph targets
+// or skinning, which can't be raycast, or more triangles than the maximum raycast.
+func (p *Picker) needsGPU(scene core.INode) bool {
+
+	triangles := 0
+	var check func(inode core.INode) bool
+	check = func(inode core.INode) bool {
+		if !inode.Visible() {
+			return false
+		}
+		if igr, ok := inode.(graphic.IGraphic); ok {
+			geom := igr.GetGeometry()
+			gr := igr.GetGraphic()
+			if _, ok := geom.ShaderDefines["MORPHTARGETS"]; ok {
+				return true
+			}
+			if _, ok := gr.ShaderDefines["BONE_INFLUENCERS"]; ok {
+				return true
+			}
+			count := geom.Items() / 3
+			if indices := geom.Indices(); indices.Size() > 0 {
+				count = indices.Size() / 3
+			}
+			if im, ok := inode.(*graphic.InstancedMesh); ok {
+				count *= im.InstanceCount()
+			}
+			triangles += count
+			if triangles > p.maxTriangles {
+				return true
+			}
+		}
+		for _, child := range inode.Children() {
+			if check(child) {
+				return true
+			}
+		}
+		return false
+	}
+	return check(scene)
+}
@@ -33,6 +33,10 @@ type Raycaster struct {
 	// a point when checking intersects with points.
 	// The default value is 0.1
 	PointPrecision float32
+	// Bitmask of the layers of the nodes which are checked.
+	// The nodes which don't belong to any of these layers are ignored,
+	// but not their children. The default value includes all the layers.
+	LayerMask uint32
 	// This field must be set with the camera view matrix used
 	// when checking for sprite intersections.
 	// It is set automatically when using camera.SetRaycaster
@@ -56,6 +60,22 @@ type Intersect struct {
 	// index in the positions buffer of the vertex intersected
 	// or the first vertex of the insersected face.
 	Index uint32
+	// Index of the intersected triangle of a mesh or of the
+	// intersected segment of lines or -1 for points and sprites.
+	Face int
+	// Normal in world coordinates of the intersected mesh face,
+	// facing the origin of the ray.
+	Normal math32.Vector3
+	// Texture coordinates interpolated at the point of intersection
+	// with a mesh face if the geometry has texture coordinates.
+	UV math32.Vector2
+	// Index of the geometry group of the intersected mesh face
+	// or -1 if the geometry has no groups or it is not a mesh.
+	Group int
+	// Material of the intersected mesh face or nil if it is not a mesh.
+	Material material.IMaterial
+	// Index of the intersected instance of an instanced mesh or -1 otherwise.
+	Instance int
 }
 
 // NewRaycaster creates and returns a pointer to a new raycaster object
@@ -68,6 +88,7 @@ func NewRaycaster(origin, direction *math32.Vector3) *Raycaster {
 	rc.Far = math32.Inf(1)
 	rc.LinePrecision = 0.1
 	rc.PointPrecision = 0.1
+	rc.LayerMask = core.AllLayers
 	return rc
 }
 
@@ -108,17 +129,22 @@ func (rc *Raycaster) intersectObject(inode core.INode, intersects *[]Intersect,
 		return
 	}
 
-	switch in := inode.(type) {
-	case *graphic.Sprite:
-		rc.RaycastSprite(in, intersects)
-	case *graphic.Points:
-		rc.RaycastPoints(in, intersects)
-	case *graphic.Mesh:
-		rc.RaycastMesh(in, intersects)
-	case *graphic.Lines:
-		rc.RaycastLines(in, intersects)
-	case *graphic.LineStrip:
-		rc.RaycastLineStrip(in, intersects)
+	// Nodes outside the layers of the mask are ignored, but not their children
+	if node.InLayers(rc.LayerMask) {
+		switch in := inode.(type) {
+		case *graphic.Sprite:
+			rc.RaycastSprite(in, intersects)
+		case *graphic.Points:
+			rc.RaycastPoints(in, intersects)
+		case *graphic.Mesh:
+			rc.RaycastMesh(in, intersects)
+		case *graphic.InstancedMesh:
+			rc.RaycastInstancedMesh(in, intersects)
+		case *graphic.Lines:
+			rc.RaycastLines(in, intersects)
+		case *graphic.LineStrip:
+			rc.RaycastLineStrip(in, intersects)
+		}
 	}
 
 	if recursive {
@@ -129,19 +155,41 @@ func (rc *Raycaster) intersectObject(inode core.INode, intersects *[]Intersect,
 	return
 }
 
-// SetRaycaster sets the specified raycaster with this camera position in world coordinates
-// pointing to the direction defined by the specified coordinates unprojected using this camera.
-func (rc *Raycaster) SetFromCamera(cam *camera.Camera, sx, sy float32) error { // TODO maybe use ICamera
-
-	var origin, direction math32.Vector3
-	matrixWorld := cam.MatrixWorld()
-	origin.SetFromMatrixPosition(&matrixWorld)
-	direction.Set(sx, sy, 0.5)
+// SetFromCamera sets this raycaster with a ray through the specified normalized device coordinates
+// (from -1 to 1, with Y up) of the specified camera, whose projection and view matrices must be updated.
+// The ray of a perspective camera starts at the camera position, so the distances of the intersections
+// are measured from the camera, and the ray of an orthographic camera starts at its near plane.
+// Returns an error if the camera matrices can't be inverted.
+func (rc *Raycaster) SetFromCamera(cam camera.ICamera, sx, sy float32) error {
+
+	var proj, view, inv math32.Matrix4
+	cam.ProjMatrix(&proj)
+	cam.ViewMatrix(&view)
+	err := inv.MultiplyMatrices(&proj, &view).GetInverse(&inv)
+	if err != nil {
+		return err
+	}
 
-	unproj := cam.Unproject(&direction) // unproj = direction after this point TODO improve clarity
-	unproj.Sub(&origin).Normalize()
+	// Unprojects the points of the near and far planes at the coordinates
+	near := math32.Vector3{X: sx, Y: sy, Z: -1}
+	near.ApplyProjection(&inv)
+	far := math32.Vector3{X: sx, Y: sy, Z: 1}
+	far.ApplyProjection(&inv)
+	direction := far
+	direction.Sub(&near).Normalize()
+
+	// The projection matrix of a perspective camera maps the depth to W
+	origin := near
+	if proj[11] != 0 {
+		var camWorld math32.Matrix4
+		err = camWorld.GetInverse(&view)
+		if err != nil {
+			return err
+		}
+		origin.SetFromMatrixPosition(&camWorld)
+	}
 	rc.Set(&origin, &direction)
-	cam.ViewMatrix(&rc.ViewMatrix) // Update the view matrix of the raycaster
+	rc.ViewMatrix = view // Update the view matrix of the raycaster
 	return nil
 }
 
@@ -176,11 +224,13 @@ func (rc *Raycaster) RaycastSprite(s *graphic.Sprite, intersects *[]Intersect) {
 	geom := s.GetGeometry()
 	vboPos := geom.VBO(gls.VertexPosition)
 	if vboPos == nil {
-		panic("sprite.Raycast(): VertexPosition VBO not found")
+		return
 	}
-	// Get vertex positions, transform to camera coordinates and
-	// checks intersection with ray
+	// Get vertex positions, which may be interleaved, transform
+	// to camera coordinates and checks intersection with ray
 	buffer := vboPos.Buffer()
+	stride := uint32(vboPos.Stride())
+	offset := vboPos.AttribOffset(gls.VertexPosition)
 	indices := geom.Indices()
 	var v1 math32.Vector3
 	var v2 math32.Vector3
@@ -189,13 +239,13 @@ func (rc *Raycaster) RaycastSprite(s *graphic.Sprite, intersects *[]Intersect) {
 	intersect := false
 	for i := 0; i < indices.Size(); i += 3 {
 		pos := indices[i]
-		buffer.GetVector3(int(pos*5), &v1)
+		buffer.GetVector3(int(pos*stride)+offset, &v1)
 		v1.ApplyMatrix4(&mv)
 		pos = indices[i+1]
-		buffer.GetVector3(int(pos*5), &v2)
+		buffer.GetVector3(int(pos*stride)+offset, &v2)
 		v2.ApplyMatrix4(&mv)
 		pos = indices[i+2]
-		buffer.GetVector3(int(pos*5), &v3)
+		buffer.GetVector3(int(pos*stride)+offset, &v3)
 		v3.ApplyMatrix4(&mv)
 		if ray.IntersectTriangle(&v1, &v2, &v3, false, &point) {
 			intersect = true
@@ -214,11 +264,19 @@ func (rc *Raycaster) RaycastSprite(s *graphic.Sprite, intersects *[]Intersect) {
 		return
 	}
 
+	// Transform intersection point from camera to world coordinates
+	var camWorld math32.Matrix4
+	camWorld.GetInverse(&rc.ViewMatrix)
+	point.ApplyMatrix4(&camWorld)
+
 	// Appends intersection to received parameter.
 	*intersects = append(*intersects, Intersect{
 		Distance: distance,
 		Point:    point,
 		Object:   s,
+		Face:     -1,
+		Group:    -1,
+		Instance: -1,
 	})
 }
 
@@ -268,6 +326,9 @@ func (rc *Raycaster) RaycastPoints(p *graphic.Points, intersects *[]Intersect) {
 			Point:    intersectPoint,
 			Index:    uint32(index),
 			Object:   p,
+			Face:     -1,
+			Group:    -1,
+			Instance: -1,
 		})
 	}
 
@@ -279,15 +340,38 @@ func (rc *Raycaster) RaycastPoints(p *graphic.Points, intersects *[]Intersect) {
 	})
 }
 
-// RaycastMesh
+// RaycastMesh checks intersections between the raycaster and the specified mesh
+// and if any found appends them to the specified intersects array.
 func (rc *Raycaster) RaycastMesh(m *graphic.Mesh, intersects *[]Intersect) {
 
+	matrixWorld := m.MatrixWorld()
+	rc.raycastMesh(m, m, &matrixWorld, -1, intersects)
+}
+
+// RaycastInstancedMesh checks intersections between the raycaster and each instance of the
+// specified instanced mesh and if any found appends them to the specified intersects array.
+func (rc *Raycaster) RaycastInstancedMesh(im *graphic.InstancedMesh, intersects *[]Intersect) {
+
+	// The instance matrices are relative to the mesh
+	matrixWorld := im.MatrixWorld()
+	var instanceWorld math32.Matrix4
+	for i := 0; i < im.InstanceCount(); i++ {
+		instanceMatrix := im.MatrixAt(i)
+		instanceWorld.MultiplyMatrices(&matrixWorld, &instanceMatrix)
+		rc.raycastMesh(im.Mesh, im, &instanceWorld, i, intersects)
+	}
+}
+
+// raycastMesh checks intersections between the raycaster and the specified mesh transformed by the
+// specified world matrix and appends them to the specified intersects array with the specified
+// object and instance index.
+func (rc *Raycaster) raycastMesh(m *graphic.Mesh, obj core.INode, matrixWorld *math32.Matrix4, instance int, intersects *[]Intersect) {
+
 	// Transform this mesh geometry bounding sphere from model
 	// to world coordinates and checks intersection with raycaster
 	geom := m.GetGeometry()
 	sphere := geom.BoundingSphere()
-	matrixWorld := m.MatrixWorld()
-	sphere.ApplyMatrix4(&matrixWorld)
+	sphere.ApplyMatrix4(matrixWorld)
 	if !rc.IsIntersectionSphere(&sphere) {
 		return
 	}
@@ -297,7 +381,7 @@ func (rc *Raycaster) RaycastMesh(m *graphic.Mesh, intersects *[]Intersect) {
 	// the geometry, as is much less expensive to transform the
 	// ray to model coordinates than the geometry to world coordinates.
 	var inverseMatrix math32.Matrix4
-	inverseMatrix.GetInverse(&matrixWorld)
+	inverseMatrix.GetInverse(matrixWorld)
 	var ray math32.Ray
 	ray.Copy(&rc.Ray).ApplyMatrix4(&inverseMatrix)
 	bbox := geom.BoundingBox()
@@ -305,26 +389,63 @@ func (rc *Raycaster) RaycastMesh(m *graphic.Mesh, intersects *[]Intersect) {
 		return
 	}
 
-	// Local function to check the intersection of the ray from the raycaster with
-	// the specified face defined by three poins.
-	checkIntersection := func(mat *material.Material, pA, pB, pC, point *math32.Vector3) *Intersect {
+	// Get the positions and the optional texture coordinates, which may be interleaved
+	vboPos := geom.VBO(gls.VertexPosition)
+	if vboPos == nil {
+		return
+	}
+	positions := vboPos.Buffer()
+	posStride := vboPos.Stride()
+	posOffset := vboPos.AttribOffset(gls.VertexPosition)
+	vboUV := geom.VBO(gls.VertexTexcoord)
+	var uvStride, uvOffset int
+	if vboUV != nil {
+		uvStride = vboUV.Stride()
+		uvOffset = vboUV.AttribOffset(gls.VertexTexcoord)
+	}
+
+	// Returns the index of the vertex at the specified position of the faces
+	indices := geom.Indices()
+	vertex := func(i int) int {
+		if indices.Size() > 0 {
+			return int(indices[i])
+		}
+		return i
+	}
+
+	var normalMatrix math32.Matrix3
+	normalMatrix.GetNormalMatrix(matrixWorld)
+	var pA, pB, pC, point, bary math32.Vector3
+
+	// Checks only the faces in the nodes of the geometry BVH intersected by the ray
+	geom.RaycastFaces(&ray, func(face int) {
+		i := face * 3
+		a, b, c := vertex(i), vertex(i+1), vertex(i+2)
+		positions.GetVector3(a*posStride+posOffset, &pA)
+		positions.GetVector3(b*posStride+posOffset, &pB)
+		positions.GetVector3(c*posStride+posOffset, &pC)
 
+		// Checks intersection of the ray with this face
+		imat := m.GetMaterial(i)
+		if imat == nil {
+			return
+		}
 		var intersect bool
-		switch mat.Side() {
+		switch imat.GetMaterial().Side() {
 		case material.SideBack:
-			intersect = ray.IntersectTriangle(pC, pB, pA, true, point)
+			intersect = ray.IntersectTriangle(&pC, &pB, &pA, true, &point)
 		case material.SideFront:
-			intersect = ray.IntersectTriangle(pA, pB, pC, true, point)
+			intersect = ray.IntersectTriangle(&pA, &pB, &pC, true, &point)
 		case material.SideDouble:
-			intersect = ray.IntersectTriangle(pA, pB, pC, false, point)
+			intersect = ray.IntersectTriangle(&pA, &pB, &pC, false, &point)
 		}
 		if !intersect {
-			return nil
+			return
 		}
 
 		// Transform intersection point from model to world coordinates
-		var intersectionPointWorld = *point
-		intersectionPointWorld.ApplyMatrix4(&matrixWorld)
+		var intersectionPointWorld = point
+		intersectionPointWorld.ApplyMatrix4(matrixWorld)
 
 		// Calculates the distance from the ray origin to intersection point
 		origin := rc.Ray.Origin()
@@ -332,28 +453,44 @@ func (rc *Raycaster) RaycastMesh(m *graphic.Mesh, intersects *[]Intersect) {
 
 		// Checks if distance is between the bounds of the raycaster
 		if distance < rc.Near || distance > rc.Far {
-			return nil
+			return
 		}
 
-		return &Intersect{
+		inter := Intersect{
 			Distance: distance,
 			Point:    intersectionPointWorld,
-			Object:   m,
+			Object:   obj,
+			Index:    uint32(i),
+			Face:     i / 3,
+			Group:    -1,
+			Material: imat,
+			Instance: instance,
+		}
+		math32.Normal(&pA, &pB, &pC, &inter.Normal)
+		inter.Normal.ApplyMatrix3(&normalMatrix).Normalize()
+		if dir := rc.Direction(); inter.Normal.Dot(&dir) > 0 {
+			inter.Normal.Negate()
+		}
+		for g := 0; g < geom.GroupCount(); g++ {
+			group := geom.GroupAt(g)
+			if i >= group.Start && i < group.Start+group.Count {
+				inter.Group = g
+				break
+			}
 		}
-	}
 
-	i := 0
-	geom.ReadFaces(func(vA, vB, vC math32.Vector3) bool {
-		// Checks intersection of the ray with this face
-		mat := m.GetMaterial(i).GetMaterial()
-		var point math32.Vector3
-		intersect := checkIntersection(mat, &vA, &vB, &vC, &point)
-		if intersect != nil {
-			intersect.Index = uint32(i)
-			*intersects = append(*intersects, *intersect)
+		// Interpolates the texture coordinates of the face vertices at the intersection point
+		if vboUV != nil {
+			var uvA, uvB, uvC math32.Vector2
+			uvs := vboUV.Buffer()
+			uvs.GetVector2(a*uvStride+uvOffset, &uvA)
+			uvs.GetVector2(b*uvStride+uvOffset, &uvB)
+			uvs.GetVector2(c*uvStride+uvOffset, &uvC)
+			math32.BarycoordFromPoint(&point, &pA, &pB, &pC, &bary)
+			inter.UV.X = uvA.X*bary.X + uvB.X*bary.Y + uvC.X*bary.Z
+			inter.UV.Y = uvA.Y*bary.X + uvB.Y*bary.Y + uvC.Y*bary.Z
 		}
-		i += 3
-		return false
+		*intersects = append(*intersects, inter)
 	})
 }
 
@@ -434,6 +571,9 @@ func lineRaycast(igr graphic.IGraphic, rc *Raycaster, intersects *[]Intersect, s
 				Point:    interSegment,
 				Index:    uint32(i),
 				Object:   igr,
+				Face:     i / step,
+				Group:    -1,
+				Instance: -1,
 			})
 		}
 		// Checks intersection with individual lines for NON indexed geometry
@@ -460,6 +600,9 @@ func lineRaycast(igr graphic.IGraphic, rc *Raycaster, intersects *[]Intersect, s
 				Point:    interSegment,
 				Index:    uint32(i),
 				Object:   igr,
+				Face:     i / step,
+				Group:    -1,
+				Instance: -1,
 			})
 		}
 	}
@@ -3,3 +3,99 @@
 // license that can be found in the LICENSE file.
 
 package shape
+
+import "github.com/g3n/engine/math32"
+
+// Box is an analytical collision box centered at the origin.
+type Box struct {
+	halfExtents math32.Vector3
+}
+
+// NewBox creates and returns a pointer to a new analytical collision box with the specified half extents.
+func NewBox(halfExtents *math32.Vector3) *Box {
+
+	b := new(Box)
+	b.halfExtents = *halfExtents
+	return b
+}
+
+// SetHalfExtents sets the half extents of the analytical collision box.
+func (b *Box) SetHalfExtents(halfExtents *math32.Vector3) {
+
+	b.halfExtents = *halfExtents
+}
+
+// HalfExtents returns the half extents of the analytical collision box.
+func (b *Box) HalfExtents() math32.Vector3 {
+
+	return b.halfExtents
+}
+
+// Corners returns the 8 corners of the analytical collision box.
+func (b *Box) Corners() [8]math32.Vector3 {
+
+	var corners [8]math32.Vector3
+	h := &b.halfExtents
+	for i := range corners {
+		corners[i].Set(h.X, h.Y, h.Z)
+		if i&1 != 0 {
+			corners[i].X = -h.X
+		}
+		if i&2 != 0 {
+			corners[i].Y = -h.Y
+		}
+		if i&4 != 0 {
+			corners[i].Z = -h.Z
+		}
+	}
+	return corners
+}
+
+// IShape =============================================================
+
+// BoundingBox computes and returns the bounding box of the analytical collision box.
+func (b *Box) BoundingBox() math32.Box3 {
+
+	h := b.halfExtents
+	return math32.Box3{Min: *h.Clone().Negate(), Max: h}
+}
+
+// BoundingSphere computes and returns the bounding sphere of the analytical collision box.
+func (b *Box) BoundingSphere() math32.Sphere {
+
+	return *math32.NewSphere(math32.NewVec3(), b.halfExtents.Length())
+}
+
+// Area computes and returns the surface area of the analytical collision box.
+func (b *Box) Area() float32 {
+
+	h := &b.halfExtents
+	return 8 * (h.X*h.Y + h.Y*h.Z + h.Z*h.X)
+}
+
+// Volume computes and returns the volume of the analytical collision box.
+func (b *Box) Volume() float32 {
+
+	h := &b.halfExtents
+	return 8 * h.X * h.Y * h.Z
+}
+
+// RotationalInertia computes and returns the rotational inertia of the analytical collision box.
+func (b *Box) RotationalInertia(mass float32) math32.Matrix3 {
+
+	h := &b.halfExtents
+	v := mass / 3
+	return *math32.NewMatrix3().Set(
+		v*(h.Y*h.Y+h.Z*h.Z), 0, 0,
+		0, v*(h.X*h.X+h.Z*h.Z), 0,
+		0, 0, v*(h.X*h.X+h.Y*h.Y),
+	)
+}
+
+// ProjectOntoAxis computes and returns the minimum and maximum distances of the analytical collision box projected onto the specified local axis.
+func (b *Box) ProjectOntoAxis(localAxis *math32.Vector3) (float32, float32) {
+
+	h := &b.halfExtents
+	r := math32.Abs(localAxis.X)*h.X + math32.Abs(localAxis.Y)*h.Y + math32.Abs(localAxis.Z)*h.Z
+	return -r, r
+}
@@ -3,3 +3,93 @@
 // license that can be found in the LICENSE file.
 
 package shape
+
+import "github.com/g3n/engine/math32"
+
+// Capsule is an analytical collision capsule centered at the origin along the Y axis,
+// which is a cylinder capped by two hemispheres.
+type Capsule struct {
+	radius float32
+	height float32
+}
+
+// NewCapsule creates and returns a pointer to a new analytical collision capsule
+// with the specified radius and total height including the hemispheres.
+func NewCapsule(radius, height float32) *Capsule {
+
+	c := new(Capsule)
+	c.radius = radius
+	c.height = math32.Max(height, 2*radius)
+	return c
+}
+
+// Radius returns the radius of the analytical collision capsule.
+func (c *Capsule) Radius() float32 {
+
+	return c.radius
+}
+
+// Height returns the total height of the analytical collision capsule including the hemispheres.
+func (c *Capsule) Height() float32 {
+
+	return c.height
+}
+
+// Segment returns the end points of the axis of the analytical collision capsule,
+// which are the centers of the hemispheres.
+func (c *Capsule) Segment() (math32.Vector3, math32.Vector3) {
+
+	h := c.height/2 - c.radius
+	return math32.Vector3{Y: -h}, math32.Vector3{Y: h}
+}
+
+// IShape =============================================================
+
+// BoundingBox computes and returns the bounding box of the analytical collision capsule.
+func (c *Capsule) BoundingBox() math32.Box3 {
+
+	h := c.height / 2
+	return math32.Box3{
+		Min: math32.Vector3{X: -c.radius, Y: -h, Z: -c.radius},
+		Max: math32.Vector3{X: c.radius, Y: h, Z: c.radius},
+	}
+}
+
+// BoundingSphere computes and returns the bounding sphere of the analytical collision capsule.
+func (c *Capsule) BoundingSphere() math32.Sphere {
+
+	return *math32.NewSphere(math32.NewVec3(), c.height/2)
+}
+
+// Area computes and returns the surface area of the analytical collision capsule.
+func (c *Capsule) Area() float32 {
+
+	return 2*math32.Pi*c.radius*(c.height-2*c.radius) + 4*math32.Pi*c.radius*c.radius
+}
+
+// Volume computes and returns the volume of the analytical collision capsule.
+func (c *Capsule) Volume() float32 {
+
+	r2 := c.radius * c.radius
+	return math32.Pi*r2*(c.height-2*c.radius) + 4.0/3.0*math32.Pi*r2*c.radius
+}
+
+// RotationalInertia computes and returns the rotational inertia of the analytical collision capsule,
+// approximated by the one of the solid cylinder with the same total height.
+func (c *Capsule) RotationalInertia(mass float32) math32.Matrix3 {
+
+	r2 := c.radius * c.radius
+	side := mass * (3*r2 + c.height*c.height) / 12
+	return *math32.NewMatrix3().Set(
+		side, 0, 0,
+		0, mass*r2/2, 0,
+		0, 0, side,
+	)
+}
+
+// ProjectOntoAxis computes and returns the minimum and maximum distances of the analytical collision capsule projected onto the specified local axis.
+func (c *Capsule) ProjectOntoAxis(localAxis *math32.Vector3) (float32, float32) {
+
+	r := math32.Abs(localAxis.Y)*(c.height/2-c.radius) + c.radius*localAxis.Length()
+	return -r, r
+}
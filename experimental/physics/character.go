@@ -0,0 +1,683 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/experimental/collision/shape"
+	"github.com/g3n/engine/experimental/physics/object"
+	"github.com/g3n/engine/math32"
+)
+
+// CollisionFlags is a bit mask of the sides of a character controller which collided during a move.
+type CollisionFlags int
+
+// The sides of a character controller which can collide.
+const (
+	CollisionSides CollisionFlags = 1 << iota // Walls and slopes steeper than the slope limit
+	CollisionAbove                            // Ceilings
+	CollisionBelow                            // Walkable ground
+)
+
+// Number of iterations resolving the penetrations after each move step
+const characterIterations = 4
+
+// CharacterController moves a kinematic capsule, as the one of a player character, through the
+// bodies of a simulation: it slides along walls, climbs steps up to the step offset and slopes
+// up to the slope limit, detects the ground and pushes the dynamic bodies it walks into.
+// It is not a body of the simulation and is moved by the application, usually every frame
+// with the desired displacement, including the one due to gravity, by calling Move.
+// It collides with bodies with sphere, plane and convex hull shapes.
+type CharacterController struct {
+	sim          *Simulation
+	node         core.INode     // Optional node moved with the capsule center
+	position     math32.Vector3 // Position of the capsule center
+	up           math32.Vector3 // Up direction
+	radius       float32        // Radius of the capsule
+	height       float32        // Total height of the capsule including the hemispheres
+	stepOffset   float32        // Maximum height of the steps climbed
+	slopeLimit   float32        // Maximum walkable slope angle in radians
+	skinWidth    float32        // Distance from the ground within which the capsule is grounded
+	mass         float32        // Mass used to push dynamic bodies
+	grounded     bool           // Whether the capsule is on walkable ground
+	groundNormal math32.Vector3 // Normal of the ground
+	groundBody   *object.Body   // Body of the ground
+	flags        CollisionFlags // Sides which collided during the last move
+	pushed       map[*object.Body]bool
+}
+
+// characterContact is a contact of the capsule of a character controller with a body.
+type characterContact struct {
+	body    *object.Body
+	normal  math32.Vector3 // Normal pointing from the body to the capsule
+	surface math32.Vector3 // Normal of the surface of the body at the contact point
+	point   math32.Vector3 // Closest point of the body
+	depth   float32        // Penetration depth (negative if separated)
+}
+
+// Types of the move passes of a character controller
+const (
+	passUp = iota
+	passSide
+	passDown
+)
+
+// NewCharacterController creates and returns a pointer to a new character controller with a capsule
+// of the specified radius and total height, colliding with the bodies of the specified simulation.
+// The capsule is vertical along the Y axis, climbs steps of 0.3 and slopes of up to 45 degrees.
+func NewCharacterController(sim *Simulation, radius, height float32) *CharacterController {
+
+	cc := new(CharacterController)
+	cc.sim = sim
+	cc.radius = radius
+	cc.height = math32.Max(height, 2*radius)
+	cc.up.Set(0, 1, 0)
+	cc.stepOffset = 0.3
+	cc.slopeLimit = math32.DegToRad(45)
+	cc.skinWidth = 0.02
+	cc.mass = 80
+	cc.pushed = make(map[*object.Body]bool)
+	return cc
+}
+
+// SetNode sets a node whose position is set to the capsule center after each move, as the model of the character.
+func (cc *CharacterController) SetNode(inode core.INode) {
+
+	cc.node = inode
+	cc.updateNode()
+}
+
+// SetPosition sets the position of the capsule center without checking collisions.
+func (cc *CharacterController) SetPosition(pos *math32.Vector3) {
+
+	cc.position = *pos
+	cc.grounded = false
+	cc.groundBody = nil
+	cc.updateNode()
+}
+
+// Position returns the position of the capsule center.
+func (cc *CharacterController) Position() math32.Vector3 {
+
+	return cc.position
+}
+
+// SetUp sets the up direction of the capsule axis, opposite to the gravity.
+func (cc *CharacterController) SetUp(up *math32.Vector3) {
+
+	cc.up = *up
+	cc.up.Normalize()
+}
+
+// Up returns the up direction of the capsule axis.
+func (cc *CharacterController) Up() math32.Vector3 {
+
+	return cc.up
+}
+
+// SetStepOffset sets the maximum height of the steps the capsule climbs when moving on the ground.
+func (cc *CharacterController) SetStepOffset(offset float32) {
+
+	cc.stepOffset = offset
+}
+
+// StepOffset returns the maximum height of the steps the capsule climbs.
+func (cc *CharacterController) StepOffset() float32 {
+
+	return cc.stepOffset
+}
+
+// SetSlopeLimit sets the maximum angle in radians between the ground and the horizontal
+// the capsule walks on. Steeper slopes block the capsule as walls and it slides down them.
+func (cc *CharacterController) SetSlopeLimit(angle float32) {
+
+	cc.slopeLimit = angle
+}
+
+// SlopeLimit returns the maximum angle in radians of the walkable slopes.
+func (cc *CharacterController) SlopeLimit() float32 {
+
+	return cc.slopeLimit
+}
+
+// SetSkinWidth sets the distance from the ground within which the capsule is grounded.
+func (cc *CharacterController) SetSkinWidth(width float32) {
+
+	cc.skinWidth = width
+}
+
+// SkinWidth returns the distance from the ground within which the capsule is grounded.
+func (cc *CharacterController) SkinWidth() float32 {
+
+	return cc.skinWidth
+}
+
+// SetMass sets the mass of the character used to push the dynamic bodies it walks into,
+// which receive the velocity of an inelastic collision with the character.
+// A zero mass disables pushing.
+func (cc *CharacterController) SetMass(mass float32) {
+
+	cc.mass = mass
+}
+
+// Mass returns the mass of the character used to push the dynamic bodies.
+func (cc *CharacterController) Mass() float32 {
+
+	return cc.mass
+}
+
+// Radius returns the radius of the capsule.
+func (cc *CharacterController) Radius() float32 {
+
+	return cc.radius
+}
+
+// Height returns the total height of the capsule.
+func (cc *CharacterController) Height() float32 {
+
+	return cc.height
+}
+
+// Grounded returns whether the capsule is on walkable ground after the last move.
+func (cc *CharacterController) Grounded() bool {
+
+	return cc.grounded
+}
+
+// GroundNormal returns the normal of the ground under the capsule if grounded.
+func (cc *CharacterController) GroundNormal() math32.Vector3 {
+
+	return cc.groundNormal
+}
+
+// GroundBody returns the body of the ground under the capsule or nil if not grounded.
+func (cc *CharacterController) GroundBody() *object.Body {
+
+	return cc.groundBody
+}
+
+// Flags returns the sides of the capsule which collided during the last move.
+func (cc *CharacterController) Flags() CollisionFlags {
+
+	return cc.flags
+}
+
+// Move moves the capsule by the specified displacement, which happened during the specified
+// time interval in seconds, and returns the sides which collided. The displacement is swept
+// in steps no longer than half the radius, so the capsule doesn't pass through thin bodies,
+// sliding along the bodies it collides with. When on the ground, the capsule is first lifted
+// by the step offset, moved horizontally and lowered back to the ground, so it climbs steps
+// and follows the ground down slopes. The time interval sets the velocity of the pushes.
+func (cc *CharacterController) Move(displacement *math32.Vector3, dt float32) CollisionFlags {
+
+	cc.flags = 0
+	for b := range cc.pushed {
+		delete(cc.pushed, b)
+	}
+
+	// Splits the displacement into vertical and horizontal components
+	vertical := displacement.Dot(&cc.up)
+	horizontal := *displacement
+	horizontal.Sub(cc.up.Clone().MultiplyScalar(vertical))
+
+	// Lifts the capsule by the step offset if moving on the ground
+	lift := float32(0)
+	if cc.grounded && horizontal.LengthSq() > 0 && vertical <= 0 {
+		lift = cc.stepOffset
+	}
+	climb := lift + math32.Max(vertical, 0)
+	if climb > 0 {
+		start := cc.position.Dot(&cc.up)
+		cc.sweep(cc.up.Clone().MultiplyScalar(climb), passUp, dt)
+		lift = math32.Min(lift, cc.position.Dot(&cc.up)-start)
+	}
+
+	// Moves horizontally
+	if horizontal.LengthSq() > 0 {
+		var vel math32.Vector3
+		if dt > 0 {
+			vel = *horizontal.Clone().MultiplyScalar(1 / dt)
+		}
+		cc.sweep(&horizontal, passSide, dt, vel)
+	}
+
+	// Lowers the capsule by the lift and the downward displacement
+	descent := lift + math32.Max(-vertical, 0)
+	if descent > 0 {
+		cc.sweep(cc.up.Clone().MultiplyScalar(-descent), passDown, dt)
+	}
+
+	cc.detectGround()
+	cc.updateNode()
+	return cc.flags
+}
+
+// sweep moves the capsule by the specified displacement in steps, resolving the penetrations
+// after each step as specified by the pass. The optional velocity is used to push bodies.
+func (cc *CharacterController) sweep(disp *math32.Vector3, pass int, dt float32, vel ...math32.Vector3) {
+
+	length := disp.Length()
+	steps := int(math32.Ceil(length / (0.5 * cc.radius)))
+	if steps < 1 {
+		steps = 1
+	}
+	step := disp.Clone().MultiplyScalar(1 / float32(steps))
+	for i := 0; i < steps; i++ {
+		cc.position.Add(step)
+		for iter := 0; iter < characterIterations; iter++ {
+			if !cc.resolve(pass, vel...) {
+				break
+			}
+		}
+	}
+}
+
+// resolve moves the capsule out of the bodies it penetrates and returns whether it was moved.
+func (cc *CharacterController) resolve(pass int, vel ...math32.Vector3) bool {
+
+	minWalkable := math32.Cos(cc.slopeLimit)
+	moved := false
+	for _, c := range cc.contacts(0) {
+		if c.depth <= 0 {
+			continue
+		}
+		// The slope is the one of the surface, so the edges of walkable surfaces, as the ones of steps, are walkable
+		n := c.normal
+		nUp := n.Dot(&cc.up)
+		var corr math32.Vector3
+		switch {
+		case c.surface.Dot(&cc.up) >= minWalkable && nUp > 0:
+			cc.flags |= CollisionBelow
+			if pass == passDown {
+				// Stays on the walkable ground without sliding down the slope
+				corr = *cc.up.Clone().MultiplyScalar(c.depth / nUp)
+			} else {
+				corr = *n.MultiplyScalar(c.depth)
+			}
+		case nUp > 0:
+			// Blocks as a wall the slopes steeper than the limit, sliding down them
+			cc.flags |= CollisionSides
+			h := n
+			h.Sub(cc.up.Clone().MultiplyScalar(nUp))
+			hl := h.Length()
+			corr = *h.MultiplyScalar(c.depth / (hl * hl))
+		default:
+			if nUp < -0.5 {
+				cc.flags |= CollisionAbove
+			} else {
+				cc.flags |= CollisionSides
+			}
+			corr = *n.MultiplyScalar(c.depth)
+		}
+		cc.position.Add(&corr)
+		moved = true
+		if pass == passSide && len(vel) > 0 {
+			cc.push(&c, &vel[0])
+		}
+	}
+	return moved
+}
+
+// push pushes the dynamic body of the specified contact with the velocity
+// of an inelastic collision with the character moving with the specified velocity.
+func (cc *CharacterController) push(c *characterContact, vel *math32.Vector3) {
+
+	b := c.body
+	if cc.mass <= 0 || b.BodyType() != object.Dynamic || cc.pushed[b] {
+		return
+	}
+	dir := c.normal
+	dir.Negate()
+	dir.Sub(cc.up.Clone().MultiplyScalar(dir.Dot(&cc.up)))
+	if dir.LengthSq() == 0 {
+		return
+	}
+	dir.Normalize()
+	speed := vel.Dot(&dir)
+	if speed <= 0 || b.InvMassEff() <= 0 {
+		return
+	}
+	bodyVel := b.Velocity()
+	bodyMass := 1 / b.InvMassEff()
+	dv := (speed - bodyVel.Dot(&dir)) * cc.mass / (cc.mass + bodyMass)
+	if dv <= 0 {
+		return
+	}
+	cc.pushed[b] = true
+	pos := b.Position()
+	rel := c.point
+	rel.Sub(&pos)
+	b.WakeUp()
+	b.ApplyImpulse(dir.MultiplyScalar(dv*bodyMass), &rel)
+}
+
+// detectGround sets the ground of the capsule from the walkable contacts within the skin width.
+func (cc *CharacterController) detectGround() {
+
+	minWalkable := math32.Cos(cc.slopeLimit)
+	cc.grounded = false
+	cc.groundBody = nil
+	best := float32(-1)
+	for _, c := range cc.contacts(cc.skinWidth) {
+		if c.depth < -cc.skinWidth {
+			continue
+		}
+		nUp := c.surface.Dot(&cc.up)
+		if nUp >= minWalkable && nUp > best && c.normal.Dot(&cc.up) > 0 {
+			best = nUp
+			cc.grounded = true
+			cc.groundNormal = c.surface
+			cc.groundBody = c.body
+		}
+	}
+	if cc.grounded {
+		cc.flags |= CollisionBelow
+	}
+}
+
+// updateNode sets the position of the node to the capsule center.
+func (cc *CharacterController) updateNode() {
+
+	if cc.node != nil {
+		cc.node.GetNode().SetPositionVec(&cc.position)
+	}
+}
+
+// segment returns the end points of the axis of the capsule.
+func (cc *CharacterController) segment() (math32.Vector3, math32.Vector3) {
+
+	half := cc.up.Clone().MultiplyScalar(cc.height/2 - cc.radius)
+	a := cc.position
+	a.Sub(half)
+	b := cc.position
+	b.Add(half)
+	return a, b
+}
+
+// contacts returns the deepest contact of the capsule with each body
+// of the simulation closer than the specified margin.
+func (cc *CharacterController) contacts(margin float32) []characterContact {
+
+	a, b := cc.segment()
+	reach := cc.radius + margin
+	var bbox math32.Box3
+	bbox.SetFromPoints([]math32.Vector3{a, b})
+	bbox.ExpandByScalar(reach)
+
+	var contacts []characterContact
+	for _, body := range cc.sim.Bodies() {
+		if body == nil || body.Shape() == nil {
+			continue
+		}
+		pos := body.Position()
+		quat := body.Quaternion()
+		var c characterContact
+		var dist float32
+		switch s := body.Shape().(type) {
+		case *shape.Sphere:
+			if !cc.overlaps(body, &bbox) {
+				continue
+			}
+			p := closestPointSegment(&pos, &a, &b)
+			c.normal.SubVectors(&p, &pos)
+			dist = c.normal.Length() - s.Radius()
+			if c.normal.LengthSq() == 0 {
+				c.normal = cc.up
+			}
+			c.normal.Normalize()
+			c.point = *c.normal.Clone().MultiplyScalar(s.Radius()).Add(&pos)
+			c.surface = c.normal
+		case *shape.Plane:
+			c.normal = s.Normal()
+			c.normal.ApplyQuaternion(quat)
+			da := c.normal.Dot(a.Clone().Sub(&pos))
+			db := c.normal.Dot(b.Clone().Sub(&pos))
+			p := a
+			dist = da
+			if db < da {
+				p = b
+				dist = db
+			}
+			c.point = *c.normal.Clone().MultiplyScalar(-dist).Add(&p)
+			c.surface = c.normal
+		case *shape.ConvexHull:
+			if !cc.overlaps(body, &bbox) {
+				continue
+			}
+			var ok bool
+			dist, ok = hullContact(s, &pos, quat, &a, &b, &cc.up, &c)
+			if !ok {
+				continue
+			}
+		default:
+			continue
+		}
+		if dist > reach {
+			continue
+		}
+		c.body = body
+		c.depth = cc.radius - dist
+		contacts = append(contacts, c)
+	}
+	return contacts
+}
+
+// overlaps returns whether the bounding box of the body intersects the specified box.
+func (cc *CharacterController) overlaps(body *object.Body, bbox *math32.Box3) bool {
+
+	bb := body.BoundingBox()
+	return bb.IsIntersectionBox(bbox)
+}
+
+// hullContact sets the normals and the closest point of the contact of the capsule axis between the
+// specified points with the convex hull, and returns the distance of the axis from the hull surface,
+// negative if inside, and whether the hull has faces. When the closest point is on an edge or vertex
+// the surface normal is the one of the adjacent face nearest to the specified up direction.
+func hullContact(ch *shape.ConvexHull, pos *math32.Vector3, quat *math32.Quaternion, a, b, up *math32.Vector3, c *characterContact) (float32, bool) {
+
+	faces := ch.Faces()
+	normals := ch.FaceNormals()
+	if len(faces) == 0 {
+		return 0, false
+	}
+
+	// Checks if a point of the axis is inside the hull, to push it out through the nearest face
+	mid := *a.Clone().Add(b).MultiplyScalar(0.5)
+	inside := false
+	var best float32
+	for _, p := range []math32.Vector3{*a, mid, *b} {
+		in := true
+		var maxDist float32
+		var maxNormal math32.Vector3
+		for i, face := range faces {
+			wf := ch.WorldFace(face, pos, quat)
+			n := normals[i]
+			n.ApplyQuaternion(quat)
+			d := n.Dot(p.Clone().Sub(&wf[0]))
+			if d >= 0 {
+				in = false
+				break
+			}
+			if i == 0 || d > maxDist {
+				maxDist = d
+				maxNormal = n
+			}
+		}
+		if in && (!inside || maxDist < best) {
+			inside = true
+			best = maxDist
+			c.normal = maxNormal
+			c.surface = maxNormal
+			c.point = *maxNormal.Clone().MultiplyScalar(-maxDist).Add(&p)
+		}
+	}
+	if inside {
+		return best, true
+	}
+
+	// Finds the face nearest to the axis
+	const eps = 1e-4
+	first := true
+	for i, face := range faces {
+		wf := ch.WorldFace(face, pos, quat)
+		ps, pt := closestSegmentTriangle(a, b, &wf)
+		fn := normals[i]
+		fn.ApplyQuaternion(quat)
+		var n math32.Vector3
+		n.SubVectors(&ps, &pt)
+		d := n.Length()
+		if !first && d > best-eps {
+			if d < best+eps && fn.Dot(up) > c.surface.Dot(up) {
+				c.surface = fn
+			}
+			continue
+		}
+		first = false
+		best = d
+		if d > 1e-6 {
+			n.MultiplyScalar(1 / d)
+		} else {
+			n = fn
+		}
+		c.normal = n
+		c.surface = fn
+		c.point = pt
+	}
+	return best, true
+}
+
+// closestPointSegment returns the point of the segment between a and b closest to p.
+func closestPointSegment(p, a, b *math32.Vector3) math32.Vector3 {
+
+	var ab, ap math32.Vector3
+	ab.SubVectors(b, a)
+	ap.SubVectors(p, a)
+	t := float32(0)
+	if l := ab.LengthSq(); l > 0 {
+		t = math32.Clamp(ap.Dot(&ab)/l, 0, 1)
+	}
+	return *ab.MultiplyScalar(t).Add(a)
+}
+
+// closestPointTriangle returns the point of the triangle abc closest to p.
+func closestPointTriangle(p, a, b, c *math32.Vector3) math32.Vector3 {
+
+	var ab, ac, ap, bp, cp math32.Vector3
+	ab.SubVectors(b, a)
+	ac.SubVectors(c, a)
+	ap.SubVectors(p, a)
+	d1 := ab.Dot(&ap)
+	d2 := ac.Dot(&ap)
+	if d1 <= 0 && d2 <= 0 {
+		return *a
+	}
+	bp.SubVectors(p, b)
+	d3 := ab.Dot(&bp)
+	d4 := ac.Dot(&bp)
+	if d3 >= 0 && d4 <= d3 {
+		return *b
+	}
+	vc := d1*d4 - d3*d2
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		return *ab.MultiplyScalar(d1 / (d1 - d3)).Add(a)
+	}
+	cp.SubVectors(p, c)
+	d5 := ab.Dot(&cp)
+	d6 := ac.Dot(&cp)
+	if d6 >= 0 && d5 <= d6 {
+		return *c
+	}
+	vb := d5*d2 - d1*d6
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		return *ac.MultiplyScalar(d2 / (d2 - d6)).Add(a)
+	}
+	va := d3*d6 - d5*d4
+	if va <= 0 && d4-d3 >= 0 && d5-d6 >= 0 {
+		var bc math32.Vector3
+		bc.SubVectors(c, b)
+		return *bc.MultiplyScalar((d4 - d3) / ((d4 - d3) + (d5 - d6))).Add(b)
+	}
+	denom := 1 / (va + vb + vc)
+	ab.MultiplyScalar(vb * denom)
+	ac.MultiplyScalar(vc * denom)
+	return *ab.Add(&ac).Add(a)
+}
+
+// closestSegmentSegment returns the closest points of the segments p1q1 and p2q2.
+func closestSegmentSegment(p1, q1, p2, q2 *math32.Vector3) (math32.Vector3, math32.Vector3) {
+
+	const eps = 1e-8
+	var d1, d2, r math32.Vector3
+	d1.SubVectors(q1, p1)
+	d2.SubVectors(q2, p2)
+	r.SubVectors(p1, p2)
+	a := d1.Dot(&d1)
+	e := d2.Dot(&d2)
+	f := d2.Dot(&r)
+	var s, t float32
+	if a <= eps && e <= eps {
+		return *p1, *p2
+	}
+	if a <= eps {
+		t = math32.Clamp(f/e, 0, 1)
+	} else {
+		c := d1.Dot(&r)
+		if e <= eps {
+			s = math32.Clamp(-c/a, 0, 1)
+		} else {
+			b := d1.Dot(&d2)
+			if denom := a*e - b*b; denom != 0 {
+				s = math32.Clamp((b*f-c*e)/denom, 0, 1)
+			}
+			t = (b*s + f) / e
+			if t < 0 {
+				t = 0
+				s = math32.Clamp(-c/a, 0, 1)
+			} else if t > 1 {
+				t = 1
+				s = math32.Clamp((b-c)/a, 0, 1)
+			}
+		}
+	}
+	return *d1.MultiplyScalar(s).Add(p1), *d2.MultiplyScalar(t).Add(p2)
+}
+
+// closestSegmentTriangle returns the closest points of the segment pq and the triangle.
+func closestSegmentTriangle(p, q *math32.Vector3, tri *[3]math32.Vector3) (math32.Vector3, math32.Vector3) {
+
+	// Checks if the segment crosses the triangle
+	var ab, ac, n, pq math32.Vector3
+	ab.SubVectors(&tri[1], &tri[0])
+	ac.SubVectors(&tri[2], &tri[0])
+	n.CrossVectors(&ab, &ac)
+	pq.SubVectors(q, p)
+	if denom := n.Dot(&pq); denom != 0 {
+		t := n.Dot(tri[0].Clone().Sub(p)) / denom
+		if t >= 0 && t <= 1 {
+			x := *pq.Clone().MultiplyScalar(t).Add(p)
+			if math32.ContainsPoint(&x, &tri[0], &tri[1], &tri[2]) {
+				return x, x
+			}
+		}
+	}
+
+	// Finds the closest of the points of the triangle closest to the segment end points
+	// and of the points of the triangle edges closest to the segment
+	ps := *p
+	pt := closestPointTriangle(p, &tri[0], &tri[1], &tri[2])
+	best := ps.DistanceToSquared(&pt)
+	check := func(s, t math32.Vector3) {
+		if d := s.DistanceToSquared(&t); d < best {
+			best = d
+			ps = s
+			pt = t
+		}
+	}
+	check(*q, closestPointTriangle(q, &tri[0], &tri[1], &tri[2]))
+	for i := 0; i < 3; i++ {
+		check(closestSegmentSegment(p, q, &tri[i], &tri[(i+1)%3]))
+	}
+	return ps, pt
+}
@@ -0,0 +1,218 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/experimental/collision/shape"
+	"github.com/g3n/engine/math32"
+)
+
+// ColliderType is the type of a collider of a collision world.
+type ColliderType int
+
+// The types of colliders.
+const (
+	StaticCollider    ColliderType = iota // Collider which doesn't move, as the level geometry
+	KinematicCollider                     // Collider moved by the application, following its node if any
+)
+
+// Collider is a collision shape with a world transform which is part of a collision world.
+// It collides but doesn't respond to collisions, which are detected by overlap and sweep queries
+// and by trigger colliders. Collider dispatches the OnEnter and OnExit events of the triggers it enters and exits.
+// The supported shapes are sphere, box, capsule, convex hull and plane.
+type Collider struct {
+	core.Dispatcher
+	shape      shape.IShape
+	ctype      ColliderType
+	trigger    bool              // Whether the collider is a trigger volume
+	node       core.INode        // Optional node whose world transform the collider follows
+	position   math32.Vector3    // World position
+	quaternion math32.Quaternion // World orientation
+	group      int               // Collision filter group
+	mask       int               // Collision filter mask
+	userData   interface{}       // Generic user data
+}
+
+// NewCollider creates and returns a pointer to a new collider with the specified shape and type.
+// The collider is at the origin, belongs to the collision filter group 1 and collides with all groups.
+func NewCollider(s shape.IShape, ctype ColliderType) *Collider {
+
+	c := new(Collider)
+	c.Dispatcher.Initialize()
+	c.shape = s
+	c.ctype = ctype
+	c.quaternion.SetIdentity()
+	c.group = 1
+	c.mask = -1
+	return c
+}
+
+// Shape returns the collision shape of the collider.
+func (c *Collider) Shape() shape.IShape {
+
+	return c.shape
+}
+
+// Type returns the type of the collider.
+func (c *Collider) Type() ColliderType {
+
+	return c.ctype
+}
+
+// SetTrigger sets whether the collider is a trigger volume. Triggers don't block sweeps
+// and dispatch OnEnter and OnExit events when other colliders start and stop overlapping them.
+func (c *Collider) SetTrigger(state bool) {
+
+	c.trigger = state
+}
+
+// Trigger returns whether the collider is a trigger volume.
+func (c *Collider) Trigger() bool {
+
+	return c.trigger
+}
+
+// SetNode sets the node whose world position and orientation the collider follows.
+// Kinematic colliders follow the node at each update of the collision world and
+// static colliders only when the node is set. A nil node stops following it.
+func (c *Collider) SetNode(inode core.INode) {
+
+	c.node = inode
+	c.syncNode()
+}
+
+// Node returns the node the collider follows or nil.
+func (c *Collider) Node() core.INode {
+
+	return c.node
+}
+
+// SetPosition sets the world position of the collider.
+func (c *Collider) SetPosition(pos *math32.Vector3) {
+
+	c.position = *pos
+}
+
+// Position returns the world position of the collider.
+func (c *Collider) Position() math32.Vector3 {
+
+	return c.position
+}
+
+// SetQuaternion sets the world orientation of the collider.
+func (c *Collider) SetQuaternion(quat *math32.Quaternion) {
+
+	c.quaternion = *quat
+}
+
+// Quaternion returns the world orientation of the collider.
+func (c *Collider) Quaternion() math32.Quaternion {
+
+	return c.quaternion
+}
+
+// SetCollisionFilter sets the collision filter group of the collider and the mask of the groups it collides with.
+// Two colliders collide if the group of each one is in the mask of the other.
+func (c *Collider) SetCollisionFilter(group, mask int) {
+
+	c.group = group
+	c.mask = mask
+}
+
+// CollisionFilter returns the collision filter group and mask of the collider.
+func (c *Collider) CollisionFilter() (int, int) {
+
+	return c.group, c.mask
+}
+
+// CollidableWith returns whether the collider collides with the specified collider according to their collision filters.
+func (c *Collider) CollidableWith(other *Collider) bool {
+
+	return c.group&other.mask != 0 && other.group&c.mask != 0
+}
+
+// SetUserData sets generic user data associated with the collider.
+func (c *Collider) SetUserData(data interface{}) {
+
+	c.userData = data
+}
+
+// UserData returns the generic user data associated with the collider.
+func (c *Collider) UserData() interface{} {
+
+	return c.userData
+}
+
+// BoundingBox returns the bounding box of the collider in world coordinates.
+func (c *Collider) BoundingBox() math32.Box3 {
+
+	mat4 := math32.NewMatrix4().Compose(&c.position, &c.quaternion, math32.NewVector3(1, 1, 1))
+	localBB := c.shape.BoundingBox()
+	return *localBB.ApplyMatrix4(mat4)
+}
+
+// syncNode sets the world transform of the collider from its node, if any.
+func (c *Collider) syncNode() {
+
+	if c.node == nil {
+		return
+	}
+	n := c.node.GetNode()
+	n.WorldPosition(&c.position)
+	n.WorldQuaternion(&c.quaternion)
+}
+
+// plane returns the world normal and a point of the surface of the collider if its shape is a plane.
+func (c *Collider) plane() (math32.Vector3, math32.Vector3, bool) {
+
+	p, ok := c.shape.(*shape.Plane)
+	if !ok {
+		return math32.Vector3{}, math32.Vector3{}, false
+	}
+	normal := p.Normal()
+	normal.ApplyQuaternion(&c.quaternion)
+	return normal, c.position, true
+}
+
+// core returns the convex core of the collider in world coordinates
+// and whether the shape of the collider has one, which planes don't.
+func (c *Collider) core() (convexCore, bool) {
+
+	return shapeCore(c.shape, &c.position, &c.quaternion)
+}
+
+// shapeCore returns the convex core of the specified shape with the specified world transform and whether
+// the shape has one. Box and convex hull cores are their vertices, sphere and capsule cores are inflated.
+func shapeCore(s shape.IShape, pos *math32.Vector3, quat *math32.Quaternion) (convexCore, bool) {
+
+	var c convexCore
+	c.offset = *pos
+	switch s := s.(type) {
+	case *shape.Sphere:
+		c.points = make([]math32.Vector3, 1)
+		c.radius = s.Radius()
+	case *shape.Capsule:
+		a, b := s.Segment()
+		c.points = []math32.Vector3{a, b}
+		c.radius = s.Radius()
+	case *shape.Box:
+		corners := s.Corners()
+		c.points = corners[:]
+	case *shape.ConvexHull:
+		for _, face := range s.Faces() {
+			c.points = append(c.points, face[:]...)
+		}
+		if len(c.points) == 0 {
+			return c, false
+		}
+	default:
+		return c, false
+	}
+	for i := range c.points {
+		c.points[i].ApplyQuaternion(quat)
+	}
+	return c, true
+}
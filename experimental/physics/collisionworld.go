@@ -0,0 +1,409 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/experimental/collision/shape"
+	"github.com/g3n/engine/math32"
+)
+
+// Trigger events dispatched by the collision world, the trigger and the other collider.
+// The event parameter is a pointer to TriggerEvent.
+const (
+	OnEnter = "physics.OnEnter" // Dispatched when a collider starts overlapping a trigger
+	OnExit  = "physics.OnExit"  // Dispatched when a collider stops overlapping a trigger or either is removed
+)
+
+// TriggerEvent describes a collider entering or exiting a trigger.
+type TriggerEvent struct {
+	Trigger *Collider // Trigger collider
+	Other   *Collider // Collider which entered or exited the trigger
+}
+
+// SweepHit describes the first collider hit by a sweep test.
+type SweepHit struct {
+	Collider *Collider      // Collider hit
+	Fraction float32        // Fraction of the displacement until the hit
+	Point    math32.Vector3 // Contact point in world coordinates
+	Normal   math32.Vector3 // Normal of the surface hit in world coordinates
+}
+
+// Sweeps stop advancing when the swept shape is closer than this distance from a collider
+const sweepTolerance = 1e-3
+
+// Maximum number of conservative advancement steps of a sweep
+const sweepIterations = 32
+
+// CollisionWorld contains static and kinematic colliders and answers overlap and sweep queries
+// against them, without simulating dynamics. It tracks the colliders overlapping its trigger
+// colliders at each Update, dispatching OnEnter and OnExit events, for gameplay logic which
+// doesn't need a full rigid body simulation.
+type CollisionWorld struct {
+	core.Dispatcher
+	colliders []*Collider
+	overlaps  []TriggerEvent // Colliders overlapping triggers since the last update
+}
+
+// NewCollisionWorld creates and returns a pointer to a new empty collision world.
+func NewCollisionWorld() *CollisionWorld {
+
+	w := new(CollisionWorld)
+	w.Dispatcher.Initialize()
+	return w
+}
+
+// Add adds the specified collider to the collision world.
+// Its overlaps with triggers are detected at the next Update.
+func (w *CollisionWorld) Add(c *Collider) {
+
+	for _, existing := range w.colliders {
+		if existing == c {
+			return
+		}
+	}
+	c.syncNode()
+	w.colliders = append(w.colliders, c)
+}
+
+// Remove removes the specified collider from the collision world, dispatching
+// OnExit events for the triggers it overlaps or the colliders overlapping it.
+// Returns true if found or false otherwise.
+func (w *CollisionWorld) Remove(c *Collider) bool {
+
+	for pos, current := range w.colliders {
+		if current == c {
+			copy(w.colliders[pos:], w.colliders[pos+1:])
+			w.colliders[len(w.colliders)-1] = nil
+			w.colliders = w.colliders[:len(w.colliders)-1]
+			var exits []TriggerEvent
+			overlaps := w.overlaps[:0]
+			for _, o := range w.overlaps {
+				if o.Trigger == c || o.Other == c {
+					exits = append(exits, o)
+				} else {
+					overlaps = append(overlaps, o)
+				}
+			}
+			w.overlaps = overlaps
+			for i := range exits {
+				w.dispatchTrigger(OnExit, &exits[i])
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// Colliders returns the colliders of the collision world.
+func (w *CollisionWorld) Colliders() []*Collider {
+
+	return w.colliders
+}
+
+// Update sets the world transforms of the kinematic colliders from their nodes and dispatches OnEnter
+// and OnExit events for the colliders which started and stopped overlapping triggers since the last update.
+// It is usually called every frame after moving the kinematic colliders.
+func (w *CollisionWorld) Update() {
+
+	for _, c := range w.colliders {
+		if c.ctype == KinematicCollider {
+			c.syncNode()
+		}
+	}
+
+	// Finds the current overlaps of the triggers with the other colliders
+	var overlaps []TriggerEvent
+	for _, trigger := range w.colliders {
+		if !trigger.trigger {
+			continue
+		}
+		for _, other := range w.colliders {
+			if other.trigger || !trigger.CollidableWith(other) {
+				continue
+			}
+			if colliderOverlaps(trigger, other) {
+				overlaps = append(overlaps, TriggerEvent{Trigger: trigger, Other: other})
+			}
+		}
+	}
+
+	// Dispatches the exits before the enters
+	previous := make(map[TriggerEvent]bool, len(w.overlaps))
+	for _, o := range w.overlaps {
+		previous[o] = true
+	}
+	current := make(map[TriggerEvent]bool, len(overlaps))
+	for _, o := range overlaps {
+		current[o] = true
+	}
+	old := w.overlaps
+	w.overlaps = overlaps
+	for i := range old {
+		if !current[old[i]] {
+			w.dispatchTrigger(OnExit, &old[i])
+		}
+	}
+	for i := range overlaps {
+		if !previous[overlaps[i]] {
+			ev := overlaps[i]
+			w.dispatchTrigger(OnEnter, &ev)
+		}
+	}
+}
+
+// Overlapping returns the colliders overlapping the specified trigger since the last update.
+func (w *CollisionWorld) Overlapping(trigger *Collider) []*Collider {
+
+	var others []*Collider
+	for _, o := range w.overlaps {
+		if o.Trigger == trigger {
+			others = append(others, o.Other)
+		}
+	}
+	return others
+}
+
+// OverlapSphere returns the colliders, including triggers, whose collision filter group is
+// in the specified mask and which overlap the sphere with the specified center and radius.
+func (w *CollisionWorld) OverlapSphere(center *math32.Vector3, radius float32, mask int) []*Collider {
+
+	return w.overlap(convexCore{points: make([]math32.Vector3, 1), offset: *center, radius: radius}, mask)
+}
+
+// OverlapBox returns the colliders, including triggers, whose collision filter group is in the specified
+// mask and which overlap the box with the specified center, half extents and orientation.
+func (w *CollisionWorld) OverlapBox(center, halfExtents *math32.Vector3, quat *math32.Quaternion, mask int) []*Collider {
+
+	c, _ := shapeCore(shape.NewBox(halfExtents), center, quat)
+	return w.overlap(c, mask)
+}
+
+// OverlapCapsule returns the colliders, including triggers, whose collision filter group is in the specified
+// mask and which overlap the capsule with the specified radius and the centers of its hemispheres at a and b.
+func (w *CollisionWorld) OverlapCapsule(a, b *math32.Vector3, radius float32, mask int) []*Collider {
+
+	return w.overlap(convexCore{points: []math32.Vector3{*a, *b}, radius: radius}, mask)
+}
+
+// Overlap returns the colliders, including triggers, whose collision filter group is in the specified mask
+// and which overlap the specified shape with the specified world position and orientation.
+// The shape can be a sphere, box, capsule or convex hull.
+func (w *CollisionWorld) Overlap(s shape.IShape, pos *math32.Vector3, quat *math32.Quaternion, mask int) []*Collider {
+
+	c, ok := shapeCore(s, pos, quat)
+	if !ok {
+		return nil
+	}
+	return w.overlap(c, mask)
+}
+
+// SweepSphere moves the sphere with the specified center and radius by the specified displacement
+// and returns the first collider it hits, ignoring triggers and colliders whose collision filter
+// group isn't in the specified mask, and whether it hits any.
+func (w *CollisionWorld) SweepSphere(center *math32.Vector3, radius float32, disp *math32.Vector3, mask int) (SweepHit, bool) {
+
+	return w.sweep(convexCore{points: make([]math32.Vector3, 1), offset: *center, radius: radius}, disp, mask)
+}
+
+// SweepBox moves the box with the specified center, half extents and orientation by the specified displacement
+// and returns the first collider it hits, ignoring triggers and colliders whose collision filter
+// group isn't in the specified mask, and whether it hits any.
+func (w *CollisionWorld) SweepBox(center, halfExtents *math32.Vector3, quat *math32.Quaternion, disp *math32.Vector3, mask int) (SweepHit, bool) {
+
+	c, _ := shapeCore(shape.NewBox(halfExtents), center, quat)
+	return w.sweep(c, disp, mask)
+}
+
+// SweepCapsule moves the capsule with the specified radius and the centers of its hemispheres at a and b
+// by the specified displacement and returns the first collider it hits, ignoring triggers and colliders
+// whose collision filter group isn't in the specified mask, and whether it hits any.
+func (w *CollisionWorld) SweepCapsule(a, b *math32.Vector3, radius float32, disp *math32.Vector3, mask int) (SweepHit, bool) {
+
+	return w.sweep(convexCore{points: []math32.Vector3{*a, *b}, radius: radius}, disp, mask)
+}
+
+// Sweep moves the specified shape with the specified world position and orientation by the specified
+// displacement and returns the first collider it hits, ignoring triggers and colliders whose collision
+// filter group isn't in the specified mask, and whether it hits any.
+// The shape can be a sphere, box, capsule or convex hull.
+func (w *CollisionWorld) Sweep(s shape.IShape, pos *math32.Vector3, quat *math32.Quaternion, disp *math32.Vector3, mask int) (SweepHit, bool) {
+
+	c, ok := shapeCore(s, pos, quat)
+	if !ok {
+		return SweepHit{}, false
+	}
+	return w.sweep(c, disp, mask)
+}
+
+// overlap returns the colliders in the specified mask overlapping the specified core.
+func (w *CollisionWorld) overlap(query convexCore, mask int) []*Collider {
+
+	bbox := query.boundingBox()
+	var result []*Collider
+	for _, c := range w.colliders {
+		if c.group&mask == 0 {
+			continue
+		}
+		if normal, point, ok := c.plane(); ok {
+			if planeSeparation(&query, &normal, &point) <= 0 {
+				result = append(result, c)
+			}
+			continue
+		}
+		cbox := c.BoundingBox()
+		if !cbox.IsIntersectionBox(&bbox) {
+			continue
+		}
+		other, ok := c.core()
+		if ok && coreSeparation(&query, &other) <= 0 {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// sweep moves the specified core by the specified displacement and returns
+// the first non-trigger collider in the specified mask it hits.
+func (w *CollisionWorld) sweep(query convexCore, disp *math32.Vector3, mask int) (SweepHit, bool) {
+
+	bbox := query.boundingBox()
+	moved := bbox
+	moved.Translate(disp)
+	bbox.Union(&moved)
+
+	var best SweepHit
+	found := false
+	for _, c := range w.colliders {
+		if c.trigger || c.group&mask == 0 {
+			continue
+		}
+		var hit SweepHit
+		var ok bool
+		if normal, point, isPlane := c.plane(); isPlane {
+			hit, ok = sweepPlane(query, disp, &normal, &point)
+		} else {
+			cbox := c.BoundingBox()
+			if !cbox.IsIntersectionBox(&bbox) {
+				continue
+			}
+			other, hasCore := c.core()
+			if !hasCore {
+				continue
+			}
+			hit, ok = sweepCore(query, &other, disp)
+		}
+		if ok && (!found || hit.Fraction < best.Fraction) {
+			hit.Collider = c
+			best = hit
+			found = true
+		}
+	}
+	return best, found
+}
+
+// dispatchTrigger dispatches the specified trigger event to the trigger, the other collider and the collision world.
+func (w *CollisionWorld) dispatchTrigger(evname string, ev *TriggerEvent) {
+
+	ev.Trigger.Dispatch(evname, ev)
+	ev.Other.Dispatch(evname, ev)
+	w.Dispatch(evname, ev)
+}
+
+// colliderOverlaps returns whether the specified trigger overlaps the specified collider.
+func colliderOverlaps(trigger, other *Collider) bool {
+
+	tn, tp, tPlane := trigger.plane()
+	on, op, oPlane := other.plane()
+	if tPlane && oPlane {
+		// Planes overlap unless they are parallel and facing away from each other
+		return tn.Dot(&on) > -1+1e-6 || tn.Dot(op.Clone().Sub(&tp)) < 0
+	}
+	if tPlane || oPlane {
+		c, ok := other.core()
+		normal, point := tn, tp
+		if oPlane {
+			c, ok = trigger.core()
+			normal, point = on, op
+		}
+		return ok && planeSeparation(&c, &normal, &point) <= 0
+	}
+	tbox := trigger.BoundingBox()
+	obox := other.BoundingBox()
+	if !tbox.IsIntersectionBox(&obox) {
+		return false
+	}
+	tc, tok := trigger.core()
+	oc, ook := other.core()
+	return tok && ook && coreSeparation(&tc, &oc) <= 0
+}
+
+// coreSeparation returns the distance between the surfaces of the specified cores, or zero or a negative value if they overlap.
+func coreSeparation(a, b *convexCore) float32 {
+
+	dist, _, _ := gjkDistance(a, b)
+	return dist - a.radius - b.radius
+}
+
+// planeSeparation returns the distance of the specified core above the plane with
+// the specified normal and surface point, or zero or a negative value if it overlaps the plane.
+func planeSeparation(c *convexCore, normal, point *math32.Vector3) float32 {
+
+	p := c.support(normal.Clone().Negate())
+	return normal.Dot(p.Sub(point)) - c.radius
+}
+
+// sweepPlane returns the hit of the specified core moved by the specified displacement with the
+// plane with the specified normal and surface point, and whether it hits the plane.
+func sweepPlane(c convexCore, disp, normal, point *math32.Vector3) (SweepHit, bool) {
+
+	var hit SweepHit
+	sep := planeSeparation(&c, normal, point)
+	if sep > 0 {
+		speed := -normal.Dot(disp)
+		if speed <= 0 || sep > speed {
+			return hit, false
+		}
+		hit.Fraction = sep / speed
+	}
+	c.offset.Add(disp.Clone().MultiplyScalar(hit.Fraction))
+	p := c.support(normal.Clone().Negate())
+	hit.Point = *normal.Clone().MultiplyScalar(-c.radius).Add(&p)
+	hit.Normal = *normal
+	return hit, true
+}
+
+// sweepCore returns the hit of the specified core moved by the specified displacement with
+// the other specified core, and whether it hits it, using conservative advancement.
+func sweepCore(c convexCore, other *convexCore, disp *math32.Vector3) (SweepHit, bool) {
+
+	var hit SweepHit
+	start := c.offset
+	for iter := 0; iter < sweepIterations; iter++ {
+		c.offset = *disp.Clone().MultiplyScalar(hit.Fraction).Add(&start)
+		dist, pa, pb := gjkDistance(&c, other)
+		sep := dist - c.radius - other.radius
+		if dist == 0 {
+			// The cores overlap, which only happens if they initially overlap
+			hit.Normal = *disp.Clone().Negate().Normalize()
+			hit.Point = pb
+			return hit, true
+		}
+		hit.Normal.SubVectors(&pa, &pb).MultiplyScalar(1 / dist)
+		hit.Point = *hit.Normal.Clone().MultiplyScalar(other.radius).Add(&pb)
+		if sep <= sweepTolerance {
+			return hit, true
+		}
+		speed := -hit.Normal.Dot(disp)
+		if speed <= 0 {
+			return hit, false
+		}
+		hit.Fraction += sep / speed
+		if hit.Fraction > 1 {
+			return hit, false
+		}
+	}
+	return hit, true
+}
@@ -0,0 +1,286 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// Maximum number of iterations of the GJK algorithm
+const gjkIterations = 64
+
+// convexCore is a convex shape in world coordinates represented by the convex hull
+// of a set of points, its core, inflated by a radius, as spheres and capsules.
+type convexCore struct {
+	points []math32.Vector3 // Points whose convex hull is the core
+	offset math32.Vector3   // Translation added to the points
+	radius float32          // Radius by which the core is inflated
+}
+
+// support returns the point of the core furthest along the specified direction.
+func (c *convexCore) support(dir *math32.Vector3) math32.Vector3 {
+
+	best := 0
+	max := c.points[0].Dot(dir)
+	for i := 1; i < len(c.points); i++ {
+		if d := c.points[i].Dot(dir); d > max {
+			max = d
+			best = i
+		}
+	}
+	p := c.points[best]
+	return *p.Add(&c.offset)
+}
+
+// boundingBox returns the bounding box of the core inflated by its radius.
+func (c *convexCore) boundingBox() math32.Box3 {
+
+	var box math32.Box3
+	box.SetFromPoints(c.points)
+	box.Translate(&c.offset)
+	box.ExpandByScalar(c.radius)
+	return box
+}
+
+// simplexVertex is a vertex of the GJK simplex with the support points which originated it.
+type simplexVertex struct {
+	p math32.Vector3 // Point of the Minkowski difference (a - b)
+	a math32.Vector3 // Support point of the first core
+	b math32.Vector3 // Support point of the second core
+}
+
+// gjkDistance returns the distance between the cores of the specified convex shapes, without their radii,
+// and their closest points, using the Gilbert-Johnson-Keerthi algorithm. The distance is zero if the cores overlap.
+func gjkDistance(ca, cb *convexCore) (float32, math32.Vector3, math32.Vector3) {
+
+	var simplex [4]simplexVertex
+	var bary [4]float32
+	newVertex := func(dir *math32.Vector3) simplexVertex {
+		var v simplexVertex
+		v.a = ca.support(dir)
+		v.b = cb.support(dir.Clone().Negate())
+		v.p.SubVectors(&v.a, &v.b)
+		return v
+	}
+
+	// Starts with the difference of the first points of the cores
+	dir := ca.points[0]
+	dir.Add(&ca.offset).Sub(&cb.points[0]).Sub(&cb.offset)
+	if dir.LengthSq() == 0 {
+		dir.Set(1, 0, 0)
+	}
+	simplex[0] = newVertex(dir.Clone().Negate())
+	bary[0] = 1
+	n := 1
+	var v math32.Vector3
+	for iter := 0; iter < gjkIterations; iter++ {
+		n = closestOnSimplex(simplex[:n], bary[:n], &v)
+		vlen := v.LengthSq()
+		if n == 4 || vlen < 1e-12 {
+			// The origin is inside the simplex so the cores overlap
+			a, b := closestPoints(simplex[:n], bary[:n])
+			return 0, a, b
+		}
+
+		// Adds the support point in the direction of the origin unless it's not closer to the origin
+		w := newVertex(v.Clone().Negate())
+		if vlen-v.Dot(&w.p) <= 1e-6*vlen {
+			break
+		}
+		duplicate := false
+		for i := 0; i < n; i++ {
+			if simplex[i].p.Equals(&w.p) {
+				duplicate = true
+			}
+		}
+		if duplicate {
+			break
+		}
+		simplex[n] = w
+		n++
+	}
+	a, b := closestPoints(simplex[:n], bary[:n])
+	return v.Length(), a, b
+}
+
+// closestPoints returns the closest points of the cores for the specified simplex and barycentric coordinates.
+func closestPoints(simplex []simplexVertex, bary []float32) (math32.Vector3, math32.Vector3) {
+
+	var a, b math32.Vector3
+	for i := range simplex {
+		a.Add(simplex[i].a.Clone().MultiplyScalar(bary[i]))
+		b.Add(simplex[i].b.Clone().MultiplyScalar(bary[i]))
+	}
+	return a, b
+}
+
+// closestOnSimplex sets v to the point of the specified simplex closest to the origin, reduces
+// the simplex to the smallest sub-simplex containing it, setting the barycentric coordinates of
+// the point in the reduced simplex, and returns the number of vertices of the reduced simplex.
+func closestOnSimplex(simplex []simplexVertex, bary []float32, v *math32.Vector3) int {
+
+	switch len(simplex) {
+	case 1:
+		*v = simplex[0].p
+		bary[0] = 1
+		return 1
+	case 2:
+		return closestOnSegment(simplex, bary, v)
+	case 3:
+		return closestOnTriangle(simplex, bary, v)
+	}
+
+	// Tetrahedron: checks the faces which have the origin outside
+	var best [3]simplexVertex
+	var bestBary [3]float32
+	bestN := 0
+	bestDist := float32(-1)
+	faces := [4][4]int{{0, 1, 2, 3}, {0, 1, 3, 2}, {0, 2, 3, 1}, {1, 2, 3, 0}}
+	for _, f := range faces {
+		if !originOutsideFace(&simplex[f[0]].p, &simplex[f[1]].p, &simplex[f[2]].p, &simplex[f[3]].p) {
+			continue
+		}
+		face := [3]simplexVertex{simplex[f[0]], simplex[f[1]], simplex[f[2]]}
+		var fb [3]float32
+		var fv math32.Vector3
+		fn := closestOnTriangle(face[:], fb[:], &fv)
+		if d := fv.LengthSq(); bestDist < 0 || d < bestDist {
+			bestDist = d
+			bestN = fn
+			best = face
+			bestBary = fb
+			*v = fv
+		}
+	}
+	if bestDist < 0 {
+		// The origin is inside the tetrahedron
+		v.Set(0, 0, 0)
+		computeTetraBary(simplex, bary)
+		return 4
+	}
+	copy(simplex, best[:bestN])
+	copy(bary, bestBary[:bestN])
+	return bestN
+}
+
+// closestOnSegment is closestOnSimplex for a segment.
+func closestOnSegment(simplex []simplexVertex, bary []float32, v *math32.Vector3) int {
+
+	a, b := &simplex[0].p, &simplex[1].p
+	var ab math32.Vector3
+	ab.SubVectors(b, a)
+	t := float32(0)
+	if l := ab.LengthSq(); l > 0 {
+		t = -a.Dot(&ab) / l
+	}
+	if t <= 0 {
+		*v = *a
+		bary[0] = 1
+		return 1
+	}
+	if t >= 1 {
+		simplex[0] = simplex[1]
+		*v = simplex[0].p
+		bary[0] = 1
+		return 1
+	}
+	*v = *ab.MultiplyScalar(t).Add(a)
+	bary[0] = 1 - t
+	bary[1] = t
+	return 2
+}
+
+// closestOnTriangle is closestOnSimplex for a triangle.
+func closestOnTriangle(simplex []simplexVertex, bary []float32, v *math32.Vector3) int {
+
+	a, b, c := &simplex[0].p, &simplex[1].p, &simplex[2].p
+	var ab, ac, ap, bp, cp math32.Vector3
+	ab.SubVectors(b, a)
+	ac.SubVectors(c, a)
+	ap.Copy(a).Negate()
+	d1 := ab.Dot(&ap)
+	d2 := ac.Dot(&ap)
+	if d1 <= 0 && d2 <= 0 {
+		return closestOnSimplex(simplex[:1], bary, v)
+	}
+	bp.Copy(b).Negate()
+	d3 := ab.Dot(&bp)
+	d4 := ac.Dot(&bp)
+	if d3 >= 0 && d4 <= d3 {
+		simplex[0] = simplex[1]
+		return closestOnSimplex(simplex[:1], bary, v)
+	}
+	vc := d1*d4 - d3*d2
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		return closestOnSegment(simplex[:2], bary, v)
+	}
+	cp.Copy(c).Negate()
+	d5 := ab.Dot(&cp)
+	d6 := ac.Dot(&cp)
+	if d6 >= 0 && d5 <= d6 {
+		simplex[0] = simplex[2]
+		return closestOnSimplex(simplex[:1], bary, v)
+	}
+	vb := d5*d2 - d1*d6
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		simplex[1] = simplex[2]
+		return closestOnSegment(simplex[:2], bary, v)
+	}
+	va := d3*d6 - d5*d4
+	if va <= 0 && d4-d3 >= 0 && d5-d6 >= 0 {
+		simplex[0] = simplex[2]
+		return closestOnSegment(simplex[:2], bary, v)
+	}
+	denom := va + vb + vc
+	if denom == 0 {
+		return closestOnSegment(simplex[:2], bary, v)
+	}
+	bary[0] = va / denom
+	bary[1] = vb / denom
+	bary[2] = vc / denom
+	v.Set(0, 0, 0)
+	for i := 0; i < 3; i++ {
+		v.Add(simplex[i].p.Clone().MultiplyScalar(bary[i]))
+	}
+	return 3
+}
+
+// originOutsideFace returns whether the origin is on the opposite side of the plane of the face abc than d.
+func originOutsideFace(a, b, c, d *math32.Vector3) bool {
+
+	var ab, ac, n, ad math32.Vector3
+	ab.SubVectors(b, a)
+	ac.SubVectors(c, a)
+	n.CrossVectors(&ab, &ac)
+	ad.SubVectors(d, a)
+	signO := -n.Dot(a)
+	signD := n.Dot(&ad)
+	return signO*signD < 0
+}
+
+// computeTetraBary sets the barycentric coordinates of the origin in the specified tetrahedron.
+func computeTetraBary(simplex []simplexVertex, bary []float32) {
+
+	vol := func(a, b, c, d *math32.Vector3) float32 {
+		var ab, ac, ad math32.Vector3
+		ab.SubVectors(b, a)
+		ac.SubVectors(c, a)
+		ad.SubVectors(d, a)
+		return ab.Dot(ac.Cross(&ad))
+	}
+	var o math32.Vector3
+	p := [4]*math32.Vector3{&simplex[0].p, &simplex[1].p, &simplex[2].p, &simplex[3].p}
+	total := vol(p[0], p[1], p[2], p[3])
+	if total == 0 {
+		for i := range bary {
+			bary[i] = 0.25
+		}
+		return
+	}
+	bary[0] = vol(&o, p[1], p[2], p[3]) / total
+	bary[1] = vol(p[0], &o, p[2], p[3]) / total
+	bary[2] = vol(p[0], p[1], &o, p[3]) / total
+	bary[3] = vol(p[0], p[1], p[2], &o) / total
+}
@@ -369,6 +369,17 @@ func (b *Body) Position() math32.Vector3 {
 	return *b.position
 }
 
+// ShiftPosition adds the specified offset to the current, previous, initial and
+// interpolated positions of the body, as when the world origin is shifted.
+func (b *Body) ShiftPosition(offset *math32.Vector3) {
+
+	b.position.Add(offset)
+	b.prevPosition.Add(offset)
+	b.initPosition.Add(offset)
+	b.interpPosition.Add(offset)
+	b.aabbNeedsUpdate = true
+}
+
 func (b *Body) Quaternion() *math32.Quaternion {
 
 	return b.quaternion.Clone()
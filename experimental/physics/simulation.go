@@ -80,6 +80,13 @@ func NewSimulation(scene *core.Node) *Simulation {
 	//s.defaultMaterial = NewMaterial
 	s.defaultContactMaterial = NewContactMaterial()
 
+	// Shifts the bodies when the world origin of the scene is shifted
+	if scene != nil {
+		scene.Subscribe(core.OnOriginShift, func(evname string, ev interface{}) {
+			s.ShiftOrigin(&ev.(*core.OriginShiftEvent).Offset)
+		})
+	}
+
 	return s
 }
 
@@ -88,6 +95,22 @@ func (s *Simulation) Scene() *core.Node {
 	return s.scene
 }
 
+// ShiftOrigin adds the specified offset to the positions of all the bodies and attractor force fields.
+// It is called automatically when an OriginShifter shifts the origin of the simulation scene.
+func (s *Simulation) ShiftOrigin(offset *math32.Vector3) {
+
+	for _, b := range s.bodies {
+		if b != nil {
+			b.ShiftPosition(offset)
+		}
+	}
+	for _, ff := range s.forceFields {
+		if af, ok := ff.(*AttractorForceField); ok {
+			af.position.Add(offset)
+		}
+	}
+}
+
 // AddForceField adds a force field to the simulation.
 func (s *Simulation) AddForceField(ff ForceField) {
 
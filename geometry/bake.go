@@ -0,0 +1,521 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// AOOptions are the options of the ambient occlusion baking.
+// The zero value of each field selects its default.
+type AOOptions struct {
+	Samples  int     // Number of rays cast from each vertex (default 32)
+	Distance float32 // Maximum distance of the occluders (default 1/4 of the bounding sphere radius)
+	Bias     float32 // Offset of the ray origins along the normals (default 1/10000 of the bounding sphere radius)
+	Threads  int     // Number of threads (default runtime.NumCPU())
+}
+
+// Maximum number of triangles in a leaf of the bounding volume hierarchy
+const bvhLeafSize = 4
+
+// ComputeSmoothNormals computes the normals of the vertices as the area weighted average of the normals
+// of the faces which share their positions, so vertices duplicated at texture seams get the same normal,
+// and stores them in the VertexNormal VBO, which is added if the geometry doesn't have one.
+// The faces are processed by multiple threads, so it's suitable for large static geometries such as terrains.
+func (g *Geometry) ComputeSmoothNormals() error {
+
+	positions := g.bakePositions()
+	if positions == nil {
+		return fmt.Errorf("geometry has no VBO with vertex positions")
+	}
+	normals := smoothNormals(positions, g.bakeIndices(len(positions)/3), runtime.NumCPU())
+	return g.setBakedVBO(gls.VertexNormal, normals)
+}
+
+// ComputeAO computes and returns the ambient occlusion of each vertex of the geometry, which is
+// the fraction of the rays cast from the vertex in the hemisphere around its normal, with a cosine
+// distribution, that don't hit any face of the geometry within the maximum distance.
+// The vertex normals are computed by ComputeSmoothNormals, without being stored, if the geometry doesn't have them.
+// The vertices are processed by multiple threads. The options may be nil to use the defaults.
+func (g *Geometry) ComputeAO(opts *AOOptions) ([]float32, error) {
+
+	positions := g.bakePositions()
+	if positions == nil {
+		return nil, fmt.Errorf("geometry has no VBO with vertex positions")
+	}
+	var o AOOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Samples <= 0 {
+		o.Samples = 32
+	}
+	if o.Threads <= 0 {
+		o.Threads = runtime.NumCPU()
+	}
+	radius := g.BoundingSphere().Radius
+	if o.Distance <= 0 {
+		o.Distance = radius / 4
+	}
+	if o.Bias <= 0 {
+		o.Bias = radius / 10000
+	}
+
+	count := len(positions) / 3
+	indices := g.bakeIndices(count)
+	var normals []float32
+	if vbo := g.VBO(gls.VertexNormal); vbo != nil {
+		normals = make([]float32, 0, len(positions))
+		vbo.ReadVectors3(gls.VertexNormal, func(n math32.Vector3) bool {
+			normals = append(normals, n.X, n.Y, n.Z)
+			return false
+		})
+	}
+	if len(normals) != len(positions) {
+		normals = smoothNormals(positions, indices, o.Threads)
+	}
+
+	bvh := newBVH(positions, indices)
+	dirs := aoDirections(o.Samples)
+	ao := make([]float32, count)
+	parallelRange(count, o.Threads, func(start, end int) {
+		var p, n, t, b, d math32.Vector3
+		for i := start; i < end; i++ {
+			n.Set(normals[3*i], normals[3*i+1], normals[3*i+2])
+			if n.LengthSq() == 0 {
+				ao[i] = 1
+				continue
+			}
+			n.Normalize()
+			tangentFrame(&n, &t, &b)
+			p.Set(positions[3*i], positions[3*i+1], positions[3*i+2])
+			p.Add(d.Copy(&n).MultiplyScalar(o.Bias))
+
+			// Rotates the directions around the normal by an angle depending on the vertex
+			// to avoid banding between neighbouring vertices
+			angle := float32(hashVertex(uint32(i))) / float32(math.MaxUint32) * 2 * math.Pi
+			sin, cos := math32.Sin(angle), math32.Cos(angle)
+			hits := 0
+			for _, dir := range dirs {
+				x := dir.X*cos - dir.Y*sin
+				y := dir.X*sin + dir.Y*cos
+				d.Set(t.X*x+b.X*y+n.X*dir.Z, t.Y*x+b.Y*y+n.Y*dir.Z, t.Z*x+b.Z*y+n.Z*dir.Z)
+				if bvh.hit(&p, &d, o.Distance) {
+					hits++
+				}
+			}
+			ao[i] = 1 - float32(hits)/float32(len(dirs))
+		}
+	})
+	return ao, nil
+}
+
+// BakeAO computes the ambient occlusion of the vertices with ComputeAO and stores it in all the
+// components of the VertexColor VBO, which is added if the geometry doesn't have one, and sets
+// the VERTEX_AO shader define so the standard and physical shaders attenuate the ambient light by it.
+// The baked values replace any vertex colors, so the VERTEX_COLORS shader define is unset.
+func (g *Geometry) BakeAO(opts *AOOptions) error {
+
+	ao, err := g.ComputeAO(opts)
+	if err != nil {
+		return err
+	}
+	colors := make([]float32, 3*len(ao))
+	for i, v := range ao {
+		colors[3*i] = v
+		colors[3*i+1] = v
+		colors[3*i+2] = v
+	}
+	err = g.setBakedVBO(gls.VertexColor, colors)
+	if err != nil {
+		return err
+	}
+	g.ShaderDefines.Set("VERTEX_AO", "")
+	g.ShaderDefines.Unset("VERTEX_COLORS")
+	return nil
+}
+
+// bakePositions returns the vertex positions of the geometry or nil if it has none.
+func (g *Geometry) bakePositions() []float32 {
+
+	vbo := g.VBO(gls.VertexPosition)
+	if vbo == nil {
+		return nil
+	}
+	positions := make([]float32, 0, 3*g.Items())
+	vbo.ReadVectors3(gls.VertexPosition, func(v math32.Vector3) bool {
+		positions = append(positions, v.X, v.Y, v.Z)
+		return false
+	})
+	return positions
+}
+
+// bakeIndices returns the indices of the faces of the geometry or
+// sequential indices for the specified number of vertices if it's not indexed.
+func (g *Geometry) bakeIndices(count int) []uint32 {
+
+	if g.Indexed() {
+		return g.indices
+	}
+	indices := make([]uint32, count-count%3)
+	for i := range indices {
+		indices[i] = uint32(i)
+	}
+	return indices
+}
+
+// setBakedVBO sets the values of the specified 3 element attribute of all the vertices,
+// adding a VBO for the attribute if the geometry doesn't have one.
+func (g *Geometry) setBakedVBO(atype gls.AttribType, data []float32) error {
+
+	if g.VBO(atype) == nil {
+		g.AddVBO(gls.NewVBO(math32.ArrayF32(data)).AddAttrib(atype))
+		return nil
+	}
+	return g.UpdateVBO(atype, 0, data)
+}
+
+// smoothNormals returns the area weighted average of the normals of the faces
+// sharing the position of each vertex using the specified number of threads.
+func smoothNormals(positions []float32, indices []uint32, threads int) []float32 {
+
+	count := len(positions) / 3
+	faces := len(indices) / 3
+
+	// Computes the normals of the faces, whose lengths are twice their areas
+	faceNormals := make([]float32, 3*faces)
+	parallelRange(faces, threads, func(start, end int) {
+		var a, b, c, ab, ac math32.Vector3
+		for f := start; f < end; f++ {
+			a.Set(positions[3*indices[3*f]], positions[3*indices[3*f]+1], positions[3*indices[3*f]+2])
+			b.Set(positions[3*indices[3*f+1]], positions[3*indices[3*f+1]+1], positions[3*indices[3*f+1]+2])
+			c.Set(positions[3*indices[3*f+2]], positions[3*indices[3*f+2]+1], positions[3*indices[3*f+2]+2])
+			ab.SubVectors(&b, &a)
+			ac.SubVectors(&c, &a)
+			ab.Cross(&ac)
+			faceNormals[3*f] = ab.X
+			faceNormals[3*f+1] = ab.Y
+			faceNormals[3*f+2] = ab.Z
+		}
+	})
+
+	// Welds the vertices with the same position
+	weld := make([]int32, count)
+	ids := make(map[[3]float32]int32)
+	for i := 0; i < count; i++ {
+		key := [3]float32{positions[3*i], positions[3*i+1], positions[3*i+2]}
+		id, ok := ids[key]
+		if !ok {
+			id = int32(len(ids))
+			ids[key] = id
+		}
+		weld[i] = id
+	}
+
+	// Lists the faces of each welded vertex in compressed rows
+	rows := make([]int32, len(ids)+1)
+	for _, idx := range indices[:3*faces] {
+		rows[weld[idx]+1]++
+	}
+	for i := 1; i < len(rows); i++ {
+		rows[i] += rows[i-1]
+	}
+	cols := make([]int32, rows[len(rows)-1])
+	next := append([]int32(nil), rows[:len(ids)]...)
+	for i, idx := range indices[:3*faces] {
+		w := weld[idx]
+		cols[next[w]] = int32(i / 3)
+		next[w]++
+	}
+
+	// Sums the normals of the faces of each vertex
+	normals := make([]float32, 3*count)
+	parallelRange(count, threads, func(start, end int) {
+		var n math32.Vector3
+		for i := start; i < end; i++ {
+			w := weld[i]
+			n.Set(0, 0, 0)
+			for _, f := range cols[rows[w]:rows[w+1]] {
+				n.X += faceNormals[3*f]
+				n.Y += faceNormals[3*f+1]
+				n.Z += faceNormals[3*f+2]
+			}
+			n.Normalize()
+			normals[3*i] = n.X
+			normals[3*i+1] = n.Y
+			normals[3*i+2] = n.Z
+		}
+	})
+	return normals
+}
+
+// parallelRange calls the specified function for consecutive ranges of
+// the specified number of items from the specified number of goroutines.
+func parallelRange(count, threads int, f func(start, end int)) {
+
+	if threads > count {
+		threads = count
+	}
+	if threads <= 1 {
+		f(0, count)
+		return
+	}
+	var wg sync.WaitGroup
+	size := (count + threads - 1) / threads
+	for start := 0; start < count; start += size {
+		end := start + size
+		if end > count {
+			end = count
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			f(start, end)
+		}(start, end)
+	}
+	wg.Wait()
+}
+
+// aoDirections returns the specified number of directions in the hemisphere around +Z
+// with a cosine distribution, spread over a spiral.
+func aoDirections(samples int) []math32.Vector3 {
+
+	golden := math.Pi * (3 - math.Sqrt(5))
+	dirs := make([]math32.Vector3, samples)
+	for i := range dirs {
+		u := (float64(i) + 0.5) / float64(samples)
+		r := math.Sqrt(u)
+		phi := float64(i) * golden
+		dirs[i].Set(float32(r*math.Cos(phi)), float32(r*math.Sin(phi)), float32(math.Sqrt(1-u)))
+	}
+	return dirs
+}
+
+// tangentFrame sets the specified vectors to an orthonormal basis with the specified unit normal.
+func tangentFrame(n, t, b *math32.Vector3) {
+
+	if math32.Abs(n.X) > 0.9 {
+		t.Set(0, 1, 0)
+	} else {
+		t.Set(1, 0, 0)
+	}
+	b.CrossVectors(n, t).Normalize()
+	t.CrossVectors(b, n)
+}
+
+// hashVertex returns a pseudo random number for the specified vertex index.
+func hashVertex(i uint32) uint32 {
+
+	i ^= i >> 16
+	i *= 0x7feb352d
+	i ^= i >> 15
+	i *= 0x846ca68b
+	i ^= i >> 16
+	return i
+}
+
+// bvhNode is a node of a bounding volume hierarchy of triangles.
+type bvhNode struct {
+	box   math32.Box3 // Bounding box of the triangles of the node
+	left  int32       // Index of the first child node (the second follows it) or -1 for leaves
+	start int32       // Index of the first triangle of a leaf
+	count int32       // Number of triangles of a leaf
+}
+
+// bvh is a bounding volume hierarchy of the triangles of a geometry used to cast rays.
+type bvh struct {
+	nodes []bvhNode
+	tris  []math32.Vector3 // Vertices of the triangles sorted by node
+	faces []int32          // Indices of the triangles sorted by node
+}
+
+// newBVH creates and returns a bounding volume hierarchy of the specified triangles.
+func newBVH(positions []float32, indices []uint32) *bvh {
+
+	faces := len(indices) / 3
+	order := make([]int32, faces)
+	centers := make([]math32.Vector3, faces)
+	for f := range order {
+		order[f] = int32(f)
+		for j := 0; j < 3; j++ {
+			p := 3 * indices[3*f+j]
+			centers[f].X += positions[p] / 3
+			centers[f].Y += positions[p+1] / 3
+			centers[f].Z += positions[p+2] / 3
+		}
+	}
+	h := new(bvh)
+	if faces > 0 {
+		h.nodes = append(h.nodes, bvhNode{})
+		h.build(0, order, 0, positions, indices, centers)
+	}
+	h.faces = order
+	h.tris = make([]math32.Vector3, 3*faces)
+	for i, f := range order {
+		for j := 0; j < 3; j++ {
+			p := 3 * indices[3*int(f)+j]
+			h.tris[3*i+j].Set(positions[p], positions[p+1], positions[p+2])
+		}
+	}
+	return h
+}
+
+// build sets the specified node for the specified triangles, which start at the specified
+// index of the sorted triangles, splitting them at the median of the largest axis of their centers.
+func (h *bvh) build(node int, order []int32, start int, positions []float32, indices []uint32, centers []math32.Vector3) {
+
+	var box, cbox math32.Box3
+	var v math32.Vector3
+	box.MakeEmpty()
+	cbox.MakeEmpty()
+	for _, f := range order {
+		for j := 0; j < 3; j++ {
+			p := 3 * indices[3*int(f)+j]
+			box.ExpandByPoint(v.Set(positions[p], positions[p+1], positions[p+2]))
+		}
+		cbox.ExpandByPoint(&centers[f])
+	}
+	h.nodes[node].box = box
+	if len(order) <= bvhLeafSize {
+		h.nodes[node].left = -1
+		h.nodes[node].start = int32(start)
+		h.nodes[node].count = int32(len(order))
+		return
+	}
+
+	var size math32.Vector3
+	cbox.Size(&size)
+	axis := func(c *math32.Vector3) float32 { return c.X }
+	if size.Y > size.X && size.Y >= size.Z {
+		axis = func(c *math32.Vector3) float32 { return c.Y }
+	} else if size.Z > size.X && size.Z > size.Y {
+		axis = func(c *math32.Vector3) float32 { return c.Z }
+	}
+	sort.Slice(order, func(i, j int) bool { return axis(&centers[order[i]]) < axis(&centers[order[j]]) })
+
+	left := len(h.nodes)
+	h.nodes[node].left = int32(left)
+	h.nodes = append(h.nodes, bvhNode{}, bvhNode{})
+	mid := len(order) / 2
+	h.build(left, order[:mid], start, positions, indices, centers)
+	h.build(left+1, order[mid:], start+mid, positions, indices, centers)
+}
+
+// hit returns whether the ray from the specified origin in the specified
+// unit direction hits any triangle within the specified distance.
+func (h *bvh) hit(origin, dir *math32.Vector3, dist float32) bool {
+
+	if len(h.nodes) == 0 {
+		return false
+	}
+	inv := math32.Vector3{X: 1 / dir.X, Y: 1 / dir.Y, Z: 1 / dir.Z}
+	var stack [64]int32
+	sp := 1
+	for sp > 0 {
+		sp--
+		node := &h.nodes[stack[sp]]
+		if !rayHitsBox(origin, &inv, &node.box, dist) {
+			continue
+		}
+		if node.left < 0 {
+			for i := node.start; i < node.start+node.count; i++ {
+				if rayHitsTriangle(origin, dir, &h.tris[3*i], &h.tris[3*i+1], &h.tris[3*i+2], dist) {
+					return true
+				}
+			}
+			continue
+		}
+		stack[sp] = node.left
+		stack[sp+1] = node.left + 1
+		sp += 2
+	}
+	return false
+}
+
+// raycast calls the specified function with the index of each triangle in the
+// leaves whose bounding boxes are intersected by the specified ray.
+func (h *bvh) raycast(ray *math32.Ray, cb func(face int)) {
+
+	if len(h.nodes) == 0 {
+		return
+	}
+	var stack [64]int32
+	sp := 1
+	for sp > 0 {
+		sp--
+		node := &h.nodes[stack[sp]]
+		if !ray.IsIntersectionBox(&node.box) {
+			continue
+		}
+		if node.left < 0 {
+			for i := node.start; i < node.start+node.count; i++ {
+				cb(int(h.faces[i]))
+			}
+			continue
+		}
+		stack[sp] = node.left
+		stack[sp+1] = node.left + 1
+		sp += 2
+	}
+}
+
+// rayHitsBox returns whether the ray from the specified origin with the specified
+// inverse direction hits the specified box within the specified distance.
+func rayHitsBox(origin, inv *math32.Vector3, box *math32.Box3, dist float32) bool {
+
+	tmin, tmax := float32(0), dist
+	slab := func(o, inv, min, max float32) bool {
+		t0 := (min - o) * inv
+		t1 := (max - o) * inv
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+		if t0 > tmin {
+			tmin = t0
+		}
+		if t1 < tmax {
+			tmax = t1
+		}
+		return tmin <= tmax
+	}
+	return slab(origin.X, inv.X, box.Min.X, box.Max.X) &&
+		slab(origin.Y, inv.Y, box.Min.Y, box.Max.Y) &&
+		slab(origin.Z, inv.Z, box.Min.Z, box.Max.Z)
+}
+
+// rayHitsTriangle returns whether the ray from the specified origin in the specified
+// direction hits the specified triangle, from any side, within the specified distance.
+func rayHitsTriangle(origin, dir, a, b, c *math32.Vector3, dist float32) bool {
+
+	const eps = 1e-7
+	var ab, ac, p, t, q math32.Vector3
+	ab.SubVectors(b, a)
+	ac.SubVectors(c, a)
+	p.CrossVectors(dir, &ac)
+	det := ab.Dot(&p)
+	if det > -eps && det < eps {
+		return false
+	}
+	inv := 1 / det
+	t.SubVectors(origin, a)
+	u := t.Dot(&p) * inv
+	if u < 0 || u > 1 {
+		return false
+	}
+	q.CrossVectors(&t, &ab)
+	v := dir.Dot(&q) * inv
+	if v < 0 || u+v > 1 {
+		return false
+	}
+	d := ac.Dot(&q) * inv
+	return d > 0 && d < dist
+}
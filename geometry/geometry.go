@@ -6,6 +6,7 @@
 package geometry
 
 import (
+	"fmt"
 	"math"
 	"strconv"
 
@@ -34,6 +35,7 @@ type Geometry struct {
 	indices       math32.ArrayU32   // Buffer with indices
 	handleIndices uint32            // Handle to OpenGL buffer for indices
 	updateIndices bool              // Flag to indicate that indices must be transferred
+	gen           uint32            // Generation of the OpenGL context of the handles
 	ShaderDefines gls.ShaderDefines // Geometry-specific shader defines
 
 	// Geometric properties
@@ -42,6 +44,7 @@ type Geometry struct {
 	area           float32        // Last calculated area
 	volume         float32        // Last calculated volume
 	rotInertia     math32.Matrix3 // Last calculated rotational inertia matrix
+	bvh            *bvh           // Last built bounding volume hierarchy of the triangles
 
 	// Flags indicating whether geometric properties are valid
 	boundingBoxValid    bool // Indicates if last calculated bounding box is valid
@@ -49,6 +52,7 @@ type Geometry struct {
 	areaValid           bool // Indicates if last calculated area is valid
 	volumeValid         bool // Indicates if last calculated volume is valid
 	rotInertiaValid     bool // Indicates if last calculated rotational inertia matrix is valid
+	bvhValid            bool // Indicates if last built bounding volume hierarchy is valid
 }
 
 // Group is a geometry group object.
@@ -110,6 +114,45 @@ func (g *Geometry) GroupAt(idx int) *Group {
 	return &g.groups[idx]
 }
 
+// GroupBoundingBox computes and returns the bounding box of the vertices
+// of the geometry group at the specified index.
+func (g *Geometry) GroupBoundingBox(idx int) math32.Box3 {
+
+	var box math32.Box3
+	box.Min.Set(math.MaxFloat32, math.MaxFloat32, math.MaxFloat32)
+	box.Max.Set(-math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32)
+
+	// Get buffer with position vertices
+	vbo := g.VBO(gls.VertexPosition)
+	if vbo == nil {
+		return box
+	}
+	group := &g.groups[idx]
+
+	// If geometry has indexed vertices need to loop over the group indexes
+	if g.Indexed() {
+		var vertex math32.Vector3
+		positions := vbo.Buffer()
+		for i := group.Start; i < group.Start+group.Count && i < g.indices.Size(); i++ {
+			positions.GetVector3(int(3*g.indices[i]), &vertex)
+			box.ExpandByPoint(&vertex)
+		}
+	} else {
+		i := 0
+		vbo.ReadVectors3(gls.VertexPosition, func(vertex math32.Vector3) bool {
+			if i >= group.Start+group.Count {
+				return true
+			}
+			if i >= group.Start {
+				box.ExpandByPoint(&vertex)
+			}
+			i++
+			return false
+		})
+	}
+	return box
+}
+
 // SetIndices sets the indices array for this geometry.
 func (g *Geometry) SetIndices(indices math32.ArrayU32) {
 
@@ -117,6 +160,7 @@ func (g *Geometry) SetIndices(indices math32.ArrayU32) {
 	g.updateIndices = true
 	g.boundingBoxValid = false
 	g.boundingSphereValid = false
+	g.bvhValid = false
 }
 
 // Indices returns the indices array for this geometry.
@@ -203,6 +247,50 @@ func (g *Geometry) AttributeName(atype gls.AttribType) string {
 	return g.VBO(atype).Attrib(atype).Name
 }
 
+// UpdateVBO sets the values of the specified attribute starting at the vertex with the specified
+// offset from the data slice, which must contain complete items of the attribute (e.g. 3 floats per
+// position). Only the updated range of the VBO buffer is transferred to OpenGL at the next render.
+func (g *Geometry) UpdateVBO(atype gls.AttribType, offset int, data []float32) error {
+
+	vbo := g.VBO(atype)
+	if vbo == nil {
+		return fmt.Errorf("geometry has no VBO with attribute type:%d", atype)
+	}
+	size := int(vbo.Attrib(atype).NumElements)
+	if len(data)%size != 0 {
+		return fmt.Errorf("data length:%d is not a multiple of the attribute size:%d", len(data), size)
+	}
+	stride := vbo.Stride()
+	count := len(data) / size
+	buffer := *vbo.Buffer()
+	if offset < 0 || (offset+count)*stride > len(buffer) {
+		return fmt.Errorf("vertices [%d,%d) out of range", offset, offset+count)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	// Copy the items to the buffer and marks the range of updated elements
+	start := offset*stride + vbo.AttribOffset(atype)
+	pos := start
+	for i := 0; i < len(data); i += size {
+		copy(buffer[pos:pos+size], data[i:i+size])
+		pos += stride
+	}
+	vbo.UpdateRange(start, pos-stride+size-start)
+
+	// Geometric properties may have changed
+	if atype == gls.VertexPosition {
+		g.boundingBoxValid = false
+		g.boundingSphereValid = false
+		g.areaValid = false
+		g.volumeValid = false
+		g.rotInertiaValid = false
+		g.bvhValid = false
+	}
+	return nil
+}
+
 // OperateOnVertices iterates over all the vertices and calls
 // the specified callback function with a pointer to each vertex.
 // The vertex pointers can be modified inside the callback and
@@ -223,6 +311,7 @@ func (g *Geometry) OperateOnVertices(cb func(vertex *math32.Vector3) bool) {
 	g.areaValid = false
 	g.volumeValid = false
 	g.rotInertiaValid = false
+	g.bvhValid = false
 }
 
 // ReadVertices iterates over all the vertices and calls
@@ -328,6 +417,39 @@ func (g *Geometry) BoundingBox() math32.Box3 {
 	return g.boundingBox
 }
 
+// RaycastFaces calls the specified function with the index of each triangle of the geometry
+// whose bounding box is intersected by the specified ray in model coordinates. The triangles
+// are found with a bounding volume hierarchy, which is built if necessary and rebuilt,
+// as the bounding box, after the vertex positions or indices are changed.
+func (g *Geometry) RaycastFaces(ray *math32.Ray, cb func(face int)) {
+
+	if !g.bvhValid {
+		g.bvh = nil
+		if positions := g.bakePositions(); positions != nil {
+			g.bvh = newBVH(positions, g.bakeIndices(len(positions)/3))
+		}
+		g.bvhValid = true
+	}
+	if g.bvh != nil {
+		g.bvh.raycast(ray, cb)
+	}
+}
+
+// ComputeOBB computes and returns a tight fitting oriented bounding box of the geometry
+// aligned with the principal axes of its vertices, which fits rotated long thin geometries
+// much better than the axis aligned bounding box. The result is not cached.
+func (g *Geometry) ComputeOBB() math32.OBB {
+
+	points := make([]math32.Vector3, 0, g.Items())
+	g.ReadVertices(func(vertex math32.Vector3) bool {
+		points = append(points, vertex)
+		return false
+	})
+	var obb math32.OBB
+	obb.SetFromPoints(points)
+	return obb
+}
+
 // BoundingSphere computes the bounding sphere of this geometry
 // if necessary and returns its value.
 func (g *Geometry) BoundingSphere() math32.Sphere {
@@ -477,6 +599,21 @@ func (g *Geometry) ApplyMatrix(m *math32.Matrix4) {
 	})
 }
 
+// Clone returns a new geometry with copies of the VBOs, indices, groups and shader
+// defines of this geometry, which can be changed without affecting this geometry.
+// Use Incref instead to share this geometry and its VBOs with another graphic.
+func (g *Geometry) Clone() *Geometry {
+
+	clone := NewGeometry()
+	for _, vbo := range g.vbos {
+		clone.vbos = append(clone.vbos, vbo.Clone())
+	}
+	clone.indices = append(math32.ArrayU32(nil), g.indices...)
+	clone.groups = append(clone.groups, g.groups...)
+	clone.ShaderDefines.Add(&g.ShaderDefines)
+	return clone
+}
+
 // Incref increments the reference count for this geometry
 // and returns a pointer to the geometry.
 // It should be used when this geometry is shared by another
@@ -497,8 +634,8 @@ func (g *Geometry) Dispose() {
 		g.refcount--
 		return
 	}
-	// Delete VAO and indices buffer
-	if g.gs != nil {
+	// Delete VAO and indices buffer unless the OpenGL context was reset
+	if g.gs != nil && g.gen == g.gs.Generation() {
 		g.gs.DeleteVertexArrays(g.handleVAO)
 		g.gs.DeleteBuffers(g.handleIndices)
 	}
@@ -512,14 +649,16 @@ func (g *Geometry) Dispose() {
 // RenderSetup is called by the renderer before drawing the geometry.
 func (g *Geometry) RenderSetup(gs *gls.GLS) {
 
-	// First time initialization
-	if g.gs == nil {
+	// First time initialization or recreation after the OpenGL context was reset
+	if g.gs == nil || g.gen != gs.Generation() {
 		// Generate VAO
 		g.handleVAO = gs.GenVertexArray()
 		// Generate buffer for indices
 		g.handleIndices = gs.GenBuffer()
+		g.updateIndices = true
 		// Save pointer to gs indicating initialization was done
 		g.gs = gs
+		g.gen = gs.Generation()
 	}
 
 	// Update VBOs
@@ -0,0 +1,179 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// mergeAttrib describes one vertex attribute of a merged geometry.
+type mergeAttrib struct {
+	atype gls.AttribType  // Type of the attribute
+	name  string          // Name of the attribute
+	size  int             // Number of elements of the attribute
+	data  math32.ArrayF32 // Concatenated values of the attribute
+}
+
+// Merge returns a new indexed geometry with the vertices of the specified geometries transformed by
+// their corresponding matrices, so many static objects can be rendered with a single draw call.
+// The transforms may be nil or contain nil matrices for the geometries which are not transformed.
+// The positions are transformed by the matrices and the normals and tangents by their rotations.
+// All the geometries must have the same vertex attributes, which are concatenated in separate VBOs,
+// and the indices of each geometry, or sequential indices if it's not indexed, are offset by its first vertex.
+// The groups of the geometries are discarded.
+func Merge(geoms []*Geometry, transforms []*math32.Matrix4) (*Geometry, error) {
+
+	return merge(geoms, transforms, false)
+}
+
+// MergeGroups returns a new indexed geometry like Merge with one group for the indices of each
+// of the specified geometries, whose material index is the index of the geometry, so the merged
+// geometry can be rendered with a different material for each source geometry.
+func MergeGroups(geoms []*Geometry, transforms []*math32.Matrix4) (*Geometry, error) {
+
+	return merge(geoms, transforms, true)
+}
+
+// merge merges the specified geometries adding a group for each geometry if requested.
+func merge(geoms []*Geometry, transforms []*math32.Matrix4, groups bool) (*Geometry, error) {
+
+	if len(geoms) == 0 {
+		return nil, fmt.Errorf("no geometries to merge")
+	}
+	if transforms != nil && len(transforms) != len(geoms) {
+		return nil, fmt.Errorf("number of transforms (%d) is different from the number of geometries (%d)", len(transforms), len(geoms))
+	}
+
+	// The attributes of the first geometry are the attributes of the merged geometry
+	var attribs []*mergeAttrib
+	for _, vbo := range geoms[0].vbos {
+		for _, a := range vbo.Attributes() {
+			attribs = append(attribs, &mergeAttrib{atype: a.Type, name: a.Name, size: int(a.NumElements)})
+		}
+	}
+
+	merged := NewGeometry()
+	var indices math32.ArrayU32
+	for gi, g := range geoms {
+		if err := mergeCheck(g, attribs); err != nil {
+			return nil, fmt.Errorf("geometry %d: %v", gi, err)
+		}
+		var m *math32.Matrix4
+		if transforms != nil {
+			m = transforms[gi]
+		}
+		var normalMatrix, tangentMatrix math32.Matrix3
+		if m != nil {
+			normalMatrix.GetNormalMatrix(m)
+			tangentMatrix.SetFromMatrix4(m)
+		}
+
+		// Append the attributes of the vertices
+		first := 0
+		if len(attribs) > 0 {
+			first = attribs[0].data.Size() / attribs[0].size
+		}
+		items := g.Items()
+		for _, ma := range attribs {
+			vbo := g.VBOName(ma.name)
+			a := vbo.AttribName(ma.name)
+			buffer := vbo.Buffer()
+			stride := vbo.StrideSize() / 4
+			offset := int(a.ByteOffset) / 4
+			start := ma.data.Size()
+			for i := 0; i < items; i++ {
+				ma.data.Append((*buffer)[i*stride+offset : i*stride+offset+ma.size]...)
+			}
+			if m != nil {
+				mergeTransform(ma, ma.data[start:], m, &normalMatrix, &tangentMatrix)
+			}
+		}
+
+		// Append the indices offset by the first vertex, reversing the
+		// winding of the triangles if the transform mirrors them
+		start := indices.Size()
+		if g.Indexed() {
+			for _, idx := range g.indices {
+				indices.Append(uint32(first) + idx)
+			}
+		} else {
+			for i := 0; i < items; i++ {
+				indices.Append(uint32(first + i))
+			}
+		}
+		if m != nil && m.Determinant() < 0 {
+			for i := start; i+2 < indices.Size(); i += 3 {
+				indices[i+1], indices[i+2] = indices[i+2], indices[i+1]
+			}
+		}
+		if groups {
+			merged.AddGroup(start, indices.Size()-start, gi)
+		}
+	}
+
+	for _, ma := range attribs {
+		vbo := gls.NewVBO(ma.data)
+		if ma.atype != gls.Undefined {
+			vbo.AddAttrib(ma.atype)
+		} else {
+			vbo.AddCustomAttrib(ma.name, int32(ma.size))
+		}
+		merged.AddVBO(vbo)
+	}
+	merged.SetIndices(indices)
+	return merged, nil
+}
+
+// mergeCheck returns an error if the specified geometry can't be merged into a geometry with the specified attributes.
+func mergeCheck(g *Geometry, attribs []*mergeAttrib) error {
+
+	count := 0
+	for _, vbo := range g.vbos {
+		if vbo.Divisor() != 0 {
+			return fmt.Errorf("instanced attributes can't be merged")
+		}
+		count += vbo.AttribCount()
+	}
+	if count != len(attribs) {
+		return fmt.Errorf("has %d attributes instead of %d", count, len(attribs))
+	}
+	for _, ma := range attribs {
+		vbo := g.VBOName(ma.name)
+		if vbo == nil {
+			return fmt.Errorf("has no %s attribute", ma.name)
+		}
+		a := vbo.AttribName(ma.name)
+		if int(a.NumElements) != ma.size || a.ElementType != gls.FLOAT {
+			return fmt.Errorf("%s attribute has a different format", ma.name)
+		}
+	}
+	return nil
+}
+
+// mergeTransform transforms the specified values of an attribute by the specified matrix if it's
+// a position, by the normal matrix if it's a normal or by the tangent matrix if it's a tangent.
+func mergeTransform(ma *mergeAttrib, data math32.ArrayF32, m *math32.Matrix4, normalMatrix, tangentMatrix *math32.Matrix3) {
+
+	var v math32.Vector3
+	for i := 0; i+ma.size <= len(data); i += ma.size {
+		switch ma.atype {
+		case gls.VertexPosition:
+			data.GetVector3(i, &v)
+			v.ApplyMatrix4(m)
+		case gls.VertexNormal:
+			data.GetVector3(i, &v)
+			v.ApplyMatrix3(normalMatrix).Normalize()
+		case gls.VertexTangent:
+			data.GetVector3(i, &v)
+			v.ApplyMatrix3(tangentMatrix).Normalize()
+		default:
+			return
+		}
+		data.SetVector3(i, &v)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// newTestTriangle returns a non indexed triangle in the XY plane with a VBO
+// for each of its positions, normals and custom weights.
+func newTestTriangle() *Geometry {
+
+	g := NewGeometry()
+	g.AddVBO(gls.NewVBO(math32.ArrayF32{0, 0, 0, 1, 0, 0, 0, 1, 0}).AddAttrib(gls.VertexPosition))
+	g.AddVBO(gls.NewVBO(math32.ArrayF32{0, 0, 1, 0, 0, 1, 0, 0, 1}).AddAttrib(gls.VertexNormal))
+	g.AddVBO(gls.NewVBO(math32.ArrayF32{0.1, 0.2, 0.3}).AddCustomAttrib("Weight", 1))
+	return g
+}
+
+// newTestQuad returns an indexed unit quad in the XY plane with
+// its positions, normals and custom weights in an interleaved VBO.
+func newTestQuad() *Geometry {
+
+	g := NewGeometry()
+	g.AddVBO(gls.NewVBO(math32.ArrayF32{
+		0, 0, 0, 0, 0, 1, 1,
+		1, 0, 0, 0, 0, 1, 2,
+		1, 1, 0, 0, 0, 1, 3,
+		0, 1, 0, 0, 0, 1, 4,
+	}).AddAttrib(gls.VertexPosition).AddAttrib(gls.VertexNormal).AddCustomAttrib("Weight", 1))
+	g.SetIndices(math32.ArrayU32{0, 1, 2, 0, 2, 3})
+	return g
+}
+
+// almostEqualArrays returns whether the specified arrays are equal within a small tolerance.
+func almostEqualArrays(a, b []float32) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math32.Abs(a[i]-b[i]) > 1e-6 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMerge(t *testing.T) {
+
+	// The quad is rotated 90 degrees around X and translated and the second triangle is mirrored
+	var rot, trans, quadMatrix, mirror math32.Matrix4
+	rot.MakeRotationX(math32.Pi / 2)
+	trans.MakeTranslation(10, 0, 0)
+	quadMatrix.MultiplyMatrices(&trans, &rot)
+	mirror.MakeScale(-1, 1, 1)
+	geoms := []*Geometry{newTestTriangle(), newTestQuad(), newTestTriangle()}
+	transforms := []*math32.Matrix4{nil, &quadMatrix, &mirror}
+
+	for _, groups := range []bool{false, true} {
+		var merged *Geometry
+		var err error
+		if groups {
+			merged, err = MergeGroups(geoms, transforms)
+		} else {
+			merged, err = Merge(geoms, transforms)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		tests := []struct {
+			name string
+			vbo  *gls.VBO
+			want []float32
+		}{
+			{"positions", merged.VBO(gls.VertexPosition), []float32{
+				0, 0, 0, 1, 0, 0, 0, 1, 0,
+				10, 0, 0, 11, 0, 0, 11, 0, 1, 10, 0, 1,
+				0, 0, 0, -1, 0, 0, 0, 1, 0,
+			}},
+			{"normals", merged.VBO(gls.VertexNormal), []float32{
+				0, 0, 1, 0, 0, 1, 0, 0, 1,
+				0, -1, 0, 0, -1, 0, 0, -1, 0, 0, -1, 0,
+				0, 0, 1, 0, 0, 1, 0, 0, 1,
+			}},
+			{"weights", merged.VBOName("Weight"), []float32{0.1, 0.2, 0.3, 1, 2, 3, 4, 0.1, 0.2, 0.3}},
+		}
+		for _, test := range tests {
+			if test.vbo == nil {
+				t.Errorf("%s: no VBO", test.name)
+				continue
+			}
+			if got := *test.vbo.Buffer(); !almostEqualArrays(got, test.want) {
+				t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+			}
+		}
+
+		// The indices of the mirrored triangle have their winding reversed
+		want := math32.ArrayU32{0, 1, 2, 3, 4, 5, 3, 5, 6, 7, 9, 8}
+		if !reflect.DeepEqual(merged.Indices(), want) {
+			t.Errorf("indices %v, want %v", merged.Indices(), want)
+		}
+		var wantGroups []Group
+		if groups {
+			wantGroups = []Group{{Start: 0, Count: 3, Matindex: 0}, {Start: 3, Count: 6, Matindex: 1}, {Start: 9, Count: 3, Matindex: 2}}
+		}
+		var gotGroups []Group
+		for i := 0; i < merged.GroupCount(); i++ {
+			gotGroups = append(gotGroups, *merged.GroupAt(i))
+		}
+		if !reflect.DeepEqual(gotGroups, wantGroups) {
+			t.Errorf("groups %v, want %v", gotGroups, wantGroups)
+		}
+	}
+
+	// Merging without transforms keeps the source vertices
+	merged, err := Merge([]*Geometry{newTestQuad(), newTestQuad()}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Items() != 8 || len(merged.Indices()) != 12 {
+		t.Errorf("merged %d vertices and %d indices", merged.Items(), len(merged.Indices()))
+	}
+}
+
+func TestMergeErrors(t *testing.T) {
+
+	// Geometries with different attributes than the test triangle
+	missing := NewGeometry()
+	missing.AddVBO(gls.NewVBO(math32.ArrayF32{0, 0, 0}).AddAttrib(gls.VertexPosition).AddAttrib(gls.VertexNormal))
+	renamed := newTestQuad()
+	renamed.VBOs()[0].AttribName("Weight").Name = "Height"
+	resized := NewGeometry()
+	resized.AddVBO(gls.NewVBO(math32.ArrayF32{0, 0, 0, 0, 0, 1, 0, 0}).AddAttrib(gls.VertexPosition).
+		AddAttrib(gls.VertexNormal).AddCustomAttrib("Weight", 2))
+	instanced := newTestTriangle()
+	instanced.VBOName("Weight").SetDivisor(1)
+
+	tests := []struct {
+		name       string
+		geoms      []*Geometry
+		transforms []*math32.Matrix4
+		err        string
+	}{
+		{"no geometries", nil, nil, "no geometries"},
+		{"transform count", []*Geometry{newTestTriangle()}, []*math32.Matrix4{nil, nil}, "number of transforms"},
+		{"missing attribute", []*Geometry{newTestTriangle(), missing}, nil, "geometry 1: has 2 attributes instead of 3"},
+		{"renamed attribute", []*Geometry{newTestTriangle(), renamed}, nil, "geometry 1: has no Weight attribute"},
+		{"attribute size", []*Geometry{newTestTriangle(), resized}, nil, "geometry 1: Weight attribute has a different format"},
+		{"instanced attribute", []*Geometry{instanced}, nil, "geometry 0: instanced attributes"},
+	}
+	for _, test := range tests {
+		_, err := Merge(test.geoms, test.transforms)
+		if err == nil {
+			t.Errorf("%s: no error", test.name)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), test.err) {
+			t.Errorf("%s: error %q, want %q", test.name, err, test.err)
+		}
+	}
+}
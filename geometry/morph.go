@@ -59,6 +59,12 @@ func (mg *MorphGeometry) Weights() []float32 {
 	return mg.weights
 }
 
+// Targets returns the morph target geometries, which contain the deltas from the base geometry.
+func (mg *MorphGeometry) Targets() []*Geometry {
+
+	return mg.targets
+}
+
 // AddMorphTargets add multiple morph targets to the morph geometry.
 // Morph target deltas are calculated internally and the morph target geometries are altered to hold the deltas instead.
 func (mg *MorphGeometry) AddMorphTargets(morphTargets ...*Geometry) {
@@ -0,0 +1,80 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// NewPathStrip creates a flat strip geometry with the specified width following the
+// specified 2D polyline path in the XY plane with Z=0. The path runs along the center of the strip.
+// The U texture coordinate goes from 0 to 1 along the path and the V texture coordinate
+// goes from 0 on the right side of the path to 1 on its left side.
+func NewPathStrip(path []math32.Vector2, width float32) *Geometry {
+
+	strip := NewGeometry()
+	halfWidth := width / 2
+
+	// Calculate the total length of the path
+	var length float32
+	for i := 1; i < len(path); i++ {
+		length += path[i].DistanceTo(&path[i-1])
+	}
+
+	// Create buffers
+	positions := math32.NewArrayF32(0, 6*len(path))
+	normals := math32.NewArrayF32(0, 6*len(path))
+	uvs := math32.NewArrayF32(0, 4*len(path))
+	indices := math32.NewArrayU32(0, 6*len(path))
+
+	// Generate two vertices for each path point displaced along the normal of the
+	// path which is the average of the normals of the adjacent segments.
+	var dist float32
+	for i := range path {
+		var dir, seg math32.Vector2
+		if i > 0 {
+			seg.SubVectors(&path[i], &path[i-1]).Normalize()
+			dir.Add(&seg)
+			dist += path[i].DistanceTo(&path[i-1])
+		}
+		if i < len(path)-1 {
+			seg.SubVectors(&path[i+1], &path[i]).Normalize()
+			dir.Add(&seg)
+		}
+		dir.Normalize()
+		nx, ny := -dir.Y*halfWidth, dir.X*halfWidth
+		u := float32(0)
+		if length > 0 {
+			u = dist / length
+		}
+		p := path[i]
+		positions.Append(p.X-nx, p.Y-ny, 0, p.X+nx, p.Y+ny, 0)
+		normals.Append(0, 0, 1, 0, 0, 1)
+		uvs.Append(u, 0, u, 1)
+	}
+
+	// Generate indices for the two triangles of each segment
+	for i := 1; i < len(path); i++ {
+		a := uint32(2 * (i - 1))
+		b := a + 2
+		indices.Append(a, b, b+1, a, b+1, a+1)
+	}
+
+	strip.SetIndices(indices)
+	strip.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	strip.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	strip.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+
+	// Update area
+	strip.area = length * width
+	strip.areaValid = true
+
+	// Update volume
+	strip.volume = 0
+	strip.volumeValid = true
+
+	return strip
+}
@@ -2,6 +2,9 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+//go:build !soft
+// +build !soft
+
 package gls
 
 // Generation of API files: glapi.c, glapi.h, consts.go
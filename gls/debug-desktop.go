@@ -0,0 +1,76 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !wasm && !soft
+// +build !wasm,!soft
+
+package gls
+
+// #include "glcorearb.h"
+// #include "glapi.h"
+//
+// extern void goDebugCallback(GLenum source, GLenum type, GLuint id, GLenum severity, GLsizei length, GLchar *message);
+//
+// static inline void APIENTRY debugCallback(GLenum source, GLenum type, GLuint id, GLenum severity, GLsizei length, const GLchar *message, const void *userParam) {
+// 	goDebugCallback(source, type, id, severity, length, (GLchar *)message);
+// }
+//
+// static inline int setDebugCallback(int enable) {
+// 	return glapiDebugMessageCallback(enable ? debugCallback : NULL, NULL);
+// }
+import "C"
+
+import (
+	"fmt"
+)
+
+// Callback which receives the debug output messages
+var debugCallback DebugCallback
+
+// EnableDebug enables the OpenGL debug output and sets the specified callback
+// to receive the driver messages. If the callback is nil the messages are sent
+// to the package logger. The messages are generated synchronously, so the
+// callback is called from inside the OpenGL call which caused the message.
+// Returns an error if the debug output is not supported by the OpenGL driver,
+// which requires OpenGL 4.3 or the KHR_debug extension and normally a debug context.
+func (gs *GLS) EnableDebug(cb DebugCallback) error {
+
+	if cb == nil {
+		cb = logDebugMessage
+	}
+	if C.setDebugCallback(1) != 0 {
+		return fmt.Errorf("OpenGL debug output not supported")
+	}
+	debugCallback = cb
+	gs.Enable(DEBUG_OUTPUT)
+	gs.Enable(DEBUG_OUTPUT_SYNCHRONOUS)
+	return nil
+}
+
+// DisableDebug disables the OpenGL debug output.
+func (gs *GLS) DisableDebug() {
+
+	if debugCallback == nil {
+		return
+	}
+	gs.Disable(DEBUG_OUTPUT)
+	gs.Disable(DEBUG_OUTPUT_SYNCHRONOUS)
+	C.setDebugCallback(0)
+	debugCallback = nil
+}
+
+//export goDebugCallback
+func goDebugCallback(source, gltype C.GLenum, id C.GLuint, severity C.GLenum, length C.GLsizei, message *C.GLchar) {
+
+	if debugCallback == nil {
+		return
+	}
+	debugCallback(&DebugMessage{
+		Source:   uint32(source),
+		Type:     uint32(gltype),
+		ID:       uint32(id),
+		Severity: uint32(severity),
+		Message:  C.GoStringN((*C.char)(message), C.int(length)),
+	})
+}
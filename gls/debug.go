@@ -0,0 +1,100 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gls
+
+import (
+	"fmt"
+)
+
+// OpenGL debug output constants (OpenGL 4.3 or KHR_debug)
+const (
+	DEBUG_OUTPUT                   = 0x92E0
+	DEBUG_OUTPUT_SYNCHRONOUS       = 0x8242
+	DEBUG_SOURCE_API               = 0x8246
+	DEBUG_SOURCE_WINDOW_SYSTEM     = 0x8247
+	DEBUG_SOURCE_SHADER_COMPILER   = 0x8248
+	DEBUG_SOURCE_THIRD_PARTY       = 0x8249
+	DEBUG_SOURCE_APPLICATION       = 0x824A
+	DEBUG_SOURCE_OTHER             = 0x824B
+	DEBUG_TYPE_ERROR               = 0x824C
+	DEBUG_TYPE_DEPRECATED_BEHAVIOR = 0x824D
+	DEBUG_TYPE_UNDEFINED_BEHAVIOR  = 0x824E
+	DEBUG_TYPE_PORTABILITY         = 0x824F
+	DEBUG_TYPE_PERFORMANCE         = 0x8250
+	DEBUG_TYPE_OTHER               = 0x8251
+	DEBUG_TYPE_MARKER              = 0x8268
+	DEBUG_TYPE_PUSH_GROUP          = 0x8269
+	DEBUG_TYPE_POP_GROUP           = 0x826A
+	DEBUG_SEVERITY_HIGH            = 0x9146
+	DEBUG_SEVERITY_MEDIUM          = 0x9147
+	DEBUG_SEVERITY_LOW             = 0x9148
+	DEBUG_SEVERITY_NOTIFICATION    = 0x826B
+)
+
+// DebugMessage describes a message generated by the OpenGL debug output.
+type DebugMessage struct {
+	Source   uint32 // Source of the message (DEBUG_SOURCE_*)
+	Type     uint32 // Type of the message (DEBUG_TYPE_*)
+	ID       uint32 // Implementation dependent message id
+	Severity uint32 // Severity of the message (DEBUG_SEVERITY_*)
+	Message  string // Message text
+}
+
+// DebugCallback is the type of the functions which receive OpenGL debug output messages.
+type DebugCallback func(msg *DebugMessage)
+
+// debugNames contains the names of the debug output sources, types and severities.
+var debugNames = map[uint32]string{
+	DEBUG_SOURCE_API:               "API",
+	DEBUG_SOURCE_WINDOW_SYSTEM:     "WindowSystem",
+	DEBUG_SOURCE_SHADER_COMPILER:   "ShaderCompiler",
+	DEBUG_SOURCE_THIRD_PARTY:       "ThirdParty",
+	DEBUG_SOURCE_APPLICATION:       "Application",
+	DEBUG_SOURCE_OTHER:             "Other",
+	DEBUG_TYPE_ERROR:               "Error",
+	DEBUG_TYPE_DEPRECATED_BEHAVIOR: "DeprecatedBehavior",
+	DEBUG_TYPE_UNDEFINED_BEHAVIOR:  "UndefinedBehavior",
+	DEBUG_TYPE_PORTABILITY:         "Portability",
+	DEBUG_TYPE_PERFORMANCE:         "Performance",
+	DEBUG_TYPE_OTHER:               "Other",
+	DEBUG_TYPE_MARKER:              "Marker",
+	DEBUG_TYPE_PUSH_GROUP:          "PushGroup",
+	DEBUG_TYPE_POP_GROUP:           "PopGroup",
+	DEBUG_SEVERITY_HIGH:            "High",
+	DEBUG_SEVERITY_MEDIUM:          "Medium",
+	DEBUG_SEVERITY_LOW:             "Low",
+	DEBUG_SEVERITY_NOTIFICATION:    "Notification",
+}
+
+// debugName returns the name of the specified debug output enum.
+func debugName(v uint32) string {
+
+	name, ok := debugNames[v]
+	if !ok {
+		return fmt.Sprintf("0x%X", v)
+	}
+	return name
+}
+
+// String returns a description of the debug message with the names of its source, type and severity.
+func (m *DebugMessage) String() string {
+
+	return fmt.Sprintf("source:%s type:%s id:%d severity:%s %s",
+		debugName(m.Source), debugName(m.Type), m.ID, debugName(m.Severity), m.Message)
+}
+
+// logDebugMessage is the default debug callback which sends
+// the debug message to the package logger according to its severity.
+func logDebugMessage(msg *DebugMessage) {
+
+	switch msg.Severity {
+	case DEBUG_SEVERITY_HIGH:
+		log.Error("%s", msg)
+	case DEBUG_SEVERITY_MEDIUM, DEBUG_SEVERITY_LOW:
+		log.Warn("%s", msg)
+	default:
+		log.Debug("%s", msg)
+	}
+}
@@ -13,4 +13,7 @@ int glapiLoad(void);
 // Set the internal flag to enable/disable OpenGL error checking
 void glapiCheckError(int check);
 
+// Sets the callback of the OpenGL debug output if supported
+int glapiDebugMessageCallback(GLDEBUGPROC callback, const void *userParam);
+
 #endif
@@ -85,7 +85,9 @@ func genFile(templText string, td *GLHeader, fout string, gosrc bool) error {
 //
 // Template for glapi C file
 //
-const templGLAPIC = `
+const templGLAPIC = `//go:build !soft
+// +build !soft
+
 // This file was generated automatically by "glapi2go" and contains functions to
 // open the platform's OpenGL dll/shared library and to load all OpenGL function
 // pointers for an specified OpenGL version described by the header file "glcorearb.h",
@@ -214,6 +216,9 @@ static int checkError = 1;
 // Declaration of internal function for loading OpenGL function pointers
 static void load_procs();
 
+// Pointer to the optional debug output function (OpenGL 4.3 or KHR_debug)
+static PFNGLDEBUGMESSAGECALLBACKPROC pglDebugMessageCallback;
+
 //
 // glapiLoad() tries to load functions addresses from the OpenGL library
 //
@@ -224,6 +229,7 @@ int glapiLoad(void) {
 		return res;
 	}
 	load_procs();
+	pglDebugMessageCallback = (PFNGLDEBUGMESSAGECALLBACKPROC)get_proc("glDebugMessageCallback");
 	close_libgl();
 	return 0;
 }
@@ -237,6 +243,19 @@ void glapiCheckError(int check) {
 	checkError = check;
 }
 
+//
+// glapiDebugMessageCallback sets the callback of the OpenGL debug output
+// Returns -1 if the debug output is not supported by the OpenGL library
+//
+int glapiDebugMessageCallback(GLDEBUGPROC callback, const void *userParam) {
+
+	if (pglDebugMessageCallback == NULL) {
+		return -1;
+	}
+	pglDebugMessageCallback(callback, userParam);
+	return 0;
+}
+
 // Internal function to abort process when error
 static void panic(GLenum err, const char* fname) {
 
@@ -336,6 +355,9 @@ int glapiLoad(void);
 // Set the internal flag to enable/disable OpenGL error checking
 void glapiCheckError(int check);
 
+// Sets the callback of the OpenGL debug output if supported
+int glapiDebugMessageCallback(GLDEBUGPROC callback, const void *userParam);
+
 #endif
 `
 
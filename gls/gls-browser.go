@@ -21,6 +21,7 @@ type GLS struct {
 	prog        *Program          // current active shader program
 	programs    map[*Program]bool // shader programs cache
 	checkErrors bool              // check openGL API errors flag
+	generation  uint32            // generation of the OpenGL context incremented by Reset
 
 	// Cache WebGL state to avoid making unnecessary API calls
 	activeTexture       uint32      // cached last set active texture unit
@@ -47,6 +48,9 @@ type GLS struct {
 	polygonModeMode     uint32      // cached last set polygon mode mode
 	polygonOffsetFactor float32     // cached last set polygon offset factor
 	polygonOffsetUnits  float32     // cached last set polygon offset units
+	cullFace            uint32      // cached last set cull face mode
+	vao                 uint32      // cached last bound vertex array object
+	boundTextures       texBindings // cached textures bound to each texture unit target
 
 	// js.Value storage maps
 	programMap      map[uint32]js.Value
@@ -122,6 +126,16 @@ func (gs *GLS) CheckErrors() bool {
 	return gs.checkErrors
 }
 
+// EnableDebug is not supported by WebGL and always returns an error.
+func (gs *GLS) EnableDebug(cb DebugCallback) error {
+
+	return fmt.Errorf("WebGL debug output not supported")
+}
+
+// DisableDebug is not supported by WebGL and does nothing.
+func (gs *GLS) DisableDebug() {
+}
+
 // reset resets the internal state kept of the WebGL
 func (gs *GLS) reset() {
 
@@ -148,6 +162,9 @@ func (gs *GLS) reset() {
 	gs.polygonModeMode = 0
 	gs.polygonOffsetFactor = -1
 	gs.polygonOffsetUnits = -1
+	gs.cullFace = 0
+	gs.vao = uintUndef
+	gs.boundTextures = make(texBindings)
 }
 
 // setDefaultState is used internally to set the initial state of WebGL
@@ -175,6 +192,33 @@ func (gs *GLS) setDefaultState() {
 	//gs.Enable(POLYGON_OFFSET_POINT)
 }
 
+// clearObjects discards the WebGL objects of a lost context.
+// The indexes are not reset so the names of the lost objects are not reused.
+func (gs *GLS) clearObjects() {
+
+	gs.programMap = make(map[uint32]js.Value)
+	gs.shaderMap = make(map[uint32]js.Value)
+	gs.bufferMap = make(map[uint32]js.Value)
+	gs.framebufferMap = make(map[uint32]js.Value)
+	gs.renderbufferMap = make(map[uint32]js.Value)
+	gs.textureMap = make(map[uint32]js.Value)
+	gs.uniformMap = make(map[uint32]js.Value)
+	gs.vertexArrayMap = make(map[uint32]js.Value)
+}
+
+// ResetStateCache discards the cached WebGL state so the next state calls are always issued.
+// It must be called after the WebGL state is changed without using this GLS object.
+func (gs *GLS) ResetStateCache() {
+
+	prog := gs.prog
+	programs := gs.programs
+	gs.reset()
+	gs.programs = programs
+	if prog != nil {
+		gs.UseProgram(prog)
+	}
+}
+
 // Stats copy the current values of the internal statistics structure
 // to the specified pointer.
 func (gs *GLS) Stats(s *Stats) {
@@ -189,6 +233,7 @@ func (gs *GLS) Stats(s *Stats) {
 func (gs *GLS) ActiveTexture(texture uint32) {
 
 	if gs.activeTexture == texture {
+		gs.stats.Statehits++
 		return
 	}
 	gs.gl.Call("activeTexture", int(texture))
@@ -210,24 +255,44 @@ func (gs *GLS) BindBuffer(target int, vbo uint32) {
 	gs.checkError("BindBuffer")
 }
 
+// BindBufferBase binds a buffer object to the specified index of
+// the binding points of the specified target, such as UNIFORM_BUFFER.
+func (gs *GLS) BindBufferBase(target uint32, index uint32, buffer uint32) {
+
+	gs.gl.Call("bindBufferBase", int(target), int(index), gs.bufferMap[buffer])
+	gs.checkError("BindBufferBase")
+}
+
 // BindTexture lets you create or use a named texture.
 func (gs *GLS) BindTexture(target int, tex uint32) {
 
+	key := texBinding{unit: gs.activeTexture, target: target}
+	if bound, ok := gs.boundTextures[key]; ok && bound == tex {
+		gs.stats.Texhits++
+		return
+	}
 	gs.gl.Call("bindTexture", target, gs.textureMap[tex])
 	gs.checkError("BindTexture")
+	gs.boundTextures[key] = tex
 }
 
 // BindVertexArray binds the vertex array object.
 func (gs *GLS) BindVertexArray(vao uint32) {
 
+	if gs.vao == vao {
+		gs.stats.Vaohits++
+		return
+	}
 	gs.gl.Call("bindVertexArray", gs.vertexArrayMap[vao])
 	gs.checkError("BindVertexArray")
+	gs.vao = vao
 }
 
 // BlendEquation sets the blend equations for all draw buffers.
 func (gs *GLS) BlendEquation(mode uint32) {
 
 	if gs.blendEquation == mode {
+		gs.stats.Statehits++
 		return
 	}
 	gs.gl.Call("blendEquation", int(mode))
@@ -240,6 +305,7 @@ func (gs *GLS) BlendEquation(mode uint32) {
 func (gs *GLS) BlendEquationSeparate(modeRGB uint32, modeAlpha uint32) {
 
 	if gs.blendEquationRGB == modeRGB && gs.blendEquationAlpha == modeAlpha {
+		gs.stats.Statehits++
 		return
 	}
 	gs.gl.Call("blendEquationSeparate", int(modeRGB), int(modeAlpha))
@@ -253,12 +319,18 @@ func (gs *GLS) BlendEquationSeparate(modeRGB uint32, modeAlpha uint32) {
 func (gs *GLS) BlendFunc(sfactor, dfactor uint32) {
 
 	if gs.blendSrc == sfactor && gs.blendDst == dfactor {
+		gs.stats.Statehits++
 		return
 	}
 	gs.gl.Call("blendFunc", int(sfactor), int(dfactor))
 	gs.checkError("BlendFunc")
 	gs.blendSrc = sfactor
 	gs.blendDst = dfactor
+	// The separate factors are also set by glBlendFunc
+	gs.blendSrcRGB = sfactor
+	gs.blendDstRGB = dfactor
+	gs.blendSrcAlpha = sfactor
+	gs.blendDstAlpha = dfactor
 }
 
 // BlendFuncSeparate defines the operation of blending for all draw buffers when blending
@@ -267,6 +339,7 @@ func (gs *GLS) BlendFuncSeparate(srcRGB uint32, dstRGB uint32, srcAlpha uint32,
 
 	if gs.blendSrcRGB == srcRGB && gs.blendDstRGB == dstRGB &&
 		gs.blendSrcAlpha == srcAlpha && gs.blendDstAlpha == dstAlpha {
+		gs.stats.Statehits++
 		return
 	}
 	gs.gl.Call("blendFuncSeparate", int(srcRGB), int(dstRGB), int(srcAlpha), int(dstAlpha))
@@ -275,18 +348,37 @@ func (gs *GLS) BlendFuncSeparate(srcRGB uint32, dstRGB uint32, srcAlpha uint32,
 	gs.blendDstRGB = dstRGB
 	gs.blendSrcAlpha = srcAlpha
 	gs.blendDstAlpha = dstAlpha
+	// Forces the next BlendFunc call as the factors may now differ
+	gs.blendSrc = uintUndef
+	gs.blendDst = uintUndef
 }
 
 // BufferData creates a new data store for the buffer object currently
 // bound to target, deleting any pre-existing data store.
 func (gs *GLS) BufferData(target uint32, size int, data interface{}, usage uint32) {
 
+	// Only allocates the data store if no data was specified
+	if data == nil {
+		gs.gl.Call("bufferData", int(target), size, int(usage))
+		gs.checkError("BufferData")
+		return
+	}
 	dataTA, free := wasm.SliceToTypedArray(data)
 	gs.gl.Call("bufferData", int(target), dataTA, int(usage))
 	gs.checkError("BufferData")
 	free()
 }
 
+// BufferSubData updates a subset of the data store of the buffer object currently bound to target,
+// starting at the specified offset in bytes, with size bytes of the specified data.
+func (gs *GLS) BufferSubData(target uint32, offset int, size int, data interface{}) {
+
+	dataTA, free := wasm.SliceToTypedArray(data)
+	gs.gl.Call("bufferSubData", int(target), offset, dataTA)
+	gs.checkError("BufferSubData")
+	free()
+}
+
 // ClearColor specifies the red, green, blue, and alpha values
 // used by glClear to clear the color buffers.
 func (gs *GLS) ClearColor(r, g, b, a float32) {
@@ -387,6 +479,7 @@ func (gs *GLS) DeleteTextures(tex ...uint32) {
 		delete(gs.textureMap, t)
 		gs.stats.Textures--
 	}
+	gs.boundTextures.unbind(tex)
 }
 
 // DeleteVertexArrays deletes n​vertex array objects named
@@ -398,6 +491,10 @@ func (gs *GLS) DeleteVertexArrays(vaos ...uint32) {
 		gs.checkError("DeleteVertexArrays")
 		delete(gs.vertexArrayMap, v)
 		gs.stats.Vaos--
+		// Deleting the bound vertex array object binds the default one
+		if gs.vao == v {
+			gs.vao = 0
+		}
 	}
 }
 
@@ -408,6 +505,7 @@ func (gs *GLS) DeleteVertexArrays(vaos ...uint32) {
 func (gs *GLS) DepthFunc(mode uint32) {
 
 	if gs.depthFunc == mode {
+		gs.stats.Statehits++
 		return
 	}
 	gs.gl.Call("depthFunc", int(mode))
@@ -419,9 +517,11 @@ func (gs *GLS) DepthFunc(mode uint32) {
 func (gs *GLS) DepthMask(flag bool) {
 
 	if gs.depthMask == intTrue && flag {
+		gs.stats.Statehits++
 		return
 	}
 	if gs.depthMask == intFalse && !flag {
+		gs.stats.Statehits++
 		return
 	}
 	gs.gl.Call("depthMask", flag)
@@ -433,12 +533,42 @@ func (gs *GLS) DepthMask(flag bool) {
 	}
 }
 
+// ColorMask enables or disables writing the red, green, blue and alpha components into the color buffer.
+func (gs *GLS) ColorMask(red, green, blue, alpha bool) {
+
+	gs.gl.Call("colorMask", red, green, blue, alpha)
+	gs.checkError("ColorMask")
+}
+
+// StencilOp sets the actions on the stencil buffer when the stencil test fails,
+// when the stencil test passes and the depth test fails and when both pass.
+func (gs *GLS) StencilOp(fail, zfail, zpass uint32) {
+
+	gs.gl.Call("stencilOp", int(fail), int(zfail), int(zpass))
+	gs.checkError("StencilOp")
+}
+
+// StencilFunc sets the function, reference value and mask of the stencil test.
+func (gs *GLS) StencilFunc(mode uint32, ref int32, mask uint32) {
+
+	gs.gl.Call("stencilFunc", int(mode), ref, mask)
+	gs.checkError("StencilFunc")
+}
+
+// StencilMask sets the mask of the bits written into the stencil buffer.
+func (gs *GLS) StencilMask(mask uint32) {
+
+	gs.gl.Call("stencilMask", mask)
+	gs.checkError("StencilMask")
+}
+
 // DrawArrays renders primitives from array data.
 func (gs *GLS) DrawArrays(mode uint32, first int32, count int32) {
 
 	gs.gl.Call("drawArrays", int(mode), first, count)
 	gs.checkError("DrawArrays")
 	gs.stats.Drawcalls++
+	gs.stats.Triangles += triangleCount(mode, count, 1)
 }
 
 // DrawElements renders primitives from array data.
@@ -447,6 +577,25 @@ func (gs *GLS) DrawElements(mode uint32, count int32, itype uint32, start uint32
 	gs.gl.Call("drawElements", int(mode), count, int(itype), start)
 	gs.checkError("DrawElements")
 	gs.stats.Drawcalls++
+	gs.stats.Triangles += triangleCount(mode, count, 1)
+}
+
+// DrawArraysInstanced renders multiple instances of primitives from array data.
+func (gs *GLS) DrawArraysInstanced(mode uint32, first int32, count int32, instances int32) {
+
+	gs.gl.Call("drawArraysInstanced", int(mode), first, count, instances)
+	gs.checkError("DrawArraysInstanced")
+	gs.stats.Drawcalls++
+	gs.stats.Triangles += triangleCount(mode, count, instances)
+}
+
+// DrawElementsInstanced renders multiple instances of primitives from array data.
+func (gs *GLS) DrawElementsInstanced(mode uint32, count int32, itype uint32, start uint32, instances int32) {
+
+	gs.gl.Call("drawElementsInstanced", int(mode), count, int(itype), start, instances)
+	gs.checkError("DrawElementsInstanced")
+	gs.stats.Drawcalls++
+	gs.stats.Triangles += triangleCount(mode, count, instances)
 }
 
 // Enable enables the specified capability.
@@ -483,14 +632,20 @@ func (gs *GLS) EnableVertexAttribArray(index uint32) {
 // CullFace specifies whether front- or back-facing facets can be culled.
 func (gs *GLS) CullFace(mode uint32) {
 
+	if gs.cullFace == mode {
+		gs.stats.Statehits++
+		return
+	}
 	gs.gl.Call("cullFace", int(mode))
 	gs.checkError("CullFace")
+	gs.cullFace = mode
 }
 
 // FrontFace defines front- and back-facing polygons.
 func (gs *GLS) FrontFace(mode uint32) {
 
 	if gs.frontFace == mode {
+		gs.stats.Statehits++
 		return
 	}
 	gs.gl.Call("frontFace", int(mode))
@@ -579,6 +734,32 @@ func (gs *GLS) GetShaderInfoLog(shader uint32) string {
 	return res
 }
 
+// CompressedTextureFormats enables the WebGL compressed texture extensions available
+// and returns the compressed internal texture formats supported by the WebGL context.
+func (gs *GLS) CompressedTextureFormats() []uint32 {
+
+	for _, ext := range []string{
+		"WEBGL_compressed_texture_s3tc",
+		"WEBGL_compressed_texture_s3tc_srgb",
+		"EXT_texture_compression_bptc",
+		"EXT_texture_compression_rgtc",
+		"WEBGL_compressed_texture_etc",
+		"WEBGL_compressed_texture_astc",
+	} {
+		gs.gl.Call("getExtension", ext)
+	}
+	values := gs.gl.Call("getParameter", int(COMPRESSED_TEXTURE_FORMATS))
+	gs.checkError("CompressedTextureFormats")
+	if wasm.Equal(values, js.Null()) || wasm.Equal(values, js.Undefined()) {
+		return nil
+	}
+	formats := make([]uint32, values.Length())
+	for i := range formats {
+		formats[i] = uint32(values.Index(i).Int())
+	}
+	return formats
+}
+
 // GetString returns a string describing the specified aspect of the current GL connection.
 func (gs *GLS) GetString(name uint32) string {
 
@@ -601,6 +782,23 @@ func (gs *GLS) GetUniformLocation(program uint32, name string) int32 {
 	return int32(idx)
 }
 
+// GetUniformBlockIndex returns the index of the specified uniform block
+// in the specified program or INVALID_INDEX if not found.
+func (gs *GLS) GetUniformBlockIndex(program uint32, name string) uint32 {
+
+	idx := gs.gl.Call("getUniformBlockIndex", gs.programMap[program], name)
+	gs.checkError("GetUniformBlockIndex")
+	return uint32(idx.Int())
+}
+
+// UniformBlockBinding assigns the specified binding point to
+// the uniform block with the specified index in the specified program.
+func (gs *GLS) UniformBlockBinding(program uint32, blockIndex uint32, binding uint32) {
+
+	gs.gl.Call("uniformBlockBinding", gs.programMap[program], int(blockIndex), int(binding))
+	gs.checkError("UniformBlockBinding")
+}
+
 // GetViewport returns the current viewport information.
 func (gs *GLS) GetViewport() (x, y, width, height int32) {
 
@@ -611,6 +809,7 @@ func (gs *GLS) GetViewport() (x, y, width, height int32) {
 func (gs *GLS) LineWidth(width float32) {
 
 	if gs.lineWidth == width {
+		gs.stats.Statehits++
 		return
 	}
 	gs.gl.Call("lineWidth", width)
@@ -665,6 +864,16 @@ func (gs *GLS) TexImage2D(target uint32, level int32, iformat int32, width int32
 	free()
 }
 
+// TexSubImage2D replaces a rectangular region of the current two-dimensional texture
+// image without reallocating its storage.
+func (gs *GLS) TexSubImage2D(target uint32, level int32, xoffset int32, yoffset int32, width int32, height int32, format uint32, itype uint32, data interface{}) {
+
+	dataTA, free := wasm.SliceToTypedArray(data)
+	gs.gl.Call("texSubImage2D", int(target), level, xoffset, yoffset, width, height, int(format), int(itype), dataTA)
+	gs.checkError("TexSubImage2D")
+	free()
+}
+
 // CompressedTexImage2D specifies a two-dimensional compressed texture image.
 func (gs *GLS) CompressedTexImage2D(target uint32, level uint32, iformat uint32, width int32, height int32, size int32, data interface{}) {
 
@@ -688,6 +897,7 @@ func (gs *GLS) PolygonMode(face, mode uint32) {
 func (gs *GLS) PolygonOffset(factor float32, units float32) {
 
 	if gs.polygonOffsetFactor == factor && gs.polygonOffsetUnits == units {
+		gs.stats.Statehits++
 		return
 	}
 	gs.gl.Call("polygonOffset", factor, units)
@@ -809,6 +1019,14 @@ func (gs *GLS) VertexAttribPointer(index uint32, size int32, xtype uint32, norma
 	gs.checkError("VertexAttribPointer")
 }
 
+// VertexAttribDivisor sets the rate at which the specified generic vertex attribute advances
+// during instanced rendering. A divisor of zero advances it once per vertex.
+func (gs *GLS) VertexAttribDivisor(index uint32, divisor uint32) {
+
+	gs.gl.Call("vertexAttribDivisor", index, divisor)
+	gs.checkError("VertexAttribDivisor")
+}
+
 // Viewport sets the viewport.
 func (gs *GLS) Viewport(x, y, width, height int32) {
 
@@ -826,6 +1044,10 @@ func (gs *GLS) UseProgram(prog *Program) {
 	if prog.handle == 0 {
 		panic("Invalid program")
 	}
+	if gs.prog == prog {
+		gs.stats.Proghits++
+		return
+	}
 
 	gs.gl.Call("useProgram", gs.programMap[prog.handle])
 	gs.checkError("UseProgram")
@@ -2,8 +2,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-//go:build !wasm
-// +build !wasm
+//go:build !wasm && !soft
+// +build !wasm,!soft
 
 package gls
 
@@ -25,6 +25,7 @@ type GLS struct {
 	prog        *Program          // current active shader program
 	programs    map[*Program]bool // shader programs cache
 	checkErrors bool              // check openGL API errors flag
+	generation  uint32            // generation of the OpenGL context incremented by Reset
 
 	// Cache OpenGL state to avoid making unnecessary API calls
 	activeTexture  uint32  // cached last set active texture unit
@@ -53,6 +54,10 @@ type GLS struct {
 	polygonModeMode     uint32      // cached last set polygon mode mode
 	polygonOffsetFactor float32     // cached last set polygon offset factor
 	polygonOffsetUnits  float32     // cached last set polygon offset units
+	cullFace            uint32      // cached last set cull face mode
+	vao                 uint32      // cached last bound vertex array object
+	boundTextures       texBindings // cached textures bound to each texture unit target
+	framebuffer         uint32      // cached last bound frame buffer object
 	gobuf               []byte      // conversion buffer with GO memory
 	cbuf                []byte      // conversion buffer with C memory
 }
@@ -128,6 +133,9 @@ func (gs *GLS) reset() {
 	gs.polygonModeMode = 0
 	gs.polygonOffsetFactor = -1
 	gs.polygonOffsetUnits = -1
+	gs.cullFace = 0
+	gs.vao = uintUndef
+	gs.boundTextures = make(texBindings)
 }
 
 // setDefaultState is used internally to set the initial state of OpenGL
@@ -155,6 +163,25 @@ func (gs *GLS) setDefaultState() {
 	gs.Enable(POLYGON_OFFSET_POINT)
 }
 
+// clearObjects discards the state of the OpenGL objects of a lost context.
+func (gs *GLS) clearObjects() {
+
+	gs.framebuffer = 0
+}
+
+// ResetStateCache discards the cached OpenGL state so the next state calls are always issued.
+// It must be called after the OpenGL state is changed without using this GLS object.
+func (gs *GLS) ResetStateCache() {
+
+	prog := gs.prog
+	programs := gs.programs
+	gs.reset()
+	gs.programs = programs
+	if prog != nil {
+		gs.UseProgram(prog)
+	}
+}
+
 // Stats copy the current values of the internal statistics structure
 // to the specified pointer.
 func (gs *GLS) Stats(s *Stats) {
@@ -169,6 +196,7 @@ func (gs *GLS) Stats(s *Stats) {
 func (gs *GLS) ActiveTexture(texture uint32) {
 
 	if gs.activeTexture == texture {
+		gs.stats.Statehits++
 		return
 	}
 	C.glActiveTexture(C.GLenum(texture))
@@ -187,22 +215,41 @@ func (gs *GLS) BindBuffer(target int, vbo uint32) {
 	C.glBindBuffer(C.GLenum(target), C.GLuint(vbo))
 }
 
+// BindBufferBase binds a buffer object to the specified index of
+// the binding points of the specified target, such as UNIFORM_BUFFER.
+func (gs *GLS) BindBufferBase(target uint32, index uint32, buffer uint32) {
+
+	C.glBindBufferBase(C.GLenum(target), C.GLuint(index), C.GLuint(buffer))
+}
+
 // BindTexture lets you create or use a named texture.
 func (gs *GLS) BindTexture(target int, tex uint32) {
 
+	key := texBinding{unit: gs.activeTexture, target: target}
+	if bound, ok := gs.boundTextures[key]; ok && bound == tex {
+		gs.stats.Texhits++
+		return
+	}
 	C.glBindTexture(C.GLenum(target), C.GLuint(tex))
+	gs.boundTextures[key] = tex
 }
 
 // BindVertexArray binds the vertex array object.
 func (gs *GLS) BindVertexArray(vao uint32) {
 
+	if gs.vao == vao {
+		gs.stats.Vaohits++
+		return
+	}
 	C.glBindVertexArray(C.GLuint(vao))
+	gs.vao = vao
 }
 
 // BlendEquation sets the blend equations for all draw buffers.
 func (gs *GLS) BlendEquation(mode uint32) {
 
 	if gs.blendEquation == mode {
+		gs.stats.Statehits++
 		return
 	}
 	C.glBlendEquation(C.GLenum(mode))
@@ -214,6 +261,7 @@ func (gs *GLS) BlendEquation(mode uint32) {
 func (gs *GLS) BlendEquationSeparate(modeRGB uint32, modeAlpha uint32) {
 
 	if gs.blendEquationRGB == modeRGB && gs.blendEquationAlpha == modeAlpha {
+		gs.stats.Statehits++
 		return
 	}
 	C.glBlendEquationSeparate(C.GLenum(modeRGB), C.GLenum(modeAlpha))
@@ -226,11 +274,17 @@ func (gs *GLS) BlendEquationSeparate(modeRGB uint32, modeAlpha uint32) {
 func (gs *GLS) BlendFunc(sfactor, dfactor uint32) {
 
 	if gs.blendSrc == sfactor && gs.blendDst == dfactor {
+		gs.stats.Statehits++
 		return
 	}
 	C.glBlendFunc(C.GLenum(sfactor), C.GLenum(dfactor))
 	gs.blendSrc = sfactor
 	gs.blendDst = dfactor
+	// The separate factors are also set by glBlendFunc
+	gs.blendSrcRGB = sfactor
+	gs.blendDstRGB = dfactor
+	gs.blendSrcAlpha = sfactor
+	gs.blendDstAlpha = dfactor
 }
 
 // BlendFuncSeparate defines the operation of blending for all draw buffers when blending
@@ -239,6 +293,7 @@ func (gs *GLS) BlendFuncSeparate(srcRGB uint32, dstRGB uint32, srcAlpha uint32,
 
 	if gs.blendSrcRGB == srcRGB && gs.blendDstRGB == dstRGB &&
 		gs.blendSrcAlpha == srcAlpha && gs.blendDstAlpha == dstAlpha {
+		gs.stats.Statehits++
 		return
 	}
 	C.glBlendFuncSeparate(C.GLenum(srcRGB), C.GLenum(dstRGB), C.GLenum(srcAlpha), C.GLenum(dstAlpha))
@@ -246,6 +301,9 @@ func (gs *GLS) BlendFuncSeparate(srcRGB uint32, dstRGB uint32, srcAlpha uint32,
 	gs.blendDstRGB = dstRGB
 	gs.blendSrcAlpha = srcAlpha
 	gs.blendDstAlpha = dstAlpha
+	// Forces the next BlendFunc call as the factors may now differ
+	gs.blendSrc = uintUndef
+	gs.blendDst = uintUndef
 }
 
 // BufferData creates a new data store for the buffer object currently
@@ -255,6 +313,13 @@ func (gs *GLS) BufferData(target uint32, size int, data interface{}, usage uint3
 	C.glBufferData(C.GLenum(target), C.GLsizeiptr(size), ptr(data), C.GLenum(usage))
 }
 
+// BufferSubData updates a subset of the data store of the buffer object currently bound to target,
+// starting at the specified offset in bytes, with size bytes of the specified data.
+func (gs *GLS) BufferSubData(target uint32, offset int, size int, data interface{}) {
+
+	C.glBufferSubData(C.GLenum(target), C.GLintptr(offset), C.GLsizeiptr(size), ptr(data))
+}
+
 // ClearColor specifies the red, green, blue, and alpha values
 // used by glClear to clear the color buffers.
 func (gs *GLS) ClearColor(r, g, b, a float32) {
@@ -332,6 +397,7 @@ func (gs *GLS) DeleteTextures(tex ...uint32) {
 
 	C.glDeleteTextures(C.GLsizei(len(tex)), (*C.GLuint)(&tex[0]))
 	gs.stats.Textures -= len(tex)
+	gs.boundTextures.unbind(tex)
 }
 
 // DeleteVertexArrays deletes n​vertex array objects named
@@ -340,6 +406,12 @@ func (gs *GLS) DeleteVertexArrays(vaos ...uint32) {
 
 	C.glDeleteVertexArrays(C.GLsizei(len(vaos)), (*C.GLuint)(&vaos[0]))
 	gs.stats.Vaos -= len(vaos)
+	// Deleting the bound vertex array object binds the default one
+	for _, vao := range vaos {
+		if gs.vao == vao {
+			gs.vao = 0
+		}
+	}
 }
 
 // ReadPixels returns the current rendered image.
@@ -349,7 +421,7 @@ func (gs *GLS) DeleteVertexArrays(vaos ...uint32) {
 // format_type: specifies the data type of the pixel data.
 // more information: http://docs.gl/gl3/glReadPixels
 func (gs *GLS) ReadPixels(x, y, width, height, format, formatType int) []byte {
-	size := uint32((width - x) * (height - y) * 4)
+	size := uint32(width * height * 4)
 	C.glReadPixels(C.GLint(x), C.GLint(y), C.GLsizei(width), C.GLsizei(height), C.GLenum(format), C.GLenum(formatType), unsafe.Pointer(gs.gobufSize(size)))
 	return gs.gobuf[:size]
 }
@@ -359,6 +431,7 @@ func (gs *GLS) ReadPixels(x, y, width, height, format, formatType int) []byte {
 func (gs *GLS) DepthFunc(mode uint32) {
 
 	if gs.depthFunc == mode {
+		gs.stats.Statehits++
 		return
 	}
 	C.glDepthFunc(C.GLenum(mode))
@@ -369,9 +442,11 @@ func (gs *GLS) DepthFunc(mode uint32) {
 func (gs *GLS) DepthMask(flag bool) {
 
 	if gs.depthMask == intTrue && flag {
+		gs.stats.Statehits++
 		return
 	}
 	if gs.depthMask == intFalse && !flag {
+		gs.stats.Statehits++
 		return
 	}
 	C.glDepthMask(bool2c(flag))
@@ -382,6 +457,12 @@ func (gs *GLS) DepthMask(flag bool) {
 	}
 }
 
+// ColorMask enables or disables writing the red, green, blue and alpha components into the color buffer.
+func (gs *GLS) ColorMask(red, green, blue, alpha bool) {
+
+	C.glColorMask(bool2c(red), bool2c(green), bool2c(blue), bool2c(alpha))
+}
+
 func (gs *GLS) StencilOp(fail, zfail, zpass uint32) {
 
 	// TODO save state
@@ -410,6 +491,7 @@ func (gs *GLS) DrawArrays(mode uint32, first int32, count int32) {
 
 	C.glDrawArrays(C.GLenum(mode), C.GLint(first), C.GLsizei(count))
 	gs.stats.Drawcalls++
+	gs.stats.Triangles += triangleCount(mode, count, 1)
 }
 
 // DrawElements renders primitives from array data.
@@ -417,6 +499,23 @@ func (gs *GLS) DrawElements(mode uint32, count int32, itype uint32, start uint32
 
 	C.glDrawElements(C.GLenum(mode), C.GLsizei(count), C.GLenum(itype), unsafe.Pointer(uintptr(start)))
 	gs.stats.Drawcalls++
+	gs.stats.Triangles += triangleCount(mode, count, 1)
+}
+
+// DrawArraysInstanced renders multiple instances of primitives from array data.
+func (gs *GLS) DrawArraysInstanced(mode uint32, first int32, count int32, instances int32) {
+
+	C.glDrawArraysInstanced(C.GLenum(mode), C.GLint(first), C.GLsizei(count), C.GLsizei(instances))
+	gs.stats.Drawcalls++
+	gs.stats.Triangles += triangleCount(mode, count, instances)
+}
+
+// DrawElementsInstanced renders multiple instances of primitives from array data.
+func (gs *GLS) DrawElementsInstanced(mode uint32, count int32, itype uint32, start uint32, instances int32) {
+
+	C.glDrawElementsInstanced(C.GLenum(mode), C.GLsizei(count), C.GLenum(itype), unsafe.Pointer(uintptr(start)), C.GLsizei(instances))
+	gs.stats.Drawcalls++
+	gs.stats.Triangles += triangleCount(mode, count, instances)
 }
 
 // DrawBuffer sets which color buffers are to be drawn into.
@@ -457,13 +556,19 @@ func (gs *GLS) EnableVertexAttribArray(index uint32) {
 // CullFace specifies whether front- or back-facing facets can be culled.
 func (gs *GLS) CullFace(mode uint32) {
 
+	if gs.cullFace == mode {
+		gs.stats.Statehits++
+		return
+	}
 	C.glCullFace(C.GLenum(mode))
+	gs.cullFace = mode
 }
 
 // FrontFace defines front- and back-facing polygons.
 func (gs *GLS) FrontFace(mode uint32) {
 
 	if gs.frontFace == mode {
+		gs.stats.Statehits++
 		return
 	}
 	C.glFrontFace(C.GLenum(mode))
@@ -489,6 +594,13 @@ func (gs *GLS) GenFramebuffer() uint32 {
 	return fb
 }
 
+// DeleteFramebuffers deletes the specified frame buffer objects.
+func (gs *GLS) DeleteFramebuffers(fbs ...uint32) {
+
+	C.glDeleteFramebuffers(C.GLsizei(len(fbs)), (*C.GLuint)(&fbs[0]))
+	gs.stats.Fbos -= uint64(len(fbs))
+}
+
 // GenRenderbuffer creates a new render buffer.
 func (gs *GLS) GenRenderbuffer() uint32 {
 
@@ -502,6 +614,41 @@ func (gs *GLS) GenRenderbuffer() uint32 {
 func (gs *GLS) BindFramebuffer(fb uint32) {
 
 	C.glBindFramebuffer(FRAMEBUFFER, C.GLuint(fb))
+	gs.framebuffer = fb
+}
+
+// Framebuffer returns the last bound frame buffer object (0 is the default frame buffer).
+func (gs *GLS) Framebuffer() uint32 {
+
+	return gs.framebuffer
+}
+
+// BindReadFramebuffer sets the framebuffer used as the source of BlitFramebuffer
+// without changing the current framebuffer used for drawing.
+func (gs *GLS) BindReadFramebuffer(fb uint32) {
+
+	C.glBindFramebuffer(READ_FRAMEBUFFER, C.GLuint(fb))
+}
+
+// BlitFramebuffer copies a block of pixels from the read framebuffer to the draw framebuffer.
+// Mask is the bitwise OR of COLOR_BUFFER_BIT, DEPTH_BUFFER_BIT and STENCIL_BUFFER_BIT.
+func (gs *GLS) BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask uint32, filter uint32) {
+
+	C.glBlitFramebuffer(C.GLint(srcX0), C.GLint(srcY0), C.GLint(srcX1), C.GLint(srcY1),
+		C.GLint(dstX0), C.GLint(dstY0), C.GLint(dstX1), C.GLint(dstY1), C.GLbitfield(mask), C.GLenum(filter))
+}
+
+// DrawBuffers specifies the list of color buffers of the current framebuffer to be drawn into.
+func (gs *GLS) DrawBuffers(bufs []uint32) {
+
+	C.glDrawBuffers(C.GLsizei(len(bufs)), (*C.GLenum)(&bufs[0]))
+}
+
+// ClearBufferfv clears the specified draw buffer of the current framebuffer to the specified
+// value, without changing the clear color. Buffer is COLOR or DEPTH.
+func (gs *GLS) ClearBufferfv(buffer uint32, drawBuffer int32, value []float32) {
+
+	C.glClearBufferfv(C.GLenum(buffer), C.GLint(drawBuffer), (*C.GLfloat)(&value[0]))
 }
 
 // BindRenderbuffer sets the current render buffer.
@@ -622,6 +769,23 @@ func (gs *GLS) GetShaderInfoLog(shader uint32) string {
 	return string(gs.gobuf[:length])
 }
 
+// CompressedTextureFormats returns the compressed internal texture formats supported by the OpenGL context.
+func (gs *GLS) CompressedTextureFormats() []uint32 {
+
+	var count C.GLint
+	C.glGetIntegerv(C.GLenum(NUM_COMPRESSED_TEXTURE_FORMATS), &count)
+	if count <= 0 {
+		return nil
+	}
+	values := make([]C.GLint, count)
+	C.glGetIntegerv(C.GLenum(COMPRESSED_TEXTURE_FORMATS), &values[0])
+	formats := make([]uint32, count)
+	for i, v := range values {
+		formats[i] = uint32(v)
+	}
+	return formats
+}
+
 // GetString returns a string describing the specified aspect of the current GL connection.
 func (gs *GLS) GetString(name uint32) string {
 
@@ -636,6 +800,20 @@ func (gs *GLS) GetUniformLocation(program uint32, name string) int32 {
 	return int32(loc)
 }
 
+// GetUniformBlockIndex returns the index of the specified uniform block
+// in the specified program or INVALID_INDEX if not found.
+func (gs *GLS) GetUniformBlockIndex(program uint32, name string) uint32 {
+
+	return uint32(C.glGetUniformBlockIndex(C.GLuint(program), gs.gobufStr(name)))
+}
+
+// UniformBlockBinding assigns the specified binding point to
+// the uniform block with the specified index in the specified program.
+func (gs *GLS) UniformBlockBinding(program uint32, blockIndex uint32, binding uint32) {
+
+	C.glUniformBlockBinding(C.GLuint(program), C.GLuint(blockIndex), C.GLuint(binding))
+}
+
 // GetViewport returns the current viewport information.
 func (gs *GLS) GetViewport() (x, y, width, height int32) {
 
@@ -646,6 +824,7 @@ func (gs *GLS) GetViewport() (x, y, width, height int32) {
 func (gs *GLS) LineWidth(width float32) {
 
 	if gs.lineWidth == width {
+		gs.stats.Statehits++
 		return
 	}
 	C.glLineWidth(C.GLfloat(width))
@@ -691,6 +870,21 @@ func (gs *GLS) TexImage2D(target uint32, level int32, iformat int32, width int32
 		ptr(data))
 }
 
+// TexSubImage2D replaces a rectangular region of the current two-dimensional texture
+// image without reallocating its storage.
+func (gs *GLS) TexSubImage2D(target uint32, level int32, xoffset int32, yoffset int32, width int32, height int32, format uint32, itype uint32, data interface{}) {
+
+	C.glTexSubImage2D(C.GLenum(target),
+		C.GLint(level),
+		C.GLint(xoffset),
+		C.GLint(yoffset),
+		C.GLsizei(width),
+		C.GLsizei(height),
+		C.GLenum(format),
+		C.GLenum(itype),
+		ptr(data))
+}
+
 // CompressedTexImage2D specifies a two-dimensional compressed texture image.
 func (gs *GLS) CompressedTexImage2D(target uint32, level uint32, iformat uint32, width int32, height int32, size int32, data interface{}) {
 
@@ -714,6 +908,7 @@ func (gs *GLS) TexParameteri(target uint32, pname uint32, param int32) {
 func (gs *GLS) PolygonMode(face, mode uint32) {
 
 	if gs.polygonModeFace == face && gs.polygonModeMode == mode {
+		gs.stats.Statehits++
 		return
 	}
 	C.glPolygonMode(C.GLenum(face), C.GLenum(mode))
@@ -725,6 +920,7 @@ func (gs *GLS) PolygonMode(face, mode uint32) {
 func (gs *GLS) PolygonOffset(factor float32, units float32) {
 
 	if gs.polygonOffsetFactor == factor && gs.polygonOffsetUnits == units {
+		gs.stats.Statehits++
 		return
 	}
 	C.glPolygonOffset(C.GLfloat(factor), C.GLfloat(units))
@@ -815,6 +1011,13 @@ func (gs *GLS) VertexAttribPointer(index uint32, size int32, xtype uint32, norma
 	C.glVertexAttribPointer(C.GLuint(index), C.GLint(size), C.GLenum(xtype), bool2c(normalized), C.GLsizei(stride), C.GLsizeiptr(offset))
 }
 
+// VertexAttribDivisor sets the rate at which the specified generic vertex attribute advances
+// during instanced rendering. A divisor of zero advances it once per vertex.
+func (gs *GLS) VertexAttribDivisor(index uint32, divisor uint32) {
+
+	C.glVertexAttribDivisor(C.GLuint(index), C.GLuint(divisor))
+}
+
 // Viewport sets the viewport.
 func (gs *GLS) Viewport(x, y, width, height int32) {
 
@@ -831,6 +1034,10 @@ func (gs *GLS) UseProgram(prog *Program) {
 	if prog.handle == 0 {
 		panic("Invalid program")
 	}
+	if gs.prog == prog {
+		gs.stats.Proghits++
+		return
+	}
 	C.glUseProgram(C.GLuint(prog.handle))
 	gs.prog = prog
 
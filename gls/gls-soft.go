@@ -0,0 +1,1936 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build soft && !wasm
+// +build soft,!wasm
+
+package gls
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"unsafe"
+)
+
+// GLS encapsulates the state of an OpenGL context and contains
+// methods to call OpenGL functions.
+//
+// This is the pure Go software implementation selected by the "soft" build tag.
+// It needs neither cgo nor OpenGL drivers and is intended to run tests of the
+// scene, graph and GUI logic on machines without a GPU. It is slow and limited:
+// shaders are accepted but not executed, only filled triangles are rasterized,
+// colored with the vertex colors, the diffuse or base color of the material or the
+// colors of the GUI panels. Textures, lighting, lines, points and stencil are ignored.
+type GLS struct {
+	stats       Stats             // statistics
+	prog        *Program          // current active shader program
+	programs    map[*Program]bool // shader programs cache
+	checkErrors bool              // check openGL API errors flag
+	generation  uint32            // generation of the OpenGL context incremented by Reset
+
+	// Cache OpenGL state to avoid making unnecessary API calls
+	activeTexture  uint32  // cached last set active texture unit
+	viewportX      int32   // cached last set viewport x
+	viewportY      int32   // cached last set viewport y
+	viewportWidth  int32   // cached last set viewport width
+	viewportHeight int32   // cached last set viewport height
+	lineWidth      float32 // cached last set line width
+	sideView       int     // cached last set triangle side view mode
+	frontFace      uint32  // cached last set glFrontFace value
+	depthFunc      uint32  // cached last set depth function
+	depthMask      int     // cached last set depth mask
+	//stencilFunc
+	stencilMask         uint32      // cached last set stencil mask
+	capabilities        map[int]int // cached capabilities (Enable/Disable)
+	blendEquation       uint32      // cached last set blend equation value
+	blendSrc            uint32      // cached last set blend src value
+	blendDst            uint32      // cached last set blend equation destination value
+	blendEquationRGB    uint32      // cached last set blend equation rgb value
+	blendEquationAlpha  uint32      // cached last set blend equation alpha value
+	blendSrcRGB         uint32      // cached last set blend src rgb
+	blendSrcAlpha       uint32      // cached last set blend src alpha value
+	blendDstRGB         uint32      // cached last set blend destination rgb value
+	blendDstAlpha       uint32      // cached last set blend destination alpha value
+	polygonModeFace     uint32      // cached last set polygon mode face
+	polygonModeMode     uint32      // cached last set polygon mode mode
+	polygonOffsetFactor float32     // cached last set polygon offset factor
+	polygonOffsetUnits  float32     // cached last set polygon offset units
+	framebuffer         uint32      // cached last bound frame buffer object
+
+	// Software context state
+	lastName     uint32                      // last generated object name
+	buffers      map[uint32]*softBuffer      // buffer objects
+	vaos         map[uint32]*softVAO         // vertex array objects
+	textures     map[uint32]*softTexture     // texture objects
+	renderbufs   map[uint32]*softTexture     // renderbuffer objects
+	framebuffers map[uint32]*softFramebuffer // framebuffer objects (0 is the default)
+	shaders      map[uint32]*softShader      // shader objects
+	sprograms    map[uint32]*softProgram     // program objects
+	arrayBuffer  uint32                      // bound array buffer
+	vao          uint32                      // bound vertex array object
+	texUnits     map[uint32]uint32           // bound textures per texture unit
+	renderbuf    uint32                      // bound renderbuffer
+	readFb       uint32                      // bound read framebuffer
+	cullFace     uint32                      // last set cull face mode
+	clearColor   [4]float32                  // last set clear color
+	clearDepth   float32                     // last set clear depth
+	scissor      [4]int32                    // last set scissor box
+}
+
+// softBuffer is a buffer object of the software context.
+type softBuffer struct {
+	data []byte
+}
+
+// softAttrib is a generic vertex attribute array of a softVAO.
+type softAttrib struct {
+	enabled bool
+	buffer  uint32
+	size    int32
+	xtype   uint32
+	stride  int32
+	offset  uint32
+	divisor uint32
+}
+
+// softVAO is a vertex array object of the software context.
+type softVAO struct {
+	attribs  map[uint32]*softAttrib
+	elements uint32
+}
+
+// softTexture is a texture or renderbuffer of the software context.
+// Its pixels are only allocated when attached to a framebuffer.
+type softTexture struct {
+	width  int
+	height int
+	depth  bool         // stores depth instead of color
+	surf   *softSurface // pixels when attached to a framebuffer
+}
+
+// softSurface contains the float pixels of a color or depth buffer, from the bottom row.
+type softSurface struct {
+	width  int
+	height int
+	comps  int // 4 for color and 1 for depth
+	pix    []float32
+}
+
+// softFramebuffer is a framebuffer object of the software context.
+type softFramebuffer struct {
+	colors      [8]*softSurface
+	depth       *softSurface
+	drawBuffers []uint32
+	readBuffer  uint32
+}
+
+// softShader is a shader object of the software context.
+type softShader struct {
+	stype  uint32
+	source string
+}
+
+// softProgram is a program object of the software context.
+type softProgram struct {
+	shaders  []uint32
+	attribs  map[string]int32       // attribute locations by name
+	names    map[string]int32       // uniform locations by name
+	uniforms map[int32]*softUniform // uniform values by location
+	blocks   map[string]uint32      // uniform block indices by name
+}
+
+// softUniform is the value of a uniform of a softProgram.
+type softUniform struct {
+	comps int // number of components of each element
+	v     []float32
+}
+
+// softVertex is a transformed vertex ready for rasterization.
+type softVertex struct {
+	x, y, z float32    // window coordinates
+	invW    float32    // inverse of the clip w
+	color   [4]float32 // color divided by w
+	uv      [2]float32 // texture coordinates divided by w
+}
+
+// Matches the vertex shader input declarations with optional explicit locations
+var softAttribRegexp = regexp.MustCompile(`(?m)^\s*(?:layout\s*\(\s*location\s*=\s*(\d+)\s*\)\s*)?in\s+\w+\s+(\w+)\s*;`)
+
+// New creates and returns a new instance of a GLS object,
+// which encapsulates the state of the software context.
+// The default framebuffer has 1x1 pixels until resized by SetDefaultFramebufferSize.
+func New() (*GLS, error) {
+
+	gs := new(GLS)
+	gs.reset()
+	gs.buffers = make(map[uint32]*softBuffer)
+	gs.vaos = map[uint32]*softVAO{0: {attribs: make(map[uint32]*softAttrib)}}
+	gs.textures = make(map[uint32]*softTexture)
+	gs.renderbufs = make(map[uint32]*softTexture)
+	gs.framebuffers = map[uint32]*softFramebuffer{0: {drawBuffers: []uint32{BACK}, readBuffer: BACK}}
+	gs.shaders = make(map[uint32]*softShader)
+	gs.sprograms = make(map[uint32]*softProgram)
+	gs.texUnits = make(map[uint32]uint32)
+	gs.SetDefaultFramebufferSize(1, 1)
+	gs.setDefaultState()
+	gs.checkErrors = true
+	return gs, nil
+}
+
+// SetDefaultFramebufferSize sets the size in pixels of the default framebuffer
+// of the software context, which replaces the window surface.
+// Its contents are cleared with the current clear color and depth and
+// the viewport is resized if it covered the whole previous framebuffer.
+func (gs *GLS) SetDefaultFramebufferSize(width, height int) {
+
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	// Resizes the viewport if it covers the whole framebuffer
+	fb := gs.framebuffers[0]
+	prev := fb.colors[0]
+	if prev == nil || gs.viewportX == 0 && gs.viewportY == 0 &&
+		int(gs.viewportWidth) == prev.width && int(gs.viewportHeight) == prev.height {
+		gs.Viewport(0, 0, int32(width), int32(height))
+	}
+	fb.colors[0] = newSoftSurface(width, height, 4)
+	fb.depth = newSoftSurface(width, height, 1)
+	fb.colors[0].fill(gs.clearColor[:])
+	fb.depth.fill([]float32{gs.clearDepth})
+}
+
+// SetCheckErrors enables/disables checking for errors after the
+// call of any OpenGL function. The software context never reports errors.
+func (gs *GLS) SetCheckErrors(enable bool) {
+
+	gs.checkErrors = enable
+}
+
+// CheckErrors returns if error checking is enabled or not.
+func (gs *GLS) CheckErrors() bool {
+
+	return gs.checkErrors
+}
+
+// reset resets the internal state kept of the OpenGL
+func (gs *GLS) reset() {
+
+	gs.lineWidth = 0.0
+	gs.sideView = uintUndef
+	gs.frontFace = 0
+	gs.depthFunc = 0
+	gs.depthMask = uintUndef
+	gs.capabilities = make(map[int]int)
+	gs.programs = make(map[*Program]bool)
+	gs.prog = nil
+
+	gs.activeTexture = uintUndef
+	gs.blendEquation = uintUndef
+	gs.blendSrc = uintUndef
+	gs.blendDst = uintUndef
+	gs.blendEquationRGB = 0
+	gs.blendEquationAlpha = 0
+	gs.blendSrcRGB = uintUndef
+	gs.blendSrcAlpha = uintUndef
+	gs.blendDstRGB = uintUndef
+	gs.blendDstAlpha = uintUndef
+	gs.polygonModeFace = 0
+	gs.polygonModeMode = 0
+	gs.polygonOffsetFactor = -1
+	gs.polygonOffsetUnits = -1
+}
+
+// clearObjects discards the objects of the software context, except the default
+// framebuffer and vertex array, as when an OpenGL context is lost.
+// The last name is not reset so the names of the discarded objects are not reused.
+func (gs *GLS) clearObjects() {
+
+	gs.buffers = make(map[uint32]*softBuffer)
+	gs.vaos = map[uint32]*softVAO{0: {attribs: make(map[uint32]*softAttrib)}}
+	gs.textures = make(map[uint32]*softTexture)
+	gs.renderbufs = make(map[uint32]*softTexture)
+	gs.framebuffers = map[uint32]*softFramebuffer{0: gs.framebuffers[0]}
+	gs.shaders = make(map[uint32]*softShader)
+	gs.sprograms = make(map[uint32]*softProgram)
+	gs.texUnits = make(map[uint32]uint32)
+	gs.arrayBuffer = 0
+	gs.vao = 0
+	gs.renderbuf = 0
+	gs.readFb = 0
+	gs.framebuffer = 0
+}
+
+// setDefaultState is used internally to set the initial state of OpenGL
+// for this context.
+func (gs *GLS) setDefaultState() {
+
+	gs.ClearColor(0, 0, 0, 1)
+	gs.ClearDepth(1)
+	gs.ClearStencil(0)
+	gs.Enable(DEPTH_TEST)
+	gs.DepthFunc(LEQUAL)
+	gs.FrontFace(CCW)
+	gs.CullFace(BACK)
+	gs.Enable(CULL_FACE)
+	gs.Enable(BLEND)
+	gs.BlendEquation(FUNC_ADD)
+	gs.BlendFunc(SRC_ALPHA, ONE_MINUS_SRC_ALPHA)
+}
+
+// ResetStateCache does nothing in the software context, whose state is never stale.
+func (gs *GLS) ResetStateCache() {
+}
+
+// Stats copy the current values of the internal statistics structure
+// to the specified pointer.
+func (gs *GLS) Stats(s *Stats) {
+
+	*s = gs.stats
+	s.Shaders = len(gs.programs)
+}
+
+// EnableDebug is not supported by the software context and returns an error.
+func (gs *GLS) EnableDebug(cb DebugCallback) error {
+
+	return fmt.Errorf("debug output not supported by the software context")
+}
+
+// DisableDebug does nothing in the software context.
+func (gs *GLS) DisableDebug() {
+}
+
+// ActiveTexture selects which texture unit subsequent texture state calls
+// will affect.
+func (gs *GLS) ActiveTexture(texture uint32) {
+
+	gs.activeTexture = texture
+}
+
+// AttachShader attaches the specified shader object to the specified program object.
+func (gs *GLS) AttachShader(program, shader uint32) {
+
+	if p := gs.sprograms[program]; p != nil {
+		p.shaders = append(p.shaders, shader)
+	}
+}
+
+// BindBuffer binds a buffer object to the specified buffer binding point.
+func (gs *GLS) BindBuffer(target int, vbo uint32) {
+
+	switch target {
+	case ARRAY_BUFFER:
+		gs.arrayBuffer = vbo
+	case ELEMENT_ARRAY_BUFFER:
+		if vao := gs.vaos[gs.vao]; vao != nil {
+			vao.elements = vbo
+		}
+	}
+}
+
+// BindBufferBase binds a buffer object to the specified index of the binding points of
+// the specified target, such as UNIFORM_BUFFER. The uniform blocks are not used by the
+// software implementation, which doesn't execute shaders.
+func (gs *GLS) BindBufferBase(target uint32, index uint32, buffer uint32) {
+
+}
+
+// BindTexture lets you create or use a named texture.
+func (gs *GLS) BindTexture(target int, tex uint32) {
+
+	gs.texUnits[gs.activeTexture] = tex
+}
+
+// BindVertexArray binds the vertex array object.
+func (gs *GLS) BindVertexArray(vao uint32) {
+
+	gs.vao = vao
+}
+
+// BlendEquation sets the blend equations for all draw buffers.
+// Only FUNC_ADD is applied by the software context.
+func (gs *GLS) BlendEquation(mode uint32) {
+
+	gs.blendEquation = mode
+}
+
+// BlendEquationSeparate sets the blend equations for all draw buffers
+// allowing different equations for the RGB and alpha components.
+func (gs *GLS) BlendEquationSeparate(modeRGB uint32, modeAlpha uint32) {
+
+	gs.blendEquationRGB = modeRGB
+	gs.blendEquationAlpha = modeAlpha
+}
+
+// BlendFunc defines the operation of blending for
+// all draw buffers when blending is enabled.
+func (gs *GLS) BlendFunc(sfactor, dfactor uint32) {
+
+	gs.blendSrc = sfactor
+	gs.blendDst = dfactor
+	gs.blendSrcRGB = sfactor
+	gs.blendDstRGB = dfactor
+	gs.blendSrcAlpha = sfactor
+	gs.blendDstAlpha = dfactor
+}
+
+// BlendFuncSeparate defines the operation of blending for all draw buffers when blending
+// is enabled, allowing different operations for the RGB and alpha components.
+func (gs *GLS) BlendFuncSeparate(srcRGB uint32, dstRGB uint32, srcAlpha uint32, dstAlpha uint32) {
+
+	gs.blendSrcRGB = srcRGB
+	gs.blendDstRGB = dstRGB
+	gs.blendSrcAlpha = srcAlpha
+	gs.blendDstAlpha = dstAlpha
+	gs.blendSrc = uintUndef
+	gs.blendDst = uintUndef
+}
+
+// BufferData creates a new data store for the buffer object currently
+// bound to target, deleting any pre-existing data store.
+func (gs *GLS) BufferData(target uint32, size int, data interface{}, usage uint32) {
+
+	buf := gs.boundBuffer(target)
+	if buf == nil {
+		return
+	}
+	buf.data = make([]byte, size)
+	if data != nil && size > 0 {
+		copy(buf.data, softBytes(data, size))
+	}
+}
+
+// BufferSubData updates a subset of the data store of the buffer object currently bound to target,
+// starting at the specified offset in bytes, with size bytes of the specified data.
+func (gs *GLS) BufferSubData(target uint32, offset int, size int, data interface{}) {
+
+	buf := gs.boundBuffer(target)
+	if buf == nil || size <= 0 || offset+size > len(buf.data) {
+		return
+	}
+	copy(buf.data[offset:offset+size], softBytes(data, size))
+}
+
+// ClearColor specifies the red, green, blue, and alpha values
+// used by glClear to clear the color buffers.
+func (gs *GLS) ClearColor(r, g, b, a float32) {
+
+	gs.clearColor = [4]float32{r, g, b, a}
+}
+
+// ClearDepth specifies the depth value used by Clear to clear the depth buffer.
+func (gs *GLS) ClearDepth(v float32) {
+
+	gs.clearDepth = v
+}
+
+// ClearStencil specifies the index used by Clear to clear the stencil buffer.
+// The software context has no stencil buffer.
+func (gs *GLS) ClearStencil(v int32) {
+}
+
+// Clear sets the bitplane area of the window to values previously
+// selected by ClearColor, ClearDepth, and ClearStencil.
+func (gs *GLS) Clear(mask uint) {
+
+	fb := gs.framebuffers[gs.framebuffer]
+	if fb == nil {
+		return
+	}
+	if mask&COLOR_BUFFER_BIT != 0 {
+		for _, surf := range fb.colors {
+			if surf != nil {
+				surf.fill(gs.clearColor[:])
+			}
+		}
+	}
+	if mask&DEPTH_BUFFER_BIT != 0 && fb.depth != nil && gs.depthMask != intFalse {
+		fb.depth.fill([]float32{gs.clearDepth})
+	}
+}
+
+// CompileShader compiles the source code strings that
+// have been stored in the specified shader object.
+// The software context does not compile the shaders.
+func (gs *GLS) CompileShader(shader uint32) {
+}
+
+// CreateProgram creates an empty program object and returns
+// a non-zero value by which it can be referenced.
+func (gs *GLS) CreateProgram() uint32 {
+
+	name := gs.genName()
+	gs.sprograms[name] = &softProgram{
+		attribs:  make(map[string]int32),
+		names:    make(map[string]int32),
+		uniforms: make(map[int32]*softUniform),
+		blocks:   make(map[string]uint32),
+	}
+	return name
+}
+
+// CreateShader creates an empty shader object and returns
+// a non-zero value by which it can be referenced.
+func (gs *GLS) CreateShader(stype uint32) uint32 {
+
+	name := gs.genName()
+	gs.shaders[name] = &softShader{stype: stype}
+	return name
+}
+
+// DeleteBuffers deletes n​buffer objects named
+// by the elements of the provided array.
+func (gs *GLS) DeleteBuffers(bufs ...uint32) {
+
+	for _, buf := range bufs {
+		delete(gs.buffers, buf)
+	}
+	gs.stats.Buffers -= len(bufs)
+}
+
+// DeleteShader frees the memory and invalidates the name
+// associated with the specified shader object.
+func (gs *GLS) DeleteShader(shader uint32) {
+
+	delete(gs.shaders, shader)
+}
+
+// DeleteProgram frees the memory and invalidates the name
+// associated with the specified program object.
+func (gs *GLS) DeleteProgram(program uint32) {
+
+	delete(gs.sprograms, program)
+}
+
+// DeleteTextures deletes n​textures named
+// by the elements of the provided array.
+func (gs *GLS) DeleteTextures(tex ...uint32) {
+
+	for _, t := range tex {
+		delete(gs.textures, t)
+	}
+	gs.stats.Textures -= len(tex)
+}
+
+// DeleteVertexArrays deletes n​vertex array objects named
+// by the elements of the provided array.
+func (gs *GLS) DeleteVertexArrays(vaos ...uint32) {
+
+	for _, vao := range vaos {
+		delete(gs.vaos, vao)
+	}
+	gs.stats.Vaos -= len(vaos)
+}
+
+// ReadPixels returns the current rendered image as RGBA bytes from the bottom row,
+// of the current read framebuffer. The format and formatType are ignored.
+func (gs *GLS) ReadPixels(x, y, width, height, format, formatType int) []byte {
+
+	pix := make([]byte, 4*width*height)
+	surf := gs.readSurface()
+	if surf == nil {
+		return pix
+	}
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			px, py := x+col, y+row
+			if px < 0 || py < 0 || px >= surf.width || py >= surf.height {
+				continue
+			}
+			src := surf.pix[4*(py*surf.width+px):]
+			dst := pix[4*(row*width+col):]
+			for i := 0; i < 4; i++ {
+				dst[i] = byte(math.Round(float64(clampf(src[i], 0, 1) * 255)))
+			}
+		}
+	}
+	return pix
+}
+
+// DepthFunc specifies the function used to compare each incoming pixel
+// depth value with the depth value present in the depth buffer.
+func (gs *GLS) DepthFunc(mode uint32) {
+
+	gs.depthFunc = mode
+}
+
+// DepthMask enables or disables writing into the depth buffer.
+func (gs *GLS) DepthMask(flag bool) {
+
+	if flag {
+		gs.depthMask = intTrue
+	} else {
+		gs.depthMask = intFalse
+	}
+}
+
+// ColorMask is ignored by the software context.
+func (gs *GLS) ColorMask(red, green, blue, alpha bool) {
+}
+
+// StencilOp is ignored by the software context.
+func (gs *GLS) StencilOp(fail, zfail, zpass uint32) {
+}
+
+// StencilFunc is ignored by the software context.
+func (gs *GLS) StencilFunc(mode uint32, ref int32, mask uint32) {
+}
+
+// StencilMask enables or disables writing into the stencil buffer.
+func (gs *GLS) StencilMask(mask uint32) {
+
+	gs.stencilMask = mask
+}
+
+// DrawArrays renders primitives from array data.
+func (gs *GLS) DrawArrays(mode uint32, first int32, count int32) {
+
+	gs.draw(mode, count, 1, func(i int) int { return int(first) + i })
+	gs.stats.Drawcalls++
+	gs.stats.Triangles += triangleCount(mode, count, 1)
+}
+
+// DrawElements renders primitives from array data.
+func (gs *GLS) DrawElements(mode uint32, count int32, itype uint32, start uint32) {
+
+	gs.draw(mode, count, 1, gs.elementIndex(itype, start))
+	gs.stats.Drawcalls++
+	gs.stats.Triangles += triangleCount(mode, count, 1)
+}
+
+// DrawArraysInstanced renders multiple instances of primitives from array data.
+func (gs *GLS) DrawArraysInstanced(mode uint32, first int32, count int32, instances int32) {
+
+	gs.draw(mode, count, int(instances), func(i int) int { return int(first) + i })
+	gs.stats.Drawcalls++
+	gs.stats.Triangles += triangleCount(mode, count, instances)
+}
+
+// DrawElementsInstanced renders multiple instances of primitives from array data.
+func (gs *GLS) DrawElementsInstanced(mode uint32, count int32, itype uint32, start uint32, instances int32) {
+
+	gs.draw(mode, count, int(instances), gs.elementIndex(itype, start))
+	gs.stats.Drawcalls++
+	gs.stats.Triangles += triangleCount(mode, count, instances)
+}
+
+// DrawBuffer sets which color buffers are to be drawn into.
+func (gs *GLS) DrawBuffer(mode uint) {
+
+	if fb := gs.framebuffers[gs.framebuffer]; fb != nil {
+		fb.drawBuffers = []uint32{uint32(mode)}
+	}
+}
+
+// Enable enables the specified capability.
+func (gs *GLS) Enable(cap int) {
+
+	if gs.capabilities[cap] == capEnabled {
+		gs.stats.Caphits++
+		return
+	}
+	gs.capabilities[cap] = capEnabled
+}
+
+// Disable disables the specified capability.
+func (gs *GLS) Disable(cap int) {
+
+	if gs.capabilities[cap] == capDisabled {
+		gs.stats.Caphits++
+		return
+	}
+	gs.capabilities[cap] = capDisabled
+}
+
+// EnableVertexAttribArray enables a generic vertex attribute array.
+func (gs *GLS) EnableVertexAttribArray(index uint32) {
+
+	if attrib := gs.vertexAttrib(index); attrib != nil {
+		attrib.enabled = true
+	}
+}
+
+// CullFace specifies whether front- or back-facing facets can be culled.
+func (gs *GLS) CullFace(mode uint32) {
+
+	gs.cullFace = mode
+}
+
+// FrontFace defines front- and back-facing polygons.
+func (gs *GLS) FrontFace(mode uint32) {
+
+	gs.frontFace = mode
+}
+
+// GenBuffer generates a ​buffer object name.
+func (gs *GLS) GenBuffer() uint32 {
+
+	buf := gs.genName()
+	gs.buffers[buf] = new(softBuffer)
+	gs.stats.Buffers++
+	return buf
+}
+
+// GenFramebuffer creates a new framebuffer.
+func (gs *GLS) GenFramebuffer() uint32 {
+
+	fb := gs.genName()
+	gs.framebuffers[fb] = &softFramebuffer{drawBuffers: []uint32{COLOR_ATTACHMENT0}, readBuffer: COLOR_ATTACHMENT0}
+	gs.stats.Fbos++
+	return fb
+}
+
+// DeleteFramebuffers deletes the specified frame buffer objects.
+func (gs *GLS) DeleteFramebuffers(fbs ...uint32) {
+
+	for _, fb := range fbs {
+		delete(gs.framebuffers, fb)
+	}
+	gs.stats.Fbos -= uint64(len(fbs))
+}
+
+// GenRenderbuffer creates a new render buffer.
+func (gs *GLS) GenRenderbuffer() uint32 {
+
+	rb := gs.genName()
+	gs.renderbufs[rb] = new(softTexture)
+	gs.stats.Rbos++
+	return rb
+}
+
+// BindFramebuffer sets the current framebuffer.
+func (gs *GLS) BindFramebuffer(fb uint32) {
+
+	gs.framebuffer = fb
+	gs.readFb = fb
+}
+
+// Framebuffer returns the last bound frame buffer object (0 is the default frame buffer).
+func (gs *GLS) Framebuffer() uint32 {
+
+	return gs.framebuffer
+}
+
+// BindReadFramebuffer sets the framebuffer used as the source of BlitFramebuffer
+// without changing the current framebuffer used for drawing.
+func (gs *GLS) BindReadFramebuffer(fb uint32) {
+
+	gs.readFb = fb
+}
+
+// BlitFramebuffer copies a block of pixels from the read framebuffer to the draw framebuffer.
+// Mask is the bitwise OR of COLOR_BUFFER_BIT, DEPTH_BUFFER_BIT and STENCIL_BUFFER_BIT.
+// The pixels are always copied with nearest filtering.
+func (gs *GLS) BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask uint32, filter uint32) {
+
+	src := gs.framebuffers[gs.readFb]
+	dst := gs.framebuffers[gs.framebuffer]
+	if src == nil || dst == nil {
+		return
+	}
+	if mask&COLOR_BUFFER_BIT != 0 {
+		blitSoftSurface(gs.readSurface(), dst.drawSurface(), srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1)
+	}
+	if mask&DEPTH_BUFFER_BIT != 0 {
+		blitSoftSurface(src.depth, dst.depth, srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1)
+	}
+}
+
+// DrawBuffers specifies the list of color buffers of the current framebuffer to be drawn into.
+// The software context only draws into the first one.
+func (gs *GLS) DrawBuffers(bufs []uint32) {
+
+	if fb := gs.framebuffers[gs.framebuffer]; fb != nil {
+		fb.drawBuffers = append([]uint32(nil), bufs...)
+	}
+}
+
+// ClearBufferfv clears the specified draw buffer of the current framebuffer to the specified
+// value, without changing the clear color. Buffer is COLOR or DEPTH.
+func (gs *GLS) ClearBufferfv(buffer uint32, drawBuffer int32, value []float32) {
+
+	fb := gs.framebuffers[gs.framebuffer]
+	if fb == nil {
+		return
+	}
+	switch buffer {
+	case COLOR:
+		if int(drawBuffer) < len(fb.drawBuffers) {
+			if surf := fb.attachment(fb.drawBuffers[drawBuffer]); surf != nil {
+				surf.fill(value)
+			}
+		}
+	case DEPTH:
+		if fb.depth != nil {
+			fb.depth.fill(value)
+		}
+	}
+}
+
+// BindRenderbuffer sets the current render buffer.
+func (gs *GLS) BindRenderbuffer(rb uint32) {
+
+	gs.renderbuf = rb
+}
+
+// RenderbufferStorage allocates space for the bound render buffer.
+// Format is the internal storage format, e.g. RGBA32F
+func (gs *GLS) RenderbufferStorage(format uint, width int, height int) {
+
+	if rb := gs.renderbufs[gs.renderbuf]; rb != nil {
+		*rb = softTexture{width: width, height: height, depth: isSoftDepthFormat(uint32(format))}
+	}
+}
+
+// FramebufferRenderbuffer attaches a renderbuffer object to the bound framebuffer object.
+// Attachment is one of COLOR_ATTACHMENT0, DEPTH_ATTACHMENT, or STENCIL_ATTACHMENT.
+func (gs *GLS) FramebufferRenderbuffer(attachment uint, rb uint32) {
+
+	gs.attach(uint32(attachment), gs.renderbufs[rb])
+}
+
+// FramebufferTexture attaches a level of a texture object as a logical buffer of a framebuffer object.
+func (gs *GLS) FramebufferTexture(attachment uint, tex uint32) {
+
+	gs.attach(uint32(attachment), gs.textures[tex])
+}
+
+// FramebufferTexture1D attaches a level of a texture object as a logical buffer to the currently bound framebuffer object
+func (gs *GLS) FramebufferTexture1D(attachment uint, textarget uint, tex uint32) {
+
+	gs.attach(uint32(attachment), gs.textures[tex])
+}
+
+// FramebufferTexture2D attaches a level of a texture object as a logical buffer to the currently bound framebuffer object
+func (gs *GLS) FramebufferTexture2D(attachment uint, textarget uint, tex uint32) {
+
+	gs.attach(uint32(attachment), gs.textures[tex])
+}
+
+// FramebufferTexture3D attaches a level of a texture object as a logical buffer to the currently bound framebuffer object
+func (gs *GLS) FramebufferTexture3D(attachment uint, textarget uint, tex uint32, layer int) {
+
+	gs.attach(uint32(attachment), gs.textures[tex])
+}
+
+// CheckFramebufferStatus get the framebuffer status
+func (gs *GLS) CheckFramebufferStatus() uint32 {
+
+	fb := gs.framebuffers[gs.framebuffer]
+	if fb == nil {
+		return FRAMEBUFFER_UNDEFINED
+	}
+	if fb.depth == nil && fb.drawSurface() == nil {
+		return FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT
+	}
+	return FRAMEBUFFER_COMPLETE
+}
+
+// ReadBuffer sets the buffer for reading using ReadPixels.
+// Attachment is one of COLOR_ATTACHMENT0, DEPTH_ATTACHMENT, or STENCIL_ATTACHMENT.
+func (gs *GLS) ReadBuffer(attachment uint) {
+
+	if fb := gs.framebuffers[gs.readFb]; fb != nil {
+		fb.readBuffer = uint32(attachment)
+	}
+}
+
+// GenerateMipmap does nothing in the software context.
+func (gs *GLS) GenerateMipmap(target uint32) {
+}
+
+// GenTexture generates a texture object name.
+func (gs *GLS) GenTexture() uint32 {
+
+	tex := gs.genName()
+	gs.textures[tex] = new(softTexture)
+	gs.stats.Textures++
+	return tex
+}
+
+// GenVertexArray generates a vertex array object name.
+func (gs *GLS) GenVertexArray() uint32 {
+
+	vao := gs.genName()
+	gs.vaos[vao] = &softVAO{attribs: make(map[uint32]*softAttrib)}
+	gs.stats.Vaos++
+	return vao
+}
+
+// GetAttribLocation returns the location of the specified attribute variable.
+func (gs *GLS) GetAttribLocation(program uint32, name string) int32 {
+
+	p := gs.sprograms[program]
+	if p == nil {
+		return -1
+	}
+	loc, ok := p.attribs[name]
+	if !ok {
+		return -1
+	}
+	return loc
+}
+
+// GetProgramiv returns the specified parameter from the specified program object.
+// The programs of the software context are always linked.
+func (gs *GLS) GetProgramiv(program, pname uint32, params *int32) {
+
+	switch pname {
+	case LINK_STATUS:
+		*params = TRUE
+	default:
+		*params = 0
+	}
+}
+
+// GetProgramInfoLog returns the information log for the specified program object.
+func (gs *GLS) GetProgramInfoLog(program uint32) string {
+
+	return ""
+}
+
+// GetShaderInfoLog returns the information log for the specified shader object.
+func (gs *GLS) GetShaderInfoLog(shader uint32) string {
+
+	return ""
+}
+
+// CompressedTextureFormats returns the compressed internal texture formats supported.
+// The software context does not decode compressed textures and returns none.
+func (gs *GLS) CompressedTextureFormats() []uint32 {
+
+	return nil
+}
+
+// GetString returns a string describing the specified aspect of the current GL connection.
+func (gs *GLS) GetString(name uint32) string {
+
+	switch name {
+	case VENDOR:
+		return "G3N"
+	case RENDERER:
+		return "G3N software renderer"
+	case VERSION:
+		return "3.3 software"
+	case SHADING_LANGUAGE_VERSION:
+		return "3.30"
+	}
+	return ""
+}
+
+// GetUniformLocation returns the location of a uniform variable for the specified program.
+// The software context assigns a location to any name.
+func (gs *GLS) GetUniformLocation(program uint32, name string) int32 {
+
+	p := gs.sprograms[program]
+	if p == nil {
+		return -1
+	}
+	loc, ok := p.names[name]
+	if !ok {
+		loc = int32(len(p.names))
+		p.names[name] = loc
+	}
+	return loc
+}
+
+// GetUniformBlockIndex returns the index of the specified uniform block
+// in the specified program or INVALID_INDEX if not found.
+func (gs *GLS) GetUniformBlockIndex(program uint32, name string) uint32 {
+
+	p := gs.sprograms[program]
+	if p == nil {
+		return INVALID_INDEX
+	}
+	idx, ok := p.blocks[name]
+	if !ok {
+		idx = uint32(len(p.blocks))
+		p.blocks[name] = idx
+	}
+	return idx
+}
+
+// UniformBlockBinding assigns the specified binding point to
+// the uniform block with the specified index in the specified program.
+func (gs *GLS) UniformBlockBinding(program uint32, blockIndex uint32, binding uint32) {
+
+}
+
+// GetViewport returns the current viewport information.
+func (gs *GLS) GetViewport() (x, y, width, height int32) {
+
+	return gs.viewportX, gs.viewportY, gs.viewportWidth, gs.viewportHeight
+}
+
+// LineWidth specifies the rasterized width of both aliased and antialiased lines.
+func (gs *GLS) LineWidth(width float32) {
+
+	gs.lineWidth = width
+}
+
+// LinkProgram links the specified program object, finding the locations
+// of the inputs declared in the source of its vertex shader.
+func (gs *GLS) LinkProgram(program uint32) {
+
+	p := gs.sprograms[program]
+	if p == nil {
+		return
+	}
+	p.attribs = make(map[string]int32)
+	var unassigned []string
+	next := int32(0)
+	for _, handle := range p.shaders {
+		shader := gs.shaders[handle]
+		if shader == nil || shader.stype != VERTEX_SHADER {
+			continue
+		}
+		for _, m := range softAttribRegexp.FindAllStringSubmatch(shader.source, -1) {
+			if m[1] == "" {
+				unassigned = append(unassigned, m[2])
+				continue
+			}
+			loc, _ := strconv.Atoi(m[1])
+			p.attribs[m[2]] = int32(loc)
+			if int32(loc) >= next {
+				next = int32(loc) + 1
+			}
+		}
+	}
+	for _, name := range unassigned {
+		if _, ok := p.attribs[name]; !ok {
+			p.attribs[name] = next
+			next++
+		}
+	}
+}
+
+// GetShaderiv returns the specified parameter from the specified shader object.
+// The shaders of the software context are always compiled.
+func (gs *GLS) GetShaderiv(shader, pname uint32, params *int32) {
+
+	switch pname {
+	case COMPILE_STATUS:
+		*params = TRUE
+	default:
+		*params = 0
+	}
+}
+
+// Scissor defines the scissor box rectangle in window coordinates.
+func (gs *GLS) Scissor(x, y int32, width, height uint32) {
+
+	gs.scissor = [4]int32{x, y, int32(width), int32(height)}
+}
+
+// ShaderSource sets the source code for the specified shader object.
+func (gs *GLS) ShaderSource(shader uint32, src string) {
+
+	if s := gs.shaders[shader]; s != nil {
+		s.source = src
+	}
+}
+
+// TexImage2D specifies a two-dimensional texture image.
+// The software context only keeps the size of the image.
+func (gs *GLS) TexImage2D(target uint32, level int32, iformat int32, width int32, height int32, format uint32, itype uint32, data interface{}) {
+
+	if tex := gs.textures[gs.texUnits[gs.activeTexture]]; tex != nil && level == 0 {
+		*tex = softTexture{width: int(width), height: int(height), depth: format == DEPTH_COMPONENT || format == DEPTH_STENCIL}
+	}
+}
+
+// TexSubImage2D replaces a rectangular region of the current two-dimensional texture
+// image without reallocating its storage. It does nothing in the software context.
+func (gs *GLS) TexSubImage2D(target uint32, level int32, xoffset int32, yoffset int32, width int32, height int32, format uint32, itype uint32, data interface{}) {
+}
+
+// CompressedTexImage2D specifies a two-dimensional compressed texture image.
+// The software context only keeps the size of the image.
+func (gs *GLS) CompressedTexImage2D(target uint32, level uint32, iformat uint32, width int32, height int32, size int32, data interface{}) {
+
+	if tex := gs.textures[gs.texUnits[gs.activeTexture]]; tex != nil && level == 0 {
+		*tex = softTexture{width: int(width), height: int(height)}
+	}
+}
+
+// TexParameteri does nothing in the software context.
+func (gs *GLS) TexParameteri(target uint32, pname uint32, param int32) {
+}
+
+// PolygonMode controls the interpretation of polygons for rasterization.
+// The software context always fills the polygons.
+func (gs *GLS) PolygonMode(face, mode uint32) {
+
+	gs.polygonModeFace = face
+	gs.polygonModeMode = mode
+}
+
+// PolygonOffset sets the scale and units used to calculate depth values.
+// It is ignored by the software context.
+func (gs *GLS) PolygonOffset(factor float32, units float32) {
+
+	gs.polygonOffsetFactor = factor
+	gs.polygonOffsetUnits = units
+}
+
+// Uniform1i sets the value of an int uniform variable for the current program object.
+func (gs *GLS) Uniform1i(location int32, v0 int32) {
+
+	gs.setUniform(location, 1, []float32{float32(v0)})
+}
+
+// Uniform1f sets the value of a float uniform variable for the current program object.
+func (gs *GLS) Uniform1f(location int32, v0 float32) {
+
+	gs.setUniform(location, 1, []float32{v0})
+}
+
+// Uniform2f sets the value of a vec2 uniform variable for the current program object.
+func (gs *GLS) Uniform2f(location int32, v0, v1 float32) {
+
+	gs.setUniform(location, 2, []float32{v0, v1})
+}
+
+// Uniform3f sets the value of a vec3 uniform variable for the current program object.
+func (gs *GLS) Uniform3f(location int32, v0, v1, v2 float32) {
+
+	gs.setUniform(location, 3, []float32{v0, v1, v2})
+}
+
+// Uniform4f sets the value of a vec4 uniform variable for the current program object.
+func (gs *GLS) Uniform4f(location int32, v0, v1, v2, v3 float32) {
+
+	gs.setUniform(location, 4, []float32{v0, v1, v2, v3})
+}
+
+// UniformMatrix3fv sets the value of one or many 3x3 float matrices for the current program object.
+func (gs *GLS) UniformMatrix3fv(location int32, count int32, transpose bool, pm *float32) {
+
+	gs.setUniform(location, 9, softMatrices(pm, 3, int(count), transpose))
+}
+
+// UniformMatrix4fv sets the value of one or many 4x4 float matrices for the current program object.
+func (gs *GLS) UniformMatrix4fv(location int32, count int32, transpose bool, pm *float32) {
+
+	gs.setUniform(location, 16, softMatrices(pm, 4, int(count), transpose))
+}
+
+// Uniform1fv sets the value of one or many float uniform variables for the current program object.
+func (gs *GLS) Uniform1fv(location int32, count int32, v *float32) {
+
+	gs.setUniform(location, 1, softFloats(v, int(count)))
+}
+
+// Uniform2fv sets the value of one or many vec2 uniform variables for the current program object.
+func (gs *GLS) Uniform2fv(location int32, count int32, v *float32) {
+
+	gs.setUniform(location, 2, softFloats(v, 2*int(count)))
+}
+
+// Uniform3fv sets the value of one or many vec3 uniform variables for the current program object.
+func (gs *GLS) Uniform3fv(location int32, count int32, v *float32) {
+
+	gs.setUniform(location, 3, softFloats(v, 3*int(count)))
+}
+
+// Uniform4fv sets the value of one or many vec4 uniform variables for the current program object.
+func (gs *GLS) Uniform4fv(location int32, count int32, v *float32) {
+
+	gs.setUniform(location, 4, softFloats(v, 4*int(count)))
+}
+
+// VertexAttribPointer defines an array of generic vertex attribute data.
+func (gs *GLS) VertexAttribPointer(index uint32, size int32, xtype uint32, normalized bool, stride int32, offset uint32) {
+
+	if attrib := gs.vertexAttrib(index); attrib != nil {
+		attrib.buffer = gs.arrayBuffer
+		attrib.size = size
+		attrib.xtype = xtype
+		attrib.stride = stride
+		attrib.offset = offset
+	}
+}
+
+// VertexAttribDivisor sets the rate at which the specified generic vertex attribute advances
+// during instanced rendering. A divisor of zero advances it once per vertex.
+func (gs *GLS) VertexAttribDivisor(index uint32, divisor uint32) {
+
+	if attrib := gs.vertexAttrib(index); attrib != nil {
+		attrib.divisor = divisor
+	}
+}
+
+// Viewport sets the viewport.
+func (gs *GLS) Viewport(x, y, width, height int32) {
+
+	gs.viewportX = x
+	gs.viewportY = y
+	gs.viewportWidth = width
+	gs.viewportHeight = height
+}
+
+// UseProgram sets the specified program as the current program.
+func (gs *GLS) UseProgram(prog *Program) {
+
+	if prog.handle == 0 {
+		panic("Invalid program")
+	}
+	gs.prog = prog
+
+	// Inserts program in cache if not already there.
+	if !gs.programs[prog] {
+		gs.programs[prog] = true
+		log.Debug("New Program activated. Total: %d", len(gs.programs))
+	}
+}
+
+// genName returns a new object name.
+func (gs *GLS) genName() uint32 {
+
+	gs.lastName++
+	return gs.lastName
+}
+
+// boundBuffer returns the buffer object bound to the specified target or nil.
+func (gs *GLS) boundBuffer(target uint32) *softBuffer {
+
+	switch target {
+	case ARRAY_BUFFER:
+		return gs.buffers[gs.arrayBuffer]
+	case ELEMENT_ARRAY_BUFFER:
+		if vao := gs.vaos[gs.vao]; vao != nil {
+			return gs.buffers[vao.elements]
+		}
+	}
+	return nil
+}
+
+// vertexAttrib returns the specified vertex attribute array of the bound vertex array object.
+func (gs *GLS) vertexAttrib(index uint32) *softAttrib {
+
+	vao := gs.vaos[gs.vao]
+	if vao == nil {
+		return nil
+	}
+	attrib := vao.attribs[index]
+	if attrib == nil {
+		attrib = new(softAttrib)
+		vao.attribs[index] = attrib
+	}
+	return attrib
+}
+
+// setUniform sets the value of the uniform at the specified location of the current program.
+func (gs *GLS) setUniform(location int32, comps int, v []float32) {
+
+	gs.stats.Unisets++
+	if location < 0 || gs.prog == nil {
+		return
+	}
+	p := gs.sprograms[gs.prog.handle]
+	if p == nil {
+		return
+	}
+	p.uniforms[location] = &softUniform{comps: comps, v: v}
+}
+
+// uniform returns the value of the uniform with the specified name of the specified program or nil.
+func (p *softProgram) uniform(name string) *softUniform {
+
+	loc, ok := p.names[name]
+	if !ok {
+		return nil
+	}
+	return p.uniforms[loc]
+}
+
+// attach attaches the specified texture or renderbuffer to the bound framebuffer,
+// allocating its pixels if necessary.
+func (gs *GLS) attach(attachment uint32, tex *softTexture) {
+
+	fb := gs.framebuffers[gs.framebuffer]
+	if fb == nil || gs.framebuffer == 0 {
+		return
+	}
+	var surf *softSurface
+	if tex != nil {
+		comps := 4
+		if tex.depth {
+			comps = 1
+		}
+		if tex.surf == nil || tex.surf.width != tex.width || tex.surf.height != tex.height || tex.surf.comps != comps {
+			tex.surf = newSoftSurface(tex.width, tex.height, comps)
+		}
+		surf = tex.surf
+	}
+	switch {
+	case attachment == DEPTH_ATTACHMENT || attachment == DEPTH_STENCIL_ATTACHMENT:
+		fb.depth = surf
+	case attachment >= COLOR_ATTACHMENT0 && attachment < COLOR_ATTACHMENT0+uint32(len(fb.colors)):
+		fb.colors[attachment-COLOR_ATTACHMENT0] = surf
+	}
+}
+
+// attachment returns the color surface of the specified buffer of the framebuffer or nil.
+func (fb *softFramebuffer) attachment(buf uint32) *softSurface {
+
+	switch {
+	case buf == BACK || buf == FRONT || buf == BACK_LEFT || buf == FRONT_LEFT:
+		return fb.colors[0]
+	case buf >= COLOR_ATTACHMENT0 && buf < COLOR_ATTACHMENT0+uint32(len(fb.colors)):
+		return fb.colors[buf-COLOR_ATTACHMENT0]
+	}
+	return nil
+}
+
+// drawSurface returns the color surface of the first draw buffer of the framebuffer or nil.
+func (fb *softFramebuffer) drawSurface() *softSurface {
+
+	if len(fb.drawBuffers) == 0 {
+		return nil
+	}
+	return fb.attachment(fb.drawBuffers[0])
+}
+
+// readSurface returns the color surface of the read buffer of the read framebuffer or nil.
+func (gs *GLS) readSurface() *softSurface {
+
+	fb := gs.framebuffers[gs.readFb]
+	if fb == nil {
+		return nil
+	}
+	return fb.attachment(fb.readBuffer)
+}
+
+// elementIndex returns a function which returns the vertex index of the specified
+// element of the element array buffer of the bound vertex array object.
+func (gs *GLS) elementIndex(itype uint32, start uint32) func(i int) int {
+
+	var data []byte
+	if vao := gs.vaos[gs.vao]; vao != nil {
+		if buf := gs.buffers[vao.elements]; buf != nil {
+			data = buf.data
+		}
+	}
+	size := 4
+	switch itype {
+	case UNSIGNED_BYTE:
+		size = 1
+	case UNSIGNED_SHORT:
+		size = 2
+	}
+	return func(i int) int {
+		pos := int(start) + i*size
+		if pos+size > len(data) {
+			return -1
+		}
+		switch size {
+		case 1:
+			return int(data[pos])
+		case 2:
+			return int(*(*uint16)(unsafe.Pointer(&data[pos])))
+		}
+		return int(*(*uint32)(unsafe.Pointer(&data[pos])))
+	}
+}
+
+// draw rasterizes the triangles of the specified primitives with the
+// current program, vertex array object and framebuffer.
+// Lines and points are ignored.
+func (gs *GLS) draw(mode uint32, count int32, instances int, index func(i int) int) {
+
+	if gs.prog == nil || count < 3 {
+		return
+	}
+	p := gs.sprograms[gs.prog.handle]
+	vao := gs.vaos[gs.vao]
+	fb := gs.framebuffers[gs.framebuffer]
+	if p == nil || vao == nil || fb == nil {
+		return
+	}
+	target := fb.drawSurface()
+	if target == nil {
+		return
+	}
+
+	// Vertex transform
+	transform := softIdentity()
+	if u := p.uniform("MVP"); u != nil && len(u.v) >= 16 {
+		copy(transform[:], u.v)
+	} else if u := p.uniform("ModelMatrix"); u != nil && len(u.v) >= 16 {
+		copy(transform[:], u.v)
+	}
+
+	// Attributes
+	attrib := func(name string) *softAttrib {
+		loc, ok := p.attribs[name]
+		if !ok {
+			return nil
+		}
+		a := vao.attribs[uint32(loc)]
+		if a == nil || !a.enabled {
+			return nil
+		}
+		return a
+	}
+	position := attrib("VertexPosition")
+	if position == nil {
+		return
+	}
+	color := attrib("VertexColor")
+	texcoord := attrib("VertexTexcoord")
+	var instanceMatrix [4]*softAttrib
+	for i := range instanceMatrix {
+		instanceMatrix[i] = attrib("InstanceMatrix" + strconv.Itoa(i))
+	}
+
+	shade := gs.shader(p)
+	for inst := 0; inst < instances; inst++ {
+		matrix := transform
+		if instanceMatrix[0] != nil {
+			var im [16]float32
+			for i, a := range instanceMatrix {
+				v := gs.fetch(a, 0, inst)
+				copy(im[4*i:], v[:])
+			}
+			matrix = softMultiply(&transform, &im)
+		}
+		vertex := func(i int) (softVertex, bool) {
+			vi := index(i)
+			if vi < 0 {
+				return softVertex{}, false
+			}
+			pos := gs.fetch(position, vi, inst)
+			pos[3] = 1
+			clip := softTransform(&matrix, pos)
+			if clip[3] <= 1e-6 {
+				return softVertex{}, false
+			}
+			var v softVertex
+			v.invW = 1 / clip[3]
+			v.x = float32(gs.viewportX) + (clip[0]*v.invW+1)*0.5*float32(gs.viewportWidth)
+			v.y = float32(gs.viewportY) + (clip[1]*v.invW+1)*0.5*float32(gs.viewportHeight)
+			v.z = (clip[2]*v.invW + 1) * 0.5
+			v.color = [4]float32{v.invW, v.invW, v.invW, v.invW}
+			if color != nil {
+				c := gs.fetch(color, vi, inst)
+				if color.size < 4 {
+					c[3] = 1
+				}
+				for k := range v.color {
+					v.color[k] = c[k] * v.invW
+				}
+			}
+			if texcoord != nil {
+				t := gs.fetch(texcoord, vi, inst)
+				v.uv = [2]float32{t[0] * v.invW, t[1] * v.invW}
+			}
+			return v, true
+		}
+		triangle := func(i0, i1, i2 int) {
+			v0, ok0 := vertex(i0)
+			v1, ok1 := vertex(i1)
+			v2, ok2 := vertex(i2)
+			if ok0 && ok1 && ok2 {
+				gs.rasterize(fb, target, &v0, &v1, &v2, shade)
+			}
+		}
+		switch mode {
+		case TRIANGLES:
+			for i := 0; i+2 < int(count); i += 3 {
+				triangle(i, i+1, i+2)
+			}
+		case TRIANGLE_STRIP:
+			for i := 0; i+2 < int(count); i++ {
+				if i%2 == 0 {
+					triangle(i, i+1, i+2)
+				} else {
+					triangle(i+1, i, i+2)
+				}
+			}
+		case TRIANGLE_FAN:
+			for i := 1; i+1 < int(count); i++ {
+				triangle(0, i, i+1)
+			}
+		}
+	}
+}
+
+// fetch returns the value of the specified vertex attribute for the specified vertex and instance.
+// Missing components are zero.
+func (gs *GLS) fetch(a *softAttrib, vertex, instance int) [4]float32 {
+
+	var v [4]float32
+	buf := gs.buffers[a.buffer]
+	if buf == nil {
+		return v
+	}
+	if a.divisor > 0 {
+		vertex = instance / int(a.divisor)
+	}
+	esize := 4
+	switch a.xtype {
+	case BYTE, UNSIGNED_BYTE:
+		esize = 1
+	case SHORT, UNSIGNED_SHORT:
+		esize = 2
+	}
+	stride := int(a.stride)
+	if stride == 0 {
+		stride = int(a.size) * esize
+	}
+	pos := int(a.offset) + vertex*stride
+	for i := 0; i < int(a.size) && i < 4; i++ {
+		p := pos + i*esize
+		if p < 0 || p+esize > len(buf.data) {
+			break
+		}
+		ptr := unsafe.Pointer(&buf.data[p])
+		switch a.xtype {
+		case FLOAT:
+			v[i] = *(*float32)(ptr)
+		case BYTE:
+			v[i] = float32(*(*int8)(ptr))
+		case UNSIGNED_BYTE:
+			v[i] = float32(*(*uint8)(ptr))
+		case SHORT:
+			v[i] = float32(*(*int16)(ptr))
+		case UNSIGNED_SHORT:
+			v[i] = float32(*(*uint16)(ptr))
+		case INT:
+			v[i] = float32(*(*int32)(ptr))
+		case UNSIGNED_INT:
+			v[i] = float32(*(*uint32)(ptr))
+		}
+	}
+	return v
+}
+
+// shader returns the function which computes the color of the fragments with the specified
+// interpolated vertex color and texture coordinates for the uniforms of the specified program.
+// It approximates the built-in panel, standard and physical shaders without textures or lighting
+// and writes the id of the pick shader.
+func (gs *GLS) shader(p *softProgram) func(color [4]float32, uv [2]float32) ([4]float32, bool) {
+
+	// Pick ids
+	if u := p.uniform("PickID"); u != nil && len(u.v) >= 4 {
+		id := [4]float32{u.v[0], u.v[1], u.v[2], u.v[3]}
+		return func(color [4]float32, uv [2]float32) ([4]float32, bool) {
+			return id, true
+		}
+	}
+
+	// GUI panels
+	if u := p.uniform("Panel"); u != nil && len(u.v) >= 28 {
+		panel := u.v
+		rect := func(i int, uv [2]float32) bool {
+			r := panel[4*i : 4*i+4]
+			return uv[0] >= r[0] && uv[0] <= r[0]+r[2] && uv[1] >= r[1] && uv[1] <= r[1]+r[3]
+		}
+		return func(color [4]float32, uv [2]float32) ([4]float32, bool) {
+			uv[1] = 1 - uv[1]
+			b := panel[0:4]
+			if uv[0] <= b[0] || uv[0] >= b[2] || uv[1] <= b[1] || uv[1] >= b[3] {
+				return color, false
+			}
+			var c []float32
+			switch {
+			case rect(3, uv):
+				c = panel[24:28]
+			case rect(2, uv):
+				c = panel[20:24]
+			case rect(1, uv):
+				c = panel[16:20]
+			default:
+				return [4]float32{1, 1, 1, 0}, true
+			}
+			return [4]float32{c[0], c[1], c[2], c[3]}, true
+		}
+	}
+
+	// Standard (vec3 array) or physical (vec4 array) materials
+	base := [4]float32{1, 1, 1, 1}
+	if u := p.uniform("Material"); u != nil {
+		switch {
+		case u.comps == 3 && len(u.v) >= 14:
+			for i := 0; i < 3; i++ {
+				base[i] = clampf(u.v[3+i]+u.v[9+i], 0, 1)
+			}
+			base[3] = u.v[13]
+		case u.comps == 4 && len(u.v) >= 8:
+			for i := 0; i < 3; i++ {
+				base[i] = clampf(u.v[i]+u.v[4+i], 0, 1)
+			}
+			base[3] = u.v[3]
+		}
+	}
+	return func(color [4]float32, uv [2]float32) ([4]float32, bool) {
+		for i := range color {
+			color[i] *= base[i]
+		}
+		return color, true
+	}
+}
+
+// rasterize fills the specified triangle in the specified target color surface,
+// applying the face culling, scissor and depth tests and the blending.
+func (gs *GLS) rasterize(fb *softFramebuffer, target *softSurface, v0, v1, v2 *softVertex,
+	shade func(color [4]float32, uv [2]float32) ([4]float32, bool)) {
+
+	area := (v1.x-v0.x)*(v2.y-v0.y) - (v2.x-v0.x)*(v1.y-v0.y)
+	if area == 0 {
+		return
+	}
+
+	// Face culling
+	front := area > 0
+	if gs.frontFace == CW {
+		front = !front
+	}
+	if gs.capabilities[CULL_FACE] == capEnabled {
+		switch gs.cullFace {
+		case FRONT_AND_BACK:
+			return
+		case FRONT:
+			if front {
+				return
+			}
+		default:
+			if !front {
+				return
+			}
+		}
+	}
+
+	// Orients the triangle counter clockwise
+	if area < 0 {
+		v1, v2 = v2, v1
+		area = -area
+	}
+
+	// Bounding box clipped to the target, viewport and scissor box
+	minX := int(math.Floor(float64(minf(v0.x, minf(v1.x, v2.x)))))
+	maxX := int(math.Ceil(float64(maxf(v0.x, maxf(v1.x, v2.x)))))
+	minY := int(math.Floor(float64(minf(v0.y, minf(v1.y, v2.y)))))
+	maxY := int(math.Ceil(float64(maxf(v0.y, maxf(v1.y, v2.y)))))
+	clip := func(x0, y0, x1, y1 int) {
+		minX, minY = maxi(minX, x0), maxi(minY, y0)
+		maxX, maxY = mini(maxX, x1), mini(maxY, y1)
+	}
+	clip(0, 0, target.width-1, target.height-1)
+	clip(int(gs.viewportX), int(gs.viewportY), int(gs.viewportX+gs.viewportWidth)-1, int(gs.viewportY+gs.viewportHeight)-1)
+	if gs.capabilities[SCISSOR_TEST] == capEnabled {
+		clip(int(gs.scissor[0]), int(gs.scissor[1]), int(gs.scissor[0]+gs.scissor[2])-1, int(gs.scissor[1]+gs.scissor[3])-1)
+	}
+
+	depth := fb.depth
+	if gs.capabilities[DEPTH_TEST] != capEnabled || depth != nil && (depth.width != target.width || depth.height != target.height) {
+		depth = nil
+	}
+	blend := gs.capabilities[BLEND] == capEnabled
+	edge := func(a, b *softVertex, x, y float32) (float32, bool) {
+		e := (b.x-a.x)*(y-a.y) - (b.y-a.y)*(x-a.x)
+		topLeft := (b.y == a.y && b.x < a.x) || b.y < a.y
+		return e, e > 0 || e == 0 && topLeft
+	}
+
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			px, py := float32(x)+0.5, float32(y)+0.5
+			w0, in0 := edge(v1, v2, px, py)
+			w1, in1 := edge(v2, v0, px, py)
+			w2, in2 := edge(v0, v1, px, py)
+			if !in0 || !in1 || !in2 {
+				continue
+			}
+			w0, w1, w2 = w0/area, w1/area, w2/area
+
+			// Depth test
+			z := w0*v0.z + w1*v1.z + w2*v2.z
+			idx := y*target.width + x
+			if depth != nil {
+				if !softDepthTest(gs.depthFunc, z, depth.pix[idx]) {
+					continue
+				}
+			}
+
+			// Perspective correct interpolation
+			invW := w0*v0.invW + w1*v1.invW + w2*v2.invW
+			var color [4]float32
+			for i := range color {
+				color[i] = (w0*v0.color[i] + w1*v1.color[i] + w2*v2.color[i]) / invW
+			}
+			var uv [2]float32
+			for i := range uv {
+				uv[i] = (w0*v0.uv[i] + w1*v1.uv[i] + w2*v2.uv[i]) / invW
+			}
+			color, ok := shade(color, uv)
+			if !ok {
+				continue
+			}
+			if depth != nil && gs.depthMask != intFalse {
+				depth.pix[idx] = z
+			}
+			dst := target.pix[4*idx : 4*idx+4]
+			if blend {
+				color = gs.blend(color, dst)
+			}
+			copy(dst, color[:])
+		}
+	}
+}
+
+// blend returns the result of blending the specified source and destination colors.
+func (gs *GLS) blend(src [4]float32, dst []float32) [4]float32 {
+
+	factor := func(f uint32, i int) float32 {
+		switch f {
+		case ZERO:
+			return 0
+		case SRC_COLOR:
+			return src[i]
+		case ONE_MINUS_SRC_COLOR:
+			return 1 - src[i]
+		case SRC_ALPHA:
+			return src[3]
+		case ONE_MINUS_SRC_ALPHA:
+			return 1 - src[3]
+		case DST_COLOR:
+			return dst[i]
+		case ONE_MINUS_DST_COLOR:
+			return 1 - dst[i]
+		case DST_ALPHA:
+			return dst[3]
+		case ONE_MINUS_DST_ALPHA:
+			return 1 - dst[3]
+		}
+		return 1
+	}
+	var res [4]float32
+	for i := range res {
+		sf, df := gs.blendSrcRGB, gs.blendDstRGB
+		if i == 3 {
+			sf, df = gs.blendSrcAlpha, gs.blendDstAlpha
+		}
+		res[i] = src[i]*factor(sf, i) + dst[i]*factor(df, i)
+	}
+	return res
+}
+
+// softDepthTest returns whether the specified fragment depth passes the depth test
+// with the specified function against the specified stored depth.
+func softDepthTest(fn uint32, z, stored float32) bool {
+
+	switch fn {
+	case NEVER:
+		return false
+	case LESS:
+		return z < stored
+	case EQUAL:
+		return z == stored
+	case LEQUAL:
+		return z <= stored
+	case GREATER:
+		return z > stored
+	case NOTEQUAL:
+		return z != stored
+	case GEQUAL:
+		return z >= stored
+	}
+	return true
+}
+
+// isSoftDepthFormat returns whether the specified internal format stores depth.
+func isSoftDepthFormat(format uint32) bool {
+
+	switch format {
+	case DEPTH_COMPONENT, DEPTH_COMPONENT16, DEPTH_COMPONENT24, DEPTH_COMPONENT32,
+		DEPTH_COMPONENT32F, DEPTH24_STENCIL8, DEPTH32F_STENCIL8, DEPTH_STENCIL:
+		return true
+	}
+	return false
+}
+
+// newSoftSurface creates and returns a pointer to a new surface with the specified size and components.
+func newSoftSurface(width, height, comps int) *softSurface {
+
+	return &softSurface{width: width, height: height, comps: comps, pix: make([]float32, width*height*comps)}
+}
+
+// fill sets all the pixels of the surface to the specified value.
+func (s *softSurface) fill(value []float32) {
+
+	n := mini(s.comps, len(value))
+	for i := 0; i < len(s.pix); i += s.comps {
+		copy(s.pix[i:i+n], value[:n])
+	}
+}
+
+// blitSoftSurface copies a block of pixels between surfaces with the same number of components.
+func blitSoftSurface(src, dst *softSurface, srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32) {
+
+	if src == nil || dst == nil || src.comps != dst.comps || dstX1 == dstX0 || dstY1 == dstY0 {
+		return
+	}
+	sx := float32(srcX1-srcX0) / float32(dstX1-dstX0)
+	sy := float32(srcY1-srcY0) / float32(dstY1-dstY0)
+	for y := mini(int(dstY0), int(dstY1)); y < maxi(int(dstY0), int(dstY1)); y++ {
+		for x := mini(int(dstX0), int(dstX1)); x < maxi(int(dstX0), int(dstX1)); x++ {
+			if x < 0 || y < 0 || x >= dst.width || y >= dst.height {
+				continue
+			}
+			fx := int(float32(srcX0) + (float32(x-int(dstX0))+0.5)*sx)
+			fy := int(float32(srcY0) + (float32(y-int(dstY0))+0.5)*sy)
+			if fx < 0 || fy < 0 || fx >= src.width || fy >= src.height {
+				continue
+			}
+			copy(dst.pix[(y*dst.width+x)*dst.comps:(y*dst.width+x+1)*dst.comps],
+				src.pix[(fy*src.width+fx)*src.comps:(fy*src.width+fx+1)*src.comps])
+		}
+	}
+}
+
+// softBytes returns a slice of the first size bytes of the specified slice or pointer.
+func softBytes(data interface{}, size int) []byte {
+
+	return (*[1 << 30]byte)(ptr(data))[:size:size]
+}
+
+// softFloats returns a copy of the specified number of floats starting at the specified pointer.
+func softFloats(v *float32, n int) []float32 {
+
+	if v == nil || n <= 0 {
+		return nil
+	}
+	return append([]float32(nil), (*[1 << 28]float32)(unsafe.Pointer(v))[:n:n]...)
+}
+
+// softMatrices returns a copy of the specified number of column major
+// square matrices with the specified size, optionally transposed.
+func softMatrices(pm *float32, size, count int, transpose bool) []float32 {
+
+	m := softFloats(pm, size*size*count)
+	if !transpose {
+		return m
+	}
+	res := make([]float32, len(m))
+	for k := 0; k < count; k++ {
+		for i := 0; i < size; i++ {
+			for j := 0; j < size; j++ {
+				res[k*size*size+i*size+j] = m[k*size*size+j*size+i]
+			}
+		}
+	}
+	return res
+}
+
+// softIdentity returns a column major 4x4 identity matrix.
+func softIdentity() [16]float32 {
+
+	return [16]float32{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1}
+}
+
+// softMultiply returns the product of the specified column major 4x4 matrices.
+func softMultiply(a, b *[16]float32) [16]float32 {
+
+	var m [16]float32
+	for col := 0; col < 4; col++ {
+		for row := 0; row < 4; row++ {
+			var s float32
+			for k := 0; k < 4; k++ {
+				s += a[k*4+row] * b[col*4+k]
+			}
+			m[col*4+row] = s
+		}
+	}
+	return m
+}
+
+// softTransform returns the product of the specified column major 4x4 matrix and vector.
+func softTransform(m *[16]float32, v [4]float32) [4]float32 {
+
+	var r [4]float32
+	for row := 0; row < 4; row++ {
+		r[row] = m[row]*v[0] + m[4+row]*v[1] + m[8+row]*v[2] + m[12+row]*v[3]
+	}
+	return r
+}
+
+// Ptr takes a slice or pointer (to a singular scalar value or the first
+// element of an array or slice) and returns its address.
+func ptr(data interface{}) unsafe.Pointer {
+	if data == nil {
+		return unsafe.Pointer(nil)
+	}
+	var addr unsafe.Pointer
+	v := reflect.ValueOf(data)
+	switch v.Type().Kind() {
+	case reflect.Ptr:
+		e := v.Elem()
+		switch e.Kind() {
+		case
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			addr = unsafe.Pointer(e.UnsafeAddr())
+		default:
+			panic(fmt.Errorf("unsupported pointer to type %s; must be a slice or pointer to a singular scalar value or the first element of an array or slice", e.Kind()))
+		}
+	case reflect.Uintptr:
+		addr = unsafe.Pointer(v.Pointer())
+	case reflect.Slice:
+		addr = unsafe.Pointer(v.Index(0).UnsafeAddr())
+	default:
+		panic(fmt.Errorf("unsupported type %s; must be a slice or pointer to a singular scalar value or the first element of an array or slice", v.Type()))
+	}
+	return addr
+}
+
+func clampf(v, min, max float32) float32 {
+
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+func minf(a, b float32) float32 {
+
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxf(a, b float32) float32 {
+
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func mini(a, b int) int {
+
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxi(a, b int) int {
+
+	if a > b {
+		return a
+	}
+	return b
+}
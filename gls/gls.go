@@ -30,10 +30,15 @@ type Stats struct {
 	Buffers    int    // Number of Buffer Objects
 	Textures   int    // Number of Textures
 	Caphits    uint64 // Cumulative number of hits for Enable/Disable
+	Texhits    uint64 // Cumulative number of hits for BindTexture
+	Vaohits    uint64 // Cumulative number of hits for BindVertexArray
+	Proghits   uint64 // Cumulative number of hits for UseProgram
+	Statehits  uint64 // Cumulative number of hits for other cached states (blend, depth, faces, etc.)
 	UnilocHits uint64 // Cumulative number of uniform location cache hits
 	UnilocMiss uint64 // Cumulative number of uniform location cache misses
 	Unisets    uint64 // Cumulative number of uniform sets
 	Drawcalls  uint64 // Cumulative number of draw calls
+	Triangles  uint64 // Cumulative number of triangles drawn
 	Fbos       uint64 // Number of frame buffer objects
 	Rbos       uint64 // Number of render buffer objects
 }
@@ -47,6 +52,69 @@ const (
 	intTrue     = 1
 )
 
+// texBinding is a texture target of a texture unit.
+type texBinding struct {
+	unit   uint32 // Texture unit (TEXTURE0 + n)
+	target int    // Texture target (TEXTURE_2D, TEXTURE_CUBE_MAP, etc.)
+}
+
+// texBindings caches the textures bound to the targets of the texture units.
+type texBindings map[texBinding]uint32
+
+// unbind removes the specified deleted textures from the cache,
+// as OpenGL unbinds the textures when they are deleted.
+func (tb texBindings) unbind(tex []uint32) {
+
+	for key, bound := range tb {
+		for _, t := range tex {
+			if bound == t {
+				delete(tb, key)
+			}
+		}
+	}
+}
+
 const (
 	FloatSize = int32(unsafe.Sizeof(float32(0)))
 )
+
+// Reset must be called after the OpenGL context of this state was lost and restored or was
+// recreated, as the OpenGL objects created before are invalid. It starts a new generation of
+// the context, discards the cached state and the shader programs and sets the default state.
+// The textures, geometries and VBOs recreate their OpenGL objects and transfer their data
+// again when they are next rendered, as the renderer does with its programs and frame buffers.
+func (gs *GLS) Reset() {
+
+	gs.generation++
+	gs.clearObjects()
+	gs.reset()
+	gs.stats.Vaos = 0
+	gs.stats.Buffers = 0
+	gs.stats.Textures = 0
+	gs.stats.Fbos = 0
+	gs.stats.Rbos = 0
+	gs.setDefaultState()
+	gs.Viewport(gs.viewportX, gs.viewportY, gs.viewportWidth, gs.viewportHeight)
+}
+
+// Generation returns the generation of the OpenGL context, which is incremented by Reset.
+// The OpenGL objects created in a previous generation are invalid and must not be deleted.
+func (gs *GLS) Generation() uint32 {
+
+	return gs.generation
+}
+
+// triangleCount returns the number of triangles drawn by a draw call
+// with the specified primitive mode, vertex count and number of instances.
+func triangleCount(mode uint32, count, instances int32) uint64 {
+
+	switch mode {
+	case TRIANGLES:
+		return uint64(count/3) * uint64(instances)
+	case TRIANGLE_STRIP, TRIANGLE_FAN:
+		if count > 2 {
+			return uint64(count-2) * uint64(instances)
+		}
+	}
+	return 0
+}
@@ -161,6 +161,18 @@ func (prog *Program) GetUniformLocation(name string) int32 {
 	return loc
 }
 
+// BindUniformBlock binds the uniform block with the specified name in this program, if the program
+// uses it, to the specified binding point and returns whether the program uses the block.
+func (prog *Program) BindUniformBlock(name string, binding uint32) bool {
+
+	idx := prog.gs.GetUniformBlockIndex(prog.handle, name)
+	if idx == INVALID_INDEX {
+		return false
+	}
+	prog.gs.UniformBlockBinding(prog.handle, idx, binding)
+	return true
+}
+
 // CompileShader creates and compiles an OpenGL shader of the specified type, with
 // the specified source code, and returns a non-zero value by which it can be referenced.
 func (prog *Program) CompileShader(stype uint32, source string) (uint32, error) {
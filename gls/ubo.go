@@ -0,0 +1,103 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gls
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// UBO abstracts an OpenGL Uniform Buffer Object, which stores the data of a uniform block
+// shared by all the shader programs which bind the block to the binding point of the UBO.
+// The data must follow the std140 layout of the block, where the scalars and vectors of
+// arrays and the columns of matrices are aligned to 4 floats.
+type UBO struct {
+	gs      *GLS            // Reference to OpenGL state
+	handle  uint32          // OpenGL handle for this UBO
+	name    string          // Name of the uniform block
+	binding uint32          // Binding point of the uniform block
+	buffer  math32.ArrayF32 // Data buffer
+	update  bool            // Update flag
+	size    int             // Size in bytes of the OpenGL data store
+	gen     uint32          // Generation of the OpenGL context of the buffer
+}
+
+// NewUBO creates and returns a pointer to a new UBO for the uniform block
+// with the specified name bound to the specified binding point.
+func NewUBO(name string, binding uint32) *UBO {
+
+	ubo := new(UBO)
+	ubo.name = name
+	ubo.binding = binding
+	ubo.buffer = math32.NewArrayF32(0, 0)
+	ubo.update = true
+	return ubo
+}
+
+// Name returns the name of the uniform block of this UBO.
+func (ubo *UBO) Name() string {
+
+	return ubo.name
+}
+
+// Binding returns the binding point of the uniform block of this UBO.
+func (ubo *UBO) Binding() uint32 {
+
+	return ubo.binding
+}
+
+// Buffer returns a pointer to the data buffer of this UBO, which may be modified
+// in place or reset and appended to, followed by a call to Update.
+func (ubo *UBO) Buffer() *math32.ArrayF32 {
+
+	return &ubo.buffer
+}
+
+// SetBuffer sets the data buffer of this UBO.
+func (ubo *UBO) SetBuffer(buffer math32.ArrayF32) {
+
+	ubo.buffer = buffer
+	ubo.update = true
+}
+
+// Update sets the update flag, so the data buffer is transferred to OpenGL the next time the UBO is bound.
+func (ubo *UBO) Update() {
+
+	ubo.update = true
+}
+
+// Dispose disposes of the OpenGL resources used by the UBO.
+func (ubo *UBO) Dispose() {
+
+	if ubo.gs != nil && ubo.gen == ubo.gs.generation {
+		ubo.gs.DeleteBuffers(ubo.handle)
+	}
+	ubo.gs = nil
+}
+
+// Bind transfers the data buffer of this UBO to OpenGL if it changed
+// and binds the UBO to the binding point of its uniform block.
+func (ubo *UBO) Bind(gs *GLS) {
+
+	// First time initialization or recreation after the OpenGL context was reset
+	if ubo.gs == nil || ubo.gen != gs.generation {
+		ubo.handle = gs.GenBuffer()
+		ubo.gs = gs
+		ubo.gen = gs.generation
+		ubo.size = 0
+		ubo.update = true
+	}
+
+	if ubo.update && ubo.buffer.Bytes() > 0 {
+		gs.BindBuffer(UNIFORM_BUFFER, ubo.handle)
+		if ubo.size != ubo.buffer.Bytes() {
+			gs.BufferData(UNIFORM_BUFFER, ubo.buffer.Bytes(), ubo.buffer.ToFloat32(), DYNAMIC_DRAW)
+			ubo.size = ubo.buffer.Bytes()
+		} else {
+			gs.BufferSubData(UNIFORM_BUFFER, 0, ubo.size, ubo.buffer.ToFloat32())
+		}
+		ubo.update = false
+	}
+	gs.BindBufferBase(UNIFORM_BUFFER, ubo.binding, ubo.handle)
+}
@@ -15,6 +15,7 @@ type Uniform struct {
 	handle    uint32 // program handle
 	location  int32  // last cached location
 	lastIndex int32  // last index
+	gen       uint32 // generation of the OpenGL context of the program handle
 }
 
 // Init initializes this uniform location cache and sets its name.
@@ -37,9 +38,10 @@ func (u *Uniform) Name() string {
 func (u *Uniform) Location(gs *GLS) int32 {
 
 	handle := gs.prog.Handle()
-	if handle != u.handle {
+	if handle != u.handle || u.gen != gs.generation {
 		u.location = gs.prog.GetUniformLocation(u.name)
 		u.handle = handle
+		u.gen = gs.generation
 	}
 	return u.location
 }
@@ -54,9 +56,10 @@ func (u *Uniform) LocationIdx(gs *GLS, idx int32) int32 {
 		u.handle = 0
 	}
 	handle := gs.prog.Handle()
-	if handle != u.handle {
+	if handle != u.handle || u.gen != gs.generation {
 		u.location = gs.prog.GetUniformLocation(u.nameIdx)
 		u.handle = handle
+		u.gen = gs.generation
 	}
 	return u.location
 }
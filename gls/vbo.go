@@ -16,6 +16,14 @@ type VBO struct {
 	update  bool            // Update flag
 	buffer  math32.ArrayF32 // Data buffer
 	attribs []VBOattrib     // List of attributes
+	first   int             // Index of the first buffer element of the range to update
+	last    int             // Index after the last buffer element of the range to update
+	size    int             // Size in bytes of the OpenGL data store
+	orphan  bool            // Orphans the data store before range updates
+	divisor uint32          // Instanced rendering divisor of the attributes
+	located []bool          // Attributes located and enabled in the vertex array
+	missing *Program        // Last program where some attributes were not found
+	gen     uint32          // Generation of the OpenGL context of the buffer
 }
 
 // VBOattrib describes one attribute of an OpenGL Vertex Buffer Object.
@@ -196,12 +204,24 @@ func (vbo *VBO) Attributes() []VBOattrib {
 // it is not referenced counted.
 func (vbo *VBO) Dispose() {
 
-	if vbo.gs != nil {
+	if vbo.gs != nil && vbo.gen == vbo.gs.generation {
 		vbo.gs.DeleteBuffers(vbo.handle)
 	}
 	vbo.gs = nil
 }
 
+// Clone returns a new VBO with a copy of the buffer and the attributes of this VBO.
+// The copy is transferred to its own OpenGL buffer when the VBO is first rendered.
+func (vbo *VBO) Clone() *VBO {
+
+	clone := NewVBO(append(math32.ArrayF32(nil), vbo.buffer...))
+	clone.attribs = append(clone.attribs, vbo.attribs...)
+	clone.usage = vbo.usage
+	clone.orphan = vbo.orphan
+	clone.divisor = vbo.divisor
+	return clone
+}
+
 // SetBuffer sets the VBO buffer.
 func (vbo *VBO) SetBuffer(buffer math32.ArrayF32) *VBO {
 
@@ -217,6 +237,21 @@ func (vbo *VBO) SetUsage(usage uint32) {
 	vbo.usage = usage
 }
 
+// SetDivisor sets the number of instances drawn before the attributes of this VBO advance
+// one item in instanced rendering. The default value of zero advances them once per vertex.
+// Must be set before the VBO is first transferred.
+func (vbo *VBO) SetDivisor(divisor uint32) *VBO {
+
+	vbo.divisor = divisor
+	return vbo
+}
+
+// Divisor returns the instanced rendering divisor of the attributes of this VBO.
+func (vbo *VBO) Divisor() uint32 {
+
+	return vbo.divisor
+}
+
 // Buffer returns a pointer to the VBO buffer.
 func (vbo *VBO) Buffer() *math32.ArrayF32 {
 
@@ -229,6 +264,41 @@ func (vbo *VBO) Update() {
 	vbo.update = true
 }
 
+// UpdateRange marks the specified range of elements of the buffer to be transferred
+// to OpenGL, without transferring the whole buffer. Successive ranges marked before
+// the transfer are merged in one range which includes all of them.
+func (vbo *VBO) UpdateRange(start, count int) {
+
+	if count <= 0 {
+		return
+	}
+	if vbo.last == vbo.first {
+		vbo.first = start
+		vbo.last = start + count
+		return
+	}
+	if start < vbo.first {
+		vbo.first = start
+	}
+	if start+count > vbo.last {
+		vbo.last = start + count
+	}
+}
+
+// SetOrphan sets if the OpenGL data store should be orphaned (reallocated) before
+// transferring a range update. Orphaning avoids stalling the pipeline when the buffer
+// is still being used by previous draw calls, at the cost of transferring the whole buffer.
+func (vbo *VBO) SetOrphan(orphan bool) {
+
+	vbo.orphan = orphan
+}
+
+// Orphan returns if the OpenGL data store is orphaned before transferring a range update.
+func (vbo *VBO) Orphan() bool {
+
+	return vbo.orphan
+}
+
 // AttribOffset returns the total number of elements from
 // all attributes preceding the attribute specified by type.
 func (vbo *VBO) AttribOffset(attribType AttribType) int {
@@ -292,36 +362,85 @@ func (vbo *VBO) Transfer(gs *GLS) {
 		return
 	}
 
-	// First time initialization
-	if vbo.gs == nil {
+	// First time initialization or recreation after the OpenGL context was reset
+	if vbo.gs == nil || vbo.gen != gs.generation {
 		vbo.handle = gs.GenBuffer()
-		gs.BindBuffer(ARRAY_BUFFER, vbo.handle)
-		// Calculates stride size
-		strideSize := vbo.StrideSize()
-		// For each attribute
-		for _, attrib := range vbo.attribs {
-			// Get attribute location in the current program
-			loc := gs.prog.GetAttribLocation(attrib.Name)
-			if loc < 0 {
-				log.Warn("Attribute not found: %v", attrib.Name)
-				continue
-			}
-			// Enables attribute and sets its stride and offset in the buffer
-			gs.EnableVertexAttribArray(uint32(loc))
-			gs.VertexAttribPointer(uint32(loc), attrib.NumElements, attrib.ElementType, false, int32(strideSize), attrib.ByteOffset)
-		}
+		vbo.located = make([]bool, len(vbo.attribs))
+		vbo.setupAttribs(gs, true)
 		vbo.gs = gs // this indicates that the vbo was initialized
+		vbo.gen = gs.generation
+		vbo.update = true
+	} else if vbo.missing != nil && vbo.missing != gs.prog {
+		// The attributes not found may be used by the current program
+		vbo.setupAttribs(gs, false)
 	}
 
 	// If nothing has changed, no need to transfer data to OpenGL
-	if !vbo.update {
+	if !vbo.update && vbo.last == vbo.first {
+		return
+	}
+	gs.BindBuffer(ARRAY_BUFFER, vbo.handle)
+
+	// Transfer all the VBO data to OpenGL if requested or if the buffer size changed
+	if vbo.update || vbo.size != vbo.buffer.Bytes() {
+		gs.BufferData(ARRAY_BUFFER, vbo.buffer.Bytes(), vbo.buffer.ToFloat32(), vbo.usage)
+		vbo.size = vbo.buffer.Bytes()
+		vbo.update = false
+		vbo.first, vbo.last = 0, 0
+		return
+	}
+
+	// Orphans the data store and transfers the whole buffer to the new store
+	if vbo.orphan {
+		gs.BufferData(ARRAY_BUFFER, vbo.size, nil, vbo.usage)
+		gs.BufferSubData(ARRAY_BUFFER, 0, vbo.size, vbo.buffer.ToFloat32())
+		vbo.first, vbo.last = 0, 0
 		return
 	}
 
-	// Transfer the VBO data to OpenGL
+	// Transfer only the updated range of the buffer
+	first := vbo.first
+	last := vbo.last
+	if first < 0 {
+		first = 0
+	}
+	if last > vbo.buffer.Size() {
+		last = vbo.buffer.Size()
+	}
+	if first < last {
+		gs.BufferSubData(ARRAY_BUFFER, first*4, (last-first)*4, vbo.buffer.ToFloat32()[first:last])
+	}
+	vbo.first, vbo.last = 0, 0
+}
+
+// setupAttribs enables the attributes not yet located which are found in the
+// current program and sets their stride, offset and divisor in the buffer.
+func (vbo *VBO) setupAttribs(gs *GLS, warn bool) {
+
 	gs.BindBuffer(ARRAY_BUFFER, vbo.handle)
-	gs.BufferData(ARRAY_BUFFER, vbo.buffer.Bytes(), vbo.buffer.ToFloat32(), vbo.usage)
-	vbo.update = false
+	strideSize := vbo.StrideSize()
+	vbo.missing = nil
+	for i, attrib := range vbo.attribs {
+		if vbo.located[i] {
+			continue
+		}
+		// Get attribute location in the current program
+		loc := gs.prog.GetAttribLocation(attrib.Name)
+		if loc < 0 {
+			if warn {
+				log.Warn("Attribute not found: %v", attrib.Name)
+			}
+			vbo.missing = gs.prog
+			continue
+		}
+		// Enables attribute and sets its stride and offset in the buffer
+		gs.EnableVertexAttribArray(uint32(loc))
+		gs.VertexAttribPointer(uint32(loc), attrib.NumElements, attrib.ElementType, false, int32(strideSize), attrib.ByteOffset)
+		if vbo.divisor != 0 {
+			gs.VertexAttribDivisor(uint32(loc), vbo.divisor)
+		}
+		vbo.located[i] = true
+	}
 }
 
 // OperateOnVectors3 iterates over all 3-float32 items for the specified attribute
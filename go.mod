@@ -3,8 +3,10 @@ module github.com/g3n/engine
 go 1.13
 
 require (
+	github.com/fsnotify/fsnotify v1.4.9
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20210410170116-ea3d685f79fb
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0
+	github.com/hajimehoshi/oto v0.7.1
 	golang.org/x/image v0.0.0-20210607152325-775e3b0c77b9
 	gopkg.in/yaml.v2 v2.4.0
 )
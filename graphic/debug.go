@@ -0,0 +1,217 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// DebugFlags is a bit mask of the debug rendering options of a graphic.
+type DebugFlags int
+
+// The debug rendering options.
+const (
+	DebugWireframe DebugFlags = 1 << iota // Black wireframe drawn over the shaded graphic
+	DebugNormals                          // Vertex normals drawn as blue lines
+	DebugTangents                         // Vertex tangents drawn as red lines
+)
+
+// debugGraphics contains the debug rendering options of a graphic and the graphics which draw them.
+type debugGraphics struct {
+	flags    DebugFlags // Debug rendering options
+	length   float32    // Length of the normal and tangent lines (0 for automatic)
+	triangle int        // Index of the highlighted triangle (-1 for none)
+	graphics []IGraphic // Wireframe, normal and tangent lines and highlighted triangle graphics
+}
+
+// SetDebug sets the debug rendering options of the graphic, which draw its wireframe over
+// the shaded graphic and its vertex normals and tangents as lines. The debug graphics are
+// created from the geometry when the options are set, so SetDebug must be called again
+// if the geometry changes. They are not deformed by skinning or morph targets.
+func (gr *Graphic) SetDebug(flags DebugFlags) {
+
+	d := gr.debugState()
+	d.flags = flags
+	gr.updateDebug()
+}
+
+// Debug returns the debug rendering options of the graphic.
+func (gr *Graphic) Debug() DebugFlags {
+
+	if gr.debug == nil {
+		return 0
+	}
+	return gr.debug.flags
+}
+
+// SetDebugLength sets the length, in the graphic local coordinates, of the normal and
+// tangent debug lines. The default (0) is 5% of the diagonal of the geometry bounding box.
+func (gr *Graphic) SetDebugLength(length float32) {
+
+	d := gr.debugState()
+	d.length = length
+	gr.updateDebug()
+}
+
+// DebugLength returns the length of the normal and tangent debug lines (0 for automatic).
+func (gr *Graphic) DebugLength() float32 {
+
+	if gr.debug == nil {
+		return 0
+	}
+	return gr.debug.length
+}
+
+// SetDebugTriangle highlights the triangle of the geometry with the specified index
+// in magenta. A negative index (the default) removes the highlight.
+func (gr *Graphic) SetDebugTriangle(index int) {
+
+	d := gr.debugState()
+	d.triangle = index
+	gr.updateDebug()
+}
+
+// DebugTriangle returns the index of the highlighted triangle or -1 if none.
+func (gr *Graphic) DebugTriangle() int {
+
+	if gr.debug == nil {
+		return -1
+	}
+	return gr.debug.triangle
+}
+
+// DebugGraphics returns the graphics which draw the debug rendering options, with
+// the world transform of the graphic. It is called by the renderer for each frame.
+func (gr *Graphic) DebugGraphics() []IGraphic {
+
+	if gr.debug == nil || len(gr.debug.graphics) == 0 {
+		return nil
+	}
+	mw := gr.MatrixWorld()
+	for _, igr := range gr.debug.graphics {
+		n := igr.GetNode()
+		n.SetMatrix(&mw)
+		n.UpdateMatrixWorld()
+	}
+	return gr.debug.graphics
+}
+
+// debugState returns the debug state of the graphic, creating it if necessary.
+func (gr *Graphic) debugState() *debugGraphics {
+
+	if gr.debug == nil {
+		gr.debug = &debugGraphics{triangle: -1}
+	}
+	return gr.debug
+}
+
+// updateDebug recreates the debug graphics from the debug options.
+func (gr *Graphic) updateDebug() {
+
+	d := gr.debug
+	d.dispose()
+	geom := gr.GetGeometry()
+
+	// Wireframe overlay drawn in front of the graphic triangles
+	if d.flags&DebugWireframe != 0 && gr.mode == gls.TRIANGLES {
+		mat := material.NewBasic()
+		mat.SetWireframe(true)
+		mat.SetSide(material.SideDouble)
+		mat.SetPolygonOffset(-1, -1)
+		d.graphics = append(d.graphics, NewMesh(geom.Incref(), mat))
+	}
+
+	// Normal and tangent lines
+	if d.flags&(DebugNormals|DebugTangents) != 0 {
+		length := d.length
+		if length <= 0 {
+			bb := geom.BoundingBox()
+			length = 0.05 * bb.Max.DistanceTo(&bb.Min)
+		}
+		positions := math32.NewArrayF32(0, 0)
+		colors := math32.NewArrayF32(0, 0)
+		var vertices []math32.Vector3
+		geom.ReadVertices(func(v math32.Vector3) bool {
+			vertices = append(vertices, v)
+			return false
+		})
+		addLines := func(atype gls.AttribType, color *math32.Color) {
+			vbo := geom.VBO(atype)
+			if vbo == nil {
+				return
+			}
+			i := 0
+			vbo.ReadVectors3(atype, func(v math32.Vector3) bool {
+				if i >= len(vertices) {
+					return true
+				}
+				p := vertices[i]
+				v.Normalize().MultiplyScalar(length).Add(&p)
+				positions.Append(p.X, p.Y, p.Z, v.X, v.Y, v.Z)
+				colors.Append(color.R, color.G, color.B, color.R, color.G, color.B)
+				i++
+				return false
+			})
+		}
+		if d.flags&DebugNormals != 0 {
+			addLines(gls.VertexNormal, &math32.Color{R: 0, G: 0, B: 1})
+		}
+		if d.flags&DebugTangents != 0 {
+			addLines(gls.VertexTangent, &math32.Color{R: 1, G: 0, B: 0})
+		}
+		if positions.Size() > 0 {
+			lgeom := geometry.NewGeometry()
+			lgeom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+			lgeom.AddVBO(gls.NewVBO(colors).AddAttrib(gls.VertexColor))
+			d.graphics = append(d.graphics, NewLines(lgeom, material.NewBasic()))
+		}
+	}
+
+	// Highlighted triangle
+	if d.triangle >= 0 && gr.mode == gls.TRIANGLES {
+		var a, b, c math32.Vector3
+		found := false
+		i := 0
+		geom.ReadFaces(func(vA, vB, vC math32.Vector3) bool {
+			if i == d.triangle {
+				a, b, c = vA, vB, vC
+				found = true
+				return true
+			}
+			i++
+			return false
+		})
+		if found {
+			positions := math32.NewArrayF32(0, 9)
+			positions.Append(a.X, a.Y, a.Z, b.X, b.Y, b.Z, c.X, c.Y, c.Z)
+			colors := math32.NewArrayF32(0, 9)
+			colors.Append(1, 0, 1, 1, 0, 1, 1, 0, 1)
+			tgeom := geometry.NewGeometry()
+			tgeom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+			tgeom.AddVBO(gls.NewVBO(colors).AddAttrib(gls.VertexColor))
+			mat := material.NewBasic()
+			mat.SetSide(material.SideDouble)
+			mat.SetPolygonOffset(-2, -2)
+			d.graphics = append(d.graphics, NewMesh(tgeom, mat))
+		}
+	}
+
+	// The debug graphics are only rendered with the graphic
+	for _, igr := range d.graphics {
+		igr.SetCullable(false)
+	}
+}
+
+// dispose disposes the debug graphics.
+func (d *debugGraphics) dispose() {
+
+	for _, igr := range d.graphics {
+		igr.Dispose()
+	}
+	d.graphics = d.graphics[:0]
+}
@@ -30,6 +30,10 @@ type IGraphic interface {
 	RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo)
 }
 
+// PreRenderFunc is the type of the callbacks called by the renderer for each frame before
+// rendering a graphic which passed frustum culling. Returning false skips the graphic in the frame.
+type PreRenderFunc func(igr IGraphic, rinfo *core.RenderInfo) bool
+
 // Graphic is a Node which has a visible representation in the scene.
 // It has an associated geometry and one or more materials.
 // It is the base type used by other graphics such as lines, line_strip,
@@ -41,7 +45,12 @@ type Graphic struct {
 	mode        uint32             // OpenGL primitive
 	renderable  bool               // Renderable flag
 	cullable    bool               // Cullable flag
+	castShadow  bool               // Casts shadows flag
 	renderOrder int                // Render order
+	instanced   bool               // Instanced rendering flag
+	instances   int                // Number of instances drawn if instanced
+	debug       *debugGraphics     // Debug rendering options and graphics (nil if never set)
+	preRender   PreRenderFunc      // Callback which decides whether the graphic is rendered in a frame
 
 	ShaderDefines gls.ShaderDefines // Graphic-specific shader defines
 
@@ -101,31 +110,98 @@ func (gr *Graphic) Dispose() {
 	for i := 0; i < len(gr.materials); i++ {
 		gr.materials[i].imat.Dispose()
 	}
+	if gr.debug != nil {
+		gr.debug.dispose()
+	}
 }
 
-// Clone clones the graphic and satisfies the INode interface.
-// It should be called by Clone() implementations of IGraphic.
-// Note that the topmost implementation calling this method needs
-// to call clone.SetIGraphic(igraphic) after calling this method.
+// Clone clones the graphic and its descendants and satisfies the INode interface.
+// The types which embed a Graphic must implement their own Clone methods using InitClone.
 func (gr *Graphic) Clone() core.INode {
 
 	clone := new(Graphic)
-	clone.Node = *gr.Node.Clone().(*core.Node)
-	clone.igeom = gr.igeom
-	clone.mode = gr.mode
-	clone.renderable = gr.renderable
-	clone.cullable = gr.cullable
-	clone.renderOrder = gr.renderOrder
-	clone.ShaderDefines = gr.ShaderDefines
-	clone.materials = make([]GraphicMaterial, len(gr.materials))
-
-	for i, grmat := range gr.materials {
-		clone.materials[i] = grmat
+	clone.Node.InitClone(clone, &gr.Node)
+	clone.cloneFrom(gr, nil)
+	return clone
+}
+
+// InitClone initializes this graphic, embedded in the specified IGraphic, as a clone of
+// the specified graphic and its descendants. The clone shares the geometry and the materials
+// of the graphic, whose reference counts are incremented, so the same loaded asset can be
+// instantiated many times. Use DeepClone to also copy them. It should be called by the
+// Clone methods of the types which embed a Graphic.
+func (gr *Graphic) InitClone(igr IGraphic, src *Graphic) {
+
+	gr.Node.InitClone(igr, &src.Node)
+	gr.cloneFrom(src, igr)
+}
+
+// cloneFrom copies the graphic properties of the specified graphic to this graphic
+// setting the specified IGraphic in the copied graphic materials.
+func (gr *Graphic) cloneFrom(src *Graphic, igr IGraphic) {
+
+	gr.igeom = src.igeom
+	gr.igeom.GetGeometry().Incref()
+	gr.mode = src.mode
+	gr.renderable = src.renderable
+	gr.cullable = src.cullable
+	gr.castShadow = src.castShadow
+	gr.renderOrder = src.renderOrder
+	gr.preRender = src.preRender
+	gr.instanced = src.instanced
+	gr.instances = src.instances
+	gr.ShaderDefines = *gls.NewShaderDefines()
+	gr.ShaderDefines.Add(&src.ShaderDefines)
+	gr.materials = make([]GraphicMaterial, len(src.materials))
+	for i, grmat := range src.materials {
+		grmat.imat.GetMaterial().Incref()
+		grmat.igraphic = igr
+		gr.materials[i] = grmat
 	}
+}
+
+// DeepClone clones the specified node and its descendants like their Clone methods but
+// the cloned graphics get copies of the materials which implement material.ICloneable and,
+// unless shareGeometries is true, copies of their geometries, so they can be changed
+// without affecting the originals. Geometries of types embedding a Geometry are always shared.
+func DeepClone(inode core.INode, shareGeometries bool) core.INode {
 
+	clone := inode.Clone()
+	deepCloneGraphics(clone, shareGeometries)
 	return clone
 }
 
+// deepCloneGraphics replaces the shared geometries and materials of the
+// graphics of the specified node and its descendants by copies.
+func deepCloneGraphics(inode core.INode, shareGeometries bool) {
+
+	if igr, ok := inode.(IGraphic); ok {
+		gr := igr.GetGraphic()
+		if geom, ok := gr.igeom.(*geometry.Geometry); ok && !shareGeometries {
+			gr.igeom = geom.Clone()
+			geom.Dispose()
+			if im, ok := inode.(*InstancedMesh); ok {
+				im.linkVBOs()
+			}
+		}
+		for i := range gr.materials {
+			if cmat, ok := gr.materials[i].imat.(material.ICloneable); ok {
+				gr.materials[i].imat = cmat.Clone()
+				cmat.Dispose()
+			}
+		}
+	}
+	for _, child := range inode.Children() {
+		deepCloneGraphics(child, shareGeometries)
+	}
+}
+
+// Mode returns the OpenGL primitive used to draw this graphic.
+func (gr *Graphic) Mode() uint32 {
+
+	return gr.mode
+}
+
 // SetRenderable satisfies the IGraphic interface and
 // sets the renderable state of this Graphic (default = true).
 func (gr *Graphic) SetRenderable(state bool) {
@@ -154,6 +230,19 @@ func (gr *Graphic) Cullable() bool {
 	return gr.cullable
 }
 
+// SetCastShadow sets whether this graphic casts shadows
+// from the lights which cast shadows (default = false, true for meshes).
+func (gr *Graphic) SetCastShadow(state bool) {
+
+	gr.castShadow = state
+}
+
+// CastShadow returns whether this graphic casts shadows.
+func (gr *Graphic) CastShadow() bool {
+
+	return gr.castShadow
+}
+
 // SetRenderOrder sets the render order of the object.
 // All objects have renderOrder of 0 by default.
 // To render before renderOrder 0 set a lower renderOrder e.g. -1.
@@ -169,6 +258,21 @@ func (gr *Graphic) RenderOrder() int {
 	return gr.renderOrder
 }
 
+// SetPreRender sets the callback called by the renderer for each frame, after culling and
+// before drawing, which can skip rendering the graphic in the frame by returning false,
+// to implement custom visibility rules. The graphic still casts shadows.
+// The callback is copied by Clone. Nil (the default) removes the callback.
+func (gr *Graphic) SetPreRender(cb PreRenderFunc) {
+
+	gr.preRender = cb
+}
+
+// PreRender returns the callback called by the renderer before rendering the graphic or nil if none.
+func (gr *Graphic) PreRender() PreRenderFunc {
+
+	return gr.preRender
+}
+
 // AddMaterial adds a material for the specified subset of vertices.
 // If the material applies to all vertices, start and count must be 0.
 func (gr *Graphic) AddMaterial(igr IGraphic, imat material.IMaterial, start, count int) {
@@ -177,6 +281,7 @@ func (gr *Graphic) AddMaterial(igr IGraphic, imat material.IMaterial, start, cou
 		imat:     imat,
 		start:    start,
 		count:    count,
+		gindex:   -1,
 		igraphic: igr,
 	}
 	gr.materials = append(gr.materials, gmat)
@@ -191,6 +296,83 @@ func (gr *Graphic) AddGroupMaterial(igr IGraphic, imat material.IMaterial, ginde
 	}
 	group := geom.GroupAt(gindex)
 	gr.AddMaterial(igr, imat, group.Start, group.Count)
+	gr.materials[len(gr.materials)-1].gindex = gindex
+}
+
+// SetGroupMaterial replaces the material of the specified geometry group
+// or adds it if the group has no material yet. The replaced material is disposed.
+func (gr *Graphic) SetGroupMaterial(igr IGraphic, imat material.IMaterial, gindex int) {
+
+	for i := range gr.materials {
+		if gr.materials[i].gindex == gindex {
+			if old := gr.materials[i].imat; old != imat {
+				gr.materials[i].imat = imat
+				old.Dispose()
+			}
+			return
+		}
+	}
+	gr.AddGroupMaterial(igr, imat, gindex)
+}
+
+// GroupMaterial returns the material of the specified geometry group
+// or nil if the group has no material.
+func (gr *Graphic) GroupMaterial(gindex int) material.IMaterial {
+
+	for i := range gr.materials {
+		if gr.materials[i].gindex == gindex {
+			return gr.materials[i].imat
+		}
+	}
+	return nil
+}
+
+// RemoveGroupMaterial removes the material of the specified geometry group.
+// Returns the removed material or nil if the group had no material.
+// The removed material is not disposed.
+func (gr *Graphic) RemoveGroupMaterial(gindex int) material.IMaterial {
+
+	for i := range gr.materials {
+		if gr.materials[i].gindex == gindex {
+			imat := gr.materials[i].imat
+			copy(gr.materials[i:], gr.materials[i+1:])
+			gr.materials = gr.materials[:len(gr.materials)-1]
+			return imat
+		}
+	}
+	return nil
+}
+
+// RemoveMaterial removes all the occurrences of the specified material from this graphic.
+// Returns true if the material was found.
+// The removed material is not disposed.
+func (gr *Graphic) RemoveMaterial(imat material.IMaterial) bool {
+
+	found := false
+	materials := gr.materials[:0]
+	for _, gmat := range gr.materials {
+		if gmat.imat == imat {
+			found = true
+			continue
+		}
+		materials = append(materials, gmat)
+	}
+	gr.materials = materials
+	return found
+}
+
+// GroupBoundingBox returns the bounding box of the specified
+// geometry group in world coordinates.
+func (gr *Graphic) GroupBoundingBox(gindex int) math32.Box3 {
+
+	geom := gr.igeom.GetGeometry()
+	if gindex < 0 || gindex >= geom.GroupCount() {
+		panic("Invalid group index")
+	}
+	bbox := geom.GroupBoundingBox(gindex)
+	m := gr.MatrixWorld()
+	bbox.ApplyMatrix4(&m)
+	return bbox
 }
 
 // Materials returns slice with this graphic materials.
@@ -279,6 +461,7 @@ type GraphicMaterial struct {
 	imat     material.IMaterial // Associated material
 	start    int                // Index of first element in the geometry
 	count    int                // Number of elements
+	gindex   int                // Index of the geometry group or -1 if not associated with a group
 	igraphic IGraphic           // Graphic which contains this GraphicMaterial
 }
 
@@ -288,6 +471,21 @@ func (grmat *GraphicMaterial) IMaterial() material.IMaterial {
 	return grmat.imat
 }
 
+// Range returns the index of the first element and the number of elements
+// of the geometry drawn with this GraphicMaterial.
+// A count of 0 indicates that all the elements are drawn.
+func (grmat *GraphicMaterial) Range() (start, count int) {
+
+	return grmat.start, grmat.count
+}
+
+// GroupIndex returns the index of the geometry group associated with
+// the GraphicMaterial or -1 if it is not associated with a group.
+func (grmat *GraphicMaterial) GroupIndex() int {
+
+	return grmat.gindex
+}
+
 // IGraphic returns the graphic associated with the GraphicMaterial.
 func (grmat *GraphicMaterial) IGraphic() IGraphic {
 
@@ -297,11 +495,27 @@ func (grmat *GraphicMaterial) IGraphic() IGraphic {
 // Render is called by the renderer to render this graphic material.
 func (grmat *GraphicMaterial) Render(gs *gls.GLS, rinfo *core.RenderInfo) {
 
+	grmat.RenderOverride(gs, rinfo, nil)
+}
+
+// RenderOverride is called by the renderer to render this graphic material calling the
+// specified function, if not nil, after the material setup so the renderer can override
+// the states set by the material (e.g. blending for order independent transparency).
+func (grmat *GraphicMaterial) RenderOverride(gs *gls.GLS, rinfo *core.RenderInfo, override func(gs *gls.GLS)) {
+
+	// Instanced graphics without instances are not drawn
+	gr := grmat.igraphic.GetGraphic()
+	if gr.instanced && gr.instances == 0 {
+		return
+	}
+
 	// Setup the associated material (set states and transfer material uniforms and textures)
 	grmat.imat.RenderSetup(gs)
+	if override != nil {
+		override(gs)
+	}
 
 	// Setup the associated geometry (set VAO and transfer VBOS)
-	gr := grmat.igraphic.GetGraphic()
 	gr.igeom.RenderSetup(gs)
 
 	// Setup current graphic (transfer matrices)
@@ -317,12 +531,20 @@ func (grmat *GraphicMaterial) Render(gs *gls.GLS, rinfo *core.RenderInfo) {
 		if count == 0 {
 			count = indices.Size()
 		}
-		gs.DrawElements(gr.mode, int32(count), gls.UNSIGNED_INT, 4*uint32(grmat.start))
+		if gr.instanced {
+			gs.DrawElementsInstanced(gr.mode, int32(count), gls.UNSIGNED_INT, 4*uint32(grmat.start), int32(gr.instances))
+		} else {
+			gs.DrawElements(gr.mode, int32(count), gls.UNSIGNED_INT, 4*uint32(grmat.start))
+		}
 		// Non indexed geometry
 	} else {
 		if count == 0 {
 			count = geom.Items()
 		}
-		gs.DrawArrays(gr.mode, int32(grmat.start), int32(count))
+		if gr.instanced {
+			gs.DrawArraysInstanced(gr.mode, int32(grmat.start), int32(count), int32(gr.instances))
+		} else {
+			gs.DrawArrays(gr.mode, int32(grmat.start), int32(count))
+		}
 	}
 }
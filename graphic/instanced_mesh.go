@@ -0,0 +1,163 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"strconv"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// InstanceDataSlots is the maximum number of generic vec4 data slots per instance.
+const InstanceDataSlots = 4
+
+// InstancedMesh is a Mesh which draws many instances of its geometry in a single draw call.
+// Each instance has its own model matrix, relative to the mesh, and optional generic vec4
+// data slots, transferred to the shaders as the InstanceData0..InstanceData3 attributes,
+// which can be used by custom shaders for per instance values as animation time offsets,
+// sway phases or tints. The INSTANCE_DATA shader define has the number of data slots.
+// The built-in shaders apply the instance matrices.
+// The instance attributes are added to the geometry, so it must not be shared with other meshes.
+// As the instances are not contained in the bounding box of the geometry,
+// the mesh is created not cullable.
+type InstancedMesh struct {
+	*Mesh              // Embedded mesh
+	count     int      // Number of instances
+	slots     int      // Number of data slots per instance
+	matrixVBO *gls.VBO // Instance matrices VBO
+	dataVBO   *gls.VBO // Instance data VBO or nil if there are no data slots
+}
+
+// NewInstancedMesh creates and returns a pointer to a new instanced mesh from the
+// specified mesh with the specified number of instances and data slots per instance.
+// The instance matrices are initialized with the identity and the data with zeros.
+func NewInstancedMesh(mesh *Mesh, count, slots int) *InstancedMesh {
+
+	if slots < 0 {
+		slots = 0
+	} else if slots > InstanceDataSlots {
+		slots = InstanceDataSlots
+	}
+	im := new(InstancedMesh)
+	im.Mesh = mesh
+	im.SetIGraphic(im)
+	im.slots = slots
+	im.SetCullable(false)
+	im.instanced = true
+	im.ShaderDefines.Set("INSTANCED", "")
+	im.ShaderDefines.Set("INSTANCE_DATA", strconv.Itoa(slots))
+
+	geom := im.GetGeometry()
+	im.matrixVBO = gls.NewVBO(math32.NewArrayF32(0, 0)).SetDivisor(1)
+	for i := 0; i < 4; i++ {
+		im.matrixVBO.AddCustomAttrib("InstanceMatrix"+strconv.Itoa(i), 4)
+	}
+	geom.AddVBO(im.matrixVBO)
+	if slots > 0 {
+		im.dataVBO = gls.NewVBO(math32.NewArrayF32(0, 0)).SetDivisor(1)
+		for i := 0; i < slots; i++ {
+			im.dataVBO.AddCustomAttrib("InstanceData"+strconv.Itoa(i), 4)
+		}
+		geom.AddVBO(im.dataVBO)
+	}
+	im.SetInstanceCount(count)
+	return im
+}
+
+// Clone clones the instanced mesh and satisfies the INode interface.
+// The clone shares the geometry and so the instance matrices and data of the instanced mesh.
+// Use DeepClone without sharing the geometries to get a clone with its own instances.
+func (im *InstancedMesh) Clone() core.INode {
+
+	clone := new(InstancedMesh)
+	clone.Mesh = new(Mesh)
+	clone.Mesh.initClone(clone, im.Mesh)
+	clone.count = im.count
+	clone.slots = im.slots
+	clone.linkVBOs()
+	return clone
+}
+
+// linkVBOs sets the instance VBOs of the instanced mesh from its geometry.
+func (im *InstancedMesh) linkVBOs() {
+
+	geom := im.GetGeometry()
+	im.matrixVBO = geom.VBOName("InstanceMatrix0")
+	im.dataVBO = nil
+	if im.slots > 0 {
+		im.dataVBO = geom.VBOName("InstanceData0")
+	}
+}
+
+// SetInstanceCount sets the number of instances drawn. The existing instances
+// are kept and the new ones are initialized with the identity matrix and zero data.
+func (im *InstancedMesh) SetInstanceCount(count int) {
+
+	if count < 0 {
+		count = 0
+	}
+	var ident math32.Matrix4
+	ident.Identity()
+	matrices := math32.NewArrayF32(16*count, 16*count)
+	copy(matrices, *im.matrixVBO.Buffer())
+	for i := im.count; i < count; i++ {
+		copy(matrices[16*i:], ident[:])
+	}
+	im.matrixVBO.SetBuffer(matrices)
+	if im.dataVBO != nil {
+		data := math32.NewArrayF32(4*im.slots*count, 4*im.slots*count)
+		copy(data, *im.dataVBO.Buffer())
+		im.dataVBO.SetBuffer(data)
+	}
+	im.count = count
+	im.instances = count
+}
+
+// InstanceCount returns the number of instances drawn.
+func (im *InstancedMesh) InstanceCount() int {
+
+	return im.count
+}
+
+// DataSlots returns the number of generic vec4 data slots per instance.
+func (im *InstancedMesh) DataSlots() int {
+
+	return im.slots
+}
+
+// SetMatrixAt sets the model matrix, relative to the mesh, of the specified instance.
+func (im *InstancedMesh) SetMatrixAt(i int, m *math32.Matrix4) {
+
+	buf := *im.matrixVBO.Buffer()
+	copy(buf[16*i:16*i+16], m[:])
+	im.matrixVBO.UpdateRange(16*i, 16)
+}
+
+// MatrixAt returns the model matrix, relative to the mesh, of the specified instance.
+func (im *InstancedMesh) MatrixAt(i int) math32.Matrix4 {
+
+	var m math32.Matrix4
+	buf := *im.matrixVBO.Buffer()
+	copy(m[:], buf[16*i:16*i+16])
+	return m
+}
+
+// SetDataAt sets the value of the specified data slot of the specified instance.
+func (im *InstancedMesh) SetDataAt(i, slot int, v *math32.Vector4) {
+
+	pos := 4 * (i*im.slots + slot)
+	im.dataVBO.Buffer().SetVector4(pos, v)
+	im.dataVBO.UpdateRange(pos, 4)
+}
+
+// DataAt returns the value of the specified data slot of the specified instance.
+func (im *InstancedMesh) DataAt(i, slot int) math32.Vector4 {
+
+	var v math32.Vector4
+	im.dataVBO.Buffer().GetVector4(4*(i*im.slots+slot), &v)
+	return v
+}
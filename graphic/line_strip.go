@@ -28,6 +28,15 @@ func NewLineStrip(igeom geometry.IGeometry, imat material.IMaterial) *LineStrip
 	return l
 }
 
+// Clone clones the line strip and satisfies the INode interface.
+func (l *LineStrip) Clone() core.INode {
+
+	clone := new(LineStrip)
+	clone.Graphic.InitClone(clone, &l.Graphic)
+	clone.uniMVPm.Init("MVP")
+	return clone
+}
+
 // RenderSetup is called by the engine before drawing this geometry.
 func (l *LineStrip) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
 
@@ -33,6 +33,15 @@ func (l *Lines) Init(igeom geometry.IGeometry, imat material.IMaterial) {
 	l.uniMVPm.Init("MVP")
 }
 
+// Clone clones the lines and satisfies the INode interface.
+func (l *Lines) Clone() core.INode {
+
+	clone := new(Lines)
+	clone.Graphic.InitClone(clone, &l.Graphic)
+	clone.uniMVPm.Init("MVP")
+	return clone
+}
+
 // RenderSetup is called by the engine before drawing this geometry.
 func (l *Lines) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
 
@@ -35,6 +35,7 @@ func NewMesh(igeom geometry.IGeometry, imat material.IMaterial) *Mesh {
 func (m *Mesh) Init(igeom geometry.IGeometry, imat material.IMaterial) {
 
 	m.Graphic.Init(m, igeom, gls.TRIANGLES)
+	m.SetCastShadow(true)
 
 	// Initialize uniforms
 	m.uniMm.Init("ModelMatrix")
@@ -67,20 +68,31 @@ func (m *Mesh) AddGroupMaterial(imat material.IMaterial, gindex int) {
 	m.Graphic.AddGroupMaterial(m, imat, gindex)
 }
 
+// SetGroupMaterial replaces the material of the specified geometry group
+// or adds it if the group has no material yet.
+func (m *Mesh) SetGroupMaterial(imat material.IMaterial, gindex int) {
+
+	m.Graphic.SetGroupMaterial(m, imat, gindex)
+}
+
 // Clone clones the mesh and satisfies the INode interface.
 func (m *Mesh) Clone() core.INode {
 
 	clone := new(Mesh)
-	clone.Graphic = *m.Graphic.Clone().(*Graphic)
-	clone.SetIGraphic(clone)
+	clone.initClone(clone, m)
+	return clone
+}
 
-	// Initialize uniforms
-	clone.uniMm.Init("ModelMatrix")
-	clone.uniMVm.Init("ModelViewMatrix")
-	clone.uniMVPm.Init("MVP")
-	clone.uniNm.Init("NormalMatrix")
+// initClone initializes this mesh, embedded in the specified IGraphic, as a clone of the specified mesh.
+func (m *Mesh) initClone(igr IGraphic, src *Mesh) {
 
-	return clone
+	m.Graphic.InitClone(igr, &src.Graphic)
+
+	// Initialize uniforms
+	m.uniMm.Init("ModelMatrix")
+	m.uniMVm.Init("ModelViewMatrix")
+	m.uniMVPm.Init("MVP")
+	m.uniNm.Init("NormalMatrix")
 }
 
 // RenderSetup is called by the engine before drawing the mesh geometry
@@ -0,0 +1,60 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/text"
+	"github.com/g3n/engine/texture"
+)
+
+// NewPathText creates and returns a pointer to a mesh with the specified single line text
+// drawn with the specified font on a strip following the specified 2D path in the XY plane.
+// The height of the strip is the specified height and its length along the path is
+// proportional to the width of the text. Text which does not fit in the path is clipped.
+// The text is drawn with the current colors of the font.
+func NewPathText(font *text.Font, msg string, path []math32.Vector2, height float32) *Mesh {
+
+	if msg == "" {
+		msg = " "
+	}
+	img := font.DrawText(msg)
+	length := height * float32(img.Rect.Dx()) / float32(img.Rect.Dy())
+
+	// Clip the text texture if it is longer than the path
+	tex := texture.NewTexture2DFromRGBA(img)
+	if pathLength := text.PathLength(path); length > pathLength {
+		tex.SetRepeat(pathLength/length, 1)
+		length = pathLength
+	}
+
+	mat := material.NewStandard(math32.NewColor("white"))
+	mat.AddTexture(tex)
+	mat.SetTransparent(true)
+	mat.SetSide(material.SideDouble)
+	return NewMesh(geometry.NewPathStrip(trimPath(path, length), height), mat)
+}
+
+// trimPath returns the initial part of the specified path with the specified length.
+func trimPath(path []math32.Vector2, length float32) []math32.Vector2 {
+
+	trimmed := make([]math32.Vector2, 0, len(path))
+	for i := range path {
+		if i > 0 {
+			seg := path[i].DistanceTo(&path[i-1])
+			if seg >= length {
+				p := path[i-1]
+				p.Lerp(&path[i], length/seg)
+				trimmed = append(trimmed, p)
+				return trimmed
+			}
+			length -= seg
+		}
+		trimmed = append(trimmed, path[i])
+	}
+	return trimmed
+}
@@ -32,6 +32,16 @@ func NewPoints(igeom geometry.IGeometry, imat material.IMaterial) *Points {
 	return p
 }
 
+// Clone clones the points and satisfies the INode interface.
+func (p *Points) Clone() core.INode {
+
+	clone := new(Points)
+	clone.Graphic.InitClone(clone, &p.Graphic)
+	clone.uniMVPm.Init("MVP")
+	clone.uniMVm.Init("MV")
+	return clone
+}
+
 // RenderSetup is called by the engine before rendering this graphic.
 func (p *Points) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
 
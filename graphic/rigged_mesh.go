@@ -15,11 +15,27 @@ import (
 // MaxBoneInfluencers is the maximum number of bone influencers per vertex.
 const MaxBoneInfluencers = 4
 
+// MaxUniformBones is the maximum number of bones of a skeleton whose matrices are
+// transferred to the vertex shader in a uniform array. The matrices of larger skeletons,
+// which would exceed the number of uniforms of the vertex shader, are transferred in a texture.
+const MaxUniformBones = 48
+
+// BoneTextureUnit is the texture unit used to bind the texture with the bone matrices.
+// It is the last unit guaranteed by WebGL 2 so it doesn't collide with the units of the
+// material textures and of the renderer.
+const BoneTextureUnit = 31
+
 // RiggedMesh is a Mesh associated with a skeleton.
 type RiggedMesh struct {
-	*Mesh    // Embedded mesh
-	skeleton *Skeleton
-	mBones   gls.Uniform
+	*Mesh                  // Embedded mesh
+	skeleton   *Skeleton   // Skeleton which deforms the mesh
+	mBones     gls.Uniform // Bone matrices uniform array
+	mBoneTex   gls.Uniform // Bone matrices texture uniform
+	boneTex    uint32      // Bone matrices texture name (0 if not created)
+	boneTexLen int         // Number of bones allocated in the bone matrices texture
+	boneData   []float32   // Bone matrices transferred to the texture
+	gs         *gls.GLS    // OpenGL state of the bone matrices texture
+	gen        uint32      // Generation of the OpenGL context of the bone matrices texture
 }
 
 // NewRiggedMesh returns a new rigged mesh.
@@ -29,17 +45,36 @@ func NewRiggedMesh(mesh *Mesh) *RiggedMesh {
 	rm.Mesh = mesh
 	rm.SetIGraphic(rm)
 	rm.mBones.Init("mBones")
+	rm.mBoneTex.Init("mBoneTexture")
 	rm.ShaderDefines.Set("BONE_INFLUENCERS", strconv.Itoa(MaxBoneInfluencers))
 	rm.ShaderDefines.Set("TOTAL_BONES", "0")
 
 	return rm
 }
 
+// Clone clones the rigged mesh and satisfies the INode interface.
+// The clone shares the skeleton of the rigged mesh.
+func (rm *RiggedMesh) Clone() core.INode {
+
+	clone := new(RiggedMesh)
+	clone.Mesh = new(Mesh)
+	clone.Mesh.initClone(clone, rm.Mesh)
+	clone.skeleton = rm.skeleton
+	clone.mBones.Init("mBones")
+	clone.mBoneTex.Init("mBoneTexture")
+	return clone
+}
+
 // SetSkeleton sets the skeleton used by the rigged mesh.
 func (rm *RiggedMesh) SetSkeleton(sk *Skeleton) {
 
 	rm.skeleton = sk
 	rm.ShaderDefines.Set("TOTAL_BONES", strconv.Itoa(len(rm.skeleton.Bones())))
+	if len(rm.skeleton.Bones()) > MaxUniformBones {
+		rm.ShaderDefines.Set("BONE_TEXTURE", "")
+	} else {
+		rm.ShaderDefines.Unset("BONE_TEXTURE")
+	}
 }
 
 // SetSkeleton returns the skeleton used by the rigged mesh.
@@ -65,6 +100,54 @@ func (rm *RiggedMesh) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
 
 	// Transfer bone matrices
 	boneMatrices := rm.skeleton.BoneMatrices(&invMat)
+	if len(boneMatrices) > MaxUniformBones {
+		rm.transferBoneTexture(gs, boneMatrices)
+		return
+	}
 	location := rm.mBones.Location(gs)
 	gs.UniformMatrix4fv(location, int32(len(boneMatrices)), false, &boneMatrices[0][0])
 }
+
+// transferBoneTexture transfers the specified bone matrices to the bone matrices texture,
+// creating it if necessary, and binds it to BoneTextureUnit.
+// Each row of the texture contains the four columns of the matrix of a bone.
+func (rm *RiggedMesh) transferBoneTexture(gs *gls.GLS, boneMatrices []math32.Matrix4) {
+
+	// Copy the matrices
+	if len(rm.boneData) != 16*len(boneMatrices) {
+		rm.boneData = make([]float32, 16*len(boneMatrices))
+	}
+	for i := range boneMatrices {
+		copy(rm.boneData[16*i:], boneMatrices[i][:])
+	}
+
+	gs.ActiveTexture(gls.TEXTURE0 + BoneTextureUnit)
+	if rm.boneTex == 0 || rm.gs != gs || rm.gen != gs.Generation() || rm.boneTexLen != len(boneMatrices) {
+		// Creates the texture or recreates it if the OpenGL context was reset
+		if rm.boneTex != 0 && rm.gs == gs && rm.gen == gs.Generation() {
+			gs.DeleteTextures(rm.boneTex)
+		}
+		rm.boneTex = gs.GenTexture()
+		rm.boneTexLen = len(boneMatrices)
+		rm.gs = gs
+		rm.gen = gs.Generation()
+		gs.BindTexture(gls.TEXTURE_2D, rm.boneTex)
+		gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_MIN_FILTER, gls.NEAREST)
+		gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_MAG_FILTER, gls.NEAREST)
+		gs.TexImage2D(gls.TEXTURE_2D, 0, gls.RGBA32F, 4, int32(rm.boneTexLen), gls.RGBA, gls.FLOAT, rm.boneData)
+	} else {
+		gs.BindTexture(gls.TEXTURE_2D, rm.boneTex)
+		gs.TexSubImage2D(gls.TEXTURE_2D, 0, 0, 0, 4, int32(rm.boneTexLen), gls.RGBA, gls.FLOAT, rm.boneData)
+	}
+	gs.Uniform1i(rm.mBoneTex.Location(gs), BoneTextureUnit)
+}
+
+// Dispose releases the OpenGL resources of this rigged mesh
+func (rm *RiggedMesh) Dispose() {
+
+	if rm.boneTex != 0 && rm.gs != nil && rm.gen == rm.gs.Generation() {
+		rm.gs.DeleteTextures(rm.boneTex)
+	}
+	rm.boneTex = 0
+	rm.Mesh.Dispose()
+}
@@ -66,6 +66,17 @@ func NewSkybox(data SkyboxData) (*Skybox, error) {
 	return skybox, nil
 }
 
+// Clone clones the skybox and satisfies the INode interface.
+func (skybox *Skybox) Clone() core.INode {
+
+	clone := new(Skybox)
+	clone.Graphic.InitClone(clone, &skybox.Graphic)
+	clone.uniMVm.Init("ModelViewMatrix")
+	clone.uniMVPm.Init("MVP")
+	clone.uniNm.Init("NormalMatrix")
+	return clone
+}
+
 // RenderSetup is called by the engine before drawing the skybox geometry
 // It is responsible to updating the current shader uniforms with
 // the model matrices.
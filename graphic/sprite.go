@@ -13,9 +13,13 @@ import (
 )
 
 // Sprite is a potentially animated image positioned in space that always faces the camera.
+// Its orientation (and size in screen space mode) is updated during rendering
+// from the current camera matrices.
 type Sprite struct {
-	Graphic             // Embedded graphic
-	uniMVPM gls.Uniform // Model view projection matrix uniform location cache
+	Graphic                     // Embedded graphic
+	uniMVPM     gls.Uniform     // Model view projection matrix uniform location cache
+	axisLock    *math32.Vector3 // Optional world axis around which the sprite rotates to face the camera
+	screenSpace bool            // Sprite dimensions are in screen pixels
 }
 
 // NewSprite creates and returns a pointer to a sprite with the specified dimensions and material
@@ -55,11 +59,58 @@ func NewSprite(width, height float32, imat material.IMaterial) *Sprite {
 	return s
 }
 
+// Clone clones the sprite and satisfies the INode interface.
+func (s *Sprite) Clone() core.INode {
+
+	clone := new(Sprite)
+	clone.Graphic.InitClone(clone, &s.Graphic)
+	clone.uniMVPM.Init("MVP")
+	clone.SetAxisLock(s.axisLock)
+	clone.screenSpace = s.screenSpace
+	return clone
+}
+
+// SetAxisLock sets the world axis around which the sprite rotates to face the camera,
+// for example the Y axis for trees and health bars which must stay upright.
+// Nil (the default) makes the sprite always fully face the camera.
+func (s *Sprite) SetAxisLock(axis *math32.Vector3) {
+
+	if axis == nil {
+		s.axisLock = nil
+		return
+	}
+	s.axisLock = axis.Clone().Normalize()
+}
+
+// AxisLock returns the world axis around which the sprite rotates or nil if not locked.
+func (s *Sprite) AxisLock() *math32.Vector3 {
+
+	return s.axisLock
+}
+
+// SetScreenSpace sets whether the sprite dimensions are in screen pixels.
+// In screen space mode the sprite keeps the same size on the screen
+// independently of its distance from the camera.
+func (s *Sprite) SetScreenSpace(state bool) {
+
+	s.screenSpace = state
+}
+
+// ScreenSpace returns whether the sprite dimensions are in screen pixels.
+func (s *Sprite) ScreenSpace() bool {
+
+	return s.screenSpace
+}
+
 // RenderSetup sets up the rendering of the sprite.
 func (s *Sprite) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
 
 	// Calculates model view matrix
 	mw := s.MatrixWorld()
+	if s.axisLock != nil {
+		s.renderSetupLocked(gs, rinfo, &mw)
+		return
+	}
 	var mvm math32.Matrix4
 	mvm.MultiplyMatrices(&rinfo.ViewMatrix, &mw)
 
@@ -83,6 +134,7 @@ func (s *Sprite) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
 		rotation.X = math32.Pi
 	}
 	quaternion.SetFromEuler(&rotation)
+	s.applyScreenScale(gs, rinfo, &position, &scale)
 	var mvmNew math32.Matrix4
 	mvmNew.Compose(&position, &quaternion, &scale)
 
@@ -92,3 +144,73 @@ func (s *Sprite) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
 	location := s.uniMVPM.Location(gs)
 	gs.UniformMatrix4fv(location, 1, false, &mvpm[0])
 }
+
+// renderSetupLocked sets up the rendering of the sprite rotating it only around
+// its lock axis to face the camera. The specified matrix is the sprite world matrix.
+func (s *Sprite) renderSetupLocked(gs *gls.GLS, rinfo *core.RenderInfo, mw *math32.Matrix4) {
+
+	// Decomposes world matrix
+	var position math32.Vector3
+	var quaternion math32.Quaternion
+	var scale math32.Vector3
+	mw.Decompose(&position, &quaternion, &scale)
+
+	// Gets the camera world position from the inverse of the view matrix
+	var camMatrix math32.Matrix4
+	camMatrix.GetInverse(&rinfo.ViewMatrix)
+	var camPos math32.Vector3
+	camPos.SetFromMatrixPosition(&camMatrix)
+
+	// Direction to the camera perpendicular to the lock axis
+	up := *s.axisLock
+	forward := camPos
+	forward.Sub(&position).ProjectOnPlane(&up)
+	if forward.LengthSq() < 1e-12 {
+		// Camera is over the lock axis: uses the camera forward direction
+		forward = *camMatrix.GetColumnVector3(2)
+		forward.ProjectOnPlane(&up)
+		if forward.LengthSq() < 1e-12 {
+			forward = *up.Clone().Cross(camMatrix.GetColumnVector3(0))
+		}
+	}
+	forward.Normalize()
+	var right math32.Vector3
+	right.CrossVectors(&up, &forward)
+
+	// Composes the world matrix with the new rotation
+	var rotMatrix math32.Matrix4
+	rotMatrix.MakeBasis(&right, &up, &forward)
+	quaternion.SetFromRotationMatrix(&rotMatrix)
+	viewPos := position
+	viewPos.ApplyMatrix4(&rinfo.ViewMatrix)
+	s.applyScreenScale(gs, rinfo, &viewPos, &scale)
+	var mwNew math32.Matrix4
+	mwNew.Compose(&position, &quaternion, &scale)
+
+	// Calculates final MVP and updates uniform
+	var mvm math32.Matrix4
+	mvm.MultiplyMatrices(&rinfo.ViewMatrix, &mwNew)
+	var mvpm math32.Matrix4
+	mvpm.MultiplyMatrices(&rinfo.ProjMatrix, &mvm)
+	location := s.uniMVPM.Location(gs)
+	gs.UniformMatrix4fv(location, 1, false, &mvpm[0])
+}
+
+// applyScreenScale multiplies the specified scale, in screen space mode, by the
+// factor which converts the sprite dimensions in pixels to view units at the
+// specified position in view coordinates.
+func (s *Sprite) applyScreenScale(gs *gls.GLS, rinfo *core.RenderInfo, viewPos, scale *math32.Vector3) {
+
+	if !s.screenSpace {
+		return
+	}
+	_, _, _, height := gs.GetViewport()
+	pm := &rinfo.ProjMatrix
+	if height == 0 || pm[5] == 0 {
+		return
+	}
+	// Clip space w of the position: the distance from the camera for
+	// perspective projections and 1 for orthographic projections.
+	w := pm[3]*viewPos.X + pm[7]*viewPos.Y + pm[11]*viewPos.Z + pm[15]
+	scale.MultiplyScalar(2 * w / (pm[5] * float32(height)))
+}
@@ -0,0 +1,248 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+// IAnchorCamera is the interface of the cameras used by anchors to project the
+// positions of their target nodes. It is implemented by camera.ICamera.
+type IAnchorCamera interface {
+	ViewMatrix(m *math32.Matrix4)
+	ProjMatrix(m *math32.Matrix4)
+}
+
+// Anchor positions a GUI panel or a 3D node, such as a screen space sprite, at the
+// screen position of a target 3D node projected by a camera, for nameplates and
+// waypoint markers. When the target is off screen, the anchored node can be
+// clamped to the screen edges or replaced by an off-screen indicator.
+// Update must be called each frame after the target and the camera are moved.
+type Anchor struct {
+	node      core.INode     // Anchored panel or node
+	target    core.INode     // Target node
+	cam       IAnchorCamera  // Camera used to project the target position
+	offset    math32.Vector3 // Offset in world coordinates added to the target position
+	pivot     math32.Vector2 // Point of the anchored panels placed at the position as fractions of their sizes
+	clamp     bool           // Clamp the anchored node to the screen edges when the target is off screen
+	margin    float32        // Distance in pixels from the screen edges of clamped nodes
+	indicator core.INode     // Node shown instead of the anchored node when the target is off screen
+	onScreen  bool           // Target is on screen
+	screen    math32.Vector2 // Position of the anchored node in window pixels
+	direction float32        // Angle of the direction of the target from the screen center
+}
+
+// NewAnchor creates and returns a pointer to a new anchor which positions the specified panel
+// or 3D node at the screen position of the target node projected by the specified camera.
+// Panels are positioned with their top center at the target position, so they are just below it.
+// Other nodes are positioned at the world position which is projected to the same screen position.
+func NewAnchor(node, target core.INode, cam IAnchorCamera) *Anchor {
+
+	a := new(Anchor)
+	a.node = node
+	a.target = target
+	a.cam = cam
+	a.pivot = math32.Vector2{X: 0.5, Y: 0}
+	return a
+}
+
+// SetOffset sets the offset in world coordinates added to the position of the target node,
+// for instance to show a nameplate above the head of a character.
+func (a *Anchor) SetOffset(x, y, z float32) {
+
+	a.offset.Set(x, y, z)
+}
+
+// Offset returns the offset in world coordinates added to the position of the target node.
+func (a *Anchor) Offset() math32.Vector3 {
+
+	return a.offset
+}
+
+// SetPivot sets the point of the anchored panels and indicators placed at the screen position,
+// as fractions of their sizes from their top left corner. The default is (0.5, 0), the top center.
+func (a *Anchor) SetPivot(x, y float32) {
+
+	a.pivot.X = x
+	a.pivot.Y = y
+}
+
+// Pivot returns the point of the anchored panels and indicators placed at the screen position.
+func (a *Anchor) Pivot() math32.Vector2 {
+
+	return a.pivot
+}
+
+// SetClamp sets whether the anchored node is kept inside the screen, at the specified distance
+// in pixels from its edges, when the target is off screen. Otherwise it is hidden.
+// The margin is also used for the off-screen indicator.
+func (a *Anchor) SetClamp(clamp bool, margin float32) {
+
+	a.clamp = clamp
+	a.margin = margin
+}
+
+// Clamp returns whether the anchored node is kept inside the screen and the margin.
+func (a *Anchor) Clamp() (bool, float32) {
+
+	return a.clamp, a.margin
+}
+
+// SetIndicator sets the panel or node shown at the screen edge, in the direction of the target,
+// instead of the anchored node when the target is off screen. It can be rotated with Direction
+// to point to the target. Nil (the default) removes the indicator.
+func (a *Anchor) SetIndicator(indicator core.INode) {
+
+	if a.indicator != nil && a.indicator != indicator {
+		a.indicator.GetNode().SetVisible(false)
+	}
+	a.indicator = indicator
+}
+
+// Indicator returns the off-screen indicator or nil if none.
+func (a *Anchor) Indicator() core.INode {
+
+	return a.indicator
+}
+
+// OnScreen returns whether the target was on screen in the last update.
+func (a *Anchor) OnScreen() bool {
+
+	return a.onScreen
+}
+
+// ScreenPosition returns the position, in window pixels, of the anchored node or of
+// the indicator in the last update, which is clamped if the target is off screen.
+func (a *Anchor) ScreenPosition() math32.Vector2 {
+
+	return a.screen
+}
+
+// Direction returns the angle, in radians counterclockwise from the right of the screen,
+// of the direction of the target from the screen center in the last update.
+func (a *Anchor) Direction() float32 {
+
+	return a.direction
+}
+
+// Update projects the position of the target node and positions and shows or hides the
+// anchored node and the indicator. It should be called each frame before rendering.
+func (a *Anchor) Update() {
+
+	w, h := Manager().ScreenSize()
+
+	// Projects the target position to clip coordinates
+	var pos math32.Vector3
+	a.target.GetNode().WorldPosition(&pos)
+	pos.Add(&a.offset)
+	var view, proj, vp math32.Matrix4
+	a.cam.ViewMatrix(&view)
+	a.cam.ProjMatrix(&proj)
+	vp.MultiplyMatrices(&proj, &view)
+	clip := math32.Vector4{X: pos.X, Y: pos.Y, Z: pos.Z, W: 1}
+	clip.ApplyMatrix4(&vp)
+	if clip.W == 0 {
+		clip.W = 1e-6
+	}
+
+	// Screen position in pixels, which is mirrored if the target is behind the camera
+	sx := (clip.X/clip.W + 1) / 2 * w
+	sy := (1 - clip.Y/clip.W) / 2 * h
+	depth := clip.Z / clip.W
+	behind := clip.W < 0
+	a.onScreen = !behind && sx >= 0 && sx <= w && sy >= 0 && sy <= h
+	dx := sx - w/2
+	dy := sy - h/2
+	if behind {
+		dx, dy = -dx, -dy
+		depth = 0
+	}
+	if dx == 0 && dy == 0 {
+		dy = 1
+	}
+	a.direction = math32.Atan2(-dy, dx)
+
+	// Selects the node to show
+	node := a.node
+	if !a.onScreen && a.indicator != nil {
+		node = a.indicator
+	}
+	a.node.GetNode().SetVisible(node == a.node && (a.onScreen || a.clamp))
+	if a.indicator != nil {
+		a.indicator.GetNode().SetVisible(node == a.indicator)
+	}
+	if !node.GetNode().Visible() {
+		a.screen.Set(sx, sy)
+		return
+	}
+
+	// Clamps the position of the pivot to the rectangle where the node is inside the screen
+	if !a.onScreen {
+		var pw, ph float32
+		if ipan, ok := node.(IPanel); ok {
+			pw = ipan.GetPanel().Width()
+			ph = ipan.GetPanel().Height()
+		}
+		minX := a.margin + a.pivot.X*pw
+		maxX := math32.Max(minX, w-a.margin-(1-a.pivot.X)*pw)
+		minY := a.margin + a.pivot.Y*ph
+		maxY := math32.Max(minY, h-a.margin-(1-a.pivot.Y)*ph)
+		// Scales the direction from the screen center to reach the border of the rectangle
+		t := float32(math32.Infinity)
+		if dx > 0 {
+			t = math32.Min(t, (maxX-w/2)/dx)
+		} else if dx < 0 {
+			t = math32.Min(t, (minX-w/2)/dx)
+		}
+		if dy > 0 {
+			t = math32.Min(t, (maxY-h/2)/dy)
+		} else if dy < 0 {
+			t = math32.Min(t, (minY-h/2)/dy)
+		}
+		if behind || t < 1 {
+			sx = w/2 + dx*t
+			sy = h/2 + dy*t
+		}
+		sx = math32.Clamp(sx, minX, maxX)
+		sy = math32.Clamp(sy, minY, maxY)
+	}
+	a.screen.Set(sx, sy)
+
+	// Panels are positioned in pixels relative to their parents
+	if ipan, ok := node.(IPanel); ok {
+		p := ipan.GetPanel()
+		x := sx - a.pivot.X*p.Width()
+		y := sy - a.pivot.Y*p.Height()
+		if par, ok := p.Parent().(IPanel); ok {
+			pp := par.GetPanel()
+			if p.bounded {
+				x, y = pp.ContentCoords(x, y)
+			} else {
+				x -= pp.pospix.X
+				y -= pp.pospix.Y
+			}
+		}
+		p.SetPosition(x, y)
+		return
+	}
+
+	// Other nodes are positioned at the world position projected to the screen position
+	var inv math32.Matrix4
+	if inv.GetInverse(&vp) != nil {
+		return
+	}
+	wpos := math32.Vector3{X: sx/w*2 - 1, Y: 1 - sy/h*2, Z: depth}
+	wpos.ApplyProjection(&inv)
+	n := node.GetNode()
+	if par := n.Parent(); par != nil {
+		pmw := par.GetNode().MatrixWorld()
+		if inv.GetInverse(&pmw) != nil {
+			return
+		}
+		wpos.ApplyMatrix4(&inv)
+	}
+	n.SetPositionVec(&wpos)
+}
@@ -119,6 +119,7 @@ const (
 	AttribAutoHeight     = "autoheight"    // bool
 	AttribAutoWidth      = "autowidth"     // bool
 	AttribName           = "name"          // string
+	AttribNinePatch      = "ninepatch"     // RectBounds
 	AttribPaddings       = "paddings"      // RectBounds
 	AttribPanel0         = "panel0"        // map[string]interface{}
 	AttribPanel1         = "panel1"        // map[string]interface{}
@@ -265,6 +266,7 @@ func NewBuilder() *Builder {
 		AttribAutoHeight:    AttribCheckBool,
 		AttribAutoWidth:     AttribCheckBool,
 		AttribName:          AttribCheckString,
+		AttribNinePatch:     AttribCheckBorderSizes,
 		AttribPaddings:      AttribCheckBorderSizes,
 		AttribPanel0:        AttribCheckMap,
 		AttribPanel1:        AttribCheckMap,
@@ -559,6 +561,11 @@ func (b *Builder) SetAttribs(am map[string]interface{}, ipan IPanel) error {
 		panel.SetColor4(am[AttribColor].(*math32.Color4))
 	}
 
+	// Set optional nine-patch insets of the content texture
+	if am[AttribNinePatch] != nil {
+		panel.SetNinePatch(am[AttribNinePatch].(*RectBounds))
+	}
+
 	if am[AttribName] != nil {
 		panel.SetName(am[AttribName].(string))
 	}
@@ -347,6 +347,11 @@ func buildDropDown(b *Builder, am map[string]interface{}) (IPanel, error) {
 		items := am[AttribItems].([]map[string]interface{})
 		for i := 0; i < len(items); i++ {
 			item := items[i]
+			// Item is a separator
+			if item[AttribType] == TypeSeparator {
+				dd.AddSeparator()
+				continue
+			}
 			child, err := b.build(item, dd)
 			if err != nil {
 				return nil, err
@@ -23,13 +23,12 @@ import (
 
 // Button represents a button GUI element
 type Button struct {
-	Panel                   // Embedded Panel
-	Label     *Label        // Label panel
-	image     *Image        // pointer to button image (may be nil)
-	icon      *Label        // pointer to button icon (may be nil
-	styles    *ButtonStyles // pointer to current button styles
-	mouseOver bool          // true if mouse is over button
-	pressed   bool          // true if button is pressed
+	Panel                 // Embedded Panel
+	Label   *Label        // Label panel
+	image   *Image        // pointer to button image (may be nil)
+	icon    *Label        // pointer to button icon (may be nil
+	styles  *ButtonStyles // pointer to current button styles
+	tracker StateTracker  // tracker of the button pseudo-state
 }
 
 // ButtonStyle contains the styling of a Button
@@ -60,11 +59,8 @@ func NewButton(text string) *Button {
 	b.Subscribe(OnMouseUp, b.onMouse)
 	b.Subscribe(OnMouseDown, b.onMouse)
 	b.Subscribe(OnMouseUpOut, b.onMouse)
-	b.Subscribe(OnCursor, b.onCursor)
-	b.Subscribe(OnCursorEnter, b.onCursor)
-	b.Subscribe(OnCursorLeave, b.onCursor)
-	b.Subscribe(OnEnable, func(name string, ev interface{}) { b.update() })
 	b.Subscribe(OnResize, func(name string, ev interface{}) { b.recalc() })
+	b.tracker.Init(&b.Panel, b.update)
 
 	// Creates label
 	b.Label = NewLabel(text)
@@ -76,6 +72,12 @@ func NewButton(text string) *Button {
 	return b
 }
 
+// SetMarkup sets the button label text from the specified markup (see Label.SetMarkup).
+func (b *Button) SetMarkup(markup string) {
+
+	b.Label.SetMarkup(markup)
+}
+
 // SetIcon sets the button icon from the default Icon font.
 // If there is currently a selected image, it is removed
 func (b *Button) SetIcon(icode string) {
@@ -120,19 +122,6 @@ func (b *Button) SetStyles(bs *ButtonStyles) {
 	b.update()
 }
 
-// onCursor process subscribed cursor events
-func (b *Button) onCursor(evname string, ev interface{}) {
-
-	switch evname {
-	case OnCursorEnter:
-		b.mouseOver = true
-		b.update()
-	case OnCursorLeave:
-		b.mouseOver = false
-		b.update()
-	}
-}
-
 // onMouseEvent process subscribed mouse events
 func (b *Button) onMouse(evname string, ev interface{}) {
 
@@ -143,16 +132,13 @@ func (b *Button) onMouse(evname string, ev interface{}) {
 	switch evname {
 	case OnMouseDown:
 		Manager().SetKeyFocus(b)
-		b.pressed = true
-		b.update()
 	case OnMouseUpOut:
 		fallthrough
 	case OnMouseUp:
-		if b.pressed && b.mouseOver {
+		// The state tracker is updated after this handler
+		if b.tracker.Pressed() && b.tracker.Over() {
 			b.Dispatch(OnClick, nil)
 		}
-		b.pressed = false
-		b.update()
 	default:
 		return
 	}
@@ -167,31 +153,28 @@ func (b *Button) onKey(evname string, ev interface{}) {
 	}
 	switch evname {
 	case OnKeyDown:
-		b.pressed = true
-		b.update()
+		b.tracker.SetPressed(true)
 		b.Dispatch(OnClick, nil)
 	case OnKeyUp:
-		b.pressed = false
-		b.update()
+		b.tracker.SetPressed(false)
 	}
 }
 
 // update updates the button visual state
 func (b *Button) update() {
 
-	if !b.Enabled() {
+	switch b.tracker.State() {
+	case StyleDisabled:
 		b.applyStyle(&b.styles.Disabled)
-		return
-	}
-	if b.pressed && b.mouseOver {
+	case StylePressed:
 		b.applyStyle(&b.styles.Pressed)
-		return
-	}
-	if b.mouseOver {
+	case StyleOver:
 		b.applyStyle(&b.styles.Over)
-		return
+	case StyleFocus:
+		b.applyStyle(&b.styles.Focus)
+	default:
+		b.applyStyle(&b.styles.Normal)
 	}
-	b.applyStyle(&b.styles.Normal)
 }
 
 // applyStyle applies the specified button style
@@ -0,0 +1,544 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/renderer/shaders"
+	"github.com/g3n/engine/texture"
+)
+
+func init() {
+	shaders.AddShader("shaderCanvasVertex", shaderCanvasVertex)
+	shaders.AddShader("shaderCanvasFrag", shaderCanvasFrag)
+	shaders.AddProgram("shaderCanvas", "shaderCanvasVertex", "shaderCanvasFrag")
+}
+
+// Canvas is a panel which draws retained 2D vector shapes. Paths are filled or
+// stroked with solid colors or gradients and the resulting shapes are kept by the
+// canvas, which tessellates them into triangles drawn with a single draw call,
+// until they are removed. Shape coordinates are in pixels relative to the top
+// left corner of the canvas and are transformed by the current drawing transform
+// when the shape is added. The canvas has no borders, paddings or background.
+type Canvas struct {
+	Panel                        // Embedded panel
+	mat       canvasMaterial     // Canvas material
+	vbo       *gls.VBO           // Vertices of the tessellated shapes
+	ramp      *texture.Texture2D // Gradient color ramps, one per row
+	shapes    []*CanvasShape     // Shapes in drawing order
+	transform math32.Matrix3     // Current drawing transform
+	stack     []math32.Matrix3   // Saved drawing transforms
+	changed   bool               // Vertices must be rebuilt
+	uniBounds gls.Uniform        // Bounds uniform location cache
+}
+
+// CanvasShape is a filled or stroked path retained by a Canvas.
+type CanvasShape struct {
+	canvas  *Canvas          // Canvas containing the shape
+	tris    []math32.Vector2 // Triangle vertices in canvas pixels
+	inverse math32.Matrix3   // Inverse of the drawing transform used to map the gradients
+	paint   Paint            // Fill or stroke paint
+	visible bool             // Visibility
+}
+
+// Paint is the solid color or gradient used to fill or stroke canvas shapes.
+// Gradient coordinates are in the same space as the path coordinates.
+type Paint struct {
+	kind   paintKind        // Paint kind
+	color  math32.Color4    // Solid color
+	p0     math32.Vector2   // Gradient start point or center
+	p1     math32.Vector2   // Linear gradient end point
+	radius float32          // Radial gradient radius
+	grad   *math32.Gradient // Gradient color stops
+}
+
+// GradientStop is a color of a gradient at the specified offset from 0 to 1.
+// The stops of a math32.Gradient can be used directly with LinearGradient and RadialGradient.
+type GradientStop = math32.GradientStop
+
+// paintKind is the kind of a paint, which is passed to the canvas shader.
+type paintKind int
+
+const (
+	paintSolid = paintKind(iota)
+	paintLinear
+	paintRadial
+)
+
+// StrokeStyle describes how paths are stroked.
+type StrokeStyle struct {
+	Width      float32  // Line width in pixels
+	Cap        LineCap  // Shape of the ends of open subpaths
+	Join       LineJoin // Shape of the corners
+	MiterLimit float32  // Maximum ratio of the miter length to half the width (default 10)
+}
+
+// LineCap is the shape of the ends of stroked open subpaths.
+type LineCap int
+
+// The line cap shapes.
+const (
+	LineCapButt = LineCap(iota)
+	LineCapRound
+	LineCapSquare
+)
+
+// LineJoin is the shape of the corners of stroked subpaths.
+type LineJoin int
+
+// The line join shapes.
+const (
+	LineJoinMiter = LineJoin(iota)
+	LineJoinRound
+	LineJoinBevel
+)
+
+// canvasRampWidth is the number of texels of each gradient color ramp.
+const canvasRampWidth = 256
+
+// NewCanvas creates and returns a pointer to a new empty canvas with the specified size.
+func NewCanvas(width, height float32) *Canvas {
+
+	c := new(Canvas)
+	c.transform.Identity()
+	c.uniBounds.Init("Bounds")
+
+	// Each vertex has its position, color and paint parameters
+	c.vbo = gls.NewVBO(math32.NewArrayF32(0, 0))
+	c.vbo.AddAttrib(gls.VertexPosition)
+	c.vbo.AddCustomAttrib("CanvasColor", 4)
+	c.vbo.AddCustomAttrib("CanvasPaint", 4)
+	geom := geometry.NewGeometry()
+	geom.AddVBO(c.vbo)
+
+	// Initializes the panel graphic
+	gr := graphic.NewGraphic(c, geom, gls.TRIANGLES)
+	c.mat.Init(c)
+	gr.AddMaterial(c, &c.mat, 0, 0)
+	c.Panel.InitializeGraphic(width, height, gr)
+	return c
+}
+
+// SolidPaint returns a paint with the specified solid color.
+func SolidPaint(color *math32.Color4) Paint {
+
+	return Paint{kind: paintSolid, color: *color}
+}
+
+// LinearGradient returns a paint with a linear gradient from the point (x0, y0),
+// at offset 0, to the point (x1, y1), at offset 1, with the specified color stops.
+func LinearGradient(x0, y0, x1, y1 float32, stops ...GradientStop) Paint {
+
+	return Paint{
+		kind:  paintLinear,
+		color: math32.Color4{R: 1, G: 1, B: 1, A: 1},
+		p0:    math32.Vector2{X: x0, Y: y0},
+		p1:    math32.Vector2{X: x1, Y: y1},
+		grad:  math32.NewGradient(stops...),
+	}
+}
+
+// RadialGradient returns a paint with a radial gradient from the center (cx, cy),
+// at offset 0, to the circle with the specified radius, at offset 1, with the specified color stops.
+func RadialGradient(cx, cy, radius float32, stops ...GradientStop) Paint {
+
+	return Paint{
+		kind:   paintRadial,
+		color:  math32.Color4{R: 1, G: 1, B: 1, A: 1},
+		p0:     math32.Vector2{X: cx, Y: cy},
+		radius: radius,
+		grad:   math32.NewGradient(stops...),
+	}
+}
+
+// IsGradient returns whether this paint is a gradient.
+func (p Paint) IsGradient() bool {
+
+	return p.kind != paintSolid
+}
+
+// ColorAt returns the color of this paint at the specified offset of its gradient,
+// or its solid color.
+func (p Paint) ColorAt(offset float32) math32.Color4 {
+
+	if p.kind == paintSolid {
+		return p.color
+	}
+	return p.grad.Evaluate(offset)
+}
+
+// Fill adds a shape with the area enclosed by the subpaths of the specified path filled with
+// the specified paint and returns it. Open subpaths are implicitly closed and subpaths inside
+// an odd number of other subpaths are holes. Subpaths must not intersect each other or themselves.
+func (c *Canvas) Fill(path *Path, paint Paint) *CanvasShape {
+
+	s := c.newShape(paint)
+	fillPolylines(path.flatten(&c.transform), s.addTriangle)
+	return s
+}
+
+// Stroke adds a shape with the outline of the subpaths of the specified path stroked with
+// the specified paint and style and returns it. A nil style strokes one pixel wide lines.
+func (c *Canvas) Stroke(path *Path, paint Paint, style *StrokeStyle) *CanvasShape {
+
+	if style == nil {
+		style = &StrokeStyle{Width: 1}
+	}
+	s := c.newShape(paint)
+	// The width is scaled by the mean scale of the drawing transform
+	m := &c.transform
+	hw := style.Width / 2 * math32.Sqrt(math32.Abs(m[0]*m[4]-m[1]*m[3]))
+	for _, line := range path.flatten(m) {
+		strokePolyline(line, style, hw, s.addTriangle)
+	}
+	return s
+}
+
+// newShape creates a new visible shape with the specified paint and appends it to this canvas.
+func (c *Canvas) newShape(paint Paint) *CanvasShape {
+
+	s := new(CanvasShape)
+	s.canvas = c
+	s.paint = paint
+	s.visible = true
+	if s.inverse.GetInverse(&c.transform) != nil {
+		s.inverse.Identity()
+	}
+	c.shapes = append(c.shapes, s)
+	c.changed = true
+	return s
+}
+
+// RemoveShape removes the specified shape from this canvas.
+// Returns true if found or false otherwise.
+func (c *Canvas) RemoveShape(s *CanvasShape) bool {
+
+	for i, shape := range c.shapes {
+		if shape == s {
+			copy(c.shapes[i:], c.shapes[i+1:])
+			c.shapes[len(c.shapes)-1] = nil
+			c.shapes = c.shapes[:len(c.shapes)-1]
+			c.changed = true
+			return true
+		}
+	}
+	return false
+}
+
+// Clear removes all the shapes of this canvas.
+func (c *Canvas) Clear() {
+
+	for i := range c.shapes {
+		c.shapes[i] = nil
+	}
+	c.shapes = c.shapes[:0]
+	c.changed = true
+}
+
+// Shapes returns the shapes of this canvas in drawing order.
+func (c *Canvas) Shapes() []*CanvasShape {
+
+	return c.shapes
+}
+
+// Save pushes the current drawing transform to a stack.
+func (c *Canvas) Save() {
+
+	c.stack = append(c.stack, c.transform)
+}
+
+// Restore pops the last saved drawing transform from the stack and makes it the current one.
+func (c *Canvas) Restore() {
+
+	if len(c.stack) == 0 {
+		return
+	}
+	c.transform = c.stack[len(c.stack)-1]
+	c.stack = c.stack[:len(c.stack)-1]
+}
+
+// TranslateDrawing translates the current drawing transform by the specified offsets.
+func (c *Canvas) TranslateDrawing(x, y float32) {
+
+	var m math32.Matrix3
+	m.Set(1, 0, x, 0, 1, y, 0, 0, 1)
+	c.transform.Multiply(&m)
+}
+
+// RotateDrawing rotates the current drawing transform by the specified angle in radians,
+// clockwise on the screen.
+func (c *Canvas) RotateDrawing(angle float32) {
+
+	var m math32.Matrix3
+	cos, sin := math32.Cos(angle), math32.Sin(angle)
+	m.Set(cos, -sin, 0, sin, cos, 0, 0, 0, 1)
+	c.transform.Multiply(&m)
+}
+
+// ScaleDrawing scales the current drawing transform by the specified factors.
+func (c *Canvas) ScaleDrawing(sx, sy float32) {
+
+	var m math32.Matrix3
+	m.Set(sx, 0, 0, 0, sy, 0, 0, 0, 1)
+	c.transform.Multiply(&m)
+}
+
+// SetDrawingTransform sets the current drawing transform from the specified 2D affine matrix.
+func (c *Canvas) SetDrawingTransform(m *math32.Matrix3) {
+
+	c.transform = *m
+}
+
+// ResetDrawingTransform sets the current drawing transform to the identity.
+func (c *Canvas) ResetDrawingTransform() {
+
+	c.transform.Identity()
+}
+
+// DrawingTransform returns the current drawing transform.
+func (c *Canvas) DrawingTransform() math32.Matrix3 {
+
+	return c.transform
+}
+
+// addTriangle appends a triangle to this shape.
+func (s *CanvasShape) addTriangle(a, b, c math32.Vector2) {
+
+	s.tris = append(s.tris, a, b, c)
+}
+
+// SetPaint sets the paint of this shape.
+func (s *CanvasShape) SetPaint(paint Paint) *CanvasShape {
+
+	s.paint = paint
+	s.canvas.changed = true
+	return s
+}
+
+// Paint returns the paint of this shape.
+func (s *CanvasShape) Paint() Paint {
+
+	return s.paint
+}
+
+// SetVisible sets the visibility of this shape.
+func (s *CanvasShape) SetVisible(state bool) *CanvasShape {
+
+	s.visible = state
+	s.canvas.changed = true
+	return s
+}
+
+// Visible returns the visibility of this shape.
+func (s *CanvasShape) Visible() bool {
+
+	return s.visible
+}
+
+// rebuild rebuilds the vertices of the visible shapes and the gradient color ramps.
+func (c *Canvas) rebuild() {
+
+	c.changed = false
+	count := 0
+	rows := 0
+	for _, s := range c.shapes {
+		if s.visible {
+			count += len(s.tris)
+			if s.paint.IsGradient() {
+				rows++
+			}
+		}
+	}
+
+	// Builds the gradient color ramps texture
+	var rgba *image.RGBA
+	if rows > 0 {
+		rgba = image.NewRGBA(image.Rect(0, 0, canvasRampWidth, rows))
+	}
+	const stride = 11
+	buf := math32.NewArrayF32(0, count*stride)
+	row := 0
+	for _, s := range c.shapes {
+		if !s.visible {
+			continue
+		}
+		p := &s.paint
+		var v float32
+		if p.kind != paintSolid {
+			for x := 0; x < canvasRampWidth; x++ {
+				col := p.ColorAt(float32(x) / (canvasRampWidth - 1))
+				rgba.SetRGBA(x, row, color.RGBA{
+					R: uint8(math32.Clamp(col.R, 0, 1) * 255),
+					G: uint8(math32.Clamp(col.G, 0, 1) * 255),
+					B: uint8(math32.Clamp(col.B, 0, 1) * 255),
+					A: uint8(math32.Clamp(col.A, 0, 1) * 255),
+				})
+			}
+			v = (float32(row) + 0.5) / float32(rows)
+			row++
+		}
+		// Gradient parameters are affine in the position, so they are computed
+		// for each vertex in the gradient space and interpolated by OpenGL
+		for _, pos := range s.tris {
+			var u0, u1 float32
+			if p.kind != paintSolid {
+				up := transformPoint(&s.inverse, pos)
+				d := up.Sub(&p.p0)
+				if p.kind == paintLinear {
+					dir := p.p1.Clone().Sub(&p.p0)
+					if lsq := dir.LengthSq(); lsq > 0 {
+						u0 = d.Dot(dir) / lsq
+					}
+				} else if p.radius > 0 {
+					u0 = d.X / p.radius
+					u1 = d.Y / p.radius
+				}
+			}
+			buf.Append(pos.X, pos.Y, 0, p.color.R, p.color.G, p.color.B, p.color.A, u0, u1, v, float32(p.kind))
+		}
+	}
+	c.vbo.SetBuffer(buf)
+
+	if rgba == nil {
+		return
+	}
+	if c.ramp == nil {
+		c.ramp = texture.NewTexture2DFromRGBA(rgba)
+		c.ramp.SetGenMipmap(false)
+		c.ramp.SetMinFilter(gls.LINEAR)
+		c.mat.AddTexture(c.ramp)
+	} else {
+		c.ramp.SetFromRGBA(rgba)
+	}
+}
+
+// RenderSetup is called by the renderer before drawing this graphic
+// It overrides the original panel RenderSetup
+// Calculates the model matrix and transfer to OpenGL.
+func (c *Canvas) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+
+	// Get scale from UI units to pixels (for HiDPI support and UI scaling)
+	sX, sY := renderScale()
+
+	// Get the current viewport width and height
+	_, _, width, height := gs.GetViewport()
+
+	// Converts the canvas pixel coordinates of the vertices to clip coordinates
+	fX := 2 * float32(sX) / float32(width)
+	fY := 2 * float32(sY) / float32(height)
+	var mm math32.Matrix4
+	mm.Set(
+		fX, 0, 0, fX*c.pospix.X-1,
+		0, -fY, 0, 1-fY*c.pospix.Y,
+		0, 0, 1, c.Position().Z,
+		0, 0, 0, 1,
+	)
+	location := c.uniMatrix.Location(gs)
+	gs.UniformMatrix4fv(location, 1, false, &mm[0])
+
+	// Sets the visible bounds in OpenGL window coordinates and transfer to shader
+	location = c.uniBounds.Location(gs)
+	gs.Uniform4f(location, c.xmin*float32(sX), float32(height)-c.ymin*float32(sY),
+		(c.xmax-c.xmin)*float32(sX), (c.ymax-c.ymin)*float32(sY))
+}
+
+// Canvas material
+type canvasMaterial struct {
+	material.Material         // Embedded material
+	canvas            *Canvas // Canvas using this material
+}
+
+func (cm *canvasMaterial) Init(canvas *Canvas) {
+
+	cm.Material.Init()
+	cm.SetShader("shaderCanvas")
+	cm.SetShaderUnique(true)
+	cm.SetUseLights(material.UseLightNone)
+	cm.SetTransparent(true)
+	cm.SetSide(material.SideDouble)
+	cm.canvas = canvas
+}
+
+// RenderSetup rebuilds the canvas vertices if its shapes changed before
+// the material textures and the geometry are transferred.
+func (cm *canvasMaterial) RenderSetup(gs *gls.GLS) {
+
+	if cm.canvas.changed {
+		cm.canvas.rebuild()
+	}
+	cm.Material.RenderSetup(gs)
+}
+
+// Vertex Shader template
+const shaderCanvasVertex = `
+// Vertex attributes
+#include <attributes>
+in vec4 CanvasColor;
+in vec4 CanvasPaint;
+
+// Input uniforms
+uniform mat4 ModelMatrix;
+
+// Outputs for fragment shader
+out vec4 Color;
+out vec4 Paint;
+
+void main() {
+
+    Color = CanvasColor;
+    Paint = CanvasPaint;
+    gl_Position = ModelMatrix * vec4(VertexPosition.xyz, 1);
+}
+`
+
+// Fragment Shader template
+const shaderCanvasFrag = `
+precision highp float;
+
+// Inputs from vertex shader
+in vec4 Color;
+in vec4 Paint;
+
+// Input uniforms
+uniform sampler2D MatTexture;
+uniform vec4 Bounds;
+
+// Output
+out vec4 FragColor;
+
+void main() {
+
+    // Discard fragment outside of the received bounds in OpenGL window pixel coordinates
+    if (gl_FragCoord.x < Bounds[0] || gl_FragCoord.x > Bounds[0] + Bounds[2]) {
+        discard;
+    }
+    if (gl_FragCoord.y > Bounds[1] || gl_FragCoord.y < Bounds[1] - Bounds[3]) {
+        discard;
+    }
+
+    // Paint[3] - kind (0 solid, 1 linear gradient, 2 radial gradient)
+    // Paint[0] - linear gradient offset or radial gradient x
+    // Paint[1] - radial gradient y
+    // Paint[2] - gradient color ramp row
+    vec4 color = Color;
+    if (Paint[3] > 0.5) {
+        float t = Paint[0];
+        if (Paint[3] > 1.5) {
+            t = length(Paint.xy);
+        }
+        t = clamp(t, 0.0, 1.0);
+        color *= texture(MatTexture, vec2((t * 255.0 + 0.5) / 256.0, Paint[2]));
+    }
+    FragColor = color;
+}
+`
@@ -0,0 +1,664 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"sort"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Path is a sequence of subpaths made of lines and Bézier curves which
+// can be filled or stroked in a Canvas. Path coordinates are in pixels
+// relative to the top left corner of the canvas, before its transform.
+type Path struct {
+	cmds  []pathCmd      // Path commands
+	start math32.Vector2 // Start point of the current subpath
+	last  math32.Vector2 // Current point
+	open  bool           // A subpath was started
+}
+
+// pathOp is the type of a path command.
+type pathOp int
+
+const (
+	pathMoveTo = pathOp(iota)
+	pathLineTo
+	pathQuadTo
+	pathCubicTo
+	pathClose
+)
+
+// pathCmd is a path command with its points.
+type pathCmd struct {
+	op  pathOp
+	pts [3]math32.Vector2
+}
+
+// polyline is a flattened subpath.
+type polyline struct {
+	pts    []math32.Vector2 // Points of the polyline
+	closed bool             // The polyline was explicitly closed
+}
+
+// canvasTolerance is the maximum distance in pixels between flattened curves and the polylines approximating them.
+const canvasTolerance = 0.25
+
+// kappa is the distance of the control points of a cubic Bézier approximating a quarter circle of unit radius.
+const kappa = 0.5522847498
+
+// NewPath creates and returns a pointer to a new empty path.
+func NewPath() *Path {
+
+	return new(Path)
+}
+
+// Reset removes all the subpaths of this path.
+func (p *Path) Reset() *Path {
+
+	p.cmds = p.cmds[:0]
+	p.open = false
+	return p
+}
+
+// IsEmpty returns whether this path has no commands.
+func (p *Path) IsEmpty() bool {
+
+	return len(p.cmds) == 0
+}
+
+// MoveTo starts a new subpath at the specified point.
+func (p *Path) MoveTo(x, y float32) *Path {
+
+	p.start = math32.Vector2{X: x, Y: y}
+	p.last = p.start
+	p.open = true
+	p.cmds = append(p.cmds, pathCmd{op: pathMoveTo, pts: [3]math32.Vector2{p.start}})
+	return p
+}
+
+// LineTo adds a straight line from the current point to the specified point.
+// Starts a new subpath at the point if there is no current point.
+func (p *Path) LineTo(x, y float32) *Path {
+
+	if !p.open {
+		return p.MoveTo(x, y)
+	}
+	p.last = math32.Vector2{X: x, Y: y}
+	p.cmds = append(p.cmds, pathCmd{op: pathLineTo, pts: [3]math32.Vector2{p.last}})
+	return p
+}
+
+// QuadTo adds a quadratic Bézier curve from the current point to
+// the point (x, y) with the control point (cx, cy).
+func (p *Path) QuadTo(cx, cy, x, y float32) *Path {
+
+	if !p.open {
+		p.MoveTo(cx, cy)
+	}
+	p.last = math32.Vector2{X: x, Y: y}
+	p.cmds = append(p.cmds, pathCmd{op: pathQuadTo, pts: [3]math32.Vector2{{X: cx, Y: cy}, p.last}})
+	return p
+}
+
+// CubicTo adds a cubic Bézier curve from the current point to the
+// point (x, y) with the control points (c1x, c1y) and (c2x, c2y).
+func (p *Path) CubicTo(c1x, c1y, c2x, c2y, x, y float32) *Path {
+
+	if !p.open {
+		p.MoveTo(c1x, c1y)
+	}
+	p.last = math32.Vector2{X: x, Y: y}
+	p.cmds = append(p.cmds, pathCmd{op: pathCubicTo, pts: [3]math32.Vector2{{X: c1x, Y: c1y}, {X: c2x, Y: c2y}, p.last}})
+	return p
+}
+
+// Close closes the current subpath with a straight line to its start point.
+func (p *Path) Close() *Path {
+
+	if !p.open {
+		return p
+	}
+	p.cmds = append(p.cmds, pathCmd{op: pathClose})
+	p.last = p.start
+	p.open = false
+	return p
+}
+
+// Arc adds a circular arc with the specified center and radius from the start angle
+// to the end angle in radians, clockwise on the screen if the end angle is greater.
+// A straight line is added from the current point to the start of the arc.
+func (p *Path) Arc(cx, cy, radius, start, end float32) *Path {
+
+	return p.EllipticArc(cx, cy, radius, radius, start, end)
+}
+
+// EllipticArc adds an elliptical arc with the specified center and radii from the start
+// angle to the end angle in radians, clockwise on the screen if the end angle is greater.
+// A straight line is added from the current point to the start of the arc.
+func (p *Path) EllipticArc(cx, cy, rx, ry, start, end float32) *Path {
+
+	sx := cx + rx*math32.Cos(start)
+	sy := cy + ry*math32.Sin(start)
+	if p.open {
+		p.LineTo(sx, sy)
+	} else {
+		p.MoveTo(sx, sy)
+	}
+	// Splits the arc in segments of at most a quarter turn approximated by cubic Béziers
+	sweep := end - start
+	n := int(math32.Ceil(math32.Abs(sweep) / (math32.Pi / 2)))
+	if n == 0 {
+		return p
+	}
+	step := sweep / float32(n)
+	k := 4.0 / 3.0 * math32.Tan(step/4)
+	a0 := start
+	for i := 0; i < n; i++ {
+		a1 := a0 + step
+		cos0, sin0 := math32.Cos(a0), math32.Sin(a0)
+		cos1, sin1 := math32.Cos(a1), math32.Sin(a1)
+		p.CubicTo(
+			cx+rx*(cos0-k*sin0), cy+ry*(sin0+k*cos0),
+			cx+rx*(cos1+k*sin1), cy+ry*(sin1-k*cos1),
+			cx+rx*cos1, cy+ry*sin1,
+		)
+		a0 = a1
+	}
+	return p
+}
+
+// Rect adds a closed rectangle subpath with the specified top left corner and size.
+func (p *Path) Rect(x, y, width, height float32) *Path {
+
+	return p.MoveTo(x, y).LineTo(x+width, y).LineTo(x+width, y+height).LineTo(x, y+height).Close()
+}
+
+// RoundedRect adds a closed rectangle subpath with the specified top left
+// corner and size with corners rounded with the specified radius.
+func (p *Path) RoundedRect(x, y, width, height, radius float32) *Path {
+
+	radius = math32.Min(radius, math32.Min(width, height)/2)
+	if radius <= 0 {
+		return p.Rect(x, y, width, height)
+	}
+	c := radius * (1 - kappa)
+	p.MoveTo(x+radius, y)
+	p.LineTo(x+width-radius, y)
+	p.CubicTo(x+width-c, y, x+width, y+c, x+width, y+radius)
+	p.LineTo(x+width, y+height-radius)
+	p.CubicTo(x+width, y+height-c, x+width-c, y+height, x+width-radius, y+height)
+	p.LineTo(x+radius, y+height)
+	p.CubicTo(x+c, y+height, x, y+height-c, x, y+height-radius)
+	p.LineTo(x, y+radius)
+	p.CubicTo(x, y+c, x+c, y, x+radius, y)
+	return p.Close()
+}
+
+// Ellipse adds a closed ellipse subpath with the specified center and radii.
+func (p *Path) Ellipse(cx, cy, rx, ry float32) *Path {
+
+	kx := rx * kappa
+	ky := ry * kappa
+	p.MoveTo(cx+rx, cy)
+	p.CubicTo(cx+rx, cy+ky, cx+kx, cy+ry, cx, cy+ry)
+	p.CubicTo(cx-kx, cy+ry, cx-rx, cy+ky, cx-rx, cy)
+	p.CubicTo(cx-rx, cy-ky, cx-kx, cy-ry, cx, cy-ry)
+	p.CubicTo(cx+kx, cy-ry, cx+rx, cy-ky, cx+rx, cy)
+	return p.Close()
+}
+
+// Circle adds a closed circle subpath with the specified center and radius.
+func (p *Path) Circle(cx, cy, radius float32) *Path {
+
+	return p.Ellipse(cx, cy, radius, radius)
+}
+
+// flatten transforms this path by the specified matrix and
+// returns its subpaths approximated by polylines.
+func (p *Path) flatten(m *math32.Matrix3) []polyline {
+
+	var lines []polyline
+	var cur *polyline
+	var last math32.Vector2
+	for i := range p.cmds {
+		cmd := &p.cmds[i]
+		switch cmd.op {
+		case pathMoveTo:
+			last = transformPoint(m, cmd.pts[0])
+			lines = append(lines, polyline{pts: []math32.Vector2{last}})
+			cur = &lines[len(lines)-1]
+		case pathLineTo:
+			last = transformPoint(m, cmd.pts[0])
+			cur.pts = append(cur.pts, last)
+		case pathQuadTo:
+			c := transformPoint(m, cmd.pts[0])
+			end := transformPoint(m, cmd.pts[1])
+			dd := last.Clone().Sub(c.Clone().MultiplyScalar(2)).Add(&end).Length()
+			n := curveSegments(dd / (4 * canvasTolerance))
+			for j := 1; j <= n; j++ {
+				t := float32(j) / float32(n)
+				u := 1 - t
+				cur.pts = append(cur.pts, math32.Vector2{
+					X: u*u*last.X + 2*u*t*c.X + t*t*end.X,
+					Y: u*u*last.Y + 2*u*t*c.Y + t*t*end.Y,
+				})
+			}
+			last = end
+		case pathCubicTo:
+			c1 := transformPoint(m, cmd.pts[0])
+			c2 := transformPoint(m, cmd.pts[1])
+			end := transformPoint(m, cmd.pts[2])
+			dd1 := last.Clone().Sub(c1.Clone().MultiplyScalar(2)).Add(&c2).Length()
+			dd2 := c1.Clone().Sub(c2.Clone().MultiplyScalar(2)).Add(&end).Length()
+			n := curveSegments(3 * math32.Max(dd1, dd2) / (4 * canvasTolerance))
+			for j := 1; j <= n; j++ {
+				t := float32(j) / float32(n)
+				u := 1 - t
+				cur.pts = append(cur.pts, math32.Vector2{
+					X: u*u*u*last.X + 3*u*u*t*c1.X + 3*u*t*t*c2.X + t*t*t*end.X,
+					Y: u*u*u*last.Y + 3*u*u*t*c1.Y + 3*u*t*t*c2.Y + t*t*t*end.Y,
+				})
+			}
+			last = end
+		case pathClose:
+			cur.closed = true
+			last = cur.pts[0]
+		}
+	}
+	// Removes consecutive duplicated points
+	for i := range lines {
+		pts := lines[i].pts[:1]
+		for _, pt := range lines[i].pts[1:] {
+			if pt.DistanceToSquared(&pts[len(pts)-1]) > 1e-8 {
+				pts = append(pts, pt)
+			}
+		}
+		if lines[i].closed && len(pts) > 1 && pts[0].DistanceToSquared(&pts[len(pts)-1]) <= 1e-8 {
+			pts = pts[:len(pts)-1]
+		}
+		lines[i].pts = pts
+	}
+	return lines
+}
+
+// curveSegments returns the number of segments used to flatten a curve from the square of the ideal number.
+func curveSegments(sq float32) int {
+
+	n := int(math32.Ceil(math32.Sqrt(sq)))
+	if n < 1 {
+		return 1
+	}
+	if n > 100 {
+		return 100
+	}
+	return n
+}
+
+// transformPoint returns the specified point transformed by the specified 2D affine matrix.
+func transformPoint(m *math32.Matrix3, p math32.Vector2) math32.Vector2 {
+
+	return math32.Vector2{
+		X: m[0]*p.X + m[3]*p.Y + m[6],
+		Y: m[1]*p.X + m[4]*p.Y + m[7],
+	}
+}
+
+// fillPolylines triangulates the area enclosed by the specified polylines calling the
+// specified function for each triangle. Polylines inside an odd number of other
+// polylines are holes. The polylines must not intersect each other or themselves.
+func fillPolylines(lines []polyline, tri func(a, b, c math32.Vector2)) {
+
+	polys := make([][]math32.Vector2, 0, len(lines))
+	for i := range lines {
+		if len(lines[i].pts) >= 3 && math32.Abs(polygonArea(lines[i].pts)) > 1e-6 {
+			polys = append(polys, lines[i].pts)
+		}
+	}
+	// Finds the nesting depth and the innermost container of each polygon
+	depth := make([]int, len(polys))
+	parent := make([]int, len(polys))
+	for i := range polys {
+		parent[i] = -1
+		for j := range polys {
+			if i != j && pointInPolygon(polys[i][0], polys[j]) {
+				depth[i]++
+			}
+		}
+	}
+	for i := range polys {
+		for j := range polys {
+			if depth[j] == depth[i]-1 && i != j && pointInPolygon(polys[i][0], polys[j]) {
+				parent[i] = j
+			}
+		}
+	}
+	// Triangulates each outer polygon with its holes
+	for i := range polys {
+		if depth[i]%2 != 0 {
+			continue
+		}
+		outer := orientPolygon(polys[i], true)
+		var holes [][]math32.Vector2
+		for j := range polys {
+			if parent[j] == i && depth[j]%2 != 0 {
+				holes = append(holes, orientPolygon(polys[j], false))
+			}
+		}
+		earClip(bridgeHoles(outer, holes), tri)
+	}
+}
+
+// polygonArea returns the signed area of the specified polygon,
+// positive if it is counterclockwise in a y up coordinate system.
+func polygonArea(pts []math32.Vector2) float32 {
+
+	var area float32
+	for i := range pts {
+		j := (i + 1) % len(pts)
+		area += pts[i].X*pts[j].Y - pts[j].X*pts[i].Y
+	}
+	return area / 2
+}
+
+// orientPolygon returns a copy of the specified polygon with positive area if positive is true or negative otherwise.
+func orientPolygon(pts []math32.Vector2, positive bool) []math32.Vector2 {
+
+	res := make([]math32.Vector2, len(pts))
+	if (polygonArea(pts) > 0) == positive {
+		copy(res, pts)
+		return res
+	}
+	for i := range pts {
+		res[i] = pts[len(pts)-1-i]
+	}
+	return res
+}
+
+// pointInPolygon returns whether the specified point is inside the specified polygon.
+func pointInPolygon(p math32.Vector2, pts []math32.Vector2) bool {
+
+	inside := false
+	for i, j := 0, len(pts)-1; i < len(pts); j, i = i, i+1 {
+		a, b := pts[i], pts[j]
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// cross2 returns the z component of the cross product of the vectors (b - a) and (c - a).
+func cross2(a, b, c math32.Vector2) float32 {
+
+	return (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+}
+
+// pointInTriangle returns whether the point p is inside or on the border of the positive triangle abc.
+func pointInTriangle(p, a, b, c math32.Vector2) bool {
+
+	return cross2(a, b, p) >= 0 && cross2(b, c, p) >= 0 && cross2(c, a, p) >= 0
+}
+
+// bridgeHoles merges the specified holes, with negative area, into the specified outer polygon,
+// with positive area, connecting each hole to a visible vertex of the outer polygon.
+func bridgeHoles(outer []math32.Vector2, holes [][]math32.Vector2) []math32.Vector2 {
+
+	// Returns the index of the vertex with maximum x of the specified polygon
+	maxX := func(pts []math32.Vector2) int {
+		mi := 0
+		for i := range pts {
+			if pts[i].X > pts[mi].X {
+				mi = i
+			}
+		}
+		return mi
+	}
+	// Bridges the holes from right to left so each bridge sees the previous ones
+	sort.Slice(holes, func(i, j int) bool {
+		return holes[i][maxX(holes[i])].X > holes[j][maxX(holes[j])].X
+	})
+	for _, hole := range holes {
+		mi := maxX(hole)
+		m := hole[mi]
+		// Casts a ray from the hole vertex to the right and finds the nearest outer edge intersected
+		pi := -1
+		var ix float32
+		for i := range outer {
+			a, b := outer[i], outer[(i+1)%len(outer)]
+			if (a.Y > m.Y) == (b.Y > m.Y) {
+				continue
+			}
+			x := a.X + (m.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y)
+			if x < m.X || (pi >= 0 && x >= ix) {
+				continue
+			}
+			ix = x
+			pi = i
+			if b.X > a.X {
+				pi = (i + 1) % len(outer)
+			}
+		}
+		if pi < 0 {
+			continue
+		}
+		// Outer vertices inside the triangle formed by the hole vertex, the intersection and the
+		// candidate would block the bridge, so the one with the smallest angle to the ray is used
+		cand := outer[pi]
+		in := math32.Vector2{X: ix, Y: m.Y}
+		a, b, c := m, in, cand
+		if cross2(a, b, c) < 0 {
+			b, c = c, b
+		}
+		best := float32(-1)
+		for i, v := range outer {
+			if i == pi || v == cand || !pointInTriangle(v, a, b, c) {
+				continue
+			}
+			d := v.Clone().Sub(&m)
+			cos := d.X / d.Length()
+			if cos > best {
+				best = cos
+				pi = i
+			}
+		}
+		// Splices the hole in the outer polygon at the bridge vertex
+		merged := make([]math32.Vector2, 0, len(outer)+len(hole)+2)
+		merged = append(merged, outer[:pi+1]...)
+		merged = append(merged, hole[mi:]...)
+		merged = append(merged, hole[:mi+1]...)
+		merged = append(merged, outer[pi:]...)
+		outer = merged
+	}
+	return outer
+}
+
+// earClip triangulates the specified simple polygon with positive area by ear clipping,
+// calling the specified function for each triangle, also with positive area.
+func earClip(pts []math32.Vector2, tri func(a, b, c math32.Vector2)) {
+
+	n := len(pts)
+	if n < 3 {
+		return
+	}
+	prev := make([]int, n)
+	next := make([]int, n)
+	for i := range pts {
+		prev[i] = (i + n - 1) % n
+		next[i] = (i + 1) % n
+	}
+	// Returns whether the vertex i is an ear which can be clipped
+	isEar := func(i int) bool {
+		a, b, c := pts[prev[i]], pts[i], pts[next[i]]
+		if cross2(a, b, c) <= 0 {
+			return false
+		}
+		for j := next[next[i]]; j != prev[i]; j = next[j] {
+			p := pts[j]
+			if p == a || p == b || p == c {
+				continue
+			}
+			if pointInTriangle(p, a, b, c) {
+				return false
+			}
+		}
+		return true
+	}
+	i := 0
+	stall := 0
+	for n > 3 {
+		if isEar(i) || stall >= n {
+			// Degenerate polygons without ears are clipped anyway to ensure termination
+			if cross2(pts[prev[i]], pts[i], pts[next[i]]) > 0 {
+				tri(pts[prev[i]], pts[i], pts[next[i]])
+			}
+			next[prev[i]] = next[i]
+			prev[next[i]] = prev[i]
+			n--
+			i = prev[i]
+			stall = 0
+			continue
+		}
+		i = next[i]
+		stall++
+	}
+	if cross2(pts[prev[i]], pts[i], pts[next[i]]) > 0 {
+		tri(pts[prev[i]], pts[i], pts[next[i]])
+	}
+}
+
+// strokePolyline triangulates the outline of the specified polyline stroked with
+// the specified style and half width, calling the specified function for each triangle.
+func strokePolyline(line polyline, style *StrokeStyle, hw float32, tri func(a, b, c math32.Vector2)) {
+
+	pts := line.pts
+	closed := line.closed && len(pts) > 2
+	if len(pts) < 2 {
+		// A single point only has caps
+		if len(pts) == 1 && style.Cap != LineCapButt {
+			d := math32.Vector2{X: hw}
+			n := math32.Vector2{Y: hw}
+			if style.Cap == LineCapRound {
+				strokeFan(pts[0], n, *n.Clone().Negate(), hw, true, tri)
+				strokeFan(pts[0], *n.Clone().Negate(), n, hw, true, tri)
+			} else {
+				strokeQuad(pts[0].Clone().Sub(&d), pts[0].Clone().Add(&d), hw, tri)
+			}
+		}
+		return
+	}
+	segs := len(pts) - 1
+	if closed {
+		segs = len(pts)
+	}
+	// Draws a quad along each segment extending the ends with square caps
+	for i := 0; i < segs; i++ {
+		a := pts[i]
+		b := pts[(i+1)%len(pts)]
+		if !closed && style.Cap == LineCapSquare {
+			d := b.Clone().Sub(&a).SetLength(hw)
+			if i == 0 {
+				a.Sub(d)
+			}
+			if i == segs-1 {
+				b.Add(d)
+			}
+		}
+		strokeQuad(&a, &b, hw, tri)
+	}
+	// Draws the joins between consecutive segments
+	for i := 0; i < len(pts); i++ {
+		if !closed && (i == 0 || i == len(pts)-1) {
+			continue
+		}
+		p := pts[i]
+		d0 := p.Clone().Sub(&pts[(i+len(pts)-1)%len(pts)]).Normalize()
+		d1 := pts[(i+1)%len(pts)].Clone().Sub(&p).Normalize()
+		turn := d0.X*d1.Y - d0.Y*d1.X
+		if math32.Abs(turn) < 1e-6 && d0.Dot(d1) > 0 {
+			continue
+		}
+		// Joins the offsets on the outer side of the turn
+		s := hw
+		if turn > 0 {
+			s = -hw
+		}
+		n0 := math32.Vector2{X: -d0.Y * s, Y: d0.X * s}
+		n1 := math32.Vector2{X: -d1.Y * s, Y: d1.X * s}
+		a := p.Clone().Add(&n0)
+		b := p.Clone().Add(&n1)
+		switch style.Join {
+		case LineJoinRound:
+			strokeFan(p, n0, n1, hw, turn > 0, tri)
+			continue
+		case LineJoinMiter:
+			mid := n0.Clone().Add(&n1).Normalize()
+			cos := mid.Dot(&n0) / hw
+			limit := style.MiterLimit
+			if limit <= 0 {
+				limit = 10
+			}
+			if cos > 1/limit {
+				tip := p.Clone().Add(mid.MultiplyScalar(hw / cos))
+				tri(p, *a, *tip)
+				tri(p, *tip, *b)
+				continue
+			}
+		}
+		tri(p, *a, *b)
+	}
+	if closed || style.Cap != LineCapRound {
+		return
+	}
+	// Draws the round caps
+	first := pts[1].Clone().Sub(&pts[0]).SetLength(hw)
+	n := math32.Vector2{X: -first.Y, Y: first.X}
+	strokeFan(pts[0], n, *n.Clone().Negate(), hw, true, tri)
+	last := pts[len(pts)-1].Clone().Sub(&pts[len(pts)-2]).SetLength(hw)
+	n = math32.Vector2{X: last.Y, Y: -last.X}
+	strokeFan(pts[len(pts)-1], n, *n.Clone().Negate(), hw, true, tri)
+}
+
+// strokeQuad calls the specified function with the two triangles of the
+// rectangle with the specified half width around the segment from a to b.
+func strokeQuad(a, b *math32.Vector2, hw float32, tri func(a, b, c math32.Vector2)) {
+
+	d := b.Clone().Sub(a).SetLength(hw)
+	n := math32.Vector2{X: -d.Y, Y: d.X}
+	tri(*a.Clone().Add(&n), *a.Clone().Sub(&n), *b.Clone().Sub(&n))
+	tri(*a.Clone().Add(&n), *b.Clone().Sub(&n), *b.Clone().Add(&n))
+}
+
+// strokeFan calls the specified function with the triangles of the circular sector centered at c
+// from the offset v0 to the offset v1 of the specified radius, turning in the positive direction
+// of the angles if positive is true, or in the negative one otherwise.
+func strokeFan(c, v0, v1 math32.Vector2, r float32, positive bool, tri func(a, b, c math32.Vector2)) {
+
+	a0 := math32.Atan2(v0.Y, v0.X)
+	a1 := math32.Atan2(v1.Y, v1.X)
+	if positive {
+		for a1 <= a0 {
+			a1 += 2 * math32.Pi
+		}
+	} else {
+		for a1 >= a0 {
+			a1 -= 2 * math32.Pi
+		}
+	}
+	n := 1
+	if r > canvasTolerance {
+		n = int(math32.Ceil(math32.Abs(a1-a0) / (2 * math32.Acos(1-canvasTolerance/r))))
+	}
+	if n > 64 {
+		n = 64
+	}
+	prev := math32.Vector2{X: c.X + v0.X, Y: c.Y + v0.Y}
+	for i := 1; i <= n; i++ {
+		a := a0 + (a1-a0)*float32(i)/float32(n)
+		pt := math32.Vector2{X: c.X + r*math32.Cos(a), Y: c.Y + r*math32.Sin(a)}
+		tri(c, prev, pt)
+		prev = pt
+	}
+}
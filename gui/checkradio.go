@@ -12,23 +12,38 @@ import (
 const (
 	checkON  = string(icon.CheckBox)
 	checkOFF = string(icon.CheckBoxOutlineBlank)
+	checkIND = string(icon.IndeterminateCheckBox)
 	radioON  = string(icon.RadioButtonChecked)
 	radioOFF = string(icon.RadioButtonUnchecked)
 )
 
+// CheckState is the state of a checkbox, which may be indeterminate
+// when it summarizes a set of options which are partly checked.
+type CheckState int
+
+// The states of a checkbox
+const (
+	Unchecked     = CheckState(iota) // Not checked
+	Checked                          // Checked
+	Indeterminate                    // Neither checked nor unchecked
+)
+
 // CheckRadio is a GUI element that can be either a checkbox or a radio button
 type CheckRadio struct {
-	Panel             // Embedded panel
-	Label      *Label // Text label
-	icon       *Label
-	styles     *CheckRadioStyles
-	check      bool
-	group      string // current group name
-	cursorOver bool
-	state      bool
-	codeON     string
-	codeOFF    string
-	subroot    bool // indicates root subcription
+	Panel                // Embedded panel
+	Label         *Label // Text label
+	icon          *Label
+	styles        *CheckRadioStyles
+	check         bool
+	group         string       // current group name
+	radioGroup    *RadioGroup  // radio group object (nil if none)
+	tracker       StateTracker // tracker of the pseudo-state
+	state         bool
+	indeterminate bool // indeterminate state of a checkbox
+	triState      bool // the user cycles through the indeterminate state
+	codeON        string
+	codeOFF       string
+	subroot       bool // indicates root subcription
 }
 
 // CheckRadioStyle contains the styling of a CheckRadio
@@ -39,6 +54,7 @@ type CheckRadioStyles struct {
 	Normal   CheckRadioStyle
 	Over     CheckRadioStyle
 	Focus    CheckRadioStyle
+	Pressed  CheckRadioStyle
 	Disabled CheckRadioStyle
 }
 
@@ -79,10 +95,8 @@ func newCheckRadio(check bool, text string) *CheckRadio {
 
 	// Subscribe to events
 	cb.Panel.Subscribe(OnKeyDown, cb.onKey)
-	cb.Panel.Subscribe(OnCursorEnter, cb.onCursor)
-	cb.Panel.Subscribe(OnCursorLeave, cb.onCursor)
 	cb.Panel.Subscribe(OnMouseDown, cb.onMouse)
-	cb.Panel.Subscribe(OnEnable, func(evname string, ev interface{}) { cb.update() })
+	cb.tracker.Init(&cb.Panel, cb.update)
 
 	// Creates label
 	cb.Label = NewLabel(text)
@@ -98,22 +112,84 @@ func newCheckRadio(check bool, text string) *CheckRadio {
 	return cb
 }
 
-// Value returns the current state of the checkbox
+// Value returns whether the checkbox is checked, which is false if it is indeterminate
 func (cb *CheckRadio) Value() bool {
 
-	return cb.state
+	return cb.state && !cb.indeterminate
 }
 
-// SetValue sets the current state of the checkbox
+// SetValue sets the current state of the checkbox, which is no longer indeterminate.
+// Checking a radio button of a RadioGroup unchecks the other buttons of the group.
 func (cb *CheckRadio) SetValue(state bool) *CheckRadio {
 
-	if state == cb.state {
+	if state && cb.radioGroup != nil {
+		cb.radioGroup.selectButton(cb)
+		return cb
+	}
+	cb.setState(state, false)
+	return cb
+}
+
+// CheckState returns the current state of the checkbox, including the indeterminate state.
+func (cb *CheckRadio) CheckState() CheckState {
+
+	if cb.indeterminate {
+		return Indeterminate
+	}
+	if cb.state {
+		return Checked
+	}
+	return Unchecked
+}
+
+// SetCheckState sets the current state of the checkbox, which may be indeterminate
+// for checkboxes, showing a dash, and dispatches OnChange if it changed.
+func (cb *CheckRadio) SetCheckState(state CheckState) *CheckRadio {
+
+	if state == Indeterminate && cb.check {
+		cb.setState(cb.state, true)
 		return cb
 	}
+	return cb.SetValue(state == Checked)
+}
+
+// Indeterminate returns whether the checkbox is in the indeterminate state.
+func (cb *CheckRadio) Indeterminate() bool {
+
+	return cb.indeterminate
+}
+
+// SetTriState sets whether the user cycles the checkbox through the unchecked, checked and
+// indeterminate states. Otherwise, the indeterminate state is only set by the application
+// and the user checks an indeterminate checkbox. The default is false.
+func (cb *CheckRadio) SetTriState(triState bool) *CheckRadio {
+
+	cb.triState = triState
+	return cb
+}
+
+// TriState returns whether the user cycles the checkbox through the indeterminate state.
+func (cb *CheckRadio) TriState() bool {
+
+	return cb.triState
+}
+
+// RadioGroup returns the radio group object of this button or nil if none.
+func (cb *CheckRadio) RadioGroup() *RadioGroup {
+
+	return cb.radioGroup
+}
+
+// setState sets the checked and indeterminate states, updating the icon and dispatching OnChange if they changed.
+func (cb *CheckRadio) setState(state, indeterminate bool) {
+
+	if state == cb.state && indeterminate == cb.indeterminate {
+		return
+	}
 	cb.state = state
+	cb.indeterminate = indeterminate
 	cb.update()
 	cb.Dispatch(OnChange, nil)
-	return cb
 }
 
 // Group returns the name of the radio group
@@ -149,20 +225,31 @@ func (cb *CheckRadio) toggleState() {
 	}
 
 	if cb.check {
+		switch {
+		case cb.indeterminate:
+			cb.setState(!cb.triState, false)
+		case cb.state && cb.triState:
+			cb.setState(true, true)
+		default:
+			cb.setState(!cb.state, false)
+		}
+		return
+	}
+	if cb.radioGroup != nil {
+		cb.radioGroup.selectButton(cb)
+		return
+	}
+	if len(cb.group) == 0 {
 		cb.state = !cb.state
 	} else {
-		if len(cb.group) == 0 {
-			cb.state = !cb.state
-		} else {
-			if cb.state {
-				return
-			}
-			cb.state = !cb.state
+		if cb.state {
+			return
 		}
+		cb.state = !cb.state
 	}
 	cb.update()
 	cb.Dispatch(OnChange, nil)
-	if !cb.check && len(cb.group) > 0 {
+	if len(cb.group) > 0 {
 		Manager().Dispatch(OnRadioGroup, cb)
 	}
 }
@@ -181,17 +268,6 @@ func (cb *CheckRadio) onMouse(evname string, ev interface{}) {
 	}
 }
 
-// onCursor process OnCursor* events
-func (cb *CheckRadio) onCursor(evname string, ev interface{}) {
-
-	if evname == OnCursorEnter {
-		cb.cursorOver = true
-	} else {
-		cb.cursorOver = false
-	}
-	cb.update()
-}
-
 // onKey receives subscribed key events
 func (cb *CheckRadio) onKey(evname string, ev interface{}) {
 
@@ -223,21 +299,26 @@ func (cb *CheckRadio) onRadioGroup(other *CheckRadio) {
 // update updates the visual appearance of the checkbox
 func (cb *CheckRadio) update() {
 
-	if cb.state {
+	if cb.indeterminate {
+		cb.icon.SetText(checkIND)
+	} else if cb.state {
 		cb.icon.SetText(cb.codeON)
 	} else {
 		cb.icon.SetText(cb.codeOFF)
 	}
 
-	if !cb.Enabled() {
+	switch cb.tracker.State() {
+	case StyleDisabled:
 		cb.applyStyle(&cb.styles.Disabled)
-		return
-	}
-	if cb.cursorOver {
+	case StylePressed:
+		cb.applyStyle(&cb.styles.Pressed)
+	case StyleOver:
 		cb.applyStyle(&cb.styles.Over)
-		return
+	case StyleFocus:
+		cb.applyStyle(&cb.styles.Focus)
+	default:
+		cb.applyStyle(&cb.styles.Normal)
 	}
-	cb.applyStyle(&cb.styles.Normal)
 }
 
 // setStyle sets the specified checkradio style
@@ -0,0 +1,147 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// panelClip contains the clip shape of a panel which is applied
+// to the panel and to all its bounded descendants.
+type panelClip struct {
+	radius float32            // Radius of the corners of the clip rectangle in pixels
+	mask   *texture.Texture2D // Optional mask texture
+}
+
+// SetClipRadius sets the radius in pixels of the corners of the rounded rectangle
+// used to clip this panel and its bounded descendants.
+// The clip rectangle is the panel area inside its margins.
+// A radius equal or greater than half of the smaller side of the rectangle clips
+// to a circle or capsule. Zero only clips to the rectangle (or to the mask if set).
+// Descendants which have their own clip shape are clipped only by their shape.
+func (p *Panel) SetClipRadius(radius float32) {
+
+	if p.clip == nil {
+		p.clip = new(panelClip)
+	}
+	p.clip.radius = math32.Max(radius, 0)
+	p.removeClipIfEmpty()
+}
+
+// ClipRadius returns the radius in pixels of the corners of the clip rectangle.
+func (p *Panel) ClipRadius() float32 {
+
+	if p.clip == nil {
+		return 0
+	}
+	return p.clip.radius
+}
+
+// SetClipMask sets a mask texture used to clip this panel and its bounded descendants.
+// The texture is stretched over the panel area inside its margins and the alpha
+// component of each texel is multiplied by the alpha of the fragments under it.
+// It is combined with the clip rectangle rounded corners if set.
+// The texture sampler uniform name is changed to "MaskTexture".
+// Nil removes the current mask.
+func (p *Panel) SetClipMask(tex *texture.Texture2D) {
+
+	if p.clip == nil {
+		p.clip = new(panelClip)
+	}
+	if tex != nil {
+		tex.SetUniformNames("MaskTexture", "MaskTexinfo")
+	}
+	p.clip.mask = tex
+	p.removeClipIfEmpty()
+}
+
+// ClipMask returns the current clip mask texture or nil if not set.
+func (p *Panel) ClipMask() *texture.Texture2D {
+
+	if p.clip == nil {
+		return nil
+	}
+	return p.clip.mask
+}
+
+// removeClipIfEmpty removes the clip shape of this panel if it does not clip anything.
+func (p *Panel) removeClipIfEmpty() {
+
+	if p.clip.radius == 0 && p.clip.mask == nil {
+		p.clip = nil
+	}
+	p.SetChanged(true)
+}
+
+// clipRect returns the absolute position and size in pixels of the clip rectangle of this panel.
+func (p *Panel) clipRect() (x, y, width, height float32) {
+
+	x = p.pospix.X + p.marginSizes.Left
+	y = p.pospix.Y + p.marginSizes.Top
+	width = p.width - p.marginSizes.Left - p.marginSizes.Right
+	height = p.height - p.marginSizes.Top - p.marginSizes.Bottom
+	return
+}
+
+// insideClip returns if the specified screen position in pixels
+// is inside the clip rounded rectangle of this panel.
+// The mask texture, if set, is not considered.
+func (p *Panel) insideClip(px, py float32) bool {
+
+	x, y, width, height := p.clipRect()
+	hw := width / 2
+	hh := height / 2
+	r := math32.Min(p.clip.radius, math32.Min(hw, hh))
+	dx := math32.Max(math32.Abs(px-x-hw)-(hw-r), 0)
+	dy := math32.Max(math32.Abs(py-y-hh)-(hh-r), 0)
+	return dx*dx+dy*dy <= r*r
+}
+
+// updateClip updates the clip valid flag of the panel uniforms and adds or
+// removes the clip mask texture of the clipping panel from this panel material.
+func (p *Panel) updateClip() {
+
+	var mask *texture.Texture2D
+	if p.clipper != nil {
+		p.udata.clipValid = 1
+		mask = p.clipper.clip.mask
+	} else {
+		p.udata.clipValid = 0
+	}
+	if mask == p.clipMask {
+		return
+	}
+	// The mask texture is shared by the materials of all the clipped panels
+	if p.clipMask != nil {
+		p.mat.RemoveTexture(p.clipMask)
+		p.clipMask.Dispose()
+	}
+	if mask != nil {
+		p.mat.AddTexture(mask.Incref())
+	}
+	p.clipMask = mask
+}
+
+// setClipUniform transfers the clip shape uniform of this panel
+// with the clip rectangle relative to this panel.
+func (p *Panel) setClipUniform(gl *gls.GLS) {
+
+	var data [8]float32
+	x, y, width, height := p.clipper.clipRect()
+	data[0] = x - p.pospix.X
+	data[1] = y - p.pospix.Y
+	data[2] = width
+	data[3] = height
+	data[4] = p.clipper.clip.radius
+	if p.clipMask != nil {
+		data[5] = 1
+	}
+	data[6] = p.width
+	data[7] = p.height
+	const vec4count = 2
+	gl.Uniform4fv(p.uniClip.Location(gl), vec4count, &data[0])
+}
@@ -4,10 +4,6 @@
 
 package gui
 
-import (
-	"fmt"
-)
-
 // ControlFolder represents a folder with controls.
 type ControlFolder struct {
 	Folder                      // Embedded folder
@@ -147,10 +143,7 @@ func (f *ControlFolder) newSlider(text string, sf, value float32) (IPanel, *Slid
 	s.SetScaleFactor(sf)
 	s.SetScaleFactor(sf)
 	s.SetValue(value)
-	s.SetText(fmt.Sprintf("%1.1f", value))
-	s.Subscribe(OnChange, func(evname string, ev interface{}) {
-		s.SetText(fmt.Sprintf("%1.1f", s.Value()))
-	})
+	s.SetFormat("%1.1f")
 	s.SetLayoutParams(&HBoxLayoutParams{AlignV: AlignCenter, Expand: 1})
 	cont.Add(s)
 
@@ -0,0 +1,478 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"math"
+	"time"
+
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// CurveEditor is a panel which edits a math32.KeyframeCurve over a grid, for example
+// an animation curve, a color ramp channel or a parameter over the lifetime of objects.
+// Dragging a keyframe moves it and dragging the handles of the selected keyframe changes
+// its tangents. Double clicking the grid adds a keyframe, right clicking a keyframe or
+// pressing Delete removes it. Dragging the grid pans the view and the mouse wheel zooms it.
+// OnChange is dispatched when the curve is edited.
+type CurveEditor struct {
+	Panel                           // Embedded panel
+	canvas    *Canvas               // Canvas which draws the grid and the curve
+	curve     *math32.KeyframeCurve // Edited curve
+	style     *CurveEditorStyle     // Pointer to current style
+	tmin      float32               // Time at the left border of the view
+	tmax      float32               // Time at the right border of the view
+	vmin      float32               // Value at the bottom border of the view
+	vmax      float32               // Value at the top border of the view
+	selected  int                   // Index of the selected keyframe (-1 if none)
+	drag      curveDrag             // Current drag operation
+	posLastX  float32               // Last cursor X position when dragging
+	posLastY  float32               // Last cursor Y position when dragging
+	cursorX   float32               // Last cursor X position over the editor
+	cursorY   float32               // Last cursor Y position over the editor
+	lastClick time.Time             // Time of the last click on the grid to detect double clicks
+}
+
+// CurveEditorStyle contains the styling of a CurveEditor.
+type CurveEditorStyle struct {
+	Border       RectBounds    // Border sizes
+	BorderColor  math32.Color4 // Border color
+	BgColor      math32.Color4 // Background color
+	GridColor    math32.Color4 // Color of the grid lines
+	AxisColor    math32.Color4 // Color of the lines of time and value zero
+	CurveColor   math32.Color4 // Color of the curve
+	KeyColor     math32.Color4 // Color of the keyframes
+	SelectColor  math32.Color4 // Color of the selected keyframe
+	HandleColor  math32.Color4 // Color of the tangent handles
+	KeySize      float32       // Size of the keyframe squares in pixels
+	HandleLength float32       // Length of the tangent handles in pixels
+}
+
+// curveDrag is the drag operation of a CurveEditor.
+type curveDrag int
+
+const (
+	curveDragNone = curveDrag(iota)
+	curveDragKey
+	curveDragIn
+	curveDragOut
+	curveDragPan
+)
+
+// curveDoubleClick is the maximum duration between the clicks of a double click.
+const curveDoubleClick = 400 * time.Millisecond
+
+// NewCurveEditor creates and returns a pointer to a new curve editor with the specified
+// size editing the specified curve, or a new empty curve if nil.
+func NewCurveEditor(width, height float32, curve *math32.KeyframeCurve) *CurveEditor {
+
+	ce := new(CurveEditor)
+	ce.Panel.Initialize(ce, width, height)
+	ce.style = &StyleDefault().CurveEditor
+	ce.selected = -1
+	ce.tmin, ce.tmax = 0, 1
+	ce.vmin, ce.vmax = 0, 1
+
+	ce.canvas = NewCanvas(ce.ContentWidth(), ce.ContentHeight())
+	ce.Add(ce.canvas)
+
+	ce.Subscribe(OnResize, func(evname string, ev interface{}) { ce.recalc() })
+	ce.Subscribe(OnMouseDown, ce.onMouse)
+	ce.Subscribe(OnMouseUp, ce.onMouse)
+	ce.Subscribe(OnCursor, ce.onCursor)
+	ce.Subscribe(OnScroll, ce.onScroll)
+	ce.Subscribe(OnKeyDown, ce.onKey)
+
+	ce.applyStyle()
+	if curve == nil {
+		curve = math32.NewKeyframeCurve()
+	}
+	ce.SetCurve(curve)
+	return ce
+}
+
+// SetCurve sets the curve edited by this editor and fits the view to it.
+func (ce *CurveEditor) SetCurve(curve *math32.KeyframeCurve) *CurveEditor {
+
+	ce.curve = curve
+	ce.selected = -1
+	ce.FitView()
+	return ce
+}
+
+// Curve returns the curve edited by this editor.
+func (ce *CurveEditor) Curve() *math32.KeyframeCurve {
+
+	return ce.curve
+}
+
+// SetView sets the ranges of time and value shown by this editor.
+func (ce *CurveEditor) SetView(tmin, tmax, vmin, vmax float32) *CurveEditor {
+
+	if tmax <= tmin || vmax <= vmin {
+		return ce
+	}
+	ce.tmin, ce.tmax = tmin, tmax
+	ce.vmin, ce.vmax = vmin, vmax
+	ce.Redraw()
+	return ce
+}
+
+// View returns the ranges of time and value shown by this editor.
+func (ce *CurveEditor) View() (tmin, tmax, vmin, vmax float32) {
+
+	return ce.tmin, ce.tmax, ce.vmin, ce.vmax
+}
+
+// FitView sets the view to show all the keyframes of the curve with a margin.
+func (ce *CurveEditor) FitView() *CurveEditor {
+
+	keys := ce.curve.Keys()
+	tmin, tmax := float32(0), float32(1)
+	vmin, vmax := float32(0), float32(1)
+	if len(keys) > 0 {
+		tmin, tmax = ce.curve.TimeRange()
+		vmin, vmax = keys[0].Value, keys[0].Value
+		for _, k := range keys {
+			vmin = math32.Min(vmin, k.Value)
+			vmax = math32.Max(vmax, k.Value)
+		}
+		if tmax-tmin < 1e-3 {
+			tmin, tmax = tmin-0.5, tmax+0.5
+		}
+		if vmax-vmin < 1e-3 {
+			vmin, vmax = vmin-0.5, vmax+0.5
+		}
+	}
+	mt := (tmax - tmin) * 0.1
+	mv := (vmax - vmin) * 0.1
+	return ce.SetView(tmin-mt, tmax+mt, vmin-mv, vmax+mv)
+}
+
+// SetSelected selects the keyframe at the specified index or none if -1.
+func (ce *CurveEditor) SetSelected(i int) *CurveEditor {
+
+	if i < -1 || i >= ce.curve.KeyCount() {
+		i = -1
+	}
+	ce.selected = i
+	ce.Redraw()
+	return ce
+}
+
+// Selected returns the index of the selected keyframe or -1 if none.
+func (ce *CurveEditor) Selected() int {
+
+	return ce.selected
+}
+
+// SetStyle sets the style of this editor.
+func (ce *CurveEditor) SetStyle(style *CurveEditorStyle) *CurveEditor {
+
+	ce.style = style
+	ce.applyStyle()
+	return ce
+}
+
+// applyStyle applies the current style to this editor.
+func (ce *CurveEditor) applyStyle() {
+
+	ce.SetBordersFrom(&ce.style.Border)
+	ce.SetBordersColor4(&ce.style.BorderColor)
+	ce.SetColor4(&ce.style.BgColor)
+	ce.recalc()
+}
+
+// recalc resizes the canvas to the content area and redraws it.
+func (ce *CurveEditor) recalc() {
+
+	if ce.canvas == nil {
+		return
+	}
+	ce.canvas.SetSize(ce.ContentWidth(), ce.ContentHeight())
+	ce.Redraw()
+}
+
+// toPixels converts the specified time and value to canvas pixel coordinates.
+func (ce *CurveEditor) toPixels(t, v float32) (float32, float32) {
+
+	x := (t - ce.tmin) / (ce.tmax - ce.tmin) * ce.canvas.Width()
+	y := (ce.vmax - v) / (ce.vmax - ce.vmin) * ce.canvas.Height()
+	return x, y
+}
+
+// fromPixels converts the specified canvas pixel coordinates to time and value.
+func (ce *CurveEditor) fromPixels(x, y float32) (float32, float32) {
+
+	t := ce.tmin + x/ce.canvas.Width()*(ce.tmax-ce.tmin)
+	v := ce.vmax - y/ce.canvas.Height()*(ce.vmax-ce.vmin)
+	return t, v
+}
+
+// handles returns the canvas pixel coordinates of the in and out tangent handles of the specified keyframe.
+func (ce *CurveEditor) handles(k *math32.Keyframe) (ix, iy, ox, oy float32) {
+
+	kx, ky := ce.toPixels(k.Time, k.Value)
+	sx := ce.canvas.Width() / (ce.tmax - ce.tmin)
+	sy := ce.canvas.Height() / (ce.vmax - ce.vmin)
+	dir := func(slope float32) (float32, float32) {
+		d := math32.Vector2{X: sx, Y: -slope * sy}
+		d.SetLength(ce.style.HandleLength)
+		return d.X, d.Y
+	}
+	dx, dy := dir(k.InTangent)
+	ix, iy = kx-dx, ky-dy
+	dx, dy = dir(k.OutTangent)
+	ox, oy = kx+dx, ky+dy
+	return
+}
+
+// Redraw redraws the grid and the curve. It must be called after
+// the curve is changed without using this editor.
+func (ce *CurveEditor) Redraw() {
+
+	if ce.canvas == nil || ce.curve == nil {
+		return
+	}
+	c := ce.canvas
+	c.Clear()
+	w, h := c.Width(), c.Height()
+	if w <= 0 || h <= 0 {
+		return
+	}
+	stroke := &StrokeStyle{Width: 1}
+
+	// Draws the grid lines and the axes
+	grid := NewPath()
+	axes := NewPath()
+	tstep := curveGridStep(ce.tmax-ce.tmin, w/60)
+	for t := math32.Ceil(ce.tmin/tstep) * tstep; t <= ce.tmax; t += tstep {
+		x, _ := ce.toPixels(t, 0)
+		x = math32.Floor(x) + 0.5
+		if math32.Abs(t) < tstep/2 {
+			axes.MoveTo(x, 0).LineTo(x, h)
+		} else {
+			grid.MoveTo(x, 0).LineTo(x, h)
+		}
+	}
+	vstep := curveGridStep(ce.vmax-ce.vmin, h/40)
+	for v := math32.Ceil(ce.vmin/vstep) * vstep; v <= ce.vmax; v += vstep {
+		_, y := ce.toPixels(0, v)
+		y = math32.Floor(y) + 0.5
+		if math32.Abs(v) < vstep/2 {
+			axes.MoveTo(0, y).LineTo(w, y)
+		} else {
+			grid.MoveTo(0, y).LineTo(w, y)
+		}
+	}
+	c.Stroke(grid, SolidPaint(&ce.style.GridColor), stroke)
+	c.Stroke(axes, SolidPaint(&ce.style.AxisColor), stroke)
+
+	// Draws the curve sampled every two pixels
+	if ce.curve.KeyCount() > 0 {
+		path := NewPath()
+		for x := float32(0); x <= w+2; x += 2 {
+			t, _ := ce.fromPixels(x, 0)
+			_, y := ce.toPixels(t, ce.curve.Evaluate(t))
+			path.LineTo(x, y)
+		}
+		c.Stroke(path, SolidPaint(&ce.style.CurveColor), &StrokeStyle{Width: 2, Join: LineJoinRound})
+	}
+
+	// Draws the tangent handles of the selected keyframe
+	keys := ce.curve.Keys()
+	if ce.selected >= 0 && ce.selected < len(keys) {
+		k := &keys[ce.selected]
+		kx, ky := ce.toPixels(k.Time, k.Value)
+		ix, iy, ox, oy := ce.handles(k)
+		paint := SolidPaint(&ce.style.HandleColor)
+		c.Stroke(NewPath().MoveTo(ix, iy).LineTo(kx, ky).LineTo(ox, oy), paint, stroke)
+		r := ce.style.KeySize / 2
+		c.Fill(NewPath().Circle(ix, iy, r).Circle(ox, oy, r), paint)
+	}
+
+	// Draws the keyframes
+	half := ce.style.KeySize / 2
+	for i := range keys {
+		x, y := ce.toPixels(keys[i].Time, keys[i].Value)
+		color := &ce.style.KeyColor
+		if i == ce.selected {
+			color = &ce.style.SelectColor
+		}
+		c.Fill(NewPath().Rect(x-half, y-half, 2*half, 2*half), SolidPaint(color))
+	}
+}
+
+// curveGridStep returns a round step (1, 2 or 5 times a power of ten) which divides
+// the specified span in at most the specified number of parts.
+func curveGridStep(span, parts float32) float32 {
+
+	if parts < 1 {
+		parts = 1
+	}
+	raw := float64(span / parts)
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if m*mag >= raw {
+			return float32(m * mag)
+		}
+	}
+	return float32(10 * mag)
+}
+
+// hit returns the drag operation and the keyframe index at the specified canvas pixel coordinates.
+func (ce *CurveEditor) hit(x, y float32) (curveDrag, int) {
+
+	r := ce.style.KeySize/2 + 2
+	keys := ce.curve.Keys()
+	if ce.selected >= 0 && ce.selected < len(keys) {
+		ix, iy, ox, oy := ce.handles(&keys[ce.selected])
+		if math32.Abs(x-ox) <= r && math32.Abs(y-oy) <= r {
+			return curveDragOut, ce.selected
+		}
+		if math32.Abs(x-ix) <= r && math32.Abs(y-iy) <= r {
+			return curveDragIn, ce.selected
+		}
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		kx, ky := ce.toPixels(keys[i].Time, keys[i].Value)
+		if math32.Abs(x-kx) <= r && math32.Abs(y-ky) <= r {
+			return curveDragKey, i
+		}
+	}
+	return curveDragNone, -1
+}
+
+// onMouse receives subscribed mouse events.
+func (ce *CurveEditor) onMouse(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	if evname == OnMouseUp {
+		if ce.drag != curveDragNone {
+			ce.drag = curveDragNone
+			Manager().SetCursorFocus(nil)
+		}
+		return
+	}
+	Manager().SetKeyFocus(ce)
+	x, y := ce.canvas.ContentCoords(mev.Xpos, mev.Ypos)
+	op, idx := ce.hit(x, y)
+	switch mev.Button {
+	case window.MouseButtonRight:
+		// Removes the keyframe under the cursor
+		if op == curveDragKey {
+			ce.removeKey(idx)
+		}
+		return
+	case window.MouseButtonLeft:
+	default:
+		return
+	}
+
+	if op == curveDragNone {
+		// Adds a keyframe on double click or starts panning the view
+		now := time.Now()
+		if now.Sub(ce.lastClick) < curveDoubleClick {
+			ce.lastClick = time.Time{}
+			t, v := ce.fromPixels(x, y)
+			i := ce.curve.AddKey(math32.Keyframe{Time: t, Value: v})
+			ce.curve.SmoothTangents(i)
+			ce.selected = i
+			ce.changed()
+			return
+		}
+		ce.lastClick = now
+		ce.selected = -1
+		op = curveDragPan
+	} else {
+		ce.selected = idx
+	}
+	ce.drag = op
+	ce.posLastX = mev.Xpos
+	ce.posLastY = mev.Ypos
+	Manager().SetCursorFocus(ce)
+	ce.Redraw()
+}
+
+// onCursor receives subscribed cursor events.
+func (ce *CurveEditor) onCursor(evname string, ev interface{}) {
+
+	cev := ev.(*window.CursorEvent)
+	ce.cursorX = cev.Xpos
+	ce.cursorY = cev.Ypos
+	if ce.drag == curveDragNone {
+		return
+	}
+	x, y := ce.canvas.ContentCoords(cev.Xpos, cev.Ypos)
+	switch ce.drag {
+	case curveDragPan:
+		dt := (cev.Xpos - ce.posLastX) / ce.canvas.Width() * (ce.tmax - ce.tmin)
+		dv := (cev.Ypos - ce.posLastY) / ce.canvas.Height() * (ce.vmax - ce.vmin)
+		ce.posLastX = cev.Xpos
+		ce.posLastY = cev.Ypos
+		ce.SetView(ce.tmin-dt, ce.tmax-dt, ce.vmin+dv, ce.vmax+dv)
+		return
+	case curveDragKey:
+		k := ce.curve.Key(ce.selected)
+		k.Time, k.Value = ce.fromPixels(x, y)
+		ce.selected = ce.curve.SetKey(ce.selected, k)
+	case curveDragIn, curveDragOut:
+		// Converts the handle offset in pixels to a slope
+		k := ce.curve.Key(ce.selected)
+		kx, ky := ce.toPixels(k.Time, k.Value)
+		dx := x - kx
+		if ce.drag == curveDragIn {
+			dx = -dx
+		}
+		dx = math32.Max(dx, 1)
+		dy := ky - y
+		if ce.drag == curveDragIn {
+			dy = -dy
+		}
+		slope := (dy / ce.canvas.Height() * (ce.vmax - ce.vmin)) / (dx / ce.canvas.Width() * (ce.tmax - ce.tmin))
+		if ce.drag == curveDragIn {
+			k.InTangent = slope
+		} else {
+			k.OutTangent = slope
+		}
+		ce.curve.SetKey(ce.selected, k)
+	}
+	ce.changed()
+}
+
+// onScroll receives subscribed scroll events and zooms the view around the cursor.
+func (ce *CurveEditor) onScroll(evname string, ev interface{}) {
+
+	sev := ev.(*window.ScrollEvent)
+	x, y := ce.canvas.ContentCoords(ce.cursorX, ce.cursorY)
+	t, v := ce.fromPixels(x, y)
+	f := math32.Pow(1.2, -sev.Yoffset)
+	ce.SetView(t-(t-ce.tmin)*f, t+(ce.tmax-t)*f, v-(v-ce.vmin)*f, v+(ce.vmax-v)*f)
+}
+
+// onKey receives subscribed key events.
+func (ce *CurveEditor) onKey(evname string, ev interface{}) {
+
+	kev := ev.(*window.KeyEvent)
+	if (kev.Key == window.KeyDelete || kev.Key == window.KeyBackspace) && ce.selected >= 0 {
+		ce.removeKey(ce.selected)
+	}
+}
+
+// removeKey removes the keyframe at the specified index.
+func (ce *CurveEditor) removeKey(i int) {
+
+	ce.curve.RemoveKey(i)
+	ce.selected = -1
+	ce.changed()
+}
+
+// changed redraws the editor and dispatches OnChange.
+func (ce *CurveEditor) changed() {
+
+	ce.Redraw()
+	ce.Dispatch(OnChange, nil)
+}
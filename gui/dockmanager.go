@@ -0,0 +1,754 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// OnDockChange is the event dispatched by a DockManager when its layout is changed by the user
+// docking, floating, moving or closing a panel or dragging a splitter, so the application can
+// save the layout returned by SaveLayout. It is not dispatched by the changes made by the methods.
+const OnDockChange = "gui.OnDockChange"
+
+// Docking parameters
+const (
+	dockRootSplit    = 0.25 // Fraction of the size of the dock manager taken by a panel docked to one of its edges
+	dockLeafSplit    = 0.5  // Fraction of the size of a docked panel taken by a panel docked to one of its edges
+	dockEdgeZone     = 0.25 // Fraction of the size of a docked panel near its edges where windows are docked to the edge
+	dockDragDistance = 24   // Distance from the tab headers the cursor must reach to float a dragged tab
+)
+
+// DockStyle contains the styling of a DockManager
+type DockStyle struct {
+	HintColor   math32.Color4 // Color of the area where the dragged window will be docked
+	FloatWidth  float32       // Initial width of the windows of floating panels
+	FloatHeight float32       // Initial height of the windows of floating panels
+}
+
+// DockManager is a panel whose area is shared by named panels docked to its edges or to the edges
+// of other panels, resized with splitters, or tabbed together with other panels.
+// A docked panel is floated in a Window by dragging its tab out of the tab headers and a floating
+// panel is docked by dropping its window title on the tab headers of a docked panel, to be tabbed
+// with it, or near one of its edges. Closing the tab of a panel hides it.
+// The layout can be saved with SaveLayout and restored with RestoreLayout.
+type DockManager struct {
+	Panel                       // Embedded panel
+	styles *DockStyle           // Pointer to current style
+	area   Panel                // Panel which contains the panel of the root node
+	root   *dockNode            // Root node of the docked panels (nil if none)
+	items  map[string]*dockItem // Panels by name
+	order  []string             // Names of the panels in the order they were added
+	hint   Panel                // Area where the dragged window will be docked
+}
+
+// dockNode is a node of the tree of docked panels, which is either
+// a splitter between two child nodes or a leaf with the tabs of the panels.
+type dockNode struct {
+	parent   *dockNode    // Parent node (nil for the root)
+	split    *Splitter    // Splitter of a split node
+	children [2]*dockNode // Left/top and right/bottom children of a split node
+	tabs     *TabBar      // Tabs of a leaf node
+}
+
+// dockItem is a panel added to a DockManager.
+type dockItem struct {
+	name     string    // Unique name of the panel
+	title    string    // Title of the tab and window
+	content  IPanel    // Content panel
+	leaf     *dockNode // Leaf node where the panel is docked (nil if not docked)
+	tab      *Tab      // Tab of the docked panel
+	win      *Window   // Window of the floating panel (nil if not floating)
+	width    float32   // Width of the window when floated
+	height   float32   // Height of the window when floated
+	dragging bool      // Window of the floating panel is being dragged
+}
+
+// dockLayout is the serialized layout of a DockManager.
+type dockLayout struct {
+	Root     *dockLayoutNode    `json:"root,omitempty"`
+	Floating []dockLayoutWindow `json:"floating,omitempty"`
+}
+
+// dockLayoutNode is a serialized split or leaf node.
+type dockLayoutNode struct {
+	Horizontal bool              `json:"horizontal,omitempty"`
+	Split      float32           `json:"split,omitempty"`
+	Children   []*dockLayoutNode `json:"children,omitempty"`
+	Panels     []string          `json:"panels,omitempty"`
+	Selected   string            `json:"selected,omitempty"`
+}
+
+// dockLayoutWindow is a serialized floating panel.
+type dockLayoutWindow struct {
+	Panel  string  `json:"panel"`
+	X      float32 `json:"x"`
+	Y      float32 `json:"y"`
+	Width  float32 `json:"width"`
+	Height float32 `json:"height"`
+}
+
+// NewDockManager creates and returns a pointer to a new dock manager with the specified dimensions.
+func NewDockManager(width, height float32) *DockManager {
+
+	dm := new(DockManager)
+	dm.Panel.Initialize(dm, width, height)
+	dm.styles = &StyleDefault().Dock
+	dm.items = make(map[string]*dockItem)
+
+	dm.area.Initialize(&dm.area, width, height)
+	dm.area.SetLayout(NewFillLayout(true, true))
+	dm.Panel.Add(&dm.area)
+
+	dm.hint.Initialize(&dm.hint, 0, 0)
+	dm.hint.SetColor4(&dm.styles.HintColor)
+	dm.hint.SetVisible(false)
+	dm.Panel.Add(&dm.hint)
+
+	dm.Subscribe(OnResize, func(evname string, ev interface{}) {
+		dm.area.SetSize(dm.ContentWidth(), dm.ContentHeight())
+	})
+	return dm
+}
+
+// AddPanel adds to the dock manager a hidden panel with the specified unique name,
+// title and content, which can be docked with Dock or floated with Float.
+func (dm *DockManager) AddPanel(name, title string, content IPanel) error {
+
+	if _, ok := dm.items[name]; ok {
+		return fmt.Errorf("dock panel already exists: %s", name)
+	}
+	dm.items[name] = &dockItem{
+		name:    name,
+		title:   title,
+		content: content,
+		width:   dm.styles.FloatWidth,
+		height:  dm.styles.FloatHeight,
+	}
+	dm.order = append(dm.order, name)
+	return nil
+}
+
+// RemovePanel removes the panel with the specified name from the dock manager
+// and returns its content panel, or nil if it was not found.
+func (dm *DockManager) RemovePanel(name string) IPanel {
+
+	item, ok := dm.items[name]
+	if !ok {
+		return nil
+	}
+	dm.detach(item)
+	delete(dm.items, name)
+	for i := range dm.order {
+		if dm.order[i] == name {
+			dm.order = append(dm.order[:i], dm.order[i+1:]...)
+			break
+		}
+	}
+	return item.content
+}
+
+// Panels returns the names of the panels in the order they were added.
+func (dm *DockManager) Panels() []string {
+
+	return append([]string(nil), dm.order...)
+}
+
+// Content returns the content panel of the panel with the specified name or nil if it was not found.
+func (dm *DockManager) Content(name string) IPanel {
+
+	item, ok := dm.items[name]
+	if !ok {
+		return nil
+	}
+	return item.content
+}
+
+// Dock docks the panel with the specified name to the specified edge (DockTop, DockRight, DockBottom or
+// DockLeft) of the docked panel with the target name, splitting its area, or tabbed with it (DockCenter).
+// If the target name is empty the panel is docked to the edge of the dock manager or, with DockCenter,
+// tabbed with the first docked panel. The panel is undocked or closes its window first if needed.
+func (dm *DockManager) Dock(name, target string, edge int) error {
+
+	item, ok := dm.items[name]
+	if !ok {
+		return fmt.Errorf("dock panel not found: %s", name)
+	}
+	if edge < DockTop || edge > DockCenter {
+		return fmt.Errorf("invalid dock edge: %d", edge)
+	}
+	var titem *dockItem
+	if target != "" {
+		titem, ok = dm.items[target]
+		if !ok {
+			return fmt.Errorf("dock panel not found: %s", target)
+		}
+		if titem == item {
+			return fmt.Errorf("dock panel can't be docked to itself: %s", name)
+		}
+		if titem.leaf == nil {
+			return fmt.Errorf("dock panel is not docked: %s", target)
+		}
+	}
+	dm.detach(item)
+	if titem != nil {
+		dm.dock(item, titem.leaf, edge)
+	} else {
+		dm.dock(item, nil, edge)
+	}
+	return nil
+}
+
+// Float shows the panel with the specified name in a window at the specified
+// position in the dock manager, undocking the panel first if needed.
+func (dm *DockManager) Float(name string, x, y float32) error {
+
+	item, ok := dm.items[name]
+	if !ok {
+		return fmt.Errorf("dock panel not found: %s", name)
+	}
+	dm.detach(item)
+	dm.float(item, x, y)
+	return nil
+}
+
+// Hide undocks the panel with the specified name or closes its window.
+func (dm *DockManager) Hide(name string) error {
+
+	item, ok := dm.items[name]
+	if !ok {
+		return fmt.Errorf("dock panel not found: %s", name)
+	}
+	dm.detach(item)
+	return nil
+}
+
+// Docked returns whether the panel with the specified name is docked.
+func (dm *DockManager) Docked(name string) bool {
+
+	item, ok := dm.items[name]
+	return ok && item.leaf != nil
+}
+
+// Window returns the window of the panel with the specified name if it's floating or nil otherwise.
+func (dm *DockManager) Window(name string) *Window {
+
+	item, ok := dm.items[name]
+	if !ok {
+		return nil
+	}
+	return item.win
+}
+
+// Select selects the tab of the panel with the specified name if it's docked
+// or brings its window to the top if it's floating.
+func (dm *DockManager) Select(name string) error {
+
+	item, ok := dm.items[name]
+	if !ok {
+		return fmt.Errorf("dock panel not found: %s", name)
+	}
+	if item.leaf != nil {
+		item.leaf.tabs.SetSelected(item.leaf.tabs.TabPosition(item.tab))
+	} else if item.win != nil {
+		dm.SetTopChild(item.win)
+	} else {
+		return fmt.Errorf("dock panel is hidden: %s", name)
+	}
+	return nil
+}
+
+// SaveLayout returns the serialized layout of the docked and floating panels,
+// including the positions of the splitters and the selected tabs.
+func (dm *DockManager) SaveLayout() ([]byte, error) {
+
+	var layout dockLayout
+	layout.Root = dm.root.layout()
+	for _, name := range dm.order {
+		item := dm.items[name]
+		if item.win == nil {
+			continue
+		}
+		pos := item.win.Position()
+		layout.Floating = append(layout.Floating, dockLayoutWindow{
+			Panel:  name,
+			X:      pos.X,
+			Y:      pos.Y,
+			Width:  item.win.Width(),
+			Height: item.win.Height(),
+		})
+	}
+	return json.Marshal(&layout)
+}
+
+// RestoreLayout restores the layout of the panels previously returned by SaveLayout.
+// The panels of the layout which were not added to the dock manager are ignored
+// and the panels which are not in the layout are hidden.
+func (dm *DockManager) RestoreLayout(data []byte) error {
+
+	var layout dockLayout
+	err := json.Unmarshal(data, &layout)
+	if err != nil {
+		return err
+	}
+	for _, name := range dm.order {
+		dm.detach(dm.items[name])
+	}
+	root := dm.newNode(layout.Root)
+	if root != nil {
+		dm.setNode(nil, 0, root)
+	}
+	for _, lw := range layout.Floating {
+		item, ok := dm.items[lw.Panel]
+		if !ok || item.leaf != nil || item.win != nil {
+			continue
+		}
+		if lw.Width > 0 && lw.Height > 0 {
+			item.width = lw.Width
+			item.height = lw.Height
+		}
+		dm.float(item, lw.X, lw.Y)
+	}
+	return nil
+}
+
+// newNode creates and returns the node of the specified serialized node, or nil if it has no panels.
+func (dm *DockManager) newNode(ln *dockLayoutNode) *dockNode {
+
+	if ln == nil {
+		return nil
+	}
+	// Split node. If one of the children has no panels it is replaced by the other child
+	if len(ln.Children) == 2 {
+		c0 := dm.newNode(ln.Children[0])
+		c1 := dm.newNode(ln.Children[1])
+		if c0 == nil {
+			return c1
+		}
+		if c1 == nil {
+			return c0
+		}
+		n := dm.newSplit(ln.Horizontal)
+		dm.setNode(n, 0, c0)
+		dm.setNode(n, 1, c1)
+		n.split.SetSplit(ln.Split)
+		return n
+	}
+	// Leaf node with the panels not already placed
+	var leaf *dockNode
+	selected := -1
+	for _, name := range ln.Panels {
+		item, ok := dm.items[name]
+		if !ok || item.leaf != nil || item.win != nil {
+			continue
+		}
+		if leaf == nil {
+			leaf = dm.newLeaf()
+		}
+		dm.addTab(leaf, item)
+		if name == ln.Selected {
+			selected = leaf.tabs.TabCount() - 1
+		}
+	}
+	if leaf != nil && selected >= 0 {
+		leaf.tabs.SetSelected(selected)
+	}
+	return leaf
+}
+
+// layout returns the serialized node.
+func (n *dockNode) layout() *dockLayoutNode {
+
+	if n == nil {
+		return nil
+	}
+	ln := new(dockLayoutNode)
+	if n.split != nil {
+		ln.Horizontal = n.split.Horizontal()
+		ln.Split = n.split.Split()
+		ln.Children = []*dockLayoutNode{n.children[0].layout(), n.children[1].layout()}
+		return ln
+	}
+	for i := 0; i < n.tabs.TabCount(); i++ {
+		item := n.tabs.TabAt(i).Header().UserData().(*dockItem)
+		ln.Panels = append(ln.Panels, item.name)
+		if i == n.tabs.Selected() {
+			ln.Selected = item.name
+		}
+	}
+	return ln
+}
+
+// panel returns the panel of the node.
+func (n *dockNode) panel() IPanel {
+
+	if n.split != nil {
+		return n.split
+	}
+	return n.tabs
+}
+
+// index returns the index of the node in the children of its parent.
+func (n *dockNode) index() int {
+
+	if n.parent == nil || n.parent.children[0] == n {
+		return 0
+	}
+	return 1
+}
+
+// firstLeaf returns the first leaf node of the subtree of the node.
+func (n *dockNode) firstLeaf() *dockNode {
+
+	for n.split != nil {
+		n = n.children[0]
+	}
+	return n
+}
+
+// leafAt returns the leaf node of the subtree of the node which contains the specified screen position or nil.
+func (n *dockNode) leafAt(x, y float32) *dockNode {
+
+	if n.split != nil {
+		leaf := n.children[0].leafAt(x, y)
+		if leaf == nil {
+			leaf = n.children[1].leafAt(x, y)
+		}
+		return leaf
+	}
+	if n.tabs.ContainsPosition(x, y) {
+		return n
+	}
+	return nil
+}
+
+// newLeaf creates and returns a leaf node without tabs.
+func (dm *DockManager) newLeaf() *dockNode {
+
+	n := &dockNode{tabs: NewTabBar(0, 0)}
+	n.tabs.Subscribe(OnTabClose, func(evname string, ev interface{}) {
+		// Hides the panel instead of removing only the tab
+		tev := ev.(*TabEvent)
+		tev.Cancel = true
+		dm.detach(tev.Tab.Header().UserData().(*dockItem))
+		dm.Dispatch(OnDockChange, nil)
+	})
+	n.tabs.Subscribe(OnTabMove, func(evname string, ev interface{}) {
+		dm.Dispatch(OnDockChange, nil)
+	})
+	return n
+}
+
+// newSplit creates and returns a split node without children with the specified orientation.
+func (dm *DockManager) newSplit(horiz bool) *dockNode {
+
+	n := new(dockNode)
+	if horiz {
+		n.split = NewHSplitter(0, 0)
+	} else {
+		n.split = NewVSplitter(0, 0)
+	}
+	n.split.P0.SetLayout(NewFillLayout(true, true))
+	n.split.P1.SetLayout(NewFillLayout(true, true))
+	n.split.Subscribe(OnChange, func(evname string, ev interface{}) {
+		dm.Dispatch(OnDockChange, nil)
+	})
+	return n
+}
+
+// container returns the panel which contains the child node with the specified index
+// of the specified parent node, or the panel of the root node if the parent is nil.
+func (dm *DockManager) container(parent *dockNode, idx int) *Panel {
+
+	if parent == nil {
+		return &dm.area
+	}
+	if idx == 0 {
+		return &parent.split.P0
+	}
+	return &parent.split.P1
+}
+
+// setNode sets the child node with the specified index of the specified parent node,
+// or the root node if the parent is nil, which must not be set.
+func (dm *DockManager) setNode(parent *dockNode, idx int, n *dockNode) {
+
+	n.parent = parent
+	if parent == nil {
+		dm.root = n
+	} else {
+		parent.children[idx] = n
+	}
+	dm.container(parent, idx).Add(n.panel())
+}
+
+// unsetNode removes the panel of the specified node from its container.
+func (dm *DockManager) unsetNode(n *dockNode) {
+
+	dm.container(n.parent, n.index()).Remove(n.panel())
+	if n.parent == nil {
+		dm.root = nil
+	} else {
+		n.parent.children[n.index()] = nil
+	}
+	n.parent = nil
+}
+
+// removeLeaf removes the specified leaf node without tabs, replacing its parent split node by its sibling.
+func (dm *DockManager) removeLeaf(leaf *dockNode) {
+
+	parent := leaf.parent
+	idx := leaf.index()
+	dm.unsetNode(leaf)
+	leaf.tabs.Dispose()
+	if parent == nil {
+		return
+	}
+	sibling := parent.children[1-idx]
+	dm.unsetNode(sibling)
+	grandParent := parent.parent
+	pidx := parent.index()
+	dm.unsetNode(parent)
+	parent.split.Dispose()
+	dm.setNode(grandParent, pidx, sibling)
+}
+
+// addTab adds a tab for the specified panel to the specified leaf node and selects it.
+func (dm *DockManager) addTab(leaf *dockNode, item *dockItem) {
+
+	tab := leaf.tabs.AddTab(item.title)
+	tab.Header().SetUserData(item)
+	tab.Header().Subscribe(OnCursor, func(evname string, ev interface{}) {
+		dm.onTabCursor(item, ev.(*window.CursorEvent))
+	})
+	tab.SetContent(item.content)
+	item.leaf = leaf
+	item.tab = tab
+}
+
+// dock docks the specified panel, which must be hidden, to the specified edge of the specified leaf
+// node or tabbed with it, or to the edge of the dock manager or tabbed with its first leaf if nil.
+func (dm *DockManager) dock(item *dockItem, target *dockNode, edge int) {
+
+	if edge == DockCenter {
+		if target == nil && dm.root != nil {
+			target = dm.root.firstLeaf()
+		}
+		if target == nil {
+			target = dm.newLeaf()
+			dm.setNode(nil, 0, target)
+		}
+		dm.addTab(target, item)
+		return
+	}
+
+	leaf := dm.newLeaf()
+	dm.addTab(leaf, item)
+	split := float32(dockLeafSplit)
+	if target == nil {
+		target = dm.root
+		split = dockRootSplit
+	}
+	if target == nil {
+		dm.setNode(nil, 0, leaf)
+		return
+	}
+
+	// Replaces the target node by a split node between the target and the new leaf
+	parent := target.parent
+	idx := target.index()
+	dm.unsetNode(target)
+	n := dm.newSplit(edge == DockLeft || edge == DockRight)
+	if edge == DockLeft || edge == DockTop {
+		dm.setNode(n, 0, leaf)
+		dm.setNode(n, 1, target)
+	} else {
+		dm.setNode(n, 0, target)
+		dm.setNode(n, 1, leaf)
+		split = 1 - split
+	}
+	n.split.SetSplit(split)
+	dm.setNode(parent, idx, n)
+}
+
+// float shows the specified panel, which must be hidden, in a window at the specified position.
+func (dm *DockManager) float(item *dockItem, x, y float32) {
+
+	w := NewWindow(item.width, item.height)
+	w.SetTitle(item.title)
+	w.SetSize(item.width, item.height)
+	w.SetResizable(true)
+	// The window is not closed because its content would be disposed
+	w.SetCloseButton(false)
+	w.SetLayout(NewFillLayout(true, true))
+	w.SetPosition(x, y)
+	item.content.GetPanel().SetVisible(true)
+	w.Add(item.content)
+	w.title.Subscribe(OnCursor, func(evname string, ev interface{}) {
+		dm.onWindowCursor(item, ev.(*window.CursorEvent))
+	})
+	w.title.Subscribe(OnMouseUp, func(evname string, ev interface{}) {
+		dm.onWindowDrop(item, ev.(*window.MouseEvent))
+	})
+	item.win = w
+	dm.Panel.Add(w)
+}
+
+// detach undocks the specified panel or closes its window.
+func (dm *DockManager) detach(item *dockItem) {
+
+	if item.leaf != nil {
+		leaf := item.leaf
+		tab := item.tab
+		tab.SetContent(nil)
+		leaf.tabs.RemoveTab(leaf.tabs.TabPosition(tab))
+		tab.Header().Dispose()
+		item.leaf = nil
+		item.tab = nil
+		if leaf.tabs.TabCount() == 0 {
+			dm.removeLeaf(leaf)
+		}
+	}
+	if item.win != nil {
+		w := item.win
+		item.win = nil
+		item.width = w.Width()
+		item.height = w.Height()
+		if item.dragging {
+			item.dragging = false
+			dm.hint.SetVisible(false)
+		}
+		w.Remove(item.content)
+		w.Close()
+	}
+}
+
+// onTabCursor floats the specified docked panel when its tab is dragged away from the tab headers
+// and starts dragging the window, so it can be dropped where it will be docked.
+func (dm *DockManager) onTabCursor(item *dockItem, cev *window.CursorEvent) {
+
+	if item.leaf == nil || item.leaf.tabs.dragTab != item.tab {
+		return
+	}
+	tabs := item.leaf.tabs
+	top := tabs.Pospix().Y
+	bottom := top + item.tab.Header().Height()
+	if cev.Ypos > top-dockDragDistance && cev.Ypos < bottom+dockDragDistance {
+		return
+	}
+
+	// Floats the panel with the cursor over the window title
+	dm.detach(item)
+	x, y := dm.ContentCoords(cev.Xpos, cev.Ypos)
+	dm.float(item, x-dockDragDistance, y)
+	item.win.SetPositionY(y - item.win.title.Height()/2)
+	dm.Dispatch(OnDockChange, nil)
+
+	// Starts dragging the window as if its title was pressed, so it receives the release
+	wt := item.win.title
+	pos := item.win.Position()
+	wt.pressed = true
+	wt.mouseX = cev.Xpos
+	wt.mouseY = cev.Ypos
+	wt.dragX = pos.X
+	wt.dragY = pos.Y
+	Manager().target = wt
+	Manager().SetCursorFocus(wt)
+}
+
+// onWindowCursor shows where the specified floating panel will be docked while its window is dragged.
+func (dm *DockManager) onWindowCursor(item *dockItem, cev *window.CursorEvent) {
+
+	if !item.win.title.pressed {
+		return
+	}
+	item.dragging = true
+	_, _, rect := dm.dropTarget(cev.Xpos, cev.Ypos)
+	if rect == nil {
+		dm.hint.SetVisible(false)
+		return
+	}
+	dm.hint.SetPosition(rect.X, rect.Y)
+	dm.hint.SetSize(rect.Width, rect.Height)
+	dm.hint.SetVisible(true)
+	dm.SetTopChild(&dm.hint)
+}
+
+// onWindowDrop docks the specified floating panel when its window is dropped over a docking area.
+func (dm *DockManager) onWindowDrop(item *dockItem, mev *window.MouseEvent) {
+
+	if !item.dragging || mev.Button != window.MouseButtonLeft {
+		return
+	}
+	item.dragging = false
+	dm.hint.SetVisible(false)
+	target, edge, rect := dm.dropTarget(mev.Xpos, mev.Ypos)
+	if rect != nil {
+		dm.detach(item)
+		dm.dock(item, target, edge)
+	}
+	dm.Dispatch(OnDockChange, nil)
+}
+
+// dropTarget returns the leaf node and edge where a window dropped at the specified screen position
+// is docked and the area it will take in the dock manager, or a nil area if it's not docked.
+// The window is tabbed with a docked panel when dropped on its tab headers and docked to one of its
+// edges when dropped near it. If there are no docked panels it's docked when dropped anywhere.
+func (dm *DockManager) dropTarget(x, y float32) (*dockNode, int, *Rect) {
+
+	if dm.root == nil {
+		if !dm.area.ContainsPosition(x, y) {
+			return nil, 0, nil
+		}
+		return nil, DockCenter, &Rect{Width: dm.area.Width(), Height: dm.area.Height()}
+	}
+	leaf := dm.root.leafAt(x, y)
+	if leaf == nil {
+		return nil, 0, nil
+	}
+	px, py := dm.ContentCoords(leaf.tabs.Pospix().X, leaf.tabs.Pospix().Y)
+	rect := &Rect{X: px, Y: py, Width: leaf.tabs.Width(), Height: leaf.tabs.Height()}
+	fx := (x - leaf.tabs.Pospix().X) / rect.Width
+	fy := (y - leaf.tabs.Pospix().Y) / rect.Height
+	if leaf.tabs.TabCount() > 0 && y-leaf.tabs.Pospix().Y < leaf.tabs.TabAt(0).Header().Height() {
+		return leaf, DockCenter, rect
+	}
+
+	// Finds the nearest edge
+	edge := DockLeft
+	dist := fx
+	if 1-fx < dist {
+		edge = DockRight
+		dist = 1 - fx
+	}
+	if fy < dist {
+		edge = DockTop
+		dist = fy
+	}
+	if 1-fy < dist {
+		edge = DockBottom
+		dist = 1 - fy
+	}
+	if dist > dockEdgeZone {
+		return nil, 0, nil
+	}
+	switch edge {
+	case DockLeft:
+		rect.Width *= dockLeafSplit
+	case DockRight:
+		rect.X += rect.Width * (1 - dockLeafSplit)
+		rect.Width *= dockLeafSplit
+	case DockTop:
+		rect.Height *= dockLeafSplit
+	case DockBottom:
+		rect.Y += rect.Height * (1 - dockLeafSplit)
+		rect.Height *= dockLeafSplit
+	}
+	return leaf, edge, rect
+}
@@ -5,20 +5,52 @@
 package gui
 
 import (
+	"math"
+	"strings"
+
 	"github.com/g3n/engine/gui/assets/icon"
+	"github.com/g3n/engine/window"
 )
 
 // DropDown represents a dropdown GUI element.
+// Its list only contains the rows for the items which fit in it,
+// so lists with many thousands of items open instantly.
 type DropDown struct {
-	Panel                        // Embedded panel
-	icon         *Label          // internal label with icon
-	list         *List           // internal list
-	styles       *DropDownStyles // pointer to dropdown styles
-	litem        *ImageLabel     // Item shown in drop box (copy of selected)
-	selItem      *ImageLabel     // selected item from list
-	overDropdown bool
-	overList     bool
-	focus        bool
+	Panel                   // Embedded panel
+	icon    *Label          // internal label with icon
+	list    *Panel          // internal list panel with the filter edit, the rows and the scroll bar
+	styles  *DropDownStyles // pointer to dropdown styles
+	litem   *ImageLabel     // Item shown in drop box (copy of selected)
+	selItem *ImageLabel     // selected item from list
+	tracker StateTracker    // tracker of the dropdown pseudo-state
+
+	listStyles     *ListStyles    // pointer to the styles of the list and its rows
+	items          []dropDownItem // all the items of the list
+	matches        []int          // positions of the items accepted by the filter
+	rows           []*dropDownRow // rows showing the matches from the first one which fit in the list
+	filter         *Edit          // optional filter edit box
+	filterText     string         // current filter text
+	vscroll        *ScrollBar     // scroll bar shown when not all the matches fit in the list
+	scrollBarEvent bool           // list recalculated because of a scroll bar event
+	first          int            // index in matches of the item shown in the first row
+	high           int            // index in matches of the highlighted item or -1
+	selPos         int            // position of the selected item or -1
+	maxVisible     int            // maximum number of items shown in the list
+}
+
+// dropDownItem describes an item of the dropdown list.
+type dropDownItem struct {
+	label    *ImageLabel // item label (nil for separators)
+	disabled bool        // item cannot be selected
+}
+
+// dropDownRow is a row of the dropdown list showing one of the matches.
+type dropDownRow struct {
+	Panel           // Embedded panel
+	line  *Panel    // separator line
+	label IPanel    // item label currently shown
+	match int       // index in matches of the item shown or -1
+	dd    *DropDown // pointer to dropdown
 }
 
 // DropDownStyle contains the styling of a DropDown.
@@ -37,12 +69,14 @@ func NewDropDown(width float32, item *ImageLabel) *DropDown {
 
 	dd := new(DropDown)
 	dd.styles = &StyleDefault().DropDown
+	dd.listStyles = &StyleDefault().List
 	dd.litem = item
+	dd.high = -1
+	dd.selPos = -1
+	dd.maxVisible = 6
 
 	dd.Panel.Initialize(dd, width, 0)
 	dd.Panel.Subscribe(OnMouseDown, dd.onMouse)
-	dd.Panel.Subscribe(OnCursorEnter, dd.onCursor)
-	dd.Panel.Subscribe(OnCursorLeave, dd.onCursor)
 	dd.Panel.Subscribe(OnResize, func(name string, ev interface{}) { dd.recalc() })
 
 	// ListItem
@@ -55,19 +89,21 @@ func NewDropDown(width float32, item *ImageLabel) *DropDown {
 	dd.Panel.Add(dd.icon)
 
 	/// Create list
-	dd.list = NewVList(0, 0)
+	dd.list = NewPanel(0, 0)
 	dd.list.bounded = false
-	dd.list.zLayerDelta = 1
-	dd.list.dropdown = true
+	dd.list.SetLayer(LayerPopup)
 	dd.list.SetVisible(false)
+	// Clicks in the list outside of the rows must not toggle it
+	dd.list.Subscribe(OnMouseDown, func(evname string, ev interface{}) {})
+	dd.list.Subscribe(OnScroll, dd.onScroll)
 
-	dd.Panel.Subscribe(OnKeyDown, dd.list.onKeyEvent)
+	dd.Panel.Subscribe(OnKeyDown, dd.onKey)
+	dd.Panel.Subscribe(OnKeyRepeat, dd.onKey)
 	dd.Subscribe(OnMouseDownOut, func(s string, i interface{}) {
 		// Hide list when clicked out
-		if dd.list.Visible() {
-			dd.list.SetVisible(false)
-		}
+		dd.Close()
 	})
+	dd.tracker.Init(&dd.Panel, dd.update)
 
 	dd.list.Subscribe(OnCursorEnter, func(evname string, ev interface{}) {
 		dd.Dispatch(OnCursorLeave, ev)
@@ -76,7 +112,12 @@ func NewDropDown(width float32, item *ImageLabel) *DropDown {
 		dd.Dispatch(OnCursorEnter, ev)
 	})
 
-	dd.list.Subscribe(OnChange, dd.onListChangeEvent)
+	// Create list scroll bar
+	dd.vscroll = NewVScrollBar(0, 0)
+	dd.vscroll.SetBorders(0, 0, 0, 1)
+	dd.vscroll.SetVisible(false)
+	dd.vscroll.Subscribe(OnChange, dd.onScrollBar)
+	dd.list.Add(dd.vscroll)
 	dd.Panel.Add(dd.list)
 
 	dd.update()
@@ -88,33 +129,190 @@ func NewDropDown(width float32, item *ImageLabel) *DropDown {
 // Add adds a list item at the end of the list
 func (dd *DropDown) Add(item *ImageLabel) {
 
-	dd.list.Add(item)
+	dd.InsertAt(len(dd.items), item)
+}
+
+// AddSeparator adds a separator at the end of the list
+func (dd *DropDown) AddSeparator() {
+
+	dd.insertAt(len(dd.items), dropDownItem{})
 }
 
 // InsertAt inserts a list item at the specified position
 // Returs true if the item was successfully inserted
 func (dd *DropDown) InsertAt(pos int, item *ImageLabel) {
 
-	dd.list.InsertAt(pos, item)
+	dd.insertAt(pos, dropDownItem{label: item})
 }
 
 // RemoveAt removes the list item from the specified position
 // Returs true if the item was successfully removed
 func (dd *DropDown) RemoveAt(pos int) {
 
-	dd.list.RemoveAt(pos)
+	for _, row := range dd.rows {
+		if row.match >= 0 && dd.matches[row.match] == pos {
+			row.setMatch(-1)
+		}
+	}
+	copy(dd.items[pos:], dd.items[pos+1:])
+	dd.items[len(dd.items)-1] = dropDownItem{}
+	dd.items = dd.items[:len(dd.items)-1]
+	if pos == dd.selPos {
+		dd.selPos = -1
+		dd.selItem = nil
+	} else if pos < dd.selPos {
+		dd.selPos--
+	}
+	dd.refresh()
 }
 
 // ItemAt returns the list item at the specified position
+// or nil if the item is a separator
 func (dd *DropDown) ItemAt(pos int) *ImageLabel {
 
-	return dd.list.ItemAt(pos).(*ImageLabel)
+	return dd.items[pos].label
 }
 
-// Len returns the number of items in the dropdown's list.
+// Len returns the number of items in the dropdown's list, including the separators.
 func (dd *DropDown) Len() int {
 
-	return dd.list.Len()
+	return len(dd.items)
+}
+
+// SetItemDisabled sets whether the item at the specified position cannot be selected.
+// Disabled items are shown with the disabled text color and are skipped by the arrow keys.
+func (dd *DropDown) SetItemDisabled(pos int, state bool) {
+
+	item := &dd.items[pos]
+	if item.label == nil || item.disabled == state {
+		return
+	}
+	item.disabled = state
+	if state {
+		item.label.SetColor4(&StyleDefault().Color.TextDis)
+	} else {
+		item.label.SetColor4(&StyleDefault().Label.FgColor)
+	}
+	dd.updateRows()
+}
+
+// ItemDisabled returns whether the item at the specified position cannot be selected.
+func (dd *DropDown) ItemDisabled(pos int) bool {
+
+	return dd.items[pos].disabled
+}
+
+// SetFilterable sets whether the list shows an edit box at its top
+// to filter the items by the text typed in it.
+func (dd *DropDown) SetFilterable(state bool) {
+
+	if state == dd.Filterable() {
+		return
+	}
+	if state {
+		dd.filter = NewEdit(0, "Filter")
+		dd.filter.SetText(dd.filterText)
+		dd.filter.Subscribe(OnChange, func(evname string, ev interface{}) {
+			dd.SetFilter(dd.filter.Text())
+		})
+		dd.filter.Subscribe(OnKeyDown, dd.onKey)
+		dd.filter.Subscribe(OnKeyRepeat, dd.onKey)
+		dd.list.Add(dd.filter)
+	} else {
+		dd.list.Remove(dd.filter)
+		dd.filter = nil
+		dd.SetFilter("")
+	}
+	dd.recalc()
+}
+
+// Filterable returns whether the list shows a filter edit box.
+func (dd *DropDown) Filterable() bool {
+
+	return dd.filter != nil
+}
+
+// SetFilter sets the text which the items shown in the list must contain, ignoring case.
+// Separators are only shown when the filter is empty.
+func (dd *DropDown) SetFilter(text string) {
+
+	if dd.filter != nil && dd.filter.Text() != text {
+		dd.filter.SetText(text)
+	}
+	if text == dd.filterText {
+		return
+	}
+	dd.filterText = text
+	dd.first = 0
+	dd.high = -1
+	dd.refresh()
+}
+
+// Filter returns the current filter text.
+func (dd *DropDown) Filter() string {
+
+	return dd.filterText
+}
+
+// SetMaxVisibleItems sets the maximum number of items shown in the list at once. The default is 6.
+func (dd *DropDown) SetMaxVisibleItems(count int) {
+
+	dd.maxVisible = count
+	dd.recalc()
+}
+
+// MaxVisibleItems returns the maximum number of items shown in the list at once.
+func (dd *DropDown) MaxVisibleItems() int {
+
+	return dd.maxVisible
+}
+
+// Open shows the list, highlighting the selected item, and dispatches OnOpen.
+func (dd *DropDown) Open() {
+
+	if dd.list.Visible() {
+		return
+	}
+	dd.updateMatches()
+	dd.high = -1
+	for m, pos := range dd.matches {
+		if pos == dd.selPos {
+			dd.high = m
+			break
+		}
+	}
+	dd.first = 0
+	dd.list.SetVisible(true)
+	dd.recalc()
+	dd.scrollTo(dd.high)
+	if dd.filter != nil {
+		Manager().SetKeyFocus(dd.filter)
+	} else {
+		Manager().SetKeyFocus(dd)
+	}
+	dd.Dispatch(OnOpen, nil)
+}
+
+// Close hides the list and dispatches OnClose.
+func (dd *DropDown) Close() {
+
+	if !dd.list.Visible() {
+		return
+	}
+	dd.list.SetVisible(false)
+	for _, row := range dd.rows {
+		row.setMatch(-1)
+	}
+	if dd.filter != nil {
+		Manager().SetKeyFocus(dd)
+	}
+	dd.Dispatch(OnClose, nil)
+}
+
+// IsOpen returns whether the list is shown.
+func (dd *DropDown) IsOpen() bool {
+
+	return dd.list.Visible()
 }
 
 // Selected returns the currently selected item or nil if no item was selected
@@ -126,24 +324,24 @@ func (dd *DropDown) Selected() *ImageLabel {
 // SelectedPos returns the currently selected position or -1 if no item was selected
 func (dd *DropDown) SelectedPos() int {
 
-	return dd.list.selected()
+	return dd.selPos
 }
 
 // SetSelected sets the selected item
 func (dd *DropDown) SetSelected(item *ImageLabel) {
 
-	dd.list.SetSelected(dd.selItem, false)
-	dd.list.SetSelected(item, true)
-	dd.copySelected()
-	dd.update()
+	for pos := range dd.items {
+		if dd.items[pos].label == item {
+			dd.setSelected(pos)
+			return
+		}
+	}
 }
 
 // SelectPos selects the item at the specified position
 func (dd *DropDown) SelectPos(pos int) {
 
-	dd.list.SetSelected(dd.selItem, false)
-	dd.list.SelectPos(pos, true)
-	dd.Dispatch(OnChange, nil)
+	dd.setSelected(pos)
 }
 
 // SetStyles sets the drop down styles overriding the default style
@@ -153,48 +351,239 @@ func (dd *DropDown) SetStyles(dds *DropDownStyles) {
 	dd.update()
 }
 
+// insertAt inserts the specified item at the specified position
+func (dd *DropDown) insertAt(pos int, item dropDownItem) {
+
+	dd.items = append(dd.items, dropDownItem{})
+	copy(dd.items[pos+1:], dd.items[pos:])
+	dd.items[pos] = item
+	if pos <= dd.selPos {
+		dd.selPos++
+	}
+	dd.refresh()
+}
+
+// setSelected selects the item at the specified position,
+// copies it to the dropdown panel and dispatches OnChange
+func (dd *DropDown) setSelected(pos int) {
+
+	item := dd.items[pos]
+	if item.label == nil {
+		return
+	}
+	dd.selPos = pos
+	dd.selItem = item.label
+	dd.litem.CopyFields(dd.selItem)
+	dd.litem.SetWidth(dd.selItem.Width())
+	dd.recalc()
+	dd.Dispatch(OnChange, nil)
+}
+
+// selectable returns whether the item at the specified position can be selected
+func (dd *DropDown) selectable(pos int) bool {
+
+	return dd.items[pos].label != nil && !dd.items[pos].disabled
+}
+
 // onMouse receives subscribed mouse events over the dropdown
 func (dd *DropDown) onMouse(evname string, ev interface{}) {
 
-	Manager().SetKeyFocus(dd.list)
+	Manager().SetKeyFocus(dd)
 	if evname == OnMouseDown {
-		dd.list.SetVisible(!dd.list.Visible())
+		if dd.list.Visible() {
+			dd.Close()
+		} else {
+			dd.Open()
+		}
 		return
 	}
 }
 
-// onCursor receives subscribed cursor events over the dropdown
-func (dd *DropDown) onCursor(evname string, ev interface{}) {
+// onKey receives subscribed key events for the dropdown and its filter edit
+func (dd *DropDown) onKey(evname string, ev interface{}) {
+
+	kev := ev.(*window.KeyEvent)
+	// List closed: the arrow keys select the next or previous item
+	if !dd.list.Visible() {
+		switch kev.Key {
+		case window.KeyDown:
+			if pos := dd.nextSelectable(dd.selPos, 1); pos >= 0 {
+				dd.setSelected(pos)
+			}
+		case window.KeyUp:
+			if pos := dd.nextSelectable(dd.selPos, -1); pos >= 0 {
+				dd.setSelected(pos)
+			}
+		case window.KeyEnter:
+			dd.Open()
+		}
+		return
+	}
 
-	if evname == OnCursorEnter {
-		dd.overDropdown = true
+	// List open: the arrow keys move the highlight
+	switch kev.Key {
+	case window.KeyDown:
+		dd.highlightNext(1)
+	case window.KeyUp:
+		dd.highlightNext(-1)
+	case window.KeyEnter:
+		if dd.high >= 0 {
+			dd.setSelected(dd.matches[dd.high])
+		}
+		dd.Close()
+	case window.KeyEscape:
+		dd.Close()
 	}
-	if evname == OnCursorLeave {
-		dd.overDropdown = false
+}
+
+// nextSelectable returns the position of the next selectable item
+// after the specified position in the specified direction or -1
+func (dd *DropDown) nextSelectable(pos, dir int) int {
+
+	for pos += dir; pos >= 0 && pos < len(dd.items); pos += dir {
+		if dd.selectable(pos) {
+			return pos
+		}
 	}
-	dd.update()
+	return -1
 }
 
-// copySelected copy to the dropdown panel the selected item
-// from the list.
-func (dd *DropDown) copySelected() {
+// highlightNext highlights the next selectable match in the specified direction
+func (dd *DropDown) highlightNext(dir int) {
 
-	selected := dd.list.Selected()
-	if len(selected) > 0 {
-		dd.selItem = selected[0].(*ImageLabel)
-		dd.litem.CopyFields(dd.selItem)
-		dd.litem.SetWidth(dd.selItem.Width())
-		dd.recalc()
-		dd.Dispatch(OnChange, nil)
+	m := dd.high
+	if m < 0 && dir < 0 {
+		m = len(dd.matches)
+	}
+	for m += dir; m >= 0 && m < len(dd.matches); m += dir {
+		if dd.selectable(dd.matches[m]) {
+			dd.high = m
+			dd.scrollTo(m)
+			return
+		}
+	}
+}
+
+// onScroll receives mouse scroll events over the list
+func (dd *DropDown) onScroll(evname string, ev interface{}) {
+
+	sev := ev.(*window.ScrollEvent)
+	if sev.Yoffset > 0 && dd.first > 0 {
+		dd.first--
+	} else if sev.Yoffset < 0 {
+		dd.first++
 	} else {
 		return
 	}
+	dd.recalcList()
+}
+
+// onScrollBar is called when the list scroll bar value changes
+func (dd *DropDown) onScrollBar(evname string, ev interface{}) {
+
+	maxFirst := dd.maxFirst()
+	first := int(math.Floor(float64(maxFirst)*dd.vscroll.Value() + 0.5))
+	if first == dd.first {
+		return
+	}
+	dd.scrollBarEvent = true
+	dd.first = first
+	dd.recalcList()
+}
+
+// updateMatches rebuilds the positions of the items accepted by the filter
+func (dd *DropDown) updateMatches() {
+
+	dd.matches = dd.matches[:0]
+	if dd.filterText == "" {
+		for pos := range dd.items {
+			dd.matches = append(dd.matches, pos)
+		}
+		return
+	}
+	filter := strings.ToLower(dd.filterText)
+	for pos, item := range dd.items {
+		if item.label != nil && strings.Contains(strings.ToLower(item.label.Text()), filter) {
+			dd.matches = append(dd.matches, pos)
+		}
+	}
+}
+
+// refresh updates the open list after its items or filter changed
+func (dd *DropDown) refresh() {
+
+	if !dd.list.Visible() {
+		return
+	}
+	for _, row := range dd.rows {
+		row.setMatch(-1)
+	}
+	dd.updateMatches()
+	if dd.high >= len(dd.matches) {
+		dd.high = -1
+	}
+	dd.recalc()
+}
+
+// rowHeight returns the height of the rows of items
+func (dd *DropDown) rowHeight() float32 {
+
+	s := &dd.listStyles.Item.Normal
+	return dd.litem.Height() + s.Margin.Top + s.Margin.Bottom + s.Border.Top + s.Border.Bottom + s.Padding.Top + s.Padding.Bottom
+}
+
+// matchHeight returns the height of the row showing the specified match
+func (dd *DropDown) matchHeight(m int) float32 {
+
+	if dd.items[dd.matches[m]].label == nil {
+		return float32(math.Ceil(float64(dd.rowHeight()) / 2))
+	}
+	return dd.rowHeight()
+}
+
+// filterHeight returns the height of the filter edit box if shown
+func (dd *DropDown) filterHeight() float32 {
+
+	if dd.filter == nil {
+		return 0
+	}
+	return dd.filter.Height()
 }
 
-// onListChangeEvent is called when an item in the list is selected
-func (dd *DropDown) onListChangeEvent(evname string, ev interface{}) {
+// maxFirst returns the maximum index in matches of the item shown in the first row
+func (dd *DropDown) maxFirst() int {
+
+	height := dd.list.ContentHeight() - dd.filterHeight()
+	var total float32
+	m := len(dd.matches) - 1
+	for ; m >= 0; m-- {
+		total += dd.matchHeight(m)
+		if total > height {
+			break
+		}
+	}
+	return m + 1
+}
 
-	dd.copySelected()
+// scrollTo changes the first row if necessary to show the specified match
+// and recalculates the list
+func (dd *DropDown) scrollTo(m int) {
+
+	if m >= 0 {
+		if m < dd.first {
+			dd.first = m
+		} else {
+			height := dd.list.ContentHeight() - dd.filterHeight()
+			total := dd.matchHeight(m)
+			f := m
+			for f > dd.first && total+dd.matchHeight(f-1) <= height {
+				f--
+				total += dd.matchHeight(f)
+			}
+			dd.first = f
+		}
+	}
+	dd.recalcList()
 }
 
 // recalc recalculates the dimensions and positions of the dropdown
@@ -208,30 +597,114 @@ func (dd *DropDown) recalc() {
 	// List item position and width
 	ipan := dd.litem.GetPanel()
 	ipan.SetPosition(0, 0)
-	height := ipan.Height()
+
+	// List height for the first matches up to the maximum number of visible items
+	var height float32
+	maxHeight := float32(dd.maxVisible) * dd.rowHeight()
+	for m := 0; m < len(dd.matches) && height < maxHeight; m++ {
+		height += dd.matchHeight(m)
+	}
+	if height < dd.rowHeight() {
+		height = dd.rowHeight()
+	}
+	if height > maxHeight {
+		height = maxHeight
+	}
 
 	// List position
 	dd.list.SetWidth(dd.Panel.Width())
-	dd.list.SetHeight(6*height + 1)
+	dd.list.SetContentHeight(dd.filterHeight() + height)
 	dd.list.SetPositionX(0)
 	dd.list.SetPositionY(dd.Panel.Height())
+	dd.recalcList()
+}
+
+// recalcList recalculates the positions of the filter edit, the rows
+// and the scroll bar of the list
+func (dd *DropDown) recalcList() {
+
+	if !dd.list.Visible() {
+		return
+	}
+	width := dd.list.ContentWidth()
+	height := dd.list.ContentHeight()
+
+	// Filter edit box
+	posY := dd.filterHeight()
+	if dd.filter != nil {
+		dd.filter.SetPosition(0, 0)
+		editWidth := int(width - dd.filter.Width() + dd.filter.ContentWidth())
+		if dd.filter.width != editWidth {
+			dd.filter.width = editWidth
+			dd.filter.update()
+		}
+	}
+
+	// Clamps the first row
+	maxFirst := dd.maxFirst()
+	if dd.first > maxFirst {
+		dd.first = maxFirst
+	}
+	var scrollWidth float32 = 20
+	scroll := maxFirst > 0
+	if scroll {
+		width -= scrollWidth
+	}
+
+	// Shows the matches which fit in the list from the first one
+	count := 0
+	for m := dd.first; m < len(dd.matches) && posY < height; m++ {
+		if count == len(dd.rows) {
+			dd.rows = append(dd.rows, newDropDownRow(dd))
+		}
+		row := dd.rows[count]
+		row.setMatch(m)
+		row.SetPosition(0, posY)
+		row.SetSize(width, dd.matchHeight(m))
+		posY += row.Height()
+		count++
+	}
+	for _, row := range dd.rows[count:] {
+		row.setMatch(-1)
+	}
+	dd.updateRows()
+
+	// Scroll bar
+	if scroll {
+		top := dd.filterHeight()
+		dd.vscroll.SetSize(scrollWidth, height-top)
+		dd.vscroll.SetPosition(width, top)
+		dd.vscroll.SetButtonSize((height - top) * float32(count) / float32(len(dd.matches)))
+		if !dd.scrollBarEvent {
+			dd.vscroll.SetValue(float32(dd.first) / float32(maxFirst))
+		}
+	}
+	dd.vscroll.SetVisible(scroll)
+	dd.scrollBarEvent = false
+}
+
+// updateRows updates the visual state of the rows
+func (dd *DropDown) updateRows() {
+
+	for _, row := range dd.rows {
+		row.update()
+	}
 }
 
 // update updates the visual state
 func (dd *DropDown) update() {
 
-	if dd.overDropdown || dd.overList {
+	dd.list.ApplyStyle(&dd.listStyles.Scroller.Normal.PanelStyle)
+	switch dd.tracker.State() {
+	case StyleDisabled:
+		dd.applyStyle(&dd.styles.Disabled)
+	case StylePressed, StyleOver:
 		dd.applyStyle(&dd.styles.Over)
-		dd.list.ApplyStyle(StyleOver)
-		return
-	}
-	if dd.focus {
+	case StyleFocus:
 		dd.applyStyle(&dd.styles.Focus)
-		dd.list.ApplyStyle(StyleFocus)
-		return
+	default:
+		dd.applyStyle(&dd.styles.Normal)
 	}
-	dd.applyStyle(&dd.styles.Normal)
-	dd.list.ApplyStyle(StyleNormal)
 }
 
 // applyStyle applies the specified style
@@ -239,3 +712,83 @@ func (dd *DropDown) applyStyle(s *DropDownStyle) {
 
 	dd.Panel.ApplyStyle(&s.PanelStyle)
 }
+
+//
+// dropDownRow methods
+//
+
+// newDropDownRow creates and returns a pointer to a new row of the specified dropdown list
+func newDropDownRow(dd *DropDown) *dropDownRow {
+
+	row := new(dropDownRow)
+	row.Panel.Initialize(row, 0, 0)
+	row.dd = dd
+	row.match = -1
+	row.line = NewPanel(0, 1)
+	row.line.SetColor4(&StyleDefault().Color.TextDis)
+	row.line.SetVisible(false)
+	row.Panel.Add(row.line)
+	row.Subscribe(OnMouseDown, row.onMouse)
+	row.Subscribe(OnCursorEnter, row.onCursor)
+	row.Subscribe(OnResize, func(evname string, ev interface{}) {
+		row.line.SetSize(row.ContentWidth(), 1)
+		row.line.SetPosition(0, float32(math.Floor(float64(row.ContentHeight())/2)))
+	})
+	dd.list.Add(row)
+	return row
+}
+
+// setMatch sets the index in matches of the item shown by this row or -1 to hide it
+func (row *dropDownRow) setMatch(m int) {
+
+	var label IPanel
+	if m >= 0 {
+		if il := row.dd.items[row.dd.matches[m]].label; il != nil {
+			label = il
+		}
+	}
+	if row.label != nil && row.label != label {
+		row.Remove(row.label)
+	}
+	if label != nil && row.label != label {
+		row.Add(label)
+		label.GetPanel().SetPosition(0, 0)
+	}
+	row.label = label
+	row.match = m
+	row.line.SetVisible(m >= 0 && label == nil)
+	row.SetVisible(m >= 0)
+}
+
+// onMouse selects the item of this row and closes the list
+func (row *dropDownRow) onMouse(evname string, ev interface{}) {
+
+	dd := row.dd
+	if row.match < 0 || !dd.selectable(dd.matches[row.match]) {
+		return
+	}
+	dd.setSelected(dd.matches[row.match])
+	dd.Close()
+}
+
+// onCursor highlights the item of this row
+func (row *dropDownRow) onCursor(evname string, ev interface{}) {
+
+	dd := row.dd
+	if row.match < 0 || !dd.selectable(dd.matches[row.match]) {
+		return
+	}
+	dd.high = row.match
+	dd.updateRows()
+}
+
+// update updates the visual state of this row
+func (row *dropDownRow) update() {
+
+	styles := row.dd.listStyles.Item
+	if row.match >= 0 && row.match == row.dd.high {
+		row.ApplyStyle(&styles.Selected.PanelStyle)
+		return
+	}
+	row.ApplyStyle(&styles.Normal.PanelStyle)
+}
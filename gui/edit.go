@@ -5,6 +5,7 @@
 package gui
 
 import (
+	"regexp"
 	"strings"
 	"time"
 
@@ -15,20 +16,22 @@ import (
 
 // Edit represents a text edit box GUI element
 type Edit struct {
-	Label              // Embedded label
-	MaxLength   int    // Maximum number of characters
-	width       int    // edit width in pixels
-	placeHolder string // place holder string
-	text        string // current edit text
-	col         int    // current column
-	selStart    int    // start column of selection. always < selEnd. if selStart == selEnd then nothing is selected.
-	selEnd      int    // end column of selection. always > selStart. if selStart == selEnd then nothing is selected.
-	focus       bool   // key focus flag
-	cursorOver  bool
-	mouseDrag   bool // true when the mouse is moved while left mouse button is down. Used for selecting text via mouse
+	Label                    // Embedded label
+	MaxLength   int          // Maximum number of characters
+	width       int          // edit width in pixels
+	placeHolder string       // place holder string
+	text        string       // current edit text
+	comp        string       // text being composed with an input method editor (IME)
+	col         int          // current column
+	selStart    int          // start column of selection. always < selEnd. if selStart == selEnd then nothing is selected.
+	selEnd      int          // end column of selection. always > selStart. if selStart == selEnd then nothing is selected.
+	focus       bool         // key focus flag
+	tracker     StateTracker // tracker of the edit pseudo-state
+	mouseDrag   bool         // true when the mouse is moved while left mouse button is down. Used for selecting text via mouse
 	blinkID     int
 	caretOn     bool
 	styles      *EditStyles
+	validator   func(string) bool // optional function which validates the text resulting from user input
 }
 
 // EditStyle contains the styling of an Edit
@@ -55,6 +58,9 @@ const (
 	blinkTime   = 1000
 )
 
+// numericMask accepts decimal numbers, including the partial numbers typed while editing
+var numericMask = regexp.MustCompile(`^[-+]?[0-9]*\.?[0-9]*$`)
+
 // NewEdit creates and returns a pointer to a new edit widget
 func NewEdit(width int, placeHolder string) *Edit {
 
@@ -74,23 +80,31 @@ func NewEdit(width int, placeHolder string) *Edit {
 	ed.Label.Subscribe(OnKeyDown, ed.onKey)
 	ed.Label.Subscribe(OnKeyRepeat, ed.onKey)
 	ed.Label.Subscribe(OnChar, ed.onChar)
+	ed.Label.Subscribe(OnComposition, ed.onComposition)
 	ed.Label.Subscribe(OnMouseDown, ed.onMouseDown)
 	ed.Label.Subscribe(OnMouseUp, ed.onMouseUp)
 	ed.Label.Subscribe(OnCursorEnter, ed.onCursor)
 	ed.Label.Subscribe(OnCursorLeave, ed.onCursor)
 	ed.Label.Subscribe(OnCursor, ed.onCursor)
-	ed.Label.Subscribe(OnEnable, func(evname string, ev interface{}) { ed.update() })
+	ed.Label.Subscribe(OnContentScale, func(evname string, ev interface{}) { ed.update() })
+	ed.Subscribe(OnFocus, ed.onFocus)
 	ed.Subscribe(OnFocusLost, ed.OnFocusLost)
+	ed.tracker.Init(&ed.Label.Panel, ed.update)
 
 	ed.update()
 	return ed
 }
 
-// SetText sets this edit text
+// SetText sets this edit text.
+// The text is not changed if it is rejected by the validator or mask.
 func (ed *Edit) SetText(newText string) *Edit {
 
 	// Remove new lines from text
-	ed.text = strings.Replace(newText, "\n", "", -1)
+	newText = strings.Replace(newText, "\n", "", -1)
+	if !ed.accepts(newText) {
+		return ed
+	}
+	ed.text = newText
 	ed.col = text.StrCount(ed.text)
 	ed.selStart = ed.col
 	ed.selEnd = ed.col
@@ -141,13 +155,74 @@ func (ed *Edit) SetStyles(es *EditStyles) {
 	ed.update()
 }
 
+// SetMaxLength sets the maximum number of characters accepted by this edit
+func (ed *Edit) SetMaxLength(length int) *Edit {
+
+	ed.MaxLength = length
+	return ed
+}
+
+// SetValidator sets a function which is called with the text which would result
+// from user input or SetText. If the function returns false the change is rejected.
+// Setting a nil validator removes any previous validator or mask.
+func (ed *Edit) SetValidator(validator func(text string) bool) *Edit {
+
+	ed.validator = validator
+	return ed
+}
+
+// SetMask sets a regular expression which the edit text must match after each change.
+// As the text is checked while it is being typed, the expression must also match
+// all partial texts leading to a complete one.
+// An empty expression removes any previous validator or mask.
+func (ed *Edit) SetMask(expr string) error {
+
+	if expr == "" {
+		ed.validator = nil
+		return nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return err
+	}
+	ed.validator = re.MatchString
+	return nil
+}
+
+// SetNumeric sets if this edit only accepts input which forms a decimal number,
+// optionally signed and with a fractional part.
+// Any previous validator or mask is replaced.
+func (ed *Edit) SetNumeric(state bool) *Edit {
+
+	if state {
+		ed.validator = numericMask.MatchString
+	} else {
+		ed.validator = nil
+	}
+	return ed
+}
+
 // LostKeyFocus satisfies the IPanel interface and is called by gui root
 // container when the panel loses the key focus
 func (ed *Edit) OnFocusLost(evname string, ev interface{}) {
 
 	ed.focus = false
+	ed.comp = ""
 	ed.update()
 	Manager().ClearTimeout(ed.blinkID)
+	window.Get().SetTextInput(false, 0, 0, 0, 0)
+}
+
+// onFocus is called when the edit receives the key focus
+// and enables the text input with an IME at its caret.
+func (ed *Edit) onFocus(evname string, ev interface{}) {
+
+	if !ed.focus {
+		ed.focus = true
+		ed.blinkID = Manager().SetInterval(750*time.Millisecond, nil, ed.blink)
+		ed.update()
+	}
+	ed.redraw(true)
 }
 
 // CursorPos sets the position of the cursor at the
@@ -306,10 +381,14 @@ func (ed *Edit) CursorBack() {
 
 	if ed.selStart == ed.selEnd {
 		if ed.col > 0 {
+			newText := text.StrRemove(ed.text, ed.col-1)
+			if !ed.accepts(newText) {
+				return
+			}
 			ed.col--
 			ed.selStart = ed.col
 			ed.selEnd = ed.col
-			ed.text = text.StrRemove(ed.text, ed.col)
+			ed.text = newText
 			ed.redraw(ed.focus)
 			ed.Dispatch(OnChange, nil)
 		}
@@ -342,7 +421,11 @@ func (ed *Edit) CursorDelete() {
 
 	if ed.selStart == ed.selEnd {
 		if ed.col < text.StrCount(ed.text) {
-			ed.text = text.StrRemove(ed.text, ed.col)
+			newText := text.StrRemove(ed.text, ed.col)
+			if !ed.accepts(newText) {
+				return
+			}
+			ed.text = newText
 			ed.redraw(ed.focus)
 			ed.Dispatch(OnChange, nil)
 		}
@@ -351,10 +434,11 @@ func (ed *Edit) CursorDelete() {
 	}
 }
 
-// DeleteSelection deletes the selected characters. Does nothing if nothing is selected.
+// DeleteSelection deletes the selected characters. Does nothing if nothing is selected
+// or if the resulting text is rejected by the validator or mask.
 func (ed *Edit) DeleteSelection() {
 
-	if ed.selStart == ed.selEnd {
+	if ed.selStart == ed.selEnd || !ed.accepts(ed.replaceSelection("")) {
 		return
 	}
 
@@ -377,21 +461,12 @@ func (ed *Edit) DeleteSelection() {
 // If text is selected the selected text gets overwritten
 func (ed *Edit) CursorInput(s string) {
 
-	if ed.selStart != ed.selEnd {
-		ed.DeleteSelection()
-	}
-	if text.StrCount(ed.text) >= ed.MaxLength {
+	// Checks if the text resulting from the input is accepted
+	newText := ed.replaceSelection(s)
+	if text.StrCount(newText) > ed.MaxLength || !ed.accepts(newText) {
 		return
 	}
 
-	// Set new text with included input
-	var newText string
-	if ed.col < text.StrCount(ed.text) {
-		newText = text.StrInsert(ed.text, s, ed.col)
-	} else {
-		newText = ed.text + s
-	}
-
 	// Checks if new text exceeds edit width
 	width, _ := ed.Label.font.MeasureText(newText)
 	if float32(width) / float32(ed.Label.font.ScaleX()) + editMarginX + float32(1) >= ed.Label.ContentWidth() {
@@ -399,7 +474,7 @@ func (ed *Edit) CursorInput(s string) {
 	}
 
 	ed.text = newText
-	ed.col++
+	ed.col = ed.selStart + text.StrCount(s)
 	ed.selStart = ed.col
 	ed.selEnd = ed.col
 
@@ -407,13 +482,84 @@ func (ed *Edit) CursorInput(s string) {
 	ed.redraw(ed.focus)
 }
 
+// Copy copies the selected text to the clipboard. Does nothing if nothing is selected.
+func (ed *Edit) Copy() {
+
+	if ed.selStart == ed.selEnd {
+		return
+	}
+	window.Get().SetClipboardString(ed.SelectedText())
+}
+
+// Cut copies the selected text to the clipboard and deletes it. Does nothing if nothing is selected.
+func (ed *Edit) Cut() {
+
+	ed.Copy()
+	ed.DeleteSelection()
+}
+
+// Paste inserts the text of the clipboard at the current cursor position
+// replacing the selected text. Characters after a line break or beyond the
+// maximum length are ignored and the text is not pasted if it is rejected by the validator or mask.
+func (ed *Edit) Paste() {
+
+	s := window.Get().GetClipboardString()
+	if i := strings.IndexAny(s, "\r\n"); i >= 0 {
+		s = s[:i]
+	}
+	room := ed.MaxLength - text.StrCount(ed.text) + ed.selEnd - ed.selStart
+	if room <= 0 {
+		return
+	}
+	ed.CursorInput(text.StrPrefix(s, room))
+}
+
+// replaceSelection returns the text resulting from replacing the selected text,
+// or inserting at the cursor position if nothing is selected, with the specified string.
+func (ed *Edit) replaceSelection(s string) string {
+
+	prefix := text.StrPrefix(ed.text, ed.selStart)
+	suffix := ed.text[len(text.StrPrefix(ed.text, ed.selEnd)):]
+	return prefix + s + suffix
+}
+
+// accepts returns if the specified text is accepted by the validator or mask, if any.
+func (ed *Edit) accepts(s string) bool {
+
+	return ed.validator == nil || ed.validator(s)
+}
+
 // redraw redraws the text showing the caret if specified
 // the selection caret is always shown (when text is selected)
 func (ed *Edit) redraw(caret bool) {
 
 	line := 0
-	scaleX, _ := window.Get().GetScale()
-	ed.Label.setTextCaret(ed.text, editMarginX, int(float64(ed.width) * scaleX), caret, line, ed.col, ed.selStart, ed.selEnd)
+	scaleX, _ := renderScale()
+	msg := ed.text
+	col, selStart, selEnd := ed.col, ed.selStart, ed.selEnd
+	compStart, compEnd := 0, 0
+	if ed.comp != "" {
+		// Shows the text being composed underlined replacing the selection, with the caret after it
+		suffix := ed.text[len(text.StrPrefix(ed.text, ed.selEnd)):]
+		msg = text.StrPrefix(ed.text, ed.selStart) + ed.comp + suffix
+		compStart = ed.selStart
+		compEnd = compStart + text.StrCount(ed.comp)
+		col, selStart, selEnd = compEnd, compEnd, compEnd
+	}
+	ed.Label.setTextCaret(msg, editMarginX, int(float64(ed.width)*scaleX), caret, line, col, selStart, selEnd, compStart, compEnd)
+	if ed.focus {
+		ed.updateTextInput(msg, col)
+	}
+}
+
+// updateTextInput enables the text input with an IME at the caret in the specified column of the text,
+// so the IME shows its candidate window next to it.
+func (ed *Edit) updateTextInput(msg string, col int) {
+
+	width, _ := ed.Label.font.MeasureText(text.StrPrefix(msg, col))
+	x := ed.pospix.X + editMarginX + float32(float64(width)/ed.Label.font.ScaleX())
+	scale := Manager().Scale()
+	window.Get().SetTextInput(true, x*scale, ed.pospix.Y*scale, scale, ed.Height()*scale)
 }
 
 // onKey receives subscribed key events
@@ -459,6 +605,12 @@ func (ed *Edit) onKey(evname string, ev interface{}) {
 		switch kev.Key {
 		case window.KeyA:
 			ed.SelectAll()
+		case window.KeyC:
+			ed.Copy()
+		case window.KeyX:
+			ed.Cut()
+		case window.KeyV:
+			ed.Paste()
 		}
 	}
 }
@@ -470,6 +622,18 @@ func (ed *Edit) onChar(evname string, ev interface{}) {
 	ed.CursorInput(string(cev.Char))
 }
 
+// onComposition receives subscribed IME composition events
+func (ed *Edit) onComposition(evname string, ev interface{}) {
+
+	cev := ev.(*window.CompositionEvent)
+	if cev.Active {
+		ed.comp = cev.Text
+	} else {
+		ed.comp = ""
+	}
+	ed.redraw(ed.focus)
+}
+
 // onMouseDown receives subscribed mouse down events
 func (ed *Edit) onMouseDown(evname string, ev interface{}) {
 
@@ -495,14 +659,18 @@ func (ed *Edit) onMouseDown(evname string, ev interface{}) {
 // or setting the text selection when the mouse is dragged
 func (ed *Edit) handleMouse(mouseX float32, dragged bool) {
 
-	// Find clicked column
+	// Find the column nearest to the click: the caret is placed after a character
+	// only if its right half is clicked, which matters for wide CJK characters
 	var nchars int
+	posx := mouseX - ed.pospix.X - editMarginX
+	prev := float32(0)
 	for nchars = 1; nchars <= text.StrCount(ed.text); nchars++ {
 		width, _ := ed.Label.font.MeasureText(text.StrPrefix(ed.text, nchars))
-		posx := mouseX - ed.pospix.X
-		if posx < editMarginX + float32(float64(width) / ed.Label.font.ScaleX()) {
+		next := float32(float64(width) / ed.Label.font.ScaleX())
+		if posx < (prev+next)/2 {
 			break
 		}
+		prev = next
 	}
 	if !ed.focus {
 		ed.focus = true
@@ -537,15 +705,11 @@ func (ed *Edit) onCursor(evname string, ev interface{}) {
 
 	if evname == OnCursorEnter {
 		window.Get().SetCursor(window.IBeamCursor)
-		ed.cursorOver = true
-		ed.update()
 		return
 	}
 	if evname == OnCursorLeave {
 		window.Get().SetCursor(window.ArrowCursor)
-		ed.cursorOver = false
 		ed.mouseDrag = false
-		ed.update()
 		return
 	}
 	if ed.mouseDrag {
@@ -572,19 +736,16 @@ func (ed *Edit) blink(arg interface{}) {
 // update updates the visual state
 func (ed *Edit) update() {
 
-	if !ed.Enabled() {
+	switch ed.tracker.State() {
+	case StyleDisabled:
 		ed.applyStyle(&ed.styles.Disabled)
-		return
-	}
-	if ed.cursorOver {
+	case StylePressed, StyleOver:
 		ed.applyStyle(&ed.styles.Over)
-		return
-	}
-	if ed.focus {
+	case StyleFocus:
 		ed.applyStyle(&ed.styles.Focus)
-		return
+	default:
+		ed.applyStyle(&ed.styles.Normal)
 	}
-	ed.applyStyle(&ed.styles.Normal)
 }
 
 // applyStyle applies the specified style
@@ -598,9 +759,9 @@ func (ed *Edit) applyStyle(s *EditStyle) {
 	//ed.Label.SetBgAlpha(s.BgAlpha)
 
 	if !ed.focus && len(ed.text) == 0 && len(ed.placeHolder) > 0 {
-		scaleX, _ := window.Get().GetScale()
+		scaleX, _ := renderScale()
 		ed.Label.SetColor4(&s.HolderColor)
-		ed.Label.setTextCaret(ed.placeHolder, editMarginX, int(float64(ed.width) * scaleX), false, -1, ed.col, ed.selStart, ed.selEnd)
+		ed.Label.setTextCaret(ed.placeHolder, editMarginX, int(float64(ed.width) * scaleX), false, -1, ed.col, ed.selStart, ed.selEnd, 0, 0)
 	} else {
 		ed.Label.SetColor4(&s.FgColor)
 		ed.redraw(ed.focus)
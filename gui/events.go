@@ -15,6 +15,7 @@ const (
 	OnMouseDown = window.OnMouseDown // Any mouse button is pressed
 	OnMouseUp   = window.OnMouseUp   // Any mouse button is released
 	OnScroll    = window.OnScroll    // Scrolling mouse wheel
+	OnDrop      = window.OnDrop      // Files dropped from the operating system (the target is the panel under the drop position)
 
 	// Events sent to all panels except the ancestors of the target panel
 	OnMouseDownOut = "gui.OnMouseDownOut" // Any mouse button is pressed
@@ -33,16 +34,22 @@ const (
 	OnFocusLost = "gui.OnFocusLost" // Keyboard events will stop being sent to the receiving IDispatcher
 
 	// Events sent to the key-focused IDispatcher
-	OnKeyDown   = window.OnKeyDown   // A key is pressed
-	OnKeyUp     = window.OnKeyUp     // A key is released
-	OnKeyRepeat = window.OnKeyRepeat // A key was pressed and is now automatically repeating
-	OnChar      = window.OnChar      // A unicode key is pressed
+	OnKeyDown     = window.OnKeyDown     // A key is pressed
+	OnKeyUp       = window.OnKeyUp       // A key is released
+	OnKeyRepeat   = window.OnKeyRepeat   // A key was pressed and is now automatically repeating
+	OnChar        = window.OnChar        // A unicode key is pressed
+	OnComposition = window.OnComposition // The text composed with an input method editor (IME) changed
 )
 
 const (
 	OnResize     = "gui.OnResize"     // Panel size changed (no parameters)
-	OnEnable     = "gui.OnEnable"     // Panel enabled/disabled (no parameters)
+	OnEnable     = "gui.OnEnable"     // Panel or ancestor panel enabled/disabled (no parameters)
 	OnClick      = "gui.OnClick"      // Widget clicked by mouse left button or via key press
 	OnChange     = "gui.OnChange"     // Value was changed. Emitted by List, DropDownList, CheckBox and Edit
 	OnRadioGroup = "gui.OnRadioGroup" // Radio button within a group changed state
+	OnOpen       = "gui.OnOpen"       // Popup list was opened. Emitted by DropDown
+	OnClose      = "gui.OnClose"      // Popup list was closed. Emitted by DropDown
+
+	// Event sent to all panels and to non-GUI
+	OnContentScale = window.OnContentScale // Window DPI scale changed (the panels redraw their images at the new scale)
 )
@@ -15,7 +15,7 @@ type Folder struct {
 	icon         Label  // Folder icon
 	contentPanel IPanel // Content panel
 	styles       *FolderStyles
-	cursorOver   bool
+	tracker      StateTracker // tracker of the folder pseudo-state
 	alignRight   bool
 }
 
@@ -68,8 +68,7 @@ func (f *Folder) Initialize(text string, width float32, contentPanel IPanel) {
 
 	// Set event callbacks
 	f.Panel.Subscribe(OnMouseDown, f.onMouse)
-	f.Panel.Subscribe(OnCursorEnter, f.onCursor)
-	f.Panel.Subscribe(OnCursorLeave, f.onCursor)
+	f.tracker.Init(&f.Panel, f.update)
 
 	f.Subscribe(OnMouseDownOut, func(s string, i interface{}) {
 		// Hide list when clicked out
@@ -134,29 +133,19 @@ func (f *Folder) onMouse(evname string, ev interface{}) {
 	}
 }
 
-// onCursor receives cursor events over the folder panel
-func (f *Folder) onCursor(evname string, ev interface{}) {
-
-	switch evname {
-	case OnCursorEnter:
-		f.cursorOver = true
-		f.update()
-	case OnCursorLeave:
-		f.cursorOver = false
-		f.update()
-	default:
-		return
-	}
-}
-
 // update updates the folder visual state
 func (f *Folder) update() {
 
-	if f.cursorOver {
+	switch f.tracker.State() {
+	case StyleDisabled:
+		f.applyStyle(&f.styles.Disabled)
+	case StylePressed, StyleOver:
 		f.applyStyle(&f.styles.Over)
-		return
+	case StyleFocus:
+		f.applyStyle(&f.styles.Focus)
+	default:
+		f.applyStyle(&f.styles.Normal)
 	}
-	f.applyStyle(&f.styles.Normal)
 }
 
 // applyStyle applies the specified style
@@ -0,0 +1,459 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+/***************************************
+
+ Circular Gauge          Linear Gauge
+ +----------------+      +--------------------------------+
+ |    ..----..    |      | +----------|-----------------+ |
+ |  /     /    \  |      | |  zones   | needle          | |
+ | |     o      | |      | +----------|-----------------+ |
+ |  \  readout /  |      |             readout            |
+ +----------------+      +--------------------------------+
+
+****************************************/
+
+// Gauge is a panel which shows a value inside a range with a needle
+// over a circular or linear scale with optional colored zones and
+// a numeric readout. Changes of the value animate the needle.
+type Gauge struct {
+	Panel                        // Embedded panel
+	tex       *texture.Texture2D // Texture with the drawn gauge
+	readout   *Label             // Numeric readout label
+	style     *GaugeStyle        // Pointer to current style
+	circular  bool               // Circular or linear gauge
+	min       float32            // Minimum value
+	max       float32            // Maximum value
+	value     float32            // Current value
+	shown     float32            // Value currently shown by the needle
+	zones     []GaugeZone        // Colored zones of the scale
+	ticks     int                // Number of divisions of the scale
+	format    string             // Format of the readout
+	duration  time.Duration      // Duration of the needle animation
+	animID    int                // Id of the animation timer (0 if not animating)
+	animStart time.Time          // Start time of the current animation
+	animFrom  float32            // Value shown at the start of the current animation
+}
+
+// GaugeStyle contains the styling of a Gauge
+type GaugeStyle struct {
+	BgColor     math32.Color4 // Color of the gauge face
+	TrackColor  math32.Color4 // Color of the scale outside of the colored zones
+	TickColor   math32.Color4 // Color of the scale ticks
+	NeedleColor math32.Color4 // Color of the needle
+	FgColor     math32.Color4 // Color of the readout text
+}
+
+// GaugeZone is a colored range of values of a Gauge scale
+type GaugeZone struct {
+	Min   float32       // Start value of the zone
+	Max   float32       // End value of the zone
+	Color math32.Color4 // Color of the zone
+}
+
+const (
+	gaugeStartAngle = 0.75 * math32.Pi // Angle of the minimum value of the circular gauge (clockwise from the right)
+	gaugeSweep      = 1.5 * math32.Pi  // Angle between the minimum and maximum values of the circular gauge
+	gaugeFrame      = 16 * time.Millisecond
+)
+
+// NewCircularGauge creates and returns a pointer to a new circular gauge
+// with the specified diameter in pixels and value range.
+func NewCircularGauge(size, min, max float32) *Gauge {
+
+	return newGauge(true, size, size, min, max)
+}
+
+// NewLinearGauge creates and returns a pointer to a new horizontal linear gauge
+// with the specified dimensions in pixels and value range.
+func NewLinearGauge(width, height, min, max float32) *Gauge {
+
+	return newGauge(false, width, height, min, max)
+}
+
+// newGauge creates and returns a pointer to a new gauge.
+func newGauge(circular bool, width, height, min, max float32) *Gauge {
+
+	g := new(Gauge)
+	g.circular = circular
+	g.style = &StyleDefault().Gauge
+	g.min = min
+	g.max = max
+	g.value = min
+	g.shown = min
+	g.ticks = 10
+	g.format = "%.1f"
+	g.duration = 300 * time.Millisecond
+
+	g.Panel.Initialize(g, width, height)
+	g.Panel.mat.SetTransparent(true)
+	g.Panel.Subscribe(OnResize, func(evname string, ev interface{}) { g.recalc() })
+	g.Panel.Subscribe(OnContentScale, func(evname string, ev interface{}) { g.recalc() })
+
+	// Create the readout label
+	g.readout = NewLabel("")
+	g.readout.SetColor4(&g.style.FgColor)
+	g.Panel.Add(g.readout)
+
+	g.recalc()
+	return g
+}
+
+// SetValue sets the current value of the gauge, clamped to its range.
+// The needle moves to the new value during the animation duration.
+func (g *Gauge) SetValue(value float32) *Gauge {
+
+	g.value = math32.Clamp(value, g.min, g.max)
+	if g.duration <= 0 {
+		g.stopAnimation()
+		g.shown = g.value
+		g.recalc()
+		return g
+	}
+	g.animFrom = g.shown
+	g.animStart = time.Now()
+	if g.animID == 0 {
+		g.animID = Manager().SetInterval(gaugeFrame, nil, g.animate)
+	}
+	return g
+}
+
+// Value returns the current value of the gauge.
+func (g *Gauge) Value() float32 {
+
+	return g.value
+}
+
+// SetRange sets the minimum and maximum values of the gauge.
+func (g *Gauge) SetRange(min, max float32) *Gauge {
+
+	g.min = min
+	g.max = max
+	g.value = math32.Clamp(g.value, min, max)
+	g.shown = math32.Clamp(g.shown, min, max)
+	g.recalc()
+	return g
+}
+
+// Range returns the minimum and maximum values of the gauge.
+func (g *Gauge) Range() (float32, float32) {
+
+	return g.min, g.max
+}
+
+// AddZone adds a zone with the specified color to the scale between the specified values.
+func (g *Gauge) AddZone(min, max float32, color *math32.Color4) *Gauge {
+
+	g.zones = append(g.zones, GaugeZone{Min: min, Max: max, Color: *color})
+	g.recalc()
+	return g
+}
+
+// ClearZones removes all the colored zones of the scale.
+func (g *Gauge) ClearZones() *Gauge {
+
+	g.zones = nil
+	g.recalc()
+	return g
+}
+
+// Zones returns the colored zones of the scale.
+func (g *Gauge) Zones() []GaugeZone {
+
+	return g.zones
+}
+
+// SetTicks sets the number of divisions of the scale.
+// Zero hides the scale ticks.
+func (g *Gauge) SetTicks(ticks int) *Gauge {
+
+	g.ticks = ticks
+	g.recalc()
+	return g
+}
+
+// Ticks returns the number of divisions of the scale.
+func (g *Gauge) Ticks() int {
+
+	return g.ticks
+}
+
+// SetFormat sets the fmt format of the numeric readout, for example "%.0f km/h".
+// An empty format hides the readout.
+func (g *Gauge) SetFormat(format string) *Gauge {
+
+	g.format = format
+	g.recalc()
+	return g
+}
+
+// Format returns the format of the numeric readout.
+func (g *Gauge) Format() string {
+
+	return g.format
+}
+
+// SetAnimationDuration sets the duration of the needle movement when the value changes.
+// Zero moves the needle immediately.
+func (g *Gauge) SetAnimationDuration(duration time.Duration) *Gauge {
+
+	g.duration = duration
+	return g
+}
+
+// AnimationDuration returns the duration of the needle movement when the value changes.
+func (g *Gauge) AnimationDuration() time.Duration {
+
+	return g.duration
+}
+
+// SetStyle sets the style of the gauge.
+func (g *Gauge) SetStyle(style *GaugeStyle) *Gauge {
+
+	g.style = style
+	g.recalc()
+	return g
+}
+
+// Readout returns the readout label so its font can be changed.
+func (g *Gauge) Readout() *Label {
+
+	return g.readout
+}
+
+// Dispose releases resources used by this gauge.
+func (g *Gauge) Dispose() {
+
+	g.stopAnimation()
+	g.Panel.Dispose()
+}
+
+// animate is called by the animation timer to move the needle towards the value.
+func (g *Gauge) animate(arg interface{}) {
+
+	t := float32(time.Since(g.animStart)) / float32(g.duration)
+	if t >= 1 {
+		g.shown = g.value
+		g.stopAnimation()
+	} else {
+		// Ease out
+		t = 1 - (1-t)*(1-t)
+		g.shown = g.animFrom + (g.value-g.animFrom)*t
+	}
+	g.recalc()
+}
+
+// stopAnimation stops the animation timer if it is running.
+func (g *Gauge) stopAnimation() {
+
+	if g.animID != 0 {
+		Manager().ClearTimeout(g.animID)
+		g.animID = 0
+	}
+}
+
+// fraction returns the position of the specified value inside the range from 0 to 1.
+func (g *Gauge) fraction(value float32) float32 {
+
+	if g.max == g.min {
+		return 0
+	}
+	return math32.Clamp((value-g.min)/(g.max-g.min), 0, 1)
+}
+
+// recalc updates the readout and redraws the gauge texture.
+func (g *Gauge) recalc() {
+
+	// Update the readout
+	g.readout.SetColor4(&g.style.FgColor)
+	g.readout.SetVisible(g.format != "")
+	if g.format != "" {
+		g.readout.SetText(fmt.Sprintf(g.format, g.shown))
+	}
+	width := g.ContentWidth()
+	height := g.ContentHeight()
+	trackHeight := height
+	if g.circular {
+		radius := math32.Min(width, height) / 2
+		g.readout.SetPosition((width-g.readout.Width())/2, height/2+radius/2-g.readout.Height()/2)
+	} else if g.readout.Visible() {
+		trackHeight = math32.Max(height-g.readout.Height(), 1)
+		g.readout.SetPosition((width-g.readout.Width())/2, trackHeight)
+	}
+
+	// Draw the gauge image at the window scale
+	scaleX, scaleY := renderScale()
+	w := int(width*float32(scaleX)) + 1
+	h := int(height*float32(scaleY)) + 1
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	if g.circular {
+		g.drawCircular(img)
+	} else {
+		g.drawLinear(img, int(trackHeight*float32(scaleY)))
+	}
+
+	// Create texture if it doesn't exist yet
+	if g.tex == nil {
+		g.tex = texture.NewTexture2DFromRGBA(img)
+		g.tex.SetMagFilter(gls.LINEAR)
+		g.tex.SetMinFilter(gls.LINEAR)
+		g.Panel.Material().AddTexture(g.tex)
+		// Otherwise update texture with new image
+	} else {
+		g.tex.SetFromRGBA(img)
+	}
+}
+
+// zoneColor returns the color of the scale at the specified value.
+func (g *Gauge) zoneColor(value float32) *math32.Color4 {
+
+	for i := len(g.zones) - 1; i >= 0; i-- {
+		if value >= g.zones[i].Min && value <= g.zones[i].Max {
+			return &g.zones[i].Color
+		}
+	}
+	return &g.style.TrackColor
+}
+
+// drawCircular draws a circular gauge on the specified image.
+func (g *Gauge) drawCircular(img *image.RGBA) {
+
+	w := float32(img.Rect.Dx())
+	h := float32(img.Rect.Dy())
+	cx, cy := w/2, h/2
+	radius := math32.Min(w, h)/2 - 1
+	inner := radius * 0.82
+	needleAngle := gaugeStartAngle + gaugeSweep*g.fraction(g.shown)
+	needleWidth := math32.Max(2, radius*0.04)
+	var color math32.Color4
+	for py := 0; py < img.Rect.Dy(); py++ {
+		for px := 0; px < img.Rect.Dx(); px++ {
+			x := float32(px) + 0.5 - cx
+			y := float32(py) + 0.5 - cy
+			dist := math32.Sqrt(x*x + y*y)
+			color = math32.Color4{}
+
+			// Face
+			gaugeBlend(&color, &g.style.BgColor, radius-dist)
+
+			// Track and zones
+			angle := math32.Atan2(y, x) - gaugeStartAngle
+			for angle < 0 {
+				angle += 2 * math32.Pi
+			}
+			if angle <= gaugeSweep {
+				value := g.min + (g.max-g.min)*angle/gaugeSweep
+				gaugeBlend(&color, g.zoneColor(value), math32.Min(radius-dist, dist-inner))
+			}
+
+			// Ticks
+			for i := 0; i <= g.ticks && g.ticks > 0; i++ {
+				a := gaugeStartAngle + gaugeSweep*float32(i)/float32(g.ticks)
+				dx, dy := math32.Cos(a), math32.Sin(a)
+				d := gaugeSegmentDist(x, y, dx*inner*0.85, dy*inner*0.85, dx*inner, dy*inner)
+				gaugeBlend(&color, &g.style.TickColor, 0.75-d)
+			}
+
+			// Needle and hub
+			dx, dy := math32.Cos(needleAngle), math32.Sin(needleAngle)
+			d := gaugeSegmentDist(x, y, 0, 0, dx*radius*0.9, dy*radius*0.9)
+			gaugeBlend(&color, &g.style.NeedleColor, math32.Max(needleWidth/2-d, radius*0.07-dist))
+
+			gaugeSetPixel(img, px, py, &color)
+		}
+	}
+}
+
+// drawLinear draws a horizontal linear gauge with the specified track height on the specified image.
+func (g *Gauge) drawLinear(img *image.RGBA, trackHeight int) {
+
+	w := float32(img.Rect.Dx())
+	th := float32(trackHeight)
+	needleWidth := math32.Max(2, th*0.08)
+	margin := needleWidth
+	needleX := margin + (w-2*margin)*g.fraction(g.shown)
+	var color math32.Color4
+	for py := 0; py < img.Rect.Dy(); py++ {
+		for px := 0; px < img.Rect.Dx(); px++ {
+			x := float32(px) + 0.5
+			y := float32(py) + 0.5
+			color = math32.Color4{}
+
+			// Face
+			gaugeBlend(&color, &g.style.BgColor, th-y)
+
+			// Track and zones
+			if x >= margin && x <= w-margin {
+				value := g.min + (g.max-g.min)*(x-margin)/(w-2*margin)
+				gaugeBlend(&color, g.zoneColor(value), math32.Min(y-th*0.2, th*0.7-y))
+			}
+
+			// Ticks
+			for i := 0; i <= g.ticks && g.ticks > 0; i++ {
+				tx := margin + (w-2*margin)*float32(i)/float32(g.ticks)
+				d := gaugeSegmentDist(x, y, tx, th*0.7, tx, th*0.9)
+				gaugeBlend(&color, &g.style.TickColor, 0.75-d)
+			}
+
+			// Needle
+			d := gaugeSegmentDist(x, y, needleX, th*0.1, needleX, th*0.9)
+			gaugeBlend(&color, &g.style.NeedleColor, needleWidth/2-d)
+
+			gaugeSetPixel(img, px, py, &color)
+		}
+	}
+}
+
+// gaugeBlend blends the specified color over the specified premultiplied color
+// with the coverage of a shape which is at the specified signed distance (in pixels)
+// inside its border. Negative distances are outside the shape.
+func gaugeBlend(dst *math32.Color4, src *math32.Color4, inside float32) {
+
+	a := src.A * math32.Clamp(inside+0.5, 0, 1)
+	if a == 0 {
+		return
+	}
+	dst.R = src.R*a + dst.R*(1-a)
+	dst.G = src.G*a + dst.G*(1-a)
+	dst.B = src.B*a + dst.B*(1-a)
+	dst.A = a + dst.A*(1-a)
+}
+
+// gaugeSetPixel sets the pixel of the image at the specified position to the specified
+// premultiplied color, converted to the non premultiplied color expected by the panel shader.
+func gaugeSetPixel(img *image.RGBA, x, y int, color *math32.Color4) {
+
+	if color.A == 0 {
+		return
+	}
+	i := img.PixOffset(x, y)
+	img.Pix[i] = uint8(color.R / color.A * 255)
+	img.Pix[i+1] = uint8(color.G / color.A * 255)
+	img.Pix[i+2] = uint8(color.B / color.A * 255)
+	img.Pix[i+3] = uint8(color.A * 255)
+}
+
+// gaugeSegmentDist returns the distance from the point (x, y) to the segment from (ax, ay) to (bx, by).
+func gaugeSegmentDist(x, y, ax, ay, bx, by float32) float32 {
+
+	dx, dy := bx-ax, by-ay
+	t := float32(0)
+	if l2 := dx*dx + dy*dy; l2 > 0 {
+		t = math32.Clamp(((x-ax)*dx+(y-ay)*dy)/l2, 0, 1)
+	}
+	px, py := ax+t*dx-x, ay+t*dy-y
+	return math32.Sqrt(px*px + py*py)
+}
@@ -0,0 +1,300 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// OnGradientStop is dispatched by a GradientEditor when a stop is selected, for example
+// to show a color editor for it.
+const OnGradientStop = "gui.OnGradientStop"
+
+// GradientEditor is a panel which edits a math32.Gradient, for example the colors over the
+// lifetime of objects, a height colorization or a color map. The gradient is shown as a bar
+// over a checkerboard with a marker below it for each stop. Clicking the bar adds a stop,
+// dragging a marker moves its stop and right clicking a marker or pressing Delete removes it.
+// The color of the selected stop is set with SetSelectedColor. OnChange is dispatched when
+// the gradient is edited.
+type GradientEditor struct {
+	Panel                         // Embedded panel
+	canvas   *Canvas              // Canvas which draws the bar and the markers
+	grad     *math32.Gradient     // Edited gradient
+	style    *GradientEditorStyle // Pointer to current style
+	selected int                  // Index of the selected stop (-1 if none)
+	dragging bool                 // Whether the selected stop is being dragged
+}
+
+// GradientEditorStyle contains the styling of a GradientEditor.
+type GradientEditorStyle struct {
+	Border       RectBounds    // Border sizes
+	BorderColor  math32.Color4 // Border color
+	BgColor      math32.Color4 // Background color
+	CheckerColor math32.Color4 // Color of the dark squares of the checkerboard behind the bar
+	CheckerLight math32.Color4 // Color of the light squares of the checkerboard behind the bar
+	MarkerColor  math32.Color4 // Outline color of the markers
+	SelectColor  math32.Color4 // Outline color of the selected marker
+	MarkerSize   float32       // Size of the markers in pixels
+}
+
+// NewGradientEditor creates and returns a pointer to a new gradient editor with the
+// specified size editing the specified gradient, or a new black to white gradient if nil.
+func NewGradientEditor(width, height float32, grad *math32.Gradient) *GradientEditor {
+
+	ge := new(GradientEditor)
+	ge.Panel.Initialize(ge, width, height)
+	ge.style = &StyleDefault().GradientEditor
+	ge.selected = -1
+
+	ge.canvas = NewCanvas(ge.ContentWidth(), ge.ContentHeight())
+	ge.Add(ge.canvas)
+
+	ge.Subscribe(OnResize, func(evname string, ev interface{}) { ge.recalc() })
+	ge.Subscribe(OnMouseDown, ge.onMouse)
+	ge.Subscribe(OnMouseUp, ge.onMouse)
+	ge.Subscribe(OnCursor, ge.onCursor)
+	ge.Subscribe(OnKeyDown, ge.onKey)
+
+	if grad == nil {
+		grad = math32.NewGradientColors(math32.Color4{R: 0, G: 0, B: 0, A: 1}, math32.Color4{R: 1, G: 1, B: 1, A: 1})
+	}
+	ge.grad = grad
+	ge.applyStyle()
+	return ge
+}
+
+// SetGradient sets the gradient edited by this editor.
+func (ge *GradientEditor) SetGradient(grad *math32.Gradient) *GradientEditor {
+
+	ge.grad = grad
+	ge.selected = -1
+	ge.Redraw()
+	return ge
+}
+
+// Gradient returns the gradient edited by this editor.
+func (ge *GradientEditor) Gradient() *math32.Gradient {
+
+	return ge.grad
+}
+
+// SetSelected selects the stop at the specified index or none if -1.
+func (ge *GradientEditor) SetSelected(i int) *GradientEditor {
+
+	if i < -1 || i >= ge.grad.StopCount() {
+		i = -1
+	}
+	ge.selected = i
+	ge.Redraw()
+	return ge
+}
+
+// Selected returns the index of the selected stop or -1 if none.
+func (ge *GradientEditor) Selected() int {
+
+	return ge.selected
+}
+
+// SetSelectedColor sets the color of the selected stop and dispatches OnChange.
+func (ge *GradientEditor) SetSelectedColor(color *math32.Color4) *GradientEditor {
+
+	if ge.selected < 0 {
+		return ge
+	}
+	stop := ge.grad.Stop(ge.selected)
+	stop.Color = *color
+	ge.selected = ge.grad.SetStop(ge.selected, stop)
+	ge.changed()
+	return ge
+}
+
+// SetStyle sets the style of this editor.
+func (ge *GradientEditor) SetStyle(style *GradientEditorStyle) *GradientEditor {
+
+	ge.style = style
+	ge.applyStyle()
+	return ge
+}
+
+// applyStyle applies the current style to this editor.
+func (ge *GradientEditor) applyStyle() {
+
+	ge.SetBordersFrom(&ge.style.Border)
+	ge.SetBordersColor4(&ge.style.BorderColor)
+	ge.SetColor4(&ge.style.BgColor)
+	ge.recalc()
+}
+
+// recalc resizes the canvas to the content area and redraws it.
+func (ge *GradientEditor) recalc() {
+
+	ge.canvas.SetSize(ge.ContentWidth(), ge.ContentHeight())
+	ge.Redraw()
+}
+
+// layout returns the horizontal margin, the width and the height of the bar.
+// The margin keeps the markers of the stops at the ends inside the editor.
+func (ge *GradientEditor) layout() (margin, width, height float32) {
+
+	margin = ge.style.MarkerSize / 2
+	width = ge.canvas.Width() - 2*margin
+	height = ge.canvas.Height() - ge.style.MarkerSize*1.5
+	return
+}
+
+// Redraw redraws the bar and the markers. It must be called after
+// the gradient is changed without using this editor.
+func (ge *GradientEditor) Redraw() {
+
+	c := ge.canvas
+	c.Clear()
+	margin, w, h := ge.layout()
+	if w <= 0 || h <= 0 {
+		return
+	}
+
+	// Draws the checkerboard and the gradient over it
+	light := NewPath().Rect(margin, 0, w, h)
+	dark := NewPath()
+	size := math32.Max(h/2, 4)
+	for y, row := float32(0), 0; y < h; y, row = y+size, row+1 {
+		for x, col := margin, 0; x < margin+w; x, col = x+size, col+1 {
+			if (row+col)%2 == 0 {
+				dark.Rect(x, y, math32.Min(size, margin+w-x), math32.Min(size, h-y))
+			}
+		}
+	}
+	c.Fill(light, SolidPaint(&ge.style.CheckerLight))
+	c.Fill(dark, SolidPaint(&ge.style.CheckerColor))
+	if ge.grad.StopCount() > 0 {
+		c.Fill(NewPath().Rect(margin, 0, w, h), LinearGradient(margin, 0, margin+w, 0, ge.grad.Stops()...))
+	}
+
+	// Draws a marker for each stop, an arrow pointing to the bar with a square of the stop color
+	ms := ge.style.MarkerSize
+	for i, stop := range ge.grad.Stops() {
+		x := margin + math32.Clamp(stop.Offset, 0, 1)*w
+		outline := &ge.style.MarkerColor
+		if i == ge.selected {
+			outline = &ge.style.SelectColor
+		}
+		marker := NewPath().MoveTo(x, h).LineTo(x+ms/2, h+ms/2).LineTo(x+ms/2, h+ms*1.5).
+			LineTo(x-ms/2, h+ms*1.5).LineTo(x-ms/2, h+ms/2).Close()
+		c.Fill(marker, SolidPaint(outline))
+		color := stop.Color
+		color.A = 1
+		c.Fill(NewPath().Rect(x-ms/2+2, h+ms/2+2, ms-4, ms-4), SolidPaint(&color))
+	}
+}
+
+// hit returns the index of the stop whose marker is at the specified canvas coordinates or -1.
+func (ge *GradientEditor) hit(x, y float32) int {
+
+	margin, w, h := ge.layout()
+	if y < h {
+		return -1
+	}
+	stops := ge.grad.Stops()
+	for i := len(stops) - 1; i >= 0; i-- {
+		sx := margin + math32.Clamp(stops[i].Offset, 0, 1)*w
+		if math32.Abs(x-sx) <= ge.style.MarkerSize/2 {
+			return i
+		}
+	}
+	return -1
+}
+
+// offset returns the gradient offset at the specified canvas x coordinate.
+func (ge *GradientEditor) offset(x float32) float32 {
+
+	margin, w, _ := ge.layout()
+	return math32.Clamp((x-margin)/w, 0, 1)
+}
+
+// onMouse receives subscribed mouse events.
+func (ge *GradientEditor) onMouse(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	if evname == OnMouseUp {
+		if ge.dragging {
+			ge.dragging = false
+			Manager().SetCursorFocus(nil)
+		}
+		return
+	}
+	Manager().SetKeyFocus(ge)
+	x, y := ge.canvas.ContentCoords(mev.Xpos, mev.Ypos)
+	i := ge.hit(x, y)
+	switch mev.Button {
+	case window.MouseButtonRight:
+		if i >= 0 {
+			ge.removeStop(i)
+		}
+		return
+	case window.MouseButtonLeft:
+	default:
+		return
+	}
+
+	// Clicking the bar adds a stop with the current color at the offset
+	_, _, h := ge.layout()
+	if i < 0 && y < h {
+		offset := ge.offset(x)
+		color := ge.grad.Evaluate(offset)
+		i = ge.grad.AddStop(offset, &color)
+		ge.changed()
+	}
+	if i < 0 {
+		return
+	}
+	ge.selected = i
+	ge.dragging = true
+	Manager().SetCursorFocus(ge)
+	ge.Redraw()
+	ge.Dispatch(OnGradientStop, i)
+}
+
+// onCursor receives subscribed cursor events.
+func (ge *GradientEditor) onCursor(evname string, ev interface{}) {
+
+	if !ge.dragging || ge.selected < 0 {
+		return
+	}
+	cev := ev.(*window.CursorEvent)
+	x, _ := ge.canvas.ContentCoords(cev.Xpos, cev.Ypos)
+	stop := ge.grad.Stop(ge.selected)
+	stop.Offset = ge.offset(x)
+	ge.selected = ge.grad.SetStop(ge.selected, stop)
+	ge.changed()
+}
+
+// onKey receives subscribed key events.
+func (ge *GradientEditor) onKey(evname string, ev interface{}) {
+
+	kev := ev.(*window.KeyEvent)
+	if (kev.Key == window.KeyDelete || kev.Key == window.KeyBackspace) && ge.selected >= 0 {
+		ge.removeStop(ge.selected)
+	}
+}
+
+// removeStop removes the stop at the specified index keeping at least one stop.
+func (ge *GradientEditor) removeStop(i int) {
+
+	if ge.grad.StopCount() <= 1 {
+		return
+	}
+	ge.grad.RemoveStop(i)
+	ge.selected = -1
+	ge.dragging = false
+	ge.changed()
+}
+
+// changed redraws the editor and dispatches OnChange.
+func (ge *GradientEditor) changed() {
+
+	ge.Redraw()
+	ge.Dispatch(OnChange, nil)
+}
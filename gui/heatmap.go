@@ -0,0 +1,471 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+	"github.com/g3n/engine/window"
+)
+
+/***************************************
+
+ HeatMap Panel
+ +-------------------------------+
+ |  image                  +--+  |
+ |  +------------------+   |  |max
+ |  |                  |   |  |  |
+ |  |  [c,r] value     |   |  |  |
+ |  |                  |   |  |  |
+ |  +------------------+   +--+min
+ +-------------------------------+
+
+****************************************/
+
+// HeatMap is a panel which shows a 2D array of values as a color mapped image
+// with a color bar showing the value range. Hovering the cursor over the image
+// shows the value of the cell under it. The mouse wheel zooms the image around
+// the cursor and dragging with the left mouse button pans the zoomed image.
+type HeatMap struct {
+	Panel                       // Embedded panel
+	image    Panel              // Data image panel
+	colorbar Panel              // Color bar panel
+	labelMin *Label             // Minimum value label
+	labelMax *Label             // Maximum value label
+	readout  *Label             // Hover value readout
+	tex      *texture.Texture2D // Data image texture
+	barTex   *texture.Texture2D // Color bar texture
+	style    *HeatMapStyle      // Pointer to current style
+	data     []float32          // Data values by rows
+	cols     int                // Number of data columns
+	rows     int                // Number of data rows
+	min      float32            // Value mapped to the first color
+	max      float32            // Value mapped to the last color
+	autoY    bool               // Auto range flag
+	cmap     ColorMap           // Color map
+	format   string             // Format of values
+	zoom     float32            // Zoom factor
+	viewX    float32            // Left of the zoomed view as a fraction of the image width
+	viewY    float32            // Top of the zoomed view as a fraction of the image height
+	pressed  bool               // Mouse button is pressed and dragging
+	posLastX float32            // Last cursor X position when dragging
+	posLastY float32            // Last cursor Y position when dragging
+	cursorX  float32            // Last cursor X position over the image
+	cursorY  float32            // Last cursor Y position over the image
+}
+
+// HeatMapStyle contains the styling of a HeatMap
+type HeatMapStyle struct {
+	ReadoutBgColor math32.Color4 // Background color of the value readout
+	ReadoutFgColor math32.Color4 // Text color of the value readout
+}
+
+// ColorMap is a sequence of equally spaced colors which maps
+// values from 0 to 1 to colors interpolated between them.
+type ColorMap []math32.Color
+
+// Predefined color maps
+var (
+	ColorMapGray    = ColorMap{{0, 0, 0}, {1, 1, 1}}
+	ColorMapHot     = ColorMap{{0, 0, 0}, {0.9, 0, 0}, {1, 0.9, 0}, {1, 1, 1}}
+	ColorMapJet     = ColorMap{{0, 0, 0.5}, {0, 0, 1}, {0, 1, 1}, {1, 1, 0}, {1, 0, 0}, {0.5, 0, 0}}
+	ColorMapViridis = ColorMap{{0.267, 0.005, 0.329}, {0.230, 0.322, 0.546}, {0.128, 0.567, 0.551}, {0.369, 0.789, 0.383}, {0.993, 0.906, 0.144}}
+)
+
+const (
+	heatMapBarWidth = 16 // Width of the color bar in pixels
+	heatMapSpacing  = 4  // Spacing between the image, color bar and labels in pixels
+	heatMapMaxZoom  = 64 // Maximum zoom factor
+)
+
+// Color returns the color of the color map for the specified value from 0 to 1.
+func (cm ColorMap) Color(t float32) math32.Color {
+
+	if len(cm) == 0 {
+		return math32.Color{}
+	}
+	t = math32.Clamp(t, 0, 1) * float32(len(cm)-1)
+	i := int(t)
+	if i >= len(cm)-1 {
+		return cm[len(cm)-1]
+	}
+	c := cm[i]
+	c.Lerp(&cm[i+1], t-float32(i))
+	return c
+}
+
+// ColorMapFromGradient returns a color map with the specified number of colors
+// sampled from the specified gradient from offset 0 to 1.
+func ColorMapFromGradient(g *math32.Gradient, count int) ColorMap {
+
+	cm := make(ColorMap, count)
+	for i, c := range g.Sample(count) {
+		cm[i] = math32.Color{R: c.R, G: c.G, B: c.B}
+	}
+	return cm
+}
+
+// NewHeatMap creates and returns a pointer to a new heat map panel
+// with the specified dimensions in pixels.
+func NewHeatMap(width, height float32) *HeatMap {
+
+	hm := new(HeatMap)
+	hm.style = &StyleDefault().HeatMap
+	hm.cmap = ColorMapViridis
+	hm.autoY = true
+	hm.format = "%.4g"
+	hm.zoom = 1
+
+	hm.Panel.Initialize(hm, width, height)
+	hm.Panel.Subscribe(OnResize, func(evname string, ev interface{}) { hm.recalc() })
+
+	// Create the data image panel
+	hm.image.Initialize(&hm.image, 0, 0)
+	hm.image.Subscribe(OnCursor, hm.onCursor)
+	hm.image.Subscribe(OnCursorLeave, hm.onCursor)
+	hm.image.Subscribe(OnScroll, hm.onScroll)
+	hm.image.Subscribe(OnMouseDown, hm.onMouse)
+	hm.image.Subscribe(OnMouseUp, hm.onMouse)
+	hm.Panel.Add(&hm.image)
+
+	// Create the color bar panel and its labels
+	hm.colorbar.Initialize(&hm.colorbar, 0, 0)
+	hm.Panel.Add(&hm.colorbar)
+	hm.labelMin = NewLabel("")
+	hm.Panel.Add(hm.labelMin)
+	hm.labelMax = NewLabel("")
+	hm.Panel.Add(hm.labelMax)
+
+	// Create the value readout
+	hm.readout = NewLabel("")
+	hm.readout.SetPaddings(1, 2, 1, 2)
+	hm.readout.SetVisible(false)
+	hm.Panel.Add(hm.readout)
+
+	hm.updateColorbar()
+	hm.recalc()
+	return hm
+}
+
+// SetData sets the values of the heat map from the specified array with
+// the specified number of columns and rows. The values are stored by rows
+// with the first row at the top of the image. The array is not copied.
+func (hm *HeatMap) SetData(cols, rows int, data []float32) *HeatMap {
+
+	if cols*rows > len(data) {
+		panic("HeatMap data is smaller than the specified columns and rows")
+	}
+	hm.cols = cols
+	hm.rows = rows
+	hm.data = data
+	hm.updateImage()
+	return hm
+}
+
+// Data returns the values of the heat map and its number of columns and rows.
+func (hm *HeatMap) Data() ([]float32, int, int) {
+
+	return hm.data, hm.cols, hm.rows
+}
+
+// Value returns the value of the cell at the specified column and row
+// and true or false if the cell is outside of the data.
+func (hm *HeatMap) Value(col, row int) (float32, bool) {
+
+	if col < 0 || col >= hm.cols || row < 0 || row >= hm.rows {
+		return 0, false
+	}
+	return hm.data[row*hm.cols+col], true
+}
+
+// SetRange sets the values mapped to the first and last colors of
+// the color map and disables the automatic range.
+func (hm *HeatMap) SetRange(min, max float32) *HeatMap {
+
+	hm.min = min
+	hm.max = max
+	hm.autoY = false
+	hm.updateImage()
+	return hm
+}
+
+// Range returns the values mapped to the first and last colors of the color map.
+func (hm *HeatMap) Range() (float32, float32) {
+
+	return hm.min, hm.max
+}
+
+// SetAutoRange sets whether the range of the color map is set from
+// the minimum and maximum values of the data (enabled by default).
+func (hm *HeatMap) SetAutoRange(state bool) *HeatMap {
+
+	hm.autoY = state
+	hm.updateImage()
+	return hm
+}
+
+// AutoRange returns whether the range of the color map is set from the data.
+func (hm *HeatMap) AutoRange() bool {
+
+	return hm.autoY
+}
+
+// SetColorMap sets the color map used to show the values.
+func (hm *HeatMap) SetColorMap(cmap ColorMap) *HeatMap {
+
+	hm.cmap = cmap
+	hm.updateColorbar()
+	hm.updateImage()
+	return hm
+}
+
+// ColorMap returns the color map used to show the values.
+func (hm *HeatMap) ColorMap() ColorMap {
+
+	return hm.cmap
+}
+
+// SetFormat sets the fmt format of the values shown in the readout and color bar labels.
+func (hm *HeatMap) SetFormat(format string) *HeatMap {
+
+	hm.format = format
+	hm.updateLabels()
+	return hm
+}
+
+// Format returns the fmt format of the values shown in the readout and color bar labels.
+func (hm *HeatMap) Format() string {
+
+	return hm.format
+}
+
+// SetZoom sets the zoom factor keeping the center of the current view.
+// A zoom of 1 shows the whole image.
+func (hm *HeatMap) SetZoom(zoom float32) *HeatMap {
+
+	hm.zoomAt(zoom, 0.5, 0.5)
+	return hm
+}
+
+// Zoom returns the current zoom factor.
+func (hm *HeatMap) Zoom() float32 {
+
+	return hm.zoom
+}
+
+// ResetZoom shows the whole image.
+func (hm *HeatMap) ResetZoom() *HeatMap {
+
+	hm.zoom = 1
+	hm.viewX = 0
+	hm.viewY = 0
+	hm.updateView()
+	return hm
+}
+
+// SetStyle sets the style of the heat map.
+func (hm *HeatMap) SetStyle(style *HeatMapStyle) *HeatMap {
+
+	hm.style = style
+	hm.recalc()
+	return hm
+}
+
+// cellAt returns the column and row of the cell at the specified
+// position in pixels of the image panel content area.
+func (hm *HeatMap) cellAt(x, y float32) (int, int) {
+
+	fx := hm.viewX + x/hm.image.ContentWidth()/hm.zoom
+	fy := hm.viewY + y/hm.image.ContentHeight()/hm.zoom
+	return int(math32.Floor(fx * float32(hm.cols))), int(math32.Floor(fy * float32(hm.rows)))
+}
+
+// zoomAt sets the zoom factor keeping the image point at the specified
+// fraction of the image panel content area fixed.
+func (hm *HeatMap) zoomAt(zoom, fx, fy float32) {
+
+	zoom = math32.Clamp(zoom, 1, heatMapMaxZoom)
+	px := hm.viewX + fx/hm.zoom
+	py := hm.viewY + fy/hm.zoom
+	hm.zoom = zoom
+	hm.viewX = px - fx/zoom
+	hm.viewY = py - fy/zoom
+	hm.updateView()
+}
+
+// updateView clamps the zoomed view to the image and updates the texture offset and repeat.
+func (hm *HeatMap) updateView() {
+
+	size := 1 / hm.zoom
+	hm.viewX = math32.Clamp(hm.viewX, 0, 1-size)
+	hm.viewY = math32.Clamp(hm.viewY, 0, 1-size)
+	if hm.tex != nil {
+		hm.tex.SetRepeat(size, size)
+		hm.tex.SetOffset(hm.viewX, hm.viewY)
+	}
+}
+
+// onCursor receives subscribed cursor events over the image panel.
+func (hm *HeatMap) onCursor(evname string, ev interface{}) {
+
+	if evname == OnCursorLeave {
+		hm.readout.SetVisible(false)
+		return
+	}
+	cev := ev.(*window.CursorEvent)
+	hm.cursorX = cev.Xpos
+	hm.cursorY = cev.Ypos
+
+	// Pan the zoomed image
+	if hm.pressed {
+		hm.viewX -= (cev.Xpos - hm.posLastX) / hm.image.ContentWidth() / hm.zoom
+		hm.viewY -= (cev.Ypos - hm.posLastY) / hm.image.ContentHeight() / hm.zoom
+		hm.posLastX = cev.Xpos
+		hm.posLastY = cev.Ypos
+		hm.updateView()
+	}
+
+	// Show the value of the cell under the cursor
+	x, y := hm.image.ContentCoords(cev.Xpos, cev.Ypos)
+	col, row := hm.cellAt(x, y)
+	value, ok := hm.Value(col, row)
+	if !ok {
+		hm.readout.SetVisible(false)
+		return
+	}
+	hm.readout.SetText(fmt.Sprintf("[%d,%d] "+hm.format, col, row, value))
+	px, py := hm.ContentCoords(cev.Xpos, cev.Ypos)
+	px = math32.Min(px+12, hm.image.ContentWidth()-hm.readout.Width())
+	py = math32.Min(py+12, hm.image.ContentHeight()-hm.readout.Height())
+	hm.readout.SetPosition(math32.Max(px, 0), math32.Max(py, 0))
+	hm.readout.SetVisible(true)
+}
+
+// onScroll receives subscribed scroll events over the image panel.
+func (hm *HeatMap) onScroll(evname string, ev interface{}) {
+
+	sev := ev.(*window.ScrollEvent)
+	x, y := hm.image.ContentCoords(hm.cursorX, hm.cursorY)
+	fx := math32.Clamp(x/hm.image.ContentWidth(), 0, 1)
+	fy := math32.Clamp(y/hm.image.ContentHeight(), 0, 1)
+	hm.zoomAt(hm.zoom*math32.Pow(1.2, sev.Yoffset), fx, fy)
+}
+
+// onMouse receives subscribed mouse events over the image panel.
+func (hm *HeatMap) onMouse(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	if mev.Button != window.MouseButtonLeft {
+		return
+	}
+	switch evname {
+	case OnMouseDown:
+		hm.pressed = true
+		hm.posLastX = mev.Xpos
+		hm.posLastY = mev.Ypos
+		Manager().SetCursorFocus(&hm.image)
+	case OnMouseUp:
+		hm.pressed = false
+		Manager().SetCursorFocus(nil)
+	}
+}
+
+// updateImage recalculates the range and redraws the data image texture.
+func (hm *HeatMap) updateImage() {
+
+	if hm.autoY && len(hm.data) > 0 {
+		hm.min = math32.Inf(1)
+		hm.max = math32.Inf(-1)
+		for _, v := range hm.data[:hm.cols*hm.rows] {
+			hm.min = math32.Min(hm.min, v)
+			hm.max = math32.Max(hm.max, v)
+		}
+	}
+	hm.updateLabels()
+	if hm.cols == 0 || hm.rows == 0 {
+		return
+	}
+
+	// Draw one pixel per cell
+	img := image.NewRGBA(image.Rect(0, 0, hm.cols, hm.rows))
+	scale := float32(0)
+	if hm.max != hm.min {
+		scale = 1 / (hm.max - hm.min)
+	}
+	for i, v := range hm.data[:hm.cols*hm.rows] {
+		c := hm.cmap.Color((v - hm.min) * scale)
+		img.Pix[4*i] = uint8(c.R * 255)
+		img.Pix[4*i+1] = uint8(c.G * 255)
+		img.Pix[4*i+2] = uint8(c.B * 255)
+		img.Pix[4*i+3] = 255
+	}
+
+	// Create texture if it doesn't exist yet
+	if hm.tex == nil {
+		hm.tex = texture.NewTexture2DFromRGBA(img)
+		hm.tex.SetMagFilter(gls.NEAREST)
+		hm.tex.SetMinFilter(gls.NEAREST)
+		hm.tex.SetWrapS(gls.CLAMP_TO_EDGE)
+		hm.tex.SetWrapT(gls.CLAMP_TO_EDGE)
+		hm.image.Material().AddTexture(hm.tex)
+		// Otherwise update texture with new image
+	} else {
+		hm.tex.SetFromRGBA(img)
+	}
+	hm.updateView()
+}
+
+// updateColorbar redraws the color bar texture with the maximum value at the top.
+func (hm *HeatMap) updateColorbar() {
+
+	const size = 256
+	img := image.NewRGBA(image.Rect(0, 0, 1, size))
+	for i := 0; i < size; i++ {
+		c := hm.cmap.Color(1 - float32(i)/(size-1))
+		img.Pix[4*i] = uint8(c.R * 255)
+		img.Pix[4*i+1] = uint8(c.G * 255)
+		img.Pix[4*i+2] = uint8(c.B * 255)
+		img.Pix[4*i+3] = 255
+	}
+	if hm.barTex == nil {
+		hm.barTex = texture.NewTexture2DFromRGBA(img)
+		hm.barTex.SetWrapS(gls.CLAMP_TO_EDGE)
+		hm.barTex.SetWrapT(gls.CLAMP_TO_EDGE)
+		hm.colorbar.Material().AddTexture(hm.barTex)
+	} else {
+		hm.barTex.SetFromRGBA(img)
+	}
+}
+
+// updateLabels updates the color bar labels with the current range.
+func (hm *HeatMap) updateLabels() {
+
+	hm.labelMin.SetText(fmt.Sprintf(hm.format, hm.min))
+	hm.labelMax.SetText(fmt.Sprintf(hm.format, hm.max))
+	hm.recalc()
+}
+
+// recalc sets the positions and sizes of the internal panels.
+func (hm *HeatMap) recalc() {
+
+	width := hm.ContentWidth()
+	height := hm.ContentHeight()
+	labelWidth := math32.Max(hm.labelMin.Width(), hm.labelMax.Width())
+	imageWidth := math32.Max(width-heatMapBarWidth-labelWidth-2*heatMapSpacing, 0)
+
+	hm.image.SetPosition(0, 0)
+	hm.image.SetSize(imageWidth, height)
+	hm.colorbar.SetPosition(imageWidth+heatMapSpacing, 0)
+	hm.colorbar.SetSize(heatMapBarWidth, height)
+	hm.labelMax.SetPosition(imageWidth+heatMapBarWidth+2*heatMapSpacing, 0)
+	hm.labelMin.SetPosition(imageWidth+heatMapBarWidth+2*heatMapSpacing, height-hm.labelMin.Height())
+
+	hm.readout.SetBgColor4(&hm.style.ReadoutBgColor)
+	hm.readout.SetColor4(&hm.style.ReadoutFgColor)
+}
@@ -36,24 +36,29 @@ func NewImageFromRGBA(rgba *image.RGBA) *Image {
 	return NewImageFromTex(tex)
 }
 
-// NewImageFromTex creates and returns an image panel from the specified texture2D
+// NewImageFromTex creates and returns an image panel from the specified texture2D.
+// The texture may be nil for an empty image whose content is set later by SetRGBA.
 func NewImageFromTex(tex *texture.Texture2D) *Image {
 
 	i := new(Image)
 	i.Panel.Initialize(i, 0, 0)
-	i.tex = tex
-	i.Panel.SetContentSize(float32(i.tex.Width()), float32(i.tex.Height()))
-	i.Material().AddTexture(i.tex)
+	i.SetTexture(tex)
 	return i
 }
 
-// SetTexture changes the image texture to the specified texture2D.
+// SetTexture changes the image texture to the specified texture2D, which may be nil.
 // It returns a pointer to the previous texture.
 func (i *Image) SetTexture(tex *texture.Texture2D) *texture.Texture2D {
 
 	prevtex := i.tex
-	i.Material().RemoveTexture(prevtex)
+	if prevtex != nil {
+		i.Material().RemoveTexture(prevtex)
+	}
 	i.tex = tex
+	if tex == nil {
+		i.Panel.SetContentSize(0, 0)
+		return prevtex
+	}
 	i.Panel.SetContentSize(float32(i.tex.Width()), float32(i.tex.Height()))
 	i.Material().AddTexture(i.tex)
 	return prevtex
@@ -69,3 +74,39 @@ func (i *Image) SetImage(imgfile string) error {
 	i.SetTexture(tex)
 	return nil
 }
+
+// Texture returns a pointer to the image texture.
+func (i *Image) Texture() *texture.Texture2D {
+
+	return i.tex
+}
+
+// SetRGBA sets the image content from the specified RGBA image, as drawn in software.
+// If the size of the image changes, the panel content area is resized to the new size.
+// If the panel has no texture yet, a new texture is created for the image.
+func (i *Image) SetRGBA(rgba *image.RGBA) {
+
+	if i.tex == nil {
+		i.SetTexture(texture.NewTexture2DFromRGBA(rgba))
+		return
+	}
+	size := rgba.Rect.Size()
+	resized := size.X != i.tex.Width() || size.Y != i.tex.Height()
+	i.tex.UpdateFromRGBA(rgba)
+	if resized {
+		i.Panel.SetContentSize(float32(size.X), float32(size.Y))
+	}
+}
+
+// UpdateRegion updates the image content from the specified RGBA image, with the same size
+// of the current content, transferring only the pixels inside the specified rectangle.
+// It allows efficient updates of widgets drawn in software as minimaps or paint canvases.
+func (i *Image) UpdateRegion(rgba *image.RGBA, rect image.Rectangle) {
+
+	size := rgba.Rect.Size()
+	if i.tex == nil || size.X != i.tex.Width() || size.Y != i.tex.Height() {
+		i.SetRGBA(rgba)
+		return
+	}
+	i.tex.UpdateRegionFromRGBA(rgba, rect)
+}
@@ -16,8 +16,7 @@ type ImageButton struct {
 	iconLabel   bool                                   // True if icon
 	image       *Image                                 // pointer to button image (may be nil)
 	styles      *ImageButtonStyles                     // pointer to current button styles
-	mouseOver   bool                                   // true if mouse is over button
-	pressed     bool                                   // true if button is pressed
+	tracker     StateTracker                           // tracker of the button pseudo-state
 	stateImages [ButtonDisabled + 1]*texture.Texture2D // array of images for each button state
 }
 
@@ -68,12 +67,8 @@ func NewImageButton(normalImgPath string) (*ImageButton, error) {
 	// Subscribe to panel events
 	b.Panel.Subscribe(OnKeyDown, b.onKey)
 	b.Panel.Subscribe(OnKeyUp, b.onKey)
-	b.Panel.Subscribe(OnMouseUp, b.onMouse)
 	b.Panel.Subscribe(OnMouseDown, b.onMouse)
-	b.Panel.Subscribe(OnCursor, b.onCursor)
-	b.Panel.Subscribe(OnCursorEnter, b.onCursor)
-	b.Panel.Subscribe(OnCursorLeave, b.onCursor)
-	b.Panel.Subscribe(OnEnable, func(name string, ev interface{}) { b.update() })
+	b.tracker.Init(b.Panel, b.update)
 	b.Panel.Subscribe(OnResize, func(name string, ev interface{}) { b.recalc() })
 
 	b.recalc()
@@ -165,34 +160,12 @@ func (b *ImageButton) SetStyles(bs *ImageButtonStyles) {
 	b.update()
 }
 
-// onCursor process subscribed cursor events
-func (b *ImageButton) onCursor(evname string, ev interface{}) {
-
-	switch evname {
-	case OnCursorEnter:
-		b.mouseOver = true
-		b.update()
-	case OnCursorLeave:
-		b.pressed = false
-		b.mouseOver = false
-		b.update()
-	}
-}
-
 // onMouseEvent process subscribed mouse events
 func (b *ImageButton) onMouse(evname string, ev interface{}) {
 
-	switch evname {
-	case OnMouseDown:
+	if evname == OnMouseDown {
 		Manager().SetKeyFocus(b)
-		b.pressed = true
-		b.update()
 		b.Dispatch(OnClick, nil)
-	case OnMouseUp:
-		b.pressed = false
-		b.update()
-	default:
-		return
 	}
 }
 
@@ -201,14 +174,12 @@ func (b *ImageButton) onKey(evname string, ev interface{}) {
 
 	kev := ev.(*window.KeyEvent)
 	if evname == OnKeyDown && kev.Key == window.KeyEnter {
-		b.pressed = true
-		b.update()
+		b.tracker.SetPressed(true)
 		b.Dispatch(OnClick, nil)
 		return
 	}
 	if evname == OnKeyUp && kev.Key == window.KeyEnter {
-		b.pressed = false
-		b.update()
+		b.tracker.SetPressed(false)
 		return
 	}
 	return
@@ -217,29 +188,29 @@ func (b *ImageButton) onKey(evname string, ev interface{}) {
 // update updates the button visual state
 func (b *ImageButton) update() {
 
-	if !b.Enabled() {
+	switch b.tracker.State() {
+	case StyleDisabled:
 		if b.stateImages[ButtonDisabled] != nil {
 			b.image.SetTexture(b.stateImages[ButtonDisabled])
 		}
 		b.applyStyle(&b.styles.Disabled)
-		return
-	}
-	if b.pressed {
+	case StylePressed:
 		if b.stateImages[ButtonPressed] != nil {
 			b.image.SetTexture(b.stateImages[ButtonPressed])
 		}
 		b.applyStyle(&b.styles.Pressed)
-		return
-	}
-	if b.mouseOver {
+	case StyleOver:
 		if b.stateImages[ButtonOver] != nil {
 			b.image.SetTexture(b.stateImages[ButtonOver])
 		}
 		b.applyStyle(&b.styles.Over)
-		return
+	case StyleFocus:
+		b.image.SetTexture(b.stateImages[ButtonNormal])
+		b.applyStyle(&b.styles.Focus)
+	default:
+		b.image.SetTexture(b.stateImages[ButtonNormal])
+		b.applyStyle(&b.styles.Normal)
 	}
-	b.image.SetTexture(b.stateImages[ButtonNormal])
-	b.applyStyle(&b.styles.Normal)
 }
 
 // applyStyle applies the specified button style
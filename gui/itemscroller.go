@@ -7,6 +7,7 @@ package gui
 import (
 	"math"
 
+	"github.com/g3n/engine/math32"
 	"github.com/g3n/engine/window"
 )
 
@@ -22,10 +23,10 @@ type ItemScroller struct {
 	maxAutoHeight  float32             // maximum auto height (if 0, auto width disabled)
 	first          int                 // first visible item position
 	adjustItem     bool                // adjust item to width or height
-	focus          bool                // has keyboard focus
-	cursorOver     bool                // mouse is over the list
+	tracker        StateTracker        // tracker of the pseudo-state
 	autoButtonSize bool                // scroll button size is adjusted relative to content/view
 	scrollBarEvent bool
+	reorder        reorderState // drag to reorder state
 }
 
 // ItemScrollerStyle contains the styling of a ItemScroller
@@ -33,10 +34,11 @@ type ItemScrollerStyle BasicStyle
 
 // ItemScrollerStyles contains a ItemScrollerStyle for each valid GUI state
 type ItemScrollerStyles struct {
-	Normal   ItemScrollerStyle
-	Over     ItemScrollerStyle
-	Focus    ItemScrollerStyle
-	Disabled ItemScrollerStyle
+	Normal    ItemScrollerStyle
+	Over      ItemScrollerStyle
+	Focus     ItemScrollerStyle
+	Disabled  ItemScrollerStyle
+	Indicator math32.Color4 // Color of the insertion indicator in the drag to reorder mode
 }
 
 // NewVScroller creates and returns a pointer to a new vertical scroller panel
@@ -65,10 +67,14 @@ func newScroller(vert bool, width, height float32) *ItemScroller {
 // Clear removes and disposes of all the scroller children
 func (s *ItemScroller) Clear() {
 
+	for _, item := range s.items {
+		s.removeDrag(item)
+	}
 	s.Panel.DisposeChildren(true)
 	s.first = 0
 	s.hscroll = nil
 	s.vscroll = nil
+	s.reorder.indicator = nil
 	s.items = s.items[0:0]
 	s.update()
 	s.recalc()
@@ -102,6 +108,9 @@ func (s *ItemScroller) InsertAt(pos int, item IPanel) {
 
 	// Insert item in the scroller
 	s.Panel.Add(item)
+	if s.reorder.enabled {
+		s.subscribeDrag(item)
+	}
 	s.autoSize()
 	s.recalc()
 
@@ -132,6 +141,7 @@ func (s *ItemScroller) RemoveAt(pos int) IPanel {
 	s.items = s.items[:len(s.items)-1]
 
 	// Remove item from the scroller children
+	s.removeDrag(item)
 	s.Panel.Remove(item)
 	s.autoSize()
 	s.recalc()
@@ -294,28 +304,14 @@ func (s *ItemScroller) initialize(vert bool, width, height float32) {
 	s.Panel.Initialize(s, width, height)
 	s.styles = &StyleDefault().ItemScroller
 
-	s.Panel.Subscribe(OnCursorEnter, s.onCursor)
-	s.Panel.Subscribe(OnCursorLeave, s.onCursor)
 	s.Panel.Subscribe(OnScroll, s.onScroll)
 	s.Panel.Subscribe(OnResize, s.onResize)
+	s.tracker.InitContainer(&s.Panel, s.update)
 
 	s.update()
 	s.recalc()
 }
 
-// onCursor receives subscribed cursor events over the panel
-func (s *ItemScroller) onCursor(evname string, ev interface{}) {
-
-	switch evname {
-	case OnCursorEnter:
-		s.cursorOver = true
-		s.update()
-	case OnCursorLeave:
-		s.cursorOver = false
-		s.update()
-	}
-}
-
 // onScroll receives mouse scroll events
 func (s *ItemScroller) onScroll(evname string, ev interface{}) {
 
@@ -621,15 +617,16 @@ func (s *ItemScroller) onScrollBarEvent(evname string, ev interface{}) {
 // update updates the visual state the list and its items
 func (s *ItemScroller) update() {
 
-	if s.cursorOver {
+	switch s.tracker.State() {
+	case StyleDisabled:
+		s.applyStyle(&s.styles.Disabled)
+	case StylePressed, StyleOver:
 		s.applyStyle(&s.styles.Over)
-		return
-	}
-	if s.focus {
+	case StyleFocus:
 		s.applyStyle(&s.styles.Focus)
-		return
+	default:
+		s.applyStyle(&s.styles.Normal)
 	}
-	s.applyStyle(&s.styles.Normal)
 }
 
 // applyStyle sets the specified style
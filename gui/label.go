@@ -5,21 +5,23 @@
 package gui
 
 import (
+	"image"
+
 	"github.com/g3n/engine/gls"
 	"github.com/g3n/engine/math32"
 	"github.com/g3n/engine/text"
 	"github.com/g3n/engine/texture"
-	"github.com/g3n/engine/window"
 )
 
 // Label is a panel which contains a texture with text.
 // The content size of the label panel is the exact size of the texture.
 type Label struct {
-	Panel                    // Embedded Panel
-	font  *text.Font         // TrueType font face
-	tex   *texture.Texture2D // Texture with text
-	style *LabelStyle        // The style of the panel and font attributes
-	text  string             // Text being displayed
+	Panel                     // Embedded Panel
+	font   *text.Font         // TrueType font face
+	tex    *texture.Texture2D // Texture with text
+	style  *LabelStyle        // The style of the panel and font attributes
+	text   string             // Text being displayed
+	markup bool               // Text is markup parsed by text.ParseMarkup
 }
 
 // LabelStyle contains all the styling attributes of a Label.
@@ -69,13 +71,38 @@ func (l *Label) initialize(msg string, font *text.Font) {
 	l.style = &styleCopy
 
 	l.SetText(msg)
+	l.Panel.Subscribe(OnContentScale, func(evname string, ev interface{}) { l.redraw() })
 }
 
 // SetText sets and draws the label text using the font.
 func (l *Label) SetText(text string) {
 
-	// Need at least a character to get dimensions
 	l.text = text
+	l.markup = false
+	l.redraw()
+}
+
+// SetMarkup sets and draws the label text from the specified markup,
+// which can contain bold, italic, colored and icon runs (see text.ParseMarkup).
+// The bold runs are drawn with the default bold font and the icons with the default icon font.
+func (l *Label) SetMarkup(markup string) {
+
+	l.text = markup
+	l.markup = true
+	l.redraw()
+}
+
+// Markup returns if the label text is markup.
+func (l *Label) Markup() bool {
+
+	return l.markup
+}
+
+// redraw draws the label text or markup into the label texture.
+func (l *Label) redraw() {
+
+	// Need at least a character to get dimensions
+	text := l.text
 	if text == "" {
 		text = " "
 	}
@@ -84,11 +111,16 @@ func (l *Label) SetText(text string) {
 	l.font.SetAttributes(&l.style.FontAttributes)
 	l.font.SetColor(&l.style.FgColor)
 
-	scaleX, scaleY := window.Get().GetScale()
+	scaleX, scaleY := renderScale()
 	l.font.SetScaleXY(scaleX, scaleY)
 
 	// Create an image with the text
-	textImage := l.font.DrawText(text)
+	var textImage *image.RGBA
+	if l.markup {
+		textImage = l.drawMarkup(text, scaleX, scaleY)
+	} else {
+		textImage = l.font.DrawText(text)
+	}
 
 	// Create texture if it doesn't exist yet
 	if l.tex == nil {
@@ -108,6 +140,26 @@ func (l *Label) SetText(text string) {
 	l.Panel.SetContentSize(width, height)
 }
 
+// drawMarkup draws the runs of the specified markup with the label font as the regular font
+// and returns the image.
+func (l *Label) drawMarkup(markup string, scaleX, scaleY float64) *image.RGBA {
+
+	fonts := text.MarkupFonts{Regular: l.font}
+	if bold := StyleDefault().FontBold; bold != nil && bold != l.font {
+		fonts.Bold = bold
+	}
+	if icon := StyleDefault().FontIcon; icon != l.font {
+		fonts.Icon = icon
+	}
+	for _, f := range []*text.Font{fonts.Bold, fonts.Icon} {
+		if f != nil {
+			f.SetAttributes(&l.style.FontAttributes)
+			f.SetScaleXY(scaleX, scaleY)
+		}
+	}
+	return fonts.DrawRuns(text.ParseMarkup(markup), &l.style.FgColor)
+}
+
 // Text returns the label text.
 func (l *Label) Text() string {
 
@@ -119,7 +171,7 @@ func (l *Label) Text() string {
 func (l *Label) SetColor(color *math32.Color) *Label {
 
 	l.style.FgColor.FromColor(color, 1.0)
-	l.SetText(l.text)
+	l.redraw()
 	return l
 }
 
@@ -127,7 +179,7 @@ func (l *Label) SetColor(color *math32.Color) *Label {
 func (l *Label) SetColor4(color4 *math32.Color4) *Label {
 
 	l.style.FgColor = *color4
-	l.SetText(l.text)
+	l.redraw()
 	return l
 }
 
@@ -143,7 +195,7 @@ func (l *Label) SetBgColor(color *math32.Color) *Label {
 
 	l.style.BgColor.FromColor(color, 1.0)
 	l.Panel.SetColor4(&l.style.BgColor)
-	l.SetText(l.text)
+	l.redraw()
 	return l
 }
 
@@ -152,7 +204,7 @@ func (l *Label) SetBgColor4(color *math32.Color4) *Label {
 
 	l.style.BgColor = *color
 	l.Panel.SetColor4(&l.style.BgColor)
-	l.SetText(l.text)
+	l.redraw()
 	return l
 }
 
@@ -166,7 +218,7 @@ func (l *Label) BgColor() math32.Color4 {
 func (l *Label) SetFont(f *text.Font) {
 
 	l.font = f
-	l.SetText(l.text)
+	l.redraw()
 }
 
 // Font returns the font.
@@ -179,7 +231,7 @@ func (l *Label) Font() *text.Font {
 func (l *Label) SetFontSize(size float64) *Label {
 
 	l.style.PointSize = size
-	l.SetText(l.text)
+	l.redraw()
 	return l
 }
 
@@ -193,7 +245,7 @@ func (l *Label) FontSize() float64 {
 func (l *Label) SetFontDPI(dpi float64) *Label {
 
 	l.style.DPI = dpi
-	l.SetText(l.text)
+	l.redraw()
 	return l
 }
 
@@ -207,7 +259,7 @@ func (l *Label) FontDPI() float64 {
 func (l *Label) SetLineSpacing(spacing float64) *Label {
 
 	l.style.LineSpacing = spacing
-	l.SetText(l.text)
+	l.redraw()
 	return l
 }
 
@@ -218,21 +270,25 @@ func (l *Label) LineSpacing() float64 {
 }
 
 // setTextCaret sets the label text and draws a caret at the
-// specified line and column.
+// specified line and column, underlining the columns from
+// compStart to compEnd of the text being composed with an IME.
 // It is normally used by the Edit widget.
-func (l *Label) setTextCaret(msg string, mx, width int, drawCaret bool, line, col, selStart, selEnd int) {
+func (l *Label) setTextCaret(msg string, mx, width int, drawCaret bool, line, col, selStart, selEnd, compStart, compEnd int) {
 
 	// Set font properties
 	l.font.SetAttributes(&l.style.FontAttributes)
 	l.font.SetColor(&l.style.FgColor)
 
-	scaleX, scaleY := window.Get().GetScale()
+	scaleX, scaleY := renderScale()
 	l.font.SetScaleXY(scaleX, scaleY)
 
 	// Create canvas and draw text
 	_, height := l.font.MeasureText(msg)
 	canvas := text.NewCanvas(width, height, &l.style.BgColor)
 	canvas.DrawTextCaret(mx, 0, msg, l.font, drawCaret, line, col, selStart, selEnd)
+	if compStart != compEnd {
+		canvas.DrawUnderline(mx, 0, msg, l.font, line, compStart, compEnd)
+	}
 
 	// Creates texture if if doesnt exist.
 	if l.tex == nil {
@@ -249,4 +305,5 @@ func (l *Label) setTextCaret(msg string, mx, width int, drawCaret bool, line, co
 	// Updates label panel dimensions
 	l.Panel.SetContentSize(float32(width) / float32(scaleX), float32(height) / float32(scaleY))
 	l.text = msg
+	l.markup = false
 }
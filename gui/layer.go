@@ -0,0 +1,111 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"fmt"
+)
+
+// Layer is an absolute Z-layer in which panels are rendered and receive events.
+// Panels in a higher layer are always above the panels in lower layers,
+// independently of their position in the scene graph. Inside a layer, panels are
+// ordered by their Z-layer delta and then by their order in the scene graph.
+type Layer int
+
+// The named GUI layers.
+// The layers are far apart so the Z-layer deltas used by widgets
+// (as the folder contents and sub menus) keep their panels inside the same layer.
+const (
+	LayerBackground = Layer(-1000) // Panels behind all other panels
+	LayerNormal     = Layer(0)     // Default layer of the GUI root panels
+	LayerFloating   = Layer(1000)  // Floating windows and palettes
+	LayerModal      = Layer(2000)  // Modal dialogs
+	LayerPopup      = Layer(2500)  // Dropdown lists, menus and other popups
+	LayerTooltip    = Layer(3000)  // Tooltips and other transient popups
+)
+
+// layerNames maps the named layers to their names
+var layerNames = map[Layer]string{
+	LayerBackground: "background",
+	LayerNormal:     "normal",
+	LayerFloating:   "floating",
+	LayerModal:      "modal",
+	LayerPopup:      "popup",
+	LayerTooltip:    "tooltip",
+}
+
+// String returns the name of the layer or its numeric value if it is not a named layer.
+func (l Layer) String() string {
+
+	if name, ok := layerNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("layer(%d)", int(l))
+}
+
+// SetLayer sets the absolute layer of this panel and of its descendants
+// overriding the layer inherited from its parent.
+func (p *Panel) SetLayer(layer Layer) {
+
+	p.layer = layer
+	p.layered = true
+	p.SetChanged(true)
+}
+
+// ClearLayer removes the layer set for this panel which
+// returns to the layer inherited from its parent.
+func (p *Panel) ClearLayer() {
+
+	p.layered = false
+	p.SetChanged(true)
+}
+
+// Layer returns the layer set for this panel and true
+// or false if this panel inherits the layer of its parent.
+func (p *Panel) Layer() (Layer, bool) {
+
+	return p.layer, p.layered
+}
+
+// SetLayer moves the specified panel, normally a window, and its descendants
+// to the specified layer.
+func (gm *manager) SetLayer(ipan IPanel, layer Layer) {
+
+	ipan.SetLayer(layer)
+}
+
+// ClearLayer moves the specified panel back to the layer inherited from its parent.
+func (gm *manager) ClearLayer(ipan IPanel) {
+
+	ipan.ClearLayer()
+}
+
+// Layer returns the layer in which the specified panel is rendered:
+// the layer set for the panel or for its nearest ancestor which has
+// one or LayerNormal if none.
+func (gm *manager) Layer(ipan IPanel) Layer {
+
+	for ipan != nil {
+		if layer, ok := ipan.Layer(); ok {
+			return layer
+		}
+		par, ok := ipan.Parent().(IPanel)
+		if !ok {
+			break
+		}
+		ipan = par
+	}
+	return LayerNormal
+}
+
+// BringToFront moves the specified panel above its siblings in the same layer.
+// Panels of different layers are ordered by their layers, so popups which must
+// be above all other panels, as dropdown lists and menus, are set in LayerPopup.
+func (gm *manager) BringToFront(ipan IPanel) {
+
+	if par, ok := ipan.Parent().(IPanel); ok {
+		par.GetPanel().SetTopChild(ipan)
+	}
+}
@@ -14,19 +14,19 @@ type List struct {
 	styles       *ListStyles // Pointer to styles
 	single       bool        // Single selection flag (default is true)
 	focus        bool        // has keyboard focus
-	dropdown     bool        // this is used as dropdown
 	keyNext      window.Key  // Code of key to select next item
 	keyPrev      window.Key  // Code of key to select previous item
 }
 
 // ListItem encapsulates each item inserted into the list
 type ListItem struct {
-	Panel               // Container panel
-	item        IPanel  // Original item
-	selected    bool    // Item selected flag
-	highlighted bool    // Item highlighted flag
-	padLeft     float32 // Additional left padding
-	list        *List   // Pointer to list
+	Panel                    // Container panel
+	item        IPanel       // Original item
+	selected    bool         // Item selected flag
+	highlighted bool         // Item highlighted flag
+	padLeft     float32      // Additional left padding
+	list        *List        // Pointer to list
+	tracker     StateTracker // Tracker of the item pseudo-state
 }
 
 // ListStyles encapsulates a set of styles for the list and item.
@@ -128,7 +128,6 @@ func (li *List) InsertAt(pos int, item IPanel) *ListItem {
 	litem := newListItem(li, item)
 	li.ItemScroller.InsertAt(pos, litem)
 	litem.Panel.Subscribe(OnMouseDown, litem.onMouse)
-	litem.Panel.Subscribe(OnCursorEnter, litem.onCursor)
 	return litem
 }
 
@@ -157,6 +156,27 @@ func (li *List) Remove(item IPanel) {
 	}
 }
 
+// SetDragHandle sets the panel, usually a grip icon inside the specified item, by which
+// the item is dragged in the drag to reorder mode. A nil handle drags the whole item.
+func (li *List) SetDragHandle(item, handle IPanel) {
+
+	pos := li.ItemPosition(item)
+	if pos < 0 {
+		return
+	}
+	li.ItemScroller.SetDragHandle(li.items[pos], handle)
+}
+
+// DragHandle returns the panel by which the specified item is dragged in the drag to reorder mode.
+func (li *List) DragHandle(item IPanel) IPanel {
+
+	pos := li.ItemPosition(item)
+	if pos < 0 {
+		return nil
+	}
+	return li.ItemScroller.DragHandle(li.items[pos])
+}
+
 // ItemAt returns the list item at the specified position
 func (li *List) ItemAt(pos int) IPanel {
 
@@ -370,22 +390,7 @@ func (li *List) highlighted() (pos int) {
 func (li *List) onKeyEvent(evname string, ev interface{}) {
 
 	kev := ev.(*window.KeyEvent)
-	// Dropdown mode
-	if li.dropdown {
-		switch kev.Key {
-		case li.keyNext:
-			li.selNext(true, true)
-		case li.keyPrev:
-			li.selPrev(true, true)
-		case window.KeyEnter:
-			li.SetVisible(false)
-		default:
-			return
-		}
-		return
-	}
-
-	// Listbox mode single selection
+	// Single selection
 	if li.single {
 		switch kev.Key {
 		case li.keyNext:
@@ -398,7 +403,7 @@ func (li *List) onKeyEvent(evname string, ev interface{}) {
 		return
 	}
 
-	// Listbox mode multiple selection
+	// Multiple selection
 	switch kev.Key {
 	case li.keyNext:
 		li.selNext(false, true)
@@ -467,6 +472,7 @@ func newListItem(list *List, item IPanel) *ListItem {
 	litem.Subscribe(OnResize, func(evname string, ev interface{}) {
 		item.GetPanel().Dispatch(OnListItemResize, nil)
 	})
+	litem.tracker.InitContainer(&litem.Panel, litem.update)
 	litem.update()
 	return litem
 }
@@ -479,18 +485,6 @@ func (litem *ListItem) onMouse(evname string, ev interface{}) {
 	} else {
 		litem.list.setSelection(litem, !litem.selected, true, true)
 	}
-	if litem.list.dropdown {
-		litem.list.SetVisible(false)
-	}
-}
-
-// onCursor receives subscribed cursor events over the list item
-func (litem *ListItem) onCursor(evname string, ev interface{}) {
-
-	if litem.list.dropdown {
-		litem.list.setSelection(litem, true, true, false)
-		return
-	}
 }
 
 // SetSelected sets this item selected state
@@ -523,6 +517,10 @@ func (litem *ListItem) update() {
 		litem.applyStyle(&list.styles.Item.SelHigh)
 		return
 	}
+	if litem.tracker.State() == StyleOver {
+		litem.applyStyle(&list.styles.Item.Over)
+		return
+	}
 	litem.applyStyle(&list.styles.Item.Normal)
 }
 
@@ -6,6 +6,7 @@ package gui
 
 import (
 	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
 	"github.com/g3n/engine/window"
 )
 
@@ -23,6 +24,14 @@ type manager struct {
 	keyFocus          core.IDispatcher    // IDispatcher which will exclusively receive all key and char events
 	cursorFocus       core.IDispatcher    // IDispatcher which will exclusively receive all OnCursor events
 	cev               *window.CursorEvent // IDispatcher which will exclusively receive all OnCursor events
+	toaster           toaster             // Toast notifications
+	menus             []*Menu             // Root menus receiving key events before the focused panel
+	scale             float32             // UI scale factor
+	scaleAuto         bool                // UI scale follows the DPI scale of the monitor
+	scaleShortcuts    bool                // UI scale is changed with keyboard and scroll shortcuts
+	scaledCursor      window.CursorEvent  // Cursor event in UI units being dispatched to the panels
+	scaledMouse       window.MouseEvent   // Mouse event in UI units being dispatched to the panels
+	scaledDrop        window.DropEvent    // Drop event in UI units being dispatched to the panels
 }
 
 // Manager returns the GUI manager singleton (creating it the first time)
@@ -36,6 +45,7 @@ func Manager() *manager {
 	gm = new(manager)
 	gm.Dispatcher.Initialize()
 	gm.TimerManager.Initialize()
+	gm.toaster.corner = ToastTopRight
 
 	// Subscribe to window events
 	gm.win = window.Get()
@@ -43,11 +53,16 @@ func Manager() *manager {
 	gm.win.Subscribe(window.OnKeyDown, gm.onKeyboard)
 	gm.win.Subscribe(window.OnKeyRepeat, gm.onKeyboard)
 	gm.win.Subscribe(window.OnChar, gm.onKeyboard)
+	gm.win.Subscribe(window.OnComposition, gm.onKeyboard)
 	gm.win.Subscribe(window.OnCursor, gm.onCursor)
 	gm.win.Subscribe(window.OnMouseUp, gm.onMouse)
 	gm.win.Subscribe(window.OnMouseDown, gm.onMouse)
 	gm.win.Subscribe(window.OnScroll, gm.onScroll)
+	gm.win.Subscribe(window.OnDrop, gm.onDrop)
+	gm.win.Subscribe(window.OnContentScale, gm.onContentScale)
 
+	gm.scaleAuto = true
+	gm.scale = math32.Clamp(gm.autoScale(), scaleMin, scaleMax)
 	return gm
 }
 
@@ -94,11 +109,29 @@ func (gm *manager) SetCursorFocus(disp core.IDispatcher) {
 	}
 }
 
-// onKeyboard is called when char or key events are received.
+// onKeyboard is called when char, key or IME composition events are received.
 // The events are dispatched to the focused IDispatcher or to non-GUI.
 func (gm *manager) onKeyboard(evname string, ev interface{}) {
 
+	if gm.onScaleShortcut(evname, ev) {
+		return
+	}
+
+	// Gives the root menus a chance to handle mnemonics and shortcuts
+	if kev, ok := ev.(*window.KeyEvent); ok {
+		for _, m := range gm.menus {
+			if gm.modal != nil && !gm.modal.IsAncestorOf(m) {
+				continue
+			}
+			if m.onGlobalKey(evname, kev) {
+				return
+			}
+		}
+	}
 	if gm.keyFocus != nil {
+		if !active(gm.keyFocus) {
+			return
+		}
 		if gm.modal == nil {
 			gm.keyFocus.Dispatch(evname, ev)
 		} else if ipan, ok := gm.keyFocus.(IPanel); ok && gm.modal.IsAncestorOf(ipan) {
@@ -109,6 +142,25 @@ func (gm *manager) onKeyboard(evname string, ev interface{}) {
 	}
 }
 
+// addMenu adds the specified root menu to the menus receiving key events before the focused panel.
+func (gm *manager) addMenu(m *Menu) {
+
+	gm.menus = append(gm.menus, m)
+}
+
+// removeMenu removes the specified menu from the menus receiving key events before the focused panel.
+func (gm *manager) removeMenu(m *Menu) {
+
+	for i, menu := range gm.menus {
+		if menu == m {
+			copy(gm.menus[i:], gm.menus[i+1:])
+			gm.menus[len(gm.menus)-1] = nil
+			gm.menus = gm.menus[:len(gm.menus)-1]
+			return
+		}
+	}
+}
+
 // onMouse is called when mouse events are received.
 // OnMouseDown/OnMouseUp are dispatched to gm.target or to non-GUI, while
 // OnMouseDownOut/OnMouseUpOut are dispatched to all non-target panels.
@@ -129,14 +181,20 @@ func (gm *manager) onMouse(evname string, ev interface{}) {
 		return
 	}
 
+	// The target may have been hidden or disabled since the last cursor event
+	if gm.target != nil && !active(gm.target) {
+		gm.target = nil
+	}
+
 	// Dispatch OnMouseDownOut/OnMouseUpOut to all panels except ancestors of target
+	pev := gm.panelEvent(ev)
 	gm.forEachIPanel(func(ipan IPanel) {
 		if gm.target == nil || !ipan.IsAncestorOf(gm.target) {
 			switch evname {
 			case OnMouseDown:
-				ipan.Dispatch(OnMouseDownOut, ev)
+				ipan.Dispatch(OnMouseDownOut, pev)
 			case OnMouseUp:
-				ipan.Dispatch(OnMouseUpOut, ev)
+				ipan.Dispatch(OnMouseUpOut, pev)
 			}
 		}
 	})
@@ -144,7 +202,7 @@ func (gm *manager) onMouse(evname string, ev interface{}) {
 	// Appropriately dispatch the event to target panel's lowest subscribed ancestor or to non-GUI or not at all
 	if gm.target != nil {
 		if gm.modal == nil || gm.modal.IsAncestorOf(gm.target) {
-			sendAncestry(gm.target, false, nil, gm.modal, evname, ev)
+			sendAncestry(gm.target, false, nil, gm.modal, evname, pev)
 		}
 	} else if gm.modal == nil {
 		gm.Dispatch(evname, ev)
@@ -155,6 +213,10 @@ func (gm *manager) onMouse(evname string, ev interface{}) {
 // The events are dispatched to the target panel or to non-GUI.
 func (gm *manager) onScroll(evname string, ev interface{}) {
 
+	if gm.onScaleShortcut(evname, ev) {
+		return
+	}
+
 	// Check if gm.scene is nil and if so then there are no IPanels to send events to
 	if gm.scene == nil {
 		gm.Dispatch(evname, ev) // Dispatch event to non-GUI since event was not filtered by any GUI component
@@ -162,7 +224,7 @@ func (gm *manager) onScroll(evname string, ev interface{}) {
 	}
 
 	// Appropriately dispatch the event to target panel's lowest subscribed ancestor or to non-GUI or not at all
-	if gm.target != nil {
+	if gm.target != nil && active(gm.target) {
 		if gm.modal == nil || gm.modal.IsAncestorOf(gm.target) {
 			sendAncestry(gm.target, false, nil, gm.modal, evname, ev)
 		}
@@ -171,13 +233,85 @@ func (gm *manager) onScroll(evname string, ev interface{}) {
 	}
 }
 
+// onDrop is called when files are dropped on the window.
+// The event is dispatched to the panel under the drop position or to non-GUI.
+// The cursor events are not received while dragging so the target is not used.
+func (gm *manager) onDrop(evname string, ev interface{}) {
+
+	// Check if gm.scene is nil and if so then there are no IPanels to send events to
+	if gm.scene == nil {
+		gm.Dispatch(evname, ev) // Dispatch event to non-GUI since event was not filtered by any GUI component
+		return
+	}
+
+	// Dispatch the event to the lowest subscribed ancestor of the panel under the drop position or to non-GUI or not at all
+	dev := gm.panelEvent(ev).(*window.DropEvent)
+	if target := gm.panelAt(dev.Xpos, dev.Ypos); target != nil {
+		if gm.modal == nil || gm.modal.IsAncestorOf(target) {
+			sendAncestry(target, false, nil, gm.modal, evname, dev)
+		}
+	} else if gm.modal == nil {
+		gm.Dispatch(evname, ev)
+	}
+}
+
+// onContentScale is called when the window DPI scale changes.
+// The automatic UI scale is updated and the event is dispatched to all panels of the scene,
+// including the disabled and invisible ones, so they redraw their images at the new scale, and to non-GUI.
+func (gm *manager) onContentScale(evname string, ev interface{}) {
+
+	scaled := false
+	if gm.scaleAuto {
+		scale := math32.Clamp(gm.autoScale(), scaleMin, scaleMax)
+		scaled = scale != gm.scale
+		gm.scale = scale
+	}
+	if gm.scene != nil {
+		gm.dispatchAll(gm.scene, evname, ev)
+	}
+	gm.Dispatch(evname, ev)
+	if scaled {
+		gm.Dispatch(OnScale, nil)
+	}
+}
+
+// dispatchAll dispatches the specified event to all the panels of the specified node and its descendants,
+// including the disabled and invisible ones.
+func (gm *manager) dispatchAll(inode core.INode, evname string, ev interface{}) {
+
+	if ipan, ok := inode.(IPanel); ok {
+		ipan.Dispatch(evname, ev)
+	}
+	for _, child := range inode.Children() {
+		gm.dispatchAll(child, evname, ev)
+	}
+}
+
+// panelAt returns the enabled and visible IPanel immediately under the specified position or nil.
+func (gm *manager) panelAt(x, y float32) IPanel {
+
+	var target IPanel
+	gm.forEachIPanel(func(ipan IPanel) {
+		if ipan.InsideBorders(x, y) && (target == nil || ipan.Position().Z < target.GetPanel().Position().Z) {
+			target = ipan
+		}
+	})
+	return target
+}
+
 // onCursor is called when (mouse) cursor events are received.
 // Updates the target/click panels and dispatches OnCursor, OnCursorEnter, OnCursorLeave events.
 func (gm *manager) onCursor(evname string, ev interface{}) {
 
 	// If an IDispatcher is capturing cursor events dispatch to it and return
 	if gm.cursorFocus != nil {
-		gm.cursorFocus.Dispatch(evname, ev)
+		if active(gm.cursorFocus) {
+			if _, ok := gm.cursorFocus.(IPanel); ok {
+				gm.cursorFocus.Dispatch(evname, gm.panelEvent(ev))
+			} else {
+				gm.cursorFocus.Dispatch(evname, ev)
+			}
+		}
 		return
 	}
 
@@ -190,16 +324,10 @@ func (gm *manager) onCursor(evname string, ev interface{}) {
 	// Get and store CursorEvent
 	gm.cev = ev.(*window.CursorEvent)
 
-	// Temporarily store last target and clear current one
-	oldTarget := gm.target
-	gm.target = nil
-
 	// Find IPanel immediately under the cursor and store it in gm.target
-	gm.forEachIPanel(func(ipan IPanel) {
-		if ipan.InsideBorders(gm.cev.Xpos, gm.cev.Ypos) && (gm.target == nil || ipan.Position().Z < gm.target.GetPanel().Position().Z) {
-			gm.target = ipan
-		}
-	})
+	pev := gm.panelEvent(ev).(*window.CursorEvent)
+	oldTarget := gm.target
+	gm.target = gm.panelAt(pev.Xpos, pev.Ypos)
 
 	// If the cursor is now over a different panel, dispatch OnCursorLeave/OnCursorEnter
 	if gm.target != oldTarget {
@@ -210,18 +338,18 @@ func (gm *manager) onCursor(evname string, ev interface{}) {
 		}
 		// If just left a panel and the new panel is not a descendant of the old panel
 		if oldTarget != nil && !oldTarget.IsAncestorOf(gm.target) && (gm.modal == nil || gm.modal.IsAncestorOf(oldTarget)) {
-			sendAncestry(oldTarget, true, commonAnc, gm.modal, OnCursorLeave, ev)
+			sendAncestry(oldTarget, true, commonAnc, gm.modal, OnCursorLeave, pev)
 		}
 		// If just entered a panel and it's not an ancestor of the old panel
 		if gm.target != nil && !gm.target.IsAncestorOf(oldTarget) && (gm.modal == nil || gm.modal.IsAncestorOf(gm.target)) {
-			sendAncestry(gm.target, true, commonAnc, gm.modal, OnCursorEnter, ev)
+			sendAncestry(gm.target, true, commonAnc, gm.modal, OnCursorEnter, pev)
 		}
 	}
 
 	// Appropriately dispatch the event to target panel's lowest subscribed ancestor or to non-GUI or not at all
 	if gm.target != nil {
 		if gm.modal == nil || gm.modal.IsAncestorOf(gm.target) {
-			sendAncestry(gm.target, false, nil, gm.modal, evname, ev)
+			sendAncestry(gm.target, false, nil, gm.modal, evname, pev)
 		}
 	} else if gm.modal == nil {
 		gm.Dispatch(evname, ev)
@@ -251,17 +379,23 @@ func sendAncestry(ipan IPanel, all bool, uptoEx IPanel, uptoIn IPanel, evname st
 	}
 }
 
+// active returns if the specified IDispatcher can receive events, which is false for
+// panels which are hidden or disabled, directly or through any of their ancestors.
+func active(disp core.IDispatcher) bool {
+
+	ipan, ok := disp.(IPanel)
+	return !ok || (ipan.EffectiveVisible() && ipan.EffectiveEnabled())
+}
+
 // traverseIPanel traverses the descendants of the provided IPanel,
 // executing the specified function for each IPanel.
 func traverseIPanel(ipan IPanel, f func(ipan IPanel)) {
 
-	// If panel not visible, ignore entire hierarchy below this point
-	if !ipan.Visible() {
+	// If panel not visible or disabled, ignore entire hierarchy below this point
+	if !ipan.Visible() || !ipan.Enabled() {
 		return
 	}
-	if ipan.Enabled() {
-		f(ipan) // Call specified function
-	}
+	f(ipan) // Call specified function
 	// Check descendants (can assume they are IPanels)
 	for _, child := range ipan.Children() {
 		traverseIPanel(child.(IPanel), f)
@@ -272,6 +406,9 @@ func traverseIPanel(ipan IPanel, f func(ipan IPanel)) {
 // executing the specified function for each IPanel.
 func traverseINode(inode core.INode, f func(ipan IPanel)) {
 
+	if !inode.Visible() {
+		return
+	}
 	if ipan, ok := inode.(IPanel); ok {
 		traverseIPanel(ipan, f)
 	} else {
@@ -9,17 +9,20 @@ import (
 	"github.com/g3n/engine/math32"
 	"github.com/g3n/engine/window"
 
+	"strings"
 	"time"
 )
 
 // Menu is the menu GUI element
 type Menu struct {
-	Panel                // embedded panel
-	styles   *MenuStyles // pointer to current styles
-	bar      bool        // true for menu bar
-	items    []*MenuItem // menu items
-	autoOpen bool        // open sub menus when mouse over if true
-	mitem    *MenuItem   // parent menu item for sub menu
+	Panel                 // embedded panel
+	styles   *MenuStyles  // pointer to current styles
+	bar      bool         // true for menu bar
+	items    []*MenuItem  // menu items
+	autoOpen bool         // open sub menus when mouse over if true
+	mitem    *MenuItem    // parent menu item for sub menu
+	altDown  bool         // Alt key pressed alone, activates the menu bar when released
+	tracker  StateTracker // tracker of the menu body pseudo-state
 }
 
 // MenuBodyStyle describes the style of the menu body
@@ -41,20 +44,23 @@ type MenuStyles struct {
 
 // MenuItem is an option of a Menu
 type MenuItem struct {
-	Panel                       // embedded panel
-	styles   *MenuItemStyles    // pointer to current styles
-	menu     *Menu              // pointer to parent menu
-	licon    *Label             // optional left icon label
-	label    *Label             // optional text label (nil for separators)
-	shortcut *Label             // optional shorcut text label
-	ricon    *Label             // optional right internal icon label for submenu
-	id       string             // optional text id
-	icode    int                // icon code (if icon is set)
-	submenu  *Menu              // pointer to optional associated sub menu
-	keyMods  window.ModifierKey // shortcut key modifier
-	keyCode  window.Key         // shortcut key code
-	disabled bool               // item disabled state
-	selected bool               // selection state
+	Panel                        // embedded panel
+	styles    *MenuItemStyles    // pointer to current styles
+	menu      *Menu              // pointer to parent menu
+	licon     *Label             // optional left icon label
+	label     *Label             // optional text label (nil for separators)
+	shortcut  *Label             // optional shorcut text label
+	ricon     *Label             // optional right internal icon label for submenu
+	id        string             // optional text id
+	icode     int                // icon code (if icon is set)
+	submenu   *Menu              // pointer to optional associated sub menu
+	keyMods   window.ModifierKey // shortcut key modifier
+	keyCode   window.Key         // shortcut key code
+	mnemonic  string             // mnemonic key text (empty if none)
+	mnemPos   int                // position of the mnemonic character in the label text
+	underline *Panel             // optional underline of the mnemonic character
+	disabled  bool               // item disabled state
+	selected  bool               // selection state
 }
 
 // MenuItemStyle describes the style of a menu item
@@ -155,12 +161,16 @@ func NewMenu() *Menu {
 	m.items = make([]*MenuItem, 0)
 	m.Panel.Subscribe(OnKeyDown, m.onKey)
 	m.Panel.Subscribe(OnResize, m.onResize)
+	m.tracker.InitContainer(&m.Panel, m.update)
 	m.update()
+	Manager().addMenu(m)
 	return m
 }
 
 // AddOption creates and adds a new menu item to this menu with the
 // specified text and returns the pointer to the created menu item.
+// A character of the text preceded by '&' is the mnemonic of the item,
+// "&&" is shown as a single '&'.
 func (m *Menu) AddOption(text string) *MenuItem {
 
 	mi := newMenuItem(text, m.styles.Item)
@@ -188,7 +198,6 @@ func (m *Menu) AddSeparator() *MenuItem {
 func (m *Menu) AddMenu(text string, subm *Menu) *MenuItem {
 
 	mi := newMenuItem(text, m.styles.Item)
-	mi.zLayerDelta = 1
 	m.Panel.Add(mi)
 	m.items = append(m.items, mi)
 	mi.submenu = subm
@@ -196,6 +205,7 @@ func (m *Menu) AddMenu(text string, subm *Menu) *MenuItem {
 	mi.submenu.SetBounded(false)
 	mi.submenu.mitem = mi
 	mi.submenu.autoOpen = true
+	Manager().removeMenu(mi.submenu)
 	mi.menu = m
 	if !m.bar {
 		mi.ricon = NewIcon(string(icon.PlayArrow))
@@ -212,6 +222,13 @@ func (m *Menu) RemoveItem(mi *MenuItem) {
 
 }
 
+// Dispose releases resources used by this menu.
+func (m *Menu) Dispose() {
+
+	Manager().removeMenu(m)
+	m.Panel.Dispose()
+}
+
 // onKey process subscribed key events
 func (m *Menu) onKey(evname string, ev interface{}) {
 
@@ -299,15 +316,23 @@ func (m *Menu) onKey(evname string, ev interface{}) {
 			m.mitem.menu.setSelectedPos(next)
 			Manager().SetKeyFocus(m.mitem.menu)
 		}
-	// Enter -> Select menu option
-	case window.KeyEnter:
+	// Enter -> Select menu option or open sub menu
+	case window.KeyEnter, window.KeyKPEnter:
 		if sel < 0 {
 			return
 		}
-		mi := m.items[sel]
-		mi.activate()
-	// Check for menu items shortcuts
+		m.openItem(m.items[sel])
+	// Escape -> Close sub menu or leave the menu
+	case window.KeyEscape:
+		m.close()
+	// Check for menu items mnemonics and shortcuts
 	default:
+		if kev.Mods == 0 || (m.bar && kev.Mods == window.ModAlt) {
+			if mi := m.mnemonicItem(kev.Key); mi != nil {
+				m.openItem(mi)
+				return
+			}
+		}
 		var root *Menu
 		if sel < 0 {
 			root = m
@@ -330,6 +355,122 @@ func (m *Menu) onKey(evname string, ev interface{}) {
 	}
 }
 
+// onGlobalKey is called by the manager with the key events of the window before they are
+// dispatched to the focused panel. It opens the menu bar when Alt is pressed alone or with
+// the mnemonic of an item and activates the menu items with the pressed shortcut.
+// Returns true if the event was consumed.
+func (m *Menu) onGlobalKey(evname string, kev *window.KeyEvent) bool {
+
+	if !m.Enabled() {
+		return false
+	}
+	// Key events of a focused menu of this tree are handled by onKey
+	focused := m.hasKeyFocus()
+	alt := kev.Key == window.KeyLeftAlt || kev.Key == window.KeyRightAlt
+	switch evname {
+	case window.OnKeyDown:
+		m.altDown = alt && m.bar
+		if alt || focused {
+			return false
+		}
+		if m.bar && kev.Mods == window.ModAlt {
+			if mi := m.mnemonicItem(kev.Key); mi != nil {
+				m.openItem(mi)
+				return true
+			}
+		}
+		// Shortcuts without Ctrl or Alt are only accelerators when no panel has the key focus
+		if Manager().keyFocus != nil && kev.Mods&(window.ModControl|window.ModAlt) == 0 {
+			return false
+		}
+		found := m.checkKey(kev)
+		if found == nil || found.submenu != nil {
+			return false
+		}
+		found.activate()
+		return true
+	case window.OnKeyUp:
+		if !alt || !m.altDown {
+			return false
+		}
+		m.altDown = false
+		if m.selectedPos() >= 0 {
+			m.setSelectedPos(-1)
+			m.autoOpen = false
+			Manager().SetKeyFocus(nil)
+		} else {
+			m.setSelectedPos(m.nextItem(-1))
+			Manager().SetKeyFocus(m)
+		}
+		return true
+	}
+	return false
+}
+
+// hasKeyFocus returns if this menu or any of its sub menus has the key focus.
+func (m *Menu) hasKeyFocus() bool {
+
+	focus, ok := Manager().keyFocus.(*Menu)
+	for ok && focus != nil {
+		if focus == m {
+			return true
+		}
+		if focus.mitem == nil {
+			break
+		}
+		focus = focus.mitem.menu
+	}
+	return false
+}
+
+// mnemonicItem returns the enabled menu item of this menu with the specified mnemonic key or nil.
+func (m *Menu) mnemonicItem(key window.Key) *MenuItem {
+
+	text := mapKeyText[key]
+	if text == "" {
+		return nil
+	}
+	for _, mi := range m.items {
+		if mi.mnemonic == text && !mi.disabled {
+			return mi
+		}
+	}
+	return nil
+}
+
+// openItem selects the specified menu item of this menu and opens its sub menu
+// with the key focus or activates it if it has no sub menu.
+func (m *Menu) openItem(mi *MenuItem) {
+
+	if mi.submenu == nil {
+		mi.activate()
+		return
+	}
+	m.autoOpen = true
+	m.setSelectedItem(mi)
+	Manager().SetKeyFocus(mi.submenu)
+	mi.submenu.setSelectedPos(mi.submenu.nextItem(-1))
+}
+
+// close closes this sub menu giving the key focus back to the parent menu
+// or, for a root menu, unselects its items and releases the key focus.
+func (m *Menu) close() {
+
+	if m.mitem != nil {
+		parent := m.mitem.menu
+		if parent.bar {
+			parent.autoOpen = false
+		}
+		parent.setSelectedItem(m.mitem)
+		m.SetVisible(false)
+		Manager().SetKeyFocus(parent)
+		return
+	}
+	m.autoOpen = false
+	m.setSelectedPos(-1)
+	Manager().SetKeyFocus(nil)
+}
+
 // onMouse process subscribed mouse events for the menu
 func (m *Menu) onMouse(evname string, ev interface{}) {
 
@@ -355,6 +496,9 @@ func (m *Menu) checkKey(kev *window.KeyEvent) *MenuItem {
 
 	for i := 0; i < len(m.items); i++ {
 		mi := m.items[i]
+		if mi.disabled {
+			continue
+		}
 		if mi.keyCode == kev.Key && mi.keyMods == kev.Mods {
 			return mi
 		}
@@ -454,7 +598,16 @@ func (m *Menu) prevItem(pos int) int {
 // update updates the menu visual state
 func (m *Menu) update() {
 
-	m.applyStyle(&m.styles.Body.Normal)
+	switch m.tracker.State() {
+	case StyleDisabled:
+		m.applyStyle(&m.styles.Body.Disabled)
+	case StylePressed, StyleOver:
+		m.applyStyle(&m.styles.Body.Over)
+	case StyleFocus:
+		m.applyStyle(&m.styles.Body.Focus)
+	default:
+		m.applyStyle(&m.styles.Body.Normal)
+	}
 }
 
 // applyStyle applies the specified menu body style
@@ -563,8 +716,9 @@ func newMenuItem(text string, styles *MenuItemStyles) *MenuItem {
 	mi.Panel.Initialize(mi, 0, 0)
 	mi.styles = styles
 	if text != "" {
-		mi.label = NewLabel(text)
+		mi.label = NewLabel("")
 		mi.Panel.Add(mi.label)
+		mi.setText(text)
 		mi.Panel.Subscribe(OnCursorEnter, mi.onCursor)
 		mi.Panel.Subscribe(OnCursor, mi.onCursor)
 		mi.Panel.Subscribe(OnMouseDown, mi.onMouse)
@@ -602,12 +756,53 @@ func (mi *MenuItem) SetText(text string) *MenuItem {
 	if mi.label == nil {
 		return mi
 	}
-	mi.label.SetText(text)
+	mi.setText(text)
 	mi.update()
 	mi.menu.recalc()
 	return mi
 }
 
+// setText sets the label text and the mnemonic of this menu item from the
+// specified text where '&' precedes the mnemonic character.
+func (mi *MenuItem) setText(text string) {
+
+	var sb strings.Builder
+	mi.mnemonic = ""
+	mi.mnemPos = 0
+	runes := []rune(text)
+	pos := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '&' && i+1 < len(runes) {
+			i++
+			r = runes[i]
+			if r != '&' && mi.mnemonic == "" {
+				mi.mnemonic = strings.ToUpper(string(r))
+				mi.mnemPos = pos
+			}
+		}
+		sb.WriteRune(r)
+		pos++
+	}
+	mi.label.SetText(sb.String())
+
+	// Creates or removes the underline of the mnemonic character
+	if mi.mnemonic != "" && mi.underline == nil {
+		mi.underline = NewPanel(0, 1)
+		mi.Panel.Add(mi.underline)
+	} else if mi.mnemonic == "" && mi.underline != nil {
+		mi.Panel.Remove(mi.underline)
+		mi.underline.Dispose()
+		mi.underline = nil
+	}
+}
+
+// Mnemonic returns the text of the mnemonic key of this menu item or an empty string if none.
+func (mi *MenuItem) Mnemonic() string {
+
+	return mi.mnemonic
+}
+
 // SetShortcut sets the keyboard shortcut of this menu item
 func (mi *MenuItem) SetShortcut(mods window.ModifierKey, key window.Key) *MenuItem {
 
@@ -782,7 +977,12 @@ func (mi *MenuItem) update() {
 	if mi.selected {
 		mi.applyStyle(&mi.styles.Over)
 		if mi.submenu != nil && mi.menu.autoOpen {
-			mi.menu.SetTopChild(mi)
+			// Opens the sub menu in the popup layer above its menu
+			layer := LayerPopup
+			if ml := Manager().Layer(mi.menu); ml >= LayerPopup {
+				layer = ml + 1
+			}
+			mi.submenu.SetLayer(layer)
 			mi.submenu.SetVisible(true)
 			if mi.menu != nil && mi.menu.bar {
 				mi.submenu.SetPosition(0, mi.Height()-2)
@@ -810,6 +1010,9 @@ func (mi *MenuItem) applyStyle(mis *MenuItemStyle) {
 	if mi.label != nil {
 		mi.label.SetColor4(&mis.FgColor)
 	}
+	if mi.underline != nil {
+		mi.underline.SetColor4(&mis.FgColor)
+	}
 	if mi.shortcut != nil {
 		mi.shortcut.SetPaddingsFrom(&mis.ShortcutPaddings)
 	}
@@ -830,6 +1033,7 @@ func (mi *MenuItem) recalc(iconWidth, labelWidth, shortcutWidth float32) {
 		mi.licon.SetPosition(0, py)
 	}
 	mi.label.SetPosition(iconWidth, 0)
+	mi.recalcUnderline()
 	if mi.shortcut != nil {
 		mi.shortcut.SetPosition(iconWidth+labelWidth, 0)
 	}
@@ -838,6 +1042,26 @@ func (mi *MenuItem) recalc(iconWidth, labelWidth, shortcutWidth float32) {
 	}
 }
 
+// recalcUnderline positions the underline below the mnemonic character of the label.
+func (mi *MenuItem) recalcUnderline() {
+
+	if mi.underline == nil {
+		return
+	}
+	runes := []rune(mi.label.Text())
+	font := mi.label.Font()
+	font.SetAttributes(&mi.label.style.FontAttributes)
+	scaleX, scaleY := renderScale()
+	font.SetScaleXY(scaleX, scaleY)
+	x0, _ := font.MeasureText(string(runes[:mi.mnemPos]))
+	x1, _ := font.MeasureText(string(runes[:mi.mnemPos+1]))
+	l := &mi.label.Panel
+	px := l.Position().X + l.marginSizes.Left + l.borderSizes.Left + l.paddingSizes.Left + float32(x0)/float32(scaleX)
+	py := l.Position().Y + l.marginSizes.Top + l.borderSizes.Top + l.paddingSizes.Top + l.ContentHeight() - 2
+	mi.underline.SetPosition(px, py)
+	mi.underline.SetWidth(float32(x1-x0) / float32(scaleX))
+}
+
 // minHeight returns the minimum height of this menu item
 func (mi *MenuItem) minHeight() float32 {
 
@@ -0,0 +1,88 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// SetNinePatch sets the insets in texels of the borders of the texture of the content area
+// of this panel (as the texture of an Image) which are not stretched when the content area
+// is resized: the corners keep their size, the top and bottom borders are stretched
+// horizontally, the left and right borders vertically and the center in both directions.
+// It allows buttons and window frames to be drawn from small textures without distortion.
+// If the content area is smaller than the borders they are scaled down to fit.
+// Nil removes the nine-patch scaling and the texture is stretched over the content area.
+func (p *Panel) SetNinePatch(insets *RectBounds) {
+
+	if insets == nil {
+		p.ninePatch = nil
+	} else {
+		p.ninePatch = new(RectBounds)
+		*p.ninePatch = *insets
+	}
+	p.SetChanged(true)
+}
+
+// NinePatch returns a pointer to a copy of the nine-patch insets in texels
+// of the content texture of this panel or nil if not set.
+func (p *Panel) NinePatch() *RectBounds {
+
+	if p.ninePatch == nil {
+		return nil
+	}
+	insets := *p.ninePatch
+	return &insets
+}
+
+// contentTexture returns the texture of the content area of this panel,
+// which is the first texture of its material other than the clip mask, or nil.
+func (p *Panel) contentTexture() *texture.Texture2D {
+
+	for _, tex := range p.mat.Textures() {
+		if tex != p.clipMask {
+			return tex
+		}
+	}
+	return nil
+}
+
+// updateNinePatch updates the nine-patch valid flag of the panel uniforms.
+func (p *Panel) updateNinePatch() {
+
+	p.udata.nineValid = 0
+	if p.ninePatch == nil || p.content.Width <= 0 || p.content.Height <= 0 {
+		return
+	}
+	tex := p.contentTexture()
+	if tex == nil || tex.Width() <= 0 || tex.Height() <= 0 {
+		return
+	}
+	p.udata.nineValid = 1
+}
+
+// setNinePatchUniform transfers the nine-patch uniform of this panel with the insets
+// in texture coordinates and in coordinates of the content area.
+func (p *Panel) setNinePatchUniform(gl *gls.GLS) {
+
+	tex := p.contentTexture()
+	tw := float32(tex.Width())
+	th := float32(tex.Height())
+	ins := p.ninePatch
+
+	// Scales down the borders if they don't fit in the content area
+	sx := math32.Min(1, p.content.Width/math32.Max(ins.Left+ins.Right, 1e-6))
+	sy := math32.Min(1, p.content.Height/math32.Max(ins.Top+ins.Bottom, 1e-6))
+
+	data := [8]float32{
+		ins.Top / th, ins.Right / tw, ins.Bottom / th, ins.Left / tw,
+		ins.Top * sy / p.content.Height, ins.Right * sx / p.content.Width,
+		ins.Bottom * sy / p.content.Height, ins.Left * sx / p.content.Width,
+	}
+	const vec4count = 2
+	gl.Uniform4fv(p.uniNine.Location(gl), vec4count, &data[0])
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/g3n/engine/graphic"
 	"github.com/g3n/engine/material"
 	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
 )
 
 /*********************************************
@@ -44,10 +45,14 @@ type IPanel interface {
 	Height() float32
 	Enabled() bool
 	SetEnabled(bool)
+	EffectiveEnabled() bool
 	SetLayout(ILayout)
 	InsideBorders(x, y float32) bool
 	SetZLayerDelta(zLayerDelta int)
 	ZLayerDelta() int
+	SetLayer(layer Layer)
+	ClearLayer()
+	Layer() (Layer, bool)
 
 	// TODO these methods here should probably be defined in INode
 	SetPosition(x, y float32)
@@ -64,12 +69,20 @@ type Panel struct {
 	*graphic.Graphic                    // Embedded graphic
 	mat              *material.Material // panel material
 	zLayerDelta      int                // Z-layer relative to parent
+	layer            Layer              // Absolute layer (if layered)
+	layered          bool               // Whether the panel layer overrides the parent layer
 
 	bounded bool // Whether panel is bounded by its parent
 	enabled bool // Whether event should be processed for this panel
 
-	layout       ILayout     // current layout for children
-	layoutParams interface{} // current layout parameters used by container panel
+	layout       ILayout            // current layout for children
+	layoutParams interface{}        // current layout parameters used by container panel
+	transition   *styleTransition   // optional style colors transition
+	clip         *panelClip         // optional clip shape of this panel and its children
+	clipper      *Panel             // nearest panel (this panel or an ancestor) which clips this panel
+	clipMask     *texture.Texture2D // clip mask texture added to the panel material
+	ninePatch    *RectBounds        // optional nine-patch insets of the content texture in texels
+	opacity      float32            // opacity of this panel and its children
 
 	marginSizes  RectBounds // external margin sizes in pixel coordinates
 	borderSizes  RectBounds // border sizes in pixel coordinates
@@ -89,6 +102,8 @@ type Panel struct {
 	// Uniforms sent to shader
 	uniMatrix gls.Uniform // model matrix uniform location cache
 	uniPanel  gls.Uniform // panel parameters uniform location cache
+	uniClip   gls.Uniform // panel clip shape uniform location cache
+	uniNine   gls.Uniform // panel nine-patch uniform location cache
 	udata     struct {    // Combined uniform data 8 * vec4
 		bounds        math32.Vector4 // panel bounds in texture coordinates
 		borders       math32.Vector4 // panel borders in texture coordinates
@@ -98,7 +113,9 @@ type Panel struct {
 		paddingsColor math32.Color4  // panel padding color
 		contentColor  math32.Color4  // panel content color
 		textureValid  float32        // texture valid flag (bool)
-		dummy         [3]float32     // complete 8 * vec4
+		clipValid     float32        // clip shape valid flag (bool)
+		nineValid     float32        // nine-patch valid flag (bool)
+		opacity       float32        // opacity multiplied by the opacities of the ancestors
 	}
 }
 
@@ -179,9 +196,12 @@ func (p *Panel) Initialize(ipan IPanel, width, height float32) { // TODO rename
 	// Initialize uniforms location caches
 	p.uniMatrix.Init("ModelMatrix")
 	p.uniPanel.Init("Panel")
+	p.uniClip.Init("PanelClip")
+	p.uniNine.Init("PanelNinePatch")
 
 	// Set defaults
 	p.udata.bordersColor = math32.Color4{0, 0, 0, 1}
+	p.opacity = 1
 	p.bounded = true
 	p.enabled = true
 	p.resize(width, height, true)
@@ -197,9 +217,12 @@ func (p *Panel) InitializeGraphic(width, height float32, gr *graphic.Graphic) {
 	// Initializes uniforms location caches
 	p.uniMatrix.Init("ModelMatrix")
 	p.uniPanel.Init("Panel")
+	p.uniClip.Init("PanelClip")
+	p.uniNine.Init("PanelNinePatch")
 
 	// Set defaults
 	p.udata.bordersColor = math32.Color4{0, 0, 0, 1}
+	p.opacity = 1
 	p.bounded = true
 	p.enabled = true
 	p.resize(width, height, true)
@@ -212,6 +235,13 @@ func (p *Panel) GetPanel() *Panel {
 	return p
 }
 
+// Dispose releases resources used by this panel, cancelling its style transition if any.
+func (p *Panel) Dispose() {
+
+	p.stopStyleTransition()
+	p.Graphic.Dispose()
+}
+
 // Material returns a pointer for this panel's Material
 func (p *Panel) Material() *material.Material { // TODO remove - allow for setting and getting a single texture
 
@@ -448,9 +478,7 @@ func (p *Panel) Color4() math32.Color4 {
 // ApplyStyle applies the provided PanelStyle to the panel
 func (p *Panel) ApplyStyle(ps *PanelStyle) {
 
-	p.udata.bordersColor = ps.BorderColor
-	p.udata.paddingsColor = ps.BgColor
-	p.udata.contentColor = ps.BgColor
+	p.applyStyleColors(ps)
 	p.marginSizes = ps.Margin
 	p.borderSizes = ps.Border
 	p.paddingSizes = ps.Padding
@@ -578,6 +606,9 @@ func (p *Panel) InsideBorders(x, y float32) bool {
 		y < (p.pospix.Y+p.marginSizes.Top) || y >= (p.pospix.Y+p.height-p.marginSizes.Bottom) {
 		return false
 	}
+	if p.clipper != nil {
+		return p.clipper.insideClip(x, y)
+	}
 	return true
 }
 
@@ -592,11 +623,20 @@ func (p *Panel) Intersects(other *Panel) bool {
 }
 
 // SetEnabled sets the panel enabled state
-// A disabled panel does not process events.
+// A disabled panel and its descendants do not process events.
+// The enabled state of the descendants is preserved and OnEnable is also
+// dispatched to the descendants whose effective enabled state is changed.
 func (p *Panel) SetEnabled(state bool) {
 
+	changed := state != p.enabled
 	p.enabled = state
 	p.Dispatch(OnEnable, nil)
+	if !changed || !parentEnabled(p) {
+		return
+	}
+	for _, child := range p.Children() {
+		dispatchEnable(child)
+	}
 }
 
 // Enabled returns the current enabled state of this panel
@@ -605,6 +645,38 @@ func (p *Panel) Enabled() bool {
 	return p.enabled
 }
 
+// EffectiveEnabled returns if this panel and all its ancestor panels are enabled.
+func (p *Panel) EffectiveEnabled() bool {
+
+	return p.enabled && parentEnabled(p)
+}
+
+// parentEnabled returns if all the ancestor panels of the specified panel are enabled.
+func parentEnabled(p *Panel) bool {
+
+	for inode := p.Parent(); inode != nil; inode = inode.Parent() {
+		if ipan, ok := inode.(IPanel); ok && !ipan.Enabled() {
+			return false
+		}
+	}
+	return true
+}
+
+// dispatchEnable dispatches OnEnable to the specified node if it is a panel
+// and to its descendants whose effective enabled state depends on it.
+func dispatchEnable(inode core.INode) {
+
+	if ipan, ok := inode.(IPanel); ok {
+		if !ipan.Enabled() {
+			return
+		}
+		ipan.Dispatch(OnEnable, nil)
+	}
+	for _, child := range inode.Children() {
+		dispatchEnable(child)
+	}
+}
+
 // SetLayout sets the layout to use to position the children of this panel
 // To remove the layout, call this function passing nil as parameter.
 func (p *Panel) SetLayout(ilayout ILayout) {
@@ -670,6 +742,14 @@ func (p *Panel) setContentSize(width, height float32, dispatch bool) {
 // bounds considering the bounds of its parent
 func (p *Panel) updateBounds(par *Panel) {
 
+	// Sets the panel which clips this panel if any
+	p.clipper = nil
+	if p.clip != nil {
+		p.clipper = p
+	} else if par != nil && p.bounded {
+		p.clipper = par.clipper
+	}
+
 	// If this panel has no parent, its pixel position is its Position
 	if par == nil {
 		p.pospix.X = p.Position().X
@@ -840,16 +920,49 @@ func (p *Panel) resize(width, height float32, dispatch bool) {
 	p.Dispatch(OnResize, nil)
 }
 
+// SetOpacity sets the opacity, from 0 (transparent) to 1 (the default), which multiplies
+// the alpha of this panel and of its descendant panels.
+func (p *Panel) SetOpacity(opacity float32) {
+
+	p.opacity = math32.Clamp(opacity, 0, 1)
+	p.SetChanged(true)
+}
+
+// Opacity returns the opacity of this panel.
+func (p *Panel) Opacity() float32 {
+
+	return p.opacity
+}
+
+// EffectiveOpacity returns the opacity of this panel multiplied by the opacities of its ancestor panels.
+func (p *Panel) EffectiveOpacity() float32 {
+
+	opacity := p.opacity
+	for par := p.Parent(); par != nil; par = par.Parent() {
+		if ipan, ok := par.(IPanel); ok {
+			opacity *= ipan.GetPanel().opacity
+		}
+	}
+	return opacity
+}
+
 // RenderSetup is called by the Engine before drawing the object
 func (p *Panel) RenderSetup(gl *gls.GLS, rinfo *core.RenderInfo) {
 
 	// Sets texture valid flag in uniforms
-	// depending if the material has texture
-	if p.mat.TextureCount() > 0 {
+	// depending if the material has texture (other than the clip mask)
+	p.updateClip()
+	p.updateNinePatch()
+	texCount := p.mat.TextureCount()
+	if p.clipMask != nil {
+		texCount--
+	}
+	if texCount > 0 {
 		p.udata.textureValid = 1
 	} else {
 		p.udata.textureValid = 0
 	}
+	p.udata.opacity = p.EffectiveOpacity()
 
 	// Sets model matrix
 	var mm math32.Matrix4
@@ -863,13 +976,23 @@ func (p *Panel) RenderSetup(gl *gls.GLS, rinfo *core.RenderInfo) {
 	location = p.uniPanel.Location(gl)
 	const vec4count = 8
 	gl.Uniform4fv(location, vec4count, &p.udata.bounds.X)
+
+	// Transfer clip shape uniform
+	if p.clipper != nil {
+		p.setClipUniform(gl)
+	}
+
+	// Transfer nine-patch uniform
+	if p.udata.nineValid != 0 {
+		p.setNinePatchUniform(gl)
+	}
 }
 
 // SetModelMatrix calculates and sets the specified matrix with the model matrix for this panel
 func (p *Panel) SetModelMatrix(gl *gls.GLS, mm *math32.Matrix4) {
 
-	// Get scale of window (for HiDPI support)
-	sX, sY := Manager().win.GetScale()
+	// Get scale from UI units to pixels (for HiDPI support and UI scaling)
+	sX, sY := renderScale()
 
 	// Get the current viewport width and height
 	_, _, width, height := gl.GetViewport()
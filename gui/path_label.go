@@ -0,0 +1,208 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/text"
+	"github.com/g3n/engine/texture"
+)
+
+// PathLabel is a panel which contains a texture with a single line of
+// text drawn along a 2D polyline path, such as an arc.
+// The path is translated so that the text fits in the content area,
+// which is the bounding box of the path enlarged by the font height.
+type PathLabel struct {
+	Panel                     // Embedded Panel
+	font   *text.Font         // TrueType font face
+	tex    *texture.Texture2D // Texture with text
+	style  *LabelStyle        // The style of the panel and font attributes
+	text   string             // Text being displayed
+	path   []math32.Vector2   // Path in pixels
+	offset float32            // Start distance of the text along the path in pixels
+}
+
+// NewPathLabel creates and returns a label panel with the specified text
+// drawn along the specified path (in pixels) using the default text font.
+func NewPathLabel(text string, path []math32.Vector2) *PathLabel {
+
+	l := new(PathLabel)
+	l.font = StyleDefault().Font
+	l.Panel.Initialize(l, 0, 0)
+	l.Panel.mat.SetTransparent(true)
+
+	// Copy the style based on the default Label style
+	styleCopy := StyleDefault().Label
+	l.style = &styleCopy
+
+	l.path = path
+	l.SetText(text)
+	l.Panel.Subscribe(OnContentScale, func(evname string, ev interface{}) { l.SetText(l.text) })
+	return l
+}
+
+// NewArcLabel creates and returns a label panel with the specified text drawn
+// along the circular arc with the specified radius from startAngle to endAngle (in radians).
+// Angles increase clockwise, so an arc from math32.Pi to 2*math32.Pi draws the text over the top of the circle.
+func NewArcLabel(text string, radius, startAngle, endAngle float32) *PathLabel {
+
+	return NewPathLabel(text, arcPath(radius, startAngle, endAngle))
+}
+
+// SetText sets and draws the label text using the font.
+func (l *PathLabel) SetText(msg string) {
+
+	l.text = msg
+
+	// Set font properties
+	l.font.SetAttributes(&l.style.FontAttributes)
+	l.font.SetColor(&l.style.FgColor)
+
+	scaleX, scaleY := renderScale()
+	l.font.SetScaleXY(scaleX, scaleY)
+
+	// Scale the path and translate it to leave a margin of the font height around it
+	metrics := l.font.Metrics()
+	margin := float32((metrics.Ascent + metrics.Descent).Ceil())
+	min := math32.Vector2{X: math32.Inf(1), Y: math32.Inf(1)}
+	max := math32.Vector2{X: math32.Inf(-1), Y: math32.Inf(-1)}
+	path := make([]math32.Vector2, len(l.path))
+	for i, p := range l.path {
+		path[i].Set(p.X*float32(scaleX), p.Y*float32(scaleY))
+		min.Min(&path[i])
+		max.Max(&path[i])
+	}
+	if len(path) == 0 {
+		min.Set(0, 0)
+		max.Set(0, 0)
+	}
+	for i := range path {
+		path[i].X += margin - min.X
+		path[i].Y += margin - min.Y
+	}
+
+	// Create an image with the text drawn along the path
+	width := int(max.X-min.X+2*margin) + 1
+	height := int(max.Y-min.Y+2*margin) + 1
+	canvas := text.NewCanvas(width, height, &l.style.BgColor)
+	l.font.DrawTextOnPath(msg, path, l.offset*float32(scaleX), canvas.RGBA)
+
+	// Create texture if it doesn't exist yet
+	if l.tex == nil {
+		l.tex = texture.NewTexture2DFromRGBA(canvas.RGBA)
+		l.tex.SetMagFilter(gls.LINEAR)
+		l.tex.SetMinFilter(gls.LINEAR)
+		l.Panel.Material().AddTexture(l.tex)
+		// Otherwise update texture with new image
+	} else {
+		l.tex.SetFromRGBA(canvas.RGBA)
+	}
+
+	// Update label panel dimensions
+	l.Panel.SetContentSize(float32(width)/float32(scaleX), float32(height)/float32(scaleY))
+}
+
+// Text returns the label text.
+func (l *PathLabel) Text() string {
+
+	return l.text
+}
+
+// SetPath sets the path (in pixels) along which the text is drawn.
+func (l *PathLabel) SetPath(path []math32.Vector2) *PathLabel {
+
+	l.path = path
+	l.SetText(l.text)
+	return l
+}
+
+// SetArc sets a circular arc with the specified radius from startAngle to endAngle (in radians)
+// as the path along which the text is drawn.
+func (l *PathLabel) SetArc(radius, startAngle, endAngle float32) *PathLabel {
+
+	return l.SetPath(arcPath(radius, startAngle, endAngle))
+}
+
+// Path returns the path along which the text is drawn.
+func (l *PathLabel) Path() []math32.Vector2 {
+
+	return l.path
+}
+
+// SetOffset sets the start distance (in pixels) of the text along the path.
+func (l *PathLabel) SetOffset(offset float32) *PathLabel {
+
+	l.offset = offset
+	l.SetText(l.text)
+	return l
+}
+
+// Offset returns the start distance (in pixels) of the text along the path.
+func (l *PathLabel) Offset() float32 {
+
+	return l.offset
+}
+
+// SetCentered sets the offset so the text is centered along the path.
+func (l *PathLabel) SetCentered() *PathLabel {
+
+	l.font.SetAttributes(&l.style.FontAttributes)
+	l.font.SetScaleXY(1, 1)
+	return l.SetOffset((text.PathLength(l.path) - l.font.MeasureTextOnPath(l.text)) / 2)
+}
+
+// SetColor4 sets the text color.
+func (l *PathLabel) SetColor4(color4 *math32.Color4) *PathLabel {
+
+	l.style.FgColor = *color4
+	l.SetText(l.text)
+	return l
+}
+
+// Color returns the text color.
+func (l *PathLabel) Color() math32.Color4 {
+
+	return l.style.FgColor
+}
+
+// SetBgColor4 sets the background color.
+func (l *PathLabel) SetBgColor4(color *math32.Color4) *PathLabel {
+
+	l.style.BgColor = *color
+	l.Panel.SetColor4(&l.style.BgColor)
+	l.SetText(l.text)
+	return l
+}
+
+// SetFont sets the font.
+func (l *PathLabel) SetFont(f *text.Font) *PathLabel {
+
+	l.font = f
+	l.SetText(l.text)
+	return l
+}
+
+// SetFontSize sets the point size of the font.
+func (l *PathLabel) SetFontSize(size float64) *PathLabel {
+
+	l.style.PointSize = size
+	l.SetText(l.text)
+	return l
+}
+
+// FontSize returns the point size of the font.
+func (l *PathLabel) FontSize() float64 {
+
+	return l.style.PointSize
+}
+
+// arcPath returns a polyline approximating a circular arc centered at the origin
+// with enough segments for smooth text.
+func arcPath(radius, startAngle, endAngle float32) []math32.Vector2 {
+
+	segments := int(math32.Abs(endAngle-startAngle)*radius/4) + 1
+	return text.ArcPath(0, 0, radius, startAngle, endAngle, segments)
+}
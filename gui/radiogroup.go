@@ -0,0 +1,127 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/core"
+)
+
+// RadioGroup manages a set of radio buttons of which at most one is checked.
+// Checking a button of the group, by the user or with SetValue, unchecks the
+// other buttons and dispatches OnChange to the group with the group as parameter.
+// The buttons are not added to any panel, so they may be placed anywhere.
+type RadioGroup struct {
+	core.Dispatcher               // Embedded event dispatcher
+	buttons         []*CheckRadio // Buttons of the group in the order they were added
+	selected        int           // Index of the checked button (-1 if none)
+}
+
+// NewRadioGroup creates and returns a pointer to a new radio group with the specified buttons.
+func NewRadioGroup(buttons ...*CheckRadio) *RadioGroup {
+
+	rg := new(RadioGroup)
+	rg.Dispatcher.Initialize()
+	rg.selected = -1
+	for _, cb := range buttons {
+		rg.Add(cb)
+	}
+	return rg
+}
+
+// Add adds the specified button to the group, removing it from its previous group,
+// and returns its index. If the button is checked, the other buttons are unchecked.
+func (rg *RadioGroup) Add(cb *CheckRadio) int {
+
+	if cb.radioGroup != nil {
+		cb.radioGroup.Remove(cb)
+	}
+	cb.radioGroup = rg
+	rg.buttons = append(rg.buttons, cb)
+	if cb.Value() {
+		rg.selectButton(cb)
+	}
+	return len(rg.buttons) - 1
+}
+
+// Remove removes the specified button from the group and returns whether it was found.
+// If it was the checked button, no button is checked and OnChange is dispatched.
+func (rg *RadioGroup) Remove(cb *CheckRadio) bool {
+
+	for i, b := range rg.buttons {
+		if b != cb {
+			continue
+		}
+		rg.buttons = append(rg.buttons[:i], rg.buttons[i+1:]...)
+		cb.radioGroup = nil
+		switch {
+		case rg.selected == i:
+			rg.selected = -1
+			rg.Dispatch(OnChange, rg)
+		case rg.selected > i:
+			rg.selected--
+		}
+		return true
+	}
+	return false
+}
+
+// Buttons returns the buttons of the group in the order they were added.
+func (rg *RadioGroup) Buttons() []*CheckRadio {
+
+	return rg.buttons
+}
+
+// Len returns the number of buttons of the group.
+func (rg *RadioGroup) Len() int {
+
+	return len(rg.buttons)
+}
+
+// SelectedIndex returns the index of the checked button or -1 if none.
+func (rg *RadioGroup) SelectedIndex() int {
+
+	return rg.selected
+}
+
+// Selected returns the checked button or nil if none.
+func (rg *RadioGroup) Selected() *CheckRadio {
+
+	if rg.selected < 0 {
+		return nil
+	}
+	return rg.buttons[rg.selected]
+}
+
+// SetSelectedIndex checks the button with the specified index and unchecks the other buttons.
+// An index out of range unchecks all the buttons.
+func (rg *RadioGroup) SetSelectedIndex(idx int) {
+
+	if idx < 0 || idx >= len(rg.buttons) {
+		rg.selectButton(nil)
+		return
+	}
+	rg.selectButton(rg.buttons[idx])
+}
+
+// selectButton checks the specified button of the group, or none if nil, unchecks the
+// other buttons and dispatches OnChange if the checked button changed.
+func (rg *RadioGroup) selectButton(cb *CheckRadio) {
+
+	prev := rg.selected
+	rg.selected = -1
+	for i, b := range rg.buttons {
+		if b == cb {
+			rg.selected = i
+			continue
+		}
+		b.setState(false, false)
+	}
+	if cb != nil {
+		cb.setState(true, false)
+	}
+	if rg.selected != prev {
+		rg.Dispatch(OnChange, rg)
+	}
+}
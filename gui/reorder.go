@@ -0,0 +1,245 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/window"
+)
+
+// OnReorder is the event dispatched by an ItemScroller or a List when the user moves an item
+// by dragging it in the drag to reorder mode. The event parameter is a pointer to ReorderEvent.
+const OnReorder = "gui.OnReorder"
+
+// ReorderEvent describes the move of an item of an ItemScroller or List.
+type ReorderEvent struct {
+	From int // Previous position of the item
+	To   int // New position of the item
+}
+
+// Thickness in pixels of the insertion indicator
+const reorderIndicatorSize = 2
+
+// reorderState contains the state of the drag to reorder mode of an ItemScroller.
+type reorderState struct {
+	enabled   bool              // Drag to reorder mode enabled
+	handles   map[IPanel]IPanel // Drag handles of the items which are not dragged by the whole item
+	item      IPanel            // Item being dragged or nil
+	dest      int               // Position where the dragged item is inserted before its removal
+	indicator *Panel            // Insertion indicator
+}
+
+// SetReorderable sets whether the user can move the items by dragging them, or their drag handles,
+// with the left mouse button. An indicator shows where the item is inserted while it's dragged,
+// the scroller scrolls when the cursor is dragged before or after it and OnReorder is dispatched
+// when the item is dropped at a different position.
+func (s *ItemScroller) SetReorderable(state bool) {
+
+	if state == s.reorder.enabled {
+		return
+	}
+	s.cancelDrag()
+	for _, item := range s.items {
+		if state {
+			s.subscribeDrag(item)
+		} else {
+			s.dragPanel(item).UnsubscribeAllID(s)
+		}
+	}
+	s.reorder.enabled = state
+}
+
+// Reorderable returns whether the user can move the items by dragging them.
+func (s *ItemScroller) Reorderable() bool {
+
+	return s.reorder.enabled
+}
+
+// SetDragHandle sets the panel, usually a grip icon inside the specified item, by which
+// the item is dragged in the drag to reorder mode. A nil handle drags the whole item, which is the default.
+func (s *ItemScroller) SetDragHandle(item, handle IPanel) {
+
+	if s.reorder.enabled {
+		s.dragPanel(item).UnsubscribeAllID(s)
+	}
+	if handle == nil || handle == item {
+		delete(s.reorder.handles, item)
+	} else {
+		if s.reorder.handles == nil {
+			s.reorder.handles = make(map[IPanel]IPanel)
+		}
+		s.reorder.handles[item] = handle
+	}
+	if s.reorder.enabled && s.ItemPosition(item) >= 0 {
+		s.subscribeDrag(item)
+	}
+}
+
+// DragHandle returns the panel by which the specified item is dragged in the drag to reorder mode.
+func (s *ItemScroller) DragHandle(item IPanel) IPanel {
+
+	return s.dragPanel(item)
+}
+
+// MoveItem moves the item at the src position to the dest position,
+// shifting the items between them.
+func (s *ItemScroller) MoveItem(src, dest int) error {
+
+	if src < 0 || src >= len(s.items) {
+		return fmt.Errorf("Invalid item source position:%d", src)
+	}
+	if dest < 0 || dest >= len(s.items) {
+		return fmt.Errorf("Invalid item destination position:%d", dest)
+	}
+	if src == dest {
+		return nil
+	}
+	item := s.items[src]
+	if src < dest {
+		copy(s.items[src:], s.items[src+1:dest+1])
+	} else {
+		copy(s.items[dest+1:], s.items[dest:src])
+	}
+	s.items[dest] = item
+	s.recalc()
+	return nil
+}
+
+// dragPanel returns the drag handle of the specified item or the item itself.
+func (s *ItemScroller) dragPanel(item IPanel) IPanel {
+
+	if handle, ok := s.reorder.handles[item]; ok {
+		return handle
+	}
+	return item
+}
+
+// subscribeDrag subscribes to the mouse and cursor events of the drag panel of the specified item.
+func (s *ItemScroller) subscribeDrag(item IPanel) {
+
+	cb := func(evname string, ev interface{}) { s.onDrag(item, evname, ev) }
+	panel := s.dragPanel(item)
+	panel.SubscribeID(OnMouseDown, s, cb)
+	panel.SubscribeID(OnMouseUp, s, cb)
+	panel.SubscribeID(OnCursor, s, cb)
+}
+
+// removeDrag unsubscribes the drag events of the specified item which is being removed.
+func (s *ItemScroller) removeDrag(item IPanel) {
+
+	if item == s.reorder.item {
+		s.cancelDrag()
+	}
+	s.dragPanel(item).UnsubscribeAllID(s)
+	delete(s.reorder.handles, item)
+}
+
+// cancelDrag stops dragging the current item, if any, without moving it.
+func (s *ItemScroller) cancelDrag() {
+
+	if s.reorder.item == nil {
+		return
+	}
+	s.reorder.item = nil
+	if s.reorder.indicator != nil {
+		s.reorder.indicator.SetVisible(false)
+	}
+	Manager().SetCursorFocus(nil)
+}
+
+// onDrag processes the mouse and cursor events over the drag panel of the specified item.
+func (s *ItemScroller) onDrag(item IPanel, evname string, ev interface{}) {
+
+	switch evname {
+	case OnMouseDown:
+		if ev.(*window.MouseEvent).Button != window.MouseButtonLeft {
+			return
+		}
+		s.reorder.item = item
+		s.reorder.dest = s.ItemPosition(item)
+		Manager().SetCursorFocus(s.dragPanel(item))
+	case OnMouseUp:
+		if item != s.reorder.item || ev.(*window.MouseEvent).Button != window.MouseButtonLeft {
+			return
+		}
+		dest := s.reorder.dest
+		s.cancelDrag()
+		src := s.ItemPosition(item)
+		if dest > src {
+			dest--
+		}
+		if src >= 0 && dest != src {
+			s.MoveItem(src, dest)
+			s.Dispatch(OnReorder, &ReorderEvent{From: src, To: dest})
+		}
+	case OnCursor:
+		if item == s.reorder.item {
+			cev := ev.(*window.CursorEvent)
+			cx, cy := s.ContentCoords(cev.Xpos, cev.Ypos)
+			if s.vert {
+				s.dragTo(cy, s.ContentHeight())
+			} else {
+				s.dragTo(cx, s.ContentWidth())
+			}
+		}
+	}
+}
+
+// dragTo updates the insertion position and the indicator for the specified cursor coordinate
+// along the scroller axis, scrolling if the cursor is before or after the scroller.
+func (s *ItemScroller) dragTo(c, size float32) {
+
+	if c < 0 {
+		s.ScrollUp()
+	} else if c > size {
+		s.ScrollDown()
+	}
+
+	// Finds the visible item whose first half is after the cursor
+	var pos float32
+	s.reorder.dest = s.first
+	for i := s.first; i < len(s.items); i++ {
+		panel := s.items[i].GetPanel()
+		if !panel.Visible() {
+			break
+		}
+		start, length := panel.Position().X, panel.Width()
+		if s.vert {
+			start, length = panel.Position().Y, panel.Height()
+		}
+		if c < start+length/2 {
+			pos = start
+			break
+		}
+		s.reorder.dest = i + 1
+		pos = start + length
+	}
+
+	// Shows the indicator between the items
+	if s.reorder.indicator == nil {
+		s.reorder.indicator = NewPanel(0, 0)
+		s.Panel.Add(s.reorder.indicator)
+	}
+	ind := s.reorder.indicator
+	ind.SetColor4(&s.styles.Indicator)
+	if s.vert {
+		width := s.ContentWidth()
+		if s.vscroll != nil && s.vscroll.Visible() {
+			width -= s.vscroll.Width()
+		}
+		ind.SetSize(width, reorderIndicatorSize)
+		ind.SetPosition(0, pos-reorderIndicatorSize/2)
+	} else {
+		height := s.ContentHeight()
+		if s.hscroll != nil && s.hscroll.Visible() {
+			height -= s.hscroll.Height()
+		}
+		ind.SetSize(reorderIndicatorSize, height)
+		ind.SetPosition(pos-reorderIndicatorSize/2, 0)
+	}
+	ind.SetVisible(true)
+	s.Panel.SetTopChild(ind)
+}
@@ -0,0 +1,176 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// OnScale is the event dispatched by the GUI manager to non-GUI when the UI scale changes,
+// so the application can resize its root panels to the new screen size returned by ScreenSize.
+const OnScale = "gui.OnScale"
+
+// Limits and shortcut step of the UI scale
+const (
+	scaleMin  = 0.25
+	scaleMax  = 4
+	scaleStep = 0.1
+)
+
+// contentScaler is implemented by the windows which report the content scale of their monitor,
+// which includes the DPI scale applied by the operating system to the window coordinates.
+type contentScaler interface {
+	GetContentScale() (float32, float32)
+}
+
+// SetScale sets the UI scale factor which multiplies the sizes and positions of all the panels,
+// the resolution of their fonts and images and the cursor positions used to find the panels under
+// the cursor. The panels keep their sizes and positions in UI units and the cursor positions of the
+// events dispatched to them are converted to UI units, so the widgets work unchanged at any scale.
+// The initial scale is the automatic scale of the window, which follows the DPI of its monitor
+// until SetScale is called. The scale is limited to the range [0.25, 4].
+func (gm *manager) SetScale(scale float32) {
+
+	gm.scaleAuto = false
+	gm.setScale(scale)
+}
+
+// Scale returns the UI scale factor.
+func (gm *manager) Scale() float32 {
+
+	return gm.scale
+}
+
+// ResetScale sets the UI scale factor to the automatic scale of the window,
+// which follows the DPI of its monitor.
+func (gm *manager) ResetScale() {
+
+	gm.scaleAuto = true
+	gm.setScale(gm.autoScale())
+}
+
+// SetScaleShortcuts sets whether the UI scale is changed by the user with Ctrl+scroll and Ctrl+'+',
+// Ctrl+'-' and reset with Ctrl+'0'. The shortcut events are not dispatched. The default is false.
+func (gm *manager) SetScaleShortcuts(enable bool) {
+
+	gm.scaleShortcuts = enable
+}
+
+// ScaleShortcuts returns whether the UI scale is changed by the user with keyboard and scroll shortcuts.
+func (gm *manager) ScaleShortcuts() bool {
+
+	return gm.scaleShortcuts
+}
+
+// ScreenSize returns the size of the window in UI units, which is the size of a panel filling the window.
+func (gm *manager) ScreenSize() (float32, float32) {
+
+	width, height := gm.win.GetSize()
+	return float32(width) / gm.scale, float32(height) / gm.scale
+}
+
+// autoScale returns the UI scale which compensates the DPI scale of the monitor not already applied
+// to the framebuffer, as on monitors with a DPI scale on Windows and Linux.
+func (gm *manager) autoScale() float32 {
+
+	cs, ok := gm.win.(contentScaler)
+	if !ok {
+		return 1
+	}
+	sx, _ := cs.GetContentScale()
+	fx, _ := gm.win.GetScale()
+	if sx <= 0 || fx <= 0 {
+		return 1
+	}
+	return math32.Max(1, sx/float32(fx))
+}
+
+// setScale sets the UI scale and, if it changed, redraws the images of the panels at the new scale and dispatches OnScale.
+func (gm *manager) setScale(scale float32) {
+
+	scale = math32.Clamp(scale, scaleMin, scaleMax)
+	if scale == gm.scale {
+		return
+	}
+	gm.scale = scale
+	if gm.scene != nil {
+		sx, sy := gm.win.GetScale()
+		gm.dispatchAll(gm.scene, OnContentScale, &window.ScaleEvent{X: sx, Y: sy})
+	}
+	gm.Dispatch(OnScale, nil)
+}
+
+// onScaleShortcut changes the UI scale if the specified key or scroll event is a scale shortcut and returns whether it was.
+func (gm *manager) onScaleShortcut(evname string, ev interface{}) bool {
+
+	if !gm.scaleShortcuts {
+		return false
+	}
+	switch ev := ev.(type) {
+	case *window.ScrollEvent:
+		if ev.Mods&window.ModControl == 0 || ev.Yoffset == 0 {
+			return false
+		}
+		if ev.Yoffset > 0 {
+			gm.SetScale(gm.scale + scaleStep)
+		} else {
+			gm.SetScale(gm.scale - scaleStep)
+		}
+		return true
+	case *window.KeyEvent:
+		if ev.Mods&window.ModControl == 0 || (evname != window.OnKeyDown && evname != window.OnKeyRepeat) {
+			return false
+		}
+		switch ev.Key {
+		case window.KeyEqual, window.KeyKPAdd:
+			gm.SetScale(gm.scale + scaleStep)
+		case window.KeyMinus, window.KeyKPSubtract:
+			gm.SetScale(gm.scale - scaleStep)
+		case window.Key0, window.KeyKP0:
+			gm.ResetScale()
+		default:
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// panelEvent returns the specified window event with its cursor position converted to UI units.
+// The converted events are stored in the manager, so they are only valid during their dispatch.
+func (gm *manager) panelEvent(ev interface{}) interface{} {
+
+	if gm.scale == 1 {
+		return ev
+	}
+	switch ev := ev.(type) {
+	case *window.CursorEvent:
+		gm.scaledCursor = *ev
+		gm.scaledCursor.Xpos /= gm.scale
+		gm.scaledCursor.Ypos /= gm.scale
+		return &gm.scaledCursor
+	case *window.MouseEvent:
+		gm.scaledMouse = *ev
+		gm.scaledMouse.Xpos /= gm.scale
+		gm.scaledMouse.Ypos /= gm.scale
+		return &gm.scaledMouse
+	case *window.DropEvent:
+		gm.scaledDrop = *ev
+		gm.scaledDrop.Xpos /= gm.scale
+		gm.scaledDrop.Ypos /= gm.scale
+		return &gm.scaledDrop
+	}
+	return ev
+}
+
+// renderScale returns the scale from UI units to framebuffer pixels,
+// used to draw the fonts and images of the panels at the screen resolution.
+func renderScale() (float64, float64) {
+
+	sx, sy := window.Get().GetScale()
+	scale := float64(Manager().scale)
+	return sx * scale, sy * scale
+}
@@ -24,11 +24,11 @@ import (
 
 // ScrollBar is the scrollbar GUI element.
 type ScrollBar struct {
-	Panel                       // Embedded panel
-	styles     *ScrollBarStyles // styles of the scrollbar
-	vertical   bool             // type of scrollbar
-	button     scrollBarButton  // scrollbar button
-	cursorOver bool
+	Panel                     // Embedded panel
+	styles   *ScrollBarStyles // styles of the scrollbar
+	vertical bool             // type of scrollbar
+	button   scrollBarButton  // scrollbar button
+	tracker  StateTracker     // tracker of the pseudo-state
 }
 
 type scrollBarButton struct {
@@ -86,6 +86,7 @@ func (sb *ScrollBar) initialize(width, height float32, vertical bool) {
 	sb.vertical = vertical
 	sb.Panel.Initialize(sb, width, height)
 	sb.Panel.Subscribe(OnMouseDown, sb.onMouse)
+	sb.tracker.Init(&sb.Panel, sb.update)
 
 	// Initialize scrollbar button
 	sb.button.Panel.Initialize(&sb.button, 0, 0)
@@ -180,16 +181,22 @@ func (sb *ScrollBar) recalc() {
 func (sb *ScrollBar) update() {
 
 	// TODO disabling the scrollbar only affects style, needs to affect behavior
-	if !sb.Enabled() {
+	switch sb.tracker.State() {
+	case StyleDisabled:
 		sb.applyStyle(&sb.styles.Disabled)
-		return
-	}
-	// TODO cursorOver is never set to true for the scrollbar
-	if sb.cursorOver {
+	case StylePressed, StyleOver:
 		sb.applyStyle(&sb.styles.Over)
-		return
+	default:
+		sb.applyStyle(&sb.styles.Normal)
 	}
-	sb.applyStyle(&sb.styles.Normal)
+}
+
+// setStyle uses the specified style for all the states of the scrollbar, as the scrollbars
+// of a Scroller which are styled by the scroller, and applies it.
+func (sb *ScrollBar) setStyle(sbs *ScrollBarStyle) {
+
+	sb.styles = &ScrollBarStyles{Normal: *sbs, Over: *sbs, Disabled: *sbs}
+	sb.update()
 }
 
 // update updates border sizes and colors
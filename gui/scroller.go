@@ -322,7 +322,7 @@ func (s *Scroller) onResize(evname string, ev interface{}) {
 func (s *Scroller) setVerticalScrollbarVisible() {
 	if s.vscroll == nil {
 		s.vscroll = NewVScrollBar(s.style.VerticalScrollbar.Broadness, 0)
-		s.vscroll.applyStyle(&s.style.VerticalScrollbar.ScrollBarStyle)
+		s.vscroll.setStyle(&s.style.VerticalScrollbar.ScrollBarStyle)
 		s.vscroll.Subscribe(OnChange, s.onScrollBarEvent)
 		s.Add(s.vscroll)
 	}
@@ -333,7 +333,7 @@ func (s *Scroller) setVerticalScrollbarVisible() {
 func (s *Scroller) setHorizontalScrollbarVisible() {
 	if s.hscroll == nil {
 		s.hscroll = NewHScrollBar(0, s.style.HorizontalScrollbar.Broadness)
-		s.hscroll.applyStyle(&s.style.HorizontalScrollbar.ScrollBarStyle)
+		s.hscroll.setStyle(&s.style.HorizontalScrollbar.ScrollBarStyle)
 		s.hscroll.Subscribe(OnChange, s.onScrollBarEvent)
 		s.Add(s.hscroll)
 	}
@@ -560,8 +560,8 @@ func (s *Scroller) applyStyle(ss *ScrollerStyle) {
 
 	s.style = ss
 
-	s.vscroll.applyStyle(&s.style.VerticalScrollbar.ScrollBarStyle)
-	s.hscroll.applyStyle(&s.style.HorizontalScrollbar.ScrollBarStyle)
+	s.vscroll.setStyle(&s.style.VerticalScrollbar.ScrollBarStyle)
+	s.hscroll.setStyle(&s.style.HorizontalScrollbar.ScrollBarStyle)
 	s.corner.ApplyStyle(&s.style.CornerPanel)
 
 	s.Update()
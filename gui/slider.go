@@ -5,6 +5,9 @@
 package gui
 
 import (
+	"fmt"
+
+	"github.com/g3n/engine/math32"
 	"github.com/g3n/engine/window"
 )
 
@@ -30,10 +33,17 @@ type Slider struct {
 	horiz       bool          // orientation
 	styles      *SliderStyles // pointer to styles
 	pos         float32       // current slider position
+	posLow      float32       // current position of the low end in range mode
 	posLast     float32       // last position of the mouse cursor when dragging
-	pressed     bool          // mouse button is pressed and dragging
-	cursorOver  bool          // mouse is over slider
+	posDrag     float32       // unsnapped position of the dragged end
+	tracker     StateTracker  // tracker of the pseudo-state
 	scaleFactor float32       // scale factor (default = 1.0)
+	ticks       []*Panel      // tick mark panels
+	tickCount   int           // number of intervals between the tick marks
+	steps       int           // number of discrete steps (0 = continuous)
+	format      string        // format of the value shown in the label (empty = label text)
+	rangeMode   bool          // the slider selects a range between two ends
+	lowActive   bool          // the low end is moved by the mouse and keys in range mode
 }
 
 // SliderStyle contains the styling of a Slider
@@ -44,6 +54,7 @@ type SliderStyles struct {
 	Normal   SliderStyle
 	Over     SliderStyle
 	Focus    SliderStyle
+	Pressed  SliderStyle
 	Disabled SliderStyle
 }
 
@@ -81,7 +92,8 @@ func newSlider(horiz bool, width, height float32) *Slider {
 	s.Panel.Subscribe(OnKeyDown, s.onKey)
 	s.Panel.Subscribe(OnKeyRepeat, s.onKey)
 	s.Panel.Subscribe(OnResize, s.onResize)
-	s.Panel.Subscribe(OnEnable, func(evname string, ev interface{}) { s.update() })
+	s.tracker.Init(&s.Panel, s.update)
+	s.tracker.SetKeepPressed(true)
 
 	// Initialize slider panel
 	s.slider.Initialize(&s.slider, 0, 0)
@@ -115,20 +127,124 @@ func (s *Slider) SetText(text string) *Slider {
 }
 
 // SetValue sets the value of the slider considering the current scale factor
-// and updates its visual appearance.
+// and updates its visual appearance. In range mode it sets the value of the high end.
 func (s *Slider) SetValue(value float32) *Slider {
 
 	pos := value / s.scaleFactor
-	s.setPos(pos)
+	s.setEnd(false, pos)
 	return s
 }
 
-// Value returns the current value of the slider considering the current scale factor
+// Value returns the current value of the slider considering the current scale factor.
+// In range mode it returns the value of the high end.
 func (s *Slider) Value() float32 {
 
 	return s.pos * s.scaleFactor
 }
 
+// SetLowValue sets the value of the low end of the slider in range mode
+// considering the current scale factor and updates its visual appearance.
+func (s *Slider) SetLowValue(value float32) *Slider {
+
+	pos := value / s.scaleFactor
+	s.setEnd(true, pos)
+	return s
+}
+
+// LowValue returns the value of the low end of the slider in range mode
+// considering the current scale factor or zero if not in range mode.
+func (s *Slider) LowValue() float32 {
+
+	return s.posLow * s.scaleFactor
+}
+
+// SetRangeMode sets whether the slider selects a range of values between a low end and
+// a high end, which are moved by dragging the nearest end with the mouse or with the keys
+// after clicking next to it. The low end starts at zero.
+func (s *Slider) SetRangeMode(enable bool) *Slider {
+
+	s.rangeMode = enable
+	s.lowActive = false
+	if s.posLow != 0 {
+		s.posLow = 0
+		s.recalc()
+		s.updateLabel()
+		s.Dispatch(OnChange, nil)
+	}
+	return s
+}
+
+// RangeMode returns whether the slider selects a range of values.
+func (s *Slider) RangeMode() bool {
+
+	return s.rangeMode
+}
+
+// SetSteps sets the number of discrete steps between the minimum and maximum values
+// the slider snaps to, or zero for a continuous slider (the default).
+// The arrow keys move the slider by one step.
+func (s *Slider) SetSteps(steps int) *Slider {
+
+	s.steps = steps
+	s.setEnd(true, s.posLow)
+	s.setEnd(false, s.pos)
+	return s
+}
+
+// Steps returns the number of discrete steps of the slider or zero if it is continuous.
+func (s *Slider) Steps() int {
+
+	return s.steps
+}
+
+// SetTicks sets the number of intervals between the tick marks drawn at
+// the bottom of a horizontal slider or at the right of a vertical slider.
+// Zero removes the tick marks (the default).
+func (s *Slider) SetTicks(count int) *Slider {
+
+	for _, t := range s.ticks {
+		s.Panel.Remove(t)
+	}
+	s.ticks = nil
+	s.tickCount = count
+	for i := 1; i < count; i++ {
+		t := NewPanel(0, 0)
+		s.ticks = append(s.ticks, t)
+		s.Panel.Add(t)
+	}
+	// Keeps the label above the tick marks
+	if s.label != nil {
+		s.Panel.Remove(s.label)
+		s.Panel.Add(s.label)
+	}
+	s.update()
+	s.recalc()
+	return s
+}
+
+// Ticks returns the number of intervals between the tick marks or zero if there are none.
+func (s *Slider) Ticks() int {
+
+	return s.tickCount
+}
+
+// SetFormat sets the format string used to show the value of the slider in its label,
+// as "%.1f dB", which is updated when the value changes. In range mode the format
+// receives the low and the high values, as "%.0f - %.0f". An empty format shows the
+// text set with SetText (the default).
+func (s *Slider) SetFormat(format string) *Slider {
+
+	s.format = format
+	s.updateLabel()
+	return s
+}
+
+// Format returns the format string used to show the value of the slider in its label.
+func (s *Slider) Format() string {
+
+	return s.format
+}
+
 // SetScaleFactor set the slider scale factor (default = 1.0)
 func (s *Slider) SetScaleFactor(factor float32) *Slider {
 
@@ -142,24 +258,69 @@ func (s *Slider) ScaleFactor() float32 {
 	return s.scaleFactor
 }
 
-// setPos sets the slider position from 0.0 to 1.0
-// and updates its visual appearance.
+// setPos sets the position from 0.0 to 1.0 of the end of the slider moved by the mouse and keys.
 func (s *Slider) setPos(pos float32) {
 
-	const eps = 0.01
-	if pos < 0 {
-		pos = 0
-	} else if pos > 1.0 {
-		pos = 1
+	s.setEnd(s.rangeMode && s.lowActive, pos)
+}
+
+// setEnd sets the position from 0.0 to 1.0 of the low or the high end of the slider,
+// snapped to the steps and limited by the other end in range mode, and updates
+// its visual appearance.
+func (s *Slider) setEnd(low bool, pos float32) {
+
+	pos = math32.Clamp(pos, 0, 1)
+	if s.steps > 0 {
+		pos = math32.Round(pos*float32(s.steps)) / float32(s.steps)
 	}
-	if pos > (s.pos+eps) && pos < (s.pos+eps) {
-		return
+	if low {
+		if !s.rangeMode {
+			return
+		}
+		pos = math32.Min(pos, s.pos)
+		if pos == s.posLow {
+			return
+		}
+		s.posLow = pos
+	} else {
+		pos = math32.Max(pos, s.posLow)
+		if pos == s.pos {
+			return
+		}
+		s.pos = pos
 	}
-	s.pos = pos
 	s.recalc()
+	s.updateLabel()
 	s.Dispatch(OnChange, nil)
 }
 
+// step returns the position change of one arrow key press.
+func (s *Slider) step() float32 {
+
+	if s.steps > 0 {
+		return 1 / float32(s.steps)
+	}
+	return 0.01
+}
+
+// updateLabel updates the label with the values of the slider if it has a format.
+func (s *Slider) updateLabel() {
+
+	if s.format == "" {
+		return
+	}
+	var text string
+	if s.rangeMode {
+		text = fmt.Sprintf(s.format, s.LowValue(), s.Value())
+	} else {
+		text = fmt.Sprintf(s.format, s.Value())
+	}
+	if s.label != nil && s.label.Text() == text {
+		return
+	}
+	s.SetText(text)
+}
+
 // onMouse process subscribed mouse events over the outer panel
 func (s *Slider) onMouse(evname string, ev interface{}) {
 
@@ -173,16 +334,29 @@ func (s *Slider) onMouse(evname string, ev interface{}) {
 	}
 	switch evname {
 	case OnMouseDown:
-		s.pressed = true
 		if s.horiz {
 			s.posLast = mev.Xpos
 		} else {
 			s.posLast = mev.Ypos
 		}
+		// In range mode the end nearest to the cursor is moved
+		if s.rangeMode {
+			cx, cy := s.ContentCoords(mev.Xpos, mev.Ypos)
+			var pos float32
+			if s.horiz {
+				pos = cx / s.ContentWidth()
+			} else {
+				pos = 1 - cy/s.ContentHeight()
+			}
+			s.lowActive = math32.Abs(pos-s.posLow) < math32.Abs(pos-s.pos) || (s.posLow == s.pos && pos < s.pos)
+		}
+		s.posDrag = s.pos
+		if s.rangeMode && s.lowActive {
+			s.posDrag = s.posLow
+		}
 		Manager().SetKeyFocus(s)
 		Manager().SetCursorFocus(s)
 	case OnMouseUp:
-		s.pressed = false
 		Manager().SetCursorFocus(nil)
 	default:
 		return
@@ -197,35 +371,30 @@ func (s *Slider) onCursor(evname string, ev interface{}) {
 	}
 
 	if evname == OnCursorEnter {
-		s.cursorOver = true
 		if s.horiz {
 			window.Get().SetCursor(window.HResizeCursor)
 		} else {
 			window.Get().SetCursor(window.VResizeCursor)
 		}
-		s.update()
 	} else if evname == OnCursorLeave {
-		s.cursorOver = false
 		window.Get().SetCursor(window.ArrowCursor)
-		s.update()
 	} else if evname == OnCursor {
-		if !s.pressed {
+		if !s.tracker.Pressed() {
 			return
 		}
+		// Accumulates the unsnapped position so slow drags reach the next step
 		cev := ev.(*window.CursorEvent)
-		var pos float32
 		if s.horiz {
 			delta := cev.Xpos - s.posLast
 			s.posLast = cev.Xpos
-			newpos := s.slider.Width() + delta
-			pos = newpos / s.Panel.ContentWidth()
+			s.posDrag += delta / s.Panel.ContentWidth()
 		} else {
 			delta := cev.Ypos - s.posLast
 			s.posLast = cev.Ypos
-			newpos := s.slider.Height() - delta
-			pos = newpos / s.Panel.ContentHeight()
+			s.posDrag -= delta / s.Panel.ContentHeight()
 		}
-		s.setPos(pos)
+		s.posDrag = math32.Clamp(s.posDrag, 0, 1)
+		s.setPos(s.posDrag)
 	}
 }
 
@@ -238,7 +407,10 @@ func (s *Slider) onScroll(evname string, ev interface{}) {
 
 	sev := ev.(*window.ScrollEvent)
 	v := s.pos
-	v += sev.Yoffset * 0.01
+	if s.rangeMode && s.lowActive {
+		v = s.posLow
+	}
+	v += sev.Yoffset * s.step()
 	s.setPos(v)
 }
 
@@ -250,14 +422,37 @@ func (s *Slider) onKey(evname string, ev interface{}) {
 	}
 
 	kev := ev.(*window.KeyEvent)
-	delta := float32(0.01)
+	pos := s.pos
+	if s.rangeMode && s.lowActive {
+		pos = s.posLow
+	}
+	delta := s.step()
+	// Page keys move by a tenth of the range or by a tick interval
+	page := float32(0.1)
+	if s.tickCount > 0 {
+		page = 1 / float32(s.tickCount)
+	}
+	switch kev.Key {
+	case window.KeyPageUp:
+		s.setPos(pos + page)
+		return
+	case window.KeyPageDown:
+		s.setPos(pos - page)
+		return
+	case window.KeyHome:
+		s.setPos(0)
+		return
+	case window.KeyEnd:
+		s.setPos(1)
+		return
+	}
 	// Horizontal slider
 	if s.horiz {
 		switch kev.Key {
 		case window.KeyLeft:
-			s.setPos(s.pos - delta)
+			s.setPos(pos - delta)
 		case window.KeyRight:
-			s.setPos(s.pos + delta)
+			s.setPos(pos + delta)
 		default:
 			return
 		}
@@ -265,9 +460,9 @@ func (s *Slider) onKey(evname string, ev interface{}) {
 	} else {
 		switch kev.Key {
 		case window.KeyDown:
-			s.setPos(s.pos - delta)
+			s.setPos(pos - delta)
 		case window.KeyUp:
-			s.setPos(s.pos + delta)
+			s.setPos(pos + delta)
 		default:
 			return
 		}
@@ -283,15 +478,18 @@ func (s *Slider) onResize(evname string, ev interface{}) {
 // update updates the slider visual state
 func (s *Slider) update() {
 
-	if !s.Enabled() {
+	switch s.tracker.State() {
+	case StyleDisabled:
 		s.applyStyle(&s.styles.Disabled)
-		return
-	}
-	if s.cursorOver || s.pressed {
+	case StylePressed:
+		s.applyStyle(&s.styles.Pressed)
+	case StyleOver:
 		s.applyStyle(&s.styles.Over)
-		return
+	case StyleFocus:
+		s.applyStyle(&s.styles.Focus)
+	default:
+		s.applyStyle(&s.styles.Normal)
 	}
-	s.applyStyle(&s.styles.Normal)
 }
 
 // applyStyle applies the specified slider style
@@ -299,6 +497,9 @@ func (s *Slider) applyStyle(ss *SliderStyle) {
 
 	s.Panel.ApplyStyle(&ss.PanelStyle)
 	s.slider.SetColor4(&ss.FgColor)
+	for _, t := range s.ticks {
+		t.SetColor4(&ss.BorderColor)
+	}
 }
 
 // recalc recalculates the dimensions and positions of the internal panels.
@@ -313,8 +514,14 @@ func (s *Slider) recalc() {
 			ly := (s.Panel.ContentHeight() - s.label.Height()) / 2
 			s.label.SetPosition(lx, ly)
 		}
-		width := s.Panel.ContentWidth() * s.pos
+		width := s.Panel.ContentWidth() * (s.pos - s.posLow)
+		s.slider.SetPositionX(s.Panel.ContentWidth() * s.posLow)
 		s.slider.SetSize(width, s.Panel.ContentHeight())
+		th := s.Panel.ContentHeight() / 4
+		for i, t := range s.ticks {
+			t.SetSize(1, th)
+			t.SetPosition(s.Panel.ContentWidth()*float32(i+1)/float32(s.tickCount), s.Panel.ContentHeight()-th)
+		}
 	} else {
 		if s.label != nil {
 			if s.Panel.ContentWidth() < s.label.Width() {
@@ -324,8 +531,13 @@ func (s *Slider) recalc() {
 			ly := (s.Panel.ContentHeight() - s.label.Height()) / 2
 			s.label.SetPosition(lx, ly)
 		}
-		height := s.Panel.ContentHeight() * s.pos
-		s.slider.SetPositionY(s.Panel.ContentHeight() - height)
+		height := s.Panel.ContentHeight() * (s.pos - s.posLow)
+		s.slider.SetPositionY(s.Panel.ContentHeight() * (1 - s.pos))
 		s.slider.SetSize(s.Panel.ContentWidth(), height)
+		tw := s.Panel.ContentWidth() / 4
+		for i, t := range s.ticks {
+			t.SetSize(tw, 1)
+			t.SetPosition(s.Panel.ContentWidth()-tw, s.Panel.ContentHeight()*(1-float32(i+1)/float32(s.tickCount)))
+		}
 	}
 }
@@ -0,0 +1,296 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"strconv"
+
+	"github.com/g3n/engine/gui/assets/icon"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+/***************************************
+
+ Spinner Panel
+ +--------------------------------+
+ |  Edit                     +--+ |
+ |  +--------------------+   |up| |
+ |  |                    |   +--+ |
+ |  +--------------------+   |dn| |
+ |                           +--+ |
+ +--------------------------------+
+
+****************************************/
+
+// Spinner is a numeric edit with up/down arrow buttons
+// which increment or decrement its value by a step.
+// It dispatches OnChange with the new float32 value
+// when its value changes.
+type Spinner struct {
+	Panel                    // Embedded panel
+	edit      *Edit          // numeric edit
+	up        *Label         // up arrow icon
+	down      *Label         // down arrow icon
+	styles    *SpinnerStyles // pointer to current spinner styles
+	value     float32        // current value
+	min       float32        // minimum value
+	max       float32        // maximum value
+	step      float32        // increment/decrement step
+	decimals  int            // number of decimal places shown
+	upState   StateTracker   // tracker of the up arrow pseudo-state
+	downState StateTracker   // tracker of the down arrow pseudo-state
+}
+
+// SpinnerStyle contains the styling of the Spinner arrow buttons
+type SpinnerStyle BasicStyle
+
+// SpinnerStyles contains a SpinnerStyle for each valid GUI state
+type SpinnerStyles struct {
+	Normal   SpinnerStyle
+	Over     SpinnerStyle
+	Disabled SpinnerStyle
+}
+
+// NewSpinner creates and returns a pointer to a new spinner widget
+// with the specified edit width, value range and step.
+func NewSpinner(width int, min, max, step float32) *Spinner {
+
+	s := new(Spinner)
+	s.styles = &StyleDefault().Spinner
+	s.min = min
+	s.max = max
+	s.step = step
+	s.decimals = 2
+	s.value = min
+
+	// Initialize main panel
+	s.Panel.Initialize(s, 0, 0)
+	s.Panel.Subscribe(OnScroll, s.onScroll)
+	s.Panel.Subscribe(OnEnable, func(evname string, ev interface{}) {
+		s.edit.SetEnabled(s.Enabled())
+		s.update()
+	})
+
+	// Create numeric edit
+	s.edit = NewEdit(width, "")
+	s.edit.SetNumeric(true)
+	s.edit.Subscribe(OnChange, s.onEditChange)
+	s.edit.Subscribe(OnKeyDown, s.onKey)
+	s.edit.Subscribe(OnKeyRepeat, s.onKey)
+	s.edit.Subscribe(OnFocusLost, func(evname string, ev interface{}) { s.SetValue(s.parseText()) })
+	s.Panel.Add(s.edit)
+
+	// Create arrow icons
+	s.up = NewIcon(icon.ArrowDropUp)
+	s.up.Subscribe(OnMouseDown, func(evname string, ev interface{}) { s.onArrow(ev, s.step) })
+	s.upState.Init(&s.up.Panel, s.update)
+	s.Panel.Add(s.up)
+	s.down = NewIcon(icon.ArrowDropDown)
+	s.down.Subscribe(OnMouseDown, func(evname string, ev interface{}) { s.onArrow(ev, -s.step) })
+	s.downState.Init(&s.down.Panel, s.update)
+	s.Panel.Add(s.down)
+
+	s.updateText()
+	s.recalc()
+	s.update()
+	return s
+}
+
+// Value returns the current value of the spinner
+func (s *Spinner) Value() float32 {
+
+	return s.value
+}
+
+// SetValue sets the value of the spinner clamped to its range.
+// OnChange is dispatched if the value changed.
+func (s *Spinner) SetValue(value float32) *Spinner {
+
+	s.setValue(value)
+	s.updateText()
+	return s
+}
+
+// SetRange sets the minimum and maximum values of the spinner
+func (s *Spinner) SetRange(min, max float32) *Spinner {
+
+	s.min = min
+	s.max = max
+	s.SetValue(s.value)
+	return s
+}
+
+// Range returns the minimum and maximum values of the spinner
+func (s *Spinner) Range() (float32, float32) {
+
+	return s.min, s.max
+}
+
+// SetStep sets the value increment/decrement step
+func (s *Spinner) SetStep(step float32) *Spinner {
+
+	s.step = step
+	return s
+}
+
+// Step returns the value increment/decrement step
+func (s *Spinner) Step() float32 {
+
+	return s.step
+}
+
+// SetDecimals sets the number of decimal places used to show the value (default = 2)
+func (s *Spinner) SetDecimals(decimals int) *Spinner {
+
+	s.decimals = decimals
+	s.updateText()
+	return s
+}
+
+// Edit returns the internal numeric edit of the spinner
+func (s *Spinner) Edit() *Edit {
+
+	return s.edit
+}
+
+// SetStyles set the spinner styles overriding the default style
+func (s *Spinner) SetStyles(ss *SpinnerStyles) *Spinner {
+
+	s.styles = ss
+	s.update()
+	return s
+}
+
+// Increment increments the spinner value by its step
+func (s *Spinner) Increment() {
+
+	s.SetValue(s.value + s.step)
+}
+
+// Decrement decrements the spinner value by its step
+func (s *Spinner) Decrement() {
+
+	s.SetValue(s.value - s.step)
+}
+
+// setValue clamps and sets the spinner value dispatching OnChange if it changed
+func (s *Spinner) setValue(value float32) {
+
+	value = math32.Clamp(value, s.min, s.max)
+	if value == s.value {
+		return
+	}
+	s.value = value
+	s.Dispatch(OnChange, s.value)
+}
+
+// updateText sets the edit text from the current value
+func (s *Spinner) updateText() {
+
+	s.edit.SetText(strconv.FormatFloat(float64(s.value), 'f', s.decimals, 32))
+}
+
+// onEditChange process OnChange events from the internal edit
+func (s *Spinner) onEditChange(evname string, ev interface{}) {
+
+	// Values out of range are only clamped when the edit loses the focus
+	// or Enter is pressed, so the user can type intermediate values.
+	v := s.parseText()
+	if v < s.min || v > s.max {
+		return
+	}
+	s.setValue(v)
+}
+
+// onArrow process mouse down events over the arrows
+func (s *Spinner) onArrow(ev interface{}, delta float32) {
+
+	if !s.Enabled() {
+		return
+	}
+	mev := ev.(*window.MouseEvent)
+	if mev.Button != window.MouseButtonLeft {
+		return
+	}
+	s.SetValue(s.value + delta)
+}
+
+// onScroll process subscribed scroll events
+func (s *Spinner) onScroll(evname string, ev interface{}) {
+
+	if !s.Enabled() {
+		return
+	}
+	sev := ev.(*window.ScrollEvent)
+	if sev.Yoffset > 0 {
+		s.Increment()
+	} else if sev.Yoffset < 0 {
+		s.Decrement()
+	}
+}
+
+// onKey process subscribed key events from the internal edit
+func (s *Spinner) onKey(evname string, ev interface{}) {
+
+	kev := ev.(*window.KeyEvent)
+	switch kev.Key {
+	case window.KeyUp:
+		s.Increment()
+	case window.KeyDown:
+		s.Decrement()
+	case window.KeyEnter, window.KeyKPEnter:
+		s.SetValue(s.parseText())
+	}
+}
+
+// parseText returns the value typed in the edit or the current value if it is not valid
+func (s *Spinner) parseText() float32 {
+
+	v, err := strconv.ParseFloat(s.edit.Text(), 32)
+	if err != nil {
+		return s.value
+	}
+	return float32(v)
+}
+
+// recalc recalculates the dimensions and positions of the internal panels
+func (s *Spinner) recalc() {
+
+	height := s.edit.Height()
+	s.up.SetFontSize(float64(height) / 2)
+	s.down.SetFontSize(float64(height) / 2)
+	s.edit.SetPosition(0, 0)
+	s.up.SetPosition(s.edit.Width(), 0)
+	s.down.SetPosition(s.edit.Width(), height/2)
+	s.Panel.SetContentSize(s.edit.Width()+s.up.Width(), height)
+}
+
+// update updates the visual state
+func (s *Spinner) update() {
+
+	s.applyState(s.up, &s.upState)
+	s.applyState(s.down, &s.downState)
+}
+
+// applyState applies the style of the current state of the specified arrow icon
+func (s *Spinner) applyState(arrow *Label, st *StateTracker) {
+
+	switch st.State() {
+	case StyleDisabled:
+		s.applyStyle(arrow, &s.styles.Disabled)
+	case StylePressed, StyleOver:
+		s.applyStyle(arrow, &s.styles.Over)
+	default:
+		s.applyStyle(arrow, &s.styles.Normal)
+	}
+}
+
+// applyStyle applies the specified style to an arrow icon
+func (s *Spinner) applyStyle(arrow *Label, ss *SpinnerStyle) {
+
+	arrow.ApplyStyle(&ss.PanelStyle)
+	arrow.SetColor4(&ss.FgColor)
+}
@@ -9,18 +9,19 @@ import (
 	"github.com/g3n/engine/window"
 )
 
-// Splitter is a GUI element that splits two panels and can be adjusted
+// Splitter is a GUI element that splits two panels and can be adjusted.
+// It dispatches OnChange when the split position is changed by the user,
+// so the position can be saved and restored with SetSplit.
 type Splitter struct {
-	Panel                     // Embedded panel
-	P0        Panel           // Left/Top panel
-	P1        Panel           // Right/Bottom panel
-	styles    *SplitterStyles // pointer to current styles
-	spacer    Panel           // spacer panel
-	horiz     bool            // horizontal or vertical splitter
-	pos       float32         // relative position of the center of the spacer panel (0 to 1)
-	posLast   float32         // last position in pixels of the mouse cursor when dragging
-	pressed   bool            // mouse button is pressed and dragging
-	mouseOver bool            // mouse is over the spacer panel
+	Panel                   // Embedded panel
+	P0      Panel           // Left/Top panel
+	P1      Panel           // Right/Bottom panel
+	styles  *SplitterStyles // pointer to current styles
+	spacer  Panel           // spacer panel
+	horiz   bool            // horizontal or vertical splitter
+	pos     float32         // relative position of the center of the spacer panel (0 to 1)
+	posLast float32         // last position in pixels of the mouse cursor when dragging
+	tracker StateTracker    // tracker of the spacer panel pseudo-state
 }
 
 // SplitterStyle contains the styling of a Splitter
@@ -86,6 +87,8 @@ func newSplitter(horiz bool, width, height float32) *Splitter {
 	s.spacer.Subscribe(OnCursor, s.onCursor)
 	s.spacer.Subscribe(OnCursorEnter, s.onCursor)
 	s.spacer.Subscribe(OnCursorLeave, s.onCursor)
+	s.tracker.Init(&s.spacer, s.update)
+	s.tracker.SetKeepPressed(true)
 	s.update()
 	s.recalc()
 	return s
@@ -106,6 +109,12 @@ func (s *Splitter) Split() float32 {
 	return s.pos
 }
 
+// Horizontal returns whether the panels are side by side (horizontal splitter) or one above the other.
+func (s *Splitter) Horizontal() bool {
+
+	return s.horiz
+}
+
 // onResize receives subscribed resize events for the whole splitter panel
 func (s *Splitter) onResize(evname string, ev interface{}) {
 
@@ -121,7 +130,6 @@ func (s *Splitter) onMouse(evname string, ev interface{}) {
 	}
 	switch evname {
 	case OnMouseDown:
-		s.pressed = true
 		if s.horiz {
 			s.posLast = mev.Xpos
 		} else {
@@ -129,7 +137,6 @@ func (s *Splitter) onMouse(evname string, ev interface{}) {
 		}
 		Manager().SetCursorFocus(&s.spacer)
 	case OnMouseUp:
-		s.pressed = false
 		window.Get().SetCursor(window.ArrowCursor)
 		Manager().SetCursorFocus(nil)
 	}
@@ -144,14 +151,10 @@ func (s *Splitter) onCursor(evname string, ev interface{}) {
 		} else {
 			window.Get().SetCursor(window.VResizeCursor)
 		}
-		s.mouseOver = true
-		s.update()
 	} else if evname == OnCursorLeave {
 		window.Get().SetCursor(window.ArrowCursor)
-		s.mouseOver = false
-		s.update()
 	} else if evname == OnCursor {
-		if !s.pressed {
+		if !s.tracker.Pressed() {
 			return
 		}
 		cev := ev.(*window.CursorEvent)
@@ -168,6 +171,7 @@ func (s *Splitter) onCursor(evname string, ev interface{}) {
 		}
 		s.setSplit(pos)
 		s.recalc()
+		s.Dispatch(OnChange, nil)
 	}
 }
 
@@ -186,15 +190,14 @@ func (s *Splitter) setSplit(pos float32) {
 // update updates the splitter visual state
 func (s *Splitter) update() {
 
-	if s.pressed {
+	switch s.tracker.State() {
+	case StylePressed:
 		s.applyStyle(&s.styles.Drag)
-		return
-	}
-	if s.mouseOver {
+	case StyleOver:
 		s.applyStyle(&s.styles.Over)
-		return
+	default:
+		s.applyStyle(&s.styles.Normal)
 	}
-	s.applyStyle(&s.styles.Normal)
 }
 
 // applyStyle applies the specified splitter style
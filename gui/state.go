@@ -0,0 +1,258 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"time"
+
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// StateTracker keeps the pseudo-state of a widget panel (cursor over, pressed,
+// key focused and disabled) updated from the panel events and calls the
+// specified function when the state changes, so the widget only needs to
+// apply the style of the current state returned by State().
+type StateTracker struct {
+	panel       *Panel // Tracked panel
+	over        bool   // Cursor is over the panel
+	pressed     bool   // Left mouse button was pressed over the panel and not released yet
+	focused     bool   // Panel has the key focus
+	keepPressed bool   // Keep the pressed state when the cursor leaves the panel
+	changed     func() // Function called when the state changes
+}
+
+// Time between steps of the style transitions
+const styleTransitionStep = 16 * time.Millisecond
+
+// styleTransition contains the state of the animation of the panel colors between two styles.
+type styleTransition struct {
+	duration   time.Duration // Duration of the transition (0 to disable)
+	applied    bool          // A style was applied to the panel before
+	timerID    int           // Id of the animation timer (0 if not animating)
+	start      time.Time     // Start time of the current transition
+	fromBorder math32.Color4 // Border color at the start of the transition
+	fromBg     math32.Color4 // Background color at the start of the transition
+	toBorder   math32.Color4 // Target border color
+	toBg       math32.Color4 // Target background color
+}
+
+// Init initializes the state tracker of the specified panel which calls the
+// specified function when the state changes. The style transition duration of
+// the panel is set from the default style.
+// Widgets which handle the same mouse events and need the state before the
+// change must subscribe to them before calling Init.
+func (st *StateTracker) Init(p *Panel, changed func()) {
+
+	st.InitContainer(p, changed)
+	p.Subscribe(OnMouseDown, st.onMouse)
+	p.Subscribe(OnMouseUp, st.onMouse)
+	p.Subscribe(OnMouseUpOut, st.onMouse)
+}
+
+// InitContainer initializes the state tracker of the specified container panel as Init,
+// except it doesn't subscribe to the mouse button events, which would stop them from
+// propagating from the children of the panel to its ancestors. The pressed state
+// is then only changed by SetPressed.
+func (st *StateTracker) InitContainer(p *Panel, changed func()) {
+
+	st.panel = p
+	st.changed = changed
+	p.Subscribe(OnCursorEnter, st.onCursor)
+	p.Subscribe(OnCursorLeave, st.onCursor)
+	p.Subscribe(OnFocus, st.onFocus)
+	p.Subscribe(OnFocusLost, st.onFocus)
+	p.Subscribe(OnEnable, func(evname string, ev interface{}) { st.changed() })
+	p.SetStyleTransition(StyleDefault().Transition)
+}
+
+// Over returns if the cursor is over the panel.
+func (st *StateTracker) Over() bool {
+
+	return st.over
+}
+
+// Pressed returns if the panel is pressed.
+func (st *StateTracker) Pressed() bool {
+
+	return st.pressed
+}
+
+// SetPressed sets the pressed state of the panel, for example when
+// the widget is pressed with the keyboard.
+func (st *StateTracker) SetPressed(state bool) {
+
+	if state == st.pressed {
+		return
+	}
+	st.pressed = state
+	st.changed()
+}
+
+// Focused returns if the panel has the key focus.
+func (st *StateTracker) Focused() bool {
+
+	return st.focused
+}
+
+// SetKeepPressed sets whether the panel keeps the pressed state when the cursor
+// leaves it while the mouse button is pressed, for example while dragging.
+// By default the state of a pressed panel changes to normal when the cursor
+// leaves it, indicating the release will be ignored.
+func (st *StateTracker) SetKeepPressed(state bool) {
+
+	st.keepPressed = state
+}
+
+// State returns the current state of the panel by priority:
+// StyleDisabled, StylePressed, StyleOver, StyleFocus or StyleNormal.
+func (st *StateTracker) State() int {
+
+	if !st.panel.EffectiveEnabled() {
+		return StyleDisabled
+	}
+	if st.pressed && (st.over || st.keepPressed) {
+		return StylePressed
+	}
+	if st.over {
+		return StyleOver
+	}
+	if st.focused {
+		return StyleFocus
+	}
+	return StyleNormal
+}
+
+// onCursor processes subscribed cursor events
+func (st *StateTracker) onCursor(evname string, ev interface{}) {
+
+	st.over = evname == OnCursorEnter
+	st.changed()
+}
+
+// onMouse processes subscribed mouse events
+func (st *StateTracker) onMouse(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	if mev.Button != window.MouseButtonLeft {
+		return
+	}
+	if evname == OnMouseDown {
+		if !st.panel.EffectiveEnabled() {
+			return
+		}
+		st.SetPressed(true)
+		return
+	}
+	st.SetPressed(false)
+}
+
+// onFocus processes subscribed focus events
+func (st *StateTracker) onFocus(evname string, ev interface{}) {
+
+	st.focused = evname == OnFocus
+	st.changed()
+}
+
+// SetStyleTransition sets the duration of the animation of the panel
+// border and background colors when a new style is applied.
+// Zero applies new styles immediately, ending the current transition if any.
+func (p *Panel) SetStyleTransition(duration time.Duration) {
+
+	if duration <= 0 && p.transition == nil {
+		return
+	}
+	if p.transition == nil {
+		p.transition = new(styleTransition)
+	}
+	p.transition.duration = duration
+	if duration <= 0 && p.transition.timerID != 0 {
+		p.stopStyleTransition()
+		p.setStyleColors(&p.transition.toBorder, &p.transition.toBg)
+	}
+}
+
+// StyleTransition returns the duration of the animation of the panel colors when a new style is applied.
+func (p *Panel) StyleTransition() time.Duration {
+
+	if p.transition == nil {
+		return 0
+	}
+	return p.transition.duration
+}
+
+// applyStyleColors sets the colors of the specified style starting a transition if enabled.
+func (p *Panel) applyStyleColors(ps *PanelStyle) {
+
+	tr := p.transition
+	if tr == nil || tr.duration <= 0 || !tr.applied {
+		if tr != nil {
+			tr.applied = true
+			tr.toBorder = ps.BorderColor
+			tr.toBg = ps.BgColor
+		}
+		p.udata.bordersColor = ps.BorderColor
+		p.udata.paddingsColor = ps.BgColor
+		p.udata.contentColor = ps.BgColor
+		return
+	}
+	if tr.toBorder == ps.BorderColor && tr.toBg == ps.BgColor {
+		return
+	}
+	tr.fromBorder = p.udata.bordersColor
+	tr.fromBg = p.udata.contentColor
+	tr.toBorder = ps.BorderColor
+	tr.toBg = ps.BgColor
+	tr.start = time.Now()
+	if tr.timerID == 0 {
+		tr.timerID = Manager().SetInterval(styleTransitionStep, nil, func(arg interface{}) { p.stepStyleTransition() })
+	}
+}
+
+// stepStyleTransition updates the panel colors of the current style transition.
+func (p *Panel) stepStyleTransition() {
+
+	tr := p.transition
+	t := float32(time.Since(tr.start)) / float32(tr.duration)
+	if t >= 1 {
+		t = 1
+		p.stopStyleTransition()
+	}
+	border := lerpColor4(&tr.fromBorder, &tr.toBorder, t)
+	bg := lerpColor4(&tr.fromBg, &tr.toBg, t)
+	p.setStyleColors(&border, &bg)
+}
+
+// stopStyleTransition cancels the animation timer of the current style transition if any,
+// leaving the panel colors as they are.
+func (p *Panel) stopStyleTransition() {
+
+	tr := p.transition
+	if tr == nil || tr.timerID == 0 {
+		return
+	}
+	Manager().ClearTimeout(tr.timerID)
+	tr.timerID = 0
+}
+
+// setStyleColors sets the border and background colors of the panel.
+func (p *Panel) setStyleColors(border, bg *math32.Color4) {
+
+	p.udata.bordersColor = *border
+	p.udata.contentColor = *bg
+	p.udata.paddingsColor = *bg
+	p.SetChanged(true)
+}
+
+// lerpColor4 returns the linear interpolation between the specified colors.
+func lerpColor4(from, to *math32.Color4, t float32) math32.Color4 {
+
+	return math32.Color4{
+		R: from.R + (to.R-from.R)*t,
+		G: from.G + (to.G-from.G)*t,
+		B: from.B + (to.B-from.B)*t,
+		A: from.A + (to.A-from.A)*t,
+	}
+}
@@ -5,34 +5,46 @@
 package gui
 
 import (
+	"time"
+
 	"github.com/g3n/engine/math32"
 	"github.com/g3n/engine/text"
 )
 
 // Style contains the styles for all GUI elements
 type Style struct {
-	Color         ColorStyle
-	Font          *text.Font
-	FontIcon      *text.Font
-	Label         LabelStyle
-	Button        ButtonStyles
-	CheckRadio    CheckRadioStyles
-	Edit          EditStyles
-	ScrollBar     ScrollBarStyles
-	Slider        SliderStyles
-	Splitter      SplitterStyles
-	Window        WindowStyles
-	ItemScroller  ItemScrollerStyles
-	Scroller      ScrollerStyle
-	List          ListStyles
-	DropDown      DropDownStyles
-	Folder        FolderStyles
-	Tree          TreeStyles
-	ControlFolder ControlFolderStyles
-	Menu          MenuStyles
-	Table         TableStyles
-	ImageButton   ImageButtonStyles
-	TabBar        TabBarStyles
+	Color          ColorStyle
+	Font           *text.Font
+	FontBold       *text.Font
+	FontIcon       *text.Font
+	Label          LabelStyle
+	Button         ButtonStyles
+	CheckRadio     CheckRadioStyles
+	Edit           EditStyles
+	Spinner        SpinnerStyles
+	Gauge          GaugeStyle
+	HeatMap        HeatMapStyle
+	CurveEditor    CurveEditorStyle
+	Timeline       TimelineStyle
+	GradientEditor GradientEditorStyle
+	ScrollBar      ScrollBarStyles
+	Slider         SliderStyles
+	Splitter       SplitterStyles
+	Window         WindowStyles
+	ItemScroller   ItemScrollerStyles
+	Scroller       ScrollerStyle
+	List           ListStyles
+	DropDown       DropDownStyles
+	Folder         FolderStyles
+	Tree           TreeStyles
+	ControlFolder  ControlFolderStyles
+	Menu           MenuStyles
+	Table          TableStyles
+	ImageButton    ImageButtonStyles
+	TabBar         TabBarStyles
+	Toast          ToastStyle
+	Dock           DockStyle
+	Transition     time.Duration // Duration of the style transitions of widgets (0 to disable)
 }
 
 // ColorStyle defines the main colors used.
@@ -54,4 +66,5 @@ const (
 	StyleDisabled
 	StyleNormal
 	StyleDef
+	StylePressed
 )
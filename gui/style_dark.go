@@ -16,6 +16,7 @@ func NewDarkStyle() *Style {
 
 	// Fonts to use
 	const textFont = "fonts/FreeSans.ttf"
+	const boldFont = "fonts/FreeSansBold.ttf"
 	const iconFont = "fonts/MaterialIcons-Regular.ttf"
 	s := new(Style)
 
@@ -27,6 +28,14 @@ func NewDarkStyle() *Style {
 	}
 	s.Font = font
 
+	// Creates bold text font
+	fontBoldData := assets.MustAsset(boldFont)
+	fontBold, err := text.NewFontFromData(fontBoldData)
+	if err != nil {
+		panic(err)
+	}
+	s.FontBold = fontBold
+
 	// Creates icon font
 	fontIconData := assets.MustAsset(iconFont)
 	fontIcon, err := text.NewFontFromData(fontIconData)
@@ -78,7 +87,8 @@ func NewDarkStyle() *Style {
 	s.Button.Normal.FgColor = s.Color.Text
 	s.Button.Over = s.Button.Normal
 	s.Button.Over.BgColor = s.Color.BgOver
-	s.Button.Focus = s.Button.Over
+	s.Button.Focus = s.Button.Normal
+	s.Button.Focus.BorderColor = s.Color.Highlight
 	s.Button.Pressed = s.Button.Over
 	s.Button.Pressed.Border = RectBounds{2, 2, 2, 2}
 	s.Button.Pressed.Padding = RectBounds{2, 2, 0, 4}
@@ -94,7 +104,8 @@ func NewDarkStyle() *Style {
 	s.CheckRadio.Normal.FgColor = s.Color.Text
 	s.CheckRadio.Over = s.CheckRadio.Normal
 	s.CheckRadio.Over.BgColor = s.Color.BgOver
-	s.CheckRadio.Focus = s.CheckRadio.Over
+	s.CheckRadio.Focus = s.CheckRadio.Normal
+	s.CheckRadio.Pressed = s.CheckRadio.Over
 	s.CheckRadio.Disabled = s.CheckRadio.Normal
 	s.CheckRadio.Disabled.FgColor = s.Color.TextDis
 
@@ -115,6 +126,73 @@ func NewDarkStyle() *Style {
 	s.Edit.Disabled = s.Edit.Normal
 	s.Edit.Disabled.FgColor = s.Color.TextDis
 
+	// Spinner styles
+	s.Spinner = SpinnerStyles{}
+	s.Spinner.Normal = SpinnerStyle{}
+	s.Spinner.Normal.BgColor = transparent
+	s.Spinner.Normal.FgColor = s.Color.Text
+	s.Spinner.Over = s.Spinner.Normal
+	s.Spinner.Over.BgColor = s.Color.BgOver
+	s.Spinner.Disabled = s.Spinner.Normal
+	s.Spinner.Disabled.FgColor = s.Color.TextDis
+
+	// Gauge style
+	s.Gauge = GaugeStyle{}
+	s.Gauge.BgColor = s.Color.BgDark
+	s.Gauge.TrackColor = s.Color.BgOver
+	s.Gauge.TickColor = s.Color.Text
+	s.Gauge.NeedleColor = math32.Color4{0.9, 0.3, 0.2, 1}
+	s.Gauge.FgColor = s.Color.Text
+
+	// HeatMap style
+	s.HeatMap = HeatMapStyle{}
+	s.HeatMap.ReadoutBgColor = math32.Color4{0, 0, 0, 0.8}
+	s.HeatMap.ReadoutFgColor = s.Color.Text
+
+	// CurveEditor style
+	s.CurveEditor = CurveEditorStyle{}
+	s.CurveEditor.Border = oneBounds
+	s.CurveEditor.BorderColor = s.Color.BgNormal
+	s.CurveEditor.BgColor = s.Color.BgDark
+	s.CurveEditor.GridColor = math32.Color4{1, 1, 1, 0.08}
+	s.CurveEditor.AxisColor = math32.Color4{1, 1, 1, 0.3}
+	s.CurveEditor.CurveColor = math32.Color4{0.3, 0.7, 1, 1}
+	s.CurveEditor.KeyColor = s.Color.Text
+	s.CurveEditor.SelectColor = math32.Color4{1, 0.3, 0.2, 1}
+	s.CurveEditor.HandleColor = math32.Color4{0.9, 0.7, 0.2, 1}
+	s.CurveEditor.KeySize = 8
+	s.CurveEditor.HandleLength = 40
+
+	// Timeline style
+	s.Timeline = TimelineStyle{}
+	s.Timeline.Border = oneBounds
+	s.Timeline.BorderColor = s.Color.BgNormal
+	s.Timeline.BgColor = s.Color.BgDark
+	s.Timeline.AltColor = math32.Color4{1, 1, 1, 0.03}
+	s.Timeline.HeaderColor = s.Color.BgNormal
+	s.Timeline.RulerColor = s.Color.BgMed
+	s.Timeline.GridColor = math32.Color4{1, 1, 1, 0.08}
+	s.Timeline.FgColor = s.Color.Text
+	s.Timeline.KeyColor = math32.Color4{0.9, 0.7, 0.2, 1}
+	s.Timeline.SelectColor = math32.Color4{1, 0.3, 0.2, 1}
+	s.Timeline.RangeColor = math32.Color4{0.3, 0.6, 1, 0.2}
+	s.Timeline.PlayheadColor = math32.Color4{0.9, 0.2, 0.2, 1}
+	s.Timeline.HeaderWidth = 120
+	s.Timeline.RulerHeight = 20
+	s.Timeline.TrackHeight = 20
+	s.Timeline.KeySize = 10
+
+	// GradientEditor style
+	s.GradientEditor = GradientEditorStyle{}
+	s.GradientEditor.Border = oneBounds
+	s.GradientEditor.BorderColor = s.Color.BgNormal
+	s.GradientEditor.BgColor = s.Color.BgDark
+	s.GradientEditor.CheckerColor = math32.Color4{0.4, 0.4, 0.4, 1}
+	s.GradientEditor.CheckerLight = math32.Color4{0.6, 0.6, 0.6, 1}
+	s.GradientEditor.MarkerColor = s.Color.Text
+	s.GradientEditor.SelectColor = math32.Color4{1, 0.3, 0.2, 1}
+	s.GradientEditor.MarkerSize = 12
+
 	// ScrollBar styles
 	s.ScrollBar = ScrollBarStyles{}
 	s.ScrollBar.Normal = ScrollBarStyle{}
@@ -138,7 +216,9 @@ func NewDarkStyle() *Style {
 	s.Slider.Over = s.Slider.Normal
 	s.Slider.Over.BgColor = s.Color.BgNormal
 	s.Slider.Over.FgColor = s.Color.Highlight //math32.Color4{0, 0.5, 0, 1}
-	s.Slider.Focus = s.Slider.Over
+	s.Slider.Focus = s.Slider.Normal
+	s.Slider.Focus.BorderColor = s.Color.Highlight
+	s.Slider.Pressed = s.Slider.Over
 	s.Slider.Disabled = s.Slider.Normal
 
 	// Splitter styles
@@ -196,6 +276,7 @@ func NewDarkStyle() *Style {
 	//s.ItemScroller.Over.BgColor = bgColorOver
 	s.ItemScroller.Focus = s.ItemScroller.Over
 	s.ItemScroller.Disabled = s.ItemScroller.Normal
+	s.ItemScroller.Indicator = s.Color.Highlight
 
 	// ItemList styles
 	s.List = ListStyles{}
@@ -356,7 +437,8 @@ func NewDarkStyle() *Style {
 	s.ImageButton.Normal.FgColor = s.Color.Text
 	s.ImageButton.Over = s.ImageButton.Normal
 	s.ImageButton.Over.BgColor = s.Color.BgOver
-	s.ImageButton.Focus = s.ImageButton.Over
+	s.ImageButton.Focus = s.ImageButton.Normal
+	s.ImageButton.Focus.BorderColor = s.Color.Highlight
 	s.ImageButton.Pressed = s.ImageButton.Over
 	s.ImageButton.Pressed.Border = oneBounds
 	s.ImageButton.Disabled = s.ImageButton.Normal
@@ -398,5 +480,25 @@ func NewDarkStyle() *Style {
 	s.TabBar.Tab.Selected = s.TabBar.Tab.Normal
 	s.TabBar.Tab.Selected.BgColor = s.Color.BgOver
 
+	// Toast style
+	s.Toast = ToastStyle{}
+	s.Toast.Border = RectBounds{1, 1, 1, 4}
+	s.Toast.Padding = RectBounds{6, 8, 6, 8}
+	s.Toast.BgColor = s.Color.BgDark
+	s.Toast.FgColor = s.Color.Text
+	s.Toast.Colors[ToastInfo] = math32.Color4{0.2, 0.5, 0.9, 1}
+	s.Toast.Colors[ToastSuccess] = math32.Color4{0.2, 0.7, 0.3, 1}
+	s.Toast.Colors[ToastWarning] = math32.Color4{0.95, 0.65, 0.1, 1}
+	s.Toast.Colors[ToastError] = math32.Color4{0.85, 0.2, 0.2, 1}
+	s.Toast.MinWidth = 240
+	s.Toast.Spacing = 8
+
+	// Dock manager style
+	s.Dock = DockStyle{}
+	s.Dock.HintColor = s.Color.Select
+	s.Dock.HintColor.A = 0.3
+	s.Dock.FloatWidth = 300
+	s.Dock.FloatHeight = 200
+
 	return s
 }
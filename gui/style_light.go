@@ -16,6 +16,7 @@ func NewLightStyle() *Style {
 
 	// Fonts to use
 	const fontName = "fonts/FreeSans.ttf"
+	const boldName = "fonts/FreeSansBold.ttf"
 	const iconName = "fonts/MaterialIcons-Regular.ttf"
 	s := new(Style)
 
@@ -27,6 +28,14 @@ func NewLightStyle() *Style {
 	}
 	s.Font = font
 
+	// Creates bold text font
+	fontBoldData := assets.MustAsset(boldName)
+	fontBold, err := text.NewFontFromData(fontBoldData)
+	if err != nil {
+		panic(err)
+	}
+	s.FontBold = fontBold
+
 	// Creates icon font
 	fontIconData := assets.MustAsset(iconName)
 	fontIcon, err := text.NewFontFromData(fontIconData)
@@ -51,6 +60,7 @@ func NewLightStyle() *Style {
 	fgColor := math32.Color4{0, 0, 0, 1}
 	fgColorSel := math32.Color4{0, 0, 0, 1}
 	fgColorDis := math32.Color4{0.4, 0.4, 0.4, 1}
+	focusColor := math32.Color4Name("SteelBlue")
 
 	// Label style
 	s.Label = LabelStyle{}
@@ -72,7 +82,8 @@ func NewLightStyle() *Style {
 	s.Button.Normal.FgColor = fgColor
 	s.Button.Over = s.Button.Normal
 	s.Button.Over.BgColor = bgColorOver
-	s.Button.Focus = s.Button.Over
+	s.Button.Focus = s.Button.Normal
+	s.Button.Focus.BorderColor = focusColor
 	s.Button.Pressed = s.Button.Over
 	s.Button.Pressed.Border = RectBounds{2, 2, 2, 2}
 	s.Button.Pressed.Padding = RectBounds{2, 2, 0, 4}
@@ -88,7 +99,8 @@ func NewLightStyle() *Style {
 	s.CheckRadio.Normal.FgColor = fgColor
 	s.CheckRadio.Over = s.CheckRadio.Normal
 	s.CheckRadio.Over.BgColor = bgColor4Over
-	s.CheckRadio.Focus = s.CheckRadio.Over
+	s.CheckRadio.Focus = s.CheckRadio.Normal
+	s.CheckRadio.Pressed = s.CheckRadio.Over
 	s.CheckRadio.Disabled = s.CheckRadio.Normal
 	s.CheckRadio.Disabled.FgColor = fgColorDis
 
@@ -109,6 +121,73 @@ func NewLightStyle() *Style {
 	s.Edit.Disabled = s.Edit.Normal
 	s.Edit.Disabled.FgColor = fgColorDis
 
+	// Spinner styles
+	s.Spinner = SpinnerStyles{}
+	s.Spinner.Normal = SpinnerStyle{}
+	s.Spinner.Normal.BgColor = bgColor4
+	s.Spinner.Normal.FgColor = fgColor
+	s.Spinner.Over = s.Spinner.Normal
+	s.Spinner.Over.BgColor = bgColor4Over
+	s.Spinner.Disabled = s.Spinner.Normal
+	s.Spinner.Disabled.FgColor = fgColorDis
+
+	// Gauge style
+	s.Gauge = GaugeStyle{}
+	s.Gauge.BgColor = math32.Color4{0.95, 0.95, 0.95, 1}
+	s.Gauge.TrackColor = math32.Color4{0.75, 0.75, 0.75, 1}
+	s.Gauge.TickColor = fgColor
+	s.Gauge.NeedleColor = math32.Color4{0.8, 0.1, 0.1, 1}
+	s.Gauge.FgColor = fgColor
+
+	// HeatMap style
+	s.HeatMap = HeatMapStyle{}
+	s.HeatMap.ReadoutBgColor = math32.Color4{1, 1, 0.9, 0.9}
+	s.HeatMap.ReadoutFgColor = fgColor
+
+	// CurveEditor style
+	s.CurveEditor = CurveEditorStyle{}
+	s.CurveEditor.Border = oneBounds
+	s.CurveEditor.BorderColor = borderColor
+	s.CurveEditor.BgColor = math32.Color4{0.98, 0.98, 0.98, 1}
+	s.CurveEditor.GridColor = math32.Color4{0, 0, 0, 0.08}
+	s.CurveEditor.AxisColor = math32.Color4{0, 0, 0, 0.35}
+	s.CurveEditor.CurveColor = math32.Color4{0.1, 0.4, 0.8, 1}
+	s.CurveEditor.KeyColor = fgColor
+	s.CurveEditor.SelectColor = math32.Color4{1, 0.3, 0.2, 1}
+	s.CurveEditor.HandleColor = math32.Color4{0.85, 0.5, 0.1, 1}
+	s.CurveEditor.KeySize = 8
+	s.CurveEditor.HandleLength = 40
+
+	// Timeline style
+	s.Timeline = TimelineStyle{}
+	s.Timeline.Border = oneBounds
+	s.Timeline.BorderColor = borderColor
+	s.Timeline.BgColor = math32.Color4{0.98, 0.98, 0.98, 1}
+	s.Timeline.AltColor = math32.Color4{0, 0, 0, 0.03}
+	s.Timeline.HeaderColor = math32.Color4{0.9, 0.9, 0.9, 1}
+	s.Timeline.RulerColor = math32.Color4{0.94, 0.94, 0.94, 1}
+	s.Timeline.GridColor = math32.Color4{0, 0, 0, 0.08}
+	s.Timeline.FgColor = fgColor
+	s.Timeline.KeyColor = math32.Color4{0.85, 0.5, 0.1, 1}
+	s.Timeline.SelectColor = math32.Color4{1, 0.3, 0.2, 1}
+	s.Timeline.RangeColor = math32.Color4{0.1, 0.4, 0.8, 0.2}
+	s.Timeline.PlayheadColor = math32.Color4{0.9, 0.2, 0.2, 1}
+	s.Timeline.HeaderWidth = 120
+	s.Timeline.RulerHeight = 20
+	s.Timeline.TrackHeight = 20
+	s.Timeline.KeySize = 10
+
+	// GradientEditor style
+	s.GradientEditor = GradientEditorStyle{}
+	s.GradientEditor.Border = oneBounds
+	s.GradientEditor.BorderColor = borderColor
+	s.GradientEditor.BgColor = math32.Color4{0.98, 0.98, 0.98, 1}
+	s.GradientEditor.CheckerColor = math32.Color4{0.75, 0.75, 0.75, 1}
+	s.GradientEditor.CheckerLight = math32.Color4{1, 1, 1, 1}
+	s.GradientEditor.MarkerColor = math32.Color4{0.3, 0.3, 0.3, 1}
+	s.GradientEditor.SelectColor = math32.Color4{1, 0.3, 0.2, 1}
+	s.GradientEditor.MarkerSize = 12
+
 	// ScrollBar styles
 	s.ScrollBar = ScrollBarStyles{}
 	s.ScrollBar.Normal = ScrollBarStyle{}
@@ -135,7 +214,9 @@ func NewLightStyle() *Style {
 	s.Slider.Over = s.Slider.Normal
 	s.Slider.Over.BgColor = math32.Color4{1, 1, 1, 1}
 	s.Slider.Over.FgColor = math32.Color4{0, 1, 0, 1}
-	s.Slider.Focus = s.Slider.Over
+	s.Slider.Focus = s.Slider.Normal
+	s.Slider.Focus.BorderColor = focusColor
+	s.Slider.Pressed = s.Slider.Over
 	s.Slider.Disabled = s.Slider.Normal
 
 	// Splitter styles
@@ -193,6 +274,7 @@ func NewLightStyle() *Style {
 	s.ItemScroller.Over.BgColor = bgColorOver
 	s.ItemScroller.Focus = s.ItemScroller.Over
 	s.ItemScroller.Disabled = s.ItemScroller.Normal
+	s.ItemScroller.Indicator = math32.Color4{0.1, 0.4, 0.8, 1}
 
 	// List styles
 	s.List = ListStyles{}
@@ -204,6 +286,8 @@ func NewLightStyle() *Style {
 	s.List.Item.Normal.BorderColor = math32.Color4{0, 0, 0, 0}
 	s.List.Item.Normal.BgColor = bgColor4
 	s.List.Item.Normal.FgColor = fgColor
+	s.List.Item.Over = s.List.Item.Normal
+	s.List.Item.Over.BgColor = bgColor4Over
 	s.List.Item.Selected = s.List.Item.Normal
 	s.List.Item.Selected.BgColor = bgColor4Sel
 	s.List.Item.Selected.FgColor = fgColorSel
@@ -352,7 +436,8 @@ func NewLightStyle() *Style {
 	s.ImageButton.Normal.FgColor = fgColor
 	s.ImageButton.Over = s.ImageButton.Normal
 	s.ImageButton.Over.BgColor = bgColor4Over
-	s.ImageButton.Focus = s.ImageButton.Over
+	s.ImageButton.Focus = s.ImageButton.Normal
+	s.ImageButton.Focus.BorderColor = focusColor
 	s.ImageButton.Pressed = s.ImageButton.Over
 	s.ImageButton.Pressed.Border = twoBounds
 	s.ImageButton.Disabled = s.ImageButton.Normal
@@ -394,5 +479,24 @@ func NewLightStyle() *Style {
 	s.TabBar.Tab.Selected = s.TabBar.Tab.Normal
 	s.TabBar.Tab.Selected.BgColor = math32.Color4{0.85, 0.85, 0.85, 1}
 
+	// Toast style
+	s.Toast = ToastStyle{}
+	s.Toast.Border = RectBounds{1, 1, 1, 4}
+	s.Toast.Padding = RectBounds{6, 8, 6, 8}
+	s.Toast.BgColor = math32.Color4{0.98, 0.98, 0.98, 1}
+	s.Toast.FgColor = fgColor
+	s.Toast.Colors[ToastInfo] = math32.Color4{0.2, 0.5, 0.9, 1}
+	s.Toast.Colors[ToastSuccess] = math32.Color4{0.2, 0.7, 0.3, 1}
+	s.Toast.Colors[ToastWarning] = math32.Color4{0.95, 0.65, 0.1, 1}
+	s.Toast.Colors[ToastError] = math32.Color4{0.85, 0.2, 0.2, 1}
+	s.Toast.MinWidth = 240
+	s.Toast.Spacing = 8
+
+	// Dock manager style
+	s.Dock = DockStyle{}
+	s.Dock.HintColor = math32.Color4{0.2, 0.5, 0.9, 0.3}
+	s.Dock.FloatWidth = 300
+	s.Dock.FloatHeight = 200
+
 	return s
 }
@@ -10,6 +10,24 @@ import (
 	"github.com/g3n/engine/window"
 )
 
+const (
+	// OnTabClose is the event generated when the user clicks the close icon of a Tab,
+	// before the Tab is removed. The event parameter is a pointer to TabEvent and
+	// the removal can be vetoed by setting its Cancel field.
+	OnTabClose = "gui.OnTabClose"
+	// OnTabMove is the event generated when the user moves a Tab by dragging its header.
+	// The event parameter is a pointer to TabEvent.
+	OnTabMove = "gui.OnTabMove"
+)
+
+// TabEvent describes the TabBar events related to a Tab
+type TabEvent struct {
+	Tab    *Tab // Pointer to the Tab
+	Pos    int  // Current position of the Tab
+	From   int  // Previous position of the Tab for OnTabMove
+	Cancel bool // Set by an OnTabClose handler to prevent the Tab removal
+}
+
 // TabBar is a panel which can contain other panels arranged in horizontal Tabs.
 // Only one panel is visible at a time.
 // To show another panel the corresponding Tab must be selected.
@@ -21,7 +39,10 @@ type TabBar struct {
 	listButton *Label        // Icon for tab list button
 	list       *List         // List for not visible tabs
 	selected   int           // Index of the selected tab
-	cursorOver bool          // Cursor over TabBar panel flag
+	offset     int           // Index of the first visible tab when tabs are scrolled
+	count      int           // Number of tabs which can be fully shown
+	dragTab    *Tab          // Tab being dragged or nil
+	tracker    StateTracker  // Tracker of the TabBar pseudo-state
 }
 
 // TabBarStyle describes the style of the TabBar
@@ -75,6 +96,7 @@ func NewTabBar(width, height float32) *TabBar {
 		tb.list.SetVisible(false)
 	})
 	tb.list.Subscribe(OnChange, tb.onListChange)
+	tb.list.SetLayer(LayerPopup)
 	tb.Add(tb.list)
 
 	// Creates list icon button
@@ -84,10 +106,8 @@ func NewTabBar(width, height float32) *TabBar {
 	tb.Add(tb.listButton)
 
 	// Subscribe to panel events
-	tb.Subscribe(OnCursorEnter, tb.onCursor)
-	tb.Subscribe(OnCursorLeave, tb.onCursor)
-	tb.Subscribe(OnEnable, func(name string, ev interface{}) { tb.update() })
 	tb.Subscribe(OnResize, func(name string, ev interface{}) { tb.recalc() })
+	tb.tracker.InitContainer(&tb.Panel, tb.update)
 
 	tb.recalc()
 	tb.update()
@@ -120,6 +140,9 @@ func (tb *TabBar) InsertTab(text string, pos int) *Tab {
 	copy(tb.tabs[pos+1:], tb.tabs[pos:])
 	tb.tabs[pos] = tab
 	tb.Add(&tab.header)
+	if tb.selected >= pos {
+		tb.selected++
+	}
 
 	tb.update()
 	tb.recalc()
@@ -147,15 +170,23 @@ func (tb *TabBar) RemoveTab(pos int) error {
 	tb.tabs[len(tb.tabs)-1] = nil
 	tb.tabs = tb.tabs[:len(tb.tabs)-1]
 
+	if tab == tb.dragTab {
+		tb.dragTab = nil
+		Manager().SetCursorFocus(nil)
+	}
+
 	// If removed tab was selected, selects other tab.
 	if tb.selected == pos {
+		tb.selected = -1
 		// Try to select tab at right
 		if len(tb.tabs) > pos {
-			tb.tabs[pos].setSelected(true)
+			tb.SetSelected(pos)
 			// Otherwise select tab at left
 		} else if pos > 0 {
-			tb.tabs[pos-1].setSelected(true)
+			tb.SetSelected(pos - 1)
 		}
+	} else if tb.selected > pos {
+		tb.selected--
 	}
 
 	tb.update()
@@ -164,6 +195,7 @@ func (tb *TabBar) RemoveTab(pos int) error {
 }
 
 // MoveTab moves a Tab to another position in the Tabs list
+// shifting the Tabs between the source and destination positions.
 func (tb *TabBar) MoveTab(src, dest int) error {
 
 	// Check source position
@@ -178,9 +210,22 @@ func (tb *TabBar) MoveTab(src, dest int) error {
 		return nil
 	}
 
-	tabDest := tb.tabs[dest]
-	tb.tabs[dest] = tb.tabs[src]
-	tb.tabs[src] = tabDest
+	tab := tb.tabs[src]
+	if src < dest {
+		copy(tb.tabs[src:], tb.tabs[src+1:dest+1])
+	} else {
+		copy(tb.tabs[dest+1:], tb.tabs[dest:src])
+	}
+	tb.tabs[dest] = tab
+
+	// Keeps the selected tab
+	if tb.selected == src {
+		tb.selected = dest
+	} else if src < tb.selected && dest >= tb.selected {
+		tb.selected--
+	} else if src > tb.selected && dest <= tb.selected {
+		tb.selected++
+	}
 	tb.recalc()
 	return nil
 }
@@ -227,6 +272,7 @@ func (tb *TabBar) SetSelected(pos int) *Tab {
 		}
 	}
 	tb.selected = pos
+	tb.ScrollTo(pos)
 	return tb.tabs[pos]
 }
 
@@ -237,21 +283,82 @@ func (tb *TabBar) Selected() int {
 	return tb.selected
 }
 
-// onCursor process subscribed cursor events
-func (tb *TabBar) onCursor(evname string, ev interface{}) {
+// ScrollTo scrolls the Tab headers if necessary so the header of
+// the Tab at the specified position is fully shown.
+func (tb *TabBar) ScrollTo(pos int) {
 
-	switch evname {
-	case OnCursorEnter:
-		tb.cursorOver = true
-		tb.update()
-	case OnCursorLeave:
-		tb.cursorOver = false
-		tb.update()
-	default:
+	if pos < 0 || pos >= len(tb.tabs) {
+		return
+	}
+	offset := tb.offset
+	if pos < offset {
+		offset = pos
+	} else if tb.count > 0 && pos >= offset+tb.count {
+		offset = pos - tb.count + 1
+	}
+	tb.setOffset(offset)
+}
+
+// setOffset sets the position of the first visible Tab header
+// and recalculates the TabBar if it changed.
+func (tb *TabBar) setOffset(offset int) {
+
+	if offset > len(tb.tabs)-tb.count {
+		offset = len(tb.tabs) - tb.count
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset == tb.offset {
+		return
+	}
+	tb.offset = offset
+	tb.recalc()
+}
+
+// onScroll process subscribed scroll events over the Tab headers
+func (tb *TabBar) onScroll(evname string, ev interface{}) {
+
+	sev := ev.(*window.ScrollEvent)
+	if sev.Yoffset > 0 {
+		tb.setOffset(tb.offset - 1)
+	} else if sev.Yoffset < 0 {
+		tb.setOffset(tb.offset + 1)
+	}
+}
+
+// onDrag process the cursor events while a Tab header is being dragged
+// and moves the Tab to the position of the Tab header under the cursor.
+func (tb *TabBar) onDrag(tab *Tab, cev *window.CursorEvent) {
+
+	cx, _ := tb.ContentCoords(cev.Xpos, cev.Ypos)
+	src := tb.TabPosition(tab)
+	for i := tb.offset; i < tb.offset+tb.count && i < len(tb.tabs); i++ {
+		header := &tb.tabs[i].header
+		hx := header.Position().X
+		if cx < hx || cx >= hx+header.Width() {
+			continue
+		}
+		if i != src {
+			tb.MoveTab(src, i)
+			tb.Dispatch(OnTabMove, &TabEvent{Tab: tab, Pos: i, From: src})
+		}
 		return
 	}
 }
 
+// closeTab removes the specified Tab after dispatching the
+// OnTabClose event if the removal was not vetoed.
+func (tb *TabBar) closeTab(tab *Tab) {
+
+	tev := &TabEvent{Tab: tab, Pos: tb.TabPosition(tab)}
+	tb.Dispatch(OnTabClose, tev)
+	if tev.Cancel {
+		return
+	}
+	tb.RemoveTab(tb.TabPosition(tab))
+}
+
 // onListButtonMouse process subscribed MouseButton events over the list button
 func (tb *TabBar) onListButton(evname string, ev interface{}) {
 
@@ -303,6 +410,13 @@ func (tb *TabBar) recalc() {
 		}
 		count++
 	}
+	tb.count = count
+	if tb.offset > len(tb.tabs)-count {
+		tb.offset = len(tb.tabs) - count
+	}
+	if tb.offset < 0 {
+		tb.offset = 0
+	}
 
 	// If there are more Tabs that can be shown, shows list button
 	if count < len(tb.tabs) {
@@ -317,7 +431,6 @@ func (tb *TabBar) recalc() {
 		ly := height + 1
 		tb.list.SetPosition(lx, ly)
 		tb.list.SetSize(listWidth, 200)
-		tb.SetTopChild(tb.list)
 	} else {
 		tb.listButton.SetVisible(false)
 		tb.list.SetVisible(false)
@@ -334,7 +447,6 @@ func (tb *TabBar) recalc() {
 		tab := tb.tabs[i]
 		// Recalculate Tab header and sets its position
 		tab.recalc(tabWidth)
-		tab.header.SetPosition(headerx, 0)
 		// Sets size and position of the Tab content panel
 		if tab.content != nil {
 			cpan := tab.content.GetPanel()
@@ -343,10 +455,11 @@ func (tb *TabBar) recalc() {
 			cpan.SetHeight(tb.ContentHeight() - contenty)
 			cpan.SetPosition(0, contenty)
 		}
-		headerx += tab.header.Width()
 		// If Tab can be shown set its header visible
-		if i < count {
+		if i >= tb.offset && i < tb.offset+count {
+			tab.header.SetPosition(headerx, 0)
 			tab.header.SetVisible(true)
+			headerx += tab.header.Width()
 			// Otherwise insert tab text in List
 		} else {
 			tab.header.SetVisible(false)
@@ -369,33 +482,34 @@ func (tb *TabBar) recalc() {
 // update updates the TabBar visual state
 func (tb *TabBar) update() {
 
-	if !tb.Enabled() {
+	switch tb.tracker.State() {
+	case StyleDisabled:
 		tb.applyStyle(&tb.styles.Disabled)
-		return
-	}
-	if tb.cursorOver {
+	case StylePressed, StyleOver:
 		tb.applyStyle(&tb.styles.Over)
-		return
+	case StyleFocus:
+		tb.applyStyle(&tb.styles.Focus)
+	default:
+		tb.applyStyle(&tb.styles.Normal)
 	}
-	tb.applyStyle(&tb.styles.Normal)
 }
 
 //
 // Tab describes an individual tab of the TabBar
 //
 type Tab struct {
-	tb         *TabBar    // Pointer to parent *TabBar
-	styles     *TabStyles // Pointer to Tab current styles
-	header     Panel      // Tab header
-	label      *Label     // Tab user label
-	iconClose  *Label     // Tab close icon
-	icon       *Label     // Tab optional user icon
-	image      *Image     // Tab optional user image
-	bottom     Panel      // Panel to cover the bottom edge of the Tab
-	content    IPanel     // User content panel
-	cursorOver bool
-	selected   bool
-	pinned     bool
+	tb        *TabBar      // Pointer to parent *TabBar
+	styles    *TabStyles   // Pointer to Tab current styles
+	header    Panel        // Tab header
+	label     *Label       // Tab user label
+	iconClose *Label       // Tab close icon
+	icon      *Label       // Tab optional user icon
+	image     *Image       // Tab optional user image
+	bottom    Panel        // Panel to cover the bottom edge of the Tab
+	content   IPanel       // User content panel
+	tracker   StateTracker // Tracker of the Tab header pseudo-state
+	selected  bool
+	pinned    bool
 }
 
 // newTab creates and returns a pointer to a new Tab
@@ -417,10 +531,13 @@ func newTab(text string, tb *TabBar, styles *TabStyles) *Tab {
 	tab.header.Add(&tab.bottom)
 
 	// Subscribe to header panel events
-	tab.header.Subscribe(OnCursorEnter, tab.onCursor)
-	tab.header.Subscribe(OnCursorLeave, tab.onCursor)
+	tab.header.Subscribe(OnCursor, tab.onCursor)
 	tab.header.Subscribe(OnMouseDown, tab.onMouseHeader)
+	tab.header.Subscribe(OnMouseUp, tab.onMouseHeader)
+	tab.header.Subscribe(OnScroll, tb.onScroll)
 	tab.iconClose.Subscribe(OnMouseDown, tab.onMouseIcon)
+	tab.tracker.Init(&tab.header, tab.update)
+	tab.tracker.SetKeepPressed(true)
 
 	tab.update()
 	return tab
@@ -429,23 +546,28 @@ func newTab(text string, tb *TabBar, styles *TabStyles) *Tab {
 // onCursor process subscribed cursor events over the tab header
 func (tab *Tab) onCursor(evname string, ev interface{}) {
 
-	switch evname {
-	case OnCursorEnter:
-		tab.cursorOver = true
-		tab.update()
-	case OnCursorLeave:
-		tab.cursorOver = false
-		tab.update()
-	default:
-		return
+	if tab.tb.dragTab == tab {
+		tab.tb.onDrag(tab, ev.(*window.CursorEvent))
 	}
 }
 
 // onMouse process subscribed mouse events over the tab header
 func (tab *Tab) onMouseHeader(evname string, ev interface{}) {
 
-	if evname == OnMouseDown && ev.(*window.MouseEvent).Button == window.MouseButtonLeft {
+	if ev.(*window.MouseEvent).Button != window.MouseButtonLeft {
+		return
+	}
+	switch evname {
+	case OnMouseDown:
 		tab.tb.SetSelected(tab.tb.TabPosition(tab))
+		// Starts dragging the Tab header
+		tab.tb.dragTab = tab
+		Manager().SetCursorFocus(&tab.header)
+	case OnMouseUp:
+		if tab.tb.dragTab == tab {
+			tab.tb.dragTab = nil
+			Manager().SetCursorFocus(nil)
+		}
 	}
 }
 
@@ -454,7 +576,7 @@ func (tab *Tab) onMouseIcon(evname string, ev interface{}) {
 
 	switch evname {
 	case OnMouseDown:
-		tab.tb.RemoveTab(tab.tb.TabPosition(tab))
+		tab.tb.closeTab(tab)
 	default:
 		return
 	}
@@ -557,6 +679,7 @@ func (tab *Tab) SetContent(ipan IPanel) {
 	tab.content = ipan
 	if ipan != nil {
 		tab.tb.Add(tab.content)
+		ipan.GetPanel().SetVisible(tab.selected)
 	}
 	tab.tb.recalc()
 }
@@ -603,19 +726,19 @@ func (tab *Tab) applyStyle(s *TabStyle) {
 // update updates the Tab header visual style
 func (tab *Tab) update() {
 
-	if !tab.header.Enabled() {
+	state := tab.tracker.State()
+	switch {
+	case state == StyleDisabled:
 		tab.applyStyle(&tab.styles.Disabled)
-		return
-	}
-	if tab.selected {
+	case tab.selected:
 		tab.applyStyle(&tab.styles.Selected)
-		return
-	}
-	if tab.cursorOver {
+	case state == StylePressed || state == StyleOver:
 		tab.applyStyle(&tab.styles.Over)
-		return
+	case state == StyleFocus:
+		tab.applyStyle(&tab.styles.Focus)
+	default:
+		tab.applyStyle(&tab.styles.Normal)
 	}
-	tab.applyStyle(&tab.styles.Normal)
 }
 
 // setBottomPanel sets the position and size of the Tab bottom panel
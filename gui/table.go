@@ -77,6 +77,8 @@ type Table struct {
 	resizerX       float32      // initial resizer x coordinate
 	resizing       bool         // dragging the column resizer
 	selType        TableSelType // table selection type
+	sortCol        string       // id of the column used in the last sort (empty if not sorted)
+	sortAsc        bool         // last sort was in ascending order
 }
 
 // TableColumn describes a table column
@@ -94,6 +96,21 @@ type TableColumn struct {
 	Resize     bool            // Allow column to be resized by user
 }
 
+// TableLayout describes the layout of the table columns set by the user,
+// which can be saved by the application and restored with SetColLayout.
+type TableLayout struct {
+	Columns []TableColumnLayout // Layouts of the columns in exhibition order
+	SortCol string              // Id of the column used in the last sort (empty if not sorted)
+	SortAsc bool                // Last sort was in ascending order
+}
+
+// TableColumnLayout describes the layout of a table column
+type TableColumnLayout struct {
+	Id     string  // Column id
+	Width  float32 // Column width in pixels
+	Hidden bool    // Hidden flag
+}
+
 // TableCell describes a table cell.
 // It is used as a parameter for formatting function
 type TableCell struct {
@@ -647,6 +664,8 @@ func (t *Table) SortColumn(col string, asString bool, asc bool) {
 	if c == nil {
 		panic(tableErrInvCol)
 	}
+	t.sortCol = col
+	t.sortAsc = asc
 	if len(t.rows) < 2 {
 		return
 	}
@@ -660,6 +679,109 @@ func (t *Table) SortColumn(col string, asString bool, asc bool) {
 	t.recalc()
 }
 
+// AutoSizeColumn sets the width of the specified column to the width
+// needed to show its header and the contents of its visible cells.
+// The widths of the columns to the right may change as when the
+// column is resized by the user.
+func (t *Table) AutoSizeColumn(colid string) {
+
+	c := t.header.cmap[colid]
+	if c == nil {
+		panic(tableErrInvCol)
+	}
+	t.setColWidth(c, t.fitColWidth(c))
+}
+
+// AutoSizeAll sets the widths of all visible columns to the widths
+// needed to show their headers and the contents of their visible cells.
+func (t *Table) AutoSizeAll() {
+
+	for ci := 0; ci < len(t.header.cols); ci++ {
+		c := t.header.cols[ci]
+		if !c.Visible() {
+			continue
+		}
+		width := t.fitColWidth(c)
+		if width < c.minWidth {
+			width = c.minWidth
+		}
+		c.SetWidth(width)
+	}
+	t.recalc()
+}
+
+// ColLayout returns the current layout of the table columns: their order,
+// widths and visibility and the last sort, which can be saved by the
+// application and restored with SetColLayout.
+func (t *Table) ColLayout() TableLayout {
+
+	layout := TableLayout{SortCol: t.sortCol, SortAsc: t.sortAsc}
+	layout.Columns = make([]TableColumnLayout, 0, len(t.header.cols))
+	for ci := 0; ci < len(t.header.cols); ci++ {
+		c := t.header.cols[ci]
+		layout.Columns = append(layout.Columns, TableColumnLayout{Id: c.id, Width: c.Width(), Hidden: !c.Visible()})
+	}
+	return layout
+}
+
+// SetColLayout restores the layout of the table columns previously returned by ColLayout.
+// Columns in the layout which do not exist in the table are ignored and table columns
+// which are not in the layout are kept after the others in their current order.
+// If the layout has a sort column the current rows are sorted by it.
+func (t *Table) SetColLayout(layout TableLayout) {
+
+	// Sets the columns order, widths and visibility
+	cols := make([]*tableColHeader, 0, len(t.header.cols))
+	used := make(map[*tableColHeader]bool)
+	for _, cl := range layout.Columns {
+		c := t.header.cmap[cl.Id]
+		if c == nil || used[c] {
+			continue
+		}
+		width := cl.Width
+		if width < c.minWidth {
+			width = c.minWidth
+		}
+		c.SetWidth(width)
+		c.SetVisible(!cl.Hidden)
+		cols = append(cols, c)
+		used[c] = true
+	}
+	for ci := 0; ci < len(t.header.cols); ci++ {
+		c := t.header.cols[ci]
+		if !used[c] {
+			cols = append(cols, c)
+		}
+	}
+	t.header.cols = cols
+
+	// Sets the sort icons and sorts the rows
+	c := t.header.cmap[layout.SortCol]
+	for ci := 0; ci < len(t.header.cols); ci++ {
+		current := t.header.cols[ci]
+		if current.ricon == nil {
+			continue
+		}
+		if current != c {
+			current.sorted = tableSortedNone
+			current.ricon.SetText(string(tableSortedNoneIcon))
+		} else if layout.SortAsc {
+			current.sorted = tableSortedDesc
+			current.ricon.SetText(string(tableSortedDescIcon))
+		} else {
+			current.sorted = tableSortedAsc
+			current.ricon.SetText(string(tableSortedAscIcon))
+		}
+	}
+	if c == nil {
+		t.sortCol = ""
+		t.sortAsc = false
+		t.recalc()
+		return
+	}
+	t.SortColumn(c.id, c.sort != TableSortNumber, layout.SortAsc)
+}
+
 // setRow sets the value of all the cells of the specified row from
 // the specified map indexed by column id.
 func (t *Table) setRow(row int, values map[string]interface{}) {
@@ -1115,6 +1237,28 @@ func (t *Table) toggleRowSel(ri int) {
 	t.Dispatch(OnChange, nil)
 }
 
+// fitColWidth returns the width needed to show the header
+// and the contents of the visible cells of the specified column
+func (t *Table) fitColWidth(c *tableColHeader) float32 {
+
+	width := c.MinWidth() + c.label.Width()
+	if c.ricon != nil {
+		width += c.ricon.Width()
+	}
+	for ri := 0; ri < len(t.rows); ri++ {
+		trow := t.rows[ri]
+		if !trow.Visible() {
+			continue
+		}
+		cell := trow.cells[c.order]
+		cellWidth := cell.MinWidth() + cell.label.Width()
+		if cellWidth > width {
+			width = cellWidth
+		}
+	}
+	return width
+}
+
 // setColWidth sets the width of the specified column
 func (t *Table) setColWidth(c *tableColHeader, width float32) {
 
@@ -0,0 +1,618 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/g3n/engine/animation"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// Timeline events
+const (
+	// OnTimelineRange is dispatched by a Timeline when its range selection is changed by the user
+	OnTimelineRange = "gui.OnTimelineRange"
+	// OnTimelineKey is dispatched by a Timeline when a keyframe marker is clicked
+	OnTimelineKey = "gui.OnTimelineKey"
+)
+
+// timelineFrame is the interval at which the playhead follows the animation.
+const timelineFrame = 16 * time.Millisecond
+
+// Timeline is a panel which shows tracks of keyframe markers over a time ruler with a playhead.
+// Dragging over the ruler scrubs the playhead dispatching OnChange, dragging over the tracks
+// selects a range of time dispatching OnTimelineRange and clicking a keyframe marker selects it
+// dispatching OnTimelineKey. The mouse wheel zooms the view and dragging with the right or
+// middle button pans it. When an animation is set its channels and events are shown as tracks,
+// the playhead follows its playback and scrubbing sets its time.
+type Timeline struct {
+	Panel                          // Embedded panel
+	canvas    *Canvas              // Canvas which draws the ruler, the tracks and the markers
+	playhead  *Panel               // Playhead line
+	ticks     []*Label             // Labels of the ruler ticks
+	tracks    []*TimelineTrack     // Tracks
+	style     *TimelineStyle       // Pointer to current style
+	anim      *animation.Animation // Optional animation shown by the timeline
+	syncID    int                  // Id of the timer which syncs the playhead with the animation
+	time      float32              // Playhead time
+	tmin      float32              // Time at the left border of the tracks
+	tmax      float32              // Time at the right border of the tracks
+	rangeOn   bool                 // Whether a range is selected
+	range0    float32              // Start of the selected range
+	range1    float32              // End of the selected range
+	selTrack  *TimelineTrack       // Track of the selected keyframe marker
+	selKey    int                  // Index of the selected keyframe marker
+	drag      timelineDrag         // Current drag operation
+	posLastX  float32              // Last cursor X position when panning
+	cursorX   float32              // Last cursor X position over the timeline
+	rangeFrom float32              // Time where the range selection drag started
+}
+
+// TimelineTrack is a named row of keyframe markers of a Timeline.
+type TimelineTrack struct {
+	timeline *Timeline // Timeline containing the track
+	name     *Label    // Name label
+	keys     []float32 // Times of the keyframes
+}
+
+// TimelineStyle contains the styling of a Timeline.
+type TimelineStyle struct {
+	Border        RectBounds    // Border sizes
+	BorderColor   math32.Color4 // Border color
+	BgColor       math32.Color4 // Background color of the tracks
+	AltColor      math32.Color4 // Background color of every other track
+	HeaderColor   math32.Color4 // Background color of the track names column
+	RulerColor    math32.Color4 // Background color of the ruler
+	GridColor     math32.Color4 // Color of the ruler ticks and grid lines
+	FgColor       math32.Color4 // Color of the track names and tick labels
+	KeyColor      math32.Color4 // Color of the keyframe markers
+	SelectColor   math32.Color4 // Color of the selected keyframe marker
+	RangeColor    math32.Color4 // Color of the selected range
+	PlayheadColor math32.Color4 // Color of the playhead
+	HeaderWidth   float32       // Width of the track names column
+	RulerHeight   float32       // Height of the ruler
+	TrackHeight   float32       // Height of each track
+	KeySize       float32       // Size of the keyframe markers
+}
+
+// timelineDrag is the drag operation of a Timeline.
+type timelineDrag int
+
+const (
+	timelineDragNone = timelineDrag(iota)
+	timelineDragScrub
+	timelineDragRange
+	timelineDragPan
+)
+
+// NewTimeline creates and returns a pointer to a new timeline with the specified size.
+func NewTimeline(width, height float32) *Timeline {
+
+	tl := new(Timeline)
+	tl.Panel.Initialize(tl, width, height)
+	tl.style = &StyleDefault().Timeline
+	tl.selKey = -1
+	tl.tmin, tl.tmax = 0, 1
+
+	tl.canvas = NewCanvas(tl.ContentWidth(), tl.ContentHeight())
+	tl.Add(tl.canvas)
+	tl.playhead = NewPanel(2, 0)
+	tl.Add(tl.playhead)
+
+	tl.Subscribe(OnResize, func(evname string, ev interface{}) { tl.recalc() })
+	tl.Subscribe(OnMouseDown, tl.onMouse)
+	tl.Subscribe(OnMouseUp, tl.onMouse)
+	tl.Subscribe(OnCursor, tl.onCursor)
+	tl.Subscribe(OnScroll, tl.onScroll)
+
+	tl.applyStyle()
+	return tl
+}
+
+// AddTrack adds a track with the specified name and keyframe times and returns a pointer to it.
+func (tl *Timeline) AddTrack(name string, keys []float32) *TimelineTrack {
+
+	track := &TimelineTrack{timeline: tl, keys: keys}
+	track.name = NewLabel(name)
+	track.name.SetColor4(&tl.style.FgColor)
+	tl.Add(track.name)
+	tl.tracks = append(tl.tracks, track)
+	tl.recalc()
+	return track
+}
+
+// RemoveTrack removes the specified track from this timeline.
+func (tl *Timeline) RemoveTrack(track *TimelineTrack) {
+
+	for i, t := range tl.tracks {
+		if t == track {
+			copy(tl.tracks[i:], tl.tracks[i+1:])
+			tl.tracks[len(tl.tracks)-1] = nil
+			tl.tracks = tl.tracks[:len(tl.tracks)-1]
+			tl.Panel.Remove(track.name)
+			track.name.Dispose()
+			if tl.selTrack == track {
+				tl.selTrack, tl.selKey = nil, -1
+			}
+			tl.recalc()
+			return
+		}
+	}
+}
+
+// ClearTracks removes all the tracks from this timeline.
+func (tl *Timeline) ClearTracks() {
+
+	for len(tl.tracks) > 0 {
+		tl.RemoveTrack(tl.tracks[len(tl.tracks)-1])
+	}
+}
+
+// Tracks returns the tracks of this timeline.
+func (tl *Timeline) Tracks() []*TimelineTrack {
+
+	return tl.tracks
+}
+
+// SetAnimation sets the animation shown by this timeline, replacing the tracks by a track
+// per channel and a track with the events of the animation, or removes it if nil.
+func (tl *Timeline) SetAnimation(anim *animation.Animation) *Timeline {
+
+	if tl.syncID != 0 {
+		Manager().ClearTimeout(tl.syncID)
+		tl.syncID = 0
+	}
+	tl.anim = anim
+	tl.ClearTracks()
+	if anim == nil {
+		return tl
+	}
+	for _, ch := range anim.Channels() {
+		tl.AddTrack(timelineTrackName(ch), ch.Keyframes())
+	}
+	if events := anim.Events(); len(events) > 0 {
+		keys := make([]float32, len(events))
+		for i, ev := range events {
+			keys[i] = ev.Time
+		}
+		tl.AddTrack("Events", keys)
+	}
+	tl.time = anim.Time()
+	tl.FitView()
+	tl.syncID = Manager().SetInterval(timelineFrame, nil, tl.sync)
+	return tl
+}
+
+// Animation returns the animation shown by this timeline or nil.
+func (tl *Timeline) Animation() *animation.Animation {
+
+	return tl.anim
+}
+
+// timelineTrackName returns the name of the track of the specified animation channel.
+func timelineTrackName(ch animation.IChannel) string {
+
+	var kind string
+	var node core.INode
+	switch c := ch.(type) {
+	case *animation.PositionChannel:
+		kind, node = "position", c.Target()
+	case *animation.RotationChannel:
+		kind, node = "rotation", c.Target()
+	case *animation.ScaleChannel:
+		kind, node = "scale", c.Target()
+	case *animation.MorphChannel:
+		return "morph"
+	default:
+		return "channel"
+	}
+	if name := node.GetNode().Name(); name != "" {
+		return name + " " + kind
+	}
+	return kind
+}
+
+// SetTime sets the time of the playhead and of the animation if set.
+func (tl *Timeline) SetTime(t float32) *Timeline {
+
+	tl.time = t
+	if tl.anim != nil {
+		tl.anim.SetTime(t)
+	}
+	tl.recalcPlayhead()
+	return tl
+}
+
+// Time returns the time of the playhead.
+func (tl *Timeline) Time() float32 {
+
+	return tl.time
+}
+
+// SetView sets the range of time shown by the tracks.
+func (tl *Timeline) SetView(tmin, tmax float32) *Timeline {
+
+	if tmax <= tmin {
+		return tl
+	}
+	tl.tmin, tl.tmax = tmin, tmax
+	tl.Redraw()
+	return tl
+}
+
+// View returns the range of time shown by the tracks.
+func (tl *Timeline) View() (float32, float32) {
+
+	return tl.tmin, tl.tmax
+}
+
+// FitView sets the view to show all the keyframes of the tracks and the animation with a margin.
+func (tl *Timeline) FitView() *Timeline {
+
+	tmin, tmax := float32(math.MaxFloat32), float32(-math.MaxFloat32)
+	if tl.anim != nil {
+		tmin, tmax = tl.anim.TimeRange()
+	}
+	for _, track := range tl.tracks {
+		for _, k := range track.keys {
+			tmin = math32.Min(tmin, k)
+			tmax = math32.Max(tmax, k)
+		}
+	}
+	if tmin > tmax {
+		tmin, tmax = 0, 1
+	}
+	if tmax-tmin < 1e-3 {
+		tmin, tmax = tmin-0.5, tmax+0.5
+	}
+	m := (tmax - tmin) * 0.05
+	return tl.SetView(tmin-m, tmax+m)
+}
+
+// SetRange selects the specified range of time.
+func (tl *Timeline) SetRange(t0, t1 float32) *Timeline {
+
+	if t1 < t0 {
+		t0, t1 = t1, t0
+	}
+	tl.rangeOn = true
+	tl.range0, tl.range1 = t0, t1
+	tl.Redraw()
+	return tl
+}
+
+// Range returns the selected range of time and whether a range is selected.
+func (tl *Timeline) Range() (float32, float32, bool) {
+
+	return tl.range0, tl.range1, tl.rangeOn
+}
+
+// ClearRange removes the range selection.
+func (tl *Timeline) ClearRange() *Timeline {
+
+	tl.rangeOn = false
+	tl.Redraw()
+	return tl
+}
+
+// SelectedKey returns the track and the index of the selected keyframe marker or nil and -1 if none.
+func (tl *Timeline) SelectedKey() (*TimelineTrack, int) {
+
+	return tl.selTrack, tl.selKey
+}
+
+// SetStyle sets the style of this timeline.
+func (tl *Timeline) SetStyle(style *TimelineStyle) *Timeline {
+
+	tl.style = style
+	tl.applyStyle()
+	return tl
+}
+
+// Dispose releases resources used by this timeline.
+func (tl *Timeline) Dispose() {
+
+	if tl.syncID != 0 {
+		Manager().ClearTimeout(tl.syncID)
+		tl.syncID = 0
+	}
+	tl.Panel.Dispose()
+}
+
+// applyStyle applies the current style to this timeline.
+func (tl *Timeline) applyStyle() {
+
+	tl.SetBordersFrom(&tl.style.Border)
+	tl.SetBordersColor4(&tl.style.BorderColor)
+	tl.SetColor4(&tl.style.BgColor)
+	tl.playhead.SetColor4(&tl.style.PlayheadColor)
+	for _, track := range tl.tracks {
+		track.name.SetColor4(&tl.style.FgColor)
+	}
+	for _, l := range tl.ticks {
+		l.SetColor4(&tl.style.FgColor)
+	}
+	tl.recalc()
+}
+
+// recalc resizes the canvas to the content area, positions the track names and redraws.
+func (tl *Timeline) recalc() {
+
+	tl.canvas.SetSize(tl.ContentWidth(), tl.ContentHeight())
+	for i, track := range tl.tracks {
+		py := tl.style.RulerHeight + float32(i)*tl.style.TrackHeight
+		track.name.SetPosition(4, py+(tl.style.TrackHeight-track.name.Height())/2)
+	}
+	tl.Redraw()
+}
+
+// recalcPlayhead positions the playhead at its time.
+func (tl *Timeline) recalcPlayhead() {
+
+	x := tl.toPixels(tl.time)
+	tl.playhead.SetVisible(x >= tl.style.HeaderWidth && x <= tl.ContentWidth())
+	tl.playhead.SetPosition(x-tl.playhead.Width()/2, 0)
+	tl.playhead.SetHeight(tl.ContentHeight())
+}
+
+// sync is called periodically by the timer to move the playhead with the animation.
+func (tl *Timeline) sync(arg interface{}) {
+
+	if tl.anim != nil && tl.anim.Time() != tl.time {
+		tl.time = tl.anim.Time()
+		tl.recalcPlayhead()
+	}
+}
+
+// toPixels converts the specified time to the x content coordinate.
+func (tl *Timeline) toPixels(t float32) float32 {
+
+	w := tl.ContentWidth() - tl.style.HeaderWidth
+	return tl.style.HeaderWidth + (t-tl.tmin)/(tl.tmax-tl.tmin)*w
+}
+
+// fromPixels converts the specified x content coordinate to time.
+func (tl *Timeline) fromPixels(x float32) float32 {
+
+	w := tl.ContentWidth() - tl.style.HeaderWidth
+	return tl.tmin + (x-tl.style.HeaderWidth)/w*(tl.tmax-tl.tmin)
+}
+
+// Redraw redraws the ruler, the tracks and the markers. It must be called
+// after the keyframes of a track are changed without using this timeline.
+func (tl *Timeline) Redraw() {
+
+	c := tl.canvas
+	c.Clear()
+	w, h := c.Width(), c.Height()
+	hw, rh, th := tl.style.HeaderWidth, tl.style.RulerHeight, tl.style.TrackHeight
+	if w <= hw || h <= 0 {
+		tl.recalcPlayhead()
+		return
+	}
+
+	// Draws the backgrounds of the every other track, the header and the ruler
+	alt := NewPath()
+	for i := 1; i < len(tl.tracks); i += 2 {
+		alt.Rect(hw, rh+float32(i)*th, w-hw, th)
+	}
+	c.Fill(alt, SolidPaint(&tl.style.AltColor))
+	c.Fill(NewPath().Rect(0, 0, hw, h), SolidPaint(&tl.style.HeaderColor))
+	c.Fill(NewPath().Rect(hw, 0, w-hw, rh), SolidPaint(&tl.style.RulerColor))
+
+	// Draws the selected range
+	if tl.rangeOn {
+		x0 := math32.Max(tl.toPixels(tl.range0), hw)
+		x1 := math32.Min(tl.toPixels(tl.range1), w)
+		if x1 > x0 {
+			c.Fill(NewPath().Rect(x0, 0, x1-x0, h), SolidPaint(&tl.style.RangeColor))
+		}
+	}
+
+	// Draws the ruler ticks and the grid lines and sets the tick labels
+	step := curveGridStep(tl.tmax-tl.tmin, (w-hw)/80)
+	decimals := int(math.Max(0, -math.Floor(math.Log10(float64(step))+1e-6)))
+	grid := NewPath()
+	ticks := NewPath()
+	nticks := 0
+	for t := math32.Ceil(tl.tmin/step) * step; t <= tl.tmax; t += step {
+		x := math32.Floor(tl.toPixels(t)) + 0.5
+		grid.MoveTo(x, rh).LineTo(x, h)
+		ticks.MoveTo(x, rh*0.5).LineTo(x, rh)
+		if nticks == len(tl.ticks) {
+			l := NewLabel("")
+			l.SetColor4(&tl.style.FgColor)
+			tl.Add(l)
+			tl.ticks = append(tl.ticks, l)
+		}
+		l := tl.ticks[nticks]
+		text := strconv.FormatFloat(float64(t), 'f', decimals, 32)
+		if l.Text() != text {
+			l.SetText(text)
+		}
+		l.SetPosition(x+3, 0)
+		l.SetVisible(x+3+l.Width() <= w)
+		nticks++
+	}
+	for _, l := range tl.ticks[nticks:] {
+		l.SetVisible(false)
+	}
+	stroke := &StrokeStyle{Width: 1}
+	grid.MoveTo(hw, rh-0.5).LineTo(w, rh-0.5)
+	grid.MoveTo(hw-0.5, 0).LineTo(hw-0.5, h)
+	c.Stroke(grid, SolidPaint(&tl.style.GridColor), stroke)
+	c.Stroke(ticks, SolidPaint(&tl.style.FgColor), stroke)
+
+	// Draws the keyframe markers as diamonds
+	r := tl.style.KeySize / 2
+	keys := NewPath()
+	for i, track := range tl.tracks {
+		cy := rh + (float32(i)+0.5)*th
+		for j, k := range track.keys {
+			x := tl.toPixels(k)
+			if x < hw-r || x > w+r || track == tl.selTrack && j == tl.selKey {
+				continue
+			}
+			keys.MoveTo(x, cy-r).LineTo(x+r, cy).LineTo(x, cy+r).LineTo(x-r, cy).Close()
+		}
+	}
+	c.Fill(keys, SolidPaint(&tl.style.KeyColor))
+	if tl.selTrack != nil && tl.selKey >= 0 && tl.selKey < len(tl.selTrack.keys) {
+		i := tl.trackIndex(tl.selTrack)
+		cy := rh + (float32(i)+0.5)*th
+		x := tl.toPixels(tl.selTrack.keys[tl.selKey])
+		sel := NewPath().MoveTo(x, cy-r).LineTo(x+r, cy).LineTo(x, cy+r).LineTo(x-r, cy).Close()
+		c.Fill(sel, SolidPaint(&tl.style.SelectColor))
+	}
+	tl.recalcPlayhead()
+}
+
+// trackIndex returns the index of the specified track or -1 if not found.
+func (tl *Timeline) trackIndex(track *TimelineTrack) int {
+
+	for i, t := range tl.tracks {
+		if t == track {
+			return i
+		}
+	}
+	return -1
+}
+
+// hitKey returns the track and the index of the keyframe marker at the specified content coordinates.
+func (tl *Timeline) hitKey(x, y float32) (*TimelineTrack, int) {
+
+	i := int((y - tl.style.RulerHeight) / tl.style.TrackHeight)
+	if y < tl.style.RulerHeight || i >= len(tl.tracks) {
+		return nil, -1
+	}
+	track := tl.tracks[i]
+	r := tl.style.KeySize/2 + 2
+	for j := len(track.keys) - 1; j >= 0; j-- {
+		if math32.Abs(tl.toPixels(track.keys[j])-x) <= r {
+			return track, j
+		}
+	}
+	return nil, -1
+}
+
+// onMouse receives subscribed mouse events.
+func (tl *Timeline) onMouse(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	if evname == OnMouseUp {
+		if tl.drag != timelineDragNone {
+			tl.drag = timelineDragNone
+			Manager().SetCursorFocus(nil)
+		}
+		return
+	}
+	x, y := tl.ContentCoords(mev.Xpos, mev.Ypos)
+	switch mev.Button {
+	case window.MouseButtonLeft:
+		if x < tl.style.HeaderWidth {
+			return
+		}
+		if y < tl.style.RulerHeight {
+			tl.drag = timelineDragScrub
+			tl.scrub(x)
+			break
+		}
+		if track, key := tl.hitKey(x, y); track != nil {
+			tl.selTrack, tl.selKey = track, key
+			tl.Redraw()
+			tl.Dispatch(OnTimelineKey, track)
+			return
+		}
+		tl.drag = timelineDragRange
+		tl.rangeFrom = tl.fromPixels(x)
+		if tl.rangeOn {
+			tl.rangeOn = false
+			tl.Redraw()
+			tl.Dispatch(OnTimelineRange, nil)
+		}
+	case window.MouseButtonRight, window.MouseButtonMiddle:
+		tl.drag = timelineDragPan
+		tl.posLastX = mev.Xpos
+	default:
+		return
+	}
+	Manager().SetCursorFocus(tl)
+}
+
+// onCursor receives subscribed cursor events.
+func (tl *Timeline) onCursor(evname string, ev interface{}) {
+
+	cev := ev.(*window.CursorEvent)
+	tl.cursorX = cev.Xpos
+	x, _ := tl.ContentCoords(cev.Xpos, cev.Ypos)
+	switch tl.drag {
+	case timelineDragScrub:
+		tl.scrub(x)
+	case timelineDragRange:
+		t := tl.fromPixels(math32.Clamp(x, tl.style.HeaderWidth, tl.ContentWidth()))
+		tl.SetRange(tl.rangeFrom, t)
+		tl.Dispatch(OnTimelineRange, nil)
+	case timelineDragPan:
+		dt := (cev.Xpos - tl.posLastX) / (tl.ContentWidth() - tl.style.HeaderWidth) * (tl.tmax - tl.tmin)
+		tl.posLastX = cev.Xpos
+		tl.SetView(tl.tmin-dt, tl.tmax-dt)
+	}
+}
+
+// scrub moves the playhead to the time at the specified x content coordinate and dispatches OnChange.
+func (tl *Timeline) scrub(x float32) {
+
+	t := tl.fromPixels(math32.Clamp(x, tl.style.HeaderWidth, tl.ContentWidth()))
+	if t == tl.time {
+		return
+	}
+	tl.SetTime(t)
+	tl.Dispatch(OnChange, nil)
+}
+
+// onScroll receives subscribed scroll events and zooms the view around the cursor.
+func (tl *Timeline) onScroll(evname string, ev interface{}) {
+
+	sev := ev.(*window.ScrollEvent)
+	x, _ := tl.ContentCoords(tl.cursorX, 0)
+	t := tl.fromPixels(math32.Max(x, tl.style.HeaderWidth))
+	f := math32.Pow(1.2, -sev.Yoffset)
+	tl.SetView(t-(t-tl.tmin)*f, t+(tl.tmax-t)*f)
+}
+
+// SetName sets the name of this track.
+func (track *TimelineTrack) SetName(name string) *TimelineTrack {
+
+	track.name.SetText(name)
+	return track
+}
+
+// Name returns the name of this track.
+func (track *TimelineTrack) Name() string {
+
+	return track.name.Text()
+}
+
+// SetKeys sets the times of the keyframes of this track.
+func (track *TimelineTrack) SetKeys(keys []float32) *TimelineTrack {
+
+	track.keys = keys
+	if track.timeline.selTrack == track && track.timeline.selKey >= len(keys) {
+		track.timeline.selTrack, track.timeline.selKey = nil, -1
+	}
+	track.timeline.Redraw()
+	return track
+}
+
+// Keys returns the times of the keyframes of this track.
+func (track *TimelineTrack) Keys() []float32 {
+
+	return track.keys
+}
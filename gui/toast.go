@@ -0,0 +1,327 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"time"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Toast is a notification panel shown by the GUI manager in a corner of the window.
+// Toasts are stacked, slide in when shown and slide out when dismissed, automatically
+// after their duration or programmatically. Clicking a toast dismisses it.
+type Toast struct {
+	Panel                     // Embedded panel
+	label      *Label         // Text label
+	button     *Button        // Optional action button
+	severity   ToastSeverity  // Severity of the notification
+	style      *ToastStyle    // Pointer to the toast style
+	timeoutID  int            // Id of the auto dismiss timer (0 if none)
+	offset     float32        // Current distance from the corner along the stack
+	target     float32        // Distance from the corner along the stack to move to
+	slide      float32        // Visible fraction of the toast from 0 to 1
+	dismissing bool           // Toast is sliding out
+	action     func(t *Toast) // Action button callback
+}
+
+// ToastSeverity is the severity of a toast notification, which selects its color.
+type ToastSeverity int
+
+// The severities of the toast notifications.
+const (
+	ToastInfo = ToastSeverity(iota)
+	ToastSuccess
+	ToastWarning
+	ToastError
+)
+
+// ToastCorner is the corner of the window where the toast notifications are stacked.
+type ToastCorner int
+
+// The corners where the toast notifications can be stacked.
+const (
+	ToastTopLeft = ToastCorner(iota)
+	ToastTopRight
+	ToastBottomLeft
+	ToastBottomRight
+)
+
+// ToastStyle contains the styling of the toast notifications.
+type ToastStyle struct {
+	Border   RectBounds       // Borders of the toast panels
+	Padding  RectBounds       // Paddings of the toast panels
+	BgColor  math32.Color4    // Background color
+	FgColor  math32.Color4    // Text color
+	Colors   [4]math32.Color4 // Border colors of the info, success, warning and error severities
+	MinWidth float32          // Minimum width of the toast panels
+	Spacing  float32          // Distance between the toasts and from the window borders
+}
+
+// toaster contains the state of the toast notifications of the GUI manager.
+type toaster struct {
+	corner  ToastCorner // Corner where the toasts are stacked
+	toasts  []*Toast    // Toasts shown from the newest to the oldest
+	timerID int         // Id of the animation timer (0 if not animating)
+	last    time.Time   // Time of the last animation step
+}
+
+const (
+	toastFrame    = 16 * time.Millisecond
+	toastSlideDur = 250 * time.Millisecond // Duration of the slide in and out animations
+	toastStackAcc = 12                     // Rate of the stack movements per second
+)
+
+// Toast shows a new toast notification with the specified text and severity which is
+// dismissed after the specified duration or only when dismissed explicitly if zero.
+// The GUI scene must be set with Set for the toast to be shown.
+func (gm *manager) Toast(text string, duration time.Duration, severity ToastSeverity) *Toast {
+
+	t := newToast(text, severity)
+	if gm.scene == nil {
+		log.Error("Toast: GUI scene not set")
+		return t
+	}
+	gm.scene.GetNode().Add(t)
+	gm.toaster.toasts = append([]*Toast{t}, gm.toaster.toasts...)
+	if duration > 0 {
+		t.timeoutID = gm.SetTimeout(duration, nil, func(arg interface{}) {
+			t.timeoutID = 0
+			t.Dismiss()
+		})
+	}
+	gm.restackToasts()
+	t.offset = t.target
+	gm.toaster.place(t)
+	gm.startToasts()
+	return t
+}
+
+// SetToastCorner sets the corner of the window where the toast notifications are stacked.
+// The default is the top right corner.
+func (gm *manager) SetToastCorner(corner ToastCorner) {
+
+	gm.toaster.corner = corner
+	for _, t := range gm.toaster.toasts {
+		gm.toaster.place(t)
+	}
+}
+
+// ToastCorner returns the corner of the window where the toast notifications are stacked.
+func (gm *manager) ToastCorner() ToastCorner {
+
+	return gm.toaster.corner
+}
+
+// DismissToasts dismisses all the toast notifications being shown.
+func (gm *manager) DismissToasts() {
+
+	for _, t := range gm.toaster.toasts {
+		t.Dismiss()
+	}
+}
+
+// newToast creates and returns a pointer to a new toast panel.
+func newToast(text string, severity ToastSeverity) *Toast {
+
+	t := new(Toast)
+	t.severity = severity
+	t.style = &StyleDefault().Toast
+	t.Panel.Initialize(t, 0, 0)
+	t.SetLayer(LayerTooltip)
+	t.SetBordersFrom(&t.style.Border)
+	t.SetPaddingsFrom(&t.style.Padding)
+	t.SetBordersColor4(&t.style.Colors[severity])
+	t.SetColor4(&t.style.BgColor)
+	t.Subscribe(OnMouseDown, func(evname string, ev interface{}) { t.Dismiss() })
+
+	t.label = NewLabel(text)
+	t.label.SetColor4(&t.style.FgColor)
+	t.Add(t.label)
+	t.recalc()
+	return t
+}
+
+// SetText sets the text of the toast.
+func (t *Toast) SetText(text string) *Toast {
+
+	t.label.SetText(text)
+	t.recalc()
+	Manager().restackToasts()
+	return t
+}
+
+// Text returns the text of the toast.
+func (t *Toast) Text() string {
+
+	return t.label.Text()
+}
+
+// Severity returns the severity of the toast.
+func (t *Toast) Severity() ToastSeverity {
+
+	return t.severity
+}
+
+// SetAction adds a button with the specified text to the toast which calls
+// the specified function when clicked and then dismisses the toast.
+func (t *Toast) SetAction(text string, cb func(t *Toast)) *Toast {
+
+	t.action = cb
+	if t.button == nil {
+		t.button = NewButton(text)
+		t.button.Subscribe(OnClick, func(evname string, ev interface{}) {
+			if t.action != nil {
+				t.action(t)
+			}
+			t.Dismiss()
+		})
+		t.Add(t.button)
+	} else {
+		t.button.Label.SetText(text)
+		t.button.recalc()
+	}
+	t.recalc()
+	Manager().restackToasts()
+	return t
+}
+
+// Dismiss starts sliding out the toast, which is removed from the GUI
+// scene and disposed at the end of the animation.
+func (t *Toast) Dismiss() {
+
+	if t.dismissing {
+		return
+	}
+	t.dismissing = true
+	gm := Manager()
+	if t.timeoutID != 0 {
+		gm.ClearTimeout(t.timeoutID)
+		t.timeoutID = 0
+	}
+	gm.startToasts()
+}
+
+// Dismissed returns whether the toast was dismissed.
+func (t *Toast) Dismissed() bool {
+
+	return t.dismissing
+}
+
+// recalc sets the size of the toast and the positions of its children.
+func (t *Toast) recalc() {
+
+	width := t.label.Width()
+	height := t.label.Height()
+	if t.button != nil {
+		width += t.style.Spacing + t.button.Width()
+		height = math32.Max(height, t.button.Height())
+	}
+	t.SetContentSize(width, height)
+	if t.Width() < t.style.MinWidth {
+		t.SetWidth(t.style.MinWidth)
+	}
+	t.label.SetPosition(0, (t.ContentHeight()-t.label.Height())/2)
+	if t.button != nil {
+		t.button.SetPosition(t.ContentWidth()-t.button.Width(), (t.ContentHeight()-t.button.Height())/2)
+	}
+}
+
+// restackToasts sets the target distance from the corner of each toast.
+func (gm *manager) restackToasts() {
+
+	var offset float32
+	for _, t := range gm.toaster.toasts {
+		t.target = offset
+		offset += t.Height() + t.style.Spacing
+	}
+}
+
+// startToasts starts the animation timer if it is not running.
+func (gm *manager) startToasts() {
+
+	if gm.toaster.timerID != 0 {
+		return
+	}
+	gm.toaster.last = time.Now()
+	gm.toaster.timerID = gm.SetInterval(toastFrame, nil, gm.animateToasts)
+}
+
+// animateToasts is called by the animation timer to slide the toasts in and out,
+// move them to their stack positions and remove the dismissed ones.
+func (gm *manager) animateToasts(arg interface{}) {
+
+	tr := &gm.toaster
+	now := time.Now()
+	dt := float32(now.Sub(tr.last).Seconds())
+	tr.last = now
+
+	animating := false
+	removed := false
+	toasts := tr.toasts[:0]
+	for _, t := range tr.toasts {
+		step := dt / float32(toastSlideDur.Seconds())
+		if t.dismissing {
+			t.slide -= step
+		} else {
+			t.slide += step
+		}
+		t.slide = math32.Clamp(t.slide, 0, 1)
+		t.offset += (t.target - t.offset) * math32.Min(1, dt*toastStackAcc)
+		if math32.Abs(t.target-t.offset) < 0.5 {
+			t.offset = t.target
+		}
+		if t.dismissing && t.slide == 0 {
+			if gm.scene != nil {
+				gm.scene.GetNode().Remove(t)
+			}
+			t.DisposeChildren(true)
+			t.Dispose()
+			removed = true
+			continue
+		}
+		if t.dismissing || t.slide < 1 || t.offset != t.target {
+			animating = true
+		}
+		tr.place(t)
+		toasts = append(toasts, t)
+	}
+	for i := len(toasts); i < len(tr.toasts); i++ {
+		tr.toasts[i] = nil
+	}
+	tr.toasts = toasts
+	if removed {
+		gm.restackToasts()
+		animating = true
+	}
+	if !animating {
+		gm.ClearTimeout(tr.timerID)
+		tr.timerID = 0
+	}
+}
+
+// place sets the position of the specified toast in the window from its
+// distance from the corner along the stack and its visible fraction.
+func (tr *toaster) place(t *Toast) {
+
+	width, height := Manager().ScreenSize()
+	s := t.style.Spacing
+	// Eases out the slide animation
+	slide := 1 - (1-t.slide)*(1-t.slide)
+	var x, y float32
+	switch tr.corner {
+	case ToastTopLeft, ToastBottomLeft:
+		x = s - (s+t.Width())*(1-slide)
+	default:
+		x = width - (s+t.Width())*slide
+	}
+	switch tr.corner {
+	case ToastBottomLeft, ToastBottomRight:
+		y = height - s - t.Height() - t.offset
+	default:
+		y = s + t.offset
+	}
+	t.SetPosition(x, y)
+}
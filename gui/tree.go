@@ -11,10 +11,14 @@ import (
 
 // Tree is the tree structure GUI element.
 type Tree struct {
-	List               // Embedded list panel
-	styles *TreeStyles // Pointer to styles
+	List                // Embedded list panel
+	styles  *TreeStyles // Pointer to styles
+	loading string      // Markup of the loading indicator of lazy nodes
 }
 
+// defaultTreeLoading is the default markup of the loading indicator of lazy nodes
+const defaultTreeLoading = "[icon=e88b] [i]Loading...[/i]"
+
 // TreeStyles contains the styling of all tree components for each valid GUI state.
 type TreeStyles struct {
 	List     *ListStyles     // Styles for the embedded list
@@ -36,14 +40,17 @@ type TreeNodeStyle struct {
 
 // TreeNode is a tree node.
 type TreeNode struct {
-	Panel              // Embedded panel
-	label    Label     // Node label
-	icon     Label     // Node icon
-	tree     *Tree     // Parent tree
-	parNode  *TreeNode // Parent node
-	items    []IPanel  // List of node items
-	expanded bool      // Node expanded flag
-	litem    *ListItem // Reference to ListItem
+	Panel                    // Embedded panel
+	label    Label           // Node label
+	icon     Label           // Node icon
+	tree     *Tree           // Parent tree
+	parNode  *TreeNode       // Parent node
+	items    []IPanel        // List of node items
+	expanded bool            // Node expanded flag
+	litem    *ListItem       // Reference to ListItem
+	onExpand func(*TreeNode) // Callback which supplies the children of a lazy node
+	loaded   bool            // Children of the lazy node were supplied
+	loading  *Label          // Loading indicator shown while the children are supplied (may be nil)
 }
 
 // NewTree creates and returns a pointer to a new tree widget.
@@ -59,6 +66,7 @@ func NewTree(width, height float32) *Tree {
 func (t *Tree) Initialize(width, height float32) {
 
 	t.List.initialize(true, width, height)
+	t.loading = defaultTreeLoading
 	t.SetStyles(&StyleDefault().Tree)
 	t.List.Subscribe(OnKeyDown, t.onKey)
 	t.List.Subscribe(OnKeyUp, t.onKey)
@@ -72,6 +80,13 @@ func (t *Tree) SetStyles(s *TreeStyles) {
 	t.update()
 }
 
+// SetLoadingText sets the markup text (see Label.SetMarkup) of the indicator
+// shown under lazy nodes while their children are being supplied.
+func (t *Tree) SetLoadingText(markup string) {
+
+	t.loading = markup
+}
+
 // InsertAt inserts a child panel at the specified position in the tree.
 func (t *Tree) InsertAt(pos int, child IPanel) {
 
@@ -182,9 +197,7 @@ func (t *Tree) onKey(evname string, ev interface{}) {
 		return
 	}
 	// Toggles the expansion state of the node
-	node.expanded = !node.expanded
-	node.update()
-	node.updateItems()
+	node.SetExpanded(!node.expanded)
 }
 
 //
@@ -226,12 +239,67 @@ func (n *TreeNode) Len() int {
 	return len(n.items)
 }
 
-// SetExpanded sets the expanded state of this node
+// SetExpanded sets the expanded state of this node.
+// Expanding a lazy node whose children were not supplied yet calls its OnExpand callback.
 func (n *TreeNode) SetExpanded(state bool) {
 
 	n.expanded = state
+	load := state && n.onExpand != nil && !n.loaded && n.loading == nil
+	if load {
+		n.loading = NewLabel("")
+		n.loading.SetMarkup(n.tree.loading)
+	}
 	n.update()
 	n.updateItems()
+	if load {
+		n.onExpand(n)
+	}
+}
+
+// Expanded returns the expanded state of this node
+func (n *TreeNode) Expanded() bool {
+
+	return n.expanded
+}
+
+// SetOnExpand makes this node lazy: its children are supplied on demand by the specified
+// callback, which is called the first time the node is expanded. A loading indicator is
+// shown under the node until SetLoaded is called, so the callback may start loading
+// the children in the background and add them later from the main goroutine.
+// A nil callback makes the node regular again.
+func (n *TreeNode) SetOnExpand(cb func(node *TreeNode)) {
+
+	n.onExpand = cb
+	n.loaded = false
+}
+
+// SetLoaded removes the loading indicator of this lazy node after its children were supplied.
+func (n *TreeNode) SetLoaded() {
+
+	n.loaded = true
+	if n.loading == nil {
+		return
+	}
+	n.tree.List.Remove(n.loading)
+	n.loading = nil
+}
+
+// Loading returns whether the children of this lazy node are being supplied.
+func (n *TreeNode) Loading() bool {
+
+	return n.loading != nil
+}
+
+// Reload removes all the children of this lazy node, so they are supplied
+// again by its OnExpand callback immediately if the node is expanded or
+// else the next time it is expanded.
+func (n *TreeNode) Reload() {
+
+	n.removeItems()
+	n.items = nil
+	n.loaded = false
+	n.loading = nil
+	n.SetExpanded(n.expanded)
 }
 
 // FindChild searches for the specified child in this node and
@@ -331,10 +399,8 @@ func (n *TreeNode) onMouse(evname string, ev interface{}) {
 
 	switch evname {
 	case OnMouseDown:
-		n.expanded = !n.expanded
-		n.update()
+		n.SetExpanded(!n.expanded)
 		n.recalc()
-		n.updateItems()
 	}
 
 	n.litem.onMouse(evname, ev)
@@ -403,6 +469,9 @@ func (n *TreeNode) removeItems() {
 			continue
 		}
 	}
+	if n.loading != nil {
+		n.tree.List.Remove(n.loading)
+	}
 }
 
 // insert inserts this node and its expanded children in the tree list
@@ -442,6 +511,12 @@ func (n *TreeNode) insertItems(pos int) int {
 		n.tree.List.SetItemPadLeftAt(pos, padLeft)
 		pos++
 	}
+	// Insert the loading indicator after the children supplied so far
+	if n.loading != nil {
+		n.tree.List.InsertAt(pos, n.loading)
+		n.tree.List.SetItemPadLeftAt(pos, padLeft)
+		pos++
+	}
 	return pos
 }
 
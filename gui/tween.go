@@ -0,0 +1,228 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"time"
+
+	"github.com/g3n/engine/math32"
+)
+
+// TweenProperty is a panel property animated by a tween.
+type TweenProperty int
+
+// The panel properties which can be animated.
+const (
+	TweenOpacity = TweenProperty(iota) // Opacity of the panel and its children
+	TweenX                             // X position of the panel
+	TweenY                             // Y position of the panel
+	TweenWidth                         // Width of the panel
+	TweenHeight                        // Height of the panel
+)
+
+// Easing is a function which maps the fraction of the duration of a tween elapsed,
+// from 0 to 1, to the fraction of the change of the animated value.
+type Easing func(t float32) float32
+
+// EaseLinear is the easing function with constant speed.
+func EaseLinear(t float32) float32 {
+
+	return t
+}
+
+// EaseInQuad is the easing function which accelerates from zero speed.
+func EaseInQuad(t float32) float32 {
+
+	return t * t
+}
+
+// EaseOutQuad is the easing function which decelerates to zero speed.
+func EaseOutQuad(t float32) float32 {
+
+	return t * (2 - t)
+}
+
+// EaseInOutQuad is the easing function which accelerates until halfway and then decelerates.
+func EaseInOutQuad(t float32) float32 {
+
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return -1 + (4-2*t)*t
+}
+
+// EaseInCubic is the cubic easing function which accelerates from zero speed.
+func EaseInCubic(t float32) float32 {
+
+	return t * t * t
+}
+
+// EaseOutCubic is the cubic easing function which decelerates to zero speed.
+func EaseOutCubic(t float32) float32 {
+
+	t--
+	return t*t*t + 1
+}
+
+// EaseInOutCubic is the cubic easing function which accelerates until halfway and then decelerates.
+func EaseInOutCubic(t float32) float32 {
+
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	t = 2*t - 2
+	return t*t*t/2 + 1
+}
+
+// EaseOutBack is the easing function which overshoots the end value and then returns to it.
+func EaseOutBack(t float32) float32 {
+
+	const s = 1.70158
+	t--
+	return t*t*((s+1)*t+s) + 1
+}
+
+// Time between steps of the tweens
+const tweenFrame = 16 * time.Millisecond
+
+// Tween animates a value from a start to an end value during a duration with an easing function.
+// It is created by Animate or AnimateFunc and advanced by a GUI manager timer.
+type Tween struct {
+	from       float32         // Start value
+	to         float32         // End value
+	duration   time.Duration   // Duration of the animation
+	easing     Easing          // Easing function
+	set        func(v float32) // Function which sets the animated value
+	start      time.Time       // Start time
+	timerID    int             // Id of the animation timer (0 if stopped)
+	onComplete func()          // Optional function called when the animation completes
+	key        *tweenKey       // Panel property animated by the tween (nil for AnimateFunc tweens)
+}
+
+// tweenKey identifies a property of a panel animated by a tween.
+type tweenKey struct {
+	panel    *Panel
+	property TweenProperty
+}
+
+// Tweens animating panel properties
+var panelTweens = map[tweenKey]*Tween{}
+
+// Animate starts and returns a tween which animates the specified property of the panel from the
+// specified value to another during the specified duration, for fades, slides and size transitions.
+// A nil easing is linear. A tween started for the same panel and property stops the previous one.
+func Animate(ipan IPanel, property TweenProperty, from, to float32, duration time.Duration, easing Easing) *Tween {
+
+	p := ipan.GetPanel()
+	var set func(v float32)
+	switch property {
+	case TweenOpacity:
+		set = p.SetOpacity
+	case TweenX:
+		set = func(v float32) { ipan.SetPositionX(v) }
+	case TweenY:
+		set = func(v float32) { ipan.SetPositionY(v) }
+	case TweenWidth:
+		set = p.SetWidth
+	case TweenHeight:
+		set = p.SetHeight
+	default:
+		panic("Invalid tween property")
+	}
+	key := tweenKey{p, property}
+	if prev := panelTweens[key]; prev != nil {
+		prev.Stop()
+	}
+	t := newTween(from, to, duration, easing, set)
+	t.key = &key
+	panelTweens[key] = t
+	t.run()
+	return t
+}
+
+// AnimateFunc starts and returns a tween which calls the specified function with the value animated
+// from the specified value to another during the specified duration. A nil easing is linear.
+func AnimateFunc(from, to float32, duration time.Duration, easing Easing, set func(v float32)) *Tween {
+
+	t := newTween(from, to, duration, easing, set)
+	t.run()
+	return t
+}
+
+// newTween creates and returns a pointer to a new tween which is not started.
+func newTween(from, to float32, duration time.Duration, easing Easing, set func(v float32)) *Tween {
+
+	t := new(Tween)
+	t.from = from
+	t.to = to
+	t.duration = duration
+	t.easing = easing
+	if t.easing == nil {
+		t.easing = EaseLinear
+	}
+	t.set = set
+	return t
+}
+
+// SetOnComplete sets a function called when the animation completes. It is not called if the tween is stopped.
+func (t *Tween) SetOnComplete(cb func()) *Tween {
+
+	t.onComplete = cb
+	return t
+}
+
+// Running returns whether the tween is animating.
+func (t *Tween) Running() bool {
+
+	return t.timerID != 0
+}
+
+// Stop stops the animation leaving the current value.
+func (t *Tween) Stop() {
+
+	if t.timerID == 0 {
+		return
+	}
+	Manager().ClearTimeout(t.timerID)
+	t.timerID = 0
+	if t.key != nil && panelTweens[*t.key] == t {
+		delete(panelTweens, *t.key)
+	}
+}
+
+// Finish stops the animation setting the end value and calls the completion function.
+func (t *Tween) Finish() {
+
+	if t.timerID == 0 {
+		return
+	}
+	t.start = time.Now().Add(-t.duration)
+	t.step(nil)
+}
+
+// run sets the start value and starts the animation timer.
+func (t *Tween) run() {
+
+	t.start = time.Now()
+	t.set(t.from)
+	t.timerID = Manager().SetInterval(tweenFrame, nil, t.step)
+}
+
+// step is called by the animation timer to set the animated value.
+func (t *Tween) step(arg interface{}) {
+
+	f := float32(1)
+	if t.duration > 0 {
+		f = math32.Min(float32(time.Since(t.start))/float32(t.duration), 1)
+	}
+	t.set(t.from + (t.to-t.from)*t.easing(f))
+	if f < 1 {
+		return
+	}
+	t.Stop()
+	if t.onComplete != nil {
+		t.onComplete()
+	}
+}
@@ -13,9 +13,9 @@ import (
 /*********************************************
 
  Window panel
- +-------------------------------------+---+
- |              Title panel            | X |
- +-------------------------------------+---+
+ +-----------------------------+---+---+---+
+ |         Title panel         | _ | O | X |
+ +-----------------------------+---+---+---+
  |  Client (content) panel                 |
  |  +-----------------------------------+  |
  |  |                                   |  |
@@ -30,6 +30,15 @@ import (
 
 *********************************************/
 
+// Window events
+const (
+	OnWindowClose    = "gui.OnWindowClose"    // Window closed by its close button
+	OnWindowMinimize = "gui.OnWindowMinimize" // Window minimized (collapsed to its title)
+	OnWindowMaximize = "gui.OnWindowMaximize" // Window maximized to fill its parent
+	OnWindowRestore  = "gui.OnWindowRestore"  // Window restored from the minimized or maximized state
+	OnWindowModal    = "gui.OnWindowModal"    // Window modal state changed
+)
+
 // Window represents a window GUI element
 type Window struct {
 	Panel       // Embedded Panel
@@ -39,6 +48,7 @@ type Window struct {
 	resizable   bool         // Specifies whether the window is resizable
 	drag        bool         // Whether the mouse buttons is pressed (i.e. when dragging)
 	dragPadding float32      // Extra width used to resize (in addition to border sizes)
+	tracker     StateTracker // tracker of the window pseudo-state
 
 	// To keep track of which window borders the cursor is over
 	overTop    bool
@@ -49,6 +59,20 @@ type Window struct {
 	// Minimum and maximum sizes
 	minSize math32.Vector2
 	maxSize math32.Vector2
+
+	// Minimized, maximized and modal states
+	minimized     bool           // Whether the window is collapsed to its title
+	maximized     bool           // Whether the window fills its parent
+	modal         bool           // Whether the window is modal
+	restoreHeight float32        // Height of the window before minimized
+	restorePos    math32.Vector3 // Position of the window before maximized
+	restoreSize   math32.Vector2 // Size of the window before maximized
+	prevLayer     Layer          // Layer set before the window was made modal
+	prevLayered   bool           // Whether a layer was set before the window was made modal
+
+	// Snapping while moved
+	snapDistance float32 // Distance from the edges under which the window snaps (0 to disable)
+	snapWindows  bool    // Whether the window also snaps to the edges of its sibling windows
 }
 
 // WindowStyle contains the styling of a Window
@@ -79,6 +103,7 @@ func NewWindow(width, height float32) *Window {
 	w.Panel.Subscribe(OnCursorEnter, w.onCursor)
 	w.Panel.Subscribe(OnCursorLeave, w.onCursor)
 	w.Panel.Subscribe(OnResize, func(evname string, ev interface{}) { w.recalc() })
+	w.tracker.Init(&w.Panel, w.update)
 
 	w.client.Initialize(&w.client, 0, 0)
 	w.Panel.Add(&w.client)
@@ -99,7 +124,151 @@ func (w *Window) SetResizable(state bool) {
 // SetCloseButton sets whether the window has a close button on the top right.
 func (w *Window) SetCloseButton(state bool) {
 
-	w.title.setCloseButton(state)
+	w.title.setButton(w.title.closeButton, &w.title.closeButtonVisible, state)
+}
+
+// SetMinimizeButton sets whether the window has a button in the title to minimize and restore it.
+// The window must have a title.
+func (w *Window) SetMinimizeButton(state bool) {
+
+	w.title.setButton(w.title.minButton, &w.title.minButtonVisible, state)
+}
+
+// SetMaximizeButton sets whether the window has a button in the title to maximize and restore it.
+// The window must have a title.
+func (w *Window) SetMaximizeButton(state bool) {
+
+	w.title.setButton(w.title.maxButton, &w.title.maxButtonVisible, state)
+}
+
+// Close removes the window from its parent, releases the modal state
+// if it is modal, disposes it and dispatches OnWindowClose.
+func (w *Window) Close() {
+
+	if w.modal {
+		w.SetModal(false)
+	}
+	if w.Parent() != nil {
+		w.Parent().GetNode().Remove(w)
+	}
+	w.Dispose()
+	w.Dispatch(OnWindowClose, nil)
+}
+
+// Minimize collapses the window to its title hiding its client area.
+// The window must have a title.
+func (w *Window) Minimize() {
+
+	if w.minimized || w.title == nil {
+		return
+	}
+	w.minimized = true
+	w.restoreHeight = w.Height()
+	w.client.SetVisible(false)
+	w.SetContentHeight(w.title.height)
+	w.title.updateButtons()
+	w.Dispatch(OnWindowMinimize, nil)
+}
+
+// Minimized returns whether the window is minimized.
+func (w *Window) Minimized() bool {
+
+	return w.minimized
+}
+
+// Maximize resizes the window to fill the content area of its parent panel,
+// or the application window if its parent is not a panel.
+func (w *Window) Maximize() {
+
+	if w.maximized {
+		return
+	}
+	if w.minimized {
+		w.unminimize()
+	}
+	w.maximized = true
+	w.restorePos = w.Position()
+	w.restoreSize = math32.Vector2{X: w.Width(), Y: w.Height()}
+	width, height := w.parentSize()
+	w.SetPosition(0, 0)
+	w.SetSize(width, height)
+	if w.title != nil {
+		w.title.updateButtons()
+	}
+	w.Dispatch(OnWindowMaximize, nil)
+}
+
+// Maximized returns whether the window is maximized.
+func (w *Window) Maximized() bool {
+
+	return w.maximized
+}
+
+// Restore restores the window from the minimized state, returning to the maximized
+// state if it was maximized, or else from the maximized state to its previous
+// position and size.
+func (w *Window) Restore() {
+
+	if w.minimized {
+		w.unminimize()
+	} else if w.maximized {
+		w.maximized = false
+		w.SetPositionVec(&w.restorePos)
+		w.SetSize(w.restoreSize.X, w.restoreSize.Y)
+	} else {
+		return
+	}
+	if w.title != nil {
+		w.title.updateButtons()
+	}
+	w.Dispatch(OnWindowRestore, nil)
+}
+
+// SetModal sets whether the window is modal.
+// A modal window is moved to the modal layer and it and its descendants
+// exclusively receive the GUI events until it is closed or not modal anymore.
+func (w *Window) SetModal(state bool) {
+
+	if state == w.modal {
+		return
+	}
+	w.modal = state
+	if state {
+		w.prevLayer, w.prevLayered = w.Layer()
+		Manager().SetLayer(w, LayerModal)
+		Manager().SetModal(w)
+	} else {
+		if w.prevLayered {
+			Manager().SetLayer(w, w.prevLayer)
+		} else {
+			Manager().ClearLayer(w)
+		}
+		if Manager().modal == IPanel(w) {
+			Manager().SetModal(nil)
+		}
+	}
+	w.Dispatch(OnWindowModal, nil)
+}
+
+// Modal returns whether the window is modal.
+func (w *Window) Modal() bool {
+
+	return w.modal
+}
+
+// SetSnap sets the distance in pixels under which the edges of the window being moved
+// snap to the edges of its parent and optionally to the edges of its sibling windows.
+// Zero disables snapping.
+func (w *Window) SetSnap(distance float32, windows bool) {
+
+	w.snapDistance = distance
+	w.snapWindows = windows
+}
+
+// Snap returns the snap distance in pixels and whether the window snaps to its sibling windows.
+func (w *Window) Snap() (float32, bool) {
+
+	return w.snapDistance, w.snapWindows
 }
 
 // SetTitle sets the title of the window.
@@ -139,9 +308,8 @@ func (w *Window) onMouse(evname string, ev interface{}) {
 
 	switch evname {
 	case OnMouseDown:
-		// Move the window above everything contained in its parent
-		parent := w.Parent().(IPanel).GetPanel()
-		parent.SetTopChild(w)
+		// Move the window above the other panels of its parent in the same layer
+		Manager().BringToFront(w)
 		// If the click happened inside the draggable area, then set drag to true
 		if w.overTop || w.overRight || w.overBottom || w.overLeft {
 			w.drag = true
@@ -159,7 +327,7 @@ func (w *Window) onMouse(evname string, ev interface{}) {
 func (w *Window) onCursor(evname string, ev interface{}) {
 
 	// If the window is not resizable we are not interested in cursor movements
-	if !w.resizable {
+	if !w.resizable || w.minimized || w.maximized {
 		return
 	}
 	if evname == OnCursor {
@@ -173,9 +341,7 @@ func (w *Window) onCursor(evname string, ev interface{}) {
 			if w.title != nil {
 				titleHeight = w.title.height
 				titleLabelWidth = w.title.label.Width()
-				if w.title.closeButton != nil {
-					titleCloseBtnWidth = w.title.closeButton.Width()
-				}
+				titleCloseBtnWidth = w.title.buttonsWidth()
 			}
 
 			if w.overTop {
@@ -261,14 +427,79 @@ func (w *Window) onCursor(evname string, ev interface{}) {
 	}
 }
 
+// unminimize restores the height and client area of the minimized window.
+func (w *Window) unminimize() {
+
+	w.minimized = false
+	w.client.SetVisible(true)
+	w.SetHeight(w.restoreHeight)
+}
+
+// parentSize returns the size of the content area of the parent
+// panel of the window or the size of the application window.
+func (w *Window) parentSize() (float32, float32) {
+
+	if par, ok := w.Parent().(IPanel); ok {
+		return par.GetPanel().ContentWidth(), par.GetPanel().ContentHeight()
+	}
+	return Manager().ScreenSize()
+}
+
+// snapPosition returns the specified position of the window being moved adjusted
+// so its edges snap to the nearest edges of its parent and sibling windows.
+func (w *Window) snapPosition(x, y float32) (float32, float32) {
+
+	if w.snapDistance <= 0 {
+		return x, y
+	}
+	pw, ph := w.parentSize()
+	xedges := []float32{0, pw}
+	yedges := []float32{0, ph}
+	if w.snapWindows && w.Parent() != nil {
+		for _, ichild := range w.Parent().Children() {
+			other, ok := ichild.(*Window)
+			if !ok || other == w || !other.Visible() {
+				continue
+			}
+			pos := other.Position()
+			xedges = append(xedges, pos.X, pos.X+other.Width())
+			yedges = append(yedges, pos.Y, pos.Y+other.Height())
+		}
+	}
+	return snapEdges(x, w.Width(), xedges, w.snapDistance), snapEdges(y, w.Height(), yedges, w.snapDistance)
+}
+
+// snapEdges returns the specified start position of a segment with the specified size
+// moved so one of its ends coincides with the nearest edge inside the snap distance.
+func snapEdges(pos, size float32, edges []float32, distance float32) float32 {
+
+	res := pos
+	for _, edge := range edges {
+		if d := math32.Abs(edge - pos); d <= distance {
+			distance = d
+			res = edge
+		}
+		if d := math32.Abs(edge - pos - size); d <= distance {
+			distance = d
+			res = edge - size
+		}
+	}
+	return res
+}
+
 // update updates the window's visual state.
 func (w *Window) update() {
 
-	if !w.Enabled() {
+	switch w.tracker.State() {
+	case StyleDisabled:
 		w.applyStyle(&w.styles.Disabled)
-		return
+	case StylePressed, StyleOver:
+		w.applyStyle(&w.styles.Over)
+	case StyleFocus:
+		w.applyStyle(&w.styles.Focus)
+	default:
+		w.applyStyle(&w.styles.Normal)
 	}
-	w.applyStyle(&w.styles.Normal)
 }
 
 // applyStyle applies a window style to the window.
@@ -314,10 +545,18 @@ type WindowTitle struct {
 	pressed            bool    // Whether the left mouse button is pressed
 	closeButton        *Button // The close button on the top right corner
 	closeButtonVisible bool    // Whether the close button is present
+	minButton          *Button // The minimize/restore button
+	minButtonVisible   bool    // Whether the minimize button is present
+	maxButton          *Button // The maximize/restore button
+	maxButtonVisible   bool    // Whether the maximize button is present
 
 	// Last mouse coordinates
 	mouseX float32
 	mouseY float32
+
+	// Position of the window being moved before snapping
+	dragX float32
+	dragY float32
 }
 
 // WindowTitleStyle contains the styling for a window title.
@@ -336,19 +575,25 @@ func newWindowTitle(win *Window, text string) *WindowTitle {
 	wt.label.initialize(text, StyleDefault().Font)
 	wt.Panel.Add(&wt.label)
 
-	wt.closeButton = NewButton("")
-	wt.closeButton.SetIcon(icon.Close)
-	wt.closeButton.Subscribe(OnCursorEnter, func(s string, i interface{}) {
-		window.Get().SetCursor(window.ArrowCursor)
-	})
-	wt.closeButton.Subscribe(OnClick, func(s string, i interface{}) {
-		wt.win.Parent().GetNode().Remove(wt.win)
-		wt.win.Dispose()
-		wt.win.Dispatch("gui.OnWindowClose", nil)
-	})
+	wt.closeButton = wt.newButton(icon.Close, func() { wt.win.Close() })
 	wt.Panel.Add(wt.closeButton)
 	wt.closeButtonVisible = true
 
+	wt.minButton = wt.newButton(icon.Remove, func() {
+		if wt.win.minimized {
+			wt.win.Restore()
+		} else {
+			wt.win.Minimize()
+		}
+	})
+	wt.maxButton = wt.newButton(icon.Fullscreen, func() {
+		if wt.win.maximized && !wt.win.minimized {
+			wt.win.Restore()
+		} else {
+			wt.win.Maximize()
+		}
+	})
+
 	wt.Subscribe(OnMouseDown, wt.onMouse)
 	wt.Subscribe(OnMouseUp, wt.onMouse)
 	wt.Subscribe(OnCursor, wt.onCursor)
@@ -359,16 +604,63 @@ func newWindowTitle(win *Window, text string) *WindowTitle {
 	return wt
 }
 
-// setCloseButton sets whether the close button is present on the top right corner.
-func (wt *WindowTitle) setCloseButton(state bool) {
+// newButton creates and returns a pointer to a new title button
+// with the specified icon which calls the specified function when clicked.
+func (wt *WindowTitle) newButton(ic string, cb func()) *Button {
 
+	b := NewButton("")
+	b.SetIcon(ic)
+	b.Subscribe(OnCursorEnter, func(s string, i interface{}) {
+		window.Get().SetCursor(window.ArrowCursor)
+	})
+	b.Subscribe(OnClick, func(s string, i interface{}) { cb() })
+	return b
+}
+
+// setButton sets whether the specified title button is present.
+func (wt *WindowTitle) setButton(b *Button, visible *bool, state bool) {
+
+	if state == *visible {
+		return
+	}
+	*visible = state
 	if state {
-		wt.closeButtonVisible = true
-		wt.Panel.Add(wt.closeButton)
+		wt.Panel.Add(b)
 	} else {
-		wt.closeButtonVisible = false
-		wt.Panel.Remove(wt.closeButton)
+		wt.Panel.Remove(b)
 	}
+	wt.recalc()
+}
+
+// updateButtons updates the icons of the minimize and maximize buttons from the window state.
+func (wt *WindowTitle) updateButtons() {
+
+	if wt.win.minimized {
+		wt.minButton.SetIcon(icon.ExpandMore)
+	} else {
+		wt.minButton.SetIcon(icon.Remove)
+	}
+	if wt.win.maximized && !wt.win.minimized {
+		wt.maxButton.SetIcon(icon.FullscreenExit)
+	} else {
+		wt.maxButton.SetIcon(icon.Fullscreen)
+	}
+}
+
+// buttonsWidth returns the total width of the buttons present in the title.
+func (wt *WindowTitle) buttonsWidth() float32 {
+
+	var width float32
+	if wt.closeButtonVisible {
+		width += wt.closeButton.Width()
+	}
+	if wt.maxButtonVisible {
+		width += wt.maxButton.Width()
+	}
+	if wt.minButtonVisible {
+		width += wt.minButton.Width()
+	}
+	return width
 }
 
 // onMouse process subscribed mouse button events over the window title.
@@ -380,6 +672,8 @@ func (wt *WindowTitle) onMouse(evname string, ev interface{}) {
 		wt.pressed = true
 		wt.mouseX = mev.Xpos
 		wt.mouseY = mev.Ypos
+		wt.dragX = wt.win.Position().X
+		wt.dragY = wt.win.Position().Y
 		Manager().SetCursorFocus(wt)
 	case OnMouseUp:
 		wt.pressed = false
@@ -396,7 +690,8 @@ func (wt *WindowTitle) onCursor(evname string, ev interface{}) {
 		window.Get().SetCursor(window.ArrowCursor)
 		wt.pressed = false
 	} else if evname == OnCursor {
-		if !wt.pressed {
+		// Maximized windows can not be moved
+		if !wt.pressed || wt.win.maximized {
 			return
 		}
 		cev := ev.(*window.CursorEvent)
@@ -404,9 +699,9 @@ func (wt *WindowTitle) onCursor(evname string, ev interface{}) {
 		dx := wt.mouseX - cev.Xpos
 		wt.mouseX = cev.Xpos
 		wt.mouseY = cev.Ypos
-		posX := wt.win.Position().X - dx
-		posY := wt.win.Position().Y - dy
-		wt.win.SetPosition(posX, posY)
+		wt.dragX -= dx
+		wt.dragY -= dy
+		wt.win.SetPosition(wt.win.snapPosition(wt.dragX, wt.dragY))
 	}
 }
 
@@ -424,7 +719,18 @@ func (wt *WindowTitle) recalc() {
 	wt.label.SetPositionX(xpos)
 	wt.SetContentHeight(wt.closeButton.Height())
 
+	// Places the visible buttons from right to left
+	xpos = wt.width
 	if wt.closeButtonVisible {
-		wt.closeButton.SetPositionX(wt.width - wt.closeButton.width)
+		xpos -= wt.closeButton.width
+		wt.closeButton.SetPositionX(xpos)
+	}
+	if wt.maxButtonVisible {
+		xpos -= wt.maxButton.width
+		wt.maxButton.SetPositionX(xpos)
+	}
+	if wt.minButtonVisible {
+		xpos -= wt.minButton.width
+		wt.minButton.SetPositionX(xpos)
 	}
 }
@@ -62,3 +62,10 @@ func (la *Ambient) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo, idx int) {
 	location := la.uni.LocationIdx(gs, int32(idx))
 	gs.Uniform3f(location, color.R, color.G, color.B)
 }
+
+// AppendBlockData appends the color of this light multiplied by its intensity
+// to the specified buffer of the AmbientLights uniform block, padded to a vec4.
+func (la *Ambient) AppendBlockData(rinfo *core.RenderInfo, buf *math32.ArrayF32) {
+
+	buf.Append(la.color.R*la.intensity, la.color.G*la.intensity, la.color.B*la.intensity, 0)
+}
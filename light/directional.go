@@ -10,6 +10,9 @@ import (
 	"github.com/g3n/engine/math32"
 )
 
+// ShadowMaxCascades is the maximum number of shadow map cascades of a directional light.
+const ShadowMaxCascades = 4
+
 // Directional represents a directional, positionless light
 type Directional struct {
 	core.Node              // Embedded node
@@ -20,6 +23,16 @@ type Directional struct {
 		color    math32.Color   // Light color
 		position math32.Vector3 // Light position
 	}
+	shadow struct { // Shadow mapping configuration
+		cast     bool      // Light casts shadows
+		mapSize  int       // Size in texels of the shadow map of each cascade
+		cascades int       // Number of shadow map cascades
+		splits   []float32 // User defined far distance of each cascade
+		lambda   float32   // Weight of the logarithmic split scheme
+		distance float32   // Maximum distance from the camera of the shadows
+		bias     float32   // Depth bias
+		blend    float32   // Fraction of each cascade blended with the next one
+	}
 }
 
 // NewDirectional creates and returns a pointer of a new directional light
@@ -33,6 +46,11 @@ func NewDirectional(color *math32.Color, intensity float32) *Directional {
 	ld.intensity = intensity
 	ld.uni.Init("DirLight")
 	ld.SetColor(color)
+	ld.shadow.mapSize = 2048
+	ld.shadow.cascades = 1
+	ld.shadow.lambda = 0.75
+	ld.shadow.bias = 0.001
+	ld.shadow.blend = 0.1
 	return ld
 }
 
@@ -64,10 +82,134 @@ func (ld *Directional) Intensity() float32 {
 	return ld.intensity
 }
 
+// SetCastShadow sets whether this light casts shadows of the graphics which cast shadows.
+func (ld *Directional) SetCastShadow(state bool) {
+
+	ld.shadow.cast = state
+}
+
+// CastShadow returns whether this light casts shadows.
+func (ld *Directional) CastShadow() bool {
+
+	return ld.shadow.cast
+}
+
+// SetShadowMapSize sets the size in texels of the square shadow map of each cascade (default = 2048).
+func (ld *Directional) SetShadowMapSize(size int) {
+
+	ld.shadow.mapSize = size
+}
+
+// ShadowMapSize returns the size in texels of the shadow map of each cascade.
+func (ld *Directional) ShadowMapSize() int {
+
+	return ld.shadow.mapSize
+}
+
+// SetShadowCascades sets the number of shadow map cascades from 1 to ShadowMaxCascades (default = 1).
+// The view frustum of the camera is split in depth into the specified number of cascades, each one
+// with its own shadow map, so near shadows are crisp and far shadows still have acceptable resolution.
+func (ld *Directional) SetShadowCascades(count int) {
+
+	ld.shadow.cascades = math32.ClampInt(count, 1, ShadowMaxCascades)
+}
+
+// ShadowCascades returns the number of shadow map cascades.
+func (ld *Directional) ShadowCascades() int {
+
+	return ld.shadow.cascades
+}
+
+// SetShadowCascadeSplits sets the far distance from the camera of each cascade.
+// If not set or if fewer distances than cascades are specified, the splits are calculated
+// using the split lambda. The last distance is limited by the shadow distance.
+func (ld *Directional) SetShadowCascadeSplits(splits ...float32) {
+
+	ld.shadow.splits = splits
+}
+
+// ShadowCascadeSplits returns the user defined far distances of the cascades.
+func (ld *Directional) ShadowCascadeSplits() []float32 {
+
+	return ld.shadow.splits
+}
+
+// SetShadowSplitLambda sets the weight from 0 to 1 of the logarithmic split scheme
+// relative to the uniform split scheme used to calculate the cascade splits (default = 0.75).
+// Higher values give more resolution to the shadows near the camera.
+func (ld *Directional) SetShadowSplitLambda(lambda float32) {
+
+	ld.shadow.lambda = math32.Clamp(lambda, 0, 1)
+}
+
+// ShadowSplitLambda returns the weight of the logarithmic split scheme.
+func (ld *Directional) ShadowSplitLambda() float32 {
+
+	return ld.shadow.lambda
+}
+
+// SetShadowDistance sets the maximum distance from the camera at which shadows are rendered.
+// Zero (the default) uses the far plane of the camera.
+func (ld *Directional) SetShadowDistance(distance float32) {
+
+	ld.shadow.distance = distance
+}
+
+// ShadowDistance returns the maximum distance from the camera at which shadows are rendered.
+func (ld *Directional) ShadowDistance() float32 {
+
+	return ld.shadow.distance
+}
+
+// SetShadowBias sets the depth bias used to avoid shadow acne (default = 0.001).
+func (ld *Directional) SetShadowBias(bias float32) {
+
+	ld.shadow.bias = bias
+}
+
+// ShadowBias returns the depth bias used to avoid shadow acne.
+func (ld *Directional) ShadowBias() float32 {
+
+	return ld.shadow.bias
+}
+
+// SetShadowCascadeBlend sets the fraction from 0 to 1 of the depth range of each cascade
+// which is blended with the next cascade to hide the transitions (default = 0.1).
+func (ld *Directional) SetShadowCascadeBlend(fraction float32) {
+
+	ld.shadow.blend = math32.Clamp(fraction, 0, 1)
+}
+
+// ShadowCascadeBlend returns the fraction of each cascade blended with the next cascade.
+func (ld *Directional) ShadowCascadeBlend() float32 {
+
+	return ld.shadow.blend
+}
+
 // RenderSetup is called by the engine before rendering the scene
 func (ld *Directional) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo, idx int) {
 
-	// Calculates light position in camera coordinates and updates uniform
+	ld.updateData(rinfo)
+
+	// Transfer uniform data
+	const vec3count = 2
+	location := ld.uni.LocationIdx(gs, vec3count*int32(idx))
+	gs.Uniform3fv(location, vec3count, &ld.udata.color.R)
+}
+
+// AppendBlockData appends the data of this light to the specified buffer
+// of the DirLights uniform block, with each vec3 padded to a vec4.
+func (ld *Directional) AppendBlockData(rinfo *core.RenderInfo, buf *math32.ArrayF32) {
+
+	ld.updateData(rinfo)
+	c := &ld.udata.color
+	p := &ld.udata.position
+	buf.Append(c.R, c.G, c.B, 0, p.X, p.Y, p.Z, 0)
+}
+
+// updateData calculates the light position in camera coordinates.
+func (ld *Directional) updateData(rinfo *core.RenderInfo) {
+
 	var pos math32.Vector3
 	ld.WorldPosition(&pos)
 	pos4 := math32.Vector4{pos.X, pos.Y, pos.Z, 0.0}
@@ -75,9 +217,4 @@ func (ld *Directional) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo, idx int)
 	ld.udata.position.X = pos4.X
 	ld.udata.position.Y = pos4.Y
 	ld.udata.position.Z = pos4.Z
-
-	// Transfer uniform data
-	const vec3count = 2
-	location := ld.uni.LocationIdx(gs, vec3count*int32(idx))
-	gs.Uniform3fv(location, vec3count, &ld.udata.color.R)
 }
@@ -0,0 +1,141 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package light
+
+import (
+	"strconv"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// FogMode specifies how the fog density increases with the distance from the camera.
+type FogMode int
+
+// The fog modes.
+const (
+	FogLinear FogMode = iota + 1 // Fog increases linearly from the near to the far distance
+	FogExp                       // Fog increases exponentially with the distance
+	FogExp2                      // Fog increases exponentially with the square of the distance
+)
+
+// Fog represents an atmospheric fog which fades the objects lit by the scene lights
+// to the fog color as their distance from the camera increases.
+// It is added to the scene like a light and only the last visible fog found in the scene is used.
+type Fog struct {
+	core.Node             // Embedded node
+	mode      FogMode     // Fog mode
+	uni       gls.Uniform // Uniform location cache
+	udata     struct {    // Combined uniform data in 2 vec3:
+		color   math32.Color // Fog color
+		near    float32      // Distance where the linear fog starts
+		far     float32      // Distance where the linear fog is total
+		density float32      // Density of the exponential fogs
+	}
+}
+
+// NewFogLinear creates and returns a pointer to a new linear fog with the specified color
+// which starts at the near distance from the camera and hides everything beyond the far distance.
+func NewFogLinear(color *math32.Color, near, far float32) *Fog {
+
+	f := newFog(FogLinear, color)
+	f.udata.near = near
+	f.udata.far = far
+	return f
+}
+
+// NewFogExp creates and returns a pointer to a new exponential fog with the specified color and density.
+func NewFogExp(color *math32.Color, density float32) *Fog {
+
+	f := newFog(FogExp, color)
+	f.udata.density = density
+	return f
+}
+
+// NewFogExp2 creates and returns a pointer to a new squared exponential fog with the specified color and density.
+func NewFogExp2(color *math32.Color, density float32) *Fog {
+
+	f := newFog(FogExp2, color)
+	f.udata.density = density
+	return f
+}
+
+// newFog creates and returns a pointer to a new fog with the specified mode and color.
+func newFog(mode FogMode, color *math32.Color) *Fog {
+
+	f := new(Fog)
+	f.Node.Init(f)
+	f.mode = mode
+	f.udata.color = *color
+	f.udata.near = 1
+	f.udata.far = 100
+	f.udata.density = 0.02
+	f.uni.Init("Fog")
+	return f
+}
+
+// SetMode sets the mode of this fog
+func (f *Fog) SetMode(mode FogMode) {
+
+	f.mode = mode
+}
+
+// Mode returns the mode of this fog
+func (f *Fog) Mode() FogMode {
+
+	return f.mode
+}
+
+// SetColor sets the color of this fog
+func (f *Fog) SetColor(color *math32.Color) {
+
+	f.udata.color = *color
+}
+
+// Color returns the color of this fog
+func (f *Fog) Color() math32.Color {
+
+	return f.udata.color
+}
+
+// SetRange sets the distances from the camera where the linear fog starts and where it is total
+func (f *Fog) SetRange(near, far float32) {
+
+	f.udata.near = near
+	f.udata.far = far
+}
+
+// Range returns the distances from the camera where the linear fog starts and where it is total
+func (f *Fog) Range() (near, far float32) {
+
+	return f.udata.near, f.udata.far
+}
+
+// SetDensity sets the density of the exponential fogs
+func (f *Fog) SetDensity(density float32) {
+
+	f.udata.density = density
+}
+
+// Density returns the density of the exponential fogs
+func (f *Fog) Density() float32 {
+
+	return f.udata.density
+}
+
+// ShaderDefine returns the value of the FOG shader define for the mode of this fog
+func (f *Fog) ShaderDefine() string {
+
+	return strconv.Itoa(int(f.mode))
+}
+
+// RenderSetup is called by the engine before rendering a graphic affected by this fog
+func (f *Fog) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+
+	const vec3count = 2
+	location := f.uni.Location(gs)
+	gs.Uniform3fv(location, vec3count, &f.udata.color.R)
+}
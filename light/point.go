@@ -97,7 +97,28 @@ func (lp *Point) QuadraticDecay() float32 {
 // RenderSetup is called by the engine before rendering the scene
 func (lp *Point) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo, idx int) {
 
-	// Calculates light position in camera coordinates and updates uniform
+	lp.updateData(rinfo)
+
+	// Transfer uniform data
+	const vec3count = 3
+	location := lp.uni.LocationIdx(gs, vec3count*int32(idx))
+	gs.Uniform3fv(location, vec3count, &lp.udata.color.R)
+}
+
+// AppendBlockData appends the data of this light to the specified buffer
+// of the PointLights uniform block, with each vec3 padded to a vec4.
+func (lp *Point) AppendBlockData(rinfo *core.RenderInfo, buf *math32.ArrayF32) {
+
+	lp.updateData(rinfo)
+	c := &lp.udata.color
+	p := &lp.udata.position
+	buf.Append(c.R, c.G, c.B, 0, p.X, p.Y, p.Z, 0)
+	buf.Append(lp.udata.linearDecay, lp.udata.quadraticDecay, 0, 0)
+}
+
+// updateData calculates the light position in camera coordinates.
+func (lp *Point) updateData(rinfo *core.RenderInfo) {
+
 	var pos math32.Vector3
 	lp.WorldPosition(&pos)
 	pos4 := math32.Vector4{pos.X, pos.Y, pos.Z, 1.0}
@@ -105,9 +126,4 @@ func (lp *Point) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo, idx int) {
 	lp.udata.position.X = pos4.X
 	lp.udata.position.Y = pos4.Y
 	lp.udata.position.Z = pos4.Z
-
-	// Transfer uniform data
-	const vec3count = 3
-	location := lp.uni.LocationIdx(gs, vec3count*int32(idx))
-	gs.Uniform3fv(location, vec3count, &lp.udata.color.R)
 }
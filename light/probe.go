@@ -0,0 +1,195 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package light
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/jpeg" // Registers the JPEG decoder
+	_ "image/png"  // Registers the PNG decoder
+	"os"
+
+	"github.com/g3n/engine/core"
+)
+
+// ProbeMode specifies when the cube map of a reflection probe is updated.
+type ProbeMode int
+
+// The reflection probe modes.
+const (
+	ProbeStatic   ProbeMode = iota // Rendered the first time the probe is found in the scene and after Refresh is called
+	ProbeRealtime                  // Rendered every frame
+	ProbeBaked                     // Uploaded from previously rendered images and never rendered
+)
+
+// ProbeFaceSuffixes are the suffixes of the image files of the cube map faces of baked reflection probes,
+// in the order of the faces: +X, -X, +Y, -Y, +Z and -Z.
+var ProbeFaceSuffixes = [6]string{"posx", "negx", "posy", "negy", "posz", "negz"}
+
+// ReflectionProbe captures the scene around its position into a cube map which is used as the
+// environment reflected by the reflective materials of the graphics nearest to it.
+// It is added to the scene like a light. Each graphic with a reflective material uses the
+// nearest probe whose radius of influence includes the position of the graphic.
+// The cube map is rendered by the renderer, once or every frame as specified by the mode,
+// or uploaded from images previously rendered and saved with the renderer.
+type ReflectionProbe struct {
+	core.Node                // Embedded node
+	mode      ProbeMode      // Update mode
+	size      int            // Size in pixels of each face of the cube map
+	near      float32        // Near distance of the cameras rendering the cube map
+	far       float32        // Far distance of the cameras rendering the cube map
+	radius    float32        // Radius of influence (0 for unlimited)
+	images    [6]*image.RGBA // Images of the cube map faces of baked probes
+	version   uint32         // Incremented when the cube map must be updated
+}
+
+// NewReflectionProbe creates and returns a pointer to a new reflection probe with the specified
+// update mode and size in pixels of the cube map faces. Its radius of influence is unlimited.
+func NewReflectionProbe(mode ProbeMode, size int) *ReflectionProbe {
+
+	p := new(ReflectionProbe)
+	p.Node.Init(p)
+	p.mode = mode
+	p.size = size
+	p.near = 0.1
+	p.far = 1000
+	return p
+}
+
+// NewReflectionProbeFromImages creates and returns a pointer to a new baked reflection probe
+// with the specified images of the cube map faces, in the order of ProbeFaceSuffixes.
+func NewReflectionProbeFromImages(images [6]image.Image) (*ReflectionProbe, error) {
+
+	p := NewReflectionProbe(ProbeBaked, 0)
+	err := p.SetImages(images)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// NewReflectionProbeFromFiles creates and returns a pointer to a new baked reflection probe with the
+// cube map faces read from the image files named with the specified prefix, ProbeFaceSuffixes and extension.
+// The supported image files are PNG and JPEG.
+func NewReflectionProbeFromFiles(dirAndPrefix, extension string) (*ReflectionProbe, error) {
+
+	var images [6]image.Image
+	for i, suffix := range ProbeFaceSuffixes {
+		f, err := os.Open(dirAndPrefix + suffix + "." + extension)
+		if err != nil {
+			return nil, err
+		}
+		images[i], _, err = image.Decode(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return NewReflectionProbeFromImages(images)
+}
+
+// SetImages sets the images of the cube map faces, in the order of ProbeFaceSuffixes,
+// and changes the probe to baked. The images must be square and have the same size.
+func (p *ReflectionProbe) SetImages(images [6]image.Image) error {
+
+	size := images[0].Bounds().Dx()
+	var rgbas [6]*image.RGBA
+	for i, img := range images {
+		bounds := img.Bounds()
+		if bounds.Dx() != size || bounds.Dy() != size {
+			return fmt.Errorf("reflection probe face %s is not %dx%d", ProbeFaceSuffixes[i], size, size)
+		}
+		rgba := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+		rgbas[i] = rgba
+	}
+	p.images = rgbas
+	p.size = size
+	p.mode = ProbeBaked
+	p.version++
+	return nil
+}
+
+// Images returns the images of the cube map faces of a baked probe.
+func (p *ReflectionProbe) Images() [6]*image.RGBA {
+
+	return p.images
+}
+
+// SetMode sets the update mode of the cube map.
+func (p *ReflectionProbe) SetMode(mode ProbeMode) {
+
+	p.mode = mode
+	p.version++
+}
+
+// Mode returns the update mode of the cube map.
+func (p *ReflectionProbe) Mode() ProbeMode {
+
+	return p.mode
+}
+
+// SetSize sets the size in pixels of each face of the cube map.
+func (p *ReflectionProbe) SetSize(size int) {
+
+	p.size = size
+	p.version++
+}
+
+// Size returns the size in pixels of each face of the cube map.
+func (p *ReflectionProbe) Size() int {
+
+	return p.size
+}
+
+// SetNear sets the distance from the probe from which the scene is rendered. The default is 0.1.
+func (p *ReflectionProbe) SetNear(near float32) {
+
+	p.near = near
+}
+
+// Near returns the distance from the probe from which the scene is rendered.
+func (p *ReflectionProbe) Near() float32 {
+
+	return p.near
+}
+
+// SetFar sets the distance from the probe up to which the scene is rendered. The default is 1000.
+func (p *ReflectionProbe) SetFar(far float32) {
+
+	p.far = far
+}
+
+// Far returns the distance from the probe up to which the scene is rendered.
+func (p *ReflectionProbe) Far() float32 {
+
+	return p.far
+}
+
+// SetRadius sets the distance from the probe within which the graphics use it.
+// The default is 0, for an unlimited radius.
+func (p *ReflectionProbe) SetRadius(radius float32) {
+
+	p.radius = radius
+}
+
+// Radius returns the distance from the probe within which the graphics use it.
+func (p *ReflectionProbe) Radius() float32 {
+
+	return p.radius
+}
+
+// Refresh requests a static probe to be rendered again in the next frame, as after the scene around it changed.
+func (p *ReflectionProbe) Refresh() {
+
+	p.version++
+}
+
+// Version returns a number which changes when the cube map must be updated.
+func (p *ReflectionProbe) Version() uint32 {
+
+	return p.version
+}
@@ -124,7 +124,31 @@ func (l *Spot) QuadraticDecay() float32 {
 // RenderSetup is called by the engine before rendering the scene
 func (l *Spot) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo, idx int) {
 
-	// Calculates and updates light position uniform in camera coordinates
+	l.updateData(rinfo)
+
+	// Transfer uniform data
+	const vec3count = 5
+	location := l.uni.LocationIdx(gs, vec3count*int32(idx))
+	gs.Uniform3fv(location, vec3count, &l.udata.color.R)
+}
+
+// AppendBlockData appends the data of this light to the specified buffer
+// of the SpotLights uniform block, with each vec3 padded to a vec4.
+func (l *Spot) AppendBlockData(rinfo *core.RenderInfo, buf *math32.ArrayF32) {
+
+	l.updateData(rinfo)
+	c := &l.udata.color
+	p := &l.udata.position
+	d := &l.udata.direction
+	buf.Append(c.R, c.G, c.B, 0, p.X, p.Y, p.Z, 0, d.X, d.Y, d.Z, 0)
+	buf.Append(l.udata.angularDecay, l.udata.cutoffAngle, l.udata.linearDecay, 0)
+	buf.Append(l.udata.quadraticDecay, 0, 0, 0)
+}
+
+// updateData calculates the light position and direction in camera coordinates.
+func (l *Spot) updateData(rinfo *core.RenderInfo) {
+
+	// Calculates light position in camera coordinates
 	var pos math32.Vector3
 	l.WorldPosition(&pos)
 	var pos4 math32.Vector4
@@ -134,7 +158,7 @@ func (l *Spot) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo, idx int) {
 	l.udata.position.Y = pos4.Y
 	l.udata.position.Z = pos4.Z
 
-	// Calculates and updates light direction uniform in camera coordinates
+	// Calculates light direction in camera coordinates
 	var dir math32.Vector3
 	l.WorldDirection(&dir)
 	pos4.SetVector3(&dir, 0.0)
@@ -142,9 +166,4 @@ func (l *Spot) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo, idx int) {
 	l.udata.direction.X = pos4.X
 	l.udata.direction.Y = pos4.Y
 	l.udata.direction.Z = pos4.Z
-
-	// Transfer uniform data
-	const vec3count = 5
-	location := l.uni.LocationIdx(gs, vec3count*int32(idx))
-	gs.Uniform3fv(location, vec3count, &l.udata.color.R)
 }
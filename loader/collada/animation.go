@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/g3n/engine/animation"
 	"github.com/g3n/engine/core"
 	"github.com/g3n/engine/math32"
 )
@@ -111,7 +112,7 @@ func (d *Decoder) NewAnimationTargets(scene core.INode) (map[string]*AnimationTa
 	targetsMap := make(map[string]*AnimationTarget)
 
 	// For each Collada animation element
-	for _, ca := range d.dom.LibraryAnimations.Animation {
+	for _, ca := range allAnimations(d.dom.LibraryAnimations.Animation) {
 
 		// For each Collada channel for this animation
 		for _, cc := range ca.Channel {
@@ -197,6 +198,106 @@ func (d *Decoder) NewAnimationTargets(scene core.INode) (map[string]*AnimationTa
 	return targetsMap, nil
 }
 
+// HasAnimations returns whether the decoded file has animations.
+func (d *Decoder) HasAnimations() bool {
+
+	return d.dom.LibraryAnimations != nil
+}
+
+// NewAnimation creates and returns an animation with channels for all the animated nodes
+// of the specified scene previously created by NewScene, as the animations of the glTF loader.
+// It supports the channels which target the transformation matrix of the nodes,
+// as exported for skeletal animations, and the channels which target their translation
+// or scale vectors. Channels of single components and rotation angles are only
+// supported by NewAnimationTargets.
+func (d *Decoder) NewAnimation(scene core.INode) (*animation.Animation, error) {
+
+	if d.dom.LibraryAnimations == nil {
+		return nil, fmt.Errorf("No animations found")
+	}
+
+	anim := animation.NewAnimation()
+	anim.SetName(d.dom.LibraryAnimations.Name)
+	for _, ca := range allAnimations(d.dom.LibraryAnimations.Animation) {
+		for _, cc := range ca.Channel {
+
+			// Get the channel target node and parameter
+			parts := strings.Split(cc.Target, "/")
+			if len(parts) < 2 {
+				return nil, fmt.Errorf("Channel target invalid")
+			}
+			target := scene.GetNode().FindLoaderID(parts[0])
+			if target == nil {
+				return nil, fmt.Errorf("Target node id:%s not found", parts[0])
+			}
+
+			si, err := NewSamplerInstance(ca, cc.Source)
+			if err != nil {
+				return nil, err
+			}
+			count := len(si.Input)
+			if count == 0 {
+				return nil, fmt.Errorf("Sampler:%s has no key frames", cc.Source)
+			}
+			keyframes := math32.ArrayF32(si.Input)
+			interp := animation.LINEAR
+			if len(si.Interp) > 0 && si.Interp[0] == "STEP" {
+				interp = animation.STEP
+			}
+
+			switch {
+			// Transformation matrices are decomposed in position, rotation and scale channels
+			case len(si.Output) == 16*count:
+				positions := math32.NewArrayF32(0, 3*count)
+				rotations := math32.NewArrayF32(0, 4*count)
+				scales := math32.NewArrayF32(0, 3*count)
+				for i := 0; i < count; i++ {
+					var m math32.Matrix4
+					m.FromArray(si.Output, i*16)
+					m.Transpose()
+					var position math32.Vector3
+					var quaternion math32.Quaternion
+					var scale math32.Vector3
+					m.Decompose(&position, &quaternion, &scale)
+					positions.AppendVector3(&position)
+					rotations.Append(quaternion.X, quaternion.Y, quaternion.Z, quaternion.W)
+					scales.AppendVector3(&scale)
+				}
+				addChannel(anim, animation.NewPositionChannel(target), keyframes, positions, interp)
+				addChannel(anim, animation.NewRotationChannel(target), keyframes, rotations, interp)
+				addChannel(anim, animation.NewScaleChannel(target), keyframes, scales, interp)
+			case len(si.Output) == 3*count && strings.HasPrefix(parts[1], "scale"):
+				addChannel(anim, animation.NewScaleChannel(target), keyframes, si.Output, interp)
+			case len(si.Output) == 3*count:
+				addChannel(anim, animation.NewPositionChannel(target), keyframes, si.Output, interp)
+			default:
+				return nil, fmt.Errorf("Unsupported channel target:%s", cc.Target)
+			}
+		}
+	}
+	return anim, nil
+}
+
+// addChannel sets the buffers and interpolation type of the specified channel
+// and adds it to the specified animation.
+func addChannel(anim *animation.Animation, ch animation.IChannel, keyframes, values math32.ArrayF32, interp animation.InterpolationType) {
+
+	ch.SetBuffers(keyframes, values)
+	ch.SetInterpolationType(interp)
+	anim.AddChannel(ch)
+}
+
+// allAnimations returns the specified animations followed by all their nested animations.
+func allAnimations(anims []*Animation) []*Animation {
+
+	var all []*Animation
+	for _, ca := range anims {
+		all = append(all, ca)
+		all = append(all, allAnimations(ca.Animation)...)
+	}
+	return all
+}
+
 func actionPositionX(at *AnimationTarget, v float32) {
 
 	at.target.GetNode().SetPositionX(v)
@@ -11,6 +11,7 @@ import (
 	"io"
 	"os"
 
+	"github.com/g3n/engine/core"
 	"github.com/g3n/engine/geometry"
 	"github.com/g3n/engine/material"
 	"github.com/g3n/engine/texture"
@@ -25,6 +26,8 @@ type Decoder struct {
 	geometries map[string]geomInstance       // Instanced geometries by id
 	materials  map[string]material.IMaterial // Instanced materials by id
 	tex2D      map[string]*texture.Texture2D // Instanced textures 2D by id
+	sids       map[*core.Node]string         // Scoped ids of the scene nodes which have one
+	skins      []skinInstance                // Rigged meshes of the scene to bind to their skeletons
 }
 
 type geomInstance struct {
@@ -78,6 +81,7 @@ type Collada struct {
 	LibraryEffects      *LibraryEffects
 	LibraryMaterials    *LibraryMaterials
 	LibraryGeometries   *LibraryGeometries
+	LibraryControllers  *LibraryControllers
 	LibraryVisualScenes *LibraryVisualScenes
 	Scene               *Scene
 }
@@ -96,6 +100,7 @@ func (d *Decoder) Dump(out io.Writer, indent int) {
 	d.dom.LibraryEffects.Dump(out, indent+step)
 	d.dom.LibraryMaterials.Dump(out, indent+step)
 	d.dom.LibraryGeometries.Dump(out, indent+step)
+	d.dom.LibraryControllers.Dump(out, indent+step)
 	d.dom.LibraryVisualScenes.Dump(out, indent+step)
 	d.dom.Scene.Dump(out, indent+step)
 }
@@ -273,6 +278,13 @@ func (d *Decoder) decCollada(dom *Collada) error {
 			}
 			continue
 		}
+		if start.Name.Local == "library_controllers" {
+			err = d.decLibraryControllers(start, dom)
+			if err != nil {
+				break
+			}
+			continue
+		}
 		if start.Name.Local == "library_visual_scenes" {
 			err = d.decLibraryVisualScenes(start, dom)
 			if err != nil {
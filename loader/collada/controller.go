@@ -0,0 +1,293 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collada
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+)
+
+// skinInstance associates a rigged mesh created for a controller instance
+// with its skin, to bind it to its skeleton after all the scene nodes are created.
+type skinInstance struct {
+	rm   *graphic.RiggedMesh
+	skin *Skin
+	ic   *InstanceController
+}
+
+// skinInfluence is the weight of a joint which influences a vertex
+type skinInfluence struct {
+	joint  float32
+	weight float32
+}
+
+// newRiggedMesh creates and returns a rigged mesh for the specified skin controller instance.
+// Its skeleton is set by bindSkeleton when all the joint nodes were created.
+func (d *Decoder) newRiggedMesh(ic *InstanceController) (*graphic.RiggedMesh, error) {
+
+	skin, err := d.findSkin(ic.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creates a new geometry which is not shared with the instances
+	// of the geometry as it contains the skin attributes
+	var vindex []int
+	geomi, gtype, err := d.newGeometry(skin.Geometry, &vindex)
+	if err != nil {
+		return nil, err
+	}
+	if gtype != gls.TRIANGLES {
+		return nil, fmt.Errorf("skinned primitive not supported")
+	}
+	geom := geomi.GetGeometry()
+	err = d.addSkinAttributes(geom, skin, vindex)
+	if err != nil {
+		return nil, err
+	}
+
+	// Applies the bind shape matrix to the geometry transposing it to a column matrix
+	var bsm math32.Matrix4
+	bsm.FromArray(skin.BindShapeMatrix[:], 0)
+	bsm.Transpose()
+	geom.ApplyMatrix(&bsm)
+
+	mesh, err := d.newTrianglesMesh(geomi, ic.BindMaterial)
+	if err != nil {
+		return nil, err
+	}
+	rm := graphic.NewRiggedMesh(mesh)
+	d.skins = append(d.skins, skinInstance{rm, skin, ic})
+	return rm, nil
+}
+
+// addSkinAttributes adds to the specified geometry the VBOs with the indices and
+// weights of the joints which influence each vertex, whose indices in the mesh
+// <vertices> are specified by vindex. Only the joints with greater weights
+// up to graphic.MaxBoneInfluencers are kept and their weights normalized.
+func (d *Decoder) addSkinAttributes(geom *geometry.Geometry, skin *Skin, vindex []int) error {
+
+	// Get the JOINT and WEIGHT inputs of the vertex weights
+	vw := &skin.VertexWeights
+	jointOffset := -1
+	weightOffset := -1
+	stride := 0
+	var weights []float32
+	var err error
+	for _, inp := range vw.Input {
+		if inp.Offset >= stride {
+			stride = inp.Offset + 1
+		}
+		switch inp.Semantic {
+		case "JOINT":
+			jointOffset = inp.Offset
+		case "WEIGHT":
+			weightOffset = inp.Offset
+			weights, err = findSkinFloatArray(skin, inp.Source)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if jointOffset < 0 || weightOffset < 0 {
+		return fmt.Errorf("vertex_weights JOINT or WEIGHT input not found")
+	}
+
+	// Get the joint influences of each vertex of the mesh <vertices>
+	// sorted by decreasing weight
+	influences := make([][graphic.MaxBoneInfluencers]skinInfluence, len(vw.Vcount))
+	v := 0
+	for i, count := range vw.Vcount {
+		inf := &influences[i]
+		for j := 0; j < count; j++ {
+			if v+stride > len(vw.V) {
+				return fmt.Errorf("vertex_weights V too short")
+			}
+			joint := vw.V[v+jointOffset]
+			windex := vw.V[v+weightOffset]
+			v += stride
+			if windex < 0 || windex >= len(weights) {
+				return fmt.Errorf("vertex_weights weight index:%d invalid", windex)
+			}
+			// Negative joint indices refer to the bind shape
+			if joint < 0 {
+				continue
+			}
+			weight := weights[windex]
+			for k := range inf {
+				if weight > inf[k].weight {
+					copy(inf[k+1:], inf[k:len(inf)-1])
+					inf[k] = skinInfluence{float32(joint), weight}
+					break
+				}
+			}
+		}
+	}
+
+	// Creates the buffers with the joints and weights of each geometry vertex
+	joints := math32.NewArrayF32(0, len(vindex)*graphic.MaxBoneInfluencers)
+	jweights := math32.NewArrayF32(0, len(vindex)*graphic.MaxBoneInfluencers)
+	for _, idx := range vindex {
+		var inf [graphic.MaxBoneInfluencers]skinInfluence
+		if idx >= 0 && idx < len(influences) {
+			inf = influences[idx]
+		}
+		var sum float32
+		for _, in := range inf {
+			sum += in.weight
+		}
+		for _, in := range inf {
+			joints.Append(in.joint)
+			if sum > 0 {
+				jweights.Append(in.weight / sum)
+			} else {
+				jweights.Append(0)
+			}
+		}
+	}
+	geom.AddVBO(gls.NewVBO(joints).AddAttrib(gls.SkinIndex))
+	geom.AddVBO(gls.NewVBO(jweights).AddAttrib(gls.SkinWeight))
+	return nil
+}
+
+// bindSkeleton creates the skeleton of the specified skin instance
+// from the joint nodes of the scene and sets it in its rigged mesh.
+func (d *Decoder) bindSkeleton(scene core.INode, si skinInstance) error {
+
+	// Get the joint names and their inverse bind matrices
+	var names []string
+	var ibms []float32
+	var err error
+	for _, inp := range si.skin.Joints.Input {
+		switch inp.Semantic {
+		case "JOINT":
+			names, err = findSkinNameArray(si.skin, inp.Source)
+		case "INV_BIND_MATRIX":
+			ibms, err = findSkinFloatArray(si.skin, inp.Source)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if names == nil {
+		return fmt.Errorf("skin JOINT input not found")
+	}
+	if ibms != nil && len(ibms) < 16*len(names) {
+		return fmt.Errorf("skin INV_BIND_MATRIX source too short")
+	}
+
+	// Get the nodes where to start the search for the joints
+	var roots []*core.Node
+	for _, url := range si.ic.Skeleton {
+		root := scene.GetNode().FindLoaderID(strings.TrimPrefix(url, "#"))
+		if root != nil {
+			roots = append(roots, root.GetNode())
+		}
+	}
+	if len(roots) == 0 {
+		roots = append(roots, scene.GetNode())
+	}
+
+	skeleton := graphic.NewSkeleton()
+	for i, name := range names {
+		var joint *core.Node
+		for _, root := range roots {
+			joint = d.findJoint(root, name)
+			if joint != nil {
+				break
+			}
+		}
+		if joint == nil {
+			return fmt.Errorf("Joint:%s not found", name)
+		}
+		// Get the inverse bind matrix transposing it to a column matrix
+		var ibm *math32.Matrix4
+		if ibms != nil {
+			ibm = new(math32.Matrix4)
+			ibm.FromArray(ibms, i*16)
+			ibm.Transpose()
+		}
+		skeleton.AddBone(joint, ibm)
+	}
+	si.rm.SetSkeleton(skeleton)
+	return nil
+}
+
+// findJoint returns the specified node or its first descendant whose scoped id
+// or id is equal to the specified joint name or nil if not found.
+func (d *Decoder) findJoint(n *core.Node, name string) *core.Node {
+
+	if d.sids[n] == name || n.LoaderID() == name {
+		return n
+	}
+	for _, child := range n.Children() {
+		joint := d.findJoint(child.GetNode(), name)
+		if joint != nil {
+			return joint
+		}
+	}
+	return nil
+}
+
+// findSkin returns the skin of the controller with the specified URL.
+func (d *Decoder) findSkin(uri string) (*Skin, error) {
+
+	id := strings.TrimPrefix(uri, "#")
+	if d.dom.LibraryControllers != nil {
+		for _, c := range d.dom.LibraryControllers.Controller {
+			if c.Id != id {
+				continue
+			}
+			if c.Skin == nil {
+				return nil, fmt.Errorf("Controller:%s is not a skin", id)
+			}
+			return c.Skin, nil
+		}
+	}
+	return nil, fmt.Errorf("Controller:%s not found", id)
+}
+
+func findSkinNameArray(skin *Skin, uri string) ([]string, error) {
+
+	src := findSkinSource(skin, uri)
+	if src == nil {
+		return nil, fmt.Errorf("Source:%s not found", uri)
+	}
+	na, ok := src.ArrayElement.(*NameArray)
+	if !ok {
+		return nil, fmt.Errorf("Source:%s is not NameArray", uri)
+	}
+	return na.Data, nil
+}
+
+func findSkinFloatArray(skin *Skin, uri string) ([]float32, error) {
+
+	src := findSkinSource(skin, uri)
+	if src == nil {
+		return nil, fmt.Errorf("Source:%s not found", uri)
+	}
+	fa, ok := src.ArrayElement.(*FloatArray)
+	if !ok {
+		return nil, fmt.Errorf("Source:%s is not FloatArray", uri)
+	}
+	return fa.Data, nil
+}
+
+func findSkinSource(skin *Skin, uri string) *Source {
+
+	id := strings.TrimPrefix(uri, "#")
+	for _, src := range skin.Source {
+		if src.Id == id {
+			return src
+		}
+	}
+	return nil
+}
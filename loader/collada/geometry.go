@@ -40,6 +40,14 @@ func (d *Decoder) GetGeometry(id string) (geometry.IGeometry, uint32, error) {
 // with the specified id in the Collada document, its primitive type and and error.
 func (d *Decoder) NewGeometry(id string) (geometry.IGeometry, uint32, error) {
 
+	return d.newGeometry(id, nil)
+}
+
+// newGeometry creates a new instance of the geometry with the specified id as NewGeometry.
+// If vindex is not nil, it is set with the index in the mesh <vertices> of each vertex
+// of the created triangles geometry and the vertices with different indices are not shared.
+func (d *Decoder) newGeometry(id string, vindex *[]int) (geometry.IGeometry, uint32, error) {
+
 	id = strings.TrimPrefix(id, "#")
 	// Look for geometry with specified id in the dom
 	var geo *Geometry
@@ -58,7 +66,7 @@ func (d *Decoder) NewGeometry(id string) (geometry.IGeometry, uint32, error) {
 	// Collada mesh category includes points, lines, linestrips, triangles,
 	// triangle fans, triangle strips and polygons.
 	case *Mesh:
-		return newMesh(gt)
+		return newMesh(gt, vindex)
 		// B-Spline
 		// Bezier
 		// NURBS
@@ -68,7 +76,7 @@ func (d *Decoder) NewGeometry(id string) (geometry.IGeometry, uint32, error) {
 	}
 }
 
-func newMesh(m *Mesh) (*geometry.Geometry, uint32, error) {
+func newMesh(m *Mesh, vindex *[]int) (*geometry.Geometry, uint32, error) {
 
 	// If no primitive elements present, it is a mesh of points
 	if len(m.PrimitiveElements) == 0 {
@@ -91,9 +99,9 @@ func newMesh(m *Mesh) (*geometry.Geometry, uint32, error) {
 	pei := m.PrimitiveElements[0]
 	switch pet := pei.(type) {
 	case *Polylist:
-		return newMeshPolylist(m, m.PrimitiveElements)
+		return newMeshPolylist(m, m.PrimitiveElements, vindex)
 	case *Triangles:
-		return newMeshTriangles(m, pet)
+		return newMeshTriangles(m, pet, vindex)
 	case *Lines:
 		return newMeshLines(m, pet)
 	case *LineStrips:
@@ -107,9 +115,15 @@ func newMesh(m *Mesh) (*geometry.Geometry, uint32, error) {
 	}
 }
 
+// meshVertex is the key of the map used to reuse the vertices with the same attributes
+type meshVertex struct {
+	attribs [8]float32 // position(3) + normal(3) + uv(2)
+	vindex  int        // index in the mesh <vertices> if kept or -1
+}
+
 // Creates a geometry from a polylist
 // Only triangles are supported
-func newMeshPolylist(m *Mesh, pels []interface{}) (*geometry.Geometry, uint32, error) {
+func newMeshPolylist(m *Mesh, pels []interface{}, vindex *[]int) (*geometry.Geometry, uint32, error) {
 
 	// Get vertices positions
 	if len(m.Vertices.Input) != 1 {
@@ -140,7 +154,7 @@ func newMeshPolylist(m *Mesh, pels []interface{}) (*geometry.Geometry, uint32, e
 	indices := math32.NewArrayU32(0, 0)
 
 	// Creates vertices attributes map for reusing indices
-	mVindex := make(map[[8]float32]uint32)
+	mVindex := make(map[meshVertex]uint32)
 	var index uint32
 	geomGroups := make([]geometry.Group, 0)
 	groupMatindex := 0
@@ -236,13 +250,20 @@ func newMeshPolylist(m *Mesh, pels []interface{}) (*geometry.Geometry, uint32, e
 			// If this vertex and its attributes has already been appended,
 			// reuse it, adding its index to the index buffer
 			// to reuse its index
-			idx, ok := mVindex[vx]
+			key := meshVertex{vx, -1}
+			if vindex != nil {
+				key.vindex = pl.P[i+inpVertex.Offset]
+			}
+			idx, ok := mVindex[key]
 			if ok {
 				indices.Append(idx)
 				continue
 			}
 			// Appends new vertex position and attributes to its buffers
 			positions.Append(vx[0], vx[1], vx[2])
+			if vindex != nil {
+				*vindex = append(*vindex, key.vindex)
+			}
 			if inpNormal != nil {
 				normals.Append(vx[3], vx[4], vx[5])
 			}
@@ -252,7 +273,7 @@ func newMeshPolylist(m *Mesh, pels []interface{}) (*geometry.Geometry, uint32, e
 			indices.Append(index)
 			// Save the index to this vertex position and attributes for
 			// future reuse
-			mVindex[vx] = index
+			mVindex[key] = index
 			index++
 		}
 		// Adds this geometry group to the list
@@ -307,7 +328,7 @@ func newMeshPolylist(m *Mesh, pels []interface{}) (*geometry.Geometry, uint32, e
 	return geom, gls.TRIANGLES, nil
 }
 
-func newMeshTriangles(m *Mesh, tr *Triangles) (*geometry.Geometry, uint32, error) {
+func newMeshTriangles(m *Mesh, tr *Triangles, vindex *[]int) (*geometry.Geometry, uint32, error) {
 
 	// Get vertices positions
 	if len(m.Vertices.Input) != 1 {
@@ -338,7 +359,7 @@ func newMeshTriangles(m *Mesh, tr *Triangles) (*geometry.Geometry, uint32, error
 	indices := math32.NewArrayU32(0, 0)
 
 	// Creates vertices attributes map for reusing indices
-	mVindex := make(map[[8]float32]uint32)
+	mVindex := make(map[meshVertex]uint32)
 	var index uint32
 	geomGroups := make([]geometry.Group, 0)
 	groupMatindex := 0
@@ -418,13 +439,20 @@ func newMeshTriangles(m *Mesh, tr *Triangles) (*geometry.Geometry, uint32, error
 		// If this vertex and its attributes has already been appended,
 		// reuse it, adding its index to the index buffer
 		// to reuse its index
-		idx, ok := mVindex[vx]
+		key := meshVertex{vx, -1}
+		if vindex != nil {
+			key.vindex = tr.P[i+inpVertex.Offset]
+		}
+		idx, ok := mVindex[key]
 		if ok {
 			indices.Append(idx)
 			continue
 		}
 		// Appends new vertex position and attributes to its buffers
 		positions.Append(vx[0], vx[1], vx[2])
+		if vindex != nil {
+			*vindex = append(*vindex, key.vindex)
+		}
 		if inpNormal != nil {
 			normals.Append(vx[3], vx[4], vx[5])
 		}
@@ -434,7 +462,7 @@ func newMeshTriangles(m *Mesh, tr *Triangles) (*geometry.Geometry, uint32, error
 		indices.Append(index)
 		// Save the index to this vertex position and attributes for
 		// future reuse
-		mVindex[vx] = index
+		mVindex[key] = index
 		index++
 	}
 	// Adds this geometry group to the list
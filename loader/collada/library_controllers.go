@@ -0,0 +1,239 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collada
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// LibraryControllers
+type LibraryControllers struct {
+	Id         string
+	Name       string
+	Asset      *Asset
+	Controller []*Controller
+}
+
+// Dump prints out information about the LibraryControllers
+func (lc *LibraryControllers) Dump(out io.Writer, indent int) {
+
+	if lc == nil {
+		return
+	}
+	fmt.Fprintf(out, "%sLibraryControllers id:%s name:%s\n", sIndent(indent), lc.Id, lc.Name)
+	for _, c := range lc.Controller {
+		c.Dump(out, indent+step)
+	}
+}
+
+// Controller
+type Controller struct {
+	Id   string
+	Name string
+	Skin *Skin // Only skin controllers are supported
+}
+
+// Dump prints out information about the Controller
+func (c *Controller) Dump(out io.Writer, indent int) {
+
+	fmt.Fprintf(out, "%sController id:%s name:%s\n", sIndent(indent), c.Id, c.Name)
+	if c.Skin != nil {
+		c.Skin.Dump(out, indent+step)
+	}
+}
+
+// Skin
+type Skin struct {
+	Geometry        string      // URL of the skinned geometry (source attribute)
+	BindShapeMatrix [16]float32 // Bind shape matrix in row major order
+	Source          []*Source   // Joints, inverse bind matrices and weights sources
+	Joints          struct {
+		Input []Input
+	}
+	VertexWeights VertexWeights
+}
+
+// Dump prints out information about the Skin
+func (s *Skin) Dump(out io.Writer, indent int) {
+
+	fmt.Fprintf(out, "%sSkin source:%s\n", sIndent(indent), s.Geometry)
+	ind := indent + step
+	fmt.Fprintf(out, "%sBindShapeMatrix:%v\n", sIndent(ind), s.BindShapeMatrix)
+	for _, src := range s.Source {
+		src.Dump(out, ind)
+	}
+	fmt.Fprintf(out, "%sJoints\n", sIndent(ind))
+	for _, inp := range s.Joints.Input {
+		inp.Dump(out, ind+step)
+	}
+	s.VertexWeights.Dump(out, ind)
+}
+
+// VertexWeights
+type VertexWeights struct {
+	Count  int
+	Input  []InputShared
+	Vcount []int
+	V      []int
+}
+
+// Dump prints out information about the VertexWeights
+func (vw *VertexWeights) Dump(out io.Writer, indent int) {
+
+	fmt.Fprintf(out, "%sVertexWeights count:%d\n", sIndent(indent), vw.Count)
+	ind := indent + step
+	for _, is := range vw.Input {
+		is.Dump(out, ind)
+	}
+	fmt.Fprintf(out, "%sVcount(%d):%v\n", sIndent(ind), len(vw.Vcount), intsToString(vw.Vcount, 20))
+	fmt.Fprintf(out, "%sV(%d):%v\n", sIndent(ind), len(vw.V), intsToString(vw.V, 20))
+}
+
+func (d *Decoder) decLibraryControllers(start xml.StartElement, dom *Collada) error {
+
+	lc := new(LibraryControllers)
+	dom.LibraryControllers = lc
+	lc.Id = findAttrib(start, "id").Value
+	lc.Name = findAttrib(start, "name").Value
+
+	for {
+		child, _, err := d.decNextChild(start)
+		if err != nil || child.Name.Local == "" {
+			return err
+		}
+		if child.Name.Local == "controller" {
+			err := d.decController(child, lc)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+	}
+}
+
+func (d *Decoder) decController(start xml.StartElement, lc *LibraryControllers) error {
+
+	c := new(Controller)
+	lc.Controller = append(lc.Controller, c)
+	c.Id = findAttrib(start, "id").Value
+	c.Name = findAttrib(start, "name").Value
+
+	for {
+		child, _, err := d.decNextChild(start)
+		if err != nil || child.Name.Local == "" {
+			return err
+		}
+		if child.Name.Local == "skin" {
+			err := d.decSkin(child, c)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+	}
+}
+
+func (d *Decoder) decSkin(start xml.StartElement, c *Controller) error {
+
+	s := new(Skin)
+	c.Skin = s
+	s.Geometry = findAttrib(start, "source").Value
+	// Identity bind shape matrix if not specified
+	s.BindShapeMatrix = [16]float32{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1}
+
+	for {
+		child, data, err := d.decNextChild(start)
+		if err != nil || child.Name.Local == "" {
+			return err
+		}
+		if child.Name.Local == "bind_shape_matrix" {
+			err := decFloat32Sequence(data, s.BindShapeMatrix[:])
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if child.Name.Local == "source" {
+			source, err := d.decSource(child)
+			if err != nil {
+				return err
+			}
+			s.Source = append(s.Source, source)
+			continue
+		}
+		if child.Name.Local == "joints" {
+			err := d.decSkinJoints(child, s)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if child.Name.Local == "vertex_weights" {
+			err := d.decVertexWeights(child, s)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+	}
+}
+
+func (d *Decoder) decSkinJoints(start xml.StartElement, s *Skin) error {
+
+	for {
+		child, _, err := d.decNextChild(start)
+		if err != nil || child.Name.Local == "" {
+			return err
+		}
+		if child.Name.Local == "input" {
+			inp, err := d.decInput(child)
+			if err != nil {
+				return err
+			}
+			s.Joints.Input = append(s.Joints.Input, inp)
+			continue
+		}
+	}
+}
+
+func (d *Decoder) decVertexWeights(start xml.StartElement, s *Skin) error {
+
+	vw := &s.VertexWeights
+	vw.Count, _ = strconv.Atoi(findAttrib(start, "count").Value)
+
+	for {
+		child, data, err := d.decNextChild(start)
+		if err != nil || child.Name.Local == "" {
+			return err
+		}
+		if child.Name.Local == "input" {
+			inp, err := d.decInputShared(child)
+			if err != nil {
+				return err
+			}
+			vw.Input = append(vw.Input, inp)
+			continue
+		}
+		if child.Name.Local == "vcount" {
+			vc, err := d.decVcount(child, data, vw.Count)
+			if err != nil {
+				return err
+			}
+			vw.Vcount = vc
+			continue
+		}
+		if child.Name.Local == "v" {
+			v, err := d.decPrimitive(child, data)
+			if err != nil {
+				return err
+			}
+			vw.V = v
+			continue
+		}
+	}
+}
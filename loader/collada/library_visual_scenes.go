@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 )
 
 //
@@ -89,6 +90,8 @@ func (n *Node) Dump(out io.Writer, indent int) {
 	switch it := n.Instance.(type) {
 	case *InstanceGeometry:
 		it.Dump(out, indent+step)
+	case *InstanceController:
+		it.Dump(out, indent+step)
 	}
 	// Dump node children
 	for _, n := range n.Node {
@@ -170,6 +173,25 @@ func (ig *InstanceGeometry) Dump(out io.Writer, indent int) {
 	}
 }
 
+//
+// InstanceController
+//
+type InstanceController struct {
+	Url          string   // Controller URL (required) references the ID of a Controller
+	Name         string   // name of this element (optional)
+	Skeleton     []string // URLs of the nodes where to start the search for the joints
+	BindMaterial *BindMaterial
+}
+
+// Dump prints out information about the InstanceController
+func (ic *InstanceController) Dump(out io.Writer, indent int) {
+
+	fmt.Fprintf(out, "%sInstanceController url:%s name:%s skeleton:%v\n", sIndent(indent), ic.Url, ic.Name, ic.Skeleton)
+	if ic.BindMaterial != nil {
+		ic.BindMaterial.Dump(out, indent+step)
+	}
+}
+
 //
 // BindMaterial
 //
@@ -292,7 +314,7 @@ func (d *Decoder) decNode(nodeStart xml.StartElement, parent *[]*Node) error {
 	n := &Node{}
 	n.Id = findAttrib(nodeStart, "id").Value
 	n.Name = findAttrib(nodeStart, "name").Value
-	n.Sid = findAttrib(nodeStart, "name").Value
+	n.Sid = findAttrib(nodeStart, "sid").Value
 	n.Type = findAttrib(nodeStart, "type").Value
 	n.Node = make([]*Node, 0)
 	*parent = append(*parent, n)
@@ -339,6 +361,13 @@ func (d *Decoder) decNode(nodeStart xml.StartElement, parent *[]*Node) error {
 			}
 			continue
 		}
+		if child.Name.Local == "instance_controller" {
+			err = d.decInstanceController(child, n)
+			if err != nil {
+				return err
+			}
+			continue
+		}
 		// Decodes child node recursively
 		if child.Name.Local == "node" {
 			err = d.decNode(child, &n.Node)
@@ -424,6 +453,37 @@ func (d *Decoder) decInstanceGeometry(start xml.StartElement, n *Node) error {
 	}
 }
 
+func (d *Decoder) decInstanceController(start xml.StartElement, n *Node) error {
+
+	// Creates new InstanceController,sets its attributes and associates with node
+	ic := new(InstanceController)
+	ic.Url = findAttrib(start, "url").Value
+	ic.Name = findAttrib(start, "name").Value
+	n.Instance = ic
+
+	// Decodes instance controller children
+	for {
+		// Get next child element
+		child, data, err := d.decNextChild(start)
+		if err != nil || child.Name.Local == "" {
+			return err
+		}
+		// Decodes skeleton
+		if child.Name.Local == "skeleton" {
+			ic.Skeleton = append(ic.Skeleton, strings.TrimSpace(string(data)))
+			continue
+		}
+		// Decodes bind_material
+		if child.Name.Local == "bind_material" {
+			err := d.decBindMaterial(child, &ic.BindMaterial)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+	}
+}
+
 func (d *Decoder) decBindMaterial(start xml.StartElement, dest **BindMaterial) error {
 
 	*dest = new(BindMaterial)
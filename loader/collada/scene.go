@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
 	"github.com/g3n/engine/gls"
 	"github.com/g3n/engine/graphic"
 	"github.com/g3n/engine/material"
@@ -41,6 +42,8 @@ func (d *Decoder) NewScene() (core.INode, error) {
 	}
 
 	// Creates each node and adds it to the scene
+	d.sids = make(map[*core.Node]string)
+	d.skins = nil
 	for _, n := range vs.Node {
 		node, err := d.newNode(n)
 		if err != nil {
@@ -48,9 +51,43 @@ func (d *Decoder) NewScene() (core.INode, error) {
 		}
 		scene.Add(node)
 	}
+
+	// Binds the rigged meshes to their skeletons after all the joint nodes were created
+	for _, si := range d.skins {
+		err := d.bindSkeleton(scene, si)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return scene, nil
 }
 
+// newTrianglesMesh creates and returns a mesh for the specified triangles geometry
+// associating the materials in <bind_material> with the geometry groups.
+func (d *Decoder) newTrianglesMesh(geomi geometry.IGeometry, bm *BindMaterial) (*graphic.Mesh, error) {
+
+	mesh := graphic.NewMesh(geomi, nil)
+	if bm == nil {
+		return mesh, nil
+	}
+	geom := geomi.GetGeometry()
+	for _, im := range bm.TechniqueCommon.InstanceMaterial {
+		matid := strings.TrimPrefix(im.Target, "#")
+		for i := 0; i < geom.GroupCount(); i++ {
+			group := geom.GroupAt(i)
+			if group.Matid == matid {
+				mat, err := d.GetMaterial(im.Target)
+				if err != nil {
+					return nil, err
+				}
+				mesh.AddGroupMaterial(mat, i)
+				break
+			}
+		}
+	}
+	return mesh, nil
+}
+
 func (d *Decoder) newNode(cnode *Node) (core.INode, error) {
 
 	var node core.INode
@@ -69,22 +106,9 @@ func (d *Decoder) newNode(cnode *Node) (core.INode, error) {
 
 		switch gtype {
 		case gls.TRIANGLES:
-			mesh := graphic.NewMesh(geomi, nil)
-			geom := geomi.GetGeometry()
-			// Associates the material in <bind_material> with the geometry group material
-			for _, im := range nt.BindMaterial.TechniqueCommon.InstanceMaterial {
-				matid := strings.TrimPrefix(im.Target, "#")
-				for i := 0; i < geom.GroupCount(); i++ {
-					group := geom.GroupAt(i)
-					if group.Matid == matid {
-						mat, err := d.GetMaterial(im.Target)
-						if err != nil {
-							return nil, err
-						}
-						mesh.AddGroupMaterial(mat, i)
-						break
-					}
-				}
+			mesh, err := d.newTrianglesMesh(geomi, nt.BindMaterial)
+			if err != nil {
+				return nil, err
 			}
 			node = mesh
 
@@ -104,12 +128,22 @@ func (d *Decoder) newNode(cnode *Node) (core.INode, error) {
 		default:
 			return nil, fmt.Errorf("primitive not supported")
 		}
+	// Skinned geometry
+	case *InstanceController:
+		rm, err := d.newRiggedMesh(nt)
+		if err != nil {
+			return nil, err
+		}
+		node = rm
 	default:
 		return nil, fmt.Errorf("instance geometry type:%T not supported", nt)
 	}
 
 	n := node.GetNode()
 	n.SetLoaderID(cnode.Id)
+	if cnode.Sid != "" {
+		d.sids[n] = cnode.Sid
+	}
 
 	// Apply transformation elements to the node
 	for _, tei := range cnode.TransformationElements {
@@ -0,0 +1,254 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/g3n/engine/core"
+)
+
+// Progress describes the progress of an asynchronous load.
+// The items are the buffers and images of the glTF file.
+type Progress struct {
+	Bytes      int64 // Number of bytes of the items loaded
+	TotalBytes int64 // Total number of bytes of the items (approximated for data URL images)
+	Items      int   // Number of items loaded and decoded
+	TotalItems int   // Total number of items
+}
+
+// ProgressCallback is the type of the functions called when the progress of an asynchronous load changes.
+type ProgressCallback func(p Progress)
+
+// LoadCallback is the type of the function called when an asynchronous load ends.
+// If there was no error, g is the parsed glTF file and scene its default scene.
+type LoadCallback func(g *GLTF, scene core.INode, err error)
+
+// AsyncLoad is an asynchronous load of a glTF file started by LoadAsync.
+// The file is parsed, its buffers are read and its images are decoded in background goroutines
+// and its default scene is built, but the callbacks are only called by Update in the render loop,
+// so the OpenGL objects of the scene are created in the goroutine which owns the OpenGL context.
+type AsyncLoad struct {
+	mutex      sync.Mutex       // Protects the state below updated by the background goroutines
+	progress   Progress         // Current progress
+	changed    bool             // Progress changed since the last Update
+	done       bool             // Load ended
+	g          *GLTF            // Parsed glTF file
+	scene      core.INode       // Default scene
+	err        error            // Load error
+	finished   bool             // Load callback already called
+	onProgress ProgressCallback // Progress callback (may be nil)
+	onLoad     LoadCallback     // Load callback
+}
+
+// LoadAsync starts loading the default scene of the specified glTF (.gltf or .glb) file in
+// background goroutines and returns the load state.
+// AsyncLoad.Update must be called in the render loop to receive the progress and the load callbacks.
+func LoadAsync(filename string, onProgress ProgressCallback, onLoad LoadCallback) *AsyncLoad {
+
+	a := new(AsyncLoad)
+	a.onProgress = onProgress
+	a.onLoad = onLoad
+	go a.run(filename)
+	return a
+}
+
+// Progress returns the current progress of this load
+func (a *AsyncLoad) Progress() Progress {
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.progress
+}
+
+// Done returns if this load ended and its load callback was called by Update
+func (a *AsyncLoad) Done() bool {
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.finished
+}
+
+// Update calls the progress callback if the progress changed since the last call
+// and the load callback when the load ends.
+// It must be called in the render loop, in the goroutine which owns the OpenGL context.
+func (a *AsyncLoad) Update() {
+
+	a.mutex.Lock()
+	if a.finished {
+		a.mutex.Unlock()
+		return
+	}
+	changed := a.changed
+	a.changed = false
+	progress := a.progress
+	done := a.done
+	if done {
+		a.finished = true
+	}
+	a.mutex.Unlock()
+
+	if changed && a.onProgress != nil {
+		a.onProgress(progress)
+	}
+	if done {
+		a.onLoad(a.g, a.scene, a.err)
+	}
+}
+
+// run loads the file and its default scene. It runs in a background goroutine.
+func (a *AsyncLoad) run(filename string) {
+
+	g, scene, err := a.load(filename)
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.g = g
+	a.scene = scene
+	a.err = err
+	a.done = true
+}
+
+// load parses the specified file, reads its buffers, decodes its images
+// and builds its default scene.
+func (a *AsyncLoad) load(filename string) (*GLTF, core.INode, error) {
+
+	var g *GLTF
+	var err error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".gltf":
+		g, err = ParseJSON(filename)
+	case ".glb":
+		g, err = ParseBin(filename)
+	default:
+		return nil, nil, fmt.Errorf("unsupported glTF file: %s", filename)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	a.start(g)
+
+	// Read the buffers
+	for i := range g.Buffers {
+		_, err = g.loadBuffer(i)
+		if err != nil {
+			return nil, nil, err
+		}
+		a.advance(int64(g.Buffers[i].ByteLength))
+	}
+
+	// Load the buffer views of the images before decoding them concurrently,
+	// so the goroutines only update the caches of their images.
+	for _, img := range g.Images {
+		if img.BufferView != nil {
+			_, err = g.loadBufferView(*img.BufferView)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	err = a.decodeImages(g)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Build the default scene
+	if len(g.Scenes) == 0 {
+		return g, nil, nil
+	}
+	sceneIdx := 0
+	if g.Scene != nil {
+		sceneIdx = *g.Scene
+	}
+	scene, err := g.LoadScene(sceneIdx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return g, scene, nil
+}
+
+// decodeImages decodes the images of the specified glTF using one goroutine per CPU
+// and returns the first error found.
+// KTX2 images which can't be decoded are left to be loaded with their textures,
+// which may use their fallback images.
+func (a *AsyncLoad) decodeImages(g *GLTF) error {
+
+	next := make(chan int)
+	errs := make(chan error, len(g.Images))
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if isKTX2(g.Images[i]) {
+					g.loadKTX2(i)
+				} else if _, err := g.LoadImage(i); err != nil {
+					errs <- err
+				}
+				a.advance(imageBytes(g, i))
+			}
+		}()
+	}
+	for i := range g.Images {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// start sets the totals of the progress of the load of the specified glTF
+func (a *AsyncLoad) start(g *GLTF) {
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	for _, buf := range g.Buffers {
+		a.progress.TotalBytes += int64(buf.ByteLength)
+	}
+	for i := range g.Images {
+		a.progress.TotalBytes += imageBytes(g, i)
+	}
+	a.progress.TotalItems = len(g.Buffers) + len(g.Images)
+	a.changed = true
+}
+
+// advance updates the progress after an item with the specified number of bytes is loaded
+func (a *AsyncLoad) advance(bytes int64) {
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.progress.Bytes += bytes
+	a.progress.Items++
+	a.changed = true
+}
+
+// imageBytes returns the number of bytes of the specified image which are not in a buffer
+func imageBytes(g *GLTF, imgIdx int) int64 {
+
+	img := g.Images[imgIdx]
+	if img.Uri == "" {
+		return 0
+	}
+	if isDataURL(img.Uri) {
+		return int64(len(img.Uri) * 3 / 4)
+	}
+	fi, err := os.Stat(filepath.Join(g.path, img.Uri))
+	if err != nil {
+		return 0
+	}
+	return fi.Size()
+}
+
+// isKTX2 returns if the specified image is a KTX2 image
+func isKTX2(img Image) bool {
+
+	return img.MimeType == mimeKTX2 || strings.HasSuffix(strings.ToLower(img.Uri), ".ktx2")
+}
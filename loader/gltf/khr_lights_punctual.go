@@ -0,0 +1,135 @@
+package gltf
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/math32"
+)
+
+// LoadLight creates and returns a new light node described by the specified index
+// in the lights array of the KHR_lights_punctual extension of the asset.
+// The light points down the -Z axis of the node, as specified by the extension:
+// https://github.com/KhronosGroup/glTF/tree/master/extensions/2.0/Khronos/KHR_lights_punctual
+func (g *GLTF) LoadLight(lightIdx int) (core.INode, error) {
+
+	ext, ok := g.Extensions[KhrLightsPunctual].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("missing %s extension", KhrLightsPunctual)
+	}
+	lights, _ := ext["lights"].([]interface{})
+	if lightIdx < 0 || lightIdx >= len(lights) {
+		return nil, fmt.Errorf("invalid light index")
+	}
+	m, ok := lights[lightIdx].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid light:%d", lightIdx)
+	}
+	log.Debug("Loading Light %d", lightIdx)
+
+	// Returns the number at the specified key or the default value
+	number := func(m map[string]interface{}, key string, def float64) float32 {
+		if v, ok := m[key].(float64); ok {
+			return float32(v)
+		}
+		return float32(def)
+	}
+
+	color := math32.Color{R: 1, G: 1, B: 1}
+	if v, ok := m["color"].([]interface{}); ok && len(v) == 3 {
+		for i, c := range v {
+			f, _ := c.(float64)
+			switch i {
+			case 0:
+				color.R = float32(f)
+			case 1:
+				color.G = float32(f)
+			case 2:
+				color.B = float32(f)
+			}
+		}
+	}
+	intensity := number(m, "intensity", 1)
+
+	// Without a range the intensity falls off with the inverse square of the distance,
+	// otherwise the decay is set so the attenuation is 1% at the range.
+	quadratic := float32(1)
+	if r := number(m, "range", 0); r > 0 {
+		quadratic = 99 / (r * r)
+	}
+
+	var in core.INode
+	typ, _ := m["type"].(string)
+	switch typ {
+	case "directional":
+		// Directional lights use their position as the direction towards the light
+		l := light.NewDirectional(&color, intensity)
+		l.SetPosition(0, 0, 1)
+		in = l
+	case "point":
+		l := light.NewPoint(&color, intensity)
+		l.SetLinearDecay(0)
+		l.SetQuadraticDecay(quadratic)
+		in = l
+	case "spot":
+		l := light.NewSpot(&color, intensity)
+		l.SetDirection(0, 0, -1)
+		l.SetLinearDecay(0)
+		l.SetQuadraticDecay(quadratic)
+		spot, _ := m["spot"].(map[string]interface{})
+		inner := number(spot, "innerConeAngle", 0)
+		outer := number(spot, "outerConeAngle", math.Pi/4)
+		l.SetCutoffAngle(math32.RadToDeg(outer))
+		// The angular decay halves the intensity midway between the inner and outer cones
+		if mid := math32.Cos((inner + outer) / 2); mid < 1 {
+			l.SetAngularDecay(float32(math.Log(0.5) / math.Log(float64(mid))))
+		} else {
+			l.SetAngularDecay(0)
+		}
+		in = l
+	default:
+		return nil, fmt.Errorf("unsupported light type:%s", typ)
+	}
+	name, _ := m["name"].(string)
+	in.GetNode().SetName(name)
+	return in, nil
+}
+
+// loadNodeLight loads the light referenced by the KHR_lights_punctual extension of the specified node
+// and adds it to the node, or does nothing if the node has no light.
+func (g *GLTF) loadNodeLight(nodeData *Node, node *core.Node) error {
+
+	ext, ok := nodeData.Extensions[KhrLightsPunctual].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	idx, ok := ext["light"].(float64)
+	if !ok {
+		return fmt.Errorf("invalid %s node extension", KhrLightsPunctual)
+	}
+	in, err := g.LoadLight(int(idx))
+	if err != nil {
+		return err
+	}
+
+	// Directional lights use their world position as the direction towards the light, so the
+	// position of the light cancels the translation of the node, which is assumed to be a root node
+	// as written by the exporters, leaving the rotated +Z axis of the node.
+	if ld, ok := in.(*light.Directional); ok {
+		var dir math32.Vector3
+		dir.Set(0, 0, 1)
+		q := node.Quaternion()
+		dir.ApplyQuaternion(&q)
+		node.UpdateMatrix()
+		var inv math32.Matrix4
+		mat := node.Matrix()
+		if inv.GetInverse(&mat) == nil {
+			dir.ApplyMatrix4(&inv)
+			ld.SetPositionVec(&dir)
+		}
+	}
+	node.Add(in)
+	return nil
+}
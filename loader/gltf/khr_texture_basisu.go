@@ -0,0 +1,75 @@
+package gltf
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/texture"
+)
+
+// loadBasisuTexture creates and returns a new texture from the KTX2 image of the specified
+// texture, referenced by its KHR_texture_basisu extension or by its source, or nil if it has
+// no KTX2 image. If the KTX2 image can't be loaded, as when it is a Basis Universal UASTC image
+// and no transcoder was set with texture.SetBasisTranscoder, and the extension is not required,
+// nil is returned to load the fallback source image. The engine transcodes the ETC1S images,
+// so the UASTC images without fallback can only be loaded if the application sets a transcoder:
+// https://github.com/KhronosGroup/glTF/tree/master/extensions/2.0/Khronos/KHR_texture_basisu
+func (g *GLTF) loadBasisuTexture(texData Texture) (*texture.Texture2D, error) {
+
+	imgIdx := -1
+	if ext, ok := texData.Extensions[KhrTextureBasisu].(map[string]interface{}); ok {
+		if src, ok := ext["source"].(float64); ok {
+			imgIdx = int(src)
+		}
+	} else if texData.Source >= 0 && texData.Source < len(g.Images) && isKTX2(g.Images[texData.Source]) {
+		imgIdx = texData.Source
+	}
+	if imgIdx < 0 {
+		return nil, nil
+	}
+
+	tex, err := g.loadKTX2Texture(imgIdx)
+	if err == nil {
+		return tex, nil
+	}
+	for _, ext := range g.ExtensionsRequired {
+		if ext == KhrTextureBasisu {
+			return nil, fmt.Errorf("loading KTX2 image %d required by %s: %v", imgIdx, KhrTextureBasisu, err)
+		}
+	}
+	if texData.Source < 0 || texData.Source >= len(g.Images) || isKTX2(g.Images[texData.Source]) {
+		return nil, fmt.Errorf("loading KTX2 image %d without fallback image: %v", imgIdx, err)
+	}
+	log.Warn("Loading KTX2 image %d: %v, using the fallback image", imgIdx, err)
+	return nil, nil
+}
+
+// loadKTX2Texture creates and returns a new texture from the specified KTX2 image.
+func (g *GLTF) loadKTX2Texture(imgIdx int) (*texture.Texture2D, error) {
+
+	ktx2, err := g.loadKTX2(imgIdx)
+	if err != nil {
+		return nil, err
+	}
+	return texture.NewTexture2DFromKTX2(ktx2)
+}
+
+// loadKTX2 loads and returns the container of the specified KTX2 image.
+func (g *GLTF) loadKTX2(imgIdx int) (*texture.KTX2, error) {
+
+	if imgIdx >= len(g.Images) {
+		return nil, fmt.Errorf("invalid image index")
+	}
+	imgData := &g.Images[imgIdx]
+	if imgData.ktx2 == nil {
+		log.Debug("Loading KTX2 Image %d", imgIdx)
+		data, err := g.loadImageData(imgIdx)
+		if err != nil {
+			return nil, err
+		}
+		imgData.ktx2, err = texture.DecodeKTX2Data(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return imgData.ktx2, nil
+}
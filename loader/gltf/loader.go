@@ -255,6 +255,11 @@ func (g *GLTF) LoadNode(nodeIdx int) (core.INode, error) {
 		}
 	}
 
+	// Add the light of the node if any
+	if err := g.loadNodeLight(&nodeData, node); err != nil {
+		return nil, err
+	}
+
 	// Cache node
 	g.Nodes[nodeIdx].cache = in
 
@@ -332,10 +337,48 @@ func (g *GLTF) LoadAnimation(animIdx int) (*animation.Animation, error) {
 		return nil, fmt.Errorf("invalid animation index")
 	}
 	log.Debug("Loading Animation %d", animIdx)
-	animData := g.Animations[animIdx]
-
 	anim := animation.NewAnimation()
-	anim.SetName(animData.Name)
+	anim.SetName(g.Animations[animIdx].Name)
+	err := g.loadAnimationChannels(anim, animIdx)
+	if err != nil {
+		return nil, err
+	}
+	return anim, nil
+}
+
+// LazyAnimation creates an Animation for the specified animation index from the GLTF
+// Animations array whose channels are only loaded the first time the animation is used.
+// The GLTF must remain available until then.
+func (g *GLTF) LazyAnimation(animIdx int) (*animation.Animation, error) {
+
+	// Check if provided animation index is valid
+	if animIdx < 0 || animIdx >= len(g.Animations) {
+		return nil, fmt.Errorf("invalid animation index")
+	}
+	anim := animation.NewLazyAnimation(func(anim *animation.Animation) error {
+		log.Debug("Loading Animation %d (lazy)", animIdx)
+		return g.loadAnimationChannels(anim, animIdx)
+	})
+	anim.SetName(g.Animations[animIdx].Name)
+	return anim, nil
+}
+
+// LazyAnimations creates lazily loaded Animations for all the GLTF animations.
+func (g *GLTF) LazyAnimations() []*animation.Animation {
+
+	anims := make([]*animation.Animation, 0, len(g.Animations))
+	for i := range g.Animations {
+		anim, _ := g.LazyAnimation(i)
+		anims = append(anims, anim)
+	}
+	return anims
+}
+
+// loadAnimationChannels adds to the specified animation the channels
+// of the specified animation index from the GLTF Animations array.
+func (g *GLTF) loadAnimationChannels(anim *animation.Animation, animIdx int) error {
+
+	animData := g.Animations[animIdx]
 	for i := 0; i < len(animData.Channels); i++ {
 
 		chData := animData.Channels[i]
@@ -343,7 +386,7 @@ func (g *GLTF) LoadAnimation(animIdx int) (*animation.Animation, error) {
 		sampler := animData.Samplers[chData.Sampler]
 		node, err := g.LoadNode(target.Node)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		var validTypes []string
@@ -365,11 +408,21 @@ func (g *GLTF) LoadAnimation(animIdx int) (*animation.Animation, error) {
 		} else if target.Path == "weights" {
 			validTypes = []string{SCALAR}
 			validComponentTypes = []int{FLOAT, BYTE, UNSIGNED_BYTE, SHORT, UNSIGNED_SHORT}
-			children := node.GetNode().Children()
-			if len(children) > 1 {
-				return nil, fmt.Errorf("animating meshes with more than a single primitive is not supported")
+			igr, ok := node.(graphic.IGraphic)
+			if !ok {
+				children := node.GetNode().Children()
+				if len(children) != 1 {
+					return fmt.Errorf("animating meshes with more than a single primitive is not supported")
+				}
+				igr, ok = children[0].(graphic.IGraphic)
+			}
+			var morphGeom *geometry.MorphGeometry
+			if ok {
+				morphGeom, ok = igr.IGeometry().(*geometry.MorphGeometry)
+			}
+			if !ok {
+				return fmt.Errorf("animated weights of node %d without morph targets", target.Node)
 			}
-			morphGeom := children[0].(graphic.IGraphic).IGeometry().(*geometry.MorphGeometry)
 			ch = animation.NewMorphChannel(morphGeom)
 		}
 
@@ -377,17 +430,17 @@ func (g *GLTF) LoadAnimation(animIdx int) (*animation.Animation, error) {
 
 		keyframes, err := g.loadAccessorF32(sampler.Input, "Input", []string{SCALAR}, []int{FLOAT})
 		if err != nil {
-			return nil, err
+			return err
 		}
 		values, err := g.loadAccessorF32(sampler.Output, "Output", validTypes, validComponentTypes)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		ch.SetBuffers(keyframes, values)
 		ch.SetInterpolationType(animation.InterpolationType(sampler.Interpolation))
 		anim.AddChannel(ch)
 	}
-	return anim, nil
+	return nil
 }
 
 // LoadCamera creates and returns a Camera Node
@@ -438,9 +491,8 @@ func (g *GLTF) LoadMesh(meshIdx int) (core.INode, error) {
 	meshData := g.Meshes[meshIdx]
 	// Return cached if available
 	if meshData.cache != nil {
-		// TODO CLONE/REINSTANCE INSTEAD
-		//log.Debug("Instancing Mesh %d (from cached)", meshIdx)
-		//return meshData.cache, nil
+		log.Debug("Instancing Mesh %d (from cached)", meshIdx)
+		return meshData.cache.Clone(), nil
 	}
 	log.Debug("Loading Mesh %d", meshIdx)
 
@@ -494,7 +546,6 @@ func (g *GLTF) LoadMesh(meshIdx int) (core.INode, error) {
 			morphGeom := geometry.NewMorphGeometry(geom)
 
 			// TODO Load morph target names if present in extras under "targetNames"
-			// TODO Update morph target weights if present in Mesh.Weights
 
 			// Load targets
 			for i := range p.Targets {
@@ -506,6 +557,9 @@ func (g *GLTF) LoadMesh(meshIdx int) (core.INode, error) {
 				}
 				morphGeom.AddMorphTargetDeltas(tGeom)
 			}
+			if len(meshData.Weights) == len(p.Targets) {
+				morphGeom.SetWeights(append([]float32{}, meshData.Weights...))
+			}
 
 			igeom = morphGeom
 		}
@@ -745,12 +799,19 @@ func (g *GLTF) LoadTexture(texIdx int) (*texture.Texture2D, error) {
 	// NOTE: Textures can't be cached because they have their own uniforms
 	log.Debug("Loading Texture %d", texIdx)
 
-	// Load texture image
-	img, err := g.LoadImage(texData.Source)
+	// Load texture image, compressed with Basis Universal or in another GPU format
+	// if the texture has the KHR_texture_basisu extension or its image is KTX2
+	tex, err := g.loadBasisuTexture(texData)
 	if err != nil {
 		return nil, err
 	}
-	tex := texture.NewTexture2DFromRGBA(img)
+	if tex == nil {
+		img, err := g.LoadImage(texData.Source)
+		if err != nil {
+			return nil, err
+		}
+		tex = texture.NewTexture2DFromRGBA(img)
+	}
 
 	// Get sampler and apply texture parameters
 	if texData.Sampler != nil {
@@ -820,22 +881,7 @@ func (g *GLTF) LoadImage(imgIdx int) (*image.RGBA, error) {
 	}
 	log.Debug("Loading Image %d", imgIdx)
 
-	var data []byte
-	var err error
-	// If Uri is empty, load image from GLB binary chunk
-	if imgData.Uri == "" {
-		if imgData.BufferView == nil {
-			return nil, fmt.Errorf("image has empty URI and no BufferView")
-		}
-		data, err = g.loadBufferView(*imgData.BufferView)
-	} else if isDataURL(imgData.Uri) {
-		// Checks if image URI is data URL
-		data, err = loadDataURL(imgData.Uri)
-	} else {
-		// Load image data from file
-		data, err = g.loadFileBytes(imgData.Uri)
-	}
-
+	data, err := g.loadImageData(imgIdx)
 	if err != nil {
 		return nil, err
 	}
@@ -860,6 +906,25 @@ func (g *GLTF) LoadImage(imgIdx int) (*image.RGBA, error) {
 	return rgba, nil
 }
 
+// loadImageData returns the encoded data of the specified image.
+func (g *GLTF) loadImageData(imgIdx int) ([]byte, error) {
+
+	imgData := g.Images[imgIdx]
+	// If Uri is empty, load image from GLB binary chunk
+	if imgData.Uri == "" {
+		if imgData.BufferView == nil {
+			return nil, fmt.Errorf("image has empty URI and no BufferView")
+		}
+		return g.loadBufferView(*imgData.BufferView)
+	}
+	// Checks if image URI is data URL
+	if isDataURL(imgData.Uri) {
+		return loadDataURL(imgData.Uri)
+	}
+	// Load image data from file
+	return g.loadFileBytes(imgData.Uri)
+}
+
 // bytesToArrayU32 converts a byte array to ArrayU32.
 func (g *GLTF) bytesToArrayU32(data []byte, componentType, count int) (math32.ArrayU32, error) {
 
@@ -1137,9 +1202,10 @@ const (
 	mimeBIN       = "application/octet-stream"
 	mimePNG       = "image/png"
 	mimeJPEG      = "image/jpeg"
+	mimeKTX2      = "image/ktx2"
 )
 
-var validMediaTypes = []string{mimeBIN, mimePNG, mimeJPEG}
+var validMediaTypes = []string{mimeBIN, mimePNG, mimeJPEG, mimeKTX2}
 
 // isDataURL checks if the specified string has the prefix of data URL.
 func isDataURL(url string) bool {
@@ -0,0 +1,640 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/g3n/engine/animation"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// Exporter builds a glTF asset from g3n node hierarchies and animations.
+// The asset can be written as a .gltf file with an external .bin file or as a single .glb file.
+// Cameras and lights are not exported.
+type Exporter struct {
+	g         GLTF                            // glTF asset being built
+	bin       []byte                          // Binary buffer data
+	nodes     map[*core.Node]int              // Indices of exported nodes
+	morphs    map[*geometry.MorphGeometry]int // Indices of the nodes of exported morph geometries
+	materials map[material.IMaterial]int      // Indices of exported materials
+	textures  map[*texture.Texture2D]int      // Indices of exported textures
+	skins     map[*graphic.Skeleton]int       // Indices of exported skins
+	pending   []pendingSkin                   // Skins of exported nodes not resolved yet, in export order
+}
+
+// pendingSkin is the skeleton of an exported node whose skin is not resolved yet.
+type pendingSkin struct {
+	node     int               // Index of the exported node
+	skeleton *graphic.Skeleton // Skeleton of the rigged mesh of the node
+}
+
+// exportedAttributes maps the internal g3n attribute type to the glTF attribute name.
+var exportedAttributes = map[gls.AttribType]string{
+	gls.VertexPosition:  "POSITION",
+	gls.VertexNormal:    "NORMAL",
+	gls.VertexTangent:   "TANGENT",
+	gls.VertexTexcoord:  "TEXCOORD_0",
+	gls.VertexTexcoord2: "TEXCOORD_1",
+	gls.VertexColor:     "COLOR_0",
+	gls.SkinIndex:       "JOINTS_0",
+	gls.SkinWeight:      "WEIGHTS_0",
+}
+
+// morphAttributes is the set of vertex attributes of the morph targets supported by glTF.
+var morphAttributes = map[gls.AttribType]bool{
+	gls.VertexPosition: true,
+	gls.VertexNormal:   true,
+	gls.VertexTangent:  true,
+}
+
+// typeNames maps a number of components to the glTF accessor type.
+var typeNames = map[int]string{
+	1:  SCALAR,
+	2:  VEC2,
+	3:  VEC3,
+	4:  VEC4,
+	16: MAT4,
+}
+
+// NewExporter creates and returns a pointer to a new empty glTF exporter.
+func NewExporter() *Exporter {
+
+	e := new(Exporter)
+	e.g.Asset = Asset{Version: "2.0", Generator: "g3n"}
+	e.nodes = make(map[*core.Node]int)
+	e.morphs = make(map[*geometry.MorphGeometry]int)
+	e.materials = make(map[material.IMaterial]int)
+	e.textures = make(map[*texture.Texture2D]int)
+	e.skins = make(map[*graphic.Skeleton]int)
+	return e
+}
+
+// GLTF returns a pointer to the glTF asset built so far.
+func (e *Exporter) GLTF() *GLTF {
+
+	return &e.g
+}
+
+// AddScene exports the specified node and all its descendants as a new glTF scene
+// with the node as its single root and returns the index of the scene.
+// The first scene added is the default scene of the asset.
+func (e *Exporter) AddScene(inode core.INode) (int, error) {
+
+	root, err := e.addNode(inode)
+	if err != nil {
+		return 0, err
+	}
+	err = e.resolveSkins()
+	if err != nil {
+		return 0, err
+	}
+	idx := len(e.g.Scenes)
+	e.g.Scenes = append(e.g.Scenes, Scene{Name: inode.GetNode().Name(), Nodes: []int{root}})
+	if e.g.Scene == nil {
+		e.g.Scene = &idx
+	}
+	return idx, nil
+}
+
+// AddAnimation exports the specified animation.
+// All nodes targeted by the animation channels must have already been exported with AddScene.
+// The morph geometries targeted by morph channels must have been exported with the meshes of these nodes.
+func (e *Exporter) AddAnimation(anim *animation.Animation) error {
+
+	ad := Animation{Name: anim.Name(), Channels: []Channel{}, Samplers: []AnimationSampler{}}
+	for _, ich := range anim.Channels() {
+		var target core.INode
+		var nodeIdx int
+		var path string
+		var size int
+		var interp animation.InterpolationType
+		switch ch := ich.(type) {
+		case *animation.PositionChannel:
+			target, path, size, interp = ch.Target(), "translation", 3, ch.InterpolationType()
+		case *animation.RotationChannel:
+			target, path, size, interp = ch.Target(), "rotation", 4, ch.InterpolationType()
+		case *animation.ScaleChannel:
+			target, path, size, interp = ch.Target(), "scale", 3, ch.InterpolationType()
+		case *animation.MorphChannel:
+			idx, ok := e.morphs[ch.Target()]
+			if !ok {
+				return fmt.Errorf("animation target morph geometry was not exported")
+			}
+			nodeIdx, path, size, interp = idx, "weights", 1, ch.InterpolationType()
+		default:
+			log.Warn("Unsupported animation channel type %T ignored", ich)
+			continue
+		}
+		if target != nil {
+			idx, ok := e.nodes[target.GetNode()]
+			if !ok {
+				return fmt.Errorf("animation target node '%s' was not exported", target.GetNode().Name())
+			}
+			nodeIdx = idx
+		}
+		keyframes := ich.Keyframes()
+		input := e.addAccessor(keyframes, 1, FLOAT, 0, true)
+		output := e.addAccessor(ich.Values(), size, FLOAT, 0, false)
+		ad.Samplers = append(ad.Samplers, AnimationSampler{Input: input, Output: output, Interpolation: string(interp)})
+		ad.Channels = append(ad.Channels, Channel{Sampler: len(ad.Samplers) - 1, Target: Target{Node: nodeIdx, Path: path}})
+	}
+	e.g.Animations = append(e.g.Animations, ad)
+	return nil
+}
+
+// WriteJSON writes the asset to a glTF JSON file with the specified name.
+// The binary data is written to a .bin file with the same base name in the same directory.
+func (e *Exporter) WriteJSON(filename string) error {
+
+	base := filepath.Base(filename)
+	binName := strings.TrimSuffix(base, filepath.Ext(base)) + ".bin"
+	if len(e.bin) > 0 {
+		err := ioutil.WriteFile(filepath.Join(filepath.Dir(filename), binName), e.bin, 0644)
+		if err != nil {
+			return err
+		}
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return e.writeJSON(f, binName)
+}
+
+// WriteJSONWriter writes the asset as glTF JSON to the specified writer.
+// The binary data is embedded in the JSON as a base64 data URI.
+func (e *Exporter) WriteJSONWriter(w io.Writer) error {
+
+	return e.writeJSON(w, dataURLprefix+mimeBIN+";base64,"+base64.StdEncoding.EncodeToString(e.bin))
+}
+
+// WriteBin writes the asset to a binary glTF (.glb) file with the specified name.
+func (e *Exporter) WriteBin(filename string) error {
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return e.WriteBinWriter(f)
+}
+
+// WriteBinWriter writes the asset in binary glTF (.glb) format to the specified writer.
+func (e *Exporter) WriteBinWriter(w io.Writer) error {
+
+	data, err := e.marshal("")
+	if err != nil {
+		return err
+	}
+	// Chunks must be aligned to 4 bytes: JSON is padded with spaces and binary data with zeros
+	for len(data)%4 != 0 {
+		data = append(data, ' ')
+	}
+	bin := e.bin
+	for len(bin)%4 != 0 {
+		bin = append(bin, 0)
+	}
+	length := 12 + 8 + len(data)
+	if len(bin) > 0 {
+		length += 8 + len(bin)
+	}
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, GLBHeader{Magic: GLBMagic, Version: 2, Length: uint32(length)})
+	binary.Write(&buf, binary.LittleEndian, GLBChunk{Length: uint32(len(data)), Type: GLBJson})
+	buf.Write(data)
+	if len(bin) > 0 {
+		binary.Write(&buf, binary.LittleEndian, GLBChunk{Length: uint32(len(bin)), Type: GLBBin})
+		buf.Write(bin)
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// writeJSON writes the asset as glTF JSON to the specified writer using the specified buffer uri.
+func (e *Exporter) writeJSON(w io.Writer, uri string) error {
+
+	data, err := e.marshal(uri)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// marshal returns the JSON encoding of the asset with its single buffer using the specified uri.
+func (e *Exporter) marshal(uri string) ([]byte, error) {
+
+	g := e.g
+	if len(e.bin) > 0 {
+		g.Buffers = []Buffer{{Uri: uri, ByteLength: len(e.bin)}}
+	}
+	return json.MarshalIndent(&g, "", "  ")
+}
+
+// addNode exports the specified node and its descendants and returns the index of the exported node.
+func (e *Exporter) addNode(inode core.INode) (int, error) {
+
+	node := inode.GetNode()
+	if _, ok := e.nodes[node]; ok {
+		return 0, fmt.Errorf("node '%s' already exported", node.Name())
+	}
+	nd := Node{Name: node.Name()}
+	pos := node.Position()
+	if pos != *math32.NewVec3() {
+		nd.Translation = &[3]float32{pos.X, pos.Y, pos.Z}
+	}
+	quat := node.Quaternion()
+	if quat != *math32.NewQuaternion(0, 0, 0, 1) {
+		nd.Rotation = &[4]float32{quat.X, quat.Y, quat.Z, quat.W}
+	}
+	scale := node.Scale()
+	if scale != *math32.NewVector3(1, 1, 1) {
+		nd.Scale = &[3]float32{scale.X, scale.Y, scale.Z}
+	}
+	idx := len(e.g.Nodes)
+	e.g.Nodes = append(e.g.Nodes, nd)
+	e.nodes[node] = idx
+
+	// Export mesh and schedule the export of the skin of rigged meshes
+	if igr, ok := inode.(graphic.IGraphic); ok {
+		meshIdx, err := e.addMesh(igr)
+		if err != nil {
+			return 0, err
+		}
+		e.g.Nodes[idx].Mesh = &meshIdx
+		if mg, ok := igr.IGeometry().(*geometry.MorphGeometry); ok {
+			e.morphs[mg] = idx
+		}
+		if rm, ok := inode.(*graphic.RiggedMesh); ok && rm.Skeleton() != nil {
+			e.pending = append(e.pending, pendingSkin{node: idx, skeleton: rm.Skeleton()})
+		}
+	}
+
+	// Export children
+	for _, ichild := range node.Children() {
+		childIdx, err := e.addNode(ichild)
+		if err != nil {
+			return 0, err
+		}
+		e.g.Nodes[idx].Children = append(e.g.Nodes[idx].Children, childIdx)
+	}
+	return idx, nil
+}
+
+// resolveSkins exports the skins of the rigged meshes exported so far.
+// All the bones of the skeletons must have already been exported.
+func (e *Exporter) resolveSkins() error {
+
+	for len(e.pending) > 0 {
+		sk := e.pending[0].skeleton
+		skinIdx, ok := e.skins[sk]
+		if !ok {
+			bones := sk.Bones()
+			joints := make([]int, len(bones))
+			for i, bone := range bones {
+				jointIdx, ok := e.nodes[bone]
+				if !ok {
+					return fmt.Errorf("skeleton bone '%s' was not exported", bone.Name())
+				}
+				joints[i] = jointIdx
+			}
+			ibms := make([]float32, 0, 16*len(bones))
+			for _, m := range sk.InverseBindMatrices() {
+				ibms = append(ibms, m[:]...)
+			}
+			skinIdx = len(e.g.Skins)
+			e.g.Skins = append(e.g.Skins, Skin{InverseBindMatrices: e.addAccessor(ibms, 16, FLOAT, 0, false), Joints: joints})
+			e.skins[sk] = skinIdx
+		}
+		e.g.Nodes[e.pending[0].node].Skin = &skinIdx
+		e.pending = e.pending[1:]
+	}
+	return nil
+}
+
+// addMesh exports the geometry and materials of the specified graphic
+// as a glTF mesh with one primitive per material and returns the index of the mesh.
+// The targets of morph geometries are exported with their current weights.
+func (e *Exporter) addMesh(igr graphic.IGraphic) (int, error) {
+
+	gr := igr.GetGraphic()
+	geom := igr.GetGeometry()
+	attributes := e.addAttributes(geom, false)
+	mesh := Mesh{Name: gr.Name()}
+	var targets []map[string]int
+	if mg, ok := igr.IGeometry().(*geometry.MorphGeometry); ok {
+		for _, t := range mg.Targets() {
+			targets = append(targets, e.addAttributes(t, true))
+		}
+		mesh.Weights = append([]float32{}, mg.Weights()...)
+	}
+	mode := int(gr.Mode())
+	materials := gr.Materials()
+	if len(materials) == 0 {
+		materials = append(materials, graphic.GraphicMaterial{})
+	}
+	for i := range materials {
+		grmat := &materials[i]
+		prim := Primitive{Attributes: attributes, Targets: targets}
+		if mode != TRIANGLES {
+			prim.Mode = &mode
+		}
+		// Export the indices of the range of the geometry rendered by this material
+		start, count := grmat.Range()
+		if geom.Indexed() {
+			indices := geom.Indices()
+			if count == 0 {
+				count = len(indices) - start
+			}
+			idx := e.addIndices(indices[start : start+count])
+			prim.Indices = &idx
+		} else if count > 0 {
+			indices := math32.NewArrayU32(count, count)
+			for j := range indices {
+				indices[j] = uint32(start + j)
+			}
+			idx := e.addIndices(indices)
+			prim.Indices = &idx
+		}
+		if grmat.IMaterial() != nil {
+			matIdx, err := e.addMaterial(grmat.IMaterial())
+			if err != nil {
+				return 0, err
+			}
+			prim.Material = &matIdx
+		}
+		mesh.Primitives = append(mesh.Primitives, prim)
+	}
+	idx := len(e.g.Meshes)
+	e.g.Meshes = append(e.g.Meshes, mesh)
+	return idx, nil
+}
+
+// addAttributes exports the vertex attributes of the specified geometry and returns
+// the map of glTF attribute names to accessor indices. The geometry of a morph target
+// only has its position, normal and tangent deltas exported, with tangents without handedness.
+func (e *Exporter) addAttributes(geom *geometry.Geometry, morph bool) map[string]int {
+
+	attributes := make(map[string]int)
+	for _, vbo := range geom.VBOs() {
+		buffer := *vbo.Buffer()
+		stride := vbo.Stride()
+		if stride == 0 {
+			continue
+		}
+		count := len(buffer) / stride
+		for _, attrib := range vbo.Attributes() {
+			name, ok := exportedAttributes[attrib.Type]
+			if !ok || morph && !morphAttributes[attrib.Type] {
+				log.Warn("Unsupported vertex attribute '%s' ignored", attrib.Name)
+				continue
+			}
+			// Extract the attribute data from the possibly interleaved VBO buffer
+			size := int(attrib.NumElements)
+			offset := int(attrib.ByteOffset) / 4
+			n := size
+			if attrib.Type == gls.VertexTangent {
+				// glTF tangents include the handedness in the w component, except in morph targets
+				n = 4
+				if morph {
+					n = 3
+				}
+			}
+			data := make([]float32, 0, count*n)
+			for i := 0; i < count; i++ {
+				pos := i*stride + offset
+				if n > size {
+					data = append(data, buffer[pos:pos+size]...)
+					data = append(data, 1)
+				} else {
+					data = append(data, buffer[pos:pos+n]...)
+				}
+			}
+			size = n
+			switch attrib.Type {
+			case gls.VertexPosition:
+				attributes[name] = e.addAccessor(data, size, FLOAT, ARRAY_BUFFER, true)
+			case gls.SkinIndex:
+				attributes[name] = e.addAccessor(data, size, UNSIGNED_SHORT, ARRAY_BUFFER, false)
+			default:
+				attributes[name] = e.addAccessor(data, size, FLOAT, ARRAY_BUFFER, false)
+			}
+		}
+	}
+	return attributes
+}
+
+// addIndices exports the specified indices and returns the index of the accessor.
+func (e *Exporter) addIndices(indices math32.ArrayU32) int {
+
+	data := make([]byte, 4*len(indices))
+	for i, v := range indices {
+		binary.LittleEndian.PutUint32(data[4*i:], v)
+	}
+	view := e.addBufferView(data, ELEMENT_ARRAY_BUFFER)
+	idx := len(e.g.Accessors)
+	e.g.Accessors = append(e.g.Accessors, Accessor{BufferView: &view, ComponentType: UNSIGNED_INT, Count: len(indices), Type: SCALAR})
+	return idx
+}
+
+// addAccessor exports the specified data with the specified number of components per element
+// as FLOAT or UNSIGNED_SHORT components and returns the index of the accessor.
+// If minmax is true the minimum and maximum values of each component are also exported.
+func (e *Exporter) addAccessor(data []float32, size, componentType, target int, minmax bool) int {
+
+	var buf []byte
+	if componentType == UNSIGNED_SHORT {
+		buf = make([]byte, 2*len(data))
+		for i, v := range data {
+			binary.LittleEndian.PutUint16(buf[2*i:], uint16(v))
+		}
+	} else {
+		buf = make([]byte, 4*len(data))
+		for i, v := range data {
+			binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+		}
+	}
+	view := e.addBufferView(buf, target)
+	acc := Accessor{BufferView: &view, ComponentType: componentType, Count: len(data) / size, Type: typeNames[size]}
+	if minmax && acc.Count > 0 {
+		acc.Min = make([]float32, size)
+		acc.Max = make([]float32, size)
+		copy(acc.Min, data[:size])
+		copy(acc.Max, data[:size])
+		for i := size; i < len(data); i++ {
+			c := i % size
+			acc.Min[c] = math32.Min(acc.Min[c], data[i])
+			acc.Max[c] = math32.Max(acc.Max[c], data[i])
+		}
+	}
+	idx := len(e.g.Accessors)
+	e.g.Accessors = append(e.g.Accessors, acc)
+	return idx
+}
+
+// addBufferView appends the specified data to the binary buffer aligned to 4 bytes
+// and returns the index of the new buffer view. A zero target is not exported.
+func (e *Exporter) addBufferView(data []byte, target int) int {
+
+	for len(e.bin)%4 != 0 {
+		e.bin = append(e.bin, 0)
+	}
+	offset := len(e.bin)
+	e.bin = append(e.bin, data...)
+	bv := BufferView{Buffer: 0, ByteLength: len(data)}
+	if offset != 0 {
+		bv.ByteOffset = &offset
+	}
+	if target != 0 {
+		bv.Target = &target
+	}
+	idx := len(e.g.BufferViews)
+	e.g.BufferViews = append(e.g.BufferViews, bv)
+	return idx
+}
+
+// addMaterial exports the specified material as a PBR metallic-roughness material
+// and returns the index of the exported material.
+// Standard materials are converted to non-metallic fully rough materials.
+func (e *Exporter) addMaterial(imat material.IMaterial) (int, error) {
+
+	if idx, ok := e.materials[imat]; ok {
+		return idx, nil
+	}
+	mat := imat.GetMaterial()
+	md := Material{DoubleSided: mat.Side() == material.SideDouble}
+	if mat.Transparent() {
+		md.AlphaMode = "BLEND"
+	}
+	pbr := new(PbrMetallicRoughness)
+	md.PbrMetallicRoughness = pbr
+	var emissive math32.Color
+	switch m := imat.(type) {
+	case *material.Physical:
+		bc := m.BaseColorFactor()
+		pbr.BaseColorFactor = &[4]float32{bc.R, bc.G, bc.B, bc.A}
+		metallic := m.MetallicFactor()
+		pbr.MetallicFactor = &metallic
+		roughness := m.RoughnessFactor()
+		pbr.RoughnessFactor = &roughness
+		emissive = m.EmissiveFactor()
+		var err error
+		if pbr.BaseColorTexture, err = e.addTextureInfo(m.BaseColorMap()); err != nil {
+			return 0, err
+		}
+		if pbr.MetallicRoughnessTexture, err = e.addTextureInfo(m.MetallicRoughnessMap()); err != nil {
+			return 0, err
+		}
+		if md.EmissiveTexture, err = e.addTextureInfo(m.EmissiveMap()); err != nil {
+			return 0, err
+		}
+		if ti, err := e.addTextureInfo(m.NormalMap()); err != nil {
+			return 0, err
+		} else if ti != nil {
+			md.NormalTexture = &NormalTextureInfo{Index: ti.Index}
+		}
+		if ti, err := e.addTextureInfo(m.OcclusionMap()); err != nil {
+			return 0, err
+		} else if ti != nil {
+			md.OcclusionTexture = &OcclusionTextureInfo{Index: ti.Index}
+		}
+	case *material.Standard:
+		color := m.Color()
+		pbr.BaseColorFactor = &[4]float32{color.R, color.G, color.B, m.Opacity()}
+		metallic := float32(0)
+		pbr.MetallicFactor = &metallic
+		roughness := float32(1)
+		pbr.RoughnessFactor = &roughness
+		emissive = m.EmissiveColor()
+		if mat.TextureCount() > 0 {
+			var err error
+			if pbr.BaseColorTexture, err = e.addTextureInfo(mat.Textures()[0]); err != nil {
+				return 0, err
+			}
+		}
+	default:
+		log.Warn("Unsupported material type %T exported as default material", imat)
+	}
+	if emissive != *math32.NewColor("black") {
+		md.EmissiveFactor = &[3]float32{emissive.R, emissive.G, emissive.B}
+	}
+	idx := len(e.g.Materials)
+	e.g.Materials = append(e.g.Materials, md)
+	e.materials[imat] = idx
+	return idx, nil
+}
+
+// addTextureInfo exports the specified texture if not nil and returns a pointer to its texture info.
+func (e *Exporter) addTextureInfo(tex *texture.Texture2D) (*TextureInfo, error) {
+
+	if tex == nil {
+		return nil, nil
+	}
+	idx, err := e.addTexture(tex)
+	if err != nil {
+		return nil, err
+	}
+	return &TextureInfo{Index: idx}, nil
+}
+
+// addTexture exports the specified texture with its image encoded as PNG
+// in the binary buffer and returns the index of the exported texture.
+// Only uncompressed RGBA textures with unsigned byte components are supported.
+func (e *Exporter) addTexture(tex *texture.Texture2D) (int, error) {
+
+	if idx, ok := e.textures[tex]; ok {
+		return idx, nil
+	}
+	data, format, formatType := tex.Data()
+	pix, ok := data.([]byte)
+	if !ok || tex.Compressed() || format != gls.RGBA || formatType != gls.UNSIGNED_BYTE {
+		return 0, fmt.Errorf("unsupported texture format for export")
+	}
+	width, height := tex.Width(), tex.Height()
+	rgba := &image.RGBA{Pix: pix, Stride: 4 * width, Rect: image.Rect(0, 0, width, height)}
+	var buf bytes.Buffer
+	err := png.Encode(&buf, rgba)
+	if err != nil {
+		return 0, err
+	}
+
+	// Export image
+	view := e.addBufferView(buf.Bytes(), 0)
+	imageIdx := len(e.g.Images)
+	e.g.Images = append(e.g.Images, Image{BufferView: &view, MimeType: mimePNG})
+
+	// Export sampler
+	magFilter := int(tex.MagFilter())
+	minFilter := int(tex.MinFilter())
+	wrapS := int(tex.WrapS())
+	wrapT := int(tex.WrapT())
+	samplerIdx := len(e.g.Samplers)
+	e.g.Samplers = append(e.g.Samplers, Sampler{MagFilter: &magFilter, MinFilter: &minFilter, WrapS: &wrapS, WrapT: &wrapT})
+
+	idx := len(e.g.Textures)
+	e.g.Textures = append(e.g.Textures, Texture{Sampler: &samplerIdx, Source: imageIdx})
+	e.textures[tex] = idx
+	return idx, nil
+}
@@ -0,0 +1,227 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package loader loads 3D model files of any of the formats supported by the
+// format specific loaders (obj, gltf, stl, ply and collada) into a scene node,
+// detecting the format from the file contents or else from the file extension.
+// Applications which need the options of a format use its loader package directly.
+package loader
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/g3n/engine/animation"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/loader/collada"
+	"github.com/g3n/engine/loader/gltf"
+	"github.com/g3n/engine/loader/obj"
+	"github.com/g3n/engine/loader/ply"
+	"github.com/g3n/engine/loader/stl"
+)
+
+// The supported file formats
+const (
+	FormatOBJ     = "obj"
+	FormatGLTF    = "gltf"
+	FormatGLB     = "glb"
+	FormatSTL     = "stl"
+	FormatPLY     = "ply"
+	FormatCollada = "collada"
+)
+
+// Size in pixels of the points of the point clouds
+const pointSize = 50
+
+// Result is a loaded model file.
+type Result struct {
+	Format     string                 // Detected file format
+	Scene      *core.Node             // Node named after the file containing the loaded objects
+	Animations []*animation.Animation // Animations of the loaded objects (empty if none)
+	Warnings   []string               // Problems which did not prevent the loading
+}
+
+// Load loads the model file at the specified path, detecting its format,
+// and returns the loaded scene and animations and an error.
+func Load(path string) (*Result, error) {
+
+	format, err := DetectFormat(path)
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{Format: format}
+	var node core.INode
+	switch format {
+	case FormatOBJ:
+		node, err = res.loadOBJ(path)
+	case FormatGLTF, FormatGLB:
+		node, err = res.loadGLTF(path, format)
+	case FormatSTL:
+		node, err = res.loadSTL(path)
+	case FormatPLY:
+		node, err = res.loadPLY(path)
+	case FormatCollada:
+		node, err = res.loadCollada(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res.Scene = core.NewNode()
+	res.Scene.SetName(filepath.Base(path))
+	res.Scene.Add(node)
+	return res, nil
+}
+
+// DetectFormat returns the format of the model file at the specified path,
+// detected from the first bytes of the file or else from its extension.
+func DetectFormat(path string) (string, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	head = head[:n]
+
+	// Detects the format from the file contents
+	text := bytes.TrimLeft(head, " \t\r\n")
+	switch {
+	case bytes.HasPrefix(head, []byte("glTF")):
+		return FormatGLB, nil
+	case bytes.HasPrefix(head, []byte("ply")):
+		return FormatPLY, nil
+	case bytes.Contains(head, []byte("<COLLADA")):
+		return FormatCollada, nil
+	case bytes.HasPrefix(text, []byte("solid")) && bytes.Contains(text, []byte("facet")):
+		return FormatSTL, nil
+	}
+	info, err := f.Stat()
+	if err == nil && info.Size() >= 84 && n >= 84 {
+		// Binary STL files have the size declared after their header
+		count := int64(head[80]) | int64(head[81])<<8 | int64(head[82])<<16 | int64(head[83])<<24
+		if info.Size() == 84+count*50 {
+			return FormatSTL, nil
+		}
+	}
+
+	// Detects the format from the file extension
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".obj":
+		return FormatOBJ, nil
+	case ".gltf":
+		return FormatGLTF, nil
+	case ".glb":
+		return FormatGLB, nil
+	case ".stl":
+		return FormatSTL, nil
+	case ".ply":
+		return FormatPLY, nil
+	case ".dae":
+		return FormatCollada, nil
+	}
+	if bytes.HasPrefix(text, []byte("{")) {
+		return FormatGLTF, nil
+	}
+	return "", fmt.Errorf("Unsupported file format:%s", path)
+}
+
+// loadOBJ loads an obj file with the materials of its mtllib file.
+func (res *Result) loadOBJ(path string) (core.INode, error) {
+
+	dec, err := obj.Decode(path, "")
+	if err != nil {
+		return nil, err
+	}
+	node, err := dec.NewGroup()
+	res.Warnings = append(res.Warnings, dec.Warnings...)
+	return node, err
+}
+
+// loadGLTF loads the default scene, or else the first scene, and all the animations of a glTF file.
+func (res *Result) loadGLTF(path, format string) (core.INode, error) {
+
+	var g *gltf.GLTF
+	var err error
+	if format == FormatGLB {
+		g, err = gltf.ParseBin(path)
+	} else {
+		g, err = gltf.ParseJSON(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(g.Scenes) == 0 {
+		return nil, fmt.Errorf("glTF file has no scenes")
+	}
+	sceneIdx := 0
+	if g.Scene != nil {
+		sceneIdx = *g.Scene
+	}
+	node, err := g.LoadScene(sceneIdx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range g.Animations {
+		anim, err := g.LoadAnimation(i)
+		if err != nil {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("animation %d: %v", i, err))
+			continue
+		}
+		res.Animations = append(res.Animations, anim)
+	}
+	return node, nil
+}
+
+// loadSTL loads the triangles of a stl file.
+func (res *Result) loadSTL(path string) (core.INode, error) {
+
+	dec, err := stl.Decode(path)
+	if err != nil {
+		return nil, err
+	}
+	return dec.NewMesh()
+}
+
+// loadPLY loads the faces or the point cloud of a ply file.
+func (res *Result) loadPLY(path string) (core.INode, error) {
+
+	dec, err := ply.Decode(path)
+	if err != nil {
+		return nil, err
+	}
+	return dec.NewNode(pointSize)
+}
+
+// loadCollada loads the scene and the animation of a collada file with the images in its directory.
+func (res *Result) loadCollada(path string) (core.INode, error) {
+
+	dec, err := collada.Decode(path)
+	if err != nil {
+		return nil, err
+	}
+	dec.SetDirImages(filepath.Dir(path))
+	node, err := dec.NewScene()
+	if err != nil {
+		return nil, err
+	}
+	if dec.HasAnimations() {
+		anim, err := dec.NewAnimation(node)
+		if err != nil {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("animation: %v", err))
+		} else {
+			res.Animations = append(res.Animations, anim)
+		}
+	}
+	return node, nil
+}
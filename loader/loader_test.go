@@ -0,0 +1,64 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package loader
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "loader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Binary STL with a header starting with "solid" and a wrong extension
+	stl := make([]byte, 84+50)
+	copy(stl, "solid binary")
+	stl[80] = 1
+
+	tests := []struct {
+		name   string
+		data   []byte
+		format string
+	}{
+		{"model.bin", []byte("glTF\x02\x00\x00\x00"), FormatGLB},
+		{"scan.txt", []byte("ply\nformat ascii 1.0\n"), FormatPLY},
+		{"scene.xml", []byte(`<?xml version="1.0"?><COLLADA version="1.4.1">`), FormatCollada},
+		{"part.txt", []byte("solid part\nfacet normal 0 0 1\n"), FormatSTL},
+		{"part.dat", stl, FormatSTL},
+		{"part.STL", []byte("solid"), FormatSTL},
+		{"cube.obj", []byte("v 0 0 0\n"), FormatOBJ},
+		{"scene.gltf", []byte("{}"), FormatGLTF},
+		{"scene.json", []byte(" {\"asset\":{}}"), FormatGLTF},
+		{"notes.txt", []byte("hello"), ""},
+	}
+	for _, test := range tests {
+		path := filepath.Join(dir, test.name)
+		err := ioutil.WriteFile(path, test.data, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		format, err := DetectFormat(path)
+		if test.format == "" {
+			if err == nil {
+				t.Errorf("%s: detected %s", test.name, format)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if format != test.format {
+			t.Errorf("%s: format %s, want %s", test.name, format, test.format)
+		}
+	}
+}
@@ -33,9 +33,11 @@ type Decoder struct {
 	Matlib        string               // name of the material lib
 	Materials     map[string]*Material // maps material name to object
 	Vertices      math32.ArrayF32      // vertices positions array
+	Colors        math32.ArrayF32      // vertices colors (empty if no vertex has a color)
 	Normals       math32.ArrayF32      // vertices normals
 	Uvs           math32.ArrayF32      // vertices texture coordinates
 	Warnings      []string             // warning messages
+	PointSize     float32              // size of the points of the point clouds
 	line          uint                 // current line number
 	objCurrent    *Object              // current object
 	matCurrent    *Material            // current material
@@ -47,6 +49,8 @@ type Decoder struct {
 type Object struct {
 	Name      string   // Object name
 	Faces     []Face   // Faces
+	Lines     []Line   // Polylines
+	Points    []Point  // Points
 	materials []string // Materials used in this object
 }
 
@@ -59,6 +63,18 @@ type Face struct {
 	Smooth   bool   // Smooth face
 }
 
+// Line contains all information about an object polyline
+type Line struct {
+	Vertices []int  // Indices to the line vertices
+	Material string // Material name
+}
+
+// Point contains all information about an object point
+type Point struct {
+	Vertex   int    // Index to the point vertex
+	Material string // Material name
+}
+
 // Material contains all information about an object material
 type Material struct {
 	Name       string       // Material name
@@ -87,6 +103,10 @@ const (
 	invINDEX = math.MaxUint32
 	objType  = "obj"
 	mtlType  = "mtl"
+	// Material name of the elements defined before any usemtl line
+	noMaterial = "internal default"
+	// Default size of the points of the point clouds
+	defaultPointSize = 50
 )
 
 // Decode decodes the specified obj and mtl files returning a decoder
@@ -135,8 +155,10 @@ func DecodeReader(objreader, mtlreader io.Reader) (*Decoder, error) {
 	dec.Warnings = make([]string, 0)
 	dec.Materials = make(map[string]*Material)
 	dec.Vertices = math32.NewArrayF32(0, 0)
+	dec.Colors = math32.NewArrayF32(0, 0)
 	dec.Normals = math32.NewArrayF32(0, 0)
 	dec.Uvs = math32.NewArrayF32(0, 0)
+	dec.PointSize = defaultPointSize
 	dec.line = 1
 
 	// Parses obj lines
@@ -217,17 +239,27 @@ func DecodeReader(objreader, mtlreader io.Reader) (*Decoder, error) {
 }
 
 // NewGroup creates and returns a group containing as children meshes
-// with all the decoded objects.
+// with the faces, lines with the polylines and points with the point clouds
+// of all the decoded objects.
 // A group is returned even if there is only one object decoded.
 func (dec *Decoder) NewGroup() (*core.Node, error) {
 
 	group := core.NewNode()
 	for i := 0; i < len(dec.Objects); i++ {
-		mesh, err := dec.NewMesh(&dec.Objects[i])
-		if err != nil {
-			return nil, err
+		obj := &dec.Objects[i]
+		if len(obj.Faces) > 0 {
+			mesh, err := dec.NewMesh(obj)
+			if err != nil {
+				return nil, err
+			}
+			group.Add(mesh)
+		}
+		if len(obj.Lines) > 0 {
+			group.Add(dec.NewLines(obj))
+		}
+		if len(obj.Points) > 0 {
+			group.Add(dec.NewPoints(obj))
 		}
-		group.Add(mesh)
 	}
 	return group, nil
 }
@@ -245,18 +277,11 @@ func (dec *Decoder) NewMesh(obj *Object) (*graphic.Mesh, error) {
 	if geom.GroupCount() == 1 {
 		// get Material info from mtl file and ensure it's valid.
 		// substitute default material if it is not.
-		var matDesc *Material
 		var matName string
 		if len(obj.materials) > 0 {
 			matName = obj.materials[0]
 		}
-		matDesc = dec.Materials[matName]
-		if matDesc == nil {
-			matDesc = defaultMat
-			// log warning
-			msg := fmt.Sprintf("could not find material for %s. using default material.", obj.Name)
-			dec.appendWarn(objType, msg)
-		}
+		matDesc := dec.material(obj, matName)
 
 		// Creates material for mesh
 		mat := material.NewStandard(&matDesc.Diffuse)
@@ -280,18 +305,11 @@ func (dec *Decoder) NewMesh(obj *Object) (*graphic.Mesh, error) {
 
 		// get Material info from mtl file and ensure it's valid.
 		// substitute default material if it is not.
-		var matDesc *Material
 		var matName string
 		if len(obj.materials) > group.Matindex {
 			matName = obj.materials[group.Matindex]
 		}
-		matDesc = dec.Materials[matName]
-		if matDesc == nil {
-			matDesc = defaultMat
-			// log warning
-			msg := fmt.Sprintf("could not find material for %s. using default material.", obj.Name)
-			dec.appendWarn(objType, msg)
-		}
+		matDesc := dec.material(obj, matName)
 
 		// Creates material for mesh
 		matGroup := material.NewStandard(&matDesc.Diffuse)
@@ -310,7 +328,85 @@ func (dec *Decoder) NewMesh(obj *Object) (*graphic.Mesh, error) {
 	return mesh, nil
 }
 
-// NewGeometry generates and returns a geometry from the specified object
+// NewLines creates and returns a lines graphic with the polylines of the specified object.
+// The lines are drawn with the vertex colors or else with the diffuse colors of their materials.
+func (dec *Decoder) NewLines(obj *Object) *graphic.Lines {
+
+	geom := geometry.NewGeometry()
+	positions := math32.NewArrayF32(0, 0)
+	colors := math32.NewArrayF32(0, 0)
+	indices := math32.NewArrayU32(0, 0)
+	for _, line := range obj.Lines {
+		matDesc := dec.material(obj, line.Material)
+		for idx, vidx := range line.Vertices {
+			if idx > 0 {
+				pos := uint32(positions.Size() / 3)
+				indices.Append(pos-1, pos)
+			}
+			dec.copyColoredVertex(&positions, &colors, vidx, &matDesc.Diffuse)
+		}
+	}
+	geom.SetIndices(indices)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(colors).AddAttrib(gls.VertexColor))
+	return graphic.NewLines(geom, material.NewBasic())
+}
+
+// NewPoints creates and returns a points graphic with the point cloud of the specified object
+// and the size of the decoder PointSize field.
+// The points are drawn with the vertex colors or else with the diffuse colors of their materials.
+func (dec *Decoder) NewPoints(obj *Object) *graphic.Points {
+
+	geom := geometry.NewGeometry()
+	positions := math32.NewArrayF32(0, 0)
+	colors := math32.NewArrayF32(0, 0)
+	for _, point := range obj.Points {
+		matDesc := dec.material(obj, point.Material)
+		dec.copyColoredVertex(&positions, &colors, point.Vertex, &matDesc.Diffuse)
+	}
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(colors).AddAttrib(gls.VertexColor))
+	mat := material.NewPoint(&math32.Color{R: 1, G: 1, B: 1})
+	mat.SetSize(dec.PointSize)
+	points := graphic.NewPoints(geom, mat)
+	points.ShaderDefines.Set("VERTEX_COLORS", "")
+	return points
+}
+
+// copyColoredVertex appends the position of the specified vertex and its color,
+// or the specified color if the vertices have no colors, to the specified arrays.
+func (dec *Decoder) copyColoredVertex(positions, colors *math32.ArrayF32, vidx int, color *math32.Color) {
+
+	var vec3 math32.Vector3
+	dec.Vertices.GetVector3(3*vidx, &vec3)
+	positions.AppendVector3(&vec3)
+	if dec.Colors.Size() > 0 {
+		dec.Colors.GetVector3(3*vidx, &vec3)
+		colors.AppendVector3(&vec3)
+	} else {
+		colors.AppendColor(color)
+	}
+}
+
+// material returns the descriptor of the material with the specified name used by the
+// specified object or the default material if it is not found. A warning is logged if
+// the object uses a material which was not found, but not if it has no material at all.
+func (dec *Decoder) material(obj *Object, name string) *Material {
+
+	matDesc := dec.Materials[name]
+	if matDesc != nil {
+		return matDesc
+	}
+	if name != "" && name != noMaterial {
+		msg := fmt.Sprintf("could not find material for %s. using default material.", obj.Name)
+		dec.appendWarn(objType, msg)
+	}
+	return defaultMat
+}
+
+// NewGeometry generates and returns a geometry from the specified object.
+// If the vertices have colors the geometry sets the VERTEX_COLORS shader define
+// so the standard and physical materials multiply their colors by them.
 func (dec *Decoder) NewGeometry(obj *Object) (*geometry.Geometry, error) {
 
 	geom := geometry.NewGeometry()
@@ -319,6 +415,7 @@ func (dec *Decoder) NewGeometry(obj *Object) (*geometry.Geometry, error) {
 	positions := math32.NewArrayF32(0, 0)
 	normals := math32.NewArrayF32(0, 0)
 	uvs := math32.NewArrayF32(0, 0)
+	colors := math32.NewArrayF32(0, 0)
 	indices := math32.NewArrayU32(0, 0)
 
 	// copy all vertex info from the decoded Object, face and index to the geometry
@@ -330,6 +427,11 @@ func (dec *Decoder) NewGeometry(obj *Object) (*geometry.Geometry, error) {
 		// Copy vertex position and append to geometry
 		dec.Vertices.GetVector3(3*face.Vertices[idx], &vec3)
 		positions.AppendVector3(&vec3)
+		// Copy vertex color if the vertices have colors
+		if dec.Colors.Size() > 0 {
+			dec.Colors.GetVector3(3*face.Vertices[idx], &vec3)
+			colors.AppendVector3(&vec3)
+		}
 		// Copy vertex normal and append to geometry
 		if face.Normals[idx] != invINDEX {
 			dec.Normals.GetVector3(3*face.Normals[idx], &vec3)
@@ -366,6 +468,10 @@ func (dec *Decoder) NewGeometry(obj *Object) (*geometry.Geometry, error) {
 	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
 	geom.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
 	geom.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	if colors.Size() > 0 {
+		geom.AddVBO(gls.NewVBO(colors).AddAttrib(gls.VertexColor))
+		geom.ShaderDefines.Set("VERTEX_COLORS", "")
+	}
 
 	return geom, nil
 }
@@ -460,6 +566,12 @@ func (dec *Decoder) parseObjLine(line string) error {
 	// Face vertex
 	case "f":
 		return dec.parseFace(fields[1:])
+	// Polyline
+	case "l":
+		return dec.parseLine(fields[1:])
+	// Points
+	case "p":
+		return dec.parsePoints(fields[1:])
 	// Use material
 	case "usemtl":
 		return dec.parseUsemtl(fields[1:])
@@ -505,8 +617,9 @@ func makeObject(name string) Object {
 	return ob
 }
 
-// Parses a vertex position line
+// Parses a vertex position line with an optional color (non-standard extension)
 // v <x> <y> <z> [w]
+// v <x> <y> <z> <r> <g> <b>
 func (dec *Decoder) parseVertex(fields []string) error {
 
 	if len(fields) < 3 {
@@ -519,6 +632,24 @@ func (dec *Decoder) parseVertex(fields []string) error {
 		}
 		dec.Vertices.Append(float32(val))
 	}
+
+	// Vertices without color are white if other vertices have colors
+	if len(fields) < 6 {
+		if dec.Colors.Size() > 0 {
+			dec.Colors.Append(1, 1, 1)
+		}
+		return nil
+	}
+	for dec.Colors.Size() < dec.Vertices.Size()-3 {
+		dec.Colors.Append(1, 1, 1)
+	}
+	for _, f := range fields[3:6] {
+		val, err := strconv.ParseFloat(f, 32)
+		if err != nil {
+			return err
+		}
+		dec.Colors.Append(float32(val))
+	}
 	return nil
 }
 
@@ -585,7 +716,7 @@ func (dec *Decoder) parseFace(fields []string) error {
 	} else {
 		// TODO (quillaja): do something better than spamming warnings for each line
 		// dec.appendWarn(objType, "No material defined")
-		face.Material = "internal default" // causes error on in NewGeom() if ""
+		face.Material = noMaterial // causes error on in NewGeom() if ""
 		// dec.matCurrent = defaultMat
 	}
 	face.Smooth = dec.smoothCurrent
@@ -594,70 +725,32 @@ func (dec *Decoder) parseFace(fields []string) error {
 
 		// Separate the current field in its components: v vt vn
 		vfields := strings.Split(f, "/")
-		if len(vfields) < 1 {
-			return dec.formatError("Face field with no parts")
-		}
 
 		// Get the index of this vertex position (must always exist)
-		val, err := strconv.ParseInt(vfields[0], 10, 32)
+		idx, err := dec.parseIndex(vfields[0], dec.Vertices.Size()/3, "vertex")
 		if err != nil {
 			return err
 		}
-
-		// Positive index is an absolute vertex index
-		if val > 0 {
-			face.Vertices[pos] = int(val - 1)
-			// Negative vertex index is relative to the last parsed vertex
-		} else if val < 0 {
-			current := (len(dec.Vertices) / 3) - 1
-			face.Vertices[pos] = current + int(val) + 1
-			// Vertex index could never be 0
-		} else {
-			return dec.formatError("Face vertex index value equal to 0")
-		}
+		face.Vertices[pos] = idx
 
 		// Get the index of this vertex UV coordinate (optional)
+		face.Uvs[pos] = invINDEX
 		if len(vfields) > 1 && len(vfields[1]) > 0 {
-			val, err := strconv.ParseInt(vfields[1], 10, 32)
+			idx, err := dec.parseIndex(vfields[1], dec.Uvs.Size()/2, "uv")
 			if err != nil {
 				return err
 			}
-
-			// Positive index is an absolute UV index
-			if val > 0 {
-				face.Uvs[pos] = int(val - 1)
-				// Negative vertex index is relative to the last parsed uv
-			} else if val < 0 {
-				current := (len(dec.Uvs) / 2) - 1
-				face.Uvs[pos] = current + int(val) + 1
-				// UV index could never be 0
-			} else {
-				return dec.formatError("Face uv index value equal to 0")
-			}
-		} else {
-			face.Uvs[pos] = invINDEX
+			face.Uvs[pos] = idx
 		}
 
 		// Get the index of this vertex normal (optional)
-		if len(vfields) >= 3 {
-			val, err = strconv.ParseInt(vfields[2], 10, 32)
+		face.Normals[pos] = invINDEX
+		if len(vfields) > 2 && len(vfields[2]) > 0 {
+			idx, err := dec.parseIndex(vfields[2], dec.Normals.Size()/3, "normal")
 			if err != nil {
 				return err
 			}
-
-			// Positive index is an absolute normal index
-			if val > 0 {
-				face.Normals[pos] = int(val - 1)
-				// Negative vertex index is relative to the last parsed normal
-			} else if val < 0 {
-				current := (len(dec.Normals) / 3) - 1
-				face.Normals[pos] = current + int(val) + 1
-				// Normal index could never be 0
-			} else {
-				return dec.formatError("Face normal index value equal to 0")
-			}
-		} else {
-			face.Normals[pos] = invINDEX
+			face.Normals[pos] = idx
 		}
 	}
 	// Appends this face to the current object
@@ -665,6 +758,85 @@ func (dec *Decoder) parseFace(fields []string) error {
 	return nil
 }
 
+// parseLine parses a polyline decription line:
+// l v1[/vt1] v2[/vt2] ...
+func (dec *Decoder) parseLine(fields []string) error {
+
+	if len(fields) < 2 {
+		return dec.formatError("Line with less 2 fields")
+	}
+	var line Line
+	line.Vertices = make([]int, len(fields))
+	line.Material = dec.currentMaterial()
+	for pos, f := range fields {
+		// The texture coordinates are ignored
+		idx, err := dec.parseIndex(strings.Split(f, "/")[0], dec.Vertices.Size()/3, "vertex")
+		if err != nil {
+			return err
+		}
+		line.Vertices[pos] = idx
+	}
+	dec.objCurrent.Lines = append(dec.objCurrent.Lines, line)
+	return nil
+}
+
+// parsePoints parses a points decription line:
+// p v1 v2 ...
+func (dec *Decoder) parsePoints(fields []string) error {
+
+	if len(fields) < 1 {
+		return dec.formatError("Points with no fields")
+	}
+	material := dec.currentMaterial()
+	for _, f := range fields {
+		idx, err := dec.parseIndex(f, dec.Vertices.Size()/3, "vertex")
+		if err != nil {
+			return err
+		}
+		dec.objCurrent.Points = append(dec.objCurrent.Points, Point{Vertex: idx, Material: material})
+	}
+	return nil
+}
+
+// currentMaterial creates the current object if necessary, adds the current material
+// to it and returns the name of the current material for a new line or points element.
+func (dec *Decoder) currentMaterial() string {
+
+	if dec.objCurrent == nil {
+		dec.parseObject([]string{fmt.Sprintf("unnamed%d", dec.line)})
+	}
+	if dec.matCurrent == nil {
+		return noMaterial
+	}
+	if len(dec.objCurrent.materials) == 0 {
+		dec.objCurrent.materials = append(dec.objCurrent.materials, dec.matCurrent.Name)
+	}
+	return dec.matCurrent.Name
+}
+
+// parseIndex parses an element index which is absolute when positive, starting at 1,
+// or relative to the last of the specified number of parsed values when negative,
+// and returns it as an absolute index starting at 0.
+func (dec *Decoder) parseIndex(field string, count int, kind string) (int, error) {
+
+	val, err := strconv.ParseInt(field, 10, 32)
+	if err != nil {
+		return 0, dec.formatError(fmt.Sprintf("Invalid %s index '%s'", kind, field))
+	}
+	// Index could never be 0
+	if val == 0 {
+		return 0, dec.formatError(fmt.Sprintf("Element %s index value equal to 0", kind))
+	}
+	idx := int(val) - 1
+	if val < 0 {
+		idx = count + int(val)
+	}
+	if idx < 0 || idx >= count {
+		return 0, dec.formatError(fmt.Sprintf("Index %d out of range of the %d parsed %s values", val, count, kind))
+	}
+	return idx, nil
+}
+
 // parseUsemtl parses a "usemtl" decription line:
 // usemtl <name>
 func (dec *Decoder) parseUsemtl(fields []string) error {
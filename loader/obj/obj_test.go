@@ -0,0 +1,150 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package obj
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+)
+
+// Object with colored vertices (the second one without color), a face,
+// a polyline and a point cloud using absolute and relative indices.
+const testOBJ = `o scan
+v 0 0 0 1 0 0
+v 1 0 0
+v 1 1 0 0 0 1
+v 0 1 0 0.5 0.5 0.5
+f 1 2 3
+f -4 -2 -1
+l 1 2/1 -1
+p 2 -3 4
+`
+
+func TestDecodeElements(t *testing.T) {
+
+	dec, err := DecodeReader(strings.NewReader(testOBJ), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dec.Objects) != 1 {
+		t.Fatalf("objects %v", dec.Objects)
+	}
+	obj := dec.Objects[0]
+	var faces [][]int
+	for _, face := range obj.Faces {
+		faces = append(faces, face.Vertices)
+	}
+	var points []int
+	for _, point := range obj.Points {
+		points = append(points, point.Vertex)
+	}
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"name", obj.Name, "scan"},
+		{"vertices", []float32(dec.Vertices), []float32{0, 0, 0, 1, 0, 0, 1, 1, 0, 0, 1, 0}},
+		{"colors", []float32(dec.Colors), []float32{1, 0, 0, 1, 1, 1, 0, 0, 1, 0.5, 0.5, 0.5}},
+		{"faces", faces, [][]int{{0, 1, 2}, {0, 2, 3}}},
+		{"lines", len(obj.Lines), 1},
+		{"line vertices", obj.Lines[0].Vertices, []int{0, 1, 3}},
+		{"line material", obj.Lines[0].Material, noMaterial},
+		{"points", points, []int{1, 1, 3}},
+	}
+	for _, test := range tests {
+		if !reflect.DeepEqual(test.got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, test.got, test.want)
+		}
+	}
+}
+
+func TestDecodeColors(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		src    string
+		colors []float32
+	}{
+		{"no colors", "v 0 0 0\nv 1 0 0\n", []float32{}},
+		{"homogeneous coordinate", "v 0 0 0 1\n", []float32{}},
+		{"first uncolored", "v 0 0 0\nv 1 0 0\nv 0 1 0 0 1 0\n", []float32{1, 1, 1, 1, 1, 1, 0, 1, 0}},
+		{"last uncolored", "v 0 0 0 0 0 1\nv 1 0 0\n", []float32{0, 0, 1, 1, 1, 1}},
+	}
+	for _, test := range tests {
+		dec, err := DecodeReader(strings.NewReader(test.src), nil)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual([]float32(dec.Colors), test.colors) {
+			t.Errorf("%s: colors %v, want %v", test.name, dec.Colors, test.colors)
+		}
+	}
+}
+
+// buffer returns the buffer of the VBO of the specified geometry attribute.
+func buffer(geom *geometry.Geometry, atype gls.AttribType) []float32 {
+
+	return *geom.VBO(atype).Buffer()
+}
+
+func TestNewLinesPoints(t *testing.T) {
+
+	dec, err := DecodeReader(strings.NewReader(testOBJ), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := &dec.Objects[0]
+	lines := dec.NewLines(obj).GetGeometry()
+	points := dec.NewPoints(obj).GetGeometry()
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"line indices", []uint32(lines.Indices()), []uint32{0, 1, 1, 2}},
+		{"line positions", buffer(lines, gls.VertexPosition), []float32{0, 0, 0, 1, 0, 0, 0, 1, 0}},
+		{"line colors", buffer(lines, gls.VertexColor), []float32{1, 0, 0, 1, 1, 1, 0.5, 0.5, 0.5}},
+		{"point positions", buffer(points, gls.VertexPosition), []float32{1, 0, 0, 1, 0, 0, 0, 1, 0}},
+		{"point colors", buffer(points, gls.VertexColor), []float32{1, 1, 1, 1, 1, 1, 0.5, 0.5, 0.5}},
+	}
+	for _, test := range tests {
+		if !reflect.DeepEqual(test.got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, test.got, test.want)
+		}
+	}
+}
+
+func TestDecodeIndexErrors(t *testing.T) {
+
+	const vertices = "v 0 0 0\nv 1 0 0\nv 0 1 0\n"
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"face index 0", "f 0 1 2\n"},
+		{"face index out of range", "f 1 2 4\n"},
+		{"face relative index out of range", "f -4 -2 -1\n"},
+		{"face uv out of range", "vt 0 0\nf 1/2 2/1 3/1\n"},
+		{"face normal out of range", "vn 0 0 1\nf 1//1 2//1 3//2\n"},
+		{"face invalid index", "f 1 2 x\n"},
+		{"face with two vertices", "f 1 2\n"},
+		{"line index 0", "l 1 0\n"},
+		{"line index out of range", "l 1 4\n"},
+		{"line with one vertex", "l 1\n"},
+		{"point index out of range", "p 1 -4\n"},
+	}
+	for _, test := range tests {
+		_, err := DecodeReader(strings.NewReader(vertices+test.src), nil)
+		if err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+}
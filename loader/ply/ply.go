@@ -0,0 +1,433 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ply is used to parse the Polygon File Format (*.ply), also known as the
+// Stanford Triangle Format, in its ASCII and binary little and big endian variants,
+// as produced by 3D scanners and photogrammetry pipelines.
+// Vertex positions, normals, colors and texture coordinates and polygonal faces are decoded.
+// Basic format info: http://paulbourke.net/dataformats/ply/
+package ply
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// The PLY formats.
+const (
+	ASCII           = "ascii"
+	BinaryLittleEnd = "binary_little_endian"
+	BinaryBigEnd    = "binary_big_endian"
+)
+
+// Decoder contains all decoded data from a PLY file
+type Decoder struct {
+	Format    string          // File format (ASCII, BinaryLittleEnd or BinaryBigEnd)
+	Comments  []string        // Comments of the header
+	Elements  []Element       // Elements declared in the header
+	Positions math32.ArrayF32 // Vertex positions
+	Normals   math32.ArrayF32 // Vertex normals (empty if not present)
+	Colors    math32.ArrayF32 // Vertex RGB colors from 0 to 1 (empty if not present)
+	Uvs       math32.ArrayF32 // Vertex texture coordinates (empty if not present)
+	Indices   math32.ArrayU32 // Indices of the vertices of the faces triangulated as fans (empty for point clouds)
+	line      int             // current header line number
+}
+
+// Element is an element declared in the header of a PLY file, such as "vertex" or "face".
+type Element struct {
+	Name       string     // Element name
+	Count      int        // Number of element instances
+	Properties []Property // Properties of each instance
+}
+
+// Property is a property of an element declared in the header of a PLY file.
+type Property struct {
+	Name      string // Property name
+	Type      string // Type of the property or of the list items
+	CountType string // Type of the number of items if the property is a list (empty otherwise)
+}
+
+// Size in bytes of the binary representation of each PLY type
+var typeSizes = map[string]int{
+	"char": 1, "int8": 1, "uchar": 1, "uint8": 1,
+	"short": 2, "int16": 2, "ushort": 2, "uint16": 2,
+	"int": 4, "int32": 4, "uint": 4, "uint32": 4,
+	"float": 4, "float32": 4, "double": 8, "float64": 8,
+}
+
+// Decode decodes the specified PLY file returning a decoder object and an error.
+func Decode(path string) (*Decoder, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodeReader(f)
+}
+
+// DecodeReader decodes PLY data from the specified reader returning a decoder object and an error.
+func DecodeReader(reader io.Reader) (*Decoder, error) {
+
+	dec := new(Decoder)
+	dec.Positions = math32.NewArrayF32(0, 0)
+	dec.Normals = math32.NewArrayF32(0, 0)
+	dec.Colors = math32.NewArrayF32(0, 0)
+	dec.Uvs = math32.NewArrayF32(0, 0)
+	dec.Indices = math32.NewArrayU32(0, 0)
+
+	bufin := bufio.NewReader(reader)
+	err := dec.parseHeader(bufin)
+	if err != nil {
+		return nil, err
+	}
+	var rd valueReader
+	switch dec.Format {
+	case ASCII:
+		scanner := bufio.NewScanner(bufin)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		scanner.Split(bufio.ScanWords)
+		rd = &asciiReader{scanner: scanner}
+	case BinaryLittleEnd:
+		rd = &binaryReader{reader: bufin, order: binary.LittleEndian}
+	case BinaryBigEnd:
+		rd = &binaryReader{reader: bufin, order: binary.BigEndian}
+	}
+	for i := range dec.Elements {
+		err = dec.parseElement(rd, &dec.Elements[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dec, nil
+}
+
+// NewGeometry creates and returns a geometry with the decoded vertices and faces.
+// Point clouds have no indices.
+func (dec *Decoder) NewGeometry() *geometry.Geometry {
+
+	geom := geometry.NewGeometry()
+	if dec.Indices.Size() > 0 {
+		geom.SetIndices(dec.Indices)
+	}
+	geom.AddVBO(gls.NewVBO(dec.Positions).AddAttrib(gls.VertexPosition))
+	if dec.Normals.Size() > 0 {
+		geom.AddVBO(gls.NewVBO(dec.Normals).AddAttrib(gls.VertexNormal))
+	}
+	if dec.Colors.Size() > 0 {
+		geom.AddVBO(gls.NewVBO(dec.Colors).AddAttrib(gls.VertexColor))
+	}
+	if dec.Uvs.Size() > 0 {
+		geom.AddVBO(gls.NewVBO(dec.Uvs).AddAttrib(gls.VertexTexcoord))
+	}
+	return geom
+}
+
+// NewMesh creates and returns a mesh with the decoded faces. Meshes with vertex colors
+// use an unlit basic material which shows the colors and other meshes a gray standard material.
+// If the vertices have no normals, they are calculated from the faces.
+func (dec *Decoder) NewMesh() (*graphic.Mesh, error) {
+
+	if dec.Indices.Size() == 0 {
+		return nil, fmt.Errorf("PLY data has no faces")
+	}
+	if dec.Colors.Size() > 0 {
+		return graphic.NewMesh(dec.NewGeometry(), material.NewBasic()), nil
+	}
+	if dec.Normals.Size() == 0 {
+		normals := math32.NewArrayF32(dec.Positions.Size(), dec.Positions.Size())
+		dec.Normals = geometry.CalculateNormals(dec.Indices, dec.Positions, normals)
+	}
+	return graphic.NewMesh(dec.NewGeometry(), material.NewStandard(&math32.Color{R: 0.7, G: 0.7, B: 0.7})), nil
+}
+
+// NewPoints creates and returns a points graphic with the decoded vertices, of the specified
+// size, showing the vertex colors if present or else white.
+func (dec *Decoder) NewPoints(size float32) *graphic.Points {
+
+	geom := dec.NewGeometry()
+	mat := material.NewPoint(&math32.Color{R: 1, G: 1, B: 1})
+	mat.SetSize(size)
+	points := graphic.NewPoints(geom, mat)
+	if dec.Colors.Size() > 0 {
+		points.ShaderDefines.Set("VERTEX_COLORS", "")
+	}
+	return points
+}
+
+// NewNode creates and returns a mesh if the decoded data has faces
+// or else a points graphic with the specified point size.
+func (dec *Decoder) NewNode(pointSize float32) (core.INode, error) {
+
+	if dec.Indices.Size() > 0 {
+		return dec.NewMesh()
+	}
+	return dec.NewPoints(pointSize), nil
+}
+
+// parseHeader parses the header up to the end_header line.
+func (dec *Decoder) parseHeader(bufin *bufio.Reader) error {
+
+	for {
+		line, err := bufin.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				return dec.formatError("unexpected end of header")
+			}
+			return err
+		}
+		dec.line++
+		line = strings.TrimRight(line, "\r\n")
+		fields := strings.Fields(line)
+		if dec.line == 1 {
+			if line != "ply" {
+				return dec.formatError("missing 'ply' magic number")
+			}
+			continue
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "format":
+			if len(fields) < 2 {
+				return dec.formatError("invalid format")
+			}
+			switch fields[1] {
+			case ASCII, BinaryLittleEnd, BinaryBigEnd:
+				dec.Format = fields[1]
+			default:
+				return dec.formatError("unsupported format " + fields[1])
+			}
+		case "comment", "obj_info":
+			dec.Comments = append(dec.Comments, strings.TrimSpace(strings.TrimPrefix(line, fields[0])))
+		case "element":
+			if len(fields) != 3 {
+				return dec.formatError("invalid element")
+			}
+			count, err := strconv.Atoi(fields[2])
+			if err != nil || count < 0 {
+				return dec.formatError("invalid element count")
+			}
+			dec.Elements = append(dec.Elements, Element{Name: fields[1], Count: count})
+		case "property":
+			if len(dec.Elements) == 0 {
+				return dec.formatError("property without element")
+			}
+			var prop Property
+			if len(fields) == 5 && fields[1] == "list" {
+				prop = Property{Name: fields[4], Type: fields[3], CountType: fields[2]}
+				if _, ok := typeSizes[prop.CountType]; !ok {
+					return dec.formatError("invalid property type " + prop.CountType)
+				}
+			} else if len(fields) == 3 {
+				prop = Property{Name: fields[2], Type: fields[1]}
+			} else {
+				return dec.formatError("invalid property")
+			}
+			if _, ok := typeSizes[prop.Type]; !ok {
+				return dec.formatError("invalid property type " + prop.Type)
+			}
+			el := &dec.Elements[len(dec.Elements)-1]
+			el.Properties = append(el.Properties, prop)
+		case "end_header":
+			if dec.Format == "" {
+				return dec.formatError("missing format")
+			}
+			return nil
+		default:
+			return dec.formatError("invalid header keyword " + fields[0])
+		}
+	}
+}
+
+// parseElement reads the instances of the specified element, keeping
+// the vertex and face data and skipping the other elements.
+func (dec *Decoder) parseElement(rd valueReader, el *Element) error {
+
+	// Destination of each vertex property
+	var dest []*float32
+	var vertex [11]float32 // x, y, z, nx, ny, nz, r, g, b, u, v
+	var hasNormal, hasColor, hasUv bool
+	if el.Name == "vertex" {
+		dest = make([]*float32, len(el.Properties))
+		for i, prop := range el.Properties {
+			idx := -1
+			switch prop.Name {
+			case "x", "y", "z":
+				idx = int(prop.Name[0] - 'x')
+			case "nx", "ny", "nz":
+				idx = 3 + int(prop.Name[1]-'x')
+				hasNormal = true
+			case "red", "diffuse_red", "r":
+				idx = 6
+				hasColor = true
+			case "green", "diffuse_green", "g":
+				idx = 7
+			case "blue", "diffuse_blue", "b":
+				idx = 8
+			case "u", "s", "texture_u", "texture_s":
+				idx = 9
+				hasUv = true
+			case "v", "t", "texture_v", "texture_t":
+				idx = 10
+			}
+			if idx >= 0 && prop.CountType == "" {
+				dest[i] = &vertex[idx]
+			}
+		}
+	}
+
+	var face []uint32
+	vcount := uint32(dec.Positions.Size() / 3)
+	for n := 0; n < el.Count; n++ {
+		for i, prop := range el.Properties {
+			// List property
+			if prop.CountType != "" {
+				count, err := rd.read(prop.CountType)
+				if err != nil {
+					return err
+				}
+				isFace := el.Name == "face" && (prop.Name == "vertex_indices" || prop.Name == "vertex_index")
+				face = face[:0]
+				for j := 0; j < int(count); j++ {
+					v, err := rd.read(prop.Type)
+					if err != nil {
+						return err
+					}
+					if isFace {
+						if v < 0 || uint32(v) >= vcount {
+							return fmt.Errorf("invalid vertex index %v in face %d", v, n)
+						}
+						face = append(face, uint32(v))
+					}
+				}
+				for j := 1; j+1 < len(face); j++ {
+					dec.Indices.Append(face[0], face[j], face[j+1])
+				}
+				continue
+			}
+			// Scalar property
+			v, err := rd.read(prop.Type)
+			if err != nil {
+				return err
+			}
+			if dest != nil && dest[i] != nil {
+				// Integer colors range from 0 to the maximum value of their type
+				if dest[i] == &vertex[6] || dest[i] == &vertex[7] || dest[i] == &vertex[8] {
+					v /= colorScale(prop.Type)
+				}
+				*dest[i] = float32(v)
+			}
+		}
+		if el.Name == "vertex" {
+			dec.Positions.Append(vertex[0], vertex[1], vertex[2])
+			if hasNormal {
+				dec.Normals.Append(vertex[3], vertex[4], vertex[5])
+			}
+			if hasColor {
+				dec.Colors.Append(vertex[6], vertex[7], vertex[8])
+			}
+			if hasUv {
+				dec.Uvs.Append(vertex[9], vertex[10])
+			}
+		}
+	}
+	return nil
+}
+
+// colorScale returns the value which scales colors of the specified type to the range from 0 to 1.
+func colorScale(ptype string) float64 {
+
+	switch ptype {
+	case "char", "int8":
+		return math.MaxInt8
+	case "uchar", "uint8":
+		return math.MaxUint8
+	case "short", "int16":
+		return math.MaxInt16
+	case "ushort", "uint16":
+		return math.MaxUint16
+	case "int", "int32":
+		return math.MaxInt32
+	case "uint", "uint32":
+		return math.MaxUint32
+	}
+	return 1
+}
+
+func (dec *Decoder) formatError(msg string) error {
+
+	return fmt.Errorf("%s in header line:%d", msg, dec.line)
+}
+
+// valueReader is the interface of the readers of the values of the ASCII and binary formats.
+type valueReader interface {
+	read(ptype string) (float64, error)
+}
+
+// asciiReader reads the values of the ASCII format.
+type asciiReader struct {
+	scanner *bufio.Scanner
+}
+
+// read reads the next value.
+func (r *asciiReader) read(ptype string) (float64, error) {
+
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return 0, err
+		}
+		return 0, io.ErrUnexpectedEOF
+	}
+	return strconv.ParseFloat(r.scanner.Text(), 64)
+}
+
+// binaryReader reads the values of the binary formats.
+type binaryReader struct {
+	reader io.Reader
+	order  binary.ByteOrder
+	buf    [8]byte
+}
+
+// read reads the next value of the specified type.
+func (r *binaryReader) read(ptype string) (float64, error) {
+
+	b := r.buf[:typeSizes[ptype]]
+	_, err := io.ReadFull(r.reader, b)
+	if err != nil {
+		return 0, err
+	}
+	switch ptype {
+	case "char", "int8":
+		return float64(int8(b[0])), nil
+	case "uchar", "uint8":
+		return float64(b[0]), nil
+	case "short", "int16":
+		return float64(int16(r.order.Uint16(b))), nil
+	case "ushort", "uint16":
+		return float64(r.order.Uint16(b)), nil
+	case "int", "int32":
+		return float64(int32(r.order.Uint32(b))), nil
+	case "uint", "uint32":
+		return float64(r.order.Uint32(b)), nil
+	case "float", "float32":
+		return float64(math.Float32frombits(r.order.Uint32(b))), nil
+	default:
+		return math.Float64frombits(r.order.Uint64(b)), nil
+	}
+}
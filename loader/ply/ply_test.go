@@ -0,0 +1,173 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ply
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Quad of the test files: vertices with float positions and uchar colors,
+// one quad face and an edge element which must be skipped.
+var (
+	testPositions = []float32{0, 0, 0, 1, 0, 0, 1, 1, 0, 0, 1, 0.5}
+	testColors    = []uint8{255, 0, 0, 0, 255, 0, 0, 0, 255, 51, 102, 153}
+	testFace      = []int32{0, 1, 2, 3}
+	testEdge      = []int32{0, 2}
+)
+
+const testHeader = `ply
+format %s 1.0
+comment test quad
+element vertex 4
+property float x
+property float y
+property float z
+property uchar red
+property uchar green
+property uchar blue
+element face 1
+property list uchar int vertex_indices
+element edge 1
+property int vertex1
+property int vertex2
+end_header
+`
+
+// newTestPLY returns the test quad in the specified format.
+func newTestPLY(format string) []byte {
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, testHeader, format)
+	if format == ASCII {
+		for i := 0; i < 4; i++ {
+			p := testPositions[3*i:]
+			c := testColors[3*i:]
+			fmt.Fprintf(&buf, "%v %v %v %d %d %d\n", p[0], p[1], p[2], c[0], c[1], c[2])
+		}
+		fmt.Fprintf(&buf, "4 %d %d %d %d\n", testFace[0], testFace[1], testFace[2], testFace[3])
+		fmt.Fprintf(&buf, "%d %d\n", testEdge[0], testEdge[1])
+		return buf.Bytes()
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if format == BinaryBigEnd {
+		order = binary.BigEndian
+	}
+	for i := 0; i < 4; i++ {
+		binary.Write(&buf, order, testPositions[3*i:3*i+3])
+		buf.Write(testColors[3*i : 3*i+3])
+	}
+	buf.WriteByte(4)
+	binary.Write(&buf, order, testFace)
+	binary.Write(&buf, order, testEdge)
+	return buf.Bytes()
+}
+
+func TestDecodeFormats(t *testing.T) {
+
+	colors := make([]float32, len(testColors))
+	for i, c := range testColors {
+		colors[i] = float32(c) / 255
+	}
+	for _, format := range []string{ASCII, BinaryLittleEnd, BinaryBigEnd} {
+		dec, err := DecodeReader(bytes.NewReader(newTestPLY(format)))
+		if err != nil {
+			t.Errorf("%s: %v", format, err)
+			continue
+		}
+		if dec.Format != format {
+			t.Errorf("%s: format %s", format, dec.Format)
+		}
+		if !reflect.DeepEqual(dec.Comments, []string{"test quad"}) {
+			t.Errorf("%s: comments %q", format, dec.Comments)
+		}
+		if len(dec.Elements) != 3 || dec.Elements[2].Name != "edge" {
+			t.Errorf("%s: elements %v", format, dec.Elements)
+		}
+		if !reflect.DeepEqual([]float32(dec.Positions), testPositions) {
+			t.Errorf("%s: positions %v, want %v", format, dec.Positions, testPositions)
+		}
+		if len(dec.Colors) != len(colors) {
+			t.Errorf("%s: colors %v, want %v", format, dec.Colors, colors)
+		} else {
+			for i := range colors {
+				if math.Abs(float64(dec.Colors[i]-colors[i])) > 1e-6 {
+					t.Errorf("%s: colors %v, want %v", format, dec.Colors, colors)
+					break
+				}
+			}
+		}
+		if dec.Normals.Size() != 0 || dec.Uvs.Size() != 0 {
+			t.Errorf("%s: normals %v, uvs %v", format, dec.Normals, dec.Uvs)
+		}
+		want := math32.ArrayU32{0, 1, 2, 0, 2, 3}
+		if !reflect.DeepEqual(dec.Indices, want) {
+			t.Errorf("%s: indices %v, want %v", format, dec.Indices, want)
+		}
+	}
+}
+
+func TestDecodePointCloud(t *testing.T) {
+
+	data := "ply\nformat ascii 1.0\nelement vertex 2\nproperty double x\nproperty double y\nproperty double z\n" +
+		"property float nx\nproperty float ny\nproperty float nz\nproperty float u\nproperty float v\nend_header\n" +
+		"1 2 3 0 0 1 0.25 0.5\n4 5 6 0 1 0 0.75 1\n"
+	dec, err := DecodeReader(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		got  math32.ArrayF32
+		want []float32
+	}{
+		{"positions", dec.Positions, []float32{1, 2, 3, 4, 5, 6}},
+		{"normals", dec.Normals, []float32{0, 0, 1, 0, 1, 0}},
+		{"uvs", dec.Uvs, []float32{0.25, 0.5, 0.75, 1}},
+		{"colors", dec.Colors, []float32{}},
+	}
+	for _, test := range tests {
+		if !reflect.DeepEqual([]float32(test.got), test.want) {
+			t.Errorf("%s: %v, want %v", test.name, test.got, test.want)
+		}
+	}
+	if dec.Indices.Size() != 0 {
+		t.Errorf("point cloud indices: %v", dec.Indices)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+
+	bin := newTestPLY(BinaryLittleEnd)
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"magic", "plx\nformat ascii 1.0\nend_header\n"},
+		{"missing format", "ply\nelement vertex 0\nend_header\n"},
+		{"unsupported format", "ply\nformat binary_middle_endian 1.0\nend_header\n"},
+		{"property type", "ply\nformat ascii 1.0\nelement vertex 1\nproperty float3 x\nend_header\n"},
+		{"property without element", "ply\nformat ascii 1.0\nproperty float x\nend_header\n"},
+		{"unterminated header", "ply\nformat ascii 1.0\nelement vertex 1\n"},
+		{"vertex index", "ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\n" +
+			"element face 1\nproperty list uchar int vertex_indices\nend_header\n0\n3 0 1 2\n"},
+		{"ascii value", "ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nend_header\nx\n"},
+		{"truncated ascii", "ply\nformat ascii 1.0\nelement vertex 2\nproperty float x\nend_header\n1\n"},
+		{"truncated binary", string(bin[:len(bin)-3])},
+	}
+	for _, test := range tests {
+		_, err := DecodeReader(strings.NewReader(test.data))
+		if err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+}
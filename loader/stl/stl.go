@@ -0,0 +1,214 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package stl is used to parse the stereolithography format (*.stl)
+// in its ASCII and binary variants, as produced by CAD programs and used for 3D printing.
+// The triangles of all the solids of a file are decoded with their facet normals.
+// Basic format info: http://paulbourke.net/dataformats/stl/
+package stl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// Sizes of the parts of the binary format
+const (
+	headerSize   = 80
+	triangleSize = 50
+)
+
+// Decoder contains all decoded data from a STL file
+type Decoder struct {
+	Name      string          // Name of the first solid (ASCII) or header text (binary)
+	Binary    bool            // Whether the file is in the binary format
+	Positions math32.ArrayF32 // Vertex positions of the triangles
+	Normals   math32.ArrayF32 // Vertex normals, which are the normals of the triangles
+	line      int             // current line number of ASCII files
+}
+
+// Decode decodes the specified STL file returning a decoder object and an error.
+func Decode(path string) (*Decoder, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodeReader(f)
+}
+
+// DecodeReader decodes STL data from the specified reader returning a decoder object and an error.
+func DecodeReader(reader io.Reader) (*Decoder, error) {
+
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	dec := new(Decoder)
+	dec.Positions = math32.NewArrayF32(0, 0)
+	dec.Normals = math32.NewArrayF32(0, 0)
+
+	// Binary files may also start with "solid", so the size is checked first
+	if IsBinary(data) {
+		err = dec.parseBinary(data)
+	} else if bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("solid")) {
+		err = dec.parseASCII(data)
+	} else {
+		err = fmt.Errorf("Invalid STL data")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dec, nil
+}
+
+// IsBinary returns whether the specified data has the size of a binary STL file
+// with the number of triangles declared after its header.
+func IsBinary(data []byte) bool {
+
+	if len(data) < headerSize+4 {
+		return false
+	}
+	count := binary.LittleEndian.Uint32(data[headerSize:])
+	return len(data) == headerSize+4+int(count)*triangleSize
+}
+
+// NewGeometry creates and returns a geometry with the decoded triangles.
+func (dec *Decoder) NewGeometry() *geometry.Geometry {
+
+	geom := geometry.NewGeometry()
+	geom.AddVBO(gls.NewVBO(dec.Positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(dec.Normals).AddAttrib(gls.VertexNormal))
+	return geom
+}
+
+// NewMesh creates and returns a mesh with the decoded triangles and a gray standard material.
+func (dec *Decoder) NewMesh() (*graphic.Mesh, error) {
+
+	if dec.Positions.Size() == 0 {
+		return nil, fmt.Errorf("STL data has no triangles")
+	}
+	mesh := graphic.NewMesh(dec.NewGeometry(), material.NewStandard(&math32.Color{R: 0.7, G: 0.7, B: 0.7}))
+	mesh.SetName(dec.Name)
+	return mesh, nil
+}
+
+// parseBinary parses the triangles of a binary STL file.
+func (dec *Decoder) parseBinary(data []byte) error {
+
+	dec.Binary = true
+	dec.Name = strings.TrimSpace(strings.TrimRight(string(data[:headerSize]), "\x00"))
+	count := int(binary.LittleEndian.Uint32(data[headerSize:]))
+	var v [12]float32
+	for i := 0; i < count; i++ {
+		tri := data[headerSize+4+i*triangleSize:]
+		for j := range v {
+			v[j] = math.Float32frombits(binary.LittleEndian.Uint32(tri[4*j:]))
+		}
+		dec.appendTriangle(v)
+	}
+	return nil
+}
+
+// parseASCII parses the solids of an ASCII STL file.
+func (dec *Decoder) parseASCII(data []byte) error {
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	var v [12]float32
+	vertex := 0
+	named := false
+	for scanner.Scan() {
+		dec.line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "solid":
+			if !named {
+				dec.Name = strings.Join(fields[1:], " ")
+				named = true
+			}
+		case "facet":
+			if len(fields) != 5 || fields[1] != "normal" {
+				return dec.formatError("Invalid facet")
+			}
+			err := dec.parseFloats(fields[2:], v[0:3])
+			if err != nil {
+				return err
+			}
+			vertex = 0
+		case "vertex":
+			if len(fields) != 4 || vertex > 2 {
+				return dec.formatError("Invalid vertex")
+			}
+			vertex++
+			err := dec.parseFloats(fields[1:], v[3*vertex:3*vertex+3])
+			if err != nil {
+				return err
+			}
+		case "endfacet":
+			if vertex != 3 {
+				return dec.formatError("Facet without 3 vertices")
+			}
+			dec.appendTriangle(v)
+		case "outer", "endloop", "endsolid":
+		default:
+			return dec.formatError(fmt.Sprintf("Unknown keyword:%s", fields[0]))
+		}
+	}
+	return scanner.Err()
+}
+
+// appendTriangle appends the triangle with the specified normal followed by its vertices.
+// A zero normal is calculated from the vertices.
+func (dec *Decoder) appendTriangle(v [12]float32) {
+
+	normal := math32.Vector3{X: v[0], Y: v[1], Z: v[2]}
+	a := math32.Vector3{X: v[3], Y: v[4], Z: v[5]}
+	b := math32.Vector3{X: v[6], Y: v[7], Z: v[8]}
+	c := math32.Vector3{X: v[9], Y: v[10], Z: v[11]}
+	if normal.X == 0 && normal.Y == 0 && normal.Z == 0 {
+		var ab, ac math32.Vector3
+		ab.SubVectors(&b, &a)
+		ac.SubVectors(&c, &a)
+		normal.CrossVectors(&ab, &ac).Normalize()
+	}
+	dec.Positions.AppendVector3(&a, &b, &c)
+	dec.Normals.AppendVector3(&normal, &normal, &normal)
+}
+
+// parseFloats parses the specified fields into the specified floats.
+func (dec *Decoder) parseFloats(fields []string, v []float32) error {
+
+	for i, f := range fields {
+		val, err := strconv.ParseFloat(f, 32)
+		if err != nil {
+			return dec.formatError(fmt.Sprintf("Invalid number:%s", f))
+		}
+		v[i] = float32(val)
+	}
+	return nil
+}
+
+// formatError returns a formatted error with the current line number.
+func (dec *Decoder) formatError(msg string) error {
+
+	return fmt.Errorf("%s in line:%d", msg, dec.line)
+}
@@ -0,0 +1,129 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Triangles of the test files: normal followed by the three vertices.
+// The normal of the second triangle is zero and must be calculated.
+var testTriangles = [][12]float32{
+	{0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 1, 0},
+	{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 1},
+}
+
+const testASCII = `solid test part
+  facet normal 0 0 1
+    outer loop
+      vertex 0 0 0
+      vertex 1 0 0
+      vertex 0 1 0
+    endloop
+  endfacet
+  facet normal 0 0 0
+    outer loop
+      vertex 0 0 0
+      vertex 0 1 0
+      vertex 0 0 1
+    endloop
+  endfacet
+endsolid test part
+`
+
+// newTestBinary returns the test triangles in the binary format
+// with a header which starts with "solid" as written by some programs.
+func newTestBinary() []byte {
+
+	var buf bytes.Buffer
+	header := make([]byte, headerSize)
+	copy(header, "solid binary part")
+	buf.Write(header)
+	binary.Write(&buf, binary.LittleEndian, uint32(len(testTriangles)))
+	for _, tri := range testTriangles {
+		binary.Write(&buf, binary.LittleEndian, tri)
+		buf.Write([]byte{0, 0}) // attribute byte count
+	}
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+
+	positions := []float32{0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 1, 0, 0, 0, 1}
+	normals := []float32{0, 0, 1, 0, 0, 1, 0, 0, 1, 1, 0, 0, 1, 0, 0, 1, 0, 0}
+	tests := []struct {
+		name   string
+		data   []byte
+		binary bool
+		solid  string
+	}{
+		{"ascii", []byte(testASCII), false, "test part"},
+		{"binary", newTestBinary(), true, "solid binary part"},
+	}
+	for _, test := range tests {
+		dec, err := DecodeReader(bytes.NewReader(test.data))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if dec.Binary != test.binary {
+			t.Errorf("%s: binary %v", test.name, dec.Binary)
+		}
+		if dec.Name != test.solid {
+			t.Errorf("%s: name %q, want %q", test.name, dec.Name, test.solid)
+		}
+		if !reflect.DeepEqual([]float32(dec.Positions), positions) {
+			t.Errorf("%s: positions %v, want %v", test.name, dec.Positions, positions)
+		}
+		if !reflect.DeepEqual([]float32(dec.Normals), normals) {
+			t.Errorf("%s: normals %v, want %v", test.name, dec.Normals, normals)
+		}
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+
+	bin := newTestBinary()
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"binary", bin, true},
+		{"ascii", []byte(testASCII), false},
+		{"truncated", bin[:len(bin)-1], false},
+		{"header only", bin[:headerSize], false},
+	}
+	for _, test := range tests {
+		if got := IsBinary(test.data); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not stl", "ply\nformat ascii 1.0\n"},
+		{"missing vertex", "solid x\nfacet normal 0 0 1\nouter loop\nvertex 0 0 0\nvertex 1 0 0\nendloop\nendfacet\nendsolid x\n"},
+		{"extra vertex", "solid x\nfacet normal 0 0 1\nouter loop\nvertex 0 0 0\nvertex 1 0 0\nvertex 0 1 0\nvertex 1 1 0\n"},
+		{"invalid number", "solid x\nfacet normal 0 0 z\n"},
+		{"invalid facet", "solid x\nfacet 0 0 1\n"},
+		{"unknown keyword", "solid x\nface normal 0 0 1\n"},
+	}
+	for _, test := range tests {
+		_, err := DecodeReader(strings.NewReader(test.data))
+		if err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+}
@@ -17,3 +17,11 @@ func NewBasic() *Basic {
 	mb.SetShader("basic")
 	return mb
 }
+
+// Clone returns a copy of this material which shares its textures.
+func (mb *Basic) Clone() IMaterial {
+
+	clone := *mb
+	clone.initClone()
+	return &clone
+}
@@ -58,6 +58,12 @@ type IMaterial interface {
 	Dispose()
 }
 
+// ICloneable is the interface for the materials which can be copied.
+type ICloneable interface {
+	IMaterial
+	Clone() IMaterial
+}
+
 // Material is the base material.
 type Material struct {
 	refcount int // Current number of references
@@ -74,6 +80,8 @@ type Material struct {
 	wireframe   bool                 // Whether to render only the wireframe
 	lineWidth   float32              // Line width for lines and wireframe
 	textures    []*texture.Texture2D // List of textures
+	uniforms    []*customUniform     // Custom uniforms set by the application
+	uniTextures []*customTexture     // Custom sampler uniforms set by the application
 
 	polyOffsetFactor float32 // polygon offset factor
 	polyOffsetUnits  float32 // polygon offset units
@@ -82,6 +90,8 @@ type Material struct {
 	depthTest bool   // Enable depth buffer test
 	depthFunc uint32 // Active depth test function
 
+	envMapIntensity float32 // Intensity of the environment reflected from the nearest reflection probe
+
 	// TODO stencil properties
 
 	// Equations used for custom blending (when blending=BlendCustom) // TODO implement methods
@@ -116,6 +126,8 @@ func (mat *Material) Init() *Material {
 	mat.polyOffsetFactor = 0
 	mat.polyOffsetUnits = 0
 	mat.textures = make([]*texture.Texture2D, 0)
+	mat.uniforms = nil
+	mat.uniTextures = nil
 
 	// Setup shader defines and add default values
 	mat.ShaderDefines = *gls.NewShaderDefines()
@@ -139,6 +151,29 @@ func (mat *Material) Incref() *Material {
 	return mat
 }
 
+// initClone initializes this material, copied from another material, with its own
+// reference count and shader defines and sharing the textures of the original material,
+// whose reference counts are incremented. It is called by the Clone methods of the materials.
+func (mat *Material) initClone() {
+
+	mat.refcount = 1
+	defines := mat.ShaderDefines
+	mat.ShaderDefines = *gls.NewShaderDefines()
+	mat.ShaderDefines.Add(&defines)
+	mat.textures = append([]*texture.Texture2D(nil), mat.textures...)
+	for _, tex := range mat.textures {
+		tex.Incref()
+	}
+	mat.uniforms = append([]*customUniform(nil), mat.uniforms...)
+	for i, cu := range mat.uniforms {
+		mat.uniforms[i] = newCustomUniform(cu.uni.Name(), cu.value)
+	}
+	mat.uniTextures = append([]*customTexture(nil), mat.uniTextures...)
+	for i, ct := range mat.uniTextures {
+		mat.uniTextures[i] = newCustomTexture(ct.uni.Name(), ct.tex.Incref())
+	}
+}
+
 // Dispose decrements this material reference count and
 // if necessary releases OpenGL resources, C memory
 // and textures associated with this material.
@@ -153,6 +188,9 @@ func (mat *Material) Dispose() {
 	for i := 0; i < len(mat.textures); i++ {
 		mat.textures[i].Dispose()
 	}
+	for _, ct := range mat.uniTextures {
+		ct.tex.Dispose()
+	}
 	mat.Init()
 }
 
@@ -232,6 +270,21 @@ func (mat *Material) Wireframe() bool {
 	return mat.wireframe
 }
 
+// SetEnvMapIntensity sets the intensity of the environment reflected from the cube map of the
+// reflection probe nearest to the graphic. Standard materials replace this fraction of their
+// color by the reflection and physical materials scale their image based lighting by it.
+// The default is 0, which disables the reflection, except for physical materials which default to 1.
+func (mat *Material) SetEnvMapIntensity(intensity float32) {
+
+	mat.envMapIntensity = intensity
+}
+
+// EnvMapIntensity returns the intensity of the environment reflected from the nearest reflection probe.
+func (mat *Material) EnvMapIntensity() float32 {
+
+	return mat.envMapIntensity
+}
+
 func (mat *Material) SetDepthMask(state bool) {
 
 	mat.depthMask = state
@@ -338,6 +391,16 @@ func (mat *Material) RenderSetup(gs *gls.GLS) {
 		tex.RenderSetup(gs, slotIdx, uniIdx)
 		samplerCounts[samplerName] = uniIdx + 1
 	}
+
+	// Transfer the custom uniforms, with the textures bound to the units following the material textures
+	for _, cu := range mat.uniforms {
+		cu.transfer(gs)
+	}
+	for i, ct := range mat.uniTextures {
+		unit := len(mat.textures) + i
+		ct.tex.Bind(gs, unit)
+		gs.Uniform1i(ct.uni.Location(gs), int32(unit))
+	}
 }
 
 // AddTexture adds the specified Texture2d to the material
@@ -376,6 +439,13 @@ func (mat *Material) TextureCount() int {
 	return len(mat.textures)
 }
 
+// TextureUnits returns the number of texture units used by the material textures
+// and the custom sampler uniforms, which are the first texture units.
+func (mat *Material) TextureUnits() int {
+
+	return len(mat.textures) + len(mat.uniTextures)
+}
+
 // Textures returns a slice with this material's textures
 func (mat *Material) Textures() []*texture.Texture2D {
 
@@ -0,0 +1,241 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package material
+
+import (
+	"strconv"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// OceanMaxWaves is the maximum number of Gerstner waves of an Ocean material.
+const OceanMaxWaves = 8
+
+// OceanWave describes one Gerstner wave of an Ocean material.
+type OceanWave struct {
+	Direction  math32.Vector2 // Direction of propagation in the plane of the geometry
+	Steepness  float32        // Sharpness of the crests from 0 (sine wave) to 1 (cusps)
+	Wavelength float32        // Distance between crests
+}
+
+// Ocean is an animated water material for large water bodies which displaces
+// the vertices of a plane geometry with a sum of Gerstner waves in the vertex shader.
+// The geometry must be a segmented plane in the XY plane, as the ones created by
+// geometry.NewSegmentedPlane, with enough segments for the shortest wavelength.
+// The waves move along the plane and their heights are along the Z axis, so the
+// mesh is normally rotated to be horizontal. The animation is driven by the time
+// set by SetTime, which should be updated every frame.
+// As the waves displace the vertices beyond the bounding box of the geometry,
+// frustum culling should be disabled for the mesh.
+type Ocean struct {
+	Standard                     // Embedded standard material
+	normalTex *texture.Texture2D // Optional normal map for the detail normals
+	waves     []OceanWave        // Gerstner waves
+	uniWaves  gls.Uniform        // Waves uniform location cache
+	uniOcean  gls.Uniform        // Ocean uniform location cache
+	wdata     [4 * OceanMaxWaves]float32
+	odata     struct { // Combined uniform data in 4 vec4:
+		time           float32        // Animation time in seconds
+		normalStrength float32        // Strength of the detail normals
+		normalScale    float32        // Scale of the normal map coordinates
+		foamThreshold  float32        // Compression where the foam starts
+		normalSpeed    math32.Vector2 // Scroll velocity of the normal map coordinates
+		foamSoftness   float32        // Compression range where the foam fades in
+		fresnel        float32        // Strength of the reflections at grazing angles
+		crestColor     math32.Color   // Color at the crests
+		crestHeight    float32        // Height where the crest color is fully applied
+		foamColor      math32.Color   // Foam color
+		foamIntensity  float32        // Foam intensity
+	}
+}
+
+// Number of glsl shader vec4 elements used by the ocean uniform data
+const oceanVec4Count = 4
+
+// NewOcean creates and returns a pointer to a new ocean material with the specified
+// water color and a default set of waves.
+func NewOcean(color *math32.Color) *Ocean {
+
+	m := new(Ocean)
+	m.Standard.Init("ocean", color)
+	m.uniWaves.Init("OceanWaves")
+	m.uniOcean.Init("Ocean")
+	m.SetSpecularColor(&math32.Color{R: 1, G: 1, B: 1})
+	m.SetShininess(100)
+	m.SetOpacity(0.8)
+	m.SetSide(SideDouble)
+	m.SetTransparent(true)
+
+	m.odata.normalStrength = 1
+	m.odata.normalScale = 0.1
+	m.odata.foamThreshold = 0.4
+	m.odata.normalSpeed = math32.Vector2{X: 0.02, Y: 0.01}
+	m.odata.foamSoftness = 0.3
+	m.odata.fresnel = 1
+	m.odata.crestColor = *color
+	m.odata.crestHeight = 1
+	m.odata.foamColor = math32.Color{R: 1, G: 1, B: 1}
+	m.odata.foamIntensity = 1
+
+	m.SetWaves([]OceanWave{
+		{Direction: math32.Vector2{X: 1, Y: 0}, Steepness: 0.25, Wavelength: 60},
+		{Direction: math32.Vector2{X: 0, Y: 1}, Steepness: 0.25, Wavelength: 31},
+		{Direction: math32.Vector2{X: 1, Y: 1}, Steepness: 0.15, Wavelength: 18},
+	})
+	return m
+}
+
+// Clone returns a copy of this material which shares its textures.
+func (m *Ocean) Clone() IMaterial {
+
+	clone := *m
+	clone.initClone()
+	clone.waves = append([]OceanWave(nil), m.waves...)
+	return &clone
+}
+
+// SetWaves sets the Gerstner waves of the ocean, up to OceanMaxWaves.
+// The sum of the steepness of all the waves should not be greater than 1 to avoid loops at the crests.
+func (m *Ocean) SetWaves(waves []OceanWave) {
+
+	if len(waves) > OceanMaxWaves {
+		waves = waves[:OceanMaxWaves]
+	}
+	m.waves = append(m.waves[:0], waves...)
+	for i, w := range m.waves {
+		m.wdata[i*4] = w.Direction.X
+		m.wdata[i*4+1] = w.Direction.Y
+		m.wdata[i*4+2] = w.Steepness
+		m.wdata[i*4+3] = w.Wavelength
+	}
+	m.ShaderDefines.Set("OCEAN_WAVES", strconv.Itoa(len(m.waves)))
+}
+
+// Waves returns the Gerstner waves of the ocean.
+func (m *Ocean) Waves() []OceanWave {
+
+	return m.waves
+}
+
+// WaveHeight returns the height of the waves at the specified position in the plane
+// of the geometry at the current time, e.g. to make floating objects follow the waves.
+func (m *Ocean) WaveHeight(x, y float32) float32 {
+
+	// Finds the undisplaced position which moves to the specified position
+	px, py := x, y
+	var height float32
+	for iter := 0; iter < 4; iter++ {
+		var dx, dy float32
+		dx, dy, height = m.displacement(px, py)
+		px = x - dx
+		py = y - dy
+	}
+	return height
+}
+
+// displacement returns the displacement of the waves for the specified undisplaced position.
+func (m *Ocean) displacement(x, y float32) (dx, dy, dz float32) {
+
+	for _, w := range m.waves {
+		d := w.Direction
+		d.Normalize()
+		k := 2 * math32.Pi / w.Wavelength
+		c := math32.Sqrt(9.8 / k)
+		f := k * (d.X*x + d.Y*y - c*m.odata.time)
+		a := w.Steepness / k
+		cosf := math32.Cos(f)
+		dx += d.X * a * cosf
+		dy += d.Y * a * cosf
+		dz += a * math32.Sin(f)
+	}
+	return dx, dy, dz
+}
+
+// SetTime sets the animation time in seconds.
+func (m *Ocean) SetTime(time float32) {
+
+	m.odata.time = time
+}
+
+// Time returns the animation time in seconds.
+func (m *Ocean) Time() float32 {
+
+	return m.odata.time
+}
+
+// SetCrestColor sets the color of the waves at the crest height. The default is the water color.
+func (m *Ocean) SetCrestColor(color *math32.Color, height float32) {
+
+	m.odata.crestColor = *color
+	m.odata.crestHeight = height
+}
+
+// CrestColor returns the color of the waves at the crests and the height where it is fully applied.
+func (m *Ocean) CrestColor() (math32.Color, float32) {
+
+	return m.odata.crestColor, m.odata.crestHeight
+}
+
+// SetFoam sets the foam color and intensity and the compression of the waves where the foam
+// starts and fades in, from 0 (flat) to 1 (cusp). The defaults are white, 1, 0.4 and 0.3.
+func (m *Ocean) SetFoam(color *math32.Color, intensity, threshold, softness float32) {
+
+	m.odata.foamColor = *color
+	m.odata.foamIntensity = intensity
+	m.odata.foamThreshold = threshold
+	m.odata.foamSoftness = softness
+}
+
+// SetFresnel sets the strength of the reflections at grazing angles. The default is 1.
+func (m *Ocean) SetFresnel(fresnel float32) {
+
+	m.odata.fresnel = fresnel
+}
+
+// SetNormalMap sets the optional normal map used to add animated detail normals to the waves.
+// The map is sampled twice scrolling in different directions.
+func (m *Ocean) SetNormalMap(tex *texture.Texture2D) {
+
+	if m.normalTex != nil {
+		m.RemoveTexture(m.normalTex)
+	}
+	m.normalTex = tex
+	if tex != nil {
+		tex.SetUniformNames("OceanNormalSampler", "OceanNormalTexParams")
+		m.ShaderDefines.Set("HAS_NORMALMAP", "")
+		m.AddTexture(tex)
+	} else {
+		m.ShaderDefines.Unset("HAS_NORMALMAP")
+	}
+}
+
+// NormalMap returns the normal map of the detail normals or nil if not set.
+func (m *Ocean) NormalMap() *texture.Texture2D {
+
+	return m.normalTex
+}
+
+// SetNormalAnimation sets the strength of the detail normals, the scale of the normal map
+// coordinates relative to the plane coordinates and the scroll velocity of the normal map.
+// The defaults are 1, 0.1 and (0.02, 0.01).
+func (m *Ocean) SetNormalAnimation(strength, scale float32, speed *math32.Vector2) {
+
+	m.odata.normalStrength = strength
+	m.odata.normalScale = scale
+	m.odata.normalSpeed = *speed
+}
+
+// RenderSetup is called by the engine before drawing the object
+// which uses this material
+func (m *Ocean) RenderSetup(gs *gls.GLS) {
+
+	m.Standard.RenderSetup(gs)
+	if len(m.waves) > 0 {
+		gs.Uniform4fv(m.uniWaves.Location(gs), int32(len(m.waves)), &m.wdata[0])
+	}
+	gs.Uniform4fv(m.uniOcean.Location(gs), oceanVec4Count, &m.odata.time)
+}
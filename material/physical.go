@@ -43,9 +43,18 @@ func NewPhysical() *Physical {
 	m.udata.emissiveFactor = math32.Color4{0, 0, 0, 1}
 	m.udata.metallicFactor = 1
 	m.udata.roughnessFactor = 1
+	m.SetEnvMapIntensity(1)
 	return m
 }
 
+// Clone returns a copy of this material which shares its textures.
+func (m *Physical) Clone() IMaterial {
+
+	clone := *m
+	clone.initClone()
+	return &clone
+}
+
 // SetBaseColorFactor sets this material base color.
 // Its default value is {1,1,1,1}.
 // Returns pointer to this updated material.
@@ -55,6 +64,12 @@ func (m *Physical) SetBaseColorFactor(c *math32.Color4) *Physical {
 	return m
 }
 
+// BaseColorFactor returns the material base color factor.
+func (m *Physical) BaseColorFactor() math32.Color4 {
+
+	return m.udata.baseColorFactor
+}
+
 // SetMetallicFactor sets this material metallic factor.
 // Its default value is 1.
 // Returns pointer to this updated material.
@@ -64,6 +79,12 @@ func (m *Physical) SetMetallicFactor(v float32) *Physical {
 	return m
 }
 
+// MetallicFactor returns the material metallic factor.
+func (m *Physical) MetallicFactor() float32 {
+
+	return m.udata.metallicFactor
+}
+
 // SetRoughnessFactor sets this material roughness factor.
 // Its default value is 1.
 // Returns pointer to this updated material.
@@ -73,6 +94,12 @@ func (m *Physical) SetRoughnessFactor(v float32) *Physical {
 	return m
 }
 
+// RoughnessFactor returns the material roughness factor.
+func (m *Physical) RoughnessFactor() float32 {
+
+	return m.udata.roughnessFactor
+}
+
 // SetEmissiveFactor sets the emissive color of the material.
 // Its default is {1, 1, 1}.
 // Returns pointer to this updated material.
@@ -84,6 +111,12 @@ func (m *Physical) SetEmissiveFactor(c *math32.Color) *Physical {
 	return m
 }
 
+// EmissiveFactor returns the material emissive factor.
+func (m *Physical) EmissiveFactor() math32.Color {
+
+	return math32.Color{R: m.udata.emissiveFactor.R, G: m.udata.emissiveFactor.G, B: m.udata.emissiveFactor.B}
+}
+
 // SetBaseColorMap sets this material optional texture base color.
 // Returns pointer to this updated material.
 func (m *Physical) SetBaseColorMap(tex *texture.Texture2D) *Physical {
@@ -100,6 +133,12 @@ func (m *Physical) SetBaseColorMap(tex *texture.Texture2D) *Physical {
 	return m
 }
 
+// BaseColorMap returns the base color texture or nil if not set.
+func (m *Physical) BaseColorMap() *texture.Texture2D {
+
+	return m.baseColorTex
+}
+
 // SetMetallicRoughnessMap sets this material optional metallic-roughness texture.
 // Returns pointer to this updated material.
 func (m *Physical) SetMetallicRoughnessMap(tex *texture.Texture2D) *Physical {
@@ -116,6 +155,12 @@ func (m *Physical) SetMetallicRoughnessMap(tex *texture.Texture2D) *Physical {
 	return m
 }
 
+// MetallicRoughnessMap returns the metallic-roughness texture or nil if not set.
+func (m *Physical) MetallicRoughnessMap() *texture.Texture2D {
+
+	return m.metallicRoughnessTex
+}
+
 // SetNormalMap sets this material optional normal texture.
 // Returns pointer to this updated material.
 // TODO add SetNormalMap (and SetSpecularMap) to StandardMaterial.
@@ -133,6 +178,12 @@ func (m *Physical) SetNormalMap(tex *texture.Texture2D) *Physical {
 	return m
 }
 
+// NormalMap returns the normal texture or nil if not set.
+func (m *Physical) NormalMap() *texture.Texture2D {
+
+	return m.normalTex
+}
+
 // SetOcclusionMap sets this material optional occlusion texture.
 // Returns pointer to this updated material.
 func (m *Physical) SetOcclusionMap(tex *texture.Texture2D) *Physical {
@@ -149,6 +200,12 @@ func (m *Physical) SetOcclusionMap(tex *texture.Texture2D) *Physical {
 	return m
 }
 
+// OcclusionMap returns the occlusion texture or nil if not set.
+func (m *Physical) OcclusionMap() *texture.Texture2D {
+
+	return m.occlusionTex
+}
+
 // SetEmissiveMap sets this material optional emissive texture.
 // Returns pointer to this updated material.
 func (m *Physical) SetEmissiveMap(tex *texture.Texture2D) *Physical {
@@ -165,6 +222,12 @@ func (m *Physical) SetEmissiveMap(tex *texture.Texture2D) *Physical {
 	return m
 }
 
+// EmissiveMap returns the emissive texture or nil if not set.
+func (m *Physical) EmissiveMap() *texture.Texture2D {
+
+	return m.emissiveTex
+}
+
 // RenderSetup transfer this material uniforms and textures to the shader
 func (m *Physical) RenderSetup(gl *gls.GLS) {
 
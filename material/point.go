@@ -26,6 +26,14 @@ func NewPoint(color *math32.Color) *Point {
 	return pm
 }
 
+// Clone returns a copy of this material which shares its textures.
+func (pm *Point) Clone() IMaterial {
+
+	clone := *pm
+	clone.initClone()
+	return &clone
+}
+
 // SetEmissiveColor sets the material emissive color
 // The default is {0,0,0}
 func (pm *Point) SetEmissiveColor(color *math32.Color) {
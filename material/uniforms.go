@@ -0,0 +1,216 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package material
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// customUniform is a uniform set by the application and transferred by the material render setup.
+type customUniform struct {
+	uni   gls.Uniform // Uniform location cache
+	value interface{} // Value of the uniform
+}
+
+// customTexture is a sampler uniform set by the application and bound by the material render setup.
+type customTexture struct {
+	uni gls.Uniform        // Uniform location cache
+	tex *texture.Texture2D // Texture bound to the sampler
+}
+
+// newCustomUniform creates and returns a pointer to a new custom uniform.
+func newCustomUniform(name string, value interface{}) *customUniform {
+
+	cu := new(customUniform)
+	cu.uni.Init(name)
+	cu.value = value
+	return cu
+}
+
+// newCustomTexture creates and returns a pointer to a new custom sampler uniform.
+func newCustomTexture(name string, tex *texture.Texture2D) *customTexture {
+
+	ct := new(customTexture)
+	ct.uni.Init(name)
+	ct.tex = tex
+	return ct
+}
+
+// SetUniform sets the value of a custom uniform of this material's shader, which is transferred
+// each time an object with this material is rendered. The value may be a float32, int32, int, bool,
+// math32.Vector2, Vector3, Vector4, Color, Color4, Matrix3, Matrix4 or []float32 for a float array,
+// or a pointer to one of these types, whose current value is transferred at each render.
+// Returns an error if the type of the value is not supported.
+func (mat *Material) SetUniform(name string, value interface{}) error {
+
+	if !validUniform(value) {
+		return fmt.Errorf("unsupported type %T for uniform: %s", value, name)
+	}
+	for _, cu := range mat.uniforms {
+		if cu.uni.Name() == name {
+			cu.value = value
+			return nil
+		}
+	}
+	mat.uniforms = append(mat.uniforms, newCustomUniform(name, value))
+	return nil
+}
+
+// Uniform returns the value of the custom uniform with the specified name or nil if not found.
+func (mat *Material) Uniform(name string) interface{} {
+
+	for _, cu := range mat.uniforms {
+		if cu.uni.Name() == name {
+			return cu.value
+		}
+	}
+	return nil
+}
+
+// RemoveUniform removes the custom uniform with the specified name and returns whether it was found.
+func (mat *Material) RemoveUniform(name string) bool {
+
+	for i, cu := range mat.uniforms {
+		if cu.uni.Name() == name {
+			mat.uniforms = append(mat.uniforms[:i], mat.uniforms[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// SetUniformTexture sets the texture bound to the custom sampler uniform with the specified name.
+// The texture is bound to a texture unit after the units of the material textures and its own
+// uniforms are not transferred. The material keeps a reference to the texture, which is disposed
+// with the material. A nil texture removes the sampler uniform.
+func (mat *Material) SetUniformTexture(name string, tex *texture.Texture2D) {
+
+	for i, ct := range mat.uniTextures {
+		if ct.uni.Name() == name {
+			ct.tex.Dispose()
+			if tex == nil {
+				mat.uniTextures = append(mat.uniTextures[:i], mat.uniTextures[i+1:]...)
+				return
+			}
+			ct.tex = tex.Incref()
+			return
+		}
+	}
+	if tex == nil {
+		return
+	}
+	mat.uniTextures = append(mat.uniTextures, newCustomTexture(name, tex.Incref()))
+}
+
+// UniformTexture returns the texture of the custom sampler uniform with the specified name or nil if not found.
+func (mat *Material) UniformTexture(name string) *texture.Texture2D {
+
+	for _, ct := range mat.uniTextures {
+		if ct.uni.Name() == name {
+			return ct.tex
+		}
+	}
+	return nil
+}
+
+// SetDefine sets a shader define ("#define <name> <value>") used when building the shader of this material.
+func (mat *Material) SetDefine(name, value string) {
+
+	mat.ShaderDefines.Set(name, value)
+}
+
+// UnsetDefine removes the shader define with the specified name.
+func (mat *Material) UnsetDefine(name string) {
+
+	mat.ShaderDefines.Unset(name)
+}
+
+// validUniform returns whether the specified value has a supported uniform type.
+func validUniform(value interface{}) bool {
+
+	switch value.(type) {
+	case float32, int32, int, bool,
+		math32.Vector2, math32.Vector3, math32.Vector4, math32.Color, math32.Color4,
+		math32.Matrix3, math32.Matrix4, []float32,
+		*float32, *int32, *int, *bool,
+		*math32.Vector2, *math32.Vector3, *math32.Vector4, *math32.Color, *math32.Color4,
+		*math32.Matrix3, *math32.Matrix4, *[]float32:
+		return true
+	}
+	return false
+}
+
+// transfer transfers the current value of the custom uniform to OpenGL.
+func (cu *customUniform) transfer(gs *gls.GLS) {
+
+	location := cu.uni.Location(gs)
+	switch v := cu.value.(type) {
+	case float32:
+		gs.Uniform1f(location, v)
+	case *float32:
+		gs.Uniform1f(location, *v)
+	case int32:
+		gs.Uniform1i(location, v)
+	case *int32:
+		gs.Uniform1i(location, *v)
+	case int:
+		gs.Uniform1i(location, int32(v))
+	case *int:
+		gs.Uniform1i(location, int32(*v))
+	case bool:
+		gs.Uniform1i(location, boolToInt(v))
+	case *bool:
+		gs.Uniform1i(location, boolToInt(*v))
+	case math32.Vector2:
+		gs.Uniform2f(location, v.X, v.Y)
+	case *math32.Vector2:
+		gs.Uniform2f(location, v.X, v.Y)
+	case math32.Vector3:
+		gs.Uniform3f(location, v.X, v.Y, v.Z)
+	case *math32.Vector3:
+		gs.Uniform3f(location, v.X, v.Y, v.Z)
+	case math32.Vector4:
+		gs.Uniform4f(location, v.X, v.Y, v.Z, v.W)
+	case *math32.Vector4:
+		gs.Uniform4f(location, v.X, v.Y, v.Z, v.W)
+	case math32.Color:
+		gs.Uniform3f(location, v.R, v.G, v.B)
+	case *math32.Color:
+		gs.Uniform3f(location, v.R, v.G, v.B)
+	case math32.Color4:
+		gs.Uniform4f(location, v.R, v.G, v.B, v.A)
+	case *math32.Color4:
+		gs.Uniform4f(location, v.R, v.G, v.B, v.A)
+	case math32.Matrix3:
+		gs.UniformMatrix3fv(location, 1, false, &v[0])
+	case *math32.Matrix3:
+		gs.UniformMatrix3fv(location, 1, false, &v[0])
+	case math32.Matrix4:
+		gs.UniformMatrix4fv(location, 1, false, &v[0])
+	case *math32.Matrix4:
+		gs.UniformMatrix4fv(location, 1, false, &v[0])
+	case []float32:
+		if len(v) > 0 {
+			gs.Uniform1fv(location, int32(len(v)), &v[0])
+		}
+	case *[]float32:
+		if len(*v) > 0 {
+			gs.Uniform1fv(location, int32(len(*v)), &(*v)[0])
+		}
+	}
+}
+
+// boolToInt returns 1 if the specified value is true or 0 otherwise.
+func boolToInt(v bool) int32 {
+
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -167,6 +167,22 @@ func (f *Frustum) IntersectsBox(box *Box3) bool {
 	return true
 }
 
+// IntersectsOBB determines whether the specified oriented box is intersecting the frustum
+func (f *Frustum) IntersectsOBB(obb *OBB) bool {
+
+	for i := 0; i < 6; i++ {
+		plane := &f.planes[i]
+		// Projected radius of the box on the plane normal
+		r := obb.HalfSize.X*Abs(plane.normal.Dot(&obb.Axes[0])) +
+			obb.HalfSize.Y*Abs(plane.normal.Dot(&obb.Axes[1])) +
+			obb.HalfSize.Z*Abs(plane.normal.Dot(&obb.Axes[2]))
+		if plane.DistanceToPoint(&obb.Center) < -r {
+			return false
+		}
+	}
+	return true
+}
+
 // ContainsPoint determines whether the frustum contains the specified point
 func (f *Frustum) ContainsPoint(point *Vector3) bool {
 
@@ -0,0 +1,145 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"sort"
+)
+
+// GradientStop is a color at an offset of a Gradient.
+type GradientStop struct {
+	Offset float32 // Offset of the stop, usually from 0 to 1
+	Color  Color4  // Color at the offset
+}
+
+// Gradient is a sequence of color stops which are linearly interpolated.
+// It is used for colors over the lifetime of objects, height based colorization
+// and color maps. Before the first and after the last stop the gradient keeps
+// the colors of these stops.
+type Gradient struct {
+	stops []GradientStop // Stops sorted by offset
+}
+
+// NewGradient creates and returns a pointer to a new gradient with the specified stops.
+func NewGradient(stops ...GradientStop) *Gradient {
+
+	g := new(Gradient)
+	g.SetStops(stops)
+	return g
+}
+
+// NewGradientColors creates and returns a pointer to a new gradient with the
+// specified colors equally spaced from offset 0 to 1.
+func NewGradientColors(colors ...Color4) *Gradient {
+
+	g := new(Gradient)
+	for i := range colors {
+		offset := float32(0)
+		if len(colors) > 1 {
+			offset = float32(i) / float32(len(colors)-1)
+		}
+		g.stops = append(g.stops, GradientStop{Offset: offset, Color: colors[i]})
+	}
+	return g
+}
+
+// SetStops replaces the stops of this gradient by a copy of the specified ones.
+func (g *Gradient) SetStops(stops []GradientStop) *Gradient {
+
+	g.stops = append(g.stops[:0], stops...)
+	sort.SliceStable(g.stops, func(i, j int) bool { return g.stops[i].Offset < g.stops[j].Offset })
+	return g
+}
+
+// Stops returns the stops of this gradient sorted by offset.
+// The returned slice must not be modified.
+func (g *Gradient) Stops() []GradientStop {
+
+	return g.stops
+}
+
+// StopCount returns the number of stops of this gradient.
+func (g *Gradient) StopCount() int {
+
+	return len(g.stops)
+}
+
+// Stop returns the stop at the specified index.
+func (g *Gradient) Stop(i int) GradientStop {
+
+	return g.stops[i]
+}
+
+// AddStop inserts a stop with the specified offset and color keeping the stops
+// sorted by offset and returns its index.
+func (g *Gradient) AddStop(offset float32, color *Color4) int {
+
+	i := sort.Search(len(g.stops), func(i int) bool { return g.stops[i].Offset > offset })
+	g.stops = append(g.stops, GradientStop{})
+	copy(g.stops[i+1:], g.stops[i:])
+	g.stops[i] = GradientStop{Offset: offset, Color: *color}
+	return i
+}
+
+// SetStop replaces the stop at the specified index, moving it if its offset
+// changed the order of the stops, and returns its new index.
+func (g *Gradient) SetStop(i int, stop GradientStop) int {
+
+	g.RemoveStop(i)
+	return g.AddStop(stop.Offset, &stop.Color)
+}
+
+// RemoveStop removes the stop at the specified index.
+func (g *Gradient) RemoveStop(i int) {
+
+	copy(g.stops[i:], g.stops[i+1:])
+	g.stops = g.stops[:len(g.stops)-1]
+}
+
+// Clone returns a pointer to a copy of this gradient.
+func (g *Gradient) Clone() *Gradient {
+
+	return NewGradient(g.stops...)
+}
+
+// Evaluate returns the color of the gradient at the specified offset.
+// A gradient without stops is transparent black.
+func (g *Gradient) Evaluate(offset float32) Color4 {
+
+	n := len(g.stops)
+	if n == 0 {
+		return Color4{}
+	}
+	if offset <= g.stops[0].Offset {
+		return g.stops[0].Color
+	}
+	if offset >= g.stops[n-1].Offset {
+		return g.stops[n-1].Color
+	}
+	i := sort.Search(n, func(i int) bool { return g.stops[i].Offset > offset })
+	s0, s1 := &g.stops[i-1], &g.stops[i]
+	t := (offset - s0.Offset) / (s1.Offset - s0.Offset)
+	return Color4{
+		R: s0.Color.R + (s1.Color.R-s0.Color.R)*t,
+		G: s0.Color.G + (s1.Color.G-s0.Color.G)*t,
+		B: s0.Color.B + (s1.Color.B-s0.Color.B)*t,
+		A: s0.Color.A + (s1.Color.A-s0.Color.A)*t,
+	}
+}
+
+// Sample returns the colors of the gradient at the specified number of offsets
+// equally spaced from 0 to 1, as used for lookup tables and textures.
+func (g *Gradient) Sample(count int) []Color4 {
+
+	res := make([]Color4, count)
+	for i := range res {
+		offset := float32(0)
+		if count > 1 {
+			offset = float32(i) / float32(count-1)
+		}
+		res[i] = g.Evaluate(offset)
+	}
+	return res
+}
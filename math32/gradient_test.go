@@ -0,0 +1,136 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"reflect"
+	"testing"
+)
+
+var (
+	testBlack = Color4{0, 0, 0, 1}
+	testRed   = Color4{1, 0, 0, 1}
+	testBlue  = Color4{0, 0, 1, 0.5}
+	testWhite = Color4{1, 1, 1, 1}
+)
+
+// almostEqualColors returns whether the specified colors are equal within a small tolerance.
+func almostEqualColors(a, b Color4) bool {
+
+	const tol = 1e-6
+	return Abs(a.R-b.R) < tol && Abs(a.G-b.G) < tol && Abs(a.B-b.B) < tol && Abs(a.A-b.A) < tol
+}
+
+func TestGradientEvaluate(t *testing.T) {
+
+	// Stops out of order and a hard transition from red to blue at 0.5
+	g := NewGradient(
+		GradientStop{1, testWhite},
+		GradientStop{0.5, testRed},
+		GradientStop{0.25, testBlack},
+		GradientStop{0.5, testBlue},
+	)
+	tests := []struct {
+		name   string
+		offset float32
+		want   Color4
+	}{
+		{"before the first stop", -1, testBlack},
+		{"first stop", 0.25, testBlack},
+		{"between black and red", 0.375, Color4{0.5, 0, 0, 1}},
+		{"hard transition", 0.5, testBlue},
+		{"between blue and white", 0.75, Color4{0.5, 0.5, 1, 0.75}},
+		{"last stop", 1, testWhite},
+		{"after the last stop", 2, testWhite},
+	}
+	for _, test := range tests {
+		if got := g.Evaluate(test.offset); !almostEqualColors(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	if got := new(Gradient).Evaluate(0.5); got != (Color4{}) {
+		t.Errorf("empty gradient: got %v", got)
+	}
+	if got := NewGradientColors(testRed).Evaluate(0.5); got != testRed {
+		t.Errorf("single color gradient: got %v", got)
+	}
+}
+
+func TestGradientSample(t *testing.T) {
+
+	g := NewGradientColors(testBlack, testRed, testWhite)
+	tests := []struct {
+		count int
+		want  []Color4
+	}{
+		{0, []Color4{}},
+		{1, []Color4{testBlack}},
+		{3, []Color4{testBlack, testRed, testWhite}},
+		{5, []Color4{testBlack, {0.5, 0, 0, 1}, testRed, {1, 0.5, 0.5, 1}, testWhite}},
+	}
+	for _, test := range tests {
+		got := g.Sample(test.count)
+		if len(got) != len(test.want) {
+			t.Errorf("%d samples: got %v, want %v", test.count, got, test.want)
+			continue
+		}
+		for i := range got {
+			if !almostEqualColors(got[i], test.want[i]) {
+				t.Errorf("%d samples: got %v, want %v", test.count, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestGradientStops(t *testing.T) {
+
+	stops := []GradientStop{{0, testBlack}, {1, testWhite}}
+	g := NewGradient(stops...)
+	stops[0].Color = testRed
+
+	// offsets returns the offsets of the stops of the gradient.
+	offsets := func() []float32 {
+		var res []float32
+		for _, s := range g.Stops() {
+			res = append(res, s.Offset)
+		}
+		return res
+	}
+	tests := []struct {
+		name    string
+		edit    func() int
+		index   int
+		offsets []float32
+	}{
+		{"add in the middle", func() int { return g.AddStop(0.5, &testRed) }, 1, []float32{0, 0.5, 1}},
+		{"add after an equal offset", func() int { return g.AddStop(0.5, &testBlue) }, 2, []float32{0, 0.5, 0.5, 1}},
+		{"move to the end", func() int { return g.SetStop(1, GradientStop{1.5, testRed}) }, 3, []float32{0, 0.5, 1, 1.5}},
+		{"move to the start", func() int { return g.SetStop(3, GradientStop{-0.5, testRed}) }, 0, []float32{-0.5, 0, 0.5, 1}},
+		{"remove", func() int { g.RemoveStop(0); return 0 }, 0, []float32{0, 0.5, 1}},
+	}
+	for _, test := range tests {
+		if index := test.edit(); index != test.index {
+			t.Errorf("%s: index %d, want %d", test.name, index, test.index)
+		}
+		if !reflect.DeepEqual(offsets(), test.offsets) {
+			t.Errorf("%s: offsets %v, want %v", test.name, offsets(), test.offsets)
+		}
+	}
+
+	// The stops are copied from the arguments and by Clone
+	if g.Stop(0).Color != testBlack {
+		t.Errorf("first stop %v changed with the argument", g.Stop(0))
+	}
+	clone := g.Clone()
+	g.RemoveStop(1)
+	if clone.StopCount() != 3 || g.StopCount() != 2 {
+		t.Errorf("clone has %d stops and gradient %d", clone.StopCount(), g.StopCount())
+	}
+	if c := clone.Stop(1).Color; c != testBlue {
+		t.Errorf("clone middle stop %v, want %v", c, testBlue)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"sort"
+)
+
+// KeyframeInterp is the interpolation of a KeyframeCurve from a keyframe to the next.
+type KeyframeInterp int
+
+// The interpolations between keyframes.
+const (
+	KeyframeCubic    = KeyframeInterp(iota) // Cubic Hermite spline using the keyframe tangents
+	KeyframeLinear                          // Straight line
+	KeyframeConstant                        // Value of the keyframe until the next keyframe
+)
+
+// Keyframe is a point of a KeyframeCurve with the slopes of the curve
+// entering and leaving it.
+type Keyframe struct {
+	Time       float32        // Time (or any other parameter) of the keyframe
+	Value      float32        // Value at the keyframe time
+	InTangent  float32        // Slope of the curve entering the keyframe
+	OutTangent float32        // Slope of the curve leaving the keyframe
+	Interp     KeyframeInterp // Interpolation to the next keyframe
+}
+
+// KeyframeCurve is a curve of a scalar value over time defined by keyframes.
+// It is used for animation curves, color ramps or parameters varying over
+// the lifetime of objects. Before the first and after the last keyframe
+// the curve keeps the values of these keyframes.
+type KeyframeCurve struct {
+	keys []Keyframe // Keyframes sorted by time
+}
+
+// NewKeyframeCurve creates and returns a pointer to a new curve with the specified keyframes.
+func NewKeyframeCurve(keys ...Keyframe) *KeyframeCurve {
+
+	c := new(KeyframeCurve)
+	c.SetKeys(keys)
+	return c
+}
+
+// NewLinearKeyframeCurve creates and returns a pointer to a new curve with two linear keyframes.
+func NewLinearKeyframeCurve(t0, v0, t1, v1 float32) *KeyframeCurve {
+
+	var slope float32
+	if t1 != t0 {
+		slope = (v1 - v0) / (t1 - t0)
+	}
+	return NewKeyframeCurve(
+		Keyframe{Time: t0, Value: v0, InTangent: slope, OutTangent: slope, Interp: KeyframeLinear},
+		Keyframe{Time: t1, Value: v1, InTangent: slope, OutTangent: slope, Interp: KeyframeLinear},
+	)
+}
+
+// SetKeys replaces the keyframes of this curve by a copy of the specified ones.
+func (c *KeyframeCurve) SetKeys(keys []Keyframe) *KeyframeCurve {
+
+	c.keys = append(c.keys[:0], keys...)
+	sort.SliceStable(c.keys, func(i, j int) bool { return c.keys[i].Time < c.keys[j].Time })
+	return c
+}
+
+// Keys returns the keyframes of this curve sorted by time.
+// The returned slice must not be modified.
+func (c *KeyframeCurve) Keys() []Keyframe {
+
+	return c.keys
+}
+
+// KeyCount returns the number of keyframes of this curve.
+func (c *KeyframeCurve) KeyCount() int {
+
+	return len(c.keys)
+}
+
+// Key returns the keyframe at the specified index.
+func (c *KeyframeCurve) Key(i int) Keyframe {
+
+	return c.keys[i]
+}
+
+// AddKey inserts the specified keyframe keeping the keyframes sorted by time
+// and returns its index.
+func (c *KeyframeCurve) AddKey(key Keyframe) int {
+
+	i := sort.Search(len(c.keys), func(i int) bool { return c.keys[i].Time > key.Time })
+	c.keys = append(c.keys, Keyframe{})
+	copy(c.keys[i+1:], c.keys[i:])
+	c.keys[i] = key
+	return i
+}
+
+// SetKey replaces the keyframe at the specified index, moving it if its time
+// changed the order of the keyframes, and returns its new index.
+func (c *KeyframeCurve) SetKey(i int, key Keyframe) int {
+
+	c.RemoveKey(i)
+	return c.AddKey(key)
+}
+
+// RemoveKey removes the keyframe at the specified index.
+func (c *KeyframeCurve) RemoveKey(i int) {
+
+	copy(c.keys[i:], c.keys[i+1:])
+	c.keys = c.keys[:len(c.keys)-1]
+}
+
+// TimeRange returns the times of the first and last keyframes.
+func (c *KeyframeCurve) TimeRange() (float32, float32) {
+
+	if len(c.keys) == 0 {
+		return 0, 0
+	}
+	return c.keys[0].Time, c.keys[len(c.keys)-1].Time
+}
+
+// SmoothTangents sets the tangents of the keyframe at the specified index to the slope
+// between its neighbours (Catmull-Rom), or to zero for the first and last keyframes.
+func (c *KeyframeCurve) SmoothTangents(i int) {
+
+	k := &c.keys[i]
+	var slope float32
+	if i > 0 && i < len(c.keys)-1 {
+		prev, next := &c.keys[i-1], &c.keys[i+1]
+		if next.Time > prev.Time {
+			slope = (next.Value - prev.Value) / (next.Time - prev.Time)
+		}
+	}
+	k.InTangent = slope
+	k.OutTangent = slope
+}
+
+// Evaluate returns the value of the curve at the specified time.
+func (c *KeyframeCurve) Evaluate(t float32) float32 {
+
+	n := len(c.keys)
+	if n == 0 {
+		return 0
+	}
+	if t <= c.keys[0].Time {
+		return c.keys[0].Value
+	}
+	if t >= c.keys[n-1].Time {
+		return c.keys[n-1].Value
+	}
+	// Finds the segment containing the time
+	i := sort.Search(n, func(i int) bool { return c.keys[i].Time > t }) - 1
+	k0, k1 := &c.keys[i], &c.keys[i+1]
+	dt := k1.Time - k0.Time
+	if dt <= 0 {
+		return k1.Value
+	}
+	s := (t - k0.Time) / dt
+	switch k0.Interp {
+	case KeyframeConstant:
+		return k0.Value
+	case KeyframeLinear:
+		return k0.Value + (k1.Value-k0.Value)*s
+	}
+	// Cubic Hermite basis functions
+	s2 := s * s
+	s3 := s2 * s
+	h00 := 2*s3 - 3*s2 + 1
+	h10 := s3 - 2*s2 + s
+	h01 := -2*s3 + 3*s2
+	h11 := s3 - s2
+	return h00*k0.Value + h10*dt*k0.OutTangent + h01*k1.Value + h11*dt*k1.InTangent
+}
@@ -82,6 +82,10 @@ func Cos(v float32) float32 {
 	return float32(math.Cos(float64(v)))
 }
 
+func Exp(v float32) float32 {
+	return float32(math.Exp(float64(v)))
+}
+
 func Floor(v float32) float32 {
 	return float32(math.Floor(float64(v)))
 }
@@ -98,6 +102,10 @@ func IsNaN(v float32) bool {
 	return math.IsNaN(float64(v))
 }
 
+func Log2(v float32) float32 {
+	return float32(math.Log2(float64(v)))
+}
+
 func Sin(v float32) float32 {
 	return float32(math.Sin(float64(v)))
 }
@@ -0,0 +1,419 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math"
+)
+
+// OBB represents a 3D oriented bounding box defined by its center, its
+// orthonormal local axes and its half size along each of the local axes.
+type OBB struct {
+	Center   Vector3    // Center of the box
+	Axes     [3]Vector3 // Unit local X, Y and Z axes of the box
+	HalfSize Vector3    // Half of the size of the box along each local axis
+}
+
+// Small value added to the rotation terms of the separating axis tests to
+// avoid false separations when edges are parallel and their cross product is near zero.
+const obbEpsilon = 1e-6
+
+// NewOBB creates and returns a pointer to a new axis aligned OBB
+// with the specified center and half size.
+func NewOBB(center, halfSize *Vector3) *OBB {
+
+	o := new(OBB)
+	o.Set(center, halfSize)
+	return o
+}
+
+// Set sets this OBB axis aligned with the specified center and half size.
+// Returns pointer to this updated OBB.
+func (o *OBB) Set(center, halfSize *Vector3) *OBB {
+
+	o.Center = *center
+	o.HalfSize = *halfSize
+	o.Axes[0].Set(1, 0, 0)
+	o.Axes[1].Set(0, 1, 0)
+	o.Axes[2].Set(0, 0, 1)
+	return o
+}
+
+// SetFromBox3 sets this OBB from the specified axis aligned box transformed by the
+// specified matrix, which may contain translation, rotation and scale but no shear.
+// Returns pointer to this updated OBB.
+func (o *OBB) SetFromBox3(box *Box3, m *Matrix4) *OBB {
+
+	box.Center(&o.Center)
+	o.HalfSize.SubVectors(&box.Max, &box.Min).MultiplyScalar(0.5)
+	o.Axes[0].Set(1, 0, 0)
+	o.Axes[1].Set(0, 1, 0)
+	o.Axes[2].Set(0, 0, 1)
+	if m != nil {
+		o.ApplyMatrix4(m)
+	}
+	return o
+}
+
+// SetFromPoints sets this OBB to a tight fitting box containing the specified points.
+// The orientation is given by the principal axes of the points and if the resulting
+// box is larger than the axis aligned box of the points the latter is used.
+// Returns pointer to this updated OBB.
+func (o *OBB) SetFromPoints(points []Vector3) *OBB {
+
+	if len(points) == 0 {
+		o.Set(NewVector3(0, 0, 0), NewVector3(0, 0, 0))
+		return o
+	}
+
+	// Axis aligned box of the points
+	var box Box3
+	box.SetFromPoints(points)
+	var aabb OBB
+	aabb.SetFromBox3(&box, nil)
+
+	// Covariance matrix of the points
+	var mean [3]float64
+	for i := range points {
+		mean[0] += float64(points[i].X)
+		mean[1] += float64(points[i].Y)
+		mean[2] += float64(points[i].Z)
+	}
+	n := float64(len(points))
+	mean[0] /= n
+	mean[1] /= n
+	mean[2] /= n
+	var cov [3][3]float64
+	for i := range points {
+		d := [3]float64{float64(points[i].X) - mean[0], float64(points[i].Y) - mean[1], float64(points[i].Z) - mean[2]}
+		for r := 0; r < 3; r++ {
+			for c := r; c < 3; c++ {
+				cov[r][c] += d[r] * d[c]
+			}
+		}
+	}
+	cov[1][0] = cov[0][1]
+	cov[2][0] = cov[0][2]
+	cov[2][1] = cov[1][2]
+
+	// The eigenvectors of the covariance matrix are the principal axes
+	vecs := symmetricEigenvectors3(cov)
+	var pca OBB
+	for i := 0; i < 3; i++ {
+		pca.Axes[i].Set(float32(vecs[0][i]), float32(vecs[1][i]), float32(vecs[2][i]))
+		pca.Axes[i].Normalize()
+	}
+	// Makes the axes orthonormal and right handed
+	pca.Axes[1].Sub(pca.Axes[0].Clone().MultiplyScalar(pca.Axes[0].Dot(&pca.Axes[1]))).Normalize()
+	pca.Axes[2].CrossVectors(&pca.Axes[0], &pca.Axes[1])
+
+	// Extents of the points along the principal axes
+	var min, max [3]float32
+	for i := 0; i < 3; i++ {
+		min[i] = Infinity
+		max[i] = -Infinity
+	}
+	for i := range points {
+		for a := 0; a < 3; a++ {
+			d := pca.Axes[a].Dot(&points[i])
+			min[a] = Min(min[a], d)
+			max[a] = Max(max[a], d)
+		}
+	}
+	pca.HalfSize.Set((max[0]-min[0])/2, (max[1]-min[1])/2, (max[2]-min[2])/2)
+	pca.Center.Set(0, 0, 0)
+	for a := 0; a < 3; a++ {
+		pca.Center.Add(pca.Axes[a].Clone().MultiplyScalar((max[a] + min[a]) / 2))
+	}
+
+	if pca.Volume() < aabb.Volume() {
+		*o = pca
+	} else {
+		*o = aabb
+	}
+	return o
+}
+
+// symmetricEigenvectors3 returns the eigenvectors, as columns, of the specified
+// symmetric 3x3 matrix using the cyclic Jacobi method.
+func symmetricEigenvectors3(a [3][3]float64) [3][3]float64 {
+
+	v := [3][3]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	for sweep := 0; sweep < 32; sweep++ {
+		off := a[0][1]*a[0][1] + a[0][2]*a[0][2] + a[1][2]*a[1][2]
+		if off < 1e-20 {
+			break
+		}
+		for p := 0; p < 2; p++ {
+			for q := p + 1; q < 3; q++ {
+				if math.Abs(a[p][q]) < 1e-30 {
+					continue
+				}
+				// Rotation which zeroes the element a[p][q]
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < 3; k++ {
+					akp := a[k][p]
+					akq := a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				for k := 0; k < 3; k++ {
+					apk := a[p][k]
+					aqk := a[q][k]
+					a[p][k] = c*apk - s*aqk
+					a[q][k] = s*apk + c*aqk
+				}
+				for k := 0; k < 3; k++ {
+					vkp := v[k][p]
+					vkq := v[k][q]
+					v[k][p] = c*vkp - s*vkq
+					v[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+	return v
+}
+
+// Copy copy other OBB to this one.
+// Returns pointer to this updated OBB.
+func (o *OBB) Copy(other *OBB) *OBB {
+
+	*o = *other
+	return o
+}
+
+// Clone creates and returns a pointer to a copy of this OBB.
+func (o *OBB) Clone() *OBB {
+
+	return new(OBB).Copy(o)
+}
+
+// Size returns the size of this OBB along each of its local axes.
+// The size is stored in optionalTarget, if not nil, and also returned.
+func (o *OBB) Size(optionalTarget *Vector3) *Vector3 {
+
+	var result *Vector3
+	if optionalTarget == nil {
+		result = NewVector3(0, 0, 0)
+	} else {
+		result = optionalTarget
+	}
+	return result.Copy(&o.HalfSize).MultiplyScalar(2)
+}
+
+// Volume returns the volume of this OBB.
+func (o *OBB) Volume() float32 {
+
+	return 8 * o.HalfSize.X * o.HalfSize.Y * o.HalfSize.Z
+}
+
+// ApplyMatrix4 transforms this OBB by the specified matrix, which may contain
+// translation, rotation and scale. Scales which are not uniform are only exact if
+// they are applied along the axes of the OBB.
+// Returns pointer to this updated OBB.
+func (o *OBB) ApplyMatrix4(m *Matrix4) *OBB {
+
+	var m3 Matrix3
+	m3.SetFromMatrix4(m)
+	o.Center.ApplyMatrix4(m)
+	half := [3]*float32{&o.HalfSize.X, &o.HalfSize.Y, &o.HalfSize.Z}
+	for i := 0; i < 3; i++ {
+		o.Axes[i].ApplyMatrix3(&m3)
+		l := o.Axes[i].Length()
+		*half[i] *= l
+		if l > 0 {
+			o.Axes[i].MultiplyScalar(1 / l)
+		}
+	}
+	return o
+}
+
+// localPoint returns the coordinates of the specified world point in the frame of this OBB.
+func (o *OBB) localPoint(point *Vector3) Vector3 {
+
+	var d Vector3
+	d.SubVectors(point, &o.Center)
+	return Vector3{X: d.Dot(&o.Axes[0]), Y: d.Dot(&o.Axes[1]), Z: d.Dot(&o.Axes[2])}
+}
+
+// worldPoint returns the world coordinates of the specified point in the frame of this OBB.
+func (o *OBB) worldPoint(local *Vector3) Vector3 {
+
+	p := o.Center
+	p.X += o.Axes[0].X*local.X + o.Axes[1].X*local.Y + o.Axes[2].X*local.Z
+	p.Y += o.Axes[0].Y*local.X + o.Axes[1].Y*local.Y + o.Axes[2].Y*local.Z
+	p.Z += o.Axes[0].Z*local.X + o.Axes[1].Z*local.Y + o.Axes[2].Z*local.Z
+	return p
+}
+
+// ContainsPoint returns if this OBB contains the specified point.
+func (o *OBB) ContainsPoint(point *Vector3) bool {
+
+	p := o.localPoint(point)
+	return Abs(p.X) <= o.HalfSize.X && Abs(p.Y) <= o.HalfSize.Y && Abs(p.Z) <= o.HalfSize.Z
+}
+
+// ClampPoint calculates the point of this OBB which is closest to the specified point.
+// The calculated point is stored in optionalTarget, if not nil, and also returned.
+func (o *OBB) ClampPoint(point *Vector3, optionalTarget *Vector3) *Vector3 {
+
+	var result *Vector3
+	if optionalTarget == nil {
+		result = NewVector3(0, 0, 0)
+	} else {
+		result = optionalTarget
+	}
+	p := o.localPoint(point)
+	p.X = Clamp(p.X, -o.HalfSize.X, o.HalfSize.X)
+	p.Y = Clamp(p.Y, -o.HalfSize.Y, o.HalfSize.Y)
+	p.Z = Clamp(p.Z, -o.HalfSize.Z, o.HalfSize.Z)
+	*result = o.worldPoint(&p)
+	return result
+}
+
+// DistanceToPoint returns the distance from this OBB to the specified point,
+// which is zero if the point is inside the OBB.
+func (o *OBB) DistanceToPoint(point *Vector3) float32 {
+
+	var closest Vector3
+	o.ClampPoint(point, &closest)
+	return closest.DistanceTo(point)
+}
+
+// GetBoundingBox calculates the axis aligned box which contains this OBB.
+// The box is stored in optionalTarget, if not nil, and also returned.
+func (o *OBB) GetBoundingBox(optionalTarget *Box3) *Box3 {
+
+	var result *Box3
+	if optionalTarget == nil {
+		result = NewBox3(nil, nil)
+	} else {
+		result = optionalTarget
+	}
+	var ext Vector3
+	half := [3]float32{o.HalfSize.X, o.HalfSize.Y, o.HalfSize.Z}
+	for i := 0; i < 3; i++ {
+		ext.X += Abs(o.Axes[i].X) * half[i]
+		ext.Y += Abs(o.Axes[i].Y) * half[i]
+		ext.Z += Abs(o.Axes[i].Z) * half[i]
+	}
+	result.Min.SubVectors(&o.Center, &ext)
+	result.Max.AddVectors(&o.Center, &ext)
+	return result
+}
+
+// IsIntersectionSphere returns if this OBB intersects the specified sphere.
+func (o *OBB) IsIntersectionSphere(sphere *Sphere) bool {
+
+	return o.DistanceToPoint(&sphere.Center) <= sphere.Radius
+}
+
+// IsIntersectionBox returns if this OBB intersects the specified axis aligned box.
+func (o *OBB) IsIntersectionBox(box *Box3) bool {
+
+	var other OBB
+	other.SetFromBox3(box, nil)
+	return o.IsIntersectionOBB(&other)
+}
+
+// IsIntersectionOBB returns if this OBB intersects the specified OBB
+// using the separating axis test.
+func (o *OBB) IsIntersectionOBB(other *OBB) bool {
+
+	ha := [3]float32{o.HalfSize.X, o.HalfSize.Y, o.HalfSize.Z}
+	hb := [3]float32{other.HalfSize.X, other.HalfSize.Y, other.HalfSize.Z}
+
+	// Rotation matrix expressing the other OBB in the frame of this OBB
+	var r, absR [3][3]float32
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r[i][j] = o.Axes[i].Dot(&other.Axes[j])
+			absR[i][j] = Abs(r[i][j]) + obbEpsilon
+		}
+	}
+
+	// Translation vector in the frame of this OBB
+	tl := o.localPoint(&other.Center)
+	t := [3]float32{tl.X, tl.Y, tl.Z}
+
+	// Axes of this OBB
+	for i := 0; i < 3; i++ {
+		rb := hb[0]*absR[i][0] + hb[1]*absR[i][1] + hb[2]*absR[i][2]
+		if Abs(t[i]) > ha[i]+rb {
+			return false
+		}
+	}
+
+	// Axes of the other OBB
+	for j := 0; j < 3; j++ {
+		ra := ha[0]*absR[0][j] + ha[1]*absR[1][j] + ha[2]*absR[2][j]
+		if Abs(t[0]*r[0][j]+t[1]*r[1][j]+t[2]*r[2][j]) > ra+hb[j] {
+			return false
+		}
+	}
+
+	// Cross products of the axes of both OBBs
+	for i := 0; i < 3; i++ {
+		i1 := (i + 1) % 3
+		i2 := (i + 2) % 3
+		for j := 0; j < 3; j++ {
+			j1 := (j + 1) % 3
+			j2 := (j + 2) % 3
+			ra := ha[i1]*absR[i2][j] + ha[i2]*absR[i1][j]
+			rb := hb[j1]*absR[i][j2] + hb[j2]*absR[i][j1]
+			if Abs(t[i2]*r[i1][j]-t[i1]*r[i2][j]) > ra+rb {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IsIntersectionRay returns if the specified ray intersects this OBB.
+func (o *OBB) IsIntersectionRay(ray *Ray) bool {
+
+	var v Vector3
+	return o.IntersectRay(ray, &v) != nil
+}
+
+// IntersectRay calculates the point which is the intersection of the specified ray with this OBB.
+// The calculated point is stored in optionalTarget, if not nil, and also returned.
+// If no intersection is found nil is returned.
+func (o *OBB) IntersectRay(ray *Ray, optionalTarget *Vector3) *Vector3 {
+
+	// Transforms the ray to the frame of this OBB and intersects it with the local box
+	var local Ray
+	local.origin = o.localPoint(&ray.origin)
+	local.direction.Set(ray.direction.Dot(&o.Axes[0]), ray.direction.Dot(&o.Axes[1]), ray.direction.Dot(&o.Axes[2]))
+	var box Box3
+	box.Max = o.HalfSize
+	box.Min.Copy(&o.HalfSize).Negate()
+	var p Vector3
+	if local.IntersectBox(&box, &p) == nil {
+		return nil
+	}
+
+	var result *Vector3
+	if optionalTarget == nil {
+		result = NewVector3(0, 0, 0)
+	} else {
+		result = optionalTarget
+	}
+	*result = o.worldPoint(&p)
+	return result
+}
+
+// IsIntersectionFrustum returns if this OBB intersects the specified frustum.
+func (o *OBB) IsIntersectionFrustum(frustum *Frustum) bool {
+
+	return frustum.IntersectsOBB(o)
+}
@@ -0,0 +1,242 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import "testing"
+
+// newTestOBB returns an OBB with the specified center and half size
+// rotated by the specified angle around the Z axis.
+func newTestOBB(center, halfSize *Vector3, angle float32) *OBB {
+
+	var m Matrix4
+	m.MakeRotationZ(angle)
+	o := NewOBB(NewVector3(0, 0, 0), halfSize).ApplyMatrix4(&m)
+	o.Center = *center
+	return o
+}
+
+func TestOBBPoints(t *testing.T) {
+
+	// Long box rotated 45 degrees with its local X axis along (1,1,0)
+	o := newTestOBB(NewVector3(0, 0, 0), NewVector3(2, 0.5, 0.5), Pi/4)
+	s := Sqrt(0.5)
+	tests := []struct {
+		name     string
+		point    Vector3
+		contains bool
+		closest  Vector3
+	}{
+		{"center", Vector3{0, 0, 0}, true, Vector3{0, 0, 0}},
+		{"along local x", Vector3{1, 1, 0}, true, Vector3{1, 1, 0}},
+		{"inside the axis aligned box only", Vector3{1.5, 0, 0}, false, Vector3{0.75 + s/2, 0.75 - s/2, 0}},
+		{"beyond the end", Vector3{3, 3, 0}, false, Vector3{2 * s, 2 * s, 0}},
+		{"above", Vector3{0, 0, 1}, false, Vector3{0, 0, 0.5}},
+	}
+	for _, test := range tests {
+		if o.ContainsPoint(&test.point) != test.contains {
+			t.Errorf("%s: contains %v, want %v", test.name, !test.contains, test.contains)
+		}
+		closest := o.ClampPoint(&test.point, nil)
+		if !closest.AlmostEquals(&test.closest, 1e-5) {
+			t.Errorf("%s: closest point %v, want %v", test.name, *closest, test.closest)
+		}
+		dist := o.DistanceToPoint(&test.point)
+		if Abs(dist-test.point.DistanceTo(&test.closest)) > 1e-5 {
+			t.Errorf("%s: distance %v, want %v", test.name, dist, test.point.DistanceTo(&test.closest))
+		}
+	}
+
+	box := o.GetBoundingBox(nil)
+	ext := 2.5 * s
+	want := Box3{Vector3{-ext, -ext, -0.5}, Vector3{ext, ext, 0.5}}
+	if !box.Min.AlmostEquals(&want.Min, 1e-5) || !box.Max.AlmostEquals(&want.Max, 1e-5) {
+		t.Errorf("bounding box %v, want %v", *box, want)
+	}
+}
+
+func TestOBBTransform(t *testing.T) {
+
+	// Unit cube scaled along X, rotated 90 degrees around Z and translated
+	var scale, rot, trans, rs, m Matrix4
+	scale.MakeScale(2, 1, 3)
+	rot.MakeRotationZ(Pi / 2)
+	trans.MakeTranslation(1, 2, 3)
+	rs.MultiplyMatrices(&rot, &scale)
+	m.MultiplyMatrices(&trans, &rs)
+	box := NewBox3(NewVector3(-1, -1, -1), NewVector3(1, 1, 1))
+	var o OBB
+	o.SetFromBox3(box, &m)
+
+	tests := []struct {
+		name string
+		got  Vector3
+		want Vector3
+	}{
+		{"center", o.Center, Vector3{1, 2, 3}},
+		{"half size", o.HalfSize, Vector3{2, 1, 3}},
+		{"x axis", o.Axes[0], Vector3{0, 1, 0}},
+		{"y axis", o.Axes[1], Vector3{-1, 0, 0}},
+		{"z axis", o.Axes[2], Vector3{0, 0, 1}},
+	}
+	for _, test := range tests {
+		if !test.got.AlmostEquals(&test.want, 1e-5) {
+			t.Errorf("%s: got %v, want %v", test.name, test.got, test.want)
+		}
+	}
+	if v := o.Volume(); Abs(v-48) > 1e-4 {
+		t.Errorf("volume %v, want 48", v)
+	}
+}
+
+func TestOBBSetFromPoints(t *testing.T) {
+
+	// Corners of the specified OBB
+	corners := func(o *OBB) []Vector3 {
+		var points []Vector3
+		for _, x := range []float32{-1, 1} {
+			for _, y := range []float32{-1, 1} {
+				for _, z := range []float32{-1, 1} {
+					local := Vector3{x * o.HalfSize.X, y * o.HalfSize.Y, z * o.HalfSize.Z}
+					points = append(points, o.worldPoint(&local))
+				}
+			}
+		}
+		return points
+	}
+	tests := []struct {
+		name   string
+		obb    *OBB
+		volume float32
+	}{
+		{"axis aligned", newTestOBB(NewVector3(1, 2, 3), NewVector3(2, 1, 0.5), 0), 8},
+		{"rotated", newTestOBB(NewVector3(1, 2, 3), NewVector3(2, 1, 0.25), Pi/6), 4},
+	}
+	for _, test := range tests {
+		points := corners(test.obb)
+		var o OBB
+		o.SetFromPoints(points)
+		if Abs(o.Volume()-test.volume) > 1e-3 {
+			t.Errorf("%s: volume %v, want %v", test.name, o.Volume(), test.volume)
+		}
+		if !o.Center.AlmostEquals(&test.obb.Center, 1e-4) {
+			t.Errorf("%s: center %v, want %v", test.name, o.Center, test.obb.Center)
+		}
+		for i := range points {
+			if d := o.DistanceToPoint(&points[i]); d > 1e-4 {
+				t.Errorf("%s: point %v outside at distance %v", test.name, points[i], d)
+			}
+		}
+	}
+}
+
+func TestOBBIntersections(t *testing.T) {
+
+	o := newTestOBB(NewVector3(0, 0, 0), NewVector3(2, 0.5, 0.5), Pi/4)
+	s := Sqrt(0.5)
+	tests := []struct {
+		name  string
+		other *OBB
+		want  bool
+	}{
+		{"overlapping", NewOBB(NewVector3(1, 1, 0), NewVector3(0.3, 0.3, 0.3)), true},
+		{"inside the axis aligned box only", NewOBB(NewVector3(1.5, -0.5, 0), NewVector3(0.3, 0.3, 0.3)), false},
+		{"parallel and touching", newTestOBB(NewVector3(3.9*s, 3.9*s, 0), NewVector3(2, 0.5, 0.5), Pi/4), true},
+		{"parallel and separated", newTestOBB(NewVector3(4.1*s, 4.1*s, 0), NewVector3(2, 0.5, 0.5), Pi/4), false},
+		{"crossing", newTestOBB(NewVector3(0, 0, 0), NewVector3(2, 0.5, 0.5), -Pi/4), true},
+		{"above", NewOBB(NewVector3(0, 0, 0.9), NewVector3(0.5, 0.5, 0.5)), true},
+		{"far above", NewOBB(NewVector3(0, 0, 1.1), NewVector3(0.5, 0.5, 0.5)), false},
+	}
+	for _, test := range tests {
+		if got := o.IsIntersectionOBB(test.other); got != test.want {
+			t.Errorf("%s: intersection %v, want %v", test.name, got, test.want)
+		}
+		if got := test.other.IsIntersectionOBB(o); got != test.want {
+			t.Errorf("%s: reverse intersection %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	// Axis aligned boxes and spheres
+	box := NewBox3(NewVector3(1.2, -0.8, -0.3), NewVector3(1.8, -0.2, 0.3))
+	if o.IsIntersectionBox(box) {
+		t.Errorf("intersects box %v", *box)
+	}
+	box.Set(NewVector3(0.7, 0.7, -0.3), NewVector3(1.3, 1.3, 0.3))
+	if !o.IsIntersectionBox(box) {
+		t.Errorf("does not intersect box %v", *box)
+	}
+	dist := NewVector3(3, 3, 0).DistanceTo(NewVector3(2*s, 2*s, 0))
+	if !o.IsIntersectionSphere(NewSphere(NewVector3(3, 3, 0), dist+0.01)) {
+		t.Error("does not intersect sphere")
+	}
+	if o.IsIntersectionSphere(NewSphere(NewVector3(3, 3, 0), dist-0.01)) {
+		t.Error("intersects sphere")
+	}
+}
+
+func TestOBBRay(t *testing.T) {
+
+	o := newTestOBB(NewVector3(0, 0, 0), NewVector3(2, 0.5, 0.5), Pi/4)
+	tests := []struct {
+		name      string
+		origin    Vector3
+		direction Vector3
+		point     *Vector3
+	}{
+		{"along world x", Vector3{-5, 0, 0}, Vector3{1, 0, 0}, &Vector3{-Sqrt(0.5), 0, 0}},
+		{"down through the box", Vector3{1, 1, 5}, Vector3{0, 0, -1}, &Vector3{1, 1, 0.5}},
+		{"inside the axis aligned box only", Vector3{1.5, -1.5, -5}, Vector3{0, 0, 1}, nil},
+		{"pointing away", Vector3{-5, 0, 0}, Vector3{-1, 0, 0}, nil},
+	}
+	for _, test := range tests {
+		ray := NewRay(&test.origin, &test.direction)
+		point := o.IntersectRay(ray, nil)
+		if o.IsIntersectionRay(ray) != (test.point != nil) {
+			t.Errorf("%s: intersection %v, want %v", test.name, test.point == nil, test.point != nil)
+		}
+		if test.point == nil {
+			if point != nil {
+				t.Errorf("%s: intersection at %v", test.name, *point)
+			}
+			continue
+		}
+		if point == nil || !point.AlmostEquals(test.point, 1e-5) {
+			t.Errorf("%s: intersection at %v, want %v", test.name, point, *test.point)
+		}
+	}
+}
+
+func TestOBBFrustum(t *testing.T) {
+
+	// Frustum looking down -Z with its right plane at x = -z
+	var proj Matrix4
+	proj.MakePerspective(90, 1, 0.1, 100)
+	frustum := NewFrustumFromMatrix(&proj)
+
+	// Thin box parallel to the right plane and outside it
+	// whose axis aligned bounding box intersects the frustum
+	var rot Matrix4
+	rot.MakeRotationY(Pi / 4)
+	thin := NewOBB(NewVector3(0, 0, 0), NewVector3(5, 0.1, 0.1)).ApplyMatrix4(&rot)
+	thin.Center.Set(3, 0, -1)
+
+	tests := []struct {
+		name string
+		obb  *OBB
+		want bool
+	}{
+		{"in front", NewOBB(NewVector3(0, 0, -5), NewVector3(1, 1, 1)), true},
+		{"behind", NewOBB(NewVector3(0, 0, 5), NewVector3(1, 1, 1)), false},
+		{"crossing the near plane", NewOBB(NewVector3(0, 0, 0), NewVector3(1, 1, 1)), true},
+		{"thin outside the right plane", thin, false},
+	}
+	for _, test := range tests {
+		if got := test.obb.IsIntersectionFrustum(frustum); got != test.want {
+			t.Errorf("%s: intersection %v, want %v", test.name, got, test.want)
+		}
+	}
+	if !frustum.IntersectsBox(thin.GetBoundingBox(nil)) {
+		t.Error("bounding box of the thin box does not intersect the frustum")
+	}
+}
@@ -0,0 +1,478 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// ISpline3 is the interface for 3D parametric spline curves
+// with the parameter t going from 0 to 1.
+type ISpline3 interface {
+	GetPoint(t float32, optionalTarget *Vector3) *Vector3
+	GetTangent(t float32, optionalTarget *Vector3) *Vector3
+}
+
+// Number of divisions of the arc length table of splines
+const splineArcDivisions = 200
+
+// Maximum subdivision depth of the adaptive sampling of splines
+const splineMaxDepth = 16
+
+// spline3 contains the arc length reparameterization and sampling
+// methods shared by all 3D splines.
+type spline3 struct {
+	ispline ISpline3  // Spline being parameterized
+	lengths []float32 // Cumulative arc lengths at uniform values of t (nil if not calculated)
+}
+
+// init initializes the spline base with the specified spline.
+func (s *spline3) init(ispline ISpline3) {
+
+	s.ispline = ispline
+	s.lengths = nil
+}
+
+// Invalidate must be called after the spline control points are changed
+// to recalculate its arc length table.
+func (s *spline3) Invalidate() {
+
+	s.lengths = nil
+}
+
+// arcLengths returns the cumulative arc lengths at uniform values of t,
+// calculating them if necessary.
+func (s *spline3) arcLengths() []float32 {
+
+	if s.lengths != nil {
+		return s.lengths
+	}
+	s.lengths = make([]float32, splineArcDivisions+1)
+	var prev, curr Vector3
+	s.ispline.GetPoint(0, &prev)
+	for i := 1; i <= splineArcDivisions; i++ {
+		s.ispline.GetPoint(float32(i)/splineArcDivisions, &curr)
+		s.lengths[i] = s.lengths[i-1] + curr.DistanceTo(&prev)
+		prev = curr
+	}
+	return s.lengths
+}
+
+// Length returns the approximate arc length of the spline.
+func (s *spline3) Length() float32 {
+
+	lengths := s.arcLengths()
+	return lengths[len(lengths)-1]
+}
+
+// UtoT converts the specified arc length fraction u from 0 to 1
+// to the spline parameter t.
+func (s *spline3) UtoT(u float32) float32 {
+
+	lengths := s.arcLengths()
+	target := Clamp(u, 0, 1) * lengths[len(lengths)-1]
+
+	// Binary search for the last arc length smaller than the target
+	low, high := 0, len(lengths)-1
+	for low < high {
+		mid := (low + high + 1) / 2
+		if lengths[mid] <= target {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	if low >= len(lengths)-1 {
+		return 1
+	}
+
+	// Interpolate inside the segment
+	segment := lengths[low+1] - lengths[low]
+	frac := float32(0)
+	if segment > 0 {
+		frac = (target - lengths[low]) / segment
+	}
+	return (float32(low) + frac) / splineArcDivisions
+}
+
+// GetPointAt returns the point of the spline at the specified arc length fraction u from 0 to 1.
+// Stores the point into optionalTarget, if not nil, and also returns it.
+func (s *spline3) GetPointAt(u float32, optionalTarget *Vector3) *Vector3 {
+
+	return s.ispline.GetPoint(s.UtoT(u), optionalTarget)
+}
+
+// GetTangentAt returns the unit tangent of the spline at the specified arc length fraction u from 0 to 1.
+// Stores the tangent into optionalTarget, if not nil, and also returns it.
+func (s *spline3) GetTangentAt(u float32, optionalTarget *Vector3) *Vector3 {
+
+	return s.ispline.GetTangent(s.UtoT(u), optionalTarget)
+}
+
+// GetPoints returns divisions+1 points of the spline at uniform values of t.
+func (s *spline3) GetPoints(divisions int) []Vector3 {
+
+	if divisions < 1 {
+		divisions = 1
+	}
+	points := make([]Vector3, divisions+1)
+	for i := range points {
+		s.ispline.GetPoint(float32(i)/float32(divisions), &points[i])
+	}
+	return points
+}
+
+// GetSpacedPoints returns divisions+1 points of the spline equally spaced along its arc length.
+func (s *spline3) GetSpacedPoints(divisions int) []Vector3 {
+
+	if divisions < 1 {
+		divisions = 1
+	}
+	points := make([]Vector3, divisions+1)
+	for i := range points {
+		s.GetPointAt(float32(i)/float32(divisions), &points[i])
+	}
+	return points
+}
+
+// Sample returns points of the spline with more points where the spline
+// bends more, so the distance between the spline and the polyline through
+// the points is less than the specified tolerance.
+func (s *spline3) Sample(tolerance float32) []Vector3 {
+
+	// Start with a few uniform segments so small features are not missed
+	const initial = 8
+	var p0, p1 Vector3
+	s.ispline.GetPoint(0, &p0)
+	points := []Vector3{p0}
+	for i := 1; i <= initial; i++ {
+		t0 := float32(i-1) / initial
+		t1 := float32(i) / initial
+		s.ispline.GetPoint(t1, &p1)
+		points = s.subdivide(points, t0, t1, &p0, &p1, tolerance, 0)
+		p0 = p1
+	}
+	return points
+}
+
+// subdivide appends to the specified points the points of the spline from t0 (exclusive)
+// to t1 (inclusive) subdividing the interval while the spline deviates from the chord more
+// than the specified tolerance.
+func (s *spline3) subdivide(points []Vector3, t0, t1 float32, p0, p1 *Vector3, tolerance float32, depth int) []Vector3 {
+
+	tm := (t0 + t1) / 2
+	var pm, mid Vector3
+	s.ispline.GetPoint(tm, &pm)
+	mid.Copy(p0).Lerp(p1, 0.5)
+	if depth >= splineMaxDepth || pm.DistanceTo(&mid) <= tolerance {
+		return append(points, *p1)
+	}
+	points = s.subdivide(points, t0, tm, p0, &pm, tolerance, depth+1)
+	return s.subdivide(points, tm, t1, &pm, p1, tolerance, depth+1)
+}
+
+// splineTarget returns optionalTarget or a new vector if it is nil.
+func splineTarget(optionalTarget *Vector3) *Vector3 {
+
+	if optionalTarget == nil {
+		return NewVector3(0, 0, 0)
+	}
+	return optionalTarget
+}
+
+// CubicBezier3 is a 3D cubic Bezier curve.
+// Invalidate must be called after changing its points.
+type CubicBezier3 struct {
+	spline3
+	V0 Vector3 // Start point
+	V1 Vector3 // First control point
+	V2 Vector3 // Second control point
+	V3 Vector3 // End point
+}
+
+// NewCubicBezier3 creates and returns a pointer to a new cubic Bezier curve
+// with the specified start point, control points and end point.
+func NewCubicBezier3(v0, v1, v2, v3 *Vector3) *CubicBezier3 {
+
+	c := new(CubicBezier3)
+	c.V0 = *v0
+	c.V1 = *v1
+	c.V2 = *v2
+	c.V3 = *v3
+	c.spline3.init(c)
+	return c
+}
+
+// GetPoint returns the point of the curve at the specified parameter t from 0 to 1.
+// Stores the point into optionalTarget, if not nil, and also returns it.
+func (c *CubicBezier3) GetPoint(t float32, optionalTarget *Vector3) *Vector3 {
+
+	k := 1 - t
+	b0 := k * k * k
+	b1 := 3 * k * k * t
+	b2 := 3 * k * t * t
+	b3 := t * t * t
+	return splineTarget(optionalTarget).Set(
+		b0*c.V0.X+b1*c.V1.X+b2*c.V2.X+b3*c.V3.X,
+		b0*c.V0.Y+b1*c.V1.Y+b2*c.V2.Y+b3*c.V3.Y,
+		b0*c.V0.Z+b1*c.V1.Z+b2*c.V2.Z+b3*c.V3.Z,
+	)
+}
+
+// GetTangent returns the unit tangent of the curve at the specified parameter t from 0 to 1.
+// Stores the tangent into optionalTarget, if not nil, and also returns it.
+func (c *CubicBezier3) GetTangent(t float32, optionalTarget *Vector3) *Vector3 {
+
+	k := 1 - t
+	d0 := -3 * k * k
+	d1 := 3*k*k - 6*k*t
+	d2 := 6*k*t - 3*t*t
+	d3 := 3 * t * t
+	return splineTarget(optionalTarget).Set(
+		d0*c.V0.X+d1*c.V1.X+d2*c.V2.X+d3*c.V3.X,
+		d0*c.V0.Y+d1*c.V1.Y+d2*c.V2.Y+d3*c.V3.Y,
+		d0*c.V0.Z+d1*c.V1.Z+d2*c.V2.Z+d3*c.V3.Z,
+	).Normalize()
+}
+
+// CatmullRom3 is a 3D Catmull-Rom spline which passes through all its points
+type CatmullRom3 struct {
+	spline3
+	points []Vector3 // Points of the spline
+	closed bool      // Closed spline flag
+}
+
+// NewCatmullRom3 creates and returns a pointer to a new uniform Catmull-Rom spline
+// passing through the specified points. If closed is true the spline also joins
+// the last point to the first one.
+func NewCatmullRom3(points []Vector3, closed bool) *CatmullRom3 {
+
+	c := new(CatmullRom3)
+	c.spline3.init(c)
+	c.SetPoints(points)
+	c.closed = closed
+	return c
+}
+
+// SetPoints sets the points of the spline.
+func (c *CatmullRom3) SetPoints(points []Vector3) {
+
+	c.points = make([]Vector3, len(points))
+	copy(c.points, points)
+	c.Invalidate()
+}
+
+// Points returns the points of the spline.
+func (c *CatmullRom3) Points() []Vector3 {
+
+	return c.points
+}
+
+// SetClosed sets whether the spline joins the last point to the first one.
+func (c *CatmullRom3) SetClosed(closed bool) {
+
+	c.closed = closed
+	c.Invalidate()
+}
+
+// Closed returns whether the spline joins the last point to the first one.
+func (c *CatmullRom3) Closed() bool {
+
+	return c.closed
+}
+
+// segment returns the four points of the segment at the specified parameter
+// and the local parameter inside the segment.
+func (c *CatmullRom3) segment(t float32) (*Vector3, *Vector3, *Vector3, *Vector3, float32) {
+
+	count := len(c.points)
+	segments := count - 1
+	if c.closed {
+		segments = count
+	}
+	p := Clamp(t, 0, 1) * float32(segments)
+	i := int(p)
+	if i >= segments {
+		i = segments - 1
+	}
+	local := p - float32(i)
+
+	// Returns the point at the specified index wrapping or clamping to the ends
+	point := func(idx int) *Vector3 {
+		if c.closed {
+			return &c.points[(idx+count)%count]
+		}
+		return &c.points[ClampInt(idx, 0, count-1)]
+	}
+	return point(i - 1), point(i), point(i + 1), point(i + 2), local
+}
+
+// GetPoint returns the point of the spline at the specified parameter t from 0 to 1.
+// Stores the point into optionalTarget, if not nil, and also returns it.
+func (c *CatmullRom3) GetPoint(t float32, optionalTarget *Vector3) *Vector3 {
+
+	result := splineTarget(optionalTarget)
+	if len(c.points) < 2 {
+		if len(c.points) == 1 {
+			result.Copy(&c.points[0])
+		}
+		return result
+	}
+	v0, v1, v2, v3, u := c.segment(t)
+	u2 := u * u
+	u3 := u2 * u
+	b0 := -0.5*u3 + u2 - 0.5*u
+	b1 := 1.5*u3 - 2.5*u2 + 1
+	b2 := -1.5*u3 + 2*u2 + 0.5*u
+	b3 := 0.5*u3 - 0.5*u2
+	return result.Set(
+		b0*v0.X+b1*v1.X+b2*v2.X+b3*v3.X,
+		b0*v0.Y+b1*v1.Y+b2*v2.Y+b3*v3.Y,
+		b0*v0.Z+b1*v1.Z+b2*v2.Z+b3*v3.Z,
+	)
+}
+
+// GetTangent returns the unit tangent of the spline at the specified parameter t from 0 to 1.
+// Stores the tangent into optionalTarget, if not nil, and also returns it.
+func (c *CatmullRom3) GetTangent(t float32, optionalTarget *Vector3) *Vector3 {
+
+	result := splineTarget(optionalTarget)
+	if len(c.points) < 2 {
+		return result.Zero()
+	}
+	v0, v1, v2, v3, u := c.segment(t)
+	u2 := u * u
+	d0 := -1.5*u2 + 2*u - 0.5
+	d1 := 4.5*u2 - 5*u
+	d2 := -4.5*u2 + 4*u + 0.5
+	d3 := 1.5*u2 - u
+	return result.Set(
+		d0*v0.X+d1*v1.X+d2*v2.X+d3*v3.X,
+		d0*v0.Y+d1*v1.Y+d2*v2.Y+d3*v3.Y,
+		d0*v0.Z+d1*v1.Z+d2*v2.Z+d3*v3.Z,
+	).Normalize()
+}
+
+// BSpline is a 3D clamped uniform B-spline which starts at its
+// first control point, ends at its last one and approximates the others
+type BSpline struct {
+	spline3
+	points []Vector3 // Control points
+	degree int       // Degree of the spline
+	knots  []float32 // Knot vector
+	deriv  []Vector3 // Control points of the derivative
+}
+
+// NewBSpline creates and returns a pointer to a new clamped uniform B-spline
+// with the specified control points and degree (3 for a cubic B-spline).
+// The degree is reduced if there are not enough control points.
+func NewBSpline(points []Vector3, degree int) *BSpline {
+
+	b := new(BSpline)
+	b.spline3.init(b)
+	b.degree = degree
+	b.SetPoints(points)
+	return b
+}
+
+// SetPoints sets the control points of the spline.
+func (b *BSpline) SetPoints(points []Vector3) {
+
+	b.points = make([]Vector3, len(points))
+	copy(b.points, points)
+	b.update()
+}
+
+// Points returns the control points of the spline.
+func (b *BSpline) Points() []Vector3 {
+
+	return b.points
+}
+
+// Degree returns the degree of the spline.
+func (b *BSpline) Degree() int {
+
+	return b.degree
+}
+
+// update calculates the knot vector and the derivative control points.
+func (b *BSpline) update() {
+
+	n := len(b.points)
+	b.degree = ClampInt(b.degree, 1, n-1)
+	if b.degree < 1 {
+		b.degree = 1
+	}
+	p := b.degree
+
+	// Clamped uniform knot vector with p+1 repeated knots at each end
+	b.knots = make([]float32, n+p+1)
+	spans := float32(n - p)
+	for i := range b.knots {
+		b.knots[i] = Clamp(float32(i-p)/spans, 0, 1)
+	}
+
+	// Control points of the derivative: p*(P[i+1]-P[i])/(u[i+p+1]-u[i+1])
+	b.deriv = b.deriv[:0]
+	for i := 0; i < n-1; i++ {
+		var d Vector3
+		du := b.knots[i+p+1] - b.knots[i+1]
+		if du > 0 {
+			d.SubVectors(&b.points[i+1], &b.points[i]).MultiplyScalar(float32(p) / du)
+		}
+		b.deriv = append(b.deriv, d)
+	}
+	b.Invalidate()
+}
+
+// deBoor evaluates the B-spline with the specified control points, degree and
+// knot vector offset at the specified parameter using the de Boor algorithm.
+func (b *BSpline) deBoor(points []Vector3, degree, offset int, t float32, result *Vector3) *Vector3 {
+
+	// Find the knot span containing t
+	knots := b.knots[offset : len(b.knots)-offset]
+	k := degree
+	for k < len(points)-1 && t >= knots[k+1] {
+		k++
+	}
+
+	d := make([]Vector3, degree+1)
+	for j := 0; j <= degree; j++ {
+		d[j] = points[j+k-degree]
+	}
+	for r := 1; r <= degree; r++ {
+		for j := degree; j >= r; j-- {
+			den := knots[j+1+k-r] - knots[j+k-degree]
+			alpha := float32(0)
+			if den > 0 {
+				alpha = (t - knots[j+k-degree]) / den
+			}
+			prev := d[j-1]
+			d[j] = *prev.Lerp(&d[j], alpha)
+		}
+	}
+	return result.Copy(&d[degree])
+}
+
+// GetPoint returns the point of the spline at the specified parameter t from 0 to 1.
+// Stores the point into optionalTarget, if not nil, and also returns it.
+func (b *BSpline) GetPoint(t float32, optionalTarget *Vector3) *Vector3 {
+
+	result := splineTarget(optionalTarget)
+	if len(b.points) < 2 {
+		if len(b.points) == 1 {
+			result.Copy(&b.points[0])
+		}
+		return result
+	}
+	return b.deBoor(b.points, b.degree, 0, Clamp(t, 0, 1), result)
+}
+
+// GetTangent returns the unit tangent of the spline at the specified parameter t from 0 to 1.
+// Stores the tangent into optionalTarget, if not nil, and also returns it.
+func (b *BSpline) GetTangent(t float32, optionalTarget *Vector3) *Vector3 {
+
+	result := splineTarget(optionalTarget)
+	if len(b.points) < 2 {
+		return result.Zero()
+	}
+	return b.deBoor(b.deriv, b.degree-1, 1, Clamp(t, 0, 1), result).Normalize()
+}
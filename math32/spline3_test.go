@@ -0,0 +1,154 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import "testing"
+
+// testSpline is a spline with the arc length methods used by the tests.
+type testSpline interface {
+	ISpline3
+	Length() float32
+	GetPointAt(u float32, optionalTarget *Vector3) *Vector3
+	GetSpacedPoints(divisions int) []Vector3
+	Sample(tolerance float32) []Vector3
+}
+
+// Control points of the test splines.
+var testSplinePoints = []Vector3{{0, 0, 0}, {1, 2, 0}, {3, 2, 1}, {4, 0, 1}}
+
+func TestSplinePoints(t *testing.T) {
+
+	p := testSplinePoints
+	line := []Vector3{{0, 0, 0}, {1, 0, 0}, {3, 0, 0}}
+	tests := []struct {
+		name   string
+		spline ISpline3
+		t      float32
+		want   Vector3
+	}{
+		{"bezier start", NewCubicBezier3(&p[0], &p[1], &p[2], &p[3]), 0, p[0]},
+		{"bezier middle", NewCubicBezier3(&p[0], &p[1], &p[2], &p[3]), 0.5, Vector3{2, 1.5, 0.5}},
+		{"bezier end", NewCubicBezier3(&p[0], &p[1], &p[2], &p[3]), 1, p[3]},
+		{"catmull-rom start", NewCatmullRom3(p, false), 0, p[0]},
+		{"catmull-rom second point", NewCatmullRom3(p, false), 1.0 / 3, p[1]},
+		{"catmull-rom third point", NewCatmullRom3(p, false), 2.0 / 3, p[2]},
+		{"catmull-rom end", NewCatmullRom3(p, false), 1, p[3]},
+		{"closed catmull-rom last point", NewCatmullRom3(p, true), 0.75, p[3]},
+		{"closed catmull-rom end", NewCatmullRom3(p, true), 1, p[0]},
+		{"single point catmull-rom", NewCatmullRom3(p[1:2], false), 0.5, p[1]},
+		{"cubic b-spline start", NewBSpline(p, 3), 0, p[0]},
+		{"cubic b-spline middle", NewBSpline(p, 3), 0.5, Vector3{2, 1.5, 0.5}},
+		{"cubic b-spline end", NewBSpline(p, 3), 1, p[3]},
+		{"linear b-spline", NewBSpline(line, 1), 0.25, Vector3{0.5, 0, 0}},
+		{"linear b-spline middle", NewBSpline(line, 1), 0.5, line[1]},
+		{"reduced degree b-spline", NewBSpline(line[:2], 3), 0.5, Vector3{0.5, 0, 0}},
+		{"clamped parameter", NewBSpline(p, 3), 2, p[3]},
+	}
+	for _, test := range tests {
+		got := test.spline.GetPoint(test.t, nil)
+		if !got.AlmostEquals(&test.want, 1e-5) {
+			t.Errorf("%s: got %v, want %v", test.name, *got, test.want)
+		}
+	}
+}
+
+func TestSplineTangents(t *testing.T) {
+
+	p := testSplinePoints
+	tests := []struct {
+		name   string
+		spline ISpline3
+	}{
+		{"bezier", NewCubicBezier3(&p[0], &p[1], &p[2], &p[3])},
+		{"catmull-rom", NewCatmullRom3(p, false)},
+		{"closed catmull-rom", NewCatmullRom3(p, true)},
+		{"cubic b-spline", NewBSpline(append(p, Vector3{5, 1, 2}), 3)},
+		{"quadratic b-spline", NewBSpline(p, 2)},
+	}
+	// The tangents must be the normalized finite differences of the points
+	const dt = 1e-3
+	for _, test := range tests {
+		for _, tv := range []float32{0.1, 0.3, 0.55, 0.9} {
+			var p0, p1, want Vector3
+			test.spline.GetPoint(tv-dt, &p0)
+			test.spline.GetPoint(tv+dt, &p1)
+			want.SubVectors(&p1, &p0).Normalize()
+			got := test.spline.GetTangent(tv, nil)
+			if !got.AlmostEquals(&want, 1e-2) {
+				t.Errorf("%s: tangent at %v: got %v, want %v", test.name, tv, *got, want)
+			}
+		}
+	}
+}
+
+func TestSplineArcLength(t *testing.T) {
+
+	// Straight splines with the points not uniformly distributed along t
+	a := Vector3{0, 0, 0}
+	b := Vector3{3, 0, 0}
+	tests := []struct {
+		name   string
+		spline testSpline
+		length float32
+	}{
+		{"bezier", NewCubicBezier3(&a, &a, &a, &b), 3},
+		{"catmull-rom", NewCatmullRom3([]Vector3{a, {0.5, 0, 0}, b}, false), 3},
+		{"b-spline", NewBSpline([]Vector3{a, a, {1, 0, 0}, b}, 3), 3},
+	}
+	for _, test := range tests {
+		length := test.spline.Length()
+		if Abs(length-test.length) > 1e-3 {
+			t.Errorf("%s: length %v, want %v", test.name, length, test.length)
+		}
+		points := test.spline.GetSpacedPoints(6)
+		if len(points) != 7 {
+			t.Errorf("%s: %d spaced points", test.name, len(points))
+			continue
+		}
+		for i := 1; i < len(points); i++ {
+			dist := points[i].DistanceTo(&points[i-1])
+			if Abs(dist-test.length/6) > 1e-2 {
+				t.Errorf("%s: distance between spaced points %d and %d: %v", test.name, i-1, i, dist)
+			}
+		}
+		var mid Vector3
+		test.spline.GetPointAt(0.5, &mid)
+		want := Vector3{test.length / 2, 0, 0}
+		if !mid.AlmostEquals(&want, 1e-2) {
+			t.Errorf("%s: point at half length %v, want %v", test.name, mid, want)
+		}
+	}
+}
+
+func TestSplineSample(t *testing.T) {
+
+	p := testSplinePoints
+	a := Vector3{0, 0, 0}
+	b := Vector3{3, 0, 0}
+	tests := []struct {
+		name      string
+		spline    testSpline
+		tolerance float32
+		min, max  int // Range of the number of points
+	}{
+		{"straight bezier", NewCubicBezier3(&a, &Vector3{1, 0, 0}, &Vector3{2, 0, 0}, &b), 1e-3, 9, 9},
+		{"bezier", NewCubicBezier3(&p[0], &p[1], &p[2], &p[3]), 0.1, 9, 9},
+		{"bezier with small tolerance", NewCubicBezier3(&p[0], &p[1], &p[2], &p[3]), 1e-3, 17, 200},
+		{"catmull-rom", NewCatmullRom3(p, true), 1e-3, 17, 400},
+	}
+	for _, test := range tests {
+		points := test.spline.Sample(test.tolerance)
+		if len(points) < test.min || len(points) > test.max {
+			t.Errorf("%s: %d points, want from %d to %d", test.name, len(points), test.min, test.max)
+			continue
+		}
+		var start, end Vector3
+		test.spline.GetPoint(0, &start)
+		test.spline.GetPoint(1, &end)
+		if !points[0].AlmostEquals(&start, 1e-6) || !points[len(points)-1].AlmostEquals(&end, 1e-6) {
+			t.Errorf("%s: sampled from %v to %v, want from %v to %v", test.name, points[0], points[len(points)-1], start, end)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math64
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// SetFloat32 sets this vector from the specified single precision vector.
+// Returns the pointer to this updated vector.
+func (v *Vector3) SetFloat32(src *math32.Vector3) *Vector3 {
+
+	v.X = float64(src.X)
+	v.Y = float64(src.Y)
+	v.Z = float64(src.Z)
+	return v
+}
+
+// Float32 returns this vector converted to single precision.
+func (v *Vector3) Float32() math32.Vector3 {
+
+	return math32.Vector3{X: float32(v.X), Y: float32(v.Y), Z: float32(v.Z)}
+}
+
+// SetFloat32 sets this matrix from the specified single precision matrix.
+// Returns the pointer to this updated matrix.
+func (m *Matrix4) SetFloat32(src *math32.Matrix4) *Matrix4 {
+
+	for i := range src {
+		m[i] = float64(src[i])
+	}
+	return m
+}
+
+// Float32 returns this matrix converted to single precision.
+func (m *Matrix4) Float32() math32.Matrix4 {
+
+	var res math32.Matrix4
+	for i := range m {
+		res[i] = float32(m[i])
+	}
+	return res
+}
+
+// SetFloat32 sets this quaternion from the specified single precision quaternion.
+// Returns the pointer to this updated quaternion.
+func (q *Quaternion) SetFloat32(src *math32.Quaternion) *Quaternion {
+
+	q.X = float64(src.X)
+	q.Y = float64(src.Y)
+	q.Z = float64(src.Z)
+	q.W = float64(src.W)
+	return q
+}
+
+// Float32 returns this quaternion converted to single precision.
+func (q *Quaternion) Float32() math32.Quaternion {
+
+	return math32.Quaternion{X: float32(q.X), Y: float32(q.Y), Z: float32(q.Z), W: float32(q.W)}
+}
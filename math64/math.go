@@ -0,0 +1,40 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package math64 contains double precision versions of the math32 vectors,
+// matrices and quaternions, for world coordinates too large for float32
+// precision, such as in geospatial and space simulations, and helpers to
+// convert them to math32 and render them relative to the camera.
+package math64
+
+import (
+	"math"
+)
+
+const degreeToRadiansFactor = math.Pi / 180
+const radianToDegreesFactor = 180.0 / math.Pi
+
+// DegToRad converts a number from degrees to radians
+func DegToRad(degrees float64) float64 {
+
+	return degrees * degreeToRadiansFactor
+}
+
+// RadToDeg converts a number from radians to degrees
+func RadToDeg(radians float64) float64 {
+
+	return radians * radianToDegreesFactor
+}
+
+// Clamp clamps x to the provided closed interval [a, b]
+func Clamp(x, a, b float64) float64 {
+
+	if x < a {
+		return a
+	}
+	if x > b {
+		return b
+	}
+	return x
+}
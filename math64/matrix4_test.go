@@ -0,0 +1,137 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math64
+
+import (
+	"math"
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+func TestMatrix4Decompose(t *testing.T) {
+
+	var q Quaternion
+	q.SetFromAxisAngle(NewVector3(1, 2, 3).Normalize(), 0.7)
+	tests := []struct {
+		name     string
+		position Vector3
+		rotation Quaternion
+		scale    Vector3
+	}{
+		{"identity", Vector3{0, 0, 0}, Quaternion{0, 0, 0, 1}, Vector3{1, 1, 1}},
+		{"planet scale", Vector3{1.5e11 + 0.125, -2e10, 3e9 + 0.5}, q, Vector3{1, 2, 3}},
+		{"mirrored", Vector3{1, 2, 3}, q, Vector3{-2, 1, 1}},
+	}
+	for _, test := range tests {
+		var m Matrix4
+		m.Compose(&test.position, &test.rotation, &test.scale)
+		var pos, scale Vector3
+		var rot Quaternion
+		m.Decompose(&pos, &rot, &scale)
+		if pos != test.position {
+			t.Errorf("%s: position %v, want %v", test.name, pos, test.position)
+		}
+		if !scale.AlmostEquals(&test.scale, 1e-12) {
+			t.Errorf("%s: scale %v, want %v", test.name, scale, test.scale)
+		}
+		if math.Abs(math.Abs(rot.Dot(&test.rotation))-1) > 1e-12 {
+			t.Errorf("%s: rotation %v, want %v", test.name, rot, test.rotation)
+		}
+
+		// The product with the inverse must be the identity
+		// with a translation error relative to the position
+		var inv, prod Matrix4
+		err := inv.GetInverse(&m)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		prod.MultiplyMatrices(&m, &inv)
+		id := NewMatrix4()
+		for i := range prod {
+			tol := 1e-12
+			if i >= 12 {
+				tol *= 1 + test.position.Length()
+			}
+			if math.Abs(prod[i]-id[i]) > tol {
+				t.Errorf("%s: product with the inverse %v", test.name, prod)
+				break
+			}
+		}
+	}
+
+	var m Matrix4
+	if m.GetInverse(new(Matrix4)) == nil {
+		t.Error("no error inverting the zero matrix")
+	}
+}
+
+func TestMatrix4Precision(t *testing.T) {
+
+	// Offsets which are lost when added to the translation in single precision
+	tests := []struct {
+		name        string
+		translation Vector3
+		point       Vector3
+	}{
+		{"kilometers", Vector3{1e7, 0, 0}, Vector3{0.25, 0, 0}},
+		{"planet", Vector3{6.4e6, 6.4e6, -6.4e6}, Vector3{0.001, 0.002, 0.003}},
+		{"solar system", Vector3{1.5e11, 0, 0}, Vector3{0.5, 1, 0}},
+	}
+	for _, test := range tests {
+		var m Matrix4
+		m.MakeTranslation(test.translation.X, test.translation.Y, test.translation.Z)
+		p := test.point
+		p.ApplyMatrix4(&m).Sub(&test.translation)
+		if !p.AlmostEquals(&test.point, 1e-6) {
+			t.Errorf("%s: got %v, want %v", test.name, p, test.point)
+		}
+
+		// Checks the test values really lose precision in single precision
+		m32 := m.Float32()
+		p32 := test.point.Float32()
+		t32 := test.translation.Float32()
+		p32.ApplyMatrix4(&m32).Sub(&t32)
+		if p32.AlmostEquals(&math32.Vector3{X: float32(test.point.X), Y: float32(test.point.Y), Z: float32(test.point.Z)}, 1e-6) {
+			t.Errorf("%s: single precision result %v is exact", test.name, p32)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+
+	var q32 math32.Quaternion
+	q32.SetFromAxisAngle(math32.NewVector3(0, 1, 0), 1.2)
+	v32 := math32.Vector3{X: 1.5, Y: -2, Z: 3.25}
+	var m32 math32.Matrix4
+	m32.Compose(&v32, &q32, math32.NewVector3(1, 2, 3))
+
+	var v Vector3
+	var q Quaternion
+	var m Matrix4
+	v.SetFloat32(&v32)
+	q.SetFloat32(&q32)
+	m.SetFloat32(&m32)
+	if got := v.Float32(); got != v32 {
+		t.Errorf("vector %v, want %v", got, v32)
+	}
+	if got := q.Float32(); got != q32 {
+		t.Errorf("quaternion %v, want %v", got, q32)
+	}
+	if got := m.Float32(); got != m32 {
+		t.Errorf("matrix %v, want %v", got, m32)
+	}
+
+	// Composing in double precision must give the same matrix
+	var composed Matrix4
+	composed.Compose(&v, &q, NewVector3(1, 2, 3))
+	for i := range composed {
+		if math.Abs(composed[i]-m[i]) > 1e-6 {
+			t.Errorf("composed matrix %v, want %v", composed, m)
+			break
+		}
+	}
+}
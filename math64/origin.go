@@ -0,0 +1,89 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math64
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+// FloatingOrigin keeps the double precision world positions of nodes and sets their
+// single precision positions relative to an origin which follows the camera, so that
+// the objects near the camera are rendered without float32 precision loss.
+// The nodes should be children of the scene, so their positions are not transformed by a parent.
+type FloatingOrigin struct {
+	origin    Vector3                // Current origin in world coordinates
+	positions map[core.INode]Vector3 // World positions of the nodes
+}
+
+// NewFloatingOrigin creates and returns a pointer to a new floating origin at the world origin.
+func NewFloatingOrigin() *FloatingOrigin {
+
+	fo := new(FloatingOrigin)
+	fo.positions = make(map[core.INode]Vector3)
+	return fo
+}
+
+// SetPosition sets the world position of the specified node, adding it
+// to the floating origin if necessary, and updates its node position.
+func (fo *FloatingOrigin) SetPosition(node core.INode, pos *Vector3) {
+
+	fo.positions[node] = *pos
+	fo.rebase(node, pos)
+}
+
+// Position returns the world position of the specified node and whether it was found.
+func (fo *FloatingOrigin) Position(node core.INode) (Vector3, bool) {
+
+	pos, ok := fo.positions[node]
+	return pos, ok
+}
+
+// Remove removes the specified node from the floating origin.
+// Its node position is left unchanged.
+func (fo *FloatingOrigin) Remove(node core.INode) {
+
+	delete(fo.positions, node)
+}
+
+// Origin returns the current origin in world coordinates.
+func (fo *FloatingOrigin) Origin() Vector3 {
+
+	return fo.origin
+}
+
+// Update moves the origin to the specified world position, usually the world position of
+// the camera, and updates the node positions of all the nodes. It should be called each frame
+// before rendering. If the camera was added with SetPosition, its node position becomes zero.
+func (fo *FloatingOrigin) Update(origin *Vector3) {
+
+	fo.origin = *origin
+	for node, pos := range fo.positions {
+		fo.rebase(node, &pos)
+	}
+}
+
+// ToLocal returns the position relative to the origin of the specified world position.
+func (fo *FloatingOrigin) ToLocal(pos *Vector3) math32.Vector3 {
+
+	var local Vector3
+	local.SubVectors(pos, &fo.origin)
+	return local.Float32()
+}
+
+// ToWorld returns the world position of the specified position relative to the origin.
+func (fo *FloatingOrigin) ToWorld(local *math32.Vector3) Vector3 {
+
+	var pos Vector3
+	pos.SetFloat32(local).Add(&fo.origin)
+	return pos
+}
+
+// rebase sets the node position of the specified node from its world position.
+func (fo *FloatingOrigin) rebase(node core.INode, pos *Vector3) {
+
+	local := fo.ToLocal(pos)
+	node.GetNode().SetPositionVec(&local)
+}
@@ -0,0 +1,70 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math64
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+func TestFloatingOrigin(t *testing.T) {
+
+	fo := NewFloatingOrigin()
+	camera := core.NewNode()
+	ship := core.NewNode()
+	station := core.NewNode()
+	fo.SetPosition(camera, &Vector3{1.5e11, 0, 0})
+	fo.SetPosition(ship, &Vector3{1.5e11 + 10.25, 0.5, -3})
+	fo.SetPosition(station, &Vector3{-1e9, 2e9, 0})
+
+	// Before the update the nodes are relative to the world origin
+	if pos := camera.Position(); pos.X != 1.5e11 {
+		t.Errorf("camera position before update %v", pos)
+	}
+
+	fo.Update(&Vector3{1.5e11, 0, 0})
+	tests := []struct {
+		name string
+		node *core.Node
+		want math32.Vector3
+	}{
+		{"camera", camera, math32.Vector3{X: 0, Y: 0, Z: 0}},
+		{"ship", ship, math32.Vector3{X: 10.25, Y: 0.5, Z: -3}},
+		{"station", station, math32.Vector3{X: -1.51e11, Y: 2e9, Z: 0}},
+	}
+	for _, test := range tests {
+		if pos := test.node.Position(); pos != test.want {
+			t.Errorf("%s: position %v, want %v", test.name, pos, test.want)
+		}
+	}
+
+	// World positions are kept in double precision
+	pos, ok := fo.Position(ship)
+	if !ok || pos != (Vector3{1.5e11 + 10.25, 0.5, -3}) {
+		t.Errorf("ship world position %v %v", pos, ok)
+	}
+	local := ship.Position()
+	if world := fo.ToWorld(&local); world != pos {
+		t.Errorf("ship position to world %v, want %v", world, pos)
+	}
+
+	// Removed nodes keep their node position and are not updated
+	fo.Remove(station)
+	if _, ok := fo.Position(station); ok {
+		t.Error("removed station found")
+	}
+	fo.Update(&Vector3{1.5e11 + 10, 0, 0})
+	if pos := ship.Position(); pos != (math32.Vector3{X: 0.25, Y: 0.5, Z: -3}) {
+		t.Errorf("ship position after moving the origin %v", pos)
+	}
+	if pos := station.Position(); pos != tests[2].want {
+		t.Errorf("removed station position %v, want %v", pos, tests[2].want)
+	}
+	if origin := fo.Origin(); origin != (Vector3{1.5e11 + 10, 0, 0}) {
+		t.Errorf("origin %v", origin)
+	}
+}
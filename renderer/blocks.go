@@ -0,0 +1,89 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Binding points of the uniform blocks updated by the renderer once per frame.
+// The binding points from BlockBindingUser on are free for the application.
+const (
+	CameraBlockBinding        = iota // Binding point of the Camera block
+	AmbientLightsBlockBinding        // Binding point of the AmbientLights block
+	DirLightsBlockBinding            // Binding point of the DirLights block
+	PointLightsBlockBinding          // Binding point of the PointLights block
+	SpotLightsBlockBinding           // Binding point of the SpotLights block
+	BlockBindingUser                 // First binding point free for the application
+)
+
+// initBlocks creates the uniform buffer objects of the uniform blocks updated once per frame
+// and adds the blocks to the shader manager, so they are bound in the programs which use them.
+func (r *Renderer) initBlocks() {
+
+	r.uboCamera = gls.NewUBO("Camera", CameraBlockBinding)
+	r.uboAmbLights = gls.NewUBO("AmbientLights", AmbientLightsBlockBinding)
+	r.uboDirLights = gls.NewUBO("DirLights", DirLightsBlockBinding)
+	r.uboPointLights = gls.NewUBO("PointLights", PointLightsBlockBinding)
+	r.uboSpotLights = gls.NewUBO("SpotLights", SpotLightsBlockBinding)
+	for _, ubo := range []*gls.UBO{r.uboCamera, r.uboAmbLights, r.uboDirLights, r.uboPointLights, r.uboSpotLights} {
+		r.Shaman.AddUniformBlock(ubo.Name(), ubo.Binding())
+	}
+}
+
+// updateBlocks transfers the camera matrices and the data of the lights of the frame to
+// their uniform blocks, instead of transferring the lights uniforms for each material.
+func (r *Renderer) updateBlocks() {
+
+	// Camera matrices
+	var camMatrix math32.Matrix4
+	camMatrix.GetInverse(&r.rinfo.ViewMatrix)
+	buf := r.uboCamera.Buffer()
+	*buf = (*buf)[:0]
+	buf.Append(r.rinfo.ViewMatrix[:]...)
+	buf.Append(r.rinfo.ProjMatrix[:]...)
+	buf.Append(camMatrix[:]...)
+	r.uboCamera.Update()
+	r.uboCamera.Bind(r.gs)
+
+	// Lights. The blocks of the light types not in the scene are not used by the shaders
+	if len(r.ambLights) > 0 {
+		buf = r.uboAmbLights.Buffer()
+		*buf = (*buf)[:0]
+		for _, l := range r.ambLights {
+			l.AppendBlockData(&r.rinfo, buf)
+		}
+		r.uboAmbLights.Update()
+		r.uboAmbLights.Bind(r.gs)
+	}
+	if len(r.dirLights) > 0 {
+		buf = r.uboDirLights.Buffer()
+		*buf = (*buf)[:0]
+		for _, l := range r.dirLights {
+			l.AppendBlockData(&r.rinfo, buf)
+		}
+		r.uboDirLights.Update()
+		r.uboDirLights.Bind(r.gs)
+	}
+	if len(r.pointLights) > 0 {
+		buf = r.uboPointLights.Buffer()
+		*buf = (*buf)[:0]
+		for _, l := range r.pointLights {
+			l.AppendBlockData(&r.rinfo, buf)
+		}
+		r.uboPointLights.Update()
+		r.uboPointLights.Bind(r.gs)
+	}
+	if len(r.spotLights) > 0 {
+		buf = r.uboSpotLights.Buffer()
+		*buf = (*buf)[:0]
+		for _, l := range r.spotLights {
+			l.AppendBlockData(&r.rinfo, buf)
+		}
+		r.uboSpotLights.Update()
+		r.uboSpotLights.Bind(r.gs)
+	}
+}
@@ -0,0 +1,709 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/gls"
+)
+
+// FrameGraph schedules render passes which declare the render targets they read and write.
+// When executed, the passes which do not contribute to the final image are culled, the other
+// passes are ordered so each pass runs after the passes which write its inputs, and the textures
+// of the render targets are allocated from a pool, where a texture is reused by another target
+// (aliased) as soon as the passes which read its current target have run.
+// The passes which write no targets render to the frame buffer bound when the graph is executed
+// and are never culled, as are the passes which write the exported targets.
+// The Renderer declares the passes of each frame, such as the shadow, SSAO, OIT, HDR and probe
+// passes, in its own frame graph. The application may execute its own graphs before or after Render.
+type FrameGraph struct {
+	gs      *gls.GLS     // OpenGL state
+	passes  []*Pass      // Passes in declaration order
+	targets []*Target    // Render targets in declaration order
+	order   []*Pass      // Passes in execution order (nil if the graph must be compiled)
+	pool    []*fgTexture // Textures allocated to the render targets
+	fbos    []fgFbo      // Frame buffer objects of removed passes to be reused
+	gen     uint32       // Generation of the OpenGL context of the textures and frame buffers
+}
+
+// TargetDesc describes the texture of a render target.
+type TargetDesc struct {
+	Width  int32   // Width in pixels or zero for the width of the viewport multiplied by Scale
+	Height int32   // Height in pixels or zero for the height of the viewport multiplied by Scale
+	Scale  float32 // Fraction of the viewport size used when Width or Height are zero (zero is one)
+	Format uint32  // Internal format of the texture, such as gls.RGBA8, gls.RGBA16F or gls.DEPTH24_STENCIL8
+	Clear  bool    // Whether the target is cleared to zero (colors) or one (depth) before it is written
+}
+
+// Target is a render target of a frame graph: a texture written by one pass and read by others.
+type Target struct {
+	name     string     // Name used in error messages
+	desc     TargetDesc // Description of the texture
+	writer   *Pass      // Pass which writes the target
+	prev     *Target    // Version of the target modified by its writer (nil if it is a new target)
+	modifier *Pass      // Pass which modifies the target
+	exported bool       // Whether the texture is kept after the graph is executed
+	imported bool       // Whether the texture is not owned by the graph
+	ext      uint32     // Texture of an imported target
+	last     int        // Index in the execution order of the last pass which uses the target
+	tex      *fgTexture // Texture allocated to the target while it is used
+}
+
+// Pass is a render pass of a frame graph.
+type Pass struct {
+	fg       *FrameGraph                 // Frame graph of the pass
+	name     string                      // Name used in error messages
+	inputs   []*Target                   // Render targets read by the pass
+	outputs  []*Target                   // Render targets written by the pass
+	exec     func(pc *PassContext) error // Function which renders the pass
+	fbo      uint32                      // Frame buffer object with the outputs attached
+	attached []uint32                    // Textures attached to the frame buffer object
+	points   []uint                      // Attachment points used in the frame buffer object
+}
+
+// PassContext is passed to the function of a pass when the frame graph is executed.
+type PassContext struct {
+	Width    int32    // Width of the viewport of the pass in pixels
+	Height   int32    // Height of the viewport of the pass in pixels
+	gs       *gls.GLS // OpenGL state
+	fb       uint32   // Frame buffer bound when the graph was executed
+	viewport [4]int32 // Viewport when the graph was executed
+}
+
+// fgTexture is a texture of the pool of a frame graph.
+type fgTexture struct {
+	tex    uint32 // Texture name
+	width  int32  // Width in pixels
+	height int32  // Height in pixels
+	format uint32 // Internal format
+	busy   bool   // Whether the texture is allocated to a render target
+	used   bool   // Whether the texture was used in the last execution
+}
+
+// fgFbo is a frame buffer object of a removed pass with the attachment points it used.
+type fgFbo struct {
+	fbo    uint32 // Frame buffer object name
+	points []uint // Attachment points with textures attached
+}
+
+// fgFormats maps the supported internal formats of the render targets
+// to the format and the type of the data of their textures.
+var fgFormats = map[uint32][2]uint32{
+	gls.RGBA8:              {gls.RGBA, gls.UNSIGNED_BYTE},
+	gls.RGBA16F:            {gls.RGBA, gls.FLOAT},
+	gls.RGBA32F:            {gls.RGBA, gls.FLOAT},
+	gls.R8:                 {gls.RED, gls.UNSIGNED_BYTE},
+	gls.R16F:               {gls.RED, gls.FLOAT},
+	gls.R32F:               {gls.RED, gls.FLOAT},
+	gls.RG16F:              {gls.RG, gls.FLOAT},
+	gls.DEPTH_COMPONENT24:  {gls.DEPTH_COMPONENT, gls.UNSIGNED_INT},
+	gls.DEPTH_COMPONENT32F: {gls.DEPTH_COMPONENT, gls.FLOAT},
+	gls.DEPTH24_STENCIL8:   {gls.DEPTH_STENCIL, gls.UNSIGNED_INT_24_8},
+}
+
+// Clear value of the color targets
+var fgClearColor = []float32{0, 0, 0, 0}
+
+// NewFrameGraph creates and returns a pointer to a new empty frame graph using the specified OpenGL state.
+func NewFrameGraph(gs *gls.GLS) *FrameGraph {
+
+	fg := new(FrameGraph)
+	fg.gs = gs
+	fg.gen = gs.Generation()
+	return fg
+}
+
+// AddTarget adds a render target with the specified name and description and returns a pointer to it.
+func (fg *FrameGraph) AddTarget(name string, desc TargetDesc) *Target {
+
+	t := &Target{name: name, desc: desc}
+	fg.targets = append(fg.targets, t)
+	fg.order = nil
+	return t
+}
+
+// Import adds a render target whose texture is not owned by the graph, such as a persistent
+// texture or, if tex is zero, the frame buffer bound when the graph is executed, and returns a pointer to it.
+// An imported target is never allocated nor aliased and may be read without being written.
+// The passes which write imported targets render to the frame buffer and viewport current when
+// the graph is executed and are culled if the imported targets they write are not read.
+func (fg *FrameGraph) Import(name string, tex uint32) *Target {
+
+	t := &Target{name: name, imported: true, ext: tex}
+	fg.targets = append(fg.targets, t)
+	fg.order = nil
+	return t
+}
+
+// AddPass adds a pass with the specified name and rendering function and returns a pointer to it,
+// so its inputs and outputs can be declared with Read and Write.
+func (fg *FrameGraph) AddPass(name string, exec func(pc *PassContext) error) *Pass {
+
+	p := &Pass{fg: fg, name: name, exec: exec}
+	fg.passes = append(fg.passes, p)
+	fg.order = nil
+	return p
+}
+
+// Export sets the specified render target to be kept after the graph is executed,
+// so its texture can be used later in the frame. The pass which writes it is never culled.
+func (fg *FrameGraph) Export(t *Target) {
+
+	t.exported = true
+	fg.order = nil
+}
+
+// Reset removes all the passes and render targets keeping the pool of textures and
+// the frame buffers, so the graph of a frame can be declared again.
+func (fg *FrameGraph) Reset() {
+
+	for _, p := range fg.passes {
+		if p.fbo != 0 {
+			fg.fbos = append(fg.fbos, fgFbo{p.fbo, p.points})
+		}
+	}
+	fg.passes = nil
+	fg.targets = nil
+	fg.order = nil
+}
+
+// Order returns the names of the passes which are not culled in execution order
+// or an error if the graph is not valid.
+func (fg *FrameGraph) Order() ([]string, error) {
+
+	if fg.order == nil {
+		err := fg.compile()
+		if err != nil {
+			return nil, err
+		}
+	}
+	names := make([]string, len(fg.order))
+	for i, p := range fg.order {
+		names[i] = p.name
+	}
+	return names, nil
+}
+
+// Execute runs the passes of the graph which are not culled in dependency order.
+// Each pass renders to a frame buffer with its outputs attached and a viewport with their size,
+// except the passes without outputs or with imported outputs, which render to the frame buffer
+// and viewport current when Execute is called. These are restored when Execute returns.
+func (fg *FrameGraph) Execute() error {
+
+	gs := fg.gs
+
+	// The textures and frame buffers were lost if the OpenGL context was reset
+	if fg.gen != gs.Generation() {
+		fg.gen = gs.Generation()
+		fg.pool = nil
+		fg.fbos = nil
+		for _, p := range fg.passes {
+			p.fbo = 0
+			p.attached = nil
+			p.points = nil
+		}
+	}
+	if fg.order == nil {
+		err := fg.compile()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Save the current frame buffer and viewport
+	fb := gs.Framebuffer()
+	vx, vy, vw, vh := gs.GetViewport()
+	for _, ft := range fg.pool {
+		ft.busy = false
+		ft.used = false
+	}
+	for _, t := range fg.targets {
+		t.tex = nil
+	}
+
+	var err error
+	for i, p := range fg.order {
+		pc := PassContext{Width: vw, Height: vh, gs: gs, fb: fb, viewport: [4]int32{vx, vy, vw, vh}}
+		if len(p.outputs) == 0 || p.outputs[0].imported {
+			gs.BindFramebuffer(fb)
+			gs.Viewport(vx, vy, vw, vh)
+		} else {
+			for _, t := range p.outputs {
+				// A modified target keeps the texture of its previous version
+				if t.prev != nil && t.prev.tex != nil {
+					t.tex = t.prev.tex
+					t.prev.tex = nil
+					continue
+				}
+				w, h := t.size(vw, vh)
+				t.tex = fg.acquire(w, h, t.desc.Format)
+			}
+			pc.Width, pc.Height = p.outputs[0].tex.width, p.outputs[0].tex.height
+			p.bind()
+			gs.Viewport(0, 0, pc.Width, pc.Height)
+			p.clear()
+		}
+		err = p.exec(&pc)
+		if err != nil {
+			break
+		}
+
+		// The textures of the targets not used by the next passes can be aliased
+		for _, t := range p.inputs {
+			t.release(i)
+		}
+		for _, t := range p.outputs {
+			t.release(i)
+		}
+	}
+
+	// Restore the frame buffer and viewport
+	gs.BindFramebuffer(fb)
+	gs.Viewport(vx, vy, vw, vh)
+	if err != nil {
+		return err
+	}
+
+	// Delete the textures not used in this execution
+	pool := fg.pool[:0]
+	for _, ft := range fg.pool {
+		if ft.used {
+			pool = append(pool, ft)
+		} else {
+			gs.DeleteTextures(ft.tex)
+		}
+	}
+	for i := len(pool); i < len(fg.pool); i++ {
+		fg.pool[i] = nil
+	}
+	fg.pool = pool
+	return nil
+}
+
+// Dispose deletes the textures of the pool and the frame buffers. The graph can still be executed.
+func (fg *FrameGraph) Dispose() {
+
+	if fg.gen == fg.gs.Generation() {
+		for _, ft := range fg.pool {
+			fg.gs.DeleteTextures(ft.tex)
+		}
+		for _, f := range fg.fbos {
+			fg.gs.DeleteFramebuffers(f.fbo)
+		}
+		for _, p := range fg.passes {
+			if p.fbo != 0 {
+				fg.gs.DeleteFramebuffers(p.fbo)
+			}
+		}
+	}
+	fg.pool = nil
+	fg.fbos = nil
+	for _, t := range fg.targets {
+		t.tex = nil
+	}
+	for _, p := range fg.passes {
+		p.fbo = 0
+		p.attached = nil
+		p.points = nil
+	}
+}
+
+// compile culls the passes which do not contribute to the final image or to the
+// exported targets, orders the other passes and calculates the lifetime of the targets.
+func (fg *FrameGraph) compile() error {
+
+	for _, t := range fg.targets {
+		if _, ok := fgFormats[t.desc.Format]; !ok && !t.imported {
+			return fmt.Errorf("render target %s has an unsupported format: 0x%X", t.name, t.desc.Format)
+		}
+		t.writer = nil
+		t.modifier = nil
+	}
+	for _, p := range fg.passes {
+		for _, t := range p.outputs {
+			if t.writer != nil {
+				return fmt.Errorf("render target %s is written by passes %s and %s", t.name, t.writer.name, p.name)
+			}
+			if t.imported != p.outputs[0].imported {
+				return fmt.Errorf("pass %s writes imported and allocated render targets", p.name)
+			}
+			t.writer = p
+			if t.prev == nil {
+				continue
+			}
+			if t.prev.modifier != nil {
+				return fmt.Errorf("render target %s is modified by passes %s and %s", t.name, t.prev.modifier.name, p.name)
+			}
+			if t.prev.exported {
+				return fmt.Errorf("exported render target %s is modified by pass %s", t.name, p.name)
+			}
+			t.prev.modifier = p
+		}
+	}
+
+	// Mark the passes needed by the passes without outputs and by the exported targets
+	needed := make(map[*Pass]bool)
+	var visit func(p *Pass) error
+	visit = func(p *Pass) error {
+		if needed[p] {
+			return nil
+		}
+		needed[p] = true
+		for _, t := range p.inputs {
+			if t.writer == nil && t.imported {
+				continue
+			}
+			if t.writer == nil {
+				return fmt.Errorf("render target %s read by pass %s is not written by any pass", t.name, p.name)
+			}
+			err := visit(t.writer)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, p := range fg.passes {
+		if len(p.outputs) == 0 {
+			err := visit(p)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, t := range fg.targets {
+		if t.exported && t.writer != nil {
+			err := visit(t.writer)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Order the needed passes keeping the declaration order of independent passes.
+	// A pass which modifies a target runs after the other passes which read its previous version.
+	order := make([]*Pass, 0, len(needed))
+	done := make(map[*Pass]bool)
+	ready := func(p *Pass) bool {
+		for _, t := range p.inputs {
+			if t.writer != nil && !done[t.writer] {
+				return false
+			}
+		}
+		for _, t := range p.outputs {
+			if t.prev == nil {
+				continue
+			}
+			for _, q := range fg.passes {
+				if q != p && needed[q] && !done[q] && q.reads(t.prev) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	for len(order) < len(needed) {
+		var next *Pass
+		for _, p := range fg.passes {
+			if !needed[p] || done[p] {
+				continue
+			}
+			if ready(p) {
+				next = p
+				break
+			}
+		}
+		if next == nil {
+			return fmt.Errorf("frame graph has a cycle")
+		}
+		done[next] = true
+		order = append(order, next)
+	}
+
+	// The lifetime of a target ends with the last pass which uses it
+	for _, t := range fg.targets {
+		t.last = -1
+	}
+	for i, p := range order {
+		for _, t := range p.outputs {
+			t.last = i
+		}
+		for _, t := range p.inputs {
+			t.last = i
+		}
+	}
+	for _, t := range fg.targets {
+		if t.exported {
+			t.last = len(order)
+		}
+	}
+	fg.order = order
+	return nil
+}
+
+// acquire returns a free texture of the pool with the specified size and format,
+// creating it if there is none, and marks it as allocated.
+func (fg *FrameGraph) acquire(width, height int32, format uint32) *fgTexture {
+
+	for _, ft := range fg.pool {
+		if !ft.busy && ft.width == width && ft.height == height && ft.format == format {
+			ft.busy = true
+			ft.used = true
+			return ft
+		}
+	}
+
+	gs := fg.gs
+	ft := &fgTexture{width: width, height: height, format: format, busy: true, used: true}
+	ft.tex = gs.GenTexture()
+	filter := int32(gls.LINEAR)
+	if fgDepth(format) {
+		filter = gls.NEAREST
+	}
+	gs.BindTexture(gls.TEXTURE_2D, ft.tex)
+	gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_WRAP_S, gls.CLAMP_TO_EDGE)
+	gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_WRAP_T, gls.CLAMP_TO_EDGE)
+	gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_MIN_FILTER, filter)
+	gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_MAG_FILTER, filter)
+	f := fgFormats[format]
+	gs.TexImage2D(gls.TEXTURE_2D, 0, int32(format), width, height, f[0], f[1], nil)
+	gs.BindTexture(gls.TEXTURE_2D, 0)
+	fg.pool = append(fg.pool, ft)
+	return ft
+}
+
+// fgDepth returns whether the specified internal format is a depth format.
+func fgDepth(format uint32) bool {
+
+	return format == gls.DEPTH_COMPONENT24 || format == gls.DEPTH_COMPONENT32F || format == gls.DEPTH24_STENCIL8
+}
+
+// Name returns the name of the render target.
+func (t *Target) Name() string {
+
+	return t.name
+}
+
+// Desc returns the description of the texture of the render target.
+func (t *Target) Desc() TargetDesc {
+
+	return t.desc
+}
+
+// Texture returns the name of the texture of the render target while the passes which use it
+// are executed or, for an exported target, after the graph is executed. Otherwise it returns zero.
+// The texture of an imported target is the texture specified to Import.
+func (t *Target) Texture() uint32 {
+
+	if t.imported {
+		return t.ext
+	}
+	if t.tex == nil {
+		return 0
+	}
+	return t.tex.tex
+}
+
+// release frees the texture of the render target if the pass with the specified
+// index in the execution order is the last pass which uses it.
+func (t *Target) release(i int) {
+
+	if t.last == i && t.tex != nil {
+		t.tex.busy = false
+		t.tex = nil
+	}
+}
+
+// size returns the size in pixels of the render target for the specified viewport size.
+func (t *Target) size(vw, vh int32) (int32, int32) {
+
+	scale := t.desc.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	w, h := t.desc.Width, t.desc.Height
+	if w == 0 {
+		w = int32(float32(vw) * scale)
+	}
+	if h == 0 {
+		h = int32(float32(vh) * scale)
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	return w, h
+}
+
+// Name returns the name of the pass.
+func (p *Pass) Name() string {
+
+	return p.name
+}
+
+// Read declares the specified render targets as inputs of the pass and returns the pass.
+func (p *Pass) Read(targets ...*Target) *Pass {
+
+	p.inputs = append(p.inputs, targets...)
+	p.fg.order = nil
+	return p
+}
+
+// Write declares the specified render targets as outputs of the pass and returns the pass.
+// All the outputs of a pass must have the same size and at most one can have a depth format.
+func (p *Pass) Write(targets ...*Target) *Pass {
+
+	p.outputs = append(p.outputs, targets...)
+	p.fg.order = nil
+	return p
+}
+
+// Modify declares the specified render target as an input and an output of the pass,
+// which renders over its contents, and returns the new version of the target, which keeps its
+// texture, to be read by the next passes. The pass runs after the passes which read the
+// specified version. A version can be modified by a single pass.
+func (p *Pass) Modify(t *Target) *Target {
+
+	desc := t.desc
+	desc.Clear = false
+	v := &Target{name: t.name, desc: desc, prev: t, imported: t.imported, ext: t.ext}
+	p.fg.targets = append(p.fg.targets, v)
+	p.inputs = append(p.inputs, t)
+	p.outputs = append(p.outputs, v)
+	p.fg.order = nil
+	return v
+}
+
+// reads returns whether the pass reads the specified render target.
+func (p *Pass) reads(t *Target) bool {
+
+	for _, in := range p.inputs {
+		if in == t {
+			return true
+		}
+	}
+	return false
+}
+
+// bind binds the frame buffer of the pass attaching the textures of its outputs.
+// A frame buffer reused from a removed pass has the textures of the attachment points
+// not used by the pass detached and its draw buffers set again.
+func (p *Pass) bind() {
+
+	gs := p.fg.gs
+	if p.fbo == 0 {
+		if n := len(p.fg.fbos); n > 0 {
+			p.fbo = p.fg.fbos[n-1].fbo
+			p.points = p.fg.fbos[n-1].points
+			p.fg.fbos = p.fg.fbos[:n-1]
+		} else {
+			p.fbo = gs.GenFramebuffer()
+		}
+		p.attached = nil
+	}
+	gs.BindFramebuffer(p.fbo)
+	if len(p.attached) != len(p.outputs) {
+		p.attached = make([]uint32, len(p.outputs))
+	}
+
+	// The attachment points not used by the pass are detached before the outputs are attached,
+	// as detaching the depth and stencil point also detaches the depth point
+	points := make([]uint, len(p.outputs))
+	color := uint(0)
+	var drawBuffers []uint32
+	for i, t := range p.outputs {
+		switch t.desc.Format {
+		case gls.DEPTH24_STENCIL8:
+			points[i] = gls.DEPTH_STENCIL_ATTACHMENT
+		case gls.DEPTH_COMPONENT24, gls.DEPTH_COMPONENT32F:
+			points[i] = gls.DEPTH_ATTACHMENT
+		default:
+			points[i] = uint(gls.COLOR_ATTACHMENT0) + color
+			drawBuffers = append(drawBuffers, uint32(points[i]))
+			color++
+		}
+	}
+	for _, old := range p.points {
+		used := false
+		for _, att := range points {
+			if att == old {
+				used = true
+				break
+			}
+		}
+		if !used {
+			gs.FramebufferTexture2D(old, gls.TEXTURE_2D, 0)
+		}
+	}
+	p.points = points
+
+	changed := false
+	for i, t := range p.outputs {
+		if p.attached[i] != t.tex.tex {
+			gs.FramebufferTexture2D(points[i], gls.TEXTURE_2D, t.tex.tex)
+			p.attached[i] = t.tex.tex
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	if len(drawBuffers) == 0 {
+		gs.DrawBuffers([]uint32{gls.NONE})
+		gs.ReadBuffer(gls.NONE)
+	} else {
+		gs.DrawBuffers(drawBuffers)
+		gs.ReadBuffer(gls.COLOR_ATTACHMENT0)
+	}
+	if gs.CheckFramebufferStatus() != gls.FRAMEBUFFER_COMPLETE {
+		log.Error("Frame buffer of pass %s is incomplete", p.name)
+	}
+}
+
+// clear clears the outputs of the pass which are set to be cleared.
+func (p *Pass) clear() {
+
+	gs := p.fg.gs
+	color := int32(0)
+	for _, t := range p.outputs {
+		if fgDepth(t.desc.Format) {
+			if t.desc.Clear {
+				gs.DepthMask(true)
+				mask := uint(gls.DEPTH_BUFFER_BIT)
+				if t.desc.Format == gls.DEPTH24_STENCIL8 {
+					mask |= gls.STENCIL_BUFFER_BIT
+				}
+				gs.Clear(mask)
+			}
+			continue
+		}
+		if t.desc.Clear {
+			gs.ClearBufferfv(gls.COLOR, color, fgClearColor)
+		}
+		color++
+	}
+}
+
+// Gls returns the OpenGL state.
+func (pc *PassContext) Gls() *gls.GLS {
+
+	return pc.gs
+}
+
+// Framebuffer returns the frame buffer bound when the graph was executed,
+// which the passes may read, for example to copy its depth.
+func (pc *PassContext) Framebuffer() uint32 {
+
+	return pc.fb
+}
+
+// Viewport returns the viewport when the graph was executed.
+func (pc *PassContext) Viewport() (x, y, width, height int32) {
+
+	return pc.viewport[0], pc.viewport[1], pc.viewport[2], pc.viewport[3]
+}
@@ -0,0 +1,201 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build soft
+// +build soft
+
+package renderer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/g3n/engine/gls"
+)
+
+// newTestFrameGraph returns a frame graph using a software context with a 64x32 viewport.
+func newTestFrameGraph(t *testing.T) *FrameGraph {
+
+	gs, err := gls.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs.SetDefaultFramebufferSize(64, 32)
+	gs.Viewport(0, 0, 64, 32)
+	return NewFrameGraph(gs)
+}
+
+// nop is the rendering function of the test passes.
+func nop(pc *PassContext) error {
+
+	return nil
+}
+
+func TestFrameGraphOrder(t *testing.T) {
+
+	color := TargetDesc{Format: gls.RGBA8}
+	tests := []struct {
+		name    string
+		declare func(fg *FrameGraph)
+		order   []string
+		err     bool
+	}{
+		{"chain declared in reverse", func(fg *FrameGraph) {
+			a := fg.AddTarget("a", color)
+			b := fg.AddTarget("b", color)
+			fg.AddPass("final", nop).Read(b)
+			fg.AddPass("second", nop).Read(a).Write(b)
+			fg.AddPass("first", nop).Write(a)
+		}, []string{"first", "second", "final"}, false},
+		{"independent passes keep the declaration order", func(fg *FrameGraph) {
+			a := fg.AddTarget("a", color)
+			b := fg.AddTarget("b", color)
+			fg.AddPass("write b", nop).Write(b)
+			fg.AddPass("write a", nop).Write(a)
+			fg.AddPass("final", nop).Read(a, b)
+		}, []string{"write b", "write a", "final"}, false},
+		{"unread target is culled", func(fg *FrameGraph) {
+			a := fg.AddTarget("a", color)
+			b := fg.AddTarget("b", color)
+			fg.AddPass("write a", nop).Write(a)
+			fg.AddPass("unused", nop).Write(b)
+			fg.AddPass("final", nop).Read(a)
+		}, []string{"write a", "final"}, false},
+		{"exported target is kept", func(fg *FrameGraph) {
+			a := fg.AddTarget("a", color)
+			fg.AddPass("write a", nop).Write(a)
+			fg.Export(a)
+		}, []string{"write a"}, false},
+		{"modifier runs after the readers of the previous version", func(fg *FrameGraph) {
+			a := fg.AddTarget("a", color)
+			b := fg.AddTarget("b", color)
+			fg.AddPass("write a", nop).Write(a)
+			a2 := fg.AddPass("modify a", nop).Modify(a)
+			fg.AddPass("read a", nop).Read(a).Write(b)
+			fg.AddPass("final", nop).Read(a2, b)
+		}, []string{"write a", "read a", "modify a", "final"}, false},
+		{"imported target read without writer", func(fg *FrameGraph) {
+			in := fg.Import("input", 0)
+			fg.AddPass("final", nop).Read(in)
+		}, []string{"final"}, false},
+		{"target written twice", func(fg *FrameGraph) {
+			a := fg.AddTarget("a", color)
+			fg.AddPass("first", nop).Write(a)
+			fg.AddPass("second", nop).Write(a)
+			fg.AddPass("final", nop).Read(a)
+		}, nil, true},
+		{"target never written", func(fg *FrameGraph) {
+			a := fg.AddTarget("a", color)
+			fg.AddPass("final", nop).Read(a)
+		}, nil, true},
+		{"cycle", func(fg *FrameGraph) {
+			a := fg.AddTarget("a", color)
+			b := fg.AddTarget("b", color)
+			fg.AddPass("first", nop).Read(b).Write(a)
+			fg.AddPass("second", nop).Read(a).Write(b)
+			fg.AddPass("final", nop).Read(b)
+		}, nil, true},
+		{"unsupported format", func(fg *FrameGraph) {
+			a := fg.AddTarget("a", TargetDesc{Format: gls.RGB})
+			fg.AddPass("first", nop).Write(a)
+			fg.AddPass("final", nop).Read(a)
+		}, nil, true},
+	}
+	for _, test := range tests {
+		fg := newTestFrameGraph(t)
+		test.declare(fg)
+		order, err := fg.Order()
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: no error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(order, test.order) {
+			t.Errorf("%s: order %v, want %v", test.name, order, test.order)
+		}
+	}
+}
+
+func TestFrameGraphAliasing(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		descs [3]TargetDesc // Targets a, b and c written in a chain
+		alias bool          // Whether c reuses the texture of a
+	}{
+		{"same size and format", [3]TargetDesc{{Format: gls.RGBA8}, {Format: gls.RGBA8}, {Format: gls.RGBA8}}, true},
+		{"different format", [3]TargetDesc{{Format: gls.RGBA8}, {Format: gls.RGBA8}, {Format: gls.RGBA16F}}, false},
+		{"different scale", [3]TargetDesc{{Format: gls.R8}, {Format: gls.R8}, {Format: gls.R8, Scale: 0.5}}, false},
+		{"same fixed size", [3]TargetDesc{{Format: gls.R8, Width: 8, Height: 8}, {Format: gls.R8}, {Format: gls.R8, Width: 8, Height: 8}}, true},
+	}
+	for _, test := range tests {
+		fg := newTestFrameGraph(t)
+		a := fg.AddTarget("a", test.descs[0])
+		b := fg.AddTarget("b", test.descs[1])
+		c := fg.AddTarget("c", test.descs[2])
+		tex := make(map[string]uint32)
+		record := func(targets ...*Target) func(pc *PassContext) error {
+			return func(pc *PassContext) error {
+				for _, target := range targets {
+					tex[target.Name()] = target.Texture()
+				}
+				return nil
+			}
+		}
+		fg.AddPass("write a", record(a)).Write(a)
+		fg.AddPass("write b", record(a, b)).Read(a).Write(b)
+		fg.AddPass("write c", record(b, c)).Read(b).Write(c)
+		fg.AddPass("final", nop).Read(c)
+		err := fg.Execute()
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if tex["a"] == 0 || tex["b"] == 0 || tex["c"] == 0 {
+			t.Errorf("%s: targets without texture: %v", test.name, tex)
+			continue
+		}
+		if tex["a"] == tex["b"] || tex["b"] == tex["c"] {
+			t.Errorf("%s: texture aliased while in use: %v", test.name, tex)
+		}
+		if (tex["a"] == tex["c"]) != test.alias {
+			t.Errorf("%s: aliasing of a and c: %v, want %v", test.name, tex["a"] == tex["c"], test.alias)
+		}
+		if c.Texture() != 0 {
+			t.Errorf("%s: texture of a target not exported kept after execution", test.name)
+		}
+		fg.Dispose()
+	}
+}
+
+func TestFrameGraphModify(t *testing.T) {
+
+	fg := newTestFrameGraph(t)
+	depth := fg.AddTarget("depth", TargetDesc{Format: gls.DEPTH24_STENCIL8, Clear: true})
+	var first, second uint32
+	fg.AddPass("write", func(pc *PassContext) error {
+		first = depth.Texture()
+		return nil
+	}).Write(depth)
+	v := fg.AddPass("modify", nop).Modify(depth)
+	fg.AddPass("final", func(pc *PassContext) error {
+		second = v.Texture()
+		return nil
+	}).Read(v)
+	err := fg.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == 0 || first != second {
+		t.Errorf("modified target texture %d, want %d", second, first)
+	}
+	if v.Desc().Clear {
+		t.Error("modified target is cleared")
+	}
+}
@@ -0,0 +1,401 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/gls"
+)
+
+// hdrBuffers contains the vertex array and the uniforms of the HDR passes and the frame buffer used
+// to copy the HDR depth, whose color, depth, luminance and bloom textures are render targets of the frame graph.
+type hdrBuffers struct {
+	vao         uint32      // Empty vertex array object used to draw the full viewport triangle
+	readFbo     uint32      // Frame buffer object the HDR depth is copied from
+	lums        []float32   // Metered luminances
+	uniColor    gls.Uniform // Color texture sampler uniform
+	uniExposure gls.Uniform // Exposure uniform
+	uniBloom    gls.Uniform // Bloom texture sampler uniform
+	uniParams   gls.Uniform // Bloom threshold, knee and intensity uniform
+	uniStep     gls.Uniform // Blur step uniform
+}
+
+// ToneMapping is the operator which maps the exposed HDR colors to the displayable range.
+type ToneMapping int
+
+// Tone mapping operators
+const (
+	ToneMappingClamp    = ToneMapping(iota) // Colors are clamped to 1
+	ToneMappingReinhard                     // Reinhard operator c/(1+c), which compresses all the colors
+	ToneMappingACES                         // Filmic curve fitted to the ACES transforms, with more contrast
+)
+
+// toneMappingDefines contains the shader define of each tone mapping operator
+var toneMappingDefines = [...]string{
+	ToneMappingClamp:    "",
+	ToneMappingReinhard: "TONE_MAPPING_REINHARD",
+	ToneMappingACES:     "TONE_MAPPING_ACES",
+}
+
+// Number of horizontal and vertical blur iterations of the bloom
+const hdrBloomBlurs = 3
+
+// Soft knee of the bloom threshold as a fraction of the threshold
+const hdrBloomKnee = 0.5
+
+// Size of the side of the luminance metering buffer in pixels
+const hdrMeterSize = 64
+
+// Range in log2 units and number of bins of the luminance histogram
+const (
+	hdrHistMin  = -16
+	hdrHistMax  = 16
+	hdrHistBins = 64
+)
+
+// Fractions of the pixels, from the darkest, between which the luminance histogram is averaged
+const (
+	hdrHistLow  = 0.5
+	hdrHistHigh = 0.95
+)
+
+// SetHDR sets whether the 3D objects are rendered to a floating point frame buffer whose colors are
+// multiplied by the exposure of the camera and clamped when written to the current frame buffer.
+// The exposure is set manually or adapted automatically to the luminance of the scene, as set
+// by the exposure mode of the camera. The contents of the current frame buffer are copied to the
+// HDR frame buffer before the 3D objects are rendered and GUI panels are rendered after the HDR
+// colors are written, without exposure. The depth buffer of the current frame buffer must be DEPTH24_STENCIL8.
+func (r *Renderer) SetHDR(enable bool) {
+
+	r.hdr = enable
+}
+
+// HDR returns whether the 3D objects are rendered to a floating point frame buffer.
+func (r *Renderer) HDR() bool {
+
+	return r.hdr
+}
+
+// SetToneMapping sets the operator which maps the exposed HDR colors to the displayable range.
+// The default ToneMappingClamp clamps the colors, so bright lights and emissive materials clip harshly.
+func (r *Renderer) SetToneMapping(tm ToneMapping) {
+
+	r.toneMapping = tm
+}
+
+// ToneMapping returns the operator which maps the exposed HDR colors to the displayable range.
+func (r *Renderer) ToneMapping() ToneMapping {
+
+	return r.toneMapping
+}
+
+// SetBloom sets whether the exposed HDR colors above the bloom threshold are blurred
+// at half resolution and added to the colors before tone mapping, so they glow.
+func (r *Renderer) SetBloom(enable bool) {
+
+	r.bloom = enable
+}
+
+// Bloom returns whether the bright HDR colors glow.
+func (r *Renderer) Bloom() bool {
+
+	return r.bloom
+}
+
+// SetBloomThreshold sets the exposed color component above which the colors glow. The default is 1.
+func (r *Renderer) SetBloomThreshold(threshold float32) {
+
+	r.bloomThreshold = threshold
+}
+
+// BloomThreshold returns the exposed color component above which the colors glow.
+func (r *Renderer) BloomThreshold() float32 {
+
+	return r.bloomThreshold
+}
+
+// SetBloomIntensity sets the factor applied to the glow added to the colors. The default is 0.5.
+func (r *Renderer) SetBloomIntensity(intensity float32) {
+
+	r.bloomIntensity = intensity
+}
+
+// BloomIntensity returns the factor applied to the glow added to the colors.
+func (r *Renderer) BloomIntensity() float32 {
+
+	return r.bloomIntensity
+}
+
+// declareHDRPasses declares in the specified frame graph the passes which meter the luminance of the
+// specified HDR color target, blur its bright colors and write them, exposed and tone mapped, to the
+// specified frame target, copying the specified HDR depth target, and returns the new version of the frame target.
+func (r *Renderer) declareHDRPasses(fg *FrameGraph, frame, color, depth *Target) *Target {
+
+	if r.hdrBuffers == nil {
+		r.hdrBuffers = newHDRBuffers(r.gs)
+	}
+	var inputs []*Target
+
+	// The exposure of the camera is adapted to the luminance before it is applied
+	if c, ok := r.cam.(*camera.Camera); ok && c.ExposureMode() != camera.ExposureManual {
+		lum := fg.AddTarget("hdr luminance", TargetDesc{Width: hdrMeterSize, Height: hdrMeterSize, Format: gls.R32F})
+		fg.AddPass("hdr meter", func(pc *PassContext) error {
+			return r.meterLuminance(c, color)
+		}).Read(color).Write(lum)
+		inputs = append(inputs, lum)
+	}
+
+	// The bright colors are blurred at half resolution horizontally and vertically
+	var bloom *Target
+	if r.bloom {
+		bloom = fg.AddTarget("bloom", TargetDesc{Scale: 0.5, Format: gls.RGBA16F})
+		fg.AddPass("bloom bright", func(pc *PassContext) error {
+			return r.renderBright(color)
+		}).Read(inputs...).Read(color).Write(bloom)
+		for i := 0; i < 2*hdrBloomBlurs; i++ {
+			in := bloom
+			step := [2]float32{1, 0}
+			if i%2 == 1 {
+				step = [2]float32{0, 1}
+			}
+			bloom = fg.AddTarget("bloom", TargetDesc{Scale: 0.5, Format: gls.RGBA16F})
+			fg.AddPass(fmt.Sprintf("bloom blur %d", i), func(pc *PassContext) error {
+				return r.blurBloom(in, step[0]/float32(pc.Width), step[1]/float32(pc.Height))
+			}).Read(in).Write(bloom)
+		}
+		inputs = append(inputs, bloom)
+	}
+	fg.AddPass("hdr resolve", func(pc *PassContext) error {
+		return r.resolveHDR(pc, color, depth, bloom)
+	}).Read(inputs...).Read(color, depth).Write(frame)
+	return frame
+}
+
+// beginHDR copies the colors, depth and stencil of the frame buffer of the frame to the HDR
+// targets of the current pass, so the 3D objects are rendered over them.
+func (r *Renderer) beginHDR(pc *PassContext) {
+
+	vx, vy, vw, vh := pc.Viewport()
+	fbo := r.gs.Framebuffer()
+	r.gs.BindReadFramebuffer(pc.Framebuffer())
+	r.gs.BlitFramebuffer(vx, vy, vx+vw, vy+vh, 0, 0, vw, vh, gls.COLOR_BUFFER_BIT|gls.DEPTH_BUFFER_BIT|gls.STENCIL_BUFFER_BIT, gls.NEAREST)
+	r.gs.BindFramebuffer(fbo)
+}
+
+// resolveHDR writes the colors of the specified HDR color target, multiplied by the exposure of the camera
+// and with the specified bloom target added if not nil, to the frame buffer of the frame, and copies the
+// specified HDR depth target to it.
+func (r *Renderer) resolveHDR(pc *PassContext, color, depth, bloom *Target) error {
+
+	hb := r.hdrBuffers
+	defines := []string{toneMappingDefines[r.toneMapping]}
+	if bloom != nil {
+		defines = append(defines, "HDR_BLOOM")
+	}
+	err := r.setHDRProgram(defines...)
+	if err != nil {
+		return err
+	}
+	r.gs.Uniform1f(hb.uniExposure.Location(r.gs), r.exposure())
+	if bloom != nil {
+		r.gs.ActiveTexture(gls.TEXTURE1)
+		r.gs.BindTexture(gls.TEXTURE_2D, bloom.Texture())
+		r.gs.Uniform1i(hb.uniBloom.Location(r.gs), 1)
+		r.gs.Uniform3f(hb.uniParams.Location(r.gs), r.bloomThreshold, hdrBloomKnee, r.bloomIntensity)
+	}
+	r.drawHDRPass(color.Texture())
+
+	// Copy the depth and stencil so the panels are tested against the 3D objects
+	vx, vy, vw, vh := pc.Viewport()
+	r.gs.BindFramebuffer(hb.readFbo)
+	r.gs.FramebufferTexture2D(gls.DEPTH_STENCIL_ATTACHMENT, gls.TEXTURE_2D, depth.Texture())
+	r.gs.BindFramebuffer(pc.Framebuffer())
+	r.gs.BindReadFramebuffer(hb.readFbo)
+	r.gs.BlitFramebuffer(0, 0, vw, vh, vx, vy, vx+vw, vy+vh, gls.DEPTH_BUFFER_BIT|gls.STENCIL_BUFFER_BIT, gls.NEAREST)
+	r.gs.BindFramebuffer(hb.readFbo)
+	r.gs.FramebufferTexture2D(gls.DEPTH_STENCIL_ATTACHMENT, gls.TEXTURE_2D, 0)
+	r.gs.BindFramebuffer(pc.Framebuffer())
+	return nil
+}
+
+// exposure returns the exposure of the camera of the frame, which is one if it is not a *camera.Camera.
+func (r *Renderer) exposure() float32 {
+
+	if c, ok := r.cam.(*camera.Camera); ok {
+		return c.Exposure()
+	}
+	return 1
+}
+
+// meterLuminance renders the luminance of the specified HDR color target to the luminance target of the
+// current pass and adapts the exposure of the specified camera to their average, calculated as set by its exposure mode.
+func (r *Renderer) meterLuminance(c *camera.Camera, color *Target) error {
+
+	hb := r.hdrBuffers
+	err := r.setHDRProgram("HDR_LUMINANCE")
+	if err != nil {
+		return err
+	}
+	r.drawHDRPass(color.Texture())
+	pix := r.gs.ReadPixels(0, 0, hdrMeterSize, hdrMeterSize, gls.RED, gls.FLOAT)
+
+	hb.lums = hb.lums[:0]
+	for i := 0; i+4 <= len(pix); i += 4 {
+		hb.lums = append(hb.lums, math.Float32frombits(binary.LittleEndian.Uint32(pix[i:])))
+	}
+	if c.ExposureMode() == camera.ExposureHistogram {
+		c.AdaptExposure(histogramLuminance(hb.lums))
+	} else {
+		c.AdaptExposure(averageLuminance(hb.lums))
+	}
+	return nil
+}
+
+// renderBright renders the exposed colors of the specified HDR color target above the bloom threshold.
+func (r *Renderer) renderBright(color *Target) error {
+
+	hb := r.hdrBuffers
+	err := r.setHDRProgram("HDR_BRIGHT")
+	if err != nil {
+		return err
+	}
+	r.gs.Uniform1f(hb.uniExposure.Location(r.gs), r.exposure())
+	r.gs.Uniform3f(hb.uniParams.Location(r.gs), r.bloomThreshold, hdrBloomKnee, r.bloomIntensity)
+	r.drawHDRPass(color.Texture())
+	return nil
+}
+
+// blurBloom blurs the bright colors of the specified bloom target in the direction of the specified texture coordinates step.
+func (r *Renderer) blurBloom(bloom *Target, stepX, stepY float32) error {
+
+	hb := r.hdrBuffers
+	err := r.setHDRProgram("HDR_BLUR")
+	if err != nil {
+		return err
+	}
+	r.gs.Uniform2f(hb.uniStep.Location(r.gs), stepX, stepY)
+	r.drawHDRPass(bloom.Texture())
+	return nil
+}
+
+// setHDRProgram sets the program of the HDR passes with the specified defines
+// and the state to draw the full viewport triangle.
+func (r *Renderer) setHDRProgram(defines ...string) error {
+
+	r.hdrSpecs.Name = "hdr"
+	r.hdrSpecs.Defines = *gls.NewShaderDefines()
+	for _, d := range defines {
+		if d != "" {
+			r.hdrSpecs.Defines.Set(d, "")
+		}
+	}
+	_, err := r.Shaman.SetProgram(&r.hdrSpecs)
+	if err != nil {
+		return err
+	}
+	r.gs.Disable(gls.DEPTH_TEST)
+	r.gs.DepthMask(false)
+	r.gs.Disable(gls.BLEND)
+	return nil
+}
+
+// drawHDRPass draws the full viewport triangle sampling the specified texture.
+func (r *Renderer) drawHDRPass(tex uint32) {
+
+	hb := r.hdrBuffers
+	r.gs.ActiveTexture(gls.TEXTURE0)
+	r.gs.BindTexture(gls.TEXTURE_2D, tex)
+	r.gs.Uniform1i(hb.uniColor.Location(r.gs), 0)
+	r.gs.BindVertexArray(hb.vao)
+	r.gs.DrawArrays(gls.TRIANGLES, 0, 3)
+}
+
+// averageLuminance returns the logarithmic average of the specified luminances,
+// which is less affected by small very bright areas than the arithmetic average.
+func averageLuminance(lums []float32) float32 {
+
+	if len(lums) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, l := range lums {
+		sum += hdrLog2(l)
+	}
+	return float32(math.Exp2(sum / float64(len(lums))))
+}
+
+// histogramLuminance returns the logarithmic average of the specified luminances between the low
+// and high fractions of the pixels, from the darkest, calculated with their histogram,
+// so large dark areas and small bright lights do not change the exposure.
+func histogramLuminance(lums []float32) float32 {
+
+	if len(lums) == 0 {
+		return 0
+	}
+	var hist [hdrHistBins]int
+	const binSize = float64(hdrHistMax-hdrHistMin) / hdrHistBins
+	for _, l := range lums {
+		bin := int((hdrLog2(l) - hdrHistMin) / binSize)
+		if bin < 0 {
+			bin = 0
+		} else if bin >= hdrHistBins {
+			bin = hdrHistBins - 1
+		}
+		hist[bin]++
+	}
+
+	// Average the centers of the bins weighted by their pixels between the low and high fractions
+	low := hdrHistLow * float64(len(lums))
+	high := hdrHistHigh * float64(len(lums))
+	var sum, count, acc float64
+	for i, n := range hist {
+		from := math.Max(acc, low)
+		to := math.Min(acc+float64(n), high)
+		if to > from {
+			sum += (hdrHistMin + (float64(i)+0.5)*binSize) * (to - from)
+			count += to - from
+		}
+		acc += float64(n)
+	}
+	if count == 0 {
+		return averageLuminance(lums)
+	}
+	return float32(math.Exp2(sum / count))
+}
+
+// hdrLog2 returns the base 2 logarithm of the specified luminance
+// limited to the range of the histogram, which is zero for invalid values.
+func hdrLog2(l float32) float64 {
+
+	if !(l > 0) {
+		return hdrHistMin
+	}
+	return math.Max(hdrHistMin, math.Min(hdrHistMax, math.Log2(float64(l))))
+}
+
+// newHDRBuffers creates and returns a pointer to new HDR buffers.
+func newHDRBuffers(gs *gls.GLS) *hdrBuffers {
+
+	hb := new(hdrBuffers)
+	hb.vao = gs.GenVertexArray()
+	hb.uniColor.Init("HDRColor")
+	hb.uniExposure.Init("HDRExposure")
+	hb.uniBloom.Init("HDRBloom")
+	hb.uniParams.Init("HDRBloomParams")
+	hb.uniStep.Init("HDRBlurStep")
+
+	// The depth is only copied from the frame buffer, which has no color buffers
+	fb := gs.Framebuffer()
+	hb.readFbo = gs.GenFramebuffer()
+	gs.BindFramebuffer(hb.readFbo)
+	gs.DrawBuffer(gls.NONE)
+	gs.ReadBuffer(gls.NONE)
+	gs.BindFramebuffer(fb)
+	return hb
+}
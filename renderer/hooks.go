@@ -0,0 +1,77 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/gls"
+)
+
+// RenderStage is a point of the rendering of a frame where render hooks are called.
+type RenderStage int
+
+// The render stages in the order they occur in a frame
+const (
+	BeforeOpaque      RenderStage = iota // Before the opaque 3D objects, after the shadow maps
+	AfterOpaque                          // After the opaque 3D objects
+	BeforeTransparent                    // Before the transparent 3D objects, after the AfterOpaque hooks
+	AfterGUI                             // After the GUI panels, at the end of the frame
+)
+
+// RenderHookFunc is the type of the functions called by the renderer at a render stage of each frame,
+// with the OpenGL state and the camera of the frame, to render custom passes such as decals, outlines
+// or debug drawings. The hooks of the stages before the GUI render to the HDR frame buffer if enabled.
+// A hook may change the OpenGL state through the specified GLS, whose state cache the renderer relies on.
+// An error returned by a hook aborts the rendering of the frame and is returned by Render.
+type RenderHookFunc func(gs *gls.GLS, cam camera.ICamera) error
+
+// renderHook is a render hook added to the renderer.
+type renderHook struct {
+	id    int            // Identifier returned by AddRenderHook
+	stage RenderStage    // Stage where the hook is called
+	fn    RenderHookFunc // Hook function
+}
+
+// AddRenderHook adds a hook called at the specified render stage of each frame, after the hooks
+// previously added to the same stage, and returns its identifier to be used by RemoveRenderHook.
+// The hooks are not called while rendering the cube maps of the reflection probes.
+func (r *Renderer) AddRenderHook(stage RenderStage, hook RenderHookFunc) int {
+
+	r.hookID++
+	r.hooks = append(r.hooks, renderHook{id: r.hookID, stage: stage, fn: hook})
+	return r.hookID
+}
+
+// RemoveRenderHook removes the render hook with the specified identifier and returns whether it was found.
+func (r *Renderer) RemoveRenderHook(id int) bool {
+
+	for i := range r.hooks {
+		if r.hooks[i].id == id {
+			r.hooks = append(r.hooks[:i], r.hooks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// runHooks calls the render hooks of the specified stage in the order they were added.
+func (r *Renderer) runHooks(stage RenderStage) error {
+
+	if r.probePass {
+		return nil
+	}
+	for _, h := range r.hooks {
+		if h.stage != stage {
+			continue
+		}
+		err := h.fn(r.gs, r.cam)
+		// The hook may have used another program, so the next SetProgram must activate its program
+		r.Shaman.specs = ShaderSpecs{}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"github.com/g3n/engine/gls"
+)
+
+// oitBuffers contains the vertex array and the uniforms of the composite pass of the weighted
+// blended order independent transparency, whose textures are render targets of the frame graph.
+type oitBuffers struct {
+	vao       uint32      // Empty vertex array object used to draw the composite triangle
+	uniAccum  gls.Uniform // Accumulation texture sampler uniform
+	uniWeight gls.Uniform // Weight texture sampler uniform
+}
+
+// Clear values of the OIT accumulation and weight targets
+var (
+	oitClearAccum  = []float32{0, 0, 0, 1}
+	oitClearWeight = []float32{0, 0, 0, 0}
+)
+
+// SetOrderIndependentTransparency sets whether the transparent 3D objects are rendered with weighted
+// blended order independent transparency instead of being sorted and blended back to front.
+// It renders intersecting transparent geometry correctly, at the cost of an additional frame buffer
+// and composite pass. Only the materials using the built-in shaders support it and GUI panels
+// are always sorted. The depth buffer of the frame buffer being rendered to must be DEPTH24_STENCIL8.
+func (r *Renderer) SetOrderIndependentTransparency(enable bool) {
+
+	r.oit = enable
+}
+
+// OrderIndependentTransparency returns whether the transparent 3D objects
+// are rendered with weighted blended order independent transparency.
+func (r *Renderer) OrderIndependentTransparency() bool {
+
+	return r.oit
+}
+
+// renderOITAccum accumulates the weighted colors of the transparent 3D objects in the
+// accumulation and weight targets of the current pass, testing them against its depth target.
+// If copyDepth is true the depth of the opaque objects is copied from the frame buffer of the frame.
+func (r *Renderer) renderOITAccum(pc *PassContext, copyDepth bool) error {
+
+	if copyDepth {
+		vx, vy, vw, vh := pc.Viewport()
+		fbo := r.gs.Framebuffer()
+		r.gs.BindReadFramebuffer(pc.Framebuffer())
+		r.gs.BlitFramebuffer(vx, vy, vx+vw, vy+vh, 0, 0, vw, vh, gls.DEPTH_BUFFER_BIT, gls.NEAREST)
+		r.gs.BindFramebuffer(fbo)
+	}
+	r.gs.ClearBufferfv(gls.COLOR, 0, oitClearAccum)
+	r.gs.ClearBufferfv(gls.COLOR, 1, oitClearWeight)
+
+	// Accumulate the transparent objects in any order
+	r.oitPass = true
+	for _, grmat := range r.grmatsTransp[:r.transp3D] {
+		err := r.renderGraphicMaterial(grmat)
+		if err != nil {
+			r.oitPass = false
+			return err
+		}
+	}
+	r.oitPass = false
+	return nil
+}
+
+// compositeOIT composites the average transparent color of the specified accumulation
+// and weight targets over the opaque objects rendered to the current frame buffer.
+func (r *Renderer) compositeOIT(accum, weight *Target) error {
+
+	if r.oitBuffers == nil {
+		r.oitBuffers = newOITBuffers(r.gs)
+	}
+	ob := r.oitBuffers
+	r.oitSpecs.Name = "oit_composite"
+	_, err := r.Shaman.SetProgram(&r.oitSpecs)
+	if err != nil {
+		return err
+	}
+	r.gs.Disable(gls.DEPTH_TEST)
+	r.gs.DepthMask(false)
+	r.gs.Enable(gls.BLEND)
+	r.gs.BlendEquation(gls.FUNC_ADD)
+	r.gs.BlendFunc(gls.SRC_ALPHA, gls.ONE_MINUS_SRC_ALPHA)
+	r.gs.ActiveTexture(gls.TEXTURE0)
+	r.gs.BindTexture(gls.TEXTURE_2D, accum.Texture())
+	r.gs.Uniform1i(ob.uniAccum.Location(r.gs), 0)
+	r.gs.ActiveTexture(gls.TEXTURE1)
+	r.gs.BindTexture(gls.TEXTURE_2D, weight.Texture())
+	r.gs.Uniform1i(ob.uniWeight.Location(r.gs), 1)
+	r.gs.BindVertexArray(ob.vao)
+	r.gs.DrawArrays(gls.TRIANGLES, 0, 3)
+	return nil
+}
+
+// oitBlending overrides the blending and depth states set by the material of a transparent object
+// for the accumulation pass: the colors and weights are added and the revealage is multiplied by
+// one minus the alpha of each fragment. The depth is tested against the opaque objects but not written.
+func oitBlending(gs *gls.GLS) {
+
+	gs.Enable(gls.BLEND)
+	gs.BlendEquation(gls.FUNC_ADD)
+	gs.BlendFuncSeparate(gls.ONE, gls.ONE, gls.ZERO, gls.ONE_MINUS_SRC_ALPHA)
+	gs.Enable(gls.DEPTH_TEST)
+	gs.DepthMask(false)
+}
+
+// newOITBuffers creates and returns a pointer to new OIT buffers.
+func newOITBuffers(gs *gls.GLS) *oitBuffers {
+
+	ob := new(oitBuffers)
+	ob.vao = gs.GenVertexArray()
+	ob.uniAccum.Init("OITAccum")
+	ob.uniWeight.Init("OITWeight")
+	return ob
+}
@@ -0,0 +1,156 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Stencil value written where the outlined graphics are
+const outlineStencil = 1
+
+// AddOutline adds the specified node to the nodes whose graphics, including the graphics of
+// their descendants, are drawn with a selection outline. The outlines are drawn over the 3D
+// objects, including the ones in front of the outlined graphics, and under the GUI panels.
+// The graphics are first rendered to the stencil buffer, which the current frame buffer must have,
+// and then extruded along their vertex normals by the outline width where the stencil is not set.
+func (r *Renderer) AddOutline(inode core.INode) {
+
+	if r.outlines == nil {
+		r.outlines = make(map[core.INode]bool)
+	}
+	r.outlines[inode] = true
+}
+
+// RemoveOutline removes the specified node from the outlined nodes and returns whether it was found.
+func (r *Renderer) RemoveOutline(inode core.INode) bool {
+
+	if !r.outlines[inode] {
+		return false
+	}
+	delete(r.outlines, inode)
+	return true
+}
+
+// ClearOutlines removes all the outlined nodes.
+func (r *Renderer) ClearOutlines() {
+
+	r.outlines = nil
+}
+
+// Outlined returns whether the specified node is outlined.
+func (r *Renderer) Outlined(inode core.INode) bool {
+
+	return r.outlines[inode]
+}
+
+// SetOutlineColor sets the color of the selection outlines, which are blended
+// with the frame buffer using the alpha component. The default is opaque orange.
+func (r *Renderer) SetOutlineColor(color *math32.Color4) {
+
+	r.outlineColor = *color
+}
+
+// OutlineColor returns the color of the selection outlines.
+func (r *Renderer) OutlineColor() math32.Color4 {
+
+	return r.outlineColor
+}
+
+// SetOutlineWidth sets the width in pixels of the selection outlines. The default is 2.
+func (r *Renderer) SetOutlineWidth(width float32) {
+
+	r.outlineWidth = width
+}
+
+// OutlineWidth returns the width in pixels of the selection outlines.
+func (r *Renderer) OutlineWidth() float32 {
+
+	return r.outlineWidth
+}
+
+// renderOutlines draws the outlines of the rendered graphics which are outlined or have an outlined ancestor.
+func (r *Renderer) renderOutlines() error {
+
+	if len(r.outlines) == 0 || r.probePass {
+		return nil
+	}
+	r.outlined = r.outlined[0:0]
+	for _, gr := range r.graphics {
+		for inode := gr.GetINode(); inode != nil; inode = inode.Parent() {
+			if r.outlines[inode] {
+				r.outlined = append(r.outlined, gr)
+				break
+			}
+		}
+	}
+	if len(r.outlined) == 0 {
+		return nil
+	}
+
+	// Set the stencil where the outlined graphics are, without changing the color and depth buffers
+	r.gs.Enable(gls.STENCIL_TEST)
+	r.gs.StencilMask(0xFF)
+	r.gs.Clear(gls.STENCIL_BUFFER_BIT)
+	r.gs.StencilFunc(gls.ALWAYS, outlineStencil, 0xFF)
+	r.gs.StencilOp(gls.KEEP, gls.KEEP, gls.REPLACE)
+	r.gs.ColorMask(false, false, false, false)
+	err := r.renderOutlinePass(0, 0)
+
+	// Draw the extruded graphics where the stencil is not set
+	if err == nil {
+		_, _, vw, vh := r.gs.GetViewport()
+		r.gs.ColorMask(true, true, true, true)
+		r.gs.StencilFunc(gls.NOTEQUAL, outlineStencil, 0xFF)
+		r.gs.StencilOp(gls.KEEP, gls.KEEP, gls.KEEP)
+		r.gs.StencilMask(0)
+		err = r.renderOutlinePass(2*r.outlineWidth/float32(vw), 2*r.outlineWidth/float32(vh))
+	}
+
+	r.gs.ColorMask(true, true, true, true)
+	r.gs.StencilMask(0xFF)
+	r.gs.Disable(gls.STENCIL_TEST)
+	return err
+}
+
+// renderOutlinePass renders the outlined graphics extruded by the specified width in normalized device coordinates.
+func (r *Renderer) renderOutlinePass(width, height float32) error {
+
+	for _, gr := range r.outlined {
+		materials := gr.Materials()
+		for i := range materials {
+			grmat := &materials[i]
+			geom := grmat.IGraphic().GetGeometry()
+
+			// Add defines from geometry and graphic for morph targets, skinning and instancing
+			r.outlineSpecs.Name = "outline"
+			r.outlineSpecs.Defines = *gls.NewShaderDefines()
+			r.outlineSpecs.Defines.Add(&geom.ShaderDefines)
+			r.outlineSpecs.Defines.Add(&gr.ShaderDefines)
+			_, err := r.Shaman.SetProgram(&r.outlineSpecs)
+			if err != nil {
+				return err
+			}
+			r.gs.Uniform2f(r.uniOutlineWidth.Location(r.gs), width, height)
+			c := &r.outlineColor
+			r.gs.Uniform4f(r.uniOutlineColor.Location(r.gs), c.R, c.G, c.B, c.A)
+			grmat.RenderOverride(r.gs, &r.rinfo, outlineState)
+		}
+	}
+	return nil
+}
+
+// outlineState overrides the blending and depth states set by the material of a graphic
+// so the outlines are blended over all the 3D objects without changing the depth buffer.
+func outlineState(gs *gls.GLS) {
+
+	gs.Enable(gls.BLEND)
+	gs.BlendEquation(gls.FUNC_ADD)
+	gs.BlendFunc(gls.SRC_ALPHA, gls.ONE_MINUS_SRC_ALPHA)
+	gs.Disable(gls.DEPTH_TEST)
+	gs.DepthMask(false)
+}
@@ -0,0 +1,178 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+)
+
+// pickBuffers contains the frame buffer used to render the graphics with their pick ids.
+type pickBuffers struct {
+	width    int32       // Width of the buffers in pixels
+	height   int32       // Height of the buffers in pixels
+	fbo      uint32      // Frame buffer object
+	colorRbo uint32      // Color buffer with the pick ids
+	depthRbo uint32      // Depth buffer
+	uniID    gls.Uniform // Pick id uniform
+}
+
+// Clear value of the pick color buffer (no graphic)
+var pickClearID = []float32{0, 0, 0, 0}
+
+// Pick renders the graphics of the specified scene visible by the specified camera, each with
+// a unique color, to an offscreen frame buffer with the size of the current viewport and returns
+// the graphic rendered at the specified viewport coordinates in pixels, with the origin at the
+// bottom left corner as in OpenGL window coordinates, or nil if there is none.
+// As the graphics are rendered by the GPU, the deformations by morph targets, skinning and
+// instancing are taken into account. Only the picked pixel is rendered using the scissor test.
+// GUI panels are not picked. Only the graphics in the layers rendered by the camera are picked.
+func (r *Renderer) Pick(scene core.INode, cam camera.ICamera, x, y int) (graphic.IGraphic, error) {
+
+	return r.PickLayers(scene, cam, x, y, core.AllLayers)
+}
+
+// PickLayers is like Pick but only picks the graphics which belong to any of the layers
+// of the specified mask and are in the layers rendered by the camera.
+func (r *Renderer) PickLayers(scene core.INode, cam camera.ICamera, x, y int, mask uint32) (graphic.IGraphic, error) {
+
+	r.checkContext()
+
+	// The render statistics are only updated by Render
+	stats := r.stats
+	defer func() { r.stats = stats }()
+
+	// Classify the scene and calculate the matrices for the camera
+	scene.UpdateMatrixWorld()
+	cam.ViewMatrix(&r.rinfo.ViewMatrix)
+	cam.ProjMatrix(&r.rinfo.ProjMatrix)
+	r.layerMask = cameraLayerMask(cam) & mask
+	r.clearScene()
+	var proj math32.Matrix4
+	proj.MultiplyMatrices(&r.rinfo.ProjMatrix, &r.rinfo.ViewMatrix)
+	r.classifyAndCull(scene, math32.NewFrustumFromMatrix(&proj), 0)
+
+	// Save the current frame buffer and viewport
+	fb := r.gs.Framebuffer()
+	vx, vy, vw, vh := r.gs.GetViewport()
+	if x < 0 || y < 0 || x >= int(vw) || y >= int(vh) {
+		return nil, nil
+	}
+	if r.pickBuffers == nil {
+		r.pickBuffers = newPickBuffers(r.gs)
+	}
+	pb := r.pickBuffers
+	pb.resize(r.gs, vw, vh)
+
+	// Clear and render only the picked pixel
+	r.gs.BindFramebuffer(pb.fbo)
+	r.gs.Viewport(0, 0, vw, vh)
+	r.gs.Enable(gls.SCISSOR_TEST)
+	r.gs.Scissor(int32(x), int32(y), 1, 1)
+	r.gs.DepthMask(true)
+	r.gs.ClearBufferfv(gls.COLOR, 0, pickClearID)
+	r.gs.Clear(gls.DEPTH_BUFFER_BIT)
+
+	var err error
+	for i := 0; i < len(r.graphics) && err == nil; i++ {
+		gr := r.graphics[i]
+		gr.CalculateMatrices(r.gs, &r.rinfo)
+		materials := gr.Materials()
+		for j := 0; j < len(materials) && err == nil; j++ {
+			err = r.renderPickID(&materials[j], uint32(i+1))
+		}
+	}
+	var pix []byte
+	if err == nil {
+		pix = r.gs.ReadPixels(x, y, 1, 1, gls.RGBA, gls.UNSIGNED_BYTE)
+	}
+
+	// Restore the frame buffer and viewport
+	r.gs.Disable(gls.SCISSOR_TEST)
+	r.gs.BindFramebuffer(fb)
+	r.gs.Viewport(vx, vy, vw, vh)
+	if err != nil {
+		return nil, err
+	}
+
+	id := int(pix[0]) | int(pix[1])<<8 | int(pix[2])<<16
+	if id == 0 || id > len(r.graphics) {
+		return nil, nil
+	}
+	igr, _ := r.graphics[id-1].GetINode().(graphic.IGraphic)
+	return igr, nil
+}
+
+// renderPickID renders the specified graphic material with the specified pick id as its color.
+func (r *Renderer) renderPickID(grmat *graphic.GraphicMaterial, id uint32) error {
+
+	geom := grmat.IGraphic().GetGeometry()
+	gr := grmat.IGraphic().GetGraphic()
+
+	// Add defines from geometry and graphic for morph targets, skinning and instancing
+	r.pickSpecs.Name = "pick"
+	r.pickSpecs.Defines = *gls.NewShaderDefines()
+	r.pickSpecs.Defines.Add(&geom.ShaderDefines)
+	r.pickSpecs.Defines.Add(&gr.ShaderDefines)
+	_, err := r.Shaman.SetProgram(&r.pickSpecs)
+	if err != nil {
+		return err
+	}
+
+	// The id is encoded in the red, green and blue components
+	location := r.pickBuffers.uniID.Location(r.gs)
+	r.gs.Uniform4f(location, float32(id&0xFF)/255, float32(id>>8&0xFF)/255, float32(id>>16&0xFF)/255, 1)
+	grmat.RenderOverride(r.gs, &r.rinfo, pickState)
+	return nil
+}
+
+// pickState overrides the blending and depth states set by the material of a graphic
+// so the pick ids are written without blending and the nearest graphic is picked.
+func pickState(gs *gls.GLS) {
+
+	gs.Disable(gls.BLEND)
+	gs.Enable(gls.DEPTH_TEST)
+	gs.DepthFunc(gls.LEQUAL)
+	gs.DepthMask(true)
+}
+
+// newPickBuffers creates and returns a pointer to new pick buffers with zero size.
+func newPickBuffers(gs *gls.GLS) *pickBuffers {
+
+	pb := new(pickBuffers)
+	pb.fbo = gs.GenFramebuffer()
+	pb.colorRbo = gs.GenRenderbuffer()
+	pb.depthRbo = gs.GenRenderbuffer()
+	pb.uniID.Init("PickID")
+	return pb
+}
+
+// resize reallocates the buffers if the specified size is different from the current size.
+func (pb *pickBuffers) resize(gs *gls.GLS, width, height int32) {
+
+	if width == pb.width && height == pb.height {
+		return
+	}
+	pb.width = width
+	pb.height = height
+
+	gs.BindRenderbuffer(pb.colorRbo)
+	gs.RenderbufferStorage(gls.RGBA8, int(width), int(height))
+	gs.BindRenderbuffer(pb.depthRbo)
+	gs.RenderbufferStorage(gls.DEPTH_COMPONENT24, int(width), int(height))
+	gs.BindRenderbuffer(0)
+
+	fb := gs.Framebuffer()
+	gs.BindFramebuffer(pb.fbo)
+	gs.FramebufferRenderbuffer(gls.COLOR_ATTACHMENT0, pb.colorRbo)
+	gs.FramebufferRenderbuffer(gls.DEPTH_ATTACHMENT, pb.depthRbo)
+	if gs.CheckFramebufferStatus() != gls.FRAMEBUFFER_COMPLETE {
+		log.Error("Pick frame buffer is incomplete")
+	}
+	gs.BindFramebuffer(fb)
+}
@@ -0,0 +1,343 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"bufio"
+	"image"
+	"image/png"
+	"os"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// probeMap contains the cube map and the frame buffer of a reflection probe.
+type probeMap struct {
+	size     int            // Size in pixels of each face
+	levels   int            // Number of mipmap levels
+	tex      uint32         // Cube map texture
+	fbo      uint32         // Frame buffer object
+	depthRbo uint32         // Depth buffer
+	version  uint32         // Version of the probe when the cube map was updated
+	valid    bool           // Whether the cube map was rendered or uploaded
+	position math32.Vector3 // World position of the probe in the current frame
+}
+
+// probeCamera is the camera used to render a face of the cube map of a reflection probe.
+type probeCamera struct {
+	view math32.Matrix4 // View matrix
+	proj math32.Matrix4 // Projection matrix
+}
+
+// ViewMatrix satisfies the camera.ICamera interface.
+func (c *probeCamera) ViewMatrix(m *math32.Matrix4) {
+
+	*m = c.view
+}
+
+// ProjMatrix satisfies the camera.ICamera interface.
+func (c *probeCamera) ProjMatrix(m *math32.Matrix4) {
+
+	*m = c.proj
+}
+
+// Directions and up vectors of the cameras rendering the faces of a cube map, in the order of the faces
+var probeFaceDirs = [6][2]math32.Vector3{
+	{{X: 1}, {Y: -1}},
+	{{X: -1}, {Y: -1}},
+	{{Y: 1}, {Z: 1}},
+	{{Y: -1}, {Z: -1}},
+	{{Z: 1}, {Y: -1}},
+	{{Z: -1}, {Y: -1}},
+}
+
+// updateProbes finds the visible reflection probes in the scene and renders
+// or uploads the cube maps of the probes which must be updated.
+// Must be called before the matrices of the graphics are calculated and the uniform
+// blocks are transferred for the camera of the frame, as it renders the scene.
+func (r *Renderer) updateProbes(scene core.INode) error {
+
+	r.probes = r.probes[0:0]
+	r.findProbes(scene)
+	for _, p := range r.probes {
+		pm := r.probeMap(p)
+		p.WorldPosition(&pm.position)
+		switch p.Mode() {
+		case light.ProbeBaked:
+			if pm.valid && pm.version == p.Version() {
+				continue
+			}
+			pm.setup(r.gs, p.Size())
+			pm.upload(r.gs, p.Images())
+		case light.ProbeStatic:
+			if pm.valid && pm.version == p.Version() {
+				continue
+			}
+			fallthrough
+		default:
+			err := r.renderProbe(scene, p, pm)
+			if err != nil {
+				return err
+			}
+		}
+		pm.version = p.Version()
+		pm.valid = true
+	}
+	return nil
+}
+
+// findProbes appends the visible reflection probes in the specified node and its descendants to the list of probes.
+func (r *Renderer) findProbes(inode core.INode) {
+
+	if !inode.Visible() {
+		return
+	}
+	if p, ok := inode.(*light.ReflectionProbe); ok {
+		r.probes = append(r.probes, p)
+	}
+	for _, ichild := range inode.Children() {
+		r.findProbes(ichild)
+	}
+}
+
+// probeMap returns the cube map of the specified probe, creating it if necessary.
+func (r *Renderer) probeMap(p *light.ReflectionProbe) *probeMap {
+
+	pm, ok := r.probeMaps[p]
+	if !ok {
+		pm = new(probeMap)
+		r.probeMaps[p] = pm
+	}
+	return pm
+}
+
+// renderProbe renders the scene around the specified probe to the faces of its cube map.
+// The screen-space effects are disabled and the reflective materials don't reflect other probes.
+// The scene is classified in the probe frame lists, so the lists of the frame are kept.
+func (r *Renderer) renderProbe(scene core.INode, p *light.ReflectionProbe, pm *probeMap) error {
+
+	pm.setup(r.gs, p.Size())
+
+	// Save the frame buffer, the viewport, the screen-space effects and the frame lists
+	fb := r.gs.Framebuffer()
+	vx, vy, vw, vh := r.gs.GetViewport()
+	hdr, ssao, oit := r.hdr, r.ssao, r.oit
+	r.hdr, r.ssao, r.oit = false, false, false
+	r.probePass = true
+	f := r.frame
+	if r.probeFrame == nil {
+		r.probeFrame = newFrame()
+	}
+	r.frame = r.probeFrame
+
+	var cam probeCamera
+	cam.proj.MakePerspective(90, 1, p.Near(), p.Far())
+	var err error
+	r.gs.BindFramebuffer(pm.fbo)
+	r.gs.Viewport(0, 0, int32(pm.size), int32(pm.size))
+	for face := 0; face < 6 && err == nil; face++ {
+		var rot math32.Matrix4
+		var dir math32.Vector3
+		dir.AddVectors(&pm.position, &probeFaceDirs[face][0])
+		rot.Identity().LookAt(&pm.position, &dir, &probeFaceDirs[face][1])
+		rot.SetPosition(&pm.position)
+		cam.view.GetInverse(&rot)
+		r.gs.FramebufferTexture2D(gls.COLOR_ATTACHMENT0, uint(gls.TEXTURE_CUBE_MAP_POSITIVE_X+face), pm.tex)
+		r.gs.DepthMask(true)
+		r.gs.Clear(gls.COLOR_BUFFER_BIT | gls.DEPTH_BUFFER_BIT)
+		err = r.Render(scene, &cam)
+	}
+
+	// Restore the frame buffer, the viewport, the screen-space effects and the frame lists
+	r.frame = f
+	r.probePass = false
+	r.hdr, r.ssao, r.oit = hdr, ssao, oit
+	r.gs.BindFramebuffer(fb)
+	r.gs.Viewport(vx, vy, vw, vh)
+	if err != nil {
+		return err
+	}
+
+	// Generate the mipmaps used for the reflections of rough surfaces
+	r.gs.BindTexture(gls.TEXTURE_CUBE_MAP, pm.tex)
+	r.gs.GenerateMipmap(gls.TEXTURE_CUBE_MAP)
+	r.gs.BindTexture(gls.TEXTURE_CUBE_MAP, 0)
+	return nil
+}
+
+// nearestProbe returns the cube map of the probe nearest to the specified graphic whose radius
+// includes it, or nil if there is none or if the material doesn't reflect the environment.
+func (r *Renderer) nearestProbe(mat *material.Material, gr *graphic.Graphic) *probeMap {
+
+	if r.probePass || len(r.probes) == 0 || mat.EnvMapIntensity() <= 0 {
+		return nil
+	}
+	var pos math32.Vector3
+	gr.WorldPosition(&pos)
+	var nearest *probeMap
+	var minDist float32
+	for _, p := range r.probes {
+		pm := r.probeMaps[p]
+		if !pm.valid {
+			continue
+		}
+		dist := pos.DistanceTo(&pm.position)
+		if radius := p.Radius(); radius > 0 && dist > radius {
+			continue
+		}
+		if nearest == nil || dist < minDist {
+			nearest = pm
+			minDist = dist
+		}
+	}
+	return nearest
+}
+
+// envMapSetup binds the cube map of the specified probe to the texture unit following
+// the material textures, the shadow maps and the ambient occlusion and transfers its uniforms.
+func (r *Renderer) envMapSetup(pm *probeMap, mat *material.Material) {
+
+	unit := r.texUnits + r.dirShadows + 1
+	r.gs.ActiveTexture(uint32(gls.TEXTURE0 + unit))
+	r.gs.BindTexture(gls.TEXTURE_CUBE_MAP, pm.tex)
+	r.gs.Uniform1i(r.uniEnvMap.Location(r.gs), int32(unit))
+	params := [4]float32{mat.EnvMapIntensity(), float32(pm.levels - 1), 0, 0}
+	r.gs.Uniform4fv(r.uniEnvMapParams.Location(r.gs), 1, &params[0])
+}
+
+// BakeProbe renders the cube map of the specified probe in the specified scene and returns
+// the images of its faces, in the order of light.ProbeFaceSuffixes, which can be used to
+// create baked probes which don't need to be rendered. The probe doesn't need to be in the scene.
+// It must be called in the goroutine which owns the OpenGL context.
+func (r *Renderer) BakeProbe(scene core.INode, p *light.ReflectionProbe) ([6]*image.RGBA, error) {
+
+	var images [6]*image.RGBA
+	r.checkContext()
+	scene.UpdateMatrixWorld()
+	p.UpdateMatrixWorld()
+	pm := r.probeMap(p)
+	p.WorldPosition(&pm.position)
+	err := r.renderProbe(scene, p, pm)
+	if err == nil {
+		// The rows are read from the bottom of the faces
+		fb := r.gs.Framebuffer()
+		r.gs.BindFramebuffer(pm.fbo)
+		stride := pm.size * 4
+		for face := range images {
+			r.gs.FramebufferTexture2D(gls.COLOR_ATTACHMENT0, uint(gls.TEXTURE_CUBE_MAP_POSITIVE_X+face), pm.tex)
+			pix := r.gs.ReadPixels(0, 0, pm.size, pm.size, gls.RGBA, gls.UNSIGNED_BYTE)
+			img := image.NewRGBA(image.Rect(0, 0, pm.size, pm.size))
+			for y := 0; y < pm.size; y++ {
+				copy(img.Pix[y*img.Stride:y*img.Stride+stride], pix[(pm.size-1-y)*stride:])
+			}
+			images[face] = img
+		}
+		r.gs.BindFramebuffer(fb)
+	}
+	return images, err
+}
+
+// SaveProbe renders the cube map of the specified probe in the specified scene and saves its faces
+// as PNG image files named with the specified prefix and light.ProbeFaceSuffixes, which can be
+// loaded with light.NewReflectionProbeFromFiles. It must be called in the goroutine which owns the OpenGL context.
+func (r *Renderer) SaveProbe(scene core.INode, p *light.ReflectionProbe, dirAndPrefix string) error {
+
+	images, err := r.BakeProbe(scene, p)
+	if err != nil {
+		return err
+	}
+	for i, img := range images {
+		err = savePNG(dirAndPrefix+light.ProbeFaceSuffixes[i]+".png", img)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// savePNG saves the specified image as a PNG image file.
+func savePNG(filename string, img image.Image) error {
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	b := bufio.NewWriter(f)
+	err = png.Encode(b, img)
+	if err != nil {
+		return err
+	}
+	return b.Flush()
+}
+
+// setup creates the cube map and the frame buffer or resizes the cube map if the size changed.
+func (pm *probeMap) setup(gs *gls.GLS, size int) {
+
+	if size <= 0 {
+		size = 1
+	}
+	if pm.fbo != 0 && size == pm.size {
+		return
+	}
+	pm.size = size
+	pm.levels = 1
+	for s := size; s > 1; s /= 2 {
+		pm.levels++
+	}
+	if pm.fbo == 0 {
+		pm.fbo = gs.GenFramebuffer()
+		pm.tex = gs.GenTexture()
+		pm.depthRbo = gs.GenRenderbuffer()
+	}
+
+	gs.BindTexture(gls.TEXTURE_CUBE_MAP, pm.tex)
+	for face := 0; face < 6; face++ {
+		gs.TexImage2D(uint32(gls.TEXTURE_CUBE_MAP_POSITIVE_X+face), 0, gls.RGBA8, int32(size), int32(size), gls.RGBA, gls.UNSIGNED_BYTE, nil)
+	}
+	gs.TexParameteri(gls.TEXTURE_CUBE_MAP, gls.TEXTURE_WRAP_S, gls.CLAMP_TO_EDGE)
+	gs.TexParameteri(gls.TEXTURE_CUBE_MAP, gls.TEXTURE_WRAP_T, gls.CLAMP_TO_EDGE)
+	gs.TexParameteri(gls.TEXTURE_CUBE_MAP, gls.TEXTURE_WRAP_R, gls.CLAMP_TO_EDGE)
+	gs.TexParameteri(gls.TEXTURE_CUBE_MAP, gls.TEXTURE_MIN_FILTER, gls.LINEAR_MIPMAP_LINEAR)
+	gs.TexParameteri(gls.TEXTURE_CUBE_MAP, gls.TEXTURE_MAG_FILTER, gls.LINEAR)
+	gs.GenerateMipmap(gls.TEXTURE_CUBE_MAP)
+	gs.BindTexture(gls.TEXTURE_CUBE_MAP, 0)
+	gs.BindRenderbuffer(pm.depthRbo)
+	gs.RenderbufferStorage(gls.DEPTH_COMPONENT24, size, size)
+	gs.BindRenderbuffer(0)
+
+	fb := gs.Framebuffer()
+	gs.BindFramebuffer(pm.fbo)
+	gs.FramebufferTexture2D(gls.COLOR_ATTACHMENT0, gls.TEXTURE_CUBE_MAP_POSITIVE_X, pm.tex)
+	gs.FramebufferRenderbuffer(gls.DEPTH_ATTACHMENT, pm.depthRbo)
+	if gs.CheckFramebufferStatus() != gls.FRAMEBUFFER_COMPLETE {
+		log.Error("Reflection probe frame buffer is incomplete")
+	}
+	gs.BindFramebuffer(fb)
+}
+
+// upload transfers the specified images, with the rows from the top of the faces, to the cube map.
+func (pm *probeMap) upload(gs *gls.GLS, images [6]*image.RGBA) {
+
+	stride := pm.size * 4
+	pix := make([]byte, stride*pm.size)
+	gs.BindTexture(gls.TEXTURE_CUBE_MAP, pm.tex)
+	for face, img := range images {
+		if img == nil {
+			continue
+		}
+		for y := 0; y < pm.size; y++ {
+			copy(pix[y*stride:(y+1)*stride], img.Pix[(pm.size-1-y)*img.Stride:])
+		}
+		gs.TexImage2D(uint32(gls.TEXTURE_CUBE_MAP_POSITIVE_X+face), 0, gls.RGBA8, int32(pm.size), int32(pm.size), gls.RGBA, gls.UNSIGNED_BYTE, pix)
+	}
+	gs.GenerateMipmap(gls.TEXTURE_CUBE_MAP)
+	gs.BindTexture(gls.TEXTURE_CUBE_MAP, 0)
+}
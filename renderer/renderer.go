@@ -24,24 +24,104 @@ var log = logger.New("RENDERER", logger.Default)
 
 // Renderer renders a scene containing 3D objects and/or 2D GUI elements.
 type Renderer struct {
-	Shaman                      // Embedded shader manager
-	gs          *gls.GLS        // Reference to OpenGL state
-	rinfo       core.RenderInfo // Preallocated Render info
-	specs       ShaderSpecs     // Preallocated Shader specs
-	sortObjects bool            // Flag indicating whether objects should be sorted before rendering
-	stats       Stats           // Renderer statistics
-
-	// Populated each frame
-	ambLights    []*light.Ambient           // Ambient lights in the scene
-	dirLights    []*light.Directional       // Directional lights in the scene
-	pointLights  []*light.Point             // Point lights in the scene
-	spotLights   []*light.Spot              // Spot lights in the scene
-	others       []core.INode               // Other nodes (audio, players, etc)
-	graphics     []*graphic.Graphic         // Graphics to be rendered
-	grmatsOpaque []*graphic.GraphicMaterial // Opaque graphic materials to be rendered
-	grmatsTransp []*graphic.GraphicMaterial // Transparent graphic materials to be rendered
-	zLayers      map[int][]gui.IPanel       // All IPanels to be rendered organized by Z-layer
-	zLayerKeys   []int                      // Z-layers being used (initially in no particular order, sorted later)
+	Shaman                     // Embedded shader manager
+	*frame                     // Lists of the scene being rendered
+	gs          *gls.GLS       // Reference to OpenGL state
+	specs       ShaderSpecs    // Preallocated Shader specs
+	sortObjects bool           // Flag indicating whether objects should be sorted before rendering
+	gen         uint32         // Generation of the OpenGL context of the frame buffers
+	texUnits    int            // Number of texture units used by the current material
+	graphs      [2]*FrameGraph // Frame graphs of the passes of the frames and of the probe faces
+	probeFrame  *frame         // Lists of the scene rendered to the faces of the probe cube maps
+
+	// Shadows
+	shadowMaps      map[*light.Directional]*shadowMap // Shadow maps of directional lights
+	shadowSpecs     ShaderSpecs                       // Preallocated Shader specs for rendering shadow maps
+	uniShadowMap    gls.Uniform                       // Shadow map sampler uniform
+	uniShadowMatrix gls.Uniform                       // Shadow cascade matrices uniform
+	uniShadowSplits gls.Uniform                       // Shadow cascade splits uniform
+	uniShadowParams gls.Uniform                       // Shadow parameters uniform
+
+	// Order independent transparency
+	oit        bool        // Render transparent 3D objects with order independent transparency
+	oitPass    bool        // Rendering the OIT accumulation pass
+	oitBuffers *oitBuffers // Vertex array and uniforms of the OIT composite pass
+	oitSpecs   ShaderSpecs // Preallocated Shader specs for the OIT composite pass
+
+	// Screen-space ambient occlusion
+	ssao          bool         // Apply screen-space ambient occlusion to the ambient light of the opaque 3D objects
+	ssaoQuality   SSAOQuality  // Quality preset of the ambient occlusion
+	ssaoRadius    float32      // Radius of the sampled hemisphere in camera coordinates
+	ssaoIntensity float32      // Exponent applied to the ambient occlusion
+	ssaoPass      bool         // Rendering the opaque objects with the ambient occlusion
+	ssaoBuffers   *ssaoBuffers // Noise texture, kernel and uniforms of the ambient occlusion passes
+	ssaoSpecs     ShaderSpecs  // Preallocated Shader specs for the ambient occlusion passes
+
+	// High dynamic range
+	hdr            bool        // Render the 3D objects to a floating point frame buffer
+	toneMapping    ToneMapping // Operator which maps the exposed HDR colors to the displayable range
+	bloom          bool        // Add the blurred bright HDR colors to the colors
+	bloomThreshold float32     // Exposed color component above which the colors glow
+	bloomIntensity float32     // Factor applied to the glow
+	hdrBuffers     *hdrBuffers // Vertex array, uniforms and metered luminances of the HDR passes
+	hdrSpecs       ShaderSpecs // Preallocated Shader specs for the HDR passes
+
+	// Reflection probes
+	probes          []*light.ReflectionProbe             // Visible reflection probes in the scene
+	probeMaps       map[*light.ReflectionProbe]*probeMap // Cube maps of the reflection probes
+	probePass       bool                                 // Rendering the cube map of a reflection probe
+	uniEnvMap       gls.Uniform                          // Environment cube map sampler uniform
+	uniEnvMapParams gls.Uniform                          // Environment map parameters uniform
+
+	// Uniform blocks updated once per frame
+	uboCamera      *gls.UBO // Camera matrices
+	uboAmbLights   *gls.UBO // Ambient lights
+	uboDirLights   *gls.UBO // Directional lights
+	uboPointLights *gls.UBO // Point lights
+	uboSpotLights  *gls.UBO // Spot lights
+
+	// Picking
+	pickBuffers *pickBuffers // Frame buffer used to render the pick ids
+	pickSpecs   ShaderSpecs  // Preallocated Shader specs for rendering the pick ids
+
+	// Selection outlines
+	outlines        map[core.INode]bool // Nodes whose graphics are outlined
+	outlineColor    math32.Color4       // Color of the outlines
+	outlineWidth    float32             // Width of the outlines in pixels
+	outlineSpecs    ShaderSpecs         // Preallocated Shader specs for rendering the outlines
+	uniOutlineColor gls.Uniform         // Outline color uniform
+	uniOutlineWidth gls.Uniform         // Outline width uniform
+
+	// Render hooks
+	hooks  []renderHook // Hooks called at the render stages in the order they were added
+	hookID int          // Identifier of the last added hook
+}
+
+// frame contains the lists populated each time a scene is rendered. The cube maps of the
+// reflection probes are rendered with other lists, so they don't clobber the lists of the frame.
+type frame struct {
+	rinfo         core.RenderInfo            // Preallocated Render info
+	stats         Stats                      // Renderer statistics
+	cam           camera.ICamera             // Camera of the frame
+	layerMask     uint32                     // Layers rendered by the camera
+	ambLights     []*light.Ambient           // Ambient lights in the scene
+	dirLights     []*light.Directional       // Directional lights in the scene
+	dirShadows    int                        // Number of directional lights which cast shadows
+	pointLights   []*light.Point             // Point lights in the scene
+	spotLights    []*light.Spot              // Spot lights in the scene
+	fog           *light.Fog                 // Fog in the scene (nil if none)
+	others        []core.INode               // Other nodes (audio, players, etc)
+	graphics      []*graphic.Graphic         // Graphics to be rendered
+	casters       []*graphic.Graphic         // Graphics which cast shadows
+	casterBoxes   []math32.Box3              // Bounding boxes of the shadow casters in world coordinates
+	shadowTargets []*Target                  // Shadow map targets of the directional lights which cast shadows
+	grmatsOpaque  []*graphic.GraphicMaterial // Opaque graphic materials to be rendered
+	grmatsTransp  []*graphic.GraphicMaterial // Transparent graphic materials to be rendered
+	opaque3D      int                        // Number of opaque graphic materials which are not panels
+	transp3D      int                        // Number of transparent graphic materials which are not panels
+	zLayers       map[int][]gui.IPanel       // All IPanels to be rendered organized by Z-layer
+	zLayerKeys    []int                      // Z-layers being used (initially in no particular order, sorted later)
+	outlined      []*graphic.Graphic         // Rendered graphics which are outlined in the current frame
 }
 
 // Stats describes how many objects of each type are being rendered.
@@ -51,6 +131,7 @@ type Stats struct {
 	Lights      int // Number of lights rendered
 	Panels      int // Number of GUI panels rendered
 	Others      int // Number of other objects rendered
+	Skipped     int // Number of graphics skipped by their pre-render callbacks
 }
 
 // NewRenderer creates and returns a pointer to a new Renderer.
@@ -60,22 +141,51 @@ func NewRenderer(gs *gls.GLS) *Renderer {
 	r.gs = gs
 	r.Shaman.Init(gs)
 	r.sortObjects = true
+	r.frame = newFrame()
 
-	r.ambLights = make([]*light.Ambient, 0)
-	r.dirLights = make([]*light.Directional, 0)
-	r.pointLights = make([]*light.Point, 0)
-	r.spotLights = make([]*light.Spot, 0)
-	r.others = make([]core.INode, 0)
-	r.graphics = make([]*graphic.Graphic, 0)
-	r.grmatsOpaque = make([]*graphic.GraphicMaterial, 0)
-	r.grmatsTransp = make([]*graphic.GraphicMaterial, 0)
-	r.zLayers = make(map[int][]gui.IPanel)
-	r.zLayers[0] = make([]gui.IPanel, 0)
-	r.zLayerKeys = append(r.zLayerKeys, 0)
+	r.shadowMaps = make(map[*light.Directional]*shadowMap)
+	r.uniShadowMap.Init("DirShadowMap")
+	r.uniShadowMatrix.Init("DirShadowMatrix")
+	r.uniShadowSplits.Init("DirShadowSplits")
+	r.uniShadowParams.Init("DirShadowParams")
+
+	r.probeMaps = make(map[*light.ReflectionProbe]*probeMap)
+	r.uniEnvMap.Init("EnvMap")
+	r.uniEnvMapParams.Init("EnvMapParams")
+
+	r.ssaoQuality = SSAOMedium
+	r.ssaoRadius = 0.5
+	r.ssaoIntensity = 1
+
+	r.bloomThreshold = 1
+	r.bloomIntensity = 0.5
+
+	r.outlineColor = math32.Color4{R: 1, G: 0.6, B: 0, A: 1}
+	r.outlineWidth = 2
+	r.uniOutlineColor.Init("OutlineColor")
+	r.uniOutlineWidth.Init("OutlineWidth")
+
+	r.initBlocks()
 
 	return r
 }
 
+// checkContext discards the probe cube maps and the frame buffers created in a previous generation
+// of the OpenGL context, which are invalid, so they are recreated when needed. The frame graphs
+// discard their textures and frame buffers themselves.
+func (r *Renderer) checkContext() {
+
+	if r.gen == r.gs.Generation() {
+		return
+	}
+	r.gen = r.gs.Generation()
+	r.probeMaps = make(map[*light.ReflectionProbe]*probeMap)
+	r.oitBuffers = nil
+	r.ssaoBuffers = nil
+	r.hdrBuffers = nil
+	r.pickBuffers = nil
+}
+
 // Stats returns a copy of the statistics for the last frame.
 // Should be called after the frame was rendered.
 func (r *Renderer) Stats() Stats {
@@ -96,29 +206,51 @@ func (r *Renderer) ObjectSorting() bool {
 }
 
 // Render renders the specified scene using the specified camera. Returns an an error.
+// The passes of the frame, from the cube maps of the reflection probes to the GUI panels, are
+// declared in a frame graph of the renderer, which orders them and allocates and aliases the
+// textures of the shadow maps and of the SSAO, OIT and HDR passes.
 func (r *Renderer) Render(scene core.INode, cam camera.ICamera) error {
 
+	r.checkContext()
+
 	// Updates world matrices of all scene nodes
 	scene.UpdateMatrixWorld()
 
+	// Classify the scene and declare the passes which render it
+	r.classify(scene, cam)
+	fg := r.frameGraph()
+	fg.Reset()
+	r.declarePasses(fg, scene)
+	return fg.Execute()
+}
+
+// frameGraph returns the frame graph of the passes of the frame or, while the cube maps of the
+// reflection probes are rendered, the frame graph of the passes of the probe faces.
+func (r *Renderer) frameGraph() *FrameGraph {
+
+	i := 0
+	if r.probePass {
+		i = 1
+	}
+	if r.graphs[i] == nil {
+		r.graphs[i] = NewFrameGraph(r.gs)
+	}
+	return r.graphs[i]
+}
+
+// classify classifies the specified scene for the specified camera, culling the renderable IGraphics which
+// are fully outside of its frustum, and compiles the lists of opaque and transparent graphic materials.
+func (r *Renderer) classify(scene core.INode, cam camera.ICamera) {
+
 	// Build RenderInfo
+	r.cam = cam
 	cam.ViewMatrix(&r.rinfo.ViewMatrix)
 	cam.ProjMatrix(&r.rinfo.ProjMatrix)
+	r.layerMask = cameraLayerMask(cam)
 
 	// Clear stats and scene arrays
 	r.stats = Stats{}
-	r.ambLights = r.ambLights[0:0]
-	r.dirLights = r.dirLights[0:0]
-	r.pointLights = r.pointLights[0:0]
-	r.spotLights = r.spotLights[0:0]
-	r.others = r.others[0:0]
-	r.graphics = r.graphics[0:0]
-	r.grmatsOpaque = r.grmatsOpaque[0:0]
-	r.grmatsTransp = r.grmatsTransp[0:0]
-	r.zLayers = make(map[int][]gui.IPanel)
-	r.zLayers[0] = make([]gui.IPanel, 0)
-	r.zLayerKeys = r.zLayerKeys[0:1]
-	r.zLayerKeys[0] = 0
+	r.clearScene()
 
 	// Prepare for frustum culling
 	var proj math32.Matrix4
@@ -127,17 +259,10 @@ func (r *Renderer) Render(scene core.INode, cam camera.ICamera) error {
 
 	// Classify scene and all scene nodes, culling renderable IGraphics which are fully outside of the camera frustum
 	r.classifyAndCull(scene, frustum, 0)
+	r.sortDirLights()
 
-	// Set light counts in shader specs
-	r.specs.AmbientLightsMax = len(r.ambLights)
-	r.specs.DirLightsMax = len(r.dirLights)
-	r.specs.PointLightsMax = len(r.pointLights)
-	r.specs.SpotLightsMax = len(r.spotLights)
-
-	// Pre-calculate MV and MVP matrices and compile initial lists of opaque and transparent graphic materials
+	// Compile initial lists of opaque and transparent graphic materials
 	for _, gr := range r.graphics {
-		// Calculate MV and MVP matrices for all non-GUI graphics to be rendered
-		gr.CalculateMatrices(r.gs, &r.rinfo)
 		// Append all graphic materials of this graphic to lists of graphic materials to be rendered
 		materials := gr.Materials()
 		for i := range materials {
@@ -150,16 +275,13 @@ func (r *Renderer) Render(scene core.INode, cam camera.ICamera) error {
 		}
 	}
 
-	// TODO: If both GraphicMaterials belong to same Graphic we might want to keep their relative order...
-	// Z-sort graphic materials back to front
-	if r.sortObjects {
-		zSort(r.grmatsOpaque)
-		zSort(r.grmatsTransp)
-	}
-
 	// Sort zLayers back to front
 	sort.Ints(r.zLayerKeys)
 
+	// Number of opaque and transparent graphic materials which are not panels
+	r.opaque3D = len(r.grmatsOpaque)
+	r.transp3D = len(r.grmatsTransp)
+
 	// Iterate over all panels from back to front, setting Z and adding graphic materials to grmatsTransp/grmatsOpaque
 	const deltaZ = 0.00001
 	panZ := float32(-1 + float32(r.stats.Panels)*deltaZ)
@@ -177,26 +299,209 @@ func (r *Renderer) Render(scene core.INode, cam camera.ICamera) error {
 			}
 		}
 	}
+}
+
+// declarePasses declares the passes which render the classified scene in the specified frame graph.
+func (r *Renderer) declarePasses(fg *FrameGraph, scene core.INode) {
+
+	// The reflection probes render the scene, which changes the matrices of the graphics,
+	// the shadow maps and the uniform blocks, so they are updated before the other passes
+	probes := fg.Import("probes", 0)
+	if !r.probePass {
+		fg.AddPass("probes", func(pc *PassContext) error {
+			return r.updateProbes(scene)
+		}).Write(probes)
+	}
+	inputs := []*Target{probes}
+	inputs = append(inputs, r.declareShadowPasses(fg, probes)...)
+
+	// The matrices of the graphics are calculated for the camera after the shadow passes,
+	// which calculate them for the lights
+	prepared := fg.Import("scene", 0)
+	fg.AddPass("prepare", func(pc *PassContext) error {
+		r.prepare()
+		return nil
+	}).Read(inputs...).Write(prepared)
+	inputs = append(inputs, prepared)
+	var ao *Target
+	if r.ssao && r.opaque3D > 0 && len(r.ambLights) > 0 {
+		ao = r.declareSSAOPasses(fg, prepared)
+		inputs = append(inputs, ao)
+	}
+
+	// With HDR enabled the 3D objects are rendered to the HDR targets and all the panels are
+	// rendered after they are resolved, so the GUI is not affected by the exposure and tone mapping
+	hdr := r.hdr
+	frame := fg.Import("frame", 0)
+	var color, depth *Target
+	opaque := fg.AddPass("opaque", func(pc *PassContext) error {
+		return r.renderOpaque(pc, ao, hdr)
+	}).Read(inputs...)
+	if hdr {
+		color = fg.AddTarget("hdr color", TargetDesc{Format: gls.RGBA16F})
+		depth = fg.AddTarget("hdr depth", TargetDesc{Format: gls.DEPTH24_STENCIL8})
+		opaque.Write(color, depth)
+	} else {
+		opaque.Write(frame)
+	}
+
+	// The transparent 3D objects are accumulated in the OIT targets, tested against
+	// the depth of the opaque objects, if order independent transparency is enabled
+	var accum, weight *Target
+	if r.oit && r.transp3D > 0 {
+		accum = fg.AddTarget("oit accum", TargetDesc{Format: gls.RGBA16F})
+		weight = fg.AddTarget("oit weight", TargetDesc{Format: gls.R16F})
+		p := fg.AddPass("oit accum", func(pc *PassContext) error {
+			return r.renderOITAccum(pc, !hdr)
+		}).Read(inputs...).Write(accum, weight)
+		if hdr {
+			depth = p.Modify(depth)
+		} else {
+			p.Read(frame).Write(fg.AddTarget("oit depth", TargetDesc{Format: gls.DEPTH24_STENCIL8}))
+		}
+	}
+	transp := fg.AddPass("transparent", func(pc *PassContext) error {
+		return r.renderTransparent(accum, weight)
+	}).Read(inputs...)
+	if accum != nil {
+		transp.Read(accum, weight)
+	}
+	if hdr {
+		color = transp.Modify(color)
+		depth = transp.Modify(depth)
+		frame = r.declareHDRPasses(fg, frame, color, depth)
+	} else {
+		frame = transp.Modify(frame)
+	}
+
+	// The outlines and the panels are rendered over the 3D objects
+	fg.AddPass("overlay", func(pc *PassContext) error {
+		return r.renderOverlay(hdr)
+	}).Read(inputs...).Read(frame)
+}
+
+// prepare sets the light counts of the shader specs, transfers the camera matrices and the lights
+// to their uniform blocks and calculates the matrices of the graphics, sorting the 3D objects.
+func (r *Renderer) prepare() {
+
+	// Set light counts in shader specs
+	r.specs.AmbientLightsMax = len(r.ambLights)
+	r.specs.DirLightsMax = len(r.dirLights)
+	r.specs.DirShadowsMax = r.dirShadows
+	r.specs.PointLightsMax = len(r.pointLights)
+	r.specs.SpotLightsMax = len(r.spotLights)
+
+	// Transfer the camera matrices and the lights to their uniform blocks
+	r.updateBlocks()
+
+	// Calculate MV and MVP matrices for all non-GUI graphics to be rendered
+	for _, gr := range r.graphics {
+		gr.CalculateMatrices(r.gs, &r.rinfo)
+	}
+
+	// TODO: If both GraphicMaterials belong to same Graphic we might want to keep their relative order...
+	// Z-sort graphic materials back to front
+	if r.sortObjects {
+		zSort(r.grmatsOpaque[:r.opaque3D])
+		zSort(r.grmatsTransp[:r.transp3D])
+	}
+}
+
+// renderOpaque renders the opaque panels, unless HDR is enabled, and the opaque 3D objects front to back,
+// with the specified ambient occlusion of the 3D objects if not nil, calling the render hooks of the stages
+// before and after them. With HDR enabled the contents of the frame buffer are first copied to the HDR targets.
+func (r *Renderer) renderOpaque(pc *PassContext, ao *Target, hdr bool) error {
+
+	var panels []*graphic.GraphicMaterial
+	if hdr {
+		r.beginHDR(pc)
+	} else {
+		panels = r.grmatsOpaque[r.opaque3D:]
+	}
+	err := r.runHooks(BeforeOpaque)
+	if err != nil {
+		return err
+	}
+
+	// Render the opaque panels front to back
+	for i := len(panels) - 1; i >= 0; i-- {
+		err := r.renderGraphicMaterial(panels[i])
+		if err != nil {
+			return err
+		}
+	}
 
 	// Render opaque objects front to back
-	for i := len(r.grmatsOpaque) - 1; i >= 0; i-- {
-		err := r.renderGraphicMaterial(r.grmatsOpaque[i])
+	if ao != nil {
+		r.beginSSAO(ao)
+	}
+	opaque := r.grmatsOpaque[:r.opaque3D]
+	for i := len(opaque) - 1; i >= 0; i-- {
+		err := r.renderGraphicMaterial(opaque[i])
+		if err != nil {
+			r.ssaoPass = false
+			return err
+		}
+	}
+	r.ssaoPass = false
+	err = r.runHooks(AfterOpaque)
+	if err != nil {
+		return err
+	}
+	return r.runHooks(BeforeTransparent)
+}
+
+// renderTransparent renders the transparent 3D objects back to front or, if the specified
+// OIT targets are not nil, composites their accumulated colors over the opaque objects.
+func (r *Renderer) renderTransparent(accum, weight *Target) error {
+
+	if accum != nil {
+		return r.compositeOIT(accum, weight)
+	}
+	for _, grmat := range r.grmatsTransp[:r.transp3D] {
+		err := r.renderGraphicMaterial(grmat)
 		if err != nil {
 			return err
 		}
 	}
+	return nil
+}
+
+// renderOverlay renders the outlines, the opaque panels if HDR is enabled and the transparent panels
+// and the other nodes, calling the render hooks of the stage at the end of the frame.
+func (r *Renderer) renderOverlay(hdr bool) error {
+
+	err := r.renderOutlines()
+	if err != nil {
+		return err
+	}
 
-	// Render transparent objects back to front
-	for _, grmat := range r.grmatsTransp {
+	// Render the remaining opaque panels front to back and the transparent panels back to front
+	if hdr {
+		panels := r.grmatsOpaque[r.opaque3D:]
+		for i := len(panels) - 1; i >= 0; i-- {
+			err := r.renderGraphicMaterial(panels[i])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, grmat := range r.grmatsTransp[r.transp3D:] {
 		err := r.renderGraphicMaterial(grmat)
 		if err != nil {
 			return err
 		}
 	}
+	err = r.runHooks(AfterGUI)
+	if err != nil {
+		return err
+	}
 
 	// Render other nodes (audio players, etc)
-	for _, inode := range r.others {
-		inode.Render(r.gs)
+	if !r.probePass {
+		for _, inode := range r.others {
+			inode.Render(r.gs)
+		}
 	}
 
 	// Enable depth mask so that clearing the depth buffer works
@@ -207,6 +512,43 @@ func (r *Renderer) Render(scene core.INode, cam camera.ICamera) error {
 	return nil
 }
 
+// newFrame creates and returns a pointer to new empty frame lists.
+func newFrame() *frame {
+
+	f := new(frame)
+	f.ambLights = make([]*light.Ambient, 0)
+	f.dirLights = make([]*light.Directional, 0)
+	f.pointLights = make([]*light.Point, 0)
+	f.spotLights = make([]*light.Spot, 0)
+	f.others = make([]core.INode, 0)
+	f.graphics = make([]*graphic.Graphic, 0)
+	f.grmatsOpaque = make([]*graphic.GraphicMaterial, 0)
+	f.grmatsTransp = make([]*graphic.GraphicMaterial, 0)
+	f.zLayers = make(map[int][]gui.IPanel)
+	f.zLayers[0] = make([]gui.IPanel, 0)
+	f.zLayerKeys = append(f.zLayerKeys, 0)
+	return f
+}
+
+// clearScene clears the scene arrays populated by classifyAndCull.
+func (r *Renderer) clearScene() {
+
+	r.ambLights = r.ambLights[0:0]
+	r.dirLights = r.dirLights[0:0]
+	r.pointLights = r.pointLights[0:0]
+	r.spotLights = r.spotLights[0:0]
+	r.fog = nil
+	r.others = r.others[0:0]
+	r.graphics = r.graphics[0:0]
+	r.casters = r.casters[0:0]
+	r.grmatsOpaque = r.grmatsOpaque[0:0]
+	r.grmatsTransp = r.grmatsTransp[0:0]
+	r.zLayers = make(map[int][]gui.IPanel)
+	r.zLayers[0] = make([]gui.IPanel, 0)
+	r.zLayerKeys = r.zLayerKeys[0:1]
+	r.zLayerKeys[0] = 0
+}
+
 // classifyAndCull classifies the provided INode and all of its descendents.
 // It ignores (culls) renderable IGraphics which are fully outside of the specified frustum.
 func (r *Renderer) classifyAndCull(inode core.INode, frustum *math32.Frustum, zLayer int) {
@@ -217,8 +559,15 @@ func (r *Renderer) classifyAndCull(inode core.INode, frustum *math32.Frustum, zL
 	}
 	// If node is an IPanel append it to appropriate list
 	if ipan, ok := inode.(gui.IPanel); ok {
+		// The panels are not rendered to the cube maps of the reflection probes
+		if r.probePass {
+			return
+		}
+		if layer, ok := ipan.Layer(); ok {
+			zLayer = int(layer)
+		}
 		zLayer += ipan.ZLayerDelta()
-		if ipan.Renderable() {
+		if ipan.Renderable() && ipan.GetNode().InLayers(r.layerMask) {
 			// TODO cull panels
 			_, ok := r.zLayers[zLayer]
 			if !ok {
@@ -230,26 +579,30 @@ func (r *Renderer) classifyAndCull(inode core.INode, frustum *math32.Frustum, zL
 		}
 		// Check if node is an IGraphic
 	} else if igr, ok := inode.(graphic.IGraphic); ok {
-		if igr.Renderable() {
+		if igr.Renderable() && igr.GetNode().InLayers(r.layerMask) {
 			gr := igr.GetGraphic()
+			// Shadow casters are not culled by the camera frustum
+			if gr.CastShadow() {
+				r.casters = append(r.casters, gr)
+			}
 			// Frustum culling
 			if igr.Cullable() {
 				mw := gr.MatrixWorld()
 				bb := igr.GetGeometry().BoundingBox()
 				bb.ApplyMatrix4(&mw)
 				if frustum.IntersectsBox(&bb) {
-					// Append graphic to list of graphics to be rendered
-					r.graphics = append(r.graphics, gr)
+					r.appendGraphic(igr)
 				}
 			} else {
-				// Append graphic to list of graphics to be rendered
-				r.graphics = append(r.graphics, gr)
+				r.appendGraphic(igr)
 			}
 		}
 		// Node is not a Graphic
 	} else {
-		// Check if node is a Light
-		if il, ok := inode.(light.ILight); ok {
+		// Check if node is a Fog or a Light
+		if fog, ok := inode.(*light.Fog); ok {
+			r.fog = fog
+		} else if il, ok := inode.(light.ILight); ok {
 			switch l := il.(type) {
 			case *light.Ambient:
 				r.ambLights = append(r.ambLights, l)
@@ -262,8 +615,8 @@ func (r *Renderer) classifyAndCull(inode core.INode, frustum *math32.Frustum, zL
 			default:
 				panic("Invalid light type")
 			}
-			// Other nodes
-		} else {
+			// Other nodes, except the reflection probes which are found before classifying
+		} else if _, ok := inode.(*light.ReflectionProbe); !ok {
 			r.others = append(r.others, inode)
 			r.stats.Others++
 		}
@@ -274,6 +627,31 @@ func (r *Renderer) classifyAndCull(inode core.INode, frustum *math32.Frustum, zL
 	}
 }
 
+// cameraLayerMask returns the layers rendered by the specified camera,
+// which are all the layers if it is not a *camera.Camera.
+func cameraLayerMask(cam camera.ICamera) uint32 {
+
+	if c, ok := cam.(*camera.Camera); ok {
+		return c.LayerMask()
+	}
+	return core.AllLayers
+}
+
+// appendGraphic appends the specified graphic and the graphics which draw its debug rendering
+// options to the list of graphics to be rendered, unless its pre-render callback returns false.
+func (r *Renderer) appendGraphic(igr graphic.IGraphic) {
+
+	gr := igr.GetGraphic()
+	if cb := gr.PreRender(); cb != nil && !cb(igr, &r.rinfo) {
+		r.stats.Skipped++
+		return
+	}
+	r.graphics = append(r.graphics, gr)
+	for _, idebug := range gr.DebugGraphics() {
+		r.graphics = append(r.graphics, idebug.GetGraphic())
+	}
+}
+
 // zSort sorts a list of graphic materials based on the user-specified render order
 // then based on their Z position relative to the camera, back to front.
 func zSort(grmats []*graphic.GraphicMaterial) {
@@ -309,12 +687,30 @@ func (r *Renderer) renderGraphicMaterial(grmat *graphic.GraphicMaterial) error {
 	r.specs.Defines.Add(&mat.ShaderDefines)
 	r.specs.Defines.Add(&geom.ShaderDefines)
 	r.specs.Defines.Add(&gr.ShaderDefines)
+	if r.oitPass {
+		r.specs.Defines.Set("OIT", "")
+	}
+	// Ambient occlusion is only applied to the ambient light of the opaque 3D objects
+	ssao := r.ssaoPass && mat.UseLights()&material.UseLightAmbient != 0
+	if ssao {
+		r.specs.Defines.Set("SSAO", "")
+	}
+	// Fog is only applied to materials lit by the scene lights
+	if r.fog != nil && mat.UseLights() != material.UseLightNone {
+		r.specs.Defines.Set("FOG", r.fog.ShaderDefine())
+	}
+	// Reflective materials reflect the cube map of the nearest reflection probe
+	probe := r.nearestProbe(mat, gr)
+	if probe != nil {
+		r.specs.Defines.Set("ENV_MAP", "")
+	}
 
 	// Set the shader specs for this material and set shader program
 	r.specs.Name = mat.Shader()
 	r.specs.ShaderUnique = mat.ShaderUnique()
 	r.specs.UseLights = mat.UseLights()
 	r.specs.MatTexturesMax = mat.TextureCount()
+	r.texUnits = mat.TextureUnits()
 
 	// Set active program and apply shader specs
 	_, err := r.Shaman.SetProgram(&r.specs)
@@ -322,36 +718,38 @@ func (r *Renderer) renderGraphicMaterial(grmat *graphic.GraphicMaterial) error {
 		return err
 	}
 
-	// Set up lights (transfer lights' uniforms)
+	// Set up lights. The lights are in uniform blocks transferred once per frame
 	if r.specs.UseLights != material.UseLightNone {
+		if r.fog != nil {
+			r.fog.RenderSetup(r.gs, &r.rinfo)
+		}
 		if r.specs.UseLights&material.UseLightAmbient != 0 {
-			for idx, l := range r.ambLights {
-				l.RenderSetup(r.gs, &r.rinfo, idx)
-				r.stats.Lights++
-			}
+			r.stats.Lights += len(r.ambLights)
 		}
 		if r.specs.UseLights&material.UseLightDirectional != 0 {
-			for idx, l := range r.dirLights {
-				l.RenderSetup(r.gs, &r.rinfo, idx)
-				r.stats.Lights++
-			}
+			r.stats.Lights += len(r.dirLights)
+			r.shadowSetup()
 		}
 		if r.specs.UseLights&material.UseLightPoint != 0 {
-			for idx, l := range r.pointLights {
-				l.RenderSetup(r.gs, &r.rinfo, idx)
-				r.stats.Lights++
-			}
+			r.stats.Lights += len(r.pointLights)
 		}
 		if r.specs.UseLights&material.UseLightSpot != 0 {
-			for idx, l := range r.spotLights {
-				l.RenderSetup(r.gs, &r.rinfo, idx)
-				r.stats.Lights++
-			}
+			r.stats.Lights += len(r.spotLights)
+		}
+		if ssao {
+			r.ssaoSetup()
 		}
 	}
+	if probe != nil {
+		r.envMapSetup(probe, mat)
+	}
 
 	// Render this graphic material
-	grmat.Render(r.gs, &r.rinfo)
+	if r.oitPass {
+		grmat.RenderOverride(r.gs, &r.rinfo, oitBlending)
+	} else {
+		grmat.Render(r.gs, &r.rinfo)
+	}
 
 	return nil
 }
@@ -1,9 +1,11 @@
 precision highp float;
 
 in vec3 Color;
-out vec4 FragColor;
+#include <oit_declaration>
 
 void main() {
 
     FragColor = vec4(Color, 1.0);
+
+    #include <oit_fragment>
 }
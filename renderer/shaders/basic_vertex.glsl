@@ -8,6 +8,8 @@ out vec3 Color;
 
 void main() {
 
+    #include <instance_vertex>
+
     Color = VertexColor;
-    gl_Position = MVP * vec4(VertexPosition, 1.0);
+    gl_Position = MVP * instanceMatrix * vec4(VertexPosition, 1.0);
 }
@@ -0,0 +1,66 @@
+//
+// HDR passes - Fragment Shader
+// Writes the HDR colors multiplied by the exposure, tone mapped and clamped to the current frame buffer.
+// If HDR_LUMINANCE is defined writes their luminance to the metering buffer, if HDR_BRIGHT is defined
+// writes their exposed colors above the bloom threshold and if HDR_BLUR is defined blurs the input
+// in the direction of the blur step.
+//
+precision highp float;
+
+in vec2 FragTexcoord;
+
+uniform sampler2D HDRColor; // Colors of the 3D objects or input of the blur pass
+uniform float HDRExposure;  // Exposure of the camera
+#if defined(HDR_BRIGHT) || defined(HDR_BLOOM)
+uniform vec3 HDRBloomParams; // Threshold, soft knee and intensity of the bloom
+#endif
+#ifdef HDR_BLOOM
+uniform sampler2D HDRBloom; // Blurred bright colors
+#endif
+#ifdef HDR_BLUR
+uniform vec2 HDRBlurStep;   // Distance between the blur samples in texture coordinates
+#endif
+
+out vec4 FragColor;
+
+// Maps the specified exposed color to the displayable range
+vec3 toneMap(vec3 c) {
+
+#if defined(TONE_MAPPING_ACES)
+    // Narkowicz's fit of the ACES reference rendering and output transforms
+    c = (c * (2.51 * c + 0.03)) / (c * (2.43 * c + 0.59) + 0.14);
+#elif defined(TONE_MAPPING_REINHARD)
+    c = c / (1.0 + c);
+#endif
+    return clamp(c, 0.0, 1.0);
+}
+
+void main() {
+
+    vec4 color = texture(HDRColor, FragTexcoord);
+#if defined(HDR_LUMINANCE)
+    FragColor = vec4(dot(color.rgb, vec3(0.2126, 0.7152, 0.0722)));
+#elif defined(HDR_BRIGHT)
+    // Keep the part of the color above the threshold with a quadratic transition over the knee
+    vec3 c = color.rgb * HDRExposure;
+    float bright = max(c.r, max(c.g, c.b));
+    float knee = HDRBloomParams.x * HDRBloomParams.y + 1e-4;
+    float soft = clamp(bright - HDRBloomParams.x + knee, 0.0, 2.0 * knee);
+    soft = soft * soft / (4.0 * knee);
+    FragColor = vec4(c * max(soft, bright - HDRBloomParams.x) / max(bright, 1e-4), 1.0);
+#elif defined(HDR_BLUR)
+    // 9 taps gaussian blur using the linear filtering to sample two texels per fetch
+    vec3 sum = color.rgb * 0.2270270270;
+    sum += texture(HDRColor, FragTexcoord + HDRBlurStep * 1.3846153846).rgb * 0.3162162162;
+    sum += texture(HDRColor, FragTexcoord - HDRBlurStep * 1.3846153846).rgb * 0.3162162162;
+    sum += texture(HDRColor, FragTexcoord + HDRBlurStep * 3.2307692308).rgb * 0.0702702703;
+    sum += texture(HDRColor, FragTexcoord - HDRBlurStep * 3.2307692308).rgb * 0.0702702703;
+    FragColor = vec4(sum, 1.0);
+#else
+    vec3 c = color.rgb * HDRExposure;
+#ifdef HDR_BLOOM
+    c += texture(HDRBloom, FragTexcoord).rgb * HDRBloomParams.z;
+#endif
+    FragColor = vec4(toneMap(c), color.a);
+#endif
+}
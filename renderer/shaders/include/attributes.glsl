@@ -5,3 +5,15 @@ layout(location = 0) in  vec3  VertexPosition;
 layout(location = 1) in  vec3  VertexNormal;
 layout(location = 2) in  vec3  VertexColor;
 layout(location = 3) in  vec2  VertexTexcoord;
+
+#ifdef INSTANCED
+// Per-instance attributes: model matrix columns and generic data slots
+layout(location = 4)  in  vec4  InstanceMatrix0;
+layout(location = 5)  in  vec4  InstanceMatrix1;
+layout(location = 6)  in  vec4  InstanceMatrix2;
+layout(location = 7)  in  vec4  InstanceMatrix3;
+layout(location = 8)  in  vec4  InstanceData0;
+layout(location = 9)  in  vec4  InstanceData1;
+layout(location = 10) in  vec4  InstanceData2;
+layout(location = 11) in  vec4  InstanceData3;
+#endif
@@ -1,6 +1,20 @@
 #ifdef BONE_INFLUENCERS
     #if BONE_INFLUENCERS > 0
+    #ifdef BONE_TEXTURE
+	// Each row of the texture contains the four columns of the matrix of a bone
+	uniform highp sampler2D mBoneTexture;
+	mat4 boneMatrix(int i) {
+	    return mat4(texelFetch(mBoneTexture, ivec2(0, i), 0),
+	                texelFetch(mBoneTexture, ivec2(1, i), 0),
+	                texelFetch(mBoneTexture, ivec2(2, i), 0),
+	                texelFetch(mBoneTexture, ivec2(3, i), 0));
+	}
+    #else
 	uniform mat4 mBones[TOTAL_BONES];
+	mat4 boneMatrix(int i) {
+	    return mBones[i];
+	}
+    #endif
     in vec4 matricesIndices;
     in vec4 matricesWeights;
 //    #if BONE_INFLUENCERS > 4
@@ -0,0 +1,10 @@
+//
+// Camera uniform block
+//
+
+// Camera matrices with the std140 layout, updated once per frame
+layout(std140) uniform Camera {
+    mat4 ViewMatrix;    // Transforms world to camera coordinates
+    mat4 ProjMatrix;    // Transforms camera to clip coordinates
+    mat4 CameraMatrix;  // Transforms camera to world coordinates
+};
@@ -0,0 +1 @@
+    dirShadows[{i}] = dirShadow(DirShadowMap[{i}], {i}, shadowPosition);
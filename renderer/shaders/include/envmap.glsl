@@ -0,0 +1,28 @@
+//
+// Environment map uniforms and functions
+//
+#ifdef ENV_MAP
+
+#include <camera>
+
+// Cube map of the nearest reflection probe
+uniform samplerCube EnvMap;
+// Intensity and maximum mipmap level of the environment map
+uniform vec4 EnvMapParams;
+
+// Returns the color of the environment reflected by a surface with the specified roughness,
+// normal and direction to the camera in camera coordinates.
+// The rough surfaces sample the blurred mipmap levels of the cube map.
+vec3 envReflection(vec3 normal, vec3 camDir, float roughness) {
+
+    vec3 dir = mat3(CameraMatrix) * reflect(-camDir, normal);
+    return textureLod(EnvMap, dir, roughness * EnvMapParams.y).rgb;
+}
+
+// Returns the average color of the environment around the specified normal in camera coordinates
+vec3 envIrradiance(vec3 normal) {
+
+    return textureLod(EnvMap, mat3(CameraMatrix) * normal, EnvMapParams.y).rgb;
+}
+
+#endif
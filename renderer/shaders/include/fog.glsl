@@ -0,0 +1,28 @@
+//
+// Fog uniforms and functions
+//
+#ifdef FOG
+
+// Fog uniform array: color and parameters (near distance, far distance, density)
+uniform vec3 Fog[2];
+// Macros to access elements inside the Fog array
+#define FogColor    Fog[0]
+#define FogNear     Fog[1].x
+#define FogFar      Fog[1].y
+#define FogDensity  Fog[1].z
+
+// Returns the specified color faded to the fog color by the specified distance from the camera
+vec3 applyFog(vec3 color, float dist) {
+
+    #if FOG == 1
+        float fogFactor = clamp((dist - FogNear) / (FogFar - FogNear), 0.0, 1.0);
+    #elif FOG == 2
+        float fogFactor = 1.0 - exp(-FogDensity * dist);
+    #else
+        float fogDist = FogDensity * dist;
+        float fogFactor = 1.0 - exp(-fogDist * fogDist);
+    #endif
+    return mix(color, FogColor, fogFactor);
+}
+
+#endif
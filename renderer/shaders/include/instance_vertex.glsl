@@ -0,0 +1,8 @@
+    // Model matrix of the instance relative to the graphic and its normal matrix
+#ifdef INSTANCED
+    mat4 instanceMatrix = mat4(InstanceMatrix0, InstanceMatrix1, InstanceMatrix2, InstanceMatrix3);
+    mat3 instanceNormalMatrix = transpose(inverse(mat3(instanceMatrix)));
+#else
+    mat4 instanceMatrix = mat4(1.0);
+    mat3 instanceNormalMatrix = mat3(1.0);
+#endif
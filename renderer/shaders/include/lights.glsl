@@ -2,38 +2,69 @@
 // Lights uniforms
 //
 
+// The light arrays are uniform blocks with the std140 layout, updated once per frame,
+// where each element is a vec4 whose last component is unused.
+
 #if AMB_LIGHTS>0
-    // Ambient lights color uniform
-    uniform vec3 AmbientLightColor[AMB_LIGHTS];
+    // Ambient lights uniform block
+    layout(std140) uniform AmbientLights {
+        vec4 AmbientLight[AMB_LIGHTS];
+    };
+    // Macro to access the color of an ambient light
+    #define AmbientLightColor(a)		AmbientLight[a].xyz
 #endif
 
 #if DIR_LIGHTS>0
-    // Directional lights uniform array. Each directional light uses 2 elements
-    uniform vec3 DirLight[2*DIR_LIGHTS];
-    // Macros to access elements inside the DirectionalLight uniform array
-    #define DirLightColor(a)		DirLight[2*a]
-    #define DirLightPosition(a)		DirLight[2*a+1]
+    // Directional lights uniform block. Each directional light uses 2 elements
+    layout(std140) uniform DirLights {
+        vec4 DirLight[2*DIR_LIGHTS];
+    };
+    // Macros to access elements inside the DirLight uniform array
+    #define DirLightColor(a)		DirLight[2*a].xyz
+    #define DirLightPosition(a)		DirLight[2*a+1].xyz
 #endif
 
 #if POINT_LIGHTS>0
-    // Point lights uniform array. Each point light uses 3 elements
-    uniform vec3 PointLight[3*POINT_LIGHTS];
+    // Point lights uniform block. Each point light uses 3 elements
+    layout(std140) uniform PointLights {
+        vec4 PointLight[3*POINT_LIGHTS];
+    };
     // Macros to access elements inside the PointLight uniform array
-    #define PointLightColor(a)			PointLight[3*a]
-    #define PointLightPosition(a)		PointLight[3*a+1]
+    #define PointLightColor(a)			PointLight[3*a].xyz
+    #define PointLightPosition(a)		PointLight[3*a+1].xyz
     #define PointLightLinearDecay(a)	PointLight[3*a+2].x
     #define PointLightQuadraticDecay(a)	PointLight[3*a+2].y
 #endif
 
 #if SPOT_LIGHTS>0
-    // Spot lights uniforms. Each spot light uses 5 elements
-    uniform vec3  SpotLight[5*SPOT_LIGHTS];
-    // Macros to access elements inside the PointLight uniform array
-    #define SpotLightColor(a)			SpotLight[5*a]
-    #define SpotLightPosition(a)		SpotLight[5*a+1]
-    #define SpotLightDirection(a)		SpotLight[5*a+2]
+    // Spot lights uniform block. Each spot light uses 5 elements
+    layout(std140) uniform SpotLights {
+        vec4 SpotLight[5*SPOT_LIGHTS];
+    };
+    // Macros to access elements inside the SpotLight uniform array
+    #define SpotLightColor(a)			SpotLight[5*a].xyz
+    #define SpotLightPosition(a)		SpotLight[5*a+1].xyz
+    #define SpotLightDirection(a)		SpotLight[5*a+2].xyz
     #define SpotLightAngularDecay(a)	SpotLight[5*a+3].x
     #define SpotLightCutoffAngle(a)		SpotLight[5*a+3].y
     #define SpotLightLinearDecay(a)		SpotLight[5*a+3].z
     #define SpotLightQuadraticDecay(a)	SpotLight[5*a+4].x
 #endif
+
+#if DIR_SHADOWS>0
+    // Maximum number of shadow map cascades of each directional light
+    #define SHADOW_CASCADES 4
+    // Shadow maps of the first DIR_SHADOWS directional lights. Each map contains its cascades side by side
+    uniform sampler2D DirShadowMap[DIR_SHADOWS];
+    // Matrices which transform camera coordinates to shadow map coordinates of each cascade
+    uniform mat4 DirShadowMatrix[SHADOW_CASCADES*DIR_SHADOWS];
+    // Far distance from the camera of each cascade
+    uniform vec4 DirShadowSplits[DIR_SHADOWS];
+    // Shadow parameters of each light: number of cascades, depth bias, cascade blend fraction and texel size
+    uniform vec4 DirShadowParams[DIR_SHADOWS];
+    // Macros to access elements inside the DirShadowParams uniform array
+    #define DirShadowCascades(a)		int(DirShadowParams[a].x)
+    #define DirShadowBias(a)			DirShadowParams[a].y
+    #define DirShadowBlend(a)			DirShadowParams[a].z
+    #define DirShadowTexel(a)			DirShadowParams[a].w
+#endif
@@ -0,0 +1,23 @@
+//
+// Ocean material uniforms
+//
+
+// Gerstner waves: direction (xy), steepness (z) and wavelength (w)
+#if OCEAN_WAVES > 0
+uniform vec4 OceanWaves[OCEAN_WAVES];
+#endif
+
+// Ocean parameters uniform array
+uniform vec4 Ocean[4];
+// Macros to access elements inside the Ocean array
+#define OceanTime           Ocean[0].x
+#define OceanNormalStrength Ocean[0].y
+#define OceanNormalScale    Ocean[0].z
+#define OceanFoamThreshold  Ocean[0].w
+#define OceanNormalSpeed    Ocean[1].xy
+#define OceanFoamSoftness   Ocean[1].z
+#define OceanFresnel        Ocean[1].w
+#define OceanCrestColor     Ocean[2].rgb
+#define OceanCrestHeight    Ocean[2].w
+#define OceanFoamColor      Ocean[3].rgb
+#define OceanFoamIntensity  Ocean[3].w
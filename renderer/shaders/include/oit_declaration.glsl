@@ -0,0 +1,9 @@
+//
+// Fragment outputs for order independent transparency
+//
+#ifdef OIT
+layout(location = 0) out vec4 FragColor;  // Weighted premultiplied color and alpha
+layout(location = 1) out vec4 FragWeight; // Weighted alpha
+#else
+out vec4 FragColor;
+#endif
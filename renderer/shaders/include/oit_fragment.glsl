@@ -0,0 +1,11 @@
+    #ifdef OIT
+    // Weighted blended order independent transparency:
+    // converts the final color to the weighted premultiplied color with the alpha
+    // used to compute the revealage and the weighted alpha used to normalize the color.
+    {
+        float oitAlpha = FragColor.a;
+        float oitWeight = clamp(pow(min(1.0, oitAlpha * 10.0) + 0.01, 3.0) * 1e8 * pow(1.0 - gl_FragCoord.z * 0.9, 3.0), 1e-2, 3e3);
+        FragColor = vec4(FragColor.rgb * oitAlpha * oitWeight, oitAlpha);
+        FragWeight = vec4(oitAlpha * oitWeight);
+    }
+    #endif
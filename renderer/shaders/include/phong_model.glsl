@@ -9,7 +9,7 @@
     ambdiff:    output ambient+diffuse color
     spec:       output specular color
  Uniforms:
-    AmbientLightColor[]
+    AmbientLightColor()
     DiffuseLightColor[]
     DiffuseLightPosition[]
     PointLightColor[]
@@ -18,7 +18,21 @@
     PointLightQuadraticDecay[]
     MatSpecularColor
     MatShininess
+    DirShadowMap[]
+    DirShadowMatrix[]
+    DirShadowSplits[]
+    DirShadowParams[]
+    SSAOMap
+    SSAOViewport
 *****/
+#include <shadows>
+#include <ssao>
+
+#ifdef VERTEX_AO
+// Baked ambient occlusion interpolated from the vertices
+in float FragAO;
+#endif
+
 void phongModel(vec4 position, vec3 normal, vec3 camDir, vec3 matAmbient, vec3 matDiffuse, out vec3 ambdiff, out vec3 spec) {
 
     vec3 ambientTotal  = vec3(0.0);
@@ -34,25 +48,43 @@ void phongModel(vec4 position, vec3 normal, vec3 camDir, vec3 matAmbient, vec3 m
     noLights = false;
     // Ambient lights
     for (int i = 0; i < AMB_LIGHTS; ++i) {
-        ambientTotal += AmbientLightColor[i] * matAmbient;
+        ambientTotal += AmbientLightColor(i) * matAmbient;
     }
+#ifdef SSAO
+    ambientTotal *= ambientOcclusion();
+#endif
+#ifdef VERTEX_AO
+    ambientTotal *= FragAO;
+#endif
 #endif
 
 #if DIR_LIGHTS>0
     noLights = false;
+#if DIR_SHADOWS>0
+    // Shadows of the directional lights which cast shadows
+    vec3 shadowPosition = vec3(position);
+    float dirShadows[DIR_SHADOWS];
+    #include <dir_shadow> [DIR_SHADOWS]
+#endif
     // Directional lights
     for (int i = 0; i < DIR_LIGHTS; ++i) {
         vec3 lightDirection = normalize(DirLightPosition(i)); // Vector from fragment to light source
         float dotNormal = dot(lightDirection, normal); // Dot product between light direction and fragment normal
+        vec3 lightColor = DirLightColor(i);
+#if DIR_SHADOWS>0
+        if (i < DIR_SHADOWS) {
+            lightColor *= dirShadows[i];
+        }
+#endif
         if (dotNormal > EPS) { // If the fragment is lit
-            diffuseTotal += DirLightColor(i) * matDiffuse * dotNormal;
+            diffuseTotal += lightColor * matDiffuse * dotNormal;
 
 #ifdef BLINN
             specular = pow(max(dot(normal, normalize(lightDirection + camDir)), 0.0), MatShininess);
 #else
             specular = pow(max(dot(reflect(-lightDirection, normal), camDir), 0.0), MatShininess);
 #endif
-            specularTotal += DirLightColor(i) * MatSpecularColor * specular;
+            specularTotal += lightColor * MatSpecularColor * specular;
         }
     }
 #endif
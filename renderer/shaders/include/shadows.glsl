@@ -0,0 +1,62 @@
+//
+// Directional light shadows with cascaded shadow maps
+//
+#if DIR_SHADOWS>0
+
+// dirShadowCascade returns the fraction of the fragment at the specified position
+// in camera coordinates which is lit in the specified cascade of the shadow map
+// of the specified directional light, using 3x3 percentage closer filtering.
+float dirShadowCascade(sampler2D shadowMap, int light, int cascade, vec3 position) {
+
+    vec4 coord = DirShadowMatrix[SHADOW_CASCADES*light + cascade] * vec4(position, 1.0);
+    if (coord.z > 1.0) { // Beyond the far plane of the light
+        return 1.0;
+    }
+
+    // Limits of the cascade in the shadow map to avoid sampling neighbour cascades
+    float count = float(DirShadowCascades(light));
+    vec2 texel = vec2(DirShadowTexel(light) / count, DirShadowTexel(light));
+    float minX = float(cascade) / count + texel.x * 0.5;
+    float maxX = float(cascade + 1) / count - texel.x * 0.5;
+
+    float lit = 0.0;
+    for (int x = -1; x <= 1; x++) {
+        for (int y = -1; y <= 1; y++) {
+            vec2 uv = coord.xy + vec2(x, y) * texel;
+            uv.x = clamp(uv.x, minX, maxX);
+            float depth = texture(shadowMap, uv).r;
+            lit += (coord.z - DirShadowBias(light) > depth) ? 0.0 : 1.0;
+        }
+    }
+    return lit / 9.0;
+}
+
+// dirShadow returns the fraction of the fragment at the specified position in camera coordinates
+// which is lit by the specified directional light. The cascade is selected by the distance of the
+// fragment from the camera and blended with the next cascade near its far distance.
+// The last cascade fades out to no shadow.
+float dirShadow(sampler2D shadowMap, int light, vec3 position) {
+
+    float dist = -position.z;
+    int count = DirShadowCascades(light);
+    float near = 0.0;
+    for (int c = 0; c < count; c++) {
+        float far = DirShadowSplits[light][c];
+        if (dist <= far) {
+            float lit = dirShadowCascade(shadowMap, light, c, position);
+            float blendStart = far - (far - near) * DirShadowBlend(light);
+            if (dist > blendStart) {
+                float next = 1.0;
+                if (c < count - 1) {
+                    next = dirShadowCascade(shadowMap, light, c + 1, position);
+                }
+                lit = mix(lit, next, (dist - blendStart) / (far - blendStart));
+            }
+            return lit;
+        }
+        near = far;
+    }
+    return 1.0;
+}
+
+#endif
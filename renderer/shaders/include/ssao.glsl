@@ -0,0 +1,18 @@
+//
+// Screen-space ambient occlusion uniforms and functions
+//
+#ifdef SSAO
+
+// Blurred ambient occlusion of the opaque objects in the viewport
+uniform sampler2D SSAOMap;
+// Origin of the viewport in window coordinates and inverse of its size
+uniform vec4 SSAOViewport;
+
+// Returns the fraction of the ambient light which reaches the current fragment
+float ambientOcclusion() {
+
+    vec2 uv = (gl_FragCoord.xy - SSAOViewport.xy) * SSAOViewport.zw;
+    return texture(SSAOMap, uv).r;
+}
+
+#endif
@@ -0,0 +1,74 @@
+//
+// Ocean material - Fragment Shader
+// Phong lighting of the wave surface with animated detail normals, crest color, foam and fresnel
+//
+precision highp float;
+
+// Inputs from vertex shader
+in vec4 Position;     // Fragment position in camera coordinates
+in vec3 Normal;       // Fragment normal in camera coordinates
+in vec3 Tangent;      // Fragment tangent in camera coordinates
+in vec3 Bitangent;    // Fragment bitangent in camera coordinates
+in vec2 FragTexcoord; // Plane coordinates used to sample the normal map
+in float Height;      // Wave height
+in float Foam;        // Wave compression
+
+#include <lights>
+#include <material>
+#include <phong_model>
+#include <ocean>
+#include <fog>
+
+#ifdef HAS_NORMALMAP
+uniform sampler2D OceanNormalSampler;
+#endif
+
+// Final fragment color
+#include <oit_declaration>
+
+void main() {
+
+    vec3 normal = normalize(Normal);
+
+#ifdef HAS_NORMALMAP
+    // Blends two samples of the normal map scrolling in different directions
+    vec2 offset = OceanNormalSpeed * OceanTime;
+    vec3 n1 = texture(OceanNormalSampler, FragTexcoord + offset).rgb * 2.0 - 1.0;
+    vec3 n2 = texture(OceanNormalSampler, FragTexcoord * 1.37 - offset.yx).rgb * 2.0 - 1.0;
+    vec3 detail = normalize(vec3(n1.xy + n2.xy, n1.z * n2.z));
+    detail.xy *= OceanNormalStrength;
+    mat3 tbn = mat3(normalize(Tangent), normalize(Bitangent), normal);
+    normal = normalize(tbn * detail);
+#endif
+
+    // Calculate the direction vector from the fragment to the camera (origin)
+    vec3 camDir = normalize(-Position.xyz);
+    if (dot(normal, camDir) < 0.0) { // Seen from below
+        normal = -normal;
+    }
+
+    // Mixes the water and crest colors by the wave height
+    float crest = OceanCrestHeight > 0.0 ? clamp(Height / OceanCrestHeight, 0.0, 1.0) : 0.0;
+    vec3 color = mix(MatDiffuseColor, OceanCrestColor, crest);
+
+    vec3 Ambdiff, Spec;
+    phongModel(Position, normal, camDir, color, color, Ambdiff, Spec);
+
+    // Surfaces seen at grazing angles reflect more
+    float fresnel = pow(1.0 - max(dot(normal, camDir), 0.0), 5.0) * OceanFresnel;
+    vec3 water = Ambdiff + Spec * (1.0 + fresnel);
+
+    // Foam where the waves are compressed
+    float foam = smoothstep(OceanFoamThreshold, OceanFoamThreshold + OceanFoamSoftness, Foam) * OceanFoamIntensity;
+    water = mix(water, OceanFoamColor, clamp(foam, 0.0, 1.0));
+
+    float alpha = clamp(MatOpacity + fresnel + foam, 0.0, 1.0);
+    FragColor = min(vec4(water, alpha), vec4(1.0));
+
+    // Fades the color to the fog color with the distance from the camera
+    #ifdef FOG
+        FragColor.rgb = applyFog(FragColor.rgb, length(Position.xyz));
+    #endif
+
+    #include <oit_fragment>
+}
@@ -0,0 +1,63 @@
+//
+// Ocean material - Vertex Shader
+// Displaces the vertices of a plane in the XY plane with a sum of Gerstner waves.
+// The wave heights are along the Z axis.
+//
+#include <attributes>
+
+// Model uniforms
+uniform mat4 ModelViewMatrix;
+uniform mat3 NormalMatrix;
+uniform mat4 MVP;
+
+#include <ocean>
+
+// Output variables for Fragment shader
+out vec4 Position;     // Vertex position in camera coordinates
+out vec3 Normal;       // Vertex normal in camera coordinates
+out vec3 Tangent;      // Vertex tangent in camera coordinates
+out vec3 Bitangent;    // Vertex bitangent in camera coordinates
+out vec2 FragTexcoord; // Plane coordinates used to sample the normal map
+out float Height;      // Wave height
+out float Foam;        // Wave compression (one minus the jacobian of the horizontal displacement)
+
+void main() {
+
+    vec3 pos = VertexPosition;
+    vec3 tangent = vec3(1.0, 0.0, 0.0);
+    vec3 bitangent = vec3(0.0, 1.0, 0.0);
+    float jxx = 1.0;
+    float jyy = 1.0;
+    float jxy = 0.0;
+#if OCEAN_WAVES > 0
+    for (int i = 0; i < OCEAN_WAVES; i++) {
+        vec4 wave = OceanWaves[i];
+        vec2 d = normalize(wave.xy);
+        float steepness = wave.z;
+        float k = 2.0 * 3.14159265 / wave.w;
+        float c = sqrt(9.8 / k);
+        float f = k * (dot(d, VertexPosition.xy) - c * OceanTime);
+        float a = steepness / k;
+        float sinf = sin(f);
+        float cosf = cos(f);
+        pos.xy += d * (a * cosf);
+        pos.z += a * sinf;
+        // Partial derivatives of the displaced position
+        tangent += vec3(-d.x * d.x * steepness * sinf, -d.x * d.y * steepness * sinf, d.x * steepness * cosf);
+        bitangent += vec3(-d.x * d.y * steepness * sinf, -d.y * d.y * steepness * sinf, d.y * steepness * cosf);
+        jxx -= d.x * d.x * steepness * sinf;
+        jyy -= d.y * d.y * steepness * sinf;
+        jxy -= d.x * d.y * steepness * sinf;
+    }
+#endif
+    vec3 normal = normalize(cross(tangent, bitangent));
+
+    Position = ModelViewMatrix * vec4(pos, 1.0);
+    Normal = normalize(NormalMatrix * normal);
+    Tangent = normalize(NormalMatrix * tangent);
+    Bitangent = normalize(NormalMatrix * bitangent);
+    FragTexcoord = pos.xy * OceanNormalScale;
+    Height = pos.z;
+    Foam = 1.0 - (jxx * jyy - jxy * jxy);
+    gl_Position = MVP * vec4(pos, 1.0);
+}
@@ -0,0 +1,23 @@
+//
+// Order independent transparency composite pass - Fragment Shader
+// Blends the average color of the transparent fragments over the opaque scene
+//
+precision highp float;
+
+in vec2 FragTexcoord;
+
+uniform sampler2D OITAccum;  // Weighted premultiplied colors and revealage
+uniform sampler2D OITWeight; // Weighted alphas
+
+out vec4 FragColor;
+
+void main() {
+
+    vec4 accum = texture(OITAccum, FragTexcoord);
+    float revealage = accum.a;
+    if (revealage >= 1.0) {
+        discard;
+    }
+    float weight = texture(OITWeight, FragTexcoord).r;
+    FragColor = vec4(accum.rgb / max(weight, 1e-5), 1.0 - revealage);
+}
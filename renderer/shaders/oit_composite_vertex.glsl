@@ -0,0 +1,14 @@
+//
+// Order independent transparency composite pass - Vertex Shader
+// Generates a triangle covering the whole viewport without vertex attributes
+//
+
+// Output texture coordinates
+out vec2 FragTexcoord;
+
+void main() {
+
+    vec2 pos = vec2(float((gl_VertexID << 1) & 2), float(gl_VertexID & 2));
+    FragTexcoord = pos;
+    gl_Position = vec4(pos * 2.0 - 1.0, 0.0, 1.0);
+}
@@ -0,0 +1,15 @@
+//
+// Selection outline pass - Fragment Shader
+//
+precision highp float;
+
+// Outline color
+uniform vec4 OutlineColor;
+
+// Output
+out vec4 FragColor;
+
+void main() {
+
+    FragColor = OutlineColor;
+}
@@ -0,0 +1,34 @@
+//
+// Selection outline pass - Vertex Shader
+// Extrudes the vertices along their normals projected on the screen
+//
+#include <attributes>
+
+// Model uniforms
+uniform mat4 MVP;
+
+// Outline width in normalized device coordinates
+uniform vec2 OutlineWidth;
+
+#include <morphtarget_vertex_declaration>
+#include <bones_vertex_declaration>
+
+void main() {
+
+    #include <instance_vertex>
+
+    vec3 vPosition = VertexPosition;
+    vec3 vNormal = VertexNormal;
+    mat4 finalWorld = instanceMatrix;
+    #include <morphtarget_vertex>
+    #include <bones_vertex>
+
+    // Offset the projected vertex position along the projected normal
+    mat4 mvp = MVP * finalWorld;
+    vec4 position = mvp * vec4(vPosition, 1.0);
+    vec2 normal = (mvp * vec4(vNormal, 0.0)).xy;
+    if (dot(normal, normal) > 0.0) {
+        position.xy += normalize(normal) * OutlineWidth * position.w;
+    }
+    gl_Position = position;
+}
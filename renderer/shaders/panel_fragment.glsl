@@ -23,6 +23,24 @@ uniform vec4 Panel[8];
 #define PaddingColor	Panel[5]		  // panel padding color
 #define ContentColor	Panel[6]		  // panel content color
 #define TextureValid	bool(Panel[7].x)  // texture valid flag
+#define ClipValid		bool(Panel[7].y)  // clip shape valid flag
+#define NinePatchValid	bool(Panel[7].z)  // nine-patch valid flag
+#define Opacity			Panel[7].w		  // opacity of the panel and its ancestors
+
+// Clip shape uniforms
+uniform vec4 PanelClip[2];
+#define ClipRect		PanelClip[0]		// clip rectangle in pixels relative to the panel
+#define ClipRadius		PanelClip[1].x		// clip rectangle corners radius in pixels
+#define ClipMaskValid	bool(PanelClip[1].y) // clip mask texture valid flag
+#define PanelSize		PanelClip[1].zw		// panel size in pixels
+
+// Clip mask texture
+uniform sampler2D MaskTexture;
+
+// Nine-patch uniforms
+uniform vec4 PanelNinePatch[2];
+#define NinePatchTex		PanelNinePatch[0]	// insets (top, right, bottom, left) in texture coordinates
+#define NinePatchContent	PanelNinePatch[1]	// insets (top, right, bottom, left) in content area coordinates
 
 // Output
 out vec4 FragColor;
@@ -54,19 +72,50 @@ bool checkRect(vec4 rect) {
 }
 
 
-void main() {
+/***
+* Returns the coverage [0,1] of the current fragment by the clip shape:
+* the rounded clip rectangle multiplied by the alpha of the optional mask texture.
+*/
+float clipCoverage() {
 
-    // Discard fragment outside of received bounds
-    // Bounds[0] - xmin
-    // Bounds[1] - ymin
-    // Bounds[2] - xmax
-    // Bounds[3] - ymax
-    if (FragTexcoord.x <= Bounds[0] || FragTexcoord.x >= Bounds[2]) {
-        discard;
+    vec2 pos = FragTexcoord * PanelSize - ClipRect.xy;
+    vec2 halfSize = ClipRect.zw * 0.5;
+    float radius = min(ClipRadius, min(halfSize.x, halfSize.y));
+
+    // Signed distance to the rounded rectangle
+    vec2 q = abs(pos - halfSize) - (halfSize - vec2(radius));
+    float dist = length(max(q, 0.0)) + min(max(q.x, q.y), 0.0) - radius;
+    float coverage = clamp(0.5 - dist, 0.0, 1.0);
+
+    if (ClipMaskValid) {
+        coverage *= texture(MaskTexture, pos / ClipRect.zw).a;
     }
-    if (FragTexcoord.y <= Bounds[1] || FragTexcoord.y >= Bounds[3]) {
-        discard;
+    return coverage;
+}
+
+
+/***
+* Maps the specified content area coordinate of one axis to the texture coordinate
+* of a nine-patch texture, keeping the size of the insets at both ends
+* and stretching the center between them.
+*/
+float ninePatch(float t, float contentStart, float contentEnd, float texStart, float texEnd) {
+
+    if (t < contentStart) {
+        return t / contentStart * texStart;
+    }
+    if (t > 1.0 - contentEnd) {
+        return 1.0 - (1.0 - t) / contentEnd * texEnd;
     }
+    return texStart + (t - contentStart) / (1.0 - contentStart - contentEnd) * (1.0 - texStart - texEnd);
+}
+
+
+/***
+* Returns the color of the current fragment
+* depending on the panel area it is in.
+*/
+vec4 panelColor() {
 
     // Check if fragment is inside content area
     if (checkRect(Content)) {
@@ -79,6 +128,10 @@ void main() {
             vec2 offset = vec2(-Content[0], -Content[1]);
             vec2 factor = vec2(1.0/Content[2], 1.0/Content[3]);
             vec2 texcoord = (FragTexcoord + offset) * factor;
+            if (NinePatchValid) {
+                texcoord.x = ninePatch(texcoord.x, NinePatchContent.w, NinePatchContent.y, NinePatchTex.w, NinePatchTex.y);
+                texcoord.y = ninePatch(texcoord.y, NinePatchContent.x, NinePatchContent.z, NinePatchTex.x, NinePatchTex.z);
+            }
             vec4 texColor = texture(MatTexture, texcoord * MatTexRepeat + MatTexOffset);
 
             // Mix content color with texture color.
@@ -101,23 +154,48 @@ void main() {
             // Un-alpha-premultiply
             color.rgb /= color.a;
 		}
-
-        FragColor = color;
-        return;
+        return color;
     }
 
     // Checks if fragment is inside paddings area
     if (checkRect(Padding)) {
-        FragColor = PaddingColor;
-        return;
+        return PaddingColor;
     }
 
     // Checks if fragment is inside borders area
     if (checkRect(Border)) {
-        FragColor = BorderColor;
-        return;
+        return BorderColor;
     }
 
     // Fragment is in margins area (always transparent)
-    FragColor = vec4(1,1,1,0);
+    return vec4(1,1,1,0);
+}
+
+
+void main() {
+
+    // Discard fragment outside of received bounds
+    // Bounds[0] - xmin
+    // Bounds[1] - ymin
+    // Bounds[2] - xmax
+    // Bounds[3] - ymax
+    if (FragTexcoord.x <= Bounds[0] || FragTexcoord.x >= Bounds[2]) {
+        discard;
+    }
+    if (FragTexcoord.y <= Bounds[1] || FragTexcoord.y >= Bounds[3]) {
+        discard;
+    }
+
+    vec4 color = panelColor();
+
+    // Applies the clip shape of this panel or of its clipping ancestor
+    if (ClipValid) {
+        float coverage = clipCoverage();
+        if (coverage <= 0.0) {
+            discard;
+        }
+        color.a *= coverage;
+    }
+    color.a *= Opacity;
+    FragColor = color;
 }
@@ -11,6 +11,8 @@
 //     https://github.com/KhronosGroup/glTF-WebGL-PBR/#environment-maps
 // [4] "An Inexpensive BRDF Model for Physically based Rendering" by Christophe Schlick
 //     https://www.cs.virginia.edu/~jdl/bib/appearance/analytic%20models/schlick94b.pdf
+// [5] Physically Based Shading on Mobile by Brian Karis
+//     https://www.unrealengine.com/en-US/blog/physically-based-shading-on-mobile
 
 //#extension GL_EXT_shader_texture_lod: enable
 //#extension GL_OES_standard_derivatives : enable
@@ -20,12 +22,6 @@ precision highp float;
 //uniform vec3 u_LightDirection;
 //uniform vec3 u_LightColor;
 
-//#ifdef USE_IBL
-//uniform samplerCube u_DiffuseEnvSampler;
-//uniform samplerCube u_SpecularEnvSampler;
-//uniform sampler2D u_brdfLUT;
-//#endif
-
 #ifdef HAS_BASECOLORMAP
 uniform sampler2D uBaseColorSampler;
 #endif
@@ -53,15 +49,25 @@ uniform vec4 Material[3];
 #define uRoughnessFactor    Material[2].y
 
 #include <lights>
+#include <shadows>
+#include <ssao>
+#include <fog>
+#include <envmap>
 
 // Inputs from vertex shader
 in vec3 Position;       // Vertex position in camera coordinates.
 in vec3 Normal;         // Vertex normal in camera coordinates.
 in vec3 CamDir;         // Direction from vertex to camera
 in vec2 FragTexcoord;
+#ifdef VERTEX_AO
+in float FragAO;        // Baked ambient occlusion interpolated from the vertices
+#endif
+#ifdef VERTEX_COLORS
+in vec3 FragVertexColor; // Color interpolated from the vertices
+#endif
 
 // Final fragment color
-out vec4 FragColor;
+#include <oit_declaration>
 
 // Encapsulate the various inputs used by the various functions in the shading equation
 // We store values in this struct to simplify the integration of alternative implementations
@@ -140,32 +146,27 @@ vec3 getNormal()
     return n;
 }
 
-// Calculation of the lighting contribution from an optional Image Based Light source.
-// Precomputed Environment Maps are required uniform inputs and are computed as outlined in [1].
-// See our README.md on Environment Maps [3] for additional discussion.
-vec3 getIBLContribution(PBRInfo pbrInputs, PBRLightInfo pbrLight, vec3 n, vec3 reflection)
+#ifdef ENV_MAP
+// Calculation of the lighting contribution from the environment map of the nearest reflection probe.
+// The prefiltered environment maps of [1] are approximated by the mipmaps of the cube map and
+// the scale and bias to F0 of the precomputed BRDF texture by the analytical approximation of [5].
+vec3 getIBLContribution(PBRInfo pbrInputs, vec3 n, vec3 v)
 {
-    float mipCount = 9.0; // resolution of 512x512
-    float lod = (pbrInputs.perceptualRoughness * mipCount);
-    // retrieve a scale and bias to F0. See [1], Figure 3
-    vec3 brdf = vec3(0.5,0.5,0.5);//SRGBtoLINEAR(texture(u_brdfLUT, vec2(pbrLight.NdotV, 1.0 - pbrInputs.perceptualRoughness))).rgb;
-    vec3 diffuseLight = vec3(0.5,0.5,0.5);//SRGBtoLINEAR(textureCube(u_DiffuseEnvSampler, n)).rgb;
-
-//#ifdef USE_TEX_LOD
-//    vec3 specularLight = SRGBtoLINEAR(textureCubeLodEXT(u_SpecularEnvSampler, reflection, lod)).rgb;
-//#else
-    vec3 specularLight = vec3(0.5,0.5,0.5);//SRGBtoLINEAR(textureCube(u_SpecularEnvSampler, reflection)).rgb;
-//#endif
+    float NdotV = clamp(dot(n, v), 0.001, 1.0);
+    const vec4 c0 = vec4(-1.0, -0.0275, -0.572, 0.022);
+    const vec4 c1 = vec4(1.0, 0.0425, 1.04, -0.04);
+    vec4 r = pbrInputs.perceptualRoughness * c0 + c1;
+    float a004 = min(r.x * r.x, exp2(-9.28 * NdotV)) * r.x + r.y;
+    vec2 brdf = vec2(-1.04, 1.04) * a004 + r.zw;
+
+    vec3 diffuseLight = SRGBtoLINEAR(vec4(envIrradiance(n), 1.0)).rgb;
+    vec3 specularLight = SRGBtoLINEAR(vec4(envReflection(n, v, pbrInputs.perceptualRoughness), 1.0)).rgb;
 
     vec3 diffuse = diffuseLight * pbrInputs.diffuseColor;
     vec3 specular = specularLight * (pbrInputs.specularColor * brdf.x + brdf.y);
-
-    // For presentation, this allows us to disable IBL terms
-//    diffuse *= u_ScaleIBLAmbient.x;
-//    specular *= u_ScaleIBLAmbient.y;
-
-    return diffuse + specular;
+    return (diffuse + specular) * EnvMapParams.x;
 }
+#endif
 
 // Basic Lambertian diffuse
 // Implementation from Lambert's Photometria https://archive.org/details/lambertsphotome00lambgoog
@@ -268,6 +269,9 @@ void main() {
 #else
     vec4 baseColor = uBaseColor;
 #endif
+#ifdef VERTEX_COLORS
+    baseColor.rgb *= FragVertexColor;
+#endif
 
     vec3 f0 = vec3(0.04);
     vec3 diffuseColor = baseColor.rgb * (vec3(1.0) - f0);
@@ -299,19 +303,39 @@ void main() {
 
 #if AMB_LIGHTS>0
     // Ambient lights
+    vec3 ambient = vec3(0.0);
     for (int i = 0; i < AMB_LIGHTS; i++) {
-        color += AmbientLightColor[i] * pbrInputs.diffuseColor;
+        ambient += AmbientLightColor(i) * pbrInputs.diffuseColor;
     }
+#ifdef SSAO
+    ambient *= ambientOcclusion();
+#endif
+#ifdef VERTEX_AO
+    ambient *= FragAO;
+#endif
+    color += ambient;
 #endif
 
 #if DIR_LIGHTS>0
+#if DIR_SHADOWS>0
+    // Shadows of the directional lights which cast shadows
+    vec3 shadowPosition = Position;
+    float dirShadows[DIR_SHADOWS];
+    #include <dir_shadow> [DIR_SHADOWS]
+#endif
     // Directional lights
     for (int i = 0; i < DIR_LIGHTS; i++) {
         // Diffuse reflection
         // DirLightPosition is the direction of the current light
         vec3 lightDirection = normalize(DirLightPosition(i));
+        vec3 lightColor = DirLightColor(i);
+#if DIR_SHADOWS>0
+        if (i < DIR_SHADOWS) {
+            lightColor *= dirShadows[i];
+        }
+#endif
         // PBR
-        color += pbrModel(pbrInputs, DirLightColor(i), lightDirection);
+        color += pbrModel(pbrInputs, lightColor, lightDirection);
     }
 #endif
 
@@ -361,9 +385,9 @@ void main() {
 #endif
 
     // Calculate lighting contribution from image based lighting source (IBL)
-//#ifdef USE_IBL
-//    color += getIBLContribution(pbrInputs, n, reflection);
-//#endif
+#ifdef ENV_MAP
+    color += getIBLContribution(pbrInputs, getNormal(), normalize(CamDir));
+#endif
 
     // Apply optional PBR terms for additional (optional) shading
 #ifdef HAS_OCCLUSIONMAP
@@ -410,4 +434,11 @@ void main() {
 
     // Final fragment color
     FragColor = vec4(pow(color,vec3(1.0/2.2)), baseColor.a);
+
+    // Fades the color to the fog color with the distance from the camera
+    #ifdef FOG
+        FragColor.rgb = applyFog(FragColor.rgb, length(Position.xyz));
+    #endif
+
+    #include <oit_fragment>
 }
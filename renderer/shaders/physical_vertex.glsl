@@ -17,14 +17,22 @@ out vec3 Position;
 out vec3 Normal;
 out vec3 CamDir;
 out vec2 FragTexcoord;
+#ifdef VERTEX_AO
+out float FragAO;     // Baked ambient occlusion of the vertex
+#endif
+#ifdef VERTEX_COLORS
+out vec3 FragVertexColor; // Color of the vertex
+#endif
 
 void main() {
 
+    #include <instance_vertex>
+
     // Transform this vertex position to camera coordinates.
-    Position = vec3(ModelViewMatrix * vec4(VertexPosition, 1.0));
+    Position = vec3(ModelViewMatrix * instanceMatrix * vec4(VertexPosition, 1.0));
 
     // Transform this vertex normal to camera coordinates.
-    Normal = normalize(NormalMatrix * VertexNormal);
+    Normal = normalize(NormalMatrix * instanceNormalMatrix * VertexNormal);
 
     // Calculate the direction vector from the vertex to the camera
     // The camera is at 0,0,0
@@ -32,9 +40,15 @@ void main() {
 
     // Output texture coordinates to fragment shader
     FragTexcoord = VertexTexcoord;
+#ifdef VERTEX_AO
+    FragAO = VertexColor.r;
+#endif
+#ifdef VERTEX_COLORS
+    FragVertexColor = VertexColor;
+#endif
 
     vec3 vPosition = VertexPosition;
-    mat4 finalWorld = mat4(1.0);
+    mat4 finalWorld = instanceMatrix;
     #include <morphtarget_vertex>
     #include <bones_vertex>
 
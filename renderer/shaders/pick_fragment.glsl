@@ -0,0 +1,16 @@
+//
+// Pick ids pass - Fragment Shader
+// Writes the id of the graphic encoded as a color
+//
+precision highp float;
+
+// Pick id of the graphic
+uniform vec4 PickID;
+
+// Output
+out vec4 FragColor;
+
+void main() {
+
+    FragColor = PickID;
+}
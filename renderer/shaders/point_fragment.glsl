@@ -7,7 +7,7 @@ in vec3 Color;
 flat in mat2 Rotation;
 
 // Output
-out vec4 FragColor;
+#include <oit_declaration>
 
 void main() {
 
@@ -51,4 +51,6 @@ void main() {
 
     // Generates final color
     FragColor = min(vec4(Color, MatOpacity) * texMixed, vec4(1));
+
+    #include <oit_fragment>
 }
@@ -27,6 +27,10 @@ void main() {
     gl_PointSize = MatPointSize / -posMV.z;
 
     // Outputs color
+#ifdef VERTEX_COLORS
+    Color = VertexColor;
+#else
     Color = MatEmissiveColor;
+#endif
 }
 
@@ -0,0 +1,8 @@
+//
+// Shadow map depth pass - Fragment Shader
+// Only the depth is written to the shadow map
+//
+precision highp float;
+
+void main() {
+}
@@ -0,0 +1,23 @@
+//
+// Shadow map depth pass - Vertex Shader
+//
+#include <attributes>
+
+// Model uniforms
+uniform mat4 MVP;
+
+#include <morphtarget_vertex_declaration>
+#include <bones_vertex_declaration>
+
+void main() {
+
+    #include <instance_vertex>
+
+    vec3 vPosition = VertexPosition;
+    mat4 finalWorld = instanceMatrix;
+    #include <morphtarget_vertex>
+    #include <bones_vertex>
+
+    // Output vertex position projected in the light space
+    gl_Position = MVP * finalWorld * vec4(vPosition, 1.0);
+}
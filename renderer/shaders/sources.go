@@ -31,21 +31,21 @@ const include_morphtarget_vertex_declaration_source = `#ifdef MORPHTARGETS
 const include_bones_vertex_source = `#ifdef BONE_INFLUENCERS
     #if BONE_INFLUENCERS > 0
 
-        mat4 influence = mBones[int(matricesIndices[0])] * matricesWeights[0];
+        mat4 influence = boneMatrix(int(matricesIndices[0])) * matricesWeights[0];
         #if BONE_INFLUENCERS > 1
-            influence += mBones[int(matricesIndices[1])] * matricesWeights[1];
+            influence += boneMatrix(int(matricesIndices[1])) * matricesWeights[1];
             #if BONE_INFLUENCERS > 2
-                influence += mBones[int(matricesIndices[2])] * matricesWeights[2];
+                influence += boneMatrix(int(matricesIndices[2])) * matricesWeights[2];
                 #if BONE_INFLUENCERS > 3
-                    influence += mBones[int(matricesIndices[3])] * matricesWeights[3];
+                    influence += boneMatrix(int(matricesIndices[3])) * matricesWeights[3];
     //                #if BONE_INFLUENCERS > 4
-    //                    influence += mBones[int(matricesIndicesExtra[0])] * matricesWeightsExtra[0];
+    //                    influence += boneMatrix(int(matricesIndicesExtra[0])) * matricesWeightsExtra[0];
     //                    #if BONE_INFLUENCERS > 5
-    //                        influence += mBones[int(matricesIndicesExtra[1])] * matricesWeightsExtra[1];
+    //                        influence += boneMatrix(int(matricesIndicesExtra[1])) * matricesWeightsExtra[1];
     //                        #if BONE_INFLUENCERS > 6
-    //                            influence += mBones[int(matricesIndicesExtra[2])] * matricesWeightsExtra[2];
+    //                            influence += boneMatrix(int(matricesIndicesExtra[2])) * matricesWeightsExtra[2];
     //                            #if BONE_INFLUENCERS > 7
-    //                                influence += mBones[int(matricesIndicesExtra[3])] * matricesWeightsExtra[3];
+    //                                influence += boneMatrix(int(matricesIndicesExtra[3])) * matricesWeightsExtra[3];
     //                            #endif
     //                        #endif
     //                    #endif
@@ -71,7 +71,7 @@ const include_phong_model_source = `/***
     ambdiff:    output ambient+diffuse color
     spec:       output specular color
  Uniforms:
-    AmbientLightColor[]
+    AmbientLightColor()
     DiffuseLightColor[]
     DiffuseLightPosition[]
     PointLightColor[]
@@ -80,7 +80,21 @@ const include_phong_model_source = `/***
     PointLightQuadraticDecay[]
     MatSpecularColor
     MatShininess
+    DirShadowMap[]
+    DirShadowMatrix[]
+    DirShadowSplits[]
+    DirShadowParams[]
+    SSAOMap
+    SSAOViewport
 *****/
+#include <shadows>
+#include <ssao>
+
+#ifdef VERTEX_AO
+// Baked ambient occlusion interpolated from the vertices
+in float FragAO;
+#endif
+
 void phongModel(vec4 position, vec3 normal, vec3 camDir, vec3 matAmbient, vec3 matDiffuse, out vec3 ambdiff, out vec3 spec) {
 
     vec3 ambientTotal  = vec3(0.0);
@@ -96,25 +110,43 @@ void phongModel(vec4 position, vec3 normal, vec3 camDir, vec3 matAmbient, vec3 m
     noLights = false;
     // Ambient lights
     for (int i = 0; i < AMB_LIGHTS; ++i) {
-        ambientTotal += AmbientLightColor[i] * matAmbient;
+        ambientTotal += AmbientLightColor(i) * matAmbient;
     }
+#ifdef SSAO
+    ambientTotal *= ambientOcclusion();
+#endif
+#ifdef VERTEX_AO
+    ambientTotal *= FragAO;
+#endif
 #endif
 
 #if DIR_LIGHTS>0
     noLights = false;
+#if DIR_SHADOWS>0
+    // Shadows of the directional lights which cast shadows
+    vec3 shadowPosition = vec3(position);
+    float dirShadows[DIR_SHADOWS];
+    #include <dir_shadow> [DIR_SHADOWS]
+#endif
     // Directional lights
     for (int i = 0; i < DIR_LIGHTS; ++i) {
         vec3 lightDirection = normalize(DirLightPosition(i)); // Vector from fragment to light source
         float dotNormal = dot(lightDirection, normal); // Dot product between light direction and fragment normal
+        vec3 lightColor = DirLightColor(i);
+#if DIR_SHADOWS>0
+        if (i < DIR_SHADOWS) {
+            lightColor *= dirShadows[i];
+        }
+#endif
         if (dotNormal > EPS) { // If the fragment is lit
-            diffuseTotal += DirLightColor(i) * matDiffuse * dotNormal;
+            diffuseTotal += lightColor * matDiffuse * dotNormal;
 
 #ifdef BLINN
             specular = pow(max(dot(normal, normalize(lightDirection + camDir)), 0.0), MatShininess);
 #else
             specular = pow(max(dot(reflect(-lightDirection, normal), camDir), 0.0), MatShininess);
 #endif
-            specularTotal += DirLightColor(i) * MatSpecularColor * specular;
+            specularTotal += lightColor * MatSpecularColor * specular;
         }
     }
 #endif
@@ -186,6 +218,18 @@ layout(location = 0) in  vec3  VertexPosition;
 layout(location = 1) in  vec3  VertexNormal;
 layout(location = 2) in  vec3  VertexColor;
 layout(location = 3) in  vec2  VertexTexcoord;
+
+#ifdef INSTANCED
+// Per-instance attributes: model matrix columns and generic data slots
+layout(location = 4)  in  vec4  InstanceMatrix0;
+layout(location = 5)  in  vec4  InstanceMatrix1;
+layout(location = 6)  in  vec4  InstanceMatrix2;
+layout(location = 7)  in  vec4  InstanceMatrix3;
+layout(location = 8)  in  vec4  InstanceData0;
+layout(location = 9)  in  vec4  InstanceData1;
+layout(location = 10) in  vec4  InstanceData2;
+layout(location = 11) in  vec4  InstanceData3;
+#endif
 `
 
 const include_material_source = `//
@@ -231,46 +275,91 @@ const include_lights_source = `//
 // Lights uniforms
 //
 
+// The light arrays are uniform blocks with the std140 layout, updated once per frame,
+// where each element is a vec4 whose last component is unused.
+
 #if AMB_LIGHTS>0
-    // Ambient lights color uniform
-    uniform vec3 AmbientLightColor[AMB_LIGHTS];
+    // Ambient lights uniform block
+    layout(std140) uniform AmbientLights {
+        vec4 AmbientLight[AMB_LIGHTS];
+    };
+    // Macro to access the color of an ambient light
+    #define AmbientLightColor(a)		AmbientLight[a].xyz
 #endif
 
 #if DIR_LIGHTS>0
-    // Directional lights uniform array. Each directional light uses 2 elements
-    uniform vec3 DirLight[2*DIR_LIGHTS];
-    // Macros to access elements inside the DirectionalLight uniform array
-    #define DirLightColor(a)		DirLight[2*a]
-    #define DirLightPosition(a)		DirLight[2*a+1]
+    // Directional lights uniform block. Each directional light uses 2 elements
+    layout(std140) uniform DirLights {
+        vec4 DirLight[2*DIR_LIGHTS];
+    };
+    // Macros to access elements inside the DirLight uniform array
+    #define DirLightColor(a)		DirLight[2*a].xyz
+    #define DirLightPosition(a)		DirLight[2*a+1].xyz
 #endif
 
 #if POINT_LIGHTS>0
-    // Point lights uniform array. Each point light uses 3 elements
-    uniform vec3 PointLight[3*POINT_LIGHTS];
+    // Point lights uniform block. Each point light uses 3 elements
+    layout(std140) uniform PointLights {
+        vec4 PointLight[3*POINT_LIGHTS];
+    };
     // Macros to access elements inside the PointLight uniform array
-    #define PointLightColor(a)			PointLight[3*a]
-    #define PointLightPosition(a)		PointLight[3*a+1]
+    #define PointLightColor(a)			PointLight[3*a].xyz
+    #define PointLightPosition(a)		PointLight[3*a+1].xyz
     #define PointLightLinearDecay(a)	PointLight[3*a+2].x
     #define PointLightQuadraticDecay(a)	PointLight[3*a+2].y
 #endif
 
 #if SPOT_LIGHTS>0
-    // Spot lights uniforms. Each spot light uses 5 elements
-    uniform vec3  SpotLight[5*SPOT_LIGHTS];
-    // Macros to access elements inside the PointLight uniform array
-    #define SpotLightColor(a)			SpotLight[5*a]
-    #define SpotLightPosition(a)		SpotLight[5*a+1]
-    #define SpotLightDirection(a)		SpotLight[5*a+2]
+    // Spot lights uniform block. Each spot light uses 5 elements
+    layout(std140) uniform SpotLights {
+        vec4 SpotLight[5*SPOT_LIGHTS];
+    };
+    // Macros to access elements inside the SpotLight uniform array
+    #define SpotLightColor(a)			SpotLight[5*a].xyz
+    #define SpotLightPosition(a)		SpotLight[5*a+1].xyz
+    #define SpotLightDirection(a)		SpotLight[5*a+2].xyz
     #define SpotLightAngularDecay(a)	SpotLight[5*a+3].x
     #define SpotLightCutoffAngle(a)		SpotLight[5*a+3].y
     #define SpotLightLinearDecay(a)		SpotLight[5*a+3].z
     #define SpotLightQuadraticDecay(a)	SpotLight[5*a+4].x
 #endif
+
+#if DIR_SHADOWS>0
+    // Maximum number of shadow map cascades of each directional light
+    #define SHADOW_CASCADES 4
+    // Shadow maps of the first DIR_SHADOWS directional lights. Each map contains its cascades side by side
+    uniform sampler2D DirShadowMap[DIR_SHADOWS];
+    // Matrices which transform camera coordinates to shadow map coordinates of each cascade
+    uniform mat4 DirShadowMatrix[SHADOW_CASCADES*DIR_SHADOWS];
+    // Far distance from the camera of each cascade
+    uniform vec4 DirShadowSplits[DIR_SHADOWS];
+    // Shadow parameters of each light: number of cascades, depth bias, cascade blend fraction and texel size
+    uniform vec4 DirShadowParams[DIR_SHADOWS];
+    // Macros to access elements inside the DirShadowParams uniform array
+    #define DirShadowCascades(a)		int(DirShadowParams[a].x)
+    #define DirShadowBias(a)			DirShadowParams[a].y
+    #define DirShadowBlend(a)			DirShadowParams[a].z
+    #define DirShadowTexel(a)			DirShadowParams[a].w
+#endif
 `
 
 const include_bones_vertex_declaration_source = `#ifdef BONE_INFLUENCERS
     #if BONE_INFLUENCERS > 0
+    #ifdef BONE_TEXTURE
+	// Each row of the texture contains the four columns of the matrix of a bone
+	uniform highp sampler2D mBoneTexture;
+	mat4 boneMatrix(int i) {
+	    return mat4(texelFetch(mBoneTexture, ivec2(0, i), 0),
+	                texelFetch(mBoneTexture, ivec2(1, i), 0),
+	                texelFetch(mBoneTexture, ivec2(2, i), 0),
+	                texelFetch(mBoneTexture, ivec2(3, i), 0));
+	}
+    #else
 	uniform mat4 mBones[TOTAL_BONES];
+	mat4 boneMatrix(int i) {
+	    return mBones[i];
+	}
+    #endif
     in vec4 matricesIndices;
     in vec4 matricesWeights;
 //    #if BONE_INFLUENCERS > 4
@@ -290,7 +379,7 @@ in vec3 Color;
 flat in mat2 Rotation;
 
 // Output
-out vec4 FragColor;
+#include <oit_declaration>
 
 void main() {
 
@@ -334,6 +423,8 @@ void main() {
 
     // Generates final color
     FragColor = min(vec4(Color, MatOpacity) * texMixed, vec4(1));
+
+    #include <oit_fragment>
 }
 `
 
@@ -356,14 +447,22 @@ out vec3 Position;
 out vec3 Normal;
 out vec3 CamDir;
 out vec2 FragTexcoord;
+#ifdef VERTEX_AO
+out float FragAO;     // Baked ambient occlusion of the vertex
+#endif
+#ifdef VERTEX_COLORS
+out vec3 FragVertexColor; // Color of the vertex
+#endif
 
 void main() {
 
+    #include <instance_vertex>
+
     // Transform this vertex position to camera coordinates.
-    Position = vec3(ModelViewMatrix * vec4(VertexPosition, 1.0));
+    Position = vec3(ModelViewMatrix * instanceMatrix * vec4(VertexPosition, 1.0));
 
     // Transform this vertex normal to camera coordinates.
-    Normal = normalize(NormalMatrix * VertexNormal);
+    Normal = normalize(NormalMatrix * instanceNormalMatrix * VertexNormal);
 
     // Calculate the direction vector from the vertex to the camera
     // The camera is at 0,0,0
@@ -371,9 +470,15 @@ void main() {
 
     // Output texture coordinates to fragment shader
     FragTexcoord = VertexTexcoord;
+#ifdef VERTEX_AO
+    FragAO = VertexColor.r;
+#endif
+#ifdef VERTEX_COLORS
+    FragVertexColor = VertexColor;
+#endif
 
     vec3 vPosition = VertexPosition;
-    mat4 finalWorld = mat4(1.0);
+    mat4 finalWorld = instanceMatrix;
     #include <morphtarget_vertex>
     #include <bones_vertex>
 
@@ -395,6 +500,8 @@ const physical_fragment_source = `//
 //     https://github.com/KhronosGroup/glTF-WebGL-PBR/#environment-maps
 // [4] "An Inexpensive BRDF Model for Physically based Rendering" by Christophe Schlick
 //     https://www.cs.virginia.edu/~jdl/bib/appearance/analytic%20models/schlick94b.pdf
+// [5] Physically Based Shading on Mobile by Brian Karis
+//     https://www.unrealengine.com/en-US/blog/physically-based-shading-on-mobile
 
 //#extension GL_EXT_shader_texture_lod: enable
 //#extension GL_OES_standard_derivatives : enable
@@ -404,12 +511,6 @@ precision highp float;
 //uniform vec3 u_LightDirection;
 //uniform vec3 u_LightColor;
 
-//#ifdef USE_IBL
-//uniform samplerCube u_DiffuseEnvSampler;
-//uniform samplerCube u_SpecularEnvSampler;
-//uniform sampler2D u_brdfLUT;
-//#endif
-
 #ifdef HAS_BASECOLORMAP
 uniform sampler2D uBaseColorSampler;
 #endif
@@ -437,15 +538,25 @@ uniform vec4 Material[3];
 #define uRoughnessFactor    Material[2].y
 
 #include <lights>
+#include <shadows>
+#include <ssao>
+#include <fog>
+#include <envmap>
 
 // Inputs from vertex shader
 in vec3 Position;       // Vertex position in camera coordinates.
 in vec3 Normal;         // Vertex normal in camera coordinates.
 in vec3 CamDir;         // Direction from vertex to camera
 in vec2 FragTexcoord;
+#ifdef VERTEX_AO
+in float FragAO;        // Baked ambient occlusion interpolated from the vertices
+#endif
+#ifdef VERTEX_COLORS
+in vec3 FragVertexColor; // Color interpolated from the vertices
+#endif
 
 // Final fragment color
-out vec4 FragColor;
+#include <oit_declaration>
 
 // Encapsulate the various inputs used by the various functions in the shading equation
 // We store values in this struct to simplify the integration of alternative implementations
@@ -524,32 +635,27 @@ vec3 getNormal()
     return n;
 }
 
-// Calculation of the lighting contribution from an optional Image Based Light source.
-// Precomputed Environment Maps are required uniform inputs and are computed as outlined in [1].
-// See our README.md on Environment Maps [3] for additional discussion.
-vec3 getIBLContribution(PBRInfo pbrInputs, PBRLightInfo pbrLight, vec3 n, vec3 reflection)
+#ifdef ENV_MAP
+// Calculation of the lighting contribution from the environment map of the nearest reflection probe.
+// The prefiltered environment maps of [1] are approximated by the mipmaps of the cube map and
+// the scale and bias to F0 of the precomputed BRDF texture by the analytical approximation of [5].
+vec3 getIBLContribution(PBRInfo pbrInputs, vec3 n, vec3 v)
 {
-    float mipCount = 9.0; // resolution of 512x512
-    float lod = (pbrInputs.perceptualRoughness * mipCount);
-    // retrieve a scale and bias to F0. See [1], Figure 3
-    vec3 brdf = vec3(0.5,0.5,0.5);//SRGBtoLINEAR(texture(u_brdfLUT, vec2(pbrLight.NdotV, 1.0 - pbrInputs.perceptualRoughness))).rgb;
-    vec3 diffuseLight = vec3(0.5,0.5,0.5);//SRGBtoLINEAR(textureCube(u_DiffuseEnvSampler, n)).rgb;
-
-//#ifdef USE_TEX_LOD
-//    vec3 specularLight = SRGBtoLINEAR(textureCubeLodEXT(u_SpecularEnvSampler, reflection, lod)).rgb;
-//#else
-    vec3 specularLight = vec3(0.5,0.5,0.5);//SRGBtoLINEAR(textureCube(u_SpecularEnvSampler, reflection)).rgb;
-//#endif
+    float NdotV = clamp(dot(n, v), 0.001, 1.0);
+    const vec4 c0 = vec4(-1.0, -0.0275, -0.572, 0.022);
+    const vec4 c1 = vec4(1.0, 0.0425, 1.04, -0.04);
+    vec4 r = pbrInputs.perceptualRoughness * c0 + c1;
+    float a004 = min(r.x * r.x, exp2(-9.28 * NdotV)) * r.x + r.y;
+    vec2 brdf = vec2(-1.04, 1.04) * a004 + r.zw;
+
+    vec3 diffuseLight = SRGBtoLINEAR(vec4(envIrradiance(n), 1.0)).rgb;
+    vec3 specularLight = SRGBtoLINEAR(vec4(envReflection(n, v, pbrInputs.perceptualRoughness), 1.0)).rgb;
 
     vec3 diffuse = diffuseLight * pbrInputs.diffuseColor;
     vec3 specular = specularLight * (pbrInputs.specularColor * brdf.x + brdf.y);
-
-    // For presentation, this allows us to disable IBL terms
-//    diffuse *= u_ScaleIBLAmbient.x;
-//    specular *= u_ScaleIBLAmbient.y;
-
-    return diffuse + specular;
+    return (diffuse + specular) * EnvMapParams.x;
 }
+#endif
 
 // Basic Lambertian diffuse
 // Implementation from Lambert's Photometria https://archive.org/details/lambertsphotome00lambgoog
@@ -652,6 +758,9 @@ void main() {
 #else
     vec4 baseColor = uBaseColor;
 #endif
+#ifdef VERTEX_COLORS
+    baseColor.rgb *= FragVertexColor;
+#endif
 
     vec3 f0 = vec3(0.04);
     vec3 diffuseColor = baseColor.rgb * (vec3(1.0) - f0);
@@ -683,19 +792,39 @@ void main() {
 
 #if AMB_LIGHTS>0
     // Ambient lights
+    vec3 ambient = vec3(0.0);
     for (int i = 0; i < AMB_LIGHTS; i++) {
-        color += AmbientLightColor[i] * pbrInputs.diffuseColor;
+        ambient += AmbientLightColor(i) * pbrInputs.diffuseColor;
     }
+#ifdef SSAO
+    ambient *= ambientOcclusion();
+#endif
+#ifdef VERTEX_AO
+    ambient *= FragAO;
+#endif
+    color += ambient;
 #endif
 
 #if DIR_LIGHTS>0
+#if DIR_SHADOWS>0
+    // Shadows of the directional lights which cast shadows
+    vec3 shadowPosition = Position;
+    float dirShadows[DIR_SHADOWS];
+    #include <dir_shadow> [DIR_SHADOWS]
+#endif
     // Directional lights
     for (int i = 0; i < DIR_LIGHTS; i++) {
         // Diffuse reflection
         // DirLightPosition is the direction of the current light
         vec3 lightDirection = normalize(DirLightPosition(i));
+        vec3 lightColor = DirLightColor(i);
+#if DIR_SHADOWS>0
+        if (i < DIR_SHADOWS) {
+            lightColor *= dirShadows[i];
+        }
+#endif
         // PBR
-        color += pbrModel(pbrInputs, DirLightColor(i), lightDirection);
+        color += pbrModel(pbrInputs, lightColor, lightDirection);
     }
 #endif
 
@@ -745,9 +874,9 @@ void main() {
 #endif
 
     // Calculate lighting contribution from image based lighting source (IBL)
-//#ifdef USE_IBL
-//    color += getIBLContribution(pbrInputs, n, reflection);
-//#endif
+#ifdef ENV_MAP
+    color += getIBLContribution(pbrInputs, getNormal(), normalize(CamDir));
+#endif
 
     // Apply optional PBR terms for additional (optional) shading
 #ifdef HAS_OCCLUSIONMAP
@@ -794,6 +923,13 @@ void main() {
 
     // Final fragment color
     FragColor = vec4(pow(color,vec3(1.0/2.2)), baseColor.a);
+
+    // Fades the color to the fog color with the distance from the camera
+    #ifdef FOG
+        FragColor.rgb = applyFog(FragColor.rgb, length(Position.xyz));
+    #endif
+
+    #include <oit_fragment>
 }
 `
 
@@ -826,7 +962,11 @@ void main() {
     gl_PointSize = MatPointSize / -posMV.z;
 
     // Outputs color
+#ifdef VERTEX_COLORS
+    Color = VertexColor;
+#else
     Color = MatEmissiveColor;
+#endif
 }
 
 `
@@ -846,14 +986,22 @@ uniform mat4 MVP;
 out vec4 Position;
 out vec3 Normal;
 out vec2 FragTexcoord;
+#ifdef VERTEX_AO
+out float FragAO;     // Baked ambient occlusion of the vertex
+#endif
+#ifdef VERTEX_COLORS
+out vec3 FragVertexColor; // Color of the vertex
+#endif
 
 void main() {
 
+    #include <instance_vertex>
+
     // Transform vertex position to camera coordinates
-    Position = ModelViewMatrix * vec4(VertexPosition, 1.0);
+    Position = ModelViewMatrix * instanceMatrix * vec4(VertexPosition, 1.0);
 
     // Transform vertex normal to camera coordinates
-    Normal = normalize(NormalMatrix * VertexNormal);
+    Normal = normalize(NormalMatrix * instanceNormalMatrix * VertexNormal);
 
     vec2 texcoord = VertexTexcoord;
 #if MAT_TEXTURES > 0
@@ -863,8 +1011,14 @@ void main() {
     }
 #endif
     FragTexcoord = texcoord;
+#ifdef VERTEX_AO
+    FragAO = VertexColor.r;
+#endif
+#ifdef VERTEX_COLORS
+    FragVertexColor = VertexColor;
+#endif
     vec3 vPosition = VertexPosition;
-    mat4 finalWorld = mat4(1.0);
+    mat4 finalWorld = instanceMatrix;
     #include <morphtarget_vertex>
     #include <bones_vertex>
 
@@ -883,8 +1037,10 @@ out vec3 Color;
 
 void main() {
 
+    #include <instance_vertex>
+
     Color = VertexColor;
-    gl_Position = MVP * vec4(VertexPosition, 1.0);
+    gl_Position = MVP * instanceMatrix * vec4(VertexPosition, 1.0);
 }
 `
 
@@ -894,13 +1050,18 @@ const standard_fragment_source = `precision highp float;
 in vec4 Position;     // Fragment position in camera coordinates
 in vec3 Normal;       // Fragment normal in camera coordinates
 in vec2 FragTexcoord; // Fragment texture coordinates
+#ifdef VERTEX_COLORS
+in vec3 FragVertexColor; // Fragment color interpolated from the vertices
+#endif
 
 #include <lights>
 #include <material>
 #include <phong_model>
+#include <fog>
+#include <envmap>
 
 // Final fragment color
-out vec4 FragColor;
+#include <oit_declaration>
 
 void main() {
 
@@ -944,6 +1105,10 @@ void main() {
     // Combine material with texture colors
     vec4 matDiffuse = vec4(MatDiffuseColor, MatOpacity) * texMixed;
     vec4 matAmbient = vec4(MatAmbientColor, MatOpacity) * texMixed;
+#ifdef VERTEX_COLORS
+    matDiffuse.rgb *= FragVertexColor;
+    matAmbient.rgb *= FragVertexColor;
+#endif
 
     // Normalize interpolated normal as it may have shrinked
     vec3 fragNormal = normalize(Normal);
@@ -965,6 +1130,18 @@ void main() {
 
     // Final fragment color
     FragColor = min(vec4(Ambdiff + Spec, matDiffuse.a), vec4(1.0));
+
+    // Replaces a fraction of the color by the reflected environment
+    #ifdef ENV_MAP
+        FragColor.rgb = mix(FragColor.rgb, envReflection(fragNormal, camDir, 0.0), EnvMapParams.x);
+    #endif
+
+    // Fades the color to the fog color with the distance from the camera
+    #ifdef FOG
+        FragColor.rgb = applyFog(FragColor.rgb, length(Position.xyz));
+    #endif
+
+    #include <oit_fragment>
 }
 `
 
@@ -993,11 +1170,13 @@ void main() {
 const basic_fragment_source = `precision highp float;
 
 in vec3 Color;
-out vec4 FragColor;
+#include <oit_declaration>
 
 void main() {
 
     FragColor = vec4(Color, 1.0);
+
+    #include <oit_fragment>
 }
 `
 
@@ -1026,6 +1205,24 @@ uniform vec4 Panel[8];
 #define PaddingColor	Panel[5]		  // panel padding color
 #define ContentColor	Panel[6]		  // panel content color
 #define TextureValid	bool(Panel[7].x)  // texture valid flag
+#define ClipValid		bool(Panel[7].y)  // clip shape valid flag
+#define NinePatchValid	bool(Panel[7].z)  // nine-patch valid flag
+#define Opacity			Panel[7].w		  // opacity of the panel and its ancestors
+
+// Clip shape uniforms
+uniform vec4 PanelClip[2];
+#define ClipRect		PanelClip[0]		// clip rectangle in pixels relative to the panel
+#define ClipRadius		PanelClip[1].x		// clip rectangle corners radius in pixels
+#define ClipMaskValid	bool(PanelClip[1].y) // clip mask texture valid flag
+#define PanelSize		PanelClip[1].zw		// panel size in pixels
+
+// Clip mask texture
+uniform sampler2D MaskTexture;
+
+// Nine-patch uniforms
+uniform vec4 PanelNinePatch[2];
+#define NinePatchTex		PanelNinePatch[0]	// insets (top, right, bottom, left) in texture coordinates
+#define NinePatchContent	PanelNinePatch[1]	// insets (top, right, bottom, left) in content area coordinates
 
 // Output
 out vec4 FragColor;
@@ -1057,19 +1254,50 @@ bool checkRect(vec4 rect) {
 }
 
 
-void main() {
+/***
+* Returns the coverage [0,1] of the current fragment by the clip shape:
+* the rounded clip rectangle multiplied by the alpha of the optional mask texture.
+*/
+float clipCoverage() {
 
-    // Discard fragment outside of received bounds
-    // Bounds[0] - xmin
-    // Bounds[1] - ymin
-    // Bounds[2] - xmax
-    // Bounds[3] - ymax
-    if (FragTexcoord.x <= Bounds[0] || FragTexcoord.x >= Bounds[2]) {
-        discard;
+    vec2 pos = FragTexcoord * PanelSize - ClipRect.xy;
+    vec2 halfSize = ClipRect.zw * 0.5;
+    float radius = min(ClipRadius, min(halfSize.x, halfSize.y));
+
+    // Signed distance to the rounded rectangle
+    vec2 q = abs(pos - halfSize) - (halfSize - vec2(radius));
+    float dist = length(max(q, 0.0)) + min(max(q.x, q.y), 0.0) - radius;
+    float coverage = clamp(0.5 - dist, 0.0, 1.0);
+
+    if (ClipMaskValid) {
+        coverage *= texture(MaskTexture, pos / ClipRect.zw).a;
     }
-    if (FragTexcoord.y <= Bounds[1] || FragTexcoord.y >= Bounds[3]) {
-        discard;
+    return coverage;
+}
+
+
+/***
+* Maps the specified content area coordinate of one axis to the texture coordinate
+* of a nine-patch texture, keeping the size of the insets at both ends
+* and stretching the center between them.
+*/
+float ninePatch(float t, float contentStart, float contentEnd, float texStart, float texEnd) {
+
+    if (t < contentStart) {
+        return t / contentStart * texStart;
+    }
+    if (t > 1.0 - contentEnd) {
+        return 1.0 - (1.0 - t) / contentEnd * texEnd;
     }
+    return texStart + (t - contentStart) / (1.0 - contentStart - contentEnd) * (1.0 - texStart - texEnd);
+}
+
+
+/***
+* Returns the color of the current fragment
+* depending on the panel area it is in.
+*/
+vec4 panelColor() {
 
     // Check if fragment is inside content area
     if (checkRect(Content)) {
@@ -1082,6 +1310,10 @@ void main() {
             vec2 offset = vec2(-Content[0], -Content[1]);
             vec2 factor = vec2(1.0/Content[2], 1.0/Content[3]);
             vec2 texcoord = (FragTexcoord + offset) * factor;
+            if (NinePatchValid) {
+                texcoord.x = ninePatch(texcoord.x, NinePatchContent.w, NinePatchContent.y, NinePatchTex.w, NinePatchTex.y);
+                texcoord.y = ninePatch(texcoord.y, NinePatchContent.x, NinePatchContent.z, NinePatchTex.x, NinePatchTex.z);
+            }
             vec4 texColor = texture(MatTexture, texcoord * MatTexRepeat + MatTexOffset);
 
             // Mix content color with texture color.
@@ -1104,25 +1336,819 @@ void main() {
             // Un-alpha-premultiply
             color.rgb /= color.a;
 		}
-
-        FragColor = color;
-        return;
+        return color;
     }
 
     // Checks if fragment is inside paddings area
     if (checkRect(Padding)) {
-        FragColor = PaddingColor;
-        return;
+        return PaddingColor;
     }
 
     // Checks if fragment is inside borders area
     if (checkRect(Border)) {
-        FragColor = BorderColor;
-        return;
+        return BorderColor;
     }
 
     // Fragment is in margins area (always transparent)
-    FragColor = vec4(1,1,1,0);
+    return vec4(1,1,1,0);
+}
+
+
+void main() {
+
+    // Discard fragment outside of received bounds
+    // Bounds[0] - xmin
+    // Bounds[1] - ymin
+    // Bounds[2] - xmax
+    // Bounds[3] - ymax
+    if (FragTexcoord.x <= Bounds[0] || FragTexcoord.x >= Bounds[2]) {
+        discard;
+    }
+    if (FragTexcoord.y <= Bounds[1] || FragTexcoord.y >= Bounds[3]) {
+        discard;
+    }
+
+    vec4 color = panelColor();
+
+    // Applies the clip shape of this panel or of its clipping ancestor
+    if (ClipValid) {
+        float coverage = clipCoverage();
+        if (coverage <= 0.0) {
+            discard;
+        }
+        color.a *= coverage;
+    }
+    color.a *= Opacity;
+    FragColor = color;
+}
+`
+
+const include_shadows_source = `//
+// Directional light shadows with cascaded shadow maps
+//
+#if DIR_SHADOWS>0
+
+// dirShadowCascade returns the fraction of the fragment at the specified position
+// in camera coordinates which is lit in the specified cascade of the shadow map
+// of the specified directional light, using 3x3 percentage closer filtering.
+float dirShadowCascade(sampler2D shadowMap, int light, int cascade, vec3 position) {
+
+    vec4 coord = DirShadowMatrix[SHADOW_CASCADES*light + cascade] * vec4(position, 1.0);
+    if (coord.z > 1.0) { // Beyond the far plane of the light
+        return 1.0;
+    }
+
+    // Limits of the cascade in the shadow map to avoid sampling neighbour cascades
+    float count = float(DirShadowCascades(light));
+    vec2 texel = vec2(DirShadowTexel(light) / count, DirShadowTexel(light));
+    float minX = float(cascade) / count + texel.x * 0.5;
+    float maxX = float(cascade + 1) / count - texel.x * 0.5;
+
+    float lit = 0.0;
+    for (int x = -1; x <= 1; x++) {
+        for (int y = -1; y <= 1; y++) {
+            vec2 uv = coord.xy + vec2(x, y) * texel;
+            uv.x = clamp(uv.x, minX, maxX);
+            float depth = texture(shadowMap, uv).r;
+            lit += (coord.z - DirShadowBias(light) > depth) ? 0.0 : 1.0;
+        }
+    }
+    return lit / 9.0;
+}
+
+// dirShadow returns the fraction of the fragment at the specified position in camera coordinates
+// which is lit by the specified directional light. The cascade is selected by the distance of the
+// fragment from the camera and blended with the next cascade near its far distance.
+// The last cascade fades out to no shadow.
+float dirShadow(sampler2D shadowMap, int light, vec3 position) {
+
+    float dist = -position.z;
+    int count = DirShadowCascades(light);
+    float near = 0.0;
+    for (int c = 0; c < count; c++) {
+        float far = DirShadowSplits[light][c];
+        if (dist <= far) {
+            float lit = dirShadowCascade(shadowMap, light, c, position);
+            float blendStart = far - (far - near) * DirShadowBlend(light);
+            if (dist > blendStart) {
+                float next = 1.0;
+                if (c < count - 1) {
+                    next = dirShadowCascade(shadowMap, light, c + 1, position);
+                }
+                lit = mix(lit, next, (dist - blendStart) / (far - blendStart));
+            }
+            return lit;
+        }
+        near = far;
+    }
+    return 1.0;
+}
+
+#endif
+`
+
+const include_dir_shadow_source = `    dirShadows[{i}] = dirShadow(DirShadowMap[{i}], {i}, shadowPosition);
+`
+
+const shadow_fragment_source = `//
+// Shadow map depth pass - Fragment Shader
+// Only the depth is written to the shadow map
+//
+precision highp float;
+
+void main() {
+}
+`
+
+const shadow_vertex_source = `//
+// Shadow map depth pass - Vertex Shader
+//
+#include <attributes>
+
+// Model uniforms
+uniform mat4 MVP;
+
+#include <morphtarget_vertex_declaration>
+#include <bones_vertex_declaration>
+
+void main() {
+
+    #include <instance_vertex>
+
+    vec3 vPosition = VertexPosition;
+    mat4 finalWorld = instanceMatrix;
+    #include <morphtarget_vertex>
+    #include <bones_vertex>
+
+    // Output vertex position projected in the light space
+    gl_Position = MVP * finalWorld * vec4(vPosition, 1.0);
+}
+`
+
+const include_oit_declaration_source = `//
+// Fragment outputs for order independent transparency
+//
+#ifdef OIT
+layout(location = 0) out vec4 FragColor;  // Weighted premultiplied color and alpha
+layout(location = 1) out vec4 FragWeight; // Weighted alpha
+#else
+out vec4 FragColor;
+#endif
+`
+
+const include_oit_fragment_source = `    #ifdef OIT
+    // Weighted blended order independent transparency:
+    // converts the final color to the weighted premultiplied color with the alpha
+    // used to compute the revealage and the weighted alpha used to normalize the color.
+    {
+        float oitAlpha = FragColor.a;
+        float oitWeight = clamp(pow(min(1.0, oitAlpha * 10.0) + 0.01, 3.0) * 1e8 * pow(1.0 - gl_FragCoord.z * 0.9, 3.0), 1e-2, 3e3);
+        FragColor = vec4(FragColor.rgb * oitAlpha * oitWeight, oitAlpha);
+        FragWeight = vec4(oitAlpha * oitWeight);
+    }
+    #endif
+`
+
+const oit_composite_fragment_source = `//
+// Order independent transparency composite pass - Fragment Shader
+// Blends the average color of the transparent fragments over the opaque scene
+//
+precision highp float;
+
+in vec2 FragTexcoord;
+
+uniform sampler2D OITAccum;  // Weighted premultiplied colors and revealage
+uniform sampler2D OITWeight; // Weighted alphas
+
+out vec4 FragColor;
+
+void main() {
+
+    vec4 accum = texture(OITAccum, FragTexcoord);
+    float revealage = accum.a;
+    if (revealage >= 1.0) {
+        discard;
+    }
+    float weight = texture(OITWeight, FragTexcoord).r;
+    FragColor = vec4(accum.rgb / max(weight, 1e-5), 1.0 - revealage);
+}
+`
+
+const oit_composite_vertex_source = `//
+// Order independent transparency composite pass - Vertex Shader
+// Generates a triangle covering the whole viewport without vertex attributes
+//
+
+// Output texture coordinates
+out vec2 FragTexcoord;
+
+void main() {
+
+    vec2 pos = vec2(float((gl_VertexID << 1) & 2), float(gl_VertexID & 2));
+    FragTexcoord = pos;
+    gl_Position = vec4(pos * 2.0 - 1.0, 0.0, 1.0);
+}
+`
+
+const include_ocean_source = `//
+// Ocean material uniforms
+//
+
+// Gerstner waves: direction (xy), steepness (z) and wavelength (w)
+#if OCEAN_WAVES > 0
+uniform vec4 OceanWaves[OCEAN_WAVES];
+#endif
+
+// Ocean parameters uniform array
+uniform vec4 Ocean[4];
+// Macros to access elements inside the Ocean array
+#define OceanTime           Ocean[0].x
+#define OceanNormalStrength Ocean[0].y
+#define OceanNormalScale    Ocean[0].z
+#define OceanFoamThreshold  Ocean[0].w
+#define OceanNormalSpeed    Ocean[1].xy
+#define OceanFoamSoftness   Ocean[1].z
+#define OceanFresnel        Ocean[1].w
+#define OceanCrestColor     Ocean[2].rgb
+#define OceanCrestHeight    Ocean[2].w
+#define OceanFoamColor      Ocean[3].rgb
+#define OceanFoamIntensity  Ocean[3].w
+`
+
+const ocean_vertex_source = `//
+// Ocean material - Vertex Shader
+// Displaces the vertices of a plane in the XY plane with a sum of Gerstner waves.
+// The wave heights are along the Z axis.
+//
+#include <attributes>
+
+// Model uniforms
+uniform mat4 ModelViewMatrix;
+uniform mat3 NormalMatrix;
+uniform mat4 MVP;
+
+#include <ocean>
+
+// Output variables for Fragment shader
+out vec4 Position;     // Vertex position in camera coordinates
+out vec3 Normal;       // Vertex normal in camera coordinates
+out vec3 Tangent;      // Vertex tangent in camera coordinates
+out vec3 Bitangent;    // Vertex bitangent in camera coordinates
+out vec2 FragTexcoord; // Plane coordinates used to sample the normal map
+out float Height;      // Wave height
+out float Foam;        // Wave compression (one minus the jacobian of the horizontal displacement)
+
+void main() {
+
+    vec3 pos = VertexPosition;
+    vec3 tangent = vec3(1.0, 0.0, 0.0);
+    vec3 bitangent = vec3(0.0, 1.0, 0.0);
+    float jxx = 1.0;
+    float jyy = 1.0;
+    float jxy = 0.0;
+#if OCEAN_WAVES > 0
+    for (int i = 0; i < OCEAN_WAVES; i++) {
+        vec4 wave = OceanWaves[i];
+        vec2 d = normalize(wave.xy);
+        float steepness = wave.z;
+        float k = 2.0 * 3.14159265 / wave.w;
+        float c = sqrt(9.8 / k);
+        float f = k * (dot(d, VertexPosition.xy) - c * OceanTime);
+        float a = steepness / k;
+        float sinf = sin(f);
+        float cosf = cos(f);
+        pos.xy += d * (a * cosf);
+        pos.z += a * sinf;
+        // Partial derivatives of the displaced position
+        tangent += vec3(-d.x * d.x * steepness * sinf, -d.x * d.y * steepness * sinf, d.x * steepness * cosf);
+        bitangent += vec3(-d.x * d.y * steepness * sinf, -d.y * d.y * steepness * sinf, d.y * steepness * cosf);
+        jxx -= d.x * d.x * steepness * sinf;
+        jyy -= d.y * d.y * steepness * sinf;
+        jxy -= d.x * d.y * steepness * sinf;
+    }
+#endif
+    vec3 normal = normalize(cross(tangent, bitangent));
+
+    Position = ModelViewMatrix * vec4(pos, 1.0);
+    Normal = normalize(NormalMatrix * normal);
+    Tangent = normalize(NormalMatrix * tangent);
+    Bitangent = normalize(NormalMatrix * bitangent);
+    FragTexcoord = pos.xy * OceanNormalScale;
+    Height = pos.z;
+    Foam = 1.0 - (jxx * jyy - jxy * jxy);
+    gl_Position = MVP * vec4(pos, 1.0);
+}
+`
+
+const ocean_fragment_source = `//
+// Ocean material - Fragment Shader
+// Phong lighting of the wave surface with animated detail normals, crest color, foam and fresnel
+//
+precision highp float;
+
+// Inputs from vertex shader
+in vec4 Position;     // Fragment position in camera coordinates
+in vec3 Normal;       // Fragment normal in camera coordinates
+in vec3 Tangent;      // Fragment tangent in camera coordinates
+in vec3 Bitangent;    // Fragment bitangent in camera coordinates
+in vec2 FragTexcoord; // Plane coordinates used to sample the normal map
+in float Height;      // Wave height
+in float Foam;        // Wave compression
+
+#include <lights>
+#include <material>
+#include <phong_model>
+#include <ocean>
+#include <fog>
+
+#ifdef HAS_NORMALMAP
+uniform sampler2D OceanNormalSampler;
+#endif
+
+// Final fragment color
+#include <oit_declaration>
+
+void main() {
+
+    vec3 normal = normalize(Normal);
+
+#ifdef HAS_NORMALMAP
+    // Blends two samples of the normal map scrolling in different directions
+    vec2 offset = OceanNormalSpeed * OceanTime;
+    vec3 n1 = texture(OceanNormalSampler, FragTexcoord + offset).rgb * 2.0 - 1.0;
+    vec3 n2 = texture(OceanNormalSampler, FragTexcoord * 1.37 - offset.yx).rgb * 2.0 - 1.0;
+    vec3 detail = normalize(vec3(n1.xy + n2.xy, n1.z * n2.z));
+    detail.xy *= OceanNormalStrength;
+    mat3 tbn = mat3(normalize(Tangent), normalize(Bitangent), normal);
+    normal = normalize(tbn * detail);
+#endif
+
+    // Calculate the direction vector from the fragment to the camera (origin)
+    vec3 camDir = normalize(-Position.xyz);
+    if (dot(normal, camDir) < 0.0) { // Seen from below
+        normal = -normal;
+    }
+
+    // Mixes the water and crest colors by the wave height
+    float crest = OceanCrestHeight > 0.0 ? clamp(Height / OceanCrestHeight, 0.0, 1.0) : 0.0;
+    vec3 color = mix(MatDiffuseColor, OceanCrestColor, crest);
+
+    vec3 Ambdiff, Spec;
+    phongModel(Position, normal, camDir, color, color, Ambdiff, Spec);
+
+    // Surfaces seen at grazing angles reflect more
+    float fresnel = pow(1.0 - max(dot(normal, camDir), 0.0), 5.0) * OceanFresnel;
+    vec3 water = Ambdiff + Spec * (1.0 + fresnel);
+
+    // Foam where the waves are compressed
+    float foam = smoothstep(OceanFoamThreshold, OceanFoamThreshold + OceanFoamSoftness, Foam) * OceanFoamIntensity;
+    water = mix(water, OceanFoamColor, clamp(foam, 0.0, 1.0));
+
+    float alpha = clamp(MatOpacity + fresnel + foam, 0.0, 1.0);
+    FragColor = min(vec4(water, alpha), vec4(1.0));
+
+    // Fades the color to the fog color with the distance from the camera
+    #ifdef FOG
+        FragColor.rgb = applyFog(FragColor.rgb, length(Position.xyz));
+    #endif
+
+    #include <oit_fragment>
+}
+`
+
+const include_instance_vertex_source = `    // Model matrix of the instance relative to the graphic and its normal matrix
+#ifdef INSTANCED
+    mat4 instanceMatrix = mat4(InstanceMatrix0, InstanceMatrix1, InstanceMatrix2, InstanceMatrix3);
+    mat3 instanceNormalMatrix = transpose(inverse(mat3(instanceMatrix)));
+#else
+    mat4 instanceMatrix = mat4(1.0);
+    mat3 instanceNormalMatrix = mat3(1.0);
+#endif
+`
+
+const include_fog_source = `//
+// Fog uniforms and functions
+//
+#ifdef FOG
+
+// Fog uniform array: color and parameters (near distance, far distance, density)
+uniform vec3 Fog[2];
+// Macros to access elements inside the Fog array
+#define FogColor    Fog[0]
+#define FogNear     Fog[1].x
+#define FogFar      Fog[1].y
+#define FogDensity  Fog[1].z
+
+// Returns the specified color faded to the fog color by the specified distance from the camera
+vec3 applyFog(vec3 color, float dist) {
+
+    #if FOG == 1
+        float fogFactor = clamp((dist - FogNear) / (FogFar - FogNear), 0.0, 1.0);
+    #elif FOG == 2
+        float fogFactor = 1.0 - exp(-FogDensity * dist);
+    #else
+        float fogDist = FogDensity * dist;
+        float fogFactor = 1.0 - exp(-fogDist * fogDist);
+    #endif
+    return mix(color, FogColor, fogFactor);
+}
+
+#endif
+`
+
+const pick_vertex_source = `//
+// Pick ids pass - Vertex Shader
+//
+#include <attributes>
+
+// Model uniforms
+uniform mat4 MVP;
+
+#include <morphtarget_vertex_declaration>
+#include <bones_vertex_declaration>
+
+void main() {
+
+    #include <instance_vertex>
+
+    vec3 vPosition = VertexPosition;
+    mat4 finalWorld = instanceMatrix;
+    #include <morphtarget_vertex>
+    #include <bones_vertex>
+
+    // Output vertex position projected in the camera space
+    gl_Position = MVP * finalWorld * vec4(vPosition, 1.0);
+}
+`
+
+const pick_fragment_source = `//
+// Pick ids pass - Fragment Shader
+// Writes the id of the graphic encoded as a color
+//
+precision highp float;
+
+// Pick id of the graphic
+uniform vec4 PickID;
+
+// Output
+out vec4 FragColor;
+
+void main() {
+
+    FragColor = PickID;
+}
+`
+
+const ssao_fragment_source = `//
+// Screen-space ambient occlusion and blur passes - Fragment Shader
+// Calculates the ambient occlusion of the opaque objects from their normals and depth
+// sampling a hemisphere kernel around the normal of each fragment or, if SSAO_BLUR
+// is defined, blurs it with a 4x4 box filter which removes the noise of the kernel rotations
+//
+precision highp float;
+
+in vec2 FragTexcoord;
+
+out vec4 FragColor;
+
+#ifdef SSAO_BLUR
+
+uniform sampler2D SSAOInput; // Ambient occlusion to be blurred
+
+void main() {
+
+    vec2 texel = 1.0 / vec2(textureSize(SSAOInput, 0));
+    float sum = 0.0;
+    for (int x = -2; x < 2; x++) {
+        for (int y = -2; y < 2; y++) {
+            sum += texture(SSAOInput, FragTexcoord + vec2(float(x), float(y)) * texel).r;
+        }
+    }
+    FragColor = vec4(sum / 16.0);
+}
+
+#else
+
+uniform sampler2D SSAONormal;          // Normals in camera coordinates (alpha is zero where there is no object)
+uniform sampler2D SSAODepth;           // Depth of the opaque objects
+uniform sampler2D SSAONoise;           // 4x4 random vectors which rotate the kernel around the normals
+uniform vec3 SSAOKernel[SSAO_SAMPLES]; // Sample offsets in the unit hemisphere around +Z
+uniform mat4 SSAOProj;                 // Camera projection matrix
+uniform mat4 SSAOInvProj;              // Inverse of the camera projection matrix
+uniform vec4 SSAOParams;               // Radius, depth bias and intensity
+
+// Returns the position in camera coordinates of the opaque object at the specified texture coordinates
+vec3 viewPosition(vec2 uv) {
+
+    float depth = texture(SSAODepth, uv).r;
+    vec4 pos = SSAOInvProj * vec4(vec3(uv, depth) * 2.0 - 1.0, 1.0);
+    return pos.xyz / pos.w;
+}
+
+void main() {
+
+    vec4 normalSample = texture(SSAONormal, FragTexcoord);
+    if (normalSample.a == 0.0) {
+        FragColor = vec4(1.0);
+        return;
+    }
+    float radius = SSAOParams.x;
+    float bias = SSAOParams.y;
+    vec3 position = viewPosition(FragTexcoord);
+    vec3 normal = normalize(normalSample.xyz);
+
+    // Tangent space around the normal randomly rotated with the noise tiled over the viewport
+    vec3 random = vec3(texture(SSAONoise, gl_FragCoord.xy / 4.0).xy, 0.0);
+    vec3 tangent = normalize(random - normal * dot(random, normal));
+    vec3 bitangent = cross(normal, tangent);
+    mat3 tbn = mat3(tangent, bitangent, normal);
+
+    // Counts the samples of the hemisphere which are behind the opaque objects,
+    // ignoring the objects much farther than the radius from the fragment
+    float occlusion = 0.0;
+    for (int i = 0; i < SSAO_SAMPLES; i++) {
+        vec3 samplePos = position + tbn * SSAOKernel[i] * radius;
+        vec4 clip = SSAOProj * vec4(samplePos, 1.0);
+        vec2 uv = clip.xy / clip.w * 0.5 + 0.5;
+        if (uv.x < 0.0 || uv.y < 0.0 || uv.x > 1.0 || uv.y > 1.0) {
+            continue;
+        }
+        float sceneZ = viewPosition(uv).z;
+        float range = smoothstep(0.0, 1.0, radius / max(abs(position.z - sceneZ), 1e-5));
+        occlusion += (sceneZ >= samplePos.z + bias ? 1.0 : 0.0) * range;
+    }
+    float ao = 1.0 - occlusion / float(SSAO_SAMPLES);
+    FragColor = vec4(pow(ao, SSAOParams.z));
+}
+
+#endif
+`
+
+const ssao_normal_fragment_source = `//
+// Screen-space ambient occlusion normal and depth prepass - Fragment Shader
+// Writes the normal in camera coordinates facing the camera
+//
+precision highp float;
+
+in vec3 Normal;
+
+// Output
+out vec4 FragColor;
+
+void main() {
+
+    vec3 normal = normalize(Normal);
+    if (!gl_FrontFacing) {
+        normal = -normal;
+    }
+    FragColor = vec4(normal, 1.0);
+}
+`
+
+const ssao_normal_vertex_source = `//
+// Screen-space ambient occlusion normal and depth prepass - Vertex Shader
+//
+#include <attributes>
+
+// Model uniforms
+uniform mat3 NormalMatrix;
+uniform mat4 MVP;
+
+#include <morphtarget_vertex_declaration>
+#include <bones_vertex_declaration>
+
+// Output normal in camera coordinates
+out vec3 Normal;
+
+void main() {
+
+    #include <instance_vertex>
+
+    // Transform vertex normal to camera coordinates
+    Normal = normalize(NormalMatrix * instanceNormalMatrix * VertexNormal);
+
+    vec3 vPosition = VertexPosition;
+    mat4 finalWorld = instanceMatrix;
+    #include <morphtarget_vertex>
+    #include <bones_vertex>
+
+    // Output vertex position projected in the camera space
+    gl_Position = MVP * finalWorld * vec4(vPosition, 1.0);
+}
+`
+
+const ssao_vertex_source = `//
+// Screen-space ambient occlusion and blur passes - Vertex Shader
+// Generates a triangle covering the whole viewport without vertex attributes
+//
+
+// Output texture coordinates
+out vec2 FragTexcoord;
+
+void main() {
+
+    vec2 pos = vec2(float((gl_VertexID << 1) & 2), float(gl_VertexID & 2));
+    FragTexcoord = pos;
+    gl_Position = vec4(pos * 2.0 - 1.0, 0.0, 1.0);
+}
+`
+
+const include_ssao_source = `//
+// Screen-space ambient occlusion uniforms and functions
+//
+#ifdef SSAO
+
+// Blurred ambient occlusion of the opaque objects in the viewport
+uniform sampler2D SSAOMap;
+// Origin of the viewport in window coordinates and inverse of its size
+uniform vec4 SSAOViewport;
+
+// Returns the fraction of the ambient light which reaches the current fragment
+float ambientOcclusion() {
+
+    vec2 uv = (gl_FragCoord.xy - SSAOViewport.xy) * SSAOViewport.zw;
+    return texture(SSAOMap, uv).r;
+}
+
+#endif
+`
+
+const hdr_fragment_source = `//
+// HDR passes - Fragment Shader
+// Writes the HDR colors multiplied by the exposure, tone mapped and clamped to the current frame buffer.
+// If HDR_LUMINANCE is defined writes their luminance to the metering buffer, if HDR_BRIGHT is defined
+// writes their exposed colors above the bloom threshold and if HDR_BLUR is defined blurs the input
+// in the direction of the blur step.
+//
+precision highp float;
+
+in vec2 FragTexcoord;
+
+uniform sampler2D HDRColor; // Colors of the 3D objects or input of the blur pass
+uniform float HDRExposure;  // Exposure of the camera
+#if defined(HDR_BRIGHT) || defined(HDR_BLOOM)
+uniform vec3 HDRBloomParams; // Threshold, soft knee and intensity of the bloom
+#endif
+#ifdef HDR_BLOOM
+uniform sampler2D HDRBloom; // Blurred bright colors
+#endif
+#ifdef HDR_BLUR
+uniform vec2 HDRBlurStep;   // Distance between the blur samples in texture coordinates
+#endif
+
+out vec4 FragColor;
+
+// Maps the specified exposed color to the displayable range
+vec3 toneMap(vec3 c) {
+
+#if defined(TONE_MAPPING_ACES)
+    // Narkowicz's fit of the ACES reference rendering and output transforms
+    c = (c * (2.51 * c + 0.03)) / (c * (2.43 * c + 0.59) + 0.14);
+#elif defined(TONE_MAPPING_REINHARD)
+    c = c / (1.0 + c);
+#endif
+    return clamp(c, 0.0, 1.0);
+}
+
+void main() {
+
+    vec4 color = texture(HDRColor, FragTexcoord);
+#if defined(HDR_LUMINANCE)
+    FragColor = vec4(dot(color.rgb, vec3(0.2126, 0.7152, 0.0722)));
+#elif defined(HDR_BRIGHT)
+    // Keep the part of the color above the threshold with a quadratic transition over the knee
+    vec3 c = color.rgb * HDRExposure;
+    float bright = max(c.r, max(c.g, c.b));
+    float knee = HDRBloomParams.x * HDRBloomParams.y + 1e-4;
+    float soft = clamp(bright - HDRBloomParams.x + knee, 0.0, 2.0 * knee);
+    soft = soft * soft / (4.0 * knee);
+    FragColor = vec4(c * max(soft, bright - HDRBloomParams.x) / max(bright, 1e-4), 1.0);
+#elif defined(HDR_BLUR)
+    // 9 taps gaussian blur using the linear filtering to sample two texels per fetch
+    vec3 sum = color.rgb * 0.2270270270;
+    sum += texture(HDRColor, FragTexcoord + HDRBlurStep * 1.3846153846).rgb * 0.3162162162;
+    sum += texture(HDRColor, FragTexcoord - HDRBlurStep * 1.3846153846).rgb * 0.3162162162;
+    sum += texture(HDRColor, FragTexcoord + HDRBlurStep * 3.2307692308).rgb * 0.0702702703;
+    sum += texture(HDRColor, FragTexcoord - HDRBlurStep * 3.2307692308).rgb * 0.0702702703;
+    FragColor = vec4(sum, 1.0);
+#else
+    vec3 c = color.rgb * HDRExposure;
+#ifdef HDR_BLOOM
+    c += texture(HDRBloom, FragTexcoord).rgb * HDRBloomParams.z;
+#endif
+    FragColor = vec4(toneMap(c), color.a);
+#endif
+}
+`
+
+const hdr_vertex_source = `//
+// HDR passes - Vertex Shader
+// Generates a triangle covering the whole viewport without vertex attributes
+//
+
+// Output texture coordinates
+out vec2 FragTexcoord;
+
+void main() {
+
+    vec2 pos = vec2(float((gl_VertexID << 1) & 2), float(gl_VertexID & 2));
+    FragTexcoord = pos;
+    gl_Position = vec4(pos * 2.0 - 1.0, 0.0, 1.0);
+}
+`
+
+const include_envmap_source = `//
+// Environment map uniforms and functions
+//
+#ifdef ENV_MAP
+
+#include <camera>
+
+// Cube map of the nearest reflection probe
+uniform samplerCube EnvMap;
+// Intensity and maximum mipmap level of the environment map
+uniform vec4 EnvMapParams;
+
+// Returns the color of the environment reflected by a surface with the specified roughness,
+// normal and direction to the camera in camera coordinates.
+// The rough surfaces sample the blurred mipmap levels of the cube map.
+vec3 envReflection(vec3 normal, vec3 camDir, float roughness) {
+
+    vec3 dir = mat3(CameraMatrix) * reflect(-camDir, normal);
+    return textureLod(EnvMap, dir, roughness * EnvMapParams.y).rgb;
+}
+
+// Returns the average color of the environment around the specified normal in camera coordinates
+vec3 envIrradiance(vec3 normal) {
+
+    return textureLod(EnvMap, mat3(CameraMatrix) * normal, EnvMapParams.y).rgb;
+}
+
+#endif
+`
+
+const include_camera_source = `//
+// Camera uniform block
+//
+
+// Camera matrices with the std140 layout, updated once per frame
+layout(std140) uniform Camera {
+    mat4 ViewMatrix;    // Transforms world to camera coordinates
+    mat4 ProjMatrix;    // Transforms camera to clip coordinates
+    mat4 CameraMatrix;  // Transforms camera to world coordinates
+};
+`
+
+const outline_fragment_source = `//
+// Selection outline pass - Fragment Shader
+//
+precision highp float;
+
+// Outline color
+uniform vec4 OutlineColor;
+
+// Output
+out vec4 FragColor;
+
+void main() {
+
+    FragColor = OutlineColor;
+}
+`
+
+const outline_vertex_source = `//
+// Selection outline pass - Vertex Shader
+// Extrudes the vertices along their normals projected on the screen
+//
+#include <attributes>
+
+// Model uniforms
+uniform mat4 MVP;
+
+// Outline width in normalized device coordinates
+uniform vec2 OutlineWidth;
+
+#include <morphtarget_vertex_declaration>
+#include <bones_vertex_declaration>
+
+void main() {
+
+    #include <instance_vertex>
+
+    vec3 vPosition = VertexPosition;
+    vec3 vNormal = VertexNormal;
+    mat4 finalWorld = instanceMatrix;
+    #include <morphtarget_vertex>
+    #include <bones_vertex>
+
+    // Offset the projected vertex position along the projected normal
+    mat4 mvp = MVP * finalWorld;
+    vec4 position = mvp * vec4(vPosition, 1.0);
+    vec2 normal = (mvp * vec4(vNormal, 0.0)).xy;
+    if (dot(normal, normal) > 0.0) {
+        position.xy += normalize(normal) * OutlineWidth * position.w;
+    }
+    gl_Position = position;
 }
 `
 
@@ -1139,29 +2165,63 @@ var includeMap = map[string]string{
 	"material":                        include_material_source,
 	"lights":                          include_lights_source,
 	"bones_vertex_declaration":        include_bones_vertex_declaration_source,
+	"shadows":                         include_shadows_source,
+	"dir_shadow":                      include_dir_shadow_source,
+	"oit_declaration":                 include_oit_declaration_source,
+	"oit_fragment":                    include_oit_fragment_source,
+	"ocean":                           include_ocean_source,
+	"instance_vertex":                 include_instance_vertex_source,
+	"fog":                             include_fog_source,
+	"ssao":                            include_ssao_source,
+	"envmap":                          include_envmap_source,
+	"camera":                          include_camera_source,
 }
 
 // Maps shader name with its source code
 var shaderMap = map[string]string{
 
-	"point_fragment":    point_fragment_source,
-	"physical_vertex":   physical_vertex_source,
-	"physical_fragment": physical_fragment_source,
-	"point_vertex":      point_vertex_source,
-	"standard_vertex":   standard_vertex_source,
-	"basic_vertex":      basic_vertex_source,
-	"standard_fragment": standard_fragment_source,
-	"panel_vertex":      panel_vertex_source,
-	"basic_fragment":    basic_fragment_source,
-	"panel_fragment":    panel_fragment_source,
+	"point_fragment":         point_fragment_source,
+	"physical_vertex":        physical_vertex_source,
+	"physical_fragment":      physical_fragment_source,
+	"point_vertex":           point_vertex_source,
+	"standard_vertex":        standard_vertex_source,
+	"basic_vertex":           basic_vertex_source,
+	"standard_fragment":      standard_fragment_source,
+	"panel_vertex":           panel_vertex_source,
+	"basic_fragment":         basic_fragment_source,
+	"panel_fragment":         panel_fragment_source,
+	"shadow_fragment":        shadow_fragment_source,
+	"shadow_vertex":          shadow_vertex_source,
+	"oit_composite_fragment": oit_composite_fragment_source,
+	"oit_composite_vertex":   oit_composite_vertex_source,
+	"ocean_vertex":           ocean_vertex_source,
+	"ocean_fragment":         ocean_fragment_source,
+	"pick_vertex":            pick_vertex_source,
+	"pick_fragment":          pick_fragment_source,
+	"ssao_fragment":          ssao_fragment_source,
+	"ssao_normal_fragment":   ssao_normal_fragment_source,
+	"ssao_normal_vertex":     ssao_normal_vertex_source,
+	"ssao_vertex":            ssao_vertex_source,
+	"hdr_fragment":           hdr_fragment_source,
+	"hdr_vertex":             hdr_vertex_source,
+	"outline_fragment":       outline_fragment_source,
+	"outline_vertex":         outline_vertex_source,
 }
 
 // Maps program name with Proginfo struct with shaders names
 var programMap = map[string]ProgramInfo{
 
-	"basic":    {"basic_vertex", "basic_fragment", ""},
-	"panel":    {"panel_vertex", "panel_fragment", ""},
-	"physical": {"physical_vertex", "physical_fragment", ""},
-	"point":    {"point_vertex", "point_fragment", ""},
-	"standard": {"standard_vertex", "standard_fragment", ""},
+	"basic":         {"basic_vertex", "basic_fragment", ""},
+	"panel":         {"panel_vertex", "panel_fragment", ""},
+	"physical":      {"physical_vertex", "physical_fragment", ""},
+	"point":         {"point_vertex", "point_fragment", ""},
+	"standard":      {"standard_vertex", "standard_fragment", ""},
+	"shadow":        {"shadow_vertex", "shadow_fragment", ""},
+	"oit_composite": {"oit_composite_vertex", "oit_composite_fragment", ""},
+	"ocean":         {"ocean_vertex", "ocean_fragment", ""},
+	"pick":          {"pick_vertex", "pick_fragment", ""},
+	"ssao":          {"ssao_vertex", "ssao_fragment", ""},
+	"ssao_normal":   {"ssao_normal_vertex", "ssao_normal_fragment", ""},
+	"hdr":           {"hdr_vertex", "hdr_fragment", ""},
+	"outline":       {"outline_vertex", "outline_fragment", ""},
 }
@@ -0,0 +1,83 @@
+//
+// Screen-space ambient occlusion and blur passes - Fragment Shader
+// Calculates the ambient occlusion of the opaque objects from their normals and depth
+// sampling a hemisphere kernel around the normal of each fragment or, if SSAO_BLUR
+// is defined, blurs it with a 4x4 box filter which removes the noise of the kernel rotations
+//
+precision highp float;
+
+in vec2 FragTexcoord;
+
+out vec4 FragColor;
+
+#ifdef SSAO_BLUR
+
+uniform sampler2D SSAOInput; // Ambient occlusion to be blurred
+
+void main() {
+
+    vec2 texel = 1.0 / vec2(textureSize(SSAOInput, 0));
+    float sum = 0.0;
+    for (int x = -2; x < 2; x++) {
+        for (int y = -2; y < 2; y++) {
+            sum += texture(SSAOInput, FragTexcoord + vec2(float(x), float(y)) * texel).r;
+        }
+    }
+    FragColor = vec4(sum / 16.0);
+}
+
+#else
+
+uniform sampler2D SSAONormal;          // Normals in camera coordinates (alpha is zero where there is no object)
+uniform sampler2D SSAODepth;           // Depth of the opaque objects
+uniform sampler2D SSAONoise;           // 4x4 random vectors which rotate the kernel around the normals
+uniform vec3 SSAOKernel[SSAO_SAMPLES]; // Sample offsets in the unit hemisphere around +Z
+uniform mat4 SSAOProj;                 // Camera projection matrix
+uniform mat4 SSAOInvProj;              // Inverse of the camera projection matrix
+uniform vec4 SSAOParams;               // Radius, depth bias and intensity
+
+// Returns the position in camera coordinates of the opaque object at the specified texture coordinates
+vec3 viewPosition(vec2 uv) {
+
+    float depth = texture(SSAODepth, uv).r;
+    vec4 pos = SSAOInvProj * vec4(vec3(uv, depth) * 2.0 - 1.0, 1.0);
+    return pos.xyz / pos.w;
+}
+
+void main() {
+
+    vec4 normalSample = texture(SSAONormal, FragTexcoord);
+    if (normalSample.a == 0.0) {
+        FragColor = vec4(1.0);
+        return;
+    }
+    float radius = SSAOParams.x;
+    float bias = SSAOParams.y;
+    vec3 position = viewPosition(FragTexcoord);
+    vec3 normal = normalize(normalSample.xyz);
+
+    // Tangent space around the normal randomly rotated with the noise tiled over the viewport
+    vec3 random = vec3(texture(SSAONoise, gl_FragCoord.xy / 4.0).xy, 0.0);
+    vec3 tangent = normalize(random - normal * dot(random, normal));
+    vec3 bitangent = cross(normal, tangent);
+    mat3 tbn = mat3(tangent, bitangent, normal);
+
+    // Counts the samples of the hemisphere which are behind the opaque objects,
+    // ignoring the objects much farther than the radius from the fragment
+    float occlusion = 0.0;
+    for (int i = 0; i < SSAO_SAMPLES; i++) {
+        vec3 samplePos = position + tbn * SSAOKernel[i] * radius;
+        vec4 clip = SSAOProj * vec4(samplePos, 1.0);
+        vec2 uv = clip.xy / clip.w * 0.5 + 0.5;
+        if (uv.x < 0.0 || uv.y < 0.0 || uv.x > 1.0 || uv.y > 1.0) {
+            continue;
+        }
+        float sceneZ = viewPosition(uv).z;
+        float range = smoothstep(0.0, 1.0, radius / max(abs(position.z - sceneZ), 1e-5));
+        occlusion += (sceneZ >= samplePos.z + bias ? 1.0 : 0.0) * range;
+    }
+    float ao = 1.0 - occlusion / float(SSAO_SAMPLES);
+    FragColor = vec4(pow(ao, SSAOParams.z));
+}
+
+#endif
@@ -0,0 +1,19 @@
+//
+// Screen-space ambient occlusion normal and depth prepass - Fragment Shader
+// Writes the normal in camera coordinates facing the camera
+//
+precision highp float;
+
+in vec3 Normal;
+
+// Output
+out vec4 FragColor;
+
+void main() {
+
+    vec3 normal = normalize(Normal);
+    if (!gl_FrontFacing) {
+        normal = -normal;
+    }
+    FragColor = vec4(normal, 1.0);
+}
@@ -0,0 +1,30 @@
+//
+// Screen-space ambient occlusion normal and depth prepass - Vertex Shader
+//
+#include <attributes>
+
+// Model uniforms
+uniform mat3 NormalMatrix;
+uniform mat4 MVP;
+
+#include <morphtarget_vertex_declaration>
+#include <bones_vertex_declaration>
+
+// Output normal in camera coordinates
+out vec3 Normal;
+
+void main() {
+
+    #include <instance_vertex>
+
+    // Transform vertex normal to camera coordinates
+    Normal = normalize(NormalMatrix * instanceNormalMatrix * VertexNormal);
+
+    vec3 vPosition = VertexPosition;
+    mat4 finalWorld = instanceMatrix;
+    #include <morphtarget_vertex>
+    #include <bones_vertex>
+
+    // Output vertex position projected in the camera space
+    gl_Position = MVP * finalWorld * vec4(vPosition, 1.0);
+}
@@ -4,13 +4,18 @@ precision highp float;
 in vec4 Position;     // Fragment position in camera coordinates
 in vec3 Normal;       // Fragment normal in camera coordinates
 in vec2 FragTexcoord; // Fragment texture coordinates
+#ifdef VERTEX_COLORS
+in vec3 FragVertexColor; // Fragment color interpolated from the vertices
+#endif
 
 #include <lights>
 #include <material>
 #include <phong_model>
+#include <fog>
+#include <envmap>
 
 // Final fragment color
-out vec4 FragColor;
+#include <oit_declaration>
 
 void main() {
 
@@ -54,6 +59,10 @@ void main() {
     // Combine material with texture colors
     vec4 matDiffuse = vec4(MatDiffuseColor, MatOpacity) * texMixed;
     vec4 matAmbient = vec4(MatAmbientColor, MatOpacity) * texMixed;
+#ifdef VERTEX_COLORS
+    matDiffuse.rgb *= FragVertexColor;
+    matAmbient.rgb *= FragVertexColor;
+#endif
 
     // Normalize interpolated normal as it may have shrinked
     vec3 fragNormal = normalize(Normal);
@@ -75,4 +84,16 @@ void main() {
 
     // Final fragment color
     FragColor = min(vec4(Ambdiff + Spec, matDiffuse.a), vec4(1.0));
+
+    // Replaces a fraction of the color by the reflected environment
+    #ifdef ENV_MAP
+        FragColor.rgb = mix(FragColor.rgb, envReflection(fragNormal, camDir, 0.0), EnvMapParams.x);
+    #endif
+
+    // Fades the color to the fog color with the distance from the camera
+    #ifdef FOG
+        FragColor.rgb = applyFog(FragColor.rgb, length(Position.xyz));
+    #endif
+
+    #include <oit_fragment>
 }
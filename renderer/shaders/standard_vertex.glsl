@@ -13,14 +13,22 @@ uniform mat4 MVP;
 out vec4 Position;
 out vec3 Normal;
 out vec2 FragTexcoord;
+#ifdef VERTEX_AO
+out float FragAO;     // Baked ambient occlusion of the vertex
+#endif
+#ifdef VERTEX_COLORS
+out vec3 FragVertexColor; // Color of the vertex
+#endif
 
 void main() {
 
+    #include <instance_vertex>
+
     // Transform vertex position to camera coordinates
-    Position = ModelViewMatrix * vec4(VertexPosition, 1.0);
+    Position = ModelViewMatrix * instanceMatrix * vec4(VertexPosition, 1.0);
 
     // Transform vertex normal to camera coordinates
-    Normal = normalize(NormalMatrix * VertexNormal);
+    Normal = normalize(NormalMatrix * instanceNormalMatrix * VertexNormal);
 
     vec2 texcoord = VertexTexcoord;
 #if MAT_TEXTURES > 0
@@ -30,8 +38,14 @@ void main() {
     }
 #endif
     FragTexcoord = texcoord;
+#ifdef VERTEX_AO
+    FragAO = VertexColor.r;
+#endif
+#ifdef VERTEX_COLORS
+    FragVertexColor = VertexColor;
+#endif
     vec3 vPosition = VertexPosition;
-    mat4 finalWorld = mat4(1.0);
+    mat4 finalWorld = instanceMatrix;
     #include <morphtarget_vertex>
     #include <bones_vertex>
 
@@ -0,0 +1,293 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/math32"
+)
+
+// shadowMap contains the configuration and the cascade matrices of the cascaded shadow map
+// of a directional light, whose depth texture is a render target of the frame graph.
+type shadowMap struct {
+	size     int                                     // Size in texels of the shadow map of each cascade
+	cascades int                                     // Number of cascades
+	view     math32.Matrix4                          // Light view matrix
+	proj     [light.ShadowMaxCascades]math32.Matrix4 // Light projection matrix of each cascade
+	matrices [light.ShadowMaxCascades]math32.Matrix4 // Transforms camera coordinates to shadow map coordinates of each cascade
+	splits   [light.ShadowMaxCascades]float32        // Far distance from the camera of each cascade
+	params   [4]float32                              // Number of cascades, depth bias, cascade blend fraction and texel size
+	used     bool                                    // Whether the shadow map was rendered in the current frame
+}
+
+// sortDirLights sorts the directional lights so the lights which cast shadows come first and counts them.
+func (r *Renderer) sortDirLights() {
+
+	sort.SliceStable(r.dirLights, func(i, j int) bool {
+		return r.dirLights[i].CastShadow() && !r.dirLights[j].CastShadow()
+	})
+	r.dirShadows = 0
+	for _, l := range r.dirLights {
+		if !l.CastShadow() {
+			break
+		}
+		r.dirShadows++
+	}
+}
+
+// declareShadowPasses declares in the specified frame graph a pass rendering the shadow map of each
+// directional light which casts shadows, after the specified probes, and returns the shadow map targets.
+// The shadow maps of the lights which no longer cast shadows are deleted.
+// Must be called after the directional lights are sorted.
+func (r *Renderer) declareShadowPasses(fg *FrameGraph, probes *Target) []*Target {
+
+	r.shadowTargets = r.shadowTargets[0:0]
+	defer r.evictShadowMaps()
+	if r.dirShadows == 0 {
+		return nil
+	}
+
+	// Calculate the bounding boxes of the casters in world coordinates
+	r.casterBoxes = r.casterBoxes[0:0]
+	for _, gr := range r.casters {
+		bb := gr.GetGeometry().BoundingBox()
+		mw := gr.MatrixWorld()
+		bb.ApplyMatrix4(&mw)
+		r.casterBoxes = append(r.casterBoxes, bb)
+	}
+
+	// The depth texture of each shadow map has the cascades side by side
+	for idx := 0; idx < r.dirShadows; idx++ {
+		l := r.dirLights[idx]
+		sm, ok := r.shadowMaps[l]
+		if !ok {
+			sm = new(shadowMap)
+			r.shadowMaps[l] = sm
+		}
+		sm.used = true
+		sm.size = l.ShadowMapSize()
+		sm.cascades = l.ShadowCascades()
+		t := fg.AddTarget(fmt.Sprintf("shadow map %d", idx), TargetDesc{
+			Width:  int32(sm.size * sm.cascades),
+			Height: int32(sm.size),
+			Format: gls.DEPTH_COMPONENT24,
+			Clear:  true,
+		})
+		fg.AddPass(fmt.Sprintf("shadow %d", idx), func(pc *PassContext) error {
+			return r.renderShadowMap(l, sm)
+		}).Read(probes).Write(t)
+		r.shadowTargets = append(r.shadowTargets, t)
+	}
+	return r.shadowTargets
+}
+
+// renderShadowMap renders the specified shadow map of the specified directional light,
+// whose depth target is bound and cleared, with the graphics which cast shadows.
+func (r *Renderer) renderShadowMap(l *light.Directional, sm *shadowMap) error {
+
+	sm.update(l, &r.rinfo, r.casterBoxes)
+
+	// Render the casters inside the light frustum of each cascade
+	var rinfo core.RenderInfo
+	rinfo.ViewMatrix = sm.view
+	for c := 0; c < sm.cascades; c++ {
+		r.gs.Viewport(int32(c*sm.size), 0, int32(sm.size), int32(sm.size))
+		rinfo.ProjMatrix = sm.proj[c]
+		var vp math32.Matrix4
+		vp.MultiplyMatrices(&sm.proj[c], &sm.view)
+		frustum := math32.NewFrustumFromMatrix(&vp)
+		for i, gr := range r.casters {
+			if !frustum.IntersectsBox(&r.casterBoxes[i]) {
+				continue
+			}
+			gr.CalculateMatrices(r.gs, &rinfo)
+			materials := gr.Materials()
+			for j := range materials {
+				err := r.renderShadowCaster(&materials[j], &rinfo)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// renderShadowCaster renders the depth of the specified graphic material to the current shadow map.
+// Transparent materials do not cast shadows.
+func (r *Renderer) renderShadowCaster(grmat *graphic.GraphicMaterial, rinfo *core.RenderInfo) error {
+
+	mat := grmat.IMaterial().GetMaterial()
+	if mat.Transparent() {
+		return nil
+	}
+	geom := grmat.IGraphic().GetGeometry()
+	gr := grmat.IGraphic().GetGraphic()
+
+	// Add defines from geometry and graphic for morph targets and skinning,
+	// reusing the defines of the previous caster
+	r.shadowSpecs.Name = "shadow"
+	if r.shadowSpecs.Defines == nil {
+		r.shadowSpecs.Defines = *gls.NewShaderDefines()
+	}
+	for name := range r.shadowSpecs.Defines {
+		delete(r.shadowSpecs.Defines, name)
+	}
+	r.shadowSpecs.Defines.Add(&geom.ShaderDefines)
+	r.shadowSpecs.Defines.Add(&gr.ShaderDefines)
+	_, err := r.Shaman.SetProgram(&r.shadowSpecs)
+	if err != nil {
+		return err
+	}
+	grmat.Render(r.gs, rinfo)
+	return nil
+}
+
+// evictShadowMaps deletes the shadow maps of the lights which did not cast
+// shadows in the current frame, which were removed from the scene or culled.
+func (r *Renderer) evictShadowMaps() {
+
+	for l, sm := range r.shadowMaps {
+		if sm.used {
+			sm.used = false
+			continue
+		}
+		delete(r.shadowMaps, l)
+	}
+}
+
+// shadowSetup binds the shadow maps of the directional lights which cast shadows
+// to the texture units following the material textures and transfers their uniforms.
+func (r *Renderer) shadowSetup() {
+
+	for idx := 0; idx < r.dirShadows; idx++ {
+		sm := r.shadowMaps[r.dirLights[idx]]
+		unit := r.texUnits + idx
+		r.gs.ActiveTexture(uint32(gls.TEXTURE0 + unit))
+		r.gs.BindTexture(gls.TEXTURE_2D, r.shadowTargets[idx].Texture())
+		r.gs.Uniform1i(r.uniShadowMap.LocationIdx(r.gs, int32(idx)), int32(unit))
+		location := r.uniShadowMatrix.LocationIdx(r.gs, int32(light.ShadowMaxCascades*idx))
+		r.gs.UniformMatrix4fv(location, int32(sm.cascades), false, &sm.matrices[0][0])
+		r.gs.Uniform4fv(r.uniShadowSplits.LocationIdx(r.gs, int32(idx)), 1, &sm.splits[0])
+		r.gs.Uniform4fv(r.uniShadowParams.LocationIdx(r.gs, int32(idx)), 1, &sm.params[0])
+	}
+}
+
+// update calculates the cascade splits and the light view and projection matrices which
+// fit each cascade slice of the view frustum of the camera with the specified render info.
+// The depth range of the projections includes all the specified caster bounding boxes.
+func (sm *shadowMap) update(l *light.Directional, rinfo *core.RenderInfo, casters []math32.Box3) {
+
+	var invProj, camWorld math32.Matrix4
+	invProj.GetInverse(&rinfo.ProjMatrix)
+	camWorld.GetInverse(&rinfo.ViewMatrix)
+
+	// Corners of the near and far planes of the camera frustum in camera coordinates
+	var nearCorners, farCorners [4]math32.Vector3
+	for i := 0; i < 4; i++ {
+		x := float32(i%2*2 - 1)
+		y := float32(i/2*2 - 1)
+		nearCorners[i].Set(x, y, -1).ApplyProjection(&invProj)
+		farCorners[i].Set(x, y, 1).ApplyProjection(&invProj)
+	}
+	near := -nearCorners[0].Z
+	far := -farCorners[0].Z
+	maxDist := far
+	if dist := l.ShadowDistance(); dist > 0 && dist < far {
+		maxDist = dist
+	}
+
+	// Calculate the far distance of each cascade blending the logarithmic and uniform split schemes
+	splits := l.ShadowCascadeSplits()
+	lambda := l.ShadowSplitLambda()
+	for c := 0; c < sm.cascades; c++ {
+		var split float32
+		if c < len(splits) {
+			split = splits[c]
+		} else {
+			t := float32(c+1) / float32(sm.cascades)
+			split = lambda*near*math32.Pow(maxDist/near, t) + (1-lambda)*(near+(maxDist-near)*t)
+		}
+		sm.splits[c] = math32.Min(split, maxDist)
+	}
+
+	// Light view matrix looking along the light direction
+	var dir math32.Vector3
+	l.WorldPosition(&dir)
+	dir.Negate().Normalize()
+	up := math32.Vector3{X: 0, Y: 1, Z: 0}
+	if math32.Abs(dir.Y) > 0.99 {
+		up.Set(0, 0, 1)
+	}
+	var rot math32.Matrix4
+	rot.LookAt(&math32.Vector3{}, &dir, &up)
+	sm.view.GetInverse(&rot)
+
+	// Maximum Z in light view coordinates of the casters (nearest to the light)
+	casterMax := math32.Inf(-1)
+	for i := range casters {
+		bb := casters[i]
+		bb.ApplyMatrix4(&sm.view)
+		casterMax = math32.Max(casterMax, bb.Max.Z)
+	}
+
+	sliceNear := near
+	for c := 0; c < sm.cascades; c++ {
+		// Corners of the cascade slice of the camera frustum in world coordinates
+		var corners [8]math32.Vector3
+		var center math32.Vector3
+		for i := 0; i < 4; i++ {
+			corners[i] = nearCorners[i]
+			corners[i].Lerp(&farCorners[i], (sliceNear-near)/(far-near))
+			corners[i+4] = nearCorners[i]
+			corners[i+4].Lerp(&farCorners[i], (sm.splits[c]-near)/(far-near))
+		}
+		for i := range corners {
+			corners[i].ApplyMatrix4(&camWorld)
+			center.Add(&corners[i])
+		}
+		center.DivideScalar(8)
+
+		// Use the bounding sphere of the slice so the projection size doesn't change with the camera rotation
+		var radius float32
+		for i := range corners {
+			radius = math32.Max(radius, center.DistanceTo(&corners[i]))
+		}
+		radius = math32.Ceil(radius*16) / 16
+
+		// Snap the center to the shadow map texels to avoid shimmering when the camera moves
+		center.ApplyMatrix4(&sm.view)
+		texel := 2 * radius / float32(sm.size)
+		center.X = math32.Floor(center.X/texel) * texel
+		center.Y = math32.Floor(center.Y/texel) * texel
+
+		// Orthographic projection of the slice including the casters between it and the light
+		zNear := -math32.Max(center.Z+radius, casterMax)
+		zFar := -(center.Z - radius)
+		sm.proj[c].MakeOrthographic(center.X-radius, center.X+radius, center.Y+radius, center.Y-radius, zNear, zFar)
+
+		// Transform from camera coordinates to the cascade tile of the shadow map texture
+		count := float32(sm.cascades)
+		tile := math32.Matrix4{
+			0.5 / count, 0, 0, 0,
+			0, 0.5, 0, 0,
+			0, 0, 0.5, 0,
+			(0.5 + float32(c)) / count, 0.5, 0.5, 1,
+		}
+		sm.matrices[c].MultiplyMatrices(&tile, &sm.proj[c])
+		sm.matrices[c].Multiply(&sm.view)
+		sm.matrices[c].Multiply(&camWorld)
+
+		// The next slice starts where this cascade starts blending with it
+		sliceNear = sm.splits[c] - (sm.splits[c]-sliceNear)*l.ShadowCascadeBlend()
+	}
+	sm.params = [4]float32{float32(sm.cascades), l.ShadowBias(), l.ShadowCascadeBlend(), 1 / float32(sm.size)}
+}
@@ -36,6 +36,7 @@ type ShaderSpecs struct {
 	DirLightsMax     int                // Current Number of directional lights
 	PointLightsMax   int                // Current Number of point lights
 	SpotLightsMax    int                // Current Number of spot lights
+	DirShadowsMax    int                // Current Number of directional lights which cast shadows
 	MatTexturesMax   int                // Current Number of material textures
 	Defines          gls.ShaderDefines  // Additional shader defines
 }
@@ -52,8 +53,10 @@ type Shaman struct {
 	includes map[string]string              // include files sources
 	shadersm map[string]string              // maps shader name to its template
 	proginfo map[string]shaders.ProgramInfo // maps name of the program to ProgramInfo
+	blocks   map[string]uint32              // maps name of uniform block to its binding point
 	programs []ProgSpecs                    // list of compiled programs with specs
 	specs    ShaderSpecs                    // Current shader specs
+	gen      uint32                         // Generation of the OpenGL context of the programs
 }
 
 // NewShaman creates and returns a pointer to a new shader manager
@@ -71,6 +74,7 @@ func (sm *Shaman) Init(gs *gls.GLS) {
 	sm.includes = make(map[string]string)
 	sm.shadersm = make(map[string]string)
 	sm.proginfo = make(map[string]shaders.ProgramInfo)
+	sm.blocks = make(map[string]uint32)
 }
 
 // AddDefaultShaders adds to this shader manager all default
@@ -103,6 +107,14 @@ func (sm *Shaman) AddShader(name, source string) {
 	sm.shadersm[name] = source
 }
 
+// AddUniformBlock adds a uniform block with the specified name, which is bound
+// to the specified binding point in the programs which use it when they are built.
+// The data of the block is supplied by a gls.UBO bound to the same binding point.
+func (sm *Shaman) AddUniformBlock(name string, binding uint32) {
+
+	sm.blocks[name] = binding
+}
+
 // AddProgram adds a program with the specified name and associated vertex
 // and fragment shaders names (previously registered)
 func (sm *Shaman) AddProgram(name, vertexName, fragName string, others ...string) {
@@ -133,6 +145,7 @@ func (sm *Shaman) SetProgram(s *ShaderSpecs) (bool, error) {
 	}
 	if (specs.UseLights & material.UseLightDirectional) == 0 {
 		specs.DirLightsMax = 0
+		specs.DirShadowsMax = 0
 	}
 	if (specs.UseLights & material.UseLightPoint) == 0 {
 		specs.PointLightsMax = 0
@@ -141,6 +154,13 @@ func (sm *Shaman) SetProgram(s *ShaderSpecs) (bool, error) {
 		specs.SpotLightsMax = 0
 	}
 
+	// Discards the programs of a previous OpenGL context, which are invalid
+	if sm.gen != sm.gs.Generation() {
+		sm.programs = nil
+		sm.specs = ShaderSpecs{}
+		sm.gen = sm.gs.Generation()
+	}
+
 	// If current shader specs are the same as the specified specs, nothing to do.
 	if sm.specs.equals(&specs) {
 		return false, nil
@@ -184,6 +204,7 @@ func (sm *Shaman) GenProgram(specs *ShaderSpecs) (*gls.Program, error) {
 	defines["DIR_LIGHTS"] = strconv.Itoa(specs.DirLightsMax)
 	defines["POINT_LIGHTS"] = strconv.Itoa(specs.PointLightsMax)
 	defines["SPOT_LIGHTS"] = strconv.Itoa(specs.SpotLightsMax)
+	defines["DIR_SHADOWS"] = strconv.Itoa(specs.DirShadowsMax)
 	defines["MAT_TEXTURES"] = strconv.Itoa(specs.MatTexturesMax)
 
 	// Adds additional material and geometry defines from the specs parameter
@@ -242,6 +263,11 @@ func (sm *Shaman) GenProgram(specs *ShaderSpecs) (*gls.Program, error) {
 		return nil, err
 	}
 
+	// Binds the uniform blocks used by the program to their binding points
+	for name, binding := range sm.blocks {
+		prog.BindUniformBlock(name, binding)
+	}
+
 	return prog, nil
 }
 
@@ -345,6 +371,7 @@ func (ss *ShaderSpecs) equals(other *ShaderSpecs) bool {
 		ss.DirLightsMax == other.DirLightsMax &&
 		ss.PointLightsMax == other.PointLightsMax &&
 		ss.SpotLightsMax == other.SpotLightsMax &&
+		ss.DirShadowsMax == other.DirShadowsMax &&
 		ss.MatTexturesMax == other.MatTexturesMax &&
 		ss.Defines.Equals(&other.Defines) {
 		return true
@@ -0,0 +1,315 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"math/rand"
+	"strconv"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+)
+
+// SSAOQuality is a quality preset of the screen-space ambient occlusion.
+type SSAOQuality int
+
+// Screen-space ambient occlusion quality presets
+const (
+	SSAOLow    = SSAOQuality(iota) // 8 samples at half resolution
+	SSAOMedium                     // 16 samples at half resolution
+	SSAOHigh                       // 32 samples at full resolution
+)
+
+// ssaoPresets contains the number of kernel samples and the resolution divisor of each quality preset
+var ssaoPresets = [...]struct {
+	samples int
+	divisor int32
+}{
+	SSAOLow:    {8, 2},
+	SSAOMedium: {16, 2},
+	SSAOHigh:   {32, 1},
+}
+
+// Size of the side of the tiled noise texture in pixels
+const ssaoNoiseSize = 4
+
+// Depth bias of the ambient occlusion samples in camera coordinates
+const ssaoBias = 0.025
+
+// ssaoBuffers contains the noise texture, the kernel and the uniforms used to render the ambient
+// occlusion, whose normal, depth and ambient occlusion textures are render targets of the frame graph.
+type ssaoBuffers struct {
+	blurTex    uint32      // Blurred ambient occlusion texture applied to the opaque objects
+	noiseTex   uint32      // Random rotations of the kernel tiled over the viewport
+	vao        uint32      // Empty vertex array object used to draw the full viewport triangle
+	kernel     []float32   // Sample offsets in the unit hemisphere around +Z
+	viewport   [4]float32  // Origin of the viewport and inverse of its size
+	uniNormal  gls.Uniform // Normal texture sampler uniform
+	uniDepth   gls.Uniform // Depth texture sampler uniform
+	uniNoise   gls.Uniform // Noise texture sampler uniform
+	uniKernel  gls.Uniform // Kernel samples uniform
+	uniProj    gls.Uniform // Projection matrix uniform
+	uniInvProj gls.Uniform // Inverse projection matrix uniform
+	uniParams  gls.Uniform // Radius, bias and intensity uniform
+	uniInput   gls.Uniform // Blur input texture sampler uniform
+	uniMap     gls.Uniform // Blurred ambient occlusion sampler uniform of the lit materials
+	uniVp      gls.Uniform // Viewport uniform of the lit materials
+}
+
+// SetSSAO sets whether screen-space ambient occlusion is applied to the ambient light of the
+// opaque 3D objects, darkening their creases and contacts. It renders the normals and depth of
+// the opaque objects in a prepass and calculates and blurs the occlusion in two additional passes.
+// Only the materials using the built-in standard and physical shaders support it.
+func (r *Renderer) SetSSAO(enable bool) {
+
+	r.ssao = enable
+}
+
+// SSAO returns whether screen-space ambient occlusion is applied to the opaque 3D objects.
+func (r *Renderer) SSAO() bool {
+
+	return r.ssao
+}
+
+// SetSSAOQuality sets the quality preset of the screen-space ambient occlusion. The default is SSAOMedium.
+func (r *Renderer) SetSSAOQuality(quality SSAOQuality) {
+
+	if quality < SSAOLow || quality > SSAOHigh {
+		quality = SSAOMedium
+	}
+	r.ssaoQuality = quality
+}
+
+// SSAOQuality returns the quality preset of the screen-space ambient occlusion.
+func (r *Renderer) SSAOQuality() SSAOQuality {
+
+	return r.ssaoQuality
+}
+
+// SetSSAORadius sets the radius in camera coordinates of the hemisphere around each
+// fragment where objects occlude the ambient light. The default is 0.5.
+func (r *Renderer) SetSSAORadius(radius float32) {
+
+	r.ssaoRadius = radius
+}
+
+// SSAORadius returns the radius of the screen-space ambient occlusion.
+func (r *Renderer) SSAORadius() float32 {
+
+	return r.ssaoRadius
+}
+
+// SetSSAOIntensity sets the exponent applied to the screen-space ambient occlusion.
+// Values greater than one darken the occluded areas. The default is 1.
+func (r *Renderer) SetSSAOIntensity(intensity float32) {
+
+	r.ssaoIntensity = intensity
+}
+
+// SSAOIntensity returns the exponent applied to the screen-space ambient occlusion.
+func (r *Renderer) SSAOIntensity() float32 {
+
+	return r.ssaoIntensity
+}
+
+// declareSSAOPasses declares in the specified frame graph the passes which render the normals
+// and the depth of the opaque 3D objects, after the specified prepared scene, and calculate and
+// blur their ambient occlusion, and returns the blurred ambient occlusion target.
+func (r *Renderer) declareSSAOPasses(fg *FrameGraph, prepared *Target) *Target {
+
+	if r.ssaoBuffers == nil {
+		r.ssaoBuffers = newSSAOBuffers(r.gs)
+	}
+	preset := ssaoPresets[r.ssaoQuality]
+	r.ssaoBuffers.setKernel(preset.samples)
+
+	// The normals are cleared to zero where there is no object
+	normal := fg.AddTarget("ssao normal", TargetDesc{Format: gls.RGBA16F, Clear: true})
+	depth := fg.AddTarget("ssao depth", TargetDesc{Format: gls.DEPTH_COMPONENT24, Clear: true})
+	ao := fg.AddTarget("ssao", TargetDesc{Scale: 1 / float32(preset.divisor), Format: gls.R8})
+	blur := fg.AddTarget("ssao blur", TargetDesc{Scale: 1 / float32(preset.divisor), Format: gls.R8})
+	fg.AddPass("ssao prepass", func(pc *PassContext) error {
+		return r.renderSSAONormals()
+	}).Read(prepared).Write(normal, depth)
+	fg.AddPass("ssao", func(pc *PassContext) error {
+		return r.renderSSAO(normal, depth, preset.samples)
+	}).Read(normal, depth).Write(ao)
+	fg.AddPass("ssao blur", func(pc *PassContext) error {
+		return r.blurSSAO(ao)
+	}).Read(ao).Write(blur)
+	return blur
+}
+
+// renderSSAONormals renders the normals and the depth of the opaque 3D objects to the prepass targets.
+func (r *Renderer) renderSSAONormals() error {
+
+	for _, grmat := range r.grmatsOpaque[:r.opaque3D] {
+		err := r.renderSSAONormal(grmat)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderSSAO calculates the ambient occlusion with the specified number of samples
+// from the specified normal and depth targets.
+func (r *Renderer) renderSSAO(normal, depth *Target, samples int) error {
+
+	sb := r.ssaoBuffers
+	r.ssaoSpecs.Name = "ssao"
+	r.ssaoSpecs.Defines = *gls.NewShaderDefines()
+	r.ssaoSpecs.Defines.Set("SSAO_SAMPLES", strconv.Itoa(samples))
+	_, err := r.Shaman.SetProgram(&r.ssaoSpecs)
+	if err != nil {
+		return err
+	}
+	r.gs.Disable(gls.DEPTH_TEST)
+	r.gs.DepthMask(false)
+	r.gs.Disable(gls.BLEND)
+	r.gs.ActiveTexture(gls.TEXTURE0)
+	r.gs.BindTexture(gls.TEXTURE_2D, normal.Texture())
+	r.gs.Uniform1i(sb.uniNormal.Location(r.gs), 0)
+	r.gs.ActiveTexture(gls.TEXTURE1)
+	r.gs.BindTexture(gls.TEXTURE_2D, depth.Texture())
+	r.gs.Uniform1i(sb.uniDepth.Location(r.gs), 1)
+	r.gs.ActiveTexture(gls.TEXTURE2)
+	r.gs.BindTexture(gls.TEXTURE_2D, sb.noiseTex)
+	r.gs.Uniform1i(sb.uniNoise.Location(r.gs), 2)
+	var invProj math32.Matrix4
+	invProj.GetInverse(&r.rinfo.ProjMatrix)
+	r.gs.Uniform3fv(sb.uniKernel.Location(r.gs), int32(samples), &sb.kernel[0])
+	r.gs.UniformMatrix4fv(sb.uniProj.Location(r.gs), 1, false, &r.rinfo.ProjMatrix[0])
+	r.gs.UniformMatrix4fv(sb.uniInvProj.Location(r.gs), 1, false, &invProj[0])
+	r.gs.Uniform4f(sb.uniParams.Location(r.gs), r.ssaoRadius, ssaoBias, r.ssaoIntensity, 0)
+	r.gs.BindVertexArray(sb.vao)
+	r.gs.DrawArrays(gls.TRIANGLES, 0, 3)
+	return nil
+}
+
+// blurSSAO blurs the specified ambient occlusion target.
+func (r *Renderer) blurSSAO(ao *Target) error {
+
+	sb := r.ssaoBuffers
+	r.ssaoSpecs.Defines.Set("SSAO_BLUR", "")
+	_, err := r.Shaman.SetProgram(&r.ssaoSpecs)
+	if err != nil {
+		return err
+	}
+	r.gs.Disable(gls.DEPTH_TEST)
+	r.gs.DepthMask(false)
+	r.gs.Disable(gls.BLEND)
+	r.gs.ActiveTexture(gls.TEXTURE0)
+	r.gs.BindTexture(gls.TEXTURE_2D, ao.Texture())
+	r.gs.Uniform1i(sb.uniInput.Location(r.gs), 0)
+	r.gs.BindVertexArray(sb.vao)
+	r.gs.DrawArrays(gls.TRIANGLES, 0, 3)
+	return nil
+}
+
+// beginSSAO sets the specified blurred ambient occlusion target to be applied to
+// the opaque objects rendered with the current viewport.
+func (r *Renderer) beginSSAO(blur *Target) {
+
+	sb := r.ssaoBuffers
+	vx, vy, vw, vh := r.gs.GetViewport()
+	sb.blurTex = blur.Texture()
+	sb.viewport = [4]float32{float32(vx), float32(vy), 1 / float32(vw), 1 / float32(vh)}
+	r.ssaoPass = true
+}
+
+// renderSSAONormal renders the normal and the depth of the specified graphic material to the prepass buffers.
+func (r *Renderer) renderSSAONormal(grmat *graphic.GraphicMaterial) error {
+
+	geom := grmat.IGraphic().GetGeometry()
+	gr := grmat.IGraphic().GetGraphic()
+
+	// Add defines from geometry and graphic for morph targets, skinning and instancing
+	r.ssaoSpecs.Name = "ssao_normal"
+	r.ssaoSpecs.Defines = *gls.NewShaderDefines()
+	r.ssaoSpecs.Defines.Add(&geom.ShaderDefines)
+	r.ssaoSpecs.Defines.Add(&gr.ShaderDefines)
+	_, err := r.Shaman.SetProgram(&r.ssaoSpecs)
+	if err != nil {
+		return err
+	}
+	grmat.RenderOverride(r.gs, &r.rinfo, ssaoNormalState)
+	return nil
+}
+
+// ssaoNormalState overrides the blending and depth states set by the material
+// of a graphic so the normals of the nearest opaque objects are written.
+func ssaoNormalState(gs *gls.GLS) {
+
+	gs.Disable(gls.BLEND)
+	gs.Enable(gls.DEPTH_TEST)
+	gs.DepthFunc(gls.LEQUAL)
+	gs.DepthMask(true)
+}
+
+// ssaoSetup binds the blurred ambient occlusion to the texture unit following
+// the material textures and the shadow maps and transfers its uniforms.
+func (r *Renderer) ssaoSetup() {
+
+	sb := r.ssaoBuffers
+	unit := r.texUnits + r.dirShadows
+	r.gs.ActiveTexture(uint32(gls.TEXTURE0 + unit))
+	r.gs.BindTexture(gls.TEXTURE_2D, sb.blurTex)
+	r.gs.Uniform1i(sb.uniMap.Location(r.gs), int32(unit))
+	r.gs.Uniform4fv(sb.uniVp.Location(r.gs), 1, &sb.viewport[0])
+}
+
+// newSSAOBuffers creates and returns a pointer to new ambient occlusion buffers.
+func newSSAOBuffers(gs *gls.GLS) *ssaoBuffers {
+
+	sb := new(ssaoBuffers)
+	sb.vao = gs.GenVertexArray()
+	sb.uniNormal.Init("SSAONormal")
+	sb.uniDepth.Init("SSAODepth")
+	sb.uniNoise.Init("SSAONoise")
+	sb.uniKernel.Init("SSAOKernel")
+	sb.uniProj.Init("SSAOProj")
+	sb.uniInvProj.Init("SSAOInvProj")
+	sb.uniParams.Init("SSAOParams")
+	sb.uniInput.Init("SSAOInput")
+	sb.uniMap.Init("SSAOMap")
+	sb.uniVp.Init("SSAOViewport")
+
+	// Random vectors in the XY plane which rotate the kernel around the normals
+	rnd := rand.New(rand.NewSource(1))
+	noise := make([]float32, 0, ssaoNoiseSize*ssaoNoiseSize*2)
+	for i := 0; i < ssaoNoiseSize*ssaoNoiseSize; i++ {
+		noise = append(noise, rnd.Float32()*2-1, rnd.Float32()*2-1)
+	}
+	sb.noiseTex = gs.GenTexture()
+	gs.BindTexture(gls.TEXTURE_2D, sb.noiseTex)
+	gs.TexImage2D(gls.TEXTURE_2D, 0, gls.RG16F, ssaoNoiseSize, ssaoNoiseSize, gls.RG, gls.FLOAT, noise)
+	gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_WRAP_S, gls.REPEAT)
+	gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_WRAP_T, gls.REPEAT)
+	gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_MIN_FILTER, gls.NEAREST)
+	gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_MAG_FILTER, gls.NEAREST)
+	gs.BindTexture(gls.TEXTURE_2D, 0)
+	return sb
+}
+
+// setKernel calculates the specified number of sample offsets in the unit hemisphere
+// around +Z if they changed, distributed so there are more samples near the center.
+func (sb *ssaoBuffers) setKernel(samples int) {
+
+	if len(sb.kernel) == samples*3 {
+		return
+	}
+	rnd := rand.New(rand.NewSource(1))
+	sb.kernel = make([]float32, 0, samples*3)
+	for i := 0; i < samples; i++ {
+		v := math32.Vector3{X: rnd.Float32()*2 - 1, Y: rnd.Float32()*2 - 1, Z: rnd.Float32()}
+		v.Normalize()
+		scale := float32(i) / float32(samples)
+		scale = 0.1 + 0.9*scale*scale
+		v.MultiplyScalar(rnd.Float32() * scale)
+		sb.kernel = append(sb.kernel, v.X, v.Y, v.Z)
+	}
+}
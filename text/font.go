@@ -378,6 +378,41 @@ func (c Canvas) DrawTextCaret(x, y int, text string, f *Font, drawCaret bool, li
 	return nil
 }
 
+// DrawUnderline draws a line, with the text color, under the characters from the start
+// to the end column of the specified line of the text drawn at the specified position,
+// as used to show the text being composed with an input method editor.
+func (c Canvas) DrawUnderline(x, y int, text string, f *Font, line, start, end int) {
+
+	f.updateFace()
+	metrics := f.face.Metrics()
+	lineHeight := (metrics.Ascent + metrics.Descent).Ceil()
+	lineGap := int((f.attrib.LineSpacing - float64(1)) * float64(lineHeight))
+	py := y + metrics.Ascent.Round()
+	lines := strings.Split(text, "\n")
+	for l := 0; l < line && l < len(lines); l++ {
+		py += lineHeight
+		if l > 1 {
+			py += lineGap
+		}
+	}
+	if line >= len(lines) || start >= end || end > StrCount(lines[line]) {
+		return
+	}
+	s := lines[line]
+	x1, _ := f.MeasureText(StrPrefix(s, start))
+	x2, _ := f.MeasureText(StrPrefix(s, end))
+	thickness := int(f.scaleY)
+	if thickness < 1 {
+		thickness = 1
+	}
+	color := f.fg.C
+	for j := py + 1; j < py+1+thickness; j++ {
+		for i := x + x1; i < x+x2; i++ {
+			c.RGBA.Set(i, j, color)
+		}
+	}
+}
+
 // Color4RGBA converts a math32.Color4 to Go's color.RGBA.
 func Color4RGBA(c *math32.Color4) color.RGBA {
 
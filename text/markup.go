@@ -0,0 +1,332 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"image"
+	"image/draw"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/math32"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// italicSlant is the horizontal shear applied to draw italic text
+const italicSlant = 0.2
+
+// Run is a sequence of characters of a markup text drawn with the same style.
+type Run struct {
+	Text   string         // Text of the run, which may contain line breaks
+	Bold   bool           // Bold text
+	Italic bool           // Italic text
+	Icon   bool           // Icon codepoints drawn with the icon font
+	Color  *math32.Color4 // Text color (nil for the default color)
+}
+
+// ParseMarkup parses the specified markup text and returns its runs.
+// The markup supports the following tags, which can be nested:
+//
+//	[b]bold[/b]
+//	[i]italic[/i]
+//	[color=red]named color[/color] or [color=#ff8000]hexadecimal color[/color]
+//	[icon=e88a] icon codepoint in hexadecimal drawn with the icon font
+//
+// A literal "[" is written as "[[". Unknown tags are kept as text.
+func ParseMarkup(markup string) []Run {
+
+	var runs []Run
+	var cur strings.Builder
+	var bold, italic int
+	var colors []*math32.Color4
+
+	// Returns the current color
+	color := func() *math32.Color4 {
+		if len(colors) == 0 {
+			return nil
+		}
+		return colors[len(colors)-1]
+	}
+	// Appends the text of the current run
+	flush := func() {
+		if cur.Len() == 0 {
+			return
+		}
+		runs = append(runs, Run{Text: cur.String(), Bold: bold > 0, Italic: italic > 0, Color: color()})
+		cur.Reset()
+	}
+
+	for i := 0; i < len(markup); {
+		if markup[i] != '[' {
+			cur.WriteByte(markup[i])
+			i++
+			continue
+		}
+		if strings.HasPrefix(markup[i:], "[[") {
+			cur.WriteByte('[')
+			i += 2
+			continue
+		}
+		end := strings.IndexByte(markup[i:], ']')
+		if end < 0 {
+			cur.WriteString(markup[i:])
+			break
+		}
+		tag := markup[i+1 : i+end]
+		name, value := tag, ""
+		if eq := strings.IndexByte(tag, '='); eq >= 0 {
+			name, value = tag[:eq], tag[eq+1:]
+		}
+		handled := true
+		switch name {
+		case "b":
+			flush()
+			bold++
+		case "/b":
+			flush()
+			if bold > 0 {
+				bold--
+			}
+		case "i":
+			flush()
+			italic++
+		case "/i":
+			flush()
+			if italic > 0 {
+				italic--
+			}
+		case "color":
+			c, ok := parseColor(value)
+			if !ok {
+				handled = false
+				break
+			}
+			flush()
+			colors = append(colors, c)
+		case "/color":
+			flush()
+			if len(colors) > 0 {
+				colors = colors[:len(colors)-1]
+			}
+		case "icon":
+			code, err := strconv.ParseUint(value, 16, 32)
+			if err != nil {
+				handled = false
+				break
+			}
+			flush()
+			runs = append(runs, Run{Text: string(rune(code)), Icon: true, Color: color()})
+		default:
+			handled = false
+		}
+		if !handled {
+			cur.WriteString(markup[i : i+end+1])
+		}
+		i += end + 1
+	}
+	flush()
+	return runs
+}
+
+// parseColor parses a color name or a hexadecimal color in the #rrggbb or #rrggbbaa formats.
+func parseColor(s string) (*math32.Color4, bool) {
+
+	if strings.HasPrefix(s, "#") {
+		hex := s[1:]
+		if len(hex) != 6 && len(hex) != 8 {
+			return nil, false
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return nil, false
+		}
+		c := new(math32.Color4)
+		if len(hex) == 6 {
+			c.SetHex(uint(v))
+			c.A = 1
+		} else {
+			c.SetHex(uint(v >> 8))
+			c.A = float32(v&0xFF) / 255
+		}
+		return c, true
+	}
+	c, ok := math32.IsColorName(s)
+	if !ok {
+		return nil, false
+	}
+	return &math32.Color4{R: c.R, G: c.G, B: c.B, A: 1}, true
+}
+
+// MarkupFonts contains the fonts used to draw the runs of a markup text.
+// Their attributes and scales must be set prior to drawing.
+// The bold and icon fonts are optional: without a bold font the bold text is drawn twice
+// with one pixel of offset and without an icon font the icons are drawn with the regular font.
+type MarkupFonts struct {
+	Regular *Font // Font of the regular text, which also sets the line spacing
+	Bold    *Font // Font of the bold text (may be nil)
+	Icon    *Font // Font of the icons (may be nil)
+}
+
+// font returns the font of the specified run and whether it must be emboldened.
+func (mf *MarkupFonts) font(r *Run) (*Font, bool) {
+
+	if r.Icon && mf.Icon != nil {
+		return mf.Icon, false
+	}
+	if r.Bold {
+		if mf.Bold != nil {
+			return mf.Bold, false
+		}
+		return mf.Regular, true
+	}
+	return mf.Regular, false
+}
+
+// metrics returns the ascent, the descent and the gap between lines
+// in pixels of the fonts used by the specified runs.
+func (mf *MarkupFonts) metrics(runs []Run) (int, int, int) {
+
+	m := mf.Regular.Metrics()
+	ascent, descent := m.Ascent.Ceil(), m.Descent.Ceil()
+	for i := range runs {
+		f, _ := mf.font(&runs[i])
+		m := f.Metrics()
+		if m.Ascent.Ceil() > ascent {
+			ascent = m.Ascent.Ceil()
+		}
+		if m.Descent.Ceil() > descent {
+			descent = m.Descent.Ceil()
+		}
+	}
+	gap := int((mf.Regular.attrib.LineSpacing - float64(1)) * float64(ascent+descent))
+	return ascent, descent, gap
+}
+
+// MeasureRuns returns the minimum width and height in pixels necessary for an image to contain the specified runs.
+func (mf *MarkupFonts) MeasureRuns(runs []Run) (int, int) {
+
+	ascent, descent, gap := mf.metrics(runs)
+	lines := splitRuns(runs)
+	width := 0
+	for _, line := range lines {
+		if w := mf.lineWidth(line, ascent); w > width {
+			width = w
+		}
+	}
+	height := len(lines)*(ascent+descent) + (len(lines)-1)*gap
+	return width, height
+}
+
+// DrawRuns draws the specified runs on a new, tightly fitting image, and returns a pointer to the image.
+// The runs without color are drawn with the specified color.
+func (mf *MarkupFonts) DrawRuns(runs []Run, fg *math32.Color4) *image.RGBA {
+
+	width, height := mf.MeasureRuns(runs)
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	bg := image.NewUniform(Color4RGBA(&math32.Color4{R: fg.R, G: fg.G, B: fg.B}))
+	draw.Draw(img, img.Bounds(), bg, image.ZP, draw.Src)
+
+	ascent, descent, gap := mf.metrics(runs)
+	lineHeight := ascent + descent
+	top := 0
+	for _, line := range splitRuns(runs) {
+		x := 0
+		for i := range line {
+			r := &line[i]
+			color := fg
+			if r.Color != nil {
+				color = r.Color
+			}
+			src := image.NewUniform(Color4RGBA(color))
+			if !r.Italic {
+				x += mf.drawRun(img, src, r, x, top+ascent)
+				continue
+			}
+			// Draws the italic run on a temporary image which is sheared onto the destination
+			adv := mf.runWidth(r)
+			tmp := image.NewRGBA(image.Rect(0, 0, adv+mf.italicExtra(ascent), lineHeight))
+			mf.drawRun(tmp, src, r, 0, ascent)
+			for y := 0; y < lineHeight; y++ {
+				off := int(math.Round(italicSlant * float64(ascent-y)))
+				rect := image.Rect(x+off, top+y, x+off+tmp.Rect.Dx(), top+y+1)
+				draw.Draw(img, rect, tmp, image.Pt(0, y), draw.Over)
+			}
+			x += adv
+		}
+		top += lineHeight + gap
+	}
+	return img
+}
+
+// drawRun draws the specified run on the specified image with the specified baseline
+// position and returns its advance width in pixels.
+func (mf *MarkupFonts) drawRun(dst *image.RGBA, src image.Image, r *Run, x, y int) int {
+
+	f, embolden := mf.font(r)
+	f.updateFace()
+	d := &font.Drawer{Dst: dst, Src: src, Face: f.face}
+	d.Dot = fixed.P(x, y)
+	d.DrawString(r.Text)
+	if embolden {
+		d.Dot = fixed.P(x+1, y)
+		d.DrawString(r.Text)
+	}
+	return mf.runWidth(r)
+}
+
+// runWidth returns the advance width in pixels of the specified run
+func (mf *MarkupFonts) runWidth(r *Run) int {
+
+	f, embolden := mf.font(r)
+	f.updateFace()
+	d := &font.Drawer{Face: f.face}
+	width := d.MeasureString(r.Text).Ceil()
+	if embolden {
+		width++
+	}
+	return width
+}
+
+// lineWidth returns the width in pixels of the specified line of runs
+func (mf *MarkupFonts) lineWidth(line []Run, ascent int) int {
+
+	width := 0
+	for i := range line {
+		width += mf.runWidth(&line[i])
+	}
+	// The top of the last glyph of an italic run extends beyond its advance
+	if len(line) > 0 && line[len(line)-1].Italic {
+		width += mf.italicExtra(ascent)
+	}
+	return width
+}
+
+// italicExtra returns the width in pixels by which italic glyphs extend beyond their advance
+func (mf *MarkupFonts) italicExtra(ascent int) int {
+
+	return int(math.Ceil(italicSlant * float64(ascent)))
+}
+
+// splitRuns splits the specified runs in lines at their line breaks
+func splitRuns(runs []Run) [][]Run {
+
+	lines := [][]Run{nil}
+	for _, r := range runs {
+		parts := strings.Split(r.Text, "\n")
+		for i, part := range parts {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if part != "" {
+				lr := r
+				lr.Text = part
+				lines[len(lines)-1] = append(lines[len(lines)-1], lr)
+			}
+		}
+	}
+	return lines
+}
@@ -0,0 +1,127 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/g3n/engine/math32"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+	"golang.org/x/image/math/fixed"
+)
+
+// ArcPath returns a polyline approximating the circular arc with the specified center
+// and radius from startAngle to endAngle (in radians) using the specified number of segments.
+// Angles are measured in image coordinates (Y pointing down), so text drawn along an arc
+// from math32.Pi to 2*math32.Pi is placed over the top of the circle.
+func ArcPath(cx, cy, radius, startAngle, endAngle float32, segments int) []math32.Vector2 {
+
+	if segments < 1 {
+		segments = 1
+	}
+	path := make([]math32.Vector2, segments+1)
+	for i := 0; i <= segments; i++ {
+		angle := startAngle + (endAngle-startAngle)*float32(i)/float32(segments)
+		path[i].Set(cx+radius*math32.Cos(angle), cy+radius*math32.Sin(angle))
+	}
+	return path
+}
+
+// PathLength returns the total length of the specified polyline path.
+func PathLength(path []math32.Vector2) float32 {
+
+	var length float32
+	for i := 1; i < len(path); i++ {
+		length += path[i].DistanceTo(&path[i-1])
+	}
+	return length
+}
+
+// PathPoint returns the position and the direction angle (in radians) of the
+// point at the specified distance along the specified polyline path.
+// Returns false if the distance is outside the path.
+func PathPoint(path []math32.Vector2, dist float32) (math32.Vector2, float32, bool) {
+
+	if dist < 0 {
+		return math32.Vector2{}, 0, false
+	}
+	for i := 1; i < len(path); i++ {
+		p0 := path[i-1]
+		p1 := path[i]
+		seg := p1.DistanceTo(&p0)
+		if dist <= seg && seg > 0 {
+			var dir math32.Vector2
+			dir.SubVectors(&p1, &p0).DivideScalar(seg)
+			pos := p0
+			pos.X += dir.X * dist
+			pos.Y += dir.Y * dist
+			return pos, math32.Atan2(dir.Y, dir.X), true
+		}
+		dist -= seg
+	}
+	return math32.Vector2{}, 0, false
+}
+
+// MeasureTextOnPath returns the length along a path in pixels of the specified single line text.
+func (f *Font) MeasureTextOnPath(text string) float32 {
+
+	f.updateFace()
+	var length fixed.Int26_6
+	prev := rune(-1)
+	for _, r := range text {
+		if prev >= 0 {
+			length += f.face.Kern(prev, r)
+		}
+		adv, _ := f.face.GlyphAdvance(r)
+		length += adv
+		prev = r
+	}
+	return float32(length) / 64
+}
+
+// DrawTextOnPath draws the specified single line text on the specified image along the
+// specified polyline path (in pixels), starting at the specified distance along the path.
+// Each glyph is rotated to follow the path direction at its center and has its baseline on the path.
+// Glyphs which do not fit in the path are not drawn.
+func (f *Font) DrawTextOnPath(text string, path []math32.Vector2, start float32, dst *image.RGBA) {
+
+	f.updateFace()
+	dist := fixed.Int26_6(start * 64)
+	prev := rune(-1)
+	for _, r := range text {
+		if prev >= 0 {
+			dist += f.face.Kern(prev, r)
+		}
+		prev = r
+		dr, mask, maskp, adv, ok := f.face.Glyph(fixed.P(0, 0), r)
+		if !ok {
+			dist += adv
+			continue
+		}
+		half := float32(adv) / 128
+		pos, angle, ok := PathPoint(path, float32(dist)/64+half)
+		dist += adv
+		if !ok {
+			continue
+		}
+		if dr.Empty() {
+			continue
+		}
+
+		// Draw the glyph with the text color on its own image with the pen position at the origin
+		glyph := image.NewRGBA(dr)
+		draw.DrawMask(glyph, dr, f.fg, image.ZP, mask, maskp, draw.Over)
+
+		// Rotate the glyph around its center on the baseline and translate it to the path point
+		cos := float64(math32.Cos(angle))
+		sin := float64(math32.Sin(angle))
+		tx := float64(pos.X) - cos*float64(half)
+		ty := float64(pos.Y) - sin*float64(half)
+		m := f64.Aff3{cos, -sin, tx, sin, cos, ty}
+		xdraw.BiLinear.Transform(dst, m, glyph, dr, xdraw.Over, nil)
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texture
+
+import (
+	"encoding/binary"
+)
+
+// EAC alpha modifiers of each table
+var eacModifiers = [16][8]int{
+	{-3, -6, -9, -15, 2, 5, 8, 14},
+	{-3, -7, -10, -13, 2, 6, 9, 12},
+	{-2, -5, -8, -13, 1, 4, 7, 12},
+	{-2, -4, -6, -13, 1, 3, 5, 12},
+	{-3, -6, -8, -12, 2, 5, 7, 11},
+	{-3, -7, -9, -11, 2, 6, 8, 10},
+	{-4, -7, -8, -11, 3, 6, 7, 10},
+	{-3, -5, -8, -11, 2, 4, 7, 10},
+	{-2, -6, -8, -10, 1, 5, 7, 9},
+	{-2, -5, -8, -10, 1, 4, 7, 9},
+	{-2, -4, -8, -10, 1, 3, 7, 9},
+	{-2, -5, -7, -10, 1, 4, 6, 9},
+	{-3, -4, -7, -10, 2, 3, 6, 9},
+	{-1, -2, -3, -10, 0, 1, 2, 9},
+	{-4, -6, -8, -9, 3, 5, 7, 8},
+	{-3, -5, -7, -9, 2, 4, 6, 8},
+}
+
+// encodeBC1 encodes the specified RGBA pixels, in rows, in a BC1 block with the
+// endpoints at the corners of the bounding box of the colors.
+func encodeBC1(px *[16][4]uint8, dst []byte) {
+
+	lo := [3]uint8{255, 255, 255}
+	var hi [3]uint8
+	for i := range px {
+		for c := 0; c < 3; c++ {
+			if px[i][c] < lo[c] {
+				lo[c] = px[i][c]
+			}
+			if px[i][c] > hi[c] {
+				hi[c] = px[i][c]
+			}
+		}
+	}
+	c0 := pack565(hi)
+	c1 := pack565(lo)
+	binary.LittleEndian.PutUint16(dst[0:], c0)
+	binary.LittleEndian.PutUint16(dst[2:], c1)
+	var indices uint32
+	if c0 != c1 {
+		// Four colors palette: c0, c1, 2/3 c0 + 1/3 c1 and 1/3 c0 + 2/3 c1
+		var palette [4][3]int
+		e0 := unpack565(c0)
+		e1 := unpack565(c1)
+		for c := 0; c < 3; c++ {
+			palette[0][c] = e0[c]
+			palette[1][c] = e1[c]
+			palette[2][c] = (2*e0[c] + e1[c]) / 3
+			palette[3][c] = (e0[c] + 2*e1[c]) / 3
+		}
+		for i := range px {
+			best := 0
+			bestDist := -1
+			for j := range palette {
+				dist := 0
+				for c := 0; c < 3; c++ {
+					d := int(px[i][c]) - palette[j][c]
+					dist += d * d
+				}
+				if bestDist < 0 || dist < bestDist {
+					best = j
+					bestDist = dist
+				}
+			}
+			indices |= uint32(best) << (2 * uint(i))
+		}
+	}
+	binary.LittleEndian.PutUint32(dst[4:], indices)
+}
+
+// encodeBC3Alpha encodes the alpha of the specified RGBA pixels, in rows,
+// in the alpha block of a BC3 block with the minimum and maximum alpha as endpoints.
+func encodeBC3Alpha(px *[16][4]uint8, dst []byte) {
+
+	lo, hi := 255, 0
+	for i := range px {
+		a := int(px[i][3])
+		lo = minInt(lo, a)
+		hi = maxInt(hi, a)
+	}
+	dst[0] = uint8(hi)
+	dst[1] = uint8(lo)
+	var indices uint64
+	if hi != lo {
+		// Eight values palette: a0, a1 and 6 values interpolated from a0 to a1
+		var palette [8]int
+		palette[0] = hi
+		palette[1] = lo
+		for j := 1; j < 7; j++ {
+			palette[j+1] = ((7-j)*hi + j*lo) / 7
+		}
+		for i := range px {
+			best := 0
+			for j := range palette {
+				if absInt(int(px[i][3])-palette[j]) < absInt(int(px[i][3])-palette[best]) {
+					best = j
+				}
+			}
+			indices |= uint64(best) << (3 * uint(i))
+		}
+	}
+	for i := 0; i < 6; i++ {
+		dst[2+i] = uint8(indices >> (8 * uint(i)))
+	}
+}
+
+// encodeEACAlpha encodes the alpha of the specified RGBA pixels, in rows, in an EAC alpha
+// block, with the modifier table and multiplier which best fit the range of the alpha values.
+func encodeEACAlpha(px *[16][4]uint8, dst []byte) {
+
+	lo, hi := 255, 0
+	for i := range px {
+		a := int(px[i][3])
+		lo = minInt(lo, a)
+		hi = maxInt(hi, a)
+	}
+	var best struct {
+		err     int
+		base    int
+		mult    int
+		table   int
+		indices uint64
+	}
+	best.err = -1
+	for t := range eacModifiers {
+		mods := &eacModifiers[t]
+		span := mods[7] - mods[3]
+		mult := clampInt((hi-lo+span/2)/span, 1, 15)
+		base := clampInt(lo-mods[3]*mult, 0, 255)
+		err := 0
+		var indices uint64
+		for i := 0; i < 16; i++ {
+			// Pixels are indexed in columns
+			a := int(px[i%4*4+i/4][3])
+			bestIdx := 0
+			bestErr := -1
+			for j, m := range mods {
+				e := absInt(int(clampByte(base+m*mult)) - a)
+				if bestErr < 0 || e < bestErr {
+					bestIdx = j
+					bestErr = e
+				}
+			}
+			err += bestErr * bestErr
+			indices = indices<<3 | uint64(bestIdx)
+		}
+		if best.err < 0 || err < best.err {
+			best.err = err
+			best.base = base
+			best.mult = mult
+			best.table = t
+			best.indices = indices
+		}
+		if err == 0 {
+			break
+		}
+	}
+	dst[0] = uint8(best.base)
+	dst[1] = uint8(best.mult<<4 | best.table)
+	for i := 0; i < 6; i++ {
+		dst[2+i] = uint8(best.indices >> (8 * uint(5-i)))
+	}
+}
+
+// pack565 returns the specified 8 bits color packed in 16 bits.
+func pack565(c [3]uint8) uint16 {
+
+	return uint16(c[0]>>3)<<11 | uint16(c[1]>>2)<<5 | uint16(c[2]>>3)
+}
+
+// unpack565 returns the 8 bits components of the specified 16 bits color.
+func unpack565(c uint16) [3]int {
+
+	r := int(c >> 11 & 31)
+	g := int(c >> 5 & 63)
+	b := int(c & 31)
+	return [3]int{r<<3 | r>>2, g<<2 | g>>4, b<<3 | b>>2}
+}
+
+// clampByte returns the specified value clamped to the range of a byte.
+func clampByte(v int) uint8 {
+
+	return uint8(clampInt(v, 0, 255))
+}
+
+// clampInt returns the specified value clamped to the specified range.
+func clampInt(v, lo, hi int) int {
+
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// minInt returns the smaller of the specified values.
+func minInt(a, b int) int {
+
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// maxInt returns the larger of the specified values.
+func maxInt(a, b int) int {
+
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// absInt returns the absolute value of the specified value.
+func absInt(v int) int {
+
+	if v < 0 {
+		return -v
+	}
+	return v
+}
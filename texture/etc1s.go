@@ -0,0 +1,758 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texture
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/g3n/engine/gls"
+)
+
+// ETC1S is a subset of ETC1 in which both subblocks of each block have the same base color
+// and intensity table. The Basis Universal ETC1S textures of KTX2 containers are
+// supercompressed with BasisLZ: the blocks are indices into an endpoint codebook, of colors
+// and intensity tables, and a selector codebook, of pixel indices, coded with Huffman tables
+// stored with the codebooks in the supercompression global data.
+// https://github.khronos.org/KTX-Specification/#basisLZ
+
+// Constants of the BasisLZ ETC1S slices
+const (
+	etc1sEndpointPredRepeat  = 256 // Endpoint predictor symbol repeating the previous symbol
+	etc1sEndpointPredMinRun  = 3   // Minimum count of repeated endpoint predictor symbols
+	etc1sEndpointPredRunBits = 4   // Bits of the chunks of the repeat count
+	etc1sSelectorRLEMin      = 3   // Minimum count of selector history runs
+	etc1sSelectorRLETotal    = 64  // Number of selector history run symbols
+	etc1sSelectorRLEBits     = 7   // Bits of the chunks of the long selector history runs
+	etc1sFlagPFrame          = 2   // Image flag of the video frames predicted from the previous one
+)
+
+// Code length codes of the Huffman tables in order of transmission
+var huffCodeLengthOrder = [...]int{17, 18, 19, 20, 0, 8, 7, 9, 6, 10, 5, 11, 4, 12, 3, 13, 2, 14, 1, 15, 16}
+
+// ETC1 intensity modifiers of each table, from the most negative to the most positive,
+// as indexed by the ETC1S selectors
+var etc1Modifiers = [8][4]int{
+	{-8, -2, 2, 8},
+	{-17, -5, 5, 17},
+	{-29, -9, 9, 29},
+	{-42, -13, 13, 42},
+	{-60, -18, 18, 60},
+	{-80, -24, 24, 80},
+	{-106, -33, 33, 106},
+	{-183, -47, 47, 183},
+}
+
+// ETC1 pixel index of each ETC1S selector
+var etc1SelectorIndex = [4]uint{3, 2, 0, 1}
+
+// etc1sTranscoder is the transcoder of Basis Universal ETC1S textures used if the
+// application doesn't set one. It transcodes to ETC2 without loss, to BC1 and BC3
+// with a small loss or to RGBA8 if the OpenGL context supports none of them.
+type etc1sTranscoder struct{}
+
+// etc1sEndpoint is an entry of the endpoint codebook.
+type etc1sEndpoint struct {
+	color [3]uint8 // 5 bits base color
+	inten uint8    // Intensity table
+}
+
+// etc1sSelector is an entry of the selector codebook
+// with the selectors of the pixels of each row in 2 bits.
+type etc1sSelector [4]uint8
+
+// etc1sImage describes the slices of an image of the texture.
+type etc1sImage struct {
+	flags       uint32
+	rgbOffset   uint32
+	rgbLength   uint32
+	alphaOffset uint32
+	alphaLength uint32
+}
+
+// etc1sBlock is a decoded ETC1S block.
+type etc1sBlock struct {
+	endpoint *etc1sEndpoint
+	selector *etc1sSelector
+}
+
+// etc1sGlobal contains the decoded supercompression global data of an ETC1S texture.
+type etc1sGlobal struct {
+	endpoints    []etc1sEndpoint
+	selectors    []etc1sSelector
+	images       []etc1sImage
+	endpointPred huffTable
+	endpointDiff huffTable
+	selector     huffTable
+	selectorRLE  huffTable
+	historySize  int
+}
+
+// huffTable is a canonical Huffman table, whose codes are read starting with the least significant bit.
+type huffTable struct {
+	bits   uint     // Length of the longest code
+	lookup []uint32 // Symbol and code length, in the upper 16 bits, of each code of the longest length
+}
+
+// bitReader reads bits from a byte slice starting with the least significant bit of each byte.
+// Reading past the end of the data returns zero bits.
+type bitReader struct {
+	data []byte
+	buf  uint64
+	n    uint
+}
+
+// errHuffCode is returned when decoding an invalid Huffman code
+var errHuffCode = errors.New("invalid ETC1S Huffman code")
+
+// fill fills the buffer with at least the specified number of bits.
+func (r *bitReader) fill(n uint) {
+
+	for r.n < n {
+		if len(r.data) > 0 {
+			r.buf |= uint64(r.data[0]) << r.n
+			r.data = r.data[1:]
+		}
+		r.n += 8
+	}
+}
+
+// bits reads and returns the specified number of bits, up to 32.
+func (r *bitReader) bits(n uint) uint32 {
+
+	r.fill(n)
+	v := uint32(r.buf & (1<<n - 1))
+	r.buf >>= n
+	r.n -= n
+	return v
+}
+
+// vlc reads and returns a value coded in chunks of the specified number of bits,
+// each followed by a bit set if another chunk follows.
+func (r *bitReader) vlc(chunkBits uint) (uint32, error) {
+
+	var v uint32
+	for shift := uint(0); ; shift += chunkBits {
+		if shift >= 32 {
+			return 0, fmt.Errorf("invalid ETC1S variable length value")
+		}
+		c := r.bits(chunkBits + 1)
+		v |= (c & (1<<chunkBits - 1)) << shift
+		if c&(1<<chunkBits) == 0 {
+			return v, nil
+		}
+	}
+}
+
+// decode reads and returns a symbol coded with the specified Huffman table.
+func (r *bitReader) decode(t *huffTable) (int, error) {
+
+	if t.bits == 0 {
+		return 0, errHuffCode
+	}
+	r.fill(t.bits)
+	e := t.lookup[r.buf&(1<<t.bits-1)]
+	n := uint(e >> 16)
+	if n == 0 {
+		return 0, errHuffCode
+	}
+	r.buf >>= n
+	r.n -= n
+	return int(e & 0xFFFF), nil
+}
+
+// init builds the table from the code length of each symbol, 0 for the unused symbols.
+func (t *huffTable) init(lengths []uint8) error {
+
+	var count [17]uint32
+	t.bits = 0
+	for _, l := range lengths {
+		if l > 16 {
+			return fmt.Errorf("invalid ETC1S Huffman code length")
+		}
+		if l > 0 {
+			count[l]++
+			if uint(l) > t.bits {
+				t.bits = uint(l)
+			}
+		}
+	}
+	t.lookup = nil
+	if t.bits == 0 {
+		return nil
+	}
+	var next [17]uint32
+	code := uint32(0)
+	for l := 1; l <= 16; l++ {
+		next[l] = code
+		code = (code + count[l]) << 1
+	}
+	t.lookup = make([]uint32, 1<<t.bits)
+	for sym, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		c := next[l]
+		next[l]++
+		if c >= 1<<l {
+			return fmt.Errorf("invalid ETC1S Huffman code lengths")
+		}
+		// Reverses the code, which is read starting with its first bit
+		rev := uint32(0)
+		for i := uint8(0); i < l; i++ {
+			rev = rev<<1 | c>>i&1
+		}
+		for i := rev; i < uint32(len(t.lookup)); i += 1 << l {
+			t.lookup[i] = uint32(l)<<16 | uint32(sym)
+		}
+	}
+	return nil
+}
+
+// table reads a Huffman table, whose code lengths are themselves Huffman coded.
+func (r *bitReader) table(t *huffTable) error {
+
+	total := int(r.bits(14))
+	if total == 0 {
+		*t = huffTable{}
+		return nil
+	}
+	var clLengths [21]uint8
+	ncl := int(r.bits(5))
+	if ncl < 1 || ncl > len(clLengths) {
+		return fmt.Errorf("invalid ETC1S Huffman table")
+	}
+	for i := 0; i < ncl; i++ {
+		clLengths[huffCodeLengthOrder[i]] = uint8(r.bits(3))
+	}
+	var cl huffTable
+	err := cl.init(clLengths[:])
+	if err != nil {
+		return err
+	}
+	lengths := make([]uint8, total)
+	for cur := 0; cur < total; {
+		c, err := r.decode(&cl)
+		if err != nil {
+			return err
+		}
+		if c <= 16 {
+			lengths[cur] = uint8(c)
+			cur++
+			continue
+		}
+		var run int
+		switch c {
+		case 17: // Short run of zeros
+			run = int(r.bits(3)) + 3
+		case 18: // Long run of zeros
+			run = int(r.bits(7)) + 11
+		case 19: // Short repeat of the previous length
+			run = int(r.bits(2)) + 3
+		case 20: // Long repeat of the previous length
+			run = int(r.bits(7)) + 7
+		}
+		if cur+run > total {
+			return fmt.Errorf("invalid ETC1S Huffman table")
+		}
+		if c >= 19 {
+			if cur == 0 || lengths[cur-1] == 0 {
+				return fmt.Errorf("invalid ETC1S Huffman table")
+			}
+			for i := 0; i < run; i++ {
+				lengths[cur+i] = lengths[cur-1]
+			}
+		}
+		cur += run
+	}
+	return t.init(lengths)
+}
+
+// newETC1SGlobal decodes the supercompression global data of the specified ETC1S texture.
+func newETC1SGlobal(k *KTX2) (*etc1sGlobal, error) {
+
+	const headerSize = 20
+	data := k.GlobalData
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("invalid ETC1S global data")
+	}
+	le := binary.LittleEndian
+	numEndpoints := int(le.Uint16(data[0:]))
+	numSelectors := int(le.Uint16(data[2:]))
+	endpointsLen := uint64(le.Uint32(data[4:]))
+	selectorsLen := uint64(le.Uint32(data[8:]))
+	tablesLen := uint64(le.Uint32(data[12:]))
+	extendedLen := uint64(le.Uint32(data[16:]))
+	imagesLen := uint64(20 * len(k.Levels))
+	if uint64(len(data)) < headerSize+imagesLen+endpointsLen+selectorsLen+tablesLen+extendedLen {
+		return nil, fmt.Errorf("invalid ETC1S global data")
+	}
+	if numEndpoints == 0 || numSelectors == 0 {
+		return nil, fmt.Errorf("empty ETC1S codebooks")
+	}
+	g := new(etc1sGlobal)
+	g.images = make([]etc1sImage, len(k.Levels))
+	pos := data[headerSize:]
+	for i := range g.images {
+		img := &g.images[i]
+		img.flags = le.Uint32(pos[0:])
+		img.rgbOffset = le.Uint32(pos[4:])
+		img.rgbLength = le.Uint32(pos[8:])
+		img.alphaOffset = le.Uint32(pos[12:])
+		img.alphaLength = le.Uint32(pos[16:])
+		pos = pos[20:]
+	}
+	endpoints := pos[:endpointsLen]
+	selectors := pos[endpointsLen : endpointsLen+selectorsLen]
+	tables := pos[endpointsLen+selectorsLen : endpointsLen+selectorsLen+tablesLen]
+
+	err := g.decodeEndpoints(endpoints, numEndpoints)
+	if err != nil {
+		return nil, err
+	}
+	err = g.decodeSelectors(selectors, numSelectors)
+	if err != nil {
+		return nil, err
+	}
+	r := &bitReader{data: tables}
+	for _, t := range []*huffTable{&g.endpointPred, &g.endpointDiff, &g.selector, &g.selectorRLE} {
+		err := r.table(t)
+		if err != nil {
+			return nil, err
+		}
+	}
+	g.historySize = int(r.bits(13))
+	return g, nil
+}
+
+// decodeEndpoints decodes the endpoint codebook, whose colors and
+// intensity tables are coded as differences with the previous entry.
+func (g *etc1sGlobal) decodeEndpoints(data []byte, count int) error {
+
+	r := &bitReader{data: data}
+	var colorDelta [3]huffTable
+	var intenDelta huffTable
+	for _, t := range []*huffTable{&colorDelta[0], &colorDelta[1], &colorDelta[2], &intenDelta} {
+		err := r.table(t)
+		if err != nil {
+			return err
+		}
+	}
+	gray := r.bits(1) == 1
+	comps := 3
+	if gray {
+		comps = 1
+	}
+	g.endpoints = make([]etc1sEndpoint, count)
+	prev := [3]int{16, 16, 16}
+	prevInten := 0
+	for i := range g.endpoints {
+		e := &g.endpoints[i]
+		d, err := r.decode(&intenDelta)
+		if err != nil {
+			return err
+		}
+		prevInten = (prevInten + d) & 7
+		e.inten = uint8(prevInten)
+		for c := 0; c < comps; c++ {
+			// The table of the differences depends on the previous value
+			t := &colorDelta[2]
+			if prev[c] <= 9 {
+				t = &colorDelta[0]
+			} else if prev[c] <= 21 {
+				t = &colorDelta[1]
+			}
+			d, err := r.decode(t)
+			if err != nil {
+				return err
+			}
+			prev[c] = (prev[c] + d) & 31
+			e.color[c] = uint8(prev[c])
+		}
+		if gray {
+			e.color[1] = e.color[0]
+			e.color[2] = e.color[0]
+		}
+	}
+	return nil
+}
+
+// decodeSelectors decodes the selector codebook, whose rows are either
+// raw or coded as differences with the previous entry.
+func (g *etc1sGlobal) decodeSelectors(data []byte, count int) error {
+
+	r := &bitReader{data: data}
+	if r.bits(1) == 1 || r.bits(1) == 1 {
+		return fmt.Errorf("unsupported ETC1S global selector codebook")
+	}
+	g.selectors = make([]etc1sSelector, count)
+	if r.bits(1) == 1 {
+		for i := range g.selectors {
+			for j := range g.selectors[i] {
+				g.selectors[i][j] = uint8(r.bits(8))
+			}
+		}
+		return nil
+	}
+	var delta huffTable
+	err := r.table(&delta)
+	if err != nil {
+		return err
+	}
+	for j := range g.selectors[0] {
+		g.selectors[0][j] = uint8(r.bits(8))
+	}
+	for i := 1; i < count; i++ {
+		for j := range g.selectors[i] {
+			d, err := r.decode(&delta)
+			if err != nil {
+				return err
+			}
+			g.selectors[i][j] = g.selectors[i-1][j] ^ uint8(d)
+		}
+	}
+	return nil
+}
+
+// decodeSlice decodes the blocks of the specified slice with the specified number of blocks
+// in each row and column. The endpoints of the blocks are predicted from their neighbours and
+// the selectors are either coded directly or refer to a history of the last selectors used.
+func (g *etc1sGlobal) decodeSlice(data []byte, bw, bh int) ([]etc1sBlock, error) {
+
+	type pred struct {
+		endpoint int
+		bits     int
+	}
+	r := &bitReader{data: data}
+	blocks := make([]etc1sBlock, bw*bh)
+	preds := [2][]pred{make([]pred, bw), make([]pred, bw)}
+	numEndpoints := len(g.endpoints)
+	numSelectors := len(g.selectors)
+	history := newSelectorHistory(g.historySize)
+	rleSymbol := numSelectors + g.historySize
+	predRun := 0
+	prevPredSym := 0
+	prevEndpoint := 0
+	selectorRun := 0
+	curPred := 0
+	for by := 0; by < bh; by++ {
+		cur := preds[by&1]
+		prev := preds[by&1^1]
+		for bx := 0; bx < bw; bx++ {
+
+			// The predictors of each 2x2 group of blocks are coded in a single symbol
+			// at its top-left block: the bits of the bottom blocks are kept for the next row.
+			if bx&1 == 0 {
+				if by&1 == 0 {
+					if predRun > 0 {
+						predRun--
+						curPred = prevPredSym
+					} else {
+						sym, err := r.decode(&g.endpointPred)
+						if err != nil {
+							return nil, err
+						}
+						if sym == etc1sEndpointPredRepeat {
+							run, err := r.vlc(etc1sEndpointPredRunBits)
+							if err != nil {
+								return nil, err
+							}
+							predRun = int(run) + etc1sEndpointPredMinRun - 1
+							curPred = prevPredSym
+						} else {
+							curPred = sym
+							prevPredSym = sym
+						}
+					}
+					prev[bx].bits = curPred >> 4
+				} else {
+					curPred = cur[bx].bits
+				}
+			}
+
+			// Endpoint from the left, upper or upper left block or coded as a difference
+			var endpoint int
+			switch curPred & 3 {
+			case 0:
+				if bx == 0 {
+					return nil, fmt.Errorf("invalid ETC1S endpoint predictor")
+				}
+				endpoint = prevEndpoint
+			case 1:
+				if by == 0 {
+					return nil, fmt.Errorf("invalid ETC1S endpoint predictor")
+				}
+				endpoint = prev[bx].endpoint
+			case 2:
+				if bx == 0 || by == 0 {
+					return nil, fmt.Errorf("invalid ETC1S endpoint predictor")
+				}
+				endpoint = prev[bx-1].endpoint
+			default:
+				d, err := r.decode(&g.endpointDiff)
+				if err != nil {
+					return nil, err
+				}
+				endpoint = prevEndpoint + d
+				if endpoint >= numEndpoints {
+					endpoint -= numEndpoints
+				}
+			}
+			curPred >>= 2
+			if endpoint >= numEndpoints {
+				return nil, fmt.Errorf("invalid ETC1S endpoint index")
+			}
+			cur[bx].endpoint = endpoint
+			prevEndpoint = endpoint
+
+			// Selector coded directly, from the history or repeating the last history entry
+			var sym int
+			if selectorRun > 0 {
+				selectorRun--
+				sym = numSelectors
+			} else {
+				var err error
+				sym, err = r.decode(&g.selector)
+				if err != nil {
+					return nil, err
+				}
+				if sym == rleSymbol {
+					run, err := r.decode(&g.selectorRLE)
+					if err != nil {
+						return nil, err
+					}
+					selectorRun = run + etc1sSelectorRLEMin
+					if run == etc1sSelectorRLETotal-1 {
+						long, err := r.vlc(etc1sSelectorRLEBits)
+						if err != nil {
+							return nil, err
+						}
+						selectorRun = int(long) + etc1sSelectorRLEMin
+					}
+					if selectorRun > len(blocks) {
+						return nil, fmt.Errorf("invalid ETC1S selector run")
+					}
+					sym = numSelectors
+					selectorRun--
+				}
+			}
+			var selector int
+			if sym >= numSelectors {
+				idx := sym - numSelectors
+				if idx >= len(history.values) {
+					return nil, fmt.Errorf("invalid ETC1S selector history index")
+				}
+				selector = history.values[idx]
+				history.use(idx)
+			} else {
+				selector = sym
+				if g.historySize > 0 {
+					history.add(selector)
+				}
+			}
+			blocks[by*bw+bx] = etc1sBlock{&g.endpoints[endpoint], &g.selectors[selector]}
+		}
+	}
+	return blocks, nil
+}
+
+// selectorHistory is the history of the last selectors used, approximately
+// ordered from the most recently used one.
+type selectorHistory struct {
+	values []int
+	rover  int
+}
+
+// newSelectorHistory returns a new history of the specified size.
+func newSelectorHistory(size int) *selectorHistory {
+
+	return &selectorHistory{values: make([]int, size), rover: size / 2}
+}
+
+// add adds a selector to the second half of the history.
+func (h *selectorHistory) add(v int) {
+
+	h.values[h.rover] = v
+	h.rover++
+	if h.rover == len(h.values) {
+		h.rover = len(h.values) / 2
+	}
+}
+
+// use moves the selector at the specified index towards the front of the history.
+func (h *selectorHistory) use(idx int) {
+
+	if idx > 0 {
+		h.values[idx/2], h.values[idx] = h.values[idx], h.values[idx/2]
+	}
+}
+
+// colors returns the four colors of the endpoint, from the darkest to the brightest.
+func (e *etc1sEndpoint) colors() [4][3]uint8 {
+
+	var colors [4][3]uint8
+	for s, m := range etc1Modifiers[e.inten] {
+		for c := range e.color {
+			colors[s][c] = clampByte(int(e.color[c]<<3|e.color[c]>>2) + m)
+		}
+	}
+	return colors
+}
+
+// pixels returns the RGBA pixels of the block, in rows, with the alpha of the specified block.
+func (b *etc1sBlock) pixels(alpha *etc1sBlock) [16][4]uint8 {
+
+	var px [16][4]uint8
+	colors := b.endpoint.colors()
+	var alphas [4][3]uint8
+	if alpha != nil {
+		alphas = alpha.endpoint.colors()
+	}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			p := &px[y*4+x]
+			s := b.selector[y] >> (2 * x) & 3
+			copy(p[:3], colors[s][:])
+			p[3] = 255
+			if alpha != nil {
+				p[3] = alphas[alpha.selector[y]>>(2*x)&3][1]
+			}
+		}
+	}
+	return px
+}
+
+// etc1 writes the block in the ETC1 format, which is also an ETC2 RGB block,
+// in the differential mode with the same base color in both subblocks.
+func (b *etc1sBlock) etc1(dst []byte) {
+
+	e := b.endpoint
+	dst[0] = e.color[0] << 3
+	dst[1] = e.color[1] << 3
+	dst[2] = e.color[2] << 3
+	dst[3] = e.inten<<5 | e.inten<<2 | 3
+	var msb, lsb uint16
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			idx := etc1SelectorIndex[b.selector[y]>>(2*x)&3]
+			bit := uint(x*4 + y)
+			msb |= uint16(idx>>1) << bit
+			lsb |= uint16(idx&1) << bit
+		}
+	}
+	binary.BigEndian.PutUint16(dst[4:], msb)
+	binary.BigEndian.PutUint16(dst[6:], lsb)
+}
+
+// Transcode returns the data of each mipmap level of the ETC1S texture transcoded to the
+// specified OpenGL internal format: ETC2 RGB or RGBA, BC1, BC3 or RGBA8.
+func (etc1sTranscoder) Transcode(k *KTX2, iformat uint32) ([][]byte, error) {
+
+	if k.ColorModel != KTX2ColorModelETC1S {
+		return nil, ErrNoBasisTranscoder
+	}
+	g, err := newETC1SGlobal(k)
+	if err != nil {
+		return nil, err
+	}
+	var blockSize int
+	switch iformat {
+	case gls.COMPRESSED_RGB8_ETC2, gls.COMPRESSED_RGB_S3TC_DXT1_EXT:
+		blockSize = 8
+	case gls.COMPRESSED_RGBA8_ETC2_EAC, gls.COMPRESSED_RGBA_S3TC_DXT5_EXT, gls.RGBA8:
+		blockSize = 16
+	default:
+		return nil, fmt.Errorf("unsupported ETC1S transcoding format 0x%X", iformat)
+	}
+	levels := make([][]byte, len(k.Levels))
+	for i, data := range k.Levels {
+		img := &g.images[i]
+		if img.flags&etc1sFlagPFrame != 0 {
+			return nil, fmt.Errorf("unsupported ETC1S video frame")
+		}
+		width := maxInt(k.Width>>uint(i), 1)
+		height := maxInt(k.Height>>uint(i), 1)
+		bw := (width + 3) / 4
+		bh := (height + 3) / 4
+		slice := func(offset, length uint32) ([]byte, error) {
+			if uint64(offset)+uint64(length) > uint64(len(data)) {
+				return nil, fmt.Errorf("invalid ETC1S slice")
+			}
+			return data[offset : offset+length], nil
+		}
+		rgbData, err := slice(img.rgbOffset, img.rgbLength)
+		if err != nil {
+			return nil, err
+		}
+		rgb, err := g.decodeSlice(rgbData, bw, bh)
+		if err != nil {
+			return nil, err
+		}
+		var alpha []etc1sBlock
+		if img.alphaLength > 0 {
+			alphaData, err := slice(img.alphaOffset, img.alphaLength)
+			if err != nil {
+				return nil, err
+			}
+			alpha, err = g.decodeSlice(alphaData, bw, bh)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if iformat == gls.RGBA8 {
+			levels[i] = etc1sPixels(rgb, alpha, width, height)
+			continue
+		}
+		out := make([]byte, len(rgb)*blockSize)
+		for j := range rgb {
+			b := &rgb[j]
+			dst := out[j*blockSize:]
+			var a *etc1sBlock
+			if alpha != nil {
+				a = &alpha[j]
+			}
+			switch iformat {
+			case gls.COMPRESSED_RGB8_ETC2:
+				b.etc1(dst)
+			case gls.COMPRESSED_RGBA8_ETC2_EAC:
+				px := b.pixels(a)
+				encodeEACAlpha(&px, dst)
+				b.etc1(dst[8:])
+			case gls.COMPRESSED_RGB_S3TC_DXT1_EXT:
+				px := b.pixels(a)
+				encodeBC1(&px, dst)
+			case gls.COMPRESSED_RGBA_S3TC_DXT5_EXT:
+				px := b.pixels(a)
+				encodeBC3Alpha(&px, dst)
+				encodeBC1(&px, dst[8:])
+			}
+		}
+		levels[i] = out
+	}
+	return levels, nil
+}
+
+// etc1sPixels returns the RGBA8 pixels of the image with the specified blocks and size.
+func etc1sPixels(rgb, alpha []etc1sBlock, width, height int) []byte {
+
+	bw := (width + 3) / 4
+	out := make([]byte, 4*width*height)
+	for j := range rgb {
+		var a *etc1sBlock
+		if alpha != nil {
+			a = &alpha[j]
+		}
+		px := rgb[j].pixels(a)
+		x0 := j % bw * 4
+		y0 := j / bw * 4
+		for y := 0; y < 4 && y0+y < height; y++ {
+			for x := 0; x < 4 && x0+x < width; x++ {
+				copy(out[4*((y0+y)*width+x0+x):], px[y*4+x][:])
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,307 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texture
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/g3n/engine/gls"
+)
+
+// bitWriter writes bits starting with the least significant bit of each byte.
+type bitWriter struct {
+	data []byte
+	n    uint
+}
+
+func (w *bitWriter) bits(v uint32, n uint) {
+
+	for i := uint(0); i < n; i++ {
+		if w.n%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+		w.data[len(w.data)-1] |= byte(v>>i&1) << (w.n % 8)
+		w.n++
+	}
+}
+
+// code writes the canonical Huffman code of the specified length, starting with its first bit.
+func (w *bitWriter) code(c uint32, length uint) {
+
+	for i := int(length) - 1; i >= 0; i-- {
+		w.bits(c>>uint(i)&1, 1)
+	}
+}
+
+// codeLength returns the length of the codes of a table of the specified number of symbols
+// with the same length for all symbols.
+func codeLength(syms int) uint {
+
+	l := uint(1)
+	for 1<<l < syms {
+		l++
+	}
+	return l
+}
+
+// table writes a Huffman table of the specified number of symbols with the same length for all
+// symbols, whose code lengths are coded with 5 bits codes for the lengths from 0 to 16.
+func (w *bitWriter) table(syms int) {
+
+	w.bits(uint32(syms), 14)
+	w.bits(21, 5)
+	for _, c := range huffCodeLengthOrder {
+		if c <= 16 {
+			w.bits(5, 3)
+		} else {
+			w.bits(0, 3)
+		}
+	}
+	l := codeLength(syms)
+	for i := 0; i < syms; i++ {
+		w.code(uint32(l), 5)
+	}
+}
+
+// symbol writes a symbol of a table written by table.
+func (w *bitWriter) symbol(sym, syms int) {
+
+	w.code(uint32(sym), codeLength(syms))
+}
+
+// newTestETC1S returns a 16x8 ETC1S texture with two endpoints, a red and a gray one, and two
+// selectors, a constant and a gradient one, whose blocks are, from the top left block:
+//
+//	red  gray gray gray
+//	red  red  gray red
+//
+// The red blocks have the constant selector and the gray blocks the gradient selector.
+func newTestETC1S() *KTX2 {
+
+	// Endpoints coded as differences from 16 and the previous intensity table
+	ep := new(bitWriter)
+	for i := 0; i < 4; i++ {
+		ep.table(32)
+	}
+	ep.bits(0, 1)
+	ep.symbol(1, 32)  // intensity 1
+	ep.symbol(15, 32) // red 31
+	ep.symbol(16, 32) // green 0
+	ep.symbol(16, 32) // blue 0
+	ep.symbol(2, 32)  // intensity 3
+	ep.symbol(17, 32) // red 16
+	ep.symbol(16, 32) // green 16
+	ep.symbol(16, 32) // blue 16
+
+	// Raw selectors
+	sel := new(bitWriter)
+	sel.bits(0, 1)
+	sel.bits(0, 1)
+	sel.bits(1, 1)
+	for i := 0; i < 4; i++ {
+		sel.bits(0xFF, 8)
+	}
+	for i := 0; i < 4; i++ {
+		sel.bits(0xE4, 8)
+	}
+
+	tables := new(bitWriter)
+	tables.table(257)
+	tables.table(2)
+	tables.table(3)
+	tables.table(64)
+	tables.bits(0, 13)
+
+	// Endpoint predictors of each 2x2 group: 0 left, 1 upper, 2 upper left and 3 difference
+	slice := new(bitWriter)
+	slice.symbol(3|3<<2|1<<4|2<<6, 257)
+	slice.symbol(0, 2)
+	slice.symbol(0, 3)
+	slice.symbol(1, 2)
+	slice.symbol(1, 3)
+	slice.symbol(0|0<<2|1<<4|3<<6, 257)
+	slice.symbol(1, 3)
+	slice.symbol(1, 3)
+	slice.symbol(0, 3)
+	slice.symbol(0, 3)
+	slice.symbol(1, 3)
+	slice.symbol(1, 2)
+	slice.symbol(0, 3)
+
+	le := binary.LittleEndian
+	global := make([]byte, 40)
+	le.PutUint16(global[0:], 2)
+	le.PutUint16(global[2:], 2)
+	le.PutUint32(global[4:], uint32(len(ep.data)))
+	le.PutUint32(global[8:], uint32(len(sel.data)))
+	le.PutUint32(global[12:], uint32(len(tables.data)))
+	le.PutUint32(global[28:], uint32(len(slice.data)))
+	global = append(global, ep.data...)
+	global = append(global, sel.data...)
+	global = append(global, tables.data...)
+	return &KTX2{
+		Width:            16,
+		Height:           8,
+		Supercompression: KTX2SupercompressionBasisLZ,
+		ColorModel:       KTX2ColorModelETC1S,
+		GlobalData:       global,
+		Levels:           [][]byte{slice.data},
+	}
+}
+
+func TestETC1SPixels(t *testing.T) {
+
+	levels, err := etc1sTranscoder{}.Transcode(newTestETC1S(), gls.RGBA8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pix := levels[0]
+	if len(pix) != 16*8*4 {
+		t.Fatalf("RGBA8 size: %d", len(pix))
+	}
+	tests := []struct {
+		x, y int
+		rgba [4]uint8
+	}{
+		{0, 0, [4]uint8{255, 17, 17, 255}}, // red 255 + 17
+		{3, 3, [4]uint8{255, 17, 17, 255}},
+		{4, 0, [4]uint8{90, 90, 90, 255}}, // gray 132 - 42
+		{5, 0, [4]uint8{119, 119, 119, 255}},
+		{6, 0, [4]uint8{145, 145, 145, 255}},
+		{7, 3, [4]uint8{174, 174, 174, 255}},
+		{12, 2, [4]uint8{90, 90, 90, 255}},
+		{0, 4, [4]uint8{255, 17, 17, 255}},
+		{7, 7, [4]uint8{255, 17, 17, 255}},
+		{11, 5, [4]uint8{174, 174, 174, 255}},
+		{15, 7, [4]uint8{255, 17, 17, 255}},
+	}
+	for _, test := range tests {
+		pos := 4 * (test.y*16 + test.x)
+		var got [4]uint8
+		copy(got[:], pix[pos:])
+		if got != test.rgba {
+			t.Errorf("pixel %d,%d: got %v, want %v", test.x, test.y, got, test.rgba)
+		}
+	}
+}
+
+func TestETC1SToETC1(t *testing.T) {
+
+	levels, err := etc1sTranscoder{}.Transcode(newTestETC1S(), gls.COMPRESSED_RGB8_ETC2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks := levels[0]
+	if len(blocks) != 8*8 {
+		t.Fatalf("ETC2 size: %d", len(blocks))
+	}
+	tests := []struct {
+		block int
+		want  [8]byte
+	}{
+		// Red, table 1, all pixels with the largest positive modifier
+		{0, [8]byte{0xF8, 0x00, 0x00, 0x27, 0x00, 0x00, 0xFF, 0xFF}},
+		// Gray, table 3, pixels from the most negative to the most positive modifier in each row
+		{6, [8]byte{0x80, 0x80, 0x80, 0x6F, 0x00, 0xFF, 0xF0, 0x0F}},
+		{7, [8]byte{0xF8, 0x00, 0x00, 0x27, 0x00, 0x00, 0xFF, 0xFF}},
+	}
+	for _, test := range tests {
+		var got [8]byte
+		copy(got[:], blocks[8*test.block:])
+		if got != test.want {
+			t.Errorf("block %d: got % X, want % X", test.block, got, test.want)
+		}
+	}
+}
+
+func TestETC1SInvalid(t *testing.T) {
+
+	k := newTestETC1S()
+	k.GlobalData = k.GlobalData[:30]
+	_, err := etc1sTranscoder{}.Transcode(k, gls.RGBA8)
+	if err == nil {
+		t.Error("truncated global data decoded")
+	}
+	k = newTestETC1S()
+	k.Levels[0] = []byte{0xFF, 0xFF, 0xFF, 0xFF}
+	_, err = etc1sTranscoder{}.Transcode(k, gls.RGBA8)
+	if err == nil {
+		t.Error("invalid slice decoded")
+	}
+}
+
+func TestEncodeBC1(t *testing.T) {
+
+	var px [16][4]uint8
+	for i := range px {
+		px[i] = [4]uint8{255, 255, 255, 255}
+		if i%2 == 1 {
+			px[i] = [4]uint8{0, 0, 0, 255}
+		}
+	}
+	var block [8]byte
+	encodeBC1(&px, block[:])
+	want := [8]byte{0xFF, 0xFF, 0x00, 0x00, 0x44, 0x44, 0x44, 0x44}
+	if block != want {
+		t.Errorf("got % X, want % X", block, want)
+	}
+}
+
+func TestEncodeAlpha(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		alphas [2]uint8
+	}{
+		{"opaque", [2]uint8{255, 255}},
+		{"transparent", [2]uint8{0, 0}},
+		{"cutout", [2]uint8{0, 255}},
+		{"smooth", [2]uint8{100, 120}},
+	}
+	for _, test := range tests {
+		var px [16][4]uint8
+		for i := range px {
+			px[i][3] = test.alphas[i/8]
+		}
+
+		// Decodes the EAC block
+		var eac [8]byte
+		encodeEACAlpha(&px, eac[:])
+		base := int(eac[0])
+		mult := int(eac[1] >> 4)
+		mods := eacModifiers[eac[1]&15]
+		var bits uint64
+		for _, b := range eac[2:] {
+			bits = bits<<8 | uint64(b)
+		}
+		for i := 0; i < 16; i++ {
+			idx := bits >> uint(45-3*i) & 7
+			got := clampByte(base + mods[idx]*mult)
+			want := px[i%4*4+i/4][3]
+			if absInt(int(got)-int(want)) > 2 {
+				t.Errorf("%s: EAC pixel %d: got %d, want %d", test.name, i, got, want)
+			}
+		}
+
+		// Decodes the BC3 alpha block
+		var bc3 [8]byte
+		encodeBC3Alpha(&px, bc3[:])
+		for i := 0; i < 16; i++ {
+			var bits uint64
+			for j := 5; j >= 0; j-- {
+				bits = bits<<8 | uint64(bc3[2+j])
+			}
+			idx := int(bits >> uint(3*i) & 7)
+			got := int(bc3[idx])
+			if idx > 1 {
+				got = ((8-idx)*int(bc3[0]) + (idx-1)*int(bc3[1])) / 7
+			}
+			if got != int(px[i][3]) {
+				t.Errorf("%s: BC3 pixel %d: got %d, want %d", test.name, i, got, px[i][3])
+			}
+		}
+	}
+}
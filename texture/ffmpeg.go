@@ -0,0 +1,93 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texture
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+)
+
+// FFmpegSource is a FrameSource which decodes video files by running the ffmpeg
+// command, which must be installed and found in the PATH.
+type FFmpegSource struct {
+	cmd    *exec.Cmd     // ffmpeg process
+	out    io.ReadCloser // Standard output of the process with the raw frames
+	width  int           // Width of the frames in pixels
+	height int           // Height of the frames in pixels
+	fps    float64       // Number of frames per second
+}
+
+// NewFFmpegSource creates and returns a pointer to a new frame source which decodes the
+// specified video file, scaling its frames to the specified size and converting them to
+// the specified frame rate. If loop is true the video is repeated indefinitely.
+func NewFFmpegSource(filename string, width, height int, fps float64, loop bool) (*FFmpegSource, error) {
+
+	path, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg not found: %v", err)
+	}
+	args := []string{"-loglevel", "error", "-nostdin"}
+	if loop {
+		args = append(args, "-stream_loop", "-1")
+	}
+	args = append(args,
+		"-i", filename,
+		"-an",
+		"-vf", fmt.Sprintf("scale=%d:%d", width, height),
+		"-r", strconv.FormatFloat(fps, 'f', -1, 64),
+		"-f", "rawvideo",
+		"-pix_fmt", "rgba",
+		"pipe:1",
+	)
+
+	fs := new(FFmpegSource)
+	fs.width = width
+	fs.height = height
+	fs.fps = fps
+	fs.cmd = exec.Command(path, args...)
+	fs.out, err = fs.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	err = fs.cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// Size satisfies the FrameSource interface.
+func (fs *FFmpegSource) Size() (width, height int) {
+
+	return fs.width, fs.height
+}
+
+// FrameRate satisfies the FrameSource interface.
+func (fs *FFmpegSource) FrameRate() float64 {
+
+	return fs.fps
+}
+
+// ReadFrame satisfies the FrameSource interface.
+func (fs *FFmpegSource) ReadFrame(pix []byte) error {
+
+	_, err := io.ReadFull(fs.out, pix[:4*fs.width*fs.height])
+	if err == io.ErrUnexpectedEOF {
+		return io.EOF
+	}
+	return err
+}
+
+// Close satisfies the FrameSource interface terminating the ffmpeg process.
+func (fs *FFmpegSource) Close() error {
+
+	if fs.cmd.ProcessState == nil {
+		fs.cmd.Process.Kill()
+	}
+	fs.cmd.Wait()
+	return nil
+}
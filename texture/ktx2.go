@@ -0,0 +1,409 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texture
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/g3n/engine/gls"
+)
+
+// KTX2 supercompression schemes.
+const (
+	KTX2SupercompressionNone    = 0 // Level data not supercompressed
+	KTX2SupercompressionBasisLZ = 1 // Basis Universal ETC1S data compressed with BasisLZ
+	KTX2SupercompressionZstd    = 2 // Level data compressed with Zstandard
+	KTX2SupercompressionZlib    = 3 // Level data compressed with zlib
+)
+
+// Color models of the data format descriptor of Basis Universal textures.
+const (
+	KTX2ColorModelETC1S = 163 // Basis Universal ETC1S
+	KTX2ColorModelUASTC = 166 // Basis Universal UASTC
+)
+
+// KTX2 file identifier
+var ktx2Identifier = []byte{0xAB, 'K', 'T', 'X', ' ', '2', '0', 0xBB, '\r', '\n', 0x1A, '\n'}
+
+// KTX2 contains the data of a two-dimensional texture decoded from a KTX2 container.
+type KTX2 struct {
+	VkFormat         uint32            // Vulkan format of the level data (0 for Basis Universal)
+	Width            int               // Width of the base level in pixels
+	Height           int               // Height of the base level in pixels
+	Supercompression uint32            // Supercompression scheme of the container
+	ColorModel       int               // Color model of the data format descriptor
+	SRGB             bool              // Whether the colors are encoded with the sRGB transfer function
+	Alpha            bool              // Whether a Basis Universal texture has an alpha channel
+	KeyValues        map[string][]byte // Key/value metadata
+	GlobalData       []byte            // Supercompression global data (BasisLZ codebooks)
+	Levels           [][]byte          // Data of each mipmap level starting with the base level
+}
+
+// BasisTranscoder is the interface for transcoders of Basis Universal textures.
+// The engine transcodes the ETC1S textures to ETC2, BC1, BC3 or RGBA8, but doesn't include
+// a transcoder of the UASTC textures: the applications which load them must set one with
+// SetBasisTranscoder, for example binding the transcoder of the Basis Universal library.
+type BasisTranscoder interface {
+	// Transcode returns the data of each mipmap level of the Basis Universal texture transcoded
+	// to the specified OpenGL internal format, which is a BCn, ETC2 or ASTC 4x4 compressed
+	// format or RGBA8 if the OpenGL context supports none of them.
+	Transcode(k *KTX2, iformat uint32) ([][]byte, error)
+}
+
+// ErrNoBasisTranscoder is returned when loading a Basis Universal UASTC texture if no transcoder was set.
+var ErrNoBasisTranscoder = errors.New("no transcoder of Basis Universal UASTC textures set with texture.SetBasisTranscoder")
+
+// Transcoder of Basis Universal textures set by the application, or nil to transcode only the ETC1S textures
+var basisTranscoder BasisTranscoder
+
+// Decoders of the supercompression schemes of the level data
+var ktx2Decoders = map[uint32]func(data []byte, size int) ([]byte, error){
+	KTX2SupercompressionZlib: inflate,
+}
+
+// Compressed formats to which Basis Universal textures are transcoded, in order of preference.
+// ETC1S transcodes without loss to ETC2 and UASTC to ASTC.
+var etc1sFormats = []uint32{
+	gls.COMPRESSED_RGB8_ETC2,
+	gls.COMPRESSED_RGB_S3TC_DXT1_EXT,
+}
+var etc1sAlphaFormats = []uint32{
+	gls.COMPRESSED_RGBA8_ETC2_EAC,
+	gls.COMPRESSED_RGBA_S3TC_DXT5_EXT,
+}
+var uastcFormats = []uint32{
+	gls.COMPRESSED_RGBA_ASTC_4x4_KHR,
+	gls.COMPRESSED_RGBA_BPTC_UNORM,
+	gls.COMPRESSED_RGBA8_ETC2_EAC,
+	gls.COMPRESSED_RGBA_S3TC_DXT5_EXT,
+}
+
+// Compressed OpenGL internal formats of the compressed Vulkan formats.
+// The sRGB formats map to their linear counterparts, as the shaders decode
+// the sRGB colors, as for the RGBA8 textures loaded from images.
+var ktx2CompressedFormats = map[uint32]uint32{
+	131: gls.COMPRESSED_RGB_S3TC_DXT1_EXT,             // BC1_RGB_UNORM
+	132: gls.COMPRESSED_RGB_S3TC_DXT1_EXT,             // BC1_RGB_SRGB
+	133: gls.COMPRESSED_RGBA_S3TC_DXT1_EXT,            // BC1_RGBA_UNORM
+	134: gls.COMPRESSED_RGBA_S3TC_DXT1_EXT,            // BC1_RGBA_SRGB
+	135: gls.COMPRESSED_RGBA_S3TC_DXT3_EXT,            // BC2_UNORM
+	136: gls.COMPRESSED_RGBA_S3TC_DXT3_EXT,            // BC2_SRGB
+	137: gls.COMPRESSED_RGBA_S3TC_DXT5_EXT,            // BC3_UNORM
+	138: gls.COMPRESSED_RGBA_S3TC_DXT5_EXT,            // BC3_SRGB
+	139: gls.COMPRESSED_RED_RGTC1,                     // BC4_UNORM
+	140: gls.COMPRESSED_SIGNED_RED_RGTC1,              // BC4_SNORM
+	141: gls.COMPRESSED_RG_RGTC2,                      // BC5_UNORM
+	142: gls.COMPRESSED_SIGNED_RG_RGTC2,               // BC5_SNORM
+	143: gls.COMPRESSED_RGB_BPTC_UNSIGNED_FLOAT,       // BC6H_UFLOAT
+	144: gls.COMPRESSED_RGB_BPTC_SIGNED_FLOAT,         // BC6H_SFLOAT
+	145: gls.COMPRESSED_RGBA_BPTC_UNORM,               // BC7_UNORM
+	146: gls.COMPRESSED_RGBA_BPTC_UNORM,               // BC7_SRGB
+	147: gls.COMPRESSED_RGB8_ETC2,                     // ETC2_R8G8B8_UNORM
+	148: gls.COMPRESSED_RGB8_ETC2,                     // ETC2_R8G8B8_SRGB
+	149: gls.COMPRESSED_RGB8_PUNCHTHROUGH_ALPHA1_ETC2, // ETC2_R8G8B8A1_UNORM
+	150: gls.COMPRESSED_RGB8_PUNCHTHROUGH_ALPHA1_ETC2, // ETC2_R8G8B8A1_SRGB
+	151: gls.COMPRESSED_RGBA8_ETC2_EAC,                // ETC2_R8G8B8A8_UNORM
+	152: gls.COMPRESSED_RGBA8_ETC2_EAC,                // ETC2_R8G8B8A8_SRGB
+	153: gls.COMPRESSED_R11_EAC,                       // EAC_R11_UNORM
+	154: gls.COMPRESSED_SIGNED_R11_EAC,                // EAC_R11_SNORM
+	155: gls.COMPRESSED_RG11_EAC,                      // EAC_R11G11_UNORM
+	156: gls.COMPRESSED_SIGNED_RG11_EAC,               // EAC_R11G11_SNORM
+}
+
+// Uncompressed OpenGL formats of the uncompressed Vulkan formats
+var ktx2Formats = map[uint32]struct{ format, iformat int }{
+	23: {gls.RGB, gls.RGB8},   // R8G8B8_UNORM
+	29: {gls.RGB, gls.RGB8},   // R8G8B8_SRGB
+	37: {gls.RGBA, gls.RGBA8}, // R8G8B8A8_UNORM
+	43: {gls.RGBA, gls.RGBA8}, // R8G8B8A8_SRGB
+}
+
+func init() {
+
+	// ASTC formats from 4x4 to 12x12 blocks, UNORM and SRGB alternating
+	for i := uint32(0); i < 14; i++ {
+		ktx2CompressedFormats[157+2*i] = gls.COMPRESSED_RGBA_ASTC_4x4_KHR + i
+		ktx2CompressedFormats[158+2*i] = gls.COMPRESSED_RGBA_ASTC_4x4_KHR + i
+	}
+}
+
+// SetBasisTranscoder sets the transcoder of the Basis Universal textures. Basis Universal
+// textures are transcoded when first rendered to the compressed format supported by the
+// OpenGL context which best preserves their quality. By default only the ETC1S textures
+// are transcoded, so the UASTC textures can't be loaded without calling this function.
+func SetBasisTranscoder(t BasisTranscoder) {
+
+	basisTranscoder = t
+}
+
+// SetKTX2Decoder sets the function which decodes the level data of KTX2 containers with
+// the specified supercompression scheme, as Zstandard, to the specified uncompressed size.
+// The zlib scheme is decoded by default.
+func SetKTX2Decoder(scheme uint32, decode func(data []byte, size int) ([]byte, error)) {
+
+	ktx2Decoders[scheme] = decode
+}
+
+// DecodeKTX2 reads and decodes the specified KTX2 file.
+func DecodeKTX2(path string) (*KTX2, error) {
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeKTX2Data(data)
+}
+
+// DecodeKTX2Data decodes the KTX2 container in the specified data.
+// Only two-dimensional textures, without layers or cube faces, are supported.
+func DecodeKTX2Data(data []byte) (*KTX2, error) {
+
+	const headerSize = 80
+	if len(data) < headerSize || !bytes.Equal(data[:len(ktx2Identifier)], ktx2Identifier) {
+		return nil, fmt.Errorf("invalid KTX2 identifier")
+	}
+	le := binary.LittleEndian
+	u32 := func(pos int) uint32 { return le.Uint32(data[pos:]) }
+	k := new(KTX2)
+	k.VkFormat = u32(12)
+	k.Width = int(u32(20))
+	k.Height = int(u32(24))
+	depth := u32(28)
+	layers := u32(32)
+	faces := u32(36)
+	levelCount := int(u32(40))
+	k.Supercompression = u32(44)
+	if k.Width == 0 || k.Height == 0 || depth > 0 || layers > 0 || faces != 1 {
+		return nil, fmt.Errorf("unsupported KTX2 texture type")
+	}
+	if levelCount == 0 {
+		levelCount = 1
+	}
+	if len(data) < headerSize+24*levelCount {
+		return nil, fmt.Errorf("invalid KTX2 level index")
+	}
+
+	// Returns the slice of the data at the specified offset and length
+	section := func(offset, length uint64) ([]byte, error) {
+		if offset > uint64(len(data)) || length > uint64(len(data))-offset {
+			return nil, fmt.Errorf("invalid KTX2 section")
+		}
+		return data[offset : offset+length], nil
+	}
+
+	// Data format descriptor basic block
+	dfd, err := section(uint64(u32(48)), uint64(u32(52)))
+	if err != nil {
+		return nil, err
+	}
+	if len(dfd) >= 16 {
+		k.ColorModel = int(dfd[12])
+		k.SRGB = dfd[14] == 2
+		// Channel of each sample: AAA for ETC1S, RGBA or RRRG for UASTC
+		for pos := 28; pos+16 <= len(dfd); pos += 16 {
+			ch := dfd[pos+3] & 0xF
+			switch {
+			case k.ColorModel == KTX2ColorModelETC1S && ch == 15:
+				k.Alpha = true
+			case k.ColorModel == KTX2ColorModelUASTC && (ch == 3 || ch == 5):
+				k.Alpha = true
+			}
+		}
+	}
+
+	// Key/value data
+	kvd, err := section(uint64(u32(56)), uint64(u32(60)))
+	if err != nil {
+		return nil, err
+	}
+	k.KeyValues = make(map[string][]byte)
+	for len(kvd) >= 4 {
+		length := int(le.Uint32(kvd))
+		if length > len(kvd)-4 {
+			return nil, fmt.Errorf("invalid KTX2 key/value data")
+		}
+		kv := kvd[4 : 4+length]
+		if sep := bytes.IndexByte(kv, 0); sep >= 0 {
+			k.KeyValues[string(kv[:sep])] = kv[sep+1:]
+		}
+		kvd = kvd[(4+length+3)&^3:]
+	}
+
+	// Supercompression global data
+	k.GlobalData, err = section(le.Uint64(data[64:]), le.Uint64(data[72:]))
+	if err != nil {
+		return nil, err
+	}
+
+	// Level data
+	decode := ktx2Decoders[k.Supercompression]
+	if k.Supercompression != KTX2SupercompressionNone && k.Supercompression != KTX2SupercompressionBasisLZ && decode == nil {
+		return nil, fmt.Errorf("no decoder for KTX2 supercompression scheme %d", k.Supercompression)
+	}
+	k.Levels = make([][]byte, levelCount)
+	for i := range k.Levels {
+		pos := headerSize + 24*i
+		level, err := section(le.Uint64(data[pos:]), le.Uint64(data[pos+8:]))
+		if err != nil {
+			return nil, err
+		}
+		if decode != nil {
+			level, err = decode(level, int(le.Uint64(data[pos+16:])))
+			if err != nil {
+				return nil, err
+			}
+		}
+		k.Levels[i] = level
+	}
+	return k, nil
+}
+
+// Basis returns whether the texture is a Basis Universal texture which must be transcoded.
+func (k *KTX2) Basis() bool {
+
+	return k.VkFormat == 0 && (k.ColorModel == KTX2ColorModelETC1S || k.ColorModel == KTX2ColorModelUASTC)
+}
+
+// basisFormat returns the OpenGL internal format to which the Basis Universal texture is transcoded
+// for the specified OpenGL context: the first of its preferred compressed formats supported or RGBA8.
+func (k *KTX2) basisFormat(gs *gls.GLS) uint32 {
+
+	if supported.gs != gs || supported.gen != gs.Generation() {
+		supported.gs = gs
+		supported.gen = gs.Generation()
+		supported.formats = make(map[uint32]bool)
+		for _, f := range gs.CompressedTextureFormats() {
+			supported.formats[f] = true
+		}
+	}
+	formats := etc1sFormats
+	if k.ColorModel == KTX2ColorModelUASTC {
+		formats = uastcFormats
+	} else if k.Alpha {
+		formats = etc1sAlphaFormats
+	}
+	for _, f := range formats {
+		if supported.formats[f] {
+			return f
+		}
+	}
+	return gls.RGBA8
+}
+
+// Compressed formats supported by the last OpenGL context which transcoded a texture
+var supported struct {
+	gs      *gls.GLS
+	gen     uint32
+	formats map[uint32]bool
+}
+
+// inflate decodes zlib supercompressed level data.
+func inflate(data []byte, size int) ([]byte, error) {
+
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	out := make([]byte, size)
+	if _, err := io.ReadFull(r, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// NewTexture2DFromKTX2 creates a new texture from the specified decoded KTX2 container.
+func NewTexture2DFromKTX2(k *KTX2) (*Texture2D, error) {
+
+	t := newTexture2D()
+	err := t.SetKTX2(k)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// NewTexture2DFromKTX2File creates a new texture from the specified KTX2 file.
+func NewTexture2DFromKTX2File(path string) (*Texture2D, error) {
+
+	k, err := DecodeKTX2(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewTexture2DFromKTX2(k)
+}
+
+// SetKTX2 sets the texture data, with all its mipmap levels, from the specified decoded KTX2
+// container. Textures in GPU compressed or RGBA8 formats are transferred directly. Basis
+// Universal textures are transcoded when first rendered by the transcoder set by
+// SetBasisTranscoder or, for the ETC1S textures, by the engine if none was set.
+func (t *Texture2D) SetKTX2(k *KTX2) error {
+
+	if k.Basis() {
+		if basisTranscoder == nil {
+			if k.ColorModel != KTX2ColorModelETC1S {
+				return ErrNoBasisTranscoder
+			}
+			// Checks the codebooks so invalid textures are reported when loaded
+			_, err := newETC1SGlobal(k)
+			if err != nil {
+				return err
+			}
+		}
+		t.SetCompressedData(k.Width, k.Height, 0, 0, nil)
+		t.ktx2 = k
+		return nil
+	}
+	if iformat, ok := ktx2CompressedFormats[k.VkFormat]; ok {
+		t.SetCompressedLevels(k.Width, k.Height, int32(iformat), k.Levels)
+		return nil
+	}
+	if f, ok := ktx2Formats[k.VkFormat]; ok {
+		t.SetData(k.Width, k.Height, f.format, gls.UNSIGNED_BYTE, f.iformat, k.Levels[0])
+		t.levels = k.Levels
+		return nil
+	}
+	return fmt.Errorf("unsupported KTX2 Vulkan format %d", k.VkFormat)
+}
+
+// SetCompressedLevels sets the compressed data of each mipmap level of the texture,
+// starting with the base level. Mipmaps are not generated for the missing levels.
+func (t *Texture2D) SetCompressedLevels(width, height int, iformat int32, levels [][]byte) {
+
+	t.SetCompressedData(width, height, iformat, int32(len(levels[0])), levels[0])
+	t.levels = levels
+}
+
+// transcode transcodes the Basis Universal texture to the best format supported by the OpenGL context.
+func (t *Texture2D) transcode(gs *gls.GLS) error {
+
+	k := t.ktx2
+	t.ktx2 = nil
+	iformat := k.basisFormat(gs)
+	var tr BasisTranscoder = etc1sTranscoder{}
+	if basisTranscoder != nil {
+		tr = basisTranscoder
+	}
+	levels, err := tr.Transcode(k, iformat)
+	if err != nil {
+		return err
+	}
+	if len(levels) == 0 {
+		return fmt.Errorf("no levels transcoded")
+	}
+	if iformat == gls.RGBA8 {
+		t.SetData(k.Width, k.Height, gls.RGBA, gls.UNSIGNED_BYTE, gls.RGBA8, levels[0])
+		t.levels = levels
+		return nil
+	}
+	t.SetCompressedLevels(k.Width, k.Height, int32(iformat), levels)
+	return nil
+}
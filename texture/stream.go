@@ -0,0 +1,176 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texture
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/g3n/engine/gls"
+)
+
+// FrameSource is the interface for the sources of the frames of a Stream, as video decoders.
+type FrameSource interface {
+	// Size returns the width and height of the frames in pixels.
+	Size() (width, height int)
+	// FrameRate returns the number of frames per second.
+	FrameRate() float64
+	// ReadFrame reads the next frame into the specified buffer with 4*width*height bytes
+	// of RGBA pixels, from the top row to the bottom row. Returns io.EOF after the last frame.
+	ReadFrame(pix []byte) error
+	// Close releases the resources used by the source.
+	Close() error
+}
+
+// Number of frame buffers used by a Stream
+const streamBuffers = 3
+
+// Stream updates a texture with the frames read from a FrameSource at its frame rate.
+// The frames are read in a separate goroutine and transferred to the existing texture
+// storage, so it can be used to show videos or other animated sources on mesh surfaces.
+type Stream struct {
+	tex      *Texture2D    // Texture updated with the frames
+	source   FrameSource   // Source of the frames
+	frameDur time.Duration // Display duration of each frame
+	free     chan []byte   // Buffers available to read frames
+	ready    chan []byte   // Buffers with frames to be displayed
+	quit     chan struct{} // Closed to stop reading frames
+	current  []byte        // Buffer with the frame being displayed
+	next     time.Time     // Time to display the next frame
+	frames   int           // Number of frames displayed
+	done     bool          // All the frames were displayed
+	mu       sync.Mutex    // Protects err
+	err      error         // Error reading frames
+	closeOne sync.Once     // Closes the stream only once
+}
+
+// NewStream creates and returns a pointer to a new stream which starts reading frames from
+// the specified source. Its texture is updated by Update, which must be called every frame.
+func NewStream(source FrameSource) *Stream {
+
+	s := new(Stream)
+	s.source = source
+	width, height := source.Size()
+	s.frameDur = time.Duration(float64(time.Second) / source.FrameRate())
+
+	// Creates the texture without mipmaps as it is updated frequently
+	s.tex = NewTexture2DFromData(width, height, gls.RGBA, gls.UNSIGNED_BYTE, gls.RGBA8, make([]byte, 4*width*height))
+	s.tex.SetGenMipmap(false)
+	s.tex.SetMinFilter(gls.LINEAR)
+
+	s.free = make(chan []byte, streamBuffers)
+	s.ready = make(chan []byte, streamBuffers)
+	s.quit = make(chan struct{})
+	for i := 0; i < streamBuffers; i++ {
+		s.free <- make([]byte, 4*width*height)
+	}
+	go s.read()
+	return s
+}
+
+// Texture returns the texture updated with the frames of the stream.
+func (s *Stream) Texture() *Texture2D {
+
+	return s.tex
+}
+
+// Frames returns the number of frames displayed.
+func (s *Stream) Frames() int {
+
+	return s.frames
+}
+
+// Done returns whether all the frames of the source were displayed.
+func (s *Stream) Done() bool {
+
+	return s.done
+}
+
+// Err returns the error which stopped reading the frames or nil.
+func (s *Stream) Err() error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Update updates the texture with the next frame if its display time was reached.
+// Must be called every frame with the current time. If the frames are read slower
+// than the frame rate the current frame is kept until the next one is available.
+func (s *Stream) Update(now time.Time) {
+
+	if s.done {
+		return
+	}
+	if s.frames > 0 && now.Before(s.next) {
+		return
+	}
+	var buf []byte
+	var ok bool
+	select {
+	case buf, ok = <-s.ready:
+		if !ok {
+			s.done = true
+			return
+		}
+	default:
+		return
+	}
+
+	// The previous frame buffer can be reused as the new frame replaces it
+	s.tex.UpdateData(buf)
+	if s.current != nil {
+		s.free <- s.current
+	}
+	s.current = buf
+	s.frames++
+
+	// Keeps the frame rate, restarting the timing if too late
+	if s.frames == 1 || now.Sub(s.next) > s.frameDur {
+		s.next = now
+	}
+	s.next = s.next.Add(s.frameDur)
+}
+
+// Close stops reading the frames and closes the source.
+// The texture is not disposed.
+func (s *Stream) Close() error {
+
+	var err error
+	s.closeOne.Do(func() {
+		close(s.quit)
+		err = s.source.Close()
+	})
+	return err
+}
+
+// read reads the frames from the source until the end, an error or the stream is closed.
+func (s *Stream) read() {
+
+	defer close(s.ready)
+	for {
+		var buf []byte
+		select {
+		case buf = <-s.free:
+		case <-s.quit:
+			return
+		}
+		err := s.source.ReadFrame(buf)
+		if err != nil {
+			if err != io.EOF {
+				s.mu.Lock()
+				s.err = err
+				s.mu.Unlock()
+			}
+			return
+		}
+		select {
+		case s.ready <- buf:
+		case <-s.quit:
+			return
+		}
+	}
+}
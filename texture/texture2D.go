@@ -24,27 +24,34 @@ var log = logger.New("TEX", logger.Default)
 
 // Texture2D represents a texture
 type Texture2D struct {
-	gs           *gls.GLS    // Pointer to OpenGL state
-	refcount     int         // Current number of references
-	texname      uint32      // Texture handle
-	magFilter    uint32      // magnification filter
-	minFilter    uint32      // minification filter
-	wrapS        uint32      // wrap mode for s coordinate
-	wrapT        uint32      // wrap mode for t coordinate
-	iformat      int32       // internal format
-	width        int32       // texture width in pixels
-	height       int32       // texture height in pixels
-	format       uint32      // format of the pixel data
-	formatType   uint32      // type of the pixel data
-	updateData   bool        // texture data needs to be sent
-	updateParams bool        // texture parameters needs to be sent
-	genMipmap    bool        // generate mipmaps flag
-	compressed   bool        // whether the texture is compressed
-	size         int32       // the size of the texture data in bytes
-	data         interface{} // array with texture data
-	uniUnit      gls.Uniform // Texture unit uniform location cache
-	uniInfo      gls.Uniform // Texture info uniform location cache
-	udata        struct {    // Combined uniform data in 3 vec2:
+	gs           *gls.GLS        // Pointer to OpenGL state
+	refcount     int             // Current number of references
+	texname      uint32          // Texture handle
+	gen          uint32          // Generation of the OpenGL context of the texture handle
+	magFilter    uint32          // magnification filter
+	minFilter    uint32          // minification filter
+	wrapS        uint32          // wrap mode for s coordinate
+	wrapT        uint32          // wrap mode for t coordinate
+	iformat      int32           // internal format
+	width        int32           // texture width in pixels
+	height       int32           // texture height in pixels
+	format       uint32          // format of the pixel data
+	formatType   uint32          // type of the pixel data
+	updateData   bool            // texture data needs to be sent
+	updateSub    bool            // texture data needs to be sent to the existing storage
+	dirty        image.Rectangle // region of the data to send to the existing storage
+	sub          []byte          // buffer with the pixels of the dirty region
+	updateParams bool            // texture parameters needs to be sent
+	genMipmap    bool            // generate mipmaps flag
+	compressed   bool            // whether the texture is compressed
+	size         int32           // the size of the texture data in bytes
+	data         interface{}     // array with texture data
+	levels       [][]byte        // data of each mipmap level when set from KTX2 or compressed levels
+	ktx2         *KTX2           // Basis Universal texture to transcode when first rendered
+	conv         *image.RGBA     // image used to convert the images set by UpdateFromImage
+	uniUnit      gls.Uniform     // Texture unit uniform location cache
+	uniInfo      gls.Uniform     // Texture info uniform location cache
+	udata        struct {        // Combined uniform data in 3 vec2:
 		offsetX float32
 		offsetY float32
 		repeatX float32
@@ -137,10 +144,10 @@ func (t *Texture2D) Dispose() {
 		t.refcount--
 		return
 	}
-	if t.gs != nil {
+	if t.gs != nil && t.gen == t.gs.Generation() {
 		t.gs.DeleteTextures(t.texname)
-		t.gs = nil
 	}
+	t.gs = nil
 }
 
 // TexName returns the texture handle for the texture
@@ -197,6 +204,8 @@ func (t *Texture2D) SetData(width, height int, format int, formatType, iformat i
 	t.iformat = int32(iformat)
 	t.compressed = false
 	t.data = data
+	t.levels = nil
+	t.ktx2 = nil
 	t.updateData = true
 }
 
@@ -209,9 +218,102 @@ func (t *Texture2D) SetCompressedData(width, height int, iformat int32, size int
 	t.compressed = true
 	t.size = size
 	t.data = data
+	t.levels = nil
+	t.ktx2 = nil
 	t.updateData = true
 }
 
+// UpdateData replaces the texture data by the specified data with the same size, format
+// and type of the current data, which is transferred to the existing texture storage
+// without reallocating it. It is intended for textures updated every frame, as video frames.
+// The data must not be modified until the texture is rendered.
+func (t *Texture2D) UpdateData(data interface{}) {
+
+	t.data = data
+	if !t.updateData {
+		t.updateSub = true
+		t.dirty = image.Rect(0, 0, int(t.width), int(t.height))
+	}
+}
+
+// UpdateRegionFromRGBA replaces the texture data by the pixels of the specified RGBA image
+// with the same size of the texture, transferring only the pixels inside the specified
+// rectangle, in image coordinates, to the existing texture storage. The regions updated
+// before the texture is rendered are merged. It is intended for images drawn in software
+// where only a small part changes each frame. If the image is not compatible with the
+// texture storage, all the data is replaced as in UpdateFromRGBA.
+func (t *Texture2D) UpdateRegionFromRGBA(rgba *image.RGBA, rect image.Rectangle) {
+
+	size := rgba.Rect.Size()
+	if t.compressed || t.format != gls.RGBA || t.formatType != gls.UNSIGNED_BYTE ||
+		int32(size.X) != t.width || int32(size.Y) != t.height || rgba.Stride != size.X*4 {
+		t.SetFromRGBA(rgba)
+		return
+	}
+	rect = rect.Intersect(rgba.Rect).Sub(rgba.Rect.Min)
+	t.data = rgba.Pix
+	if t.updateData || rect.Empty() {
+		return
+	}
+	if t.updateSub {
+		t.dirty = t.dirty.Union(rect)
+	} else {
+		t.dirty = rect
+		t.updateSub = true
+	}
+}
+
+// UpdateFromRGBA replaces the texture data by the pixels of the specified RGBA image.
+// If the image has the same size of the texture and the texture has RGBA data,
+// the pixels are transferred without reallocating the texture storage.
+func (t *Texture2D) UpdateFromRGBA(rgba *image.RGBA) {
+
+	size := rgba.Rect.Size()
+	if t.compressed || t.format != gls.RGBA || t.formatType != gls.UNSIGNED_BYTE ||
+		int32(size.X) != t.width || int32(size.Y) != t.height || rgba.Stride != size.X*4 {
+		t.SetFromRGBA(rgba)
+		return
+	}
+	t.UpdateData(rgba.Pix)
+}
+
+// UpdateFromImage replaces the texture data by the pixels of the specified image,
+// converting it to RGBA if necessary, as UpdateFromRGBA.
+func (t *Texture2D) UpdateFromImage(img image.Image) {
+
+	if rgba, ok := img.(*image.RGBA); ok {
+		t.UpdateFromRGBA(rgba)
+		return
+	}
+	// Reuses the conversion image if the size has not changed
+	bounds := img.Bounds()
+	if t.conv == nil || t.conv.Rect.Size() != bounds.Size() {
+		t.conv = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	}
+	draw.Draw(t.conv, t.conv.Bounds(), img, bounds.Min, draw.Src)
+	t.UpdateFromRGBA(t.conv)
+}
+
+// Data returns the current texture data and its format and type.
+func (t *Texture2D) Data() (data interface{}, format, formatType uint32) {
+
+	return t.data, t.format, t.formatType
+}
+
+// SetGenMipmap sets whether mipmaps are generated when the texture data is transferred.
+// The default is true. Textures updated every frame normally disable it and use
+// a minification filter without mipmaps.
+func (t *Texture2D) SetGenMipmap(state bool) {
+
+	t.genMipmap = state
+}
+
+// GenMipmap returns whether mipmaps are generated when the texture data is transferred.
+func (t *Texture2D) GenMipmap() bool {
+
+	return t.genMipmap
+}
+
 // SetVisible sets the visibility state of the texture
 func (t *Texture2D) SetVisible(state bool) {
 
@@ -240,6 +342,12 @@ func (t *Texture2D) SetMagFilter(magFilter uint32) {
 	t.updateParams = true
 }
 
+// MagFilter returns the current magnification filter.
+func (t *Texture2D) MagFilter() uint32 {
+
+	return t.magFilter
+}
+
 // SetMinFilter sets the filter to be applied when the texture element
 // covers less than on pixel. The default value is gls.Linear.
 func (t *Texture2D) SetMinFilter(minFilter uint32) {
@@ -248,6 +356,12 @@ func (t *Texture2D) SetMinFilter(minFilter uint32) {
 	t.updateParams = true
 }
 
+// MinFilter returns the current minification filter.
+func (t *Texture2D) MinFilter() uint32 {
+
+	return t.minFilter
+}
+
 // SetWrapS set the wrapping mode for texture S coordinate
 // The default value is GL_CLAMP_TO_EDGE;
 func (t *Texture2D) SetWrapS(wrapS uint32) {
@@ -256,6 +370,12 @@ func (t *Texture2D) SetWrapS(wrapS uint32) {
 	t.updateParams = true
 }
 
+// WrapS returns the current wrapping mode for texture S coordinate.
+func (t *Texture2D) WrapS() uint32 {
+
+	return t.wrapS
+}
+
 // SetWrapT set the wrapping mode for texture T coordinate
 // The default value is GL_CLAMP_TO_EDGE;
 func (t *Texture2D) SetWrapT(wrapT uint32) {
@@ -264,6 +384,12 @@ func (t *Texture2D) SetWrapT(wrapT uint32) {
 	t.updateParams = true
 }
 
+// WrapT returns the current wrapping mode for texture T coordinate.
+func (t *Texture2D) WrapT() uint32 {
+
+	return t.wrapT
+}
+
 // SetRepeat set the repeat factor
 func (t *Texture2D) SetRepeat(x, y float32) {
 
@@ -347,18 +473,57 @@ func DecodeImage(imgfile string) (*image.RGBA, error) {
 // RenderSetup is called by the material render setup
 func (t *Texture2D) RenderSetup(gs *gls.GLS, slotIdx, uniIdx int) { // Could have as input - TEXTURE0 (slot) and uni location
 
-	// One time initialization
-	if t.gs == nil {
+	t.Bind(gs, slotIdx)
+
+	// Transfer texture unit uniform
+	var location int32
+	if uniIdx == 0 {
+		location = t.uniUnit.Location(gs)
+	} else {
+		location = t.uniUnit.LocationIdx(gs, int32(uniIdx))
+	}
+	gs.Uniform1i(location, int32(slotIdx))
+
+	// Transfer texture info combined uniform
+	const vec2count = 3
+	location = t.uniInfo.LocationIdx(gs, vec2count*int32(uniIdx))
+	gs.Uniform2fv(location, vec2count, &t.udata.offsetX)
+}
+
+// Bind binds this texture to the specified texture unit, transferring its data and parameters
+// to OpenGL if they changed, without transferring its uniforms.
+func (t *Texture2D) Bind(gs *gls.GLS, slotIdx int) {
+
+	// One time initialization or recreation after the OpenGL context was reset
+	if t.gs == nil || t.gen != gs.Generation() {
+		if t.gs != nil {
+			t.updateData = t.width > 0
+			t.updateParams = true
+		}
 		t.texname = gs.GenTexture()
 		t.gs = gs
+		t.gen = gs.Generation()
 	}
 
 	// Sets the texture unit for this texture
 	gs.ActiveTexture(uint32(gls.TEXTURE0 + slotIdx))
 	gs.BindTexture(gls.TEXTURE_2D, t.texname)
 
+	// Transcodes the Basis Universal texture to the best format supported by the context
+	if t.ktx2 != nil && t.updateData {
+		err := t.transcode(gs)
+		if err != nil {
+			log.Error("Transcoding Basis Universal texture: %v", err)
+			t.updateData = false
+		}
+	}
+
 	// Transfer texture data to OpenGL if necessary
-	if t.updateData {
+	if t.updateData && t.levels != nil {
+		t.transferLevels(gs)
+		t.updateData = false
+		t.updateSub = false
+	} else if t.updateData {
 		if t.compressed {
 			gs.CompressedTexImage2D(
 				gls.TEXTURE_2D,
@@ -387,6 +552,17 @@ func (t *Texture2D) RenderSetup(gs *gls.GLS, slotIdx, uniIdx int) { // Could hav
 		}
 		// No data to send
 		t.updateData = false
+		t.updateSub = false
+	} else if t.updateSub {
+		if t.dirty == image.Rect(0, 0, int(t.width), int(t.height)) {
+			gs.TexSubImage2D(gls.TEXTURE_2D, 0, 0, 0, t.width, t.height, t.format, t.formatType, t.data)
+		} else {
+			t.transferRegion(gs)
+		}
+		if t.genMipmap {
+			gs.GenerateMipmap(gls.TEXTURE_2D)
+		}
+		t.updateSub = false
 	}
 
 	// Sets texture parameters if needed
@@ -397,18 +573,49 @@ func (t *Texture2D) RenderSetup(gs *gls.GLS, slotIdx, uniIdx int) { // Could hav
 		gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_WRAP_T, int32(t.wrapT))
 		t.updateParams = false
 	}
+}
 
-	// Transfer texture unit uniform
-	var location int32
-	if uniIdx == 0 {
-		location = t.uniUnit.Location(gs)
-	} else {
-		location = t.uniUnit.LocationIdx(gs, int32(uniIdx))
+// transferLevels transfers the data of each mipmap level to OpenGL and limits
+// the mipmap levels to those transferred, generating the missing levels
+// only for an uncompressed texture with the base level only.
+func (t *Texture2D) transferLevels(gs *gls.GLS) {
+
+	for i, level := range t.levels {
+		width := t.width >> uint(i)
+		if width < 1 {
+			width = 1
+		}
+		height := t.height >> uint(i)
+		if height < 1 {
+			height = 1
+		}
+		if t.compressed {
+			gs.CompressedTexImage2D(gls.TEXTURE_2D, uint32(i), uint32(t.iformat), width, height, int32(len(level)), level)
+		} else {
+			gs.TexImage2D(gls.TEXTURE_2D, int32(i), t.iformat, width, height, t.format, t.formatType, level)
+		}
 	}
-	gs.Uniform1i(location, int32(slotIdx))
+	if t.genMipmap && !t.compressed && len(t.levels) == 1 {
+		gs.GenerateMipmap(gls.TEXTURE_2D)
+		return
+	}
+	gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_MAX_LEVEL, int32(len(t.levels)-1))
+}
 
-	// Transfer texture info combined uniform
-	const vec2count = 3
-	location = t.uniInfo.LocationIdx(gs, vec2count*int32(uniIdx))
-	gs.Uniform2fv(location, vec2count, &t.udata.offsetX)
+// transferRegion copies the pixels of the dirty region of the RGBA data
+// to a packed buffer and transfers them to the existing texture storage.
+func (t *Texture2D) transferRegion(gs *gls.GLS) {
+
+	pix := t.data.([]byte)
+	w, h := t.dirty.Dx(), t.dirty.Dy()
+	if cap(t.sub) < 4*w*h {
+		t.sub = make([]byte, 4*w*h)
+	}
+	t.sub = t.sub[:4*w*h]
+	stride := 4 * int(t.width)
+	for row := 0; row < h; row++ {
+		start := (t.dirty.Min.Y+row)*stride + 4*t.dirty.Min.X
+		copy(t.sub[4*w*row:4*w*(row+1)], pix[start:start+4*w])
+	}
+	gs.TexSubImage2D(gls.TEXTURE_2D, 0, int32(t.dirty.Min.X), int32(t.dirty.Min.Y), int32(w), int32(h), t.format, t.formatType, t.sub)
 }
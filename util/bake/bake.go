@@ -0,0 +1,264 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bake renders the response of materials into textures.
+package bake
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/renderer"
+	"github.com/g3n/engine/texture"
+)
+
+// Baker renders the response of materials, as procedural ramps and combinations of maps,
+// into textures at a chosen resolution, producing simplified materials for export and for
+// lower-end devices. The material is rendered with its own shader over a copy of a geometry
+// whose vertices are moved to their texture coordinates, so each texel gets the color of the
+// material at the surface point with those texture coordinates. By default the scene of the
+// baker only has a white ambient light, so the albedo of lit materials is baked, but other
+// lights can be added to it to also bake the lighting.
+type Baker struct {
+	rend     *renderer.Renderer // Renderer used to render the materials
+	gs       *gls.GLS           // OpenGL state
+	width    int32              // Width of the baked textures in pixels
+	height   int32              // Height of the baked textures in pixels
+	padding  int                // Number of pixels the colors are extended around the texture islands
+	scene    *core.Node         // Scene with the lights
+	cam      *camera.Camera     // Orthographic camera over the texture space
+	fbo      uint32             // Offscreen frame buffer object
+	colorRbo uint32             // Color buffer
+	depthRbo uint32             // Depth buffer
+	gen      uint32             // Generation of the OpenGL context of the frame buffer
+}
+
+// Clear value of the color buffer (no texel)
+var bakeClearColor = []float32{0, 0, 0, 0}
+
+// NewBaker creates and returns a pointer to a new baker which renders textures
+// with the specified size using the specified renderer and OpenGL state.
+func NewBaker(rend *renderer.Renderer, gs *gls.GLS, width, height int) *Baker {
+
+	b := new(Baker)
+	b.rend = rend
+	b.gs = gs
+	b.width = int32(width)
+	b.height = int32(height)
+	b.padding = 2
+	b.scene = core.NewNode()
+	b.scene.Add(light.NewAmbient(&math32.Color{R: 1, G: 1, B: 1}, 1))
+	b.cam = camera.NewOrthographic(1, 0.5, 1.5, 2, camera.Vertical)
+	b.cam.SetPosition(0, 0, 1)
+	b.scene.Add(b.cam)
+
+	b.createBuffers()
+	return b
+}
+
+// createBuffers creates the offscreen frame buffer in the current generation of the OpenGL context.
+func (b *Baker) createBuffers() {
+
+	gs := b.gs
+	b.gen = gs.Generation()
+	b.fbo = gs.GenFramebuffer()
+	b.colorRbo = gs.GenRenderbuffer()
+	b.depthRbo = gs.GenRenderbuffer()
+	gs.BindRenderbuffer(b.colorRbo)
+	gs.RenderbufferStorage(gls.RGBA8, int(b.width), int(b.height))
+	gs.BindRenderbuffer(b.depthRbo)
+	gs.RenderbufferStorage(gls.DEPTH_COMPONENT24, int(b.width), int(b.height))
+	gs.BindRenderbuffer(0)
+	fb := gs.Framebuffer()
+	gs.BindFramebuffer(b.fbo)
+	gs.FramebufferRenderbuffer(gls.COLOR_ATTACHMENT0, b.colorRbo)
+	gs.FramebufferRenderbuffer(gls.DEPTH_ATTACHMENT, b.depthRbo)
+	if gs.CheckFramebufferStatus() != gls.FRAMEBUFFER_COMPLETE {
+		log.Error("Bake frame buffer is incomplete")
+	}
+	gs.BindFramebuffer(fb)
+}
+
+// SetPadding sets the number of pixels the baked colors are extended around the
+// texture islands to avoid seams when the texture is filtered. The default is 2.
+func (b *Baker) SetPadding(pixels int) {
+
+	b.padding = pixels
+}
+
+// Padding returns the number of pixels the baked colors are extended around the texture islands.
+func (b *Baker) Padding() int {
+
+	return b.padding
+}
+
+// Scene returns the scene with the lights used to bake the materials.
+func (b *Baker) Scene() *core.Node {
+
+	return b.scene
+}
+
+// Bake renders and returns the response of the specified material over the texture
+// coordinates of the specified geometry, or over the whole texture if the geometry is nil.
+// The texels not covered by the geometry are transparent except for the padding.
+// It must be called in the goroutine which owns the OpenGL context.
+func (b *Baker) Bake(imat material.IMaterial, geom *geometry.Geometry) (*image.RGBA, error) {
+
+	uvGeom, err := uvGeometry(geom)
+	if err != nil {
+		return nil, err
+	}
+	defer uvGeom.Dispose()
+
+	// The texture islands can have any winding
+	mat := imat.GetMaterial()
+	side := mat.Side()
+	mat.SetSide(material.SideDouble)
+	mesh := graphic.NewMesh(uvGeom, imat)
+	mesh.SetCullable(false)
+	b.scene.Add(mesh)
+	defer func() {
+		b.scene.Remove(mesh)
+		mat.SetSide(side)
+	}()
+
+	// Recreates the frame buffer if the OpenGL context was reset
+	if b.gen != b.gs.Generation() {
+		b.createBuffers()
+	}
+
+	// Render to the offscreen frame buffer saving the current frame buffer and viewport
+	fb := b.gs.Framebuffer()
+	vx, vy, vw, vh := b.gs.GetViewport()
+	b.gs.BindFramebuffer(b.fbo)
+	b.gs.Viewport(0, 0, b.width, b.height)
+	b.gs.DepthMask(true)
+	b.gs.ClearBufferfv(gls.COLOR, 0, bakeClearColor)
+	b.gs.Clear(gls.DEPTH_BUFFER_BIT)
+	err = b.rend.Render(b.scene, b.cam)
+	var pix []byte
+	if err == nil {
+		pix = b.gs.ReadPixels(0, 0, int(b.width), int(b.height), gls.RGBA, gls.UNSIGNED_BYTE)
+	}
+	b.gs.BindFramebuffer(fb)
+	b.gs.Viewport(vx, vy, vw, vh)
+	if err != nil {
+		return nil, err
+	}
+
+	// The rows are read from the bottom (v = 0) to the top of the texture image
+	img := image.NewRGBA(image.Rect(0, 0, int(b.width), int(b.height)))
+	stride := int(b.width) * 4
+	for y := 0; y < int(b.height); y++ {
+		copy(img.Pix[y*img.Stride:y*img.Stride+stride], pix[(int(b.height)-1-y)*stride:])
+	}
+	dilate(img, b.padding)
+	return img, nil
+}
+
+// BakeTexture renders and returns a texture with the response of the specified material
+// over the texture coordinates of the specified geometry, or over the whole texture if nil.
+func (b *Baker) BakeTexture(imat material.IMaterial, geom *geometry.Geometry) (*texture.Texture2D, error) {
+
+	img, err := b.Bake(imat, geom)
+	if err != nil {
+		return nil, err
+	}
+	return texture.NewTexture2DFromRGBA(img), nil
+}
+
+// BakeMaterial returns a new simplified physical material for the specified geometry, or
+// for any geometry if nil, with a base color map with the baked response of the specified material.
+// The material is not metallic and fully rough, as the lighting of the baked albedo is done by the
+// physical shader, and keeps the side and transparency of the specified material.
+func (b *Baker) BakeMaterial(imat material.IMaterial, geom *geometry.Geometry) (*material.Physical, error) {
+
+	tex, err := b.BakeTexture(imat, geom)
+	if err != nil {
+		return nil, err
+	}
+	src := imat.GetMaterial()
+	m := material.NewPhysical()
+	m.SetBaseColorMap(tex)
+	m.SetMetallicFactor(0)
+	m.SetRoughnessFactor(1)
+	m.SetSide(src.Side())
+	m.SetTransparent(src.Transparent())
+	return m, nil
+}
+
+// uvGeometry returns a copy of the specified geometry with the vertex positions moved
+// to their texture coordinates in the XY plane, mapping the texture space [0,1] to
+// [-1,1], or a square covering the whole texture space if the geometry is nil.
+func uvGeometry(geom *geometry.Geometry) (*geometry.Geometry, error) {
+
+	if geom == nil {
+		return geometry.NewPlane(2, 2), nil
+	}
+	if geom.VBO(gls.VertexPosition) == nil || geom.VBO(gls.VertexTexcoord) == nil {
+		return nil, fmt.Errorf("geometry has no vertex positions or texture coordinates")
+	}
+	clone := geom.Clone()
+	posVBO := clone.VBO(gls.VertexPosition)
+	uvVBO := clone.VBO(gls.VertexTexcoord)
+	positions := posVBO.Buffer()
+	uvs := uvVBO.Buffer()
+	posStride := posVBO.Stride()
+	uvStride := uvVBO.Stride()
+	posOffset := posVBO.AttribOffset(gls.VertexPosition)
+	uvOffset := uvVBO.AttribOffset(gls.VertexTexcoord)
+	count := positions.Size() / posStride
+	if n := uvs.Size() / uvStride; n < count {
+		count = n
+	}
+	for i := 0; i < count; i++ {
+		u := (*uvs)[i*uvStride+uvOffset]
+		v := (*uvs)[i*uvStride+uvOffset+1]
+		p := i*posStride + posOffset
+		(*positions)[p] = 2*u - 1
+		(*positions)[p+1] = 2*v - 1
+		(*positions)[p+2] = 0
+	}
+	posVBO.Update()
+	return clone, nil
+}
+
+// dilate extends the colors of the covered pixels of the specified image over
+// the specified number of transparent pixels around them.
+func dilate(img *image.RGBA, pixels int) {
+
+	w := img.Rect.Dx()
+	h := img.Rect.Dy()
+	offsets := [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}}
+	for pass := 0; pass < pixels; pass++ {
+		src := append([]uint8(nil), img.Pix...)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				i := y*img.Stride + x*4
+				if src[i+3] != 0 {
+					continue
+				}
+				for _, off := range offsets {
+					nx, ny := x+off[0], y+off[1]
+					if nx < 0 || ny < 0 || nx >= w || ny >= h {
+						continue
+					}
+					j := ny*img.Stride + nx*4
+					if src[j+3] != 0 {
+						copy(img.Pix[i:i+4], src[j:j+4])
+						break
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"fmt"
+)
+
+// Entry is a logger with structured fields added to all its messages.
+// It is created by Logger.With and emits its messages with the level and writers of its logger.
+type Entry struct {
+	logger *Logger
+	fields []Field
+}
+
+// Debug emits a DEBUG level log message
+func (e *Entry) Debug(format string, v ...interface{}) {
+
+	e.logf(DEBUG, format, v)
+}
+
+// Info emits an INFO level log message
+func (e *Entry) Info(format string, v ...interface{}) {
+
+	e.logf(INFO, format, v)
+}
+
+// Warn emits a WARN level log message
+func (e *Entry) Warn(format string, v ...interface{}) {
+
+	e.logf(WARN, format, v)
+}
+
+// Error emits an ERROR level log message
+func (e *Entry) Error(format string, v ...interface{}) {
+
+	e.logf(ERROR, format, v)
+}
+
+// Debugw emits a DEBUG level log message with the specified key-value pairs
+func (e *Entry) Debugw(msg string, keyvals ...interface{}) {
+
+	e.logger.log(DEBUG, msg, e.fields, keyvals)
+}
+
+// Infow emits an INFO level log message with the specified key-value pairs
+func (e *Entry) Infow(msg string, keyvals ...interface{}) {
+
+	e.logger.log(INFO, msg, e.fields, keyvals)
+}
+
+// Warnw emits a WARN level log message with the specified key-value pairs
+func (e *Entry) Warnw(msg string, keyvals ...interface{}) {
+
+	e.logger.log(WARN, msg, e.fields, keyvals)
+}
+
+// Errorw emits an ERROR level log message with the specified key-value pairs
+func (e *Entry) Errorw(msg string, keyvals ...interface{}) {
+
+	e.logger.log(ERROR, msg, e.fields, keyvals)
+}
+
+// With returns an entry with the fields of this entry and the specified key-value pairs.
+func (e *Entry) With(keyvals ...interface{}) ILogger {
+
+	fields := append([]Field{}, e.fields...)
+	return &Entry{logger: e.logger, fields: appendFields(fields, keyvals)}
+}
+
+// logf formats and emits a log message with the specified level if it is enabled.
+func (e *Entry) logf(level int, format string, v []interface{}) {
+
+	if !e.logger.enabled || level < e.logger.Level() {
+		return
+	}
+	e.logger.log(level, fmt.Sprintf(format, v...), e.fields, nil)
+}
+
+// appendFields appends to the specified fields the specified key-value pairs.
+// Keys which are not strings are formatted and a missing last value is logged as "MISSING".
+func appendFields(fields []Field, keyvals []interface{}) []Field {
+
+	for i := 0; i < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyvals[i])
+		}
+		var value interface{} = "MISSING"
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+		fields = append(fields, Field{Key: key, Value: value})
+	}
+	return fields
+}
@@ -0,0 +1,70 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logger
+
+// Func is a writer which calls a function for each logger event,
+// for instance to show the log messages in a console widget.
+// The function is called with the global logger mutex locked,
+// so it must not emit log messages.
+type Func struct {
+	fn func(event *Event)
+}
+
+// NewFunc creates and returns a pointer to a new Func writer which calls the specified function.
+func NewFunc(fn func(event *Event)) *Func {
+
+	return &Func{fn}
+}
+
+// Write calls the function of the writer with the provided logger event.
+func (f *Func) Write(event *Event) {
+
+	f.fn(event)
+}
+
+// Close does nothing.
+func (f *Func) Close() {
+
+}
+
+// Sync does nothing.
+func (f *Func) Sync() {
+
+}
+
+// Filter is a writer which only writes to another writer the events with the
+// same or higher priorities than its level, so for instance a remote writer
+// only receives errors while the console shows all the messages.
+type Filter struct {
+	writer LoggerWriter
+	level  int
+}
+
+// NewFilter creates and returns a pointer to a new Filter writer which writes to the
+// specified writer the events with the same or higher priorities than the specified level.
+func NewFilter(writer LoggerWriter, level int) *Filter {
+
+	return &Filter{writer, level}
+}
+
+// Write writes the provided logger event to the filtered writer if its level is enabled.
+func (f *Filter) Write(event *Event) {
+
+	if event.level >= f.level {
+		f.writer.Write(event)
+	}
+}
+
+// Close closes the filtered writer.
+func (f *Filter) Close() {
+
+	f.writer.Close()
+}
+
+// Sync syncs the filtered writer.
+func (f *Filter) Sync() {
+
+	f.writer.Sync()
+}
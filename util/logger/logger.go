@@ -3,10 +3,14 @@
 // license that can be found in the LICENSE file.
 
 // Package logger implements an hierarchical logger used by other packages.
+// Each package has its own logger, child of the Default logger, whose level can be set
+// at runtime with SetLevels or, at startup, with the G3N_LOG environment variable,
+// for instance G3N_LOG=gltf=debug,gls=warn.
 package logger
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -43,6 +47,10 @@ var Default *Logger
 var rootLoggers = []*Logger{}
 var mutex sync.Mutex
 
+// Levels set with SetLevels by logger name or path, applied to the loggers created later.
+// They are protected by the global mutex, as the tree of loggers.
+var levelOverrides = map[string]int{}
+
 // LoggerWriter is the interface for all logger writers
 type LoggerWriter interface {
 	Write(*Event)
@@ -50,31 +58,95 @@ type LoggerWriter interface {
 	Sync()
 }
 
+// ILogger is the interface of the loggers and of the entries with structured fields.
+// The methods with the w suffix log a message followed by key-value pairs.
+type ILogger interface {
+	Debug(format string, v ...interface{})
+	Info(format string, v ...interface{})
+	Warn(format string, v ...interface{})
+	Error(format string, v ...interface{})
+	Debugw(msg string, keyvals ...interface{})
+	Infow(msg string, keyvals ...interface{})
+	Warnw(msg string, keyvals ...interface{})
+	Errorw(msg string, keyvals ...interface{})
+	With(keyvals ...interface{}) ILogger
+}
+
 // Logger Object state structure
 type Logger struct {
 	name     string
 	prefix   string
 	enabled  bool
 	level    int
+	levelSet bool
 	format   int
 	outputs  []LoggerWriter
 	parent   *Logger
 	children []*Logger
 }
 
+// Field is a structured field of a log event.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
 // Event is a logger event passed from the logger to its writers.
 type Event struct {
 	time    time.Time
 	level   int
+	logger  string
 	usermsg string
+	fields  []Field
 	fmsg    string
 }
 
+// Time returns the time of the event.
+func (e *Event) Time() time.Time {
+
+	return e.time
+}
+
+// Level returns the level of the event.
+func (e *Event) Level() int {
+
+	return e.level
+}
+
+// Logger returns the path of the logger which emitted the event.
+func (e *Event) Logger() string {
+
+	return e.logger
+}
+
+// Message returns the message of the event without the date, level, logger and fields.
+func (e *Event) Message() string {
+
+	return e.usermsg
+}
+
+// Fields returns the structured fields of the event.
+func (e *Event) Fields() []Field {
+
+	return e.fields
+}
+
+// Formatted returns the formatted line of the event written by the console and file writers.
+func (e *Event) Formatted() string {
+
+	return e.fmsg
+}
+
 // creates the default logger
 func init() {
 	Default = New("G3N", nil)
 	Default.SetFormat(FTIME | FMICROS)
 	Default.AddWriter(NewConsole(false))
+	if spec := os.Getenv("G3N_LOG"); spec != "" {
+		if err := SetLevels(spec); err != nil {
+			Default.Error("G3N_LOG: %v", err)
+		}
+	}
 }
 
 // New creates and returns a new logger with the specified name.
@@ -91,27 +163,71 @@ func New(name string, parent *Logger) *Logger {
 	self.outputs = make([]LoggerWriter, 0)
 	self.children = make([]*Logger, 0)
 	self.parent = parent
+	mutex.Lock()
 	if parent != nil {
 		self.prefix = parent.prefix + "/" + name
 		self.enabled = parent.enabled
-		self.level = parent.level
 		self.format = parent.format
 		parent.children = append(parent.children, self)
 	} else {
 		rootLoggers = append(rootLoggers, self)
 	}
+	level, ok := levelOverrides[strings.ToUpper(self.prefix)]
+	if !ok {
+		level, ok = levelOverrides[strings.ToUpper(name)]
+	}
+	mutex.Unlock()
+	if ok {
+		self.SetLevel(level)
+	}
 	return self
 }
 
+// Name returns the name of this logger.
+func (l *Logger) Name() string {
+
+	return l.name
+}
+
+// Path returns the names of this logger and of its ancestors separated by slashes.
+func (l *Logger) Path() string {
+
+	return l.prefix
+}
+
+// Children returns the child loggers of this logger.
+func (l *Logger) Children() []*Logger {
+
+	return l.children
+}
+
 // SetLevel sets the current level of this logger.
 // Only log messages with levels with the same or higher
 // priorities than the current level will be emitted.
+// Loggers without a level set use the level of their parent.
 func (l *Logger) SetLevel(level int) {
 
 	if level < DEBUG || level > FATAL {
 		return
 	}
 	l.level = level
+	l.levelSet = true
+}
+
+// ResetLevel removes the level set for this logger, which then uses the level of its parent.
+func (l *Logger) ResetLevel() {
+
+	l.levelSet = false
+}
+
+// Level returns the current level of this logger, which is the
+// level of its parent if no level was set for this logger.
+func (l *Logger) Level() int {
+
+	if !l.levelSet && l.parent != nil {
+		return l.parent.Level()
+	}
+	return l.level
 }
 
 // SetLevelByName sets the current level of this logger by level name:
@@ -124,7 +240,7 @@ func (l *Logger) SetLevelByName(lname string) error {
 	lname = strings.ToUpper(lname)
 	for level = 0; level < len(levelNames); level++ {
 		if lname == levelNames[level] {
-			l.level = level
+			l.SetLevel(level)
 			return nil
 		}
 	}
@@ -195,11 +311,51 @@ func (l *Logger) Fatal(format string, v ...interface{}) {
 	l.Log(FATAL, format, v...)
 }
 
+// Debugw emits a DEBUG level log message with the specified key-value pairs
+func (l *Logger) Debugw(msg string, keyvals ...interface{}) {
+
+	l.log(DEBUG, msg, nil, keyvals)
+}
+
+// Infow emits an INFO level log message with the specified key-value pairs
+func (l *Logger) Infow(msg string, keyvals ...interface{}) {
+
+	l.log(INFO, msg, nil, keyvals)
+}
+
+// Warnw emits a WARN level log message with the specified key-value pairs
+func (l *Logger) Warnw(msg string, keyvals ...interface{}) {
+
+	l.log(WARN, msg, nil, keyvals)
+}
+
+// Errorw emits an ERROR level log message with the specified key-value pairs
+func (l *Logger) Errorw(msg string, keyvals ...interface{}) {
+
+	l.log(ERROR, msg, nil, keyvals)
+}
+
+// With returns an entry of this logger which adds the specified key-value pairs to its messages.
+func (l *Logger) With(keyvals ...interface{}) ILogger {
+
+	return &Entry{logger: l, fields: appendFields(nil, keyvals)}
+}
+
 // Log emits a log message with the specified level
 func (l *Logger) Log(level int, format string, v ...interface{}) {
 
 	// Ignores message if logger not enabled or with level bellow the current one.
-	if !l.enabled || level < l.level {
+	if !l.enabled || level < l.Level() {
+		return
+	}
+	l.log(level, fmt.Sprintf(format, v...), nil, nil)
+}
+
+// log emits a log message with the specified level, fields and key-value pairs
+func (l *Logger) log(level int, usermsg string, fields []Field, keyvals []interface{}) {
+
+	// Ignores message if logger not enabled or with level bellow the current one.
+	if !l.enabled || level < l.Level() {
 		return
 	}
 
@@ -228,16 +384,29 @@ func (l *Logger) Log(level int, format string, v ...interface{}) {
 		fdate = append(fdate, sdecs)
 	}
 
-	// Formats message
-	usermsg := fmt.Sprintf(format, v...)
+	// Formats message followed by the fields
+	if len(keyvals) > 0 {
+		fields = appendFields(append([]Field{}, fields...), keyvals)
+	}
+	var sb strings.Builder
+	sb.WriteString(usermsg)
+	for _, f := range fields {
+		value := fmt.Sprint(f.Value)
+		if strings.ContainsAny(value, " \t\n\"=") {
+			value = fmt.Sprintf("%q", value)
+		}
+		sb.WriteString(" " + f.Key + "=" + value)
+	}
 	prefix := l.prefix
-	msg := fmt.Sprintf("%s:%s:%s:%s\n", strings.Join(fdate, ""), levelNames[level][:1], prefix, usermsg)
+	msg := fmt.Sprintf("%s:%s:%s:%s\n", strings.Join(fdate, ""), levelNames[level][:1], prefix, sb.String())
 
 	// Log event
 	var event = Event{
 		time:    now,
 		level:   level,
+		logger:  prefix,
 		usermsg: usermsg,
+		fields:  fields,
 		fmsg:    msg,
 	}
 
@@ -336,9 +505,69 @@ func Fatal(format string, v ...interface{}) {
 	Default.Fatal(format, v...)
 }
 
+// SetLevels sets the levels of loggers from a comma separated list of logger names or paths
+// and level names, such as "gltf=debug,gls=warn". A level without a logger name sets the
+// level of the Default logger. The levels are also applied to the loggers created later.
+func SetLevels(spec string) error {
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name := ""
+		lname := item
+		if pos := strings.Index(item, "="); pos >= 0 {
+			name = strings.ToUpper(strings.TrimSpace(item[:pos]))
+			lname = strings.TrimSpace(item[pos+1:])
+		}
+		level := -1
+		for i, n := range levelNames {
+			if strings.ToUpper(lname) == n {
+				level = i
+			}
+		}
+		if level < 0 {
+			return fmt.Errorf("Invalid log level name: %s", lname)
+		}
+		if name == "" {
+			Default.SetLevel(level)
+			continue
+		}
+		mutex.Lock()
+		levelOverrides[name] = level
+		mutex.Unlock()
+		for _, l := range Loggers() {
+			if strings.ToUpper(l.prefix) == name || strings.ToUpper(l.name) == name {
+				l.SetLevel(level)
+			}
+		}
+	}
+	return nil
+}
+
+// Loggers returns all the loggers, each followed by its descendants.
+func Loggers() []*Logger {
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	var list []*Logger
+	var add func([]*Logger)
+	add = func(logs []*Logger) {
+		for _, l := range logs {
+			list = append(list, l)
+			add(l.children)
+		}
+	}
+	add(rootLoggers)
+	return list
+}
+
 // Find finds a logger with the specified path.
 func Find(path string) *Logger {
 
+	mutex.Lock()
+	defer mutex.Unlock()
 	parts := strings.Split(strings.ToUpper(path), "/")
 	level := 0
 	var find func([]*Logger) *Logger
@@ -0,0 +1,182 @@
+package stats
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/gui"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// HUD parameters
+const (
+	hudWidth        = 200                   // Minimum width of the panel in pixels
+	hudPadding      = 4                     // Padding around the label and the graph in pixels
+	hudGraphHeight  = 40                    // Height of the frame time graph in pixels
+	hudGraphSamples = 100                   // Number of frame times shown in the graph
+	hudGraphMax     = 50 * time.Millisecond // Frame time at the top of the graph
+)
+
+// HUD colors
+var (
+	hudBgColor     = math32.Color4{R: 0, G: 0, B: 0, A: 0.6}
+	hudTextColor   = math32.Color{R: 0.9, G: 0.9, B: 0.9}
+	hudGoodColor   = math32.Color4{R: 0.3, G: 0.9, B: 0.3, A: 1}
+	hudSlowColor   = math32.Color4{R: 0.9, G: 0.9, B: 0.2, A: 1}
+	hudBadColor    = math32.Color4{R: 0.9, G: 0.2, B: 0.2, A: 1}
+	hudTargetColor = math32.Color4{R: 1, G: 1, B: 1, A: 0.4}
+)
+
+// HUD is a small overlay panel which shows the frame rate, a graph of the recent frame
+// times, the draw calls, triangles and cgo calls per frame and the garbage collection
+// pauses. It is added to the scene as any other GUI panel and its Update method must be
+// called once per frame in the render loop. Pressing its toggle key shows and hides it.
+type HUD struct {
+	gui.Panel                 // Embedded panel
+	stats     *Stats          // Statistics shown
+	label     *gui.Label      // Label with the statistics
+	graph     *gui.Canvas     // Canvas with the frame time graph
+	times     []time.Duration // Circular buffer of the recent frame times
+	next      int             // Index of the next frame time in the buffer
+	last      time.Time       // Time of the previous frame
+	interval  time.Duration   // Interval between updates of the statistics
+	key       window.Key      // Key which toggles the visibility
+}
+
+// NewHUD creates and returns a pointer to a new performance HUD
+// showing the statistics of the specified OpenGL state.
+func NewHUD(gs *gls.GLS) *HUD {
+
+	h := new(HUD)
+	h.Panel.Initialize(h, hudWidth, 0)
+	h.SetColor4(&hudBgColor)
+	h.SetPaddings(hudPadding, hudPadding, hudPadding, hudPadding)
+	h.stats = NewStats(gs)
+	h.times = make([]time.Duration, hudGraphSamples)
+	h.last = time.Now()
+	h.interval = 250 * time.Millisecond
+	h.key = window.KeyF3
+
+	h.label = gui.NewLabel("")
+	h.label.SetColor(&hudTextColor)
+	h.Add(h.label)
+	h.graph = gui.NewCanvas(hudWidth-2*hudPadding, hudGraphHeight)
+	h.Add(h.graph)
+	h.setText()
+
+	window.Get().SubscribeID(window.OnKeyDown, h, h.onKey)
+	return h
+}
+
+// SetToggleKey sets the key which shows and hides this HUD. The default is F3.
+func (h *HUD) SetToggleKey(key window.Key) *HUD {
+
+	h.key = key
+	return h
+}
+
+// ToggleKey returns the key which shows and hides this HUD.
+func (h *HUD) ToggleKey() window.Key {
+
+	return h.key
+}
+
+// SetInterval sets the interval between updates of the statistics shown. The default is 250ms.
+func (h *HUD) SetInterval(d time.Duration) *HUD {
+
+	h.interval = d
+	return h
+}
+
+// Interval returns the interval between updates of the statistics shown.
+func (h *HUD) Interval() time.Duration {
+
+	return h.interval
+}
+
+// Stats returns the statistics shown by this HUD.
+func (h *HUD) Stats() *Stats {
+
+	return h.stats
+}
+
+// Update should be called once per frame in the render loop. It records the frame
+// time and updates the statistics shown when the update interval has elapsed.
+func (h *HUD) Update() {
+
+	now := time.Now()
+	h.times[h.next] = now.Sub(h.last)
+	h.next = (h.next + 1) % len(h.times)
+	h.last = now
+	if !h.stats.Update(h.interval) || !h.Visible() {
+		return
+	}
+	h.setText()
+	h.drawGraph()
+}
+
+// Dispose unsubscribes from the window events and releases resources used by this HUD.
+func (h *HUD) Dispose() {
+
+	window.Get().UnsubscribeID(window.OnKeyDown, h)
+	h.Panel.Dispose()
+}
+
+// setText updates the label with the current statistics and resizes the panel to fit it.
+func (h *HUD) setText() {
+
+	s := h.stats
+	h.label.SetText(fmt.Sprintf("FPS: %.1f (%.2f ms)\nDraw calls: %d\nTriangles: %d\nCgo calls: %d\nGC: %d pauses, %.2f ms (max %.2f ms)",
+		s.FPS, durationMs(s.FrameTime), s.Drawcalls, s.Triangles, s.Cgocalls,
+		s.GCPauses, durationMs(s.GCPauseTime), durationMs(s.GCMaxPause)))
+	h.graph.SetPosition(0, h.label.Height()+hudPadding)
+	width := math32.Max(hudWidth-2*hudPadding, h.label.Width())
+	h.graph.SetWidth(width)
+	h.SetContentSize(width, h.label.Height()+hudPadding+hudGraphHeight)
+}
+
+// drawGraph draws a bar for each recent frame time with a line at the 60 FPS frame time.
+func (h *HUD) drawGraph() {
+
+	c := h.graph
+	c.Clear()
+	width := c.Width()
+	barWidth := width / float32(len(h.times))
+	good := gui.NewPath()
+	slow := gui.NewPath()
+	bad := gui.NewPath()
+	for i := 0; i < len(h.times); i++ {
+		ft := h.times[(h.next+i)%len(h.times)]
+		height := hudGraphHeight * math32.Min(float32(ft)/float32(hudGraphMax), 1)
+		path := good
+		if ft > time.Second/30 {
+			path = bad
+		} else if ft > time.Second/60+time.Millisecond {
+			path = slow
+		}
+		path.Rect(float32(i)*barWidth, hudGraphHeight-height, barWidth, height)
+	}
+	c.Fill(good, gui.SolidPaint(&hudGoodColor))
+	c.Fill(slow, gui.SolidPaint(&hudSlowColor))
+	c.Fill(bad, gui.SolidPaint(&hudBadColor))
+
+	y := hudGraphHeight * (1 - float32(time.Second/60)/float32(hudGraphMax))
+	c.Fill(gui.NewPath().Rect(0, y, width, 1), gui.SolidPaint(&hudTargetColor))
+}
+
+// onKey receives subscribed window key events.
+func (h *HUD) onKey(evname string, ev interface{}) {
+
+	kev := ev.(*window.KeyEvent)
+	if kev.Key == h.key {
+		h.SetVisible(!h.Visible())
+	}
+}
+
+// durationMs returns the specified duration in milliseconds.
+func durationMs(d time.Duration) float64 {
+
+	return float64(d) / float64(time.Millisecond)
+}
@@ -9,17 +9,27 @@ import (
 
 // Stats contains several statistics useful for performance evaluation
 type Stats struct {
-	gs           *gls.GLS  // Reference to OpenGL state
-	Glstats      gls.Stats // GLS statistics structure
-	UnilocHits   int       // Uniform location cache hits per frame
-	UnilocMiss   int       // Uniform location cache misses per frame
-	Unisets      int       // Uniform sets per frame
-	Drawcalls    int       // Draw calls per frame
-	Cgocalls     int       // Cgo calls per frame
-	prevGls      gls.Stats // previous gls statistics
-	prevCgocalls int64     // previous number of cgo calls
-	frames       int       // frame counter
-	last         time.Time // last update time
+	gs           *gls.GLS         // Reference to OpenGL state
+	Glstats      gls.Stats        // GLS statistics structure
+	UnilocHits   int              // Uniform location cache hits per frame
+	UnilocMiss   int              // Uniform location cache misses per frame
+	Unisets      int              // Uniform sets per frame
+	Drawcalls    int              // Draw calls per frame
+	Statehits    int              // Redundant state calls avoided by the GLS state cache per frame
+	Cgocalls     int              // Cgo calls per frame
+	Triangles    int              // Triangles drawn per frame
+	FPS          float64          // Frames per second
+	FrameTime    time.Duration    // Average frame time
+	GCPauses     int              // Number of garbage collections in the interval
+	GCPauseTime  time.Duration    // Total garbage collection pause time in the interval
+	GCMaxPause   time.Duration    // Longest garbage collection pause in the interval
+	prevGls      gls.Stats        // previous gls statistics
+	prevCgocalls int64            // previous number of cgo calls
+	prevNumGC    uint32           // previous number of garbage collections
+	prevPauseNs  uint64           // previous total garbage collection pause time
+	memStats     runtime.MemStats // runtime memory statistics
+	frames       int              // frame counter
+	last         time.Time        // last update time
 }
 
 // NewStats creates and returns a pointer to a new statistics object
@@ -28,6 +38,9 @@ func NewStats(gs *gls.GLS) *Stats {
 	s := new(Stats)
 	s.gs = gs
 	s.last = time.Now()
+	runtime.ReadMemStats(&s.memStats)
+	s.prevNumGC = s.memStats.NumGC
+	s.prevPauseNs = s.memStats.PauseTotalNs
 	return s
 }
 
@@ -62,12 +75,41 @@ func (s *Stats) Update(d time.Duration) bool {
 	drawcalls := s.Glstats.Drawcalls - s.prevGls.Drawcalls
 	s.Drawcalls = int(float64(drawcalls) / float64(s.frames))
 
+	// Calculates redundant state calls avoided per frame
+	statehits := s.Glstats.Caphits + s.Glstats.Texhits + s.Glstats.Vaohits + s.Glstats.Proghits + s.Glstats.Statehits -
+		(s.prevGls.Caphits + s.prevGls.Texhits + s.prevGls.Vaohits + s.prevGls.Proghits + s.prevGls.Statehits)
+	s.Statehits = int(float64(statehits) / float64(s.frames))
+
 	// Calculates number of cgo calls per frame
 	current := runtime.NumCgoCall()
 	cgocalls := current - s.prevCgocalls
 	s.Cgocalls = int(float64(cgocalls) / float64(s.frames))
 	s.prevCgocalls = current
 
+	// Calculates number of triangles per frame
+	triangles := s.Glstats.Triangles - s.prevGls.Triangles
+	s.Triangles = int(float64(triangles) / float64(s.frames))
+
+	// Calculates frame rate and average frame time
+	elapsed := now.Sub(s.last)
+	s.FPS = float64(s.frames) / elapsed.Seconds()
+	s.FrameTime = elapsed / time.Duration(s.frames)
+
+	// Calculates garbage collection pauses in the interval
+	runtime.ReadMemStats(&s.memStats)
+	s.GCPauses = int(s.memStats.NumGC - s.prevNumGC)
+	s.GCPauseTime = time.Duration(s.memStats.PauseTotalNs - s.prevPauseNs)
+	s.GCMaxPause = 0
+	n := len(s.memStats.PauseNs) // Circular buffer of the most recent pauses
+	for i := 0; i < s.GCPauses && i < n; i++ {
+		pause := time.Duration(s.memStats.PauseNs[(int(s.memStats.NumGC)+n-1-i)%n])
+		if pause > s.GCMaxPause {
+			s.GCMaxPause = pause
+		}
+	}
+	s.prevNumGC = s.memStats.NumGC
+	s.prevPauseNs = s.memStats.PauseTotalNs
+
 	s.prevGls = s.Glstats
 	s.last = now
 	s.frames = 0
@@ -0,0 +1,306 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package thumbnail renders thumbnail images of models for asset browsers.
+package thumbnail
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/loader/gltf"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/renderer"
+)
+
+// Callback is the function called with the thumbnail image of a model or the error which prevented its rendering.
+type Callback func(img *image.RGBA, err error)
+
+// Thumbnailer renders thumbnail images of models framed by a default camera and lit by
+// a default lighting rig. The glTF files of the models requested with Load are read and
+// parsed by background goroutines and the loaded models are rendered to an offscreen
+// frame buffer, one per call of Update, which must be called in the render loop.
+// The thumbnails can also be rendered synchronously with Render, for example with
+// the headless window of the soft build tag.
+type Thumbnailer struct {
+	rend     *renderer.Renderer // Renderer used to render the thumbnails
+	gs       *gls.GLS           // OpenGL state
+	width    int32              // Width of the thumbnails in pixels
+	height   int32              // Height of the thumbnails in pixels
+	bgColor  []float32          // Background color of the thumbnails
+	dir      math32.Vector3     // Direction from the model center to the camera
+	scene    *core.Node         // Scene with the lighting rig
+	cam      *camera.Camera     // Camera which frames the models
+	fbo      uint32             // Offscreen frame buffer object
+	colorRbo uint32             // Color buffer
+	depthRbo uint32             // Depth buffer
+	gen      uint32             // Generation of the OpenGL context of the frame buffer
+	mutex    sync.Mutex         // Protects the loaded requests
+	loaded   []request          // Requests whose models were loaded and wait to be rendered
+}
+
+// request is a thumbnail requested with Load.
+type request struct {
+	node core.INode // Loaded model
+	err  error      // Loading error
+	cb   Callback   // Function called with the thumbnail
+}
+
+// NewThumbnailer creates and returns a pointer to a new thumbnailer which renders
+// thumbnails with the specified size using the specified renderer and OpenGL state.
+func NewThumbnailer(rend *renderer.Renderer, gs *gls.GLS, width, height int) *Thumbnailer {
+
+	t := new(Thumbnailer)
+	t.rend = rend
+	t.gs = gs
+	t.width = int32(width)
+	t.height = int32(height)
+	t.bgColor = []float32{0, 0, 0, 0}
+	t.dir.Set(1, 0.7, 1.3).Normalize()
+
+	// Default lighting rig with a key, a fill and a back light
+	t.scene = core.NewNode()
+	t.scene.Add(light.NewAmbient(&math32.Color{R: 1, G: 1, B: 1}, 0.4))
+	key := light.NewDirectional(&math32.Color{R: 1, G: 1, B: 1}, 1.0)
+	key.SetPosition(1, 2, 2)
+	t.scene.Add(key)
+	fill := light.NewDirectional(&math32.Color{R: 0.8, G: 0.85, B: 1}, 0.4)
+	fill.SetPosition(-2, 0.5, 1)
+	t.scene.Add(fill)
+	back := light.NewDirectional(&math32.Color{R: 1, G: 1, B: 1}, 0.3)
+	back.SetPosition(0, 1, -2)
+	t.scene.Add(back)
+	t.cam = camera.NewPerspective(float32(width)/float32(height), 0.1, 100, 30, camera.Vertical)
+	t.scene.Add(t.cam)
+
+	t.createBuffers()
+	return t
+}
+
+// createBuffers creates the offscreen frame buffer in the current generation of the OpenGL context.
+func (t *Thumbnailer) createBuffers() {
+
+	gs := t.gs
+	t.gen = gs.Generation()
+	t.fbo = gs.GenFramebuffer()
+	t.colorRbo = gs.GenRenderbuffer()
+	t.depthRbo = gs.GenRenderbuffer()
+	gs.BindRenderbuffer(t.colorRbo)
+	gs.RenderbufferStorage(gls.RGBA8, int(t.width), int(t.height))
+	gs.BindRenderbuffer(t.depthRbo)
+	gs.RenderbufferStorage(gls.DEPTH_COMPONENT24, int(t.width), int(t.height))
+	gs.BindRenderbuffer(0)
+	fb := gs.Framebuffer()
+	gs.BindFramebuffer(t.fbo)
+	gs.FramebufferRenderbuffer(gls.COLOR_ATTACHMENT0, t.colorRbo)
+	gs.FramebufferRenderbuffer(gls.DEPTH_ATTACHMENT, t.depthRbo)
+	if gs.CheckFramebufferStatus() != gls.FRAMEBUFFER_COMPLETE {
+		log.Error("Thumbnail frame buffer is incomplete")
+	}
+	gs.BindFramebuffer(fb)
+}
+
+// SetBgColor sets the background color of the thumbnails. The default is transparent.
+func (t *Thumbnailer) SetBgColor(color *math32.Color4) {
+
+	t.bgColor = []float32{color.R, color.G, color.B, color.A}
+}
+
+// BgColor returns the background color of the thumbnails.
+func (t *Thumbnailer) BgColor() math32.Color4 {
+
+	return math32.Color4{R: t.bgColor[0], G: t.bgColor[1], B: t.bgColor[2], A: t.bgColor[3]}
+}
+
+// SetViewDirection sets the direction from the center of the models to the camera.
+// The default is from the front, right and above.
+func (t *Thumbnailer) SetViewDirection(dir *math32.Vector3) {
+
+	t.dir = *dir
+	t.dir.Normalize()
+}
+
+// ViewDirection returns the direction from the center of the models to the camera.
+func (t *Thumbnailer) ViewDirection() math32.Vector3 {
+
+	return t.dir
+}
+
+// Scene returns the scene with the lighting rig and the camera, so lights can be changed.
+func (t *Thumbnailer) Scene() *core.Node {
+
+	return t.scene
+}
+
+// Load starts loading the default scene of the specified glTF (.gltf or .glb) file in
+// a background goroutine. When it is loaded, its thumbnail is rendered by Update and
+// the specified function is called with it in the goroutine which calls Update.
+func (t *Thumbnailer) Load(path string, cb Callback) {
+
+	go func() {
+		node, err := loadGLTF(path)
+		t.mutex.Lock()
+		t.loaded = append(t.loaded, request{node: node, err: err, cb: cb})
+		t.mutex.Unlock()
+	}()
+}
+
+// Pending returns if there are loaded models waiting to be rendered by Update.
+func (t *Thumbnailer) Pending() bool {
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return len(t.loaded) > 0
+}
+
+// Update renders the thumbnail of the next loaded model, if any, and calls its callback.
+// It must be called in the render loop, in the goroutine which owns the OpenGL context.
+func (t *Thumbnailer) Update() {
+
+	t.mutex.Lock()
+	if len(t.loaded) == 0 {
+		t.mutex.Unlock()
+		return
+	}
+	req := t.loaded[0]
+	t.loaded[0] = request{}
+	t.loaded = t.loaded[1:]
+	t.mutex.Unlock()
+
+	if req.err != nil {
+		req.cb(nil, req.err)
+		return
+	}
+	img, err := t.Render(req.node)
+	req.node.Dispose()
+	req.cb(img, err)
+}
+
+// Render renders and returns the thumbnail of the specified model, which is temporarily
+// added to the scene of the thumbnailer. It must be called in the goroutine which owns
+// the OpenGL context.
+func (t *Thumbnailer) Render(model core.INode) (*image.RGBA, error) {
+
+	parent := model.Parent()
+	t.scene.Add(model)
+	defer func() {
+		t.scene.Remove(model)
+		if parent != nil {
+			parent.GetNode().Add(model)
+		}
+	}()
+	t.scene.UpdateMatrixWorld()
+	t.frame(model)
+
+	// Recreates the frame buffer if the OpenGL context was reset
+	if t.gen != t.gs.Generation() {
+		t.createBuffers()
+	}
+
+	// Render to the offscreen frame buffer saving the current frame buffer and viewport
+	fb := t.gs.Framebuffer()
+	vx, vy, vw, vh := t.gs.GetViewport()
+	t.gs.BindFramebuffer(t.fbo)
+	t.gs.Viewport(0, 0, t.width, t.height)
+	t.gs.DepthMask(true)
+	t.gs.ClearBufferfv(gls.COLOR, 0, t.bgColor)
+	t.gs.Clear(gls.DEPTH_BUFFER_BIT)
+	err := t.rend.Render(t.scene, t.cam)
+	var pix []byte
+	if err == nil {
+		pix = t.gs.ReadPixels(0, 0, int(t.width), int(t.height), gls.RGBA, gls.UNSIGNED_BYTE)
+	}
+	t.gs.BindFramebuffer(fb)
+	t.gs.Viewport(vx, vy, vw, vh)
+	if err != nil {
+		return nil, err
+	}
+
+	// The rows are read from the bottom to the top
+	img := image.NewRGBA(image.Rect(0, 0, int(t.width), int(t.height)))
+	stride := int(t.width) * 4
+	for y := 0; y < int(t.height); y++ {
+		copy(img.Pix[y*img.Stride:y*img.Stride+stride], pix[(int(t.height)-1-y)*stride:])
+	}
+	return img, nil
+}
+
+// frame positions the camera so the bounding sphere of the specified model fills the thumbnail.
+func (t *Thumbnailer) frame(model core.INode) {
+
+	box := worldBox(model)
+	if box.Empty() {
+		box.Set(&math32.Vector3{X: -1, Y: -1, Z: -1}, &math32.Vector3{X: 1, Y: 1, Z: 1})
+	}
+	var center math32.Vector3
+	box.Center(&center)
+	radius := math32.Max(box.Size(nil).Length()/2, 1e-3)
+
+	// Distance where the bounding sphere fits the smallest field of view
+	halfFov := math32.DegToRad(t.cam.Fov() / 2)
+	if aspect := t.cam.Aspect(); aspect < 1 {
+		halfFov = math32.Atan(math32.Tan(halfFov) * aspect)
+	}
+	dist := radius / math32.Sin(halfFov)
+	t.cam.SetNear(math32.Max(dist-radius, dist/100) * 0.9)
+	t.cam.SetFar((dist + radius) * 1.1)
+	pos := t.dir
+	pos.MultiplyScalar(dist).Add(&center)
+	t.cam.SetPositionVec(&pos)
+	t.cam.LookAt(&center, &math32.Vector3{X: 0, Y: 1, Z: 0})
+}
+
+// worldBox returns the bounding box in world coordinates of the geometries of the visible
+// graphics of the specified node and its descendants. The world matrices must be updated.
+func worldBox(inode core.INode) math32.Box3 {
+
+	var box math32.Box3
+	box.MakeEmpty()
+	if !inode.Visible() {
+		return box
+	}
+	if igr, ok := inode.(graphic.IGraphic); ok && igr.Renderable() {
+		box = igr.GetGeometry().BoundingBox()
+		m := inode.GetNode().MatrixWorld()
+		box.ApplyMatrix4(&m)
+	}
+	for _, child := range inode.Children() {
+		childBox := worldBox(child)
+		if !childBox.Empty() {
+			box.Union(&childBox)
+		}
+	}
+	return box
+}
+
+// loadGLTF loads and returns the default scene of the specified glTF file.
+func loadGLTF(path string) (core.INode, error) {
+
+	var g *gltf.GLTF
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gltf":
+		g, err = gltf.ParseJSON(path)
+	case ".glb":
+		g, err = gltf.ParseBin(path)
+	default:
+		return nil, fmt.Errorf("unsupported model file: %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	scene := 0
+	if g.Scene != nil {
+		scene = *g.Scene
+	}
+	return g.LoadScene(scene)
+}
@@ -0,0 +1,18 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build wasm
+// +build wasm
+
+package watcher
+
+import (
+	"errors"
+)
+
+// newBackend returns an error as file notifications are not supported in the browser.
+func newBackend(w *Watcher) (backend, error) {
+
+	return nil, errors.New("file watching is not supported")
+}
@@ -0,0 +1,59 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !wasm
+// +build !wasm
+
+package watcher
+
+import (
+	"github.com/fsnotify/fsnotify"
+)
+
+// fsnotifyBackend receives the operating system notifications using fsnotify.
+type fsnotifyBackend struct {
+	fsw *fsnotify.Watcher
+}
+
+// newBackend creates the fsnotify backend and starts
+// the goroutine which notifies the specified watcher.
+func newBackend(w *Watcher) (backend, error) {
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for {
+			select {
+			case ev, ok := <-fsw.Events:
+				if !ok {
+					return
+				}
+				w.notify(ev.Name, Op(ev.Op))
+			case err, ok := <-fsw.Errors:
+				if !ok {
+					return
+				}
+				log.Error("%v", err)
+			}
+		}
+	}()
+	return &fsnotifyBackend{fsw}, nil
+}
+
+func (b *fsnotifyBackend) add(path string) error {
+
+	return b.fsw.Add(path)
+}
+
+func (b *fsnotifyBackend) remove(path string) error {
+
+	return b.fsw.Remove(path)
+}
+
+func (b *fsnotifyBackend) close() error {
+
+	return b.fsw.Close()
+}
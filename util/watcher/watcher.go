@@ -0,0 +1,200 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package watcher implements a file watcher with debounce and
+// glob filters used to reload files when they are changed.
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/g3n/engine/core"
+)
+
+// OnChange is the event dispatched by Update for each changed file.
+// The event parameter is a pointer to an Event.
+const OnChange = "watcher.OnChange"
+
+// Op is a bit mask of the operations which changed a file.
+type Op uint32
+
+// The operations which change a file
+const (
+	Create Op = 1 << iota // File or directory created
+	Write                 // File written
+	Remove                // File or directory removed
+	Rename                // File or directory renamed (the new name is reported as created)
+	Chmod                 // File attributes changed
+)
+
+// Event describes the changes of a file during the debounce period.
+type Event struct {
+	Name string // Path of the changed file
+	Op   Op     // Operations which changed the file
+}
+
+// Watcher watches files and directories and dispatches the OnChange event from Update
+// for each changed file whose name matches the filters, after it has not changed
+// for the debounce period, so the multiple changes done by editors when saving
+// a file are reported once and the file can be reloaded from the main loop.
+type Watcher struct {
+	core.Dispatcher                    // Embedded event dispatcher
+	backend         backend            // Operating system notifications
+	mutex           sync.Mutex         // Protects the fields below
+	filters         []string           // Glob patterns matched with the base names of the changed files
+	debounce        time.Duration      // Time without changes after which a change is dispatched
+	pending         map[string]*change // Changes not yet dispatched by path
+	events          []Event            // Events being dispatched by Update
+}
+
+// change describes the pending changes of a file.
+type change struct {
+	op   Op        // Operations which changed the file
+	last time.Time // Time of the last change
+}
+
+// backend is the interface of the operating system notifications.
+type backend interface {
+	add(path string) error
+	remove(path string) error
+	close() error
+}
+
+// New creates and returns a pointer to a new Watcher with a debounce period of
+// 100ms and no filters, or an error if file notifications are not supported.
+func New() (*Watcher, error) {
+
+	w := new(Watcher)
+	w.Dispatcher.Initialize()
+	w.debounce = 100 * time.Millisecond
+	w.pending = make(map[string]*change)
+	b, err := newBackend(w)
+	if err != nil {
+		return nil, err
+	}
+	w.backend = b
+	return w, nil
+}
+
+// Add starts watching the specified file or the files of the specified directory.
+func (w *Watcher) Add(path string) error {
+
+	return w.backend.add(path)
+}
+
+// AddTree starts watching the files of the specified directory and of all its subdirectories.
+// Subdirectories created after the call are not watched.
+func (w *Watcher) AddTree(dir string) error {
+
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		return w.backend.add(path)
+	})
+}
+
+// Remove stops watching the specified file or directory.
+func (w *Watcher) Remove(path string) error {
+
+	return w.backend.remove(path)
+}
+
+// SetFilters sets the glob patterns, as used by filepath.Match, which the base names of the
+// changed files must match to be dispatched. With no patterns (the default) all changes are dispatched.
+func (w *Watcher) SetFilters(patterns ...string) error {
+
+	for _, p := range patterns {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return err
+		}
+	}
+	w.mutex.Lock()
+	w.filters = append([]string(nil), patterns...)
+	w.mutex.Unlock()
+	return nil
+}
+
+// SetDebounce sets the time a file must not change before its changes are dispatched.
+func (w *Watcher) SetDebounce(d time.Duration) {
+
+	w.mutex.Lock()
+	w.debounce = d
+	w.mutex.Unlock()
+}
+
+// Debounce returns the time a file must not change before its changes are dispatched.
+func (w *Watcher) Debounce() time.Duration {
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.debounce
+}
+
+// Update dispatches the OnChange event, in the order of their paths, for each file which has not changed for the debounce
+// period since its last change. It should be called from the main loop, normally once per frame.
+func (w *Watcher) Update() {
+
+	now := time.Now()
+	w.mutex.Lock()
+	w.events = w.events[:0]
+	for name, c := range w.pending {
+		if now.Sub(c.last) < w.debounce {
+			continue
+		}
+		w.events = append(w.events, Event{Name: name, Op: c.op})
+		delete(w.pending, name)
+	}
+	w.mutex.Unlock()
+
+	sort.Slice(w.events, func(i, j int) bool { return w.events[i].Name < w.events[j].Name })
+	for i := range w.events {
+		w.Dispatch(OnChange, &w.events[i])
+	}
+}
+
+// Close stops watching all files and releases the resources of the watcher.
+func (w *Watcher) Close() error {
+
+	return w.backend.close()
+}
+
+// notify is called by the backend for each change of a file.
+func (w *Watcher) notify(name string, op Op) {
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if !w.match(name) {
+		return
+	}
+	c := w.pending[name]
+	if c == nil {
+		c = new(change)
+		w.pending[name] = c
+	}
+	c.op |= op
+	c.last = time.Now()
+}
+
+// match returns whether the base name of the specified path matches the filters.
+func (w *Watcher) match(name string) bool {
+
+	if len(w.filters) == 0 {
+		return true
+	}
+	base := filepath.Base(name)
+	for _, p := range w.filters {
+		if ok, _ := filepath.Match(p, base); ok {
+			return true
+		}
+	}
+	return false
+}
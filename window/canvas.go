@@ -8,11 +8,15 @@
 package window
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
 	"github.com/g3n/engine/core"
 	"github.com/g3n/engine/gls"
 	"github.com/g3n/engine/util/wasm"
-	_ "image/png"
+	"image"
+	"image/png"
+	"os"
 	"syscall/js"
 )
 
@@ -304,6 +308,7 @@ const (
 	CursorInputMode             = InputMode(iota) // See Cursor mode values
 	StickyKeysInputMode                           // Value can be either 1 or 0
 	StickyMouseButtonsInputMode                   // Value can be either 1 or 0
+	RawMouseMotionInputMode                       // Value can be either 1 or 0
 )
 
 // Cursor mode values
@@ -317,7 +322,13 @@ const (
 type WebGlCanvas struct {
 	core.Dispatcher          // Embedded event dispatcher
 	canvas          js.Value // Associated WebGL canvas
+	textArea        js.Value // Hidden text area focused to receive IME compositions
 	gls             *gls.GLS // Associated WebGL state
+	clipboard       string   // Last clipboard contents set
+
+	// Cursors
+	cursors       map[Cursor]string // CSS cursor property values
+	lastCursorKey Cursor
 
 	// Events
 	keyEv    KeyEvent
@@ -328,6 +339,7 @@ type WebGlCanvas struct {
 	cursorEv CursorEvent
 	scrollEv ScrollEvent
 	focusEv  FocusEvent
+	compEv   CompositionEvent
 
 	// Callbacks
 	onCtxMenu  js.Func
@@ -340,6 +352,11 @@ type WebGlCanvas struct {
 	winResize  js.Func
 	winFocus   js.Func
 	winBlur    js.Func
+	compUpdate js.Func
+	compEnd    js.Func
+	textInput  js.Func
+	ctxLost    js.Func
+	ctxRestore js.Func
 }
 
 // Init initializes the WebGlCanvas singleton.
@@ -356,6 +373,19 @@ func Init(canvasId string) error {
 	w := new(WebGlCanvas)
 	w.Dispatcher.Initialize()
 
+	// Initialize standard cursors
+	w.cursors = map[Cursor]string{
+		ArrowCursor:       "default",
+		IBeamCursor:       "text",
+		CrosshairCursor:   "crosshair",
+		HandCursor:        "pointer",
+		HResizeCursor:     "ew-resize",
+		VResizeCursor:     "ns-resize",
+		DiagResize1Cursor: "nesw-resize",
+		DiagResize2Cursor: "nwse-resize",
+	}
+	w.lastCursorKey = CursorLast
+
 	// Create or get WebGlCanvas
 	doc := js.Global().Get("document")
 	if canvasId == "" {
@@ -384,11 +414,30 @@ func Init(canvasId string) error {
 	w.onCtxMenu = js.FuncOf(func(this js.Value, args []js.Value) interface{} { return false })
 	w.canvas.Set("oncontextmenu", w.onCtxMenu)
 
+	// Set up WebGL context loss callbacks. The default handling is prevented so the context
+	// can be restored, after which the WebGL state is reset so the resources are recreated.
+	w.ctxLost = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		args[0].Call("preventDefault")
+		w.Dispatch(OnContextLost, nil)
+		return nil
+	})
+	w.ctxRestore = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		w.gls.Reset()
+		w.Dispatch(OnContextReset, nil)
+		return nil
+	})
+	w.canvas.Call("addEventListener", "webglcontextlost", w.ctxLost)
+	w.canvas.Call("addEventListener", "webglcontextrestored", w.ctxRestore)
+
 	// TODO scaling/hidpi (device pixel ratio)
 
 	// Set up key down callback to dispatch event
 	w.keyDown = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		event := args[0]
+		// The keys pressed while composing text are handled by the IME
+		if event.Get("isComposing").Bool() {
+			return nil
+		}
 		eventCode := event.Get("code").String()
 		w.keyEv.Key = Key(keyMap[eventCode])
 		w.keyEv.Mods = getModifiers(event)
@@ -435,8 +484,14 @@ func Init(canvasId string) error {
 	// Set up mouse move callback to dispatch event
 	w.mouseMove = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		event := args[0]
-		w.cursorEv.Xpos = float32(event.Get("offsetX").Float()) //* float32(w.scaleX) TODO
-		w.cursorEv.Ypos = float32(event.Get("offsetY").Float()) //* float32(w.scaleY)
+		if w.MouseCaptured() {
+			// The pointer is locked so only its movement is reported
+			w.cursorEv.Xpos += float32(event.Get("movementX").Float())
+			w.cursorEv.Ypos += float32(event.Get("movementY").Float())
+		} else {
+			w.cursorEv.Xpos = float32(event.Get("offsetX").Float()) //* float32(w.scaleX) TODO
+			w.cursorEv.Ypos = float32(event.Get("offsetY").Float()) //* float32(w.scaleY)
+		}
 		w.cursorEv.Mods = getModifiers(event)
 		w.Dispatch(OnCursor, &w.cursorEv)
 		return nil
@@ -482,12 +537,61 @@ func Init(canvasId string) error {
 	js.Global().Get("window").Call("addEventListener", "onfocus", w.winFocus)
 	js.Global().Get("window").Call("addEventListener", "onblur", w.winBlur)
 
-	//// Set up char callback to dispatch event TODO
-	//w.SetCharModsCallback(func(x *glfw.Window, char rune, mods glfw.ModifierKey) {	//
-	//	w.charEv.Char = char
-	//	w.charEv.Mods = ModifierKey(mods)
-	//	w.Dispatch(OnChar, &w.charEv)
-	//})
+	// Set up IME composition callbacks to dispatch events
+	w.compUpdate = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		w.compEv.Text = args[0].Get("data").String()
+		w.compEv.Active = true
+		w.Dispatch(OnComposition, &w.compEv)
+		return nil
+	})
+	w.compEnd = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		w.compEv.Text = args[0].Get("data").String()
+		w.compEv.Active = false
+		w.Dispatch(OnComposition, &w.compEv)
+		// The committed text is also dispatched as chars
+		for _, char := range w.compEv.Text {
+			w.charEv.Char = char
+			w.charEv.Mods = 0
+			w.Dispatch(OnChar, &w.charEv)
+		}
+		w.textArea.Set("value", "")
+		return nil
+	})
+	js.Global().Call("addEventListener", "compositionstart", w.compUpdate)
+	js.Global().Call("addEventListener", "compositionupdate", w.compUpdate)
+	js.Global().Call("addEventListener", "compositionend", w.compEnd)
+
+	// Set up the hidden text area which has the focus while the text input is active,
+	// as the IME only composes text in editable elements, and dispatches the text
+	// typed without composition as chars
+	w.textArea = doc.Call("createElement", "textarea")
+	style := w.textArea.Get("style")
+	style.Set("position", "fixed")
+	style.Set("opacity", "0")
+	style.Set("pointerEvents", "none")
+	style.Set("resize", "none")
+	style.Set("border", "0")
+	style.Set("padding", "0")
+	style.Set("overflow", "hidden")
+	doc.Get("body").Call("appendChild", w.textArea)
+	w.textInput = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		event := args[0]
+		inputType := event.Get("inputType").String()
+		if event.Get("isComposing").Bool() || inputType == "insertCompositionText" || inputType == "insertFromComposition" {
+			return nil
+		}
+		data := event.Get("data")
+		if !wasm.Equal(data, js.Null()) && !wasm.Equal(data, js.Undefined()) {
+			for _, char := range data.String() {
+				w.charEv.Char = char
+				w.charEv.Mods = 0
+				w.Dispatch(OnChar, &w.charEv)
+			}
+		}
+		w.textArea.Set("value", "")
+		return nil
+	})
+	w.textArea.Call("addEventListener", "input", w.textInput)
 
 	win = w // Set singleton
 	return nil
@@ -542,6 +646,38 @@ func (w *WebGlCanvas) SetFullScreen(full bool) {
 	// Make it so that the first user interaction (e.g. click) should set the canvas as fullscreen.
 }
 
+// SetFullScreenMode sets this canvas full screen. The browser doesn't allow
+// choosing the monitor or the video mode, so they are ignored.
+func (w *WebGlCanvas) SetFullScreenMode(mon *Monitor, mode *VideoMode) {
+
+	w.SetFullScreen(true)
+}
+
+// SetBorderlessFullScreen sets this canvas full screen. The browser doesn't allow
+// choosing the monitor, so it is ignored.
+func (w *WebGlCanvas) SetBorderlessFullScreen(mon *Monitor) {
+
+	w.SetFullScreen(true)
+}
+
+// Monitors returns the screen which contains the browser window,
+// as the browser doesn't allow enumerating the monitors.
+func (w *WebGlCanvas) Monitors() []*Monitor {
+
+	screen := js.Global().Get("screen")
+	mon := new(Monitor)
+	mon.Name = "Screen"
+	mon.Primary = true
+	mon.WorkWidth = screen.Get("availWidth").Int()
+	mon.WorkHeight = screen.Get("availHeight").Int()
+	mon.ScaleX = js.Global().Get("devicePixelRatio").Float()
+	mon.ScaleY = mon.ScaleX
+	mon.Mode.Width = screen.Get("width").Int()
+	mon.Mode.Height = screen.Get("height").Int()
+	mon.Modes = []VideoMode{mon.Mode}
+	return []*Monitor{mon}
+}
+
 // Destroy destroys the WebGL canvas and removes all event listeners.
 func (w *WebGlCanvas) Destroy() {
 
@@ -556,6 +692,13 @@ func (w *WebGlCanvas) Destroy() {
 	js.Global().Get("window").Call("removeEventListener", "resize", w.winResize)
 	js.Global().Get("window").Call("removeEventListener", "onfocus", w.winFocus)
 	js.Global().Get("window").Call("removeEventListener", "onfocus", w.winBlur)
+	js.Global().Call("removeEventListener", "compositionstart", w.compUpdate)
+	js.Global().Call("removeEventListener", "compositionupdate", w.compUpdate)
+	js.Global().Call("removeEventListener", "compositionend", w.compEnd)
+	w.textArea.Call("removeEventListener", "input", w.textInput)
+	w.textArea.Call("remove")
+	w.canvas.Call("removeEventListener", "webglcontextlost", w.ctxLost)
+	w.canvas.Call("removeEventListener", "webglcontextrestored", w.ctxRestore)
 
 	// Release callbacks
 	w.onCtxMenu.Release()
@@ -568,6 +711,11 @@ func (w *WebGlCanvas) Destroy() {
 	w.winResize.Release()
 	w.winFocus.Release()
 	w.winBlur.Release()
+	w.compUpdate.Release()
+	w.compEnd.Release()
+	w.textInput.Release()
+	w.ctxLost.Release()
+	w.ctxRestore.Release()
 }
 
 // GetFramebufferSize returns the framebuffer size.
@@ -597,23 +745,136 @@ func (w *WebGlCanvas) GetScale() (x float64, y float64) {
 	return 1, 1
 }
 
-// CreateCursor creates a new custom cursor and returns an int handle.
+// GetClipboardString returns the last contents set in the clipboard by this canvas,
+// as the browser only allows reading the clipboard asynchronously.
+func (w *WebGlCanvas) GetClipboardString() string {
+
+	return w.clipboard
+}
+
+// SetClipboardString sets the contents of the clipboard if the browser allows it.
+func (w *WebGlCanvas) SetClipboardString(str string) {
+
+	w.clipboard = str
+	clipboard := js.Global().Get("navigator").Get("clipboard")
+	if wasm.Equal(clipboard, js.Undefined()) {
+		return
+	}
+	clipboard.Call("writeText", str)
+}
+
+// SetTextInput enables or disables the text input with an input method editor (IME) and sets
+// the rectangle, in canvas coordinates, of the edited text, near which the browser shows the
+// IME candidate window. While enabled a hidden text area has the focus, and the composed
+// text is dispatched as OnComposition events and the typed characters as OnChar events.
+func (w *WebGlCanvas) SetTextInput(active bool, x, y, width, height float32) {
+
+	if !active {
+		w.textArea.Call("blur")
+		return
+	}
+	rect := w.canvas.Call("getBoundingClientRect")
+	style := w.textArea.Get("style")
+	style.Set("left", fmt.Sprintf("%.0fpx", rect.Get("left").Float()+float64(x)))
+	style.Set("top", fmt.Sprintf("%.0fpx", rect.Get("top").Float()+float64(y)))
+	style.Set("width", fmt.Sprintf("%.0fpx", width))
+	style.Set("height", fmt.Sprintf("%.0fpx", height))
+	style.Set("fontSize", fmt.Sprintf("%.0fpx", height))
+	if !wasm.Equal(js.Global().Get("document").Get("activeElement"), w.textArea) {
+		w.textArea.Call("focus", map[string]interface{}{"preventScroll": true})
+	}
+}
+
+// CaptureMouse requests or exits the pointer lock of the canvas. While locked the cursor is
+// hidden and the cursor events report unbounded positions, as needed by first person camera
+// controls. Browsers only grant the pointer lock when requested from a user input event handler
+// and release it when the user presses Escape.
+func (w *WebGlCanvas) CaptureMouse(capture bool) {
+
+	if capture {
+		w.canvas.Call("requestPointerLock")
+	} else if w.MouseCaptured() {
+		js.Global().Get("document").Call("exitPointerLock")
+	}
+}
+
+// MouseCaptured returns whether the canvas has the pointer lock.
+func (w *WebGlCanvas) MouseCaptured() bool {
+
+	return wasm.Equal(js.Global().Get("document").Get("pointerLockElement"), w.canvas)
+}
+
+// CreateCursor creates a new custom cursor from the specified image file
+// and returns an int handle.
 func (w *WebGlCanvas) CreateCursor(imgFile string, xhot, yhot int) (Cursor, error) {
 
-	// TODO
-	return 0, nil
+	// Open image file
+	file, err := os.Open(imgFile)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	// Decode image
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return 0, err
+	}
+	return w.CreateCursorFromImage(img, xhot, yhot)
 }
 
-// SetCursor sets the window's cursor to a standard one
+// CreateCursorFromImage creates a new custom cursor from the specified image
+// with the hotspot at the specified pixel coordinates from the top left corner
+// of the image and returns an int handle.
+func (w *WebGlCanvas) CreateCursorFromImage(img image.Image, xhot, yhot int) (Cursor, error) {
+
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return 0, fmt.Errorf("empty cursor image")
+	}
+	if xhot < 0 || yhot < 0 || xhot >= bounds.Dx() || yhot >= bounds.Dy() {
+		return 0, fmt.Errorf("cursor hotspot (%d,%d) outside of image", xhot, yhot)
+	}
+	// Encodes the image as a PNG data URL
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		return 0, err
+	}
+	url := "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+	w.lastCursorKey++
+	w.cursors[w.lastCursorKey] = fmt.Sprintf("url(%s) %d %d, auto", url, xhot, yhot)
+	return w.lastCursorKey, nil
+}
+
+// SetCursor sets the window's cursor to a standard cursor
+// or to a custom cursor created by CreateCursor or CreateCursorFromImage.
 func (w *WebGlCanvas) SetCursor(cursor Cursor) {
 
-	// TODO
+	css, ok := w.cursors[cursor]
+	if !ok {
+		panic("Invalid cursor")
+	}
+	w.canvas.Get("style").Set("cursor", css)
+}
+
+// DisposeCursor deletes the existing custom cursor with the provided int handle.
+func (w *WebGlCanvas) DisposeCursor(cursor Cursor) {
+
+	if cursor <= CursorLast {
+		panic("Can't dispose standard cursor")
+	}
+	delete(w.cursors, cursor)
 }
 
 // DisposeAllCursors deletes all existing custom cursors.
 func (w *WebGlCanvas) DisposeAllCustomCursors() {
 
-	// TODO
+	for key := range w.cursors {
+		if key > CursorLast {
+			delete(w.cursors, key)
+		}
+	}
+	w.lastCursorKey = CursorLast
 }
 
 // SetInputMode changes specified input to specified state
@@ -2,8 +2,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-//go:build !wasm
-// +build !wasm
+//go:build !wasm && !soft
+// +build !wasm,!soft
 
 package window
 
@@ -176,6 +176,7 @@ const (
 	CursorInputMode             = InputMode(glfw.CursorMode)             // See Cursor mode values
 	StickyKeysInputMode         = InputMode(glfw.StickyKeysMode)         // Value can be either 1 or 0
 	StickyMouseButtonsInputMode = InputMode(glfw.StickyMouseButtonsMode) // Value can be either 1 or 0
+	RawMouseMotionInputMode     = InputMode(glfw.RawMouseMotion)         // Value can be either 1 or 0
 )
 
 // Cursor mode values
@@ -191,6 +192,8 @@ type GlfwWindow struct {
 	core.Dispatcher          // Embedded event dispatcher
 	gls             *gls.GLS // Associated OpenGL State
 	fullscreen      bool
+	borderless      bool
+	decorated       bool
 	lastX           int
 	lastY           int
 	lastWidth       int
@@ -207,6 +210,9 @@ type GlfwWindow struct {
 	cursorEv CursorEvent
 	scrollEv ScrollEvent
 	focusEv  FocusEvent
+	dropEv   DropEvent
+	scaleEv  ScaleEvent
+	monEv    MonitorEvent
 
 	mods ModifierKey // Current modifier keys
 
@@ -215,6 +221,15 @@ type GlfwWindow struct {
 	lastCursorKey Cursor
 }
 
+// glfwBool returns the GLFW value of the specified boolean.
+func glfwBool(v bool) int {
+
+	if v {
+		return glfw.True
+	}
+	return glfw.False
+}
+
 func mini(x, y int) int {
 	if x < y {
 		return x
@@ -232,6 +247,14 @@ func maxi(x, y int) int {
 // Init initializes the GlfwWindow singleton with the specified width, height, and title.
 func Init(width, height int, title string) error {
 
+	return InitWithOptions(&Options{Width: width, Height: height, Title: title})
+}
+
+// InitWithOptions initializes the GlfwWindow singleton with the specified options.
+// A transparent window shows the desktop where the alpha of the framebuffer is less than one,
+// as where it is cleared with a transparent color, if supported by the window system.
+func InitWithOptions(opts *Options) error {
+
 	// Panic if already created
 	if win != nil {
 		panic(fmt.Errorf("can only call window.Init() once"))
@@ -264,15 +287,26 @@ func Init(width, height int, title string) error {
 	if runtime.GOOS == "darwin" {
 		glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
 	}
+	glfw.WindowHint(glfw.Decorated, glfwBool(!opts.Undecorated))
+	glfw.WindowHint(glfw.Floating, glfwBool(opts.Floating))
+	glfw.WindowHint(glfw.TransparentFramebuffer, glfwBool(opts.Transparent))
+	glfw.WindowHint(glfw.Maximized, glfwBool(opts.Maximized))
+	// GLFW has no position hint, so the window is created hidden and shown after it is positioned
+	glfw.WindowHint(glfw.Visible, glfwBool(!opts.Positioned))
+	w.decorated = !opts.Undecorated
 
 	// Create window and set it as the current context.
 	// The window is created always as not full screen because if it is
 	// created as full screen it not possible to revert it to windowed mode.
 	// At the end of this function, the window will be set to full screen if requested.
-	w.Window, err = glfw.CreateWindow(width, height, title, nil, nil)
+	w.Window, err = glfw.CreateWindow(opts.Width, opts.Height, opts.Title, nil, nil)
 	if err != nil {
 		return err
 	}
+	if opts.Positioned {
+		w.SetPos(opts.X, opts.Y)
+		w.Show()
+	}
 	w.MakeContextCurrent()
 
 	// Create OpenGL state
@@ -282,6 +316,7 @@ func Init(width, height int, title string) error {
 	}
 
 	// Compute and store scale
+	width, height := w.GetSize()
 	fbw, fbh := w.GetFramebufferSize()
 	w.scaleX = float64(fbw) / float64(width)
 	w.scaleY = float64(fbh) / float64(height)
@@ -346,14 +381,24 @@ func Init(width, height int, title string) error {
 
 	// Set up window size callback to dispatch event
 	w.SetSizeCallback(func(x *glfw.Window, width int, height int) {
-		fbw, fbh := x.GetFramebufferSize()
+		if w.updateScale() {
+			return
+		}
 		w.sizeEv.Width = width
 		w.sizeEv.Height = height
-		w.scaleX = float64(fbw) / float64(width)
-		w.scaleY = float64(fbh) / float64(height)
 		w.Dispatch(OnWindowSize, &w.sizeEv)
 	})
 
+	// Set up framebuffer size and content scale callbacks to update the scale
+	// when it changes without a change of the window size, as when the window
+	// is moved to a monitor with another scale on Wayland and macOS.
+	w.SetFramebufferSizeCallback(func(x *glfw.Window, width int, height int) {
+		w.updateScale()
+	})
+	w.SetContentScaleCallback(func(x *glfw.Window, xscale float32, yscale float32) {
+		w.updateScale()
+	})
+
 	// Set up window position callback to dispatch event
 	w.SetPosCallback(func(x *glfw.Window, xpos int, ypos int) {
 		w.posEv.Xpos = xpos
@@ -383,6 +428,33 @@ func Init(width, height int, title string) error {
 		w.Dispatch(OnScroll, &w.scrollEv)
 	})
 
+	// Set up file drop callback to dispatch event
+	w.SetDropCallback(func(x *glfw.Window, names []string) {
+		xpos, ypos := x.GetCursorPos()
+		w.dropEv.Xpos = float32(xpos)
+		w.dropEv.Ypos = float32(ypos)
+		w.dropEv.Paths = names
+		w.dropEv.Mods = w.mods
+		w.Dispatch(OnDrop, &w.dropEv)
+	})
+
+	// Set up monitor callback to dispatch event.
+	// GLFW switches the windows which are fullscreen on a disconnected monitor to windowed mode.
+	glfw.SetMonitorCallback(func(mon *glfw.Monitor, event glfw.PeripheralEvent) {
+		if event == glfw.Connected {
+			w.monEv.Monitor = newMonitor(mon, glfw.GetPrimaryMonitor())
+			w.monEv.Connected = true
+		} else {
+			// Only the name of a disconnected monitor is available
+			w.monEv.Monitor = &Monitor{Name: mon.GetName(), handle: mon}
+			w.monEv.Connected = false
+			if w.fullscreen && !w.borderless && w.Window.GetMonitor() == nil {
+				w.fullscreen = false
+			}
+		}
+		w.Dispatch(OnMonitor, &w.monEv)
+	})
+
 	win = w // Set singleton
 	return nil
 }
@@ -399,34 +471,198 @@ func (w *GlfwWindow) FullScreen() bool {
 	return w.fullscreen
 }
 
-// SetFullScreen sets this window as fullscreen on the primary monitor
-// TODO allow for fullscreen with resolutions different than the monitor's
+// SetFullScreen sets this window as fullscreen on the monitor which contains it,
+// with the current video mode of the monitor, or restores it to windowed mode.
 func (w *GlfwWindow) SetFullScreen(full bool) {
 
 	// If already in the desired state, nothing to do
 	if w.fullscreen == full {
 		return
 	}
-	// Set window fullscreen on the primary monitor
 	if full {
-		// Save current position and size of the window
-		w.lastX, w.lastY = w.GetPos()
-		w.lastWidth, w.lastHeight = w.GetSize()
-		// Get size of primary monitor
-		mon := w.GetMonitor()
-		vmode := mon.GetVideoMode()
-		width := vmode.Width
-		height := vmode.Height
-		// Set as fullscreen on the primary monitor
-		w.SetMonitor(mon, 0, 0, width, height, vmode.RefreshRate)
-		w.fullscreen = true
+		w.SetFullScreenMode(nil, nil)
+		return
+	}
+	// Restore window to previous position, size and decorations
+	if w.borderless {
+		w.SetAttrib(glfw.Decorated, glfwBool(w.decorated))
+		w.borderless = false
+	}
+	w.SetMonitor(nil, w.lastX, w.lastY, w.lastWidth, w.lastHeight, glfw.DontCare)
+	w.fullscreen = false
+}
+
+// SetFullScreenMode sets this window as fullscreen on the specified monitor with the specified
+// video mode. If the monitor is nil the monitor which contains the window is used and
+// if the video mode is nil the current video mode of the monitor is kept.
+func (w *GlfwWindow) SetFullScreenMode(mon *Monitor, mode *VideoMode) {
+
+	gmon := w.glfwMonitor(mon)
+	w.saveWindowed()
+	if w.borderless {
+		w.SetAttrib(glfw.Decorated, glfwBool(w.decorated))
+		w.borderless = false
+	}
+	var width, height, rate int
+	if mode != nil {
+		width, height, rate = mode.Width, mode.Height, mode.RefreshRate
+	} else {
+		vmode := gmon.GetVideoMode()
+		width, height, rate = vmode.Width, vmode.Height, vmode.RefreshRate
+	}
+	w.SetMonitor(gmon, 0, 0, width, height, rate)
+	w.fullscreen = true
+}
+
+// SetBorderlessFullScreen sets this window as an undecorated window which covers the specified
+// monitor, or the monitor which contains the window if nil, without changing its video mode.
+// SetFullScreen(false) restores the window.
+func (w *GlfwWindow) SetBorderlessFullScreen(mon *Monitor) {
+
+	gmon := w.glfwMonitor(mon)
+	w.saveWindowed()
+	mx, my := gmon.GetPos()
+	vmode := gmon.GetVideoMode()
+	w.SetAttrib(glfw.Decorated, glfw.False)
+	w.SetMonitor(nil, mx, my, vmode.Width, vmode.Height, glfw.DontCare)
+	w.fullscreen = true
+	w.borderless = true
+}
+
+// SetDecorated sets whether this window has the border, title bar and buttons of the window manager.
+// Undecorated windows are used by applications which draw their own title bar.
+func (w *GlfwWindow) SetDecorated(decorated bool) {
+
+	w.decorated = decorated
+	if !w.borderless {
+		w.SetAttrib(glfw.Decorated, glfwBool(decorated))
+	}
+}
+
+// Decorated returns whether this window has the decorations of the window manager when it is not fullscreen.
+func (w *GlfwWindow) Decorated() bool {
+
+	return w.decorated
+}
+
+// SetFloating sets whether this window is kept above the other windows (always on top).
+func (w *GlfwWindow) SetFloating(floating bool) {
+
+	w.SetAttrib(glfw.Floating, glfwBool(floating))
+}
+
+// Floating returns whether this window is kept above the other windows.
+func (w *GlfwWindow) Floating() bool {
+
+	return w.GetAttrib(glfw.Floating) == glfw.True
+}
+
+// Transparent returns whether the framebuffer of this window is composited with the desktop.
+func (w *GlfwWindow) Transparent() bool {
+
+	return w.GetAttrib(glfw.TransparentFramebuffer) == glfw.True
+}
+
+// saveWindowed saves the position and size of this window if it is in windowed mode,
+// so they are restored when it leaves fullscreen mode.
+func (w *GlfwWindow) saveWindowed() {
+
+	if w.fullscreen {
+		return
+	}
+	w.lastX, w.lastY = w.GetPos()
+	w.lastWidth, w.lastHeight = w.GetSize()
+}
+
+// glfwMonitor returns the GLFW monitor of the specified monitor
+// or the monitor which contains the window if nil.
+func (w *GlfwWindow) glfwMonitor(mon *Monitor) *glfw.Monitor {
+
+	if mon == nil || mon.handle == nil {
+		return w.GetMonitor()
+	}
+	return mon.handle.(*glfw.Monitor)
+}
+
+// Monitors returns the monitors connected to the system
+func (w *GlfwWindow) Monitors() []*Monitor {
+
+	primary := glfw.GetPrimaryMonitor()
+	monitors := make([]*Monitor, 0)
+	for _, mon := range glfw.GetMonitors() {
+		monitors = append(monitors, newMonitor(mon, primary))
+	}
+	return monitors
+}
+
+// newMonitor creates and returns a pointer to a new Monitor which describes the specified GLFW monitor
+func newMonitor(mon, primary *glfw.Monitor) *Monitor {
+
+	m := new(Monitor)
+	m.handle = mon
+	m.Name = mon.GetName()
+	m.Primary = primary != nil && *mon == *primary
+	m.X, m.Y = mon.GetPos()
+	m.WorkX, m.WorkY, m.WorkWidth, m.WorkHeight = mon.GetWorkarea()
+	sx, sy := mon.GetContentScale()
+	m.ScaleX, m.ScaleY = float64(sx), float64(sy)
+	m.WidthMM, m.HeightMM = mon.GetPhysicalSize()
+	if vmode := mon.GetVideoMode(); vmode != nil {
+		m.Mode = videoMode(vmode)
+	}
+	for _, vmode := range mon.GetVideoModes() {
+		m.Modes = append(m.Modes, videoMode(vmode))
+	}
+	return m
+}
+
+// videoMode converts a GLFW video mode to a VideoMode
+func videoMode(vmode *glfw.VidMode) VideoMode {
+
+	return VideoMode{
+		Width:       vmode.Width,
+		Height:      vmode.Height,
+		RefreshRate: vmode.RefreshRate,
+		RedBits:     vmode.RedBits,
+		GreenBits:   vmode.GreenBits,
+		BlueBits:    vmode.BlueBits,
+	}
+}
+
+// CaptureMouse captures or releases the mouse. While captured the cursor is hidden and
+// locked to the window, which is centered on it, and the cursor events report unbounded
+// positions, as needed by first person camera controls. Raw mouse motion, without the
+// acceleration applied by the operating system, is used when supported.
+func (w *GlfwWindow) CaptureMouse(capture bool) {
+
+	if capture {
+		w.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+		if glfw.RawMouseMotionSupported() {
+			w.SetInputMode(glfw.RawMouseMotion, glfw.True)
+		}
+		width, height := w.GetSize()
+		w.SetCursorPos(float64(width)/2, float64(height)/2)
 	} else {
-		// Restore window to previous position and size
-		w.SetMonitor(nil, w.lastX, w.lastY, w.lastWidth, w.lastHeight, glfw.DontCare)
-		w.fullscreen = false
+		if glfw.RawMouseMotionSupported() {
+			w.SetInputMode(glfw.RawMouseMotion, glfw.False)
+		}
+		w.SetInputMode(glfw.CursorMode, glfw.CursorNormal)
 	}
 }
 
+// MouseCaptured returns whether the mouse is captured.
+func (w *GlfwWindow) MouseCaptured() bool {
+
+	return w.GetInputMode(glfw.CursorMode) == glfw.CursorDisabled
+}
+
+// SetTextInput enables or disables the text input with an input method editor (IME) and sets
+// the rectangle of the edited text. GLFW 3.3 has no IME composition (preedit) interface:
+// the system IME composes the text in its own window and the committed characters are
+// dispatched as OnChar events, so it does nothing.
+func (w *GlfwWindow) SetTextInput(active bool, x, y, width, height float32) {
+}
+
 // Destroy destroys this window and its context
 func (w *GlfwWindow) Destroy() {
 
@@ -441,6 +677,32 @@ func (w *GlfwWindow) GetScale() (x float64, y float64) {
 	return w.scaleX, w.scaleY
 }
 
+// updateScale recomputes the DPI scale factor from the framebuffer and window sizes and,
+// if it changed, dispatches OnContentScale followed by OnWindowSize and returns true.
+func (w *GlfwWindow) updateScale() bool {
+
+	width, height := w.GetSize()
+	if width == 0 || height == 0 {
+		// Minimized
+		return false
+	}
+	fbw, fbh := w.GetFramebufferSize()
+	scaleX := float64(fbw) / float64(width)
+	scaleY := float64(fbh) / float64(height)
+	if scaleX == w.scaleX && scaleY == w.scaleY {
+		return false
+	}
+	w.scaleX = scaleX
+	w.scaleY = scaleY
+	w.scaleEv.X = scaleX
+	w.scaleEv.Y = scaleY
+	w.Dispatch(OnContentScale, &w.scaleEv)
+	w.sizeEv.Width = width
+	w.sizeEv.Height = height
+	w.Dispatch(OnWindowSize, &w.sizeEv)
+	return true
+}
+
 // GetMonitor returns the window's best-guessed monitor (by max area).
 // Implemented to allow putting the window in fullscreen mode
 // on the same monitor that contains the window at the moment.
@@ -491,7 +753,8 @@ func (w *GlfwWindow) SetSwapInterval(interval int) {
 	glfw.SwapInterval(interval)
 }
 
-// SetCursor sets the window's cursor.
+// SetCursor sets the window's cursor to a standard cursor
+// or to a custom cursor created by CreateCursor or CreateCursorFromImage.
 func (w *GlfwWindow) SetCursor(cursor Cursor) {
 
 	cur, ok := w.cursors[cursor]
@@ -501,7 +764,8 @@ func (w *GlfwWindow) SetCursor(cursor Cursor) {
 	w.Window.SetCursor(cur)
 }
 
-// CreateCursor creates a new custom cursor and returns an int handle.
+// CreateCursor creates a new custom cursor from the specified image file
+// and returns an int handle.
 func (w *GlfwWindow) CreateCursor(imgFile string, xhot, yhot int) (Cursor, error) {
 
 	// Open image file
@@ -515,9 +779,28 @@ func (w *GlfwWindow) CreateCursor(imgFile string, xhot, yhot int) (Cursor, error
 	if err != nil {
 		return 0, err
 	}
-	// Create and store cursor
+	return w.CreateCursorFromImage(img, xhot, yhot)
+}
+
+// CreateCursorFromImage creates a new custom cursor from the specified image
+// with the hotspot at the specified pixel coordinates from the top left corner
+// of the image and returns an int handle.
+func (w *GlfwWindow) CreateCursorFromImage(img image.Image, xhot, yhot int) (Cursor, error) {
+
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return 0, fmt.Errorf("empty cursor image")
+	}
+	if xhot < 0 || yhot < 0 || xhot >= bounds.Dx() || yhot >= bounds.Dy() {
+		return 0, fmt.Errorf("cursor hotspot (%d,%d) outside of image", xhot, yhot)
+	}
+	cur := glfw.CreateCursor(img, xhot, yhot)
+	if cur == nil {
+		return 0, fmt.Errorf("error creating cursor")
+	}
+	// Store cursor
 	w.lastCursorKey += 1
-	w.cursors[Cursor(w.lastCursorKey)] = glfw.CreateCursor(img, xhot, yhot)
+	w.cursors[Cursor(w.lastCursorKey)] = cur
 
 	return w.lastCursorKey, nil
 }
@@ -528,7 +811,11 @@ func (w *GlfwWindow) DisposeCursor(cursor Cursor) {
 	if cursor <= CursorLast {
 		panic("Can't dispose standard cursor")
 	}
-	w.cursors[cursor].Destroy()
+	cur, ok := w.cursors[cursor]
+	if !ok {
+		return
+	}
+	cur.Destroy()
 	delete(w.cursors, cursor)
 }
 
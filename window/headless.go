@@ -0,0 +1,500 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build soft && !wasm
+// +build soft,!wasm
+
+package window
+
+import (
+	"fmt"
+	"image"
+	_ "image/png"
+	"os"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+)
+
+// Keycodes
+const (
+	KeyUnknown      = Key(-1)
+	KeySpace        = Key(32)
+	KeyApostrophe   = Key(39)
+	KeyComma        = Key(44)
+	KeyMinus        = Key(45)
+	KeyPeriod       = Key(46)
+	KeySlash        = Key(47)
+	Key0            = Key(48)
+	Key1            = Key(49)
+	Key2            = Key(50)
+	Key3            = Key(51)
+	Key4            = Key(52)
+	Key5            = Key(53)
+	Key6            = Key(54)
+	Key7            = Key(55)
+	Key8            = Key(56)
+	Key9            = Key(57)
+	KeySemicolon    = Key(59)
+	KeyEqual        = Key(61)
+	KeyA            = Key(65)
+	KeyB            = Key(66)
+	KeyC            = Key(67)
+	KeyD            = Key(68)
+	KeyE            = Key(69)
+	KeyF            = Key(70)
+	KeyG            = Key(71)
+	KeyH            = Key(72)
+	KeyI            = Key(73)
+	KeyJ            = Key(74)
+	KeyK            = Key(75)
+	KeyL            = Key(76)
+	KeyM            = Key(77)
+	KeyN            = Key(78)
+	KeyO            = Key(79)
+	KeyP            = Key(80)
+	KeyQ            = Key(81)
+	KeyR            = Key(82)
+	KeyS            = Key(83)
+	KeyT            = Key(84)
+	KeyU            = Key(85)
+	KeyV            = Key(86)
+	KeyW            = Key(87)
+	KeyX            = Key(88)
+	KeyY            = Key(89)
+	KeyZ            = Key(90)
+	KeyLeftBracket  = Key(91)
+	KeyBackslash    = Key(92)
+	KeyRightBracket = Key(93)
+	KeyGraveAccent  = Key(96)
+	KeyWorld1       = Key(161)
+	KeyWorld2       = Key(162)
+	KeyEscape       = Key(256)
+	KeyEnter        = Key(257)
+	KeyTab          = Key(258)
+	KeyBackspace    = Key(259)
+	KeyInsert       = Key(260)
+	KeyDelete       = Key(261)
+	KeyRight        = Key(262)
+	KeyLeft         = Key(263)
+	KeyDown         = Key(264)
+	KeyUp           = Key(265)
+	KeyPageUp       = Key(266)
+	KeyPageDown     = Key(267)
+	KeyHome         = Key(268)
+	KeyEnd          = Key(269)
+	KeyCapsLock     = Key(280)
+	KeyScrollLock   = Key(281)
+	KeyNumLock      = Key(282)
+	KeyPrintScreen  = Key(283)
+	KeyPause        = Key(284)
+	KeyF1           = Key(290)
+	KeyF2           = Key(291)
+	KeyF3           = Key(292)
+	KeyF4           = Key(293)
+	KeyF5           = Key(294)
+	KeyF6           = Key(295)
+	KeyF7           = Key(296)
+	KeyF8           = Key(297)
+	KeyF9           = Key(298)
+	KeyF10          = Key(299)
+	KeyF11          = Key(300)
+	KeyF12          = Key(301)
+	KeyF13          = Key(302)
+	KeyF14          = Key(303)
+	KeyF15          = Key(304)
+	KeyF16          = Key(305)
+	KeyF17          = Key(306)
+	KeyF18          = Key(307)
+	KeyF19          = Key(308)
+	KeyF20          = Key(309)
+	KeyF21          = Key(310)
+	KeyF22          = Key(311)
+	KeyF23          = Key(312)
+	KeyF24          = Key(313)
+	KeyF25          = Key(314)
+	KeyKP0          = Key(320)
+	KeyKP1          = Key(321)
+	KeyKP2          = Key(322)
+	KeyKP3          = Key(323)
+	KeyKP4          = Key(324)
+	KeyKP5          = Key(325)
+	KeyKP6          = Key(326)
+	KeyKP7          = Key(327)
+	KeyKP8          = Key(328)
+	KeyKP9          = Key(329)
+	KeyKPDecimal    = Key(330)
+	KeyKPDivide     = Key(331)
+	KeyKPMultiply   = Key(332)
+	KeyKPSubtract   = Key(333)
+	KeyKPAdd        = Key(334)
+	KeyKPEnter      = Key(335)
+	KeyKPEqual      = Key(336)
+	KeyLeftShift    = Key(340)
+	KeyLeftControl  = Key(341)
+	KeyLeftAlt      = Key(342)
+	KeyLeftSuper    = Key(343)
+	KeyRightShift   = Key(344)
+	KeyRightControl = Key(345)
+	KeyRightAlt     = Key(346)
+	KeyRightSuper   = Key(347)
+	KeyMenu         = Key(348)
+	KeyLast         = Key(348)
+)
+
+// Modifier keys
+const (
+	ModShift   = ModifierKey(0x0001)
+	ModControl = ModifierKey(0x0002)
+	ModAlt     = ModifierKey(0x0004)
+	ModSuper   = ModifierKey(0x0008)
+)
+
+// Mouse buttons
+const (
+	MouseButton1      = MouseButton(0)
+	MouseButton2      = MouseButton(1)
+	MouseButton3      = MouseButton(2)
+	MouseButton4      = MouseButton(3)
+	MouseButton5      = MouseButton(4)
+	MouseButton6      = MouseButton(5)
+	MouseButton7      = MouseButton(6)
+	MouseButton8      = MouseButton(7)
+	MouseButtonLast   = MouseButton(7)
+	MouseButtonLeft   = MouseButton(0)
+	MouseButtonRight  = MouseButton(1)
+	MouseButtonMiddle = MouseButton(2)
+)
+
+// Input modes
+const (
+	CursorInputMode             = InputMode(0x00033001) // See Cursor mode values
+	StickyKeysInputMode         = InputMode(0x00033002) // Value can be either 1 or 0
+	StickyMouseButtonsInputMode = InputMode(0x00033003) // Value can be either 1 or 0
+	RawMouseMotionInputMode     = InputMode(0x00033005) // Value can be either 1 or 0
+)
+
+// Cursor mode values
+const (
+	CursorNormal   = CursorMode(0x00034001)
+	CursorHidden   = CursorMode(0x00034002)
+	CursorDisabled = CursorMode(0x00034003)
+)
+
+// HeadlessWindow is a window without a platform window, selected by the "soft" build tag,
+// which renders with the software OpenGL state into its default framebuffer.
+// It allows running the scene, graph and GUI logic without OpenGL drivers,
+// for instance in unit tests, which can dispatch window events directly with Dispatch.
+type HeadlessWindow struct {
+	core.Dispatcher                 // Embedded event dispatcher
+	gls             *gls.GLS        // Associated software OpenGL state
+	width           int             // Width in pixels
+	height          int             // Height in pixels
+	fullscreen      bool            // Full screen flag (has no effect)
+	shouldClose     bool            // Close requested flag
+	cursor          Cursor          // Current cursor
+	lastCursorKey   Cursor          // Last custom cursor handle
+	cursors         map[Cursor]bool // Custom cursors
+	scaleX          float64         // Horizontal DPI scale factor
+	scaleY          float64         // Vertical DPI scale factor
+	clipboard       string          // Clipboard contents
+	mouseCaptured   bool            // Mouse captured flag (has no effect)
+	textInput       bool            // Text input flag (has no effect)
+	textRect        [4]float32      // Rectangle of the edited text (has no effect)
+	monitors        []*Monitor      // Simulated monitors
+	sizeEv          SizeEvent       // Window size event
+	scaleEv         ScaleEvent      // Window scale event
+	monEv           MonitorEvent    // Monitor event
+}
+
+// Init initializes the HeadlessWindow singleton with the specified width and height in pixels.
+// The title is ignored.
+func Init(width, height int, title string) error {
+
+	return InitWithOptions(&Options{Width: width, Height: height, Title: title})
+}
+
+// InitWithOptions initializes the HeadlessWindow singleton with the width and height
+// in pixels of the specified options. The other options are ignored.
+func InitWithOptions(opts *Options) error {
+
+	// Panic if already created
+	if win != nil {
+		panic(fmt.Errorf("can only call window.Init() once"))
+	}
+
+	w := new(HeadlessWindow)
+	w.Dispatcher.Initialize()
+	var err error
+	w.gls, err = gls.New()
+	if err != nil {
+		return err
+	}
+	w.width = opts.Width
+	w.height = opts.Height
+	w.scaleX = 1
+	w.scaleY = 1
+	w.gls.SetDefaultFramebufferSize(opts.Width, opts.Height)
+	w.cursors = make(map[Cursor]bool)
+	w.lastCursorKey = CursorLast
+	mode := VideoMode{Width: opts.Width, Height: opts.Height, RefreshRate: 60, RedBits: 8, GreenBits: 8, BlueBits: 8}
+	w.monitors = []*Monitor{{
+		Name:       "Headless",
+		Primary:    true,
+		WorkWidth:  opts.Width,
+		WorkHeight: opts.Height,
+		ScaleX:     1,
+		ScaleY:     1,
+		Mode:       mode,
+		Modes:      []VideoMode{mode},
+	}}
+
+	win = w // Set singleton
+	return nil
+}
+
+// Gls returns the associated software OpenGL state.
+func (w *HeadlessWindow) Gls() *gls.GLS {
+
+	return w.gls
+}
+
+// GetFramebufferSize returns the size of the framebuffer in pixels,
+// which is the window size multiplied by the scale.
+func (w *HeadlessWindow) GetFramebufferSize() (width int, height int) {
+
+	return int(float64(w.width) * w.scaleX), int(float64(w.height) * w.scaleY)
+}
+
+// GetSize returns the size of the window in pixels.
+func (w *HeadlessWindow) GetSize() (width int, height int) {
+
+	return w.width, w.height
+}
+
+// SetSize resizes the window and its default framebuffer and dispatches OnWindowSize.
+func (w *HeadlessWindow) SetSize(width int, height int) {
+
+	w.width = width
+	w.height = height
+	w.gls.SetDefaultFramebufferSize(w.GetFramebufferSize())
+	w.sizeEv.Width = width
+	w.sizeEv.Height = height
+	w.Dispatch(OnWindowSize, &w.sizeEv)
+}
+
+// GetScale returns the window DPI scale factor (FramebufferSize / Size). The default is 1.
+func (w *HeadlessWindow) GetScale() (x float64, y float64) {
+
+	return w.scaleX, w.scaleY
+}
+
+// SetScale sets the window DPI scale factor, as when a platform window is moved to
+// a monitor with another scale, resizes the default framebuffer and dispatches
+// OnContentScale followed by OnWindowSize.
+func (w *HeadlessWindow) SetScale(x float64, y float64) {
+
+	w.scaleX = x
+	w.scaleY = y
+	w.scaleEv.X = x
+	w.scaleEv.Y = y
+	w.Dispatch(OnContentScale, &w.scaleEv)
+	w.SetSize(w.width, w.height)
+}
+
+// GetClipboardString returns the contents of the clipboard of this window.
+func (w *HeadlessWindow) GetClipboardString() string {
+
+	return w.clipboard
+}
+
+// SetClipboardString sets the contents of the clipboard of this window.
+func (w *HeadlessWindow) SetClipboardString(str string) {
+
+	w.clipboard = str
+}
+
+// FullScreen returns whether this window was set as full screen.
+func (w *HeadlessWindow) FullScreen() bool {
+
+	return w.fullscreen
+}
+
+// SetFullScreen sets the full screen flag, which has no other effect.
+func (w *HeadlessWindow) SetFullScreen(full bool) {
+
+	w.fullscreen = full
+}
+
+// SetFullScreenMode sets the full screen flag, which has no other effect.
+func (w *HeadlessWindow) SetFullScreenMode(mon *Monitor, mode *VideoMode) {
+
+	w.fullscreen = true
+}
+
+// SetBorderlessFullScreen sets the full screen flag, which has no other effect.
+func (w *HeadlessWindow) SetBorderlessFullScreen(mon *Monitor) {
+
+	w.fullscreen = true
+}
+
+// Monitors returns the simulated monitors. By default there is one
+// primary monitor with the size of the window when it was created.
+func (w *HeadlessWindow) Monitors() []*Monitor {
+
+	return append([]*Monitor(nil), w.monitors...)
+}
+
+// SetMonitors sets the simulated monitors, as when monitors are connected to or
+// disconnected from the system, and dispatches OnMonitor for each monitor
+// which was added or removed from the current monitors.
+func (w *HeadlessWindow) SetMonitors(monitors []*Monitor) {
+
+	old := w.monitors
+	w.monitors = append([]*Monitor(nil), monitors...)
+	for _, mon := range old {
+		if !containsMonitor(monitors, mon) {
+			w.monEv.Monitor = mon
+			w.monEv.Connected = false
+			w.Dispatch(OnMonitor, &w.monEv)
+		}
+	}
+	for _, mon := range monitors {
+		if !containsMonitor(old, mon) {
+			w.monEv.Monitor = mon
+			w.monEv.Connected = true
+			w.Dispatch(OnMonitor, &w.monEv)
+		}
+	}
+}
+
+// containsMonitor returns whether the specified list contains the specified monitor
+func containsMonitor(monitors []*Monitor, mon *Monitor) bool {
+
+	for _, m := range monitors {
+		if m == mon {
+			return true
+		}
+	}
+	return false
+}
+
+// CaptureMouse sets the mouse captured flag, which has no other effect.
+func (w *HeadlessWindow) CaptureMouse(capture bool) {
+
+	w.mouseCaptured = capture
+}
+
+// MouseCaptured returns whether the mouse was set as captured.
+func (w *HeadlessWindow) MouseCaptured() bool {
+
+	return w.mouseCaptured
+}
+
+// SetTextInput sets the text input state and rectangle, which have no other effect.
+func (w *HeadlessWindow) SetTextInput(active bool, x, y, width, height float32) {
+
+	w.textInput = active
+	w.textRect = [4]float32{x, y, width, height}
+}
+
+// TextInput returns the text input state and rectangle set by SetTextInput.
+func (w *HeadlessWindow) TextInput() (active bool, x, y, width, height float32) {
+
+	r := w.textRect
+	return w.textInput, r[0], r[1], r[2], r[3]
+}
+
+// ShouldClose returns whether the window was requested to close.
+func (w *HeadlessWindow) ShouldClose() bool {
+
+	return w.shouldClose
+}
+
+// SetShouldClose sets whether the window is requested to close.
+func (w *HeadlessWindow) SetShouldClose(close bool) {
+
+	w.shouldClose = close
+}
+
+// SwapBuffers does nothing as the default framebuffer is not shown.
+func (w *HeadlessWindow) SwapBuffers() {
+}
+
+// PollEvents does nothing as there are no platform events.
+func (w *HeadlessWindow) PollEvents() {
+}
+
+// Destroy destroys this window.
+func (w *HeadlessWindow) Destroy() {
+
+	w.shouldClose = true
+}
+
+// Cursor returns the current cursor.
+func (w *HeadlessWindow) Cursor() Cursor {
+
+	return w.cursor
+}
+
+// SetCursor sets the window's cursor to a standard cursor
+// or to a custom cursor created by CreateCursor or CreateCursorFromImage.
+func (w *HeadlessWindow) SetCursor(cursor Cursor) {
+
+	if cursor > CursorLast && !w.cursors[cursor] {
+		panic("Invalid cursor")
+	}
+	w.cursor = cursor
+}
+
+// CreateCursor creates a new custom cursor from the specified image file
+// and returns an int handle.
+func (w *HeadlessWindow) CreateCursor(imgFile string, xhot, yhot int) (Cursor, error) {
+
+	// Open image file
+	file, err := os.Open(imgFile)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	// Decode image
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return 0, err
+	}
+	return w.CreateCursorFromImage(img, xhot, yhot)
+}
+
+// CreateCursorFromImage creates a new custom cursor from the specified image
+// with the hotspot at the specified pixel coordinates from the top left corner
+// of the image and returns an int handle.
+func (w *HeadlessWindow) CreateCursorFromImage(img image.Image, xhot, yhot int) (Cursor, error) {
+
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return 0, fmt.Errorf("empty cursor image")
+	}
+	if xhot < 0 || yhot < 0 || xhot >= bounds.Dx() || yhot >= bounds.Dy() {
+		return 0, fmt.Errorf("cursor hotspot (%d,%d) outside of image", xhot, yhot)
+	}
+	w.lastCursorKey++
+	w.cursors[w.lastCursorKey] = true
+	return w.lastCursorKey, nil
+}
+
+// DisposeCursor deletes the existing custom cursor with the provided int handle.
+func (w *HeadlessWindow) DisposeCursor(cursor Cursor) {
+
+	if cursor <= CursorLast {
+		panic("Can't dispose standard cursor")
+	}
+	delete(w.cursors, cursor)
+}
+
+// DisposeAllCustomCursors deletes all existing custom cursors.
+func (w *HeadlessWindow) DisposeAllCustomCursors() {
+
+	w.cursors = make(map[Cursor]bool)
+	w.lastCursorKey = CursorLast
+}
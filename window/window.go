@@ -8,6 +8,7 @@ package window
 
 import (
 	"fmt"
+	"image"
 
 	"github.com/g3n/engine/core"
 	"github.com/g3n/engine/gls"
@@ -37,11 +38,21 @@ type IWindow interface {
 	GetSize() (width int, height int)
 	GetScale() (x float64, y float64)
 	CreateCursor(imgFile string, xhot, yhot int) (Cursor, error)
+	CreateCursorFromImage(img image.Image, xhot, yhot int) (Cursor, error)
 	SetCursor(cursor Cursor)
+	DisposeCursor(cursor Cursor)
 	DisposeAllCustomCursors()
 	Destroy()
 	FullScreen() bool
 	SetFullScreen(full bool)
+	Monitors() []*Monitor
+	SetFullScreenMode(mon *Monitor, mode *VideoMode)
+	SetBorderlessFullScreen(mon *Monitor)
+	GetClipboardString() string
+	SetClipboardString(str string)
+	CaptureMouse(capture bool)
+	MouseCaptured() bool
+	SetTextInput(active bool, x, y, width, height float32)
 }
 
 // Key corresponds to a keyboard key.
@@ -76,18 +87,24 @@ const (
 )
 
 // Window event names. See availability per platform below ("x" indicates available).
-const ( //                               Desktop | Browser |
-	OnWindowFocus = "w.OnWindowFocus" //    x    |    x    |
-	OnWindowPos   = "w.OnWindowPos"   //    x    |         |
-	OnWindowSize  = "w.OnWindowSize"  //    x    |         |
-	OnKeyUp       = "w.OnKeyUp"       //    x    |    x    |
-	OnKeyDown     = "w.OnKeyDown"     //    x    |    x    |
-	OnKeyRepeat   = "w.OnKeyRepeat"   //    x    |         |
-	OnChar        = "w.OnChar"        //    x    |    x    |
-	OnCursor      = "w.OnCursor"      //    x    |    x    |
-	OnMouseUp     = "w.OnMouseUp"     //    x    |    x    |
-	OnMouseDown   = "w.OnMouseDown"   //    x    |    x    |
-	OnScroll      = "w.OnScroll"      //    x    |    x    |
+const ( //                                 Desktop | Browser |
+	OnWindowFocus  = "w.OnWindowFocus"  //    x    |    x    |
+	OnWindowPos    = "w.OnWindowPos"    //    x    |         |
+	OnWindowSize   = "w.OnWindowSize"   //    x    |         |
+	OnKeyUp        = "w.OnKeyUp"        //    x    |    x    |
+	OnKeyDown      = "w.OnKeyDown"      //    x    |    x    |
+	OnKeyRepeat    = "w.OnKeyRepeat"    //    x    |         |
+	OnChar         = "w.OnChar"         //    x    |    x    |
+	OnCursor       = "w.OnCursor"       //    x    |    x    |
+	OnMouseUp      = "w.OnMouseUp"      //    x    |    x    |
+	OnMouseDown    = "w.OnMouseDown"    //    x    |    x    |
+	OnScroll       = "w.OnScroll"       //    x    |    x    |
+	OnDrop         = "w.OnDrop"         //    x    |         |
+	OnContentScale = "w.OnContentScale" //    x    |         |
+	OnComposition  = "w.OnComposition"  //         |    x    |
+	OnContextLost  = "w.OnContextLost"  //         |    x    |
+	OnContextReset = "w.OnContextReset" //         |    x    |
+	OnMonitor      = "w.OnMonitor"      //    x    |         |
 )
 
 // PosEvent describes a windows position changed event
@@ -136,6 +153,81 @@ type ScrollEvent struct {
 	Mods    ModifierKey
 }
 
+// DropEvent describes files dropped on the window from the operating system
+type DropEvent struct {
+	Xpos  float32  // Cursor position where the files were dropped
+	Ypos  float32  // Cursor position where the files were dropped
+	Paths []string // Paths of the dropped files
+	Mods  ModifierKey
+}
+
+// ScaleEvent describes a change of the window DPI scale factor returned by GetScale,
+// as when the window is moved to a monitor with another scale. It is followed by an
+// OnWindowSize event as the framebuffer size also changes.
+type ScaleEvent struct {
+	X float64
+	Y float64
+}
+
+// CompositionEvent describes the text being composed with an input method editor (IME),
+// which is enabled by SetTextInput. The composed text is dispatched with Active false
+// when the composition ends and its characters are also dispatched as OnChar events.
+type CompositionEvent struct {
+	Text   string // Text being composed or committed
+	Active bool   // Composition in progress
+}
+
+// MonitorEvent describes the connection or disconnection of a monitor
+type MonitorEvent struct {
+	Monitor   *Monitor // Monitor connected or disconnected
+	Connected bool     // Monitor was connected
+}
+
+// VideoMode describes a video mode of a monitor
+type VideoMode struct {
+	Width       int // Width in screen coordinates
+	Height      int // Height in screen coordinates
+	RefreshRate int // Refresh rate in Hz
+	RedBits     int // Bit depth of the red channel
+	GreenBits   int // Bit depth of the green channel
+	BlueBits    int // Bit depth of the blue channel
+}
+
+// Monitor describes a monitor connected to the system, as returned by IWindow.Monitors.
+// The positions and sizes are in screen coordinates of the virtual screen which contains all the monitors.
+type Monitor struct {
+	Name       string      // Human readable name
+	Primary    bool        // Primary monitor
+	X          int         // Position of the monitor in the virtual screen
+	Y          int         // Position of the monitor in the virtual screen
+	WorkX      int         // Position of the work area, not covered by task bars or menu bars
+	WorkY      int         // Position of the work area, not covered by task bars or menu bars
+	WorkWidth  int         // Size of the work area
+	WorkHeight int         // Size of the work area
+	ScaleX     float64     // Horizontal DPI content scale
+	ScaleY     float64     // Vertical DPI content scale
+	WidthMM    int         // Physical width in millimeters (0 if unknown)
+	HeightMM   int         // Physical height in millimeters (0 if unknown)
+	Mode       VideoMode   // Current video mode
+	Modes      []VideoMode // Supported video modes
+	handle     interface{} // Platform monitor
+}
+
+// Options contains the options of the desktop window created by InitWithOptions.
+// The zero value of each option is the default of the window created by Init.
+type Options struct {
+	Width       int    // Width of the window in screen coordinates
+	Height      int    // Height of the window in screen coordinates
+	Title       string // Title of the window
+	Undecorated bool   // Create the window without the border, title bar and buttons of the window manager
+	Floating    bool   // Keep the window above the other windows (always on top)
+	Transparent bool   // Composite the framebuffer with the desktop using the alpha of its pixels
+	Maximized   bool   // Create the window maximized
+	Positioned  bool   // Place the window at X, Y instead of the position chosen by the window manager
+	X           int    // Horizontal position of the content area of the window if Positioned
+	Y           int    // Vertical position of the content area of the window if Positioned
+}
+
 // FocusEvent describes a focus event
 type FocusEvent struct {
 	Focused bool